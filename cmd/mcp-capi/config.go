@@ -0,0 +1,585 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/approval"
+	"github.com/giantswarm/mcp-capi/internal/audit"
+	"github.com/giantswarm/mcp-capi/internal/auth"
+	"github.com/giantswarm/mcp-capi/internal/backup"
+	"github.com/giantswarm/mcp-capi/internal/fleet"
+	"github.com/giantswarm/mcp-capi/internal/jobs"
+	"github.com/giantswarm/mcp-capi/internal/locks"
+	"github.com/giantswarm/mcp-capi/internal/logging"
+	"github.com/giantswarm/mcp-capi/internal/maintenance"
+	"github.com/giantswarm/mcp-capi/internal/monitor"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/internal/ratelimit"
+	"github.com/giantswarm/mcp-capi/internal/schedule"
+	"github.com/giantswarm/mcp-capi/internal/toolpolicy"
+	"github.com/giantswarm/mcp-capi/internal/tools"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"sigs.k8s.io/yaml"
+)
+
+// loadLogger configures the structured logs from LOG_LEVEL (debug, info,
+// warn or error, default: info) and LOG_FORMAT (text or json, default: text)
+func loadLogger() (*slog.Logger, error) {
+	level, err := logging.ParseLevel(os.Getenv("LOG_LEVEL"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOG_LEVEL %q (must be debug, info, warn or error)", os.Getenv("LOG_LEVEL"))
+	}
+	format, err := logging.ParseFormat(os.Getenv("LOG_FORMAT"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LOG_FORMAT %q (must be text or json)", os.Getenv("LOG_FORMAT"))
+	}
+	return logging.New(os.Stderr, logging.Config{Level: level, Format: format}), nil
+}
+
+// loadAllowedNamespaces reads MCP_ALLOWED_NAMESPACES, the comma-separated
+// namespaces all tool calls are confined to; empty allows all namespaces
+func loadAllowedNamespaces() ([]string, error) {
+	namespaces := toolpolicy.ParseList(os.Getenv("MCP_ALLOWED_NAMESPACES"))
+	for _, namespace := range namespaces {
+		if err := params.Namespace("namespace", namespace); err != nil {
+			return nil, fmt.Errorf("invalid MCP_ALLOWED_NAMESPACES entry %q (must be a namespace name)", namespace)
+		}
+	}
+	return namespaces, nil
+}
+
+// loadDefaultKubernetesVersion reads MCP_DEFAULT_KUBERNETES_VERSION, the
+// version of new clusters that do not ask for one
+func loadDefaultKubernetesVersion() (string, error) {
+	version := os.Getenv("MCP_DEFAULT_KUBERNETES_VERSION")
+	if version == "" {
+		return "", nil
+	}
+	if err := params.Semver("version", version); err != nil {
+		return "", fmt.Errorf("invalid MCP_DEFAULT_KUBERNETES_VERSION %q (must be a version such as v1.31.2)", version)
+	}
+	return version, nil
+}
+
+// loadChangeHistory configures the undo registry from MCP_CHANGE_HISTORY_FILE and MCP_CHANGE_HISTORY_SIZE
+func loadChangeHistory() (*capi.ChangeHistory, error) {
+	size := capi.DefaultChangeHistorySize
+	if value := os.Getenv("MCP_CHANGE_HISTORY_SIZE"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid MCP_CHANGE_HISTORY_SIZE %q (must be a positive integer)", value)
+		}
+		size = n
+	}
+
+	return capi.NewChangeHistory(size, os.Getenv("MCP_CHANGE_HISTORY_FILE"))
+}
+
+// loadApprovalManager configures the approval manager from environment variables
+func loadApprovalManager() (*approval.Manager, error) {
+	mode := approval.Mode(strings.ToLower(os.Getenv("MCP_APPROVAL_MODE")))
+	switch mode {
+	case approval.ModeDisabled, approval.ModeBlock, approval.ModeEnqueue:
+	default:
+		return nil, fmt.Errorf("invalid MCP_APPROVAL_MODE %q (must be 'block' or 'enqueue')", mode)
+	}
+
+	config := approval.Config{Mode: mode}
+
+	if timeout := os.Getenv("MCP_APPROVAL_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MCP_APPROVAL_TIMEOUT: %w", err)
+		}
+		config.Timeout = d
+	}
+
+	if value := os.Getenv("MCP_APPROVAL_RETENTION"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid MCP_APPROVAL_RETENTION %q (must be a positive duration)", value)
+		}
+		config.Retention = d
+	}
+
+	if webhookURL := os.Getenv("MCP_APPROVAL_WEBHOOK_URL"); webhookURL != "" {
+		config.Notifier = approval.NewWebhookNotifier(webhookURL, os.Getenv("MCP_APPROVAL_CALLBACK_URL"))
+	}
+
+	return approval.NewManager(config), nil
+}
+
+// startApprovalCallbackServer serves the approval callback endpoint if configured
+func startApprovalCallbackServer(mgr *approval.Manager) *http.Server {
+	addr := os.Getenv("MCP_APPROVAL_CALLBACK_ADDR")
+	if addr == "" || !mgr.Enabled() {
+		return nil
+	}
+
+	token := os.Getenv("MCP_APPROVAL_CALLBACK_TOKEN")
+	if token == "" {
+		slog.Warn("MCP_APPROVAL_CALLBACK_ADDR is set but MCP_APPROVAL_CALLBACK_TOKEN is empty, callback endpoint disabled")
+		return nil
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mgr.Handler(token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		slog.Info("Starting approval callback endpoint", "address", addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("Approval callback endpoint failed", "error", err)
+		}
+	}()
+
+	return srv
+}
+
+// loadToolPolicy builds the tool policy from MCP_TOOLS_CONFIG, MCP_TOOLS_ALLOW and MCP_TOOLS_DENY
+func loadToolPolicy() (*toolpolicy.Policy, error) {
+	policy := &toolpolicy.Policy{}
+
+	if filename := os.Getenv("MCP_TOOLS_CONFIG"); filename != "" {
+		filePolicy, err := toolpolicy.LoadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		policy = filePolicy
+	}
+
+	envPolicy := &toolpolicy.Policy{
+		Allow: toolpolicy.ParseList(os.Getenv("MCP_TOOLS_ALLOW")),
+		Deny:  toolpolicy.ParseList(os.Getenv("MCP_TOOLS_DENY")),
+	}
+	if err := envPolicy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return policy.Merge(envPolicy), nil
+}
+
+// loadAuditLogger configures the audit log from MCP_AUDIT_LOG_FILE,
+// MCP_AUDIT_EVENTS_NAMESPACE and MCP_AUDIT_BUFFER_SIZE
+func loadAuditLogger(capiClient *capi.Client) (*audit.Logger, error) {
+	var sinks []audit.Sink
+
+	if filename := os.Getenv("MCP_AUDIT_LOG_FILE"); filename != "" {
+		sink, err := audit.NewFileSink(filename)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	if namespace := os.Getenv("MCP_AUDIT_EVENTS_NAMESPACE"); namespace != "" {
+		sinks = append(sinks, audit.NewEventSink(capiClient.GetK8sClient(), namespace, serverName))
+	}
+
+	capacity := audit.DefaultCapacity
+	if size := os.Getenv("MCP_AUDIT_BUFFER_SIZE"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid MCP_AUDIT_BUFFER_SIZE %q (must be a positive integer)", size)
+		}
+		capacity = n
+	}
+
+	return audit.NewLogger(capacity, sinks...), nil
+}
+
+// loadAuthenticator configures authentication of network transports from
+// MCP_AUTH_CONFIG. It returns nil if authentication is not configured.
+func loadAuthenticator() (auth.Authenticator, error) {
+	filename := os.Getenv("MCP_AUTH_CONFIG")
+	if filename == "" {
+		return nil, nil
+	}
+
+	config, err := auth.LoadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return auth.New(config)
+}
+
+// managementClustersFile is the format of MCP_MANAGEMENT_CLUSTERS_CONFIG
+type managementClustersFile struct {
+	ManagementClusters []capi.ManagementCluster `json:"managementClusters"`
+}
+
+// loadManagementClusters builds the management cluster registry from the YAML
+// file referenced by MCP_MANAGEMENT_CLUSTERS_CONFIG and from
+// MCP_MANAGEMENT_CLUSTERS, a comma-separated list of name=context pairs of the
+// default kubeconfig
+func loadManagementClusters() ([]capi.ManagementCluster, error) {
+	var clusters []capi.ManagementCluster
+
+	if filename := os.Getenv("MCP_MANAGEMENT_CLUSTERS_CONFIG"); filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read management clusters file: %w", err)
+		}
+		file := managementClustersFile{}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse management clusters file: %w", err)
+		}
+		clusters = append(clusters, file.ManagementClusters...)
+	}
+
+	for _, entry := range toolpolicy.ParseList(os.Getenv("MCP_MANAGEMENT_CLUSTERS")) {
+		name, contextName, ok := strings.Cut(entry, "=")
+		name, contextName = strings.TrimSpace(name), strings.TrimSpace(contextName)
+		if !ok || name == "" || contextName == "" {
+			return nil, fmt.Errorf("invalid MCP_MANAGEMENT_CLUSTERS entry %q (must be name=context)", entry)
+		}
+		clusters = append(clusters, capi.ManagementCluster{Name: name, Context: contextName})
+	}
+	return clusters, nil
+}
+
+// loadClientOptions configures the connection to the management cluster from
+// MCP_KUBE_CONTEXT, MCP_KUBE_QPS, MCP_KUBE_BURST, MCP_KUBE_TIMEOUT, the
+// operation timeouts MCP_KUBE_READ_TIMEOUT, MCP_KUBE_WRITE_TIMEOUT and
+// MCP_KUBE_DRAIN_TIMEOUT, the retry policy MCP_KUBE_MAX_RETRIES,
+// MCP_KUBE_RETRY_BACKOFF and MCP_KUBE_RETRY_MAX_BACKOFF,
+// MCP_KUBE_IMPERSONATE_USER and MCP_KUBE_IMPERSONATE_GROUPS
+func loadClientOptions() ([]capi.Option, error) {
+	opts := []capi.Option{capi.WithUserAgent(serverName + "/" + serverVersion)}
+
+	if contextName := os.Getenv("MCP_KUBE_CONTEXT"); contextName != "" {
+		opts = append(opts, capi.WithContext(contextName))
+	}
+
+	var qps float64
+	if value := os.Getenv("MCP_KUBE_QPS"); value != "" {
+		n, err := strconv.ParseFloat(value, 32)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid MCP_KUBE_QPS %q (must be a positive number)", value)
+		}
+		qps = n
+	}
+	var burst int
+	if value := os.Getenv("MCP_KUBE_BURST"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid MCP_KUBE_BURST %q (must be a positive integer)", value)
+		}
+		burst = n
+	}
+	if qps > 0 || burst > 0 {
+		opts = append(opts, capi.WithRateLimit(float32(qps), burst))
+	}
+
+	if value := os.Getenv("MCP_KUBE_TIMEOUT"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid MCP_KUBE_TIMEOUT %q (must be a positive duration)", value)
+		}
+		opts = append(opts, capi.WithTimeout(d))
+	}
+
+	var timeouts capi.OperationTimeouts
+	for _, timeout := range []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"MCP_KUBE_READ_TIMEOUT", &timeouts.Read},
+		{"MCP_KUBE_WRITE_TIMEOUT", &timeouts.Write},
+		{"MCP_KUBE_DRAIN_TIMEOUT", &timeouts.Drain},
+	} {
+		value := os.Getenv(timeout.name)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid %s %q (must be a positive duration)", timeout.name, value)
+		}
+		*timeout.value = d
+	}
+	if timeouts != (capi.OperationTimeouts{}) {
+		opts = append(opts, capi.WithOperationTimeouts(timeouts))
+	}
+
+	policy := capi.DefaultRetryPolicy
+	if value := os.Getenv("MCP_KUBE_MAX_RETRIES"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid MCP_KUBE_MAX_RETRIES %q (must be a non-negative integer)", value)
+		}
+		policy.MaxRetries = n
+	}
+	for _, backoff := range []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"MCP_KUBE_RETRY_BACKOFF", &policy.InitialBackoff},
+		{"MCP_KUBE_RETRY_MAX_BACKOFF", &policy.MaxBackoff},
+	} {
+		value := os.Getenv(backoff.name)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid %s %q (must be a positive duration)", backoff.name, value)
+		}
+		*backoff.value = d
+	}
+	if policy != capi.DefaultRetryPolicy {
+		opts = append(opts, capi.WithRetryPolicy(policy))
+	}
+
+	groups := toolpolicy.ParseList(os.Getenv("MCP_KUBE_IMPERSONATE_GROUPS"))
+	if user := os.Getenv("MCP_KUBE_IMPERSONATE_USER"); user != "" {
+		opts = append(opts, capi.WithImpersonation(user, groups...))
+	} else if len(groups) > 0 {
+		return nil, fmt.Errorf("MCP_KUBE_IMPERSONATE_GROUPS requires MCP_KUBE_IMPERSONATE_USER")
+	}
+
+	return opts, nil
+}
+
+// loadRateLimiter configures per-tool rate limits from MCP_TOOL_RATE_LIMITS
+func loadRateLimiter() (*ratelimit.Limiter, error) {
+	rules, err := ratelimit.ParseRules(os.Getenv("MCP_TOOL_RATE_LIMITS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MCP_TOOL_RATE_LIMITS: %w", err)
+	}
+	return ratelimit.New(rules), nil
+}
+
+// loadJobManager configures the background jobs from MCP_JOBS_MAX_RUNNING and
+// MCP_JOBS_RETENTION
+func loadJobManager() (*jobs.Manager, error) {
+	config := jobs.Config{}
+
+	if value := os.Getenv("MCP_JOBS_MAX_RUNNING"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid MCP_JOBS_MAX_RUNNING %q (must be a positive integer)", value)
+		}
+		config.MaxRunning = n
+	}
+
+	if value := os.Getenv("MCP_JOBS_RETENTION"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid MCP_JOBS_RETENTION %q (must be a positive duration)", value)
+		}
+		config.Retention = d
+	}
+
+	return jobs.NewManager(config), nil
+}
+
+// loadLockManager configures how operations on the same cluster are
+// serialized from MCP_LOCK_MODE, MCP_LOCK_WAIT_TIMEOUT, and
+// MCP_LOCK_LEASE_DURATION and MCP_LOCK_IDENTITY for operation leases
+func loadLockManager() (*locks.Manager, error) {
+	config := locks.Config{
+		Mode:     os.Getenv("MCP_LOCK_MODE"),
+		Identity: os.Getenv("MCP_LOCK_IDENTITY"),
+	}
+
+	if value := os.Getenv("MCP_LOCK_WAIT_TIMEOUT"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid MCP_LOCK_WAIT_TIMEOUT %q (must be a positive duration)", value)
+		}
+		config.WaitTimeout = d
+	}
+
+	if value := os.Getenv("MCP_LOCK_LEASE_DURATION"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < time.Second {
+			return nil, fmt.Errorf("invalid MCP_LOCK_LEASE_DURATION %q (must be a duration of at least 1s)", value)
+		}
+		config.LeaseDuration = d
+	}
+
+	return locks.NewManager(config)
+}
+
+// loadCanaryStore configures the state of canary upgrades from MCP_CANARY_STATE_FILE
+func loadCanaryStore() (*fleet.CanaryStore, error) {
+	return fleet.NewCanaryStore(os.Getenv("MCP_CANARY_STATE_FILE"))
+}
+
+// loadMaintenanceWindows loads the maintenance window rules from the file at
+// MCP_MAINTENANCE_WINDOWS_CONFIG. Without it only the windows of cluster
+// annotations apply.
+func loadMaintenanceWindows() (*maintenance.Config, error) {
+	filename := os.Getenv("MCP_MAINTENANCE_WINDOWS_CONFIG")
+	if filename == "" {
+		return nil, nil
+	}
+	return maintenance.LoadFile(filename)
+}
+
+// loadBackupTargets reads the storage backends cluster backups are pushed
+// to from the file at MCP_BACKUP_TARGETS_CONFIG
+func loadBackupTargets() (*backup.Targets, error) {
+	filename := os.Getenv("MCP_BACKUP_TARGETS_CONFIG")
+	if filename == "" {
+		return nil, nil
+	}
+	return backup.LoadFile(filename)
+}
+
+// loadGitOpsGuardMode reads MCP_GITOPS_GUARD, how tools treat objects
+// managed by Flux or Argo CD: refuse (the default) or warn
+func loadGitOpsGuardMode() (string, error) {
+	switch mode := os.Getenv("MCP_GITOPS_GUARD"); mode {
+	case "":
+		return tools.GitOpsGuardRefuse, nil
+	case tools.GitOpsGuardRefuse, tools.GitOpsGuardWarn:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid MCP_GITOPS_GUARD %q, use %s or %s", mode, tools.GitOpsGuardRefuse, tools.GitOpsGuardWarn)
+	}
+}
+
+// loadScheduler configures scheduled operations from MCP_SCHEDULE_NAMESPACE,
+// where they are stored, and MCP_SCHEDULE_STARTING_DEADLINE. Schedules act on
+// the management cluster the server was started with, skip runs outside
+// the maintenance windows of their cluster, lock it with clusterLocks while
+// they change it and push backups to backupTargets. It returns nil if no
+// namespace is set.
+func loadScheduler(capiClient *capi.Client, jobManager *jobs.Manager, windows *maintenance.Config, clusterLocks *locks.Manager, backupTargets *backup.Targets) (*schedule.Scheduler, error) {
+	namespace := os.Getenv("MCP_SCHEDULE_NAMESPACE")
+	if namespace == "" {
+		return nil, nil
+	}
+
+	config := schedule.Config{BackupTargets: backupTargets, Locks: clusterLocks}
+	if value := os.Getenv("MCP_SCHEDULE_STARTING_DEADLINE"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid MCP_SCHEDULE_STARTING_DEADLINE %q (must be a positive duration)", value)
+		}
+		config.StartingDeadline = d
+	}
+
+	config.Gate = func(ctx context.Context, s *schedule.Schedule, now time.Time) error {
+		cluster, err := capiClient.GetCluster(ctx, s.Namespace, s.Cluster)
+		if err != nil {
+			return err
+		}
+		return windows.Check(cluster, now)
+	}
+
+	store := schedule.NewStore(capiClient.GetK8sClient(), namespace)
+	return schedule.NewScheduler(store, capiClient, jobManager, config), nil
+}
+
+// staleScanConfig configures the background scan for stale resources
+type staleScanConfig struct {
+	Interval   time.Duration
+	Thresholds capi.StaleThresholds
+}
+
+// loadStaleScan reads MCP_STALE_SCAN_INTERVAL, how often clients are
+// notified about new stale resources, and MCP_STALE_AFTER, which replaces the
+// default thresholds of all kinds. The scan is disabled without an interval.
+func loadStaleScan() (staleScanConfig, error) {
+	config := staleScanConfig{}
+	if value := os.Getenv("MCP_STALE_SCAN_INTERVAL"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return config, fmt.Errorf("invalid MCP_STALE_SCAN_INTERVAL %q (must be a positive duration)", value)
+		}
+		config.Interval = d
+	}
+	if value := os.Getenv("MCP_STALE_AFTER"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return config, fmt.Errorf("invalid MCP_STALE_AFTER %q (must be a positive duration)", value)
+		}
+		config.Thresholds = capi.StaleThresholds{Provisioning: d, Deleting: d, Cluster: d, Rollout: d}
+	}
+	return config, nil
+}
+
+// healthMonitorConfig configures the background health monitor and where
+// it sends alerts
+type healthMonitorConfig struct {
+	Interval      time.Duration
+	WebhookURL    string
+	SlackURL      string
+	Notifications bool
+}
+
+// loadHealthMonitor reads MCP_MONITOR_INTERVAL, how often cluster health is
+// checked, and the alert sinks MCP_MONITOR_WEBHOOK_URL, MCP_MONITOR_SLACK_URL
+// and MCP_MONITOR_NOTIFICATIONS, whether MCP clients get logging
+// notifications (default: true). The monitor is disabled without an interval.
+func loadHealthMonitor() (healthMonitorConfig, error) {
+	config := healthMonitorConfig{Notifications: true}
+	if value := os.Getenv("MCP_MONITOR_INTERVAL"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return config, fmt.Errorf("invalid MCP_MONITOR_INTERVAL %q (must be a positive duration)", value)
+		}
+		config.Interval = d
+	}
+	for name, target := range map[string]*string{"MCP_MONITOR_WEBHOOK_URL": &config.WebhookURL, "MCP_MONITOR_SLACK_URL": &config.SlackURL} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		if u, err := url.Parse(value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return config, fmt.Errorf("invalid %s %q (must be an http or https URL)", name, value)
+		}
+		*target = value
+	}
+	if value := os.Getenv("MCP_MONITOR_NOTIFICATIONS"); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid MCP_MONITOR_NOTIFICATIONS %q (must be a boolean)", value)
+		}
+		config.Notifications = enabled
+	}
+	return config, nil
+}
+
+// sinks creates the configured alert sinks, sending MCP logging
+// notifications through notifier
+func (c healthMonitorConfig) sinks(notifier monitor.Notifier) []monitor.Sink {
+	var sinks []monitor.Sink
+	if c.WebhookURL != "" {
+		sinks = append(sinks, monitor.NewWebhookSink(c.WebhookURL))
+	}
+	if c.SlackURL != "" {
+		sinks = append(sinks, monitor.NewSlackSink(c.SlackURL))
+	}
+	if c.Notifications {
+		sinks = append(sinks, &monitor.NotificationSink{Notifier: notifier})
+	}
+	return sinks
+}
+
+// loadProviderRepository configures where provider releases and CNI
+// manifests are fetched from: GitHub, or the mirror at
+// MCP_PROVIDER_REPOSITORY_URL, MCP_PROVIDER_API_URL and MCP_PROVIDER_RAW_URL,
+// authenticated with GITHUB_TOKEN like clusterctl
+func loadProviderRepository() *capi.GitHubRepository {
+	return &capi.GitHubRepository{
+		BaseURL:    os.Getenv("MCP_PROVIDER_REPOSITORY_URL"),
+		APIURL:     os.Getenv("MCP_PROVIDER_API_URL"),
+		RawURL:     os.Getenv("MCP_PROVIDER_RAW_URL"),
+		Token:      os.Getenv("GITHUB_TOKEN"),
+		HTTPClient: &http.Client{Timeout: time.Minute},
+	}
+}
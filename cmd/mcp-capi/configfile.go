@@ -0,0 +1,227 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// serverConfigFile is the YAML configuration file of the server. Every
+// setting corresponds to an environment variable, which takes precedence
+// over the file, and command line flags take precedence over both.
+type serverConfigFile struct {
+	Kubernetes struct {
+		Kubeconfig        string   `json:"kubeconfig"`
+		Context           string   `json:"context"`
+		KubeconfigDir     string   `json:"kubeconfigDir"`
+		Reload            *bool    `json:"reload"`
+		QPS               *float64 `json:"qps"`
+		Burst             *int     `json:"burst"`
+		Timeout           string   `json:"timeout"`
+		ReadTimeout       string   `json:"readTimeout"`
+		WriteTimeout      string   `json:"writeTimeout"`
+		DrainTimeout      string   `json:"drainTimeout"`
+		MaxRetries        *int     `json:"maxRetries"`
+		RetryBackoff      string   `json:"retryBackoff"`
+		RetryMaxBackoff   string   `json:"retryMaxBackoff"`
+		ImpersonateUser   string   `json:"impersonateUser"`
+		ImpersonateGroups []string `json:"impersonateGroups"`
+	} `json:"kubernetes"`
+	ManagementClusters struct {
+		Config   string            `json:"config"`
+		Contexts map[string]string `json:"contexts"`
+	} `json:"managementClusters"`
+	Transport struct {
+		Type            string `json:"type"`
+		ListenAddr      string `json:"listenAddr"`
+		BaseURL         string `json:"baseURL"`
+		TLSCertFile     string `json:"tlsCertFile"`
+		TLSKeyFile      string `json:"tlsKeyFile"`
+		ShutdownTimeout string `json:"shutdownTimeout"`
+	} `json:"transport"`
+	Auth struct {
+		Config   string `json:"config"`
+		Disabled *bool  `json:"disabled"`
+	} `json:"auth"`
+	Log struct {
+		Level  string `json:"level"`
+		Format string `json:"format"`
+	} `json:"log"`
+	// AllowedNamespaces confines all tool calls to these namespaces
+	AllowedNamespaces []string `json:"allowedNamespaces"`
+	Tools             struct {
+		Config            string   `json:"config"`
+		Allow             []string `json:"allow"`
+		Deny              []string `json:"deny"`
+		ProviderDiscovery *bool    `json:"providerDiscovery"`
+		// RateLimits map tool rules to limits like 20/m
+		RateLimits map[string]string `json:"rateLimits"`
+	} `json:"tools"`
+	// Cache sizes and retention of the state the server keeps in memory
+	Cache struct {
+		AuditEntries  *int   `json:"auditEntries"`
+		Changes       *int   `json:"changes"`
+		JobsRetention string `json:"jobsRetention"`
+	} `json:"cache"`
+	// Locks serialize operations changing the same cluster
+	Locks struct {
+		Mode          string `json:"mode"`
+		WaitTimeout   string `json:"waitTimeout"`
+		LeaseDuration string `json:"leaseDuration"`
+		Identity      string `json:"identity"`
+	} `json:"locks"`
+	Defaults struct {
+		KubernetesVersion string `json:"kubernetesVersion"`
+	} `json:"defaults"`
+}
+
+// environment returns the environment variables of the settings in the file
+func (f *serverConfigFile) environment() map[string]string {
+	env := map[string]string{}
+	set := func(name, value string) {
+		if value != "" {
+			env[name] = value
+		}
+	}
+	list := func(name string, values []string) {
+		set(name, strings.Join(values, ","))
+	}
+	boolean := func(name string, value *bool) {
+		if value != nil {
+			set(name, strconv.FormatBool(*value))
+		}
+	}
+	integer := func(name string, value *int) {
+		if value != nil {
+			set(name, strconv.Itoa(*value))
+		}
+	}
+
+	set("KUBECONFIG", f.Kubernetes.Kubeconfig)
+	set("MCP_KUBE_CONTEXT", f.Kubernetes.Context)
+	set("MCP_KUBECONFIG_DIR", f.Kubernetes.KubeconfigDir)
+	boolean("MCP_KUBECONFIG_RELOAD", f.Kubernetes.Reload)
+	if f.Kubernetes.QPS != nil {
+		set("MCP_KUBE_QPS", strconv.FormatFloat(*f.Kubernetes.QPS, 'f', -1, 64))
+	}
+	integer("MCP_KUBE_BURST", f.Kubernetes.Burst)
+	set("MCP_KUBE_TIMEOUT", f.Kubernetes.Timeout)
+	set("MCP_KUBE_READ_TIMEOUT", f.Kubernetes.ReadTimeout)
+	set("MCP_KUBE_WRITE_TIMEOUT", f.Kubernetes.WriteTimeout)
+	set("MCP_KUBE_DRAIN_TIMEOUT", f.Kubernetes.DrainTimeout)
+	integer("MCP_KUBE_MAX_RETRIES", f.Kubernetes.MaxRetries)
+	set("MCP_KUBE_RETRY_BACKOFF", f.Kubernetes.RetryBackoff)
+	set("MCP_KUBE_RETRY_MAX_BACKOFF", f.Kubernetes.RetryMaxBackoff)
+	set("MCP_KUBE_IMPERSONATE_USER", f.Kubernetes.ImpersonateUser)
+	list("MCP_KUBE_IMPERSONATE_GROUPS", f.Kubernetes.ImpersonateGroups)
+
+	set("MCP_MANAGEMENT_CLUSTERS_CONFIG", f.ManagementClusters.Config)
+	var contexts []string
+	for name, contextName := range f.ManagementClusters.Contexts {
+		contexts = append(contexts, name+"="+contextName)
+	}
+	sort.Strings(contexts)
+	list("MCP_MANAGEMENT_CLUSTERS", contexts)
+
+	set("MCP_TRANSPORT", f.Transport.Type)
+	set("MCP_LISTEN_ADDR", f.Transport.ListenAddr)
+	set("MCP_BASE_URL", f.Transport.BaseURL)
+	set("MCP_TLS_CERT_FILE", f.Transport.TLSCertFile)
+	set("MCP_TLS_KEY_FILE", f.Transport.TLSKeyFile)
+	set("MCP_SHUTDOWN_TIMEOUT", f.Transport.ShutdownTimeout)
+
+	set("MCP_AUTH_CONFIG", f.Auth.Config)
+	boolean("MCP_AUTH_DISABLED", f.Auth.Disabled)
+
+	set("LOG_LEVEL", f.Log.Level)
+	set("LOG_FORMAT", f.Log.Format)
+
+	list("MCP_ALLOWED_NAMESPACES", f.AllowedNamespaces)
+	set("MCP_TOOLS_CONFIG", f.Tools.Config)
+	list("MCP_TOOLS_ALLOW", f.Tools.Allow)
+	list("MCP_TOOLS_DENY", f.Tools.Deny)
+	boolean("MCP_PROVIDER_DISCOVERY", f.Tools.ProviderDiscovery)
+	var rateLimits []string
+	for rule, limit := range f.Tools.RateLimits {
+		rateLimits = append(rateLimits, rule+"="+limit)
+	}
+	sort.Strings(rateLimits)
+	list("MCP_TOOL_RATE_LIMITS", rateLimits)
+
+	integer("MCP_AUDIT_BUFFER_SIZE", f.Cache.AuditEntries)
+	integer("MCP_CHANGE_HISTORY_SIZE", f.Cache.Changes)
+	set("MCP_JOBS_RETENTION", f.Cache.JobsRetention)
+
+	set("MCP_LOCK_MODE", f.Locks.Mode)
+	set("MCP_LOCK_WAIT_TIMEOUT", f.Locks.WaitTimeout)
+	set("MCP_LOCK_LEASE_DURATION", f.Locks.LeaseDuration)
+	set("MCP_LOCK_IDENTITY", f.Locks.Identity)
+
+	set("MCP_DEFAULT_KUBERNETES_VERSION", f.Defaults.KubernetesVersion)
+	return env
+}
+
+// configFlags are the command line flags of the server, each overriding an
+// environment variable
+var configFlags = []struct {
+	name, env, usage string
+}{
+	{"kubeconfig", "KUBECONFIG", "path to the kubeconfig file"},
+	{"context", "MCP_KUBE_CONTEXT", "kubeconfig context to start with"},
+	{"transport", "MCP_TRANSPORT", "transport type: stdio, sse or streamable-http"},
+	{"listen-addr", "MCP_LISTEN_ADDR", "listen address of HTTP transports"},
+	{"log-level", "LOG_LEVEL", "logging level: debug, info, warn or error"},
+	{"log-format", "LOG_FORMAT", "log record format: text or json"},
+	{"allowed-namespaces", "MCP_ALLOWED_NAMESPACES", "comma-separated namespaces tool calls are confined to"},
+}
+
+// loadServerConfig parses the command line flags and the configuration file
+// given by --config or MCP_CONFIG_FILE, and exports their settings as the
+// environment variables the rest of the configuration is read from. Flags
+// override the environment, which overrides the file. It returns the
+// arguments after the flags, such as a subcommand.
+func loadServerConfig(args []string) ([]string, error) {
+	flags := flag.NewFlagSet(serverName, flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("MCP_CONFIG_FILE"), "path to the YAML configuration file")
+	values := make([]*string, len(configFlags))
+	for i, f := range configFlags {
+		values[i] = flags.String(f.name, "", f.usage+" (overrides "+f.env+")")
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read configuration file: %w", err)
+		}
+		file := &serverConfigFile{}
+		if err := yaml.UnmarshalStrict(data, file); err != nil {
+			return nil, fmt.Errorf("failed to parse configuration file %s: %w", *configFile, err)
+		}
+		for name, value := range file.environment() {
+			if _, ok := os.LookupEnv(name); ok {
+				continue
+			}
+			if err := os.Setenv(name, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i, f := range configFlags {
+		if *values[i] == "" {
+			continue
+		}
+		if err := os.Setenv(f.env, *values[i]); err != nil {
+			return nil, err
+		}
+	}
+	return flags.Args(), nil
+}
@@ -2,11 +2,18 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
+	"github.com/giantswarm/mcp-capi/internal/monitor"
+	"github.com/giantswarm/mcp-capi/internal/resources"
+	"github.com/giantswarm/mcp-capi/internal/schedule"
+	"github.com/giantswarm/mcp-capi/internal/tools"
 	"github.com/giantswarm/mcp-capi/pkg/capi"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -17,926 +24,359 @@ const (
 	serverVersion = "0.1.0"
 )
 
-// ServerContext holds shared resources for the server
-type ServerContext struct {
-	capiClient *capi.Client
-}
-
 func main() {
-	// Create context that cancels on interrupt
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Handle shutdown gracefully
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		log.Println("Shutdown signal received, closing server...")
-		cancel()
-	}()
-
-	// Initialize CAPI client
-	log.Println("Initializing CAPI client...")
-	capiClient, err := capi.NewClient("")
+	// Settings of the configuration file and flags become the environment
+	// the configuration is read from below
+	args, err := loadServerConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		log.Fatalf("Failed to create CAPI client: %v", err)
+		fatal("Failed to load configuration", err)
 	}
 
-	// Initialize providers
-	if err := capiClient.InitializeProviders(); err != nil {
-		log.Printf("Warning: Failed to initialize providers: %v", err)
+	// Run CLI subcommands such as rbac-manifest without starting the server
+	if runSubcommand(args) {
+		return
 	}
 
-	// Create server context
-	serverCtx := &ServerContext{
-		capiClient: capiClient,
+	// Log structured, redacted records; stray log.Printf calls of libraries
+	// go through the same handler
+	logger, err := loadLogger()
+	if err != nil {
+		fatal("Failed to configure logging", err)
 	}
+	slog.SetDefault(logger)
 
-	// Create MCP server
-	mcpServer := server.NewMCPServer(
-		serverName,
-		serverVersion,
-		server.WithToolCapabilities(true),
-		server.WithResourceCapabilities(true, true), // subscribe, list
-		server.WithPromptCapabilities(true),
-		server.WithLogging(),
-	)
-
-	// Add a simple test tool
-	testTool := mcp.NewTool(
-		"test",
-		mcp.WithDescription("A simple test tool"),
-		mcp.WithString("message",
-			mcp.Required(),
-			mcp.Description("Message to echo back"),
-		),
-	)
-
-	mcpServer.AddTool(testTool, testToolHandler)
-
-	// Add CAPI create cluster tool
-	createClusterTool := mcp.NewTool(
-		"capi_create_cluster",
-		mcp.WithDescription("Create a new CAPI cluster (basic implementation)"),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the cluster"),
-		),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace for the cluster"),
-		),
-		mcp.WithString("provider",
-			mcp.Required(),
-			mcp.Description("Infrastructure provider (aws, azure, gcp, vsphere)"),
-		),
-		mcp.WithString("kubernetes_version",
-			mcp.Description("Kubernetes version (default: v1.29.0)"),
-		),
-		mcp.WithNumber("control_plane_count",
-			mcp.Description("Number of control plane nodes (default: 3)"),
-		),
-		mcp.WithNumber("worker_count",
-			mcp.Description("Number of worker nodes (default: 3)"),
-		),
-		mcp.WithString("region",
-			mcp.Description("Cloud provider region"),
-		),
-		mcp.WithString("instance_type",
-			mcp.Description("Instance type for nodes"),
-		),
-	)
-
-	mcpServer.AddTool(createClusterTool, createCreateClusterHandler(serverCtx))
-
-	// Add CAPI list clusters tool
-	listClustersTool := mcp.NewTool(
-		"capi_list_clusters",
-		mcp.WithDescription("List all CAPI clusters"),
-		mcp.WithString("namespace",
-			mcp.Description("Namespace to filter clusters (optional, empty for all)"),
-		),
-	)
-
-	mcpServer.AddTool(listClustersTool, createListClustersHandler(serverCtx))
-
-	// Add CAPI get cluster tool
-	getClusterTool := mcp.NewTool(
-		"capi_get_cluster",
-		mcp.WithDescription("Get details of a specific CAPI cluster"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the cluster"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the cluster"),
-		),
-	)
+	// Validate the transport before connecting to the management cluster
+	transport, err := loadTransportConfig()
+	if err != nil {
+		fatal("Failed to configure transport", err)
+	}
 
-	mcpServer.AddTool(getClusterTool, createGetClusterHandler(serverCtx))
-
-	// Add CAPI cluster status tool
-	clusterStatusTool := mcp.NewTool(
-		"capi_cluster_status",
-		mcp.WithDescription("Get detailed status of a CAPI cluster including conditions and provider status"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the cluster"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the cluster"),
-		),
-	)
+	// Create context that is canceled once in-flight work has drained on
+	// shutdown; signals are handled once the server is set up
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigChan := make(chan os.Signal, 2)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	mcpServer.AddTool(clusterStatusTool, createClusterStatusHandler(serverCtx))
-
-	// Add CAPI cluster health tool
-	clusterHealthTool := mcp.NewTool(
-		"capi_cluster_health",
-		mcp.WithDescription("Check cluster health and identify issues"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the cluster"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the cluster"),
-		),
-	)
+	// Initialize CAPI client
+	slog.Info("Initializing CAPI client")
+	clientOptions, err := loadClientOptions()
+	if err != nil {
+		fatal("Failed to configure CAPI client", err)
+	}
+	capiClient, err := newDefaultClient(clientOptions)
+	if err != nil {
+		fatal("Failed to create CAPI client", err)
+	}
 
-	mcpServer.AddTool(clusterHealthTool, createClusterHealthHandler(serverCtx))
-
-	// Add CAPI upgrade cluster tool
-	upgradeClusterTool := mcp.NewTool(
-		"capi_upgrade_cluster",
-		mcp.WithDescription("Upgrade a CAPI cluster to a new Kubernetes version"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the cluster"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the cluster"),
-		),
-		mcp.WithString("target_version",
-			mcp.Required(),
-			mcp.Description("Target Kubernetes version (e.g., v1.29.0)"),
-		),
-		mcp.WithBoolean("upgrade_workers",
-			mcp.Description("Also upgrade worker nodes (default: true)"),
-		),
-	)
+	// Detect the Cluster API contract; failures are retried on first use
+	if contract, err := capiClient.APIContract(ctx); err != nil {
+		slog.Warn("Failed to detect the Cluster API contract", "error", err)
+	} else {
+		slog.Info("Detected the Cluster API contract", "contract", contract.Version, "served", strings.Join(contract.Served, ", "))
+		if len(contract.Converted) > 0 {
+			slog.Info("v1beta1 is no longer served, converting objects from v1beta2", "kinds", strings.Join(contract.Converted, ", "))
+		}
+	}
 
-	mcpServer.AddTool(upgradeClusterTool, createUpgradeClusterHandler(serverCtx))
-
-	// Add CAPI update cluster tool
-	updateClusterTool := mcp.NewTool(
-		"capi_update_cluster",
-		mcp.WithDescription("Update cluster metadata (labels and annotations)"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the cluster"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the cluster"),
-		),
-		mcp.WithObject("labels",
-			mcp.Description("Labels to add/update/remove (use empty string to remove)"),
-		),
-		mcp.WithObject("annotations",
-			mcp.Description("Annotations to add/update/remove (use empty string to remove)"),
-		),
-	)
+	// Initialize the undo registry for changes made through the server
+	changeHistory, err := loadChangeHistory()
+	if err != nil {
+		fatal("Failed to configure change history", err)
+	}
+	capiClient.SetChangeHistory(changeHistory)
 
-	mcpServer.AddTool(updateClusterTool, createUpdateClusterHandler(serverCtx))
-
-	// Add CAPI move cluster tool
-	moveClusterTool := mcp.NewTool(
-		"capi_move_cluster",
-		mcp.WithDescription("Prepare a cluster for migration to another management cluster"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the cluster"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the cluster"),
-		),
-		mcp.WithString("target_kubeconfig",
-			mcp.Description("Path to target management cluster kubeconfig"),
-		),
-		mcp.WithString("target_namespace",
-			mcp.Description("Target namespace (defaults to source namespace)"),
-		),
-		mcp.WithBoolean("dry_run",
-			mcp.Description("Show what would be moved without doing it"),
-		),
-	)
+	// Initialize approval gates for destructive tools
+	approvals, err := loadApprovalManager()
+	if err != nil {
+		fatal("Failed to configure approval gates", err)
+	}
+	if approvals.Enabled() {
+		slog.Info("Approval gates enabled", "mode", approvals.Mode())
+	}
+	startApprovalCallbackServer(approvals)
 
-	mcpServer.AddTool(moveClusterTool, createMoveClusterHandler(serverCtx))
-
-	// Add CAPI backup cluster tool
-	backupClusterTool := mcp.NewTool(
-		"capi_backup_cluster",
-		mcp.WithDescription("Create a backup of cluster configuration and resources"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the cluster"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the cluster"),
-		),
-		mcp.WithBoolean("include_secrets",
-			mcp.Description("Include secrets in backup (kubeconfig, certificates)"),
-		),
-		mcp.WithString("output_format",
-			mcp.Description("Output format: yaml or json (default: yaml)"),
-		),
-	)
+	// Load tool allow/deny policy
+	toolPolicy, err := loadToolPolicy()
+	if err != nil {
+		fatal("Failed to load tool policy", err)
+	}
 
-	mcpServer.AddTool(backupClusterTool, createBackupClusterHandler(serverCtx))
-
-	// Add CAPI scale cluster tool
-	scaleClusterTool := mcp.NewTool(
-		"capi_scale_cluster",
-		mcp.WithDescription("Scale control plane or worker nodes of a CAPI cluster"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the cluster"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the cluster"),
-		),
-		mcp.WithString("target",
-			mcp.Required(),
-			mcp.Description("What to scale: 'controlplane' or 'workers'"),
-		),
-		mcp.WithNumber("replicas",
-			mcp.Required(),
-			mcp.Description("Number of replicas to scale to"),
-		),
-		mcp.WithString("machineDeployment",
-			mcp.Description("Name of the machine deployment (required when target is 'workers')"),
-		),
-	)
+	// Confine tool calls to the allowed namespaces
+	allowedNamespaces, err := loadAllowedNamespaces()
+	if err != nil {
+		fatal("Failed to configure allowed namespaces", err)
+	}
+	if len(allowedNamespaces) > 0 {
+		slog.Info("Tool calls confined to namespaces", "namespaces", allowedNamespaces)
+	}
+	defaultKubernetesVersion, err := loadDefaultKubernetesVersion()
+	if err != nil {
+		fatal("Failed to configure the default Kubernetes version", err)
+	}
 
-	mcpServer.AddTool(scaleClusterTool, createScaleClusterHandler(serverCtx))
-
-	// Add CAPI list machines tool
-	listMachinesTool := mcp.NewTool(
-		"capi_list_machines",
-		mcp.WithDescription("List CAPI machines with optional filtering by cluster"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace to list machines from"),
-		),
-		mcp.WithString("clusterName",
-			mcp.Description("Filter machines by cluster name (optional)"),
-		),
-	)
+	// Initialize audit log of mutating operations
+	auditLog, err := loadAuditLogger(capiClient)
+	if err != nil {
+		fatal("Failed to configure audit log", err)
+	}
 
-	mcpServer.AddTool(listMachinesTool, createListMachinesHandler(serverCtx))
-
-	// Add CAPI list machine deployments tool
-	listMachineDeploymentsTool := mcp.NewTool(
-		"capi_list_machinedeployments",
-		mcp.WithDescription("List CAPI machine deployments (worker node pools)"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace to list machine deployments from"),
-		),
-		mcp.WithString("clusterName",
-			mcp.Description("Filter machine deployments by cluster name (optional)"),
-		),
-	)
+	// Pool clients of additional management clusters selected per request
+	managementClusters, err := loadManagementClusters()
+	if err != nil {
+		fatal("Failed to configure management clusters", err)
+	}
+	clients, err := capi.NewClientPool(capiClient, managementClusters, clientOptions...)
+	if err != nil {
+		fatal("Failed to configure management clusters", err)
+	}
+	if len(managementClusters) > 0 {
+		slog.Info("Additional management clusters available per request", "count", len(managementClusters))
+	}
 
-	mcpServer.AddTool(listMachineDeploymentsTool, createListMachineDeploymentsHandler(serverCtx))
-
-	// Add CAPI create machine deployment tool
-	createMachineDeploymentTool := mcp.NewTool(
-		"capi_create_machinedeployment",
-		mcp.WithDescription("Create a new worker node pool (MachineDeployment)"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace for the machine deployment"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the machine deployment"),
-		),
-		mcp.WithString("cluster_name",
-			mcp.Required(),
-			mcp.Description("Name of the cluster this deployment belongs to"),
-		),
-		mcp.WithNumber("replicas",
-			mcp.Description("Number of replicas (default: 1)"),
-		),
-		mcp.WithString("version",
-			mcp.Description("Kubernetes version (e.g., v1.29.0)"),
-		),
-		mcp.WithString("infra_kind",
-			mcp.Required(),
-			mcp.Description("Kind of infrastructure template (e.g., AWSMachineTemplate)"),
-		),
-		mcp.WithString("infra_name",
-			mcp.Required(),
-			mcp.Description("Name of infrastructure template"),
-		),
-		mcp.WithString("infra_api_version",
-			mcp.Description("API version of infrastructure template"),
-		),
-		mcp.WithString("bootstrap_kind",
-			mcp.Required(),
-			mcp.Description("Kind of bootstrap config (e.g., KubeadmConfigTemplate)"),
-		),
-		mcp.WithString("bootstrap_name",
-			mcp.Required(),
-			mcp.Description("Name of bootstrap config template"),
-		),
-		mcp.WithString("bootstrap_api_version",
-			mcp.Description("API version of bootstrap config"),
-		),
-	)
+	// Only list the tools of the infrastructure providers installed on the
+	// management clusters
+	discoveryEnabled, err := providerDiscoveryEnabled()
+	if err != nil {
+		fatal("Failed to configure provider discovery", err)
+	}
+	var discovery *tools.ProviderDiscovery
+	refreshDiscovery := func() {}
+	if discoveryEnabled {
+		discovery = tools.NewProviderDiscovery(clients)
+		refreshDiscovery = func() {
+			if err := discovery.Refresh(ctx); err != nil {
+				slog.Warn("Provider discovery incomplete, listing all provider tools", "error", err)
+				return
+			}
+			providers, _ := discovery.Providers()
+			slog.Info("Discovered installed infrastructure providers", "providers", providers)
+		}
+		refreshDiscovery()
+	}
 
-	mcpServer.AddTool(createMachineDeploymentTool, createCreateMachineDeploymentHandler(serverCtx))
-
-	// Add CAPI scale machine deployment tool
-	scaleMachineDeploymentTool := mcp.NewTool(
-		"capi_scale_machinedeployment",
-		mcp.WithDescription("Scale worker nodes up or down"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the machine deployment"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the machine deployment"),
-		),
-		mcp.WithNumber("replicas",
-			mcp.Required(),
-			mcp.Description("Number of replicas to scale to"),
-		),
-	)
+	// Pick up rotated credentials and new contexts without a restart
+	reload, err := kubeconfigReloadEnabled()
+	if err != nil {
+		fatal("Failed to configure kubeconfig reload", err)
+	}
+	if reload {
+		if err := watchKubeconfig(ctx, clients, clientOptions, refreshDiscovery); err != nil {
+			slog.Warn("Not reloading kubeconfig changes", "error", err)
+		}
+	}
 
-	mcpServer.AddTool(scaleMachineDeploymentTool, createScaleMachineDeploymentHandler(serverCtx))
-
-	// Add CAPI get kubeconfig tool
-	getKubeconfigTool := mcp.NewTool(
-		"capi_get_kubeconfig",
-		mcp.WithDescription("Retrieve kubeconfig for a workload cluster"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the cluster"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the cluster"),
-		),
-	)
+	// Run long operations in the background when tools are called with async
+	jobManager, err := loadJobManager()
+	if err != nil {
+		fatal("Failed to configure background jobs", err)
+	}
 
-	mcpServer.AddTool(getKubeconfigTool, createGetKubeconfigHandler(serverCtx))
-
-	// Add CAPI pause cluster tool
-	pauseClusterTool := mcp.NewTool(
-		"capi_pause_cluster",
-		mcp.WithDescription("Pause cluster reconciliation (stops all CAPI controllers from reconciling the cluster)"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the cluster"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the cluster"),
-		),
-	)
+	// Load canary upgrades so interrupted ones can be resumed
+	canaries, err := loadCanaryStore()
+	if err != nil {
+		fatal("Failed to configure canary upgrades", err)
+	}
+	for _, canary := range canaries.List() {
+		if !canary.Finished() {
+			slog.Info("Canary upgrade stopped, resume it with capi_canary_resume", "canary", canary.ID, "stage", canary.Stage)
+		}
+	}
 
-	mcpServer.AddTool(pauseClusterTool, createPauseClusterHandler(serverCtx))
-
-	// Add CAPI resume cluster tool
-	resumeClusterTool := mcp.NewTool(
-		"capi_resume_cluster",
-		mcp.WithDescription("Resume cluster reconciliation (allows CAPI controllers to reconcile the cluster again)"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the cluster"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the cluster"),
-		),
-	)
+	// Restrict cluster changes to their maintenance windows
+	windows, err := loadMaintenanceWindows()
+	if err != nil {
+		fatal("Failed to configure maintenance windows", err)
+	}
 
-	mcpServer.AddTool(resumeClusterTool, createResumeClusterHandler(serverCtx))
-
-	// Add CAPI get machine tool
-	getMachineTool := mcp.NewTool(
-		"capi_get_machine",
-		mcp.WithDescription("Get detailed information about a specific CAPI machine"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the machine"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the machine"),
-		),
-	)
+	// Keep tools from changing objects Flux or Argo CD apply from Git
+	gitOpsGuard, err := loadGitOpsGuardMode()
+	if err != nil {
+		fatal("Failed to configure the GitOps guard", err)
+	}
 
-	mcpServer.AddTool(getMachineTool, createGetMachineHandler(serverCtx))
-
-	// Add CAPI delete machine tool
-	deleteMachineTool := mcp.NewTool(
-		"capi_delete_machine",
-		mcp.WithDescription("Delete a specific CAPI machine"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the machine"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the machine to delete"),
-		),
-		mcp.WithBoolean("force",
-			mcp.Description("Force deletion even if machine is healthy or control plane"),
-		),
-	)
+	rateLimiter, err := loadRateLimiter()
+	if err != nil {
+		fatal("Failed to configure tool rate limits", err)
+	}
 
-	mcpServer.AddTool(deleteMachineTool, createDeleteMachineHandler(serverCtx))
-
-	// Add CAPI remediate machine tool
-	remediateMachineTool := mcp.NewTool(
-		"capi_remediate_machine",
-		mcp.WithDescription("Trigger machine health check remediation"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the machine"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the machine to remediate"),
-		),
-	)
+	clusterLocks, err := loadLockManager()
+	if err != nil {
+		fatal("Failed to configure cluster locks", err)
+	}
 
-	mcpServer.AddTool(remediateMachineTool, createRemediateMachineHandler(serverCtx))
-
-	// Add CAPI delete cluster tool
-	deleteClusterTool := mcp.NewTool(
-		"capi_delete_cluster",
-		mcp.WithDescription("Delete a CAPI cluster safely (with confirmation)"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace of the cluster"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Name of the cluster"),
-		),
-		mcp.WithBoolean("force",
-			mcp.Description("Skip safety checks and force deletion (use with caution)"),
-		),
-	)
+	// Push cluster backups to S3 buckets or OCI registries
+	backupTargets, err := loadBackupTargets()
+	if err != nil {
+		fatal("Failed to configure backup targets", err)
+	}
 
-	mcpServer.AddTool(deleteClusterTool, createDeleteClusterHandler(serverCtx))
-
-	// Add CAPI update machine deployment tool
-	updateMachineDeploymentTool := mcp.NewTool(
-		"capi_update_machinedeployment",
-		mcp.WithDescription("Update MachineDeployment configuration"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("MachineDeployment namespace"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("MachineDeployment name"),
-		),
-		mcp.WithString("version",
-			mcp.Description("Kubernetes version to update to"),
-		),
-		mcp.WithNumber("replicas",
-			mcp.Description("Number of replicas"),
-		),
-		mcp.WithNumber("min_ready_seconds",
-			mcp.Description("Minimum ready seconds before considering a machine available"),
-		),
-		mcp.WithObject("labels",
-			mcp.Description("Labels to add/update (empty value removes label)"),
-		),
-		mcp.WithObject("annotations",
-			mcp.Description("Annotations to add/update (empty value removes annotation)"),
-		),
-	)
+	// Run scheduled operations stored in the management cluster
+	scheduler, err := loadScheduler(capiClient, jobManager, windows, clusterLocks, backupTargets)
+	if err != nil {
+		fatal("Failed to configure scheduled operations", err)
+	}
+	var schedules *schedule.Store
+	if scheduler != nil {
+		schedules = scheduler.Store()
+		go scheduler.Run(ctx)
+		slog.Info("Scheduled operations enabled", "namespace", schedules.Namespace())
+	}
 
-	mcpServer.AddTool(updateMachineDeploymentTool, createUpdateMachineDeploymentHandler(serverCtx))
-
-	// Add CAPI rollout machine deployment tool
-	rolloutMachineDeploymentTool := mcp.NewTool(
-		"capi_rollout_machinedeployment",
-		mcp.WithDescription("Trigger rolling update of MachineDeployment"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("MachineDeployment namespace"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("MachineDeployment name"),
-		),
-		mcp.WithString("reason",
-			mcp.Description("Reason for the rollout"),
-		),
-	)
+	// Notify clients about resources stuck on their way to their desired state
+	staleScan, err := loadStaleScan()
+	if err != nil {
+		fatal("Failed to configure the stale resource scan", err)
+	}
 
-	mcpServer.AddTool(rolloutMachineDeploymentTool, createRolloutMachineDeploymentHandler(serverCtx))
-
-	// Add CAPI list machine sets tool
-	listMachineSetsTool := mcp.NewTool(
-		"capi_list_machinesets",
-		mcp.WithDescription("List CAPI MachineSets"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace to list machine sets in"),
-		),
-		mcp.WithString("clusterName",
-			mcp.Description("Filter by cluster name"),
-		),
-	)
+	// Alert about clusters becoming unhealthy
+	healthMonitor, err := loadHealthMonitor()
+	if err != nil {
+		fatal("Failed to configure the health monitor", err)
+	}
 
-	mcpServer.AddTool(listMachineSetsTool, createListMachineSetsHandler(serverCtx))
-
-	// Add CAPI get machine set tool
-	getMachineSetTool := mcp.NewTool(
-		"capi_get_machineset",
-		mcp.WithDescription("Get detailed MachineSet information"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("MachineSet namespace"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("MachineSet name"),
-		),
-	)
+	// Create server context
+	serverCtx := &tools.ServerContext{
+		Clients:                  clients,
+		Approvals:                approvals,
+		ToolPolicy:               toolPolicy,
+		AuditLog:                 auditLog,
+		Jobs:                     jobManager,
+		Canaries:                 canaries,
+		Schedules:                schedules,
+		Providers:                loadProviderRepository(),
+		Discovery:                discovery,
+		BackupTargets:            backupTargets,
+		KubeconfigDir:            os.Getenv("MCP_KUBECONFIG_DIR"),
+		Remote:                   transport.Transport != transportStdio,
+		DefaultKubernetesVersion: defaultKubernetesVersion,
+		ServerVersion:            serverVersion,
+	}
 
-	mcpServer.AddTool(getMachineSetTool, createGetMachineSetHandler(serverCtx))
-
-	// Add CAPI drain node tool
-	drainNodeTool := mcp.NewTool(
-		"capi_drain_node",
-		mcp.WithDescription("Safely drain a Kubernetes node"),
-		mcp.WithString("namespace",
-			mcp.Description("Machine namespace (required if using machine_name)"),
-		),
-		mcp.WithString("machine_name",
-			mcp.Description("Machine name to get node from"),
-		),
-		mcp.WithString("node_name",
-			mcp.Description("Node name to drain directly"),
-		),
-		mcp.WithBoolean("ignore_daemonsets",
-			mcp.Description("Ignore DaemonSet-managed pods"),
-		),
-		mcp.WithBoolean("delete_local_data",
-			mcp.Description("Delete pods with local storage"),
-		),
-		mcp.WithBoolean("force",
-			mcp.Description("Force deletion of pods"),
-		),
-		mcp.WithNumber("grace_period_seconds",
-			mcp.Description("Grace period for pod termination"),
-		),
-	)
+	// Drop the resource subscriptions of closed sessions
+	hooks := &server.Hooks{}
+	var subscriptions *resources.Subscriptions
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		subscriptions.RemoveSession(session.SessionID())
+	})
 
-	mcpServer.AddTool(drainNodeTool, createDrainNodeHandler(serverCtx))
-
-	// Add CAPI cordon node tool
-	cordonNodeTool := mcp.NewTool(
-		"capi_cordon_node",
-		mcp.WithDescription("Cordon or uncordon a Kubernetes node"),
-		mcp.WithString("namespace",
-			mcp.Description("Machine namespace (required if using machine_name)"),
-		),
-		mcp.WithString("machine_name",
-			mcp.Description("Machine name to get node from"),
-		),
-		mcp.WithString("node_name",
-			mcp.Description("Node name to cordon/uncordon directly"),
-		),
-		mcp.WithBoolean("uncordon",
-			mcp.Description("Set to true to uncordon (make schedulable)"),
-		),
-	)
+	// Track tool calls so shutdown can wait for them
+	calls := tools.NewCallTracker()
 
-	mcpServer.AddTool(cordonNodeTool, createCordonNodeHandler(serverCtx))
-
-	// Add CAPI node status tool
-	nodeStatusTool := mcp.NewTool(
-		"capi_node_status",
-		mcp.WithDescription("Get node status from workload cluster"),
-		mcp.WithString("namespace",
-			mcp.Description("Machine namespace (required if using machine_name)"),
-		),
-		mcp.WithString("machine_name",
-			mcp.Description("Machine name to get node from"),
-		),
-		mcp.WithString("node_name",
-			mcp.Description("Node name to get status for directly"),
-		),
-	)
+	// Abort tool calls the client cancels
+	cancellations := tools.NewCancellations()
+	hooks.AddBeforeCallTool(cancellations.BeforeCallTool)
 
-	mcpServer.AddTool(nodeStatusTool, createNodeStatusHandler(serverCtx))
+	// Create MCP server
+	mcpServer := server.NewMCPServer(
+		serverName,
+		serverVersion,
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(true, true), // subscribe, list
+		server.WithPromptCapabilities(true),
+		server.WithLogging(),
+		server.WithHooks(hooks),
+		server.WithCompletions(),
+		server.WithResourceCompletionProvider(resources.NewCompletions(clients)),
+		server.WithToolFilter(tools.NewToolPolicyFilter(toolPolicy)),
+		// Outermost, so every tool call is logged with its request fields
+		server.WithToolHandlerMiddleware(tools.NewLoggingMiddleware()),
+		server.WithToolHandlerMiddleware(tools.NewShutdownMiddleware(calls)),
+		server.WithToolHandlerMiddleware(tools.NewCancellationMiddleware(cancellations)),
+		server.WithToolHandlerMiddleware(tools.NewToolPolicyMiddleware(toolPolicy)),
+		server.WithToolHandlerMiddleware(tools.NewRateLimitMiddleware(rateLimiter)),
+		server.WithToolHandlerMiddleware(tools.NewOrganizationMiddleware()),
+		server.WithToolFilter(tools.NewAuthFilter()),
+		server.WithToolFilter(tools.NewProviderToolFilter(discovery)),
+		server.WithToolHandlerMiddleware(tools.NewAuditMiddleware(auditLog)),
+		server.WithToolHandlerMiddleware(tools.NewAuthMiddleware()),
+		server.WithToolHandlerMiddleware(tools.NewNamespaceMiddleware(allowedNamespaces)),
+		server.WithToolHandlerMiddleware(tools.NewMaintenanceWindowMiddleware(clients, windows)),
+		server.WithToolHandlerMiddleware(tools.NewApprovalMiddleware(approvals)),
+		server.WithToolHandlerMiddleware(tools.NewManagementClusterMiddleware(clients)),
+		server.WithToolHandlerMiddleware(tools.NewGitOpsGuardMiddleware(gitOpsGuard)),
+		// After approvals, so calls waiting for approval hold no lock
+		server.WithToolHandlerMiddleware(tools.NewClusterLockMiddleware(clients, clusterLocks)),
+		server.WithToolHandlerMiddleware(tools.NewPreviewMiddleware()),
+		// Innermost, so audit entries are redacted as well
+		server.WithToolHandlerMiddleware(tools.NewRedactionMiddleware()),
+	)
+
+	mcpServer.AddNotificationHandler(tools.MethodNotificationCancelled, cancellations.HandleCancelled)
+
+	// Tell clients to list the tools again when providers come and go
+	if discovery != nil {
+		discovery.OnChange(func() {
+			mcpServer.SendNotificationToAllClients(mcp.MethodNotificationToolsListChanged, nil)
+		})
+	}
 
-	// Infrastructure Provider Tools
+	// Register all tools, grouped by domain
+	tools.RegisterAll(mcpServer, serverCtx)
 
-	// Generic infrastructure provider tools
-	listInfraProvidersTool := mcp.NewTool(
-		"capi_list_infrastructure_providers",
-		mcp.WithDescription("List available infrastructure providers"),
-	)
-	mcpServer.AddTool(listInfraProvidersTool, createListInfrastructureProvidersHandler(serverCtx))
-
-	getProviderConfigTool := mcp.NewTool(
-		"capi_get_provider_config",
-		mcp.WithDescription("Get provider configuration requirements"),
-		mcp.WithString("provider",
-			mcp.Required(),
-			mcp.Description("Provider name (aws, azure, gcp, vsphere)"),
-		),
-	)
-	mcpServer.AddTool(getProviderConfigTool, createGetProviderConfigHandler(serverCtx))
-
-	// AWS infrastructure tools
-	awsListClustersTool := mcp.NewTool(
-		"capi_aws_list_clusters",
-		mcp.WithDescription("List AWS clusters"),
-		mcp.WithString("namespace",
-			mcp.Description("Namespace to filter clusters (optional)"),
-		),
-	)
-	mcpServer.AddTool(awsListClustersTool, createAWSListClustersHandler(serverCtx))
-
-	awsGetClusterTool := mcp.NewTool(
-		"capi_aws_get_cluster",
-		mcp.WithDescription("Get AWS cluster details"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Cluster namespace"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Cluster name"),
-		),
-	)
-	mcpServer.AddTool(awsGetClusterTool, createAWSGetClusterHandler(serverCtx))
-
-	awsCreateClusterTool := mcp.NewTool(
-		"capi_aws_create_cluster",
-		mcp.WithDescription("Create AWS cluster with specific configuration (placeholder)"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Cluster namespace"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Cluster name"),
-		),
-		mcp.WithString("region",
-			mcp.Required(),
-			mcp.Description("AWS region"),
-		),
-		mcp.WithString("vpc_cidr",
-			mcp.Description("VPC CIDR block"),
-		),
-	)
-	mcpServer.AddTool(awsCreateClusterTool, createAWSCreateClusterHandler(serverCtx))
-
-	awsUpdateVPCTool := mcp.NewTool(
-		"capi_aws_update_vpc",
-		mcp.WithDescription("Update VPC configuration (placeholder)"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Cluster namespace"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Cluster name"),
-		),
-		mcp.WithString("operation",
-			mcp.Required(),
-			mcp.Description("Operation to perform"),
-		),
-	)
-	mcpServer.AddTool(awsUpdateVPCTool, createAWSUpdateVPCHandler(serverCtx))
-
-	awsManageSecurityGroupsTool := mcp.NewTool(
-		"capi_aws_manage_security_groups",
-		mcp.WithDescription("Manage security groups (placeholder)"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Cluster namespace"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Cluster name"),
-		),
-		mcp.WithString("operation",
-			mcp.Required(),
-			mcp.Description("Operation to perform"),
-		),
-	)
-	mcpServer.AddTool(awsManageSecurityGroupsTool, createAWSManageSecurityGroupsHandler(serverCtx))
-
-	awsGetMachineTemplateTool := mcp.NewTool(
-		"capi_aws_get_machine_template",
-		mcp.WithDescription("Get/list AWS machine templates"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Namespace to search in"),
-		),
-		mcp.WithString("name",
-			mcp.Description("Template name (optional, lists all if not provided)"),
-		),
-	)
-	mcpServer.AddTool(awsGetMachineTemplateTool, createAWSGetMachineTemplateHandler(serverCtx))
-
-	// Azure infrastructure tools
-	azureListClustersTool := mcp.NewTool(
-		"capi_azure_list_clusters",
-		mcp.WithDescription("List Azure clusters"),
-		mcp.WithString("namespace",
-			mcp.Description("Namespace to filter clusters (optional)"),
-		),
-	)
-	mcpServer.AddTool(azureListClustersTool, createAzureListClustersHandler(serverCtx))
-
-	azureGetClusterTool := mcp.NewTool(
-		"capi_azure_get_cluster",
-		mcp.WithDescription("Get Azure cluster details"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Cluster namespace"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Cluster name"),
-		),
-	)
-	mcpServer.AddTool(azureGetClusterTool, createAzureGetClusterHandler(serverCtx))
-
-	azureManageResourceGroupTool := mcp.NewTool(
-		"capi_azure_manage_resource_group",
-		mcp.WithDescription("Manage resource groups (placeholder)"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Cluster namespace"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Cluster name"),
-		),
-		mcp.WithString("operation",
-			mcp.Required(),
-			mcp.Description("Operation to perform"),
-		),
-	)
-	mcpServer.AddTool(azureManageResourceGroupTool, createAzureManageResourceGroupHandler(serverCtx))
-
-	azureNetworkConfigTool := mcp.NewTool(
-		"capi_azure_network_config",
-		mcp.WithDescription("Configure Azure networking (placeholder)"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Cluster namespace"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Cluster name"),
-		),
-		mcp.WithString("operation",
-			mcp.Required(),
-			mcp.Description("Operation to perform"),
-		),
-	)
-	mcpServer.AddTool(azureNetworkConfigTool, createAzureNetworkConfigHandler(serverCtx))
-
-	// GCP infrastructure tools
-	gcpListClustersTool := mcp.NewTool(
-		"capi_gcp_list_clusters",
-		mcp.WithDescription("List GCP clusters"),
-		mcp.WithString("namespace",
-			mcp.Description("Namespace to filter clusters (optional)"),
-		),
-	)
-	mcpServer.AddTool(gcpListClustersTool, createGCPListClustersHandler(serverCtx))
-
-	gcpGetClusterTool := mcp.NewTool(
-		"capi_gcp_get_cluster",
-		mcp.WithDescription("Get GCP cluster details"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Cluster namespace"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Cluster name"),
-		),
-	)
-	mcpServer.AddTool(gcpGetClusterTool, createGCPGetClusterHandler(serverCtx))
-
-	gcpManageNetworkTool := mcp.NewTool(
-		"capi_gcp_manage_network",
-		mcp.WithDescription("Manage GCP networks (placeholder)"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Cluster namespace"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Cluster name"),
-		),
-		mcp.WithString("operation",
-			mcp.Required(),
-			mcp.Description("Operation to perform"),
-		),
-	)
-	mcpServer.AddTool(gcpManageNetworkTool, createGCPManageNetworkHandler(serverCtx))
-
-	// vSphere infrastructure tools
-	vsphereListClustersTool := mcp.NewTool(
-		"capi_vsphere_list_clusters",
-		mcp.WithDescription("List vSphere clusters"),
-		mcp.WithString("namespace",
-			mcp.Description("Namespace to filter clusters (optional)"),
-		),
-	)
-	mcpServer.AddTool(vsphereListClustersTool, createVSphereListClustersHandler(serverCtx))
-
-	vsphereGetClusterTool := mcp.NewTool(
-		"capi_vsphere_get_cluster",
-		mcp.WithDescription("Get vSphere cluster details"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Cluster namespace"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Cluster name"),
-		),
-	)
-	mcpServer.AddTool(vsphereGetClusterTool, createVSphereGetClusterHandler(serverCtx))
-
-	vsphereManageVMsTool := mcp.NewTool(
-		"capi_vsphere_manage_vms",
-		mcp.WithDescription("Manage vSphere VMs (placeholder)"),
-		mcp.WithString("namespace",
-			mcp.Required(),
-			mcp.Description("Cluster namespace"),
-		),
-		mcp.WithString("name",
-			mcp.Required(),
-			mcp.Description("Cluster name"),
-		),
-		mcp.WithString("operation",
-			mcp.Required(),
-			mcp.Description("Operation to perform"),
-		),
-	)
-	mcpServer.AddTool(vsphereManageVMsTool, createVSphereManageVMsHandler(serverCtx))
+	// Expose clusters, machines and machine deployments as resources
+	resources.Register(mcpServer, clients)
 
-	// Add a simple test resource
-	testResource := mcp.NewResource(
-		"capi://test",
-		"Test Resource",
-		mcp.WithMIMEType("text/plain"),
-	)
+	// Notify subscribed clients when the watched resources change
+	subscriptions = resources.NewSubscriptions(ctx, clients, mcpServer, resources.DefaultNotifyDelay)
 
-	mcpServer.AddResource(testResource, testResourceHandler)
+	// Tell clients about resources that get stuck, without them asking
+	if staleScan.Interval > 0 {
+		go monitor.NewStaleScanner(capiClient, mcpServer, staleScan.Interval, staleScan.Thresholds).Run(ctx)
+		slog.Info("Scanning for stale resources", "interval", staleScan.Interval)
+	}
 
-	// Start server based on transport type
-	transport := os.Getenv("MCP_TRANSPORT")
-	if transport == "" {
-		transport = "stdio"
+	// Push alerts when clusters become unhealthy or recover
+	if sinks := healthMonitor.sinks(mcpServer); healthMonitor.Interval > 0 && len(sinks) > 0 {
+		go monitor.NewHealthMonitor(capiClient, sinks, healthMonitor.Interval).Run(ctx)
+		slog.Info("Checking cluster health", "interval", healthMonitor.Interval, "sinks", len(sinks))
 	}
 
-	// Set up signal handling for graceful shutdown
+	// Drain tool calls and jobs on the first signal, exit on the second
 	go func() {
-		<-ctx.Done()
-		log.Println("Context cancelled, shutting down...")
-		os.Exit(0)
+		<-sigChan
+		slog.Info("Shutdown signal received, waiting for tool calls and jobs in flight", "timeout", transport.ShutdownTimeout)
+		go func() {
+			<-sigChan
+			slog.Warn("Second shutdown signal received, exiting immediately")
+			os.Exit(1)
+		}()
+		shutdownGracefully(calls, jobManager, transport.ShutdownTimeout, cancel)
 	}()
 
-	switch transport {
-	case "stdio":
-		log.Println("Starting MCP CAPI server with stdio transport...")
-		if err := server.ServeStdio(mcpServer); err != nil {
-			log.Fatalf("Server error: %v", err)
+	switch transport.Transport {
+	case transportStdio:
+		slog.Info("Starting MCP CAPI server", "transport", transportStdio)
+		stdin := subscriptions.InterceptReader(stdioSessionID, os.Stdin)
+		if err := server.NewStdioServer(mcpServer).Listen(ctx, stdin, os.Stdout); err != nil && !errors.Is(err, context.Canceled) {
+			fatal("Server error", err)
 		}
 	default:
-		log.Fatalf("Unsupported transport: %s", transport)
+		scheme := "http"
+		if transport.tlsEnabled() {
+			scheme = "https"
+		}
+		slog.Info("Starting MCP CAPI server", "transport", transport.Transport, "address", transport.ListenAddr, "scheme", scheme)
+		if err := serveHTTP(ctx, mcpServer, transport, subscriptions); err != nil {
+			fatal("Server error", err)
+		}
 	}
+
+	// The stdio client went away without a signal, still let jobs finish
+	if ctx.Err() == nil {
+		shutdownGracefully(calls, jobManager, transport.ShutdownTimeout, cancel)
+	}
+	if err := auditLog.Close(); err != nil {
+		slog.Warn("Failed to flush the audit log", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+
+// fatal logs err and exits
+func fatal(message string, err error) {
+	slog.Error(message, "error", err)
+	os.Exit(1)
 }
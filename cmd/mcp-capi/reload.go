@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+)
+
+// kubeconfigReloadDelay collects the events of a file being rewritten into a
+// single reload
+const kubeconfigReloadDelay = time.Second
+
+// providerDiscoveryEnabled reads MCP_PROVIDER_DISCOVERY, which defaults to true
+func providerDiscoveryEnabled() (bool, error) {
+	value := os.Getenv("MCP_PROVIDER_DISCOVERY")
+	if value == "" {
+		return true, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid MCP_PROVIDER_DISCOVERY %q (must be a boolean)", value)
+	}
+	return enabled, nil
+}
+
+// kubeconfigReloadEnabled reads MCP_KUBECONFIG_RELOAD, which defaults to true
+func kubeconfigReloadEnabled() (bool, error) {
+	value := os.Getenv("MCP_KUBECONFIG_RELOAD")
+	if value == "" {
+		return true, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid MCP_KUBECONFIG_RELOAD %q (must be a boolean)", value)
+	}
+	return enabled, nil
+}
+
+// newDefaultClient creates the client of the kubeconfig loaded at startup
+func newDefaultClient(opts []capi.Option) (*capi.Client, error) {
+	c, err := capi.NewClientWithOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.InitializeProviders(); err != nil {
+		slog.Warn("Failed to initialize providers", "error", err)
+	}
+	return c, nil
+}
+
+// watchKubeconfig rebuilds the clients of the pool whenever one of its
+// kubeconfig files changes, until ctx is canceled. The directories of the
+// files are watched rather than the files themselves, so files replaced by
+// a rename, such as mounted secrets, are picked up as well. onReload, if
+// set, is called after each reload.
+func watchKubeconfig(ctx context.Context, clients *capi.ClientPool, opts []capi.Option, onReload func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch kubeconfig: %w", err)
+	}
+
+	files := clients.KubeconfigFiles()
+	var dirs []string
+	for _, file := range files {
+		dir := filepath.Dir(file)
+		if slices.Contains(dirs, dir) {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			// Kubeconfig files that do not exist yet are not watched
+			slog.Warn("Not watching directory for kubeconfig changes", "directory", dir, "error", err)
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		var reload <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if affectsKubeconfig(event.Name, files) {
+					reload = time.After(kubeconfigReloadDelay)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Warn("Kubeconfig watch error", "error", err)
+			case <-reload:
+				reload = nil
+				reloadClients(clients, opts)
+				if onReload != nil {
+					onReload()
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// affectsKubeconfig reports whether a changed path is one of the kubeconfig
+// files or, for mounted secrets, one of the symlinks they resolve through
+func affectsKubeconfig(path string, files []string) bool {
+	for _, file := range files {
+		if path == file {
+			return true
+		}
+		if filepath.Dir(path) == filepath.Dir(file) && filepath.Base(path) == "..data" {
+			return true
+		}
+	}
+	return false
+}
+
+// reloadClients rebuilds the clients of the pool from the changed files
+func reloadClients(clients *capi.ClientPool, opts []capi.Option) {
+	startupClient, err := newDefaultClient(opts)
+	if err != nil {
+		slog.Warn("Failed to reload kubeconfig, keeping the previous configuration", "error", err)
+		startupClient = nil
+	}
+	if err := clients.Reload(startupClient); err != nil {
+		slog.Warn("Failed to reload management clusters", "error", err)
+	}
+	slog.Info("Reloaded kubeconfig")
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/jobs"
+	"github.com/giantswarm/mcp-capi/internal/tools"
+)
+
+// shutdownGracefully stops accepting tool calls and jobs and waits up to
+// timeout for those in flight. It then cancels the serving context, which
+// stops the transport, the watches of resource subscriptions and the
+// background loops. Jobs still running at the deadline are canceled.
+func shutdownGracefully(calls *tools.CallTracker, jobManager *jobs.Manager, timeout time.Duration, cancel context.CancelFunc) {
+	defer cancel()
+	ctx, stop := context.WithTimeout(context.Background(), timeout)
+	defer stop()
+
+	if err := calls.Drain(ctx); err != nil {
+		slog.Warn("Shutting down with tool calls in flight", "error", err)
+	}
+	for _, job := range jobManager.Shutdown(ctx) {
+		slog.Warn("Background job canceled by shutdown", "job", job.ID, "operation", job.Operation, "target", job.Target)
+	}
+}
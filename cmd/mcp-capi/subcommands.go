@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/giantswarm/mcp-capi/internal/rbac"
+	"github.com/giantswarm/mcp-capi/internal/tools"
+)
+
+// runRBACManifestCommand implements the "rbac-manifest" subcommand
+func runRBACManifestCommand(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("rbac-manifest", flag.ContinueOnError)
+	readOnly := flags.Bool("read-only", false, "only include permissions for read-only tools")
+	namespace := flags.String("namespace", "", "generate a Role in this namespace instead of a ClusterRole")
+	name := flags.String("name", rbac.DefaultName, "name of the generated roles")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	policy, err := loadToolPolicy()
+	if err != nil {
+		return fmt.Errorf("failed to load tool policy: %w", err)
+	}
+
+	manifest, err := rbac.Manifest(tools.RequiredPermissions(policy, *readOnly), rbac.Options{
+		Name:      *name,
+		Namespace: *namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(manifest)
+	return err
+}
+
+// runSubcommand runs a CLI subcommand if one was given and reports whether it did
+func runSubcommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "rbac-manifest":
+		if err := runRBACManifestCommand(args[1:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "rbac-manifest: %v\n", err)
+			os.Exit(1)
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -1,27 +0,0 @@
-package main
-
-import (
-	"context"
-	"fmt"
-
-	"github.com/mark3labs/mcp-go/mcp"
-)
-
-// testToolHandler handles the test tool
-func testToolHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	arguments := request.GetArguments()
-	message, ok := arguments["message"].(string)
-	if !ok {
-		return nil, fmt.Errorf("message argument is required and must be a string")
-	}
-
-	response := fmt.Sprintf("Echo from CAPI MCP Server: %s", message)
-	return &mcp.CallToolResult{
-		Content: []mcp.Content{
-			mcp.TextContent{
-				Type: "text",
-				Text: response,
-			},
-		},
-	}, nil
-}
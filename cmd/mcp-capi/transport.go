@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/auth"
+	"github.com/giantswarm/mcp-capi/internal/resources"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	transportStdio          = "stdio"
+	transportSSE            = "sse"
+	transportStreamableHTTP = "streamable-http"
+
+	defaultListenAddr      = ":8080"
+	defaultShutdownTimeout = 30 * time.Second
+
+	// streamableHTTPPath is the endpoint of the streamable HTTP transport
+	streamableHTTPPath = "/mcp"
+	// healthPath answers liveness and readiness probes of HTTP transports
+	healthPath = "/healthz"
+	// stdioSessionID is the ID of the single session of the stdio transport
+	stdioSessionID = "stdio"
+)
+
+// transportConfig describes how MCP clients connect to the server
+type transportConfig struct {
+	Transport       string
+	ListenAddr      string
+	BaseURL         string
+	TLSCertFile     string
+	TLSKeyFile      string
+	ShutdownTimeout time.Duration
+
+	// Authenticator validates the bearer tokens of HTTP clients
+	Authenticator auth.Authenticator
+	// AuthDisabled explicitly allows serving HTTP without authentication
+	AuthDisabled bool
+}
+
+// loadTransportConfig reads the transport settings from MCP_TRANSPORT,
+// MCP_LISTEN_ADDR, MCP_BASE_URL, MCP_TLS_CERT_FILE, MCP_TLS_KEY_FILE,
+// MCP_SHUTDOWN_TIMEOUT, MCP_AUTH_CONFIG and MCP_AUTH_DISABLED
+func loadTransportConfig() (transportConfig, error) {
+	config := transportConfig{
+		Transport:       os.Getenv("MCP_TRANSPORT"),
+		ListenAddr:      os.Getenv("MCP_LISTEN_ADDR"),
+		BaseURL:         os.Getenv("MCP_BASE_URL"),
+		TLSCertFile:     os.Getenv("MCP_TLS_CERT_FILE"),
+		TLSKeyFile:      os.Getenv("MCP_TLS_KEY_FILE"),
+		ShutdownTimeout: defaultShutdownTimeout,
+	}
+
+	switch config.Transport {
+	case "":
+		config.Transport = transportStdio
+	case transportStdio, transportSSE, transportStreamableHTTP:
+	default:
+		return transportConfig{}, fmt.Errorf("invalid MCP_TRANSPORT %q (must be 'stdio', 'sse' or 'streamable-http')", config.Transport)
+	}
+
+	if config.ListenAddr == "" {
+		config.ListenAddr = defaultListenAddr
+	}
+
+	if (config.TLSCertFile == "") != (config.TLSKeyFile == "") {
+		return transportConfig{}, fmt.Errorf("MCP_TLS_CERT_FILE and MCP_TLS_KEY_FILE must be set together")
+	}
+
+	if timeout := os.Getenv("MCP_SHUTDOWN_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil || d <= 0 {
+			return transportConfig{}, fmt.Errorf("invalid MCP_SHUTDOWN_TIMEOUT %q (must be a positive duration)", timeout)
+		}
+		config.ShutdownTimeout = d
+	}
+
+	authenticator, err := loadAuthenticator()
+	if err != nil {
+		return transportConfig{}, fmt.Errorf("failed to configure authentication: %w", err)
+	}
+	config.Authenticator = authenticator
+
+	if disabled := os.Getenv("MCP_AUTH_DISABLED"); disabled != "" {
+		config.AuthDisabled, err = strconv.ParseBool(disabled)
+		if err != nil {
+			return transportConfig{}, fmt.Errorf("invalid MCP_AUTH_DISABLED %q (must be a boolean)", disabled)
+		}
+	}
+	if config.Transport != transportStdio && config.Authenticator == nil && !config.AuthDisabled {
+		return transportConfig{}, fmt.Errorf("%s transport requires MCP_AUTH_CONFIG (set MCP_AUTH_DISABLED=true to serve without authentication)", config.Transport)
+	}
+
+	return config, nil
+}
+
+// tlsEnabled reports whether HTTP transports serve HTTPS
+func (c transportConfig) tlsEnabled() bool {
+	return c.TLSCertFile != ""
+}
+
+// serveHTTP serves the MCP server over SSE or streamable HTTP until ctx is
+// canceled, then shuts down gracefully, closing open sessions and waiting up
+// to the shutdown timeout for in-flight requests. Resource subscription
+// requests are handled by subscriptions, if set.
+func serveHTTP(ctx context.Context, mcpServer *server.MCPServer, config transportConfig, subscriptions *resources.Subscriptions) error {
+	mux := http.NewServeMux()
+	srv := &http.Server{
+		Addr:              config.ListenAddr,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if config.tlsEnabled() {
+		srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	var shutdown func(context.Context) error
+	switch config.Transport {
+	case transportSSE:
+		opts := []server.SSEOption{server.WithHTTPServer(srv)}
+		if config.BaseURL != "" {
+			opts = append(opts, server.WithBaseURL(config.BaseURL))
+		}
+		sseServer := server.NewSSEServer(mcpServer, opts...)
+		mux.Handle(sseServer.CompleteSsePath(), sseServer)
+		mux.Handle(sseServer.CompleteMessagePath(), sseServer)
+		shutdown = sseServer.Shutdown
+	case transportStreamableHTTP:
+		httpServer := server.NewStreamableHTTPServer(mcpServer, server.WithStreamableHTTPServer(srv))
+		mux.Handle(streamableHTTPPath, httpServer)
+		shutdown = httpServer.Shutdown
+	default:
+		return fmt.Errorf("transport %q is not served over HTTP", config.Transport)
+	}
+
+	var handler http.Handler = mux
+	if subscriptions != nil {
+		handler = subscriptions.Middleware(handler)
+	}
+
+	// Health probes stay unauthenticated, MCP endpoints require a bearer token
+	root := http.NewServeMux()
+	if config.Authenticator != nil {
+		root.Handle("/", auth.Middleware(config.Authenticator, handler))
+	} else {
+		slog.Warn("Serving without authentication, any client can call every enabled tool")
+		root.Handle("/", handler)
+	}
+	root.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	srv.Handler = root
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if config.tlsEnabled() {
+			serveErr <- srv.ListenAndServeTLS(config.TLSCertFile, config.TLSKeyFile)
+		} else {
+			serveErr <- srv.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down transport", "transport", config.Transport, "timeout", config.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	if err := shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("graceful shutdown failed: %w", err)
+	}
+	return nil
+}
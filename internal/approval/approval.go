@@ -0,0 +1,352 @@
+// Package approval implements external approval gates for destructive tool calls.
+//
+// A destructive tool call creates an approval Request which is announced to an
+// external system (e.g. a Slack incoming webhook or a ticketing system) and
+// either blocks until an approver decides or is enqueued so the caller can
+// retry later with the approval ID. Approvers confirm through the HTTP
+// callback endpoint or by handing the one-time approval code to a follow-up
+// tool call, which enables four-eyes workflows for production clusters.
+package approval
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ArgumentName is the tool argument used to reference an approved request
+const ArgumentName = "approval_id"
+
+// Mode controls how a destructive call behaves while its approval is pending
+type Mode string
+
+const (
+	// ModeDisabled turns approval gates off
+	ModeDisabled Mode = ""
+	// ModeBlock waits for a decision until the configured timeout expires
+	ModeBlock Mode = "block"
+	// ModeEnqueue returns immediately and expects the caller to retry with the approval ID
+	ModeEnqueue Mode = "enqueue"
+)
+
+// Status is the lifecycle state of an approval request
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+	StatusExpired  Status = "expired"
+	StatusConsumed Status = "consumed"
+)
+
+// Request represents a single approval request for a tool invocation
+type Request struct {
+	ID        string                 `json:"id"`
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments"`
+	Requester string                 `json:"requester"`
+	Status    Status                 `json:"status"`
+	CreatedAt time.Time              `json:"createdAt"`
+	ExpiresAt time.Time              `json:"expiresAt"`
+	Approver  string                 `json:"approver,omitempty"`
+	Reason    string                 `json:"reason,omitempty"`
+	DecidedAt *time.Time             `json:"decidedAt,omitempty"`
+
+	// code is the one-time secret that must be presented to approve via a tool call.
+	// It is only delivered through the notifier and never returned to the requester.
+	code string
+	// argsKey is the canonical encoding of Arguments used to match retries
+	argsKey string
+	// done is closed once a decision has been made
+	done chan struct{}
+}
+
+// Config contains the settings for the approval manager
+type Config struct {
+	Mode Mode
+	// Timeout is how long a request stays valid (and how long ModeBlock waits)
+	Timeout time.Duration
+	// Notifier announces new requests; may be nil
+	Notifier Notifier
+	// Retention is how long requests are kept after their expiry or
+	// decision, whichever is later, unless they are still pending
+	Retention time.Duration
+}
+
+// Manager keeps track of approval requests
+type Manager struct {
+	config Config
+
+	mu       sync.Mutex
+	requests map[string]*Request
+
+	// now is overridable for tests
+	now func() time.Time
+}
+
+// NewManager creates a new approval manager
+func NewManager(config Config) *Manager {
+	if config.Timeout <= 0 {
+		config.Timeout = 15 * time.Minute
+	}
+	if config.Retention <= 0 {
+		config.Retention = 24 * time.Hour
+	}
+	return &Manager{
+		config:   config,
+		requests: make(map[string]*Request),
+		now:      time.Now,
+	}
+}
+
+// Enabled reports whether approval gates are active
+func (m *Manager) Enabled() bool {
+	return m != nil && m.config.Mode != ModeDisabled
+}
+
+// Mode returns the configured approval mode
+func (m *Manager) Mode() Mode {
+	return m.config.Mode
+}
+
+// Submit creates a new pending approval request and notifies approvers
+func (m *Manager) Submit(ctx context.Context, tool string, args map[string]interface{}, requester string) (*Request, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate approval ID: %w", err)
+	}
+	code, err := randomHex(4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate approval code: %w", err)
+	}
+	key, err := canonicalArgs(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode arguments: %w", err)
+	}
+
+	now := m.now()
+	req := &Request{
+		ID:        id,
+		Tool:      tool,
+		Arguments: args,
+		Requester: requester,
+		Status:    StatusPending,
+		CreatedAt: now,
+		ExpiresAt: now.Add(m.config.Timeout),
+		code:      code,
+		argsKey:   key,
+		done:      make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	m.pruneLocked()
+	m.requests[id] = req
+	copied := *req
+	m.mu.Unlock()
+
+	if m.config.Notifier != nil {
+		if err := m.config.Notifier.Notify(ctx, Notification{Request: copied, Code: code}); err != nil {
+			return &copied, fmt.Errorf("approval request %s created but notification failed: %w", id, err)
+		}
+	}
+
+	return &copied, nil
+}
+
+// Wait blocks until the request has been decided, the context ends or the request expires
+func (m *Manager) Wait(ctx context.Context, id string) (*Request, error) {
+	m.mu.Lock()
+	req, ok := m.requests[id]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("approval request %s not found", id)
+	}
+
+	timer := time.NewTimer(time.Until(req.ExpiresAt))
+	defer timer.Stop()
+
+	select {
+	case <-req.done:
+	case <-timer.C:
+	case <-ctx.Done():
+		return m.Get(id)
+	}
+
+	return m.Get(id)
+}
+
+// Approve marks a pending request as approved. If code is non-empty it must
+// match the one-time code delivered by the notifier.
+func (m *Manager) Approve(id, approver, code string) (*Request, error) {
+	return m.decide(id, approver, code, "", StatusApproved)
+}
+
+// Reject marks a pending request as rejected
+func (m *Manager) Reject(id, approver, code, reason string) (*Request, error) {
+	return m.decide(id, approver, code, reason, StatusRejected)
+}
+
+// ApproveWithCode approves a request via a tool call; the code is mandatory on this path
+func (m *Manager) ApproveWithCode(id, approver, code string) (*Request, error) {
+	if code == "" {
+		return nil, fmt.Errorf("approval code is required")
+	}
+	return m.Approve(id, approver, code)
+}
+
+// RejectWithCode rejects a request via a tool call; the code is mandatory on this path
+func (m *Manager) RejectWithCode(id, approver, code, reason string) (*Request, error) {
+	if code == "" {
+		return nil, fmt.Errorf("approval code is required")
+	}
+	return m.Reject(id, approver, code, reason)
+}
+
+func (m *Manager) decide(id, approver, code, reason string, status Status) (*Request, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	req, ok := m.requests[id]
+	if !ok {
+		return nil, fmt.Errorf("approval request %s not found", id)
+	}
+	m.expireLocked(req)
+	if req.Status != StatusPending {
+		return nil, fmt.Errorf("approval request %s is %s", id, req.Status)
+	}
+	if code != "" && code != req.code {
+		return nil, fmt.Errorf("invalid approval code for request %s", id)
+	}
+	if approver == "" {
+		return nil, fmt.Errorf("approver is required")
+	}
+	if approver == req.Requester {
+		return nil, fmt.Errorf("approver must differ from requester %q (four-eyes principle)", req.Requester)
+	}
+
+	now := m.now()
+	req.Status = status
+	req.Approver = approver
+	req.Reason = reason
+	req.DecidedAt = &now
+	close(req.done)
+
+	copied := *req
+	return &copied, nil
+}
+
+// Consume validates that an approved request matches the given tool call and
+// marks it as used so it cannot be replayed
+func (m *Manager) Consume(id, tool string, args map[string]interface{}) (*Request, error) {
+	key, err := canonicalArgs(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode arguments: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	req, ok := m.requests[id]
+	if !ok {
+		return nil, fmt.Errorf("approval request %s not found", id)
+	}
+	m.expireLocked(req)
+	if req.Status != StatusApproved {
+		return nil, fmt.Errorf("approval request %s is %s", id, req.Status)
+	}
+	if req.Tool != tool || req.argsKey != key {
+		return nil, fmt.Errorf("approval request %s was granted for a different operation", id)
+	}
+
+	req.Status = StatusConsumed
+	copied := *req
+	return &copied, nil
+}
+
+// Get returns a copy of the request with the given ID
+func (m *Manager) Get(id string) (*Request, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	req, ok := m.requests[id]
+	if !ok {
+		return nil, fmt.Errorf("approval request %s not found", id)
+	}
+	m.expireLocked(req)
+	copied := *req
+	return &copied, nil
+}
+
+// List returns copies of all known requests, newest first
+func (m *Manager) List() []Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneLocked()
+	result := make([]Request, 0, len(m.requests))
+	for _, req := range m.requests {
+		result = append(result, *req)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result
+}
+
+// expireLocked transitions a pending request to expired once its deadline has passed
+func (m *Manager) expireLocked(req *Request) {
+	if req.Status == StatusPending && m.now().After(req.ExpiresAt) {
+		req.Status = StatusExpired
+		close(req.done)
+	}
+}
+
+// pruneLocked forgets the requests that expired or were decided longer than
+// the retention ago
+func (m *Manager) pruneLocked() {
+	cutoff := m.now().Add(-m.config.Retention)
+	for id, req := range m.requests {
+		m.expireLocked(req)
+		if req.Status == StatusPending {
+			continue
+		}
+		end := req.ExpiresAt
+		if req.DecidedAt != nil && req.DecidedAt.After(end) {
+			end = *req.DecidedAt
+		}
+		if end.Before(cutoff) {
+			delete(m.requests, id)
+		}
+	}
+}
+
+// canonicalArgs encodes arguments deterministically, ignoring the approval_id itself
+func canonicalArgs(args map[string]interface{}) (string, error) {
+	filtered := make(map[string]interface{}, len(args))
+	for k, v := range args {
+		if k == ArgumentName {
+			continue
+		}
+		filtered[k] = v
+	}
+	// encoding/json sorts map keys, which makes the output canonical
+	data, err := json.Marshal(filtered)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,171 @@
+package approval
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingNotifier captures notifications for assertions
+type recordingNotifier struct {
+	notifications []Notification
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, n Notification) error {
+	r.notifications = append(r.notifications, n)
+	return nil
+}
+
+func TestApproveAndConsume(t *testing.T) {
+	notifier := &recordingNotifier{}
+	mgr := NewManager(Config{Mode: ModeEnqueue, Notifier: notifier})
+	args := map[string]interface{}{"namespace": "org-acme", "name": "prod"}
+
+	req, err := mgr.Submit(context.Background(), "capi_delete_cluster", args, "assistant")
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if len(notifier.notifications) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(notifier.notifications))
+	}
+	code := notifier.notifications[0].Code
+
+	if _, err := mgr.Consume(req.ID, "capi_delete_cluster", args); err == nil {
+		t.Fatal("Consume() of a pending request should fail")
+	}
+	if _, err := mgr.ApproveWithCode(req.ID, "alice", "wrong"); err == nil {
+		t.Fatal("ApproveWithCode() with a wrong code should fail")
+	}
+	if _, err := mgr.ApproveWithCode(req.ID, "assistant", code); err == nil {
+		t.Fatal("ApproveWithCode() by the requester should fail")
+	}
+	if _, err := mgr.ApproveWithCode(req.ID, "alice", code); err != nil {
+		t.Fatalf("ApproveWithCode() error = %v", err)
+	}
+
+	retryArgs := map[string]interface{}{"namespace": "org-acme", "name": "prod", ArgumentName: req.ID}
+	if _, err := mgr.Consume(req.ID, "capi_delete_cluster", map[string]interface{}{"namespace": "org-acme", "name": "other"}); err == nil {
+		t.Fatal("Consume() with different arguments should fail")
+	}
+	if _, err := mgr.Consume(req.ID, "capi_delete_cluster", retryArgs); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if _, err := mgr.Consume(req.ID, "capi_delete_cluster", retryArgs); err == nil {
+		t.Fatal("Consume() must not allow replays")
+	}
+}
+
+func TestWaitReturnsDecision(t *testing.T) {
+	mgr := NewManager(Config{Mode: ModeBlock, Timeout: time.Hour})
+	req, err := mgr.Submit(context.Background(), "capi_drain_node", nil, "assistant")
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	if _, err := mgr.Reject(req.ID, "bob", "", "change freeze"); err != nil {
+		t.Fatalf("Reject() error = %v", err)
+	}
+
+	decided, err := mgr.Wait(context.Background(), req.ID)
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if decided.Status != StatusRejected || decided.Reason != "change freeze" {
+		t.Errorf("unexpected decision: %+v", decided)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	mgr := NewManager(Config{Mode: ModeEnqueue, Timeout: time.Minute})
+	now := time.Now()
+	mgr.now = func() time.Time { return now }
+
+	req, err := mgr.Submit(context.Background(), "capi_delete_machine", nil, "assistant")
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	got, err := mgr.Get(req.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Status != StatusExpired {
+		t.Errorf("expected status %s, got %s", StatusExpired, got.Status)
+	}
+	if _, err := mgr.Approve(req.ID, "alice", ""); err == nil {
+		t.Fatal("Approve() of an expired request should fail")
+	}
+}
+
+func TestCallbackHandler(t *testing.T) {
+	mgr := NewManager(Config{Mode: ModeEnqueue})
+	req, err := mgr.Submit(context.Background(), "capi_delete_cluster", nil, "assistant")
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	handler := mgr.Handler("secret")
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{name: "missing token", token: "", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", token: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "valid token", token: "secret", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/approvals/"+req.ID+"/approve", strings.NewReader(`{"approver":"alice"}`))
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+
+	got, _ := mgr.Get(req.ID)
+	if got.Status != StatusApproved || got.Approver != "alice" {
+		t.Errorf("unexpected request state: %+v", got)
+	}
+}
+
+func TestRetention(t *testing.T) {
+	mgr := NewManager(Config{Mode: ModeEnqueue, Timeout: time.Minute, Retention: time.Hour})
+	now := time.Now()
+	mgr.now = func() time.Time { return now }
+
+	expired, _ := mgr.Submit(context.Background(), "capi_delete_machine", nil, "assistant")
+	approved, _ := mgr.Submit(context.Background(), "capi_delete_cluster", nil, "assistant")
+	if _, err := mgr.Approve(approved.ID, "alice", ""); err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+
+	// Kept for the retention after their expiry
+	now = now.Add(time.Hour)
+	if got := mgr.List(); len(got) != 2 {
+		t.Fatalf("List() = %d requests, want 2", len(got))
+	}
+
+	pending, _ := mgr.Submit(context.Background(), "capi_delete_machine", nil, "assistant")
+	now = now.Add(2 * time.Minute)
+	got := mgr.List()
+	if len(got) != 1 || got[0].ID != pending.ID || got[0].Status != StatusExpired {
+		t.Fatalf("List() = %+v, want only the request expired last", got)
+	}
+	if _, err := mgr.Get(expired.ID); err == nil {
+		t.Error("Get() of a pruned request should fail")
+	}
+	if _, err := mgr.Consume(approved.ID, "capi_delete_cluster", nil); err == nil {
+		t.Error("Consume() of a pruned request should fail")
+	}
+}
@@ -0,0 +1,98 @@
+package approval
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// decisionRequest is the body accepted by the callback endpoint
+type decisionRequest struct {
+	Approver string `json:"approver"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Handler returns an HTTP handler serving the approval callback endpoints:
+//
+//	GET  /approvals              list requests
+//	GET  /approvals/{id}         get a single request
+//	POST /approvals/{id}/approve approve a pending request
+//	POST /approvals/{id}/reject  reject a pending request
+//
+// Every call must carry "Authorization: Bearer <token>".
+func (m *Manager) Handler(token string) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /approvals", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, m.List())
+	})
+
+	mux.HandleFunc("GET /approvals/{id}", func(w http.ResponseWriter, r *http.Request) {
+		req, err := m.Get(r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, req)
+	})
+
+	mux.HandleFunc("POST /approvals/{id}/approve", func(w http.ResponseWriter, r *http.Request) {
+		body, ok := decodeDecision(w, r)
+		if !ok {
+			return
+		}
+		req, err := m.Approve(r.PathValue("id"), body.Approver, "")
+		if err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, req)
+	})
+
+	mux.HandleFunc("POST /approvals/{id}/reject", func(w http.ResponseWriter, r *http.Request) {
+		body, ok := decodeDecision(w, r)
+		if !ok {
+			return
+		}
+		req, err := m.Reject(r.PathValue("id"), body.Approver, "", body.Reason)
+		if err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, req)
+	})
+
+	return requireToken(token, mux)
+}
+
+// requireToken rejects requests that do not present the shared bearer token
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		presented := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func decodeDecision(w http.ResponseWriter, r *http.Request) (decisionRequest, bool) {
+	var body decisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return body, false
+	}
+	return body, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
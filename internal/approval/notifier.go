@@ -0,0 +1,108 @@
+package approval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Notification is the information delivered to approvers for a new request
+type Notification struct {
+	Request Request
+	// Code is the one-time approval code for the request
+	Code string
+}
+
+// Notifier announces new approval requests to approvers
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// WebhookNotifier posts approval requests as JSON to a webhook URL. The payload
+// carries a Slack-compatible "text" field plus the structured request, so it
+// works with Slack incoming webhooks as well as ticketing integrations.
+type WebhookNotifier struct {
+	URL string
+	// CallbackURL is the externally reachable base URL of the callback endpoint (optional)
+	CallbackURL string
+	Client      *http.Client
+}
+
+// NewWebhookNotifier creates a webhook notifier with a sensible HTTP timeout
+func NewWebhookNotifier(url, callbackURL string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:         url,
+		CallbackURL: strings.TrimSuffix(callbackURL, "/"),
+		Client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type webhookPayload struct {
+	Text     string  `json:"text"`
+	Approval Request `json:"approval"`
+	Code     string  `json:"code"`
+}
+
+// Notify sends the approval request to the webhook
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	payload := webhookPayload{
+		Text:     FormatMessage(n, w.CallbackURL),
+		Approval: n.Request,
+		Code:     n.Code,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call approval webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("approval webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FormatMessage renders a human-readable description of an approval request
+func FormatMessage(n Notification, callbackURL string) string {
+	req := n.Request
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Approval required for destructive operation `%s`\n", req.Tool))
+	sb.WriteString(fmt.Sprintf("Request ID: %s\n", req.ID))
+	sb.WriteString(fmt.Sprintf("Requested by: %s\n", req.Requester))
+	sb.WriteString(fmt.Sprintf("Expires: %s\n", req.ExpiresAt.UTC().Format(time.RFC3339)))
+
+	if len(req.Arguments) > 0 {
+		keys := make([]string, 0, len(req.Arguments))
+		for k := range req.Arguments {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		sb.WriteString("Arguments:\n")
+		for _, k := range keys {
+			sb.WriteString(fmt.Sprintf("  • %s: %v\n", k, req.Arguments[k]))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("Approval code: %s\n", n.Code))
+	if callbackURL != "" {
+		sb.WriteString(fmt.Sprintf("Approve: POST %s/approvals/%s/approve\n", callbackURL, req.ID))
+		sb.WriteString(fmt.Sprintf("Reject:  POST %s/approvals/%s/reject\n", callbackURL, req.ID))
+	}
+	return sb.String()
+}
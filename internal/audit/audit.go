@@ -0,0 +1,214 @@
+// Package audit records mutating tool invocations.
+//
+// Every entry captures the tool name, the call arguments with secret values
+// redacted, the caller identity, the outcome and a timestamp. Entries are
+// kept in a bounded in-memory buffer for querying and forwarded to the
+// configured sinks, such as a JSON lines file or Kubernetes Events.
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCapacity is the number of entries kept in memory when none is configured
+const DefaultCapacity = 1000
+
+// Redacted replaces secret argument values
+const Redacted = "[REDACTED]"
+
+// Result is the outcome of an audited tool call
+type Result string
+
+const (
+	ResultSuccess Result = "success"
+	ResultError   Result = "error"
+)
+
+// Entry is a single audit record
+type Entry struct {
+	Time      time.Time              `json:"time"`
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Caller    string                 `json:"caller"`
+	Result    Result                 `json:"result"`
+	Message   string                 `json:"message,omitempty"`
+	Duration  time.Duration          `json:"duration"`
+}
+
+// Sink persists audit entries
+type Sink interface {
+	Write(ctx context.Context, entry Entry) error
+}
+
+// Query filters entries returned by Logger.Recent
+type Query struct {
+	Tool   string
+	Caller string
+	Result Result
+	Since  time.Time
+	Limit  int
+}
+
+// Logger keeps recent entries in memory and forwards them to sinks
+type Logger struct {
+	mu       sync.RWMutex
+	entries  []Entry
+	next     int
+	full     bool
+	capacity int
+	sinks    []Sink
+	// closed stops writing to the sinks
+	closed bool
+}
+
+// NewLogger creates an audit logger keeping up to capacity entries in memory
+func NewLogger(capacity int, sinks ...Sink) *Logger {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Logger{
+		entries:  make([]Entry, capacity),
+		capacity: capacity,
+		sinks:    sinks,
+	}
+}
+
+// Record stores an entry and writes it to all sinks. Arguments are redacted
+// before they are stored. Sink failures are logged but never fail the call.
+func (l *Logger) Record(ctx context.Context, entry Entry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	entry.Arguments = Redact(entry.Arguments)
+
+	l.mu.Lock()
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % l.capacity
+	if l.next == 0 {
+		l.full = true
+	}
+	closed := l.closed
+	l.mu.Unlock()
+	if closed {
+		slog.WarnContext(ctx, "Audit log closed, entry kept in memory only", "tool", entry.Tool)
+		return
+	}
+
+	for _, sink := range l.sinks {
+		if err := sink.Write(ctx, entry); err != nil {
+			slog.WarnContext(ctx, "Failed to write audit entry", "tool", entry.Tool, "error", err)
+		}
+	}
+}
+
+// Close flushes and closes the sinks that hold resources, such as files.
+// Entries recorded afterwards are only kept in memory.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	var errs []error
+	for _, sink := range l.sinks {
+		if closer, ok := sink.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Recent returns matching entries, newest first
+func (l *Logger) Recent(q Query) []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	count := l.next
+	if l.full {
+		count = l.capacity
+	}
+
+	var result []Entry
+	for i := 0; i < count; i++ {
+		entry := l.entries[(l.next-1-i+l.capacity)%l.capacity]
+		if q.Tool != "" && entry.Tool != q.Tool {
+			continue
+		}
+		if q.Caller != "" && entry.Caller != q.Caller {
+			continue
+		}
+		if q.Result != "" && entry.Result != q.Result {
+			continue
+		}
+		if !q.Since.IsZero() && entry.Time.Before(q.Since) {
+			continue
+		}
+		result = append(result, entry)
+		if q.Limit > 0 && len(result) >= q.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// secretKeyParts are argument name fragments whose values are never recorded
+var secretKeyParts = []string{"password", "secret", "token", "credential", "kubeconfig", "key", "code", "cert"}
+
+// isSecretKey reports whether an argument name refers to a secret value
+func isSecretKey(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range secretKeyParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns a copy of the arguments with secret values replaced
+func Redact(arguments map[string]interface{}) map[string]interface{} {
+	if arguments == nil {
+		return nil
+	}
+	redacted := make(map[string]interface{}, len(arguments))
+	for name, value := range arguments {
+		if isSecretKey(name) {
+			redacted[name] = Redacted
+			continue
+		}
+		redacted[name] = redactValue(value)
+	}
+	return redacted
+}
+
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return Redact(v)
+	case []interface{}:
+		values := make([]interface{}, len(v))
+		for i, item := range v {
+			values[i] = redactValue(item)
+		}
+		return values
+	default:
+		return v
+	}
+}
+
+// String renders the entry on a single line
+func (e Entry) String() string {
+	line := fmt.Sprintf("%s %s by %s: %s", e.Time.UTC().Format(time.RFC3339), e.Tool, e.Caller, e.Result)
+	if e.Message != "" {
+		line += fmt.Sprintf(" (%s)", e.Message)
+	}
+	return line
+}
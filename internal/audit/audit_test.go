@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRedact(t *testing.T) {
+	args := map[string]interface{}{
+		"name":        "prod",
+		"code":        "123456",
+		"aws_secret":  "s3cr3t",
+		"annotations": map[string]interface{}{"api-token": "abc", "owner": "team-a"},
+	}
+
+	got := Redact(args)
+	if got["name"] != "prod" {
+		t.Errorf("name should not be redacted: %v", got["name"])
+	}
+	if got["code"] != Redacted || got["aws_secret"] != Redacted {
+		t.Errorf("secret values were not redacted: %v", got)
+	}
+	nested := got["annotations"].(map[string]interface{})
+	if nested["api-token"] != Redacted || nested["owner"] != "team-a" {
+		t.Errorf("nested values not redacted correctly: %v", nested)
+	}
+	if args["code"] != "123456" {
+		t.Error("Redact() must not modify its input")
+	}
+}
+
+func TestLoggerRecent(t *testing.T) {
+	logger := NewLogger(3)
+	ctx := context.Background()
+
+	logger.Record(ctx, Entry{Tool: "capi_scale_cluster", Caller: "a", Result: ResultSuccess})
+	logger.Record(ctx, Entry{Tool: "capi_delete_machine", Caller: "b", Result: ResultError})
+	logger.Record(ctx, Entry{Tool: "capi_scale_cluster", Caller: "a", Result: ResultSuccess})
+	logger.Record(ctx, Entry{Tool: "capi_pause_cluster", Caller: "a", Result: ResultSuccess})
+
+	all := logger.Recent(Query{})
+	if len(all) != 3 {
+		t.Fatalf("expected capacity-bounded 3 entries, got %d", len(all))
+	}
+	if all[0].Tool != "capi_pause_cluster" || all[2].Tool != "capi_delete_machine" {
+		t.Errorf("entries not returned newest first: %v", all)
+	}
+
+	if got := logger.Recent(Query{Result: ResultError}); len(got) != 1 || got[0].Caller != "b" {
+		t.Errorf("result filter returned %v", got)
+	}
+	if got := logger.Recent(Query{Tool: "capi_scale_cluster", Limit: 5}); len(got) != 1 {
+		t.Errorf("tool filter returned %v", got)
+	}
+	if got := logger.Recent(Query{Caller: "a", Limit: 1}); len(got) != 1 {
+		t.Errorf("limit not applied: %v", got)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(filename)
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+
+	logger := NewLogger(10, sink)
+	logger.Record(context.Background(), Entry{Tool: "capi_get_kubeconfig", Arguments: map[string]interface{}{"token": "x"}, Result: ResultSuccess})
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Entries after closing stay in memory without failing on the closed file
+	logger.Record(context.Background(), Entry{Tool: "capi_scale_cluster", Result: ResultSuccess})
+	if recent := logger.Recent(Query{}); len(recent) != 2 {
+		t.Errorf("Recent() after Close() = %d entries, want 2", len(recent))
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		t.Fatal("audit log file is empty")
+	}
+	var entry Entry
+	if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid JSON line: %v", err)
+	}
+	if entry.Tool != "capi_get_kubeconfig" || entry.Arguments["token"] != Redacted {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if scanner.Scan() {
+		t.Errorf("entry written after Close(): %s", scanner.Text())
+	}
+}
+
+func TestEventSink(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	sink := NewEventSink(clientset, "mcp-system", "mcp-capi")
+
+	if err := sink.Write(context.Background(), Entry{Tool: "capi_delete_cluster", Caller: "cursor", Result: ResultError}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	events, err := clientset.CoreV1().Events("mcp-system").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events.Items) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events.Items))
+	}
+	if event := events.Items[0]; event.Reason != "DeleteCluster" || event.Type != "Warning" {
+		t.Errorf("unexpected event: reason=%s type=%s", event.Reason, event.Type)
+	}
+}
@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// FileSink appends entries as JSON lines to a file
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens (or creates) the audit log file for appending
+func NewFileSink(filename string) (*FileSink, error) {
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log file: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Write appends the entry to the file
+func (s *FileSink) Write(ctx context.Context, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(append(data, '\n'))
+	return err
+}
+
+// Close flushes the entries to disk and closes the underlying file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.file.Sync(); err != nil {
+		s.file.Close()
+		return fmt.Errorf("failed to flush audit log file: %w", err)
+	}
+	return s.file.Close()
+}
+
+// EventSink records entries as Kubernetes Events in a namespace
+type EventSink struct {
+	client    kubernetes.Interface
+	namespace string
+	component string
+}
+
+// NewEventSink creates a sink emitting Events in the given namespace
+func NewEventSink(client kubernetes.Interface, namespace, component string) *EventSink {
+	return &EventSink{client: client, namespace: namespace, component: component}
+}
+
+// Write creates an Event describing the entry
+func (s *EventSink) Write(ctx context.Context, entry Entry) error {
+	arguments, err := json.Marshal(entry.Arguments)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit arguments: %w", err)
+	}
+
+	eventType := corev1.EventTypeNormal
+	if entry.Result == ResultError {
+		eventType = corev1.EventTypeWarning
+	}
+
+	message := fmt.Sprintf("%s called %s (%s) with %s", entry.Caller, entry.Tool, entry.Result, arguments)
+	if entry.Message != "" {
+		message += ": " + entry.Message
+	}
+
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: s.component + "-audit-",
+			Namespace:    s.namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/component": "audit",
+				"app.kubernetes.io/name":      s.component,
+			},
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      "Namespace",
+			Name:      s.namespace,
+			Namespace: s.namespace,
+		},
+		Reason:              eventReason(entry.Tool),
+		Message:             message,
+		Type:                eventType,
+		Source:              corev1.EventSource{Component: s.component},
+		FirstTimestamp:      metav1.NewTime(entry.Time),
+		LastTimestamp:       metav1.NewTime(entry.Time),
+		Count:               1,
+		ReportingController: s.component,
+		EventTime:           metav1.NewMicroTime(entry.Time.Truncate(time.Microsecond)),
+		Action:              entry.Tool,
+		ReportingInstance:   s.component,
+	}
+
+	if _, err := s.client.CoreV1().Events(s.namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create audit event: %w", err)
+	}
+	return nil
+}
+
+// eventReason turns a tool name into a CamelCase event reason
+func eventReason(tool string) string {
+	var reason strings.Builder
+	for _, part := range strings.Split(strings.TrimPrefix(tool, "capi_"), "_") {
+		if part == "" {
+			continue
+		}
+		reason.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return reason.String()
+}
@@ -0,0 +1,182 @@
+// Package auth authenticates MCP clients of the network transports.
+//
+// Clients present a bearer token, which is either one of the static tokens of
+// the configuration or an OIDC ID token. Every authenticated identity carries
+// a policy: read-only identities may only call read-only tools, and identities
+// restricted to namespaces may only operate on resources in those namespaces.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// ErrUnauthenticated is returned for missing, unknown or invalid credentials
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Policy restricts what an identity may do
+type Policy struct {
+	// ReadOnly limits the identity to read-only tools
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// Namespaces limits the identity to these namespaces; empty allows all
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// AllowsNamespace reports whether the policy permits access to a namespace
+func (p Policy) AllowsNamespace(namespace string) bool {
+	return len(p.Namespaces) == 0 || slices.Contains(p.Namespaces, namespace)
+}
+
+// Restricted reports whether the policy limits the identity to namespaces
+func (p Policy) Restricted() bool {
+	return len(p.Namespaces) > 0
+}
+
+// Identity is an authenticated client
+type Identity struct {
+	Name   string
+	Groups []string
+	Policy Policy
+}
+
+// Authenticator validates bearer tokens
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*Identity, error)
+}
+
+// TokenConfig declares a static bearer token
+type TokenConfig struct {
+	Name string `json:"name"`
+	// Token is the token itself; prefer TokenFile to keep it out of the config
+	Token string `json:"token,omitempty"`
+	// TokenFile is read once at startup, surrounding whitespace is ignored
+	TokenFile string `json:"tokenFile,omitempty"`
+	// Policy applies to clients presenting the token
+	Policy
+}
+
+// Config configures the accepted credentials
+type Config struct {
+	Tokens []TokenConfig `json:"tokens,omitempty"`
+	OIDC   *OIDCConfig   `json:"oidc,omitempty"`
+}
+
+// LoadFile reads an authentication config from a YAML file
+func LoadFile(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth config file: %w", err)
+	}
+
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse auth config file: %w", err)
+	}
+	return config, nil
+}
+
+// New creates an authenticator accepting the credentials of the config
+func New(config *Config) (Authenticator, error) {
+	if len(config.Tokens) == 0 && config.OIDC == nil {
+		return nil, fmt.Errorf("auth config declares neither tokens nor oidc")
+	}
+
+	a := &authenticator{}
+	for i, token := range config.Tokens {
+		if token.Name == "" {
+			return nil, fmt.Errorf("token %d has no name", i)
+		}
+		value := token.Token
+		if token.TokenFile != "" {
+			data, err := os.ReadFile(token.TokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read token file of %s: %w", token.Name, err)
+			}
+			value = strings.TrimSpace(string(data))
+		}
+		if value == "" {
+			return nil, fmt.Errorf("token %s is empty", token.Name)
+		}
+		a.tokens = append(a.tokens, staticToken{value: []byte(value), identity: Identity{Name: token.Name, Policy: token.Policy}})
+	}
+
+	if config.OIDC != nil {
+		verifier, err := newOIDCVerifier(*config.OIDC)
+		if err != nil {
+			return nil, err
+		}
+		a.oidc = verifier
+	}
+	return a, nil
+}
+
+type staticToken struct {
+	value    []byte
+	identity Identity
+}
+
+// authenticator checks static tokens first, then OIDC ID tokens
+type authenticator struct {
+	tokens []staticToken
+	oidc   *oidcVerifier
+}
+
+func (a *authenticator) Authenticate(ctx context.Context, token string) (*Identity, error) {
+	if token == "" {
+		return nil, fmt.Errorf("%w: missing bearer token", ErrUnauthenticated)
+	}
+
+	for _, static := range a.tokens {
+		if subtle.ConstantTimeCompare(static.value, []byte(token)) == 1 {
+			identity := static.identity
+			return &identity, nil
+		}
+	}
+
+	if a.oidc != nil && strings.Count(token, ".") == 2 {
+		return a.oidc.Authenticate(ctx, token)
+	}
+	return nil, fmt.Errorf("%w: unknown token", ErrUnauthenticated)
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a context carrying the authenticated identity
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the authenticated identity, if any. Calls over
+// stdio are not authenticated and have no identity.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// Middleware rejects HTTP requests without valid bearer credentials and adds
+// the identity to the context of authenticated requests
+func Middleware(authenticator Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found {
+			token = ""
+		}
+
+		identity, err := authenticator.Authenticate(r.Context(), strings.TrimSpace(token))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp-capi"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+	})
+}
@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStaticTokens(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("viewer-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator, err := New(&Config{Tokens: []TokenConfig{
+		{Name: "admin", Token: "admin-secret"},
+		{Name: "viewer", TokenFile: tokenFile, Policy: Policy{ReadOnly: true, Namespaces: []string{"org-acme"}}},
+	}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	identity, err := authenticator.Authenticate(context.Background(), "viewer-secret")
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if identity.Name != "viewer" || !identity.Policy.ReadOnly {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+	if !identity.Policy.AllowsNamespace("org-acme") || identity.Policy.AllowsNamespace("default") {
+		t.Error("namespace restriction not applied")
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		if _, err := authenticator.Authenticate(context.Background(), token); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("Authenticate(%q) error = %v, want ErrUnauthenticated", token, err)
+		}
+	}
+
+	if _, err := New(&Config{Tokens: []TokenConfig{{Name: "empty"}}}); err == nil {
+		t.Error("New() should reject empty tokens")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	authenticator, err := New(&Config{Tokens: []TokenConfig{{Name: "admin", Token: "admin-secret"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := Middleware(authenticator, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, ok := IdentityFromContext(r.Context())
+		if !ok {
+			t.Error("identity missing from request context")
+			return
+		}
+		_, _ = w.Write([]byte(identity.Name))
+	}))
+
+	tests := []struct {
+		header string
+		status int
+	}{
+		{"Bearer admin-secret", http.StatusOK},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Basic YWRtaW46YWRtaW4=", http.StatusUnauthorized},
+		{"", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.status {
+			t.Errorf("Authorization %q: status = %d, want %d", tt.header, rec.Code, tt.status)
+		}
+	}
+}
+
+// testIssuer serves OIDC discovery and signs ID tokens with an RSA key
+type testIssuer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := &testIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": issuer.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": "test",
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+func (i *testIssuer) sign(t *testing.T, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "test"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, i.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestOIDC(t *testing.T) {
+	issuer := newTestIssuer(t)
+	authenticator, err := New(&Config{OIDC: &OIDCConfig{
+		IssuerURL:     issuer.URL,
+		Audience:      "mcp-capi",
+		UsernameClaim: "email",
+		Policies: []GroupPolicy{
+			{Group: "capi-viewers", Policy: Policy{ReadOnly: true}},
+			{Group: "capi-admins"},
+		},
+	}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	valid := func() map[string]any {
+		return map[string]any{
+			"iss":    issuer.URL,
+			"aud":    []string{"mcp-capi"},
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"email":  "jane@example.com",
+			"groups": []string{"capi-viewers"},
+		}
+	}
+
+	identity, err := authenticator.Authenticate(context.Background(), issuer.sign(t, valid()))
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if identity.Name != "jane@example.com" || !identity.Policy.ReadOnly {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+
+	invalid := map[string]func(map[string]any){
+		"expired":        func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"wrong audience": func(c map[string]any) { c["aud"] = "other" },
+		"wrong issuer":   func(c map[string]any) { c["iss"] = "https://evil.example.com" },
+		"no group":       func(c map[string]any) { c["groups"] = []string{"developers"} },
+	}
+	for name, mutate := range invalid {
+		t.Run(name, func(t *testing.T) {
+			claims := valid()
+			mutate(claims)
+			if _, err := authenticator.Authenticate(context.Background(), issuer.sign(t, claims)); !errors.Is(err, ErrUnauthenticated) {
+				t.Errorf("Authenticate() error = %v, want ErrUnauthenticated", err)
+			}
+		})
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		token := issuer.sign(t, valid())
+		claims := valid()
+		claims["groups"] = []string{"capi-admins"}
+		payload, _ := json.Marshal(claims)
+		parts := strings.Split(token, ".")
+		tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload) + "." + parts[2]
+		if _, err := authenticator.Authenticate(context.Background(), tampered); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("Authenticate() error = %v, want ErrUnauthenticated", err)
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"filippo.io/age"
+)
+
+// The bundles are encrypted in the age format (age-encryption.org/v1) for
+// X25519 recipients, so they can be decrypted with the age CLI:
+// age --decrypt -i key.txt backup.yaml.age
+
+const ageSuffix = ".age"
+
+// parseAgeRecipient reads a public key such as age1...
+func parseAgeRecipient(s string) (*age.X25519Recipient, error) {
+	recipient, err := age.ParseX25519Recipient(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid age recipient %q: %w", s, err)
+	}
+	return recipient, nil
+}
+
+// loadAgeIdentities reads an age identity file, ignoring comments and
+// empty lines
+func loadAgeIdentities(filename string) ([]age.Identity, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read age identity file: %w", err)
+	}
+	defer file.Close()
+	identities, err := age.ParseIdentities(file)
+	if err != nil {
+		return nil, fmt.Errorf("age identity file %s: %w", filename, err)
+	}
+	return identities, nil
+}
+
+// ageEncrypt encrypts data for the recipients
+func ageEncrypt(data []byte, recipients []age.Recipient) ([]byte, error) {
+	var encrypted bytes.Buffer
+	w, err := age.Encrypt(&encrypted, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+	return encrypted.Bytes(), nil
+}
+
+// ageDecrypt decrypts data encrypted for one of the identities
+func ageDecrypt(data []byte, identities []age.Identity) ([]byte, error) {
+	r, err := age.Decrypt(bytes.NewReader(data), identities...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
@@ -0,0 +1,104 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+)
+
+// testAgeIdentity is the secret key of 32 0x42 bytes from the age test
+// vectors
+const testAgeIdentity = "AGE-SECRET-KEY-1GFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPYYSJZGFPQ4EGAEX"
+
+// exampleAgeFile is testdata/example.age of the age repository, encrypted
+// for the secret key in exampleAgeKeys
+const (
+	exampleAgeKeys = "# Test key for ExampleParseIdentities.\nAGE-SECRET-KEY-184JMZMVQH3E6U0PSL869004Y3U2NYV7R30EU99CSEDNPH02YUVFSZW44VU\n"
+	exampleAgeFile = "YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSA4aHJsTStaQkczRGQ0ZkYyK2E1ODN6ZFRJV0RrOC9SNDFrQ1lac3Z3VFc0CnlPNFBZZGxNV0RKK0N4Z1VOUnFZNVowVC9tK2czRkNoNWpJeEdMYkNWWGMKLS0tIEkvaW1ldlp6eTgxMjBKU3ptSm5tbi9LTWszcDVBMTFWODNOazQxbTlOUEUKcMXlNiShUgdT+Sxa0Q7KsnO6TWEXgHcT6DggQXod8soIGCJyyPhchXc0oTEaO3XpjQ6v"
+)
+
+func TestAgeKeys(t *testing.T) {
+	identity, err := age.ParseX25519Identity(testAgeIdentity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient := identity.Recipient().String()
+	if recipient != "age1zvkyg2lqzraa2lnjvqej32nkuu0ues2s82hzrye869xeexvn73equnujwj" {
+		t.Errorf("recipient = %q", recipient)
+	}
+	if _, err := parseAgeRecipient(recipient); err != nil {
+		t.Errorf("parseAgeRecipient(%q) error = %v", recipient, err)
+	}
+	for _, s := range []string{"", recipient[:len(recipient)-1] + "q", testAgeIdentity} {
+		if _, err := parseAgeRecipient(s); err == nil {
+			t.Errorf("parseAgeRecipient(%q) should fail", s)
+		}
+	}
+}
+
+func TestAgeDecryptExample(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key.txt")
+	if err := os.WriteFile(keyFile, []byte(exampleAgeKeys), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	identities, err := loadAgeIdentities(keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := base64.StdEncoding.DecodeString(exampleAgeFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := ageDecrypt(encrypted, identities)
+	if err != nil || string(decrypted) != "Black lives matter." {
+		t.Errorf("ageDecrypt() = %q, %v", decrypted, err)
+	}
+}
+
+func TestAgeEncryption(t *testing.T) {
+	identity, err := age.ParseX25519Identity(testAgeIdentity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := parseAgeRecipient("age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p")
+	chunk := 64 * 1024
+
+	for name, data := range map[string][]byte{
+		"empty":          {},
+		"small":          []byte("kind: Cluster\n"),
+		"one chunk":      bytes.Repeat([]byte("x"), chunk),
+		"several chunks": bytes.Repeat([]byte("cluster\n"), chunk/4+3),
+	} {
+		t.Run(name, func(t *testing.T) {
+			encrypted, err := ageEncrypt(data, []age.Recipient{other, identity.Recipient()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(encrypted, []byte("age-encryption.org/v1\n-> X25519 ")) {
+				t.Errorf("encrypted header = %q", encrypted[:40])
+			}
+			decrypted, err := ageDecrypt(encrypted, []age.Identity{identity})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(decrypted, data) {
+				t.Errorf("decrypted %d bytes, want %d", len(decrypted), len(data))
+			}
+
+			tampered := append([]byte{}, encrypted...)
+			tampered[len(tampered)-1] ^= 1
+			if _, err := ageDecrypt(tampered, []age.Identity{identity}); err == nil {
+				t.Error("ageDecrypt() of a tampered payload should fail")
+			}
+		})
+	}
+
+	encrypted, _ := ageEncrypt([]byte("secret"), []age.Recipient{other})
+	if _, err := ageDecrypt(encrypted, []age.Identity{identity}); err == nil {
+		t.Error("ageDecrypt() for another recipient should fail")
+	}
+}
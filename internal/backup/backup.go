@@ -0,0 +1,409 @@
+// Package backup stores the backup bundles of clusters outside the
+// management cluster, in S3-compatible buckets or OCI registries.
+//
+// Targets are configured in a YAML file. Bundles are stored under
+// <namespace>/<cluster>/<timestamp>.<format>, so the backups of a cluster
+// list in order, optionally encrypted for age recipients (with .age
+// appended) and with server-side KMS encryption on S3. After each upload the
+// retention policy of the target deletes the oldest backups of the cluster,
+// always keeping the newest one.
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"sigs.k8s.io/yaml"
+)
+
+// timestampFormat names the bundles, sorting them by time
+const timestampFormat = "20060102T150405Z"
+
+// ErrNotFound is returned for backups that are not stored on a target
+var ErrNotFound = errors.New("backup not found")
+
+// Store keeps objects by key, like a bucket
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	// List returns the objects with keys starting with prefix
+	List(ctx context.Context, prefix string) ([]StoredObject, error)
+	Delete(ctx context.Context, key string) error
+	// Location describes where the objects are stored, e.g. s3://bucket/prefix
+	Location() string
+}
+
+// StoredObject is an object of a Store
+type StoredObject struct {
+	Key string
+	// Size is 0 when the store does not report it
+	Size int64
+}
+
+// Backup is a bundle stored on a target
+type Backup struct {
+	Key       string    `json:"key"`
+	Namespace string    `json:"namespace"`
+	Cluster   string    `json:"cluster"`
+	Time      time.Time `json:"time"`
+	// Format is yaml or json
+	Format    string `json:"format"`
+	Encrypted bool   `json:"encrypted"`
+	Size      int64  `json:"size,omitempty"`
+}
+
+// Key returns the key of a bundle of a cluster
+func Key(namespace, cluster, format string, t time.Time, encrypted bool) string {
+	key := path.Join(namespace, cluster, t.UTC().Format(timestampFormat)+"."+format)
+	if encrypted {
+		key += ageSuffix
+	}
+	return key
+}
+
+// parseKey reads a key written by Key
+func parseKey(key string) (Backup, bool) {
+	parts := strings.Split(key, "/")
+	if len(parts) != 3 {
+		return Backup{}, false
+	}
+	file := parts[2]
+	backup := Backup{Key: key, Namespace: parts[0], Cluster: parts[1]}
+	if strings.HasSuffix(file, ageSuffix) {
+		backup.Encrypted = true
+		file = strings.TrimSuffix(file, ageSuffix)
+	}
+	timestamp, format, ok := strings.Cut(file, ".")
+	if !ok || (format != "yaml" && format != "json") {
+		return Backup{}, false
+	}
+	t, err := time.Parse(timestampFormat, timestamp)
+	if err != nil {
+		return Backup{}, false
+	}
+	backup.Time, backup.Format = t, format
+	return backup, true
+}
+
+// Retention limits the backups kept per cluster. The newest backup is
+// always kept.
+type Retention struct {
+	// Keep is the number of backups to keep, all when 0
+	Keep int `json:"keep,omitempty"`
+	// MaxAge deletes older backups, e.g. 720h, none when empty
+	MaxAge string `json:"maxAge,omitempty"`
+
+	maxAge time.Duration
+}
+
+// expired returns the backups to delete, given the backups of a cluster
+// newest first
+func (r Retention) expired(backups []Backup, now time.Time) []Backup {
+	var expired []Backup
+	for i, backup := range backups {
+		if i == 0 {
+			continue
+		}
+		if (r.Keep > 0 && i >= r.Keep) || (r.maxAge > 0 && now.Sub(backup.Time) > r.maxAge) {
+			expired = append(expired, backup)
+		}
+	}
+	return expired
+}
+
+// String describes the policy, e.g. "keep 14, max age 720h0m0s"
+func (r Retention) String() string {
+	var parts []string
+	if r.Keep > 0 {
+		parts = append(parts, fmt.Sprintf("keep %d", r.Keep))
+	}
+	if r.maxAge > 0 {
+		parts = append(parts, fmt.Sprintf("max age %s", r.maxAge))
+	}
+	if len(parts) == 0 {
+		return "keep all"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Encryption configures the client-side encryption of the bundles of a
+// target
+type Encryption struct {
+	// AgeRecipients are the age public keys (age1...) bundles are encrypted
+	// for
+	AgeRecipients []string `json:"ageRecipients,omitempty"`
+	// AgeIdentityFile holds age secret keys (AGE-SECRET-KEY-1...), one per
+	// line, to decrypt bundles, only needed to read them back
+	AgeIdentityFile string `json:"ageIdentityFile,omitempty"`
+}
+
+// TargetConfig configures where and how backups are stored. Exactly one of
+// S3 and OCI is set.
+type TargetConfig struct {
+	Name       string      `json:"name"`
+	S3         *S3Config   `json:"s3,omitempty"`
+	OCI        *OCIConfig  `json:"oci,omitempty"`
+	Encryption *Encryption `json:"encryption,omitempty"`
+	Retention  Retention   `json:"retention,omitempty"`
+}
+
+// Config is the format of the backup targets file
+type Config struct {
+	Targets []TargetConfig `json:"backupTargets"`
+}
+
+// targetNamePattern restricts target names to what tools accept as
+// arguments
+var targetNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// LoadFile reads a YAML backup targets file
+func LoadFile(filename string) (*Targets, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup targets file: %w", err)
+	}
+	config := Config{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse backup targets file: %w", err)
+	}
+	return New(config)
+}
+
+// New validates a config and creates its targets
+func New(config Config) (*Targets, error) {
+	targets := &Targets{}
+	for _, tc := range config.Targets {
+		if !targetNamePattern.MatchString(tc.Name) {
+			return nil, fmt.Errorf("invalid backup target name %q, use lowercase letters, digits and dashes", tc.Name)
+		}
+		if _, err := targets.Get(tc.Name); err == nil {
+			return nil, fmt.Errorf("duplicate backup target %q", tc.Name)
+		}
+		target := &Target{Name: tc.Name, Retention: tc.Retention}
+		var err error
+		switch {
+		case tc.S3 != nil && tc.OCI != nil:
+			return nil, fmt.Errorf("backup target %s: set either s3 or oci", tc.Name)
+		case tc.S3 != nil:
+			target.store, err = NewS3Store(*tc.S3)
+		case tc.OCI != nil:
+			target.store, err = NewOCIStore(*tc.OCI)
+		default:
+			return nil, fmt.Errorf("backup target %s: s3 or oci is required", tc.Name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("backup target %s: %w", tc.Name, err)
+		}
+		if tc.Retention.Keep < 0 {
+			return nil, fmt.Errorf("backup target %s: retention keep must not be negative", tc.Name)
+		}
+		if tc.Retention.MaxAge != "" {
+			d, err := time.ParseDuration(tc.Retention.MaxAge)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("backup target %s: invalid retention maxAge %q (must be a positive duration)", tc.Name, tc.Retention.MaxAge)
+			}
+			target.Retention.maxAge = d
+		}
+		if tc.Encryption != nil {
+			for _, recipient := range tc.Encryption.AgeRecipients {
+				r, err := parseAgeRecipient(recipient)
+				if err != nil {
+					return nil, fmt.Errorf("backup target %s: %w", tc.Name, err)
+				}
+				target.recipients = append(target.recipients, r)
+			}
+			if tc.Encryption.AgeIdentityFile != "" {
+				if target.identities, err = loadAgeIdentities(tc.Encryption.AgeIdentityFile); err != nil {
+					return nil, fmt.Errorf("backup target %s: %w", tc.Name, err)
+				}
+			}
+		}
+		targets.targets = append(targets.targets, target)
+	}
+	return targets, nil
+}
+
+// Targets are the configured backup targets. A nil Targets has none.
+type Targets struct {
+	targets []*Target
+}
+
+// NewTargets groups targets created with NewTarget
+func NewTargets(targets ...*Target) *Targets {
+	return &Targets{targets: targets}
+}
+
+// List returns the targets in the order of the config
+func (t *Targets) List() []*Target {
+	if t == nil {
+		return nil
+	}
+	return t.targets
+}
+
+// Get returns a target by name
+func (t *Targets) Get(name string) (*Target, error) {
+	if t == nil || len(t.targets) == 0 {
+		return nil, fmt.Errorf("no backup targets are configured, see MCP_BACKUP_TARGETS_CONFIG")
+	}
+	names := make([]string, 0, len(t.targets))
+	for _, target := range t.targets {
+		if target.Name == name {
+			return target, nil
+		}
+		names = append(names, target.Name)
+	}
+	return nil, fmt.Errorf("unknown backup target %q, configured: %s", name, strings.Join(names, ", "))
+}
+
+// Target is a place bundles are stored in
+type Target struct {
+	Name      string
+	Retention Retention
+
+	store      Store
+	recipients []age.Recipient
+	identities []age.Identity
+}
+
+// NewTarget creates a target storing bundles in a store, unencrypted
+func NewTarget(name string, store Store, retention Retention) *Target {
+	if d, err := time.ParseDuration(retention.MaxAge); err == nil {
+		retention.maxAge = d
+	}
+	return &Target{Name: name, store: store, Retention: retention}
+}
+
+// WithKeep returns a copy of the target keeping keep backups per cluster
+// instead of the count of its retention policy
+func (t *Target) WithKeep(keep int) *Target {
+	target := *t
+	target.Retention.Keep = keep
+	return &target
+}
+
+// Location describes where the target stores bundles
+func (t *Target) Location() string {
+	return t.store.Location()
+}
+
+// Encrypted reports whether bundles are encrypted before they are uploaded
+func (t *Target) Encrypted() bool {
+	return len(t.recipients) > 0
+}
+
+// Upload is the result of storing a bundle
+type Upload struct {
+	Backup Backup `json:"backup"`
+	// Pruned are the keys of the backups the retention policy deleted
+	Pruned []string `json:"pruned,omitempty"`
+	// PruneError reports a failed retention run, the upload succeeded
+	PruneError string `json:"pruneError,omitempty"`
+}
+
+// Upload stores a bundle of a cluster, encrypted if the target has
+// recipients, and applies the retention policy to the backups of the
+// cluster
+func (t *Target) Upload(ctx context.Context, namespace, cluster, format string, bundle []byte, now time.Time) (*Upload, error) {
+	data := bundle
+	if t.Encrypted() {
+		var err error
+		if data, err = ageEncrypt(bundle, t.recipients); err != nil {
+			return nil, fmt.Errorf("failed to encrypt backup: %w", err)
+		}
+	}
+	key := Key(namespace, cluster, format, now, t.Encrypted())
+	if err := t.store.Put(ctx, key, data); err != nil {
+		return nil, fmt.Errorf("failed to upload backup to %s: %w", t.Location(), err)
+	}
+	backup, _ := parseKey(key)
+	backup.Size = int64(len(data))
+	upload := &Upload{Backup: backup}
+
+	pruned, err := t.Prune(ctx, namespace, cluster, now)
+	upload.Pruned = pruned
+	if err != nil {
+		upload.PruneError = err.Error()
+	}
+	return upload, nil
+}
+
+// List returns the backups of a cluster, or of all clusters of a namespace
+// when cluster is empty, newest first
+func (t *Target) List(ctx context.Context, namespace, cluster string) ([]Backup, error) {
+	prefix := namespace + "/"
+	if cluster != "" {
+		prefix += cluster + "/"
+	}
+	objects, err := t.store.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups on %s: %w", t.Location(), err)
+	}
+	backups := []Backup{}
+	for _, obj := range objects {
+		backup, ok := parseKey(obj.Key)
+		if !ok {
+			continue
+		}
+		backup.Size = obj.Size
+		backups = append(backups, backup)
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		if !backups[i].Time.Equal(backups[j].Time) {
+			return backups[i].Time.After(backups[j].Time)
+		}
+		return backups[i].Key < backups[j].Key
+	})
+	return backups, nil
+}
+
+// Download returns a bundle, decrypted with the identities of the target
+func (t *Target) Download(ctx context.Context, key string) ([]byte, error) {
+	backup, ok := parseKey(key)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a backup key such as <namespace>/<cluster>/%s.yaml", key, timestampFormat)
+	}
+	data, err := t.store.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download backup from %s: %w", t.Location(), err)
+	}
+	if !backup.Encrypted {
+		return data, nil
+	}
+	if len(t.identities) == 0 {
+		return nil, fmt.Errorf("backup %s is encrypted and target %s has no age identity file to decrypt it", key, t.Name)
+	}
+	bundle, err := ageDecrypt(data, t.identities)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt backup %s: %w", key, err)
+	}
+	return bundle, nil
+}
+
+// Prune deletes the backups of a cluster the retention policy expires and
+// returns their keys
+func (t *Target) Prune(ctx context.Context, namespace, cluster string, now time.Time) ([]string, error) {
+	if t.Retention.Keep == 0 && t.Retention.maxAge == 0 {
+		return nil, nil
+	}
+	backups, err := t.List(ctx, namespace, cluster)
+	if err != nil {
+		return nil, err
+	}
+	var pruned []string
+	for _, backup := range t.Retention.expired(backups, now) {
+		if err := t.store.Delete(ctx, backup.Key); err != nil {
+			return pruned, fmt.Errorf("failed to delete expired backup %s: %w", backup.Key, err)
+		}
+		pruned = append(pruned, backup.Key)
+	}
+	return pruned, nil
+}
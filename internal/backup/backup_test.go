@@ -0,0 +1,218 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+)
+
+// memoryStore keeps objects in a map
+type memoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: map[string][]byte{}}
+}
+
+func (s *memoryStore) Put(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return nil
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return data, nil
+}
+
+func (s *memoryStore) List(ctx context.Context, prefix string) ([]StoredObject, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var objects []StoredObject
+	for key, data := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, StoredObject{Key: key, Size: int64(len(data))})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memoryStore) Location() string {
+	return "memory://"
+}
+
+func TestParseKey(t *testing.T) {
+	now := time.Date(2026, time.October, 16, 2, 0, 0, 0, time.UTC)
+	key := Key("org-acme", "prod", "yaml", now, true)
+	if key != "org-acme/prod/20261016T020000Z.yaml.age" {
+		t.Errorf("Key() = %q", key)
+	}
+	backup, ok := parseKey(key)
+	if !ok || backup.Namespace != "org-acme" || backup.Cluster != "prod" || !backup.Time.Equal(now) || backup.Format != "yaml" || !backup.Encrypted {
+		t.Errorf("parseKey(%q) = %+v, %v", key, backup, ok)
+	}
+	for _, key := range []string{"org-acme/prod", "org-acme/prod/latest.yaml", "org-acme/prod/20261016T020000Z.txt", "a/b/c/20261016T020000Z.yaml"} {
+		if _, ok := parseKey(key); ok {
+			t.Errorf("parseKey(%q) should fail", key)
+		}
+	}
+}
+
+func TestTargetRetention(t *testing.T) {
+	store := newMemoryStore()
+	target := NewTarget("test", store, Retention{Keep: 3, MaxAge: "72h"})
+	ctx := context.Background()
+	start := time.Date(2026, time.October, 1, 2, 0, 0, 0, time.UTC)
+
+	// Another cluster is never pruned
+	if _, err := target.Upload(ctx, "org-acme", "dev", "yaml", []byte("dev"), start); err != nil {
+		t.Fatal(err)
+	}
+	var upload *Upload
+	for day := 0; day < 5; day++ {
+		var err error
+		if upload, err = target.Upload(ctx, "org-acme", "prod", "yaml", []byte("prod"), start.Add(time.Duration(day)*24*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(upload.Pruned) != 1 || upload.Pruned[0] != "org-acme/prod/20261002T020000Z.yaml" {
+		t.Errorf("pruned = %v, want the backup beyond the third", upload.Pruned)
+	}
+
+	backups, err := target.List(ctx, "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, backup := range backups {
+		keys = append(keys, backup.Key)
+	}
+	want := []string{"org-acme/prod/20261005T020000Z.yaml", "org-acme/prod/20261004T020000Z.yaml", "org-acme/prod/20261003T020000Z.yaml"}
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Errorf("backups = %v, want %v", keys, want)
+	}
+
+	// The newest backup is kept however old it is
+	pruned, err := target.Prune(ctx, "org-acme", "dev", start.Add(30*24*time.Hour))
+	if err != nil || len(pruned) != 0 {
+		t.Errorf("Prune(dev) = %v, %v, want the only backup kept", pruned, err)
+	}
+	all, err := target.List(ctx, "org-acme", "")
+	if err != nil || len(all) != 4 {
+		t.Errorf("List(org-acme) = %d backups, %v, want 4", len(all), err)
+	}
+}
+
+func TestTargetEncryption(t *testing.T) {
+	identity, err := age.ParseX25519Identity(testAgeIdentity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	identityFile := filepath.Join(t.TempDir(), "key.txt")
+	if err := os.WriteFile(identityFile, []byte("# created by a test\n"+testAgeIdentity+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	targets, err := New(Config{Targets: []TargetConfig{{
+		Name:       "encrypted",
+		S3:         &S3Config{Bucket: "backups", Region: "eu-central-1"},
+		Encryption: &Encryption{AgeRecipients: []string{identity.Recipient().String()}, AgeIdentityFile: identityFile},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := targets.Get("encrypted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := newMemoryStore()
+	target.store = store
+	ctx := context.Background()
+
+	bundle := []byte("apiVersion: cluster.x-k8s.io/v1beta1\nkind: Cluster\n")
+	upload, err := target.Upload(ctx, "org-acme", "prod", "yaml", bundle, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !upload.Backup.Encrypted || !strings.HasSuffix(upload.Backup.Key, ".yaml.age") {
+		t.Errorf("upload = %+v, want an encrypted backup", upload.Backup)
+	}
+	stored, _ := store.Get(ctx, upload.Backup.Key)
+	if strings.Contains(string(stored), "Cluster") {
+		t.Error("the stored backup is not encrypted")
+	}
+	downloaded, err := target.Download(ctx, upload.Backup.Key)
+	if err != nil || string(downloaded) != string(bundle) {
+		t.Errorf("Download() = %q, %v", downloaded, err)
+	}
+
+	target.identities = nil
+	if _, err := target.Download(ctx, upload.Backup.Key); err == nil {
+		t.Error("Download() without identities should fail")
+	}
+	if _, err := target.Download(ctx, "org-acme/prod/20200101T000000Z.yaml"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Download(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	s3 := &S3Config{Bucket: "backups", Region: "eu-central-1"}
+	for name, config := range map[string]TargetConfig{
+		"invalid name":       {Name: "Backups", S3: s3},
+		"no store":           {Name: "backups"},
+		"two stores":         {Name: "backups", S3: s3, OCI: &OCIConfig{Repository: "ghcr.io/acme/backups"}},
+		"negative keep":      {Name: "backups", S3: s3, Retention: Retention{Keep: -1}},
+		"invalid max age":    {Name: "backups", S3: s3, Retention: Retention{MaxAge: "a month"}},
+		"invalid recipient":  {Name: "backups", S3: s3, Encryption: &Encryption{AgeRecipients: []string{"age1invalid"}}},
+		"invalid repository": {Name: "backups", OCI: &OCIConfig{Repository: "https://ghcr.io"}},
+	} {
+		if _, err := New(Config{Targets: []TargetConfig{config}}); err == nil {
+			t.Errorf("New(%s) should fail", name)
+		}
+	}
+	if _, err := New(Config{Targets: []TargetConfig{{Name: "backups", S3: s3}, {Name: "backups", S3: s3}}}); err == nil {
+		t.Error("New() with duplicate targets should fail")
+	}
+
+	targets, err := New(Config{Targets: []TargetConfig{{Name: "backups", S3: s3, Retention: Retention{Keep: 7, MaxAge: "720h"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := targets.Get("backups")
+	if err != nil || target.Location() != "s3://backups/" || target.Retention.String() != "keep 7, max age 720h0m0s" {
+		t.Errorf("Get(backups) = %+v, %v", target, err)
+	}
+	if _, err := targets.Get("other"); err == nil {
+		t.Error("Get(other) should fail")
+	}
+	if _, err := (*Targets)(nil).Get("backups"); err == nil {
+		t.Error("Get() without targets should fail")
+	}
+}
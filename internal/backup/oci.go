@@ -0,0 +1,442 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Media types of the OCI artifacts holding bundles
+const (
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	ociEmptyMediaType    = "application/vnd.oci.empty.v1+json"
+	// ociArtifactType marks the artifacts as cluster backups
+	ociArtifactType = "application/vnd.giantswarm.mcp-capi.backup.v1"
+	// ociLayerMediaType is the bundle, encrypted or not
+	ociLayerMediaType    = "application/vnd.giantswarm.mcp-capi.backup.layer.v1"
+	ociTitleAnnotation   = "org.opencontainers.image.title"
+	ociCreatedAnnotation = "org.opencontainers.image.created"
+)
+
+// ociTagPattern is the grammar of OCI tags
+var ociTagPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]{0,127}$`)
+
+// OCIConfig configures a repository of an OCI registry, such as ghcr.io,
+// Harbor or Zot
+type OCIConfig struct {
+	// Repository is the registry host and repository path, e.g.
+	// ghcr.io/acme/cluster-backups
+	Repository string `json:"repository"`
+	// Username and PasswordEnv, the environment variable holding the
+	// password or token, authenticate to the registry, anonymous when empty
+	Username    string `json:"username,omitempty"`
+	PasswordEnv string `json:"passwordEnv,omitempty"`
+	// Insecure talks plain HTTP to the registry, for local registries
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// OCIStore stores objects as OCI artifacts, one tag per object. Keys are
+// tagged with their slashes replaced by underscores, which Kubernetes names
+// never contain.
+type OCIStore struct {
+	config     OCIConfig
+	baseURL    string
+	repository string
+	password   string
+	httpClient *http.Client
+
+	mu sync.Mutex
+	// authorization is the last accepted Authorization header
+	authorization string
+}
+
+// NewOCIStore creates a store for a repository, reading the password from
+// the environment
+func NewOCIStore(config OCIConfig) (*OCIStore, error) {
+	host, repository, ok := strings.Cut(config.Repository, "/")
+	if !ok || host == "" || repository == "" || strings.Contains(config.Repository, "://") {
+		return nil, fmt.Errorf("invalid oci repository %q, use <registry>/<repository> such as ghcr.io/acme/cluster-backups", config.Repository)
+	}
+	scheme := "https"
+	if config.Insecure {
+		scheme = "http"
+	}
+	store := &OCIStore{
+		config:     config,
+		baseURL:    scheme + "://" + host,
+		repository: repository,
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+	}
+	if config.PasswordEnv != "" {
+		store.password = os.Getenv(config.PasswordEnv)
+		if store.password == "" {
+			return nil, fmt.Errorf("oci password variable %s is not set", config.PasswordEnv)
+		}
+	}
+	return store, nil
+}
+
+// Location returns oci://<registry>/<repository>
+func (s *OCIStore) Location() string {
+	return "oci://" + s.config.Repository
+}
+
+// ociDescriptor describes a blob of a manifest
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ociManifest is an OCI image manifest of an artifact
+type ociManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        ociDescriptor     `json:"config"`
+	Layers        []ociDescriptor   `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// tag returns the tag of a key
+func (s *OCIStore) tag(key string) (string, error) {
+	tag := strings.ReplaceAll(key, "/", "_")
+	if !ociTagPattern.MatchString(tag) {
+		return "", fmt.Errorf("key %q is not a valid OCI tag (at most 128 characters)", key)
+	}
+	return tag, nil
+}
+
+// Put pushes an object as an artifact with an empty config and the object
+// as its only layer
+func (s *OCIStore) Put(ctx context.Context, key string, data []byte) error {
+	tag, err := s.tag(key)
+	if err != nil {
+		return err
+	}
+	empty := []byte("{}")
+	config := ociDescriptor{MediaType: ociEmptyMediaType, Digest: ociDigest(empty), Size: int64(len(empty))}
+	layer := ociDescriptor{
+		MediaType:   ociLayerMediaType,
+		Digest:      ociDigest(data),
+		Size:        int64(len(data)),
+		Annotations: map[string]string{ociTitleAnnotation: path.Base(key)},
+	}
+	for _, blob := range []struct {
+		descriptor ociDescriptor
+		data       []byte
+	}{{config, empty}, {layer, data}} {
+		if err := s.pushBlob(ctx, blob.descriptor.Digest, blob.data); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := json.Marshal(ociManifest{
+		SchemaVersion: 2,
+		MediaType:     ociManifestMediaType,
+		ArtifactType:  ociArtifactType,
+		Config:        config,
+		Layers:        []ociDescriptor{layer},
+		Annotations:   map[string]string{ociCreatedAnnotation: time.Now().UTC().Format(time.RFC3339)},
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodPut, s.url("manifests/"+tag), http.Header{"Content-Type": {ociManifestMediaType}}, manifest)
+	if err != nil {
+		return fmt.Errorf("failed to push manifest: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// pushBlob uploads a blob in a single request, unless the registry has it
+func (s *OCIStore) pushBlob(ctx context.Context, digest string, data []byte) error {
+	if resp, err := s.do(ctx, http.MethodHead, s.url("blobs/"+digest), nil, nil); err == nil {
+		resp.Body.Close()
+		return nil
+	}
+	resp, err := s.do(ctx, http.MethodPost, s.url("blobs/uploads/"), nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start blob upload: %w", err)
+	}
+	resp.Body.Close()
+	location, err := resp.Location()
+	if err != nil {
+		return fmt.Errorf("registry returned no blob upload location: %w", err)
+	}
+	query := location.Query()
+	query.Set("digest", digest)
+	location.RawQuery = query.Encode()
+	resp, err = s.do(ctx, http.MethodPut, location.String(), http.Header{"Content-Type": {"application/octet-stream"}}, data)
+	if err != nil {
+		return fmt.Errorf("failed to upload blob: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get pulls the layer of an artifact
+func (s *OCIStore) Get(ctx context.Context, key string) ([]byte, error) {
+	tag, err := s.tag(key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, s.url("manifests/"+tag), http.Header{"Accept": {ociManifestMediaType}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	manifest := ociManifest{}
+	err = json.NewDecoder(resp.Body).Decode(&manifest)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	if len(manifest.Layers) != 1 || manifest.Layers[0].MediaType != ociLayerMediaType {
+		return nil, fmt.Errorf("%s:%s is not a cluster backup", s.config.Repository, tag)
+	}
+
+	resp, err = s.do(ctx, http.MethodGet, s.url("blobs/"+manifest.Layers[0].Digest), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if ociDigest(data) != manifest.Layers[0].Digest {
+		return nil, fmt.Errorf("digest mismatch of %s:%s", s.config.Repository, tag)
+	}
+	return data, nil
+}
+
+// Delete deletes the manifest of an artifact by digest, as registries
+// require. Registries garbage collect the blobs.
+func (s *OCIStore) Delete(ctx context.Context, key string) error {
+	tag, err := s.tag(key)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodHead, s.url("manifests/"+tag), http.Header{"Accept": {ociManifestMediaType}}, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return fmt.Errorf("registry returned no digest for %s:%s", s.config.Repository, tag)
+	}
+	resp, err = s.do(ctx, http.MethodDelete, s.url("manifests/"+digest), nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// List lists the tags of the repository starting with the tag of prefix,
+// following the pagination links
+func (s *OCIStore) List(ctx context.Context, prefix string) ([]StoredObject, error) {
+	tagPrefix := strings.ReplaceAll(prefix, "/", "_")
+	var objects []StoredObject
+	next := s.url("tags/list")
+	for next != "" {
+		resp, err := s.do(ctx, http.MethodGet, next, nil, nil)
+		if errors.Is(err, ErrNotFound) {
+			// Nothing was pushed to the repository yet
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		list := struct {
+			Tags []string `json:"tags"`
+		}{}
+		err = json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tag list: %w", err)
+		}
+		for _, tag := range list.Tags {
+			if strings.HasPrefix(tag, tagPrefix) {
+				objects = append(objects, StoredObject{Key: strings.ReplaceAll(tag, "_", "/")})
+			}
+		}
+		next = ""
+		if link := resp.Header.Get("Link"); link != "" {
+			target, _, _ := strings.Cut(strings.TrimPrefix(link, "<"), ">")
+			if u, err := url.Parse(s.baseURL); err == nil {
+				if ref, err := u.Parse(target); err == nil {
+					next = ref.String()
+				}
+			}
+		}
+	}
+	return objects, nil
+}
+
+// url returns the URL of an API path of the repository
+func (s *OCIStore) url(apiPath string) string {
+	return s.baseURL + "/v2/" + s.repository + "/" + apiPath
+}
+
+// ociError is the error document of the distribution API
+type ociError struct {
+	Errors []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// do sends a request, authenticating with basic auth or a bearer token
+// when the registry asks for it
+func (s *OCIStore) do(ctx context.Context, method, rawURL string, header http.Header, body []byte) (*http.Response, error) {
+	send := func(authorization string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, rawURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		return s.httpClient.Do(req)
+	}
+
+	s.mu.Lock()
+	authorization := s.authorization
+	s.mu.Unlock()
+	resp, err := send(authorization)
+	if err != nil {
+		return nil, fmt.Errorf("registry %s failed: %w", method, err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if authorization, err = s.authorize(ctx, challenge); err != nil {
+			return nil, err
+		}
+		if resp, err = send(authorization); err != nil {
+			return nil, fmt.Errorf("registry %s failed: %w", method, err)
+		}
+		if resp.StatusCode != http.StatusUnauthorized {
+			s.mu.Lock()
+			s.authorization = authorization
+			s.mu.Unlock()
+		}
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	reason := resp.Status
+	ociErr := ociError{}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if json.Unmarshal(data, &ociErr) == nil && len(ociErr.Errors) > 0 {
+		reason = ociErr.Errors[0].Code + ": " + ociErr.Errors[0].Message
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, reason)
+	}
+	return nil, fmt.Errorf("registry %s failed: %s", method, reason)
+}
+
+// authorize answers the WWW-Authenticate challenge of the registry with
+// basic credentials or a bearer token fetched from its token service
+func (s *OCIStore) authorize(ctx context.Context, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if s.config.Username == "" {
+			return "", fmt.Errorf("registry requires credentials, set username and passwordEnv")
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(s.config.Username+":"+s.password)), nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("registry denied access (%q)", challenge)
+	}
+
+	values := parseChallengeParams(params)
+	realm, err := url.Parse(values["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("registry sent an invalid token realm %q", values["realm"])
+	}
+	query := realm.Query()
+	for _, param := range []string{"service", "scope"} {
+		if values[param] != "" {
+			query.Set(param, values[param])
+		}
+	}
+	realm.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if s.config.Username != "" {
+		req.SetBasicAuth(s.config.Username, s.password)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get registry token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to get registry token: %s", resp.Status)
+	}
+	response := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return "", fmt.Errorf("failed to decode registry token: %w", err)
+	}
+	if response.Token == "" {
+		response.Token = response.AccessToken
+	}
+	return "Bearer " + response.Token, nil
+}
+
+// parseChallengeParams reads the key="value" pairs of a WWW-Authenticate
+// challenge
+func parseChallengeParams(params string) map[string]string {
+	values := map[string]string{}
+	for params != "" {
+		key, rest, ok := strings.Cut(strings.TrimLeft(params, " ,"), "=")
+		if !ok {
+			break
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		values[strings.ToLower(strings.TrimSpace(key))] = value
+		params = rest
+	}
+	return values
+}
+
+// ociDigest returns the sha256 digest of data
+func ociDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
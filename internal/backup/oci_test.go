@@ -0,0 +1,192 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRegistry serves the distribution API of acme/backups from memory,
+// behind a bearer token service and listing two tags per page
+type fakeRegistry struct {
+	mu        sync.Mutex
+	url       string
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	tags      map[string]string
+	tokens    int
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.URL.Path == "/token" {
+		if user, password, _ := r.BasicAuth(); user != "robot" || password != "s3cret" || r.URL.Query().Get("scope") != "repository:acme/backups:pull,push" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.tokens++
+		_, _ = io.WriteString(w, `{"token":"t0ken"}`)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer t0ken" {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:acme/backups:pull,push"`, f.url))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	apiPath, ok := strings.CutPrefix(r.URL.Path, "/v2/acme/backups/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	notFound := func(code string) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprintf(w, `{"errors":[{"code":%q,"message":"unknown"}]}`, code)
+	}
+	switch {
+	case r.Method == http.MethodPost && apiPath == "blobs/uploads/":
+		w.Header().Set("Location", "/v2/acme/backups/blobs/uploads/1?state=x")
+		w.WriteHeader(http.StatusAccepted)
+	case r.Method == http.MethodPut && strings.HasPrefix(apiPath, "blobs/uploads/"):
+		data, _ := io.ReadAll(r.Body)
+		if r.URL.Query().Get("state") != "x" || ociDigest(data) != r.URL.Query().Get("digest") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.blobs[ociDigest(data)] = data
+		w.WriteHeader(http.StatusCreated)
+	case strings.HasPrefix(apiPath, "blobs/"):
+		data, ok := f.blobs[strings.TrimPrefix(apiPath, "blobs/")]
+		if !ok {
+			notFound("BLOB_UNKNOWN")
+			return
+		}
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	case apiPath == "tags/list":
+		var tags []string
+		for tag := range f.tags {
+			if tag > r.URL.Query().Get("last") {
+				tags = append(tags, tag)
+			}
+		}
+		sort.Strings(tags)
+		if len(tags) > 2 {
+			tags = tags[:2]
+			w.Header().Set("Link", fmt.Sprintf(`</v2/acme/backups/tags/list?n=2&last=%s>; rel="next"`, tags[1]))
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "acme/backups", "tags": tags})
+	case strings.HasPrefix(apiPath, "manifests/"):
+		reference := strings.TrimPrefix(apiPath, "manifests/")
+		if r.Method == http.MethodPut {
+			data, _ := io.ReadAll(r.Body)
+			f.manifests[ociDigest(data)] = data
+			f.tags[reference] = ociDigest(data)
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		digest := reference
+		if d, ok := f.tags[reference]; ok {
+			digest = d
+		}
+		data, ok := f.manifests[digest]
+		if !ok {
+			notFound("MANIFEST_UNKNOWN")
+			return
+		}
+		switch r.Method {
+		case http.MethodDelete:
+			delete(f.manifests, digest)
+			for tag, d := range f.tags {
+				if d == digest {
+					delete(f.tags, tag)
+				}
+			}
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.Header().Set("Docker-Content-Digest", digest)
+			w.Header().Set("Content-Type", ociManifestMediaType)
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(data)
+			}
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestOCIStore(t *testing.T) {
+	fake := &fakeRegistry{blobs: map[string][]byte{}, manifests: map[string][]byte{}, tags: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	fake.url = server.URL
+	t.Setenv("REGISTRY_PASSWORD", "s3cret")
+	store, err := NewOCIStore(OCIConfig{
+		Repository:  strings.TrimPrefix(server.URL, "http://") + "/acme/backups",
+		Username:    "robot",
+		PasswordEnv: "REGISTRY_PASSWORD",
+		Insecure:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	objects, err := store.List(ctx, "org-acme/")
+	if err != nil || len(objects) != 0 {
+		t.Errorf("List() of an empty repository = %v, %v", objects, err)
+	}
+	keys := []string{"org-acme/prod/20261001T020000Z.yaml", "org-acme/prod/20261002T020000Z.yaml", "org-acme/prod/20261003T020000Z.yaml.age", "org-acme/dev/20261001T020000Z.json"}
+	for _, key := range keys {
+		if err := store.Put(ctx, key, []byte("bundle of "+key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fake.tokens != 1 {
+		t.Errorf("fetched %d tokens, want the token reused", fake.tokens)
+	}
+	if _, ok := fake.tags["org-acme_prod_20261003T020000Z.yaml.age"]; !ok {
+		t.Errorf("tags = %v, want keys with underscores", fake.tags)
+	}
+
+	objects, err = store.List(ctx, "org-acme/prod/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var listed []string
+	for _, object := range objects {
+		listed = append(listed, object.Key)
+	}
+	if strings.Join(listed, ",") != strings.Join(keys[:3], ",") {
+		t.Errorf("List() = %v, want %v", listed, keys[:3])
+	}
+
+	data, err := store.Get(ctx, keys[2])
+	if err != nil || string(data) != "bundle of "+keys[2] {
+		t.Errorf("Get() = %q, %v", data, err)
+	}
+	if err := store.Delete(ctx, keys[3]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, keys[3]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of a deleted artifact error = %v, want ErrNotFound", err)
+	}
+	if err := store.Put(ctx, "org-acme/prod/"+strings.Repeat("x", 130), nil); err == nil {
+		t.Error("Put() of a key longer than a tag should fail")
+	}
+
+	store.password = "wrong"
+	store.authorization = ""
+	if _, err := store.Get(ctx, keys[0]); err == nil {
+		t.Error("Get() with a wrong password should fail")
+	}
+}
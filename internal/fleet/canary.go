@@ -0,0 +1,431 @@
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"k8s.io/apimachinery/pkg/util/version"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// DefaultSoakDuration is how long a canary must stay healthy by default
+const DefaultSoakDuration = 30 * time.Minute
+
+// CanaryStage is the step a canary upgrade is at
+type CanaryStage string
+
+const (
+	// StageCanary upgrades the canary cluster or machine deployment
+	StageCanary CanaryStage = "canary"
+	// StageSoak watches the health of the canary cluster
+	StageSoak CanaryStage = "soak"
+	// StageRollout upgrades the remaining clusters
+	StageRollout CanaryStage = "rollout"
+	// StageDone means every cluster has been upgraded
+	StageDone CanaryStage = "done"
+)
+
+// CanaryClient is the part of the CAPI client used by a canary upgrade
+type CanaryClient interface {
+	Client
+	GetMachineDeployment(ctx context.Context, namespace, name string) (*clusterv1.MachineDeployment, error)
+	UpdateMachineDeployment(ctx context.Context, opts capi.UpdateMachineDeploymentOptions) (*clusterv1.MachineDeployment, error)
+	WaitForMachineDeploymentReady(ctx context.Context, namespace, name string, opts capi.WaitOptions) error
+}
+
+// Canary is the saved state of a canary upgrade
+type Canary struct {
+	ID      string         `json:"id"`
+	Options UpgradeOptions `json:"options"`
+	Canary  Target         `json:"canary"`
+	// MachineDeployment limits the canary to one machine deployment of the
+	// canary cluster, whose control plane must already run the target version
+	MachineDeployment string        `json:"machineDeployment,omitempty"`
+	Rest              []Target      `json:"rest"`
+	SoakDuration      time.Duration `json:"soakDuration"`
+	// ManagementCluster is the management cluster the upgrade runs against,
+	// empty for the default one
+	ManagementCluster string `json:"managementCluster,omitempty"`
+	Requester         string `json:"requester,omitempty"`
+
+	Stage         CanaryStage    `json:"stage"`
+	Error         string         `json:"error,omitempty"`
+	SoakStartedAt *time.Time     `json:"soakStartedAt,omitempty"`
+	CanaryResult  *ClusterResult `json:"canaryResult,omitempty"`
+	Rollout       *UpgradeResult `json:"rollout,omitempty"`
+	JobID         string         `json:"jobId,omitempty"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+}
+
+// Finished reports whether every cluster has been upgraded
+func (c *Canary) Finished() bool {
+	return c.Stage == StageDone
+}
+
+// Subject names the cluster or machine deployment upgraded first
+func (c *Canary) Subject() string {
+	if c.MachineDeployment != "" {
+		return fmt.Sprintf("machine deployment %s of %s", c.MachineDeployment, c.Canary)
+	}
+	return c.Canary.String()
+}
+
+// Failed reports whether the current stage failed; resuming retries it
+func (c *Canary) Failed() bool {
+	return c.Error != ""
+}
+
+// CanaryStore keeps the state of canary upgrades, optionally persisted to a
+// file so they can be resumed after a restart
+type CanaryStore struct {
+	mu       sync.Mutex
+	canaries []Canary
+	running  map[string]bool
+	filename string
+	seq      int
+}
+
+// NewCanaryStore creates a canary store. If filename is set, existing canary
+// upgrades are loaded from it and every modification is written back.
+func NewCanaryStore(filename string) (*CanaryStore, error) {
+	s := &CanaryStore{filename: filename, running: make(map[string]bool)}
+
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read canary state: %w", err)
+		default:
+			if err := json.Unmarshal(data, &s.canaries); err != nil {
+				return nil, fmt.Errorf("failed to parse canary state: %w", err)
+			}
+		}
+	}
+
+	for _, canary := range s.canaries {
+		var n int
+		if _, err := fmt.Sscanf(canary.ID, "canary-%d", &n); err == nil && n > s.seq {
+			s.seq = n
+		}
+	}
+	return s, nil
+}
+
+// Create saves a new canary upgrade at its first stage
+func (s *CanaryStore) Create(canary Canary) (*Canary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	now := time.Now()
+	canary.ID = fmt.Sprintf("canary-%d", s.seq)
+	canary.Stage = StageCanary
+	canary.CreatedAt = now
+	canary.UpdatedAt = now
+	s.canaries = append(s.canaries, canary)
+	return &canary, s.save()
+}
+
+// Get returns a canary upgrade by ID
+func (s *CanaryStore) Get(id string) (*Canary, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.canaries {
+		if s.canaries[i].ID == id {
+			canary := s.canaries[i]
+			return &canary, true
+		}
+	}
+	return nil, false
+}
+
+// List returns all canary upgrades, newest first
+func (s *CanaryStore) List() []Canary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	canaries := make([]Canary, 0, len(s.canaries))
+	for i := len(s.canaries) - 1; i >= 0; i-- {
+		canaries = append(canaries, s.canaries[i])
+	}
+	return canaries
+}
+
+// Running reports whether a canary upgrade is being run by this server
+func (s *CanaryStore) Running(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running[id]
+}
+
+// Update modifies a canary upgrade and saves it
+func (s *CanaryStore) Update(id string, fn func(*Canary)) (*Canary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.canaries {
+		if s.canaries[i].ID == id {
+			fn(&s.canaries[i])
+			s.canaries[i].UpdatedAt = time.Now()
+			canary := s.canaries[i]
+			return &canary, s.save()
+		}
+	}
+	return nil, fmt.Errorf("canary upgrade %s not found", id)
+}
+
+// claim marks a canary upgrade as running, failing if it already is
+func (s *CanaryStore) claim(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running[id] {
+		return fmt.Errorf("canary upgrade %s is already running", id)
+	}
+	s.running[id] = true
+	return nil
+}
+
+// release marks a canary upgrade as no longer running
+func (s *CanaryStore) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, id)
+}
+
+// save writes the canary upgrades to the file; the caller must hold the lock
+func (s *CanaryStore) save() error {
+	if s.filename == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.canaries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode canary state: %w", err)
+	}
+	if err := os.WriteFile(s.filename, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write canary state: %w", err)
+	}
+	return nil
+}
+
+// RunCanary drives a canary upgrade from its saved stage to the end, saving
+// each stage to store. A failed stage is retried. If ctx ends, the upgrade
+// keeps its stage and can be run again to resume it.
+func RunCanary(ctx context.Context, c CanaryClient, store *CanaryStore, id string, logf func(format string, args ...any)) (*Canary, error) {
+	if err := store.claim(id); err != nil {
+		return nil, err
+	}
+	defer store.release(id)
+
+	canary, ok := store.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("canary upgrade %s not found", id)
+	}
+	if canary.Finished() {
+		return canary, nil
+	}
+	target, err := version.ParseSemantic(canary.Options.TargetVersion)
+	if err != nil {
+		return canary, fmt.Errorf("invalid target version %q: %w", canary.Options.TargetVersion, err)
+	}
+	opts := canary.Options.withDefaults()
+
+	// fail records the error of the current stage; a soak restarts from
+	// scratch when resumed
+	fail := func(err error) (*Canary, error) {
+		if ctx.Err() != nil {
+			logf("Canary upgrade %s interrupted during the %s stage, resume it to continue", id, canary.Stage)
+			return canary, ctx.Err()
+		}
+		updated, saveErr := store.Update(id, func(c *Canary) {
+			c.Error = err.Error()
+			if c.Stage == StageSoak {
+				c.SoakStartedAt = nil
+			}
+		})
+		if saveErr != nil {
+			logf("Failed to save canary upgrade %s: %v", id, saveErr)
+			updated = canary
+		}
+		logf("Canary upgrade %s failed during the %s stage: %v", id, canary.Stage, err)
+		return updated, fmt.Errorf("canary upgrade failed during the %s stage: %w", canary.Stage, err)
+	}
+	advance := func(fn func(*Canary)) error {
+		updated, err := store.Update(id, func(c *Canary) {
+			c.Error = ""
+			fn(c)
+		})
+		if err != nil {
+			return err
+		}
+		canary = updated
+		return nil
+	}
+	if err := advance(func(*Canary) {}); err != nil {
+		return canary, err
+	}
+
+	if canary.Stage == StageCanary {
+		logf("Canary upgrade %s: upgrading canary %s to %s", id, canary.Subject(), opts.TargetVersion)
+		var result ClusterResult
+		if canary.MachineDeployment != "" {
+			result = upgradeCanaryMachineDeployment(ctx, c, canary, target, opts, logf)
+		} else {
+			result = upgradeCluster(ctx, c, canary.Canary, target, opts, logf)
+		}
+		if result.Outcome == OutcomeFailed {
+			return fail(errors.New(result.Message))
+		}
+		now := time.Now()
+		if err := advance(func(c *Canary) {
+			c.CanaryResult = &result
+			c.Stage = StageSoak
+			c.SoakStartedAt = &now
+		}); err != nil {
+			return canary, err
+		}
+	}
+
+	if canary.Stage == StageSoak {
+		if canary.SoakStartedAt == nil {
+			now := time.Now()
+			if err := advance(func(c *Canary) { c.SoakStartedAt = &now }); err != nil {
+				return canary, err
+			}
+		}
+		if err := soak(ctx, c, canary.Canary, *canary.SoakStartedAt, canary.SoakDuration, opts.PollInterval, logf); err != nil {
+			return fail(err)
+		}
+		if err := advance(func(c *Canary) { c.Stage = StageRollout }); err != nil {
+			return canary, err
+		}
+	}
+
+	if canary.Stage == StageRollout {
+		rest := canary.Rest
+		if canary.MachineDeployment != "" && opts.UpgradeWorkers {
+			// The other machine deployments of the canary cluster come first
+			rest = append([]Target{canary.Canary}, rest...)
+		}
+		result, err := Upgrade(ctx, c, rest, opts, logf)
+		if result != nil {
+			if saveErr := advance(func(c *Canary) { c.Rollout = result }); saveErr != nil {
+				logf("Failed to save canary upgrade %s: %v", id, saveErr)
+			}
+		}
+		if err != nil {
+			return fail(err)
+		}
+		if err := advance(func(c *Canary) { c.Stage = StageDone }); err != nil {
+			return canary, err
+		}
+	}
+
+	logf("Canary upgrade %s finished", id)
+	return canary, nil
+}
+
+// upgradeCanaryMachineDeployment upgrades one machine deployment of the
+// canary cluster and checks the health of the cluster afterwards
+func upgradeCanaryMachineDeployment(ctx context.Context, c CanaryClient, canary *Canary, target *version.Version, opts UpgradeOptions, logf func(format string, args ...any)) ClusterResult {
+	t := canary.Canary
+	result := ClusterResult{Target: t}
+	fail := func(format string, args ...any) ClusterResult {
+		result.Outcome = OutcomeFailed
+		result.Message = fmt.Sprintf(format, args...)
+		return result
+	}
+
+	status, err := c.GetClusterStatus(ctx, t.Namespace, t.Name)
+	if err != nil {
+		return fail("pre-check: %v", err)
+	}
+	result.FromVersion = status.Version
+	if skip, err := preCheck(status, target); err != nil || !skip {
+		return fail("pre-check: the control plane must run %s before one of its machine deployments is upgraded (runs %s)", opts.TargetVersion, status.Version)
+	}
+
+	md, err := c.GetMachineDeployment(ctx, t.Namespace, canary.MachineDeployment)
+	if err != nil {
+		return fail("pre-check: %v", err)
+	}
+	if md.Spec.ClusterName != t.Name {
+		return fail("pre-check: machine deployment %s belongs to cluster %s", md.Name, md.Spec.ClusterName)
+	}
+	if md.Spec.Template.Spec.Version != nil {
+		result.FromVersion = *md.Spec.Template.Spec.Version
+	}
+
+	if result.FromVersion != opts.TargetVersion {
+		logf("%s: upgrading machine deployment %s from %s to %s", t, md.Name, result.FromVersion, opts.TargetVersion)
+		_, err = c.UpdateMachineDeployment(ctx, capi.UpdateMachineDeploymentOptions{
+			Namespace: t.Namespace,
+			Name:      md.Name,
+			Version:   &opts.TargetVersion,
+		})
+		if err != nil {
+			return fail("upgrade: %v", err)
+		}
+	}
+
+	err = c.WaitForMachineDeploymentReady(ctx, t.Namespace, md.Name, capi.WaitOptions{
+		Timeout:         opts.ClusterTimeout,
+		PollInterval:    opts.PollInterval,
+		MaxPollInterval: opts.PollInterval,
+	})
+	if err != nil {
+		return fail("rollout: %v", err)
+	}
+
+	status, err = c.GetClusterStatus(ctx, t.Namespace, t.Name)
+	if err != nil {
+		return fail("health gate: %v", err)
+	}
+	if health := capi.ClusterHealth(status); health != capi.HealthHealthy {
+		return fail("health gate: cluster is %s after the upgrade (%d/%d machines with a node)", health, status.ReadyMachines, status.TotalMachines)
+	}
+
+	logf("%s: machine deployment %s upgraded to %s and healthy", t, md.Name, opts.TargetVersion)
+	result.Outcome = OutcomeSucceeded
+	return result
+}
+
+// soak checks the health of a cluster until duration has passed since start.
+// The first check that finds the cluster not healthy ends the soak.
+func soak(ctx context.Context, c Client, t Target, start time.Time, duration, interval time.Duration, logf func(format string, args ...any)) error {
+	end := start.Add(duration)
+	logf("%s: soaking until %s", t, end.Format(time.RFC3339))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status, err := c.GetClusterStatus(ctx, t.Namespace, t.Name)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			logf("%s: failed to check the health, retrying: %v", t, err)
+		case err == nil:
+			if health := capi.ClusterHealth(status); health != capi.HealthHealthy {
+				return fmt.Errorf("cluster became %s during the soak (%d/%d machines with a node)", health, status.ReadyMachines, status.TotalMachines)
+			}
+		}
+		if !time.Now().Before(end) {
+			logf("%s: healthy for the whole soak period", t)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
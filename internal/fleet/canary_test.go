@@ -0,0 +1,124 @@
+package fleet
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// fakeCanaryClient adds machine deployments to fakeClient
+type fakeCanaryClient struct {
+	*fakeClient
+	mds map[string]*clusterv1.MachineDeployment
+}
+
+func (f *fakeCanaryClient) GetMachineDeployment(ctx context.Context, namespace, name string) (*clusterv1.MachineDeployment, error) {
+	return f.mds[namespace+"/"+name], nil
+}
+
+func (f *fakeCanaryClient) UpdateMachineDeployment(ctx context.Context, opts capi.UpdateMachineDeploymentOptions) (*clusterv1.MachineDeployment, error) {
+	md := f.mds[opts.Namespace+"/"+opts.Name]
+	md.Spec.Template.Spec.Version = opts.Version
+	return md, nil
+}
+
+func (f *fakeCanaryClient) WaitForMachineDeploymentReady(ctx context.Context, namespace, name string, opts capi.WaitOptions) error {
+	return nil
+}
+
+func TestRunCanary(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "canaries.json")
+	store, err := NewCanaryStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeCanaryClient{fakeClient: &fakeClient{
+		versions: map[string]string{
+			"org-a/canary": "v1.29.4",
+			"org-a/one":    "v1.29.4",
+			"org-a/two":    "v1.29.4",
+		},
+		unhealthyAfter: map[string]bool{"org-a/canary": true},
+	}}
+	canary, err := store.Create(Canary{
+		Options:      UpgradeOptions{TargetVersion: "v1.30.0", UpgradeWorkers: true, PollInterval: time.Millisecond},
+		Canary:       Target{"org-a", "canary"},
+		Rest:         []Target{{"org-a", "one"}, {"org-a", "two"}},
+		SoakDuration: 5 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The canary fails its health gate and the rest is left alone
+	failed, err := RunCanary(context.Background(), client, store, canary.ID, t.Logf)
+	if err == nil || !failed.Failed() || failed.Stage != StageCanary {
+		t.Fatalf("RunCanary() = %+v, %v, want a failed canary stage", failed, err)
+	}
+	if len(client.upgraded) != 1 {
+		t.Fatalf("upgraded %v, want the canary only", client.upgraded)
+	}
+
+	// Once the canary is fixed, a store reloaded from the file resumes it
+	client.unhealthyAfter = nil
+	store, err = NewCanaryStore(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done, err := RunCanary(context.Background(), client, store, canary.ID, t.Logf)
+	if err != nil || !done.Finished() || done.Failed() {
+		t.Fatalf("RunCanary() = %+v, %v, want a finished upgrade", done, err)
+	}
+	if done.CanaryResult == nil || done.SoakStartedAt == nil || done.Rollout == nil || len(done.Rollout.Clusters) != 2 {
+		t.Errorf("RunCanary() did not record the stages: %+v", done)
+	}
+	if client.versions["org-a/two"] != "v1.30.0" {
+		t.Errorf("org-a/two runs %s, want v1.30.0", client.versions["org-a/two"])
+	}
+
+	if next, _ := store.Create(Canary{}); next.ID != "canary-2" {
+		t.Errorf("Create() ID = %s after reloading, want canary-2", next.ID)
+	}
+}
+
+func TestRunCanaryMachineDeployment(t *testing.T) {
+	store, err := NewCanaryStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldVersion := "v1.29.4"
+	md := &clusterv1.MachineDeployment{}
+	md.Name = "workers-a"
+	md.Spec.ClusterName = "canary"
+	md.Spec.Template.Spec.Version = &oldVersion
+	client := &fakeCanaryClient{
+		fakeClient: &fakeClient{versions: map[string]string{"org-a/canary": "v1.30.0", "org-a/one": "v1.29.4"}},
+		mds:        map[string]*clusterv1.MachineDeployment{"org-a/workers-a": md},
+	}
+
+	canary, err := store.Create(Canary{
+		Options:           UpgradeOptions{TargetVersion: "v1.30.0", UpgradeWorkers: true, PollInterval: time.Millisecond},
+		Canary:            Target{"org-a", "canary"},
+		MachineDeployment: "workers-a",
+		Rest:              []Target{{"org-a", "one"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := RunCanary(context.Background(), client, store, canary.ID, t.Logf)
+	if err != nil || !done.Finished() {
+		t.Fatalf("RunCanary() = %+v, %v, want a finished upgrade", done, err)
+	}
+	if *md.Spec.Template.Spec.Version != "v1.30.0" {
+		t.Errorf("machine deployment runs %s, want v1.30.0", *md.Spec.Template.Spec.Version)
+	}
+	// The canary cluster is rolled out first to upgrade its other machine deployments
+	if len(done.Rollout.Clusters) != 2 || done.Rollout.Clusters[0].Target != canary.Canary {
+		t.Errorf("rollout = %+v, want the canary cluster first", done.Rollout.Clusters)
+	}
+}
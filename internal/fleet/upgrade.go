@@ -0,0 +1,302 @@
+// Package fleet orchestrates operations across many clusters.
+//
+// A fleet upgrade moves a list of clusters to a Kubernetes version. Each
+// cluster is checked before it is touched, and must be healthy again after
+// its rollout before the next cluster starts. The first failure aborts the
+// upgrade: clusters already rolling out are followed to the end, but no new
+// cluster is started.
+//
+// A canary upgrade first upgrades one cluster, or one machine deployment,
+// and watches its health for a soak period before upgrading the rest. Its
+// progress is saved to a CanaryStore so it can be resumed after a restart.
+package fleet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+const (
+	// DefaultPollInterval is how often the rollout of a cluster is checked
+	DefaultPollInterval = 30 * time.Second
+	// DefaultClusterTimeout bounds the rollout of a single cluster
+	DefaultClusterTimeout = 3 * time.Hour
+)
+
+// Outcome is the state of a cluster in a fleet upgrade
+type Outcome string
+
+const (
+	// OutcomePending clusters have not been started yet
+	OutcomePending Outcome = "pending"
+	// OutcomeUpgrading clusters are rolling out
+	OutcomeUpgrading Outcome = "upgrading"
+	// OutcomeSucceeded clusters run the target version and are healthy
+	OutcomeSucceeded Outcome = "succeeded"
+	// OutcomeSkipped clusters already run the target version
+	OutcomeSkipped Outcome = "skipped"
+	// OutcomeFailed clusters failed a pre-check, the rollout or the health gate
+	OutcomeFailed Outcome = "failed"
+	// OutcomeNotStarted clusters were left alone after the upgrade aborted
+	OutcomeNotStarted Outcome = "not_started"
+)
+
+// Client is the part of the CAPI client used by a fleet upgrade
+type Client interface {
+	GetClusterStatus(ctx context.Context, namespace, name string) (*capi.ClusterStatus, error)
+	UpgradeCluster(ctx context.Context, opts capi.UpgradeClusterOptions) error
+	GetUpgradeProgress(ctx context.Context, namespace, name, targetVersion string) (*capi.UpgradeProgress, error)
+}
+
+// Target identifies a cluster to upgrade
+type Target struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+func (t Target) String() string {
+	return t.Namespace + "/" + t.Name
+}
+
+// UpgradeOptions configures a fleet upgrade
+type UpgradeOptions struct {
+	TargetVersion  string `json:"targetVersion"`
+	UpgradeWorkers bool   `json:"upgradeWorkers"`
+	// Concurrency is how many clusters roll out at the same time, default 1
+	Concurrency int `json:"concurrency,omitempty"`
+	// PollInterval and ClusterTimeout default to DefaultPollInterval and
+	// DefaultClusterTimeout
+	PollInterval   time.Duration `json:"pollInterval,omitempty"`
+	ClusterTimeout time.Duration `json:"clusterTimeout,omitempty"`
+}
+
+// withDefaults fills in the defaults of unset options
+func (o UpgradeOptions) withDefaults() UpgradeOptions {
+	if o.Concurrency <= 0 {
+		o.Concurrency = 1
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = DefaultPollInterval
+	}
+	if o.ClusterTimeout <= 0 {
+		o.ClusterTimeout = DefaultClusterTimeout
+	}
+	return o
+}
+
+// ClusterResult is the outcome of the upgrade of one cluster
+type ClusterResult struct {
+	Target
+	FromVersion string  `json:"fromVersion,omitempty"`
+	Outcome     Outcome `json:"outcome"`
+	Message     string  `json:"message,omitempty"`
+}
+
+// UpgradeResult is the outcome of a fleet upgrade
+type UpgradeResult struct {
+	TargetVersion string          `json:"targetVersion"`
+	Aborted       bool            `json:"aborted"`
+	Clusters      []ClusterResult `json:"clusters"`
+}
+
+// Counts returns the number of clusters per outcome
+func (r *UpgradeResult) Counts() map[Outcome]int {
+	counts := make(map[Outcome]int)
+	for _, cluster := range r.Clusters {
+		counts[cluster.Outcome]++
+	}
+	return counts
+}
+
+// Upgrade upgrades targets in order with at most opts.Concurrency clusters
+// rolling out at a time. It returns the outcome of every cluster, along with
+// an error if the upgrade aborted or ctx ended.
+func Upgrade(ctx context.Context, c Client, targets []Target, opts UpgradeOptions, logf func(format string, args ...any)) (*UpgradeResult, error) {
+	target, err := version.ParseSemantic(opts.TargetVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target version %q: %w", opts.TargetVersion, err)
+	}
+	opts = opts.withDefaults()
+
+	result := &UpgradeResult{TargetVersion: opts.TargetVersion, Clusters: make([]ClusterResult, len(targets))}
+	for i, t := range targets {
+		result.Clusters[i] = ClusterResult{Target: t, Outcome: OutcomePending}
+	}
+	logf("Upgrading %d clusters to %s, %d at a time", len(targets), opts.TargetVersion, opts.Concurrency)
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		aborted bool
+	)
+	slots := make(chan struct{}, opts.Concurrency)
+	for i, t := range targets {
+		// Wait for a free slot; a failure while waiting aborts the upgrade
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		mu.Lock()
+		stop := aborted || ctx.Err() != nil
+		if !stop {
+			result.Clusters[i].Outcome = OutcomeUpgrading
+		}
+		mu.Unlock()
+		if stop {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			cluster := upgradeCluster(ctx, c, t, target, opts, logf)
+			mu.Lock()
+			defer mu.Unlock()
+			result.Clusters[i] = cluster
+			if cluster.Outcome == OutcomeFailed && !aborted {
+				aborted = true
+				logf("Aborting the fleet upgrade: %s failed: %s", t, cluster.Message)
+			}
+		}(i, t)
+	}
+	wg.Wait()
+
+	for i := range result.Clusters {
+		if result.Clusters[i].Outcome == OutcomePending {
+			result.Clusters[i].Outcome = OutcomeNotStarted
+		}
+	}
+	result.Aborted = aborted
+
+	counts := result.Counts()
+	logf("Fleet upgrade finished: %d succeeded, %d skipped, %d failed, %d not started",
+		counts[OutcomeSucceeded], counts[OutcomeSkipped], counts[OutcomeFailed], counts[OutcomeNotStarted])
+	switch {
+	case ctx.Err() != nil:
+		return result, ctx.Err()
+	case aborted:
+		return result, fmt.Errorf("fleet upgrade aborted after %d failed clusters", counts[OutcomeFailed])
+	}
+	return result, nil
+}
+
+// upgradeCluster runs the pre-checks, the rollout and the health gate of one
+// cluster
+func upgradeCluster(ctx context.Context, c Client, t Target, target *version.Version, opts UpgradeOptions, logf func(format string, args ...any)) ClusterResult {
+	result := ClusterResult{Target: t}
+	fail := func(format string, args ...any) ClusterResult {
+		result.Outcome = OutcomeFailed
+		result.Message = fmt.Sprintf(format, args...)
+		return result
+	}
+
+	status, err := c.GetClusterStatus(ctx, t.Namespace, t.Name)
+	if err != nil {
+		return fail("pre-check: %v", err)
+	}
+	result.FromVersion = status.Version
+	skip, err := preCheck(status, target)
+	if err != nil {
+		return fail("pre-check: %v", err)
+	}
+	if skip {
+		// A cluster left mid-rollout, e.g. by a restarted server, is upgraded
+		// again to finish the rollout and pass the health gate
+		progress, err := c.GetUpgradeProgress(ctx, t.Namespace, t.Name, opts.TargetVersion)
+		if err == nil && progress.Done(opts.UpgradeWorkers) {
+			logf("%s already runs %s, skipping", t, status.Version)
+			result.Outcome = OutcomeSkipped
+			return result
+		}
+		logf("%s: already set to %s, completing its rollout", t, status.Version)
+	} else {
+		logf("%s: upgrading from %s to %s", t, status.Version, opts.TargetVersion)
+	}
+	err = c.UpgradeCluster(ctx, capi.UpgradeClusterOptions{
+		Namespace:      t.Namespace,
+		Name:           t.Name,
+		TargetVersion:  opts.TargetVersion,
+		UpgradeWorkers: opts.UpgradeWorkers,
+	})
+	if err != nil {
+		return fail("upgrade: %v", err)
+	}
+
+	if err := waitForRollout(ctx, c, t, opts, logf); err != nil {
+		return fail("rollout: %v", err)
+	}
+
+	// Health gate: the cluster must be ready with all machines running a node
+	// before the upgrade moves on
+	status, err = c.GetClusterStatus(ctx, t.Namespace, t.Name)
+	if err != nil {
+		return fail("health gate: %v", err)
+	}
+	if health := capi.ClusterHealth(status); health != capi.HealthHealthy {
+		return fail("health gate: cluster is %s after the upgrade (%d/%d machines with a node)", health, status.ReadyMachines, status.TotalMachines)
+	}
+
+	logf("%s: upgraded to %s and healthy", t, opts.TargetVersion)
+	result.Outcome = OutcomeSucceeded
+	return result
+}
+
+// preCheck verifies that a cluster can be upgraded to target. It reports
+// whether the cluster already runs the target version.
+func preCheck(status *capi.ClusterStatus, target *version.Version) (bool, error) {
+	if err := capi.CheckVersionSkew(status.Version, target); err != nil {
+		return false, err
+	}
+	if current := version.MustParseSemantic(status.Version); current.EqualTo(target) {
+		return true, nil
+	}
+	if health := capi.ClusterHealth(status); health != capi.HealthHealthy {
+		return false, fmt.Errorf("cluster is %s (%d/%d machines with a node)", health, status.ReadyMachines, status.TotalMachines)
+	}
+	return false, nil
+}
+
+// waitForRollout waits until the machines of a cluster run the target version
+func waitForRollout(ctx context.Context, c Client, t Target, opts UpgradeOptions, logf func(format string, args ...any)) error {
+	ctx, cancel := context.WithTimeout(ctx, opts.ClusterTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(opts.PollInterval)
+	defer ticker.Stop()
+
+	var last capi.UpgradeProgress
+	for {
+		progress, err := c.GetUpgradeProgress(ctx, t.Namespace, t.Name, opts.TargetVersion)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			logf("%s: failed to check the upgrade progress, retrying: %v", t, err)
+		case err == nil:
+			if *progress != last {
+				logf("%s: control plane machines upgraded: %d/%d, worker machines upgraded: %d/%d",
+					t, progress.ControlPlaneUpgraded, progress.ControlPlaneMachines,
+					progress.WorkersUpgraded, progress.WorkerMachines)
+				last = *progress
+			}
+			if progress.Done(opts.UpgradeWorkers) {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("did not complete within %s", opts.ClusterTimeout)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
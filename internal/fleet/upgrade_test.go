@@ -0,0 +1,110 @@
+package fleet
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// fakeClient upgrades clusters instantly. Clusters in unhealthyAfter come
+// back unhealthy from their upgrade.
+type fakeClient struct {
+	mu             sync.Mutex
+	versions       map[string]string
+	unhealthyAfter map[string]bool
+	upgraded       []string
+}
+
+func (f *fakeClient) GetClusterStatus(ctx context.Context, namespace, name string) (*capi.ClusterStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := namespace + "/" + name
+	status := &capi.ClusterStatus{Namespace: namespace, Name: name, Version: f.versions[key], Ready: true, TotalMachines: 3, ReadyMachines: 3}
+	for _, upgraded := range f.upgraded {
+		if upgraded == key && f.unhealthyAfter[key] {
+			status.ReadyMachines = 2
+		}
+	}
+	return status, nil
+}
+
+func (f *fakeClient) UpgradeCluster(ctx context.Context, opts capi.UpgradeClusterOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := opts.Namespace + "/" + opts.Name
+	f.versions[key] = opts.TargetVersion
+	f.upgraded = append(f.upgraded, key)
+	return nil
+}
+
+func (f *fakeClient) GetUpgradeProgress(ctx context.Context, namespace, name, targetVersion string) (*capi.UpgradeProgress, error) {
+	return &capi.UpgradeProgress{TargetVersion: targetVersion, ClusterReady: true, ControlPlaneMachines: 1, ControlPlaneUpgraded: 1}, nil
+}
+
+func TestUpgrade(t *testing.T) {
+	client := &fakeClient{
+		versions: map[string]string{
+			"org-a/one":   "v1.29.4",
+			"org-a/two":   "v1.30.0",
+			"org-a/three": "v1.29.4",
+			"org-a/four":  "v1.29.4",
+		},
+		unhealthyAfter: map[string]bool{"org-a/three": true},
+	}
+	targets := []Target{{"org-a", "one"}, {"org-a", "two"}, {"org-a", "three"}, {"org-a", "four"}}
+
+	result, err := Upgrade(context.Background(), client, targets, UpgradeOptions{
+		TargetVersion: "v1.30.0",
+		PollInterval:  time.Millisecond,
+	}, t.Logf)
+	if err == nil || !result.Aborted {
+		t.Fatalf("Upgrade() error = %v, aborted = %v, want an aborted upgrade", err, result.Aborted)
+	}
+
+	want := []Outcome{OutcomeSucceeded, OutcomeSkipped, OutcomeFailed, OutcomeNotStarted}
+	for i, cluster := range result.Clusters {
+		if cluster.Outcome != want[i] {
+			t.Errorf("%s: outcome = %s (%s), want %s", cluster.Target, cluster.Outcome, cluster.Message, want[i])
+		}
+	}
+	if !strings.HasPrefix(result.Clusters[2].Message, "health gate:") {
+		t.Errorf("failure message = %q, want the health gate", result.Clusters[2].Message)
+	}
+	if len(client.upgraded) != 2 {
+		t.Errorf("upgraded %v, want the clusters before the failure only", client.upgraded)
+	}
+}
+
+func TestPreCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   capi.ClusterStatus
+		wantSkip bool
+		wantErr  string
+	}{
+		{"next minor", capi.ClusterStatus{Version: "v1.29.4", Ready: true}, false, ""},
+		{"patch", capi.ClusterStatus{Version: "v1.30.0", Ready: true}, false, ""},
+		{"same version", capi.ClusterStatus{Version: "v1.30.1", Ready: true}, true, ""},
+		{"downgrade", capi.ClusterStatus{Version: "v1.31.0", Ready: true}, false, "downgrade"},
+		{"skipped minor", capi.ClusterStatus{Version: "v1.28.9", Ready: true}, false, "skip minor"},
+		{"not ready", capi.ClusterStatus{Version: "v1.29.4"}, false, "unhealthy"},
+		{"unknown version", capi.ClusterStatus{Ready: true}, false, "unknown"},
+	}
+	target := version.MustParseSemantic("v1.30.1")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skip, err := preCheck(&tt.status, target)
+			if skip != tt.wantSkip {
+				t.Errorf("preCheck() skip = %v, want %v", skip, tt.wantSkip)
+			}
+			if (err == nil) != (tt.wantErr == "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("preCheck() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+// Package jobs runs long-running operations in the background.
+//
+// A tool that starts a job returns its ID immediately. The job reports its
+// progress as log lines and ends with a result or an error, which callers
+// follow up on with the job tools. Finished jobs are kept for a retention
+// period so their outcome can still be inspected.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+// Finished reports whether a job in this state has ended
+func (s Status) Finished() bool {
+	return s != StatusRunning
+}
+
+// Func is the work of a job. It reports progress through logf, stops when ctx
+// is canceled and returns its result. A result returned along with an error
+// is kept as a partial result.
+type Func func(ctx context.Context, logf func(format string, args ...any)) (any, error)
+
+// LogEntry is a progress message of a job
+type LogEntry struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// Job is a background operation
+type Job struct {
+	ID        string `json:"id"`
+	Operation string `json:"operation"`
+	// Namespace and Target identify the resource the job works on
+	Namespace  string     `json:"namespace,omitempty"`
+	Target     string     `json:"target"`
+	Requester  string     `json:"requester,omitempty"`
+	Status     Status     `json:"status"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Result     any        `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	LogLines   int        `json:"logLines"`
+
+	logs   []LogEntry
+	cancel context.CancelFunc
+	// done is closed once the job has finished
+	done chan struct{}
+}
+
+// Config contains the settings for the job manager
+type Config struct {
+	// MaxRunning limits the jobs running at the same time
+	MaxRunning int
+	// Retention is how long finished jobs are kept
+	Retention time.Duration
+	// MaxLogLines limits the log lines kept per job; older lines are dropped
+	MaxLogLines int
+}
+
+// Manager starts and keeps track of jobs
+type Manager struct {
+	config Config
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+	// shuttingDown refuses new jobs
+	shuttingDown bool
+
+	// now is overridable for tests
+	now func() time.Time
+}
+
+// NewManager creates a new job manager
+func NewManager(config Config) *Manager {
+	if config.MaxRunning <= 0 {
+		config.MaxRunning = 10
+	}
+	if config.Retention <= 0 {
+		config.Retention = 24 * time.Hour
+	}
+	if config.MaxLogLines <= 0 {
+		config.MaxLogLines = 1000
+	}
+	return &Manager{
+		config: config,
+		jobs:   make(map[string]*Job),
+		now:    time.Now,
+	}
+}
+
+// Start runs fn in the background and returns the new job. The job is not
+// bound to ctx, which usually belongs to the tool call that started it; only
+// Cancel stops it.
+func (m *Manager) Start(ctx context.Context, operation, namespace, target, requester string, fn Func) (*Job, error) {
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate job ID: %w", err)
+	}
+
+	m.mu.Lock()
+	if m.shuttingDown {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("the server is shutting down and no longer starts jobs")
+	}
+	m.pruneLocked()
+	running := 0
+	for _, job := range m.jobs {
+		if !job.Status.Finished() {
+			running++
+		}
+	}
+	if running >= m.config.MaxRunning {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%d jobs are already running, wait for one to finish or cancel one", running)
+	}
+
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	job := &Job{
+		ID:        id,
+		Operation: operation,
+		Namespace: namespace,
+		Target:    target,
+		Requester: requester,
+		Status:    StatusRunning,
+		CreatedAt: m.now(),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	m.jobs[id] = job
+	copied := job.snapshot()
+	m.mu.Unlock()
+
+	go m.run(jobCtx, job, fn)
+	return &copied, nil
+}
+
+// run executes fn and records its outcome
+func (m *Manager) run(ctx context.Context, job *Job, fn Func) {
+	logf := func(format string, args ...any) {
+		m.logf(job, format, args...)
+	}
+
+	result, err := func() (result any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("job panicked: %v", r)
+			}
+		}()
+		return fn(ctx, logf)
+	}()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	job.FinishedAt = &now
+	if result != nil {
+		job.Result = result
+	}
+	switch {
+	case ctx.Err() != nil:
+		job.Status = StatusCanceled
+		if err != nil {
+			job.Error = err.Error()
+		}
+	case err != nil:
+		job.Status = StatusFailed
+		job.Error = err.Error()
+	default:
+		job.Status = StatusSucceeded
+	}
+	job.cancel()
+	close(job.done)
+}
+
+// logf appends a progress message to the log of a job
+func (m *Manager) logf(job *Job, format string, args ...any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job.logs = append(job.logs, LogEntry{Time: m.now(), Message: fmt.Sprintf(format, args...)})
+	job.LogLines++
+	if len(job.logs) > m.config.MaxLogLines {
+		job.logs = job.logs[len(job.logs)-m.config.MaxLogLines:]
+	}
+}
+
+// Get returns a copy of the job with the given ID
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	copied := job.snapshot()
+	return &copied, nil
+}
+
+// Logs returns the log lines of a job starting at line offset, counted from
+// the first line the job ever logged. Lines dropped for exceeding
+// MaxLogLines are skipped.
+func (m *Manager) Logs(id string, offset int) ([]LogEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	// Index of the first kept line
+	first := job.LogLines - len(job.logs)
+	start := max(offset-first, 0)
+	if start >= len(job.logs) {
+		return []LogEntry{}, nil
+	}
+	return append([]LogEntry(nil), job.logs[start:]...), nil
+}
+
+// Cancel stops a running job and returns it once it has finished or ctx ends
+func (m *Manager) Cancel(ctx context.Context, id string) (*Job, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	if job.Status.Finished() {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("job %s has already %s", id, job.Status)
+	}
+	job.cancel()
+	m.mu.Unlock()
+
+	return m.Wait(ctx, id)
+}
+
+// Wait blocks until a job has finished or ctx ends, and returns the job
+func (m *Manager) Wait(ctx context.Context, id string) (*Job, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+
+	select {
+	case <-job.done:
+	case <-ctx.Done():
+	}
+	return m.Get(id)
+}
+
+// Shutdown refuses new jobs and waits until the running jobs have finished
+// or ctx ends. Jobs still running then are canceled, so they stop at the
+// next point where their work can be interrupted, and are returned.
+func (m *Manager) Shutdown(ctx context.Context) []Job {
+	m.mu.Lock()
+	m.shuttingDown = true
+	var running []*Job
+	for _, job := range m.jobs {
+		if !job.Status.Finished() {
+			running = append(running, job)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, job := range running {
+		select {
+		case <-job.done:
+		case <-ctx.Done():
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var interrupted []Job
+	for _, job := range running {
+		if !job.Status.Finished() {
+			job.cancel()
+			interrupted = append(interrupted, job.snapshot())
+		}
+	}
+	return interrupted
+}
+
+// List returns copies of all known jobs, newest first
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pruneLocked()
+	result := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		result = append(result, job.snapshot())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result
+}
+
+// pruneLocked forgets jobs that finished longer than the retention ago
+func (m *Manager) pruneLocked() {
+	cutoff := m.now().Add(-m.config.Retention)
+	for id, job := range m.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// snapshot copies the exported state of a job; m.mu must be held
+func (j *Job) snapshot() Job {
+	return Job{
+		ID:         j.ID,
+		Operation:  j.Operation,
+		Namespace:  j.Namespace,
+		Target:     j.Target,
+		Requester:  j.Requester,
+		Status:     j.Status,
+		CreatedAt:  j.CreatedAt,
+		FinishedAt: j.FinishedAt,
+		Result:     j.Result,
+		Error:      j.Error,
+		LogLines:   j.LogLines,
+	}
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJobSucceeds(t *testing.T) {
+	mgr := NewManager(Config{})
+	job, err := mgr.Start(context.Background(), "upgrade", "org-acme", "org-acme/prod", "assistant",
+		func(ctx context.Context, logf func(string, ...any)) (any, error) {
+			logf("step %d", 1)
+			logf("step %d", 2)
+			return map[string]any{"version": "v1.30.0"}, nil
+		})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if job.Status != StatusRunning {
+		t.Errorf("Start() status = %s, want %s", job.Status, StatusRunning)
+	}
+
+	finished, err := mgr.Wait(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if finished.Status != StatusSucceeded || finished.Result == nil || finished.FinishedAt == nil {
+		t.Errorf("unexpected job: %+v", finished)
+	}
+
+	logs, err := mgr.Logs(job.ID, 1)
+	if err != nil {
+		t.Fatalf("Logs() error = %v", err)
+	}
+	if len(logs) != 1 || logs[0].Message != "step 2" {
+		t.Errorf("Logs(offset 1) = %+v", logs)
+	}
+	if _, err := mgr.Cancel(context.Background(), job.ID); err == nil {
+		t.Error("Cancel() of a finished job should fail")
+	}
+}
+
+func TestJobFails(t *testing.T) {
+	mgr := NewManager(Config{})
+	job, err := mgr.Start(context.Background(), "drain", "", "node-1", "",
+		func(ctx context.Context, logf func(string, ...any)) (any, error) {
+			return map[string]int{"evicted": 3}, errors.New("eviction blocked")
+		})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	finished, _ := mgr.Wait(context.Background(), job.ID)
+	if finished.Status != StatusFailed || finished.Error != "eviction blocked" {
+		t.Errorf("unexpected job: %+v", finished)
+	}
+	if partial, ok := finished.Result.(map[string]int); !ok || partial["evicted"] != 3 {
+		t.Errorf("partial result = %v, want it kept with the error", finished.Result)
+	}
+}
+
+func TestJobCancel(t *testing.T) {
+	mgr := NewManager(Config{MaxRunning: 1})
+
+	// The job must outlive the tool call that started it
+	callCtx, endCall := context.WithCancel(context.Background())
+	job, err := mgr.Start(callCtx, "upgrade", "org-acme", "org-acme/prod", "",
+		func(ctx context.Context, logf func(string, ...any)) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	endCall()
+
+	if _, err := mgr.Start(context.Background(), "upgrade", "", "other", "", nil); err == nil {
+		t.Error("Start() beyond MaxRunning should fail")
+	}
+	if current, _ := mgr.Get(job.ID); current.Status != StatusRunning {
+		t.Fatalf("job stopped with the tool call: %+v", current)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	canceled, err := mgr.Cancel(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if canceled.Status != StatusCanceled {
+		t.Errorf("Cancel() status = %s, want %s", canceled.Status, StatusCanceled)
+	}
+}
+
+func TestLogsAndRetention(t *testing.T) {
+	mgr := NewManager(Config{MaxLogLines: 2, Retention: time.Hour})
+	job, _ := mgr.Start(context.Background(), "upgrade", "", "prod", "",
+		func(ctx context.Context, logf func(string, ...any)) (any, error) {
+			for i := range 5 {
+				logf("line %d", i)
+			}
+			return nil, nil
+		})
+	finished, _ := mgr.Wait(context.Background(), job.ID)
+	if finished.LogLines != 5 {
+		t.Errorf("LogLines = %d, want 5", finished.LogLines)
+	}
+
+	logs, _ := mgr.Logs(job.ID, 0)
+	if len(logs) != 2 || logs[0].Message != "line 3" {
+		t.Errorf("Logs() should keep the newest lines, got %+v", logs)
+	}
+	if logs, _ := mgr.Logs(job.ID, 10); len(logs) != 0 {
+		t.Errorf("Logs() past the end = %+v", logs)
+	}
+
+	mgr.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if jobs := mgr.List(); len(jobs) != 0 {
+		t.Errorf("List() should drop expired jobs, got %d", len(jobs))
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	mgr := NewManager(Config{})
+	release := make(chan struct{})
+	quick, _ := mgr.Start(context.Background(), "scale", "", "quick", "",
+		func(ctx context.Context, logf func(string, ...any)) (any, error) {
+			<-release
+			return nil, nil
+		})
+	stuck, _ := mgr.Start(context.Background(), "upgrade", "", "stuck", "",
+		func(ctx context.Context, logf func(string, ...any)) (any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	interrupted := mgr.Shutdown(ctx)
+	if len(interrupted) != 1 || interrupted[0].ID != stuck.ID {
+		t.Errorf("Shutdown() interrupted %+v, want only the stuck job", interrupted)
+	}
+	if job, _ := mgr.Get(quick.ID); job.Status != StatusSucceeded {
+		t.Errorf("quick job = %s, want it finished before shutdown", job.Status)
+	}
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer waitCancel()
+	if job, _ := mgr.Wait(waitCtx, stuck.ID); job.Status != StatusCanceled {
+		t.Errorf("stuck job = %s, want it canceled", job.Status)
+	}
+	if _, err := mgr.Start(context.Background(), "scale", "", "late", "", nil); err == nil {
+		t.Error("Start() during shutdown should fail")
+	}
+}
@@ -0,0 +1,242 @@
+// Package locks serializes operations changing the same cluster, so two
+// tool calls such as a scale and an upgrade do not race each other.
+//
+// Clusters are locked within the server process. With a lease duration, the
+// server also takes an operation lease in the namespace of each cluster, so
+// replicas sharing a management cluster exclude each other as well.
+// Conflicting operations are rejected with an "operation in progress" error,
+// or wait for the lock up to a timeout.
+package locks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+)
+
+// Modes of handling an operation on a locked cluster
+const (
+	// ModeReject fails the operation at once
+	ModeReject = "reject"
+	// ModeWait waits for the lock up to the wait timeout
+	ModeWait = "wait"
+)
+
+// DefaultWaitTimeout bounds how long ModeWait waits for a lock
+const DefaultWaitTimeout = 5 * time.Minute
+
+// leasePollInterval is how often ModeWait retries a lease held elsewhere
+const leasePollInterval = 2 * time.Second
+
+// Config configures how operations on the same cluster are serialized
+type Config struct {
+	// Mode is ModeReject or ModeWait, ModeReject when empty
+	Mode string
+	// WaitTimeout bounds the wait of ModeWait, DefaultWaitTimeout when zero
+	WaitTimeout time.Duration
+	// LeaseDuration enables operation leases in the management cluster,
+	// expiring after this duration when their holder stops renewing them
+	LeaseDuration time.Duration
+	// Identity is the lease holder, the host name and process ID when empty
+	Identity string
+}
+
+// Target is a cluster an operation changes
+type Target struct {
+	ManagementCluster string
+	Namespace         string
+	Name              string
+}
+
+func (t Target) String() string {
+	if t.ManagementCluster != "" {
+		return t.ManagementCluster + ":" + t.Namespace + "/" + t.Name
+	}
+	return t.Namespace + "/" + t.Name
+}
+
+// Lock describes an operation holding the lock of a cluster
+type Lock struct {
+	Target    Target    `json:"target"`
+	Operation string    `json:"operation"`
+	Since     time.Time `json:"since"`
+}
+
+// held is a lock taken within the process
+type held struct {
+	Lock
+	// released is closed when the lock is released
+	released chan struct{}
+}
+
+// Manager hands out the locks of clusters
+type Manager struct {
+	config Config
+
+	mu    sync.Mutex
+	locks map[Target]*held
+}
+
+// NewManager validates config and creates a manager without locks
+func NewManager(config Config) (*Manager, error) {
+	switch config.Mode {
+	case "":
+		config.Mode = ModeReject
+	case ModeReject, ModeWait:
+	default:
+		return nil, fmt.Errorf("invalid lock mode %q, use %s or %s", config.Mode, ModeReject, ModeWait)
+	}
+	if config.WaitTimeout < 0 || config.LeaseDuration < 0 {
+		return nil, fmt.Errorf("lock timeouts must not be negative")
+	}
+	if config.WaitTimeout == 0 {
+		config.WaitTimeout = DefaultWaitTimeout
+	}
+	if config.LeaseDuration > 0 && config.LeaseDuration < time.Second {
+		return nil, fmt.Errorf("lease duration must be at least 1s")
+	}
+	if config.Identity == "" {
+		hostname, _ := os.Hostname()
+		config.Identity = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	return &Manager{config: config, locks: map[Target]*held{}}, nil
+}
+
+// Acquire locks the targets for operation, in a fixed order so operations
+// on overlapping clusters cannot deadlock. Leases are taken through c when
+// enabled. The returned function releases all locks. A target locked by
+// another operation fails with an error matching capi.ErrOperationInProgress.
+func (m *Manager) Acquire(ctx context.Context, c *capi.Client, targets []Target, operation string) (func(), error) {
+	targets = uniqueTargets(targets)
+	if m.config.Mode == ModeWait {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.config.WaitTimeout)
+		defer cancel()
+	}
+
+	var releases []func()
+	release := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+	for _, target := range targets {
+		if err := m.lock(ctx, target, operation); err != nil {
+			release()
+			return nil, err
+		}
+		releases = append(releases, func() { m.unlock(target) })
+
+		if m.config.LeaseDuration == 0 || c == nil {
+			continue
+		}
+		lease, err := m.acquireLease(ctx, c, target, operation)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		releases = append(releases, func() {
+			// The operation's context may have ended, release regardless
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := lease.Release(ctx); err != nil {
+				slog.Warn("Failed to release operation lease", "cluster", target.String(), "error", err)
+			}
+		})
+	}
+	return release, nil
+}
+
+// lock takes the in-process lock of target, waiting for it in ModeWait
+func (m *Manager) lock(ctx context.Context, target Target, operation string) error {
+	for {
+		m.mu.Lock()
+		current, ok := m.locks[target]
+		if !ok {
+			m.locks[target] = &held{
+				Lock:     Lock{Target: target, Operation: operation, Since: time.Now()},
+				released: make(chan struct{}),
+			}
+			m.mu.Unlock()
+			return nil
+		}
+		m.mu.Unlock()
+
+		if m.config.Mode == ModeReject {
+			return inProgress(current.Lock, nil)
+		}
+		select {
+		case <-current.released:
+		case <-ctx.Done():
+			return inProgress(current.Lock, ctx.Err())
+		}
+	}
+}
+
+// unlock releases the in-process lock of target
+func (m *Manager) unlock(target Target) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if current, ok := m.locks[target]; ok {
+		delete(m.locks, target)
+		close(current.released)
+	}
+}
+
+// acquireLease takes the operation lease of target, polling for it in ModeWait
+func (m *Manager) acquireLease(ctx context.Context, c *capi.Client, target Target, operation string) (*capi.OperationLease, error) {
+	for {
+		lease, err := c.AcquireOperationLease(ctx, target.Namespace, target.Name, m.config.Identity, operation, m.config.LeaseDuration)
+		if err == nil || m.config.Mode == ModeReject || !errors.Is(err, capi.ErrOperationInProgress) {
+			return lease, err
+		}
+		select {
+		case <-time.After(leasePollInterval):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w (gave up waiting: %w)", err, ctx.Err())
+		}
+	}
+}
+
+// Locks returns the locks held within the process, oldest first
+func (m *Manager) Locks() []Lock {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	locks := make([]Lock, 0, len(m.locks))
+	for _, current := range m.locks {
+		locks = append(locks, current.Lock)
+	}
+	sort.Slice(locks, func(i, j int) bool { return locks[i].Since.Before(locks[j].Since) })
+	return locks
+}
+
+// inProgress reports the operation holding a lock. waitErr tells why
+// waiting for it ended.
+func inProgress(lock Lock, waitErr error) error {
+	err := fmt.Errorf("%w on cluster %s: %s since %s", capi.ErrOperationInProgress, lock.Target, lock.Operation, lock.Since.UTC().Format(time.RFC3339))
+	if waitErr != nil {
+		return fmt.Errorf("%w (gave up waiting: %w)", err, waitErr)
+	}
+	return err
+}
+
+// uniqueTargets sorts targets and drops duplicates
+func uniqueTargets(targets []Target) []Target {
+	sorted := make([]Target, 0, len(targets))
+	seen := map[Target]bool{}
+	for _, target := range targets {
+		if !seen[target] {
+			seen[target] = true
+			sorted = append(sorted, target)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].String() < sorted[j].String() })
+	return sorted
+}
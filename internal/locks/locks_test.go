@@ -0,0 +1,84 @@
+package locks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+)
+
+func TestReject(t *testing.T) {
+	m, err := NewManager(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prod := Target{Namespace: "org-acme", Name: "prod"}
+	release, err := m.Acquire(context.Background(), nil, []Target{prod}, "capi_scale_cluster by jane")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = m.Acquire(context.Background(), nil, []Target{{Namespace: "org-acme", Name: "staging"}, prod}, "capi_fleet_upgrade by joe")
+	if !errors.Is(err, capi.ErrOperationInProgress) {
+		t.Fatalf("Acquire() error = %v, want ErrOperationInProgress", err)
+	}
+	// The lock of staging taken before the conflict was released
+	if locks := m.Locks(); len(locks) != 1 || locks[0].Target != prod {
+		t.Errorf("Locks() = %+v, want only the lock of prod", locks)
+	}
+
+	release()
+	if _, err := m.Acquire(context.Background(), nil, []Target{prod}, "capi_upgrade_cluster by joe"); err != nil {
+		t.Errorf("Acquire() after release error = %v", err)
+	}
+}
+
+func TestWait(t *testing.T) {
+	m, err := NewManager(Config{Mode: ModeWait, WaitTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prod := Target{Namespace: "org-acme", Name: "prod"}
+	release, err := m.Acquire(context.Background(), nil, []Target{prod}, "capi_scale_cluster by jane")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The lock is not released within the wait timeout
+	if _, err := m.Acquire(context.Background(), nil, []Target{prod}, "capi_upgrade_cluster by joe"); !errors.Is(err, capi.ErrOperationInProgress) {
+		t.Fatalf("Acquire() error = %v, want ErrOperationInProgress", err)
+	}
+
+	// The lock is released while waiting
+	acquired := make(chan error, 1)
+	go func() {
+		release, err := m.Acquire(context.Background(), nil, []Target{prod}, "capi_upgrade_cluster by joe")
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	release()
+	if err := <-acquired; err != nil {
+		t.Errorf("waiting Acquire() error = %v", err)
+	}
+}
+
+func TestNewManager(t *testing.T) {
+	if _, err := NewManager(Config{Mode: "queue"}); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+	if _, err := NewManager(Config{LeaseDuration: time.Millisecond}); err == nil {
+		t.Error("expected an error for a lease duration below 1s")
+	}
+	m, err := NewManager(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.config.Mode != ModeReject || m.config.WaitTimeout != DefaultWaitTimeout || m.config.Identity == "" {
+		t.Errorf("defaults not applied: %+v", m.config)
+	}
+}
@@ -0,0 +1,173 @@
+// Package logging configures the structured logs of the server.
+//
+// Logs are written with log/slog as text or JSON at a configurable level.
+// Every record passes through the redaction of tool output, so kubeconfigs,
+// Secret data and tokens that end up in a message or an attribute are
+// replaced before they are written. Tool calls carry request-scoped
+// attributes, such as the tool and the cluster, in their context; records
+// logged with that context include them.
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/redact"
+)
+
+// Format is the encoding of log records
+type Format string
+
+const (
+	// FormatText writes key=value records
+	FormatText Format = "text"
+	// FormatJSON writes a JSON object per record
+	FormatJSON Format = "json"
+)
+
+// Config contains the settings of the logger
+type Config struct {
+	Level  slog.Level
+	Format Format
+}
+
+// ParseLevel parses a level name: debug, info, warn (or warning) and error
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q, use debug, info, warn or error", name)
+	}
+}
+
+// ParseFormat parses a format name, text by default
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case "":
+		return FormatText, nil
+	case FormatText, FormatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q, use %s or %s", name, FormatText, FormatJSON)
+	}
+}
+
+// New creates a logger writing redacted records to w
+func New(w io.Writer, config Config) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: config.Level}
+	var handler slog.Handler = slog.NewTextHandler(w, opts)
+	if config.Format == FormatJSON {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(&redactingHandler{next: handler})
+}
+
+// contextKey is the key of the request-scoped attributes in a context
+type contextKey struct{}
+
+// With returns a context whose log records carry attrs in addition to those
+// of ctx
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing, _ := ctx.Value(contextKey{}).([]slog.Attr)
+	combined := make([]slog.Attr, 0, len(existing)+len(attrs))
+	combined = append(append(combined, existing...), attrs...)
+	return context.WithValue(ctx, contextKey{}, combined)
+}
+
+// redactingHandler adds the request-scoped attributes of the context to
+// records and scrubs credentials from their message and attributes
+type redactingHandler struct {
+	next slog.Handler
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, redact.String(record.Message), record.PC)
+	if attrs, ok := ctx.Value(contextKey{}).([]slog.Attr); ok {
+		for _, attr := range attrs {
+			redacted.AddAttrs(redactAttr(attr))
+		}
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = redactAttr(attr)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr scrubs the value of an attribute: the values of secret keys
+// are replaced, strings, errors and other values scrubbed like tool output
+func redactAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch {
+	case value.Kind() == slog.KindGroup:
+		group := value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, member := range group {
+			redacted[i] = redactAttr(member)
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+	case redact.IsSecretKey(attr.Key) && !isEmpty(value):
+		return slog.String(attr.Key, redact.Placeholder)
+	case value.Kind() == slog.KindString:
+		return slog.String(attr.Key, redact.String(value.String()))
+	case value.Kind() == slog.KindAny:
+		return slog.Any(attr.Key, redactValue(value.Any()))
+	default:
+		return slog.Attr{Key: attr.Key, Value: value}
+	}
+}
+
+// redactValue scrubs an arbitrary value through its JSON form, so the
+// secret keys of maps and structs are found. Values that do not encode as
+// JSON are scrubbed as text.
+func redactValue(v any) any {
+	switch value := v.(type) {
+	case error:
+		return redact.String(value.Error())
+	case fmt.Stringer:
+		return redact.String(value.String())
+	case []byte:
+		return redact.String(string(value))
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return redact.String(fmt.Sprint(v))
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return redact.String(fmt.Sprint(v))
+	}
+	return redact.Value(decoded)
+}
+
+// isEmpty reports whether a value carries nothing to redact
+func isEmpty(value slog.Value) bool {
+	return value.Kind() == slog.KindString && value.String() == ""
+}
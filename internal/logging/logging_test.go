@@ -0,0 +1,63 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+users:
+- name: admin
+  user:
+    client-key-data: c2VjcmV0LWtleQ==
+    token: abc123`
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, Config{Level: slog.LevelInfo, Format: FormatJSON})
+	ctx := With(context.Background(), slog.String("tool", "capi_get_kubeconfig"), slog.String("cluster", "org-acme/prod"))
+
+	logger.DebugContext(ctx, "hidden")
+	logger.With("password", "hunter2").InfoContext(ctx, "fetched kubeconfig\n"+testKubeconfig,
+		"error", errors.New("login failed: Authorization: Bearer abcdef"),
+		"secret", map[string]any{"data": map[string]any{"token": "xyz"}},
+		slog.Group("request", slog.String("client_secret", "s3cr3t")),
+	)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("log output %q: %v", buf.String(), err)
+	}
+	if record["tool"] != "capi_get_kubeconfig" || record["cluster"] != "org-acme/prod" {
+		t.Errorf("record = %v, want the request-scoped attributes", record)
+	}
+	for _, leaked := range []string{"c2VjcmV0LWtleQ==", "abc123", "hunter2", "abcdef", "xyz", "s3cr3t", "hidden"} {
+		if strings.Contains(buf.String(), leaked) {
+			t.Errorf("log output contains %q: %s", leaked, buf.String())
+		}
+	}
+	if !strings.Contains(buf.String(), "fetched kubeconfig") {
+		t.Errorf("log output = %s, want the message", buf.String())
+	}
+}
+
+func TestParse(t *testing.T) {
+	if level, err := ParseLevel("WARNING"); err != nil || level != slog.LevelWarn {
+		t.Errorf("ParseLevel(WARNING) = %v, %v", level, err)
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel(verbose) should fail")
+	}
+	if format, err := ParseFormat(""); err != nil || format != FormatText {
+		t.Errorf("ParseFormat() = %v, %v, want text", format, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(xml) should fail")
+	}
+}
@@ -0,0 +1,251 @@
+// Package maintenance restricts changes to clusters to their maintenance
+// windows.
+//
+// A window is a cron expression for when it opens, how long it stays open
+// and an optional time zone, e.g. "0 22 * * 1-5 4h Europe/Berlin" for
+// weekday nights from 22:00 to 02:00 Berlin time. Windows are set per
+// cluster with the WindowAnnotation or by the rules of a config file
+// matching clusters by namespace, name and labels. Clusters without a window
+// may be changed at any time.
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/schedule"
+	"k8s.io/apimachinery/pkg/labels"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+// WindowAnnotation sets the maintenance windows of a Cluster, separated by
+// semicolons or newlines. It takes precedence over the config file.
+const WindowAnnotation = "mcp-capi.giantswarm.io/maintenance-window"
+
+// Window is a recurring period in which a cluster may be changed
+type Window struct {
+	// Cron is when the window opens
+	Cron     string
+	Duration time.Duration
+	// TimeZone is the IANA time zone of Cron, UTC when empty
+	TimeZone string
+
+	cron     *schedule.Cron
+	location *time.Location
+}
+
+// ParseWindow parses a window written as a cron expression, a duration and
+// an optional time zone, e.g. "0 22 * * 1-5 4h Europe/Berlin" or
+// "@daily 2h"
+func ParseWindow(text string) (Window, error) {
+	fields := strings.Fields(text)
+	cronFields := 5
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+		cronFields = 1
+	}
+	if len(fields) != cronFields+1 && len(fields) != cronFields+2 {
+		return Window{}, fmt.Errorf("invalid maintenance window %q: want a cron expression, a duration and an optional time zone, e.g. '0 22 * * 1-5 4h Europe/Berlin'", text)
+	}
+
+	window := Window{Cron: strings.Join(fields[:cronFields], " ")}
+	cron, err := schedule.ParseCron(window.Cron)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid maintenance window %q: %w", text, err)
+	}
+	window.cron = cron
+	window.Duration, err = time.ParseDuration(fields[cronFields])
+	if err != nil || window.Duration <= 0 {
+		return Window{}, fmt.Errorf("invalid maintenance window %q: invalid duration %q", text, fields[cronFields])
+	}
+	if len(fields) > cronFields+1 {
+		window.TimeZone = fields[cronFields+1]
+	}
+	window.location, err = time.LoadLocation(window.TimeZone)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid maintenance window %q: invalid time zone %q", text, window.TimeZone)
+	}
+	return window, nil
+}
+
+// ParseWindows parses windows separated by semicolons or newlines
+func ParseWindows(text string) ([]Window, error) {
+	var windows []Window
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool { return r == ';' || r == '\n' }) {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		window, err := ParseWindow(part)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// String returns the window as it is written
+func (w Window) String() string {
+	text := w.Cron + " " + w.Duration.String()
+	if w.TimeZone != "" {
+		text += " " + w.TimeZone
+	}
+	return text
+}
+
+// Contains reports whether the window is open at t
+func (w Window) Contains(t time.Time) bool {
+	// The first opening after t-duration is the only one that can still
+	// be open at t
+	start := w.cron.Next(t.Add(-w.Duration).In(w.location))
+	return !start.IsZero() && !start.After(t)
+}
+
+// Next returns when the window opens next after t
+func (w Window) Next(t time.Time) time.Time {
+	return w.cron.Next(t.In(w.location))
+}
+
+// Rule sets the maintenance windows of the clusters it matches. Empty
+// fields match every cluster.
+type Rule struct {
+	Namespace     string   `json:"namespace,omitempty"`
+	Cluster       string   `json:"cluster,omitempty"`
+	LabelSelector string   `json:"labelSelector,omitempty"`
+	Windows       []string `json:"windows"`
+
+	selector labels.Selector
+	windows  []Window
+}
+
+// matches reports whether a rule applies to a cluster
+func (r *Rule) matches(cluster *clusterv1.Cluster) bool {
+	return (r.Namespace == "" || r.Namespace == cluster.Namespace) &&
+		(r.Cluster == "" || r.Cluster == cluster.Name) &&
+		(r.selector == nil || r.selector.Matches(labels.Set(cluster.Labels)))
+}
+
+// Config holds the maintenance window rules of the config file. A nil
+// Config only applies the windows of cluster annotations.
+type Config struct {
+	// Rules are checked in order, the first rule matching a cluster applies
+	Rules []Rule `json:"maintenanceWindows"`
+}
+
+// LoadFile reads and validates a YAML config file
+func LoadFile(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read maintenance windows file: %w", err)
+	}
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse maintenance windows file: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate parses the windows and label selectors of the rules
+func (c *Config) Validate() error {
+	for i := range c.Rules {
+		rule := &c.Rules[i]
+		if len(rule.Windows) == 0 {
+			return fmt.Errorf("maintenance window rule %d has no windows", i+1)
+		}
+		if rule.LabelSelector != "" {
+			selector, err := labels.Parse(rule.LabelSelector)
+			if err != nil {
+				return fmt.Errorf("maintenance window rule %d: invalid label selector: %w", i+1, err)
+			}
+			rule.selector = selector
+		}
+		rule.windows = nil
+		for _, text := range rule.Windows {
+			window, err := ParseWindow(text)
+			if err != nil {
+				return fmt.Errorf("maintenance window rule %d: %w", i+1, err)
+			}
+			rule.windows = append(rule.windows, window)
+		}
+	}
+	return nil
+}
+
+// Windows returns the maintenance windows of a cluster, from its annotation
+// or the first matching rule, and where they come from. A cluster without
+// windows has none.
+func (c *Config) Windows(cluster *clusterv1.Cluster) ([]Window, string, error) {
+	if text, ok := cluster.Annotations[WindowAnnotation]; ok {
+		windows, err := ParseWindows(text)
+		if err != nil {
+			return nil, "", fmt.Errorf("cluster %s/%s has an %w", cluster.Namespace, cluster.Name, err)
+		}
+		return windows, "annotation " + WindowAnnotation, nil
+	}
+	if c == nil {
+		return nil, "", nil
+	}
+	for i := range c.Rules {
+		if c.Rules[i].matches(cluster) {
+			return c.Rules[i].windows, fmt.Sprintf("maintenance window rule %d", i+1), nil
+		}
+	}
+	return nil, "", nil
+}
+
+// ErrOutsideWindow is returned by Check for changes outside the maintenance
+// windows of a cluster
+var ErrOutsideWindow = errors.New("outside the maintenance window")
+
+// OutsideWindowError tells when a cluster may be changed next
+type OutsideWindowError struct {
+	Namespace string
+	Cluster   string
+	Windows   []Window
+	// Source is where the windows are configured
+	Source string
+	// NextWindow is when the next window opens
+	NextWindow time.Time
+}
+
+func (e *OutsideWindowError) Error() string {
+	windows := make([]string, 0, len(e.Windows))
+	for _, window := range e.Windows {
+		windows = append(windows, window.String())
+	}
+	message := fmt.Sprintf("cluster %s/%s is %s (%s, from %s)", e.Namespace, e.Cluster, ErrOutsideWindow, strings.Join(windows, "; "), e.Source)
+	if !e.NextWindow.IsZero() {
+		message += ", the next window opens at " + e.NextWindow.Format(time.RFC3339)
+	}
+	return message
+}
+
+func (e *OutsideWindowError) Unwrap() error {
+	return ErrOutsideWindow
+}
+
+// Check returns nil if a cluster may be changed at t: it has no maintenance
+// window or one of its windows is open. Otherwise it returns an
+// *OutsideWindowError.
+func (c *Config) Check(cluster *clusterv1.Cluster, t time.Time) error {
+	windows, source, err := c.Windows(cluster)
+	if err != nil || len(windows) == 0 {
+		return err
+	}
+	outside := &OutsideWindowError{Namespace: cluster.Namespace, Cluster: cluster.Name, Windows: windows, Source: source}
+	for _, window := range windows {
+		if window.Contains(t) {
+			return nil
+		}
+		if next := window.Next(t); !next.IsZero() && (outside.NextWindow.IsZero() || next.Before(outside.NextWindow)) {
+			outside.NextWindow = next
+		}
+	}
+	return outside
+}
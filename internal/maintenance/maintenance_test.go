@@ -0,0 +1,180 @@
+package maintenance
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestParseWindow(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"0 22 * * 1-5",
+		"0 22 * * 1-5 forever",
+		"0 22 * * 1-5 -1h",
+		"0 22 * * 1-5 4h Mars/Olympus",
+		"0 22 * * 1-5 4h Europe/Berlin extra",
+		"@sometimes 1h",
+	} {
+		if _, err := ParseWindow(text); err == nil {
+			t.Errorf("ParseWindow(%q) should fail", text)
+		}
+	}
+
+	window, err := ParseWindow("@daily 2h")
+	if err != nil || window.Cron != "@daily" || window.Duration != 2*time.Hour || window.String() != "@daily 2h0m0s" {
+		t.Errorf("ParseWindow(@daily 2h) = %+v, %v", window, err)
+	}
+}
+
+func TestWindowContains(t *testing.T) {
+	// Weekday nights from 22:00 to 02:00 Berlin time, UTC+1 in March
+	window, err := ParseWindow("0 22 * * mon-fri 4h Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		time time.Time
+		want bool
+	}{
+		// Wednesday
+		{time.Date(2026, time.March, 4, 20, 59, 0, 0, time.UTC), false},
+		{time.Date(2026, time.March, 4, 21, 0, 0, 0, time.UTC), true},
+		{time.Date(2026, time.March, 5, 0, 59, 59, 0, time.UTC), true},
+		{time.Date(2026, time.March, 5, 1, 0, 0, 0, time.UTC), false},
+		// Friday night runs into Saturday, Saturday night is closed
+		{time.Date(2026, time.March, 7, 0, 30, 0, 0, time.UTC), true},
+		{time.Date(2026, time.March, 7, 22, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		if got := window.Contains(tt.time); got != tt.want {
+			t.Errorf("Contains(%s) = %v, want %v", tt.time, got, tt.want)
+		}
+	}
+}
+
+func TestWindowWallClock(t *testing.T) {
+	// Nightly from 22:00 to 00:00 India time, UTC+5:30
+	kolkata, err := ParseWindow("0 22 * * * 2h Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Nightly from 01:00 for three hours Berlin time, across the change
+	// from 02:00 to 03:00 on March 29
+	berlin, err := ParseWindow("0 1 * * * 3h Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		window Window
+		time   time.Time
+		want   bool
+	}{
+		{kolkata, time.Date(2026, time.July, 1, 16, 29, 0, 0, time.UTC), false},
+		{kolkata, time.Date(2026, time.July, 1, 16, 30, 0, 0, time.UTC), true},
+		{kolkata, time.Date(2026, time.July, 1, 18, 29, 0, 0, time.UTC), true},
+		{kolkata, time.Date(2026, time.July, 1, 18, 30, 0, 0, time.UTC), false},
+		{berlin, time.Date(2026, time.March, 28, 23, 59, 0, 0, time.UTC), false},
+		{berlin, time.Date(2026, time.March, 29, 0, 0, 0, 0, time.UTC), true},
+		{berlin, time.Date(2026, time.March, 29, 2, 59, 0, 0, time.UTC), true},
+		{berlin, time.Date(2026, time.March, 29, 3, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		if got := tt.window.Contains(tt.time); got != tt.want {
+			t.Errorf("%s: Contains(%s) = %v, want %v", tt.window, tt.time, got, tt.want)
+		}
+	}
+
+	if got, want := kolkata.Next(time.Date(2026, time.July, 1, 12, 0, 0, 0, time.UTC)), time.Date(2026, time.July, 1, 16, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next() = %s, want %s", got.UTC(), want)
+	}
+	// The window opens at 01:00 CEST after the change on March 29
+	if got, want := berlin.Next(time.Date(2026, time.March, 29, 0, 30, 0, 0, time.UTC)), time.Date(2026, time.March, 29, 23, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next() = %s, want %s", got.UTC(), want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "windows.yaml")
+	err := os.WriteFile(filename, []byte(`maintenanceWindows:
+- namespace: org-acme
+  cluster: legacy
+  windows: ["0 2 * * sun 2h"]
+- labelSelector: env=production
+  windows:
+  - "0 22 * * mon-fri 4h"
+  - "0 6 * * sat 12h"
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadFile(filename)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	cluster := func(name string, labels, annotations map[string]string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: name, Labels: labels, Annotations: annotations}}
+	}
+	production := map[string]string{"env": "production"}
+	// A Wednesday afternoon
+	now := time.Date(2026, time.March, 4, 15, 0, 0, 0, time.UTC)
+
+	if err := config.Check(cluster("dev", nil, nil), now); err != nil {
+		t.Errorf("Check() of a cluster without windows = %v", err)
+	}
+	if err := config.Check(cluster("prod", production, nil), now.Add(8*time.Hour)); err != nil {
+		t.Errorf("Check() inside the window = %v", err)
+	}
+
+	err = config.Check(cluster("prod", production, nil), now)
+	var outside *OutsideWindowError
+	if !errors.As(err, &outside) || !errors.Is(err, ErrOutsideWindow) {
+		t.Fatalf("Check() outside the window = %v", err)
+	}
+	if want := time.Date(2026, time.March, 4, 22, 0, 0, 0, time.UTC); !outside.NextWindow.Equal(want) || outside.Source != "maintenance window rule 2" {
+		t.Errorf("Check() = %+v, want the next window at %s from rule 2", outside, want)
+	}
+
+	// The first matching rule applies
+	err = config.Check(cluster("legacy", production, nil), now.Add(8*time.Hour))
+	if !errors.As(err, &outside) || outside.Source != "maintenance window rule 1" {
+		t.Errorf("Check() = %v, want rule 1", err)
+	}
+
+	// The annotation takes precedence, also without a config file
+	annotated := cluster("prod", production, map[string]string{WindowAnnotation: "0 14 * * * 2h; 0 3 * * * 1h"})
+	for _, c := range []*Config{config, nil} {
+		if err := c.Check(annotated, now); err != nil {
+			t.Errorf("Check() inside the annotated window = %v", err)
+		}
+		if err := c.Check(annotated, now.Add(2*time.Hour)); !errors.Is(err, ErrOutsideWindow) {
+			t.Errorf("Check() outside the annotated windows = %v", err)
+		}
+	}
+	invalid := cluster("prod", nil, map[string]string{WindowAnnotation: "weekends"})
+	if err := config.Check(invalid, now); err == nil || errors.Is(err, ErrOutsideWindow) {
+		t.Errorf("Check() with an invalid annotation = %v", err)
+	}
+}
+
+func TestLoadFileInvalid(t *testing.T) {
+	for _, content := range []string{
+		"maintenanceWindows:\n- cluster: prod\n",
+		"maintenanceWindows:\n- labelSelector: 'env in (('\n  windows: ['@daily 1h']\n",
+		"maintenanceWindows:\n- windows: ['0 22 * * 1-5']\n",
+	} {
+		filename := filepath.Join(t.TempDir(), "windows.yaml")
+		if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFile(filename); err == nil {
+			t.Errorf("LoadFile(%q) should fail", content)
+		}
+	}
+}
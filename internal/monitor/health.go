@@ -0,0 +1,129 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// healthLogger names the cluster health notifications
+const healthLogger = "mcp-capi/health"
+
+// HealthMonitor checks the health of all clusters on an interval and sends
+// an alert to its sinks when a cluster becomes unhealthy, and another when it
+// recovers. Clusters that are unhealthy at the first check are alerted about
+// as well, so problems that started while the server was down are not missed.
+type HealthMonitor struct {
+	client   Client
+	sinks    []Sink
+	interval time.Duration
+
+	// health holds the health of each cluster at the previous check; it is
+	// only used by the goroutine running the monitor
+	health map[string]string
+	// now is overridable for tests
+	now func() time.Time
+}
+
+// NewHealthMonitor creates a monitor of all namespaces of the management
+// cluster of client
+func NewHealthMonitor(client Client, sinks []Sink, interval time.Duration) *HealthMonitor {
+	return &HealthMonitor{
+		client:   client,
+		sinks:    sinks,
+		interval: interval,
+		health:   make(map[string]string),
+		now:      time.Now,
+	}
+}
+
+// Run checks every interval until ctx ends
+func (m *HealthMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := m.Check(ctx); err != nil {
+			slog.ErrorContext(ctx, "Failed to check cluster health", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check classifies the health of all clusters, sends alerts for those that
+// became unhealthy or recovered since the previous check and returns them.
+// Failed deliveries are logged, they do not fail the check.
+func (m *HealthMonitor) Check(ctx context.Context) ([]Alert, error) {
+	statuses, err := m.client.GetClustersStatus(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+	current := make(map[string]string, len(statuses.Items))
+	var alerts []Alert
+	for _, status := range statuses.Items {
+		key := status.Namespace + "/" + status.Name
+		health := capi.ClusterHealth(status)
+		current[key] = health
+		previous, seen := m.health[key]
+		if health == previous {
+			continue
+		}
+		alert := Alert{Time: now, Namespace: status.Namespace, Cluster: status.Name, Health: health, Previous: previous}
+		switch {
+		case health == capi.HealthUnhealthy:
+			alert.Message = healthMessage(status)
+		case seen && previous == capi.HealthUnhealthy:
+			alert.Resolved = true
+		default:
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+	m.health = current
+
+	for _, alert := range alerts {
+		for _, sink := range m.sinks {
+			if err := sink.Send(ctx, alert); err != nil {
+				slog.WarnContext(ctx, "Failed to deliver health alert", "cluster", alert.Namespace+"/"+alert.Cluster, "error", err)
+			}
+		}
+	}
+	return alerts, nil
+}
+
+// healthMessage explains why a cluster is unhealthy
+func healthMessage(status *capi.ClusterStatus) string {
+	var issues []string
+	if status.Phase != "" && status.Phase != string(clusterv1.ClusterPhaseProvisioned) {
+		issues = append(issues, "phase "+status.Phase)
+	}
+	if !status.InfraReady {
+		issues = append(issues, "infrastructure not ready")
+	}
+	if !status.ControlPlaneReady {
+		issues = append(issues, "control plane not ready")
+	}
+	for _, condition := range status.Conditions {
+		if condition.Type == clusterv1.ReadyCondition && condition.Message != "" {
+			issues = append(issues, condition.Message)
+		}
+	}
+	if status.TotalMachines > 0 {
+		issues = append(issues, fmt.Sprintf("%d/%d machines have a node", status.ReadyMachines, status.TotalMachines))
+	}
+	if len(issues) == 0 {
+		return "not ready"
+	}
+	return strings.Join(issues, ", ")
+}
@@ -0,0 +1,77 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+)
+
+// recordingSink captures the alerts sent to it
+type recordingSink struct {
+	alerts []Alert
+	err    error
+}
+
+func (s *recordingSink) Send(ctx context.Context, alert Alert) error {
+	s.alerts = append(s.alerts, alert)
+	return s.err
+}
+
+func TestHealthMonitor(t *testing.T) {
+	healthy := func(name string) *capi.ClusterStatus {
+		return &capi.ClusterStatus{Namespace: "org-acme", Name: name, Phase: "Provisioned", Ready: true, ControlPlaneReady: true, InfraReady: true, TotalMachines: 3, ReadyMachines: 3}
+	}
+	unhealthy := func(name string) *capi.ClusterStatus {
+		return &capi.ClusterStatus{Namespace: "org-acme", Name: name, Phase: "Provisioned", InfraReady: true, TotalMachines: 3, ReadyMachines: 1}
+	}
+	degraded := healthy("prod")
+	degraded.ReadyMachines = 2
+
+	client := &fakeClient{}
+	sink, failing := &recordingSink{}, &recordingSink{err: errors.New("unreachable")}
+	m := NewHealthMonitor(client, []Sink{failing, sink}, time.Minute)
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name     string
+		statuses []*capi.ClusterStatus
+		want     string
+	}{
+		{name: "first check", statuses: []*capi.ClusterStatus{healthy("prod"), unhealthy("dev")}, want: "dev=unhealthy"},
+		{name: "no change", statuses: []*capi.ClusterStatus{healthy("prod"), unhealthy("dev")}},
+		{name: "degraded", statuses: []*capi.ClusterStatus{degraded, unhealthy("dev")}},
+		{name: "transitions", statuses: []*capi.ClusterStatus{unhealthy("prod"), healthy("dev")}, want: "prod=unhealthy,dev=healthy resolved"},
+		{name: "deleted", statuses: []*capi.ClusterStatus{unhealthy("prod")}},
+	} {
+		client.statuses = tt.statuses
+		alerts, err := m.Check(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, alert := range alerts {
+			entry := alert.Cluster + "=" + alert.Health
+			if alert.Resolved {
+				entry += " resolved"
+			}
+			got = append(got, entry)
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Errorf("%s: alerts = %v, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	if len(sink.alerts) != 3 || len(failing.alerts) != 3 {
+		t.Errorf("delivered %d and %d alerts, want 3 to each sink despite failures", len(sink.alerts), len(failing.alerts))
+	}
+	if got := sink.alerts[0].Message; got != "control plane not ready, 1/3 machines have a node" {
+		t.Errorf("alert message = %q", got)
+	}
+	if got := sink.alerts[1].Previous; got != capi.HealthDegraded {
+		t.Errorf("previous health = %q, want degraded", got)
+	}
+}
@@ -0,0 +1,38 @@
+// Package monitor scans the clusters of a management cluster in the
+// background and reports problems as they appear, so operators learn about
+// them without calling a tool.
+//
+// The stale resource scanner notifies MCP clients about resources stuck on
+// their way to their desired state. The health monitor sends alerts about
+// clusters becoming unhealthy and recovering to sinks: webhooks, Slack
+// compatible incoming webhooks and MCP logging notifications.
+package monitor
+
+import (
+	"context"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+)
+
+// methodLogMessage is the MCP logging notification alerts are sent as
+const methodLogMessage = "notifications/message"
+
+// Notifier sends notifications to all client sessions
+type Notifier interface {
+	SendNotificationToAllClients(method string, params map[string]any)
+}
+
+// Client is the part of the CAPI client used by the scanners
+type Client interface {
+	GetClustersStatus(ctx context.Context, namespace string, opts ...capi.ListOption) (*capi.ClusterStatusList, error)
+	FindStaleResources(ctx context.Context, namespace, clusterName string, thresholds capi.StaleThresholds) ([]capi.StaleResource, error)
+}
+
+// logMessage builds the params of an MCP logging notification
+func logMessage(level, logger, message string, data map[string]any) map[string]any {
+	payload := map[string]any{"message": message}
+	for key, value := range data {
+		payload[key] = value
+	}
+	return map[string]any{"level": level, "logger": logger, "data": payload}
+}
@@ -0,0 +1,114 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// sinkTimeout bounds the delivery of an alert to a sink
+const sinkTimeout = 10 * time.Second
+
+// Alert tells that a cluster became unhealthy or recovered
+type Alert struct {
+	Time      time.Time `json:"time"`
+	Namespace string    `json:"namespace"`
+	Cluster   string    `json:"cluster"`
+	Health    string    `json:"health"`
+	// Previous is the health at the previous check, empty for clusters
+	// seen for the first time
+	Previous string `json:"previous,omitempty"`
+	// Resolved is set when an unhealthy cluster recovered
+	Resolved bool   `json:"resolved"`
+	Message  string `json:"message"`
+}
+
+// Text renders the alert as a chat message
+func (a Alert) Text() string {
+	if a.Resolved {
+		return fmt.Sprintf("✅ Cluster %s/%s recovered and is %s", a.Namespace, a.Cluster, a.Health)
+	}
+	return fmt.Sprintf("🚨 Cluster %s/%s is %s: %s", a.Namespace, a.Cluster, a.Health, a.Message)
+}
+
+// Sink delivers alerts
+type Sink interface {
+	Send(ctx context.Context, alert Alert) error
+}
+
+// WebhookSink posts alerts as JSON to a URL. The payload carries the
+// structured alert and its text.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhookSink creates a webhook sink with a sensible HTTP timeout
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{URL: url, Client: &http.Client{Timeout: sinkTimeout}}
+}
+
+// Send posts the alert to the webhook
+func (s *WebhookSink) Send(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, s.Client, s.URL, map[string]any{"text": alert.Text(), "alert": alert})
+}
+
+// SlackSink posts alerts to a Slack incoming webhook, or any URL accepting
+// its {"text": ...} payload such as Mattermost or Rocket.Chat
+type SlackSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewSlackSink creates a Slack sink with a sensible HTTP timeout
+func NewSlackSink(url string) *SlackSink {
+	return &SlackSink{URL: url, Client: &http.Client{Timeout: sinkTimeout}}
+}
+
+// Send posts the text of the alert to the webhook
+func (s *SlackSink) Send(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, s.Client, s.URL, map[string]any{"text": alert.Text()})
+}
+
+// NotificationSink sends alerts to all MCP clients as logging notifications,
+// errors for unhealthy clusters and info messages for recoveries
+type NotificationSink struct {
+	Notifier Notifier
+}
+
+// Send notifies all clients about the alert
+func (s *NotificationSink) Send(ctx context.Context, alert Alert) error {
+	level := "error"
+	if alert.Resolved {
+		level = "info"
+	}
+	s.Notifier.SendNotificationToAllClients(methodLogMessage, logMessage(level, healthLogger, alert.Text(), map[string]any{"alert": alert}))
+	return nil
+}
+
+// postJSON posts payload as JSON to url and expects a 2xx response
+func postJSON(ctx context.Context, client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSinks(t *testing.T) {
+	alert := Alert{Time: time.Now(), Namespace: "org-acme", Cluster: "prod", Health: "unhealthy", Message: "control plane not ready"}
+	var received []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]any{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		received = append(received, payload)
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	if err := NewWebhookSink(server.URL+"/hook").Send(ctx, alert); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewSlackSink(server.URL+"/slack").Send(ctx, alert); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewWebhookSink(server.URL+"/broken").Send(ctx, alert); err == nil {
+		t.Error("Send() to a failing webhook should fail")
+	}
+	if len(received) != 3 {
+		t.Fatalf("received %d payloads, want 3", len(received))
+	}
+	if structured, ok := received[0]["alert"].(map[string]any); !ok || structured["cluster"] != "prod" {
+		t.Errorf("webhook payload = %v, want the structured alert", received[0])
+	}
+	if text := received[1]["text"]; text != "🚨 Cluster org-acme/prod is unhealthy: control plane not ready" || len(received[1]) != 1 {
+		t.Errorf("slack payload = %v, want only the text", received[1])
+	}
+
+	recorder := &notificationRecorder{}
+	alert.Resolved, alert.Health = true, "healthy"
+	if err := (&NotificationSink{Notifier: recorder}).Send(ctx, alert); err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.sent) != 1 || recorder.sent[0]["level"] != "info" || recorder.sent[0]["logger"] != healthLogger {
+		t.Errorf("notifications = %+v, want an info message", recorder.sent)
+	}
+}
@@ -0,0 +1,93 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+)
+
+// staleLogger names the stale resource notifications
+const staleLogger = "mcp-capi/stale"
+
+// StaleScanner looks for stale clusters, machines and MachineDeployments on
+// an interval and notifies clients once about each resource that becomes
+// stale. A resource that recovers and gets stuck again is reported again.
+type StaleScanner struct {
+	client     Client
+	notifier   Notifier
+	interval   time.Duration
+	thresholds capi.StaleThresholds
+
+	// reported holds the keys of the resources already notified about; it
+	// is only used by the goroutine running the scanner
+	reported map[string]bool
+	// now is overridable for tests
+	now func() time.Time
+}
+
+// NewStaleScanner creates a scanner of all namespaces of the management
+// cluster of client
+func NewStaleScanner(client Client, notifier Notifier, interval time.Duration, thresholds capi.StaleThresholds) *StaleScanner {
+	return &StaleScanner{
+		client:     client,
+		notifier:   notifier,
+		interval:   interval,
+		thresholds: thresholds,
+		reported:   make(map[string]bool),
+		now:        time.Now,
+	}
+}
+
+// Run scans every interval until ctx ends
+func (s *StaleScanner) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Scan(ctx); err != nil {
+			slog.ErrorContext(ctx, "Failed to scan for stale resources", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan finds the stale resources, notifies about those that were not stale
+// at the previous scan and returns them
+func (s *StaleScanner) Scan(ctx context.Context) ([]capi.StaleResource, error) {
+	stale, err := s.client.FindStaleResources(ctx, "", "", s.thresholds)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	current := make(map[string]bool, len(stale))
+	var added []capi.StaleResource
+	for _, resource := range stale {
+		key := resource.Key()
+		current[key] = true
+		if s.reported[key] {
+			continue
+		}
+		added = append(added, resource)
+		s.notifier.SendNotificationToAllClients(methodLogMessage, logMessage("warning", staleLogger, describeStale(resource, now), map[string]any{"resource": resource}))
+	}
+	s.reported = current
+	return added, nil
+}
+
+// describeStale summarizes a stale resource in a sentence
+func describeStale(resource capi.StaleResource, now time.Time) string {
+	message := fmt.Sprintf("%s %s/%s of cluster %s is stale: %s for %s", resource.Kind, resource.Namespace, resource.Name,
+		resource.Cluster, resource.State, now.Sub(resource.Since).Round(time.Minute))
+	if resource.Message != "" {
+		message += ", " + resource.Message
+	}
+	return message
+}
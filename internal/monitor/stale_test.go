@@ -0,0 +1,80 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+)
+
+// notificationRecorder captures the notifications sent to all sessions
+type notificationRecorder struct {
+	mu   sync.Mutex
+	sent []map[string]any
+}
+
+func (r *notificationRecorder) SendNotificationToAllClients(method string, params map[string]any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if method != methodLogMessage {
+		return
+	}
+	r.sent = append(r.sent, params)
+}
+
+// fakeClient returns the cluster statuses and stale resources set by the test
+type fakeClient struct {
+	statuses []*capi.ClusterStatus
+	stale    []capi.StaleResource
+}
+
+func (c *fakeClient) GetClustersStatus(ctx context.Context, namespace string, opts ...capi.ListOption) (*capi.ClusterStatusList, error) {
+	return &capi.ClusterStatusList{Items: c.statuses}, nil
+}
+
+func (c *fakeClient) FindStaleResources(ctx context.Context, namespace, clusterName string, thresholds capi.StaleThresholds) ([]capi.StaleResource, error) {
+	return c.stale, nil
+}
+
+func TestStaleScanner(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	machine := capi.StaleResource{Kind: "Machine", Namespace: "org-acme", Name: "prod-md-a", Cluster: "prod", State: capi.StaleProvisioning, Since: now.Add(-time.Hour), Message: "phase Provisioning"}
+	cluster := capi.StaleResource{Kind: "Cluster", Namespace: "org-acme", Name: "dev", Cluster: "dev", State: capi.StaleDeleting, Since: now.Add(-2 * time.Hour)}
+	client := &fakeClient{stale: []capi.StaleResource{machine}}
+	recorder := &notificationRecorder{}
+	scanner := NewStaleScanner(client, recorder, time.Minute, capi.StaleThresholds{})
+	scanner.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		stale []capi.StaleResource
+		added int
+	}{
+		{stale: []capi.StaleResource{machine}, added: 1},
+		{stale: []capi.StaleResource{machine, cluster}, added: 1},
+		{stale: []capi.StaleResource{cluster}, added: 0},
+		// The machine recovered in between, so it is reported again
+		{stale: []capi.StaleResource{machine, cluster}, added: 1},
+	} {
+		client.stale = tt.stale
+		added, err := scanner.Scan(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(added) != tt.added {
+			t.Errorf("Scan() of %d stale resources = %+v, want %d new", len(tt.stale), added, tt.added)
+		}
+	}
+
+	if len(recorder.sent) != 3 {
+		t.Fatalf("notifications = %+v, want 3", recorder.sent)
+	}
+	first := recorder.sent[0]
+	message := first["data"].(map[string]any)["message"].(string)
+	if first["level"] != "warning" || !strings.Contains(message, "Machine org-acme/prod-md-a of cluster prod is stale: Provisioning for 1h0m0s") {
+		t.Errorf("notification = %+v", first)
+	}
+}
@@ -0,0 +1,185 @@
+// Package params extracts and validates MCP tool arguments.
+//
+// Handlers can either use the typed helpers such as RequiredString and
+// OptionalInt directly, or declare a Schema that produces both the tool's
+// input schema and the validation of incoming arguments. Either way, invalid
+// arguments are reported as *Error values with consistent wording.
+package params
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// ErrInvalid is matched by every *Error with errors.Is
+var ErrInvalid = errors.New("invalid argument")
+
+// Error describes an argument that is missing or has an unusable value
+type Error struct {
+	Param  string
+	Reason string
+}
+
+// Error returns a message such as "name is required"
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s", e.Param, e.Reason)
+}
+
+// Is lets *Error match ErrInvalid
+func (e *Error) Is(target error) bool {
+	return target == ErrInvalid
+}
+
+func invalid(param, format string, args ...any) error {
+	return &Error{Param: param, Reason: fmt.Sprintf(format, args...)}
+}
+
+// RequiredString returns a non-empty string argument
+func RequiredString(arguments map[string]any, name string) (string, error) {
+	value, ok := arguments[name]
+	if !ok || value == nil {
+		return "", invalid(name, "is required")
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", invalid(name, "must be a string")
+	}
+	if s == "" {
+		return "", invalid(name, "is required")
+	}
+	return s, nil
+}
+
+// OptionalString returns a string argument, or def if it is absent or empty
+func OptionalString(arguments map[string]any, name, def string) string {
+	if s, ok := arguments[name].(string); ok && s != "" {
+		return s
+	}
+	return def
+}
+
+// RequiredInt returns a whole-number argument
+func RequiredInt(arguments map[string]any, name string) (int, error) {
+	if _, ok := arguments[name]; !ok {
+		return 0, invalid(name, "is required")
+	}
+	return OptionalInt(arguments, name, 0)
+}
+
+// OptionalInt returns a whole-number argument, or def if it is absent.
+// JSON numbers arrive as float64, so fractional values are rejected.
+func OptionalInt(arguments map[string]any, name string, def int) (int, error) {
+	value, ok := arguments[name]
+	if !ok || value == nil {
+		return def, nil
+	}
+	switch n := value.(type) {
+	case float64:
+		if n != math.Trunc(n) || n > math.MaxInt32 || n < math.MinInt32 {
+			return 0, invalid(name, "must be a whole number")
+		}
+		return int(n), nil
+	case int:
+		return n, nil
+	case int32:
+		return int(n), nil
+	case int64:
+		return int(n), nil
+	default:
+		return 0, invalid(name, "must be a number")
+	}
+}
+
+// OptionalBool returns a boolean argument, or def if it is absent
+func OptionalBool(arguments map[string]any, name string, def bool) (bool, error) {
+	value, ok := arguments[name]
+	if !ok || value == nil {
+		return def, nil
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, invalid(name, "must be a boolean")
+	}
+	return b, nil
+}
+
+// OptionalStringMap returns an object argument with string values, or nil if it is absent
+func OptionalStringMap(arguments map[string]any, name string) (map[string]string, error) {
+	value, ok := arguments[name]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, invalid(name, "must be an object")
+	}
+	result := make(map[string]string, len(object))
+	for key, v := range object {
+		s, ok := v.(string)
+		if !ok {
+			return nil, invalid(name, "must only contain string values (%s is not)", key)
+		}
+		result[key] = s
+	}
+	return result, nil
+}
+
+// OneOf checks that value is one of allowed
+func OneOf(name, value string, allowed ...string) error {
+	for _, a := range allowed {
+		if value == a {
+			return nil
+		}
+	}
+	return invalid(name, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
+
+// KubernetesName checks that value is a valid name for most Kubernetes
+// resources (a DNS-1123 subdomain)
+func KubernetesName(name, value string) error {
+	if msgs := validation.IsDNS1123Subdomain(value); len(msgs) > 0 {
+		return invalid(name, "is not a valid Kubernetes name: %s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// Namespace checks that value is a valid namespace name (a DNS-1123 label)
+func Namespace(name, value string) error {
+	if msgs := validation.IsDNS1123Label(value); len(msgs) > 0 {
+		return invalid(name, "is not a valid namespace: %s", strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// Semver checks that value is a semantic version such as v1.29.0
+func Semver(name, value string) error {
+	if _, err := version.ParseSemantic(value); err != nil {
+		return invalid(name, "must be a semantic version such as v1.29.0, got %q", value)
+	}
+	return nil
+}
+
+// LabelSelector checks that value is a Kubernetes label selector such as
+// "team=platform,env!=dev"
+func LabelSelector(name, value string) error {
+	if _, err := labels.Parse(value); err != nil {
+		return invalid(name, "is not a valid label selector: %v", err)
+	}
+	return nil
+}
+
+// FieldSelector checks that value is a Kubernetes field selector such as
+// "metadata.name=prod"
+func FieldSelector(name, value string) error {
+	if _, err := fields.ParseSelector(value); err != nil {
+		return invalid(name, "is not a valid field selector: %v", err)
+	}
+	return nil
+}
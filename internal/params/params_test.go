@@ -0,0 +1,116 @@
+package params
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTypedExtraction(t *testing.T) {
+	arguments := map[string]any{
+		"name":     "prod",
+		"empty":    "",
+		"number":   "3",
+		"replicas": float64(5),
+		"half":     2.5,
+		"labels":   map[string]any{"team": "a", "count": float64(1)},
+	}
+
+	if v, err := RequiredString(arguments, "name"); err != nil || v != "prod" {
+		t.Errorf("RequiredString(name) = %q, %v", v, err)
+	}
+	for _, name := range []string{"empty", "missing"} {
+		if _, err := RequiredString(arguments, name); err == nil || err.Error() != name+" is required" {
+			t.Errorf("RequiredString(%s) error = %v, want %q", name, err, name+" is required")
+		}
+	}
+	if v := OptionalString(arguments, "empty", "fallback"); v != "fallback" {
+		t.Errorf("OptionalString(empty) = %q, want default", v)
+	}
+	if v, err := OptionalInt(arguments, "replicas", 1); err != nil || v != 5 {
+		t.Errorf("OptionalInt(replicas) = %d, %v", v, err)
+	}
+	if v, err := OptionalInt(arguments, "missing", 1); err != nil || v != 1 {
+		t.Errorf("OptionalInt(missing) = %d, %v, want default", v, err)
+	}
+	if _, err := OptionalInt(arguments, "half", 1); !errors.Is(err, ErrInvalid) {
+		t.Errorf("OptionalInt(half) error = %v, want ErrInvalid", err)
+	}
+	if _, err := OptionalInt(arguments, "number", 1); err == nil {
+		t.Error("OptionalInt should reject strings")
+	}
+	if _, err := OptionalStringMap(arguments, "labels"); err == nil {
+		t.Error("OptionalStringMap should reject non-string values")
+	}
+}
+
+func TestValidators(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{"valid name", KubernetesName("name", "prod-cluster.eu"), false},
+		{"uppercase name", KubernetesName("name", "Prod"), true},
+		{"valid namespace", Namespace("namespace", "org-acme"), false},
+		{"dotted namespace", Namespace("namespace", "org.acme"), true},
+		{"semver", Semver("version", "v1.29.0"), false},
+		{"semver without v", Semver("version", "1.29.0"), false},
+		{"not semver", Semver("version", "1.29"), true},
+		{"enum", OneOf("target", "workers", "controlplane", "workers"), false},
+		{"not in enum", OneOf("target", "nodes", "controlplane", "workers"), true},
+		{"label selector", LabelSelector("label_selector", "cluster.x-k8s.io/control-plane,team in (a,b)"), false},
+		{"invalid label selector", LabelSelector("label_selector", "team in ("), true},
+		{"field selector", FieldSelector("field_selector", "metadata.name=prod"), false},
+		{"invalid field selector", FieldSelector("field_selector", "metadata.name"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", tt.err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchemaParse(t *testing.T) {
+	schema := Schema{
+		{Name: "name", Type: String, Required: true, Validate: KubernetesName},
+		{Name: "version", Type: String, Default: "v1.29.0", Validate: Semver},
+		{Name: "target", Type: String, Enum: []string{"controlplane", "workers"}},
+		{Name: "replicas", Type: Int, Default: 3, NonNegative: true},
+		{Name: "workers", Type: Bool, Default: true},
+	}
+
+	values, err := schema.Parse(map[string]any{"name": "prod", "replicas": float64(0)})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if values.String("version") != "v1.29.0" || values.Int("replicas") != 0 || !values.Bool("workers") {
+		t.Errorf("unexpected values: version=%q replicas=%d workers=%v", values.String("version"), values.Int("replicas"), values.Bool("workers"))
+	}
+	if values.Provided("version") || !values.Provided("replicas") {
+		t.Error("Provided() should distinguish defaults from supplied values")
+	}
+
+	invalid := []map[string]any{
+		{},
+		{"name": "prod", "version": "latest"},
+		{"name": "prod", "target": "nodes"},
+		{"name": "prod", "replicas": float64(-1)},
+		{"name": "prod", "workers": "yes"},
+	}
+	for _, arguments := range invalid {
+		if _, err := schema.Parse(arguments); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%v) error = %v, want ErrInvalid", arguments, err)
+		}
+	}
+
+	tool := schema.NewTool("example", "Example tool")
+	if len(tool.InputSchema.Required) != 1 || tool.InputSchema.Required[0] != "name" {
+		t.Errorf("required = %v, want [name]", tool.InputSchema.Required)
+	}
+	if len(tool.InputSchema.Properties) != len(schema) {
+		t.Errorf("got %d properties, want %d", len(tool.InputSchema.Properties), len(schema))
+	}
+}
@@ -0,0 +1,224 @@
+package params
+
+import (
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Type is the type of a declared parameter
+type Type int
+
+const (
+	// String parameters are JSON strings
+	String Type = iota
+	// Int parameters are JSON numbers holding whole numbers
+	Int
+	// Bool parameters are JSON booleans
+	Bool
+	// StringMap parameters are JSON objects with string values
+	StringMap
+)
+
+// Param declares a single tool parameter
+type Param struct {
+	Name        string
+	Type        Type
+	Description string
+	Required    bool
+
+	// Default is used when an optional parameter is absent. It must be a
+	// string, int or bool matching Type.
+	Default any
+
+	// Enum restricts String parameters to a fixed set of values
+	Enum []string
+
+	// Validate checks String parameters, e.g. KubernetesName or Semver
+	Validate func(name, value string) error
+
+	// NonNegative rejects negative Int parameters
+	NonNegative bool
+}
+
+// Schema declares the parameters of a tool. It is the single source for the
+// tool's input schema and the validation of its arguments.
+type Schema []Param
+
+// Options returns the tool options describing the schema's parameters
+func (s Schema) Options() []mcp.ToolOption {
+	options := make([]mcp.ToolOption, 0, len(s))
+	for _, p := range s {
+		var props []mcp.PropertyOption
+		if p.Description != "" {
+			props = append(props, mcp.Description(p.Description))
+		}
+		if p.Required {
+			props = append(props, mcp.Required())
+		}
+
+		switch p.Type {
+		case String:
+			if len(p.Enum) > 0 {
+				props = append(props, mcp.Enum(p.Enum...))
+			}
+			if def, ok := p.Default.(string); ok {
+				props = append(props, mcp.DefaultString(def))
+			}
+			options = append(options, mcp.WithString(p.Name, props...))
+		case Int:
+			if p.NonNegative {
+				props = append(props, mcp.Min(0))
+			}
+			if def, ok := p.Default.(int); ok {
+				props = append(props, mcp.DefaultNumber(float64(def)))
+			}
+			options = append(options, mcp.WithNumber(p.Name, props...))
+		case Bool:
+			if def, ok := p.Default.(bool); ok {
+				props = append(props, mcp.DefaultBool(def))
+			}
+			options = append(options, mcp.WithBoolean(p.Name, props...))
+		case StringMap:
+			options = append(options, mcp.WithObject(p.Name, props...))
+		}
+	}
+	return options
+}
+
+// NewTool creates a tool whose input schema is declared by s. Additional
+// options, such as parameters added by middlewares, are appended.
+func (s Schema) NewTool(name, description string, opts ...mcp.ToolOption) mcp.Tool {
+	options := append([]mcp.ToolOption{mcp.WithDescription(description)}, s.Options()...)
+	return mcp.NewTool(name, append(options, opts...)...)
+}
+
+// Parse extracts and validates arguments according to the schema. The first
+// invalid argument is reported as an *Error.
+func (s Schema) Parse(arguments map[string]any) (Values, error) {
+	values := Values{values: make(map[string]any, len(s)), provided: make(map[string]bool, len(s))}
+	for _, p := range s {
+		value, err := p.parse(arguments)
+		if err != nil {
+			return Values{}, err
+		}
+		values.values[p.Name] = value
+		if v, ok := arguments[p.Name]; ok && v != nil && v != "" {
+			values.provided[p.Name] = true
+		}
+	}
+	return values, nil
+}
+
+func (p Param) parse(arguments map[string]any) (any, error) {
+	switch p.Type {
+	case String:
+		var value string
+		if p.Required {
+			s, err := RequiredString(arguments, p.Name)
+			if err != nil {
+				return nil, err
+			}
+			value = s
+		} else {
+			if v, ok := arguments[p.Name]; ok && v != nil {
+				if _, isString := v.(string); !isString {
+					return nil, invalid(p.Name, "must be a string")
+				}
+			}
+			def, _ := p.Default.(string)
+			value = OptionalString(arguments, p.Name, def)
+			if value == "" {
+				return value, nil
+			}
+		}
+		if len(p.Enum) > 0 {
+			if err := OneOf(p.Name, value, p.Enum...); err != nil {
+				return nil, err
+			}
+		}
+		if p.Validate != nil {
+			if err := p.Validate(p.Name, value); err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+
+	case Int:
+		var (
+			value int
+			err   error
+		)
+		if p.Required {
+			value, err = RequiredInt(arguments, p.Name)
+		} else {
+			def, _ := p.Default.(int)
+			value, err = OptionalInt(arguments, p.Name, def)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if p.NonNegative && value < 0 {
+			return nil, invalid(p.Name, "must not be negative")
+		}
+		return value, nil
+
+	case Bool:
+		if _, ok := arguments[p.Name]; p.Required && !ok {
+			return nil, invalid(p.Name, "is required")
+		}
+		def, _ := p.Default.(bool)
+		return OptionalBool(arguments, p.Name, def)
+
+	case StringMap:
+		value, err := OptionalStringMap(arguments, p.Name)
+		if err != nil {
+			return nil, err
+		}
+		if p.Required && value == nil {
+			return nil, invalid(p.Name, "is required")
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("parameter %s has unknown type %d", p.Name, p.Type)
+}
+
+// Values holds arguments parsed by a Schema, with defaults applied
+type Values struct {
+	values   map[string]any
+	provided map[string]bool
+}
+
+// String returns a String parameter
+func (v Values) String(name string) string {
+	s, _ := v.values[name].(string)
+	return s
+}
+
+// Int returns an Int parameter
+func (v Values) Int(name string) int {
+	n, _ := v.values[name].(int)
+	return n
+}
+
+// Int32 returns an Int parameter as int32, the type used by replica counts
+func (v Values) Int32(name string) int32 {
+	return int32(v.Int(name))
+}
+
+// Bool returns a Bool parameter
+func (v Values) Bool(name string) bool {
+	b, _ := v.values[name].(bool)
+	return b
+}
+
+// StringMap returns a StringMap parameter, or nil if it was not provided
+func (v Values) StringMap(name string) map[string]string {
+	m, _ := v.values[name].(map[string]string)
+	return m
+}
+
+// Provided reports whether the caller supplied a non-empty value for name
+func (v Values) Provided(name string) bool {
+	return v.provided[name]
+}
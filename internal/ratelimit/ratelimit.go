@@ -0,0 +1,156 @@
+// Package ratelimit limits how often MCP tools may be called, so a client
+// looping over list or status calls cannot starve the API server of the
+// management cluster.
+//
+// Limits are rules of a tool rule, as used by the tool policy, and a rate
+// such as "capi_list_*=20/m" or "group:readonly=5/s:10". The rate is a
+// number of calls per second, minute or hour with an optional burst after a
+// colon, which defaults to the number of calls. The first rule matching a
+// tool applies, and every tool gets its own budget.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/toolpolicy"
+	"golang.org/x/time/rate"
+)
+
+// Limit is a rate of tool calls
+type Limit struct {
+	// Calls are allowed per Per
+	Calls float64
+	Per   time.Duration
+	// Burst is how many calls may be made at once
+	Burst int
+}
+
+// ParseLimit parses a limit like "20/m", "5/s:10" or "100/h"
+func ParseLimit(text string) (Limit, error) {
+	value, burstText, hasBurst := strings.Cut(strings.TrimSpace(text), ":")
+	callsText, unit, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: want calls per unit, e.g. 20/m or 5/s:10", text)
+	}
+	limit := Limit{}
+	switch unit {
+	case "s":
+		limit.Per = time.Second
+	case "m":
+		limit.Per = time.Minute
+	case "h":
+		limit.Per = time.Hour
+	default:
+		return Limit{}, fmt.Errorf("invalid rate limit %q: the unit must be s, m or h", text)
+	}
+	calls, err := strconv.ParseFloat(callsText, 64)
+	if err != nil || calls <= 0 || math.IsInf(calls, 0) {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: the number of calls must be positive", text)
+	}
+	limit.Calls = calls
+	limit.Burst = int(math.Max(1, math.Ceil(calls)))
+	if hasBurst {
+		burst, err := strconv.Atoi(burstText)
+		if err != nil || burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit %q: the burst must be a positive integer", text)
+		}
+		limit.Burst = burst
+	}
+	return limit, nil
+}
+
+func (l Limit) String() string {
+	unit := map[time.Duration]string{time.Second: "s", time.Minute: "m", time.Hour: "h"}[l.Per]
+	return fmt.Sprintf("%s/%s:%d", strconv.FormatFloat(l.Calls, 'f', -1, 64), unit, l.Burst)
+}
+
+// rate returns the limit in calls per second
+func (l Limit) rate() rate.Limit {
+	return rate.Limit(l.Calls / l.Per.Seconds())
+}
+
+// Rule limits the calls of the tools matching a tool rule
+type Rule struct {
+	Tools string
+	Limit Limit
+}
+
+// ParseRules parses a comma-separated list of rules like
+// "capi_list_*=20/m,group:readonly=5/s:10"
+func ParseRules(value string) ([]Rule, error) {
+	var rules []Rule
+	for _, text := range toolpolicy.ParseList(value) {
+		tools, limitText, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit rule %q: want <tool rule>=<limit>", text)
+		}
+		tools = strings.TrimSpace(tools)
+		if err := toolpolicy.ValidateRule(tools); err != nil {
+			return nil, err
+		}
+		limit, err := ParseLimit(limitText)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, Rule{Tools: tools, Limit: limit})
+	}
+	return rules, nil
+}
+
+// Limiter hands out the budgets of tool calls
+type Limiter struct {
+	rules []Rule
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// New creates a limiter applying the first matching rule to each tool
+func New(rules []Rule) *Limiter {
+	return &Limiter{rules: rules, limiters: map[string]*rate.Limiter{}}
+}
+
+// Empty tells whether the limiter has no rules, so it allows every call
+func (l *Limiter) Empty() bool {
+	return l == nil || len(l.rules) == 0
+}
+
+// Allow takes a call from the budget of a tool with the given name and
+// groups. When the budget is used up it returns false, the rule limiting the
+// tool and how long to wait before retrying.
+func (l *Limiter) Allow(name string, groups []string, now time.Time) (bool, Rule, time.Duration) {
+	if l.Empty() {
+		return true, Rule{}, 0
+	}
+	for _, rule := range l.rules {
+		if !toolpolicy.Matches(rule.Tools, name, groups) {
+			continue
+		}
+		limiter := l.limiter(rule, name)
+		reservation := limiter.ReserveN(now, 1)
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			return false, rule, delay
+		}
+		return true, rule, 0
+	}
+	return true, Rule{}, 0
+}
+
+// limiter returns the budget of a tool under a rule
+func (l *Limiter) limiter(rule Rule, name string) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := rule.Tools + "\x00" + name
+	limiter, ok := l.limiters[key]
+	if !ok {
+		limiter = rate.NewLimiter(rule.Limit.rate(), rule.Limit.Burst)
+		l.limiters[key] = limiter
+	}
+	return limiter
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    Limit
+		wantErr bool
+	}{
+		{text: "20/m", want: Limit{Calls: 20, Per: time.Minute, Burst: 20}},
+		{text: "5/s:10", want: Limit{Calls: 5, Per: time.Second, Burst: 10}},
+		{text: "0.5/s", want: Limit{Calls: 0.5, Per: time.Second, Burst: 1}},
+		{text: "20", wantErr: true},
+		{text: "20/d", wantErr: true},
+		{text: "0/m", wantErr: true},
+		{text: "5/s:many", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseLimit(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLimit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseLimit() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules("capi_list_*=20/m, group:readonly=5/s:10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 || rules[0].Tools != "capi_list_*" || rules[1].Limit.Burst != 10 {
+		t.Errorf("ParseRules() = %+v", rules)
+	}
+	for _, invalid := range []string{"capi_list_clusters", "group:=1/s", "[=1/s", "capi_*=fast"} {
+		if _, err := ParseRules(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	rules, err := ParseRules("capi_list_clusters=2/m,group:readonly=1/s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter := New(rules)
+	now := time.Now()
+	readonly := []string{"readonly"}
+
+	for i := 0; i < 2; i++ {
+		if allowed, _, _ := limiter.Allow("capi_list_clusters", readonly, now); !allowed {
+			t.Fatalf("call %d refused within the burst", i+1)
+		}
+	}
+	allowed, rule, retryAfter := limiter.Allow("capi_list_clusters", readonly, now)
+	if allowed || rule.Tools != "capi_list_clusters" || retryAfter <= 0 || retryAfter > 30*time.Second {
+		t.Errorf("Allow() = %v, %+v, %s, want a refusal by the first rule for up to 30s", allowed, rule, retryAfter)
+	}
+	if allowed, _, _ := limiter.Allow("capi_list_clusters", readonly, now.Add(30*time.Second)); !allowed {
+		t.Error("call refused after the budget refilled")
+	}
+
+	// Every tool has its own budget
+	if allowed, _, _ := limiter.Allow("capi_list_machines", readonly, now); !allowed {
+		t.Error("another read-only tool shared the budget")
+	}
+	if allowed, _, _ := limiter.Allow("capi_scale_cluster", []string{"mutating"}, now); !allowed {
+		t.Error("a tool without a matching rule was limited")
+	}
+	if allowed, _, _ := New(nil).Allow("capi_list_clusters", readonly, now); !allowed {
+		t.Error("a limiter without rules refused a call")
+	}
+}
@@ -0,0 +1,154 @@
+// Package rbac renders Kubernetes RBAC manifests from the permissions
+// required by the server's tools.
+//
+// Each tool declares the API verbs it needs as a list of Permissions. The
+// permissions of all enabled tools are merged into a minimal set of policy
+// rules and emitted either as a single ClusterRole, or as a namespaced Role
+// plus a ClusterRole for cluster-scoped resources such as nodes.
+package rbac
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultName is the name used for generated roles when none is given
+const DefaultName = "mcp-capi"
+
+// Permission describes verbs a tool needs on a resource
+type Permission struct {
+	Group         string
+	Resource      string
+	Verbs         []string
+	ClusterScoped bool
+}
+
+// Options controls manifest generation
+type Options struct {
+	// Name of the generated Role/ClusterRole
+	Name string
+	// Namespace restricts namespaced permissions to a Role in this namespace.
+	// When empty a single ClusterRole is generated.
+	Namespace string
+}
+
+// resourceKey identifies a resource independent of verbs
+type resourceKey struct {
+	group         string
+	resource      string
+	clusterScoped bool
+}
+
+// Rules merges permissions into policy rules, split into namespaced and
+// cluster-scoped rules. Rules are sorted by group and resource and verbs are
+// deduplicated so the output is stable.
+func Rules(permissions []Permission) (namespaced, clusterScoped []rbacv1.PolicyRule) {
+	verbs := make(map[resourceKey]map[string]bool)
+	for _, p := range permissions {
+		key := resourceKey{group: p.Group, resource: p.Resource, clusterScoped: p.ClusterScoped}
+		if verbs[key] == nil {
+			verbs[key] = make(map[string]bool)
+		}
+		for _, verb := range p.Verbs {
+			verbs[key][verb] = true
+		}
+	}
+
+	keys := make([]resourceKey, 0, len(verbs))
+	for key := range verbs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].group != keys[j].group {
+			return keys[i].group < keys[j].group
+		}
+		return keys[i].resource < keys[j].resource
+	})
+
+	for _, key := range keys {
+		rule := rbacv1.PolicyRule{
+			APIGroups: []string{key.group},
+			Resources: []string{key.resource},
+			Verbs:     sortedKeys(verbs[key]),
+		}
+		if key.clusterScoped {
+			clusterScoped = append(clusterScoped, rule)
+		} else {
+			namespaced = append(namespaced, rule)
+		}
+	}
+	return namespaced, clusterScoped
+}
+
+// role is the serialized form of a Role or ClusterRole. It is used instead of
+// the rbacv1 types to keep status-like fields such as creationTimestamp out of
+// the generated YAML.
+type role struct {
+	APIVersion string              `json:"apiVersion"`
+	Kind       string              `json:"kind"`
+	Metadata   roleMetadata        `json:"metadata"`
+	Rules      []rbacv1.PolicyRule `json:"rules"`
+}
+
+type roleMetadata struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Manifest renders the YAML manifest granting the given permissions
+func Manifest(permissions []Permission, opts Options) ([]byte, error) {
+	name := opts.Name
+	if name == "" {
+		name = DefaultName
+	}
+
+	namespaced, clusterScoped := Rules(permissions)
+
+	var roles []role
+	if opts.Namespace == "" {
+		roles = append(roles, newRole("ClusterRole", name, "", append(namespaced, clusterScoped...)))
+	} else {
+		roles = append(roles, newRole("Role", name, opts.Namespace, namespaced))
+		if len(clusterScoped) > 0 {
+			roles = append(roles, newRole("ClusterRole", name+"-cluster", "", clusterScoped))
+		}
+	}
+
+	var buf bytes.Buffer
+	for i, r := range roles {
+		data, err := yaml.Marshal(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s %s: %w", r.Kind, r.Metadata.Name, err)
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+func newRole(kind, name, namespace string, rules []rbacv1.PolicyRule) role {
+	if rules == nil {
+		rules = []rbacv1.PolicyRule{}
+	}
+	return role{
+		APIVersion: rbacv1.SchemeGroupVersion.String(),
+		Kind:       kind,
+		Metadata:   roleMetadata{Name: name, Namespace: namespace},
+		Rules:      rules,
+	}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
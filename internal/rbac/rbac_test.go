@@ -0,0 +1,59 @@
+package rbac
+
+import (
+	"strings"
+	"testing"
+)
+
+var testPermissions = []Permission{
+	{Group: "cluster.x-k8s.io", Resource: "clusters", Verbs: []string{"list", "get"}},
+	{Group: "cluster.x-k8s.io", Resource: "clusters", Verbs: []string{"get", "update"}},
+	{Group: "", Resource: "secrets", Verbs: []string{"get"}},
+	{Group: "", Resource: "nodes", Verbs: []string{"update"}, ClusterScoped: true},
+}
+
+func TestRules(t *testing.T) {
+	namespaced, clusterScoped := Rules(testPermissions)
+
+	if len(namespaced) != 2 {
+		t.Fatalf("expected 2 namespaced rules, got %d: %+v", len(namespaced), namespaced)
+	}
+	if namespaced[0].Resources[0] != "secrets" || namespaced[1].Resources[0] != "clusters" {
+		t.Errorf("rules are not sorted by group: %+v", namespaced)
+	}
+	if got := strings.Join(namespaced[1].Verbs, ","); got != "get,list,update" {
+		t.Errorf("merged verbs = %s, want get,list,update", got)
+	}
+
+	if len(clusterScoped) != 1 || clusterScoped[0].Resources[0] != "nodes" {
+		t.Errorf("unexpected cluster-scoped rules: %+v", clusterScoped)
+	}
+}
+
+func TestManifest(t *testing.T) {
+	clusterWide, err := Manifest(testPermissions, Options{})
+	if err != nil {
+		t.Fatalf("Manifest() error = %v", err)
+	}
+	if strings.Count(string(clusterWide), "kind: ClusterRole") != 1 || strings.Contains(string(clusterWide), "kind: Role\n") {
+		t.Errorf("expected a single ClusterRole:\n%s", clusterWide)
+	}
+	if !strings.Contains(string(clusterWide), "name: mcp-capi") {
+		t.Errorf("expected default name:\n%s", clusterWide)
+	}
+
+	namespaced, err := Manifest(testPermissions, Options{Name: "capi-operator", Namespace: "org-acme"})
+	if err != nil {
+		t.Fatalf("Manifest() error = %v", err)
+	}
+	docs := strings.Split(string(namespaced), "---\n")
+	if len(docs) != 2 {
+		t.Fatalf("expected Role and ClusterRole documents, got %d:\n%s", len(docs), namespaced)
+	}
+	if !strings.Contains(docs[0], "kind: Role") || !strings.Contains(docs[0], "namespace: org-acme") || strings.Contains(docs[0], "nodes") {
+		t.Errorf("unexpected Role:\n%s", docs[0])
+	}
+	if !strings.Contains(docs[1], "kind: ClusterRole") || !strings.Contains(docs[1], "name: capi-operator-cluster") || !strings.Contains(docs[1], "nodes") {
+		t.Errorf("unexpected ClusterRole:\n%s", docs[1])
+	}
+}
@@ -0,0 +1,91 @@
+// Package redact scrubs credentials from tool output before it reaches the
+// MCP client.
+//
+// Kubeconfigs, Secrets and provider objects carry certificates, private
+// keys, tokens and passwords. Once they are part of a chat they end up in
+// transcripts and model context, so they are replaced with a placeholder
+// unless a tool call explicitly asks for them.
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces redacted values
+const Placeholder = "REDACTED"
+
+// secretKeys are the keys, lowercased and without separators, whose values
+// are credentials in kubeconfigs, Secrets and provider objects
+var secretKeys = map[string]bool{
+	"clientkeydata":            true,
+	"clientcertificatedata":    true,
+	"certificateauthoritydata": true,
+	"token":                    true,
+	"idtoken":                  true,
+	"refreshtoken":             true,
+	"accesstoken":              true,
+	"password":                 true,
+	"clientsecret":             true,
+	"secretaccesskey":          true,
+}
+
+var (
+	// pemBlock matches certificates and keys in PEM encoding
+	pemBlock = regexp.MustCompile(`-----BEGIN ([A-Z0-9 ]+)-----[\s\S]*?-----END [A-Z0-9 ]+-----`)
+	// keyValue matches the values of secret keys in YAML, JSON and
+	// key=value text
+	keyValue = regexp.MustCompile(`(?i)(\b(?:client-key-data|client-certificate-data|certificate-authority-data|token|id-token|refresh-token|access-token|password|client-secret|secret-access-key)["']?\s*[:=]\s*["']?)([^\s"',}]+)`)
+	// bearerToken matches tokens in Authorization headers
+	bearerToken = regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9\-._~+/]+=*`)
+	// jwt matches JSON web tokens such as service account and OIDC tokens
+	jwt = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]*`)
+)
+
+// String scrubs PEM blocks, the values of secret keys, bearer tokens and
+// JSON web tokens from text
+func String(s string) string {
+	s = pemBlock.ReplaceAllString(s, "-----BEGIN $1-----\n"+Placeholder+"\n-----END $1-----")
+	s = keyValue.ReplaceAllStringFunc(s, func(match string) string {
+		groups := keyValue.FindStringSubmatch(match)
+		if groups[2] == Placeholder {
+			return match
+		}
+		return groups[1] + Placeholder
+	})
+	s = bearerToken.ReplaceAllString(s, "${1}"+Placeholder)
+	return jwt.ReplaceAllString(s, Placeholder)
+}
+
+// Value scrubs a decoded JSON value: the values of secret keys are replaced
+// and all other strings scrubbed like text. Maps and slices are modified in
+// place.
+func Value(v any) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for key, item := range value {
+			if s, ok := item.(string); ok && s != "" && IsSecretKey(key) {
+				value[key] = Placeholder
+				continue
+			}
+			value[key] = Value(item)
+		}
+		return value
+	case []any:
+		for i, item := range value {
+			value[i] = Value(item)
+		}
+		return value
+	case string:
+		return String(value)
+	default:
+		return v
+	}
+}
+
+// IsSecretKey reports whether values of a key are credentials, ignoring
+// case, dashes and underscores
+func IsSecretKey(key string) bool {
+	normalized := strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(key))
+	return secretKeys[normalized]
+}
@@ -0,0 +1,113 @@
+// Package report renders machine-readable reports about a fleet of clusters.
+//
+// The inventory lists every cluster with its provider, Kubernetes version,
+// node counts, age and health, as JSON or CSV, for capacity planning and
+// compliance audits.
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+)
+
+// Format is the encoding of a report
+type Format string
+
+const (
+	// FormatJSON renders a JSON array of objects
+	FormatJSON Format = "json"
+	// FormatCSV renders a header row followed by one row per cluster
+	FormatCSV Format = "csv"
+)
+
+// InventoryRow describes one cluster of the inventory
+type InventoryRow struct {
+	Cluster           string        `json:"cluster"`
+	Namespace         string        `json:"namespace"`
+	Provider          capi.Provider `json:"provider"`
+	Version           string        `json:"version"`
+	Phase             string        `json:"phase"`
+	Health            string        `json:"health"`
+	ControlPlaneNodes int           `json:"controlPlaneNodes"`
+	WorkerNodes       int           `json:"workerNodes"`
+	ReadyNodes        int           `json:"readyNodes"`
+	CreatedAt         time.Time     `json:"createdAt"`
+	AgeDays           int           `json:"ageDays"`
+}
+
+// inventoryColumns is the CSV header, in the order of the InventoryRow fields
+var inventoryColumns = []string{
+	"cluster", "namespace", "provider", "version", "phase", "health",
+	"control_plane_nodes", "worker_nodes", "ready_nodes", "created_at", "age_days",
+}
+
+// Inventory builds the inventory rows of clusters as of now
+func Inventory(statuses []*capi.ClusterStatus, now time.Time) []InventoryRow {
+	rows := make([]InventoryRow, 0, len(statuses))
+	for _, status := range statuses {
+		row := InventoryRow{
+			Cluster:           status.Name,
+			Namespace:         status.Namespace,
+			Provider:          status.Provider,
+			Version:           status.Version,
+			Phase:             status.Phase,
+			Health:            capi.ClusterHealth(status),
+			ControlPlaneNodes: status.ControlPlaneMachines,
+			WorkerNodes:       status.TotalMachines - status.ControlPlaneMachines,
+			ReadyNodes:        status.ReadyMachines,
+			CreatedAt:         status.CreatedAt.UTC(),
+		}
+		if !status.CreatedAt.IsZero() {
+			row.AgeDays = int(now.Sub(status.CreatedAt).Hours() / 24)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// WriteInventory encodes inventory rows in a format
+func WriteInventory(w io.Writer, format Format, rows []InventoryRow) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(inventoryColumns); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			createdAt := ""
+			if !row.CreatedAt.IsZero() {
+				createdAt = row.CreatedAt.Format(time.RFC3339)
+			}
+			record := []string{
+				row.Cluster,
+				row.Namespace,
+				string(row.Provider),
+				row.Version,
+				row.Phase,
+				row.Health,
+				strconv.Itoa(row.ControlPlaneNodes),
+				strconv.Itoa(row.WorkerNodes),
+				strconv.Itoa(row.ReadyNodes),
+				createdAt,
+				strconv.Itoa(row.AgeDays),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+}
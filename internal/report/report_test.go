@@ -0,0 +1,85 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+)
+
+func TestInventory(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	statuses := []*capi.ClusterStatus{
+		{
+			Namespace:            "org-a",
+			Name:                 "prod",
+			Phase:                "Provisioned",
+			Ready:                true,
+			Provider:             capi.ProviderAWS,
+			Version:              "v1.30.1",
+			TotalMachines:        5,
+			ReadyMachines:        4,
+			ControlPlaneMachines: 3,
+			CreatedAt:            now.Add(-10*24*time.Hour - time.Hour),
+		},
+		{Namespace: "org-b", Name: "new", Phase: "Pending", Provider: capi.ProviderUnknown},
+	}
+
+	rows := Inventory(statuses, now)
+	if len(rows) != 2 {
+		t.Fatalf("Inventory() returned %d rows, want 2", len(rows))
+	}
+	want := InventoryRow{
+		Cluster:           "prod",
+		Namespace:         "org-a",
+		Provider:          capi.ProviderAWS,
+		Version:           "v1.30.1",
+		Phase:             "Provisioned",
+		Health:            capi.HealthDegraded,
+		ControlPlaneNodes: 3,
+		WorkerNodes:       2,
+		ReadyNodes:        4,
+		CreatedAt:         statuses[0].CreatedAt,
+		AgeDays:           10,
+	}
+	if rows[0] != want {
+		t.Errorf("Inventory()[0] = %+v, want %+v", rows[0], want)
+	}
+	if rows[1].AgeDays != 0 || rows[1].Health != capi.HealthUnhealthy {
+		t.Errorf("Inventory()[1] = %+v", rows[1])
+	}
+
+	var buf bytes.Buffer
+	if err := WriteInventory(&buf, FormatCSV, rows); err != nil {
+		t.Fatalf("WriteInventory(csv) error = %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || len(records[0]) != len(inventoryColumns) {
+		t.Fatalf("CSV has %d records, want a header and 2 rows", len(records))
+	}
+	if records[1][0] != "prod" || records[1][7] != "2" || records[1][9] != "2026-02-19T11:00:00Z" {
+		t.Errorf("CSV row = %v", records[1])
+	}
+	if records[2][9] != "" {
+		t.Errorf("CSV row of a cluster without creation time = %v", records[2])
+	}
+
+	buf.Reset()
+	if err := WriteInventory(&buf, FormatJSON, rows); err != nil {
+		t.Fatalf("WriteInventory(json) error = %v", err)
+	}
+	var decoded []InventoryRow
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded) != 2 {
+		t.Errorf("JSON report = %s, error %v", buf.String(), err)
+	}
+
+	if err := WriteInventory(&buf, "xml", rows); err == nil {
+		t.Error("WriteInventory() accepted an unsupported format")
+	}
+}
@@ -0,0 +1,83 @@
+package resources
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxCompletionValues is the most values a completion may return
+const maxCompletionValues = 100
+
+// Completions suggests values for the variables of the resource templates by
+// querying the default management cluster
+type Completions struct {
+	clients *capi.ClientPool
+}
+
+// NewCompletions creates the completion provider of the resource templates
+func NewCompletions(clients *capi.ClientPool) *Completions {
+	return &Completions{clients: clients}
+}
+
+// CompleteResourceArgument suggests namespaces holding clusters, cluster names
+// and machine deployment names starting with the typed value. Names are
+// limited to the namespace already chosen, if any.
+func (c *Completions) CompleteResourceArgument(ctx context.Context, uri string, argument mcp.CompleteArgument, completeContext mcp.CompleteContext) (*mcp.Completion, error) {
+	namespace := completeContext.Arguments["namespace"]
+
+	var candidates []string
+	switch {
+	case argument.Name == "namespace":
+		clusters, err := c.clients.Default().ListClusters(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, cluster := range clusters.Items {
+			candidates = append(candidates, cluster.Namespace)
+		}
+	case argument.Name == "machineDeployment" || (argument.Name == "name" && uri == machineDeploymentTemplate):
+		mds, err := c.clients.Default().ListMachineDeployments(ctx, namespace, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, md := range mds.Items {
+			candidates = append(candidates, md.Name)
+		}
+	case argument.Name == "name":
+		clusters, err := c.clients.Default().ListClusters(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		for _, cluster := range clusters.Items {
+			candidates = append(candidates, cluster.Name)
+		}
+	}
+
+	return completion(candidates, argument.Value), nil
+}
+
+// completion returns the sorted, distinct candidates starting with prefix
+func completion(candidates []string, prefix string) *mcp.Completion {
+	var values []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			values = append(values, candidate)
+		}
+	}
+	slices.Sort(values)
+	values = slices.Compact(values)
+
+	result := &mcp.Completion{Values: values, Total: len(values)}
+	if len(values) > maxCompletionValues {
+		result.Values = values[:maxCompletionValues]
+		result.HasMore = true
+	}
+	if result.Values == nil {
+		result.Values = []string{}
+	}
+	return result
+}
@@ -0,0 +1,27 @@
+package resources
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestCompletion(t *testing.T) {
+	got := completion([]string{"org-beta", "org-acme", "default", "org-acme"}, "org-")
+	if !slices.Equal(got.Values, []string{"org-acme", "org-beta"}) || got.Total != 2 || got.HasMore {
+		t.Errorf("completion() = %+v, want the distinct sorted matches", got)
+	}
+
+	if got := completion(nil, "x"); got.Values == nil || len(got.Values) != 0 {
+		t.Errorf("completion() without matches = %+v, want an empty list", got)
+	}
+
+	var many []string
+	for i := range 150 {
+		many = append(many, fmt.Sprintf("cluster-%03d", i))
+	}
+	got = completion(many, "cluster-")
+	if len(got.Values) != maxCompletionValues || got.Total != 150 || !got.HasMore {
+		t.Errorf("completion() of 150 matches returned %d values, total %d, hasMore %v", len(got.Values), got.Total, got.HasMore)
+	}
+}
@@ -0,0 +1,221 @@
+// Package resources exposes CAPI objects of the default management cluster
+// as MCP resources.
+//
+// Clusters, their machines and machine deployments are addressed by URIs
+// below capi://, next to a summary of the whole fleet. Parameterized URIs are registered as resource templates, and
+// every resource is served as JSON.
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ClustersURI lists the clusters of all namespaces
+	ClustersURI = "capi://clusters"
+	// SummaryURI aggregates the clusters of all namespaces
+	SummaryURI = "capi://summary"
+
+	clusterTemplate           = "capi://clusters/{namespace}/{name}"
+	clusterMachinesTemplate   = "capi://clusters/{namespace}/{name}/machines"
+	machineDeploymentTemplate = "capi://machinedeployments/{namespace}/{name}"
+
+	mimeTypeJSON = "application/json"
+)
+
+// ClusterURI addresses a cluster
+func ClusterURI(namespace, name string) string {
+	return fmt.Sprintf("capi://clusters/%s/%s", namespace, name)
+}
+
+// ClusterMachinesURI addresses the machines of a cluster
+func ClusterMachinesURI(namespace, name string) string {
+	return ClusterURI(namespace, name) + "/machines"
+}
+
+// MachineDeploymentURI addresses a machine deployment
+func MachineDeploymentURI(namespace, name string) string {
+	return fmt.Sprintf("capi://machinedeployments/%s/%s", namespace, name)
+}
+
+// Registry is the part of the MCP server resources are registered with
+type Registry interface {
+	AddResource(resource mcp.Resource, handler server.ResourceHandlerFunc)
+	AddResourceTemplate(template mcp.ResourceTemplate, handler server.ResourceTemplateHandlerFunc)
+}
+
+// Register adds the CAPI resources and resource templates, served from the
+// default client of the pool
+func Register(s Registry, clients *capi.ClientPool) {
+	s.AddResource(
+		mcp.NewResource(ClustersURI, "Clusters",
+			mcp.WithResourceDescription("Summary of the CAPI clusters in all namespaces"),
+			mcp.WithMIMEType(mimeTypeJSON),
+		),
+		listClustersHandler(clients),
+	)
+	s.AddResource(
+		mcp.NewResource(SummaryURI, "Fleet summary",
+			mcp.WithResourceDescription("Counts of the clusters by provider, phase, Kubernetes version and health, with the clusters needing attention"),
+			mcp.WithMIMEType(mimeTypeJSON),
+		),
+		summaryHandler(clients),
+	)
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(clusterTemplate, "Cluster",
+			mcp.WithTemplateDescription("A CAPI cluster with its spec and status"),
+			mcp.WithTemplateMIMEType(mimeTypeJSON),
+		),
+		clusterHandler(clients),
+	)
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(clusterMachinesTemplate, "Cluster machines",
+			mcp.WithTemplateDescription("The machines of a CAPI cluster"),
+			mcp.WithTemplateMIMEType(mimeTypeJSON),
+		),
+		clusterMachinesHandler(clients),
+	)
+	s.AddResourceTemplate(
+		mcp.NewResourceTemplate(machineDeploymentTemplate, "Machine deployment",
+			mcp.WithTemplateDescription("A CAPI machine deployment with its spec and status"),
+			mcp.WithTemplateMIMEType(mimeTypeJSON),
+		),
+		machineDeploymentHandler(clients),
+	)
+}
+
+// clusterSummary is the entry of a cluster in the cluster list
+type clusterSummary struct {
+	Namespace           string `json:"namespace"`
+	Name                string `json:"name"`
+	Phase               string `json:"phase,omitempty"`
+	KubernetesVersion   string `json:"kubernetesVersion,omitempty"`
+	InfrastructureReady bool   `json:"infrastructureReady"`
+	ControlPlaneReady   bool   `json:"controlPlaneReady"`
+	Paused              bool   `json:"paused"`
+	URI                 string `json:"uri"`
+}
+
+func listClustersHandler(clients *capi.ClientPool) server.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		clusters, err := clients.Default().ListClusters(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+
+		summaries := make([]clusterSummary, 0, len(clusters.Items))
+		for _, cluster := range clusters.Items {
+			summary := clusterSummary{
+				Namespace:           cluster.Namespace,
+				Name:                cluster.Name,
+				Phase:               cluster.Status.Phase,
+				InfrastructureReady: cluster.Status.InfrastructureReady,
+				ControlPlaneReady:   cluster.Status.ControlPlaneReady,
+				Paused:              cluster.Spec.Paused,
+				URI:                 ClusterURI(cluster.Namespace, cluster.Name),
+			}
+			if cluster.Spec.Topology != nil {
+				summary.KubernetesVersion = cluster.Spec.Topology.Version
+			}
+			summaries = append(summaries, summary)
+		}
+		return jsonContents(request.Params.URI, summaries)
+	}
+}
+
+func summaryHandler(clients *capi.ClientPool) server.ResourceHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		summary, err := clients.Default().GetFleetSummary(ctx, "", capi.DefaultStuckAfter)
+		if err != nil {
+			return nil, err
+		}
+		return jsonContents(request.Params.URI, summary)
+	}
+}
+
+func clusterHandler(clients *capi.ClientPool) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		namespace, name := templateArgument(request, "namespace"), templateArgument(request, "name")
+		cluster, err := clients.Default().GetCluster(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		return jsonContents(request.Params.URI, withoutManagedFields(cluster))
+	}
+}
+
+func clusterMachinesHandler(clients *capi.ClientPool) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		namespace, name := templateArgument(request, "namespace"), templateArgument(request, "name")
+		c := clients.Default()
+
+		// Distinguish a missing cluster from a cluster without machines
+		if _, err := c.GetCluster(ctx, namespace, name); err != nil {
+			return nil, err
+		}
+		machines, err := c.ListMachines(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+
+		items := make([]*clusterv1.Machine, 0, len(machines.Items))
+		for i := range machines.Items {
+			items = append(items, withoutManagedFields(&machines.Items[i]))
+		}
+		return jsonContents(request.Params.URI, items)
+	}
+}
+
+func machineDeploymentHandler(clients *capi.ClientPool) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		namespace, name := templateArgument(request, "namespace"), templateArgument(request, "name")
+		md, err := clients.Default().GetMachineDeployment(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		return jsonContents(request.Params.URI, withoutManagedFields(md))
+	}
+}
+
+// templateArgument returns a variable of the URI template matched by a request
+func templateArgument(request mcp.ReadResourceRequest, name string) string {
+	switch value := request.Params.Arguments[name].(type) {
+	case string:
+		return value
+	case []string:
+		if len(value) > 0 {
+			return value[0]
+		}
+	}
+	return ""
+}
+
+// withoutManagedFields drops the server-side apply bookkeeping, which is
+// large and of no use to readers of a resource
+func withoutManagedFields[T client.Object](obj T) T {
+	obj.SetManagedFields(nil)
+	return obj
+}
+
+// jsonContents encodes a value as the JSON contents of a resource
+func jsonContents(uri string, value any) ([]mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resource %s: %w", uri, err)
+	}
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      uri,
+			MIMEType: mimeTypeJSON,
+			Text:     string(data),
+		},
+	}, nil
+}
@@ -0,0 +1,62 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// resourceRecorder captures registered resources and templates
+type resourceRecorder struct {
+	resources map[string]mcp.Resource
+	templates map[string]mcp.ResourceTemplate
+}
+
+func (r *resourceRecorder) AddResource(resource mcp.Resource, handler server.ResourceHandlerFunc) {
+	r.resources[resource.URI] = resource
+}
+
+func (r *resourceRecorder) AddResourceTemplate(template mcp.ResourceTemplate, handler server.ResourceTemplateHandlerFunc) {
+	r.templates[template.URITemplate.Raw()] = template
+}
+
+func TestRegister(t *testing.T) {
+	recorder := &resourceRecorder{resources: map[string]mcp.Resource{}, templates: map[string]mcp.ResourceTemplate{}}
+	Register(recorder, nil)
+
+	for _, uri := range []string{ClustersURI, SummaryURI} {
+		if _, ok := recorder.resources[uri]; !ok {
+			t.Errorf("%s is not registered", uri)
+		}
+	}
+
+	// Each URI must match exactly one template, with the expected variables
+	uris := map[string]string{
+		ClusterURI("org-acme", "prod"):           clusterTemplate,
+		ClusterMachinesURI("org-acme", "prod"):   clusterMachinesTemplate,
+		MachineDeploymentURI("org-acme", "md-0"): machineDeploymentTemplate,
+	}
+	for uri, want := range uris {
+		var matched []string
+		for raw, template := range recorder.templates {
+			if template.URITemplate.Regexp().MatchString(uri) {
+				matched = append(matched, raw)
+			}
+		}
+		if len(matched) != 1 || matched[0] != want {
+			t.Errorf("%s matches templates %v, want %s", uri, matched, want)
+			continue
+		}
+
+		request := mcp.ReadResourceRequest{}
+		request.Params.URI = uri
+		request.Params.Arguments = map[string]any{}
+		for name, value := range recorder.templates[want].URITemplate.Match(uri) {
+			request.Params.Arguments[name] = value.V
+		}
+		if namespace := templateArgument(request, "namespace"); namespace != "org-acme" {
+			t.Errorf("%s: namespace = %q", uri, namespace)
+		}
+	}
+}
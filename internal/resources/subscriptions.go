@@ -0,0 +1,272 @@
+package resources
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// DefaultNotifyDelay collects the changes of a resource within this
+	// period into a single notification
+	DefaultNotifyDelay = 2 * time.Second
+
+	methodSubscribe   = "resources/subscribe"
+	methodUnsubscribe = "resources/unsubscribe"
+)
+
+// Notifier sends notifications to a client session
+type Notifier interface {
+	SendNotificationToSpecificClient(sessionID string, method string, params map[string]any) error
+}
+
+// Subscriptions tracks the resources clients subscribed to and notifies them
+// when the underlying CAPI objects change.
+//
+// Changes are observed through watches on the default client of the pool,
+// which are started with the first subscription and stopped when the last
+// one is removed. Notifications are debounced per resource, so a rollout
+// touching many objects yields one notification per delay and resource.
+type Subscriptions struct {
+	ctx      context.Context
+	clients  *capi.ClientPool
+	notifier Notifier
+	delay    time.Duration
+
+	mu sync.Mutex
+	// subscribers maps resource URIs to the subscribed session IDs
+	subscribers map[string]map[string]bool
+	// pending holds the URIs with a scheduled notification
+	pending map[string]bool
+	// watched is the client whose resources are watched
+	watched   *capi.Client
+	stopWatch context.CancelFunc
+}
+
+// NewSubscriptions creates the subscription registry; watches run until ctx
+// is canceled
+func NewSubscriptions(ctx context.Context, clients *capi.ClientPool, notifier Notifier, delay time.Duration) *Subscriptions {
+	return &Subscriptions{
+		ctx:         ctx,
+		clients:     clients,
+		notifier:    notifier,
+		delay:       delay,
+		subscribers: make(map[string]map[string]bool),
+		pending:     make(map[string]bool),
+	}
+}
+
+// Subscribe registers a session for updates of a resource
+func (s *Subscriptions) Subscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subscribers[uri] == nil {
+		s.subscribers[uri] = make(map[string]bool)
+	}
+	s.subscribers[uri][sessionID] = true
+	s.ensureWatchLocked()
+}
+
+// Unsubscribe removes the subscription of a session to a resource
+func (s *Subscriptions) Unsubscribe(sessionID, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.subscribers[uri], sessionID)
+	if len(s.subscribers[uri]) == 0 {
+		delete(s.subscribers, uri)
+	}
+	s.stopWatchIfUnusedLocked()
+}
+
+// RemoveSession drops all subscriptions of a closed session
+func (s *Subscriptions) RemoveSession(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for uri, sessions := range s.subscribers {
+		delete(sessions, sessionID)
+		if len(sessions) == 0 {
+			delete(s.subscribers, uri)
+		}
+	}
+	s.stopWatchIfUnusedLocked()
+}
+
+// ensureWatchLocked watches the current default client, replacing the watch
+// of a client that is no longer the default; s.mu must be held
+func (s *Subscriptions) ensureWatchLocked() {
+	current := s.clients.Default()
+	if s.watched == current {
+		return
+	}
+	if s.stopWatch != nil {
+		s.stopWatch()
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	s.watched, s.stopWatch = current, cancel
+	go func() {
+		if err := current.WatchResources(ctx, s.changed); err != nil {
+			slog.Warn("Resource subscriptions will not be notified", "error", err)
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			// Retry with the next subscription
+			if s.watched == current {
+				s.watched, s.stopWatch = nil, nil
+			}
+			cancel()
+		}
+	}()
+}
+
+// stopWatchIfUnusedLocked stops watching once nobody is subscribed; s.mu must be held
+func (s *Subscriptions) stopWatchIfUnusedLocked() {
+	if len(s.subscribers) > 0 || s.stopWatch == nil {
+		return
+	}
+	s.stopWatch()
+	s.watched, s.stopWatch = nil, nil
+}
+
+// changed schedules notifications for the resources showing a changed object
+func (s *Subscriptions) changed(change capi.ResourceChange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, uri := range changedURIs(change) {
+		if len(s.subscribers[uri]) == 0 || s.pending[uri] {
+			continue
+		}
+		s.pending[uri] = true
+		time.AfterFunc(s.delay, func() { s.notify(uri) })
+	}
+}
+
+// notify sends the update notification of a resource to its subscribers
+func (s *Subscriptions) notify(uri string) {
+	s.mu.Lock()
+	delete(s.pending, uri)
+	sessions := make([]string, 0, len(s.subscribers[uri]))
+	for sessionID := range s.subscribers[uri] {
+		sessions = append(sessions, sessionID)
+	}
+	s.mu.Unlock()
+
+	for _, sessionID := range sessions {
+		if err := s.notifier.SendNotificationToSpecificClient(sessionID, mcp.MethodNotificationResourceUpdated, map[string]any{"uri": uri}); err != nil {
+			slog.Warn("Failed to notify session about a resource change", "session", sessionID, "uri", uri, "error", err)
+		}
+	}
+}
+
+// changedURIs lists the resources showing an object
+func changedURIs(change capi.ResourceChange) []string {
+	switch change.Kind {
+	case "Cluster":
+		return []string{ClustersURI, SummaryURI, ClusterURI(change.Namespace, change.Name)}
+	case "Machine":
+		return []string{SummaryURI, ClusterMachinesURI(change.Namespace, change.ClusterName)}
+	case "MachineDeployment":
+		return []string{MachineDeploymentURI(change.Namespace, change.Name)}
+	}
+	return nil
+}
+
+// Intercept handles the resources/subscribe and resources/unsubscribe
+// requests of a session, which the MCP library does not implement. A handled
+// request is rewritten into a ping with the same ID, so the library still
+// answers with the empty result the protocol expects. Other messages are
+// returned unchanged.
+func (s *Subscriptions) Intercept(sessionID string, message []byte) []byte {
+	var request struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(message, &request); err != nil || request.ID == nil || request.Params.URI == "" {
+		return message
+	}
+
+	switch request.Method {
+	case methodSubscribe:
+		s.Subscribe(sessionID, request.Params.URI)
+	case methodUnsubscribe:
+		s.Unsubscribe(sessionID, request.Params.URI)
+	default:
+		return message
+	}
+
+	ping, err := json.Marshal(map[string]any{
+		"jsonrpc": mcp.JSONRPC_VERSION,
+		"id":      request.ID,
+		"method":  string(mcp.MethodPing),
+	})
+	if err != nil {
+		return message
+	}
+	if bytes.HasSuffix(message, []byte("\n")) {
+		ping = append(ping, '\n')
+	}
+	return ping
+}
+
+// Middleware intercepts subscription requests posted to the HTTP transports.
+// The session is identified by the Mcp-Session-Id header of the streamable
+// HTTP transport or the sessionId query parameter of the SSE transport.
+func (s *Subscriptions) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessionID := r.Header.Get("Mcp-Session-Id")
+		if sessionID == "" {
+			sessionID = r.URL.Query().Get("sessionId")
+		}
+		if r.Method != http.MethodPost || sessionID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		body = s.Intercept(sessionID, body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// InterceptReader intercepts subscription requests read from a line-based
+// transport such as stdio
+func (s *Subscriptions) InterceptReader(sessionID string, r io.Reader) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				if _, writeErr := pw.Write(s.Intercept(sessionID, line)); writeErr != nil {
+					return
+				}
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	return pr
+}
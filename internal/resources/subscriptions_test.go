@@ -0,0 +1,90 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"k8s.io/client-go/rest"
+)
+
+// notificationRecorder captures the notifications sent to sessions
+type notificationRecorder struct {
+	mu   sync.Mutex
+	sent []string
+}
+
+func (r *notificationRecorder) SendNotificationToSpecificClient(sessionID string, method string, params map[string]any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, sessionID+" "+params["uri"].(string))
+	return nil
+}
+
+func (r *notificationRecorder) notifications() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.sent...)
+}
+
+func TestSubscriptions(t *testing.T) {
+	// The watch of the unreachable cluster fails in the background; changes
+	// are fed in directly
+	c, err := capi.NewClientWithOptions(capi.WithRESTConfig(&rest.Config{Host: "https://127.0.0.1:1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := capi.NewClientPool(c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	recorder := &notificationRecorder{}
+	subscriptions := NewSubscriptions(ctx, pool, recorder, 20*time.Millisecond)
+
+	uri := ClusterURI("org-acme", "prod")
+	rewritten := subscriptions.Intercept("session-1", []byte(`{"jsonrpc":"2.0","id":7,"method":"resources/subscribe","params":{"uri":"`+uri+`"}}`+"\n"))
+	var ping struct {
+		ID     int    `json:"id"`
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(rewritten, &ping); err != nil || ping.ID != 7 || ping.Method != "ping" || rewritten[len(rewritten)-1] != '\n' {
+		t.Fatalf("subscribe request rewritten to %s", rewritten)
+	}
+
+	other := []byte(`{"jsonrpc":"2.0","id":8,"method":"resources/read","params":{"uri":"` + uri + `"}}`)
+	if got := subscriptions.Intercept("session-1", other); string(got) != string(other) {
+		t.Errorf("other requests must pass unchanged, got %s", got)
+	}
+
+	// A burst of changes yields a single notification per resource
+	change := capi.ResourceChange{Type: capi.ResourceUpdated, Kind: "Cluster", Namespace: "org-acme", Name: "prod", ClusterName: "prod"}
+	for range 5 {
+		subscriptions.changed(change)
+	}
+	subscriptions.changed(capi.ResourceChange{Type: capi.ResourceUpdated, Kind: "Cluster", Namespace: "org-acme", Name: "staging"})
+	time.Sleep(100 * time.Millisecond)
+	if got := recorder.notifications(); len(got) != 1 || got[0] != "session-1 "+uri {
+		t.Errorf("notifications = %v, want one for %s", got, uri)
+	}
+
+	subscriptions.Intercept("session-1", []byte(`{"jsonrpc":"2.0","id":9,"method":"resources/unsubscribe","params":{"uri":"`+uri+`"}}`))
+	subscriptions.changed(change)
+	time.Sleep(100 * time.Millisecond)
+	if got := recorder.notifications(); len(got) != 1 {
+		t.Errorf("notified after unsubscribing: %v", got)
+	}
+
+	subscriptions.Subscribe("session-2", ClustersURI)
+	subscriptions.RemoveSession("session-2")
+	subscriptions.changed(change)
+	time.Sleep(100 * time.Millisecond)
+	if got := recorder.notifications(); len(got) != 1 {
+		t.Errorf("notified a removed session: %v", got)
+	}
+}
@@ -0,0 +1,190 @@
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCronSearch bounds the search for the next time of an expression that
+// rarely matches, such as the 31st of February
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+// cronMacros are the shorthands of common expressions
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField describes a field of a cron expression
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 6, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// Cron is a parsed cron expression with the five standard fields: minute,
+// hour, day of month, month and day of week
+type Cron struct {
+	expression string
+	fields     [5]uint64
+	// restricted tells whether the day of month and day of week fields are
+	// not *: when both are, a day matching either is a match, like cron
+	domRestricted, dowRestricted bool
+}
+
+// ParseCron parses a cron expression such as "0 22 * * 1-5" or a macro such
+// as "@daily". Fields accept *, ranges, lists, steps and, for months and days
+// of the week, three letter names; 7 is Sunday like 0.
+func ParseCron(expression string) (*Cron, error) {
+	spec := strings.TrimSpace(expression)
+	if macro, ok := cronMacros[strings.ToLower(spec)]; ok {
+		spec = macro
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("invalid cron expression %q: want 5 fields (minute hour day-of-month month day-of-week), got %d", expression, len(parts))
+	}
+
+	cron := &Cron{expression: strings.TrimSpace(expression)}
+	for i, part := range parts {
+		field := cronFields[i]
+		if i == 4 {
+			// 7 is Sunday as well
+			field.max = 7
+		}
+		bits, err := parseCronField(part, field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expression, err)
+		}
+		if i == 4 && bits&(1<<7) != 0 {
+			bits = bits&^(1<<7) | 1
+		}
+		cron.fields[i] = bits
+	}
+	cron.domRestricted = parts[2] != "*"
+	cron.dowRestricted = parts[4] != "*"
+	return cron, nil
+}
+
+// parseCronField parses a comma separated field into a bit per value
+func parseCronField(part string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(part, ",") {
+		rangeText, stepText, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepText, field.name)
+			}
+			step = n
+		}
+
+		low, high := field.min, field.max
+		switch {
+		case rangeText == "*":
+		case strings.Contains(rangeText, "-"):
+			lowText, highText, _ := strings.Cut(rangeText, "-")
+			var err error
+			if low, err = cronValue(lowText, field); err != nil {
+				return 0, err
+			}
+			if high, err = cronValue(highText, field); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q in %s field", rangeText, field.name)
+			}
+		default:
+			value, err := cronValue(rangeText, field)
+			if err != nil {
+				return 0, err
+			}
+			low = value
+			if !hasStep {
+				high = value
+			}
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+// cronValue parses a number or name of a field
+func cronValue(text string, field cronField) (int, error) {
+	for i, name := range field.names {
+		if strings.EqualFold(text, name) {
+			return field.min + i, nil
+		}
+	}
+	value, err := strconv.Atoi(text)
+	if err != nil || value < field.min || value > field.max {
+		return 0, fmt.Errorf("invalid value %q in %s field (%d-%d)", text, field.name, field.min, field.max)
+	}
+	return value, nil
+}
+
+// String returns the expression as it was parsed
+func (c *Cron) String() string {
+	return c.expression
+}
+
+// Next returns the first time after t matching the expression, in the
+// location of t, or the zero time if there is none within five years.
+// Fields match the wall clock of the location: minutes and hours step on
+// the local clock rather than absolute time, so zones with a half-hour
+// offset and days with a daylight saving time change work as well.
+func (c *Cron) Next(t time.Time) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, t.Location())
+	deadline := t.Add(maxCronSearch)
+	for t.Before(deadline) {
+		switch {
+		case !c.matches(0, int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !c.matches(1, t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !c.matches(2, t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matches reports whether a value is set in the month (0), hour (1) or
+// minute (2) field
+func (c *Cron) matches(field, value int) bool {
+	index := [...]int{3, 1, 0}[field]
+	return c.fields[index]&(1<<value) != 0
+}
+
+// dayMatches reports whether the day of t matches the day of month and day
+// of week fields
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.fields[2]&(1<<t.Day()) != 0
+	dow := c.fields[4]&(1<<int(t.Weekday())) != 0
+	if c.domRestricted && c.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
@@ -0,0 +1,110 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+		"@often",
+	} {
+		if _, err := ParseCron(expression); err == nil {
+			t.Errorf("ParseCron(%q) should fail", expression)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2026, time.March, 4, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		expression string
+		want       time.Time
+	}{
+		{"* * * * *", time.Date(2026, time.March, 4, 10, 31, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, time.March, 4, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2026, time.March, 4, 10, 40, 0, 0, time.UTC)},
+		{"0 22 * * mon-fri", time.Date(2026, time.March, 4, 22, 0, 0, 0, time.UTC)},
+		{"0 18 * * FRI", time.Date(2026, time.March, 6, 18, 0, 0, 0, time.UTC)},
+		{"0 6 * * 7", time.Date(2026, time.March, 8, 6, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"15 3 1,15 * *", time.Date(2026, time.March, 15, 3, 15, 0, 0, time.UTC)},
+		// Day of month or day of week when both are set
+		{"0 0 20 * 5", time.Date(2026, time.March, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.expression)
+		if err != nil {
+			t.Fatalf("ParseCron(%q) error = %v", tt.expression, err)
+		}
+		if got := cron.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %s, want %s", tt.expression, got, tt.want)
+		}
+	}
+
+	cron, _ := ParseCron("0 0 31 2 *")
+	if got := cron.Next(from); !got.IsZero() {
+		t.Errorf("Next() of an impossible date = %s, want zero", got)
+	}
+}
+
+func TestCronNextTimeZone(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cron, _ := ParseCron("0 22 * * *")
+	got := cron.Next(time.Date(2026, time.July, 1, 12, 0, 0, 0, time.UTC).In(berlin))
+	if want := time.Date(2026, time.July, 1, 20, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next() = %s, want %s", got.UTC(), want)
+	}
+}
+
+func TestCronNextWallClock(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		expression string
+		from       time.Time
+		want       time.Time
+	}{
+		// UTC+5:30, hours start at half past the hour of UTC
+		{"0 22 * * *", time.Date(2026, time.July, 1, 12, 0, 0, 0, time.UTC).In(kolkata), time.Date(2026, time.July, 1, 16, 30, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, time.July, 1, 12, 0, 0, 0, time.UTC).In(kolkata), time.Date(2026, time.July, 1, 12, 30, 0, 0, time.UTC)},
+		{"15 * * * *", time.Date(2026, time.July, 1, 12, 50, 0, 0, time.UTC).In(kolkata), time.Date(2026, time.July, 1, 13, 45, 0, 0, time.UTC)},
+		// Clocks go from 02:00 to 03:00 on March 29 and from 03:00 back to
+		// 02:00 on October 25
+		{"0 3 * * *", time.Date(2026, time.March, 28, 23, 0, 0, 0, time.UTC).In(berlin), time.Date(2026, time.March, 29, 1, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2026, time.March, 28, 23, 0, 0, 0, time.UTC).In(berlin), time.Date(2026, time.March, 30, 0, 30, 0, 0, time.UTC)},
+		{"0 4 * * *", time.Date(2026, time.October, 24, 23, 0, 0, 0, time.UTC).In(berlin), time.Date(2026, time.October, 25, 3, 0, 0, 0, time.UTC)},
+		{"0 0 * * *", time.Date(2026, time.October, 24, 23, 0, 0, 0, time.UTC).In(berlin), time.Date(2026, time.October, 25, 23, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		cron, err := ParseCron(tt.expression)
+		if err != nil {
+			t.Fatalf("ParseCron(%q) error = %v", tt.expression, err)
+		}
+		if got := cron.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("Next(%q, %s) = %s, want %s", tt.expression, tt.from, got.UTC(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+// Package schedule runs recurring operations on clusters, such as nightly
+// hibernation, a weekend scale-down, an upgrade at a quiet hour or a daily
+// backup.
+//
+// Schedules are cron expressions attached to an action. They are persisted as
+// ConfigMaps in a namespace of the management cluster, so they survive
+// restarts and are shared by the replicas of the server. Each run is claimed
+// by updating its ConfigMap with optimistic locking, so only one replica
+// starts it, and runs as a background job.
+package schedule
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+	// Time zones of schedules must load in images without a zoneinfo database
+	_ "time/tzdata"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ManagedByLabel marks the ConfigMaps of the server
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// ScheduleLabel marks the ConfigMaps holding a schedule
+	ScheduleLabel = "mcp-capi.giantswarm.io/schedule"
+	// configMapPrefix prefixes the ID of a schedule in its ConfigMap name
+	configMapPrefix = "mcp-capi-"
+	// dataKey is the ConfigMap key holding the JSON schedule
+	dataKey = "schedule.json"
+)
+
+// Action is the operation a schedule runs
+type Action string
+
+const (
+	// ActionHibernate scales the cluster to zero and pauses it
+	ActionHibernate Action = "hibernate"
+	// ActionWake resumes a hibernated cluster
+	ActionWake Action = "wake"
+	// ActionScale scales a MachineDeployment
+	ActionScale Action = "scale"
+	// ActionUpgrade upgrades the cluster to a Kubernetes version
+	ActionUpgrade Action = "upgrade"
+	// ActionBackup pushes a backup bundle of the cluster, or of every
+	// cluster of the namespace, to a backup target
+	ActionBackup Action = "backup"
+)
+
+// Actions lists the supported actions
+var Actions = []Action{ActionHibernate, ActionWake, ActionScale, ActionUpgrade, ActionBackup}
+
+// Schedule is a recurring action on a cluster
+type Schedule struct {
+	ID string `json:"id"`
+	// Cron is a five field cron expression or a macro such as @daily
+	Cron string `json:"cron"`
+	// TimeZone is the IANA time zone of Cron, UTC when empty
+	TimeZone  string `json:"timeZone,omitempty"`
+	Action    Action `json:"action"`
+	Namespace string `json:"namespace"`
+	// Cluster is empty for backups of all clusters of the namespace
+	Cluster string `json:"cluster,omitempty"`
+	// MachineDeployment and Replicas are the target of a scale action
+	MachineDeployment string `json:"machineDeployment,omitempty"`
+	Replicas          *int32 `json:"replicas,omitempty"`
+	// Version is the target of an upgrade action
+	Version        string `json:"version,omitempty"`
+	UpgradeWorkers bool   `json:"upgradeWorkers,omitempty"`
+	// ControlPlane also hibernates the control plane
+	ControlPlane bool `json:"controlPlane,omitempty"`
+	// BackupTarget is the backup target of a backup action, BackupKeep the
+	// number of backups kept per cluster instead of the count of the
+	// target's retention policy, and IncludeSecrets adds the Secrets of the
+	// clusters to the bundles
+	BackupTarget   string `json:"backupTarget,omitempty"`
+	BackupKeep     int    `json:"backupKeep,omitempty"`
+	IncludeSecrets bool   `json:"includeSecrets,omitempty"`
+	// MaintenanceOverride runs the action outside the maintenance windows
+	// of the cluster
+	MaintenanceOverride bool   `json:"maintenanceOverride,omitempty"`
+	Description         string `json:"description,omitempty"`
+	Requester           string `json:"requester,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	// LastRun is the scheduled time of the last run, claimed by one replica
+	LastRun   *time.Time `json:"lastRun,omitempty"`
+	LastJob   string     `json:"lastJob,omitempty"`
+	LastError string     `json:"lastError,omitempty"`
+
+	// resourceVersion of the ConfigMap, to claim runs with optimistic locking
+	resourceVersion string
+}
+
+// Validate checks the cron expression, time zone and the arguments of the
+// action
+func (s *Schedule) Validate() error {
+	if _, err := ParseCron(s.Cron); err != nil {
+		return err
+	}
+	if _, err := time.LoadLocation(s.TimeZone); err != nil {
+		return fmt.Errorf("invalid time zone %q: %w", s.TimeZone, err)
+	}
+	if s.Namespace == "" || (s.Cluster == "" && s.Action != ActionBackup) {
+		return fmt.Errorf("namespace and cluster are required")
+	}
+	switch s.Action {
+	case ActionHibernate, ActionWake:
+	case ActionScale:
+		if s.MachineDeployment == "" || s.Replicas == nil {
+			return fmt.Errorf("a scale schedule needs a machine deployment and replicas")
+		}
+		if *s.Replicas < 0 {
+			return fmt.Errorf("replicas must not be negative")
+		}
+	case ActionUpgrade:
+		if s.Version == "" {
+			return fmt.Errorf("an upgrade schedule needs a version")
+		}
+	case ActionBackup:
+		if s.BackupTarget == "" {
+			return fmt.Errorf("a backup schedule needs a backup target")
+		}
+		if s.BackupKeep < 0 {
+			return fmt.Errorf("the number of backups to keep must not be negative")
+		}
+	default:
+		return fmt.Errorf("invalid action %q, supported actions: %v", s.Action, Actions)
+	}
+	return nil
+}
+
+// Next returns the first time the schedule runs after t
+func (s *Schedule) Next(t time.Time) time.Time {
+	cron, err := ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}
+	}
+	location, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return time.Time{}
+	}
+	return cron.Next(t.In(location))
+}
+
+// due returns the last time the schedule should have run at or before now and
+// after its last run, or the zero time if no run is due
+func (s *Schedule) due(now time.Time) time.Time {
+	since := s.CreatedAt
+	if s.LastRun != nil {
+		since = *s.LastRun
+	}
+	var due time.Time
+	for next := s.Next(since); !next.IsZero() && !next.After(now); next = s.Next(next) {
+		due = next
+	}
+	return due
+}
+
+// Covers reports whether the schedule acts on a cluster, directly or as one
+// of the clusters of its namespace
+func (s *Schedule) Covers(namespace, cluster string) bool {
+	return s.Namespace == namespace && (s.Cluster == cluster || s.Cluster == "")
+}
+
+// Target describes what the schedule acts on
+func (s *Schedule) Target() string {
+	switch s.Action {
+	case ActionScale:
+		return fmt.Sprintf("machine deployment %s/%s to %d replicas", s.Namespace, s.MachineDeployment, *s.Replicas)
+	case ActionUpgrade:
+		return fmt.Sprintf("cluster %s/%s to %s", s.Namespace, s.Cluster, s.Version)
+	case ActionBackup:
+		if s.Cluster == "" {
+			return fmt.Sprintf("clusters of namespace %s to %s", s.Namespace, s.BackupTarget)
+		}
+		return fmt.Sprintf("cluster %s/%s to %s", s.Namespace, s.Cluster, s.BackupTarget)
+	}
+	return fmt.Sprintf("cluster %s/%s", s.Namespace, s.Cluster)
+}
+
+// Store keeps schedules as ConfigMaps in a namespace of the management cluster
+type Store struct {
+	clientset kubernetes.Interface
+	namespace string
+}
+
+// NewStore creates a schedule store in namespace
+func NewStore(clientset kubernetes.Interface, namespace string) *Store {
+	return &Store{clientset: clientset, namespace: namespace}
+}
+
+// Namespace returns where the schedules are stored
+func (s *Store) Namespace() string {
+	return s.namespace
+}
+
+// Create validates and saves a new schedule
+func (s *Store) Create(ctx context.Context, schedule Schedule) (*Schedule, error) {
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+	id, err := randomHex(4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate schedule ID: %w", err)
+	}
+	schedule.ID = "schedule-" + id
+	schedule.CreatedAt = time.Now().UTC()
+	schedule.LastRun = nil
+
+	configMap, err := s.configMap(&schedule)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to save schedule: %w", err)
+	}
+	return &schedule, nil
+}
+
+// Get returns a schedule by ID
+func (s *Store) Get(ctx context.Context, id string) (*Schedule, error) {
+	configMap, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Get(ctx, configMapPrefix+id, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || (err == nil && configMap.Labels[ScheduleLabel] != "true") {
+		return nil, fmt.Errorf("schedule %s not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get schedule %s: %w", id, err)
+	}
+	return decode(configMap)
+}
+
+// List returns all schedules, oldest first
+func (s *Store) List(ctx context.Context) ([]Schedule, error) {
+	configMaps, err := s.clientset.CoreV1().ConfigMaps(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: ScheduleLabel + "=true"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	schedules := make([]Schedule, 0, len(configMaps.Items))
+	for i := range configMaps.Items {
+		schedule, err := decode(&configMaps.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, *schedule)
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].CreatedAt.Before(schedules[j].CreatedAt)
+	})
+	return schedules, nil
+}
+
+// Delete removes a schedule. A run already started keeps going.
+func (s *Store) Delete(ctx context.Context, id string) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	err := s.clientset.CoreV1().ConfigMaps(s.namespace).Delete(ctx, configMapPrefix+id, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete schedule %s: %w", id, err)
+	}
+	return nil
+}
+
+// update saves a modified schedule. It fails with a conflict if the
+// schedule changed since it was read.
+func (s *Store) update(ctx context.Context, schedule *Schedule) (*Schedule, error) {
+	configMap, err := s.configMap(schedule)
+	if err != nil {
+		return nil, err
+	}
+	configMap.ResourceVersion = schedule.resourceVersion
+	updated, err := s.clientset.CoreV1().ConfigMaps(s.namespace).Update(ctx, configMap, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return decode(updated)
+}
+
+// configMap encodes a schedule
+func (s *Store) configMap(schedule *Schedule) (*corev1.ConfigMap, error) {
+	data, err := json.MarshalIndent(schedule, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schedule: %w", err)
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      configMapPrefix + schedule.ID,
+			Namespace: s.namespace,
+			Labels: map[string]string{
+				ManagedByLabel: "mcp-capi",
+				ScheduleLabel:  "true",
+			},
+		},
+		Data: map[string]string{dataKey: string(data)},
+	}, nil
+}
+
+// decode reads the schedule of a ConfigMap
+func decode(configMap *corev1.ConfigMap) (*Schedule, error) {
+	schedule := &Schedule{}
+	if err := json.Unmarshal([]byte(configMap.Data[dataKey]), schedule); err != nil {
+		return nil, fmt.Errorf("failed to parse schedule in ConfigMap %s: %w", configMap.Name, err)
+	}
+	schedule.resourceVersion = configMap.ResourceVersion
+	return schedule, nil
+}
+
+// randomHex returns n random bytes as hex
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
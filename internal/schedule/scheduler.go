@@ -0,0 +1,288 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/backup"
+	"github.com/giantswarm/mcp-capi/internal/jobs"
+	"github.com/giantswarm/mcp-capi/internal/locks"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// DefaultInterval is how often the scheduler checks for due runs
+	DefaultInterval = time.Minute
+	// DefaultStartingDeadline is how late a run may start, e.g. after the
+	// server was down; older runs are skipped
+	DefaultStartingDeadline = time.Hour
+	// DefaultHibernateTimeout bounds the wait of a scheduled hibernation
+	DefaultHibernateTimeout = 15 * time.Minute
+)
+
+// Client is the part of the CAPI client used by scheduled actions
+type Client interface {
+	HibernateCluster(ctx context.Context, namespace, name string, opts capi.HibernateOptions) (*capi.Hibernation, error)
+	WakeCluster(ctx context.Context, namespace, name string) (*capi.Hibernation, error)
+	ScaleMachineDeployment(ctx context.Context, namespace, name string, replicas int32) error
+	UpgradeCluster(ctx context.Context, opts capi.UpgradeClusterOptions) error
+	ListClusters(ctx context.Context, namespace string, listOpts ...capi.ListOption) (*clusterv1.ClusterList, error)
+	BackupCluster(ctx context.Context, opts capi.BackupClusterOptions) (string, error)
+}
+
+// Config contains the settings of the scheduler
+type Config struct {
+	// Interval is how often due runs are checked
+	Interval time.Duration
+	// StartingDeadline is how late a run may still start
+	StartingDeadline time.Duration
+	// Gate, when set, may refuse a run at the given time, e.g. outside the
+	// maintenance windows of the cluster. Schedules with
+	// MaintenanceOverride and backups, which do not change clusters, are
+	// not checked.
+	Gate func(ctx context.Context, schedule *Schedule, now time.Time) error
+	// BackupTargets are the targets of backup schedules
+	BackupTargets *backup.Targets
+	// Locks, when set, locks the cluster of a run changing it, so it does
+	// not race tool calls changing the same cluster. Leases are taken
+	// through the client when it is a *capi.Client.
+	Locks *locks.Manager
+}
+
+// Scheduler starts the due runs of the schedules of a store as jobs
+type Scheduler struct {
+	store  *Store
+	client Client
+	jobs   *jobs.Manager
+	config Config
+
+	// now is overridable for tests
+	now func() time.Time
+}
+
+// NewScheduler creates a scheduler running the actions of the schedules in
+// store with client, usually of the management cluster holding the store
+func NewScheduler(store *Store, client Client, jobManager *jobs.Manager, config Config) *Scheduler {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.StartingDeadline <= 0 {
+		config.StartingDeadline = DefaultStartingDeadline
+	}
+	return &Scheduler{store: store, client: client, jobs: jobManager, config: config, now: time.Now}
+}
+
+// Store returns where the schedules are stored
+func (s *Scheduler) Store() *Store {
+	return s.store
+}
+
+// Run checks for due runs every interval until ctx ends
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunDue(ctx); err != nil {
+			slog.ErrorContext(ctx, "Failed to check for due scheduled runs", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue starts the runs that are due
+func (s *Scheduler) RunDue(ctx context.Context) error {
+	schedules, err := s.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	for i := range schedules {
+		schedule := &schedules[i]
+		due := schedule.due(now)
+		if due.IsZero() {
+			continue
+		}
+		if err := s.start(ctx, schedule, due, now); err != nil {
+			slog.ErrorContext(ctx, "Failed to start scheduled run", "schedule", schedule.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// start claims the run of a schedule due at the given time and starts it,
+// unless it is past the starting deadline or refused by the gate
+func (s *Scheduler) start(ctx context.Context, schedule *Schedule, due, now time.Time) error {
+	skipped := ""
+	if now.Sub(due) > s.config.StartingDeadline {
+		skipped = fmt.Sprintf("run at %s missed its starting deadline", due.Format(time.RFC3339))
+	} else if s.config.Gate != nil && !schedule.MaintenanceOverride && schedule.Action != ActionBackup {
+		if err := s.config.Gate(ctx, schedule, now); err != nil {
+			skipped = fmt.Sprintf("run at %s skipped: %v", due.Format(time.RFC3339), err)
+		}
+	}
+	schedule.LastRun = &due
+	schedule.LastJob = ""
+	schedule.LastError = skipped
+
+	// Claim the run first, so another replica does not start it as well
+	_, err := s.store.update(ctx, schedule)
+	if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to claim run: %w", err)
+	}
+	if skipped != "" {
+		slog.InfoContext(ctx, "Skipped scheduled run", "schedule", schedule.ID, "reason", skipped)
+		return nil
+	}
+
+	c := s.client
+	job, err := s.jobs.Start(ctx, "scheduled-"+string(schedule.Action), schedule.Namespace, schedule.Target(), schedule.Requester, func(ctx context.Context, logf func(format string, args ...any)) (any, error) {
+		logf("Running schedule %s (%s): %s %s", schedule.ID, schedule.Cron, schedule.Action, schedule.Target())
+		var result any
+		var err error
+		if schedule.Action == ActionBackup {
+			result, err = s.backup(ctx, schedule, logf)
+		} else {
+			result, err = s.runLocked(ctx, c, schedule)
+		}
+		if err != nil {
+			s.record(schedule.ID, due, func(schedule *Schedule) { schedule.LastError = err.Error() })
+		}
+		return result, err
+	})
+	if err != nil {
+		s.record(schedule.ID, due, func(schedule *Schedule) { schedule.LastError = fmt.Sprintf("failed to start job: %v", err) })
+		return err
+	}
+	s.record(schedule.ID, due, func(schedule *Schedule) { schedule.LastJob = job.ID })
+	return nil
+}
+
+// record saves the job or outcome of the run due at the given time, unless
+// the schedule was canceled or has run again since. Conflicts are retried,
+// since the job of a run may finish while its ID is saved.
+func (s *Scheduler) record(id string, due time.Time, fn func(*Schedule)) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	for attempt := 0; attempt < 3; attempt++ {
+		schedule, err := s.store.Get(ctx, id)
+		if err != nil || schedule.LastRun == nil || !schedule.LastRun.Equal(due) {
+			return
+		}
+		fn(schedule)
+		if _, err = s.store.update(ctx, schedule); !apierrors.IsConflict(err) {
+			if err != nil {
+				slog.Error("Failed to save scheduled run", "schedule", id, "error", err)
+			}
+			return
+		}
+	}
+}
+
+// runLocked performs the action of a schedule while holding the lock of its
+// cluster
+func (s *Scheduler) runLocked(ctx context.Context, c Client, schedule *Schedule) (any, error) {
+	if s.config.Locks == nil {
+		return runAction(ctx, c, schedule)
+	}
+	leaseClient, _ := c.(*capi.Client)
+	target := locks.Target{Namespace: schedule.Namespace, Name: schedule.Cluster}
+	release, err := s.config.Locks.Acquire(ctx, leaseClient, []locks.Target{target}, "schedule "+schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return runAction(ctx, c, schedule)
+}
+
+// runAction performs the action of a schedule
+func runAction(ctx context.Context, c Client, schedule *Schedule) (any, error) {
+	switch schedule.Action {
+	case ActionHibernate:
+		return c.HibernateCluster(ctx, schedule.Namespace, schedule.Cluster, capi.HibernateOptions{
+			ControlPlane: schedule.ControlPlane,
+			Wait:         capi.WaitOptions{Timeout: DefaultHibernateTimeout},
+		})
+	case ActionWake:
+		return c.WakeCluster(ctx, schedule.Namespace, schedule.Cluster)
+	case ActionScale:
+		err := c.ScaleMachineDeployment(ctx, schedule.Namespace, schedule.MachineDeployment, *schedule.Replicas)
+		return nil, err
+	case ActionUpgrade:
+		err := c.UpgradeCluster(ctx, capi.UpgradeClusterOptions{
+			Namespace:      schedule.Namespace,
+			Name:           schedule.Cluster,
+			TargetVersion:  schedule.Version,
+			UpgradeWorkers: schedule.UpgradeWorkers,
+		})
+		return nil, err
+	}
+	return nil, fmt.Errorf("invalid action %q", schedule.Action)
+}
+
+// backup pushes bundles of the cluster of a backup schedule, or of every
+// cluster of its namespace, to its target. A failed cluster does not stop
+// the backups of the others.
+func (s *Scheduler) backup(ctx context.Context, schedule *Schedule, logf func(format string, args ...any)) ([]*backup.Upload, error) {
+	target, err := s.config.BackupTargets.Get(schedule.BackupTarget)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.BackupKeep > 0 {
+		target = target.WithKeep(schedule.BackupKeep)
+	}
+	clusters := []string{schedule.Cluster}
+	if schedule.Cluster == "" {
+		list, err := s.client.ListClusters(ctx, schedule.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		clusters = clusters[:0]
+		for _, cluster := range list.Items {
+			clusters = append(clusters, cluster.Name)
+		}
+		logf("Backing up %d clusters of namespace %s", len(clusters), schedule.Namespace)
+	}
+
+	var uploads []*backup.Upload
+	var errs []error
+	for _, cluster := range clusters {
+		bundle, err := s.client.BackupCluster(ctx, capi.BackupClusterOptions{
+			Namespace:      schedule.Namespace,
+			Name:           cluster,
+			IncludeSecrets: schedule.IncludeSecrets,
+			OutputFormat:   "yaml",
+		})
+		var upload *backup.Upload
+		if err == nil {
+			upload, err = target.Upload(ctx, schedule.Namespace, cluster, "yaml", []byte(bundle), s.now())
+		}
+		if err != nil {
+			logf("Backup of cluster %s/%s failed: %v", schedule.Namespace, cluster, err)
+			errs = append(errs, fmt.Errorf("cluster %s: %w", cluster, err))
+			continue
+		}
+		logf("Pushed %s to %s", upload.Backup.Key, target.Location())
+		if len(upload.Pruned) > 0 {
+			logf("Retention (%s) deleted %d older backups of cluster %s", target.Retention, len(upload.Pruned), cluster)
+		}
+		if upload.PruneError != "" {
+			logf("Applying the retention policy to cluster %s failed: %s", cluster, upload.PruneError)
+		}
+		uploads = append(uploads, upload)
+	}
+	return uploads, errors.Join(errs...)
+}
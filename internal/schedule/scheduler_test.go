@@ -0,0 +1,395 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/backup"
+	"github.com/giantswarm/mcp-capi/internal/jobs"
+	"github.com/giantswarm/mcp-capi/internal/locks"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// fakeClient records the scheduled actions
+type fakeClient struct {
+	mu      sync.Mutex
+	actions []string
+	err     error
+}
+
+func (f *fakeClient) record(action string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.actions = append(f.actions, action)
+	return f.err
+}
+
+func (f *fakeClient) HibernateCluster(ctx context.Context, namespace, name string, opts capi.HibernateOptions) (*capi.Hibernation, error) {
+	return &capi.Hibernation{Namespace: namespace, Cluster: name}, f.record("hibernate " + namespace + "/" + name)
+}
+
+func (f *fakeClient) WakeCluster(ctx context.Context, namespace, name string) (*capi.Hibernation, error) {
+	return &capi.Hibernation{Namespace: namespace, Cluster: name}, f.record("wake " + namespace + "/" + name)
+}
+
+func (f *fakeClient) ScaleMachineDeployment(ctx context.Context, namespace, name string, replicas int32) error {
+	return f.record("scale " + namespace + "/" + name)
+}
+
+func (f *fakeClient) UpgradeCluster(ctx context.Context, opts capi.UpgradeClusterOptions) error {
+	return f.record("upgrade " + opts.Namespace + "/" + opts.Name + " " + opts.TargetVersion)
+}
+
+func (f *fakeClient) ListClusters(ctx context.Context, namespace string, listOpts ...capi.ListOption) (*clusterv1.ClusterList, error) {
+	list := &clusterv1.ClusterList{}
+	for _, name := range []string{"dev", "prod"} {
+		list.Items = append(list.Items, clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}})
+	}
+	return list, nil
+}
+
+func (f *fakeClient) BackupCluster(ctx context.Context, opts capi.BackupClusterOptions) (string, error) {
+	if opts.Name == "broken" {
+		return "", errors.New("cluster broken not found")
+	}
+	return "kind: Cluster\n", f.record("backup " + opts.Namespace + "/" + opts.Name)
+}
+
+// mapStore is a backup.Store in memory
+type mapStore map[string][]byte
+
+func (m mapStore) Put(ctx context.Context, key string, data []byte) error {
+	m[key] = data
+	return nil
+}
+
+func (m mapStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return m[key], nil
+}
+
+func (m mapStore) List(ctx context.Context, prefix string) ([]backup.StoredObject, error) {
+	var objects []backup.StoredObject
+	for key := range m {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, backup.StoredObject{Key: key})
+		}
+	}
+	return objects, nil
+}
+
+func (m mapStore) Delete(ctx context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
+
+func (m mapStore) Location() string {
+	return "memory://"
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(k8sfake.NewClientset(), "mcp-capi")
+
+	invalid := []Schedule{
+		{Cron: "0 22 * *", Action: ActionHibernate, Namespace: "org-acme", Cluster: "dev"},
+		{Cron: "0 22 * * *", TimeZone: "Mars/Olympus", Action: ActionHibernate, Namespace: "org-acme", Cluster: "dev"},
+		{Cron: "0 22 * * *", Action: ActionScale, Namespace: "org-acme", Cluster: "dev"},
+		{Cron: "0 22 * * *", Action: ActionUpgrade, Namespace: "org-acme", Cluster: "dev"},
+		{Cron: "0 22 * * *", Action: "delete", Namespace: "org-acme", Cluster: "dev"},
+	}
+	for _, schedule := range invalid {
+		if _, err := store.Create(ctx, schedule); err == nil {
+			t.Errorf("Create(%+v) should fail", schedule)
+		}
+	}
+
+	created, err := store.Create(ctx, Schedule{Cron: "0 22 * * 1-5", TimeZone: "Europe/Berlin", Action: ActionHibernate, Namespace: "org-acme", Cluster: "dev"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := store.Create(ctx, Schedule{Cron: "0 7 * * 1-5", Action: ActionWake, Namespace: "org-acme", Cluster: "dev"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	got, err := store.Get(ctx, created.ID)
+	if err != nil || got.Cron != "0 22 * * 1-5" || got.TimeZone != "Europe/Berlin" {
+		t.Errorf("Get() = %+v, %v", got, err)
+	}
+	schedules, err := store.List(ctx)
+	if err != nil || len(schedules) != 2 || schedules[0].ID != created.ID {
+		t.Fatalf("List() = %+v, %v", schedules, err)
+	}
+
+	if err := store.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(ctx, created.ID); err == nil {
+		t.Error("Get() of a deleted schedule should fail")
+	}
+	if err := store.Delete(ctx, created.ID); err == nil {
+		t.Error("Delete() of a deleted schedule should fail")
+	}
+}
+
+func TestSchedulerRunDue(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(k8sfake.NewClientset(), "mcp-capi")
+	client := &fakeClient{}
+	mgr := jobs.NewManager(jobs.Config{})
+	scheduler := NewScheduler(store, client, mgr, Config{})
+
+	// Created on a Monday at 12:00 UTC
+	created := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+	save := func(schedule Schedule) *Schedule {
+		saved, err := store.Create(ctx, schedule)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		saved.CreatedAt = created
+		saved, err = store.update(ctx, saved)
+		if err != nil {
+			t.Fatalf("update() error = %v", err)
+		}
+		return saved
+	}
+	nightly := save(Schedule{Cron: "0 22 * * *", Action: ActionHibernate, Namespace: "org-acme", Cluster: "dev"})
+	weekend := save(Schedule{Cron: "0 18 * * fri", Action: ActionScale, Namespace: "org-acme", Cluster: "dev", MachineDeployment: "dev-workers", Replicas: ptr.To[int32](0)})
+
+	run := func(now time.Time) {
+		t.Helper()
+		scheduler.now = func() time.Time { return now }
+		if err := scheduler.RunDue(ctx); err != nil {
+			t.Fatalf("RunDue() error = %v", err)
+		}
+		for _, job := range mgr.List() {
+			if _, err := mgr.Wait(ctx, job.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Nothing due yet
+	run(created.Add(9 * time.Hour))
+	if len(client.actions) != 0 {
+		t.Fatalf("actions = %v, want none", client.actions)
+	}
+
+	// The nightly hibernation runs once
+	run(created.Add(10*time.Hour + 30*time.Second))
+	run(created.Add(10*time.Hour + 45*time.Second))
+	if len(client.actions) != 1 || client.actions[0] != "hibernate org-acme/dev" {
+		t.Fatalf("actions = %v, want one hibernation", client.actions)
+	}
+	got, _ := store.Get(ctx, nightly.ID)
+	if got.LastRun == nil || !got.LastRun.Equal(created.Add(10*time.Hour)) || got.LastJob == "" || got.LastError != "" {
+		t.Errorf("schedule after run = %+v", got)
+	}
+
+	// Runs missed for longer than the starting deadline are skipped, the
+	// failure of the weekend scale-down is recorded
+	client.err = errors.New("machine deployment not found")
+	run(time.Date(2026, time.March, 6, 18, 5, 0, 0, time.UTC))
+	if len(client.actions) != 2 || client.actions[1] != "scale org-acme/dev-workers" {
+		t.Fatalf("actions = %v, want the scale-down only", client.actions)
+	}
+	got, _ = store.Get(ctx, nightly.ID)
+	if got.LastJob != "" || got.LastError == "" || !got.LastRun.Equal(time.Date(2026, time.March, 5, 22, 0, 0, 0, time.UTC)) {
+		t.Errorf("missed schedule = %+v", got)
+	}
+	got, _ = store.Get(ctx, weekend.ID)
+	if got.LastError != "machine deployment not found" || got.LastJob == "" {
+		t.Errorf("failed schedule = %+v", got)
+	}
+}
+
+func TestSchedulerLocks(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(k8sfake.NewClientset(), "mcp-capi")
+	client := &fakeClient{}
+	mgr := jobs.NewManager(jobs.Config{})
+	clusterLocks, err := locks.NewManager(locks.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduler := NewScheduler(store, client, mgr, Config{Locks: clusterLocks})
+
+	created, err := store.Create(ctx, Schedule{Cron: "* * * * *", Action: ActionWake, Namespace: "org-acme", Cluster: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := func(due time.Time) *Schedule {
+		t.Helper()
+		schedule, err := store.Get(ctx, created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := scheduler.start(ctx, schedule, due, due); err != nil {
+			t.Fatalf("start() error = %v", err)
+		}
+		for _, job := range mgr.List() {
+			if _, err := mgr.Wait(ctx, job.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		schedule, _ = store.Get(ctx, created.ID)
+		return schedule
+	}
+
+	// A tool call changing the cluster holds its lock
+	release, err := clusterLocks.Acquire(ctx, nil, []locks.Target{{Namespace: "org-acme", Name: "dev"}}, "capi_scale_cluster by alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	due := created.CreatedAt.Truncate(time.Minute).Add(time.Minute)
+	got := run(due)
+	if len(client.actions) != 0 || !strings.Contains(got.LastError, "capi_scale_cluster by alice") {
+		t.Errorf("run on a locked cluster: actions = %v, schedule = %+v", client.actions, got)
+	}
+
+	release()
+	got = run(due.Add(time.Minute))
+	if len(client.actions) != 1 || got.LastError != "" {
+		t.Errorf("run on an unlocked cluster: actions = %v, schedule = %+v", client.actions, got)
+	}
+	if held := clusterLocks.Locks(); len(held) != 0 {
+		t.Errorf("locks after the run = %+v", held)
+	}
+}
+
+func TestSchedulerClaimConflict(t *testing.T) {
+	ctx := context.Background()
+	clientset := k8sfake.NewClientset()
+	store := NewStore(clientset, "mcp-capi")
+	client := &fakeClient{}
+	mgr := jobs.NewManager(jobs.Config{})
+	scheduler := NewScheduler(store, client, mgr, Config{})
+
+	created, err := store.Create(ctx, Schedule{Cron: "* * * * *", Action: ActionWake, Namespace: "org-acme", Cluster: "dev"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Another replica claims the run first
+	clientset.PrependReactor("update", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewConflict(corev1.Resource("configmaps"), "mcp-capi-"+created.ID, errors.New("object was modified"))
+	})
+	due := created.CreatedAt.Truncate(time.Minute).Add(time.Minute)
+	if err := scheduler.start(ctx, created, due, due); err != nil {
+		t.Fatalf("start() error = %v", err)
+	}
+	if len(mgr.List()) != 0 {
+		t.Errorf("a run claimed by another replica should not start, jobs = %+v", mgr.List())
+	}
+}
+
+func TestSchedulerGate(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(k8sfake.NewClientset(), "mcp-capi")
+	client := &fakeClient{}
+	mgr := jobs.NewManager(jobs.Config{})
+	closed := errors.New("cluster org-acme/prod is outside the maintenance window")
+	scheduler := NewScheduler(store, client, mgr, Config{
+		Gate: func(ctx context.Context, schedule *Schedule, now time.Time) error {
+			if schedule.Cluster == "prod" {
+				return closed
+			}
+			return nil
+		},
+	})
+
+	for _, override := range []bool{false, true} {
+		created, err := store.Create(ctx, Schedule{Cron: "* * * * *", Action: ActionWake, Namespace: "org-acme", Cluster: "prod", MaintenanceOverride: override})
+		if err != nil {
+			t.Fatal(err)
+		}
+		due := created.CreatedAt.Truncate(time.Minute).Add(time.Minute)
+		if err := scheduler.start(ctx, created, due, due); err != nil {
+			t.Fatalf("start() error = %v", err)
+		}
+		for _, job := range mgr.List() {
+			if _, err := mgr.Wait(ctx, job.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got, _ := store.Get(ctx, created.ID)
+		if override {
+			if len(client.actions) != 1 || got.LastJob == "" {
+				t.Errorf("run with override: actions = %v, schedule = %+v", client.actions, got)
+			}
+		} else if len(client.actions) != 0 || got.LastJob != "" || !strings.Contains(got.LastError, closed.Error()) {
+			t.Errorf("refused run: actions = %v, schedule = %+v", client.actions, got)
+		}
+	}
+}
+
+func TestSchedulerBackup(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(k8sfake.NewClientset(), "mcp-capi")
+	client := &fakeClient{}
+	mgr := jobs.NewManager(jobs.Config{})
+	objects := mapStore{}
+	scheduler := NewScheduler(store, client, mgr, Config{
+		// Backups do not change clusters and ignore the maintenance windows
+		Gate: func(ctx context.Context, schedule *Schedule, now time.Time) error {
+			return errors.New("outside the maintenance window")
+		},
+		BackupTargets: backup.NewTargets(backup.NewTarget("s3", objects, backup.Retention{Keep: 5})),
+	})
+
+	if _, err := store.Create(ctx, Schedule{Cron: "@daily", Action: ActionBackup, Namespace: "org-acme"}); err == nil {
+		t.Error("Create() of a backup schedule without a target should fail")
+	}
+	namespace, err := store.Create(ctx, Schedule{Cron: "@daily", Action: ActionBackup, Namespace: "org-acme", BackupTarget: "s3", BackupKeep: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if namespace.Target() != "clusters of namespace org-acme to s3" || !namespace.Covers("org-acme", "prod") || namespace.Covers("org-globex", "prod") {
+		t.Errorf("Target() = %q", namespace.Target())
+	}
+	broken, err := store.Create(ctx, Schedule{Cron: "@daily", Action: ActionBackup, Namespace: "org-acme", Cluster: "broken", BackupTarget: "s3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := namespace.CreatedAt.Truncate(24 * time.Hour)
+	for day := 1; day <= 3; day++ {
+		now := start.Add(time.Duration(day) * 24 * time.Hour)
+		scheduler.now = func() time.Time { return now }
+		if err := scheduler.RunDue(ctx); err != nil {
+			t.Fatal(err)
+		}
+		for _, job := range mgr.List() {
+			_, _ = mgr.Wait(ctx, job.ID)
+		}
+	}
+
+	if len(client.actions) != 6 {
+		t.Errorf("actions = %v, want dev and prod backed up three times", client.actions)
+	}
+	var keys []string
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	if len(keys) != 4 {
+		t.Errorf("stored backups = %v, want two per cluster", keys)
+	}
+	got, _ := store.Get(ctx, namespace.ID)
+	if got.LastJob == "" || got.LastError != "" {
+		t.Errorf("namespace schedule = %+v, want a successful run", got)
+	}
+	got, _ = store.Get(ctx, broken.ID)
+	if !strings.Contains(got.LastError, "cluster broken not found") {
+		t.Errorf("broken schedule = %+v, want the failure recorded", got)
+	}
+}
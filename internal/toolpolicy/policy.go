@@ -0,0 +1,134 @@
+// Package toolpolicy decides which MCP tools a server deployment exposes.
+//
+// A Policy consists of allow and deny rules. Each rule is either a glob
+// pattern matched against the tool name (e.g. "capi_aws_*") or a group
+// reference of the form "group:<name>" (e.g. "group:destructive"). A tool is
+// enabled when it matches at least one allow rule (or the allow list is empty)
+// and does not match any deny rule.
+package toolpolicy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// groupPrefix marks a rule as a tool group reference
+const groupPrefix = "group:"
+
+// Policy describes which tools are enabled
+type Policy struct {
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+}
+
+// LoadFile reads a policy from a YAML file
+func LoadFile(filename string) (*Policy, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tool policy file: %w", err)
+	}
+
+	policy := &Policy{}
+	if err := yaml.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse tool policy file: %w", err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ParseList splits a comma-separated list of rules
+func ParseList(value string) []string {
+	var rules []string
+	for _, rule := range strings.Split(value, ",") {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// Validate checks that all rules are well-formed
+func (p *Policy) Validate() error {
+	for _, rule := range append(append([]string{}, p.Allow...), p.Deny...) {
+		if err := ValidateRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateRule checks that a rule is a valid glob pattern or group reference
+func ValidateRule(rule string) error {
+	if strings.HasPrefix(rule, groupPrefix) {
+		if strings.TrimPrefix(rule, groupPrefix) == "" {
+			return fmt.Errorf("invalid tool rule %q: missing group name", rule)
+		}
+		return nil
+	}
+	if _, err := path.Match(rule, ""); err != nil {
+		return fmt.Errorf("invalid tool rule %q: %w", rule, err)
+	}
+	return nil
+}
+
+// Merge returns a policy with the rules of both policies
+func (p *Policy) Merge(other *Policy) *Policy {
+	if other == nil {
+		return p
+	}
+	return &Policy{
+		Allow: append(append([]string{}, p.Allow...), other.Allow...),
+		Deny:  append(append([]string{}, p.Deny...), other.Deny...),
+	}
+}
+
+// IsEmpty reports whether the policy has no rules and therefore enables everything
+func (p *Policy) IsEmpty() bool {
+	return p == nil || (len(p.Allow) == 0 && len(p.Deny) == 0)
+}
+
+// Enabled reports whether a tool with the given name and groups is enabled
+func (p *Policy) Enabled(name string, groups []string) bool {
+	if p.IsEmpty() {
+		return true
+	}
+
+	for _, rule := range p.Deny {
+		if Matches(rule, name, groups) {
+			return false
+		}
+	}
+
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, rule := range p.Allow {
+		if Matches(rule, name, groups) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches checks a single rule against a tool
+func Matches(rule, name string, groups []string) bool {
+	if strings.HasPrefix(rule, groupPrefix) {
+		group := strings.TrimPrefix(rule, groupPrefix)
+		for _, g := range groups {
+			if g == group {
+				return true
+			}
+		}
+		return false
+	}
+
+	matched, err := path.Match(rule, name)
+	return err == nil && matched
+}
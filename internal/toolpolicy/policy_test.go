@@ -0,0 +1,95 @@
+package toolpolicy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPolicyEnabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *Policy
+		tool   string
+		groups []string
+		want   bool
+	}{
+		{
+			name:   "empty policy enables everything",
+			policy: &Policy{},
+			tool:   "capi_delete_cluster",
+			want:   true,
+		},
+		{
+			name:   "deny glob",
+			policy: &Policy{Deny: []string{"capi_aws_*"}},
+			tool:   "capi_aws_get_cluster",
+			want:   false,
+		},
+		{
+			name:   "deny glob does not affect other tools",
+			policy: &Policy{Deny: []string{"capi_aws_*"}},
+			tool:   "capi_azure_get_cluster",
+			want:   true,
+		},
+		{
+			name:   "deny group",
+			policy: &Policy{Deny: []string{"group:destructive"}},
+			tool:   "capi_delete_cluster",
+			groups: []string{"clusters", "destructive"},
+			want:   false,
+		},
+		{
+			name:   "allow list excludes unlisted tools",
+			policy: &Policy{Allow: []string{"group:readonly"}},
+			tool:   "capi_scale_cluster",
+			groups: []string{"clusters"},
+			want:   false,
+		},
+		{
+			name:   "deny wins over allow",
+			policy: &Policy{Allow: []string{"capi_*"}, Deny: []string{"capi_delete_*"}},
+			tool:   "capi_delete_machine",
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Enabled(tt.tool, tt.groups); got != tt.want {
+				t.Errorf("Enabled(%q) = %v, want %v", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "tools.yaml")
+	content := "allow:\n  - capi_*\ndeny:\n  - group:destructive\n"
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := LoadFile(filename)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if len(policy.Allow) != 1 || len(policy.Deny) != 1 {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+
+	if err := os.WriteFile(filename, []byte("deny:\n  - \"capi_[\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(filename); err == nil {
+		t.Error("LoadFile() should reject malformed patterns")
+	}
+}
+
+func TestParseList(t *testing.T) {
+	got := ParseList(" capi_aws_*, group:destructive ,,")
+	if len(got) != 2 || got[0] != "capi_aws_*" || got[1] != "group:destructive" {
+		t.Errorf("ParseList() = %v", got)
+	}
+}
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerAddonTools adds the tools inspecting the core addons of workload
+// clusters
+func registerAddonTools(s Registry, serverCtx *ServerContext) {
+	addonsStatusTool := addonsStatusParams.NewTool(
+		"capi_addons_status",
+		"Check the core addons of a workload cluster (CNI such as Calico or Cilium, cloud controller manager, CSI drivers, CoreDNS and kube-proxy) with the health of their DaemonSets and Deployments, explaining why a ready cluster has NotReady nodes",
+	)
+	addTool(s, addonsStatusTool, createAddonsStatusHandler(serverCtx))
+
+	installCNITool := installCNIParams.NewTool(
+		"capi_install_cni",
+		"Install Calico or Cilium at a pinned version in a newly provisioned workload cluster, configured with the pod CIDR of the Cluster, so its nodes become ready",
+	)
+	addTool(s, installCNITool, createInstallCNIHandler(serverCtx))
+}
+
+// addonsStatusParams declares the arguments of capi_addons_status
+var addonsStatusParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+}
+
+// createAddonsStatusHandler creates a handler reporting workload cluster
+// addons
+func createAddonsStatusHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := addonsStatusParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+
+		report, err := serverCtx.client(ctx).GetAddonsStatus(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get addons status: %w", err))
+		}
+
+		var content strings.Builder
+		health := "✅ Healthy"
+		if !report.Healthy {
+			health = "❌ Unhealthy"
+		}
+		content.WriteString(fmt.Sprintf("Addons of cluster %s/%s: %s\n", namespace, name, health))
+		content.WriteString(fmt.Sprintf("Nodes: %d, %d not ready, %d not initialized\n\n", report.Nodes, report.NotReadyNodes, report.UninitializedNodes))
+
+		for _, addon := range report.Addons {
+			icon := "✅"
+			if !addon.Healthy {
+				icon = "❌"
+			}
+			content.WriteString(fmt.Sprintf("%s %s: %s %s/%s (%d/%d ready)", icon, addon.Category, addon.Kind, addon.Namespace, addon.Name, addon.Ready, addon.Desired))
+			if addon.Message != "" {
+				content.WriteString(": " + addon.Message)
+			}
+			content.WriteString("\n")
+		}
+		for _, missing := range report.Missing {
+			content.WriteString(fmt.Sprintf("❌ %s: not installed\n", missing))
+		}
+		if len(report.Hints) > 0 {
+			content.WriteString("\nHints:\n")
+			for _, hint := range report.Hints {
+				content.WriteString(fmt.Sprintf("  - %s\n", hint))
+			}
+		}
+
+		return newToolResult(content.String(), report)
+	}
+}
+
+// installCNIParams declares the arguments of capi_install_cni
+var installCNIParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "cni", Type: params.String, Required: true, Enum: []string{string(capi.CNICalico), string(capi.CNICilium)},
+		Description: "CNI to install"},
+	{Name: "version", Type: params.String,
+		Description: fmt.Sprintf("Version of the CNI (default: calico %s, cilium %s)", capi.DefaultCNIVersions[capi.CNICalico], capi.DefaultCNIVersions[capi.CNICilium])},
+}
+
+// cniRepository returns the repository CNI manifests are fetched from
+func (s *ServerContext) cniRepository() capi.CNIRepository {
+	if repo, ok := s.Providers.(capi.CNIRepository); ok {
+		return repo
+	}
+	return &capi.GitHubRepository{}
+}
+
+// createInstallCNIHandler creates a handler installing CNIs in workload
+// clusters
+func createInstallCNIHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := installCNIParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+
+		installation, err := serverCtx.client(ctx).InstallCNI(ctx, serverCtx.cniRepository(), namespace, name, capi.CNIOptions{
+			CNI:     capi.CNI(args.String("cni")),
+			Version: args.String("version"),
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to install CNI: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Applied %s %s to cluster %s/%s (%d objects)\n", installation.CNI, installation.Version, namespace, name, installation.Objects))
+		content.WriteString(fmt.Sprintf("Pod CIDR: %s\n", installation.PodCIDR))
+		if installation.Job != "" {
+			content.WriteString(fmt.Sprintf("\nThe Job %s installs Cilium with the Cilium CLI in the workload cluster.\n", installation.Job))
+		}
+		content.WriteString("\nCheck that the nodes become ready with capi_addons_status.\n")
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "install-cni",
+			Resource:  clusterRef(namespace, name),
+			Details:   map[string]any{"installation": installation},
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// idempotentTools lists mutating tools where repeating a call with the same
+// arguments has no additional effect. Read-only tools are always idempotent.
+var idempotentTools = map[string]bool{
+	"capi_pause_cluster":             true,
+	"capi_resume_cluster":            true,
+	"capi_update_cluster":            true,
+	"capi_upgrade_cluster":           true,
+	"capi_scale_cluster":             true,
+	"capi_scale_machinedeployment":   true,
+	"capi_update_machinedeployment":  true,
+	"capi_set_machine_annotation":    true,
+	"capi_remove_machine_annotation": true,
+	"capi_cordon_node":               true,
+	"capi_drain_node":                true,
+	"capi_use_context":               true,
+	"capi_job_cancel":                true,
+	"capi_cancel_schedule":           true,
+	"capi_check_webhooks":            true,
+	"capi_init_providers":            true,
+	"capi_upgrade_providers":         true,
+	"capi_install_cni":               true,
+	"capi_apply_manifest":            true,
+	"capi_prepare_namespace":         true,
+	"capi_restore_cluster":           true,
+}
+
+// openWorldTools reach systems beyond the management cluster, such as the
+// provider releases on GitHub or the backup targets
+var openWorldTools = map[string]bool{
+	"capi_init_providers":        true,
+	"capi_provider_upgrade_plan": true,
+	"capi_generate_cluster":      true,
+	"capi_template_variables":    true,
+	"capi_upgrade_providers":     true,
+	"capi_install_cni":           true,
+	"capi_push_backup":           true,
+	"capi_list_stored_backups":   true,
+	"capi_backup_history":        true,
+	"capi_restore_cluster":       true,
+	"capi_pivot_cluster":         true,
+}
+
+// toolAnnotations derives the MCP behaviour hints of a tool from the
+// read-only, destructive, idempotent and open world registries, so clients
+// can decide which calls need a confirmation from the user
+func toolAnnotations(name string) mcp.ToolAnnotation {
+	readOnly := readOnlyTools[name]
+	destructive := !readOnly && isDestructiveTool(name)
+	idempotent := readOnly || idempotentTools[name]
+	openWorld := openWorldTools[name]
+
+	return mcp.ToolAnnotation{
+		ReadOnlyHint:    &readOnly,
+		DestructiveHint: &destructive,
+		IdempotentHint:  &idempotent,
+		OpenWorldHint:   &openWorld,
+	}
+}
+
+// addTool registers a tool with its annotations filled in from the registries
+// and, for tools operating on a management cluster, the cluster selection
+func addTool(s Registry, tool mcp.Tool, handler server.ToolHandlerFunc) {
+	annotations := toolAnnotations(tool.Name)
+	annotations.Title = tool.Annotations.Title
+	tool.Annotations = annotations
+	if !managementClusterIndependentTools[tool.Name] {
+		withManagementClusterArgs(&tool)
+	}
+	if _, ok := maintenanceTargets[tool.Name]; ok {
+		withMaintenanceOverride(&tool)
+	}
+	if gitOpsGuardedTools[tool.Name] {
+		withGitOpsOverride(&tool)
+	}
+	if previewTools[tool.Name] {
+		withPreview(&tool)
+	}
+	if _, ok := tool.InputSchema.Properties["namespace"]; ok && !ownOrganizationTools[tool.Name] {
+		withOrganization(&tool)
+	}
+	s.AddTool(tool, handler)
+}
@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerAPIServerTools adds the tools probing workload cluster API servers
+func registerAPIServerTools(s Registry, serverCtx *ServerContext) {
+	probeAPIServerTool := probeAPIServerParams.NewTool(
+		"capi_probe_api_server",
+		"Probe the API servers of clusters from this server: dial the control plane endpoint, verify its certificate against the cluster CA and call /healthz with the workload kubeconfig, reporting reachability, TLS validity and latency",
+	)
+	addTool(s, probeAPIServerTool, createProbeAPIServerHandler(serverCtx))
+}
+
+// probeAPIServerParams declares the arguments of capi_probe_api_server
+var probeAPIServerParams = params.Schema{
+	{Name: "namespace", Type: params.String, Description: "Namespace of the clusters (optional, empty for all)"},
+	{Name: "name", Type: params.String, Description: "Name of a single cluster to probe (optional, requires namespace)"},
+}
+
+// createProbeAPIServerHandler creates a handler probing API servers
+func createProbeAPIServerHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := probeAPIServerParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+
+		var probes []*capi.APIServerProbe
+		if name != "" {
+			if namespace == "" {
+				return invalidArgument("namespace is required with name")
+			}
+			probe, err := serverCtx.client(ctx).ProbeAPIServer(ctx, namespace, name)
+			if err != nil {
+				return toolError(fmt.Errorf("failed to probe API server: %w", err))
+			}
+			probes = []*capi.APIServerProbe{probe}
+		} else if probes, err = serverCtx.client(ctx).ProbeAPIServers(ctx, namespace); err != nil {
+			return toolError(fmt.Errorf("failed to probe API servers: %w", err))
+		}
+
+		healthy := 0
+		for _, probe := range probes {
+			if probe.Healthy && probe.TLS != nil && probe.TLS.Valid {
+				healthy++
+			}
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("API servers: %d of %d healthy\n\n", healthy, len(probes)))
+		for _, probe := range probes {
+			writeAPIServerProbe(&content, probe)
+		}
+
+		return newToolResult(content.String(), map[string]any{"healthy": healthy, "probes": probes})
+	}
+}
+
+// writeAPIServerProbe describes the outcome of an API server probe
+func writeAPIServerProbe(content *strings.Builder, probe *capi.APIServerProbe) {
+	icon := "✅"
+	if !probe.Healthy || probe.TLS == nil || !probe.TLS.Valid {
+		icon = "❌"
+	}
+	content.WriteString(fmt.Sprintf("%s %s/%s", icon, probe.Namespace, probe.Name))
+	if probe.Endpoint != "" {
+		content.WriteString(fmt.Sprintf(" (%s)", probe.Endpoint))
+	}
+	content.WriteString("\n")
+
+	if probe.Reachable {
+		content.WriteString(fmt.Sprintf("  TCP: reachable in %s\n", probe.DialLatency.Round(time.Millisecond)))
+	} else if probe.Endpoint != "" {
+		content.WriteString("  TCP: unreachable\n")
+		if probe.InfrastructureReady {
+			content.WriteString("  ⚠️  The infrastructure is ready but the API server cannot be reached: check load balancers, security groups and the control plane machines\n")
+		}
+	}
+	if tlsProbe := probe.TLS; tlsProbe != nil {
+		if tlsProbe.Valid {
+			content.WriteString(fmt.Sprintf("  TLS: valid until %s, handshake in %s\n", tlsProbe.Expires.Format(time.RFC3339), tlsProbe.HandshakeLatency.Round(time.Millisecond)))
+		} else {
+			content.WriteString(fmt.Sprintf("  TLS: invalid: %s\n", tlsProbe.Error))
+		}
+	}
+	if probe.Healthz != "" || probe.HealthzLatency > 0 {
+		content.WriteString(fmt.Sprintf("  /healthz: %q in %s\n", probe.Healthz, probe.HealthzLatency.Round(time.Millisecond)))
+	}
+	for _, probeErr := range probe.Errors {
+		content.WriteString(fmt.Sprintf("  Error: %s\n", probeErr))
+	}
+	content.WriteString("\n")
+}
@@ -0,0 +1,281 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/approval"
+	"github.com/giantswarm/mcp-capi/internal/auth"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerApprovalTools adds the approval workflow tools
+func registerApprovalTools(s Registry, serverCtx *ServerContext) {
+	// Approval tools
+	listApprovalsTool := mcp.NewTool(
+		"capi_list_approvals",
+		mcp.WithDescription("List approval requests for destructive operations"),
+		mcp.WithString("status",
+			mcp.Description("Filter by status (pending, approved, rejected, expired, consumed)"),
+		),
+	)
+	addTool(s, listApprovalsTool, createListApprovalsHandler(serverCtx))
+
+	approveOperationTool := mcp.NewTool(
+		"capi_approve_operation",
+		mcp.WithDescription("Approve a pending destructive operation using the one-time code sent to approvers"),
+		mcp.WithString("approval_id",
+			mcp.Required(),
+			mcp.Description("ID of the approval request"),
+		),
+		mcp.WithString("approver",
+			mcp.Required(),
+			mcp.Description("Name of the approver (must differ from the requester)"),
+		),
+		mcp.WithString("code",
+			mcp.Required(),
+			mcp.Description("One-time approval code from the approval notification"),
+		),
+	)
+	addTool(s, approveOperationTool, createDecideApprovalHandler(serverCtx, true))
+
+	rejectOperationTool := mcp.NewTool(
+		"capi_reject_operation",
+		mcp.WithDescription("Reject a pending destructive operation using the one-time code sent to approvers"),
+		mcp.WithString("approval_id",
+			mcp.Required(),
+			mcp.Description("ID of the approval request"),
+		),
+		mcp.WithString("approver",
+			mcp.Required(),
+			mcp.Description("Name of the approver (must differ from the requester)"),
+		),
+		mcp.WithString("code",
+			mcp.Required(),
+			mcp.Description("One-time approval code from the approval notification"),
+		),
+		mcp.WithString("reason",
+			mcp.Description("Reason for the rejection"),
+		),
+	)
+	addTool(s, rejectOperationTool, createDecideApprovalHandler(serverCtx, false))
+}
+
+// destructiveTools lists the tools that delete, replace or disrupt machines and clusters.
+// These require an external approval when approval gates are enabled.
+var destructiveTools = map[string]bool{
+	"capi_delete_cluster":            true,
+	"capi_upgrade_cluster":           true,
+	"capi_fleet_upgrade":             true,
+	"capi_canary_upgrade":            true,
+	"capi_canary_resume":             true,
+	"capi_create_schedule":           true,
+	"capi_scale_cluster":             true,
+	"capi_hibernate_cluster":         true,
+	"capi_delete_machine":            true,
+	"capi_remediate_machine":         true,
+	"capi_scale_machinedeployment":   true,
+	"capi_update_machinedeployment":  true,
+	"capi_rollout_machinedeployment": true,
+	"capi_update_machine_image":      true,
+	"capi_aws_configure_spot":        true,
+	"capi_drain_node":                true,
+	"capi_revert_change":             true,
+	"capi_init_providers":            true,
+	"capi_upgrade_providers":         true,
+	"capi_apply_manifest":            true,
+	"capi_prepare_namespace":         true,
+	"capi_upgrade_release":           true,
+	"capi_restore_backup":            true,
+	"capi_restore_cluster":           true,
+	"capi_pivot_cluster":             true,
+}
+
+// isDestructiveTool reports whether a tool requires approval
+func isDestructiveTool(name string) bool {
+	return destructiveTools[name]
+}
+
+// withApprovalID adds the optional approval_id parameter to destructive tools
+func withApprovalID() mcp.ToolOption {
+	return mcp.WithString(approval.ArgumentName,
+		mcp.Description("ID of an approved request (only needed when approval gates are enabled)"),
+	)
+}
+
+// requesterFromContext identifies the MCP client that issued a tool call,
+// preferring the authenticated identity of network transports
+func requesterFromContext(ctx context.Context) string {
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		return identity.Name
+	}
+
+	session := server.ClientSessionFromContext(ctx)
+	if session == nil {
+		return "mcp-client"
+	}
+	if withInfo, ok := session.(server.SessionWithClientInfo); ok {
+		if name := withInfo.GetClientInfo().Name; name != "" {
+			return name
+		}
+	}
+	return "mcp-client"
+}
+
+// NewApprovalMiddleware gates destructive tools behind an external approval
+func NewApprovalMiddleware(mgr *approval.Manager) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			toolName := request.Params.Name
+			// Previews change nothing and need no approval
+			if !mgr.Enabled() || !isDestructiveTool(toolName) || isPreview(toolName, request.GetArguments()) {
+				return next(ctx, request)
+			}
+
+			arguments := request.GetArguments()
+
+			// A retry referencing an approved request proceeds directly
+			if approvalID, _ := arguments[approval.ArgumentName].(string); approvalID != "" {
+				if _, err := mgr.Consume(approvalID, toolName, arguments); err != nil {
+					return mcp.NewToolResultError(fmt.Sprintf("Approval check failed: %v", err)), nil
+				}
+				return next(ctx, request)
+			}
+
+			req, err := mgr.Submit(ctx, toolName, arguments, requesterFromContext(ctx))
+			if req == nil {
+				return toolError(fmt.Errorf("failed to create approval request: %w", err))
+			}
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to notify approvers", "error", err)
+			}
+
+			if mgr.Mode() == approval.ModeBlock {
+				decided, err := mgr.Wait(ctx, req.ID)
+				if err != nil {
+					return toolError(fmt.Errorf("failed to wait for approval: %w", err))
+				}
+				switch decided.Status {
+				case approval.StatusApproved:
+					if _, err := mgr.Consume(req.ID, toolName, arguments); err != nil {
+						return mcp.NewToolResultError(fmt.Sprintf("Approval check failed: %v", err)), nil
+					}
+					return next(ctx, request)
+				case approval.StatusRejected:
+					return mcp.NewToolResultError(formatRejection(decided)), nil
+				case approval.StatusExpired:
+					return mcp.NewToolResultError(fmt.Sprintf("Approval request %s expired without a decision", decided.ID)), nil
+				}
+				req = decided
+			}
+
+			return newToolResult(formatPendingApproval(req), req)
+		}
+	}
+}
+
+// formatPendingApproval explains how to continue with a pending approval
+func formatPendingApproval(req *approval.Request) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("⏸️  Operation '%s' requires approval\n\n", req.Tool))
+	content.WriteString(fmt.Sprintf("  • Approval ID: %s\n", req.ID))
+	content.WriteString(fmt.Sprintf("  • Status: %s\n", req.Status))
+	content.WriteString(fmt.Sprintf("  • Expires: %s\n\n", req.ExpiresAt.UTC().Format(time.RFC3339)))
+	content.WriteString("An approval request has been sent to the configured approvers.\n")
+	content.WriteString("Once it has been approved, re-run the same tool call with the additional\n")
+	content.WriteString(fmt.Sprintf("argument %s=%s.\n", approval.ArgumentName, req.ID))
+	return content.String()
+}
+
+func formatRejection(req *approval.Request) string {
+	msg := fmt.Sprintf("Approval request %s was rejected by %s", req.ID, req.Approver)
+	if req.Reason != "" {
+		msg += ": " + req.Reason
+	}
+	return msg
+}
+
+// createListApprovalsHandler creates a handler for listing approval requests
+func createListApprovalsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		statusFilter, _ := arguments["status"].(string)
+
+		if !serverCtx.Approvals.Enabled() {
+			return newToolResult("Approval gates are disabled (set MCP_APPROVAL_MODE to 'block' or 'enqueue' to enable them).", map[string]any{"enabled": false, "approvals": []approval.Request{}})
+		}
+
+		var content strings.Builder
+		requests := []approval.Request{}
+		for _, req := range serverCtx.Approvals.List() {
+			if statusFilter != "" && string(req.Status) != statusFilter {
+				continue
+			}
+			requests = append(requests, req)
+			content.WriteString(fmt.Sprintf("Approval: %s\n", req.ID))
+			content.WriteString(fmt.Sprintf("  Tool: %s\n", req.Tool))
+			content.WriteString(fmt.Sprintf("  Status: %s\n", req.Status))
+			content.WriteString(fmt.Sprintf("  Requester: %s\n", req.Requester))
+			content.WriteString(fmt.Sprintf("  Created: %s\n", req.CreatedAt.UTC().Format(time.RFC3339)))
+			if req.Approver != "" {
+				content.WriteString(fmt.Sprintf("  Decided by: %s\n", req.Approver))
+			}
+			if req.Reason != "" {
+				content.WriteString(fmt.Sprintf("  Reason: %s\n", req.Reason))
+			}
+			content.WriteString("\n")
+		}
+
+		header := fmt.Sprintf("Found %d approval requests:\n\n", len(requests))
+		return newToolResult(header+content.String(), map[string]any{"enabled": true, "approvals": requests})
+	}
+}
+
+// createDecideApprovalHandler creates a handler for approving or rejecting a request
+func createDecideApprovalHandler(serverCtx *ServerContext, approve bool) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		approvalID, err := params.RequiredString(arguments, "approval_id")
+		if err != nil {
+			return toolError(err)
+		}
+		approver, err := params.RequiredString(arguments, "approver")
+		if err != nil {
+			return toolError(err)
+		}
+		code, err := params.RequiredString(arguments, "code")
+		if err != nil {
+			return toolError(err)
+		}
+		reason, _ := arguments["reason"].(string)
+
+		if !serverCtx.Approvals.Enabled() {
+			return mcp.NewToolResultError("Approval gates are disabled"), nil
+		}
+
+		var req *approval.Request
+		if approve {
+			req, err = serverCtx.Approvals.ApproveWithCode(approvalID, approver, code)
+		} else {
+			req, err = serverCtx.Approvals.RejectWithCode(approvalID, approver, code, reason)
+		}
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Approval failed: %v", err)), nil
+		}
+
+		if !approve {
+			return newToolResult(fmt.Sprintf("❌ %s", formatRejection(req)), req)
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("✅ Approval request %s approved by %s\n\n", req.ID, req.Approver))
+		content.WriteString(fmt.Sprintf("The operation '%s' may now be executed once by re-running it with\n", req.Tool))
+		content.WriteString(fmt.Sprintf("%s=%s and the same arguments.\n", approval.ArgumentName, req.ID))
+		return newToolResult(content.String(), req)
+	}
+}
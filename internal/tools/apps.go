@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerAppTools adds the tools showing the Giant Swarm Apps of clusters
+func registerAppTools(s Registry, serverCtx *ServerContext) {
+	listAppsTool := listAppsParams.NewTool(
+		"capi_list_apps",
+		"List the Giant Swarm Apps (App CRs) installed in a workload cluster with their chart, version and deployment status, flagging apps that failed or are not at their desired version",
+	)
+	addTool(s, listAppsTool, createListAppsHandler(serverCtx))
+
+	getAppTool := getAppParams.NewTool(
+		"capi_get_app",
+		"Get a Giant Swarm App (App CR): its chart, catalog, desired and deployed version, target namespace, user configuration and release status",
+	)
+	addTool(s, getAppTool, createGetAppHandler(serverCtx))
+}
+
+// listAppsParams declares the arguments of capi_list_apps
+var listAppsParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "only_failing", Type: params.Bool, Default: false, Description: "Only list the apps that are not deployed at their desired version (default: false)"},
+}
+
+// createListAppsHandler lists the apps of a workload cluster
+func createListAppsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := listAppsParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace, name := args.String("namespace"), args.String("name")
+
+		apps, err := serverCtx.client(ctx).ListClusterApps(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list apps: %w", err))
+		}
+		deployed := 0
+		for _, app := range apps {
+			if app.Deployed() {
+				deployed++
+			}
+		}
+		if args.Bool("only_failing") {
+			failing := []capi.App{}
+			for _, app := range apps {
+				if !app.Deployed() {
+					failing = append(failing, app)
+				}
+			}
+			apps = failing
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Apps of cluster %s/%s (%d deployed, %d listed):\n\n", namespace, name, deployed, len(apps)))
+		for _, app := range apps {
+			content.WriteString(formatAppLine(app))
+		}
+		if len(apps) == 0 {
+			content.WriteString("No apps found.\n")
+		}
+
+		return newToolResult(content.String(), map[string]any{"deployed": deployed, "apps": apps})
+	}
+}
+
+// formatAppLine summarizes an app on one line
+func formatAppLine(app capi.App) string {
+	icon := "✅"
+	if !app.Deployed() {
+		icon = "❌"
+	}
+	var line strings.Builder
+	line.WriteString(fmt.Sprintf("%s %s: %s %s", icon, app.Name, app.Chart, app.Version))
+	if app.AppVersion != "" {
+		line.WriteString(fmt.Sprintf(" (app %s)", app.AppVersion))
+	}
+	status := app.Status
+	if status == "" {
+		status = "not installed"
+	}
+	line.WriteString(", " + status)
+	if app.DeployedVersion != "" && app.DeployedVersion != app.Version {
+		line.WriteString(fmt.Sprintf(", %s deployed", app.DeployedVersion))
+	}
+	if app.InCluster {
+		line.WriteString(", in the management cluster")
+	}
+	if app.Reason != "" {
+		line.WriteString(": " + app.Reason)
+	}
+	line.WriteString("\n")
+	return line.String()
+}
+
+// getAppParams declares the arguments of capi_get_app
+var getAppParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the App"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the App"},
+}
+
+// createGetAppHandler shows an app
+func createGetAppHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := getAppParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+
+		app, err := serverCtx.client(ctx).GetApp(ctx, args.String("namespace"), args.String("name"))
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get app: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("App %s/%s\n\n", app.Namespace, app.Name))
+		content.WriteString(fmt.Sprintf("Chart: %s\n", app.Chart))
+		if app.Catalog != "" {
+			content.WriteString(fmt.Sprintf("Catalog: %s\n", app.Catalog))
+		}
+		content.WriteString(fmt.Sprintf("Version: %s\n", app.Version))
+		if app.DeployedVersion != "" {
+			content.WriteString(fmt.Sprintf("Deployed Version: %s\n", app.DeployedVersion))
+		}
+		if app.AppVersion != "" {
+			content.WriteString(fmt.Sprintf("App Version: %s\n", app.AppVersion))
+		}
+		if app.TargetNamespace != "" {
+			content.WriteString(fmt.Sprintf("Target Namespace: %s\n", app.TargetNamespace))
+		}
+		if app.InCluster {
+			content.WriteString("Installed in: management cluster\n")
+		}
+		status := app.Status
+		if status == "" {
+			status = "not installed"
+		}
+		content.WriteString(fmt.Sprintf("Status: %s\n", status))
+		if app.Reason != "" {
+			content.WriteString(fmt.Sprintf("Reason: %s\n", app.Reason))
+		}
+		if app.LastDeployed != "" {
+			content.WriteString(fmt.Sprintf("Last Deployed: %s\n", app.LastDeployed))
+		}
+		if app.UserConfigMap != "" {
+			content.WriteString(fmt.Sprintf("User ConfigMap: %s\n", app.UserConfigMap))
+		}
+		if app.UserConfigSecret != "" {
+			content.WriteString(fmt.Sprintf("User Secret: %s\n", app.UserConfigSecret))
+		}
+
+		return newToolResult(content.String(), app)
+	}
+}
@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/audit"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerAuditTools adds the audit log tools
+func registerAuditTools(s Registry, serverCtx *ServerContext) {
+	// Audit tools
+	auditLogTool := mcp.NewTool(
+		"capi_audit_log",
+		mcp.WithDescription("Query recent mutating tool invocations recorded in the audit log"),
+		mcp.WithString("tool",
+			mcp.Description("Only show entries for this tool"),
+		),
+		mcp.WithString("caller",
+			mcp.Description("Only show entries from this caller"),
+		),
+		mcp.WithString("result",
+			mcp.Description("Only show entries with this result (success, error)"),
+		),
+		mcp.WithString("since",
+			mcp.Description("Only show entries newer than this duration (e.g., 1h, 30m)"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of entries to return (default: 20)"),
+		),
+	)
+	addTool(s, auditLogTool, createAuditLogHandler(serverCtx))
+}
+
+// maxAuditMessageLength bounds the result summary stored with each audit entry
+const maxAuditMessageLength = 200
+
+// NewAuditMiddleware records every call to a mutating tool and every call
+// writing a file on the server
+func NewAuditMiddleware(logger *audit.Logger) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			toolName := request.Params.Name
+			if readOnlyCall(toolName, request.GetArguments()) {
+				return next(ctx, request)
+			}
+
+			start := time.Now()
+			result, err := next(ctx, request)
+
+			entry := audit.Entry{
+				Time:      start,
+				Tool:      toolName,
+				Arguments: request.GetArguments(),
+				Caller:    requesterFromContext(ctx),
+				Result:    audit.ResultSuccess,
+				Duration:  time.Since(start),
+			}
+			switch {
+			case err != nil:
+				entry.Result = audit.ResultError
+				entry.Message = err.Error()
+			case result != nil:
+				if result.IsError {
+					entry.Result = audit.ResultError
+				}
+				entry.Message = summarizeResult(result)
+			}
+			logger.Record(ctx, entry)
+
+			return result, err
+		}
+	}
+}
+
+// summarizeResult returns the first line of a tool result's text content
+func summarizeResult(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		text, ok := content.(mcp.TextContent)
+		if !ok {
+			continue
+		}
+		line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(text.Text), "\n", 2)[0])
+		if len(line) > maxAuditMessageLength {
+			line = line[:maxAuditMessageLength] + "..."
+		}
+		return line
+	}
+	return ""
+}
+
+// createAuditLogHandler creates a handler for querying recent audit entries
+func createAuditLogHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		query := audit.Query{Limit: 20}
+		query.Tool, _ = arguments["tool"].(string)
+		query.Caller, _ = arguments["caller"].(string)
+		if result, _ := arguments["result"].(string); result != "" {
+			query.Result = audit.Result(result)
+		}
+		limit, err := params.OptionalInt(arguments, "limit", query.Limit)
+		if err != nil {
+			return toolError(err)
+		}
+		if limit > 0 {
+			query.Limit = limit
+		}
+		if since, _ := arguments["since"].(string); since != "" {
+			d, err := time.ParseDuration(since)
+			if err != nil {
+				return invalidArgument("since must be a duration such as 30m or 24h, got %q", since)
+			}
+			query.Since = time.Now().Add(-d)
+		}
+
+		entries := serverCtx.AuditLog.Recent(query)
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Found %d audit entries:\n\n", len(entries)))
+		for _, entry := range entries {
+			icon := "✅"
+			if entry.Result == audit.ResultError {
+				icon = "❌"
+			}
+			content.WriteString(fmt.Sprintf("%s %s\n", icon, entry.Tool))
+			content.WriteString(fmt.Sprintf("  Time: %s\n", entry.Time.UTC().Format(time.RFC3339)))
+			content.WriteString(fmt.Sprintf("  Caller: %s\n", entry.Caller))
+			content.WriteString(fmt.Sprintf("  Result: %s\n", entry.Result))
+			if len(entry.Arguments) > 0 {
+				content.WriteString(fmt.Sprintf("  Arguments: %v\n", entry.Arguments))
+			}
+			if entry.Message != "" {
+				content.WriteString(fmt.Sprintf("  Message: %s\n", entry.Message))
+			}
+			content.WriteString(fmt.Sprintf("  Duration: %s\n\n", entry.Duration.Round(time.Millisecond)))
+		}
+
+		return newToolResult(content.String(), map[string]any{"entries": entries})
+	}
+}
@@ -0,0 +1,137 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/mcp-capi/internal/auth"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// namespaceIndependentTools do not touch namespaced resources, so identities
+// restricted to namespaces may call them without a namespace argument
+var namespaceIndependentTools = map[string]bool{
+	"test":                               true,
+	"capi_list_infrastructure_providers": true,
+	"capi_server_info":                   true,
+	"capi_list_management_clusters":      true,
+	"capi_get_provider_config":           true,
+	"capi_discover_providers":            true,
+	"capi_controllers_status":            true,
+	"capi_init_providers":                true,
+	"capi_provider_upgrade_plan":         true,
+	"capi_upgrade_providers":             true,
+	"capi_check_permissions":             true,
+	"capi_rbac_manifest":                 true,
+	// Job tools hide the jobs of other namespaces themselves
+	"capi_job_status": true,
+	"capi_job_logs":   true,
+	"capi_job_cancel": true,
+	// Canary tools hide the canary upgrades of other namespaces themselves
+	"capi_canary_status": true,
+	"capi_canary_resume": true,
+	// capi_list_organizations hides the organizations of other namespaces itself
+	"capi_list_organizations": true,
+	// Schedule tools hide the schedules of other namespaces themselves
+	"capi_list_schedules":  true,
+	"capi_cancel_schedule": true,
+}
+
+// clusterWideTools act on records of all namespaces without taking a
+// namespace argument, so identities restricted to namespaces may not call
+// them at all
+var clusterWideTools = map[string]bool{
+	"capi_revert_change":     true,
+	"capi_audit_log":         true,
+	"capi_list_approvals":    true,
+	"capi_approve_operation": true,
+	"capi_reject_operation":  true,
+	"capi_list_identities":   true,
+	"capi_use_context":       true,
+}
+
+// authorize checks a tool call against the policy of the calling identity
+func authorize(identity *auth.Identity, name string, arguments map[string]any) error {
+	policy := identity.Policy
+	if policy.ReadOnly && !readOnlyCall(name, arguments) {
+		return fmt.Errorf("%s has read-only access and may not call %s", identity.Name, name)
+	}
+	// Read-only tools that hand out credentials on request are not read-only
+	// access anymore
+	if requested, ok := secretRequests[name]; ok && policy.ReadOnly && requested(arguments) {
+		return fmt.Errorf("%s has read-only access and may not request credentials from %s", identity.Name, name)
+	}
+	if !policy.Restricted() || namespaceIndependentTools[name] {
+		return nil
+	}
+	if clusterWideTools[name] {
+		return fmt.Errorf("%s is restricted to namespaces %v and may not call %s, which is not scoped to a namespace", identity.Name, policy.Namespaces, name)
+	}
+
+	namespace, _ := arguments["namespace"].(string)
+	if namespace == "" {
+		return fmt.Errorf("%s is restricted to namespaces %v and must pass a namespace to %s", identity.Name, policy.Namespaces, name)
+	}
+	if !policy.AllowsNamespace(namespace) {
+		return fmt.Errorf("%s may not access namespace %s", identity.Name, namespace)
+	}
+	// Tools copying or moving resources write to the target namespace
+	if target, _ := arguments["target_namespace"].(string); target != "" && !policy.AllowsNamespace(target) {
+		return fmt.Errorf("%s may not access namespace %s", identity.Name, target)
+	}
+	return nil
+}
+
+// NewAuthFilter hides tools from tools/list that the calling identity may
+// never call. Unauthenticated calls over stdio see all tools.
+func NewAuthFilter() server.ToolFilterFunc {
+	return func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		identity, ok := auth.IdentityFromContext(ctx)
+		if !ok || !identity.Policy.ReadOnly {
+			return tools
+		}
+		filtered := make([]mcp.Tool, 0, len(tools))
+		for _, tool := range tools {
+			if readOnlyTools[tool.Name] {
+				filtered = append(filtered, tool)
+			}
+		}
+		return filtered
+	}
+}
+
+// NewAuthMiddleware rejects calls that the policy of the authenticated
+// identity does not permit
+func NewAuthMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			identity, ok := auth.IdentityFromContext(ctx)
+			if !ok {
+				return next(ctx, request)
+			}
+			if err := authorize(identity, request.Params.Name, request.GetArguments()); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Permission denied: %v", err)), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// NewNamespaceMiddleware confines every tool call to the namespaces the
+// server is allowed to access, like the policy of an identity restricted to
+// them. Calls pass through when namespaces is empty.
+func NewNamespaceMiddleware(namespaces []string) server.ToolHandlerMiddleware {
+	scope := &auth.Identity{Name: "the server", Policy: auth.Policy{Namespaces: namespaces}}
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !scope.Policy.Restricted() {
+				return next(ctx, request)
+			}
+			if err := authorize(scope, request.Params.Name, request.GetArguments()); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("Permission denied: %v", err)), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,548 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/backup"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/internal/schedule"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// registerBackupTools adds the tools pushing backup bundles to the backup
+// targets and reading them back
+func registerBackupTools(s Registry, serverCtx *ServerContext) {
+	pushBackupTool := pushBackupParams.NewTool(
+		"capi_push_backup",
+		"Create a backup bundle of the CAPI objects of a cluster, like capi_backup_cluster, and push it to a configured backup target (S3 bucket or OCI registry, optionally age-encrypted). Applying the retention policy of the target deletes its older backups of the cluster.",
+	)
+	addTool(s, pushBackupTool, createPushBackupHandler(serverCtx))
+
+	listStoredBackupsTool := listStoredBackupsParams.NewTool(
+		"capi_list_stored_backups",
+		"List the backup targets (S3 buckets and OCI registries) with their encryption and retention, and the backup bundles stored there for the clusters of a namespace, newest first",
+	)
+	addTool(s, listStoredBackupsTool, createListStoredBackupsHandler(serverCtx))
+
+	backupHistoryTool := backupHistoryParams.NewTool(
+		"capi_backup_history",
+		"Show the backup history of the clusters of a namespace on the backup targets with the backup schedules covering them, and verify that the latest backup of every cluster is more recent than max_age",
+	)
+	addTool(s, backupHistoryTool, createBackupHistoryHandler(serverCtx))
+
+	restoreClusterTool := restoreClusterParams.NewTool(
+		"capi_restore_cluster",
+		"Restore a cluster from a backup bundle of capi_backup_cluster, capi_push_backup or capi_export_gitops, read from a backup target, a file, a .tar.gz export or a directory, or given inline. Re-creates the objects in order (Secrets, templates, infrastructure cluster, Cluster, control plane, machine deployments) with the Cluster paused until all exist, re-links the owner references and reports the restored and skipped objects. Existing objects are skipped.",
+		withApprovalID(),
+	)
+	addTool(s, restoreClusterTool, createRestoreClusterHandler(serverCtx))
+}
+
+// pushBackupParams declares the arguments of capi_push_backup
+var pushBackupParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "target", Type: params.String, Required: true, Description: "Backup target to push the bundle to, see capi_list_stored_backups"},
+	{Name: "include_secrets", Type: params.Bool, Description: "Include secrets in backup (kubeconfig, certificates)"},
+	{Name: "output_format", Type: params.String, Default: "yaml", Enum: []string{"yaml", "json"}, Description: "Output format: yaml or json (default: yaml)"},
+}
+
+// createPushBackupHandler creates a handler pushing the backup bundle of a
+// cluster to a backup target
+func createPushBackupHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := pushBackupParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace, name := args.String("namespace"), args.String("name")
+		includeSecrets, outputFormat := args.Bool("include_secrets"), args.String("output_format")
+
+		target, err := serverCtx.BackupTargets.Get(args.String("target"))
+		if err != nil {
+			return invalidArgument("%v", err)
+		}
+		bundle, err := serverCtx.client(ctx).BackupCluster(ctx, capi.BackupClusterOptions{
+			Namespace:      namespace,
+			Name:           name,
+			IncludeSecrets: includeSecrets,
+			OutputFormat:   outputFormat,
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to create cluster backup: %w", err))
+		}
+		return pushBackup(ctx, target, namespace, name, outputFormat, includeSecrets, bundle)
+	}
+}
+
+// pushBackup uploads a backup bundle to a backup target and applies the
+// retention policy of the target
+func pushBackup(ctx context.Context, target *backup.Target, namespace, name, format string, includeSecrets bool, bundle string) (*mcp.CallToolResult, error) {
+	upload, err := target.Upload(ctx, namespace, name, format, []byte(bundle), time.Now())
+	if err != nil {
+		return toolError(err)
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("📦 Pushed backup of cluster %s/%s to target %s\n\n", namespace, name, target.Name))
+	content.WriteString(fmt.Sprintf("  • Location: %s\n", target.Location()))
+	content.WriteString(fmt.Sprintf("  • Key: %s\n", upload.Backup.Key))
+	content.WriteString(fmt.Sprintf("  • Format: %s, %s\n", format, formatBytes(upload.Backup.Size)))
+	if upload.Backup.Encrypted {
+		content.WriteString("  • Encrypted with age\n")
+	} else if includeSecrets {
+		content.WriteString("  • ⚠️  Secrets are included and the target does not encrypt backups\n")
+	}
+	content.WriteString(fmt.Sprintf("  • Include Secrets: %v\n", includeSecrets))
+	if len(upload.Pruned) > 0 {
+		content.WriteString(fmt.Sprintf("\nRetention (%s) deleted %d older backups:\n", target.Retention, len(upload.Pruned)))
+		for _, key := range upload.Pruned {
+			content.WriteString(fmt.Sprintf("  - %s\n", key))
+		}
+	}
+	if upload.PruneError != "" {
+		content.WriteString(fmt.Sprintf("\n⚠️  Applying the retention policy failed: %s\n", upload.PruneError))
+	}
+
+	return newToolResult(content.String(), operationResult{
+		Operation: "backup",
+		Resource:  clusterRef(namespace, name),
+		Details: map[string]any{
+			"target":         target.Name,
+			"location":       target.Location(),
+			"includeSecrets": includeSecrets,
+			"upload":         upload,
+		},
+	})
+}
+
+// listStoredBackupsParams declares the arguments of capi_list_stored_backups
+var listStoredBackupsParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Validate: params.Namespace, Description: "Namespace whose backups to list"},
+	{Name: "name", Type: params.String, Description: "Only list the backups of this cluster (optional)"},
+	{Name: "target", Type: params.String, Description: "Only list this backup target (default: all targets)"},
+}
+
+// storedBackups are the backups of a namespace on a target
+type storedBackups struct {
+	Target    string          `json:"target"`
+	Location  string          `json:"location"`
+	Encrypted bool            `json:"encrypted"`
+	Retention string          `json:"retention"`
+	Backups   []backup.Backup `json:"backups"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// createListStoredBackupsHandler lists the backups stored on the backup
+// targets
+func createListStoredBackupsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := listStoredBackupsParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace, name := args.String("namespace"), args.String("name")
+
+		targets := serverCtx.BackupTargets.List()
+		if targetName := args.String("target"); targetName != "" || len(targets) == 0 {
+			target, err := serverCtx.BackupTargets.Get(targetName)
+			if err != nil {
+				return invalidArgument("%v", err)
+			}
+			targets = []*backup.Target{target}
+		}
+
+		var content strings.Builder
+		subject := "namespace " + namespace
+		if name != "" {
+			subject = fmt.Sprintf("cluster %s/%s", namespace, name)
+		}
+		content.WriteString(fmt.Sprintf("Stored backups of %s:\n", subject))
+		results := make([]storedBackups, 0, len(targets))
+		for _, target := range targets {
+			result := storedBackups{
+				Target:    target.Name,
+				Location:  target.Location(),
+				Encrypted: target.Encrypted(),
+				Retention: target.Retention.String(),
+			}
+			content.WriteString(fmt.Sprintf("\n%s (%s, %s", target.Name, target.Location(), result.Retention))
+			if result.Encrypted {
+				content.WriteString(", age-encrypted")
+			}
+			content.WriteString("):\n")
+
+			if result.Backups, err = target.List(ctx, namespace, name); err != nil {
+				result.Error = err.Error()
+				content.WriteString(fmt.Sprintf("  ❌ %v\n", err))
+			} else if len(result.Backups) == 0 {
+				content.WriteString("  No backups\n")
+			}
+			for _, stored := range result.Backups {
+				content.WriteString(fmt.Sprintf("  - %s: cluster %s, %s", stored.Key, stored.Cluster, stored.Time.Format(time.RFC3339)))
+				if stored.Size > 0 {
+					content.WriteString(", " + formatBytes(stored.Size))
+				}
+				content.WriteString("\n")
+			}
+			results = append(results, result)
+		}
+
+		return newToolResult(content.String(), map[string]any{
+			"namespace": namespace,
+			"name":      name,
+			"targets":   results,
+		})
+	}
+}
+
+// backupHistoryParams declares the arguments of capi_backup_history
+var backupHistoryParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Validate: params.Namespace, Description: "Namespace whose clusters to check"},
+	{Name: "name", Type: params.String, Validate: params.KubernetesName, Description: "Only check this cluster (optional)"},
+	{Name: "target", Type: params.String, Description: "Only consider this backup target (default: all targets)"},
+	{Name: "max_age", Type: params.String, Default: "25h",
+		Description: "Age up to which the latest backup of a cluster counts as recent, e.g. 25h for daily backups (default: 25h)"},
+	{Name: "limit", Type: params.Int, Default: 5, NonNegative: true, Description: "Number of backups listed per cluster, newest first (default: 5)"},
+}
+
+// targetBackup is a backup on a target
+type targetBackup struct {
+	Target string `json:"target"`
+	backup.Backup
+}
+
+// clusterBackupHistory is the backup history of a cluster
+type clusterBackupHistory struct {
+	Cluster string `json:"cluster"`
+	// Deleted is set for backups of clusters that no longer exist, which
+	// are not verified
+	Deleted bool          `json:"deleted,omitempty"`
+	Recent  bool          `json:"recent"`
+	Latest  *targetBackup `json:"latest,omitempty"`
+	Age     string        `json:"age,omitempty"`
+	Total   int           `json:"total"`
+	// Backups are the newest backups, up to the limit
+	Backups []targetBackup `json:"backups"`
+}
+
+// createBackupHistoryHandler reports the backups of the clusters of a
+// namespace and whether they are recent
+func createBackupHistoryHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := backupHistoryParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace, name, limit := args.String("namespace"), args.String("name"), args.Int("limit")
+		maxAge, err := time.ParseDuration(args.String("max_age"))
+		if err != nil || maxAge <= 0 {
+			return invalidArgument("max_age must be a positive duration such as 25h, got %q", args.String("max_age"))
+		}
+		targets := serverCtx.BackupTargets.List()
+		if targetName := args.String("target"); targetName != "" || len(targets) == 0 {
+			target, err := serverCtx.BackupTargets.Get(targetName)
+			if err != nil {
+				return invalidArgument("%v", err)
+			}
+			targets = []*backup.Target{target}
+		}
+
+		// The clusters to verify, and those only found in backups
+		histories := map[string]*clusterBackupHistory{}
+		if name != "" {
+			if _, err := serverCtx.client(ctx).GetCluster(ctx, namespace, name); err != nil {
+				return toolError(err)
+			}
+			histories[name] = &clusterBackupHistory{Cluster: name}
+		} else {
+			clusters, err := serverCtx.client(ctx).ListClusters(ctx, namespace)
+			if err != nil {
+				return toolError(fmt.Errorf("failed to list clusters: %w", err))
+			}
+			for _, cluster := range clusters.Items {
+				histories[cluster.Name] = &clusterBackupHistory{Cluster: cluster.Name}
+			}
+		}
+		var targetErrors []string
+		for _, target := range targets {
+			backups, err := target.List(ctx, namespace, name)
+			if err != nil {
+				targetErrors = append(targetErrors, fmt.Sprintf("%s: %v", target.Name, err))
+				continue
+			}
+			for _, stored := range backups {
+				history, ok := histories[stored.Cluster]
+				if !ok {
+					history = &clusterBackupHistory{Cluster: stored.Cluster, Deleted: true}
+					histories[stored.Cluster] = history
+				}
+				history.Backups = append(history.Backups, targetBackup{Target: target.Name, Backup: stored})
+			}
+		}
+
+		now := time.Now()
+		results := make([]*clusterBackupHistory, 0, len(histories))
+		verified := true
+		clusters := make([]string, 0, len(histories))
+		for cluster := range histories {
+			clusters = append(clusters, cluster)
+		}
+		sort.Strings(clusters)
+		for _, cluster := range clusters {
+			history := histories[cluster]
+			sort.SliceStable(history.Backups, func(i, j int) bool { return history.Backups[i].Time.After(history.Backups[j].Time) })
+			history.Total = len(history.Backups)
+			if history.Total > 0 {
+				history.Latest = &history.Backups[0]
+				history.Age = now.Sub(history.Latest.Time).Round(time.Minute).String()
+				history.Recent = now.Sub(history.Latest.Time) <= maxAge
+			}
+			if len(history.Backups) > limit {
+				history.Backups = history.Backups[:limit]
+			}
+			if history.Backups == nil {
+				history.Backups = []targetBackup{}
+			}
+			if !history.Deleted && !history.Recent {
+				verified = false
+			}
+			results = append(results, history)
+		}
+
+		var schedules []schedule.Schedule
+		if serverCtx.Schedules != nil {
+			all, err := serverCtx.Schedules.List(ctx)
+			if err != nil {
+				return toolError(err)
+			}
+			for _, sched := range all {
+				if sched.Action == schedule.ActionBackup && sched.Namespace == namespace && (name == "" || sched.Covers(namespace, name)) {
+					schedules = append(schedules, sched)
+				}
+			}
+		}
+
+		return newToolResult(formatBackupHistory(namespace, name, maxAge, verified, results, schedules, targetErrors, now), map[string]any{
+			"namespace":    namespace,
+			"name":         name,
+			"maxAge":       maxAge.String(),
+			"verified":     verified,
+			"clusters":     results,
+			"schedules":    schedules,
+			"targetErrors": targetErrors,
+		})
+	}
+}
+
+// formatBackupHistory describes the backup history of a namespace for the
+// text result
+func formatBackupHistory(namespace, name string, maxAge time.Duration, verified bool, histories []*clusterBackupHistory, schedules []schedule.Schedule, targetErrors []string, now time.Time) string {
+	var content strings.Builder
+	subject := "namespace " + namespace
+	if name != "" {
+		subject = fmt.Sprintf("cluster %s/%s", namespace, name)
+	}
+	if verified {
+		content.WriteString(fmt.Sprintf("✅ Every cluster of %s has a backup younger than %s\n", subject, maxAge))
+	} else {
+		content.WriteString(fmt.Sprintf("⚠️  Not every cluster of %s has a backup younger than %s\n", subject, maxAge))
+	}
+	for _, targetError := range targetErrors {
+		content.WriteString(fmt.Sprintf("❌ Failed to list target %s\n", targetError))
+	}
+
+	for _, history := range histories {
+		content.WriteString(fmt.Sprintf("\n%s", history.Cluster))
+		switch {
+		case history.Deleted:
+			content.WriteString(" (cluster no longer exists)")
+		case history.Latest == nil:
+			content.WriteString(": ❌ no backups")
+		case history.Recent:
+			content.WriteString(fmt.Sprintf(": ✅ latest backup %s ago", history.Age))
+		default:
+			content.WriteString(fmt.Sprintf(": ⚠️  latest backup %s ago", history.Age))
+		}
+		content.WriteString(fmt.Sprintf(", %d backups\n", history.Total))
+		for _, stored := range history.Backups {
+			content.WriteString(fmt.Sprintf("  - %s on %s: %s\n", stored.Time.Format(time.RFC3339), stored.Target, stored.Key))
+		}
+	}
+
+	if len(schedules) > 0 {
+		content.WriteString("\nBackup schedules:\n")
+		for i := range schedules {
+			content.WriteString(formatSchedule(&schedules[i], now))
+		}
+	} else {
+		content.WriteString("\nNo backup schedules cover these clusters, create one with capi_create_schedule and action backup.\n")
+	}
+	return content.String()
+}
+
+// restoreClusterParams declares the arguments of capi_restore_cluster
+var restoreClusterParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Validate: params.Namespace,
+		Description: "Namespace to restore the cluster into, whatever namespace the bundle names"},
+	{Name: "target", Type: params.String, Description: "Backup target to download the bundle from, with key (default: the only target)"},
+	{Name: "key", Type: params.String, Description: "Key of the backup on the target, as listed by capi_list_stored_backups"},
+	{Name: "path", Type: params.String,
+		Description: "Bundle file, .tar.gz archive of capi_export_gitops or directory of YAML files to restore, instead of a backup on a target"},
+	{Name: "bundle", Type: params.String, Description: "Bundle to restore given inline, as returned by capi_backup_cluster"},
+	{Name: "keep_paused", Type: params.Bool, Description: "Leave the restored cluster paused to check it before resuming it with capi_resume_cluster (default: false)"},
+	{Name: "dry_run", Type: params.Bool, Description: "Report the objects that would be restored without creating them (default: false)"},
+}
+
+// createRestoreClusterHandler restores a cluster from a backup bundle
+func createRestoreClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := restoreClusterParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+
+		var objects []*unstructured.Unstructured
+		var source string
+		key, path, bundle := args.String("key"), args.String("path"), args.String("bundle")
+		if (key != "" && path != "") || (bundle != "" && (key != "" || path != "")) {
+			return invalidArgument("only one of key, path and bundle may be given")
+		}
+		switch {
+		case key != "":
+			target, err := serverCtx.BackupTargets.Get(args.String("target"))
+			if err != nil {
+				return invalidArgument("%v", err)
+			}
+			data, err := target.Download(ctx, key)
+			if err != nil {
+				return toolError(err)
+			}
+			if objects, err = capi.DecodeBundle(data); err != nil {
+				return toolError(err)
+			}
+			source = fmt.Sprintf("%s%s", target.Location(), key)
+		case path != "":
+			resolved, err := serverCtx.kubeconfigPath(path)
+			if err != nil {
+				return toolError(err)
+			}
+			if objects, err = readBundlePath(resolved); err != nil {
+				return toolError(err)
+			}
+			source = resolved
+		case bundle != "":
+			if objects, err = capi.DecodeBundle([]byte(bundle)); err != nil {
+				return toolError(err)
+			}
+			source = "inline bundle"
+		default:
+			return invalidArgument("one of key, path and bundle is required")
+		}
+
+		restore, err := serverCtx.client(ctx).RestoreCluster(ctx, capi.RestoreClusterOptions{
+			Namespace:  namespace,
+			Objects:    objects,
+			KeepPaused: args.Bool("keep_paused"),
+			DryRun:     args.Bool("dry_run"),
+		})
+		if restore == nil {
+			return toolError(err)
+		}
+
+		var content strings.Builder
+		switch {
+		case restore.DryRun:
+			content.WriteString(fmt.Sprintf("🔍 Dry run: restoring cluster %s/%s from %s would create %d objects\n", namespace, restore.Cluster, source, len(restore.Restored)))
+		case err != nil || len(restore.Failed) > 0:
+			content.WriteString(fmt.Sprintf("⚠️  Restored cluster %s/%s from %s partially\n", namespace, restore.Cluster, source))
+		default:
+			content.WriteString(fmt.Sprintf("✅ Restored cluster %s/%s from %s\n", namespace, restore.Cluster, source))
+		}
+		if err != nil {
+			content.WriteString(fmt.Sprintf("❌ %v\n", err))
+		}
+		if len(restore.Restored) > 0 {
+			content.WriteString("\nRestored:\n")
+			for _, obj := range restore.Restored {
+				content.WriteString(fmt.Sprintf("  - %s %s\n", obj.Kind, obj.Name))
+			}
+		}
+		if len(restore.Skipped) > 0 {
+			content.WriteString("\nSkipped:\n")
+			for _, obj := range restore.Skipped {
+				content.WriteString(fmt.Sprintf("  - %s %s: %s\n", obj.Kind, obj.Name, obj.Reason))
+			}
+		}
+		if len(restore.Failed) > 0 {
+			content.WriteString("\nFailed:\n")
+			for _, obj := range restore.Failed {
+				content.WriteString(fmt.Sprintf("  - %s %s: %s\n", obj.Kind, obj.Name, obj.Reason))
+			}
+		}
+		if len(restore.OwnerReferences) > 0 {
+			content.WriteString(fmt.Sprintf("\nLinked %d owner references\n", len(restore.OwnerReferences)))
+		}
+		if !restore.DryRun && restore.Paused {
+			content.WriteString("\nThe cluster is paused, resume it with capi_resume_cluster once its objects are checked.\n")
+		}
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "restore",
+			Resource:  clusterRef(namespace, restore.Cluster),
+			Details: map[string]any{
+				"source":  source,
+				"restore": restore,
+			},
+		})
+	}
+}
+
+// readBundlePath reads the objects of a bundle file, an archive of
+// capi_export_gitops or the YAML files of a directory, such as an unpacked
+// export
+func readBundlePath(path string) ([]*unstructured.Unstructured, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz") {
+			return capi.DecodeExportArchive(data)
+		}
+		return capi.DecodeBundle(data)
+	}
+
+	var objects []*unstructured.Unstructured
+	err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || (filepath.Ext(file) != ".yaml" && filepath.Ext(file) != ".yml") {
+			return err
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		fileObjects, err := capi.DecodeBundle(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		objects = append(objects, fileObjects...)
+		return nil
+	})
+	return objects, err
+}
@@ -0,0 +1,268 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/auth"
+	"github.com/giantswarm/mcp-capi/internal/fleet"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// canaryUpgradeParams declares the arguments of capi_canary_upgrade
+var canaryUpgradeParams = params.Schema{
+	{Name: "target_version", Type: params.String, Required: true, Validate: params.Semver,
+		Description: "Target Kubernetes version (e.g., v1.30.2)"},
+	{Name: "namespace", Type: params.String, Required: true, Validate: params.Namespace,
+		Description: "Namespace of the clusters"},
+	{Name: "canary_cluster", Type: params.String, Required: true, Validate: params.KubernetesName,
+		Description: "Cluster upgraded first and watched during the soak period"},
+	{Name: "canary_machinedeployment", Type: params.String, Validate: params.KubernetesName,
+		Description: "Only upgrade this machine deployment of the canary cluster first; its control plane must already run the target version"},
+	{Name: "clusters", Type: params.String,
+		Description: "Comma-separated names of the clusters to upgrade after the soak, in order (optional, default: all clusters matching the label selector)"},
+	{Name: labelSelectorArgument, Type: params.String, Validate: params.LabelSelector,
+		Description: "Kubernetes label selector choosing the clusters upgraded after the soak, e.g. 'env=staging'"},
+	{Name: "soak_minutes", Type: params.Int, Default: 30, NonNegative: true,
+		Description: "How long the canary must stay healthy before the rest is upgraded (default: 30)"},
+	{Name: "upgrade_workers", Type: params.Bool, Default: true, Description: "Also upgrade worker nodes (default: true)"},
+	{Name: "concurrency", Type: params.Int, Default: 1, NonNegative: true,
+		Description: "How many clusters roll out at the same time after the soak (default: 1)"},
+}
+
+// canaryStatusParams declares the arguments of capi_canary_status
+var canaryStatusParams = params.Schema{
+	{Name: "canary_id", Type: params.String, Description: "ID of the canary upgrade (optional, empty to list all)"},
+}
+
+// canaryResumeParams declares the arguments of capi_canary_resume
+var canaryResumeParams = params.Schema{
+	{Name: "canary_id", Type: params.String, Required: true, Description: "ID of the canary upgrade to resume"},
+}
+
+// registerCanaryTools adds the tools running canary upgrades
+func registerCanaryTools(s Registry, serverCtx *ServerContext) {
+	canaryUpgradeTool := canaryUpgradeParams.NewTool(
+		"capi_canary_upgrade",
+		"Upgrade a canary cluster (or one of its machine deployments) to a Kubernetes version, verify it stays healthy for a soak period, then upgrade the remaining clusters. Runs in a background job and can be resumed after a server restart.",
+		withApprovalID(),
+	)
+	addTool(s, canaryUpgradeTool, createCanaryUpgradeHandler(serverCtx))
+
+	canaryStatusTool := canaryStatusParams.NewTool(
+		"capi_canary_status",
+		"Show the stage of canary upgrades: canary, soak, rollout or done, with the outcome of every cluster",
+	)
+	addTool(s, canaryStatusTool, createCanaryStatusHandler(serverCtx))
+
+	canaryResumeTool := canaryResumeParams.NewTool(
+		"capi_canary_resume",
+		"Resume a canary upgrade interrupted by a server restart or a canceled job, or retry its failed stage",
+		withApprovalID(),
+	)
+	addTool(s, canaryResumeTool, createCanaryResumeHandler(serverCtx))
+}
+
+// canaryVisible reports whether the caller may see a canary upgrade
+func canaryVisible(ctx context.Context, canary *fleet.Canary) bool {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok || !identity.Policy.Restricted() {
+		return true
+	}
+	return identity.Policy.AllowsNamespace(canary.Canary.Namespace)
+}
+
+// getCanary returns a canary upgrade the caller may see
+func (s *ServerContext) getCanary(ctx context.Context, id string) (*fleet.Canary, error) {
+	if s.Canaries == nil {
+		return nil, fmt.Errorf("canary upgrade %s not found", id)
+	}
+	canary, ok := s.Canaries.Get(id)
+	if !ok || !canaryVisible(ctx, canary) {
+		return nil, fmt.Errorf("canary upgrade %s not found", id)
+	}
+	return canary, nil
+}
+
+// startCanary runs a canary upgrade in a background job
+func (s *ServerContext) startCanary(ctx context.Context, canary *fleet.Canary) (*fleet.Canary, error) {
+	c := s.client(ctx)
+	target := fmt.Sprintf("%s to %s", canary.ID, canary.Options.TargetVersion)
+	job, err := s.startJob(ctx, "canary-upgrade", canary.Canary.Namespace, target, func(ctx context.Context, logf func(format string, args ...any)) (any, error) {
+		return fleet.RunCanary(ctx, c, s.Canaries, canary.ID, logf)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Canaries.Update(canary.ID, func(c *fleet.Canary) { c.JobID = job.ID })
+}
+
+// createCanaryUpgradeHandler creates a handler for starting a canary upgrade
+func createCanaryUpgradeHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := canaryUpgradeParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		if serverCtx.Canaries == nil {
+			return toolError(fmt.Errorf("canary upgrades are not available"))
+		}
+		namespace := args.String("namespace")
+		canaryCluster := args.String("canary_cluster")
+
+		var rest []fleet.Target
+		if names := args.String("clusters"); names != "" {
+			if args.Provided(labelSelectorArgument) {
+				return invalidArgument("clusters and label_selector cannot be combined")
+			}
+			for _, name := range strings.Split(names, ",") {
+				if name = strings.TrimSpace(name); name != "" && name != canaryCluster {
+					rest = append(rest, fleet.Target{Namespace: namespace, Name: name})
+				}
+			}
+		} else {
+			clusters, err := serverCtx.client(ctx).ListClusters(ctx, namespace, capi.WithLabelSelector(args.String(labelSelectorArgument)))
+			if err != nil {
+				return toolError(fmt.Errorf("failed to list clusters: %w", err))
+			}
+			for _, cluster := range clusters.Items {
+				if cluster.Name != canaryCluster {
+					rest = append(rest, fleet.Target{Namespace: cluster.Namespace, Name: cluster.Name})
+				}
+			}
+		}
+
+		canary, err := serverCtx.Canaries.Create(fleet.Canary{
+			Options: fleet.UpgradeOptions{
+				TargetVersion:  args.String("target_version"),
+				UpgradeWorkers: args.Bool("upgrade_workers"),
+				Concurrency:    args.Int("concurrency"),
+			},
+			Canary:            fleet.Target{Namespace: namespace, Name: canaryCluster},
+			MachineDeployment: args.String("canary_machinedeployment"),
+			Rest:              rest,
+			SoakDuration:      time.Duration(args.Int("soak_minutes")) * time.Minute,
+			ManagementCluster: params.OptionalString(request.GetArguments(), managementClusterArgument, ""),
+			Requester:         requesterFromContext(ctx),
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to save the canary upgrade: %w", err))
+		}
+		canary, err = serverCtx.startCanary(ctx, canary)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to start the canary upgrade: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("🐤 Started canary upgrade %s to %s in job %s\n\n", canary.ID, canary.Options.TargetVersion, canary.JobID))
+		content.WriteString(fmt.Sprintf("1. Upgrade the canary %s\n", canary.Subject()))
+		content.WriteString(fmt.Sprintf("2. Verify it stays healthy for %s\n", canary.SoakDuration))
+		content.WriteString(fmt.Sprintf("3. Upgrade %d more clusters, %d at a time\n", len(canary.Rest), max(canary.Options.Concurrency, 1)))
+		content.WriteString("\n• Check its stage with: capi_canary_status\n")
+		content.WriteString("• Resume it after a server restart with: capi_canary_resume\n")
+
+		return newToolResult(content.String(), canary)
+	}
+}
+
+// createCanaryStatusHandler creates a handler for showing canary upgrades
+func createCanaryStatusHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := canaryStatusParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		if id := args.String("canary_id"); id != "" {
+			canary, err := serverCtx.getCanary(ctx, id)
+			if err != nil {
+				return toolError(err)
+			}
+			return newToolResult(serverCtx.formatCanary(canary), canary)
+		}
+
+		var all []fleet.Canary
+		if serverCtx.Canaries != nil {
+			all = serverCtx.Canaries.List()
+		}
+		var content strings.Builder
+		visible := []fleet.Canary{}
+		for i := range all {
+			if !canaryVisible(ctx, &all[i]) {
+				continue
+			}
+			visible = append(visible, all[i])
+			content.WriteString(serverCtx.formatCanary(&all[i]))
+			content.WriteString("\n")
+		}
+
+		header := fmt.Sprintf("Found %d canary upgrades:\n\n", len(visible))
+		return newToolResult(header+content.String(), map[string]any{"canaries": visible})
+	}
+}
+
+// createCanaryResumeHandler creates a handler for resuming a canary upgrade
+func createCanaryResumeHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := canaryResumeParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		canary, err := serverCtx.getCanary(ctx, args.String("canary_id"))
+		if err != nil {
+			return toolError(err)
+		}
+		switch {
+		case canary.Finished():
+			return invalidArgument("canary upgrade %s has already finished", canary.ID)
+		case serverCtx.Canaries.Running(canary.ID):
+			return invalidArgument("canary upgrade %s is already running in job %s", canary.ID, canary.JobID)
+		}
+		if managementCluster := params.OptionalString(request.GetArguments(), managementClusterArgument, ""); managementCluster != canary.ManagementCluster {
+			return invalidArgument("canary upgrade %s runs against management cluster %q, resume it with the same management_cluster", canary.ID, canary.ManagementCluster)
+		}
+
+		stage := canary.Stage
+		canary, err = serverCtx.startCanary(ctx, canary)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to resume the canary upgrade: %w", err))
+		}
+
+		message := fmt.Sprintf("▶️ Resumed canary upgrade %s at the %s stage in job %s\n", canary.ID, stage, canary.JobID)
+		return newToolResult(message, canary)
+	}
+}
+
+// formatCanary describes a canary upgrade for the text result
+func (s *ServerContext) formatCanary(canary *fleet.Canary) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Canary upgrade: %s\n", canary.ID))
+	content.WriteString(fmt.Sprintf("  Target version: %s\n", canary.Options.TargetVersion))
+	content.WriteString(fmt.Sprintf("  Canary: %s\n", canary.Subject()))
+	content.WriteString(fmt.Sprintf("  Remaining clusters: %d\n", len(canary.Rest)))
+
+	state := "interrupted, resume with capi_canary_resume"
+	switch {
+	case canary.Finished():
+		state = "finished"
+	case canary.Failed():
+		state = "failed: " + canary.Error
+	case s.Canaries != nil && s.Canaries.Running(canary.ID):
+		state = "running in job " + canary.JobID
+	}
+	content.WriteString(fmt.Sprintf("  Stage: %s (%s)\n", canary.Stage, state))
+	if canary.Stage == fleet.StageSoak && canary.SoakStartedAt != nil {
+		content.WriteString(fmt.Sprintf("  Soak ends: %s\n", canary.SoakStartedAt.Add(canary.SoakDuration).UTC().Format(time.RFC3339)))
+	}
+	if canary.Rollout != nil {
+		counts := canary.Rollout.Counts()
+		content.WriteString(fmt.Sprintf("  Rollout: %d succeeded, %d skipped, %d failed, %d not started\n",
+			counts[fleet.OutcomeSucceeded], counts[fleet.OutcomeSkipped], counts[fleet.OutcomeFailed], counts[fleet.OutcomeNotStarted]))
+	}
+	content.WriteString(fmt.Sprintf("  Updated: %s\n", canary.UpdatedAt.UTC().Format(time.RFC3339)))
+	return content.String()
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// requestIDHeader carries the JSON-RPC id of a tool call from the hook that
+// sees it to the middleware, which only receives the request
+const requestIDHeader = "Mcp-Capi-Request-Id"
+
+// MethodNotificationCancelled is sent by clients abandoning a request
+const MethodNotificationCancelled = "notifications/cancelled"
+
+// Cancellations aborts tool calls whose client sent notifications/cancelled,
+// so their Kubernetes requests are aborted instead of running to the end
+type Cancellations struct {
+	mu sync.Mutex
+	// calls holds the cancel function of each call in flight, by session
+	// and request id
+	calls map[string]context.CancelFunc
+}
+
+// NewCancellations creates a registry without calls in flight
+func NewCancellations() *Cancellations {
+	return &Cancellations{calls: map[string]context.CancelFunc{}}
+}
+
+// cancellationKey identifies a request id within the session of ctx
+func cancellationKey(ctx context.Context, id string) string {
+	sessionID := ""
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		sessionID = session.SessionID()
+	}
+	return sessionID + "/" + id
+}
+
+// BeforeCallTool stamps the JSON-RPC id onto the tool call. Register it with
+// server.Hooks.AddBeforeCallTool.
+func (c *Cancellations) BeforeCallTool(_ context.Context, id any, message *mcp.CallToolRequest) {
+	// Clone, so the headers of an HTTP request are left untouched
+	header := http.Header{}
+	if message.Header != nil {
+		header = message.Header.Clone()
+	}
+	header.Set(requestIDHeader, mcp.NewRequestId(id).String())
+	message.Header = header
+}
+
+// HandleCancelled cancels the call named by a notifications/cancelled
+// notification. Register it with server.MCPServer.AddNotificationHandler.
+func (c *Cancellations) HandleCancelled(ctx context.Context, notification mcp.JSONRPCNotification) {
+	id, ok := notification.Params.AdditionalFields["requestId"]
+	if !ok {
+		return
+	}
+	requestID := mcp.NewRequestId(id).String()
+	c.mu.Lock()
+	cancel, ok := c.calls[cancellationKey(ctx, requestID)]
+	c.mu.Unlock()
+	if !ok {
+		// The call already finished, or was no tool call
+		return
+	}
+	reason, _ := notification.Params.AdditionalFields["reason"].(string)
+	slog.InfoContext(ctx, "Tool call cancelled by the client", "request_id", id, "reason", reason)
+	cancel()
+}
+
+// begin registers a call under key and returns its cancelable context
+func (c *Cancellations) begin(ctx context.Context, key string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	c.calls[key] = cancel
+	c.mu.Unlock()
+	return ctx, func() {
+		c.mu.Lock()
+		delete(c.calls, key)
+		c.mu.Unlock()
+		cancel()
+	}
+}
+
+// NewCancellationMiddleware derives a context for each tool call that is
+// canceled when the client cancels the request, aborting the Kubernetes
+// requests made with it
+func NewCancellationMiddleware(cancellations *Cancellations) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			id := request.Header.Get(requestIDHeader)
+			if id == "" {
+				return next(ctx, request)
+			}
+			ctx, end := cancellations.begin(ctx, cancellationKey(ctx, id))
+			defer end()
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerChangeTools adds the change history tools
+func registerChangeTools(s Registry, serverCtx *ServerContext) {
+	// Change history tools
+	listChangesTool := mcp.NewTool(
+		"capi_list_changes",
+		mcp.WithDescription("List changes made through the server that can be reverted"),
+		mcp.WithString("namespace",
+			mcp.Description("Only show changes in this namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Only show changes to resources with this name"),
+		),
+	)
+	addTool(s, listChangesTool, createListChangesHandler(serverCtx))
+
+	revertChangeTool := mcp.NewTool(
+		"capi_revert_change",
+		mcp.WithDescription("Revert a change made through the server by restoring the resource's prior spec, labels and annotations"),
+		mcp.WithString("change_id",
+			mcp.Required(),
+			mcp.Description("ID of the change to revert (from capi_list_changes)"),
+		),
+		withApprovalID(),
+	)
+	addTool(s, revertChangeTool, createRevertChangeHandler(serverCtx))
+}
+
+// createListChangesHandler creates a handler for listing revertible changes
+func createListChangesHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, _ := arguments["namespace"].(string)
+		name, _ := arguments["name"].(string)
+
+		var content strings.Builder
+		changes := []capi.Change{}
+		for _, change := range serverCtx.client(ctx).ChangeHistory().List() {
+			if namespace != "" && change.Namespace != namespace {
+				continue
+			}
+			if name != "" && change.Name != name {
+				continue
+			}
+			// Snapshots are only needed to revert and would dominate the output
+			change.Snapshot = nil
+			changes = append(changes, change)
+			content.WriteString(fmt.Sprintf("Change: %s\n", change.ID))
+			content.WriteString(fmt.Sprintf("  Operation: %s\n", change.Operation))
+			content.WriteString(fmt.Sprintf("  Resource: %s %s/%s\n", change.Kind, change.Namespace, change.Name))
+			content.WriteString(fmt.Sprintf("  Time: %s\n", change.Time.UTC().Format(time.RFC3339)))
+			if change.Reverted() {
+				content.WriteString(fmt.Sprintf("  Reverted: %s\n", change.RevertedAt.UTC().Format(time.RFC3339)))
+			}
+			content.WriteString("\n")
+		}
+
+		header := fmt.Sprintf("Found %d changes:\n\n", len(changes))
+		return newToolResult(header+content.String(), map[string]any{"changes": changes})
+	}
+}
+
+// createRevertChangeHandler creates a handler for rolling back a recorded change
+func createRevertChangeHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		changeID, err := params.RequiredString(arguments, "change_id")
+		if err != nil {
+			return toolError(err)
+		}
+
+		change, err := serverCtx.client(ctx).RevertChange(ctx, changeID)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to revert change %s: %w", changeID, err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("↩️  Reverted change %s\n\n", change.ID))
+		content.WriteString(fmt.Sprintf("  • Operation: %s\n", change.Operation))
+		content.WriteString(fmt.Sprintf("  • Resource: %s %s/%s\n", change.Kind, change.Namespace, change.Name))
+		content.WriteString(fmt.Sprintf("  • Originally made: %s\n\n", change.Time.UTC().Format(time.RFC3339)))
+		content.WriteString("The spec, labels and annotations were restored to their prior values.\n")
+		content.WriteString("The revert itself was recorded and can be undone with capi_revert_change.\n")
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "revert",
+			Resource:  resourceRef{Kind: change.Kind, Namespace: change.Namespace, Name: change.Name},
+			Details:   map[string]any{"changeId": change.ID, "revertedOperation": change.Operation},
+		})
+	}
+}
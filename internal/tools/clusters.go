@@ -0,0 +1,1870 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/jobs"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerClusterTools adds the cluster lifecycle tools
+func registerClusterTools(s Registry, serverCtx *ServerContext) {
+	// Add CAPI create cluster tool
+	createClusterTool := createClusterParams.NewTool(
+		"capi_create_cluster",
+		"Create a new CAPI cluster (basic implementation)",
+	)
+
+	addTool(s, createClusterTool, createCreateClusterHandler(serverCtx))
+
+	// Add CAPI list clusters tool
+	listClustersTool := listClustersParams.NewTool(
+		"capi_list_clusters",
+		"List all CAPI clusters",
+	)
+
+	addTool(s, listClustersTool, createListClustersHandler(serverCtx))
+
+	// Add CAPI get cluster tool
+	getClusterTool := mcp.NewTool(
+		"capi_get_cluster",
+		mcp.WithDescription("Get details of a specific CAPI cluster"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the cluster"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the cluster"),
+		),
+	)
+
+	addTool(s, getClusterTool, createGetClusterHandler(serverCtx))
+
+	// Add CAPI cluster status tool
+	clusterStatusTool := mcp.NewTool(
+		"capi_cluster_status",
+		mcp.WithDescription("Get detailed status of a CAPI cluster including conditions and provider status"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the cluster"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the cluster"),
+		),
+	)
+
+	addTool(s, clusterStatusTool, createClusterStatusHandler(serverCtx))
+
+	// Add CAPI cluster health tool
+	clusterHealthTool := mcp.NewTool(
+		"capi_cluster_health",
+		mcp.WithDescription("Check cluster health and identify issues"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the cluster"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the cluster"),
+		),
+	)
+
+	addTool(s, clusterHealthTool, createClusterHealthHandler(serverCtx))
+
+	// Reachability of the API servers from this server
+	registerAPIServerTools(s, serverCtx)
+
+	// Add CAPI upgrade cluster tool
+	upgradeClusterTool := upgradeClusterParams.NewTool(
+		"capi_upgrade_cluster",
+		"Upgrade a CAPI cluster to a new Kubernetes version",
+		withApprovalID(),
+	)
+
+	addTool(s, upgradeClusterTool, createUpgradeClusterHandler(serverCtx))
+
+	// Add CAPI generate cluster tool
+	generateClusterTool := generateClusterParams.NewTool(
+		"capi_generate_cluster",
+		"Render the complete manifests of a new cluster like clusterctl generate cluster, from the cluster template of a provider release (optionally a flavor) or as a Cluster of a ClusterClass, filled with the given variables. Returns the YAML for review; creates the objects with apply.",
+	)
+
+	addTool(s, generateClusterTool, createGenerateClusterHandler(serverCtx))
+
+	// Add CAPI template variables tool
+	templateVariablesTool := templateVariablesParams.NewTool(
+		"capi_template_variables",
+		"List the variables of the cluster template of a provider release or of a ClusterClass, with their type, default and whether they are required, and validate proposed values against them before capi_generate_cluster",
+	)
+
+	addTool(s, templateVariablesTool, createTemplateVariablesHandler(serverCtx))
+
+	// Add CAPI validate cluster config tool
+	validateClusterConfigTool := validateClusterConfigParams.NewTool(
+		"capi_validate_cluster_config",
+		"Check a proposed cluster before creating it: valid and unused name, existing namespace, installed and healthy infrastructure provider, existing machine templates and ClusterClass, pod and service CIDRs not overlapping each other or the clusters in the namespace, and an available Kubernetes version. Returns a pass/fail report with the reasons of every check.",
+	)
+
+	addTool(s, validateClusterConfigTool, createValidateClusterConfigHandler(serverCtx))
+
+	// Add CAPI clone cluster tool
+	cloneClusterTool := cloneClusterParams.NewTool(
+		"capi_clone_cluster",
+		"Create a new cluster from the configuration of an existing one, e.g. an ephemeral copy of a known-good cluster. Copies the Cluster (only it for ClusterClass-based clusters) with its infrastructure cluster, control plane, machine deployments, machine pools and their templates under the new name, without status, endpoints or the paused and hibernated markers.",
+	)
+
+	addTool(s, cloneClusterTool, createCloneClusterHandler(serverCtx))
+
+	// Add CAPI available versions tool
+	availableVersionsTool := availableVersionsParams.NewTool(
+		"capi_available_versions",
+		"List the Kubernetes versions available for a provider or cluster, from Giant Swarm releases, machine images and clusters in use",
+	)
+
+	addTool(s, availableVersionsTool, createAvailableVersionsHandler(serverCtx))
+
+	// Add CAPI upgrade plan tool
+	upgradePlanTool := upgradePlanParams.NewTool(
+		"capi_upgrade_plan",
+		"Preview the upgrade of a cluster without changing anything: current and target versions, objects modified, machines replaced and blockers",
+	)
+
+	addTool(s, upgradePlanTool, createUpgradePlanHandler(serverCtx))
+
+	// Add CAPI update cluster tool
+	updateClusterTool := mcp.NewTool(
+		"capi_update_cluster",
+		mcp.WithDescription("Update cluster metadata (labels and annotations)"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the cluster"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the cluster"),
+		),
+		mcp.WithObject("labels",
+			mcp.Description("Labels to add/update/remove (use empty string to remove)"),
+		),
+		mcp.WithObject("annotations",
+			mcp.Description("Annotations to add/update/remove (use empty string to remove)"),
+		),
+	)
+
+	addTool(s, updateClusterTool, createUpdateClusterHandler(serverCtx))
+
+	// Add CAPI move cluster tool
+	moveClusterTool := mcp.NewTool(
+		"capi_move_cluster",
+		mcp.WithDescription("Prepare a cluster for migration to another management cluster"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the cluster"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the cluster"),
+		),
+		mcp.WithString("target_kubeconfig",
+			mcp.Description("Path to target management cluster kubeconfig"),
+		),
+		mcp.WithString("target_namespace",
+			mcp.Description("Target namespace (defaults to source namespace)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Show what would be moved without doing it"),
+		),
+	)
+
+	addTool(s, moveClusterTool, createMoveClusterHandler(serverCtx))
+
+	// Add CAPI backup cluster tool
+	backupClusterTool := backupClusterParams.NewTool(
+		"capi_backup_cluster",
+		"Create a backup bundle of the CAPI objects of a cluster and return it inline; capi_push_backup stores it on a backup target instead",
+	)
+
+	addTool(s, backupClusterTool, createBackupClusterHandler(serverCtx))
+
+	// Add CAPI scale cluster tool
+	scaleClusterTool := scaleClusterParams.NewTool(
+		"capi_scale_cluster",
+		"Scale control plane or worker nodes of a CAPI cluster",
+		withApprovalID(),
+	)
+
+	addTool(s, scaleClusterTool, createScaleClusterHandler(serverCtx))
+
+	// Add CAPI get kubeconfig tool
+	getKubeconfigTool := getKubeconfigParams.NewTool(
+		"capi_get_kubeconfig",
+		"Retrieve the kubeconfig of a workload cluster. Client keys and tokens are masked unless output is 'full'; set path to write the full kubeconfig to a file and return only its connection metadata.",
+	)
+
+	addTool(s, getKubeconfigTool, createGetKubeconfigHandler(serverCtx))
+
+	// Short-lived alternatives to the admin kubeconfig
+	registerCredentialTools(s, serverCtx)
+
+	// Add CAPI pause cluster tool
+	pauseClusterTool := mcp.NewTool(
+		"capi_pause_cluster",
+		mcp.WithDescription("Pause cluster reconciliation (stops all CAPI controllers from reconciling the cluster)"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the cluster"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the cluster"),
+		),
+	)
+
+	addTool(s, pauseClusterTool, createPauseClusterHandler(serverCtx))
+
+	// Add CAPI resume cluster tool
+	resumeClusterTool := mcp.NewTool(
+		"capi_resume_cluster",
+		mcp.WithDescription("Resume cluster reconciliation (allows CAPI controllers to reconcile the cluster again)"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the cluster"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the cluster"),
+		),
+	)
+
+	addTool(s, resumeClusterTool, createResumeClusterHandler(serverCtx))
+
+	hibernateClusterTool := hibernateClusterParams.NewTool(
+		"capi_hibernate_cluster",
+		"Hibernate a cluster to save costs: record the replicas of its MachineDeployments and MachinePools in annotations, scale them to zero (and the control plane where its kind supports it), then pause reconciliation once the machines are deleted",
+		withApprovalID(),
+	)
+
+	addTool(s, hibernateClusterTool, createHibernateClusterHandler(serverCtx))
+
+	wakeClusterTool := wakeClusterParams.NewTool(
+		"capi_wake_cluster",
+		"Wake up a cluster hibernated by capi_hibernate_cluster: resume reconciliation and scale its MachineDeployments, MachinePools and control plane back to their recorded replicas",
+	)
+
+	addTool(s, wakeClusterTool, createWakeClusterHandler(serverCtx))
+
+	// Add CAPI delete cluster tool
+	deleteClusterTool := mcp.NewTool(
+		"capi_delete_cluster",
+		mcp.WithDescription("Delete a CAPI cluster safely (with confirmation)"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the cluster"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the cluster"),
+		),
+		mcp.WithBoolean("force",
+			mcp.Description("Skip safety checks and force deletion (use with caution)"),
+		),
+		withApprovalID(),
+	)
+
+	addTool(s, deleteClusterTool, createDeleteClusterHandler(serverCtx))
+}
+
+// createClusterParams declares the arguments of capi_create_cluster
+var createClusterParams = params.Schema{
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster", Validate: params.KubernetesName},
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace for the cluster", Validate: params.Namespace},
+	{Name: "provider", Type: params.String, Required: true, Description: "Infrastructure provider, e.g. aws, azure, gcp, vsphere, or docker for a CAPD development cluster", Enum: capi.ProviderNames()},
+	{Name: "kubernetes_version", Type: params.String, Validate: params.Semver,
+		Description: "Kubernetes version (default: the default version of the server, else the newest version available for the provider, see capi_available_versions)"},
+	{Name: "control_plane_count", Type: params.Int, Default: 3, NonNegative: true, Description: "Number of control plane nodes (default: 3)"},
+	{Name: "worker_count", Type: params.Int, Default: 3, NonNegative: true, Description: "Number of worker nodes (default: 3)"},
+	{Name: "region", Type: params.String, Description: "Cloud provider region"},
+	{Name: "instance_type", Type: params.String, Description: "Instance type for nodes"},
+}
+
+// generateClusterParams declares the arguments of capi_generate_cluster
+var generateClusterParams = params.Schema{
+	{Name: "name", Type: params.String, Required: true, Validate: params.KubernetesName, Description: "Name of the cluster"},
+	{Name: "namespace", Type: params.String, Required: true, Validate: params.Namespace, Description: "Namespace for the cluster"},
+	{Name: "provider", Type: params.String,
+		Description: "Infrastructure provider whose cluster template is rendered, e.g. aws or infrastructure-aws (required without cluster_class)"},
+	{Name: "provider_version", Type: params.String, Validate: params.Semver,
+		Description: "Release of the provider to take the template from (default: the latest release)"},
+	{Name: "flavor", Type: params.String, Description: "Template flavor, e.g. machinepool for cluster-template-machinepool.yaml (default: cluster-template.yaml)"},
+	{Name: "cluster_class", Type: params.String, Validate: params.KubernetesName,
+		Description: "ClusterClass to create a Cluster with a topology of, instead of a provider template"},
+	{Name: "class_namespace", Type: params.String, Validate: params.Namespace,
+		Description: "Namespace of the ClusterClass (defaults to the namespace of the cluster)"},
+	{Name: "kubernetes_version", Type: params.String, Validate: params.Semver,
+		Description: "Kubernetes version (default: the default version of the server, else with a provider the newest version available for it, see capi_available_versions)"},
+	{Name: "control_plane_count", Type: params.Int, Default: 1, NonNegative: true, Description: "Number of control plane nodes (default: 1, like clusterctl)"},
+	{Name: "worker_count", Type: params.Int, Default: 0, NonNegative: true, Description: "Number of worker nodes (default: 0, like clusterctl)"},
+	{Name: "variables", Type: params.StringMap,
+		Description: "Template variables such as AWS_REGION, or the ClusterClass variables as JSON values or strings. CLUSTER_NAME, NAMESPACE, KUBERNETES_VERSION and the machine counts are set from the other arguments; the server environment is not used."},
+	{Name: "apply", Type: params.Bool, Description: "Create the rendered objects in the management cluster (default: false, only return the YAML)"},
+}
+
+// createGenerateClusterHandler creates a handler for rendering cluster templates
+func createGenerateClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := generateClusterParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		if args.String("provider") == "" && args.String("cluster_class") == "" {
+			return invalidArgument("provider or cluster_class is required")
+		}
+		repo, ok := serverCtx.providerRepository().(capi.TemplateRepository)
+		if !ok {
+			return toolError(fmt.Errorf("the provider repository does not serve cluster templates"))
+		}
+
+		opts := capi.GenerateClusterOptions{
+			Name:              args.String("name"),
+			Namespace:         args.String("namespace"),
+			KubernetesVersion: serverCtx.kubernetesVersion(args.String("kubernetes_version")),
+			ControlPlaneCount: args.Int32("control_plane_count"),
+			WorkerCount:       args.Int32("worker_count"),
+			Provider:          args.String("provider"),
+			Version:           args.String("provider_version"),
+			Flavor:            args.String("flavor"),
+			ClusterClass:      args.String("cluster_class"),
+			ClassNamespace:    args.String("class_namespace"),
+			Variables:         args.StringMap("variables"),
+			Apply:             args.Bool("apply"),
+		}
+		c := serverCtx.client(ctx)
+		if opts.KubernetesVersion == "" && opts.ClusterClass == "" {
+			provider := strings.TrimPrefix(opts.Provider, "infrastructure-")
+			catalog, err := c.AvailableVersions(ctx, capi.VersionQuery{Provider: capi.Provider(provider), Namespace: opts.Namespace})
+			if err != nil {
+				return toolError(fmt.Errorf("failed to look up available versions: %w", err))
+			}
+			opts.KubernetesVersion = catalog.Default()
+		}
+
+		generated, err := c.GenerateCluster(ctx, repo, opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to generate cluster: %w", err))
+		}
+
+		var content strings.Builder
+		source := "ClusterClass " + generated.ClusterClass
+		if generated.Provider != "" {
+			template := "cluster-template.yaml"
+			if generated.Flavor != "" {
+				template = "cluster-template-" + generated.Flavor + ".yaml"
+			}
+			source = fmt.Sprintf("%s %s template %s", generated.Provider, generated.Version, template)
+		}
+		if generated.Applied {
+			content.WriteString(fmt.Sprintf("✅ Created cluster %s/%s from %s\n\n", generated.Namespace, generated.Name, source))
+		} else {
+			content.WriteString(fmt.Sprintf("📄 Rendered cluster %s/%s from %s, nothing was created\n\n", generated.Namespace, generated.Name, source))
+		}
+		for _, obj := range generated.Objects {
+			content.WriteString(fmt.Sprintf("  - %s %s\n", obj.Kind, obj.Name))
+		}
+		content.WriteString("\nManifests:\n```yaml\n" + generated.Manifests + "```\n")
+		if generated.Applied {
+			content.WriteString("\nMonitor cluster creation with: capi_cluster_status\n")
+		} else {
+			content.WriteString("\nCreate the cluster by calling again with apply: true\n")
+		}
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "generate",
+			Resource:  clusterRef(generated.Namespace, generated.Name),
+			Details:   map[string]any{"cluster": generated},
+		})
+	}
+}
+
+// templateVariablesParams declares the arguments of capi_template_variables
+var templateVariablesParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Validate: params.Namespace, Description: "Namespace of the cluster to generate"},
+	{Name: "provider", Type: params.String,
+		Description: "Infrastructure provider whose cluster template is inspected, e.g. aws or infrastructure-aws (required without cluster_class)"},
+	{Name: "provider_version", Type: params.String, Validate: params.Semver,
+		Description: "Release of the provider to take the template from (default: the latest release)"},
+	{Name: "flavor", Type: params.String, Description: "Template flavor, e.g. machinepool for cluster-template-machinepool.yaml (default: cluster-template.yaml)"},
+	{Name: "cluster_class", Type: params.String, Validate: params.KubernetesName,
+		Description: "ClusterClass to inspect, instead of a provider template"},
+	{Name: "class_namespace", Type: params.String, Validate: params.Namespace,
+		Description: "Namespace of the ClusterClass (defaults to namespace)"},
+	{Name: "variables", Type: params.StringMap,
+		Description: "Proposed variable values to validate, as for capi_generate_cluster; without them the variables are only listed"},
+}
+
+// createTemplateVariablesHandler creates a handler for listing and
+// validating the variables of cluster templates and ClusterClasses
+func createTemplateVariablesHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := templateVariablesParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		if (args.String("provider") == "") == (args.String("cluster_class") == "") {
+			return invalidArgument("either provider or cluster_class is required")
+		}
+		repo, ok := serverCtx.providerRepository().(capi.TemplateRepository)
+		if !ok {
+			return toolError(fmt.Errorf("the provider repository does not serve cluster templates"))
+		}
+
+		result, err := serverCtx.client(ctx).TemplateVariables(ctx, repo, capi.GenerateClusterOptions{
+			Namespace:      args.String("namespace"),
+			Provider:       args.String("provider"),
+			Version:        args.String("provider_version"),
+			Flavor:         args.String("flavor"),
+			ClusterClass:   args.String("cluster_class"),
+			ClassNamespace: args.String("class_namespace"),
+			Variables:      args.StringMap("variables"),
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get template variables: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Variables of %s (%d):\n\n", result.Source, len(result.Variables)))
+		for _, variable := range result.Variables {
+			line := fmt.Sprintf("  - %s (%s", variable.Name, variable.Type)
+			switch {
+			case variable.Builtin:
+				line += ", set by capi_generate_cluster"
+			case variable.Required:
+				line += ", required"
+			}
+			if variable.Default != "" {
+				line += ", default " + variable.Default
+			}
+			if len(variable.Enum) > 0 {
+				line += ", one of " + strings.Join(variable.Enum, ", ")
+			}
+			line += ")"
+			if variable.Description != "" {
+				line += ": " + variable.Description
+			}
+			content.WriteString(line + "\n")
+		}
+		if args.Provided("variables") {
+			if len(result.Problems) == 0 {
+				content.WriteString("\n✅ The proposed variables are valid\n")
+			} else {
+				content.WriteString(fmt.Sprintf("\n❌ The proposed variables have %d problems:\n", len(result.Problems)))
+				for _, problem := range result.Problems {
+					content.WriteString(fmt.Sprintf("  - %s\n", problem))
+				}
+			}
+		}
+
+		return newToolResult(content.String(), result)
+	}
+}
+
+// validateClusterConfigParams declares the arguments of
+// capi_validate_cluster_config. Name and namespace are checked by the tool
+// itself, so invalid ones end up in the report.
+var validateClusterConfigParams = params.Schema{
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the proposed cluster"},
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the proposed cluster"},
+	{Name: "provider", Type: params.String, Required: true, Description: "Infrastructure provider, e.g. aws, azure, gcp, vsphere or docker"},
+	{Name: "kubernetes_version", Type: params.String,
+		Description: "Kubernetes version (default: the default version of the server, else the newest version available for the provider, see capi_available_versions)"},
+	{Name: "pod_cidrs", Type: params.String,
+		Description: "Comma-separated pod CIDR blocks (default: those capi_create_cluster sets)"},
+	{Name: "service_cidrs", Type: params.String,
+		Description: "Comma-separated service CIDR blocks (default: those capi_create_cluster sets)"},
+	{Name: "machine_templates", Type: params.String,
+		Description: "Comma-separated names of infrastructure machine templates of the provider the cluster would use, e.g. AWSMachineTemplates"},
+	{Name: "cluster_class", Type: params.String, Description: "ClusterClass the cluster would have a topology of"},
+	{Name: "class_namespace", Type: params.String, Description: "Namespace of the ClusterClass (defaults to namespace)"},
+}
+
+// createValidateClusterConfigHandler creates a handler for the preflight
+// checks of a proposed cluster
+func createValidateClusterConfigHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := validateClusterConfigParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+
+		report := serverCtx.client(ctx).ValidateClusterConfig(ctx, capi.ClusterConfig{
+			Name:              args.String("name"),
+			Namespace:         args.String("namespace"),
+			Provider:          args.String("provider"),
+			KubernetesVersion: serverCtx.kubernetesVersion(args.String("kubernetes_version")),
+			PodCIDRs:          splitList(args.String("pod_cidrs")),
+			ServiceCIDRs:      splitList(args.String("service_cidrs")),
+			MachineTemplates:  splitList(args.String("machine_templates")),
+			ClusterClass:      args.String("cluster_class"),
+			ClassNamespace:    args.String("class_namespace"),
+		})
+
+		var content strings.Builder
+		if report.Passed {
+			content.WriteString(fmt.Sprintf("✅ Cluster %s/%s passed all checks\n\n", report.Namespace, report.Name))
+		} else {
+			content.WriteString(fmt.Sprintf("❌ Cluster %s/%s failed checks\n\n", report.Namespace, report.Name))
+		}
+		for _, check := range report.Checks {
+			icon := "✅"
+			if !check.Passed {
+				icon = "❌"
+			}
+			content.WriteString(fmt.Sprintf("%s %s\n", icon, check.Name))
+			for _, reason := range check.Reasons {
+				content.WriteString(fmt.Sprintf("  - %s\n", reason))
+			}
+		}
+
+		return newToolResult(content.String(), report)
+	}
+}
+
+// cloneClusterParams declares the arguments of capi_clone_cluster
+var cloneClusterParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Validate: params.Namespace, Description: "Namespace of the cluster to clone"},
+	{Name: "name", Type: params.String, Required: true, Validate: params.KubernetesName, Description: "Name of the cluster to clone"},
+	{Name: "new_name", Type: params.String, Required: true, Validate: params.KubernetesName,
+		Description: "Name of the new cluster; object names starting with the source name get it instead, others get it as a prefix"},
+	{Name: "target_namespace", Type: params.String, Validate: params.Namespace,
+		Description: "Namespace of the new cluster (defaults to the namespace of the source); ClusterClass-based clones keep using the class of the source namespace"},
+	{Name: "pod_cidrs", Type: params.String,
+		Description: "Comma-separated pod CIDR blocks of the new cluster (defaults to those of the source)"},
+	{Name: "service_cidrs", Type: params.String,
+		Description: "Comma-separated service CIDR blocks of the new cluster (defaults to those of the source)"},
+	{Name: "dry_run", Type: params.Bool, Description: "Return the manifests of the new cluster without creating it (default: false)"},
+}
+
+// createCloneClusterHandler creates a handler for cloning a cluster
+func createCloneClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := cloneClusterParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+
+		clone, err := serverCtx.client(ctx).CloneCluster(ctx, capi.CloneClusterOptions{
+			SourceNamespace: args.String("namespace"),
+			SourceName:      args.String("name"),
+			Namespace:       args.String("target_namespace"),
+			Name:            args.String("new_name"),
+			PodCIDRs:        splitList(args.String("pod_cidrs")),
+			ServiceCIDRs:    splitList(args.String("service_cidrs")),
+			DryRun:          args.Bool("dry_run"),
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to clone cluster: %w", err))
+		}
+
+		var content strings.Builder
+		if clone.DryRun {
+			content.WriteString(fmt.Sprintf("🔍 Dry run: cloning %s would create %s/%s from %d objects\n\n", clone.Source, clone.Namespace, clone.Name, len(clone.Objects)))
+		} else {
+			content.WriteString(fmt.Sprintf("✅ Cloned %s to %s/%s\n\n", clone.Source, clone.Namespace, clone.Name))
+		}
+		for _, obj := range clone.Objects {
+			content.WriteString(fmt.Sprintf("  - %s %s (from %s)\n", obj.Kind, obj.Name, obj.Source))
+		}
+		if clone.Topology {
+			content.WriteString("\nThe topology controller creates the other objects from the ClusterClass.\n")
+		}
+		if clone.DryRun {
+			content.WriteString("\nManifests:\n```yaml\n" + clone.Manifests + "```\n")
+		} else {
+			content.WriteString("\nMonitor cluster creation with: capi_cluster_status\n")
+		}
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "clone",
+			Resource:  clusterRef(clone.Namespace, clone.Name),
+			Details:   map[string]any{"clone": clone},
+		})
+	}
+}
+
+// splitList splits a comma-separated argument, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// createCreateClusterHandler creates a handler for creating new CAPI clusters
+func createCreateClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := createClusterParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		name := args.String("name")
+		namespace := args.String("namespace")
+		provider := args.String("provider")
+		kubernetesVersion := serverCtx.kubernetesVersion(args.String("kubernetes_version"))
+		if kubernetesVersion == "" {
+			catalog, err := serverCtx.client(ctx).AvailableVersions(ctx, capi.VersionQuery{Provider: capi.Provider(provider), Namespace: namespace})
+			if err != nil {
+				return toolError(fmt.Errorf("failed to look up available versions: %w", err))
+			}
+			if kubernetesVersion = catalog.Default(); kubernetesVersion == "" {
+				return invalidArgument("kubernetes_version is required: no available %s versions were found", provider)
+			}
+		}
+		controlPlaneCount := args.Int32("control_plane_count")
+		workerCount := args.Int32("worker_count")
+		region := args.String("region")
+		instanceType := args.String("instance_type")
+
+		// Create cluster options
+		opts := capi.CreateClusterOptions{
+			Name:              name,
+			Namespace:         namespace,
+			InfraProvider:     provider,
+			KubernetesVersion: kubernetesVersion,
+			ControlPlaneCount: controlPlaneCount,
+			WorkerCount:       workerCount,
+			Region:            region,
+			InstanceType:      instanceType,
+		}
+
+		// Create the cluster
+		cluster, err := serverCtx.client(ctx).CreateCluster(ctx, opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to create cluster: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("✅ Cluster '%s' creation initiated successfully!\n\n", name))
+		content.WriteString("Cluster Details:\n")
+		content.WriteString(fmt.Sprintf("  Name: %s\n", cluster.Name))
+		content.WriteString(fmt.Sprintf("  Namespace: %s\n", cluster.Namespace))
+		content.WriteString(fmt.Sprintf("  Provider: %s\n", provider))
+		content.WriteString(fmt.Sprintf("  Kubernetes Version: %s\n", kubernetesVersion))
+		content.WriteString(fmt.Sprintf("  Control Plane Nodes: %d\n", controlPlaneCount))
+		content.WriteString(fmt.Sprintf("  Worker Nodes: %d\n", workerCount))
+		if region != "" {
+			content.WriteString(fmt.Sprintf("  Region: %s\n", region))
+		}
+		if instanceType != "" {
+			content.WriteString(fmt.Sprintf("  Instance Type: %s\n", instanceType))
+		}
+		if provider == string(capi.ProviderDocker) {
+			content.WriteString("\nCreated the CAPD development cluster:\n")
+			content.WriteString(fmt.Sprintf("  - DockerCluster %s\n", name))
+			content.WriteString(fmt.Sprintf("  - KubeadmControlPlane %s-control-plane with DockerMachineTemplate %s-control-plane\n", name, name))
+			content.WriteString(fmt.Sprintf("  - MachineDeployment %s-md-0 with DockerMachineTemplate and KubeadmConfigTemplate %s-md-0\n", name, name))
+			content.WriteString("  - Cluster\n\n")
+			content.WriteString("Install a CNI once the control plane is up, e.g. Calico, for the nodes to become ready.\n")
+		} else {
+			content.WriteString("\n⚠️  Note: This is a basic implementation that creates only the Cluster resource.\n")
+			content.WriteString("Use capi_generate_cluster to render complete manifests from the provider's cluster template or a ClusterClass.\n")
+			content.WriteString("In a production setup, you would need to:\n")
+			content.WriteString("1. Create the infrastructure-specific cluster resource (e.g., AWSCluster)\n")
+			content.WriteString("2. Create the control plane (e.g., KubeadmControlPlane)\n")
+			content.WriteString("3. Create machine deployments for worker nodes\n")
+			content.WriteString("4. Configure networking, storage, and other cluster settings\n\n")
+		}
+		content.WriteString("Monitor cluster creation with: capi_cluster_status\n")
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "create",
+			Resource:  clusterRef(cluster.Namespace, cluster.Name),
+			Details: map[string]any{
+				"provider":          provider,
+				"kubernetesVersion": kubernetesVersion,
+				"controlPlaneCount": controlPlaneCount,
+				"workerCount":       workerCount,
+			},
+		})
+	}
+}
+
+// listClustersParams declares the arguments of capi_list_clusters
+var listClustersParams = append(params.Schema{
+	{Name: "namespace", Type: params.String, Description: "Namespace to filter clusters (optional, empty for all)"},
+}, listParams...)
+
+// clusterSummary is the compact form of a cluster in list results
+type clusterSummary struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Phase     string `json:"phase"`
+	Ready     bool   `json:"ready"`
+	Version   string `json:"version,omitempty"`
+	Machines  string `json:"machines"`
+}
+
+// createListClustersHandler creates a handler for listing CAPI clusters
+func createListClustersHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := listClustersParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+
+		list, err := serverCtx.client(ctx).GetClustersStatus(ctx, namespace, listOptions(args)...)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list clusters: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Found %d clusters:\n\n", len(list.Items)))
+
+		if summaryOutput(args) {
+			summaries := make([]clusterSummary, 0, len(list.Items))
+			for _, status := range list.Items {
+				summary := clusterSummary{
+					Name:      status.Name,
+					Namespace: status.Namespace,
+					Phase:     status.Phase,
+					Ready:     status.Ready,
+					Version:   status.Version,
+					Machines:  fmt.Sprintf("%d/%d", status.ReadyMachines, status.TotalMachines),
+				}
+				summaries = append(summaries, summary)
+				content.WriteString(fmt.Sprintf("%s/%s  phase=%s ready=%v version=%s machines=%s\n",
+					summary.Namespace, summary.Name, summary.Phase, summary.Ready, summaryValue(summary.Version), summary.Machines))
+			}
+			content.WriteString(continueHint(list.Continue))
+			return newToolResult(content.String(), withContinue(map[string]any{"clusters": summaries}, list.Continue))
+		}
+
+		for _, status := range list.Items {
+			content.WriteString(capi.FormatClusterInfo(status))
+			content.WriteString("\n---\n\n")
+		}
+		content.WriteString(continueHint(list.Continue))
+
+		return newToolResult(content.String(), withContinue(map[string]any{"clusters": list.Items}, list.Continue))
+	}
+}
+
+// createGetClusterHandler creates a handler for getting a specific cluster
+func createGetClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		status, err := serverCtx.client(ctx).GetClusterStatus(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get cluster status: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(capi.FormatClusterInfo(status))
+
+		return newToolResult(content.String(), status)
+	}
+}
+
+// createClusterStatusHandler creates a handler for getting detailed cluster status
+func createClusterStatusHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		status, err := serverCtx.client(ctx).GetClusterStatus(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get cluster status: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(capi.FormatClusterInfo(status))
+
+		return newToolResult(content.String(), status)
+	}
+}
+
+// createClusterHealthHandler creates a handler for checking cluster health
+func createClusterHealthHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		health, err := serverCtx.client(ctx).GetClusterHealth(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get cluster health: %w", err))
+		}
+
+		var content strings.Builder
+
+		// Overall status
+		if health.Healthy {
+			content.WriteString(fmt.Sprintf("✅ Cluster %s/%s is HEALTHY\n\n", namespace, name))
+		} else {
+			content.WriteString(fmt.Sprintf("❌ Cluster %s/%s is UNHEALTHY\n\n", namespace, name))
+		}
+
+		// Component status
+		content.WriteString("Component Status:\n")
+		content.WriteString(fmt.Sprintf("  • Control Plane: %s\n", formatHealthStatus(health.ControlPlaneReady)))
+		content.WriteString(fmt.Sprintf("  • Infrastructure: %s\n", formatHealthStatus(health.InfraReady)))
+		content.WriteString(fmt.Sprintf("  • Worker Nodes: %s\n", formatHealthStatus(health.WorkersReady)))
+
+		// Issues
+		if len(health.Issues) > 0 {
+			content.WriteString("\n🔴 Issues:\n")
+			for _, issue := range health.Issues {
+				content.WriteString(fmt.Sprintf("  • %s\n", issue))
+			}
+		}
+
+		// Warnings
+		if len(health.Warnings) > 0 {
+			content.WriteString("\n⚠️  Warnings:\n")
+			for _, warning := range health.Warnings {
+				content.WriteString(fmt.Sprintf("  • %s\n", warning))
+			}
+		}
+
+		// Recommendations
+		if !health.Healthy {
+			content.WriteString("\n📋 Recommendations:\n")
+			if !health.ControlPlaneReady {
+				content.WriteString("  • Check control plane pods and logs\n")
+				content.WriteString("  • Verify API server connectivity\n")
+			}
+			if !health.InfraReady {
+				content.WriteString("  • Check infrastructure provider status\n")
+				content.WriteString("  • Verify cloud resources are provisioned\n")
+			}
+			if !health.WorkersReady {
+				content.WriteString("  • Check machine status with 'capi_list_machines'\n")
+				content.WriteString("  • Review machine deployment events\n")
+			}
+		}
+
+		return newToolResult(content.String(), map[string]any{"cluster": clusterRef(namespace, name), "health": health})
+	}
+}
+
+// formatHealthStatus returns a formatted string for component health status
+func formatHealthStatus(ready bool) string {
+	if ready {
+		return "✅ Ready"
+	}
+	return "❌ Not Ready"
+}
+
+// scaleClusterParams declares the arguments of capi_scale_cluster
+var scaleClusterParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "target", Type: params.String, Required: true, Description: "What to scale: 'controlplane' or 'workers'", Enum: []string{"controlplane", "workers"}},
+	{Name: "replicas", Type: params.Int, Required: true, NonNegative: true, Description: "Number of replicas to scale to"},
+	{Name: "machineDeployment", Type: params.String, Description: "Name of the machine deployment (required when target is 'workers', unless machinePool is set)"},
+	{Name: "machinePool", Type: params.String, Description: "Name of the machine pool to scale instead of a machine deployment, e.g. an AKS node pool"},
+}
+
+// createScaleClusterHandler creates a handler for scaling clusters
+func createScaleClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := scaleClusterParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+
+		err = serverCtx.client(ctx).ScaleCluster(ctx, namespace, name, args.String("target"), args.Int("replicas"), args.String("machineDeployment"), args.String("machinePool"))
+		if err != nil {
+			return toolError(fmt.Errorf("failed to scale cluster: %w", err))
+		}
+
+		return newToolResult(fmt.Sprintf("Cluster %s/%s scaled successfully", namespace, name), operationResult{
+			Operation: "scale",
+			Resource:  clusterRef(namespace, name),
+			Details: map[string]any{
+				"target":            args.String("target"),
+				"replicas":          args.Int("replicas"),
+				"machineDeployment": args.String("machineDeployment"),
+				"machinePool":       args.String("machinePool"),
+			},
+		})
+	}
+}
+
+// Outputs of capi_get_kubeconfig
+const (
+	kubeconfigRedacted = "redacted"
+	kubeconfigMetadata = "metadata"
+	kubeconfigFull     = "full"
+)
+
+// getKubeconfigParams declares the arguments of capi_get_kubeconfig
+var getKubeconfigParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "output", Type: params.String, Default: kubeconfigRedacted, Enum: []string{kubeconfigRedacted, kubeconfigMetadata, kubeconfigFull},
+		Description: "What to return: 'redacted' masks client keys and tokens, 'metadata' only describes the servers, users and contexts, 'full' returns the admin credentials"},
+	{Name: "path", Type: params.String,
+		Description: "Write the full kubeconfig to this new file with mode 0600 and only return its metadata (optional)"},
+}
+
+// createGetKubeconfigHandler creates a handler for retrieving cluster kubeconfig
+func createGetKubeconfigHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := getKubeconfigParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+		output := args.String("output")
+
+		var path string
+		if args.String("path") != "" {
+			if path, err = serverCtx.kubeconfigPath(args.String("path")); err != nil {
+				return toolError(err)
+			}
+		}
+
+		kubeconfig, err := serverCtx.client(ctx).GetKubeconfig(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get kubeconfig: %w", err))
+		}
+		info, err := capi.ParseKubeconfig(kubeconfig)
+		if err != nil {
+			return toolError(err)
+		}
+		data := map[string]any{"cluster": clusterRef(namespace, name), "metadata": info}
+
+		var content strings.Builder
+		switch {
+		case path != "":
+			if err := writeNewFile(path, []byte(kubeconfig)); err != nil {
+				return toolError(fmt.Errorf("failed to write kubeconfig: %w", err))
+			}
+			data["path"] = path
+			content.WriteString(fmt.Sprintf("Wrote the kubeconfig for cluster %s/%s to %s\n\n", namespace, name, path))
+			writeKubeconfigInfo(&content, info)
+			content.WriteString(fmt.Sprintf("\nUse it with kubectl: kubectl --kubeconfig=%s get nodes\n", path))
+		case output == kubeconfigMetadata:
+			content.WriteString(fmt.Sprintf("Kubeconfig for cluster %s/%s:\n\n", namespace, name))
+			writeKubeconfigInfo(&content, info)
+		default:
+			if output == kubeconfigRedacted {
+				if kubeconfig, err = capi.RedactKubeconfig(kubeconfig); err != nil {
+					return toolError(err)
+				}
+			}
+			data["kubeconfig"] = kubeconfig
+			content.WriteString(fmt.Sprintf("Kubeconfig for cluster %s/%s:\n\n", namespace, name))
+			content.WriteString("```yaml\n")
+			content.WriteString(kubeconfig)
+			content.WriteString("\n```\n\n")
+			if output == kubeconfigRedacted {
+				content.WriteString("Client keys and tokens are masked. Call again with path to write a usable kubeconfig to a file, or with output 'full' to return the credentials.\n")
+				break
+			}
+			content.WriteString("To use this kubeconfig:\n")
+			content.WriteString("1. Save the content between the ``` markers to a file (e.g., cluster-kubeconfig.yaml)\n")
+			content.WriteString("2. Use it with kubectl: kubectl --kubeconfig=cluster-kubeconfig.yaml get nodes\n")
+		}
+
+		return newToolResult(content.String(), data)
+	}
+}
+
+// writeKubeconfigInfo describes the servers, users and contexts of a
+// kubeconfig
+func writeKubeconfigInfo(content *strings.Builder, info *capi.KubeconfigInfo) {
+	if info.CurrentContext != "" {
+		content.WriteString(fmt.Sprintf("Current Context: %s\n", info.CurrentContext))
+	}
+	for _, cluster := range info.Clusters {
+		content.WriteString(fmt.Sprintf("Cluster %s: %s\n", cluster.Name, cluster.Server))
+		if cluster.InsecureSkipTLSVerify {
+			content.WriteString("  ⚠️  TLS verification is disabled\n")
+		}
+		if cluster.CAExpires != nil {
+			content.WriteString(fmt.Sprintf("  CA Expires: %s\n", cluster.CAExpires.Format(time.RFC3339)))
+		}
+	}
+	for _, user := range info.Users {
+		content.WriteString(fmt.Sprintf("User %s: %s\n", user.Name, user.AuthMethod))
+		if user.Subject != "" {
+			content.WriteString(fmt.Sprintf("  Subject: %s\n", user.Subject))
+		}
+		if user.CertificateExpires != nil {
+			content.WriteString(fmt.Sprintf("  Certificate Expires: %s\n", user.CertificateExpires.Format(time.RFC3339)))
+		}
+	}
+	for _, kubeContext := range info.Contexts {
+		content.WriteString(fmt.Sprintf("Context %s: cluster %s, user %s\n", kubeContext.Name, kubeContext.Cluster, kubeContext.User))
+	}
+}
+
+// kubernetesVersion returns the requested Kubernetes version, or the default
+// version of the server when none was requested
+func (s *ServerContext) kubernetesVersion(requested string) string {
+	if requested == "" {
+		return s.DefaultKubernetesVersion
+	}
+	return requested
+}
+
+// kubeconfigPath resolves where capi_get_kubeconfig writes a kubeconfig,
+// which must be inside KubeconfigDir when it is set. Clients of network
+// transports may only use files inside KubeconfigDir.
+func (s *ServerContext) kubeconfigPath(path string) (string, error) {
+	if s.KubeconfigDir == "" {
+		if s.Remote {
+			return "", fmt.Errorf("%w: files on the server can only be used when MCP_KUBECONFIG_DIR is set", capi.ErrInvalidArgument)
+		}
+		return filepath.Abs(path)
+	}
+	dir, err := filepath.Abs(s.KubeconfigDir)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path = filepath.Clean(path)
+	if rel, err := filepath.Rel(dir, path); err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: path %s is outside the kubeconfig directory %s", capi.ErrInvalidArgument, path, dir)
+	}
+	return path, nil
+}
+
+// writeNewFile writes data to a file with mode 0600, which must not exist yet
+func writeNewFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// createPauseClusterHandler creates a handler for pausing cluster reconciliation
+func createPauseClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		err = serverCtx.client(ctx).PauseCluster(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to pause cluster: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("✅ Cluster %s/%s has been paused\n\n", namespace, name))
+		content.WriteString("The cluster reconciliation has been stopped. This means:\n")
+		content.WriteString("- CAPI controllers will not make any changes to the cluster\n")
+		content.WriteString("- The cluster will not be updated or scaled automatically\n")
+		content.WriteString("- Manual operations can be performed safely\n\n")
+		content.WriteString("To resume normal operations, use the capi_resume_cluster tool.")
+
+		return newToolResult(content.String(), operationResult{Operation: "pause", Resource: clusterRef(namespace, name)})
+	}
+}
+
+// hibernateClusterParams declares the arguments of capi_hibernate_cluster
+var hibernateClusterParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "control_plane", Type: params.Bool, Default: false,
+		Description: "Also scale the control plane to zero, when its kind supports it; KubeadmControlPlanes keep running (default: false)"},
+	{Name: "timeout_seconds", Type: params.Int, Default: 900, NonNegative: true,
+		Description: "Maximum time to wait for the machines to be deleted before pausing the cluster, in seconds (default: 900)"},
+	asyncParam,
+}
+
+// createHibernateClusterHandler creates a handler for hibernating clusters
+func createHibernateClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := hibernateClusterParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+		opts := capi.HibernateOptions{
+			ControlPlane: args.Bool("control_plane"),
+			Wait:         capi.WaitOptions{Timeout: time.Duration(args.Int("timeout_seconds")) * time.Second},
+		}
+		client := serverCtx.client(ctx)
+
+		if args.Bool(asyncArgument) {
+			job, err := serverCtx.startJob(ctx, "hibernate", namespace, namespace+"/"+name, func(ctx context.Context, logf func(format string, args ...any)) (any, error) {
+				opts.Wait.OnProgress = func(p capi.WaitProgress) {
+					logf("cluster %s/%s: %s", namespace, name, p.Message)
+				}
+				return client.HibernateCluster(ctx, namespace, name, opts)
+			})
+			if err != nil {
+				return toolError(err)
+			}
+			message := fmt.Sprintf("Hibernating cluster %s/%s in job %s. Check it with capi_job_status or capi_job_logs.", namespace, name, job.ID)
+			return newToolResult(message, map[string]any{"jobId": job.ID})
+		}
+
+		progress := 0
+		notify := progressNotifier(ctx, request)
+		opts.Wait.OnProgress = func(p capi.WaitProgress) {
+			progress++
+			notify(progress, fmt.Sprintf("cluster %s/%s: %s", namespace, name, p.Message))
+		}
+		hibernation, err := client.HibernateCluster(ctx, namespace, name, opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to hibernate cluster: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("💤 Cluster %s/%s is hibernated\n\n", namespace, name))
+		content.WriteString("Scaled to zero:\n")
+		for _, obj := range hibernation.Objects {
+			content.WriteString(fmt.Sprintf("  • %s %s (was %d replicas)\n", obj.Kind, obj.Name, obj.Replicas))
+		}
+		if hibernation.ControlPlaneKept != "" {
+			content.WriteString(fmt.Sprintf("\nThe control plane keeps running: %s\n", hibernation.ControlPlaneKept))
+		}
+		content.WriteString("\nReconciliation is paused. Wake the cluster up with capi_wake_cluster.\n")
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "hibernate",
+			Resource:  clusterRef(namespace, name),
+			Details:   map[string]any{"hibernation": hibernation},
+		})
+	}
+}
+
+// wakeClusterParams declares the arguments of capi_wake_cluster
+var wakeClusterParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+}
+
+// createWakeClusterHandler creates a handler for waking up hibernated clusters
+func createWakeClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := wakeClusterParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+
+		hibernation, err := serverCtx.client(ctx).WakeCluster(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to wake up cluster: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("☀️  Cluster %s/%s, hibernated since %s, is waking up\n\n", namespace, name, hibernation.Since))
+		content.WriteString("Scaled back to:\n")
+		for _, obj := range hibernation.Objects {
+			content.WriteString(fmt.Sprintf("  • %s %s: %d replicas\n", obj.Kind, obj.Name, obj.Replicas))
+		}
+		content.WriteString("\nReconciliation is resumed. Follow the new machines with capi_wait_for_ready or capi_cluster_status.\n")
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "wake",
+			Resource:  clusterRef(namespace, name),
+			Details:   map[string]any{"hibernation": hibernation},
+		})
+	}
+}
+
+// createResumeClusterHandler creates a handler for resuming cluster reconciliation
+func createResumeClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		err = serverCtx.client(ctx).ResumeCluster(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to resume cluster: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("✅ Cluster %s/%s has been resumed\n\n", namespace, name))
+		content.WriteString("The cluster reconciliation has been restarted. This means:\n")
+		content.WriteString("- CAPI controllers will now reconcile the cluster normally\n")
+		content.WriteString("- Any pending updates or changes will be applied\n")
+		content.WriteString("- Automatic scaling and updates are re-enabled\n\n")
+		content.WriteString("The cluster is now under normal CAPI management.")
+
+		return newToolResult(content.String(), operationResult{Operation: "resume", Resource: clusterRef(namespace, name)})
+	}
+}
+
+// createDeleteClusterHandler creates a handler for deleting a cluster
+func createDeleteClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+		force, _ := arguments["force"].(bool)
+
+		// Get cluster status first to show what will be deleted
+		status, err := serverCtx.client(ctx).GetClusterStatus(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get cluster status: %w", err))
+		}
+
+		var content strings.Builder
+
+		// Show cluster information
+		content.WriteString("⚠️  WARNING: You are about to delete the following cluster:\n\n")
+		content.WriteString(capi.FormatClusterInfo(status))
+		content.WriteString("\n")
+
+		// Safety checks if not forced
+		if !force {
+			if status.Ready {
+				content.WriteString("❌ SAFETY CHECK FAILED: Cluster is currently in Ready state.\n")
+				content.WriteString("   This cluster appears to be healthy and operational.\n")
+				content.WriteString("   Use force=true to override this safety check.\n\n")
+				content.WriteString("   Recommended actions before deletion:\n")
+				content.WriteString("   1. Backup any important data\n")
+				content.WriteString("   2. Migrate workloads to another cluster\n")
+				content.WriteString("   3. Ensure this is the correct cluster\n")
+
+				return newToolResult(content.String(), operationResult{
+					Operation: "delete",
+					Resource:  clusterRef(namespace, name),
+					Details:   map[string]any{"deleted": false, "reason": "cluster is Ready; use force=true to delete it"},
+				})
+			}
+		}
+
+		// Proceed with deletion
+		err = serverCtx.client(ctx).DeleteCluster(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to delete cluster: %w", err))
+		}
+
+		content.WriteString(fmt.Sprintf("\n✅ Cluster %s/%s deletion initiated successfully.\n\n", namespace, name))
+		content.WriteString("Note: The actual deletion process may take several minutes as:\n")
+		content.WriteString("- All cluster resources are being cleaned up\n")
+		content.WriteString("- Infrastructure resources are being deprovisioned\n")
+		content.WriteString("- Finalizers are being processed\n\n")
+		content.WriteString("You can monitor the deletion progress by listing clusters in this namespace.")
+
+		return newToolResult(content.String(), operationResult{Operation: "delete", Resource: clusterRef(namespace, name), Details: map[string]any{"deleted": true}})
+	}
+}
+
+// upgradeClusterParams declares the arguments of capi_upgrade_cluster
+var upgradeClusterParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "target_version", Type: params.String, Required: true, Description: "Target Kubernetes version (e.g., v1.29.0)", Validate: params.Semver},
+	{Name: "upgrade_workers", Type: params.Bool, Default: true, Description: "Also upgrade worker nodes (default: true)"},
+	{Name: "force", Type: params.Bool, Default: false,
+		Description: "Upgrade even if the target version is a downgrade, skips a minor version or leaves workers outside the version skew policy (default: false)"},
+	asyncParam,
+}
+
+// createUpgradeClusterHandler creates a handler for upgrading cluster Kubernetes version
+func createUpgradeClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := upgradeClusterParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+		targetVersion := args.String("target_version")
+		upgradeWorkers := args.Bool("upgrade_workers")
+
+		// Get current cluster status
+		status, err := serverCtx.client(ctx).GetClusterStatus(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get cluster status: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("🚀 Initiating cluster upgrade for %s/%s\n\n", namespace, name))
+		content.WriteString("Current State:\n")
+		content.WriteString(fmt.Sprintf("  • Current Version: %s\n", status.Version))
+		content.WriteString(fmt.Sprintf("  • Target Version: %s\n", targetVersion))
+		content.WriteString(fmt.Sprintf("  • Upgrade Workers: %v\n\n", upgradeWorkers))
+
+		// Perform the upgrade
+		opts := capi.UpgradeClusterOptions{
+			Namespace:      namespace,
+			Name:           name,
+			TargetVersion:  targetVersion,
+			UpgradeWorkers: upgradeWorkers,
+			Force:          args.Bool("force"),
+		}
+
+		if err := serverCtx.client(ctx).UpgradeCluster(ctx, opts); err != nil {
+			return toolError(fmt.Errorf("failed to upgrade cluster: %w", err))
+		}
+
+		content.WriteString("✅ Upgrade initiated successfully!\n\n")
+
+		details := map[string]any{
+			"currentVersion": status.Version,
+			"targetVersion":  targetVersion,
+			"upgradeWorkers": upgradeWorkers,
+		}
+		if args.Bool(asyncArgument) {
+			follow := followUpgrade(serverCtx.client(ctx), namespace, name, targetVersion, upgradeWorkers)
+			job, err := serverCtx.startJob(ctx, "upgrade", namespace, namespace+"/"+name, follow)
+			if err != nil {
+				return toolError(fmt.Errorf("upgrade initiated but its progress is not followed: %w", err))
+			}
+			details["jobId"] = job.ID
+
+			content.WriteString(fmt.Sprintf("Job %s follows the rollout until all machines run %s.\n", job.ID, targetVersion))
+			content.WriteString("• Check its progress with: capi_job_status or capi_job_logs\n")
+			content.WriteString("• Stop following it with: capi_job_cancel (the upgrade itself continues)\n")
+
+			return newToolResult(content.String(), operationResult{
+				Operation: "upgrade",
+				Resource:  clusterRef(namespace, name),
+				Details:   details,
+			})
+		}
+
+		content.WriteString("Upgrade Process:\n")
+		content.WriteString("1. Control plane nodes will be upgraded first (one by one)\n")
+		if upgradeWorkers {
+			content.WriteString("2. Worker nodes will be upgraded after control plane is ready\n")
+		} else {
+			content.WriteString("2. Worker nodes will NOT be upgraded (upgrade_workers=false)\n")
+		}
+		content.WriteString("\n⚠️  Important Notes:\n")
+		content.WriteString("• The upgrade process can take 30-60 minutes depending on cluster size\n")
+		content.WriteString("• Control plane will remain available during rolling upgrade\n")
+		content.WriteString("• Workloads may be rescheduled during worker node upgrades\n")
+		content.WriteString("• Monitor progress with: capi_cluster_status\n")
+		content.WriteString("\n📋 Recommended Actions:\n")
+		content.WriteString("1. Monitor cluster health: capi_cluster_health\n")
+		content.WriteString("2. Watch control plane: capi_list_machines\n")
+		content.WriteString("3. Check events for any issues\n")
+		content.WriteString("4. Verify workloads after upgrade completes\n")
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "upgrade",
+			Resource:  clusterRef(namespace, name),
+			Details:   details,
+		})
+	}
+}
+
+// availableVersionsParams declares the arguments of capi_available_versions
+var availableVersionsParams = params.Schema{
+	{Name: "namespace", Type: params.String, Description: "Namespace of the cluster, machine templates and clusters in use (optional, empty for all; required with cluster)"},
+	{Name: "cluster", Type: params.String, Description: "Cluster whose provider is used and whose upgrade targets are marked (optional)"},
+	{Name: "provider", Type: params.String, Enum: capi.ProviderNames(),
+		Description: "Infrastructure provider (optional, default: the provider of the cluster, or all)"},
+}
+
+// createAvailableVersionsHandler creates a handler for listing available versions
+func createAvailableVersionsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := availableVersionsParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		query := capi.VersionQuery{
+			Provider:  capi.Provider(args.String("provider")),
+			Namespace: args.String("namespace"),
+			Cluster:   args.String("cluster"),
+		}
+		if query.Cluster != "" && query.Namespace == "" {
+			return invalidArgument("namespace is required with cluster")
+		}
+
+		catalog, err := serverCtx.client(ctx).AvailableVersions(ctx, query)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to look up available versions: %w", err))
+		}
+
+		return newToolResult(formatVersionCatalog(catalog), catalog)
+	}
+}
+
+// formatVersionCatalog renders a version catalog for display
+func formatVersionCatalog(catalog *capi.VersionCatalog) string {
+	var content strings.Builder
+	content.WriteString("Available Kubernetes versions")
+	if catalog.Provider != "" {
+		content.WriteString(fmt.Sprintf(" for %s", catalog.Provider))
+	}
+	if catalog.Cluster != "" {
+		content.WriteString(fmt.Sprintf(" (cluster %s runs %s)", catalog.Cluster, summaryValue(catalog.CurrentVersion)))
+	}
+	content.WriteString(fmt.Sprintf(": %d\n\n", len(catalog.Versions)))
+
+	for _, available := range catalog.Versions {
+		content.WriteString(fmt.Sprintf("%s  sources=%s", available.Version, formatSources(available.Sources)))
+		if available.Deprecated {
+			content.WriteString(" deprecated")
+		}
+		if available.UpgradeTarget != nil && *available.UpgradeTarget {
+			content.WriteString(" upgrade-target")
+		}
+		content.WriteString("\n")
+		for _, detail := range available.Details {
+			content.WriteString(fmt.Sprintf("  • %s\n", detail))
+		}
+	}
+	if len(catalog.Versions) == 0 {
+		content.WriteString("No versions found\n")
+	}
+	if len(catalog.Skipped) > 0 {
+		content.WriteString(fmt.Sprintf("\n⚠️  Not permitted to read: %s\n", strings.Join(catalog.Skipped, ", ")))
+	}
+	if def := catalog.Default(); def != "" {
+		content.WriteString(fmt.Sprintf("\nDefault for new clusters: %s\n", def))
+	}
+	return content.String()
+}
+
+// formatSources joins version sources with commas
+func formatSources(sources []capi.VersionSource) string {
+	names := make([]string, len(sources))
+	for i, source := range sources {
+		names[i] = string(source)
+	}
+	return strings.Join(names, ",")
+}
+
+// upgradePlanParams declares the arguments of capi_upgrade_plan
+var upgradePlanParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "target_version", Type: params.String, Required: true, Description: "Target Kubernetes version (e.g., v1.29.0)", Validate: params.Semver},
+	{Name: "upgrade_workers", Type: params.Bool, Default: true, Description: "Also plan the upgrade of worker nodes (default: true)"},
+}
+
+// createUpgradePlanHandler creates a handler for previewing a cluster upgrade
+func createUpgradePlanHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := upgradePlanParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+
+		plan, err := serverCtx.client(ctx).PlanUpgrade(ctx, args.String("namespace"), args.String("name"), args.String("target_version"), args.Bool("upgrade_workers"))
+		if err != nil {
+			return toolError(fmt.Errorf("failed to plan the upgrade: %w", err))
+		}
+
+		return newToolResult(formatUpgradePlan(plan), plan)
+	}
+}
+
+// formatUpgradePlan renders an upgrade plan for display
+func formatUpgradePlan(plan *capi.UpgradePlan) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Upgrade plan for %s/%s to %s\n\n", plan.Namespace, plan.Cluster, plan.TargetVersion))
+
+	objects := plan.MachineDeployments
+	if plan.ControlPlane != nil {
+		objects = append([]capi.PlannedObject{*plan.ControlPlane}, objects...)
+	}
+	for _, object := range objects {
+		change := "unchanged"
+		if object.Modified {
+			change = fmt.Sprintf("%s -> %s", summaryValue(object.CurrentVersion), plan.TargetVersion)
+		}
+		content.WriteString(fmt.Sprintf("%s %s  version=%s replicas=%d replacements=%d",
+			object.Kind, object.Name, change, object.Replicas, object.Replacements))
+		if object.Note != "" {
+			content.WriteString(fmt.Sprintf(" (%s)", object.Note))
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString(fmt.Sprintf("\nEstimated machine replacements: %d\n", plan.Replacements))
+
+	if !plan.Blocked() {
+		content.WriteString("\n✅ No blockers found, start the upgrade with: capi_upgrade_cluster\n")
+		return content.String()
+	}
+	content.WriteString(fmt.Sprintf("\n🛑 Blockers (%d):\n", len(plan.Blockers)))
+	for _, blocker := range plan.Blockers {
+		content.WriteString(fmt.Sprintf("  • %s\n", blocker))
+	}
+	return content.String()
+}
+
+const (
+	// upgradePollInterval is how often an upgrade job checks the rollout
+	upgradePollInterval = 30 * time.Second
+	// upgradeJobTimeout bounds how long an upgrade job follows the rollout
+	upgradeJobTimeout = 3 * time.Hour
+)
+
+// followUpgrade returns a job that waits until the machines of a cluster run
+// the target version, logging each step of the rollout. Errors reading the
+// cluster are logged and retried, since API hiccups are common while control
+// plane machines are replaced.
+func followUpgrade(c capi.CAPIClient, namespace, name, targetVersion string, upgradeWorkers bool) jobs.Func {
+	return func(ctx context.Context, logf func(format string, args ...any)) (any, error) {
+		ctx, cancel := context.WithTimeout(ctx, upgradeJobTimeout)
+		defer cancel()
+
+		logf("Following the upgrade of %s/%s to %s", namespace, name, targetVersion)
+		ticker := time.NewTicker(upgradePollInterval)
+		defer ticker.Stop()
+
+		var last capi.UpgradeProgress
+		for {
+			progress, err := c.GetUpgradeProgress(ctx, namespace, name, targetVersion)
+			switch {
+			case err != nil && ctx.Err() == nil:
+				logf("Failed to check the upgrade progress, retrying: %v", err)
+			case err == nil:
+				if *progress != last {
+					logf("Control plane machines upgraded: %d/%d, worker machines upgraded: %d/%d, cluster ready: %v",
+						progress.ControlPlaneUpgraded, progress.ControlPlaneMachines,
+						progress.WorkersUpgraded, progress.WorkerMachines, progress.ClusterReady)
+					last = *progress
+				}
+				if progress.Done(upgradeWorkers) {
+					logf("Upgrade to %s complete", targetVersion)
+					return progress, nil
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return nil, fmt.Errorf("upgrade did not complete within %s", upgradeJobTimeout)
+				}
+				return nil, ctx.Err()
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+// createUpdateClusterHandler creates a handler for updating cluster metadata
+func createUpdateClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		// Get labels and annotations from arguments
+		labels, _ := arguments["labels"].(map[string]interface{})
+		annotations, _ := arguments["annotations"].(map[string]interface{})
+
+		// Convert interface{} maps to string maps
+		labelMap := make(map[string]string)
+		for k, v := range labels {
+			if strVal, ok := v.(string); ok {
+				labelMap[k] = strVal
+			}
+		}
+
+		annotationMap := make(map[string]string)
+		for k, v := range annotations {
+			if strVal, ok := v.(string); ok {
+				annotationMap[k] = strVal
+			}
+		}
+
+		// Update the cluster
+		opts := capi.UpdateClusterOptions{
+			Namespace:   namespace,
+			Name:        name,
+			Labels:      labelMap,
+			Annotations: annotationMap,
+		}
+
+		cluster, err := serverCtx.client(ctx).UpdateCluster(ctx, opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to update cluster: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("✅ Cluster %s/%s updated successfully!\n\n", namespace, name))
+
+		// Show what was updated
+		if len(labelMap) > 0 {
+			content.WriteString("Labels updated:\n")
+			for _, k := range slices.Sorted(maps.Keys(labelMap)) {
+				if v := labelMap[k]; v == "" {
+					content.WriteString(fmt.Sprintf("  ✗ Removed: %s\n", k))
+				} else {
+					content.WriteString(fmt.Sprintf("  ✓ Set: %s=%s\n", k, v))
+				}
+			}
+			content.WriteString("\n")
+		}
+
+		if len(annotationMap) > 0 {
+			content.WriteString("Annotations updated:\n")
+			for _, k := range slices.Sorted(maps.Keys(annotationMap)) {
+				if v := annotationMap[k]; v == "" {
+					content.WriteString(fmt.Sprintf("  ✗ Removed: %s\n", k))
+				} else {
+					content.WriteString(fmt.Sprintf("  ✓ Set: %s=%s\n", k, v))
+				}
+			}
+			content.WriteString("\n")
+		}
+
+		// Show current metadata
+		content.WriteString("Current metadata:\n")
+		content.WriteString("Labels:\n")
+		if len(cluster.Labels) > 0 {
+			for _, k := range slices.Sorted(maps.Keys(cluster.Labels)) {
+				content.WriteString(fmt.Sprintf("  %s: %s\n", k, cluster.Labels[k]))
+			}
+		} else {
+			content.WriteString("  (none)\n")
+		}
+
+		content.WriteString("\nAnnotations:\n")
+		if len(cluster.Annotations) > 0 {
+			for _, k := range slices.Sorted(maps.Keys(cluster.Annotations)) {
+				content.WriteString(fmt.Sprintf("  %s: %s\n", k, cluster.Annotations[k]))
+			}
+		} else {
+			content.WriteString("  (none)\n")
+		}
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "update",
+			Resource:  clusterRef(namespace, name),
+			Details:   map[string]any{"labels": cluster.Labels, "annotations": cluster.Annotations},
+		})
+	}
+}
+
+// createMoveClusterHandler creates a handler for moving clusters between management clusters
+func createMoveClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		targetKubeconfig, _ := arguments["target_kubeconfig"].(string)
+		targetNamespace, _ := arguments["target_namespace"].(string)
+		dryRun, _ := arguments["dry_run"].(bool)
+
+		// Prepare move options
+		opts := capi.MoveClusterOptions{
+			Namespace:        namespace,
+			Name:             name,
+			TargetKubeconfig: targetKubeconfig,
+			TargetNamespace:  targetNamespace,
+			DryRun:           dryRun,
+		}
+
+		// Get move instructions/manifest
+		manifest, err := serverCtx.client(ctx).MoveCluster(ctx, opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to prepare cluster move: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("🚀 Cluster Move Preparation for %s/%s\n\n", namespace, name))
+
+		if dryRun {
+			content.WriteString("⚠️  DRY RUN MODE - No actual changes will be made\n\n")
+		}
+
+		content.WriteString("📋 Move Instructions:\n")
+		content.WriteString("1. Ensure target management cluster is ready\n")
+		content.WriteString("2. Install required providers on target cluster\n")
+		content.WriteString("3. Create target namespace if needed\n")
+		content.WriteString("4. Use clusterctl to perform the move:\n\n")
+
+		content.WriteString("```bash\n")
+		content.WriteString("# Pause the cluster first\n")
+		content.WriteString(fmt.Sprintf("kubectl patch cluster %s -n %s --type merge -p '{\"spec\":{\"paused\":true}}'\n\n", name, namespace))
+
+		content.WriteString("# Move the cluster\n")
+		if targetKubeconfig != "" {
+			content.WriteString(fmt.Sprintf("clusterctl move --to-kubeconfig=%s", targetKubeconfig))
+		} else {
+			content.WriteString("clusterctl move --to-kubeconfig=<target-kubeconfig>")
+		}
+		if targetNamespace != "" && targetNamespace != namespace {
+			content.WriteString(fmt.Sprintf(" --namespace %s --to-namespace %s", namespace, targetNamespace))
+		} else {
+			content.WriteString(fmt.Sprintf(" --namespace %s", namespace))
+		}
+		content.WriteString("\n")
+		content.WriteString("```\n\n")
+
+		content.WriteString("⚠️  Important Notes:\n")
+		content.WriteString("• The source cluster will be paused during move\n")
+		content.WriteString("• All cluster resources will be migrated\n")
+		content.WriteString("• Ensure network connectivity between clusters\n")
+		content.WriteString("• Verify provider versions match\n\n")
+
+		content.WriteString("📝 Move Manifest Preview:\n")
+		content.WriteString("```yaml\n")
+		content.WriteString(manifest)
+		content.WriteString("\n```\n")
+
+		return newToolResult(content.String(), map[string]any{
+			"cluster":          clusterRef(namespace, name),
+			"dryRun":           dryRun,
+			"targetKubeconfig": targetKubeconfig,
+			"targetNamespace":  targetNamespace,
+			"manifest":         manifest,
+		})
+	}
+}
+
+// backupClusterParams declares the arguments of capi_backup_cluster
+var backupClusterParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "include_secrets", Type: params.Bool, Description: "Include secrets in backup (kubeconfig, certificates)"},
+	{Name: "output_format", Type: params.String, Default: "yaml", Enum: []string{"yaml", "json"}, Description: "Output format: yaml or json (default: yaml)"},
+}
+
+// createBackupClusterHandler creates a handler for backing up cluster configurations
+func createBackupClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := backupClusterParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace, name := args.String("namespace"), args.String("name")
+		includeSecrets, outputFormat := args.Bool("include_secrets"), args.String("output_format")
+
+		// Create backup
+		opts := capi.BackupClusterOptions{
+			Namespace:      namespace,
+			Name:           name,
+			IncludeSecrets: includeSecrets,
+			OutputFormat:   outputFormat,
+		}
+
+		bundle, err := serverCtx.client(ctx).BackupCluster(ctx, opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to create cluster backup: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("📦 Cluster Backup for %s/%s\n\n", namespace, name))
+
+		content.WriteString("Backup Configuration:\n")
+		content.WriteString(fmt.Sprintf("  • Format: %s\n", outputFormat))
+		content.WriteString(fmt.Sprintf("  • Include Secrets: %v\n\n", includeSecrets))
+
+		content.WriteString("📋 Backup Instructions:\n")
+		content.WriteString("1. Save the backup content below to a file\n")
+		content.WriteString("2. Store in a secure location (git, S3, etc.)\n")
+		content.WriteString("3. Test restore procedure in a non-production environment\n\n")
+
+		content.WriteString("🔧 Recommended Backup Tools:\n")
+		content.WriteString("• Velero - Complete cluster backup solution\n")
+		content.WriteString("  velero backup create <backup-name> --include-namespaces=<namespace>\n")
+		content.WriteString("• etcd snapshot - For control plane state\n")
+		content.WriteString("• Git repositories - For GitOps managed clusters\n\n")
+
+		content.WriteString("⚠️  Important Notes:\n")
+		content.WriteString("• This backup includes CAPI resources only\n")
+		content.WriteString("• Workload data is NOT included\n")
+		content.WriteString("• Infrastructure provider resources may need separate backup\n")
+		if includeSecrets {
+			content.WriteString("• ⚠️  Secrets are included - handle with care!\n")
+		}
+		content.WriteString("\n")
+
+		content.WriteString("📄 Backup Content:\n")
+		content.WriteString("```" + outputFormat + "\n")
+		content.WriteString(bundle)
+		content.WriteString("\n```\n\n")
+
+		content.WriteString("💾 To save this backup:\n")
+		content.WriteString(fmt.Sprintf("1. Copy the content between the ``` markers\n"))
+		content.WriteString(fmt.Sprintf("2. Save to a file: cluster-%s-%s-backup.%s\n", namespace, name, outputFormat))
+		content.WriteString("3. Encrypt if it contains secrets\n")
+
+		return newToolResult(content.String(), map[string]any{
+			"cluster":        clusterRef(namespace, name),
+			"format":         outputFormat,
+			"includeSecrets": includeSecrets,
+			"backup":         bundle,
+		})
+	}
+}
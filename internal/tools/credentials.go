@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/internal/toolpolicy"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerCredentialTools adds the tools issuing workload cluster credentials
+func registerCredentialTools(s Registry, serverCtx *ServerContext) {
+	issueKubeconfigTool := issueKubeconfigParams.NewTool(
+		"capi_issue_kubeconfig",
+		"Issue a kubeconfig with short-lived credentials for a workload cluster instead of its permanent admin kubeconfig: a client certificate signed with the cluster CA, or a token of a ServiceAccount of the workload cluster",
+	)
+	addTool(s, issueKubeconfigTool, createIssueKubeconfigHandler(serverCtx))
+
+	rotateKubeconfigTool := rotateKubeconfigParams.NewTool(
+		"capi_rotate_kubeconfig",
+		"Regenerate the admin kubeconfig Secret of a workload cluster with a new client certificate signed by the cluster CA, after rotating the CA or when the kubeconfig may have leaked",
+	)
+	addTool(s, rotateKubeconfigTool, createRotateKubeconfigHandler(serverCtx))
+}
+
+// issueKubeconfigParams declares the arguments of capi_issue_kubeconfig
+var issueKubeconfigParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "method", Type: params.String, Default: string(capi.CredentialCertificate),
+		Enum:        []string{string(capi.CredentialCertificate), string(capi.CredentialToken)},
+		Description: "'certificate' signs a client certificate with the cluster CA, 'token' requests a token for service_account"},
+	{Name: "ttl_minutes", Type: params.Int, Default: int(capi.DefaultCredentialsTTL.Minutes()), NonNegative: true,
+		Description: fmt.Sprintf("How long the credentials are valid in minutes (default: %d, at most %d; tokens at least 10)", int(capi.DefaultCredentialsTTL.Minutes()), int(capi.MaxCredentialsTTL.Minutes()))},
+	{Name: "user", Type: params.String,
+		Description: "Common name of the client certificate (default: the caller)"},
+	{Name: "groups", Type: params.String,
+		Description: "Comma-separated groups of the client certificate (default: system:masters)"},
+	{Name: "service_account", Type: params.String,
+		Description: "ServiceAccount of the workload cluster to request a token for, as namespace/name (required for 'token')"},
+	{Name: "path", Type: params.String,
+		Description: "Write the kubeconfig to this new file with mode 0600 instead of returning it (optional)"},
+}
+
+// createIssueKubeconfigHandler creates a handler issuing short-lived
+// workload cluster kubeconfigs
+func createIssueKubeconfigHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := issueKubeconfigParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+
+		var path string
+		if args.String("path") != "" {
+			if path, err = serverCtx.kubeconfigPath(args.String("path")); err != nil {
+				return toolError(err)
+			}
+		}
+
+		user := args.String("user")
+		if user == "" {
+			user = requesterFromContext(ctx)
+		}
+		credentials, err := serverCtx.client(ctx).IssueWorkloadCredentials(ctx, namespace, name, capi.CredentialsOptions{
+			Method:         capi.CredentialMethod(args.String("method")),
+			TTL:            time.Duration(args.Int("ttl_minutes")) * time.Minute,
+			User:           user,
+			Groups:         toolpolicy.ParseList(args.String("groups")),
+			ServiceAccount: args.String("service_account"),
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to issue kubeconfig: %w", err))
+		}
+
+		data := map[string]any{"cluster": clusterRef(namespace, name), "credentials": credentials}
+		var content strings.Builder
+		if path != "" {
+			if err := writeNewFile(path, []byte(credentials.Kubeconfig)); err != nil {
+				return toolError(fmt.Errorf("failed to write kubeconfig: %w", err))
+			}
+			data["path"] = path
+			content.WriteString(fmt.Sprintf("Wrote a kubeconfig for cluster %s/%s to %s\n\n", namespace, name, path))
+		} else {
+			data["kubeconfig"] = credentials.Kubeconfig
+			content.WriteString(fmt.Sprintf("Kubeconfig for cluster %s/%s:\n\n", namespace, name))
+		}
+		content.WriteString(fmt.Sprintf("Server: %s\n", credentials.Server))
+		content.WriteString(fmt.Sprintf("Method: %s\n", credentials.Method))
+		content.WriteString(fmt.Sprintf("Subject: %s\n", credentials.Subject))
+		if len(credentials.Groups) > 0 {
+			content.WriteString(fmt.Sprintf("Groups: %s\n", strings.Join(credentials.Groups, ", ")))
+		}
+		content.WriteString(fmt.Sprintf("Expires: %s (in %s)\n", credentials.Expires.Format(time.RFC3339), time.Until(credentials.Expires).Round(time.Minute)))
+		if path == "" {
+			content.WriteString("\n```yaml\n")
+			content.WriteString(credentials.Kubeconfig)
+			content.WriteString("```\n")
+		}
+
+		return newToolResult(content.String(), data)
+	}
+}
+
+// rotateKubeconfigParams declares the arguments of capi_rotate_kubeconfig
+var rotateKubeconfigParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+}
+
+// createRotateKubeconfigHandler creates a handler regenerating kubeconfig
+// Secrets
+func createRotateKubeconfigHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := rotateKubeconfigParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+
+		rotation, err := serverCtx.client(ctx).RotateKubeconfig(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to rotate kubeconfig: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Rotated the kubeconfig Secret %s of cluster %s/%s\n\n", rotation.Secret, namespace, name))
+		content.WriteString(fmt.Sprintf("Server: %s\n", rotation.Server))
+		content.WriteString(fmt.Sprintf("Subject: %s\n", rotation.Subject))
+		content.WriteString(fmt.Sprintf("Expires: %s\n", rotation.Expires.Format(time.RFC3339)))
+		if rotation.CAChanged {
+			content.WriteString("The previous kubeconfig trusted another CA; it now trusts the current cluster CA.\n")
+		}
+		if rotation.PreviousExpires != nil && time.Now().Before(*rotation.PreviousExpires) {
+			content.WriteString(fmt.Sprintf("\n⚠️  Client certificates cannot be revoked: the previous one stays valid until %s unless the cluster CA is rotated.\n", rotation.PreviousExpires.Format(time.RFC3339)))
+		}
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "rotate-kubeconfig",
+			Resource:  clusterRef(namespace, name),
+			Details:   map[string]any{"rotation": rotation},
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ProviderDiscovery tracks the infrastructure providers installed on the
+// management clusters, so tools/list only advertises the provider tools that
+// can find anything. The providers of the default and all registered
+// management clusters count, as tools may target any of them.
+type ProviderDiscovery struct {
+	clients *capi.ClientPool
+
+	mu sync.RWMutex
+	// complete is set once every management cluster was discovered; until
+	// then all provider tools are listed
+	complete  bool
+	providers []capi.Provider
+	kinds     []capi.InfrastructureKind
+	onChange  []func()
+}
+
+// NewProviderDiscovery returns a discovery of the providers of clients. Call
+// Refresh to run it.
+func NewProviderDiscovery(clients *capi.ClientPool) *ProviderDiscovery {
+	return &ProviderDiscovery{clients: clients}
+}
+
+// OnChange registers fn to be called when a refresh changes the listed tools
+func (d *ProviderDiscovery) OnChange(fn func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onChange = append(d.onChange, fn)
+}
+
+// Refresh discovers the providers of all management clusters again. When a
+// management cluster cannot be discovered, all provider tools are listed
+// until a later refresh succeeds.
+func (d *ProviderDiscovery) Refresh(ctx context.Context) error {
+	clients := []*capi.Client{d.clients.Default()}
+	var errs []error
+	for _, cluster := range d.clients.ManagementClusters() {
+		c, err := d.clients.Get(cluster.Name, "")
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		clients = append(clients, c)
+	}
+
+	var providers []capi.Provider
+	var kinds []capi.InfrastructureKind
+	for _, c := range clients {
+		discovered, err := c.DiscoverInfrastructure(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, provider := range discovered.Providers {
+			if !slices.Contains(providers, provider) {
+				providers = append(providers, provider)
+			}
+		}
+		for _, kind := range discovered.Kinds {
+			if !slices.ContainsFunc(kinds, func(k capi.InfrastructureKind) bool { return k.Kind == kind.Kind }) {
+				kinds = append(kinds, kind)
+			}
+		}
+	}
+	slices.SortFunc(kinds, func(a, b capi.InfrastructureKind) int { return strings.Compare(a.Kind, b.Kind) })
+
+	d.mu.Lock()
+	changed := d.complete != (len(errs) == 0) || !slices.Equal(d.providers, providers)
+	d.complete = len(errs) == 0
+	d.providers = providers
+	d.kinds = kinds
+	onChange := slices.Clone(d.onChange)
+	d.mu.Unlock()
+
+	if changed {
+		for _, fn := range onChange {
+			fn()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Providers returns the discovered providers and whether every management
+// cluster was discovered
+func (d *ProviderDiscovery) Providers() ([]capi.Provider, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return slices.Clone(d.providers), d.complete
+}
+
+// Kinds returns the infrastructure kinds served by any management cluster
+func (d *ProviderDiscovery) Kinds() []capi.InfrastructureKind {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return slices.Clone(d.kinds)
+}
+
+// listed reports whether a tool is listed with the discovered providers
+func (d *ProviderDiscovery) listed(name string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if !d.complete {
+		return true
+	}
+	for prefix, group := range providerGroups {
+		if strings.HasPrefix(name, prefix) {
+			return slices.Contains(d.providers, capi.Provider(group))
+		}
+	}
+	return true
+}
+
+// NewProviderToolFilter hides the tools of providers not installed on any
+// management cluster from tools/list. The tools stay callable. A nil
+// discovery lists all tools.
+func NewProviderToolFilter(discovery *ProviderDiscovery) server.ToolFilterFunc {
+	return func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		if discovery == nil {
+			return tools
+		}
+		filtered := make([]mcp.Tool, 0, len(tools))
+		for _, tool := range tools {
+			if discovery.listed(tool.Name) {
+				filtered = append(filtered, tool)
+			}
+		}
+		return filtered
+	}
+}
+
+// registerDiscoveryTools adds the tools working on any discovered
+// infrastructure kind
+func registerDiscoveryTools(s Registry, serverCtx *ServerContext) {
+	discoverProvidersTool := mcp.NewTool(
+		"capi_discover_providers",
+		mcp.WithDescription("Discover the infrastructure providers and kinds served by the management clusters again and update the listed provider tools, e.g. after installing a provider"),
+	)
+	addTool(s, discoverProvidersTool, createDiscoverProvidersHandler(serverCtx))
+
+	listObjectsTool := mcp.NewTool(
+		"capi_list_infrastructure_objects",
+		mcp.WithDescription("List the objects of any infrastructure kind the management cluster serves, e.g. HetznerCluster or ProxmoxMachine, for providers without dedicated tools"),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Infrastructure kind, see capi_discover_providers for the served kinds"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace to filter objects (optional)"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Only list the objects labeled with this cluster (optional)"),
+		),
+	)
+	addTool(s, listObjectsTool, createListInfrastructureObjectsHandler(serverCtx))
+
+	getObjectTool := mcp.NewTool(
+		"capi_get_infrastructure_object",
+		mcp.WithDescription("Get the spec and status of an object of any infrastructure kind the management cluster serves"),
+		mcp.WithString("kind",
+			mcp.Required(),
+			mcp.Description("Infrastructure kind, see capi_discover_providers for the served kinds"),
+		),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Object namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Object name"),
+		),
+	)
+	addTool(s, getObjectTool, createGetInfrastructureObjectHandler(serverCtx))
+}
+
+// createDiscoverProvidersHandler refreshes the provider discovery
+func createDiscoverProvidersHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var providers []capi.Provider
+		var kinds []capi.InfrastructureKind
+		var warning string
+		if serverCtx.Discovery != nil {
+			if err := serverCtx.Discovery.Refresh(ctx); err != nil {
+				warning = err.Error()
+			}
+			providers, _ = serverCtx.Discovery.Providers()
+			kinds = serverCtx.Discovery.Kinds()
+		} else {
+			// Without a discovery all tools are listed, report the default
+			// management cluster
+			discovered, err := serverCtx.client(ctx).DiscoverInfrastructure(ctx)
+			if err != nil {
+				return toolError(fmt.Errorf("failed to discover infrastructure providers: %w", err))
+			}
+			providers, kinds = discovered.Providers, discovered.Kinds
+		}
+
+		var content strings.Builder
+		if warning != "" {
+			content.WriteString(fmt.Sprintf("⚠️  Discovery incomplete, all provider tools stay listed: %s\n\n", warning))
+		}
+		content.WriteString(fmt.Sprintf("Installed Infrastructure Providers (%d):\n", len(providers)))
+		for _, provider := range providers {
+			info, _ := capi.LookupProvider(string(provider))
+			tools := "generic tools only (capi_list_infrastructure_objects, capi_get_infrastructure_object)"
+			if info.Capabilities.Inspect {
+				tools = fmt.Sprintf("capi_%s_* tools", provider)
+			}
+			content.WriteString(fmt.Sprintf("- %s: %s\n", info.DisplayName, tools))
+		}
+		content.WriteString(fmt.Sprintf("\nInfrastructure Kinds (%d):\n", len(kinds)))
+		for _, kind := range kinds {
+			content.WriteString(fmt.Sprintf("- %s (%s, provider %s)\n", kind.Kind, kind.APIVersion, kind.Provider))
+		}
+
+		result := map[string]any{"providers": providers, "kinds": kinds}
+		if warning != "" {
+			result["warning"] = warning
+		}
+		return newToolResult(content.String(), result)
+	}
+}
+
+// createListInfrastructureObjectsHandler lists the objects of an infrastructure kind
+func createListInfrastructureObjectsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		kind, err := params.RequiredString(arguments, "kind")
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := params.OptionalString(arguments, "namespace", "")
+		clusterName := params.OptionalString(arguments, "cluster", "")
+
+		objects, err := serverCtx.client(ctx).ListInfrastructureObjects(ctx, kind, namespace, clusterName)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list %s objects: %w", kind, err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("%s Objects (%d):\n\n", kind, len(objects)))
+		for _, object := range objects {
+			content.WriteString(fmt.Sprintf("- %s/%s: ready %v, cluster %s\n", object.Namespace, object.Name, object.Ready, summaryValue(object.Cluster)))
+			if object.FailureMessage != "" {
+				content.WriteString(fmt.Sprintf("  ❌ Failure: %s\n", object.FailureMessage))
+			}
+		}
+
+		return newToolResult(content.String(), map[string]any{"kind": kind, "objects": objects})
+	}
+}
+
+// createGetInfrastructureObjectHandler gets an object of an infrastructure kind
+func createGetInfrastructureObjectHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		kind, err := params.RequiredString(arguments, "kind")
+		if err != nil {
+			return toolError(err)
+		}
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		object, err := serverCtx.client(ctx).GetInfrastructureObject(ctx, kind, namespace, name)
+		if err != nil {
+			return toolError(err)
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("%s: %s/%s\n\n", object.Kind, namespace, name))
+		content.WriteString(fmt.Sprintf("Cluster: %s\n", summaryValue(object.Cluster)))
+		content.WriteString(fmt.Sprintf("Ready: %v\n", object.Ready))
+		if object.FailureMessage != "" {
+			content.WriteString(fmt.Sprintf("❌ Failure: %s\n", object.FailureMessage))
+		}
+		content.WriteString("\nThe spec and status are part of the structured result.\n")
+
+		return newToolResult(content.String(), map[string]any{"object": object})
+	}
+}
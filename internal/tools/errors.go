@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/giantswarm/mcp-capi/internal/maintenance"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// errorCategory describes how a class of errors is presented to the caller
+type errorCategory struct {
+	target error
+	label  string
+	hint   string
+}
+
+// errorCategories are checked in order; the first match wins
+var errorCategories = []errorCategory{
+	{capi.ErrForbidden, "Permission denied", "Use capi_check_permissions to inspect the server's RBAC permissions."},
+	{capi.ErrClusterNotFound, "Cluster not found", "Use capi_list_clusters to see the clusters in a namespace."},
+	{capi.ErrNotFound, "Not found", "Check the namespace and name, or list the resources in the namespace."},
+	{capi.ErrConflict, "Conflict", "The resource is being modified concurrently; retry the operation."},
+	{capi.ErrOperationInProgress, "Operation in progress", "Another operation is changing the cluster; retry once it has finished."},
+	{capi.ErrWorkloadUnreachable, "Workload cluster unreachable", "Check the cluster health with capi_cluster_health."},
+	{capi.ErrInvalidArgument, "Invalid argument", ""},
+	{params.ErrInvalid, "Invalid argument", ""},
+	{capi.ErrAlreadyExists, "Already exists", "Choose a different name or update the existing resource."},
+	{capi.ErrPreconditionFailed, "Precondition failed", ""},
+	{maintenance.ErrOutsideWindow, "Outside maintenance window", "Wait for the window to open, or pass maintenance_override: true to change the cluster anyway."},
+	{context.DeadlineExceeded, "Timeout", "The operation took too long; retry or check the management cluster."},
+	{context.Canceled, "Canceled", ""},
+}
+
+// toolError converts an error caused by the request or by the state of the
+// clusters into a tool result the caller can act on. Handlers only return Go
+// errors directly for faults of the server itself.
+func toolError(err error) (*mcp.CallToolResult, error) {
+	for _, category := range errorCategories {
+		if !errors.Is(err, category.target) {
+			continue
+		}
+		message := fmt.Sprintf("%s: %v", category.label, err)
+		if category.hint != "" {
+			message += "\n" + category.hint
+		}
+		return mcp.NewToolResultError(message), nil
+	}
+	return mcp.NewToolResultError(fmt.Sprintf("Error: %v", err)), nil
+}
+
+// invalidArgument reports an argument that was provided with an unusable value
+func invalidArgument(format string, args ...any) (*mcp.CallToolResult, error) {
+	return mcp.NewToolResultError("Invalid argument: " + fmt.Sprintf(format, args...)), nil
+}
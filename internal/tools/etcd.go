@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerEtcdTools adds the tools inspecting the etcd of kubeadm control
+// planes
+func registerEtcdTools(s Registry, serverCtx *ServerContext) {
+	etcdStatusTool := etcdStatusParams.NewTool(
+		"capi_etcd_status",
+		"Report the etcd members of the control plane machines of a cluster with their health, the leader, alarms and database sizes, from the conditions of the KubeadmControlPlane and etcdctl in the etcd pods of the workload cluster",
+	)
+	addTool(s, etcdStatusTool, createEtcdStatusHandler(serverCtx))
+}
+
+// etcdStatusParams declares the arguments of capi_etcd_status
+var etcdStatusParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "query", Type: params.Bool, Default: true,
+		Description: "Query the members with etcdctl in the workload cluster for the leader, alarms and database sizes; false only reports the conditions of the control plane (default: true)"},
+}
+
+// createEtcdStatusHandler creates a handler reporting the etcd of a cluster
+func createEtcdStatusHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := etcdStatusParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+
+		status, err := serverCtx.client(ctx).GetEtcdStatus(ctx, namespace, name, args.Bool("query"))
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get etcd status: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("etcd of cluster %s/%s (%s)\n\n", namespace, name, status.ControlPlane))
+		if status.External {
+			content.WriteString(status.Message + "\n")
+			return newToolResult(content.String(), status)
+		}
+		health := "✅ Healthy"
+		if !status.Healthy {
+			health = "❌ Unhealthy"
+		}
+		content.WriteString(health)
+		if status.Message != "" {
+			content.WriteString(": " + status.Message)
+		}
+		content.WriteString("\n")
+		if status.Leader != "" {
+			content.WriteString(fmt.Sprintf("Leader: %s\n", status.Leader))
+		}
+		for _, alarm := range status.Alarms {
+			content.WriteString(fmt.Sprintf("⚠️  Alarm: %s\n", alarm))
+		}
+
+		content.WriteString(fmt.Sprintf("\nMembers (%d):\n", len(status.Members)))
+		for _, member := range status.Members {
+			label := member.Machine
+			if label == "" {
+				label = member.Name
+			}
+			content.WriteString(fmt.Sprintf("- %s", label))
+			if member.Node != "" {
+				content.WriteString(fmt.Sprintf(" (node %s)", member.Node))
+			}
+			if member.Leader {
+				content.WriteString(" [leader]")
+			}
+			if member.Learner {
+				content.WriteString(" [learner]")
+			}
+			content.WriteString("\n")
+			if member.PodHealthy != "" || member.MemberHealthy != "" {
+				content.WriteString(fmt.Sprintf("  Pod healthy: %s, member healthy: %s\n", conditionText(member.PodHealthy, member.PodMessage), conditionText(member.MemberHealthy, member.MemberMessage)))
+			}
+			if member.ID != "" {
+				content.WriteString(fmt.Sprintf("  ID: %s", member.ID))
+				if member.Version != "" {
+					content.WriteString(fmt.Sprintf(", version %s", member.Version))
+				}
+				content.WriteString("\n")
+			}
+			if member.DBSize > 0 {
+				content.WriteString(fmt.Sprintf("  DB size: %s (%s in use), raft term %d, index %d\n", formatBytes(member.DBSize), formatBytes(member.DBSizeInUse), member.RaftTerm, member.RaftIndex))
+			}
+			for _, memberErr := range member.Errors {
+				content.WriteString(fmt.Sprintf("  ⚠️  %s\n", memberErr))
+			}
+		}
+		if status.QueryError != "" {
+			content.WriteString(fmt.Sprintf("\nCould not query etcd in the workload cluster: %s\n", status.QueryError))
+		}
+
+		return newToolResult(content.String(), status)
+	}
+}
+
+// conditionText formats the status and message of a condition
+func conditionText(status, message string) string {
+	if status == "" {
+		return "unknown"
+	}
+	if message == "" {
+		return status
+	}
+	return fmt.Sprintf("%s (%s)", status, message)
+}
+
+// formatBytes formats a size in bytes with a binary unit
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,288 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/fleet"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/internal/report"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fleetSummaryParams declares the arguments of capi_fleet_summary
+var fleetSummaryParams = params.Schema{
+	{Name: "namespace", Type: params.String, Description: "Namespace to summarize (optional, empty for all)"},
+	{Name: "stuck_after_minutes", Type: params.Int, Default: 30, NonNegative: true,
+		Description: "Report clusters that have not been ready for longer than this as stuck (default: 30)"},
+}
+
+// findStaleResourcesParams declares the arguments of capi_find_stale_resources
+var findStaleResourcesParams = params.Schema{
+	{Name: "namespace", Type: params.String, Description: "Namespace to scan (optional, empty for all)"},
+	{Name: "clusterName", Type: params.String, Description: "Only scan this cluster (optional)"},
+	{Name: "provisioning_minutes", Type: params.Int, Default: 30, NonNegative: true,
+		Description: "Report machines without a node this long after their creation (default: 30)"},
+	{Name: "deleting_minutes", Type: params.Int, Default: 30, NonNegative: true,
+		Description: "Report machines and clusters still deleting this long after their deletion started (default: 30)"},
+	{Name: "cluster_minutes", Type: params.Int, Default: 30, NonNegative: true,
+		Description: "Report clusters not provisioned this long after their creation (default: 30)"},
+	{Name: "rollout_minutes", Type: params.Int, Default: 60, NonNegative: true,
+		Description: "Report MachineDeployments whose replicas are not all updated and available this long after their newest MachineSet was created (default: 60)"},
+}
+
+// exportInventoryParams declares the arguments of capi_export_inventory
+var exportInventoryParams = params.Schema{
+	{Name: "namespace", Type: params.String, Description: "Namespace to export (optional, empty for all)"},
+	{Name: "format", Type: params.String, Default: string(report.FormatCSV),
+		Enum:        []string{string(report.FormatCSV), string(report.FormatJSON)},
+		Description: "Report format (default: csv)"},
+}
+
+// fleetUpgradeParams declares the arguments of capi_fleet_upgrade
+var fleetUpgradeParams = params.Schema{
+	{Name: "target_version", Type: params.String, Required: true, Validate: params.Semver,
+		Description: "Target Kubernetes version (e.g., v1.30.2)"},
+	{Name: "namespace", Type: params.String, Description: "Namespace of the clusters (optional, empty for all; required with clusters)"},
+	{Name: "clusters", Type: params.String,
+		Description: "Comma-separated names of the clusters to upgrade, in order (optional, default: all clusters matching the label selector)"},
+	{Name: labelSelectorArgument, Type: params.String, Validate: params.LabelSelector,
+		Description: "Kubernetes label selector choosing the clusters, e.g. 'env=staging'"},
+	{Name: "upgrade_workers", Type: params.Bool, Default: true, Description: "Also upgrade worker nodes (default: true)"},
+	{Name: "concurrency", Type: params.Int, Default: 1, NonNegative: true,
+		Description: "How many clusters roll out at the same time (default: 1)"},
+}
+
+// registerFleetTools adds the tools giving an overview of the fleet
+func registerFleetTools(s Registry, serverCtx *ServerContext) {
+	fleetSummaryTool := fleetSummaryParams.NewTool(
+		"capi_fleet_summary",
+		"Summarize all clusters by provider, phase, Kubernetes version and health, highlighting unhealthy or stuck clusters",
+	)
+	addTool(s, fleetSummaryTool, createFleetSummaryHandler(serverCtx))
+
+	findStaleResourcesTool := findStaleResourcesParams.NewTool(
+		"capi_find_stale_resources",
+		"Find machines stuck provisioning or deleting, clusters that are still not provisioned or stuck deleting, and MachineDeployments whose rollout stopped progressing, beyond configurable durations. Paused clusters are skipped.",
+	)
+	addTool(s, findStaleResourcesTool, createFindStaleResourcesHandler(serverCtx))
+
+	exportInventoryTool := exportInventoryParams.NewTool(
+		"capi_export_inventory",
+		"Export the cluster inventory (cluster, namespace, provider, version, node counts, age, health) as CSV or JSON for capacity planning and compliance",
+	)
+	addTool(s, exportInventoryTool, createExportInventoryHandler(serverCtx))
+
+	fleetUpgradeTool := fleetUpgradeParams.NewTool(
+		"capi_fleet_upgrade",
+		"Upgrade many clusters to a Kubernetes version in a background job. Each cluster is pre-checked and must be healthy after its rollout before the next one starts; the first failure aborts the upgrade.",
+		withApprovalID(),
+	)
+	addTool(s, fleetUpgradeTool, createFleetUpgradeHandler(serverCtx))
+}
+
+// createFleetSummaryHandler creates a handler for summarizing the fleet
+func createFleetSummaryHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := fleetSummaryParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		stuckAfter := time.Duration(args.Int("stuck_after_minutes")) * time.Minute
+
+		summary, err := serverCtx.client(ctx).GetFleetSummary(ctx, args.String("namespace"), stuckAfter)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to summarize the fleet: %w", err))
+		}
+
+		return newToolResult(formatFleetSummary(summary, time.Now()), summary)
+	}
+}
+
+// createFindStaleResourcesHandler creates a handler for finding stale resources
+func createFindStaleResourcesHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := findStaleResourcesParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		thresholds := capi.StaleThresholds{
+			Provisioning: time.Duration(args.Int("provisioning_minutes")) * time.Minute,
+			Deleting:     time.Duration(args.Int("deleting_minutes")) * time.Minute,
+			Cluster:      time.Duration(args.Int("cluster_minutes")) * time.Minute,
+			Rollout:      time.Duration(args.Int("rollout_minutes")) * time.Minute,
+		}
+
+		stale, err := serverCtx.client(ctx).FindStaleResources(ctx, args.String("namespace"), args.String("clusterName"), thresholds)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to scan for stale resources: %w", err))
+		}
+
+		return newToolResult(formatStaleResources(stale, time.Now()), map[string]any{"stale": stale})
+	}
+}
+
+// createExportInventoryHandler creates a handler for exporting the inventory
+func createExportInventoryHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := exportInventoryParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		format := report.Format(args.String("format"))
+
+		statuses, err := serverCtx.client(ctx).GetClustersStatus(ctx, args.String("namespace"))
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list clusters: %w", err))
+		}
+		rows := report.Inventory(statuses.Items, time.Now())
+
+		var content strings.Builder
+		if err := report.WriteInventory(&content, format, rows); err != nil {
+			return toolError(fmt.Errorf("failed to render the inventory: %w", err))
+		}
+
+		return newToolResult(content.String(), map[string]any{"format": format, "clusters": rows})
+	}
+}
+
+// createFleetUpgradeHandler creates a handler for upgrading many clusters
+func createFleetUpgradeHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := fleetUpgradeParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		opts := fleet.UpgradeOptions{
+			TargetVersion:  args.String("target_version"),
+			UpgradeWorkers: args.Bool("upgrade_workers"),
+			Concurrency:    args.Int("concurrency"),
+		}
+
+		var targets []fleet.Target
+		if names := args.String("clusters"); names != "" {
+			if namespace == "" {
+				return invalidArgument("namespace is required when clusters are named")
+			}
+			if args.Provided(labelSelectorArgument) {
+				return invalidArgument("clusters and label_selector cannot be combined")
+			}
+			for _, name := range strings.Split(names, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					targets = append(targets, fleet.Target{Namespace: namespace, Name: name})
+				}
+			}
+		} else {
+			clusters, err := serverCtx.client(ctx).ListClusters(ctx, namespace, capi.WithLabelSelector(args.String(labelSelectorArgument)))
+			if err != nil {
+				return toolError(fmt.Errorf("failed to list clusters: %w", err))
+			}
+			for _, cluster := range clusters.Items {
+				targets = append(targets, fleet.Target{Namespace: cluster.Namespace, Name: cluster.Name})
+			}
+		}
+		if len(targets) == 0 {
+			return invalidArgument("no clusters match the selection")
+		}
+
+		c := serverCtx.client(ctx)
+		target := fmt.Sprintf("%d clusters to %s", len(targets), opts.TargetVersion)
+		job, err := serverCtx.startJob(ctx, "fleet-upgrade", namespace, target, func(ctx context.Context, logf func(format string, args ...any)) (any, error) {
+			return fleet.Upgrade(ctx, c, targets, opts, logf)
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to start the fleet upgrade: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("🚀 Upgrading %s in job %s\n\n", target, job.ID))
+		content.WriteString("Order:\n")
+		for i, t := range targets {
+			content.WriteString(fmt.Sprintf("  %d. %s\n", i+1, t))
+		}
+		content.WriteString(fmt.Sprintf("\nUp to %d clusters roll out at a time. The first failed pre-check, rollout or health gate aborts the upgrade.\n", max(opts.Concurrency, 1)))
+		content.WriteString("• Check its progress with: capi_job_status or capi_job_logs\n")
+		content.WriteString("• Stop starting new clusters with: capi_job_cancel (rollouts in progress continue)\n")
+
+		return newToolResult(content.String(), map[string]any{
+			"jobId":         job.ID,
+			"targetVersion": opts.TargetVersion,
+			"clusters":      targets,
+		})
+	}
+}
+
+// formatFleetSummary renders a fleet summary for display
+func formatFleetSummary(summary *capi.FleetSummary, now time.Time) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Fleet: %d clusters (%d healthy, %d degraded, %d unhealthy), %d/%d machines with a node\n\n",
+		summary.TotalClusters,
+		summary.ByHealth[capi.HealthHealthy],
+		summary.ByHealth[capi.HealthDegraded],
+		summary.ByHealth[capi.HealthUnhealthy],
+		summary.ReadyMachines,
+		summary.TotalMachines))
+	content.WriteString(fmt.Sprintf("By provider: %s\n", formatCounts(summary.ByProvider)))
+	content.WriteString(fmt.Sprintf("By phase: %s\n", formatCounts(summary.ByPhase)))
+	content.WriteString(fmt.Sprintf("By version: %s\n", formatCounts(summary.ByVersion)))
+
+	if len(summary.NeedsAttention) == 0 {
+		content.WriteString("\n✅ All clusters are healthy\n")
+		return content.String()
+	}
+
+	content.WriteString(fmt.Sprintf("\nNeeds attention (%d):\n", len(summary.NeedsAttention)))
+	for _, issue := range summary.NeedsAttention {
+		marker := "⚠️"
+		if issue.Stuck {
+			marker = "🛑 STUCK"
+		}
+		content.WriteString(fmt.Sprintf("  %s %s/%s (%s): %s", marker, issue.Namespace, issue.Name, issue.Health, issue.Message))
+		if !issue.Since.IsZero() {
+			content.WriteString(fmt.Sprintf(", for %s", now.Sub(issue.Since).Round(time.Minute)))
+		}
+		content.WriteString("\n")
+	}
+	return content.String()
+}
+
+// formatStaleResources renders stale resources for display
+func formatStaleResources(stale []capi.StaleResource, now time.Time) string {
+	if len(stale) == 0 {
+		return "✅ No stale clusters, machines or machine deployments\n"
+	}
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("🛑 Found %d stale resources:\n\n", len(stale)))
+	for _, resource := range stale {
+		content.WriteString(fmt.Sprintf("  - %s %s/%s (cluster %s): %s for %s", resource.Kind, resource.Namespace, resource.Name,
+			resource.Cluster, resource.State, now.Sub(resource.Since).Round(time.Minute)))
+		if resource.Message != "" {
+			content.WriteString(", " + resource.Message)
+		}
+		content.WriteString("\n")
+	}
+	return content.String()
+}
+
+// formatCounts renders counts as "key=count" pairs sorted by key
+func formatCounts[K ~string](counts map[K]int) string {
+	if len(counts) == 0 {
+		return "none"
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, string(key))
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", key, counts[K(key)]))
+	}
+	return strings.Join(pairs, ", ")
+}
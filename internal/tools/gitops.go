@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// gitOpsOverrideArgument lets a tool change objects owned by Flux or Argo CD
+const gitOpsOverrideArgument = "gitops_override"
+
+// Modes of the GitOps guard
+const (
+	// GitOpsGuardRefuse rejects changes to objects owned by Flux or Argo CD
+	// unless the call passes gitops_override
+	GitOpsGuardRefuse = "refuse"
+	// GitOpsGuardWarn makes the changes and warns about the drift
+	GitOpsGuardWarn = "warn"
+)
+
+// gitOpsGuardedTools lists the tools changing or deleting existing CAPI
+// objects, which Flux or Argo CD may manage. Other mutating tools only
+// create objects or do not touch CAPI objects at all.
+var gitOpsGuardedTools = map[string]bool{
+	"capi_update_cluster":            true,
+	"capi_upgrade_cluster":           true,
+	"capi_scale_cluster":             true,
+	"capi_pause_cluster":             true,
+	"capi_resume_cluster":            true,
+	"capi_delete_cluster":            true,
+	"capi_hibernate_cluster":         true,
+	"capi_wake_cluster":              true,
+	"capi_upgrade_release":           true,
+	"capi_scale_machinedeployment":   true,
+	"capi_update_machinedeployment":  true,
+	"capi_rollout_machinedeployment": true,
+	"capi_update_machine_image":      true,
+	"capi_delete_machine":            true,
+	"capi_remediate_machine":         true,
+	"capi_set_machine_annotation":    true,
+	"capi_remove_machine_annotation": true,
+	"capi_aws_configure_spot":        true,
+	"capi_fleet_upgrade":             true,
+	"capi_canary_upgrade":            true,
+	"capi_canary_resume":             true,
+	"capi_apply_manifest":            true,
+	"capi_restore_cluster":           true,
+	"capi_revert_change":             true,
+	"capi_pivot_cluster":             true,
+}
+
+// withGitOpsOverride adds the GitOps guard override to a tool
+func withGitOpsOverride(tool *mcp.Tool) {
+	mcp.WithBoolean(gitOpsOverrideArgument,
+		mcp.Description("Change objects managed by Flux or Argo CD anyway, although their next sync reverts the change (default: false); the override is recorded in the audit log"),
+	)(tool)
+}
+
+// NewGitOpsGuardMiddleware keeps tools from changing objects that Flux or
+// Argo CD apply from Git, which would revert the change and drift. In refuse
+// mode the calls fail unless they pass gitops_override; calls overriding the
+// guard and all calls in warn mode succeed with a warning naming the owners.
+func NewGitOpsGuardMiddleware(mode string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			override, _ := request.GetArguments()[gitOpsOverrideArgument].(bool)
+			if !gitOpsGuardedTools[request.Params.Name] || (mode != GitOpsGuardWarn && !override) {
+				return next(ctx, request)
+			}
+
+			ctx, guard := capi.WithGitOpsOverride(ctx)
+			result, err := next(ctx, request)
+			objects := guard.Objects()
+			if err != nil || result == nil || result.IsError || len(objects) == 0 {
+				return result, err
+			}
+			warning := formatGitOpsWarning(objects)
+			for i, content := range result.Content {
+				if text, ok := content.(mcp.TextContent); ok {
+					result.Content[i] = mcp.NewTextContent(warning + text.Text)
+					break
+				}
+			}
+			return result, nil
+		}
+	}
+}
+
+// formatGitOpsWarning names the objects owned by GitOps tools that a call
+// changed
+func formatGitOpsWarning(objects []capi.GitOpsOwnedObject) string {
+	var warning strings.Builder
+	warning.WriteString("⚠️ Changed objects managed by GitOps, their next sync reverts the change unless it is made in Git as well:\n")
+	for _, obj := range objects {
+		warning.WriteString(fmt.Sprintf("  - %s %s/%s, managed by %s\n", obj.Kind, obj.Namespace, obj.Name, obj.Owner))
+	}
+	warning.WriteString("\n")
+	return warning.String()
+}
+
+// registerGitOpsTools adds the tools moving clusters into GitOps repositories
+func registerGitOpsTools(s Registry, serverCtx *ServerContext) {
+	exportGitOpsTool := exportGitOpsParams.NewTool(
+		"capi_export_gitops",
+		"Export the CAPI manifests of a cluster as a kustomize directory for Flux or Argo CD (one file per object, kustomization.yaml, optional sync object), without status and with Secrets only referenced, to move a cluster created imperatively into Git. Returns the file tree or writes it as a .tar.gz archive.",
+	)
+	addTool(s, exportGitOpsTool, createExportGitOpsHandler(serverCtx))
+}
+
+// exportGitOpsParams declares the arguments of capi_export_gitops
+var exportGitOpsParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "path", Type: params.String, Description: "Directory of the cluster in the repository (default: clusters/<namespace>/<name>)"},
+	{Name: "tool", Type: params.String, Default: capi.GitOpsKustomize, Enum: capi.GitOpsTools,
+		Description: "GitOps tool to add a sync object for: 'flux' adds a Kustomization, 'argocd' an Application, 'kustomize' none"},
+	{Name: "source", Type: params.String,
+		Description: "Flux GitRepository name (default: flux-system) or Argo CD repository URL (required for argocd)"},
+	{Name: "archive", Type: params.String,
+		Description: "Write the files as a .tar.gz archive to this new file instead of returning their content (optional)"},
+}
+
+// createExportGitOpsHandler exports a cluster as a GitOps directory
+func createExportGitOpsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := exportGitOpsParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace, name := args.String("namespace"), args.String("name")
+
+		var archivePath string
+		if args.String("archive") != "" {
+			if archivePath, err = serverCtx.kubeconfigPath(args.String("archive")); err != nil {
+				return toolError(err)
+			}
+		}
+
+		export, err := serverCtx.client(ctx).ExportGitOps(ctx, capi.ExportGitOpsOptions{
+			Namespace: namespace,
+			Name:      name,
+			Path:      args.String("path"),
+			Tool:      args.String("tool"),
+			Source:    args.String("source"),
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to export cluster: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("GitOps export of cluster %s/%s to %s/ (%s):\n\n", namespace, name, export.Path, export.Tool))
+		for _, file := range export.Files {
+			content.WriteString(fmt.Sprintf("  - %s\n", file.Path))
+		}
+		if export.Topology {
+			content.WriteString("\nThe cluster uses a ClusterClass, only the Cluster is exported.\n")
+		}
+		if len(export.Secrets) > 0 {
+			content.WriteString("\nReferenced Secrets to provide outside of Git:\n")
+			for _, secret := range export.Secrets {
+				content.WriteString(fmt.Sprintf("  - %s (used by %s)\n", secret.Name, strings.Join(secret.ReferencedBy, ", ")))
+			}
+		}
+
+		data := map[string]any{"export": export}
+		if archivePath != "" {
+			archive, err := export.Archive()
+			if err != nil {
+				return toolError(err)
+			}
+			if err := writeNewFile(archivePath, archive); err != nil {
+				return toolError(fmt.Errorf("failed to write archive: %w", err))
+			}
+			data = map[string]any{"path": archivePath, "files": len(export.Files), "secrets": export.Secrets}
+			content.WriteString(fmt.Sprintf("\nWrote the archive to %s; extract it in the repository with: tar -xzf %s\n", archivePath, archivePath))
+			return newToolResult(content.String(), data)
+		}
+
+		for _, file := range export.Files {
+			lang := "yaml"
+			if strings.HasSuffix(file.Path, ".md") {
+				lang = "markdown"
+			}
+			content.WriteString(fmt.Sprintf("\n%s:\n```%s\n%s```\n", file.Path, lang, file.Content))
+		}
+		return newToolResult(content.String(), data)
+	}
+}
@@ -0,0 +1,1176 @@
+package tools
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/approval"
+	"github.com/giantswarm/mcp-capi/internal/audit"
+	"github.com/giantswarm/mcp-capi/internal/backup"
+	"github.com/giantswarm/mcp-capi/internal/fleet"
+	"github.com/giantswarm/mcp-capi/internal/jobs"
+	"github.com/giantswarm/mcp-capi/internal/schedule"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/giantswarm/mcp-capi/pkg/capi/capitest"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+)
+
+// updateGolden rewrites the golden files instead of comparing against them:
+//
+//	go test ./internal/tools -run TestGoldenResults -update
+var updateGolden = flag.Bool("update", false, "rewrite the golden files of tool results")
+
+// goldenCase calls a tool with canonical arguments against the golden
+// fixtures. Each case is compared against testdata/golden/<name>.golden.
+type goldenCase struct {
+	// name defaults to the tool name
+	name      string
+	tool      string
+	arguments map[string]any
+	// seed prepares the server context of the case and returns the
+	// arguments only known once it ran, such as generated IDs. Arguments the
+	// tool does not take are dropped, so listing tools share the seeds.
+	seed func(t *testing.T, serverCtx *ServerContext) map[string]any
+}
+
+func (c goldenCase) file() string {
+	name := c.name
+	if name == "" {
+		name = c.tool
+	}
+	return filepath.Join("testdata", "golden", name+".golden")
+}
+
+// goldenCases cover every registered tool, TestGoldenResults fails for a tool
+// without a case. Mutating tools run against a fresh fake, so the order of
+// the cases does not matter.
+var goldenCases = []goldenCase{
+	{tool: "test", arguments: map[string]any{"message": "hello"}},
+
+	// Clusters
+	{tool: "capi_list_clusters", arguments: map[string]any{"namespace": "org-acme"}},
+	{name: "capi_list_clusters_summary", tool: "capi_list_clusters", arguments: map[string]any{"output": "summary"}},
+	{tool: "capi_get_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{name: "capi_get_cluster_not_found", tool: "capi_get_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "staging"}},
+	{tool: "capi_cluster_status", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_cluster_health", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_cluster_capacity", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_create_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "dev", "provider": "docker", "kubernetes_version": "v1.30.2"}},
+	{tool: "capi_validate_cluster_config", arguments: map[string]any{"namespace": "org-acme", "name": "dev", "provider": "docker", "kubernetes_version": "v1.30.2"}},
+	{tool: "capi_generate_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "dev", "provider": "docker", "kubernetes_version": "v1.30.2"}},
+	{tool: "capi_template_variables", arguments: map[string]any{"namespace": "org-acme", "provider": "docker"}},
+	{tool: "capi_update_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "prod", "labels": map[string]any{"team": "platform"}}},
+	{tool: "capi_upgrade_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "prod", "target_version": "v1.31.0"}},
+	{tool: "capi_upgrade_plan", arguments: map[string]any{"namespace": "org-acme", "name": "prod", "target_version": "v1.31.0"}},
+	{tool: "capi_upgrade_release", arguments: map[string]any{"namespace": "org-acme", "name": "prod", "release": "30.0.0"}},
+	{tool: "capi_scale_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "prod", "target": "workers", "replicas": 3, "machineDeployment": "prod-md-0"}},
+	{tool: "capi_delete_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_pause_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_resume_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_hibernate_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "aws", "timeout_seconds": 1}},
+	{tool: "capi_wake_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "aws"}, seed: seedHibernation},
+	{tool: "capi_clone_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "prod", "new_name": "prod-copy"}},
+	{tool: "capi_move_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "prod", "target_namespace": "org-other"}},
+	{tool: "capi_pivot_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "prod", "dry_run": true}},
+	{tool: "capi_get_kubeconfig", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_issue_kubeconfig", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_rotate_kubeconfig", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_wait_for_ready", arguments: map[string]any{"kind": "cluster", "namespace": "org-acme", "name": "prod", "timeout_seconds": 1}},
+	{tool: "capi_etcd_status", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_probe_api_server", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_addons_status", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_autoscaler_status", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_install_cni", arguments: map[string]any{"namespace": "org-acme", "name": "prod", "cni": "calico"}},
+	{tool: "capi_export_gitops", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+
+	// Machines, machine sets and machine deployments
+	{tool: "capi_list_machines", arguments: map[string]any{"namespace": "org-acme", "clusterName": "prod"}},
+	{tool: "capi_get_machine", arguments: map[string]any{"namespace": "org-acme", "name": "prod-md-0-abc12-w1"}},
+	{tool: "capi_delete_machine", arguments: map[string]any{"namespace": "org-acme", "name": "prod-md-0-abc12-w1"}},
+	{tool: "capi_remediate_machine", arguments: map[string]any{"namespace": "org-acme", "name": "prod-md-0-abc12-w1"}},
+	{tool: "capi_list_machine_annotations", arguments: map[string]any{"namespace": "org-acme", "clusterName": "prod"}},
+	{tool: "capi_set_machine_annotation", arguments: map[string]any{"namespace": "org-acme", "name": "prod-md-0-abc12-w1", "annotation": "skip-remediation"}},
+	{tool: "capi_remove_machine_annotation", arguments: map[string]any{"namespace": "org-acme", "name": "prod-md-0-abc12-w1", "annotation": "skip-remediation"}},
+	{tool: "capi_list_machinesets", arguments: map[string]any{"namespace": "org-acme", "clusterName": "prod"}},
+	{tool: "capi_get_machineset", arguments: map[string]any{"namespace": "org-acme", "name": "prod-md-0-abc12"}},
+	{tool: "capi_list_machinedeployments", arguments: map[string]any{"namespace": "org-acme", "clusterName": "prod"}},
+	{tool: "capi_create_machinedeployment", arguments: map[string]any{
+		"namespace": "org-acme", "name": "prod-md-1", "cluster_name": "prod", "replicas": 1, "version": "v1.30.2",
+		"infra_kind": "DockerMachineTemplate", "infra_name": "prod-md-0",
+		"bootstrap_kind": "KubeadmConfigTemplate", "bootstrap_name": "prod-md-0",
+	}},
+	{tool: "capi_update_machinedeployment", arguments: map[string]any{"namespace": "org-acme", "name": "prod-md-0", "min_ready_seconds": 30}},
+	{tool: "capi_scale_machinedeployment", arguments: map[string]any{"namespace": "org-acme", "name": "prod-md-0", "replicas": 3}},
+	{tool: "capi_rollout_machinedeployment", arguments: map[string]any{"namespace": "org-acme", "name": "prod-md-0"}},
+	{tool: "capi_update_machine_image", arguments: map[string]any{"namespace": "org-acme", "name": "aws-md-0", "image": "ami-0f1e2d3c"}},
+
+	// Nodes of workload clusters, which the fake cannot reach
+	{tool: "capi_node_status", arguments: map[string]any{"namespace": "org-acme", "machine_name": "prod-md-0-abc12-w1"}},
+	{tool: "capi_node_report", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_node_pods", arguments: map[string]any{"namespace": "org-acme", "cluster_name": "prod", "node_name": "prod-md-0-w1"}},
+	{tool: "capi_top_nodes", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_cordon_node", arguments: map[string]any{"namespace": "org-acme", "machine_name": "prod-md-0-abc12-w1"}},
+	{tool: "capi_drain_node", arguments: map[string]any{"namespace": "org-acme", "machine_name": "prod-md-0-abc12-w1"}},
+	{tool: "capi_pdb_conflicts", arguments: map[string]any{"namespace": "org-acme", "cluster_name": "prod", "operation": "drain", "machine_name": "prod-md-0-abc12-w1"}},
+
+	// Providers
+	{tool: "capi_list_infrastructure_providers"},
+	{tool: "capi_discover_providers"},
+	{tool: "capi_get_provider_config", arguments: map[string]any{"provider": "docker"}},
+	{tool: "capi_list_infrastructure_objects", arguments: map[string]any{"kind": "DockerMachineTemplate", "namespace": "org-acme"}},
+	{tool: "capi_get_infrastructure_object", arguments: map[string]any{"kind": "DockerMachineTemplate", "namespace": "org-acme", "name": "prod-md-0"}},
+	{tool: "capi_init_providers", arguments: map[string]any{"infrastructure": "docker"}},
+	{tool: "capi_upgrade_providers"},
+	{tool: "capi_provider_upgrade_plan"},
+	{tool: "capi_available_versions"},
+	{tool: "capi_controllers_status"},
+	{tool: "capi_check_webhooks"},
+	{tool: "capi_list_identities"},
+	{tool: "capi_validate_identities"},
+	{tool: "capi_aws_list_clusters"},
+	{tool: "capi_aws_get_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "aws"}},
+	{tool: "capi_aws_create_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "dev", "region": "eu-west-1"}},
+	{tool: "capi_aws_create_machine_template", arguments: map[string]any{"namespace": "org-acme", "name": "dev-md-0", "instance_type": "m5.large"}},
+	{tool: "capi_aws_get_machine_template", arguments: map[string]any{"namespace": "org-acme", "name": "aws-md-0"}},
+	{tool: "capi_aws_clone_machine_template", arguments: map[string]any{"namespace": "org-acme", "source": "aws-md-0", "name": "aws-md-2", "instance_type": "m5.2xlarge"}},
+	{tool: "capi_aws_diff_machine_templates", arguments: map[string]any{"namespace": "org-acme", "from": "aws-md-0", "to": "aws-md-1"}},
+	{tool: "capi_aws_configure_spot", arguments: map[string]any{"namespace": "org-acme", "machine_deployment": "aws-md-0"}},
+	{tool: "capi_aws_manage_security_groups", arguments: map[string]any{"namespace": "org-acme", "name": "aws", "operation": "list"}},
+	{tool: "capi_aws_update_vpc", arguments: map[string]any{"namespace": "org-acme", "name": "aws", "operation": "show"}},
+	{tool: "capi_azure_list_clusters"},
+	{tool: "capi_azure_list_aks_clusters"},
+	{tool: "capi_azure_get_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "azure"}},
+	{tool: "capi_azure_manage_resource_group", arguments: map[string]any{"namespace": "org-acme", "name": "azure", "operation": "show"}},
+	{tool: "capi_azure_network_config", arguments: map[string]any{"namespace": "org-acme", "name": "azure", "operation": "show"}},
+	{tool: "capi_gcp_list_clusters"},
+	{tool: "capi_gcp_get_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "gcp"}},
+	{tool: "capi_gcp_manage_network", arguments: map[string]any{"namespace": "org-acme", "name": "gcp", "operation": "show"}},
+	{tool: "capi_vsphere_list_clusters"},
+	{tool: "capi_vsphere_get_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "vsphere"}},
+	{tool: "capi_vsphere_list_vms", arguments: map[string]any{"namespace": "org-acme", "name": "vsphere"}},
+	{tool: "capi_metal3_list_clusters"},
+	{tool: "capi_metal3_get_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "metal3"}},
+	{tool: "capi_metal3_list_hosts"},
+	{tool: "capi_kubevirt_list_clusters"},
+	{tool: "capi_kubevirt_get_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "kubevirt"}},
+	{tool: "capi_kubevirt_list_vms", arguments: map[string]any{"namespace": "org-acme", "name": "kubevirt"}},
+
+	// Approvals, audit, changes, jobs and schedules
+	{tool: "capi_list_approvals", seed: seedApproval},
+	{tool: "capi_approve_operation", arguments: map[string]any{"approver": "jane"}, seed: seedApproval},
+	{tool: "capi_reject_operation", arguments: map[string]any{"approver": "jane", "reason": "not during the freeze"}, seed: seedApproval},
+	{tool: "capi_audit_log"},
+	{tool: "capi_list_changes", seed: seedChange},
+	{tool: "capi_revert_change", seed: seedChange},
+	{tool: "capi_job_status", seed: seedFinishedJob},
+	{tool: "capi_job_logs", seed: seedFinishedJob},
+	{tool: "capi_job_cancel", seed: seedRunningJob},
+	{tool: "capi_list_schedules", seed: seedSchedule},
+	{tool: "capi_create_schedule", arguments: map[string]any{"action": "hibernate", "namespace": "org-acme", "cluster_name": "prod", "cron": "0 20 * * 1-5"}},
+	{tool: "capi_cancel_schedule", seed: seedSchedule},
+
+	// Permissions, management clusters and the server
+	{tool: "capi_check_permissions"},
+	{tool: "capi_rbac_manifest"},
+	{tool: "capi_prepare_namespace", arguments: map[string]any{"namespace": "org-acme"}},
+	{tool: "capi_list_management_clusters"},
+	{tool: "capi_use_context", arguments: map[string]any{"context": "kind-capi"}},
+	{tool: "capi_server_info"},
+
+	// Fleet, search and inventory
+	{tool: "capi_search", arguments: map[string]any{"query": "prod"}},
+	{tool: "capi_fleet_summary"},
+	{tool: "capi_fleet_upgrade", arguments: map[string]any{"target_version": "v1.31.0"}},
+	{tool: "capi_canary_upgrade", arguments: map[string]any{"target_version": "v1.31.0", "namespace": "org-acme", "canary_cluster": "prod"}},
+	{tool: "capi_canary_status", seed: seedCanary},
+	{tool: "capi_canary_resume", seed: seedCanary},
+	{tool: "capi_export_inventory"},
+	{tool: "capi_find_stale_resources"},
+
+	// Manifests, organizations, releases and apps
+	{tool: "capi_apply_manifest", arguments: map[string]any{"namespace": "org-acme", "manifest": goldenManifest}},
+	{tool: "capi_list_organizations"},
+	{tool: "capi_list_releases"},
+	{tool: "capi_list_apps", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_get_app", arguments: map[string]any{"namespace": "org-acme", "name": "prod-cilium"}},
+
+	// Backups
+	{tool: "capi_velero_backup", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_backup_cluster", arguments: map[string]any{"namespace": "org-acme", "name": "prod"}},
+	{tool: "capi_push_backup", arguments: map[string]any{"namespace": "org-acme", "name": "prod", "target": "golden"}},
+	{tool: "capi_backup_status", arguments: map[string]any{"namespace": "org-acme", "name": "prod-backup"}},
+	{tool: "capi_backup_history", arguments: map[string]any{"namespace": "org-acme"}},
+	{tool: "capi_list_backups", arguments: map[string]any{"namespace": "org-acme"}},
+	{tool: "capi_list_stored_backups", arguments: map[string]any{"namespace": "org-acme"}},
+	{tool: "capi_restore_backup", arguments: map[string]any{"namespace": "org-acme", "backup": "prod-backup", "target_namespace": "org-restore"}},
+	{tool: "capi_restore_cluster", arguments: map[string]any{"namespace": "org-restore", "target": "golden", "dry_run": true}, seed: seedStoredBackup},
+}
+
+// approvalCodes is a Notifier keeping the codes of approval requests by ID
+type approvalCodes map[string]string
+
+func (c approvalCodes) Notify(ctx context.Context, n approval.Notification) error {
+	c[n.Request.ID] = n.Code
+	return nil
+}
+
+// seedApproval enables approval gates and submits a pending request to
+// delete prod
+func seedApproval(t *testing.T, serverCtx *ServerContext) map[string]any {
+	codes := approvalCodes{}
+	serverCtx.Approvals = approval.NewManager(approval.Config{Mode: approval.ModeEnqueue, Notifier: codes})
+	req, err := serverCtx.Approvals.Submit(context.Background(), "capi_delete_cluster", map[string]any{"namespace": "org-acme", "name": "prod"}, "mcp-client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]any{"approval_id": req.ID, "code": codes[req.ID]}
+}
+
+// seedChange pauses prod, recording the change
+func seedChange(t *testing.T, serverCtx *ServerContext) map[string]any {
+	if err := serverCtx.Client.PauseCluster(context.Background(), "org-acme", "prod"); err != nil {
+		t.Fatal(err)
+	}
+	return map[string]any{"change_id": serverCtx.Client.ChangeHistory().List()[0].ID}
+}
+
+// seedFinishedJob runs a job logging its progress to the end
+func seedFinishedJob(t *testing.T, serverCtx *ServerContext) map[string]any {
+	ctx := context.Background()
+	job, err := serverCtx.Jobs.Start(ctx, "backup", "org-acme", "org-acme/prod", "mcp-client", func(ctx context.Context, logf func(format string, args ...any)) (any, error) {
+		logf("Backing up cluster org-acme/prod")
+		return map[string]any{"objects": 12}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := serverCtx.Jobs.Wait(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	return map[string]any{"job_id": job.ID}
+}
+
+// seedRunningJob starts a job running until it is canceled
+func seedRunningJob(t *testing.T, serverCtx *ServerContext) map[string]any {
+	job, err := serverCtx.Jobs.Start(context.Background(), "backup", "org-acme", "org-acme/prod", "mcp-client", func(ctx context.Context, logf func(format string, args ...any)) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]any{"job_id": job.ID}
+}
+
+// seedSchedule schedules the hibernation of prod on weekday evenings
+func seedSchedule(t *testing.T, serverCtx *ServerContext) map[string]any {
+	created, err := serverCtx.Schedules.Create(context.Background(), schedule.Schedule{
+		Cron: "0 20 * * 1-5", Action: schedule.ActionHibernate, Namespace: "org-acme", Cluster: "prod", Requester: "mcp-client",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]any{"schedule_id": created.ID}
+}
+
+// seedCanary saves a canary upgrade of prod interrupted before it started
+func seedCanary(t *testing.T, serverCtx *ServerContext) map[string]any {
+	canary, err := serverCtx.Canaries.Create(fleet.Canary{
+		Options:      fleet.UpgradeOptions{TargetVersion: "v1.31.0", UpgradeWorkers: true, Concurrency: 1},
+		Canary:       fleet.Target{Namespace: "org-acme", Name: "prod"},
+		Rest:         []fleet.Target{{Namespace: "org-acme", Name: "aws"}},
+		SoakDuration: 30 * time.Minute,
+		Requester:    "mcp-client",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]any{"canary_id": canary.ID}
+}
+
+// seedHibernation hibernates aws, whose machine deployment has no machines
+func seedHibernation(t *testing.T, serverCtx *ServerContext) map[string]any {
+	opts := capi.HibernateOptions{Wait: capi.WaitOptions{Timeout: time.Second}}
+	if _, err := serverCtx.Client.HibernateCluster(context.Background(), "org-acme", "aws", opts); err != nil {
+		t.Fatal(err)
+	}
+	return nil
+}
+
+// seedStoredBackup pushes a backup of prod to the golden target
+func seedStoredBackup(t *testing.T, serverCtx *ServerContext) map[string]any {
+	ctx := context.Background()
+	bundle, err := serverCtx.Client.BackupCluster(ctx, capi.BackupClusterOptions{Namespace: "org-acme", Name: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := serverCtx.BackupTargets.Get("golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	upload, err := target.Upload(ctx, "org-acme", "prod", "yaml", []byte(bundle), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]any{"key": upload.Backup.Key}
+}
+
+// goldenManifest is applied by the capi_apply_manifest case
+const goldenManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: prod-settings
+data:
+  region: eu-west-1
+`
+
+// goldenTime is the creation and transition time of the fixtures, a fixed
+// age before the test so ages in days are stable
+var goldenTime = metav1.NewTime(time.Now().Add(-(30*24 + 12) * time.Hour).Truncate(time.Second).UTC())
+
+// goldenFixtures return a Docker cluster prod in the organization namespace
+// org-acme, with a control plane machine and a machine deployment of one
+// worker. The API server of prod is apiServer, its certificate is the cluster
+// CA of the kubeconfig and CA Secrets. Without apiServer prod has neither
+// an endpoint nor these Secrets.
+func goldenFixtures(apiServer *httptest.Server) []runtime.Object {
+	var endpoint clusterv1.APIEndpoint
+	if apiServer != nil {
+		address := apiServer.Listener.Addr().(*net.TCPAddr)
+		endpoint = clusterv1.APIEndpoint{Host: address.IP.String(), Port: int32(address.Port)}
+	}
+	meta := func(name string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: "org-acme", Name: name, Labels: labels, CreationTimestamp: goldenTime, UID: k8stypes.UID("uid-" + name)}
+	}
+	clusterLabel := map[string]string{clusterv1.ClusterNameLabel: "prod"}
+	ready := clusterv1.Conditions{{Type: clusterv1.ReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: goldenTime}}
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:              "org-acme",
+		Labels:            map[string]string{capi.OrganizationLabel: "acme"},
+		CreationTimestamp: goldenTime,
+	}}
+
+	cluster := &clusterv1.Cluster{ObjectMeta: meta("prod", map[string]string{capi.ReleaseVersionLabel: "29.0.0"})}
+	cluster.Spec.ControlPlaneRef = &corev1.ObjectReference{APIVersion: controlplanev1.GroupVersion.String(), Kind: "KubeadmControlPlane", Namespace: "org-acme", Name: "prod-control-plane"}
+	cluster.Spec.InfrastructureRef = &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "DockerCluster", Namespace: "org-acme", Name: "prod"}
+	cluster.Spec.ControlPlaneEndpoint = endpoint
+	cluster.Spec.ClusterNetwork = &clusterv1.ClusterNetwork{
+		Pods:     &clusterv1.NetworkRanges{CIDRBlocks: []string{"192.168.0.0/16"}},
+		Services: &clusterv1.NetworkRanges{CIDRBlocks: []string{"10.128.0.0/12"}},
+	}
+	cluster.Status.Phase = string(clusterv1.ClusterPhaseProvisioned)
+	cluster.Status.InfrastructureReady = true
+	cluster.Status.ControlPlaneReady = true
+	cluster.Status.Conditions = ready
+
+	controlPlane := &controlplanev1.KubeadmControlPlane{ObjectMeta: meta("prod-control-plane", clusterLabel)}
+	controlPlane.Spec.Replicas = ptr.To[int32](1)
+	controlPlane.Spec.Version = "v1.30.2"
+	controlPlane.Spec.MachineTemplate.InfrastructureRef = corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "DockerMachineTemplate", Namespace: "org-acme", Name: "prod-control-plane"}
+	controlPlane.Status.Replicas, controlPlane.Status.ReadyReplicas, controlPlane.Status.UpdatedReplicas = 1, 1, 1
+	controlPlane.Status.Ready, controlPlane.Status.Initialized = true, true
+	controlPlane.Status.Version = ptr.To("v1.30.2")
+	controlPlane.Status.Conditions = ready
+
+	deployment := &clusterv1.MachineDeployment{ObjectMeta: meta("prod-md-0", clusterLabel)}
+	deployment.Spec.ClusterName = "prod"
+	deployment.Spec.Replicas = ptr.To[int32](1)
+	deployment.Spec.Selector.MatchLabels = map[string]string{clusterv1.MachineDeploymentNameLabel: "prod-md-0"}
+	deployment.Spec.Template.Labels = map[string]string{clusterv1.MachineDeploymentNameLabel: "prod-md-0"}
+	deployment.Spec.Template.Spec.ClusterName = "prod"
+	deployment.Spec.Template.Spec.Version = ptr.To("v1.30.2")
+	deployment.Spec.Template.Spec.Bootstrap.ConfigRef = &corev1.ObjectReference{APIVersion: bootstrapv1.GroupVersion.String(), Kind: "KubeadmConfigTemplate", Namespace: "org-acme", Name: "prod-md-0"}
+	deployment.Spec.Template.Spec.InfrastructureRef = corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "DockerMachineTemplate", Namespace: "org-acme", Name: "prod-md-0"}
+	deployment.Status.Phase = string(clusterv1.MachineDeploymentPhaseRunning)
+	deployment.Status.Replicas, deployment.Status.ReadyReplicas, deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas = 1, 1, 1, 1
+	deployment.Status.Conditions = ready
+
+	machineSet := &clusterv1.MachineSet{ObjectMeta: meta("prod-md-0-abc12", map[string]string{
+		clusterv1.ClusterNameLabel:           "prod",
+		clusterv1.MachineDeploymentNameLabel: "prod-md-0",
+	})}
+	machineSet.OwnerReferences = []metav1.OwnerReference{{APIVersion: clusterv1.GroupVersion.String(), Kind: "MachineDeployment", Name: "prod-md-0", UID: deployment.UID, Controller: ptr.To(true)}}
+	machineSet.Spec.ClusterName = "prod"
+	machineSet.Spec.Replicas = ptr.To[int32](1)
+	machineSet.Spec.Template = deployment.Spec.Template
+	machineSet.Status.Replicas, machineSet.Status.ReadyReplicas, machineSet.Status.AvailableReplicas = 1, 1, 1
+
+	machine := func(name, node string, labels map[string]string) *clusterv1.Machine {
+		m := &clusterv1.Machine{ObjectMeta: meta(name, labels)}
+		m.Spec.ClusterName = "prod"
+		m.Spec.Version = ptr.To("v1.30.2")
+		m.Spec.ProviderID = ptr.To("docker:////" + node)
+		m.Spec.InfrastructureRef = corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "DockerMachine", Namespace: "org-acme", Name: name}
+		m.Status.Phase = string(clusterv1.MachinePhaseRunning)
+		m.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: node}
+		m.Status.BootstrapReady, m.Status.InfrastructureReady = true, true
+		m.Status.Conditions = ready
+		return m
+	}
+	controlPlaneMachine := machine("prod-control-plane-x1", "prod-control-plane-x1", map[string]string{
+		clusterv1.ClusterNameLabel:         "prod",
+		clusterv1.MachineControlPlaneLabel: "",
+	})
+	controlPlaneMachine.OwnerReferences = []metav1.OwnerReference{{APIVersion: controlplanev1.GroupVersion.String(), Kind: "KubeadmControlPlane", Name: "prod-control-plane", UID: controlPlane.UID, Controller: ptr.To(true)}}
+	worker := machine("prod-md-0-abc12-w1", "prod-md-0-w1", map[string]string{
+		clusterv1.ClusterNameLabel:           "prod",
+		clusterv1.MachineDeploymentNameLabel: "prod-md-0",
+		clusterv1.MachineSetNameLabel:        "prod-md-0-abc12",
+	})
+	worker.OwnerReferences = []metav1.OwnerReference{{APIVersion: clusterv1.GroupVersion.String(), Kind: "MachineSet", Name: "prod-md-0-abc12", UID: machineSet.UID, Controller: ptr.To(true)}}
+
+	configTemplate := &bootstrapv1.KubeadmConfigTemplate{ObjectMeta: meta("prod-md-0", clusterLabel)}
+
+	infra := func(kind, name string, spec map[string]any) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+		u.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta1")
+		u.SetKind(kind)
+		u.SetNamespace("org-acme")
+		u.SetName(name)
+		u.SetLabels(clusterLabel)
+		u.SetCreationTimestamp(goldenTime)
+		return u
+	}
+	dockerCluster := infra("DockerCluster", "prod", map[string]any{"controlPlaneEndpoint": map[string]any{"host": endpoint.Host, "port": int64(endpoint.Port)}})
+	dockerCluster.Object["status"] = map[string]any{"ready": true}
+	controlPlaneTemplate := infra("DockerMachineTemplate", "prod-control-plane", map[string]any{"template": map[string]any{"spec": map[string]any{"customImage": "kindest/node:v1.30.2"}}})
+	workerTemplate := infra("DockerMachineTemplate", "prod-md-0", map[string]any{"template": map[string]any{"spec": map[string]any{"customImage": "kindest/node:v1.30.2"}}})
+
+	objects := []runtime.Object{
+		namespace, cluster, controlPlane, deployment, machineSet, controlPlaneMachine, worker,
+		configTemplate, dockerCluster, controlPlaneTemplate, workerTemplate,
+	}
+	objects = append(objects, goldenProviders()...)
+	objects = append(objects, goldenProviderClusters()...)
+	objects = append(objects, goldenOperatorObjects()...)
+	// The legacy node tools read the nodes from the management cluster
+	for _, node := range goldenNodes() {
+		objects = append(objects, node)
+	}
+	if apiServer != nil {
+		objects = append(objects, goldenClusterSecrets(apiServer)...)
+	}
+	return objects
+}
+
+// goldenProviders return the clusterctl inventory and controller deployments
+// of the core and Docker providers of the management cluster
+func goldenProviders() []runtime.Object {
+	var objects []runtime.Object
+	for _, provider := range []struct{ name, providerName, kind, namespace, image string }{
+		{"cluster-api", "cluster-api", "CoreProvider", "capi-system", "registry.k8s.io/cluster-api/cluster-api-controller:v1.8.0"},
+		{"infrastructure-docker", "docker", "InfrastructureProvider", "capd-system", "registry.k8s.io/cluster-api/capd-manager:v1.8.0"},
+	} {
+		inventory := &unstructured.Unstructured{Object: map[string]any{
+			"providerName": provider.providerName,
+			"type":         provider.kind,
+			"version":      "v1.8.0",
+		}}
+		inventory.SetAPIVersion("clusterctl.cluster.x-k8s.io/v1alpha3")
+		inventory.SetKind("Provider")
+		inventory.SetNamespace(provider.namespace)
+		inventory.SetName(provider.name)
+		inventory.SetCreationTimestamp(goldenTime)
+
+		labels := map[string]string{clusterv1.ProviderNameLabel: provider.name}
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Namespace:         provider.namespace,
+			Name:              strings.SplitN(provider.image[strings.LastIndex(provider.image, "/")+1:], ":", 2)[0],
+			Labels:            labels,
+			CreationTimestamp: goldenTime,
+		}}
+		deployment.Spec.Replicas = ptr.To[int32](1)
+		deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: labels}
+		deployment.Spec.Template.Labels = labels
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{Name: "manager", Image: provider.image}}
+		deployment.Status.Replicas, deployment.Status.ReadyReplicas, deployment.Status.AvailableReplicas = 1, 1, 1
+		objects = append(objects, inventory, deployment)
+	}
+	return objects
+}
+
+// goldenProviderClusters return a cluster named after each infrastructure
+// provider besides Docker in org-acme, with the infrastructure objects the
+// provider tools read: AWS machine templates and a machine deployment, vSphere
+// and KubeVirt machines and a Metal3 bare metal host
+func goldenProviderClusters() []runtime.Object {
+	object := func(apiVersion, kind, name string, labels map[string]string, content map[string]any) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: content}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetNamespace("org-acme")
+		u.SetName(name)
+		u.SetLabels(labels)
+		u.SetCreationTimestamp(goldenTime)
+		return u
+	}
+	labels := func(cluster string) map[string]string {
+		return map[string]string{clusterv1.ClusterNameLabel: cluster}
+	}
+	cluster := func(name, apiVersion, kind string) *clusterv1.Cluster {
+		c := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: name, CreationTimestamp: goldenTime, UID: k8stypes.UID("uid-" + name)}}
+		c.Spec.InfrastructureRef = &corev1.ObjectReference{APIVersion: apiVersion, Kind: kind, Namespace: "org-acme", Name: name}
+		c.Status.Phase = string(clusterv1.ClusterPhaseProvisioned)
+		c.Status.InfrastructureReady = true
+		return c
+	}
+	machine := func(clusterName, name, infraKind string) *clusterv1.Machine {
+		m := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: name + "-x", Labels: labels(clusterName), CreationTimestamp: goldenTime}}
+		m.Spec.ClusterName = clusterName
+		m.Spec.InfrastructureRef = corev1.ObjectReference{Kind: infraKind, Namespace: "org-acme", Name: name}
+		m.Status.Phase = string(clusterv1.MachinePhaseRunning)
+		m.Status.NodeRef = &corev1.ObjectReference{Kind: "Node", Name: name}
+		return m
+	}
+	ready := map[string]any{"ready": true}
+
+	// AWS
+	awsTemplate := func(name, instanceType string) *unstructured.Unstructured {
+		return object("infrastructure.cluster.x-k8s.io/v1beta2", "AWSMachineTemplate", name, labels("aws"), map[string]any{
+			"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+				"instanceType":       instanceType,
+				"ami":                map[string]any{"id": "ami-0a1b2c3d"},
+				"rootVolume":         map[string]any{"size": int64(50), "type": "gp3"},
+				"iamInstanceProfile": "nodes.cluster-api-provider-aws.sigs.k8s.io",
+			}}},
+		})
+	}
+	awsDeployment := &clusterv1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "aws-md-0", Labels: labels("aws"), CreationTimestamp: goldenTime}}
+	awsDeployment.Spec.ClusterName = "aws"
+	awsDeployment.Spec.Replicas = ptr.To[int32](2)
+	awsDeployment.Spec.Template.Spec.ClusterName = "aws"
+	awsDeployment.Spec.Template.Spec.Version = ptr.To("v1.30.2")
+	awsDeployment.Spec.Template.Spec.InfrastructureRef = corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta2", Kind: "AWSMachineTemplate", Namespace: "org-acme", Name: "aws-md-0"}
+	awsDeployment.Status.Replicas, awsDeployment.Status.ReadyReplicas = 2, 2
+
+	// vSphere
+	vsphereMachine := object("infrastructure.cluster.x-k8s.io/v1beta1", "VSphereMachine", "vsphere-md-0-abc", labels("vsphere"), map[string]any{
+		"spec": map[string]any{
+			"template": "ubuntu-2204-kube-v1.30.2", "datacenter": "dc1", "datastore": "ds1",
+			"resourcePool": "vsphere-pool", "numCPUs": int64(4), "memoryMiB": int64(8192), "diskGiB": int64(40),
+		},
+		"status": ready,
+	})
+	vsphereVM := object("infrastructure.cluster.x-k8s.io/v1beta1", "VSphereVM", "vsphere-md-0-abc", labels("vsphere"), map[string]any{
+		"status": map[string]any{"ready": true, "host": "esxi-01", "addresses": []any{"10.0.0.21"}},
+	})
+	vsphereVM.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "VSphereMachine", Name: "vsphere-md-0-abc", UID: "uid-vsphere-md-0-abc"}})
+
+	// KubeVirt
+	vmi := object("kubevirt.io/v1", "VirtualMachineInstance", "kubevirt-md-0-abc", nil, map[string]any{
+		"status": map[string]any{
+			"phase":      "Running",
+			"nodeName":   "infra-node-3",
+			"interfaces": []any{map[string]any{"name": "default", "ipAddress": "10.244.1.17"}},
+		},
+	})
+
+	// Metal3
+	host := object("metal3.io/v1alpha1", "BareMetalHost", "server-1", nil, map[string]any{
+		"spec": map[string]any{
+			"online":         true,
+			"bootMACAddress": "00:5c:52:31:3a:9c",
+			"bmc":            map[string]any{"address": "redfish://10.0.1.1/redfish/v1/Systems/1"},
+			"consumerRef":    map[string]any{"apiVersion": "infrastructure.cluster.x-k8s.io/v1beta1", "kind": "Metal3Machine", "name": "metal3-control-plane-abc"},
+		},
+		"status": map[string]any{
+			"operationalStatus": "OK",
+			"poweredOn":         true,
+			"provisioning":      map[string]any{"state": "provisioned", "image": map[string]any{"url": "http://images/ubuntu.qcow2"}},
+			"hardware":          map[string]any{"cpu": map[string]any{"count": int64(32)}, "ramMebibytes": int64(131072)},
+		},
+	})
+
+	return []runtime.Object{
+		cluster("aws", "infrastructure.cluster.x-k8s.io/v1beta2", "AWSCluster"),
+		object("infrastructure.cluster.x-k8s.io/v1beta2", "AWSCluster", "aws", labels("aws"), map[string]any{
+			"spec": map[string]any{
+				"region":               "eu-west-1",
+				"controlPlaneEndpoint": map[string]any{"host": "api.aws.example.com", "port": int64(443)},
+				"network": map[string]any{
+					"vpc":     map[string]any{"id": "vpc-0a1b", "cidrBlock": "10.0.0.0/16"},
+					"subnets": []any{map[string]any{"resourceID": "subnet-0a1b", "availabilityZone": "eu-west-1a", "cidrBlock": "10.0.0.0/20", "isPublic": true}},
+				},
+			},
+			"status": map[string]any{
+				"ready": true,
+				"networkStatus": map[string]any{
+					"securityGroups": map[string]any{"node": map[string]any{"id": "sg-0b2c", "name": "aws-node"}},
+				},
+			},
+		}),
+		awsTemplate("aws-md-0", "m5.large"), awsTemplate("aws-md-1", "m5.xlarge"), awsDeployment,
+
+		cluster("azure", "infrastructure.cluster.x-k8s.io/v1beta1", "AzureCluster"),
+		object("infrastructure.cluster.x-k8s.io/v1beta1", "AzureCluster", "azure", labels("azure"), map[string]any{
+			"spec":   map[string]any{"location": "westeurope", "resourceGroup": "acme-azure"},
+			"status": ready,
+		}),
+
+		cluster("gcp", "infrastructure.cluster.x-k8s.io/v1beta1", "GCPCluster"),
+		object("infrastructure.cluster.x-k8s.io/v1beta1", "GCPCluster", "gcp", labels("gcp"), map[string]any{
+			"spec": map[string]any{
+				"project":              "acme-prod",
+				"region":               "europe-west3",
+				"controlPlaneEndpoint": map[string]any{"host": "34.1.2.3", "port": int64(443)},
+				"network":              map[string]any{"name": "gcp-net"},
+			},
+			"status": ready,
+		}),
+
+		cluster("vsphere", "infrastructure.cluster.x-k8s.io/v1beta1", "VSphereCluster"),
+		object("infrastructure.cluster.x-k8s.io/v1beta1", "VSphereCluster", "vsphere", labels("vsphere"), map[string]any{
+			"spec": map[string]any{
+				"server":               "vcenter.example.com",
+				"controlPlaneEndpoint": map[string]any{"host": "10.0.0.10", "port": int64(6443)},
+			},
+			"status": map[string]any{"ready": true, "vCenterVersion": "8.0.2"},
+		}),
+		machine("vsphere", "vsphere-md-0-abc", "VSphereMachine"), vsphereMachine, vsphereVM,
+
+		cluster("metal3", "infrastructure.cluster.x-k8s.io/v1beta1", "Metal3Cluster"),
+		object("infrastructure.cluster.x-k8s.io/v1beta1", "Metal3Cluster", "metal3", labels("metal3"), map[string]any{
+			"spec":   map[string]any{"controlPlaneEndpoint": map[string]any{"host": "192.168.10.5", "port": int64(6443)}},
+			"status": ready,
+		}),
+		machine("metal3", "metal3-control-plane-abc", "Metal3Machine"), host,
+
+		cluster("kubevirt", "infrastructure.cluster.x-k8s.io/v1alpha1", "KubevirtCluster"),
+		object("infrastructure.cluster.x-k8s.io/v1alpha1", "KubevirtCluster", "kubevirt", labels("kubevirt"), map[string]any{
+			"spec": map[string]any{
+				"controlPlaneEndpoint":        map[string]any{"host": "kubevirt-lb.org-acme.svc", "port": int64(6443)},
+				"controlPlaneServiceTemplate": map[string]any{"spec": map[string]any{"type": "LoadBalancer"}},
+			},
+			"status": ready,
+		}),
+		machine("kubevirt", "kubevirt-md-0-abc", "KubevirtMachine"),
+		object("infrastructure.cluster.x-k8s.io/v1alpha1", "KubevirtMachine", "kubevirt-md-0-abc", labels("kubevirt"), map[string]any{"status": ready}),
+		vmi,
+	}
+}
+
+// goldenOperatorObjects return the Giant Swarm releases and apps and the
+// Velero backups of the management cluster
+func goldenOperatorObjects() []runtime.Object {
+	object := func(apiVersion, kind, namespace, name string, labels map[string]string, content map[string]any) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: content}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetNamespace(namespace)
+		u.SetName(name)
+		u.SetLabels(labels)
+		u.SetCreationTimestamp(goldenTime)
+		return u
+	}
+	components := func(versions ...string) []any {
+		var list []any
+		for i := 0; i < len(versions); i += 2 {
+			list = append(list, map[string]any{"name": versions[i], "version": versions[i+1]})
+		}
+		return list
+	}
+	release := func(name, date, kubernetes, cilium string) *unstructured.Unstructured {
+		return object("release.giantswarm.io/v1alpha1", "Release", "", name, nil, map[string]any{"spec": map[string]any{
+			"state":      "active",
+			"date":       date,
+			"components": components("cluster-docker", "1.0.0", "kubernetes", kubernetes),
+			"apps":       components("cilium", cilium, "coredns", "1.21.0"),
+		}})
+	}
+
+	app := object("application.giantswarm.io/v1alpha1", "App", "org-acme", "prod-cilium", map[string]string{capi.ClusterLabel: "prod"}, map[string]any{
+		"spec": map[string]any{
+			"name": "cilium", "catalog": "default", "version": "0.25.1", "namespace": "kube-system",
+			"kubeConfig": map[string]any{"inCluster": false, "secret": map[string]any{"name": "prod-kubeconfig", "namespace": "org-acme"}},
+		},
+		"status": map[string]any{
+			"version": "0.25.1", "appVersion": "1.15.6",
+			"release": map[string]any{"status": "deployed", "lastDeployed": "2026-01-02T03:04:05Z"},
+		},
+	})
+
+	veleroBackup := object("velero.io/v1", "Backup", capi.DefaultVeleroNamespace, "prod-backup", map[string]string{
+		capi.BackupClusterLabel:          "prod",
+		capi.BackupClusterNamespaceLabel: "org-acme",
+	}, map[string]any{
+		"spec": map[string]any{"includedNamespaces": []any{"org-acme"}, "storageLocation": "default", "ttl": "720h0m0s"},
+		"status": map[string]any{
+			"phase":               "Completed",
+			"startTimestamp":      "2026-01-02T03:04:05Z",
+			"completionTimestamp": "2026-01-02T03:05:05Z",
+			"expiration":          "2026-02-01T03:04:05Z",
+			"progress":            map[string]any{"itemsBackedUp": int64(42), "totalItems": int64(42)},
+		},
+	})
+
+	return []runtime.Object{
+		release("docker-29.0.0", "2026-01-05T00:00:00Z", "1.30.2", "0.24.0"),
+		release("docker-30.0.0", "2026-03-02T00:00:00Z", "1.31.0", "0.25.1"),
+		app,
+		veleroBackup,
+	}
+}
+
+// goldenClusterSecrets return the admin kubeconfig and CA Secrets of prod,
+// trusting the certificate of apiServer as cluster CA
+func goldenClusterSecrets(apiServer *httptest.Server) []runtime.Object {
+	certificate := apiServer.TLS.Certificates[0]
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Certificate[0]})
+	key, err := x509.MarshalPKCS8PrivateKey(certificate.PrivateKey)
+	if err != nil {
+		panic(err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key})
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters["prod"] = &clientcmdapi.Cluster{Server: apiServer.URL, CertificateAuthorityData: certPEM}
+	config.AuthInfos["prod-admin"] = &clientcmdapi.AuthInfo{Token: "golden-token"}
+	config.Contexts["prod-admin@prod"] = &clientcmdapi.Context{Cluster: "prod", AuthInfo: "prod-admin"}
+	config.CurrentContext = "prod-admin@prod"
+	kubeconfig, err := clientcmd.Write(*config)
+	if err != nil {
+		panic(err)
+	}
+
+	secret := func(name string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "org-acme",
+				Name:              name,
+				Labels:            map[string]string{clusterv1.ClusterNameLabel: "prod"},
+				CreationTimestamp: goldenTime,
+			},
+			Data: data,
+		}
+	}
+	return []runtime.Object{
+		secret("prod-kubeconfig", map[string][]byte{"value": kubeconfig}),
+		secret("prod-ca", map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM}),
+	}
+}
+
+// goldenNodes return the nodes of the machines of prod
+func goldenNodes() []runtime.Object {
+	node := func(name string, labels map[string]string) *corev1.Node {
+		n := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, CreationTimestamp: goldenTime}}
+		n.Spec.ProviderID = "docker:////" + name
+		resources := corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("4"),
+			corev1.ResourceMemory: resource.MustParse("8Gi"),
+			corev1.ResourcePods:   resource.MustParse("110"),
+		}
+		n.Status.Capacity, n.Status.Allocatable = resources, resources
+		n.Status.Conditions = []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: corev1.ConditionTrue, Reason: "KubeletReady", LastTransitionTime: goldenTime, LastHeartbeatTime: goldenTime},
+			{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse, LastTransitionTime: goldenTime, LastHeartbeatTime: goldenTime},
+			{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse, LastTransitionTime: goldenTime, LastHeartbeatTime: goldenTime},
+		}
+		n.Status.Addresses = []corev1.NodeAddress{{Type: corev1.NodeHostName, Address: name}}
+		n.Status.NodeInfo = corev1.NodeSystemInfo{
+			KubeletVersion:          "v1.30.2",
+			KubeProxyVersion:        "v1.30.2",
+			OSImage:                 "Ubuntu 22.04.4 LTS",
+			KernelVersion:           "6.5.0",
+			ContainerRuntimeVersion: "containerd://1.7.18",
+			OperatingSystem:         "linux",
+			Architecture:            "amd64",
+		}
+		return n
+	}
+	return []runtime.Object{
+		node("prod-control-plane-x1", map[string]string{"node-role.kubernetes.io/control-plane": ""}),
+		node("prod-md-0-w1", nil),
+	}
+}
+
+// goldenAutoscalerStatus is the status of an idle cluster-autoscaler of prod
+const goldenAutoscalerStatus = `time: 2026-10-16 09:12:40.713420 +0000 UTC
+autoscalerStatus: Running
+clusterWide:
+  health:
+    status: Healthy
+    nodeCounts:
+      registered:
+        total: 1
+        ready: 1
+  scaleUp:
+    status: NoActivity
+  scaleDown:
+    status: NoCandidates
+nodeGroups:
+- name: MachineDeployment/org-acme/prod-md-0
+  health:
+    status: Healthy
+    nodeCounts:
+      registered:
+        total: 1
+        ready: 1
+    cloudProviderTarget: 1
+    minSize: 1
+    maxSize: 3
+  scaleUp:
+    status: NoActivity
+  scaleDown:
+    status: NoCandidates
+`
+
+// goldenWorkloadFixtures return the nodes of prod and their metrics, with a
+// deployment whose PodDisruptionBudget blocks draining the worker, DaemonSet
+// pods and the status of cluster-autoscaler
+func goldenWorkloadFixtures() []runtime.Object {
+	pod := func(namespace, name, node string, owner metav1.OwnerReference, labels map[string]string) *corev1.Pod {
+		p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			Labels:            labels,
+			CreationTimestamp: goldenTime,
+			OwnerReferences:   []metav1.OwnerReference{owner},
+		}}
+		p.Spec.NodeName = node
+		p.Spec.Containers = []corev1.Container{{
+			Name:  strings.Split(name, "-")[0],
+			Image: "registry.k8s.io/pause:3.9",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			}},
+		}}
+		p.Status.Phase = corev1.PodRunning
+		return p
+	}
+	replicaSet := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-5d4f8", UID: "uid-web-5d4f8", Controller: ptr.To(true)}
+	daemonSet := metav1.OwnerReference{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "kube-proxy", UID: "uid-kube-proxy", Controller: ptr.To(true)}
+
+	budget := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", CreationTimestamp: goldenTime}}
+	budget.Spec.MinAvailable = ptr.To(intstr.FromInt32(1))
+	budget.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	budget.Status.CurrentHealthy, budget.Status.DesiredHealthy, budget.Status.ExpectedPods = 1, 1, 1
+
+	autoscalerStatus := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "cluster-autoscaler-status", CreationTimestamp: goldenTime},
+		Data:       map[string]string{"status": goldenAutoscalerStatus},
+	}
+	nodeMetrics := func(name, cpu, memory string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{Object: map[string]any{
+			"usage": map[string]any{"cpu": cpu, "memory": memory},
+		}}
+		u.SetAPIVersion("metrics.k8s.io/v1beta1")
+		u.SetKind("NodeMetrics")
+		u.SetName(name)
+		return u
+	}
+
+	return append(goldenNodes(),
+		autoscalerStatus,
+		nodeMetrics("prod-control-plane-x1", "412m", "2Gi"),
+		nodeMetrics("prod-md-0-w1", "1200m", "3Gi"),
+		pod("default", "web-5d4f8-k2x9p", "prod-md-0-w1", replicaSet, map[string]string{"app": "web"}),
+		pod("kube-system", "kube-proxy-7hq4n", "prod-md-0-w1", daemonSet, map[string]string{"k8s-app": "kube-proxy"}),
+		pod("kube-system", "kube-proxy-m3v8d", "prod-control-plane-x1", daemonSet, map[string]string{"k8s-app": "kube-proxy"}),
+		budget,
+	)
+}
+
+// goldenRepository serves canned provider releases, cluster templates and CNI
+// manifests instead of downloading them from GitHub
+type goldenRepository struct{}
+
+func (goldenRepository) LatestVersion(ctx context.Context, provider string) (string, error) {
+	return "v1.10.0", nil
+}
+
+func (goldenRepository) Components(ctx context.Context, provider, version string) ([]byte, error) {
+	return []byte(fmt.Sprintf(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: capi-%s-system
+`, strings.TrimPrefix(provider, "infrastructure-"))), nil
+}
+
+func (goldenRepository) ClusterTemplate(ctx context.Context, provider, version, flavor string) ([]byte, error) {
+	return []byte(`apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: ${CLUSTER_NAME}
+  namespace: ${NAMESPACE}
+spec:
+  clusterNetwork:
+    pods:
+      cidrBlocks: ["${POD_CIDR:=192.168.0.0/16}"]
+`), nil
+}
+
+func (goldenRepository) CNIManifest(ctx context.Context, cni capi.CNI, version string) ([]byte, error) {
+	return []byte(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: calico-system
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: calico-node
+  namespace: calico-system
+spec:
+  selector:
+    matchLabels:
+      k8s-app: calico-node
+  template:
+    metadata:
+      labels:
+        k8s-app: calico-node
+    spec:
+      containers:
+      - name: calico-node
+        image: docker.io/calico/node:` + version + `
+`), nil
+}
+
+// goldenStore is an initially empty backup store in memory
+type goldenStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *goldenStore) Put(ctx context.Context, key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return nil
+}
+
+func (s *goldenStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, backup.ErrNotFound
+	}
+	return data, nil
+}
+
+func (s *goldenStore) List(ctx context.Context, prefix string) ([]backup.StoredObject, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var objects []backup.StoredObject
+	for key, data := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, backup.StoredObject{Key: key, Size: int64(len(data))})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+func (s *goldenStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *goldenStore) Location() string {
+	return "memory://golden/"
+}
+
+// handlerRecorder is a Registry keeping the handlers of registered tools
+type handlerRecorder map[string]server.ToolHandlerFunc
+
+func (r handlerRecorder) AddTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	r[tool.Name] = handler
+}
+
+// goldenServerContext serves the golden fixtures from a fresh fake
+func goldenServerContext(t *testing.T, apiServer *httptest.Server) *ServerContext {
+	t.Helper()
+	fake := capitest.NewFake(goldenFixtures(apiServer)...)
+	fake.ServeWorkload(goldenWorkloadFixtures()...)
+	pool, err := capi.NewClientPool(fake.Client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canaries, err := fleet.NewCanaryStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &ServerContext{
+		Clients:                  pool,
+		Client:                   fake,
+		Approvals:                approval.NewManager(approval.Config{}),
+		AuditLog:                 audit.NewLogger(100),
+		Jobs:                     jobs.NewManager(jobs.Config{}),
+		Canaries:                 canaries,
+		Schedules:                schedule.NewStore(fake.Kube, "mcp-capi"),
+		Providers:                goldenRepository{},
+		BackupTargets:            backup.NewTargets(backup.NewTarget("golden", &goldenStore{objects: map[string][]byte{}}, backup.Retention{})),
+		DefaultKubernetesVersion: "v1.30.2",
+		ServerVersion:            "v0.0.0-golden",
+	}
+}
+
+// volatileOutput matches the parts of results that change between runs, such
+// as generated identifiers and the durations since the fixture times
+var volatileOutput = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b20[0-9]{2}-[0-9]{2}-[0-9]{2}T[0-9:.]+(Z|[+-][0-9:]+)`), "<time>"},
+	{regexp.MustCompile(`\b20[0-9]{2}-[0-9]{2}-[0-9]{2} [0-9:]+ [+-][0-9]{4} \w+`), "<time>"},
+	{regexp.MustCompile(`\b20[0-9]{12}\b`), "<timestamp>"},
+	{regexp.MustCompile(`(client-(certificate|key)-data: )[A-Za-z0-9+/=]+`), "$1<generated>"},
+	{regexp.MustCompile(`\b20[0-9]{6}T[0-9]{6}Z\b`), "<timestamp>"},
+	{regexp.MustCompile(`\b[0-9a-f]{16}\b`), "<job>"},
+	{regexp.MustCompile(`\bschedule-[0-9a-f]{8}\b`), "schedule-<id>"},
+	// A lone integer of minutes is rather millicores of CPU
+	{regexp.MustCompile(`\b(([0-9]+(\.[0-9]+)?(h|m|s|ms|µs|d)){2,}|[0-9]+(\.[0-9]+)?(h|s|ms|µs|d)|[0-9]+\.[0-9]+m)\b`), "<duration>"},
+	{regexp.MustCompile(`("\w*(Latency|elapsed)"): [0-9]+`), `$1: "<duration>"`},
+}
+
+// formatGoldenResult renders the text and structured content of a result as
+// compared against the golden files
+func formatGoldenResult(result *mcp.CallToolResult) string {
+	var out strings.Builder
+	if result.IsError {
+		out.WriteString("error: true\n")
+	}
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			out.WriteString(text.Text)
+			out.WriteString("\n")
+		}
+	}
+	if data, ok := structuredResult(result); ok {
+		out.WriteString("--- structured content ---\n")
+		out.Write(data)
+		out.WriteString("\n")
+	}
+	text := out.String()
+	for _, volatile := range volatileOutput {
+		text = volatile.pattern.ReplaceAllString(text, volatile.replacement)
+	}
+	return text
+}
+
+// TestGoldenResults calls every tool against the fake CAPI client and
+// compares the text and structured results with the golden files
+func TestGoldenResults(t *testing.T) {
+	// Kubeconfig contexts come from the fixture, not the developer's files
+	t.Setenv("KUBECONFIG", filepath.Join("testdata", "kubeconfig.yaml"))
+
+	// The API server of prod serves TLS with a certificate of its own CA
+	apiServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer apiServer.Close()
+	address := apiServer.Listener.Addr().(*net.TCPAddr)
+
+	recorder := &toolRecorder{t: t, tools: map[string]mcp.Tool{}}
+	RegisterAll(recorder, &ServerContext{})
+	covered := map[string]bool{}
+	for _, c := range goldenCases {
+		tool, ok := recorder.tools[c.tool]
+		if !ok {
+			t.Errorf("golden case %s calls %s, which is not registered", c.file(), c.tool)
+		}
+		for argument := range c.arguments {
+			if _, ok := tool.InputSchema.Properties[argument]; !ok {
+				t.Errorf("golden case %s passes %s, which %s does not take", c.file(), argument, c.tool)
+			}
+		}
+		covered[c.tool] = true
+	}
+	var missing []string
+	for name := range recorder.tools {
+		if !covered[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		t.Errorf("tools without golden cases: %s", strings.Join(missing, ", "))
+	}
+
+	for _, c := range goldenCases {
+		t.Run(strings.TrimSuffix(filepath.Base(c.file()), ".golden"), func(t *testing.T) {
+			serverCtx := goldenServerContext(t, apiServer)
+			arguments := map[string]any{}
+			for name, value := range c.arguments {
+				arguments[name] = value
+			}
+			if c.seed != nil {
+				for name, value := range c.seed(t, serverCtx) {
+					if _, ok := recorder.tools[c.tool].InputSchema.Properties[name]; ok {
+						arguments[name] = value
+					}
+				}
+			}
+			handlers := handlerRecorder{}
+			RegisterAll(handlers, serverCtx)
+			handler, ok := handlers[c.tool]
+			if !ok {
+				t.Skipf("%s is not registered", c.tool)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			result, err := handler(ctx, mcp.CallToolRequest{Params: mcp.CallToolParams{Name: c.tool, Arguments: arguments}})
+			if err != nil {
+				t.Fatalf("%s returned a transport error: %v", c.tool, err)
+			}
+			got := strings.ReplaceAll(formatGoldenResult(result), address.String(), "<api-server>")
+
+			if *updateGolden {
+				if err := os.MkdirAll(filepath.Dir(c.file()), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(c.file(), []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(c.file())
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("result of %s differs from %s (run with -update to accept it)\n--- got ---\n%s\n--- want ---\n%s", c.tool, c.file(), got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/auth"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// identityProviders are the providers whose identities the tools validate
+var identityProviders = []string{string(capi.ProviderAWS), string(capi.ProviderAzure), string(capi.ProviderGCP), string(capi.ProviderVSphere)}
+
+// registerIdentityTools adds the cloud credential validation tools
+func registerIdentityTools(s Registry, serverCtx *ServerContext) {
+	listIdentitiesTool := listIdentitiesParams.NewTool(
+		"capi_list_identities",
+		"List the provider identities (AWSClusterStaticIdentity, AWSClusterRoleIdentity, AWSClusterControllerIdentity, AzureClusterIdentity, VSphereClusterIdentity) with their credentials Secret, allowed namespaces, the clusters using them and whether they are valid",
+	)
+	addTool(s, listIdentitiesTool, createListIdentitiesHandler(serverCtx))
+
+	validateIdentitiesTool := validateIdentitiesParams.NewTool(
+		"capi_validate_identities",
+		"Check that clusters reference an existing identity or credentials Secret that their namespace may use, a top cause of clusters that silently never provision",
+	)
+	addTool(s, validateIdentitiesTool, createValidateIdentitiesHandler(serverCtx))
+
+	prepareNamespaceTool := prepareNamespaceParams.NewTool(
+		"capi_prepare_namespace",
+		"Prepare a namespace for workload clusters so capi_create_cluster does not fail on missing prerequisites: create it if missing, set the Giant Swarm organization and other labels, copy credentials Secrets into it, and report whether it is ready, including the provider identities it may use",
+		withApprovalID(),
+	)
+	addTool(s, prepareNamespaceTool, createPrepareNamespaceHandler(serverCtx))
+}
+
+// listIdentitiesParams declares the arguments of capi_list_identities
+var listIdentitiesParams = params.Schema{
+	{Name: "provider", Type: params.String, Enum: identityProviders,
+		Description: "Only list the identities of this provider (optional)"},
+}
+
+// validateIdentitiesParams declares the arguments of capi_validate_identities
+var validateIdentitiesParams = params.Schema{
+	{Name: "provider", Type: params.String, Enum: identityProviders,
+		Description: "Only check the clusters of this provider (optional)"},
+	{Name: "namespace", Type: params.String,
+		Description: "Only check the clusters of this namespace (optional)"},
+	{Name: "invalid_only", Type: params.Bool, Default: false,
+		Description: "Only show clusters with credential issues"},
+}
+
+// prepareNamespaceParams declares the arguments of capi_prepare_namespace
+var prepareNamespaceParams = params.Schema{
+	{Name: "namespace", Type: params.String, Validate: params.Namespace,
+		Description: "Namespace to prepare (default: org-<organization>)"},
+	{Name: "organization", Type: params.String,
+		Description: "Giant Swarm organization owning the namespace, set as the giantswarm.io/organization label (optional)"},
+	{Name: "labels", Type: params.StringMap, Description: "Other labels to set on the namespace (optional)"},
+	{Name: "copy_secrets", Type: params.String,
+		Description: "Comma-separated namespace/name of Secrets to copy into the namespace, such as provider credentials; existing Secrets are never overwritten (optional)"},
+	{Name: "provider", Type: params.String, Enum: capi.ProviderNames(),
+		Description: "Infrastructure provider whose identities must allow the namespace for it to be ready (optional)"},
+}
+
+// createListIdentitiesHandler lists the provider identities
+func createListIdentitiesHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := listIdentitiesParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+
+		report, err := serverCtx.client(ctx).ValidateIdentities(ctx, capi.Provider(args.String("provider")), "")
+		if err != nil {
+			return toolError(fmt.Errorf("failed to validate identities: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Provider Identities (%d):\n\n", len(report.Identities)))
+		for _, identity := range report.Identities {
+			status := "✅ valid"
+			if !identity.Valid {
+				status = "❌ invalid"
+			}
+			content.WriteString(fmt.Sprintf("- %s %s: %s\n", identity.Kind, strings.TrimPrefix(identity.Namespace+"/"+identity.Name, "/"), status))
+			if identity.Type != "" {
+				content.WriteString(fmt.Sprintf("  Type: %s\n", identity.Type))
+			}
+			if identity.Secret != "" {
+				content.WriteString(fmt.Sprintf("  Secret: %s\n", identity.Secret))
+			}
+			if identity.SourceIdentity != "" {
+				content.WriteString(fmt.Sprintf("  Source Identity: %s\n", identity.SourceIdentity))
+			}
+			content.WriteString(fmt.Sprintf("  Allowed Namespaces: %s\n", identity.AllowedNamespaces))
+			if len(identity.UsedBy) > 0 {
+				content.WriteString(fmt.Sprintf("  Used by: %s\n", strings.Join(identity.UsedBy, ", ")))
+			}
+			for _, issue := range identity.Issues {
+				content.WriteString(fmt.Sprintf("  ⚠️  %s\n", issue))
+			}
+		}
+		if len(report.Identities) == 0 {
+			content.WriteString("No provider identities found.\n")
+		}
+
+		return newToolResult(content.String(), map[string]any{"identities": report.Identities})
+	}
+}
+
+// createValidateIdentitiesHandler checks the credentials of clusters
+func createValidateIdentitiesHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := validateIdentitiesParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+
+		report, err := serverCtx.client(ctx).ValidateIdentities(ctx, capi.Provider(args.String("provider")), args.String("namespace"))
+		if err != nil {
+			return toolError(fmt.Errorf("failed to validate identities: %w", err))
+		}
+
+		checks := []capi.ClusterIdentityCheck{}
+		invalid := 0
+		for _, check := range report.Clusters {
+			if !check.Valid {
+				invalid++
+			} else if args.Bool("invalid_only") {
+				continue
+			}
+			checks = append(checks, check)
+		}
+
+		var content strings.Builder
+		if invalid == 0 {
+			content.WriteString(fmt.Sprintf("The credentials of all %d clusters are valid.\n\n", len(report.Clusters)))
+		} else {
+			content.WriteString(fmt.Sprintf("%d of %d clusters have credential issues:\n\n", invalid, len(report.Clusters)))
+		}
+		for _, check := range checks {
+			status := "✅"
+			if !check.Valid {
+				status = "❌"
+			}
+			content.WriteString(fmt.Sprintf("%s %s/%s (%s): %s\n", status, check.Namespace, check.Name, check.Provider, summaryValue(check.Identity)))
+			for _, issue := range check.Issues {
+				content.WriteString(fmt.Sprintf("  - %s\n", issue))
+			}
+		}
+
+		return newToolResult(content.String(), map[string]any{"valid": invalid == 0, "clusters": checks})
+	}
+}
+
+// createPrepareNamespaceHandler prepares the namespace of new clusters
+func createPrepareNamespaceHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := prepareNamespaceParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		secrets := splitList(args.String("copy_secrets"))
+		// Restricted identities may only copy Secrets out of their namespaces
+		if identity, ok := auth.IdentityFromContext(ctx); ok && identity.Policy.Restricted() {
+			for _, secret := range secrets {
+				if namespace, _, _ := strings.Cut(secret, "/"); !identity.Policy.AllowsNamespace(namespace) {
+					return toolError(fmt.Errorf("%w: %s may not read Secrets of namespace %s", capi.ErrForbidden, identity.Name, namespace))
+				}
+			}
+		}
+
+		result, err := serverCtx.client(ctx).PrepareNamespace(ctx, capi.PrepareNamespaceOptions{
+			Namespace:    args.String("namespace"),
+			Organization: args.String("organization"),
+			Labels:       args.StringMap("labels"),
+			Secrets:      secrets,
+			Provider:     capi.Provider(args.String("provider")),
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to prepare namespace: %w", err))
+		}
+
+		var content strings.Builder
+		if result.Ready {
+			content.WriteString(fmt.Sprintf("✅ Namespace %s is ready for clusters\n\n", result.Namespace))
+		} else {
+			content.WriteString(fmt.Sprintf("❌ Namespace %s is not ready for clusters\n\n", result.Namespace))
+		}
+		if result.Created {
+			content.WriteString("Created the namespace\n")
+		}
+		for _, key := range slices.Sorted(maps.Keys(result.Labels)) {
+			content.WriteString(fmt.Sprintf("Labeled %s=%s\n", key, result.Labels[key]))
+		}
+		for _, secret := range result.Secrets {
+			content.WriteString(fmt.Sprintf("Secret %s from %s: %s\n", secret.Name, secret.Source, secret.Result))
+		}
+		if len(result.Identities) > 0 {
+			content.WriteString(fmt.Sprintf("Usable identities: %s\n", strings.Join(result.Identities, ", ")))
+		}
+		for _, issue := range result.Issues {
+			content.WriteString(fmt.Sprintf("⚠️  %s\n", issue))
+		}
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "prepare",
+			Resource:  resourceRef{Kind: "Namespace", Name: result.Namespace},
+			Details:   map[string]any{"namespace": result},
+		})
+	}
+}
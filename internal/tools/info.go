@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// serverName is the name the server reports in capi_server_info
+const serverName = "mcp-capi"
+
+// registerInfoTools adds the tool describing the server deployment
+func registerInfoTools(s Registry, serverCtx *ServerContext) {
+	serverInfoTool := mcp.NewTool(
+		"capi_server_info",
+		mcp.WithDescription("Describe this server deployment: its version, the tool groups enabled by the tool policy, and the Kubernetes version, Cluster API contract and release and installed providers of the management cluster"),
+	)
+	addTool(s, serverInfoTool, createServerInfoHandler(serverCtx))
+}
+
+// toolGroupInfo counts the tools of a tool group enabled by the tool policy
+type toolGroupInfo struct {
+	Name    string `json:"name"`
+	Enabled int    `json:"enabled"`
+	Total   int    `json:"total"`
+}
+
+// serverInfo is the structured result of capi_server_info
+type serverInfo struct {
+	Name       string          `json:"name"`
+	Version    string          `json:"version"`
+	ToolGroups []toolGroupInfo `json:"toolGroups"`
+	// ManagementCluster is nil when it could not be reached
+	ManagementCluster *capi.ManagementClusterInfo `json:"managementCluster,omitempty"`
+	Error             string                      `json:"error,omitempty"`
+}
+
+// createServerInfoHandler creates a handler describing the server and its
+// management cluster
+func createServerInfoHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		info := serverInfo{
+			Name:       serverName,
+			Version:    serverCtx.ServerVersion,
+			ToolGroups: enabledToolGroups(serverCtx),
+		}
+		managementCluster, err := serverCtx.client(ctx).ManagementClusterInfo(ctx)
+		if err != nil {
+			info.Error = err.Error()
+		}
+		info.ManagementCluster = managementCluster
+		return newToolResult(formatServerInfo(&info), info)
+	}
+}
+
+// enabledToolGroups counts the tools of each group, and how many of them
+// the tool policy enables
+func enabledToolGroups(serverCtx *ServerContext) []toolGroupInfo {
+	groups := map[string]*toolGroupInfo{}
+	for name := range toolPermissions {
+		enabled := serverCtx.ToolPolicy.Enabled(name, toolGroups(name))
+		for _, group := range toolGroups(name) {
+			info, ok := groups[group]
+			if !ok {
+				info = &toolGroupInfo{Name: group}
+				groups[group] = info
+			}
+			info.Total++
+			if enabled {
+				info.Enabled++
+			}
+		}
+	}
+	result := make([]toolGroupInfo, 0, len(groups))
+	for _, info := range groups {
+		result = append(result, *info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// formatServerInfo renders the server information for display
+func formatServerInfo(info *serverInfo) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Server: %s %s\n", info.Name, summaryValue(info.Version)))
+
+	content.WriteString("\nTool groups (enabled/total):\n")
+	for _, group := range info.ToolGroups {
+		content.WriteString(fmt.Sprintf("  - %s: %d/%d\n", group.Name, group.Enabled, group.Total))
+	}
+
+	content.WriteString("\nManagement cluster:\n")
+	if info.ManagementCluster == nil {
+		content.WriteString(fmt.Sprintf("  ❌ %s\n", info.Error))
+		return content.String()
+	}
+	mc := info.ManagementCluster
+	content.WriteString(fmt.Sprintf("  Kubernetes: %s", mc.KubernetesVersion))
+	if mc.Platform != "" {
+		content.WriteString(fmt.Sprintf(" (%s)", mc.Platform))
+	}
+	content.WriteString("\n")
+	if mc.Contract != nil {
+		content.WriteString(fmt.Sprintf("  Cluster API contract: %s (served: %s)\n", mc.Contract.Version, strings.Join(mc.Contract.Served, ", ")))
+	}
+	content.WriteString(fmt.Sprintf("  Cluster API version: %s\n", summaryValue(mc.ClusterAPIVersion)))
+	if len(mc.Providers) > 0 {
+		content.WriteString("  Providers:\n")
+		for _, provider := range mc.Providers {
+			content.WriteString(fmt.Sprintf("    - %s (%s) %s\n", provider.Name, provider.Type, summaryValue(provider.Version)))
+		}
+	}
+	content.WriteString(fmt.Sprintf("  Retried requests: %d (%d failed after all retries)\n", mc.Retries.Retries, mc.Retries.Exhausted))
+	for _, warning := range mc.Warnings {
+		content.WriteString(fmt.Sprintf("  ⚠️  %s\n", warning))
+	}
+	return content.String()
+}
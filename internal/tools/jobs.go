@@ -0,0 +1,206 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/auth"
+	"github.com/giantswarm/mcp-capi/internal/jobs"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// asyncArgument makes a long-running tool return a job ID instead of waiting
+	asyncArgument = "async"
+	// jobCancelTimeout bounds how long capi_job_cancel waits for the job to stop
+	jobCancelTimeout = 10 * time.Second
+)
+
+// registerJobTools adds the tools that follow up on background jobs
+func registerJobTools(s Registry, serverCtx *ServerContext) {
+	jobStatusTool := mcp.NewTool(
+		"capi_job_status",
+		mcp.WithDescription("Show the status and result of a background job, or list all jobs"),
+		mcp.WithString("job_id",
+			mcp.Description("ID of the job (optional, lists all jobs if omitted)"),
+		),
+	)
+	addTool(s, jobStatusTool, createJobStatusHandler(serverCtx))
+
+	jobLogsTool := mcp.NewTool(
+		"capi_job_logs",
+		mcp.WithDescription("Show the progress log of a background job"),
+		mcp.WithString("job_id",
+			mcp.Required(),
+			mcp.Description("ID of the job"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("Skip this many log lines, e.g. the logLines of an earlier call, to only show new ones (default: 0)"),
+		),
+	)
+	addTool(s, jobLogsTool, createJobLogsHandler(serverCtx))
+
+	jobCancelTool := mcp.NewTool(
+		"capi_job_cancel",
+		mcp.WithDescription("Cancel a running background job. Changes the job already made to the cluster are not rolled back."),
+		mcp.WithString("job_id",
+			mcp.Required(),
+			mcp.Description("ID of the job"),
+		),
+	)
+	addTool(s, jobCancelTool, createJobCancelHandler(serverCtx))
+}
+
+// asyncParam is the optional async parameter of long-running tools
+var asyncParam = params.Param{
+	Name:        asyncArgument,
+	Type:        params.Bool,
+	Description: "Return immediately with a job ID and follow the operation in the background; check it with capi_job_status and capi_job_logs",
+}
+
+// startJob runs fn as a background job for the caller of a tool
+func (s *ServerContext) startJob(ctx context.Context, operation, namespace, target string, fn jobs.Func) (*jobs.Job, error) {
+	if s.Jobs == nil {
+		return nil, fmt.Errorf("background jobs are not available")
+	}
+	return s.Jobs.Start(ctx, operation, namespace, target, requesterFromContext(ctx), fn)
+}
+
+// jobVisible reports whether the caller may see a job, hiding jobs in
+// namespaces a restricted identity has no access to
+func jobVisible(ctx context.Context, job *jobs.Job) bool {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok || !identity.Policy.Restricted() {
+		return true
+	}
+	return job.Namespace != "" && identity.Policy.AllowsNamespace(job.Namespace)
+}
+
+// getJob returns a job the caller may see
+func (s *ServerContext) getJob(ctx context.Context, id string) (*jobs.Job, error) {
+	if s.Jobs == nil {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	job, err := s.Jobs.Get(id)
+	if err != nil || !jobVisible(ctx, job) {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	return job, nil
+}
+
+// createJobStatusHandler creates a handler for showing background jobs
+func createJobStatusHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		jobID := params.OptionalString(request.GetArguments(), "job_id", "")
+		if jobID != "" {
+			job, err := serverCtx.getJob(ctx, jobID)
+			if err != nil {
+				return toolError(err)
+			}
+			return newToolResult(formatJob(job), job)
+		}
+
+		var all []jobs.Job
+		if serverCtx.Jobs != nil {
+			all = serverCtx.Jobs.List()
+		}
+		var content strings.Builder
+		visible := []jobs.Job{}
+		for i := range all {
+			if !jobVisible(ctx, &all[i]) {
+				continue
+			}
+			visible = append(visible, all[i])
+			content.WriteString(formatJob(&all[i]))
+			content.WriteString("\n")
+		}
+
+		header := fmt.Sprintf("Found %d jobs:\n\n", len(visible))
+		return newToolResult(header+content.String(), map[string]any{"jobs": visible})
+	}
+}
+
+// formatJob describes a job for the text result
+func formatJob(job *jobs.Job) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Job: %s\n", job.ID))
+	content.WriteString(fmt.Sprintf("  Operation: %s %s\n", job.Operation, job.Target))
+	content.WriteString(fmt.Sprintf("  Status: %s\n", job.Status))
+	content.WriteString(fmt.Sprintf("  Started: %s\n", job.CreatedAt.UTC().Format(time.RFC3339)))
+	if job.FinishedAt != nil {
+		content.WriteString(fmt.Sprintf("  Finished: %s\n", job.FinishedAt.UTC().Format(time.RFC3339)))
+	}
+	if job.Error != "" {
+		content.WriteString(fmt.Sprintf("  Error: %s\n", job.Error))
+	}
+	content.WriteString(fmt.Sprintf("  Log lines: %d\n", job.LogLines))
+	return content.String()
+}
+
+// createJobLogsHandler creates a handler for reading the log of a job
+func createJobLogsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		jobID, err := params.RequiredString(arguments, "job_id")
+		if err != nil {
+			return toolError(err)
+		}
+		offset, err := params.OptionalInt(arguments, "offset", 0)
+		if err != nil {
+			return toolError(err)
+		}
+
+		job, err := serverCtx.getJob(ctx, jobID)
+		if err != nil {
+			return toolError(err)
+		}
+		logs, err := serverCtx.Jobs.Logs(jobID, offset)
+		if err != nil {
+			return toolError(err)
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Job %s (%s %s) is %s\n\n", job.ID, job.Operation, job.Target, job.Status))
+		for _, entry := range logs {
+			content.WriteString(fmt.Sprintf("%s %s\n", entry.Time.UTC().Format(time.RFC3339), entry.Message))
+		}
+		if len(logs) == 0 {
+			content.WriteString("No new log lines\n")
+		}
+		return newToolResult(content.String(), map[string]any{
+			"job":      job,
+			"logs":     logs,
+			"logLines": job.LogLines,
+		})
+	}
+}
+
+// createJobCancelHandler creates a handler for canceling a running job
+func createJobCancelHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		jobID, err := params.RequiredString(request.GetArguments(), "job_id")
+		if err != nil {
+			return toolError(err)
+		}
+		if _, err := serverCtx.getJob(ctx, jobID); err != nil {
+			return toolError(err)
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, jobCancelTimeout)
+		defer cancel()
+		job, err := serverCtx.Jobs.Cancel(waitCtx, jobID)
+		if err != nil {
+			return toolError(err)
+		}
+
+		message := fmt.Sprintf("Canceled job %s", job.ID)
+		if !job.Status.Finished() {
+			message = fmt.Sprintf("Requested cancellation of job %s, it is still stopping", job.ID)
+		}
+		return newToolResult(fmt.Sprintf("%s\n\n%s", message, formatJob(job)), job)
+	}
+}
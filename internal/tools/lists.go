@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+)
+
+const (
+	limitArgument    = "limit"
+	continueArgument = "continue"
+	outputArgument   = "output"
+
+	labelSelectorArgument = "label_selector"
+	fieldSelectorArgument = "field_selector"
+
+	// outputSummary renders one line per item instead of the full details
+	outputSummary = "summary"
+)
+
+// selectorParams are the filter arguments shared by the list tools
+var selectorParams = params.Schema{
+	{Name: labelSelectorArgument, Type: params.String, Validate: params.LabelSelector,
+		Description: "Kubernetes label selector, e.g. 'cluster.x-k8s.io/control-plane' or 'team=platform,env!=dev'"},
+	{Name: fieldSelectorArgument, Type: params.String, Validate: params.FieldSelector,
+		Description: "Kubernetes field selector, e.g. 'metadata.name=prod'; CAPI resources support metadata.name and metadata.namespace"},
+}
+
+// listParams are the pagination, filter and output arguments shared by the
+// list tools
+var listParams = append(params.Schema{
+	{Name: limitArgument, Type: params.Int, NonNegative: true,
+		Description: "Maximum number of items to return (default: all). When more items exist, the result holds a continue token."},
+	{Name: continueArgument, Type: params.String,
+		Description: "Continue token of a previous call to fetch the next page; the other arguments must be unchanged"},
+	{Name: outputArgument, Type: params.String, Default: "full", Enum: []string{"full", outputSummary},
+		Description: "Output mode: full details or a compact summary with one line per item (default: full)"},
+}, selectorParams...)
+
+// listOptions returns the client options for the pagination and filter
+// arguments
+func listOptions(args params.Values) []capi.ListOption {
+	return append(selectorOptions(args),
+		capi.WithLimit(int64(args.Int(limitArgument))),
+		capi.WithContinue(args.String(continueArgument)),
+	)
+}
+
+// selectorOptions returns the client options for the filter arguments
+func selectorOptions(args params.Values) []capi.ListOption {
+	return []capi.ListOption{
+		capi.WithLabelSelector(args.String(labelSelectorArgument)),
+		capi.WithFieldSelector(args.String(fieldSelectorArgument)),
+	}
+}
+
+// summaryOutput reports whether a list tool was asked for a compact summary
+func summaryOutput(args params.Values) bool {
+	return args.String(outputArgument) == outputSummary
+}
+
+// continueHint tells how to fetch the next page of a list, if there is one
+func continueHint(token string) string {
+	if token == "" {
+		return ""
+	}
+	return fmt.Sprintf("\nMore items are available. Call again with continue=%q to fetch the next page.\n", token)
+}
+
+// withContinue adds the continue token to the structured result of a list
+func withContinue(data map[string]any, token string) map[string]any {
+	if token != "" {
+		data[continueArgument] = token
+	}
+	return data
+}
+
+// summaryValue renders an optional value in a summary line
+func summaryValue(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/giantswarm/mcp-capi/internal/locks"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// NewClusterLockMiddleware locks the clusters a tool call changes, the
+// clusters of maintenanceTargets, for the duration of the call, so
+// concurrent calls changing the same cluster are serialized or rejected.
+// Previews and dry-runs change nothing and are not locked.
+func NewClusterLockMiddleware(pool *capi.ClientPool, manager *locks.Manager) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			resolve, ok := maintenanceTargets[request.Params.Name]
+			arguments := request.GetArguments()
+			if dryRun, _ := arguments["dry_run"].(bool); !ok || dryRun || isPreview(request.Params.Name, arguments) {
+				return next(ctx, request)
+			}
+
+			managementCluster := params.OptionalString(arguments, managementClusterArgument, "")
+			c, err := pool.Get(managementCluster, params.OptionalString(arguments, kubeconfigContextArgument, ""))
+			if err != nil {
+				return toolError(err)
+			}
+			clusters, err := resolve(ctx, c, arguments)
+			if errors.Is(err, capi.ErrNotFound) {
+				// The tool reports missing resources itself
+				return next(ctx, request)
+			}
+			if err != nil {
+				return toolError(fmt.Errorf("failed to find the clusters to lock: %w", err))
+			}
+
+			targets := make([]locks.Target, 0, len(clusters))
+			for _, cluster := range clusters {
+				targets = append(targets, locks.Target{ManagementCluster: managementCluster, Namespace: cluster.Namespace, Name: cluster.Name})
+			}
+			operation := fmt.Sprintf("%s by %s", request.Params.Name, requesterFromContext(ctx))
+			release, err := manager.Acquire(ctx, c, targets, operation)
+			if err != nil {
+				return toolError(err)
+			}
+			defer release()
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/logging"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// clusterArguments are the arguments naming the workload cluster of a tool
+// call, in order of preference
+var clusterArguments = []string{"clusterName", "cluster_name", "cluster"}
+
+// NewLoggingMiddleware attaches the tool, the caller and the cluster of each
+// tool call to the records logged with its context, and logs the call:
+// failures at warn level, everything else at debug level
+func NewLoggingMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx = logging.With(ctx, requestAttrs(ctx, request)...)
+
+			start := time.Now()
+			result, err := next(ctx, request)
+			duration := slog.Duration("duration", time.Since(start))
+			switch {
+			case err != nil:
+				slog.WarnContext(ctx, "Tool call failed", duration, slog.Any("error", err))
+			case result != nil && result.IsError:
+				slog.WarnContext(ctx, "Tool call failed", duration, slog.String("error", summarizeResult(result)))
+			default:
+				slog.DebugContext(ctx, "Tool call completed", duration)
+			}
+			return result, err
+		}
+	}
+}
+
+// requestAttrs returns the log attributes identifying a tool call
+func requestAttrs(ctx context.Context, request mcp.CallToolRequest) []slog.Attr {
+	arguments := request.GetArguments()
+	attrs := []slog.Attr{slog.String("tool", request.Params.Name)}
+	if caller := requesterFromContext(ctx); caller != "" {
+		attrs = append(attrs, slog.String("caller", caller))
+	}
+	if managementCluster := params.OptionalString(arguments, managementClusterArgument, ""); managementCluster != "" {
+		attrs = append(attrs, slog.String("management_cluster", managementCluster))
+	}
+	if namespace := params.OptionalString(arguments, "namespace", ""); namespace != "" {
+		attrs = append(attrs, slog.String("namespace", namespace))
+	}
+	for _, name := range clusterArguments {
+		if cluster := params.OptionalString(arguments, name, ""); cluster != "" {
+			attrs = append(attrs, slog.String("cluster", cluster))
+			break
+		}
+	}
+	if name := params.OptionalString(arguments, "name", ""); name != "" {
+		attrs = append(attrs, slog.String("name", name))
+	}
+	return attrs
+}
@@ -0,0 +1,1288 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	v1 "k8s.io/api/core/v1"
+)
+
+// registerMachineTools adds the Machine, MachineDeployment and MachineSet tools
+func registerMachineTools(s Registry, serverCtx *ServerContext) {
+	// Add CAPI list machines tool
+	listMachinesTool := listMachinesParams.NewTool(
+		"capi_list_machines",
+		"List CAPI machines with optional filtering by cluster",
+	)
+
+	addTool(s, listMachinesTool, createListMachinesHandler(serverCtx))
+
+	// Add CAPI list machine deployments tool
+	listMachineDeploymentsTool := listMachineDeploymentsParams.NewTool(
+		"capi_list_machinedeployments",
+		"List CAPI machine deployments (worker node pools)",
+	)
+
+	addTool(s, listMachineDeploymentsTool, createListMachineDeploymentsHandler(serverCtx))
+
+	// Add CAPI create machine deployment tool
+	createMachineDeploymentTool := mcp.NewTool(
+		"capi_create_machinedeployment",
+		mcp.WithDescription("Create a new worker node pool (MachineDeployment)"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace for the machine deployment"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the machine deployment"),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Required(),
+			mcp.Description("Name of the cluster this deployment belongs to"),
+		),
+		mcp.WithNumber("replicas",
+			mcp.Description("Number of replicas (default: 1)"),
+		),
+		mcp.WithString("version",
+			mcp.Description("Kubernetes version (e.g., v1.29.0, default: the control plane version of the cluster)"),
+		),
+		mcp.WithString("infra_kind",
+			mcp.Required(),
+			mcp.Description("Kind of infrastructure template (e.g., AWSMachineTemplate)"),
+		),
+		mcp.WithString("infra_name",
+			mcp.Required(),
+			mcp.Description("Name of infrastructure template"),
+		),
+		mcp.WithString("infra_api_version",
+			mcp.Description("API version of infrastructure template"),
+		),
+		mcp.WithString("bootstrap_kind",
+			mcp.Required(),
+			mcp.Description("Kind of bootstrap config (e.g., KubeadmConfigTemplate)"),
+		),
+		mcp.WithString("bootstrap_name",
+			mcp.Required(),
+			mcp.Description("Name of bootstrap config template"),
+		),
+		mcp.WithString("bootstrap_api_version",
+			mcp.Description("API version of bootstrap config"),
+		),
+	)
+
+	addTool(s, createMachineDeploymentTool, createCreateMachineDeploymentHandler(serverCtx))
+
+	// Add CAPI scale machine deployment tool
+	scaleMachineDeploymentTool := scaleMachineDeploymentParams.NewTool(
+		"capi_scale_machinedeployment",
+		"Scale worker nodes up or down",
+		withApprovalID(),
+	)
+
+	addTool(s, scaleMachineDeploymentTool, createScaleMachineDeploymentHandler(serverCtx))
+
+	// Add CAPI get machine tool
+	getMachineTool := mcp.NewTool(
+		"capi_get_machine",
+		mcp.WithDescription("Get detailed information about a specific CAPI machine"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the machine"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the machine"),
+		),
+	)
+
+	addTool(s, getMachineTool, createGetMachineHandler(serverCtx))
+
+	// Add CAPI delete machine tool
+	deleteMachineTool := mcp.NewTool(
+		"capi_delete_machine",
+		mcp.WithDescription("Delete a specific CAPI machine"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the machine"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the machine to delete"),
+		),
+		mcp.WithBoolean("force",
+			mcp.Description("Force deletion even if machine is healthy or control plane"),
+		),
+		withApprovalID(),
+	)
+
+	addTool(s, deleteMachineTool, createDeleteMachineHandler(serverCtx))
+
+	// Add CAPI remediate machine tool
+	remediateMachineTool := mcp.NewTool(
+		"capi_remediate_machine",
+		mcp.WithDescription("Trigger machine health check remediation"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the machine"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the machine to remediate"),
+		),
+		withApprovalID(),
+	)
+
+	addTool(s, remediateMachineTool, createRemediateMachineHandler(serverCtx))
+
+	// Add CAPI machine annotation tools
+	setMachineAnnotationTool := setMachineAnnotationParams.NewTool(
+		"capi_set_machine_annotation",
+		"Protect a machine from MachineHealthCheck remediation (skip-remediation) or mark it to be deleted first when its MachineSet scales down (delete-first). Machines without delete-first are removed according to the delete policy of the MachineSet, so marking the machines to remove keeps the others during a scale-down.",
+	)
+
+	addTool(s, setMachineAnnotationTool, createMachineAnnotationHandler(serverCtx, setMachineAnnotationParams, false))
+
+	removeMachineAnnotationTool := removeMachineAnnotationParams.NewTool(
+		"capi_remove_machine_annotation",
+		"Remove the skip-remediation or delete-first annotation of a machine, handing it back to MachineHealthCheck remediation or the delete policy of its MachineSet",
+	)
+
+	addTool(s, removeMachineAnnotationTool, createMachineAnnotationHandler(serverCtx, removeMachineAnnotationParams, true))
+
+	listMachineAnnotationsTool := listMachineAnnotationsParams.NewTool(
+		"capi_list_machine_annotations",
+		"List the machines excluded from MachineHealthCheck remediation or marked to be deleted first on scale-down, with the delete policy of their MachineSet",
+	)
+
+	addTool(s, listMachineAnnotationsTool, createListMachineAnnotationsHandler(serverCtx))
+
+	// Add CAPI update machine deployment tool
+	updateMachineDeploymentTool := mcp.NewTool(
+		"capi_update_machinedeployment",
+		mcp.WithDescription("Update MachineDeployment configuration"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("MachineDeployment namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("MachineDeployment name"),
+		),
+		mcp.WithString("version",
+			mcp.Description("Kubernetes version to update to"),
+		),
+		mcp.WithNumber("replicas",
+			mcp.Description("Number of replicas"),
+		),
+		mcp.WithNumber("min_ready_seconds",
+			mcp.Description("Minimum ready seconds before considering a machine available"),
+		),
+		mcp.WithObject("labels",
+			mcp.Description("Labels to add/update (empty value removes label)"),
+		),
+		mcp.WithObject("annotations",
+			mcp.Description("Annotations to add/update (empty value removes annotation)"),
+		),
+		mcp.WithBoolean("force",
+			mcp.Description("Set the version even if it is a downgrade or violates the kubelet version skew policy (default: false)"),
+		),
+		withApprovalID(),
+	)
+
+	addTool(s, updateMachineDeploymentTool, createUpdateMachineDeploymentHandler(serverCtx))
+
+	// Add CAPI rollout machine deployment tool
+	rolloutMachineDeploymentTool := mcp.NewTool(
+		"capi_rollout_machinedeployment",
+		mcp.WithDescription("Trigger rolling update of MachineDeployment"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("MachineDeployment namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("MachineDeployment name"),
+		),
+		mcp.WithString("reason",
+			mcp.Description("Reason for the rollout"),
+		),
+		withApprovalID(),
+	)
+
+	addTool(s, rolloutMachineDeploymentTool, createRolloutMachineDeploymentHandler(serverCtx))
+
+	updateMachineImageTool := mcp.NewTool(
+		"capi_update_machine_image",
+		mcp.WithDescription("Update the node image (AMI ID, Azure image version, vSphere template or GCP image) of a MachineDeployment or KubeadmControlPlane by cloning its infrastructure template and rolling out the machines. Images whose name carries a Kubernetes version must match the version of the machines."),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the MachineDeployment or control plane"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("MachineDeployment name, or KubeadmControlPlane name with control_plane"),
+		),
+		mcp.WithBoolean("control_plane",
+			mcp.Description("Update the KubeadmControlPlane named name instead of a MachineDeployment (default: false)"),
+		),
+		mcp.WithString("image",
+			mcp.Required(),
+			mcp.Description("New image: AMI ID, Azure image version, vSphere template or GCP image"),
+		),
+		mcp.WithString("template_name",
+			mcp.Description("Name of the new infrastructure template (default: name with a suffix derived from the image)"),
+		),
+		mcp.WithBoolean("force",
+			mcp.Description("Update even if the image is built for another Kubernetes version (default: false)"),
+		),
+		withApprovalID(),
+	)
+
+	addTool(s, updateMachineImageTool, createUpdateMachineImageHandler(serverCtx))
+
+	// Add CAPI list machine sets tool
+	listMachineSetsTool := listMachineSetsParams.NewTool(
+		"capi_list_machinesets",
+		"List CAPI MachineSets",
+	)
+
+	addTool(s, listMachineSetsTool, createListMachineSetsHandler(serverCtx))
+
+	// Add CAPI get machine set tool
+	getMachineSetTool := mcp.NewTool(
+		"capi_get_machineset",
+		mcp.WithDescription("Get detailed MachineSet information"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("MachineSet namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("MachineSet name"),
+		),
+	)
+
+	addTool(s, getMachineSetTool, createGetMachineSetHandler(serverCtx))
+}
+
+// listMachinesParams declares the arguments of capi_list_machines
+var listMachinesParams = append(params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace to list machines from"},
+	{Name: "clusterName", Type: params.String, Description: "Filter machines by cluster name (optional)"},
+}, listParams...)
+
+// listMachineDeploymentsParams declares the arguments of capi_list_machinedeployments
+var listMachineDeploymentsParams = append(params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace to list machine deployments from"},
+	{Name: "clusterName", Type: params.String, Description: "Filter machine deployments by cluster name (optional)"},
+}, listParams...)
+
+// listMachineSetsParams declares the arguments of capi_list_machinesets
+var listMachineSetsParams = append(params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace to list machine sets in"},
+	{Name: "clusterName", Type: params.String, Description: "Filter by cluster name"},
+}, selectorParams...)
+
+// machineAnnotationNames are the annotations of capi_set_machine_annotation
+// and capi_remove_machine_annotation
+var machineAnnotationNames = []string{capi.SkipRemediation, capi.DeleteFirst}
+
+// setMachineAnnotationParams declares the arguments of capi_set_machine_annotation
+var setMachineAnnotationParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Validate: params.Namespace, Description: "Namespace of the machine"},
+	{Name: "name", Type: params.String, Required: true, Validate: params.KubernetesName, Description: "Name of the machine"},
+	{Name: "annotation", Type: params.String, Required: true, Enum: machineAnnotationNames,
+		Description: "skip-remediation sets cluster.x-k8s.io/skip-remediation, delete-first sets cluster.x-k8s.io/delete-machine"},
+	{Name: "reason", Type: params.String, Description: "Why the machine is annotated, stored as the annotation value (optional)"},
+}
+
+// removeMachineAnnotationParams declares the arguments of capi_remove_machine_annotation
+var removeMachineAnnotationParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Validate: params.Namespace, Description: "Namespace of the machine"},
+	{Name: "name", Type: params.String, Required: true, Validate: params.KubernetesName, Description: "Name of the machine"},
+	{Name: "annotation", Type: params.String, Required: true, Enum: machineAnnotationNames, Description: "Annotation to remove: skip-remediation or delete-first"},
+}
+
+// listMachineAnnotationsParams declares the arguments of capi_list_machine_annotations
+var listMachineAnnotationsParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Validate: params.Namespace, Description: "Namespace to list machines from"},
+	{Name: "clusterName", Type: params.String, Description: "Filter machines by cluster name (optional)"},
+}
+
+// machineSummary is the compact form of a machine in list results
+type machineSummary struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Cluster   string `json:"cluster"`
+	Phase     string `json:"phase,omitempty"`
+	Ready     bool   `json:"ready"`
+	Node      string `json:"node,omitempty"`
+}
+
+// machineDeploymentSummary is the compact form of a machine deployment in list results
+type machineDeploymentSummary struct {
+	Name          string `json:"name"`
+	Namespace     string `json:"namespace"`
+	Cluster       string `json:"cluster"`
+	Phase         string `json:"phase,omitempty"`
+	Replicas      int32  `json:"replicas"`
+	ReadyReplicas int32  `json:"readyReplicas"`
+	Version       string `json:"version,omitempty"`
+}
+
+// createListMachinesHandler creates a handler for listing CAPI machines
+func createListMachinesHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := listMachinesParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		clusterName := args.String("clusterName")
+
+		machines, err := serverCtx.client(ctx).ListMachines(ctx, namespace, clusterName, listOptions(args)...)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list machines: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Found %d machines", len(machines.Items)))
+		if clusterName != "" {
+			content.WriteString(fmt.Sprintf(" in cluster %s", clusterName))
+		}
+		content.WriteString(":\n\n")
+
+		if summaryOutput(args) {
+			summaries := make([]machineSummary, 0, len(machines.Items))
+			for _, machine := range machines.Items {
+				summary := machineSummary{
+					Name:      machine.Name,
+					Namespace: machine.Namespace,
+					Cluster:   machine.Spec.ClusterName,
+					Phase:     machine.Status.Phase,
+					Ready:     capi.IsMachineReady(&machine),
+				}
+				if machine.Status.NodeRef != nil {
+					summary.Node = machine.Status.NodeRef.Name
+				}
+				summaries = append(summaries, summary)
+				content.WriteString(fmt.Sprintf("%s/%s  cluster=%s phase=%s ready=%v node=%s\n",
+					summary.Namespace, summary.Name, summary.Cluster, summaryValue(summary.Phase), summary.Ready, summaryValue(summary.Node)))
+			}
+			content.WriteString(continueHint(machines.Continue))
+			return newToolResult(content.String(), withContinue(map[string]any{"machines": summaries}, machines.Continue))
+		}
+
+		for _, machine := range machines.Items {
+			content.WriteString(fmt.Sprintf("Machine: %s/%s\n", machine.Namespace, machine.Name))
+			content.WriteString(fmt.Sprintf("  Cluster: %s\n", machine.Spec.ClusterName))
+			if machine.Status.Phase != "" {
+				content.WriteString(fmt.Sprintf("  Phase: %s\n", machine.Status.Phase))
+			}
+			if machine.Status.NodeRef != nil {
+				content.WriteString(fmt.Sprintf("  Node: %s\n", machine.Status.NodeRef.Name))
+			}
+			if machine.Spec.ProviderID != nil {
+				content.WriteString(fmt.Sprintf("  Provider ID: %s\n", *machine.Spec.ProviderID))
+			}
+			// Check if machine has Ready condition
+			ready := false
+			for _, condition := range machine.Status.Conditions {
+				if condition.Type == "Ready" && condition.Status == "True" {
+					ready = true
+					break
+				}
+			}
+			content.WriteString(fmt.Sprintf("  Ready: %v\n", ready))
+			content.WriteString("\n")
+		}
+
+		content.WriteString(continueHint(machines.Continue))
+
+		return newToolResult(content.String(), withContinue(map[string]any{"machines": trimItems(machines.Items)}, machines.Continue))
+	}
+}
+
+// createListMachineDeploymentsHandler creates a handler for listing CAPI machine deployments
+func createListMachineDeploymentsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := listMachineDeploymentsParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		clusterName := args.String("clusterName")
+
+		mds, err := serverCtx.client(ctx).ListMachineDeployments(ctx, namespace, clusterName, listOptions(args)...)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list machine deployments: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Found %d machine deployments", len(mds.Items)))
+		if clusterName != "" {
+			content.WriteString(fmt.Sprintf(" in cluster %s", clusterName))
+		}
+		content.WriteString(":\n\n")
+
+		if summaryOutput(args) {
+			summaries := make([]machineDeploymentSummary, 0, len(mds.Items))
+			for _, md := range mds.Items {
+				summary := machineDeploymentSummary{
+					Name:          md.Name,
+					Namespace:     md.Namespace,
+					Cluster:       md.Spec.ClusterName,
+					Phase:         md.Status.Phase,
+					ReadyReplicas: md.Status.ReadyReplicas,
+				}
+				if md.Spec.Replicas != nil {
+					summary.Replicas = *md.Spec.Replicas
+				}
+				if md.Spec.Template.Spec.Version != nil {
+					summary.Version = *md.Spec.Template.Spec.Version
+				}
+				summaries = append(summaries, summary)
+				content.WriteString(fmt.Sprintf("%s/%s  cluster=%s phase=%s replicas=%d/%d version=%s\n",
+					summary.Namespace, summary.Name, summary.Cluster, summaryValue(summary.Phase),
+					summary.ReadyReplicas, summary.Replicas, summaryValue(summary.Version)))
+			}
+			content.WriteString(continueHint(mds.Continue))
+			return newToolResult(content.String(), withContinue(map[string]any{"machineDeployments": summaries}, mds.Continue))
+		}
+
+		for _, md := range mds.Items {
+			content.WriteString(fmt.Sprintf("MachineDeployment: %s/%s\n", md.Namespace, md.Name))
+			content.WriteString(fmt.Sprintf("  Cluster: %s\n", md.Spec.ClusterName))
+			content.WriteString(fmt.Sprintf("  Replicas: %d\n", *md.Spec.Replicas))
+			if md.Status.Replicas > 0 {
+				content.WriteString(fmt.Sprintf("  Status: %d ready / %d updated / %d available\n",
+					md.Status.ReadyReplicas,
+					md.Status.UpdatedReplicas,
+					md.Status.AvailableReplicas))
+			}
+			if md.Status.Phase != "" {
+				content.WriteString(fmt.Sprintf("  Phase: %s\n", md.Status.Phase))
+			}
+			if md.Spec.Template.Spec.Version != nil {
+				content.WriteString(fmt.Sprintf("  Kubernetes Version: %s\n", *md.Spec.Template.Spec.Version))
+			}
+			content.WriteString("\n")
+		}
+
+		content.WriteString(continueHint(mds.Continue))
+
+		return newToolResult(content.String(), withContinue(map[string]any{"machineDeployments": trimItems(mds.Items)}, mds.Continue))
+	}
+}
+
+// createGetMachineHandler creates a handler for getting detailed machine information
+func createGetMachineHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		machine, err := serverCtx.client(ctx).GetMachine(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get machine: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Machine: %s/%s\n\n", machine.Namespace, machine.Name))
+
+		// Basic information
+		content.WriteString("Basic Information:\n")
+		content.WriteString(fmt.Sprintf("  Cluster: %s\n", machine.Spec.ClusterName))
+		if machine.Status.Phase != "" {
+			content.WriteString(fmt.Sprintf("  Phase: %s\n", machine.Status.Phase))
+		}
+		if machine.Spec.Version != nil {
+			content.WriteString(fmt.Sprintf("  Kubernetes Version: %s\n", *machine.Spec.Version))
+		}
+		if machine.Spec.ProviderID != nil {
+			content.WriteString(fmt.Sprintf("  Provider ID: %s\n", *machine.Spec.ProviderID))
+		}
+
+		// Node information
+		if machine.Status.NodeRef != nil {
+			content.WriteString(fmt.Sprintf("\nNode Information:\n"))
+			content.WriteString(fmt.Sprintf("  Node Name: %s\n", machine.Status.NodeRef.Name))
+			content.WriteString(fmt.Sprintf("  Node UID: %s\n", machine.Status.NodeRef.UID))
+		}
+
+		// Bootstrap information
+		if machine.Spec.Bootstrap.ConfigRef != nil {
+			content.WriteString(fmt.Sprintf("\nBootstrap:\n"))
+			content.WriteString(fmt.Sprintf("  Kind: %s\n", machine.Spec.Bootstrap.ConfigRef.Kind))
+			content.WriteString(fmt.Sprintf("  Name: %s\n", machine.Spec.Bootstrap.ConfigRef.Name))
+		}
+
+		// Infrastructure information
+		if machine.Spec.InfrastructureRef.Kind != "" {
+			content.WriteString(fmt.Sprintf("\nInfrastructure:\n"))
+			content.WriteString(fmt.Sprintf("  Kind: %s\n", machine.Spec.InfrastructureRef.Kind))
+			content.WriteString(fmt.Sprintf("  Name: %s\n", machine.Spec.InfrastructureRef.Name))
+		}
+
+		// Conditions
+		if len(machine.Status.Conditions) > 0 {
+			content.WriteString("\nConditions:\n")
+			for _, condition := range machine.Status.Conditions {
+				content.WriteString(fmt.Sprintf("  - Type: %s\n", condition.Type))
+				content.WriteString(fmt.Sprintf("    Status: %s\n", condition.Status))
+				if condition.Reason != "" {
+					content.WriteString(fmt.Sprintf("    Reason: %s\n", condition.Reason))
+				}
+				if condition.Message != "" {
+					content.WriteString(fmt.Sprintf("    Message: %s\n", condition.Message))
+				}
+			}
+		}
+
+		// Addresses
+		if len(machine.Status.Addresses) > 0 {
+			content.WriteString("\nAddresses:\n")
+			for _, addr := range machine.Status.Addresses {
+				content.WriteString(fmt.Sprintf("  - Type: %s, Address: %s\n", addr.Type, addr.Address))
+			}
+		}
+
+		return newToolResult(content.String(), trimObject(machine))
+	}
+}
+
+// createDeleteMachineHandler creates a handler for deleting CAPI machines
+func createDeleteMachineHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		force, _ := arguments["force"].(bool)
+
+		// Delete the machine
+		err = serverCtx.client(ctx).DeleteMachine(ctx, capi.DeleteMachineOptions{
+			Namespace: namespace,
+			Name:      name,
+			Force:     force,
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to delete machine: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("✅ Successfully initiated deletion of machine %s/%s\n\n", namespace, name))
+		content.WriteString("Note: Machine deletion is asynchronous. The machine will be:\n")
+		content.WriteString("1. Drained (if it has a node)\n")
+		content.WriteString("2. Removed from the cluster\n")
+		content.WriteString("3. Infrastructure resources cleaned up\n\n")
+		content.WriteString("Monitor deletion progress with:\n")
+		content.WriteString(fmt.Sprintf("  capi_get_machine --namespace %s --name %s\n", namespace, name))
+
+		return newToolResult(content.String(), operationResult{Operation: "delete", Resource: resourceRef{Kind: "Machine", Namespace: namespace, Name: name}, Details: map[string]any{"force": force}})
+	}
+}
+
+// createRemediateMachineHandler creates a handler for triggering machine remediation
+func createRemediateMachineHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		// Get current machine status first
+		machine, err := serverCtx.client(ctx).GetMachine(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get machine: %w", err))
+		}
+
+		// Trigger remediation
+		err = serverCtx.client(ctx).RemediateMachine(ctx, capi.RemediateMachineOptions{
+			Namespace: namespace,
+			Name:      name,
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to remediate machine: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("🔧 Triggered remediation for machine %s/%s\n\n", namespace, name))
+		content.WriteString("Current Machine Status:\n")
+		content.WriteString(fmt.Sprintf("  • Phase: %s\n", machine.Status.Phase))
+		if machine.Status.NodeRef != nil {
+			content.WriteString(fmt.Sprintf("  • Node: %s\n", machine.Status.NodeRef.Name))
+		}
+		content.WriteString("\nRemediation Process:\n")
+		content.WriteString("1. Machine will be marked for remediation\n")
+		content.WriteString("2. MachineHealthCheck controller will process the remediation\n")
+		content.WriteString("3. Depending on remediation strategy:\n")
+		content.WriteString("   - Machine may be deleted and recreated\n")
+		content.WriteString("   - Node may be rebooted\n")
+		content.WriteString("   - Custom remediation may be applied\n\n")
+		content.WriteString("Monitor remediation progress with:\n")
+		content.WriteString(fmt.Sprintf("  capi_get_machine --namespace %s --name %s\n", namespace, name))
+
+		return newToolResult(content.String(), operationResult{Operation: "remediate", Resource: resourceRef{Kind: "Machine", Namespace: namespace, Name: name}, Details: map[string]any{"phase": machine.Status.Phase}})
+	}
+}
+
+// createMachineAnnotationHandler creates a handler setting or, with remove,
+// removing a remediation or scale-down annotation of a machine
+func createMachineAnnotationHandler(serverCtx *ServerContext, schema params.Schema, remove bool) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := schema.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace, name, annotation := args.String("namespace"), args.String("name"), args.String("annotation")
+		protection, err := serverCtx.client(ctx).SetMachineAnnotation(ctx, capi.MachineAnnotationOptions{
+			Namespace:  namespace,
+			Name:       name,
+			Annotation: annotation,
+			Reason:     args.String("reason"),
+			Remove:     remove,
+		})
+		if err != nil {
+			return toolError(err)
+		}
+
+		var content strings.Builder
+		switch {
+		case !protection.Changed && remove:
+			content.WriteString(fmt.Sprintf("Machine %s/%s has no %s annotation\n", namespace, name, annotation))
+		case !protection.Changed:
+			content.WriteString(fmt.Sprintf("Machine %s/%s already has the %s annotation\n", namespace, name, annotation))
+		case remove:
+			content.WriteString(fmt.Sprintf("✅ Removed the %s annotation of machine %s/%s\n", annotation, namespace, name))
+		default:
+			content.WriteString(fmt.Sprintf("✅ Set the %s annotation of machine %s/%s\n", annotation, namespace, name))
+		}
+		content.WriteString("\n" + formatMachineProtection(protection))
+		for _, warning := range protection.Warnings {
+			content.WriteString(fmt.Sprintf("⚠️  %s\n", warning))
+		}
+
+		operation := "annotate"
+		if remove {
+			operation = "unannotate"
+		}
+		return newToolResult(content.String(), operationResult{
+			Operation: operation,
+			Resource:  resourceRef{Kind: "Machine", Namespace: namespace, Name: name},
+			Details:   map[string]any{"annotation": annotation, "machine": protection},
+		})
+	}
+}
+
+// createListMachineAnnotationsHandler creates a handler for listing the
+// machines with remediation or scale-down annotations
+func createListMachineAnnotationsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := listMachineAnnotationsParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		protections, err := serverCtx.client(ctx).ListMachineProtections(ctx, namespace, args.String("clusterName"))
+		if err != nil {
+			return toolError(err)
+		}
+
+		var content strings.Builder
+		if len(protections) == 0 {
+			content.WriteString(fmt.Sprintf("No machines in namespace %s are excluded from remediation or marked to be deleted first\n", namespace))
+		} else {
+			content.WriteString(fmt.Sprintf("Found %d annotated machines in namespace %s:\n", len(protections), namespace))
+		}
+		for i := range protections {
+			content.WriteString("\n" + formatMachineProtection(&protections[i]))
+		}
+		return newToolResult(content.String(), map[string]any{"machines": protections})
+	}
+}
+
+// formatMachineProtection describes the annotations of a machine
+func formatMachineProtection(protection *capi.MachineProtection) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Machine %s (cluster %s):\n", protection.Name, protection.Cluster))
+	remediation := "remediated by MachineHealthChecks"
+	if protection.SkipRemediation {
+		remediation = "skipped"
+	}
+	content.WriteString(fmt.Sprintf("  • Remediation: %s\n", remediation))
+	if protection.MachineSet != "" {
+		scaleDown := fmt.Sprintf("by the delete policy of MachineSet %s", protection.MachineSet)
+		if protection.DeletePolicy != "" {
+			scaleDown = fmt.Sprintf("by the %s delete policy of MachineSet %s", protection.DeletePolicy, protection.MachineSet)
+		}
+		if protection.DeleteFirst {
+			scaleDown = fmt.Sprintf("deleted first by MachineSet %s", protection.MachineSet)
+		}
+		content.WriteString(fmt.Sprintf("  • Scale-down: %s\n", scaleDown))
+	} else if protection.DeleteFirst {
+		content.WriteString("  • Scale-down: marked to be deleted first\n")
+	}
+	for _, name := range machineAnnotationNames {
+		if reason, ok := protection.Reasons[name]; ok {
+			content.WriteString(fmt.Sprintf("  • Reason for %s: %s\n", name, reason))
+		}
+	}
+	return content.String()
+}
+
+// createCreateMachineDeploymentHandler creates a handler for creating new machine deployments
+func createCreateMachineDeploymentHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+		clusterName, err := params.RequiredString(arguments, "cluster_name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		// Get replicas
+		replicas, err := params.OptionalInt(arguments, "replicas", 1)
+		if err != nil {
+			return toolError(err)
+		}
+
+		// Get infrastructure reference
+		infraKind, _ := arguments["infra_kind"].(string)
+		infraName, _ := arguments["infra_name"].(string)
+		infraAPIVersion, _ := arguments["infra_api_version"].(string)
+
+		if infraKind == "" || infraName == "" {
+			return invalidArgument("infra_kind and infra_name are required")
+		}
+
+		// Get bootstrap reference
+		bootstrapKind, _ := arguments["bootstrap_kind"].(string)
+		bootstrapName, _ := arguments["bootstrap_name"].(string)
+		bootstrapAPIVersion, _ := arguments["bootstrap_api_version"].(string)
+
+		if bootstrapKind == "" || bootstrapName == "" {
+			return invalidArgument("bootstrap_kind and bootstrap_name are required")
+		}
+
+		// Workers default to the version of their control plane
+		version, _ := arguments["version"].(string)
+		if version == "" {
+			status, err := serverCtx.client(ctx).GetClusterStatus(ctx, namespace, clusterName)
+			if err != nil {
+				return toolError(fmt.Errorf("failed to get the cluster version: %w", err))
+			}
+			if status.Version == "" {
+				return invalidArgument("version is required: the control plane version of cluster %s is unknown", clusterName)
+			}
+			version = status.Version
+		}
+
+		// Create the machine deployment
+		md, err := serverCtx.client(ctx).CreateMachineDeployment(ctx, capi.CreateMachineDeploymentOptions{
+			Namespace:   namespace,
+			Name:        name,
+			ClusterName: clusterName,
+			Replicas:    int32(replicas),
+			InfrastructureRef: v1.ObjectReference{
+				Kind:       infraKind,
+				Name:       infraName,
+				APIVersion: infraAPIVersion,
+			},
+			BootstrapConfigRef: v1.ObjectReference{
+				Kind:       bootstrapKind,
+				Name:       bootstrapName,
+				APIVersion: bootstrapAPIVersion,
+			},
+			Version: version,
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to create machine deployment: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("✅ Successfully created machine deployment %s/%s\n\n", namespace, name))
+		content.WriteString("Configuration:\n")
+		content.WriteString(fmt.Sprintf("  • Cluster: %s\n", clusterName))
+		content.WriteString(fmt.Sprintf("  • Replicas: %d\n", replicas))
+		content.WriteString(fmt.Sprintf("  • Version: %s\n", version))
+		content.WriteString(fmt.Sprintf("  • Infrastructure: %s/%s\n", infraKind, infraName))
+		content.WriteString(fmt.Sprintf("  • Bootstrap: %s/%s\n", bootstrapKind, bootstrapName))
+		if md.Spec.MinReadySeconds != nil {
+			content.WriteString(fmt.Sprintf("  • Min Ready Seconds: %d\n", *md.Spec.MinReadySeconds))
+		}
+		content.WriteString("\nNote: Before creating a MachineDeployment, ensure you have:\n")
+		content.WriteString("1. Created the infrastructure template (e.g., AWSMachineTemplate)\n")
+		content.WriteString("2. Created the bootstrap config template (e.g., KubeadmConfigTemplate)\n\n")
+		content.WriteString("Monitor the deployment with:\n")
+		content.WriteString(fmt.Sprintf("  capi_list_machines --cluster %s\n", clusterName))
+		content.WriteString("\nScale the deployment with:\n")
+		content.WriteString(fmt.Sprintf("  capi_scale_machinedeployment --namespace %s --name %s --replicas <count>\n", namespace, name))
+
+		return newToolResult(content.String(), operationResult{Operation: "create", Resource: resourceRef{Kind: "MachineDeployment", Namespace: namespace, Name: name}, Details: map[string]any{"cluster": clusterName, "replicas": replicas, "version": version}})
+	}
+}
+
+// scaleMachineDeploymentParams declares the arguments of capi_scale_machinedeployment
+var scaleMachineDeploymentParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the machine deployment"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the machine deployment"},
+	{Name: "replicas", Type: params.Int, Required: true, NonNegative: true, Description: "Number of replicas to scale to"},
+}
+
+// createScaleMachineDeploymentHandler creates a handler for scaling machine deployments
+func createScaleMachineDeploymentHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := scaleMachineDeploymentParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+		replicas := args.Int32("replicas")
+
+		// Get current state
+		list, err := serverCtx.client(ctx).ListMachineDeployments(ctx, namespace, "")
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get machine deployment: %w", err))
+		}
+
+		var currentReplicas int32
+		found := false
+		for _, md := range list.Items {
+			if md.Name == name {
+				if md.Spec.Replicas != nil {
+					currentReplicas = *md.Spec.Replicas
+				}
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return mcp.NewToolResultError(fmt.Sprintf("Not found: machine deployment %s/%s does not exist", namespace, name)), nil
+		}
+
+		// Scale the machine deployment
+		err = serverCtx.client(ctx).ScaleMachineDeployment(ctx, namespace, name, replicas)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to scale machine deployment: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("✅ Successfully scaled machine deployment %s/%s\n\n", namespace, name))
+		content.WriteString("Scaling Operation:\n")
+		content.WriteString(fmt.Sprintf("  • Previous Replicas: %d\n", currentReplicas))
+		content.WriteString(fmt.Sprintf("  • New Replicas: %d\n", replicas))
+
+		if replicas > currentReplicas {
+			content.WriteString(fmt.Sprintf("  • Action: Scaling UP by %d nodes\n", replicas-currentReplicas))
+			content.WriteString("\nNew nodes will be:\n")
+			content.WriteString("1. Provisioned by the infrastructure provider\n")
+			content.WriteString("2. Bootstrapped with Kubernetes\n")
+			content.WriteString("3. Joined to the cluster\n")
+		} else if replicas < currentReplicas {
+			content.WriteString(fmt.Sprintf("  • Action: Scaling DOWN by %d nodes\n", currentReplicas-replicas))
+			content.WriteString("\nNodes will be:\n")
+			content.WriteString("1. Cordoned to prevent new workloads\n")
+			content.WriteString("2. Drained to move existing workloads\n")
+			content.WriteString("3. Removed from the cluster\n")
+			content.WriteString("4. Infrastructure resources cleaned up\n")
+		} else {
+			content.WriteString("  • Action: No change (same replica count)\n")
+		}
+
+		content.WriteString("\nMonitor scaling progress with:\n")
+		content.WriteString(fmt.Sprintf("  capi_list_machines --namespace %s\n", namespace))
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "scale",
+			Resource:  resourceRef{Kind: "MachineDeployment", Namespace: namespace, Name: name},
+			Details:   map[string]any{"previousReplicas": currentReplicas, "replicas": replicas},
+		})
+	}
+}
+
+// createUpdateMachineDeploymentHandler creates a handler for updating machine deployment configuration
+func createUpdateMachineDeploymentHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		force, err := params.OptionalBool(arguments, "force", false)
+		if err != nil {
+			return toolError(err)
+		}
+
+		// Parse optional parameters
+		opts := capi.UpdateMachineDeploymentOptions{
+			Namespace: namespace,
+			Name:      name,
+			Force:     force,
+		}
+
+		// Version update
+		if version, ok := arguments["version"].(string); ok && version != "" {
+			opts.Version = &version
+		}
+
+		// Replicas update
+		if replicasFloat, ok := arguments["replicas"].(float64); ok {
+			replicas := int32(replicasFloat)
+			opts.Replicas = &replicas
+		}
+
+		// MinReadySeconds update
+		if minReadyFloat, ok := arguments["min_ready_seconds"].(float64); ok {
+			minReady := int32(minReadyFloat)
+			opts.MinReadySeconds = &minReady
+		}
+
+		// Labels update
+		if labels, ok := arguments["labels"].(map[string]interface{}); ok {
+			opts.Labels = make(map[string]string)
+			for k, v := range labels {
+				if strVal, ok := v.(string); ok {
+					opts.Labels[k] = strVal
+				}
+			}
+		}
+
+		// Annotations update
+		if annotations, ok := arguments["annotations"].(map[string]interface{}); ok {
+			opts.Annotations = make(map[string]string)
+			for k, v := range annotations {
+				if strVal, ok := v.(string); ok {
+					opts.Annotations[k] = strVal
+				}
+			}
+		}
+
+		// Update the machine deployment
+		md, err := serverCtx.client(ctx).UpdateMachineDeployment(ctx, opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to update machine deployment: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("✅ Successfully updated machine deployment %s/%s\n\n", namespace, name))
+		content.WriteString("Updated Configuration:\n")
+
+		if opts.Version != nil {
+			content.WriteString(fmt.Sprintf("  • Version: %s\n", *opts.Version))
+		}
+		if opts.Replicas != nil {
+			content.WriteString(fmt.Sprintf("  • Replicas: %d\n", *opts.Replicas))
+		}
+		if opts.MinReadySeconds != nil {
+			content.WriteString(fmt.Sprintf("  • Min Ready Seconds: %d\n", *opts.MinReadySeconds))
+		}
+		if len(opts.Labels) > 0 {
+			content.WriteString("  • Labels updated\n")
+		}
+		if len(opts.Annotations) > 0 {
+			content.WriteString("  • Annotations updated\n")
+		}
+
+		content.WriteString("\nCurrent Status:\n")
+		content.WriteString(fmt.Sprintf("  • Ready Replicas: %d\n", md.Status.ReadyReplicas))
+		content.WriteString(fmt.Sprintf("  • Updated Replicas: %d\n", md.Status.UpdatedReplicas))
+		content.WriteString(fmt.Sprintf("  • Available Replicas: %d\n", md.Status.AvailableReplicas))
+
+		return newToolResult(content.String(), map[string]any{
+			"operation":         "update",
+			"resource":          resourceRef{Kind: "MachineDeployment", Namespace: namespace, Name: name},
+			"machineDeployment": trimObject(md),
+		})
+	}
+}
+
+// createRolloutMachineDeploymentHandler creates a handler for triggering machine deployment rollout
+func createRolloutMachineDeploymentHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		reason, _ := arguments["reason"].(string)
+
+		// Trigger the rollout
+		err = serverCtx.client(ctx).RolloutMachineDeployment(ctx, capi.RolloutMachineDeploymentOptions{
+			Namespace: namespace,
+			Name:      name,
+			Reason:    reason,
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to trigger rollout: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("🔄 Successfully triggered rollout for machine deployment %s/%s\n\n", namespace, name))
+
+		if reason != "" {
+			content.WriteString(fmt.Sprintf("Reason: %s\n\n", reason))
+		}
+
+		content.WriteString("Rollout Process:\n")
+		content.WriteString("1. New machines will be created with updated configuration\n")
+		content.WriteString("2. Old machines will be gradually replaced\n")
+		content.WriteString("3. The rollout respects the deployment's update strategy\n")
+		content.WriteString("4. Health checks ensure machines are ready before proceeding\n\n")
+
+		content.WriteString("Monitor rollout progress with:\n")
+		content.WriteString(fmt.Sprintf("  capi_list_machines --namespace %s --cluster <cluster-name>\n", namespace))
+		content.WriteString(fmt.Sprintf("  capi_list_machinedeployments --namespace %s\n", namespace))
+
+		return newToolResult(content.String(), operationResult{Operation: "rollout", Resource: resourceRef{Kind: "MachineDeployment", Namespace: namespace, Name: name}, Details: map[string]any{"reason": reason}})
+	}
+}
+
+// createUpdateMachineImageHandler creates a handler for replacing the node
+// image of a MachineDeployment or KubeadmControlPlane
+func createUpdateMachineImageHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+		image, err := params.RequiredString(arguments, "image")
+		if err != nil {
+			return toolError(err)
+		}
+		controlPlane, err := params.OptionalBool(arguments, "control_plane", false)
+		if err != nil {
+			return toolError(err)
+		}
+		force, err := params.OptionalBool(arguments, "force", false)
+		if err != nil {
+			return toolError(err)
+		}
+		templateName := params.OptionalString(arguments, "template_name", "")
+		if templateName != "" {
+			if err := params.KubernetesName("template_name", templateName); err != nil {
+				return toolError(err)
+			}
+		}
+
+		update, err := serverCtx.client(ctx).UpdateMachineImage(ctx, capi.UpdateMachineImageOptions{
+			Namespace:    namespace,
+			Name:         name,
+			ControlPlane: controlPlane,
+			Image:        image,
+			TemplateName: templateName,
+			Force:        force,
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to update the machine image: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("🔄 Updating the node image of %s %s/%s\n\n", update.Kind, namespace, name))
+		content.WriteString(fmt.Sprintf("  • Image (%s): %s → %s\n", update.ImageField, summaryValue(update.OldImage), update.NewImage))
+		content.WriteString(fmt.Sprintf("  • %s: %s → %s", update.TemplateKind, update.OldTemplate, update.NewTemplate))
+		if update.Reused {
+			content.WriteString(" (existing template reused)")
+		}
+		content.WriteString("\n")
+		content.WriteString(fmt.Sprintf("  • Kubernetes version: %s\n", summaryValue(update.Version)))
+		switch {
+		case update.ImageVersion == "":
+			content.WriteString("  • The Kubernetes version of the image could not be determined; make sure it matches the machines\n")
+		case force && update.Version != "" && update.ImageVersion != update.Version:
+			content.WriteString(fmt.Sprintf("  • ⚠️ The image is built for %s (forced)\n", update.ImageVersion))
+		default:
+			content.WriteString(fmt.Sprintf("  • The image is built for %s\n", update.ImageVersion))
+		}
+
+		content.WriteString("\nThe machines are replaced according to the rollout strategy. Monitor the rollout with:\n")
+		content.WriteString(fmt.Sprintf("  capi_list_machines --namespace %s --cluster <cluster-name>\n", namespace))
+		if !controlPlane {
+			content.WriteString(fmt.Sprintf("  capi_list_machinedeployments --namespace %s\n", namespace))
+		}
+		content.WriteString(fmt.Sprintf("\nThe old template %s is kept; capi_list_changes and capi_revert_change roll back to it.\n", update.OldTemplate))
+
+		return newToolResult(content.String(), operationResult{Operation: "update-image", Resource: resourceRef{Kind: update.Kind, Namespace: namespace, Name: name}, Details: map[string]any{"update": update}})
+	}
+}
+
+// createListMachineSetsHandler creates a handler for listing machine sets
+func createListMachineSetsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := listMachineSetsParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		clusterName := args.String("clusterName")
+
+		machineSets, err := serverCtx.client(ctx).ListMachineSets(ctx, namespace, clusterName, selectorOptions(args)...)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list machine sets: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Found %d machine sets", len(machineSets.Items)))
+		if clusterName != "" {
+			content.WriteString(fmt.Sprintf(" in cluster %s", clusterName))
+		}
+		content.WriteString(":\n\n")
+
+		for _, ms := range machineSets.Items {
+			content.WriteString(fmt.Sprintf("MachineSet: %s/%s\n", ms.Namespace, ms.Name))
+			content.WriteString(fmt.Sprintf("  Cluster: %s\n", ms.Spec.ClusterName))
+			if ms.Spec.Replicas != nil {
+				content.WriteString(fmt.Sprintf("  Replicas: %d\n", *ms.Spec.Replicas))
+			}
+			content.WriteString(fmt.Sprintf("  Ready: %d/%d\n", ms.Status.ReadyReplicas, ms.Status.Replicas))
+			content.WriteString(fmt.Sprintf("  Available: %d\n", ms.Status.AvailableReplicas))
+
+			// Show owner reference (usually MachineDeployment)
+			for _, owner := range ms.OwnerReferences {
+				if owner.Kind == "MachineDeployment" {
+					content.WriteString(fmt.Sprintf("  Owner: MachineDeployment/%s\n", owner.Name))
+				}
+			}
+
+			// Show machine template
+			if ms.Spec.Template.Spec.InfrastructureRef.Name != "" {
+				content.WriteString(fmt.Sprintf("  Infrastructure: %s/%s\n",
+					ms.Spec.Template.Spec.InfrastructureRef.Kind,
+					ms.Spec.Template.Spec.InfrastructureRef.Name))
+			}
+			content.WriteString("\n")
+		}
+
+		return newToolResult(content.String(), map[string]any{"machineSets": trimItems(machineSets.Items)})
+	}
+}
+
+// createGetMachineSetHandler creates a handler for getting machine set details
+func createGetMachineSetHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		ms, err := serverCtx.client(ctx).GetMachineSet(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get machine set: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("MachineSet: %s/%s\n\n", ms.Namespace, ms.Name))
+
+		// Basic information
+		content.WriteString("Basic Information:\n")
+		content.WriteString(fmt.Sprintf("  Cluster: %s\n", ms.Spec.ClusterName))
+		if ms.Spec.Replicas != nil {
+			content.WriteString(fmt.Sprintf("  Desired Replicas: %d\n", *ms.Spec.Replicas))
+		}
+
+		// Status
+		content.WriteString("\nStatus:\n")
+		content.WriteString(fmt.Sprintf("  Total Replicas: %d\n", ms.Status.Replicas))
+		content.WriteString(fmt.Sprintf("  Ready Replicas: %d\n", ms.Status.ReadyReplicas))
+		content.WriteString(fmt.Sprintf("  Available Replicas: %d\n", ms.Status.AvailableReplicas))
+		if ms.Status.FailureReason != nil {
+			content.WriteString(fmt.Sprintf("  Failure Reason: %s\n", *ms.Status.FailureReason))
+		}
+		if ms.Status.FailureMessage != nil {
+			content.WriteString(fmt.Sprintf("  Failure Message: %s\n", *ms.Status.FailureMessage))
+		}
+
+		// Machine template
+		content.WriteString("\nMachine Template:\n")
+		if ms.Spec.Template.Spec.Version != nil {
+			content.WriteString(fmt.Sprintf("  Kubernetes Version: %s\n", *ms.Spec.Template.Spec.Version))
+		}
+		if ms.Spec.Template.Spec.InfrastructureRef.Name != "" {
+			content.WriteString(fmt.Sprintf("  Infrastructure: %s/%s\n",
+				ms.Spec.Template.Spec.InfrastructureRef.Kind,
+				ms.Spec.Template.Spec.InfrastructureRef.Name))
+		}
+		if ms.Spec.Template.Spec.Bootstrap.ConfigRef != nil {
+			content.WriteString(fmt.Sprintf("  Bootstrap: %s/%s\n",
+				ms.Spec.Template.Spec.Bootstrap.ConfigRef.Kind,
+				ms.Spec.Template.Spec.Bootstrap.ConfigRef.Name))
+		}
+
+		// Owner references
+		if len(ms.OwnerReferences) > 0 {
+			content.WriteString("\nOwners:\n")
+			for _, owner := range ms.OwnerReferences {
+				content.WriteString(fmt.Sprintf("  - %s: %s\n", owner.Kind, owner.Name))
+			}
+		}
+
+		// Conditions
+		if len(ms.Status.Conditions) > 0 {
+			content.WriteString("\nConditions:\n")
+			for _, condition := range ms.Status.Conditions {
+				content.WriteString(fmt.Sprintf("  - Type: %s\n", condition.Type))
+				content.WriteString(fmt.Sprintf("    Status: %s\n", condition.Status))
+				if condition.Reason != "" {
+					content.WriteString(fmt.Sprintf("    Reason: %s\n", condition.Reason))
+				}
+				if condition.Message != "" {
+					content.WriteString(fmt.Sprintf("    Message: %s\n", condition.Message))
+				}
+			}
+		}
+
+		return newToolResult(content.String(), trimObject(ms))
+	}
+}
@@ -0,0 +1,272 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/maintenance"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// maintenanceOverrideArgument lets a call change clusters outside their
+// maintenance windows
+const maintenanceOverrideArgument = "maintenance_override"
+
+// clusterResolver returns the clusters a tool call changes. It returns no
+// clusters when the arguments do not identify them, leaving the tool to
+// reject the call.
+type clusterResolver func(ctx context.Context, c capi.CAPIClient, arguments map[string]any) ([]*clusterv1.Cluster, error)
+
+// maintenanceTargets lists the tools that change existing clusters, with how
+// to find the clusters they change. Calls outside the maintenance windows of
+// these clusters need maintenance_override.
+var maintenanceTargets = map[string]clusterResolver{
+	"capi_delete_cluster":              clusterArgument("name"),
+	"capi_upgrade_cluster":             clusterArgument("name"),
+	"capi_upgrade_release":             clusterArgument("name"),
+	"capi_update_cluster":              clusterArgument("name"),
+	"capi_scale_cluster":               clusterArgument("name"),
+	"capi_pause_cluster":               clusterArgument("name"),
+	"capi_resume_cluster":              clusterArgument("name"),
+	"capi_hibernate_cluster":           clusterArgument("name"),
+	"capi_wake_cluster":                clusterArgument("name"),
+	"capi_rotate_kubeconfig":           clusterArgument("name"),
+	"capi_install_cni":                 clusterArgument("name"),
+	"capi_pivot_cluster":               clusterArgument("name"),
+	"capi_aws_update_vpc":              clusterArgument("name"),
+	"capi_aws_manage_security_groups":  clusterArgument("name"),
+	"capi_azure_manage_resource_group": clusterArgument("name"),
+	"capi_azure_network_config":        clusterArgument("name"),
+	"capi_gcp_manage_network":          clusterArgument("name"),
+	"capi_create_machinedeployment":    clusterArgument("cluster_name"),
+	"capi_scale_machinedeployment":     objectArgument("MachineDeployment", "name"),
+	"capi_update_machinedeployment":    objectArgument("MachineDeployment", "name"),
+	"capi_rollout_machinedeployment":   objectArgument("MachineDeployment", "name"),
+	"capi_update_machine_image":        resolveMachineImageTarget,
+	"capi_delete_machine":              objectArgument("Machine", "name"),
+	"capi_remediate_machine":           objectArgument("Machine", "name"),
+	"capi_set_machine_annotation":      objectArgument("Machine", "name"),
+	"capi_remove_machine_annotation":   objectArgument("Machine", "name"),
+	"capi_cordon_node":                 objectArgument("Machine", "machine_name"),
+	"capi_drain_node":                  objectArgument("Machine", "machine_name"),
+	"capi_aws_configure_spot":          resolveSpotTarget,
+	"capi_fleet_upgrade":               resolveFleetTargets("clusters"),
+	"capi_canary_upgrade":              resolveCanaryTargets,
+	"capi_revert_change":               resolveChangeTarget,
+}
+
+// maintenanceKinds are the kinds whose cluster objectArgument can look up
+var maintenanceKinds = map[string]schema.GroupVersionKind{
+	"Cluster":             clusterv1.GroupVersion.WithKind("Cluster"),
+	"MachineDeployment":   clusterv1.GroupVersion.WithKind("MachineDeployment"),
+	"Machine":             clusterv1.GroupVersion.WithKind("Machine"),
+	"MachinePool":         clusterv1.GroupVersion.WithKind("MachinePool"),
+	"KubeadmControlPlane": controlplanev1.GroupVersion.WithKind("KubeadmControlPlane"),
+}
+
+// withMaintenanceOverride adds the maintenance window override to a tool
+func withMaintenanceOverride(tool *mcp.Tool) {
+	mcp.WithBoolean(maintenanceOverrideArgument,
+		mcp.Description("Change the cluster outside its maintenance window (default: false); the override is recorded in the audit log"),
+	)(tool)
+}
+
+// clusterArgument resolves the cluster named by an argument in the namespace
+// argument
+func clusterArgument(name string) clusterResolver {
+	return func(ctx context.Context, c capi.CAPIClient, arguments map[string]any) ([]*clusterv1.Cluster, error) {
+		namespace := params.OptionalString(arguments, "namespace", "")
+		clusterName := params.OptionalString(arguments, name, "")
+		if namespace == "" || clusterName == "" {
+			return nil, nil
+		}
+		cluster, err := c.GetCluster(ctx, namespace, clusterName)
+		if err != nil {
+			return nil, err
+		}
+		return []*clusterv1.Cluster{cluster}, nil
+	}
+}
+
+// objectArgument resolves the cluster of the object of a kind named by an
+// argument in the namespace argument
+func objectArgument(kind, name string) clusterResolver {
+	return func(ctx context.Context, c capi.CAPIClient, arguments map[string]any) ([]*clusterv1.Cluster, error) {
+		namespace := params.OptionalString(arguments, "namespace", "")
+		objectName := params.OptionalString(arguments, name, "")
+		if namespace == "" || objectName == "" {
+			return nil, nil
+		}
+		cluster, err := objectCluster(ctx, c, kind, namespace, objectName)
+		if err != nil || cluster == nil {
+			return nil, err
+		}
+		return []*clusterv1.Cluster{cluster}, nil
+	}
+}
+
+// objectCluster returns the cluster an object belongs to, according to its
+// spec.clusterName, its cluster name label or its owner reference. It
+// returns nil for kinds it does not know.
+func objectCluster(ctx context.Context, c capi.CAPIClient, kind, namespace, name string) (*clusterv1.Cluster, error) {
+	if kind == "Cluster" {
+		return c.GetCluster(ctx, namespace, name)
+	}
+	gvk, ok := maintenanceKinds[kind]
+	if !ok {
+		return nil, nil
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	if err := c.GetCtrlClient().Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%s %s/%s: %w", kind, namespace, name, capi.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get %s %s/%s: %w", kind, namespace, name, err)
+	}
+
+	clusterName, _, _ := unstructured.NestedString(obj.Object, "spec", "clusterName")
+	if clusterName == "" {
+		clusterName = obj.GetLabels()[clusterv1.ClusterNameLabel]
+	}
+	if clusterName == "" {
+		for _, ref := range obj.GetOwnerReferences() {
+			if ref.Kind == "Cluster" && strings.HasPrefix(ref.APIVersion, clusterv1.GroupVersion.Group+"/") {
+				clusterName = ref.Name
+			}
+		}
+	}
+	if clusterName == "" {
+		return nil, fmt.Errorf("cannot tell the cluster of %s %s/%s", kind, namespace, name)
+	}
+	return c.GetCluster(ctx, namespace, clusterName)
+}
+
+// resolveMachineImageTarget resolves the machine deployment, or control plane
+// with control_plane, of capi_update_machine_image
+func resolveMachineImageTarget(ctx context.Context, c capi.CAPIClient, arguments map[string]any) ([]*clusterv1.Cluster, error) {
+	if controlPlane, _ := arguments["control_plane"].(bool); controlPlane {
+		return objectArgument("KubeadmControlPlane", "name")(ctx, c, arguments)
+	}
+	return objectArgument("MachineDeployment", "name")(ctx, c, arguments)
+}
+
+// resolveSpotTarget resolves the machine deployment or machine pool of
+// capi_aws_configure_spot
+func resolveSpotTarget(ctx context.Context, c capi.CAPIClient, arguments map[string]any) ([]*clusterv1.Cluster, error) {
+	if params.OptionalString(arguments, "machine_pool", "") != "" {
+		return objectArgument("MachinePool", "machine_pool")(ctx, c, arguments)
+	}
+	return objectArgument("MachineDeployment", "machine_deployment")(ctx, c, arguments)
+}
+
+// resolveFleetTargets resolves the clusters named by a comma-separated
+// argument or, without it, matching the label selector in the namespace
+func resolveFleetTargets(name string) clusterResolver {
+	return func(ctx context.Context, c capi.CAPIClient, arguments map[string]any) ([]*clusterv1.Cluster, error) {
+		namespace := params.OptionalString(arguments, "namespace", "")
+		if names := params.OptionalString(arguments, name, ""); names != "" {
+			var clusters []*clusterv1.Cluster
+			for _, clusterName := range strings.Split(names, ",") {
+				if clusterName = strings.TrimSpace(clusterName); clusterName == "" {
+					continue
+				}
+				cluster, err := c.GetCluster(ctx, namespace, clusterName)
+				if err != nil {
+					return nil, err
+				}
+				clusters = append(clusters, cluster)
+			}
+			return clusters, nil
+		}
+
+		list, err := c.ListClusters(ctx, namespace, capi.WithLabelSelector(params.OptionalString(arguments, labelSelectorArgument, "")))
+		if err != nil {
+			return nil, err
+		}
+		clusters := make([]*clusterv1.Cluster, 0, len(list.Items))
+		for i := range list.Items {
+			clusters = append(clusters, &list.Items[i])
+		}
+		return clusters, nil
+	}
+}
+
+// resolveCanaryTargets resolves the canary cluster and the clusters upgraded
+// after it
+func resolveCanaryTargets(ctx context.Context, c capi.CAPIClient, arguments map[string]any) ([]*clusterv1.Cluster, error) {
+	canary, err := clusterArgument("canary_cluster")(ctx, c, arguments)
+	if err != nil {
+		return nil, err
+	}
+	rest, err := resolveFleetTargets("clusters")(ctx, c, arguments)
+	if err != nil {
+		return nil, err
+	}
+	return append(canary, rest...), nil
+}
+
+// resolveChangeTarget resolves the cluster of the resource a change reverts
+func resolveChangeTarget(ctx context.Context, c capi.CAPIClient, arguments map[string]any) ([]*clusterv1.Cluster, error) {
+	history := c.ChangeHistory()
+	if history == nil {
+		return nil, nil
+	}
+	change, ok := history.Get(params.OptionalString(arguments, "change_id", ""))
+	if !ok {
+		return nil, nil
+	}
+	cluster, err := objectCluster(ctx, c, change.Kind, change.Namespace, change.Name)
+	if err != nil || cluster == nil {
+		return nil, err
+	}
+	return []*clusterv1.Cluster{cluster}, nil
+}
+
+// NewMaintenanceWindowMiddleware rejects calls changing clusters outside
+// their maintenance windows, unless they pass maintenance_override or only
+// preview their changes. Windows
+// come from the cluster annotation or the rules of windows, which may be nil.
+func NewMaintenanceWindowMiddleware(pool *capi.ClientPool, windows *maintenance.Config) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			resolve, ok := maintenanceTargets[request.Params.Name]
+			arguments := request.GetArguments()
+			if override, _ := arguments[maintenanceOverrideArgument].(bool); !ok || override || isPreview(request.Params.Name, arguments) {
+				return next(ctx, request)
+			}
+
+			c, err := pool.Get(params.OptionalString(arguments, managementClusterArgument, ""), params.OptionalString(arguments, kubeconfigContextArgument, ""))
+			if err != nil {
+				return toolError(err)
+			}
+			clusters, err := resolve(ctx, c, arguments)
+			if errors.Is(err, capi.ErrNotFound) {
+				// The tool reports missing resources itself
+				return next(ctx, request)
+			}
+			if err != nil {
+				return toolError(fmt.Errorf("failed to check the maintenance window: %w", err))
+			}
+			now := time.Now()
+			for _, cluster := range clusters {
+				if err := windows.Check(cluster, now); err != nil {
+					return toolError(err)
+				}
+			}
+			return next(ctx, request)
+		}
+	}
+}
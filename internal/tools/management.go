@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	// managementClusterArgument selects one of the configured management clusters
+	managementClusterArgument = "management_cluster"
+	// kubeconfigContextArgument selects a context of the server's kubeconfig
+	kubeconfigContextArgument = "kubeconfig_context"
+)
+
+// managementClusterIndependentTools do not talk to a management cluster and
+// therefore do not accept management_cluster or kubeconfig_context
+var managementClusterIndependentTools = map[string]bool{
+	"test":                          true,
+	"capi_list_management_clusters": true,
+	"capi_use_context":              true,
+	"capi_get_provider_config":      true,
+	"capi_discover_providers":       true,
+	"capi_list_approvals":           true,
+	"capi_approve_operation":        true,
+	"capi_reject_operation":         true,
+	"capi_rbac_manifest":            true,
+	"capi_audit_log":                true,
+	"capi_job_status":               true,
+	"capi_job_logs":                 true,
+	"capi_job_cancel":               true,
+	"capi_canary_status":            true,
+	"capi_create_schedule":          true,
+	"capi_list_schedules":           true,
+	"capi_cancel_schedule":          true,
+}
+
+// withManagementClusterArgs adds the optional management cluster selection to a tool
+func withManagementClusterArgs(tool *mcp.Tool) {
+	mcp.WithString(managementClusterArgument,
+		mcp.Description("Name of the management cluster to operate on (optional, defaults to the server's cluster)"),
+	)(tool)
+	mcp.WithString(kubeconfigContextArgument,
+		mcp.Description("Kubeconfig context of the management cluster to operate on (optional, alternative to management_cluster)"),
+	)(tool)
+}
+
+// registerManagementTools adds the management cluster registry tools
+func registerManagementTools(s Registry, serverCtx *ServerContext) {
+	listManagementClustersTool := mcp.NewTool(
+		"capi_list_management_clusters",
+		mcp.WithDescription("List the named management clusters that tools can target with management_cluster"),
+	)
+	addTool(s, listManagementClustersTool, createListManagementClustersHandler(serverCtx))
+
+	useContextTool := mcp.NewTool(
+		"capi_use_context",
+		mcp.WithDescription("Switch the default management cluster to another context of the server's kubeconfig. Applies to all later tool calls without management_cluster or kubeconfig_context."),
+		mcp.WithString("context",
+			mcp.Description("Kubeconfig context to use (optional, switches back to the cluster the server was started with if omitted)"),
+		),
+	)
+	addTool(s, useContextTool, createUseContextHandler(serverCtx))
+}
+
+// createListManagementClustersHandler creates a handler for listing the management cluster registry
+func createListManagementClustersHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		clusters := serverCtx.Clients.ManagementClusters()
+
+		var content strings.Builder
+		content.WriteString("Management clusters:\n\n")
+		if active := serverCtx.Clients.ActiveContext(); active != "" {
+			content.WriteString(fmt.Sprintf("- (default): kubeconfig context %s selected with capi_use_context, used when management_cluster is not set\n", active))
+		} else {
+			content.WriteString("- (default): the cluster the server was started with, used when management_cluster is not set\n")
+		}
+		for _, cluster := range clusters {
+			content.WriteString(fmt.Sprintf("- %s", cluster.Name))
+			if cluster.Description != "" {
+				content.WriteString(fmt.Sprintf(": %s", cluster.Description))
+			}
+			var source []string
+			if cluster.Kubeconfig != "" {
+				source = append(source, "kubeconfig "+cluster.Kubeconfig)
+			}
+			if cluster.Context != "" {
+				source = append(source, "context "+cluster.Context)
+			}
+			content.WriteString(fmt.Sprintf(" (%s)\n", strings.Join(source, ", ")))
+		}
+
+		return newToolResult(content.String(), map[string]any{"managementClusters": clusters})
+	}
+}
+
+// createUseContextHandler creates a handler for switching the default kubeconfig context
+func createUseContextHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		contextName := params.OptionalString(request.GetArguments(), "context", "")
+
+		if err := serverCtx.Clients.UseContext(contextName); err != nil {
+			return toolError(err)
+		}
+
+		message := fmt.Sprintf("Switched to kubeconfig context %s", contextName)
+		if contextName == "" {
+			message = "Switched back to the cluster the server was started with"
+		}
+		return newToolResult(message, map[string]any{"context": contextName})
+	}
+}
+
+type clientKey struct{}
+
+// client returns the client of the management cluster selected for the
+// current tool call, or the default client
+func (s *ServerContext) client(ctx context.Context) capi.CAPIClient {
+	if c, ok := ctx.Value(clientKey{}).(*capi.Client); ok {
+		return c
+	}
+	if s.Client != nil {
+		return s.Client
+	}
+	return s.Clients.Default()
+}
+
+// NewManagementClusterMiddleware resolves the management_cluster and
+// kubeconfig_context arguments to a client of the pool
+func NewManagementClusterMiddleware(pool *capi.ClientPool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			arguments := request.GetArguments()
+			managementCluster := params.OptionalString(arguments, managementClusterArgument, "")
+			kubeconfigContext := params.OptionalString(arguments, kubeconfigContextArgument, "")
+			if managementCluster == "" && kubeconfigContext == "" {
+				return next(ctx, request)
+			}
+
+			c, err := pool.Get(managementCluster, kubeconfigContext)
+			if err != nil {
+				return toolError(err)
+			}
+			return next(context.WithValue(ctx, clientKey{}, c), request)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/auth"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// applyManifestParams declares the arguments of capi_apply_manifest
+var applyManifestParams = params.Schema{
+	{Name: "manifest", Type: params.String, Required: true,
+		Description: "YAML documents separated by ---, or JSON, of the objects to apply"},
+	{Name: "namespace", Type: params.String, Required: true, Validate: params.Namespace,
+		Description: "Namespace of the namespaced objects that do not set one"},
+	{Name: "dry_run", Type: params.Bool, Default: true,
+		Description: "Only validate the objects and show the diff against the cluster (default: true)"},
+	{Name: "force", Type: params.Bool,
+		Description: "Take over fields managed by other field managers instead of reporting conflicts (default: false)"},
+}
+
+// registerManifestTools adds the tools applying raw manifests
+func registerManifestTools(s Registry, serverCtx *ServerContext) {
+	applyManifestTool := applyManifestParams.NewTool(
+		"capi_apply_manifest",
+		"Apply arbitrary Kubernetes objects, e.g. CAPI resources the other tools cannot express, with server-side apply. Validates every object against the schema of its CRD and with a server-side dry-run, and shows a field-level diff against the existing objects. Nothing is applied while any object has a problem; by default only the diff is returned.",
+		withApprovalID(),
+	)
+	addTool(s, applyManifestTool, createApplyManifestHandler(serverCtx))
+}
+
+// createApplyManifestHandler creates a handler for applying manifests
+func createApplyManifestHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := applyManifestParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+
+		opts := capi.ApplyManifestOptions{
+			Manifest:  []byte(args.String("manifest")),
+			Namespace: args.String("namespace"),
+			Force:     args.Bool("force"),
+			DryRun:    args.Bool("dry_run"),
+		}
+		// Restricted identities may only touch objects in their namespaces
+		if identity, ok := auth.IdentityFromContext(ctx); ok && identity.Policy.Restricted() {
+			opts.AllowNamespace = identity.Policy.AllowsNamespace
+		}
+		result, err := serverCtx.client(ctx).ApplyManifest(ctx, opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to apply manifest: %w", err))
+		}
+
+		var content strings.Builder
+		switch {
+		case result.Applied:
+			content.WriteString(fmt.Sprintf("✅ Applied %d objects\n\n", len(result.Objects)))
+		case !result.Valid:
+			content.WriteString("❌ The manifest has problems, nothing was applied\n\n")
+		default:
+			content.WriteString(fmt.Sprintf("📄 Dry-run of %d objects, nothing was applied\n\n", len(result.Objects)))
+		}
+		for _, obj := range result.Objects {
+			ref := obj.Kind + " " + obj.Name
+			if obj.Namespace != "" {
+				ref = fmt.Sprintf("%s %s/%s", obj.Kind, obj.Namespace, obj.Name)
+			}
+			if len(obj.Problems) > 0 {
+				content.WriteString(fmt.Sprintf("❌ %s\n", ref))
+				for _, problem := range obj.Problems {
+					content.WriteString(fmt.Sprintf("  - %s\n", problem))
+				}
+				continue
+			}
+			content.WriteString(fmt.Sprintf("%s %s\n", manifestActionIcon[obj.Action], ref))
+			for _, change := range obj.Changes {
+				content.WriteString(fmt.Sprintf("  - %s: %s → %s\n", change.Field, fieldChangeValue(change.From), fieldChangeValue(change.To)))
+			}
+		}
+		if result.Valid && !result.Applied {
+			content.WriteString("\nApply the objects by calling again with dry_run: false\n")
+		}
+
+		return newToolResult(content.String(), result)
+	}
+}
+
+// manifestActionIcon marks what applying an object does
+var manifestActionIcon = map[string]string{
+	capi.ManifestCreate:    "➕ create",
+	capi.ManifestUpdate:    "✏️ update",
+	capi.ManifestUnchanged: "= unchanged",
+}
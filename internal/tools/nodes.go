@@ -0,0 +1,772 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	v1 "k8s.io/api/core/v1"
+)
+
+// registerNodeTools adds the workload cluster node tools
+func registerNodeTools(s Registry, serverCtx *ServerContext) {
+	// Add CAPI drain node tool
+	drainNodeTool := mcp.NewTool(
+		"capi_drain_node",
+		mcp.WithDescription("Safely drain a Kubernetes node"),
+		mcp.WithString("namespace",
+			mcp.Description("Machine namespace (required if using machine_name)"),
+		),
+		mcp.WithString("machine_name",
+			mcp.Description("Machine name to get node from"),
+		),
+		mcp.WithString("node_name",
+			mcp.Description("Node name to drain directly"),
+		),
+		mcp.WithBoolean("ignore_daemonsets",
+			mcp.Description("Ignore DaemonSet-managed pods"),
+		),
+		mcp.WithBoolean("delete_local_data",
+			mcp.Description("Delete pods with local storage"),
+		),
+		mcp.WithBoolean("force",
+			mcp.Description("Force deletion of pods"),
+		),
+		mcp.WithNumber("grace_period_seconds",
+			mcp.Description("Grace period for pod termination"),
+		),
+		withApprovalID(),
+	)
+
+	addTool(s, drainNodeTool, createDrainNodeHandler(serverCtx))
+
+	// Add CAPI cordon node tool
+	cordonNodeTool := mcp.NewTool(
+		"capi_cordon_node",
+		mcp.WithDescription("Cordon or uncordon a Kubernetes node"),
+		mcp.WithString("namespace",
+			mcp.Description("Machine namespace (required if using machine_name)"),
+		),
+		mcp.WithString("machine_name",
+			mcp.Description("Machine name to get node from"),
+		),
+		mcp.WithString("node_name",
+			mcp.Description("Node name to cordon/uncordon directly"),
+		),
+		mcp.WithBoolean("uncordon",
+			mcp.Description("Set to true to uncordon (make schedulable)"),
+		),
+	)
+
+	addTool(s, cordonNodeTool, createCordonNodeHandler(serverCtx))
+
+	// Add CAPI node status tool
+	nodeStatusTool := mcp.NewTool(
+		"capi_node_status",
+		mcp.WithDescription("Get node status from workload cluster"),
+		mcp.WithString("namespace",
+			mcp.Description("Machine namespace (required if using machine_name)"),
+		),
+		mcp.WithString("machine_name",
+			mcp.Description("Machine name to get node from"),
+		),
+		mcp.WithString("node_name",
+			mcp.Description("Node name to get status for directly"),
+		),
+	)
+
+	addTool(s, nodeStatusTool, createNodeStatusHandler(serverCtx))
+
+	nodeReportTool := nodeReportParams.NewTool(
+		"capi_node_report",
+		"Join the Machines of a cluster to the Nodes of its workload cluster and report machines without nodes, nodes without machines, provider ID mismatches and kubelet versions drifting from their Machine after partial upgrades",
+	)
+
+	addTool(s, nodeReportTool, createNodeReportHandler(serverCtx))
+
+	nodePodsTool := nodePodsParams.NewTool(
+		"capi_node_pods",
+		"List the pods running on a node of a workload cluster grouped by controller, flagging pods protected by PodDisruptionBudgets, with local storage or without controller, to assess the impact of draining the node or deleting its machine",
+	)
+
+	addTool(s, nodePodsTool, createNodePodsHandler(serverCtx))
+
+	pdbConflictsTool := pdbConflictsParams.NewTool(
+		"capi_pdb_conflicts",
+		"Check the PodDisruptionBudgets of a workload cluster against a planned drain, scale-down or upgrade, reporting the budgets that allow no disruption and would block evictions before the operation starts",
+	)
+
+	addTool(s, pdbConflictsTool, createPDBConflictsHandler(serverCtx))
+
+	clusterCapacityTool := clusterCapacityParams.NewTool(
+		"capi_cluster_capacity",
+		"Report the allocatable and requested CPU and memory of the nodes of a workload cluster, with their usage when metrics-server is installed, flagging nodes under memory, disk or PID pressure",
+	)
+
+	addTool(s, clusterCapacityTool, createClusterCapacityHandler(serverCtx))
+
+	topNodesTool := topNodesParams.NewTool(
+		"capi_top_nodes",
+		"Show the live CPU and memory usage of the nodes of a workload cluster from metrics-server, like kubectl top nodes, with the Machine and MachineDeployment of each node",
+	)
+
+	addTool(s, topNodesTool, createTopNodesHandler(serverCtx))
+
+	autoscalerStatusTool := autoscalerStatusParams.NewTool(
+		"capi_autoscaler_status",
+		"Report the cluster-autoscaler activity of a workload cluster from its status ConfigMap and events: scale-ups, scale-downs, backoffs and unschedulable pods per node group, tied back to the MachineDeployments",
+	)
+
+	addTool(s, autoscalerStatusTool, createAutoscalerStatusHandler(serverCtx))
+
+	// etcd runs on the control plane nodes
+	registerEtcdTools(s, serverCtx)
+
+	// Nodes stay NotReady without their addons
+	registerAddonTools(s, serverCtx)
+}
+
+// createDrainNodeHandler creates a handler for draining nodes
+func createDrainNodeHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+
+		// Build options
+		opts := capi.NodeOperationOptions{}
+
+		// Either namespace+machineName or nodeName is required
+		namespace, _ := arguments["namespace"].(string)
+		machineName, _ := arguments["machine_name"].(string)
+		nodeName, _ := arguments["node_name"].(string)
+
+		if nodeName == "" && (namespace == "" || machineName == "") {
+			return invalidArgument("either node_name or (namespace and machine_name) must be provided")
+		}
+
+		opts.Namespace = namespace
+		opts.MachineName = machineName
+		opts.NodeName = nodeName
+
+		// Optional parameters
+		opts.IgnoreDaemonSets, _ = arguments["ignore_daemonsets"].(bool)
+		opts.DeleteLocalData, _ = arguments["delete_local_data"].(bool)
+		opts.Force, _ = arguments["force"].(bool)
+
+		if gracePeriodFloat, ok := arguments["grace_period_seconds"].(float64); ok {
+			gracePeriod := int32(gracePeriodFloat)
+			opts.GracePeriodSeconds = &gracePeriod
+		}
+
+		// Drain the node
+		err := serverCtx.client(ctx).DrainNode(ctx, opts)
+		if err != nil {
+			// The node was cordoned but pod eviction is not implemented yet
+			if errors.Is(err, capi.ErrDrainIncomplete) {
+				var content strings.Builder
+				content.WriteString("⚠️  Node drain partially implemented\n\n")
+				content.WriteString(fmt.Sprintf("Node has been cordoned (marked as unschedulable)\n"))
+				content.WriteString("\nFull drain implementation would:\n")
+				content.WriteString("1. List all pods on the node\n")
+				content.WriteString("2. Filter out DaemonSet pods if requested\n")
+				content.WriteString("3. Create pod evictions respecting PodDisruptionBudgets\n")
+				content.WriteString("4. Wait for pods to terminate gracefully\n")
+				content.WriteString("5. Force delete pods that exceed grace period\n\n")
+				content.WriteString("For now, you can manually drain using kubectl:\n")
+				if nodeName != "" {
+					content.WriteString(fmt.Sprintf("  kubectl drain %s --ignore-daemonsets --delete-emptydir-data\n", nodeName))
+				}
+
+				return newToolResult(content.String(), map[string]any{"operation": "drain", "target": map[string]any{"nodeName": nodeName, "namespace": namespace, "machineName": machineName}, "cordoned": true, "drained": false})
+			}
+			return toolError(fmt.Errorf("failed to drain node: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString("✅ Successfully drained node\n\n")
+		content.WriteString("Drain Options Applied:\n")
+		content.WriteString(fmt.Sprintf("  • Ignore DaemonSets: %v\n", opts.IgnoreDaemonSets))
+		content.WriteString(fmt.Sprintf("  • Delete Local Data: %v\n", opts.DeleteLocalData))
+		content.WriteString(fmt.Sprintf("  • Force: %v\n", opts.Force))
+		if opts.GracePeriodSeconds != nil {
+			content.WriteString(fmt.Sprintf("  • Grace Period: %d seconds\n", *opts.GracePeriodSeconds))
+		}
+		content.WriteString("\nThe node is now:\n")
+		content.WriteString("• Cordoned (no new pods will be scheduled)\n")
+		content.WriteString("• Drained (existing pods have been evicted)\n")
+
+		return newToolResult(content.String(), map[string]any{"operation": "drain", "target": map[string]any{"nodeName": nodeName, "namespace": namespace, "machineName": machineName}, "cordoned": true, "drained": true})
+	}
+}
+
+// createCordonNodeHandler creates a handler for cordoning/uncordoning nodes
+func createCordonNodeHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+
+		// Build options
+		opts := capi.NodeOperationOptions{}
+
+		// Either namespace+machineName or nodeName is required
+		namespace, _ := arguments["namespace"].(string)
+		machineName, _ := arguments["machine_name"].(string)
+		nodeName, _ := arguments["node_name"].(string)
+
+		if nodeName == "" && (namespace == "" || machineName == "") {
+			return invalidArgument("either node_name or (namespace and machine_name) must be provided")
+		}
+
+		opts.Namespace = namespace
+		opts.MachineName = machineName
+		opts.NodeName = nodeName
+		opts.Uncordon, _ = arguments["uncordon"].(bool)
+
+		// Cordon/uncordon the node
+		err := serverCtx.client(ctx).CordonNode(ctx, opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to update node: %w", err))
+		}
+
+		var content strings.Builder
+		action := "cordoned"
+		if opts.Uncordon {
+			action = "uncordoned"
+		}
+
+		content.WriteString(fmt.Sprintf("✅ Successfully %s node\n\n", action))
+
+		if opts.Uncordon {
+			content.WriteString("The node is now:\n")
+			content.WriteString("• Schedulable (new pods can be scheduled on this node)\n")
+			content.WriteString("• Ready to accept workloads\n")
+		} else {
+			content.WriteString("The node is now:\n")
+			content.WriteString("• Unschedulable (no new pods will be scheduled)\n")
+			content.WriteString("• Existing pods will continue running\n\n")
+			content.WriteString("To drain the node and evict pods, use:\n")
+			content.WriteString("  capi_drain_node\n")
+		}
+
+		return newToolResult(content.String(), map[string]any{"operation": strings.TrimSuffix(action, "ed"), "target": map[string]any{"nodeName": nodeName, "namespace": namespace, "machineName": machineName}, "unschedulable": !opts.Uncordon})
+	}
+}
+
+// createNodeStatusHandler creates a handler for getting node status
+func createNodeStatusHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+
+		// Build options
+		opts := capi.NodeOperationOptions{}
+
+		// Either namespace+machineName or nodeName is required
+		namespace, _ := arguments["namespace"].(string)
+		machineName, _ := arguments["machine_name"].(string)
+		nodeName, _ := arguments["node_name"].(string)
+
+		if nodeName == "" && (namespace == "" || machineName == "") {
+			return invalidArgument("either node_name or (namespace and machine_name) must be provided")
+		}
+
+		opts.Namespace = namespace
+		opts.MachineName = machineName
+		opts.NodeName = nodeName
+
+		// Get node status
+		node, err := serverCtx.client(ctx).GetNodeStatus(ctx, opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get node status: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Node: %s\n\n", node.Name))
+
+		// Basic information
+		content.WriteString("Basic Information:\n")
+		content.WriteString(fmt.Sprintf("  UID: %s\n", node.UID))
+		content.WriteString(fmt.Sprintf("  Created: %s\n", node.CreationTimestamp))
+		content.WriteString(fmt.Sprintf("  Schedulable: %v\n", !node.Spec.Unschedulable))
+		if node.Spec.ProviderID != "" {
+			content.WriteString(fmt.Sprintf("  Provider ID: %s\n", node.Spec.ProviderID))
+		}
+
+		// Node info
+		info := node.Status.NodeInfo
+		content.WriteString("\nNode Info:\n")
+		content.WriteString(fmt.Sprintf("  OS: %s (%s)\n", info.OperatingSystem, info.OSImage))
+		content.WriteString(fmt.Sprintf("  Kernel: %s\n", info.KernelVersion))
+		content.WriteString(fmt.Sprintf("  Container Runtime: %s\n", info.ContainerRuntimeVersion))
+		content.WriteString(fmt.Sprintf("  Kubelet: %s\n", info.KubeletVersion))
+		content.WriteString(fmt.Sprintf("  Architecture: %s\n", info.Architecture))
+
+		// Capacity and allocatable resources
+		content.WriteString("\nResources:\n")
+		content.WriteString("  Capacity:\n")
+		if cpu := node.Status.Capacity[v1.ResourceCPU]; !cpu.IsZero() {
+			content.WriteString(fmt.Sprintf("    CPU: %s\n", cpu.String()))
+		}
+		if memory := node.Status.Capacity[v1.ResourceMemory]; !memory.IsZero() {
+			content.WriteString(fmt.Sprintf("    Memory: %s\n", memory.String()))
+		}
+		if pods := node.Status.Capacity[v1.ResourcePods]; !pods.IsZero() {
+			content.WriteString(fmt.Sprintf("    Pods: %s\n", pods.String()))
+		}
+
+		content.WriteString("  Allocatable:\n")
+		if cpu := node.Status.Allocatable[v1.ResourceCPU]; !cpu.IsZero() {
+			content.WriteString(fmt.Sprintf("    CPU: %s\n", cpu.String()))
+		}
+		if memory := node.Status.Allocatable[v1.ResourceMemory]; !memory.IsZero() {
+			content.WriteString(fmt.Sprintf("    Memory: %s\n", memory.String()))
+		}
+		if pods := node.Status.Allocatable[v1.ResourcePods]; !pods.IsZero() {
+			content.WriteString(fmt.Sprintf("    Pods: %s\n", pods.String()))
+		}
+
+		// Conditions
+		content.WriteString("\nConditions:\n")
+		for _, condition := range node.Status.Conditions {
+			content.WriteString(fmt.Sprintf("  - Type: %s\n", condition.Type))
+			content.WriteString(fmt.Sprintf("    Status: %s\n", condition.Status))
+			if condition.Reason != "" {
+				content.WriteString(fmt.Sprintf("    Reason: %s\n", condition.Reason))
+			}
+			if condition.Message != "" {
+				content.WriteString(fmt.Sprintf("    Message: %s\n", condition.Message))
+			}
+		}
+
+		// Addresses
+		if len(node.Status.Addresses) > 0 {
+			content.WriteString("\nAddresses:\n")
+			for _, addr := range node.Status.Addresses {
+				content.WriteString(fmt.Sprintf("  - %s: %s\n", addr.Type, addr.Address))
+			}
+		}
+
+		// Taints
+		if len(node.Spec.Taints) > 0 {
+			content.WriteString("\nTaints:\n")
+			for _, taint := range node.Spec.Taints {
+				content.WriteString(fmt.Sprintf("  - Key: %s\n", taint.Key))
+				if taint.Value != "" {
+					content.WriteString(fmt.Sprintf("    Value: %s\n", taint.Value))
+				}
+				content.WriteString(fmt.Sprintf("    Effect: %s\n", taint.Effect))
+			}
+		}
+
+		return newToolResult(content.String(), trimObject(node))
+	}
+}
+
+// nodeReportParams declares the arguments of capi_node_report
+var nodeReportParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+}
+
+// createNodeReportHandler creates a handler correlating Machines and Nodes
+func createNodeReportHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := nodeReportParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+
+		report, err := serverCtx.client(ctx).GetNodeReport(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to correlate machines and nodes: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Machines and nodes of cluster %s/%s: %d machines, %d nodes\n\n", namespace, name, report.Machines, report.Nodes))
+		if len(report.Issues) == 0 {
+			content.WriteString("✅ Every machine has its node, with matching provider IDs and versions\n")
+		} else {
+			content.WriteString(fmt.Sprintf("Issues (%d):\n", len(report.Issues)))
+			for _, issue := range report.Issues {
+				content.WriteString(fmt.Sprintf("  ⚠️  %s", issue.Kind))
+				if issue.Machine != "" {
+					content.WriteString(fmt.Sprintf(" machine %s", issue.Machine))
+				}
+				if issue.Node != "" {
+					content.WriteString(fmt.Sprintf(" node %s", issue.Node))
+				}
+				content.WriteString(fmt.Sprintf(": %s\n", issue.Message))
+			}
+		}
+
+		content.WriteString("\nPairs:\n")
+		for _, pair := range report.Pairs {
+			owner := pair.Machine
+			if owner == "" && pair.MachinePool != "" {
+				owner = "MachinePool " + pair.MachinePool
+			}
+			if owner == "" {
+				owner = "-"
+			}
+			node := pair.Node
+			if node == "" {
+				node = "-"
+			}
+			content.WriteString(fmt.Sprintf("  - %s → %s", owner, node))
+			if pair.KubeletVersion != "" {
+				content.WriteString(fmt.Sprintf(" (kubelet %s, ready: %v)", pair.KubeletVersion, pair.Ready))
+			}
+			content.WriteString("\n")
+		}
+
+		return newToolResult(content.String(), report)
+	}
+}
+
+// nodePodsParams declares the arguments of capi_node_pods
+var nodePodsParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the machine or cluster"},
+	{Name: "machine_name", Type: params.String, Description: "Machine whose node to list the pods of"},
+	{Name: "cluster_name", Type: params.String, Description: "Cluster of node_name (required with node_name)"},
+	{Name: "node_name", Type: params.String, Description: "Node to list the pods of, instead of machine_name"},
+}
+
+// createNodePodsHandler creates a handler listing the pods of a node
+func createNodePodsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := nodePodsParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		clusterName := args.String("cluster_name")
+		nodeName := args.String("node_name")
+		client := serverCtx.client(ctx)
+
+		if machineName := args.String("machine_name"); machineName != "" {
+			machine, err := client.GetMachine(ctx, namespace, machineName)
+			if err != nil {
+				return toolError(err)
+			}
+			if machine.Status.NodeRef == nil {
+				return toolError(fmt.Errorf("machine %s has no node: %w", machineName, capi.ErrPreconditionFailed))
+			}
+			clusterName = machine.Spec.ClusterName
+			nodeName = machine.Status.NodeRef.Name
+		} else if clusterName == "" || nodeName == "" {
+			return invalidArgument("either machine_name or cluster_name and node_name must be provided")
+		}
+
+		result, err := client.ListNodePods(ctx, namespace, clusterName, nodeName)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list the pods of node %s: %w", nodeName, err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Node %s of cluster %s/%s runs %d pods\n", nodeName, namespace, clusterName, result.Pods))
+		content.WriteString(fmt.Sprintf("  DaemonSet pods (stay during a drain): %d\n", result.DaemonSetPods))
+		content.WriteString(fmt.Sprintf("  Static pods (stay during a drain): %d\n", result.MirrorPods))
+		content.WriteString(fmt.Sprintf("  Pods without controller (lost unless forced): %d\n", result.UnmanagedPods))
+		content.WriteString(fmt.Sprintf("  Pods with local storage (emptyDir data lost): %d\n", result.LocalStoragePods))
+		content.WriteString(fmt.Sprintf("  Pods blocked by a PodDisruptionBudget: %d\n\n", result.BlockedPods))
+
+		for _, group := range result.Groups {
+			if group.Kind == "" {
+				content.WriteString(fmt.Sprintf("%s (no controller):\n", group.Namespace))
+			} else {
+				content.WriteString(fmt.Sprintf("%s %s/%s:\n", group.Kind, group.Namespace, group.Name))
+			}
+			for _, pod := range group.Pods {
+				var flags []string
+				if pod.Mirror {
+					flags = append(flags, "static")
+				}
+				if pod.LocalStorage {
+					flags = append(flags, "local storage")
+				}
+				if pod.PDB != "" {
+					flags = append(flags, fmt.Sprintf("PDB %s allows %d disruptions", pod.PDB, pod.DisruptionsAllowed))
+				}
+				content.WriteString(fmt.Sprintf("  - %s (%s)", pod.Name, pod.Phase))
+				if len(flags) > 0 {
+					content.WriteString(" [" + strings.Join(flags, ", ") + "]")
+				}
+				content.WriteString("\n")
+			}
+		}
+
+		return newToolResult(content.String(), result)
+	}
+}
+
+// pdbConflictsParams declares the arguments of capi_pdb_conflicts
+var pdbConflictsParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "cluster_name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "operation", Type: params.String, Required: true, Enum: []string{"drain", "scale-down", "upgrade"}, Description: "Planned operation"},
+	{Name: "node_name", Type: params.String, Description: "Node to drain"},
+	{Name: "machine_name", Type: params.String, Description: "Machine whose node to drain, instead of node_name"},
+	{Name: "machine_deployment", Type: params.String, Description: "Machine deployment to scale down (required for scale-down), or to limit an upgrade to"},
+}
+
+// createPDBConflictsHandler creates a handler checking PodDisruptionBudgets
+// against a planned operation
+func createPDBConflictsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := pdbConflictsParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		clusterName := args.String("cluster_name")
+
+		analysis, err := serverCtx.client(ctx).AnalyzePDBConflicts(ctx, namespace, clusterName, capi.PDBAnalysisOptions{
+			Operation:         capi.DisruptionOperation(args.String("operation")),
+			Node:              args.String("node_name"),
+			Machine:           args.String("machine_name"),
+			MachineDeployment: args.String("machine_deployment"),
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to analyze pod disruption budgets: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("PodDisruptionBudgets for a %s of cluster %s/%s\n", analysis.Operation, namespace, clusterName))
+		content.WriteString(fmt.Sprintf("Nodes: %s\n", strings.Join(analysis.Nodes, ", ")))
+		content.WriteString(fmt.Sprintf("Pods to evict: %d\n\n", analysis.Pods))
+		if len(analysis.Nodes) == 0 {
+			content.WriteString("No node is affected.\n")
+		} else if len(analysis.Budgets) == 0 {
+			content.WriteString("✅ No PodDisruptionBudget protects the evicted pods.\n")
+		}
+
+		for _, budget := range analysis.Budgets {
+			icon := "✅"
+			switch {
+			case budget.Blocking:
+				icon = "❌"
+			case budget.Reason != "":
+				icon = "⚠️"
+			}
+			content.WriteString(fmt.Sprintf("%s %s/%s: %d disruptions allowed, %d/%d pods healthy (%d required)\n",
+				icon, budget.Namespace, budget.Name, budget.DisruptionsAllowed, budget.CurrentHealthy, budget.ExpectedPods, budget.DesiredHealthy))
+			content.WriteString(fmt.Sprintf("   Evicted pods: %d on %s\n", budget.AffectedPods, strings.Join(budget.AffectedNodes, ", ")))
+			if budget.Reason != "" {
+				content.WriteString(fmt.Sprintf("   %s\n", budget.Reason))
+			}
+		}
+
+		if analysis.Blocked {
+			content.WriteString("\n❌ Evictions would be blocked: relax or fix the blocking budgets, or scale up their workloads, before starting the operation.\n")
+		}
+
+		return newToolResult(content.String(), analysis)
+	}
+}
+
+// clusterCapacityParams declares the arguments of capi_cluster_capacity
+var clusterCapacityParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+}
+
+// createClusterCapacityHandler creates a handler reporting the capacity of a
+// workload cluster
+func createClusterCapacityHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := clusterCapacityParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+
+		capacity, err := serverCtx.client(ctx).GetClusterCapacity(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get the capacity of cluster %s: %w", name, err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Capacity of cluster %s/%s (%d nodes)\n\n", namespace, name, len(capacity.Nodes)))
+		content.WriteString(formatCapacity("Total", capacity.Total))
+		if !capacity.MetricsAvailable {
+			if capacity.MetricsError != "" {
+				content.WriteString(fmt.Sprintf("  Usage unavailable: %s\n", capacity.MetricsError))
+			} else {
+				content.WriteString("  Usage unavailable: metrics-server is not installed\n")
+			}
+		}
+		content.WriteString("\n")
+
+		for _, node := range capacity.Nodes {
+			var flags []string
+			if !node.Ready {
+				flags = append(flags, "NotReady")
+			}
+			if node.Unschedulable {
+				flags = append(flags, "cordoned")
+			}
+			flags = append(flags, node.Pressure...)
+			label := node.Name
+			if len(flags) > 0 {
+				label += " ⚠️  " + strings.Join(flags, ", ")
+			}
+			content.WriteString(formatCapacity(label, node.ResourceCapacity))
+		}
+
+		if capacity.PressureNodes > 0 {
+			content.WriteString(fmt.Sprintf("\n⚠️  %d nodes are under resource pressure and evict pods: add capacity before scaling workloads up\n", capacity.PressureNodes))
+		}
+
+		return newToolResult(content.String(), capacity)
+	}
+}
+
+// formatCapacity describes the CPU, memory and pods of a node or cluster
+func formatCapacity(label string, capacity capi.ResourceCapacity) string {
+	percent := func(part, total int64) float64 {
+		if total == 0 {
+			return 0
+		}
+		return float64(part) * 100 / float64(total)
+	}
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("%s:\n", label))
+	content.WriteString(fmt.Sprintf("  CPU: %dm of %dm requested (%.0f%%)", capacity.RequestedCPU, capacity.AllocatableCPU, percent(capacity.RequestedCPU, capacity.AllocatableCPU)))
+	if capacity.UsedCPU != nil {
+		content.WriteString(fmt.Sprintf(", %dm used (%.0f%%)", *capacity.UsedCPU, percent(*capacity.UsedCPU, capacity.AllocatableCPU)))
+	}
+	content.WriteString(fmt.Sprintf("\n  Memory: %s of %s requested (%.0f%%)", formatBytes(capacity.RequestedMemory), formatBytes(capacity.AllocatableMemory), percent(capacity.RequestedMemory, capacity.AllocatableMemory)))
+	if capacity.UsedMemory != nil {
+		content.WriteString(fmt.Sprintf(", %s used (%.0f%%)", formatBytes(*capacity.UsedMemory), percent(*capacity.UsedMemory, capacity.AllocatableMemory)))
+	}
+	content.WriteString(fmt.Sprintf("\n  Pods: %d of %d\n", capacity.Pods, capacity.AllocatablePods))
+	return content.String()
+}
+
+// topNodesParams declares the arguments of capi_top_nodes
+var topNodesParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "sort_by", Type: params.String, Default: "cpu", Enum: []string{"cpu", "memory"}, Description: "Resource to sort the nodes by, busiest first"},
+}
+
+// createTopNodesHandler creates a handler showing the usage of workload nodes
+func createTopNodesHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := topNodesParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+
+		top, err := serverCtx.client(ctx).TopNodes(ctx, namespace, name, args.String("sort_by"))
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get the node usage of cluster %s: %w", name, err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Node usage of cluster %s/%s\n\n", namespace, name))
+		content.WriteString(fmt.Sprintf("%-32s %-8s %-5s %-10s %-7s %-24s %s\n", "NODE", "CPU", "CPU%", "MEMORY", "MEMORY%", "MACHINE", "OWNER"))
+		for _, node := range top {
+			owner := node.MachineDeployment
+			if node.ControlPlane {
+				owner = "control plane"
+			}
+			machine := node.Machine
+			if machine == "" {
+				machine = "-"
+			}
+			content.WriteString(fmt.Sprintf("%-32s %-8s %-5s %-10s %-7s %-24s %s\n",
+				node.Node,
+				fmt.Sprintf("%dm", node.CPU),
+				fmt.Sprintf("%.0f%%", node.CPUPercent),
+				formatBytes(node.Memory),
+				fmt.Sprintf("%.0f%%", node.MemoryPercent),
+				machine,
+				owner))
+		}
+
+		return newToolResult(content.String(), map[string]any{"nodes": top})
+	}
+}
+
+// autoscalerStatusParams declares the arguments of capi_autoscaler_status
+var autoscalerStatusParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "autoscaler_namespace", Type: params.String, Default: "kube-system", Description: "Namespace of the workload cluster the autoscaler runs in"},
+}
+
+// createAutoscalerStatusHandler creates a handler reporting the activity of
+// the cluster-autoscaler of a workload cluster
+func createAutoscalerStatusHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := autoscalerStatusParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		name := args.String("name")
+
+		status, err := serverCtx.client(ctx).GetAutoscalerStatus(ctx, namespace, name, args.String("autoscaler_namespace"))
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get the autoscaler status of cluster %s: %w", name, err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Cluster autoscaler of %s/%s (namespace %s)\n", namespace, name, status.Namespace))
+		if status.Raw != "" {
+			content.WriteString("\n" + status.Raw + "\n")
+		} else {
+			content.WriteString(fmt.Sprintf("  Updated: %s\n", status.Time))
+			content.WriteString(fmt.Sprintf("  Health: %s, scale-up: %s, scale-down: %s\n", status.Health, status.ScaleUp, status.ScaleDown))
+		}
+		content.WriteString(fmt.Sprintf("  Unschedulable pods: %d\n", status.UnschedulablePods))
+
+		if len(status.NodeGroups) > 0 {
+			content.WriteString("\nNode groups:\n")
+		}
+		for _, group := range status.NodeGroups {
+			content.WriteString(fmt.Sprintf("  %s: %s, %d/%d ready, target %d (min %d, max %d)\n",
+				group.Name, group.Health, group.Ready, group.Registered, group.Target, group.MinSize, group.MaxSize))
+			if group.MachineDeployment != "" && group.Replicas != nil {
+				content.WriteString(fmt.Sprintf("    MachineDeployment %s: %d replicas\n", group.MachineDeployment, *group.Replicas))
+			}
+			content.WriteString(fmt.Sprintf("    Scale-up: %s, scale-down: %s", group.ScaleUp, group.ScaleDown))
+			if group.ScaleDownCandidates > 0 {
+				content.WriteString(fmt.Sprintf(" (%d candidates)", group.ScaleDownCandidates))
+			}
+			content.WriteString("\n")
+			if group.Backoff != "" {
+				content.WriteString(fmt.Sprintf("    ⚠️  Backoff: %s\n", group.Backoff))
+			}
+			if group.Target >= group.MaxSize && group.MaxSize > 0 && status.UnschedulablePods > 0 {
+				content.WriteString("    ⚠️  At its maximum size while pods are unschedulable\n")
+			}
+			if group.TriggeredScaleUps > 0 {
+				content.WriteString(fmt.Sprintf("    Recent scale-ups triggered by pending pods: %d\n", group.TriggeredScaleUps))
+			}
+		}
+
+		if len(status.UnmanagedMachineDeployments) > 0 {
+			content.WriteString(fmt.Sprintf("\n⚠️  MachineDeployments with autoscaler annotations unknown to the autoscaler: %s\n", strings.Join(status.UnmanagedMachineDeployments, ", ")))
+			content.WriteString("   Check its --node-group-auto-discovery flag and its access to the management cluster.\n")
+		}
+
+		if len(status.Events) > 0 {
+			content.WriteString("\nRecent events:\n")
+		}
+		for _, event := range status.Events {
+			content.WriteString(fmt.Sprintf("  %s %s %s: %s\n", event.Time.Format(time.RFC3339), event.Reason, event.Object, event.Message))
+		}
+
+		return newToolResult(content.String(), status)
+	}
+}
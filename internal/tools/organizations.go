@@ -0,0 +1,119 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/auth"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// organizationArgument names a Giant Swarm organization instead of its
+// namespace
+const organizationArgument = "organization"
+
+// ownOrganizationTools use the organization argument for more than the
+// namespace: it only defaults their namespace and is passed on
+var ownOrganizationTools = map[string]bool{
+	"capi_prepare_namespace": true,
+}
+
+// withOrganization adds the organization argument to a tool taking a namespace
+func withOrganization(tool *mcp.Tool) {
+	mcp.WithString(organizationArgument,
+		mcp.Description("Giant Swarm organization whose namespace org-<organization> to use (optional, alternative to namespace)"),
+	)(tool)
+}
+
+// resolveOrganization sets the namespace of a call from its organization. It
+// returns the arguments unchanged when there is no organization.
+func resolveOrganization(name string, arguments map[string]any) (map[string]any, error) {
+	organization, _ := arguments[organizationArgument].(string)
+	if organization == "" {
+		return arguments, nil
+	}
+	namespace := capi.OrganizationNamespace(organization)
+	if msgs := validation.IsDNS1123Label(namespace); len(msgs) > 0 {
+		return nil, fmt.Errorf("invalid organization %q: %s", organization, strings.Join(msgs, "; "))
+	}
+	current, _ := arguments["namespace"].(string)
+	if ownOrganizationTools[name] {
+		if current != "" {
+			return arguments, nil
+		}
+		arguments = maps.Clone(arguments)
+		arguments["namespace"] = namespace
+		return arguments, nil
+	}
+	if current != "" && current != namespace {
+		return nil, fmt.Errorf("the namespace of organization %s is %s, not %s", organization, namespace, current)
+	}
+	arguments = maps.Clone(arguments)
+	delete(arguments, organizationArgument)
+	arguments["namespace"] = namespace
+	return arguments, nil
+}
+
+// NewOrganizationMiddleware resolves the organization argument to the
+// namespace argument before any other middleware sees the call, so
+// authorization, auditing and the tools only deal with namespaces
+func NewOrganizationMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			arguments, err := resolveOrganization(request.Params.Name, request.GetArguments())
+			if err != nil {
+				return invalidArgument("%v", err)
+			}
+			request.Params.Arguments = arguments
+			return next(ctx, request)
+		}
+	}
+}
+
+// registerOrganizationTools adds the Giant Swarm organization tools
+func registerOrganizationTools(s Registry, serverCtx *ServerContext) {
+	listOrganizationsTool := mcp.NewTool(
+		"capi_list_organizations",
+		mcp.WithDescription("List the Giant Swarm organizations (org-* namespaces) of the management cluster with their clusters. Other tools accept an organization argument instead of the namespace."),
+	)
+	addTool(s, listOrganizationsTool, createListOrganizationsHandler(serverCtx))
+}
+
+// createListOrganizationsHandler lists the organizations and their clusters
+func createListOrganizationsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		organizations, err := serverCtx.client(ctx).ListOrganizations(ctx)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list organizations: %w", err))
+		}
+		// Restricted identities only see the organizations of their namespaces
+		if identity, ok := auth.IdentityFromContext(ctx); ok && identity.Policy.Restricted() {
+			visible := []capi.Organization{}
+			for _, org := range organizations {
+				if identity.Policy.AllowsNamespace(org.Namespace) {
+					visible = append(visible, org)
+				}
+			}
+			organizations = visible
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Organizations (%d):\n\n", len(organizations)))
+		for _, org := range organizations {
+			content.WriteString(fmt.Sprintf("- %s (namespace %s): %d clusters\n", org.Name, org.Namespace, len(org.Clusters)))
+			if len(org.Clusters) > 0 {
+				content.WriteString(fmt.Sprintf("  %s\n", strings.Join(org.Clusters, ", ")))
+			}
+		}
+		if len(organizations) == 0 {
+			content.WriteString("No organizations found.\n")
+		}
+
+		return newToolResult(content.String(), map[string]any{"organizations": organizations})
+	}
+}
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// createCheckPermissionsHandler creates a handler for checking the server's RBAC permissions
+func createCheckPermissionsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		verb, _ := arguments["verb"].(string)
+		resource, _ := arguments["resource"].(string)
+		group, _ := arguments["group"].(string)
+		subresource, _ := arguments["subresource"].(string)
+		namespace, _ := arguments["namespace"].(string)
+		name, _ := arguments["name"].(string)
+
+		if (verb == "") != (resource == "") {
+			return invalidArgument("verb and resource must be provided together (omit both to check the default capability set)")
+		}
+
+		var checks []capi.PermissionCheck
+		if verb != "" {
+			checks = []capi.PermissionCheck{{
+				Verb:        verb,
+				Group:       group,
+				Resource:    resource,
+				Subresource: subresource,
+				Namespace:   namespace,
+				Name:        name,
+			}}
+		} else {
+			checks = capi.DefaultPermissionChecks(namespace)
+		}
+
+		results, err := serverCtx.client(ctx).CheckPermissions(ctx, checks)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to check permissions: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString("RBAC Permission Check:\n\n")
+
+		var missing []capi.PermissionCheck
+		for _, result := range results {
+			if result.Allowed {
+				content.WriteString(fmt.Sprintf("  ✅ %s\n", result.PermissionCheck))
+			} else {
+				missing = append(missing, result.PermissionCheck)
+				content.WriteString(fmt.Sprintf("  ❌ %s", result.PermissionCheck))
+				if result.Reason != "" {
+					content.WriteString(fmt.Sprintf(" (%s)", result.Reason))
+				}
+				content.WriteString("\n")
+			}
+		}
+
+		if len(missing) == 0 {
+			content.WriteString("\nAll checked permissions are granted.\n")
+		} else {
+			content.WriteString(fmt.Sprintf("\n⚠️  %d of %d permissions are missing.\n", len(missing), len(results)))
+			content.WriteString(fmt.Sprintf("%s\n", (&capi.MissingPermissionsError{Missing: missing}).Error()))
+		}
+
+		return newToolResult(content.String(), map[string]any{"results": results, "missing": missing})
+	}
+}
@@ -0,0 +1,143 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerPivotTools adds the tool making workload clusters manage themselves
+func registerPivotTools(s Registry, serverCtx *ServerContext) {
+	pivotClusterTool := pivotClusterParams.NewTool(
+		"capi_pivot_cluster",
+		"Make a workload cluster manage itself, like clusterctl init and clusterctl move: install the providers of the management cluster on it, move its Cluster API objects into it with their owner references re-linked, remove them from the management cluster and verify that its own controllers reconcile it to Ready. A failed move is rolled back.",
+		withApprovalID(),
+	)
+	addTool(s, pivotClusterTool, createPivotClusterHandler(serverCtx))
+}
+
+// pivotClusterParams declares the arguments of capi_pivot_cluster
+var pivotClusterParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Validate: params.Namespace, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Validate: params.KubernetesName, Description: "Name of the cluster"},
+	{Name: "providers", Type: params.String,
+		Description: "Comma-separated providers to install on the cluster with optional versions, e.g. cluster-api:v1.10.2,infrastructure-aws:v2.8.1 (default: the core, bootstrap, control plane and infrastructure providers of the management cluster in their versions)"},
+	{Name: "provider_timeout_seconds", Type: params.Int, Default: 600, NonNegative: true,
+		Description: "Maximum time to wait for the provider controllers on the cluster to become ready, in seconds (default: 600)"},
+	{Name: "verify_timeout_seconds", Type: params.Int, Default: 900, NonNegative: true,
+		Description: "Maximum time to wait for the moved cluster to become Ready under its own controllers, in seconds (default: 900)"},
+	{Name: "dry_run", Type: params.Bool, Description: "Validate the provider components and list the objects that would move, without changing anything (default: false)"},
+	asyncParam,
+}
+
+// createPivotClusterHandler creates a handler for pivoting clusters
+func createPivotClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := pivotClusterParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace, name := args.String("namespace"), args.String("name")
+		opts := capi.PivotOptions{
+			Namespace:       namespace,
+			Name:            name,
+			Repository:      serverCtx.providerRepository(),
+			Providers:       providerSpecs(args.String("providers")),
+			Variables:       os.LookupEnv,
+			ProviderTimeout: time.Duration(args.Int("provider_timeout_seconds")) * time.Second,
+			VerifyTimeout:   time.Duration(args.Int("verify_timeout_seconds")) * time.Second,
+			DryRun:          args.Bool("dry_run"),
+		}
+		client := serverCtx.client(ctx)
+
+		if args.Bool(asyncArgument) {
+			job, err := serverCtx.startJob(ctx, "pivot", namespace, namespace+"/"+name, func(ctx context.Context, logf func(format string, args ...any)) (any, error) {
+				opts.OnProgress = func(step capi.PivotStep) {
+					logf("%s: %s %s", step.Name, step.Status, step.Message)
+				}
+				return client.PivotCluster(ctx, opts)
+			})
+			if err != nil {
+				return toolError(err)
+			}
+			message := fmt.Sprintf("Pivoting cluster %s/%s in job %s. Check it with capi_job_status or capi_job_logs.", namespace, name, job.ID)
+			return newToolResult(message, map[string]any{"jobId": job.ID})
+		}
+
+		progress := 0
+		notify := progressNotifier(ctx, request)
+		opts.OnProgress = func(step capi.PivotStep) {
+			progress++
+			notify(progress, fmt.Sprintf("%s: %s", step.Name, step.Status))
+		}
+		pivot, err := client.PivotCluster(ctx, opts)
+		if pivot == nil {
+			return toolError(fmt.Errorf("failed to pivot cluster: %w", err))
+		}
+		details := map[string]any{"pivot": pivot}
+		if err != nil {
+			details["error"] = err.Error()
+		}
+		return newToolResult(formatPivot(pivot, err), operationResult{
+			Operation: "pivot",
+			Resource:  clusterRef(namespace, name),
+			Details:   details,
+		})
+	}
+}
+
+// formatPivot describes the steps of a pivot for the text result
+func formatPivot(pivot *capi.Pivot, err error) string {
+	var content strings.Builder
+	switch {
+	case pivot.DryRun:
+		content.WriteString(fmt.Sprintf("🔍 Dry run: pivoting cluster %s/%s would move %d objects into it\n", pivot.Namespace, pivot.Cluster, len(pivot.Moved)))
+	case err != nil:
+		content.WriteString(fmt.Sprintf("❌ Pivoting cluster %s/%s failed: %v\n", pivot.Namespace, pivot.Cluster, err))
+	case pivot.Verified:
+		content.WriteString(fmt.Sprintf("✅ Cluster %s/%s manages itself and is Ready under its own controllers\n", pivot.Namespace, pivot.Cluster))
+	default:
+		content.WriteString(fmt.Sprintf("⚠️  Cluster %s/%s was moved into itself but is not Ready yet\n", pivot.Namespace, pivot.Cluster))
+	}
+
+	content.WriteString("\nSteps:\n")
+	icons := map[string]string{"done": "✅", "skipped": "⏭️ ", "failed": "❌"}
+	for _, step := range pivot.Steps {
+		content.WriteString(fmt.Sprintf("  %s %s", icons[step.Status], step.Name))
+		if step.Message != "" {
+			content.WriteString(": " + step.Message)
+		}
+		content.WriteString("\n")
+	}
+	if len(pivot.Providers) > 0 {
+		content.WriteString("\nProviders on the cluster:\n")
+		for _, change := range pivot.Providers {
+			state := "installed " + summaryValue(change.TargetVersion)
+			if change.Note != "" {
+				state = change.Note
+			}
+			content.WriteString(fmt.Sprintf("  - %s (%s): %s\n", change.Name, change.Type, state))
+		}
+	}
+	if len(pivot.Moved) > 0 {
+		content.WriteString("\nObjects:\n")
+		for _, obj := range pivot.Moved {
+			content.WriteString(fmt.Sprintf("  - %s %s\n", obj.Kind, obj.Name))
+		}
+	}
+
+	if pivot.SelfManaged {
+		content.WriteString("\nThe cluster is no longer on this management cluster. Manage it with the admin kubeconfig of the cluster itself, e.g. through a management cluster entry in the server configuration.\n")
+		if !pivot.Verified {
+			content.WriteString("Check its conditions there, the controllers may still be catching up.\n")
+		}
+	}
+	return content.String()
+}
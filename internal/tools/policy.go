@@ -0,0 +1,195 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/toolpolicy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// readOnlyTools lists the tools that never modify cluster state
+var readOnlyTools = map[string]bool{
+	"test":                               true,
+	"capi_list_clusters":                 true,
+	"capi_get_cluster":                   true,
+	"capi_cluster_status":                true,
+	"capi_cluster_health":                true,
+	"capi_probe_api_server":              true,
+	"capi_get_kubeconfig":                true,
+	"capi_move_cluster":                  true,
+	"capi_backup_cluster":                true,
+	"capi_list_machines":                 true,
+	"capi_list_machine_annotations":      true,
+	"capi_get_machine":                   true,
+	"capi_list_machinedeployments":       true,
+	"capi_list_machinesets":              true,
+	"capi_get_machineset":                true,
+	"capi_node_status":                   true,
+	"capi_node_report":                   true,
+	"capi_node_pods":                     true,
+	"capi_pdb_conflicts":                 true,
+	"capi_cluster_capacity":              true,
+	"capi_top_nodes":                     true,
+	"capi_autoscaler_status":             true,
+	"capi_addons_status":                 true,
+	"capi_etcd_status":                   true,
+	"capi_list_infrastructure_providers": true,
+	"capi_server_info":                   true,
+	"capi_get_provider_config":           true,
+	"capi_provider_upgrade_plan":         true,
+	"capi_template_variables":            true,
+	"capi_validate_cluster_config":       true,
+	"capi_list_organizations":            true,
+	"capi_export_gitops":                 true,
+	"capi_list_apps":                     true,
+	"capi_get_app":                       true,
+	"capi_list_releases":                 true,
+	"capi_list_backups":                  true,
+	"capi_backup_status":                 true,
+	"capi_list_stored_backups":           true,
+	"capi_backup_history":                true,
+	"capi_controllers_status":            true,
+	"capi_list_identities":               true,
+	"capi_validate_identities":           true,
+	"capi_aws_list_clusters":             true,
+	"capi_aws_get_cluster":               true,
+	"capi_aws_get_machine_template":      true,
+	"capi_aws_diff_machine_templates":    true,
+	"capi_azure_list_clusters":           true,
+	"capi_azure_get_cluster":             true,
+	"capi_azure_list_aks_clusters":       true,
+	"capi_gcp_list_clusters":             true,
+	"capi_gcp_get_cluster":               true,
+	"capi_vsphere_list_clusters":         true,
+	"capi_vsphere_get_cluster":           true,
+	"capi_vsphere_list_vms":              true,
+	"capi_metal3_list_clusters":          true,
+	"capi_metal3_get_cluster":            true,
+	"capi_metal3_list_hosts":             true,
+	"capi_kubevirt_list_clusters":        true,
+	"capi_kubevirt_get_cluster":          true,
+	"capi_kubevirt_list_vms":             true,
+	"capi_discover_providers":            true,
+	"capi_list_infrastructure_objects":   true,
+	"capi_get_infrastructure_object":     true,
+	"capi_list_approvals":                true,
+	"capi_check_permissions":             true,
+	"capi_rbac_manifest":                 true,
+	"capi_audit_log":                     true,
+	"capi_list_changes":                  true,
+	"capi_list_management_clusters":      true,
+	"capi_job_status":                    true,
+	"capi_job_logs":                      true,
+	"capi_wait_for_ready":                true,
+	"capi_search":                        true,
+	"capi_fleet_summary":                 true,
+	"capi_find_stale_resources":          true,
+	"capi_export_inventory":              true,
+	"capi_canary_status":                 true,
+	"capi_list_schedules":                true,
+	"capi_upgrade_plan":                  true,
+	"capi_available_versions":            true,
+}
+
+// fileWrites tells for the read-only tools that can write files on the
+// server whether a call asked for it, which makes the call mutating
+var fileWrites = map[string]func(arguments map[string]any) bool{
+	"capi_get_kubeconfig": func(arguments map[string]any) bool {
+		path, _ := arguments["path"].(string)
+		return path != ""
+	},
+	"capi_export_gitops": func(arguments map[string]any) bool {
+		archive, _ := arguments["archive"].(string)
+		return archive != ""
+	},
+}
+
+// readOnlyCall reports whether a call neither modifies cluster state nor
+// writes files on the server
+func readOnlyCall(name string, arguments map[string]any) bool {
+	if writes, ok := fileWrites[name]; ok && writes(arguments) {
+		return false
+	}
+	return readOnlyTools[name]
+}
+
+// providerGroups maps tool name prefixes to provider groups
+var providerGroups = map[string]string{
+	"capi_aws_":      "aws",
+	"capi_azure_":    "azure",
+	"capi_gcp_":      "gcp",
+	"capi_vsphere_":  "vsphere",
+	"capi_metal3_":   "metal3",
+	"capi_kubevirt_": "kubevirt",
+}
+
+// toolGroups returns the groups a tool belongs to, usable as "group:<name>" in tool policies
+func toolGroups(name string) []string {
+	var groups []string
+
+	if isDestructiveTool(name) {
+		groups = append(groups, "destructive")
+	}
+	if readOnlyTools[name] {
+		groups = append(groups, "readonly")
+	} else {
+		groups = append(groups, "mutating")
+	}
+
+	for prefix, group := range providerGroups {
+		if strings.HasPrefix(name, prefix) {
+			return append(groups, group, "providers")
+		}
+	}
+
+	switch {
+	case strings.Contains(name, "provider"):
+		groups = append(groups, "providers")
+	case strings.Contains(name, "approv") || strings.HasSuffix(name, "_operation"):
+		groups = append(groups, "approvals")
+	case strings.Contains(name, "audit"):
+		groups = append(groups, "audit")
+	case strings.HasSuffix(name, "_change") || strings.HasSuffix(name, "_changes"):
+		groups = append(groups, "changes")
+	case strings.Contains(name, "node"):
+		groups = append(groups, "nodes")
+	case strings.Contains(name, "machine"):
+		groups = append(groups, "machines")
+	case strings.Contains(name, "cluster"):
+		groups = append(groups, "clusters")
+	}
+
+	return groups
+}
+
+// NewToolPolicyFilter hides disabled tools from tools/list
+func NewToolPolicyFilter(policy *toolpolicy.Policy) server.ToolFilterFunc {
+	return func(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+		if policy.IsEmpty() {
+			return tools
+		}
+		filtered := make([]mcp.Tool, 0, len(tools))
+		for _, tool := range tools {
+			if policy.Enabled(tool.Name, toolGroups(tool.Name)) {
+				filtered = append(filtered, tool)
+			}
+		}
+		return filtered
+	}
+}
+
+// NewToolPolicyMiddleware rejects calls to disabled tools
+func NewToolPolicyMiddleware(policy *toolpolicy.Policy) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			name := request.Params.Name
+			if !policy.Enabled(name, toolGroups(name)) {
+				return mcp.NewToolResultError(fmt.Sprintf("Tool '%s' is disabled on this server", name)), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// previewArgument makes a tool return the changes it would make instead of
+// making them
+const previewArgument = "preview"
+
+// previewTools lists the tools whose changes all go through updates of
+// existing objects, which a preview runs as server-side dry-runs. Tools that
+// create or delete objects cannot be previewed this way.
+var previewTools = map[string]bool{
+	"capi_update_cluster":            true,
+	"capi_upgrade_cluster":           true,
+	"capi_scale_cluster":             true,
+	"capi_scale_machinedeployment":   true,
+	"capi_update_machinedeployment":  true,
+	"capi_set_machine_annotation":    true,
+	"capi_remove_machine_annotation": true,
+}
+
+// withPreview adds the preview argument to a tool
+func withPreview(tool *mcp.Tool) {
+	mcp.WithBoolean(previewArgument,
+		mcp.Description("Return a field-level diff of every object that would change, from a server-side dry-run, without changing anything (default: false)"),
+	)(tool)
+}
+
+// isPreview reports whether a call only previews its changes
+func isPreview(name string, arguments map[string]any) bool {
+	preview, _ := arguments[previewArgument].(bool)
+	return preview && previewTools[name]
+}
+
+// NewPreviewMiddleware runs the calls with preview as server-side dry-runs
+// and replaces their result with the diff of the objects they would change
+func NewPreviewMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			arguments := request.GetArguments()
+			if !isPreview(request.Params.Name, arguments) {
+				return next(ctx, request)
+			}
+
+			// A preview has nothing to follow in the background
+			arguments = maps.Clone(arguments)
+			delete(arguments, asyncArgument)
+			request.Params.Arguments = arguments
+
+			ctx, preview := capi.WithPreview(ctx)
+			result, err := next(ctx, request)
+			if err != nil || result == nil || result.IsError {
+				return result, err
+			}
+			diffs := preview.Diffs()
+			return newToolResult(formatPreview(request.Params.Name, diffs), map[string]any{"preview": true, "diffs": diffs})
+		}
+	}
+}
+
+// formatPreview renders the diffs of a previewed call
+func formatPreview(name string, diffs []capi.ObjectDiff) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("🔍 Preview of %s, nothing was changed\n\n", name))
+	if len(diffs) == 0 {
+		content.WriteString("No object would change.\n")
+		return content.String()
+	}
+	for _, diff := range diffs {
+		content.WriteString(fmt.Sprintf("%s %s/%s:\n", diff.Kind, diff.Namespace, diff.Name))
+		for _, change := range diff.Changes {
+			content.WriteString(fmt.Sprintf("  - %s: %s → %s\n", change.Field, fieldChangeValue(change.From), fieldChangeValue(change.To)))
+		}
+	}
+	content.WriteString(fmt.Sprintf("\nMake the changes by calling %s again without preview\n", name))
+	return content.String()
+}
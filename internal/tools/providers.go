@@ -0,0 +1,635 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// registerProviderTools adds the generic infrastructure provider tools and
+// the tools of each supported provider
+func registerProviderTools(s Registry, serverCtx *ServerContext) {
+	listInfraProvidersTool := listInfrastructureProvidersParams.NewTool(
+		"capi_list_infrastructure_providers",
+		"List the providers installed on the management cluster with their versions, namespaces and controller health",
+	)
+	addTool(s, listInfraProvidersTool, createListInfrastructureProvidersHandler(serverCtx))
+
+	getProviderConfigTool := mcp.NewTool(
+		"capi_get_provider_config",
+		mcp.WithDescription("Get provider configuration requirements"),
+		mcp.WithString("provider",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("Provider name (%s)", strings.Join(capi.ProviderNames(), ", "))),
+		),
+	)
+	addTool(s, getProviderConfigTool, createGetProviderConfigHandler(serverCtx))
+
+	controllersStatusTool := controllersStatusParams.NewTool(
+		"capi_controllers_status",
+		"Check the health of the Cluster API controllers: replicas, restarts and images of the core, kubeadm and provider controller deployments and the webhook configurations calling them",
+	)
+	addTool(s, controllersStatusTool, createControllersStatusHandler(serverCtx))
+
+	checkWebhooksTool := checkWebhooksParams.NewTool(
+		"capi_check_webhooks",
+		"Check that the Cluster API admission webhooks work: CA bundles and serving certificates, ready service endpoints and a dry-run create of a canary Cluster, the usual suspects behind \"failed calling webhook\" and \"connection refused\" errors",
+	)
+	addTool(s, checkWebhooksTool, createCheckWebhooksHandler(serverCtx))
+
+	initProvidersTool := initProvidersParams.NewTool(
+		"capi_init_providers",
+		"Install Cluster API providers on the management cluster like clusterctl init. Components are fetched from the provider releases and applied with server-side apply; providers that are already installed are skipped.",
+		withApprovalID(),
+	)
+	addTool(s, initProvidersTool, createInitProvidersHandler(serverCtx))
+
+	providerUpgradePlanTool := providerUpgradePlanParams.NewTool(
+		"capi_provider_upgrade_plan",
+		"Show the installed version of each provider next to the latest release and whether it can be upgraded",
+	)
+	addTool(s, providerUpgradePlanTool, createProviderUpgradePlanHandler(serverCtx))
+
+	upgradeProvidersTool := upgradeProvidersParams.NewTool(
+		"capi_upgrade_providers",
+		"Upgrade installed providers like clusterctl upgrade apply, to the latest releases or to the given versions. Downgrades are refused.",
+		withApprovalID(),
+	)
+	addTool(s, upgradeProvidersTool, createUpgradeProvidersHandler(serverCtx))
+
+	registerIdentityTools(s, serverCtx)
+	registerAWSTools(s, serverCtx)
+	registerAzureTools(s, serverCtx)
+	registerGCPTools(s, serverCtx)
+	registerVSphereTools(s, serverCtx)
+	registerMetal3Tools(s, serverCtx)
+	registerKubevirtTools(s, serverCtx)
+}
+
+// providerClusterSummary is the structured form of a cluster in provider-specific listings
+type providerClusterSummary struct {
+	Namespace           string `json:"namespace"`
+	Name                string `json:"name"`
+	InfrastructureKind  string `json:"infrastructureKind"`
+	Phase               string `json:"phase"`
+	InfrastructureReady bool   `json:"infrastructureReady"`
+}
+
+// newProviderClusterSummary summarizes a cluster with an infrastructure reference
+func newProviderClusterSummary(cluster *clusterv1.Cluster) providerClusterSummary {
+	return providerClusterSummary{
+		Namespace:           cluster.Namespace,
+		Name:                cluster.Name,
+		InfrastructureKind:  cluster.Spec.InfrastructureRef.Kind,
+		Phase:               cluster.Status.Phase,
+		InfrastructureReady: cluster.Status.InfrastructureReady,
+	}
+}
+
+// filterClustersByProvider returns the clusters running on a provider
+func filterClustersByProvider(clusters *clusterv1.ClusterList, provider capi.Provider) []*clusterv1.Cluster {
+	var filtered []*clusterv1.Cluster
+	for i := range clusters.Items {
+		if capi.ClusterProvider(&clusters.Items[i]) == provider {
+			filtered = append(filtered, &clusters.Items[i])
+		}
+	}
+	return filtered
+}
+
+// createProviderListClustersHandler lists the clusters of a provider, which
+// the provider registry recognizes by their infrastructure kinds
+func createProviderListClustersHandler(serverCtx *ServerContext, provider capi.Provider) server.ToolHandlerFunc {
+	displayName := string(provider)
+	if info, ok := capi.LookupProvider(string(provider)); ok {
+		displayName = info.DisplayName
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		namespace := params.OptionalString(request.GetArguments(), "namespace", "")
+
+		clusters, err := serverCtx.client(ctx).ListClusters(ctx, namespace)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list clusters: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("%s Clusters:\n\n", displayName))
+
+		summaries := []providerClusterSummary{}
+		for _, cluster := range filterClustersByProvider(clusters, provider) {
+			summaries = append(summaries, newProviderClusterSummary(cluster))
+			content.WriteString(fmt.Sprintf("Cluster: %s/%s\n", cluster.Namespace, cluster.Name))
+			content.WriteString(fmt.Sprintf("  Infrastructure: %s\n", cluster.Spec.InfrastructureRef.Kind))
+			content.WriteString(fmt.Sprintf("  Phase: %s\n", cluster.Status.Phase))
+			content.WriteString(fmt.Sprintf("  Ready: %v\n\n", cluster.Status.InfrastructureReady))
+		}
+
+		if len(summaries) == 0 {
+			content.WriteString(fmt.Sprintf("No %s clusters found.\n", displayName))
+		} else {
+			content.WriteString(fmt.Sprintf("Total %s clusters: %d\n", displayName, len(summaries)))
+		}
+
+		return newToolResult(content.String(), map[string]any{"clusters": summaries})
+	}
+}
+
+// placeholderResult is the structured result of tools that are not implemented yet
+var placeholderResult = map[string]any{"implemented": false}
+
+// listInfrastructureProvidersParams declares the arguments of capi_list_infrastructure_providers
+var listInfrastructureProvidersParams = params.Schema{
+	{Name: "all_types", Type: params.Bool, Default: false,
+		Description: "Include the core, bootstrap, control plane and other providers, not only infrastructure providers"},
+}
+
+// createListInfrastructureProvidersHandler creates a handler for listing the installed providers
+func createListInfrastructureProvidersHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := listInfrastructureProvidersParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+
+		installed, err := serverCtx.client(ctx).ListInstalledProviders(ctx)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to discover installed providers: %w", err))
+		}
+		providers := make([]capi.InstalledProvider, 0, len(installed))
+		for _, provider := range installed {
+			if args.Bool("all_types") || provider.Type == "InfrastructureProvider" {
+				providers = append(providers, provider)
+			}
+		}
+
+		return newToolResult(formatInstalledProviders(providers), map[string]any{"providers": providers})
+	}
+}
+
+// formatInstalledProviders renders installed providers for display
+func formatInstalledProviders(providers []capi.InstalledProvider) string {
+	var content strings.Builder
+	if len(providers) == 0 {
+		content.WriteString("No installed providers found.\n")
+		content.WriteString("Providers are discovered from the clusterctl inventory and from deployments labeled cluster.x-k8s.io/provider.\n")
+		return content.String()
+	}
+
+	content.WriteString(fmt.Sprintf("Installed providers (%d):\n\n", len(providers)))
+	for _, provider := range providers {
+		health := "healthy"
+		if !provider.Healthy {
+			health = "unhealthy"
+		}
+		content.WriteString(fmt.Sprintf("Provider: %s (%s)\n", provider.Name, provider.Type))
+		content.WriteString(fmt.Sprintf("  Version: %s\n", summaryValue(provider.Version)))
+		content.WriteString(fmt.Sprintf("  Namespace: %s\n", provider.Namespace))
+		content.WriteString(fmt.Sprintf("  Source: %s\n", provider.Source))
+		content.WriteString(fmt.Sprintf("  Health: %s (%s)\n", health, provider.Message))
+		for _, deployment := range provider.Deployments {
+			content.WriteString(fmt.Sprintf("  Deployment: %s %d/%d ready, image %s\n",
+				deployment.Name, deployment.ReadyReplicas, deployment.Replicas, summaryValue(deployment.Image)))
+		}
+		content.WriteString("\n")
+	}
+	return content.String()
+}
+
+// controllersStatusParams declares the arguments of capi_controllers_status
+var controllersStatusParams = params.Schema{
+	{Name: "unhealthy_only", Type: params.Bool, Default: false,
+		Description: "Only show unhealthy controllers and webhook configurations"},
+}
+
+// createControllersStatusHandler creates a handler for checking the controllers
+func createControllersStatusHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := controllersStatusParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+
+		status, err := serverCtx.client(ctx).GetControllersStatus(ctx)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to check the controllers: %w", err))
+		}
+		if args.Bool("unhealthy_only") {
+			controllers := status.Controllers[:0]
+			for _, controller := range status.Controllers {
+				if !controller.Healthy {
+					controllers = append(controllers, controller)
+				}
+			}
+			webhooks := status.Webhooks[:0]
+			for _, webhook := range status.Webhooks {
+				if !webhook.Healthy {
+					webhooks = append(webhooks, webhook)
+				}
+			}
+			status.Controllers, status.Webhooks = controllers, webhooks
+		}
+
+		return newToolResult(formatControllersStatus(status), status)
+	}
+}
+
+// formatControllersStatus renders the health of the controllers for display
+func formatControllersStatus(status *capi.ControllersStatus) string {
+	var content strings.Builder
+	if status.Healthy {
+		content.WriteString("Cluster API controllers are healthy.\n\n")
+	} else {
+		content.WriteString(fmt.Sprintf("Cluster API controllers have %d issues:\n", len(status.Issues)))
+		for _, issue := range status.Issues {
+			content.WriteString(fmt.Sprintf("  - %s\n", issue))
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString(fmt.Sprintf("Controllers (%d):\n", len(status.Controllers)))
+	for _, controller := range status.Controllers {
+		content.WriteString(fmt.Sprintf("  %s/%s (%s): %d/%d ready, %d updated, %d restarts\n",
+			controller.Namespace, controller.Name, summaryValue(controller.Provider),
+			controller.ReadyReplicas, controller.Replicas, controller.UpdatedReplicas, controller.Restarts))
+		content.WriteString(fmt.Sprintf("    Images: %s\n", summaryValue(strings.Join(controller.Images, ", "))))
+		content.WriteString(fmt.Sprintf("    Status: %s\n", controller.Message))
+	}
+
+	content.WriteString(fmt.Sprintf("\nWebhook configurations (%d):\n", len(status.Webhooks)))
+	for _, webhook := range status.Webhooks {
+		content.WriteString(fmt.Sprintf("  %s (%s, %d webhooks, failure policy %s)\n",
+			webhook.Name, webhook.Type, webhook.Webhooks, summaryValue(webhook.FailurePolicy)))
+		content.WriteString(fmt.Sprintf("    Services: %s\n", summaryValue(strings.Join(webhook.Services, ", "))))
+		content.WriteString(fmt.Sprintf("    Status: %s\n", webhook.Message))
+	}
+	return content.String()
+}
+
+// checkWebhooksParams declares the arguments of capi_check_webhooks
+var checkWebhooksParams = params.Schema{
+	{Name: "namespace", Type: params.String, Default: "default",
+		Description: "Namespace of the canary Cluster created in dry-run mode (default: default)"},
+	{Name: "dry_run", Type: params.Bool, Default: true,
+		Description: "Create a canary Cluster in dry-run mode to call the webhooks through the API server (default: true)"},
+}
+
+// createCheckWebhooksHandler creates a handler for checking the webhooks
+func createCheckWebhooksHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := checkWebhooksParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+
+		health, err := serverCtx.client(ctx).CheckWebhooks(ctx, args.String("namespace"), args.Bool("dry_run"))
+		if err != nil {
+			return toolError(fmt.Errorf("failed to check the webhooks: %w", err))
+		}
+
+		return newToolResult(formatWebhookHealth(health), health)
+	}
+}
+
+// formatWebhookHealth renders the result of the webhook checks for display
+func formatWebhookHealth(health *capi.WebhookHealth) string {
+	var content strings.Builder
+	if health.Healthy {
+		content.WriteString("Cluster API webhooks are healthy.\n\n")
+	} else {
+		content.WriteString(fmt.Sprintf("Cluster API webhooks have %d issues:\n", len(health.Issues)))
+		for _, issue := range health.Issues {
+			content.WriteString(fmt.Sprintf("  - %s\n", issue))
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString(fmt.Sprintf("Webhook services (%d):\n", len(health.Services)))
+	for _, check := range health.Services {
+		status := "healthy"
+		if !check.Healthy {
+			status = "unhealthy"
+		}
+		content.WriteString(fmt.Sprintf("  %s (%s %s, %d webhooks): %s\n", check.Service, check.Type, check.Configuration, len(check.Webhooks), status))
+		content.WriteString(fmt.Sprintf("    Ready endpoints: %d\n", check.ReadyEndpoints))
+		if check.CAExpires != nil {
+			content.WriteString(fmt.Sprintf("    CA expires: %s\n", check.CAExpires.Format(time.RFC3339)))
+		}
+		if check.ServingCertExpires != nil {
+			content.WriteString(fmt.Sprintf("    Serving certificate expires: %s\n", check.ServingCertExpires.Format(time.RFC3339)))
+		}
+	}
+
+	if health.DryRun != nil {
+		content.WriteString(fmt.Sprintf("\nDry-run create of a %s in %s: %s\n", health.DryRun.Kind, health.DryRun.Namespace, health.DryRun.Message))
+	}
+	return content.String()
+}
+
+// initProvidersParams declares the arguments of capi_init_providers
+var initProvidersParams = params.Schema{
+	{Name: "infrastructure", Type: params.String, Required: true,
+		Description: "Infrastructure provider with an optional version, e.g. aws or aws:v2.7.1"},
+	{Name: "core", Type: params.String, Default: "cluster-api",
+		Description: "Core provider with an optional version (default: cluster-api)"},
+	{Name: "bootstrap", Type: params.String, Default: "kubeadm",
+		Description: "Bootstrap provider with an optional version (default: kubeadm)"},
+	{Name: "control_plane", Type: params.String, Default: "kubeadm",
+		Description: "Control plane provider with an optional version (default: kubeadm)"},
+	{Name: "dry_run", Type: params.Bool, Default: false,
+		Description: "Validate the components on the server without installing them"},
+}
+
+// providerUpgradePlanParams declares the arguments of capi_provider_upgrade_plan
+var providerUpgradePlanParams = params.Schema{
+	{Name: "providers", Type: params.String,
+		Description: "Comma-separated providers to plan, with optional target versions, e.g. cluster-api,infrastructure-aws:v2.7.1 (optional, default: all installed providers)"},
+}
+
+// upgradeProvidersParams declares the arguments of capi_upgrade_providers
+var upgradeProvidersParams = params.Schema{
+	{Name: "providers", Type: params.String,
+		Description: "Comma-separated providers to upgrade, with optional target versions, e.g. cluster-api,infrastructure-aws:v2.7.1 (optional, default: all installed providers to their latest releases)"},
+	{Name: "dry_run", Type: params.Bool, Default: false,
+		Description: "Validate the new components on the server without applying them"},
+}
+
+// providerPrefixes complete the short provider names of capi_init_providers
+// to clusterctl names
+var providerPrefixes = map[string]string{
+	"core":           "",
+	"bootstrap":      "bootstrap-",
+	"control_plane":  "control-plane-",
+	"infrastructure": "infrastructure-",
+}
+
+// providerSpecs splits a comma-separated list of providers
+func providerSpecs(list string) []string {
+	var specs []string
+	for _, spec := range strings.Split(list, ",") {
+		if spec = strings.TrimSpace(spec); spec != "" {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// providerRepository returns the repository providers are installed from
+func (s *ServerContext) providerRepository() capi.ProviderRepository {
+	if s.Providers != nil {
+		return s.Providers
+	}
+	return &capi.GitHubRepository{}
+}
+
+// createInitProvidersHandler creates a handler for installing providers
+func createInitProvidersHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := initProvidersParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+
+		opts := capi.ProviderOptions{Variables: os.LookupEnv, DryRun: args.Bool("dry_run")}
+		for _, argument := range []string{"core", "bootstrap", "control_plane", "infrastructure"} {
+			spec := strings.TrimSpace(args.String(argument))
+			if spec == "" {
+				continue
+			}
+			if prefix := providerPrefixes[argument]; !strings.HasPrefix(spec, prefix) {
+				spec = prefix + spec
+			}
+			opts.Providers = append(opts.Providers, spec)
+		}
+
+		changes, err := serverCtx.client(ctx).InstallProviders(ctx, serverCtx.providerRepository(), opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to install providers: %w", err))
+		}
+
+		title := "Installed providers"
+		if opts.DryRun {
+			title = "Dry run, providers that would be installed"
+		}
+		return newToolResult(formatProviderChanges(title, changes), map[string]any{"dryRun": opts.DryRun, "providers": changes})
+	}
+}
+
+// createProviderUpgradePlanHandler creates a handler for planning provider upgrades
+func createProviderUpgradePlanHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := providerUpgradePlanParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+
+		plan, err := serverCtx.client(ctx).PlanProviderUpgrade(ctx, serverCtx.providerRepository(), providerSpecs(args.String("providers")))
+		if err != nil {
+			return toolError(fmt.Errorf("failed to plan the provider upgrade: %w", err))
+		}
+
+		return newToolResult(formatProviderChanges("Provider upgrade plan", plan), map[string]any{"providers": plan})
+	}
+}
+
+// createUpgradeProvidersHandler creates a handler for upgrading providers
+func createUpgradeProvidersHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := upgradeProvidersParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+
+		opts := capi.ProviderOptions{
+			Providers: providerSpecs(args.String("providers")),
+			Variables: os.LookupEnv,
+			DryRun:    args.Bool("dry_run"),
+		}
+		changes, err := serverCtx.client(ctx).UpgradeProviders(ctx, serverCtx.providerRepository(), opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to upgrade providers: %w", err))
+		}
+
+		title := "Upgraded providers"
+		if opts.DryRun {
+			title = "Dry run, providers that would be upgraded"
+		}
+		return newToolResult(formatProviderChanges(title, changes), map[string]any{"dryRun": opts.DryRun, "providers": changes})
+	}
+}
+
+// formatProviderChanges renders installed, upgraded or planned providers
+func formatProviderChanges(title string, changes []capi.ProviderChange) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("%s (%d):\n\n", title, len(changes)))
+	for _, change := range changes {
+		action := "unchanged"
+		if change.Changed {
+			action = "changed"
+		}
+		content.WriteString(fmt.Sprintf("Provider: %s (%s)\n", change.Name, change.Type))
+		content.WriteString(fmt.Sprintf("  Namespace: %s\n", summaryValue(change.Namespace)))
+		content.WriteString(fmt.Sprintf("  Version: %s -> %s (%s)\n", summaryValue(change.CurrentVersion), summaryValue(change.TargetVersion), action))
+		if change.Objects > 0 {
+			content.WriteString(fmt.Sprintf("  Objects applied: %d\n", change.Objects))
+		}
+		if change.Note != "" {
+			content.WriteString(fmt.Sprintf("  Note: %s\n", change.Note))
+		}
+		content.WriteString("\n")
+	}
+	return content.String()
+}
+
+// formatProviderInfo renders a registered provider without dedicated
+// configuration notes
+func formatProviderInfo(info capi.ProviderInfo) string {
+	var capabilities []string
+	if info.Capabilities.ManagedKubernetes {
+		capabilities = append(capabilities, "managed Kubernetes")
+	}
+	if info.Capabilities.MachinePools {
+		capabilities = append(capabilities, "MachinePools")
+	}
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("%s Provider Configuration:\n", info.DisplayName))
+	content.WriteString(fmt.Sprintf("  Infrastructure Kinds: %s\n", strings.Join(info.ClusterKinds, ", ")))
+	content.WriteString(fmt.Sprintf("  API Version: %s\n", info.APIVersion))
+	content.WriteString(fmt.Sprintf("  Capabilities: %s\n", summaryValue(strings.Join(capabilities, ", "))))
+	content.WriteString("  Clusters of this provider are detected and listed by the generic tools; see the\n")
+	content.WriteString("  provider's documentation for its credentials and settings.\n")
+	return content.String()
+}
+
+// createGetProviderConfigHandler creates a handler for getting provider configuration
+func createGetProviderConfigHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		provider, ok := arguments["provider"].(string)
+		if !ok || provider == "" {
+			return invalidArgument("provider is required (%s)", strings.Join(capi.ProviderNames(), ", "))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Configuration for %s Provider:\n\n", strings.ToUpper(provider)))
+
+		switch strings.ToLower(provider) {
+		case "aws":
+			content.WriteString("AWS Provider Configuration:\n")
+			content.WriteString("  Required Credentials:\n")
+			content.WriteString("    - AWS_ACCESS_KEY_ID\n")
+			content.WriteString("    - AWS_SECRET_ACCESS_KEY\n")
+			content.WriteString("    - AWS_REGION\n")
+			content.WriteString("  Optional:\n")
+			content.WriteString("    - AWS_SESSION_TOKEN (for temporary credentials)\n")
+			content.WriteString("    - AWS_PROFILE (to use a specific profile)\n\n")
+			content.WriteString("  Common Resources:\n")
+			content.WriteString("    - AWSCluster: Manages VPC, subnets, security groups\n")
+			content.WriteString("    - AWSMachine: Individual EC2 instances\n")
+			content.WriteString("    - AWSMachineTemplate: Template for creating machines\n")
+			content.WriteString("    - AWSManagedControlPlane: EKS-based control plane\n")
+
+		case "azure":
+			content.WriteString("Azure Provider Configuration:\n")
+			content.WriteString("  Required Credentials:\n")
+			content.WriteString("    - AZURE_SUBSCRIPTION_ID\n")
+			content.WriteString("    - AZURE_TENANT_ID\n")
+			content.WriteString("    - AZURE_CLIENT_ID\n")
+			content.WriteString("    - AZURE_CLIENT_SECRET\n")
+			content.WriteString("  Optional:\n")
+			content.WriteString("    - AZURE_ENVIRONMENT (AzurePublicCloud, AzureGermanCloud, etc.)\n\n")
+			content.WriteString("  Common Resources:\n")
+			content.WriteString("    - AzureCluster: Manages resource group, vnet, subnets\n")
+			content.WriteString("    - AzureMachine: Individual VM instances\n")
+			content.WriteString("    - AzureMachineTemplate: Template for creating machines\n")
+			content.WriteString("    - AzureManagedControlPlane: AKS-based control plane\n")
+
+		case "gcp":
+			content.WriteString("GCP Provider Configuration:\n")
+			content.WriteString("  Required Credentials:\n")
+			content.WriteString("    - GOOGLE_APPLICATION_CREDENTIALS (path to service account key)\n")
+			content.WriteString("    - GCP_PROJECT_ID\n")
+			content.WriteString("    - GCP_REGION\n")
+			content.WriteString("  Optional:\n")
+			content.WriteString("    - GCP_NETWORK (custom network name)\n\n")
+			content.WriteString("  Common Resources:\n")
+			content.WriteString("    - GCPCluster: Manages VPC, subnets, firewall rules\n")
+			content.WriteString("    - GCPMachine: Individual GCE instances\n")
+			content.WriteString("    - GCPMachineTemplate: Template for creating machines\n")
+
+		case "vsphere":
+			content.WriteString("vSphere Provider Configuration:\n")
+			content.WriteString("  Required Credentials:\n")
+			content.WriteString("    - VSPHERE_SERVER\n")
+			content.WriteString("    - VSPHERE_USERNAME\n")
+			content.WriteString("    - VSPHERE_PASSWORD\n")
+			content.WriteString("  Required Settings:\n")
+			content.WriteString("    - VSPHERE_DATACENTER\n")
+			content.WriteString("    - VSPHERE_DATASTORE\n")
+			content.WriteString("    - VSPHERE_NETWORK\n")
+			content.WriteString("    - VSPHERE_RESOURCE_POOL\n")
+			content.WriteString("  Optional:\n")
+			content.WriteString("    - VSPHERE_FOLDER\n")
+			content.WriteString("    - VSPHERE_TEMPLATE (VM template to clone)\n\n")
+			content.WriteString("  Common Resources:\n")
+			content.WriteString("    - VSphereCluster: Manages cluster-level settings\n")
+			content.WriteString("    - VSphereMachine: Individual VM instances\n")
+			content.WriteString("    - VSphereMachineTemplate: Template for creating machines\n")
+
+		case "metal3":
+			content.WriteString("Metal3 Provider Configuration:\n")
+			content.WriteString("  Required Components:\n")
+			content.WriteString("    - Baremetal Operator and Ironic, managing the hosts\n")
+			content.WriteString("    - A BMC credentials Secret per BareMetalHost\n")
+			content.WriteString("  Required Settings:\n")
+			content.WriteString("    - CLUSTER_APIENDPOINT_HOST\n")
+			content.WriteString("    - IMAGE_URL and IMAGE_CHECKSUM (node image)\n")
+			content.WriteString("  Common Resources:\n")
+			content.WriteString("    - Metal3Cluster: Manages the control plane endpoint\n")
+			content.WriteString("    - Metal3Machine: Claims a BareMetalHost for a Machine\n")
+			content.WriteString("    - Metal3MachineTemplate: Template for creating machines\n")
+			content.WriteString("    - BareMetalHost: A physical host and its BMC\n")
+
+		case "kubevirt":
+			content.WriteString("KubeVirt Provider Configuration:\n")
+			content.WriteString("  Required Components:\n")
+			content.WriteString("    - KubeVirt (and CDI for data volumes) in the infra cluster\n")
+			content.WriteString("    - A kubeconfig Secret when the infra cluster is not the management cluster\n")
+			content.WriteString("  Required Settings:\n")
+			content.WriteString("    - NODE_VM_IMAGE_TEMPLATE (container disk image)\n")
+			content.WriteString("    - CRI_PATH\n")
+			content.WriteString("  Common Resources:\n")
+			content.WriteString("    - KubevirtCluster: Manages the API server Service in the infra cluster\n")
+			content.WriteString("    - KubevirtMachine: Creates a VirtualMachine for a Machine\n")
+			content.WriteString("    - KubevirtMachineTemplate: Template for creating machines\n")
+
+		case "docker":
+			content.WriteString("Docker Provider (CAPD) Configuration:\n")
+			content.WriteString("  For development and testing only\n")
+			content.WriteString("  Required Components:\n")
+			content.WriteString("    - A kind management cluster with /var/run/docker.sock mounted\n")
+			content.WriteString("    - clusterctl init --infrastructure docker\n")
+			content.WriteString("  Common Resources:\n")
+			content.WriteString("    - DockerCluster: Runs the load balancer container of the API server\n")
+			content.WriteString("    - DockerMachine: A node running as a kind container\n")
+			content.WriteString("    - DockerMachineTemplate: Template for creating machines\n")
+			content.WriteString("  Create a development cluster with: capi_create_cluster --provider docker\n")
+
+		default:
+			info, ok := capi.LookupProvider(provider)
+			if !ok {
+				return invalidArgument("unknown provider %s, supported providers: %s", provider, strings.Join(capi.ProviderNames(), ", "))
+			}
+			content.WriteString(formatProviderInfo(info))
+		}
+
+		return newToolResult(content.String(), map[string]any{"provider": strings.ToLower(provider)})
+	}
+}
@@ -0,0 +1,733 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerAWSTools adds the AWS infrastructure tools
+func registerAWSTools(s Registry, serverCtx *ServerContext) {
+	awsListClustersTool := mcp.NewTool(
+		"capi_aws_list_clusters",
+		mcp.WithDescription("List AWS clusters"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace to filter clusters (optional)"),
+		),
+	)
+	addTool(s, awsListClustersTool, createProviderListClustersHandler(serverCtx, capi.ProviderAWS))
+
+	awsGetClusterTool := mcp.NewTool(
+		"capi_aws_get_cluster",
+		mcp.WithDescription("Get AWS cluster details: region, VPC, subnets, security groups, bastion, load balancers and failure domains from the AWSCluster, or the AWSManagedCluster and AWSManagedControlPlane of EKS clusters"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Cluster namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Cluster name"),
+		),
+	)
+	addTool(s, awsGetClusterTool, createAWSGetClusterHandler(serverCtx))
+
+	awsCreateClusterTool := mcp.NewTool(
+		"capi_aws_create_cluster",
+		mcp.WithDescription("Create AWS cluster with specific configuration (placeholder)"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Cluster namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Cluster name"),
+		),
+		mcp.WithString("region",
+			mcp.Required(),
+			mcp.Description("AWS region"),
+		),
+		mcp.WithString("vpc_cidr",
+			mcp.Description("VPC CIDR block"),
+		),
+	)
+	addTool(s, awsCreateClusterTool, createAWSCreateClusterHandler(serverCtx))
+
+	awsUpdateVPCTool := mcp.NewTool(
+		"capi_aws_update_vpc",
+		mcp.WithDescription("Update VPC configuration (placeholder)"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Cluster namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Cluster name"),
+		),
+		mcp.WithString("operation",
+			mcp.Required(),
+			mcp.Description("Operation to perform"),
+		),
+	)
+	addTool(s, awsUpdateVPCTool, createAWSUpdateVPCHandler(serverCtx))
+
+	awsManageSecurityGroupsTool := mcp.NewTool(
+		"capi_aws_manage_security_groups",
+		mcp.WithDescription("Manage security groups (placeholder)"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Cluster namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Cluster name"),
+		),
+		mcp.WithString("operation",
+			mcp.Required(),
+			mcp.Description("Operation to perform"),
+		),
+	)
+	addTool(s, awsManageSecurityGroupsTool, createAWSManageSecurityGroupsHandler(serverCtx))
+
+	awsGetMachineTemplateTool := mcp.NewTool(
+		"capi_aws_get_machine_template",
+		mcp.WithDescription("Get or list AWSMachineTemplates with their instance type, AMI, root volume and the MachineDeployments and control planes using them"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace to search in"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Template name (optional, lists all if not provided)"),
+		),
+	)
+	addTool(s, awsGetMachineTemplateTool, createAWSGetMachineTemplateHandler(serverCtx))
+
+	awsCreateMachineTemplateTool := mcp.NewTool(
+		"capi_aws_create_machine_template",
+		mcp.WithDescription("Create an AWSMachineTemplate for the machines of a cluster"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Template namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Template name"),
+		),
+		mcp.WithString("cluster_name",
+			mcp.Description("Cluster the template belongs to (sets the cluster name label)"),
+		),
+		mcp.WithString("instance_type",
+			mcp.Required(),
+			mcp.Description("EC2 instance type (e.g., m5.xlarge)"),
+		),
+		mcp.WithString("ami",
+			mcp.Description("AMI ID (default: looked up for the Kubernetes version of the machines)"),
+		),
+		mcp.WithNumber("root_volume_size",
+			mcp.Description("Root volume size in GiB"),
+		),
+		mcp.WithString("root_volume_type",
+			mcp.Description("Root volume type (e.g., gp3)"),
+		),
+		mcp.WithString("iam_instance_profile",
+			mcp.Description("IAM instance profile (default: nodes.cluster-api-provider-aws.sigs.k8s.io)"),
+		),
+		mcp.WithString("ssh_key_name",
+			mcp.Description("EC2 key pair for SSH access"),
+		),
+	)
+	addTool(s, awsCreateMachineTemplateTool, createAWSCreateMachineTemplateHandler(serverCtx))
+
+	awsCloneMachineTemplateTool := mcp.NewTool(
+		"capi_aws_clone_machine_template",
+		mcp.WithDescription("Clone an AWSMachineTemplate under a new name with a different instance type, AMI or root volume. Templates are immutable: point a MachineDeployment at the clone to roll out the change."),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Template namespace"),
+		),
+		mcp.WithString("source",
+			mcp.Required(),
+			mcp.Description("Template to clone"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the new template"),
+		),
+		mcp.WithString("instance_type",
+			mcp.Description("EC2 instance type (default: the one of the source)"),
+		),
+		mcp.WithString("ami",
+			mcp.Description("AMI ID (default: the one of the source)"),
+		),
+		mcp.WithNumber("root_volume_size",
+			mcp.Description("Root volume size in GiB (default: the one of the source)"),
+		),
+		mcp.WithString("root_volume_type",
+			mcp.Description("Root volume type (default: the one of the source)"),
+		),
+	)
+	addTool(s, awsCloneMachineTemplateTool, createAWSCloneMachineTemplateHandler(serverCtx))
+
+	awsDiffMachineTemplatesTool := mcp.NewTool(
+		"capi_aws_diff_machine_templates",
+		mcp.WithDescription("Compare the machine specs of two AWSMachineTemplates"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Template namespace"),
+		),
+		mcp.WithString("from",
+			mcp.Required(),
+			mcp.Description("Template to compare from"),
+		),
+		mcp.WithString("to",
+			mcp.Required(),
+			mcp.Description("Template to compare to"),
+		),
+	)
+	addTool(s, awsDiffMachineTemplatesTool, createAWSDiffMachineTemplatesHandler(serverCtx))
+
+	awsConfigureSpotTool := mcp.NewTool(
+		"capi_aws_configure_spot",
+		mcp.WithDescription("Switch the machines of a MachineDeployment (by cloning its AWSMachineTemplate) or an AWSMachinePool between spot and on-demand instances, with mixed instances and capacity rebalancing for pools. Reports the nodes and workloads spot interruptions may disrupt; use dry_run to preview."),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Namespace of the MachineDeployment or MachinePool"),
+		),
+		mcp.WithString("machine_deployment",
+			mcp.Description("MachineDeployment to configure (set this or machine_pool)"),
+		),
+		mcp.WithString("machine_pool",
+			mcp.Description("MachinePool backed by an AWSMachinePool to configure (set this or machine_deployment)"),
+		),
+		mcp.WithBoolean("spot",
+			mcp.Description("Use spot instances; false switches back to on-demand (default: true)"),
+		),
+		mcp.WithString("max_price",
+			mcp.Description("Maximum hourly spot price in USD (default: the on-demand price)"),
+		),
+		mcp.WithNumber("on_demand_base_capacity",
+			mcp.Description("MachinePools: number of instances that always run on-demand"),
+		),
+		mcp.WithNumber("on_demand_percentage",
+			mcp.Description("MachinePools: percentage of on-demand instances above the base capacity (default: 0)"),
+		),
+		mcp.WithString("spot_allocation_strategy",
+			mcp.Description("MachinePools: how spot capacity pools are chosen"),
+			mcp.Enum(capi.SpotAllocationStrategies...),
+		),
+		mcp.WithString("instance_types",
+			mcp.Description("MachinePools: comma-separated instance types to spread spot instances over"),
+		),
+		mcp.WithBoolean("capacity_rebalance",
+			mcp.Description("MachinePools: replace instances at elevated risk of interruption ahead of time"),
+		),
+		mcp.WithString("template_name",
+			mcp.Description("Name of the AWSMachineTemplate cloned for a MachineDeployment (default: derived from the settings)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only report the changes and the disruption (default: false)"),
+		),
+		withApprovalID(),
+	)
+	addTool(s, awsConfigureSpotTool, createAWSConfigureSpotHandler(serverCtx))
+}
+
+// AWS Provider Tools
+
+// createAWSGetClusterHandler gets details of an AWS cluster
+func createAWSGetClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		// Get the cluster
+		cluster, err := serverCtx.client(ctx).GetCluster(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get cluster: %w", err))
+		}
+
+		// Verify it's an AWS cluster
+		if capi.ClusterProvider(cluster) != capi.ProviderAWS {
+			return invalidArgument("cluster %s/%s is not an AWS cluster", namespace, name)
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("AWS Cluster: %s/%s\n\n", namespace, name))
+
+		// Basic cluster info
+		content.WriteString("Cluster Information:\n")
+		content.WriteString(fmt.Sprintf("  Phase: %s\n", cluster.Status.Phase))
+		content.WriteString(fmt.Sprintf("  Infrastructure Ready: %v\n", cluster.Status.InfrastructureReady))
+		content.WriteString(fmt.Sprintf("  Control Plane Ready: %v\n", cluster.Status.ControlPlaneReady))
+
+		// Infrastructure reference
+		content.WriteString("\nInfrastructure:\n")
+		content.WriteString(fmt.Sprintf("  Kind: %s\n", cluster.Spec.InfrastructureRef.Kind))
+		content.WriteString(fmt.Sprintf("  Name: %s\n", cluster.Spec.InfrastructureRef.Name))
+		content.WriteString(fmt.Sprintf("  API Version: %s\n", cluster.Spec.InfrastructureRef.APIVersion))
+
+		// Network configuration
+		if cluster.Spec.ClusterNetwork != nil {
+			content.WriteString("\nNetwork Configuration:\n")
+			if cluster.Spec.ClusterNetwork.Pods != nil && len(cluster.Spec.ClusterNetwork.Pods.CIDRBlocks) > 0 {
+				content.WriteString(fmt.Sprintf("  Pod CIDR: %s\n", strings.Join(cluster.Spec.ClusterNetwork.Pods.CIDRBlocks, ", ")))
+			}
+			if cluster.Spec.ClusterNetwork.Services != nil && len(cluster.Spec.ClusterNetwork.Services.CIDRBlocks) > 0 {
+				content.WriteString(fmt.Sprintf("  Service CIDR: %s\n", strings.Join(cluster.Spec.ClusterNetwork.Services.CIDRBlocks, ", ")))
+			}
+		}
+
+		// Conditions
+		if len(cluster.Status.Conditions) > 0 {
+			content.WriteString("\nConditions:\n")
+			for _, condition := range cluster.Status.Conditions {
+				content.WriteString(fmt.Sprintf("  - %s: %s", condition.Type, condition.Status))
+				if condition.Reason != "" {
+					content.WriteString(fmt.Sprintf(" (%s)", condition.Reason))
+				}
+				content.WriteString("\n")
+			}
+		}
+
+		aws, err := serverCtx.client(ctx).GetAWSCluster(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to read the AWS infrastructure: %w", err))
+		}
+		content.WriteString("\n")
+		content.WriteString(formatAWSCluster(aws))
+
+		return newToolResult(content.String(), map[string]any{"cluster": trimObject(cluster), "aws": aws})
+	}
+}
+
+// formatAWSCluster renders the AWS infrastructure of a cluster for display
+func formatAWSCluster(aws *capi.AWSClusterInfo) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("%s %s:\n", aws.Kind, aws.Name))
+	content.WriteString(fmt.Sprintf("  Region: %s\n", summaryValue(aws.Region)))
+	content.WriteString(fmt.Sprintf("  Ready: %v\n", aws.Ready))
+	content.WriteString(fmt.Sprintf("  Control Plane Endpoint: %s\n", summaryValue(aws.ControlPlaneEndpoint)))
+	if aws.ControlPlane != "" {
+		content.WriteString(fmt.Sprintf("  Network read from AWSManagedControlPlane %s\n", aws.ControlPlane))
+	}
+	if aws.SSHKeyName != "" {
+		content.WriteString(fmt.Sprintf("  SSH Key: %s\n", aws.SSHKeyName))
+	}
+
+	content.WriteString(fmt.Sprintf("\nVPC: %s (%s)\n", summaryValue(aws.VPC.ID), summaryValue(aws.VPC.CIDR)))
+
+	content.WriteString(fmt.Sprintf("\nSubnets (%d):\n", len(aws.Subnets)))
+	for _, subnet := range aws.Subnets {
+		visibility := "private"
+		if subnet.Public {
+			visibility = "public"
+		}
+		content.WriteString(fmt.Sprintf("  - %s %s %s (%s)", summaryValue(subnet.ID), summaryValue(subnet.AvailabilityZone), summaryValue(subnet.CIDR), visibility))
+		if subnet.NatGatewayID != "" {
+			content.WriteString(fmt.Sprintf(", NAT gateway %s", subnet.NatGatewayID))
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString(fmt.Sprintf("\nSecurity Groups (%d):\n", len(aws.SecurityGroups)))
+	for _, group := range aws.SecurityGroups {
+		content.WriteString(fmt.Sprintf("  - %s: %s (%s)\n", group.Role, summaryValue(group.ID), summaryValue(group.Name)))
+	}
+
+	content.WriteString(fmt.Sprintf("\nLoad Balancers (%d):\n", len(aws.LoadBalancers)))
+	for _, lb := range aws.LoadBalancers {
+		content.WriteString(fmt.Sprintf("  - %s: %s %s (%s, %s)\n", lb.Role, summaryValue(lb.Name), summaryValue(lb.DNSName), summaryValue(lb.Scheme), summaryValue(lb.Type)))
+	}
+
+	content.WriteString("\nBastion:\n")
+	if aws.Bastion.Enabled {
+		content.WriteString(fmt.Sprintf("  Instance: %s (%s, %s)\n", summaryValue(aws.Bastion.InstanceID), summaryValue(aws.Bastion.InstanceType), summaryValue(aws.Bastion.State)))
+		content.WriteString(fmt.Sprintf("  Public IP: %s, Private IP: %s\n", summaryValue(aws.Bastion.PublicIP), summaryValue(aws.Bastion.PrivateIP)))
+	} else {
+		content.WriteString("  Disabled\n")
+	}
+
+	content.WriteString(fmt.Sprintf("\nFailure Domains (%d):\n", len(aws.FailureDomains)))
+	for _, domain := range aws.FailureDomains {
+		suffix := ""
+		if domain.ControlPlane {
+			suffix = " (control plane)"
+		}
+		content.WriteString(fmt.Sprintf("  - %s%s\n", domain.Name, suffix))
+	}
+	return content.String()
+}
+
+// createAWSGetMachineTemplateHandler gets or lists AWS machine templates
+func createAWSGetMachineTemplateHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, _ := arguments["name"].(string)
+
+		if name != "" {
+			template, err := serverCtx.client(ctx).GetAWSMachineTemplate(ctx, namespace, name)
+			if err != nil {
+				return toolError(fmt.Errorf("failed to get AWS machine template: %w", err))
+			}
+			content := fmt.Sprintf("AWS Machine Template: %s/%s\n\n", namespace, name) + formatAWSMachineTemplate(template)
+			return newToolResult(content, map[string]any{"template": template})
+		}
+
+		templates, err := serverCtx.client(ctx).ListAWSMachineTemplates(ctx, namespace)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list AWS machine templates: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("AWS Machine Templates in namespace %s:\n\n", namespace))
+		if len(templates) == 0 {
+			content.WriteString("No AWS machine templates found.\n")
+		}
+		for _, template := range templates {
+			used := "unused"
+			if len(template.UsedBy) > 0 {
+				used = "used by " + strings.Join(template.UsedBy, ", ")
+			}
+			content.WriteString(fmt.Sprintf("- %s: %s, AMI %s (%s)\n", template.Name, summaryValue(template.InstanceType), summaryValue(template.AMI), used))
+		}
+		return newToolResult(content.String(), map[string]any{"templates": templates})
+	}
+}
+
+// createAWSCreateMachineTemplateHandler creates an AWS machine template
+func createAWSCreateMachineTemplateHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+		if err := params.KubernetesName("name", name); err != nil {
+			return toolError(err)
+		}
+		instanceType, err := params.RequiredString(arguments, "instance_type")
+		if err != nil {
+			return toolError(err)
+		}
+		opts, err := awsMachineTemplateOptions(arguments)
+		if err != nil {
+			return toolError(err)
+		}
+		opts.InstanceType = instanceType
+		opts.IAMInstanceProfile, _ = arguments["iam_instance_profile"].(string)
+		opts.SSHKeyName, _ = arguments["ssh_key_name"].(string)
+		clusterName, _ := arguments["cluster_name"].(string)
+
+		template, err := serverCtx.client(ctx).CreateAWSMachineTemplate(ctx, namespace, name, clusterName, opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to create AWS machine template: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("✅ Successfully created AWS machine template %s/%s\n\n", namespace, name))
+		content.WriteString(formatAWSMachineTemplate(template))
+		return newToolResult(content.String(), operationResult{Operation: "create", Resource: resourceRef{Kind: "AWSMachineTemplate", Namespace: namespace, Name: name}, Details: map[string]any{"template": template}})
+	}
+}
+
+// createAWSCloneMachineTemplateHandler clones an AWS machine template with
+// modified machine settings
+func createAWSCloneMachineTemplateHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		source, err := params.RequiredString(arguments, "source")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+		if err := params.KubernetesName("name", name); err != nil {
+			return toolError(err)
+		}
+		opts, err := awsMachineTemplateOptions(arguments)
+		if err != nil {
+			return toolError(err)
+		}
+		opts.InstanceType, _ = arguments["instance_type"].(string)
+
+		c := serverCtx.client(ctx)
+		template, err := c.CloneAWSMachineTemplate(ctx, namespace, source, name, opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to clone AWS machine template: %w", err))
+		}
+		changes, err := c.DiffAWSMachineTemplates(ctx, namespace, source, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to compare the AWS machine templates: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("✅ Successfully cloned AWS machine template %s/%s to %s\n\n", namespace, source, name))
+		content.WriteString(formatAWSMachineTemplate(template))
+		content.WriteString("\n")
+		content.WriteString(formatFieldChanges(changes))
+		content.WriteString(fmt.Sprintf("\nPoint the infrastructure reference of a MachineDeployment to %s to roll out its machines.\n", name))
+		return newToolResult(content.String(), operationResult{Operation: "clone", Resource: resourceRef{Kind: "AWSMachineTemplate", Namespace: namespace, Name: name}, Details: map[string]any{"source": source, "template": template, "changes": changes}})
+	}
+}
+
+// createAWSDiffMachineTemplatesHandler compares two AWS machine templates
+func createAWSDiffMachineTemplatesHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		from, err := params.RequiredString(arguments, "from")
+		if err != nil {
+			return toolError(err)
+		}
+		to, err := params.RequiredString(arguments, "to")
+		if err != nil {
+			return toolError(err)
+		}
+
+		changes, err := serverCtx.client(ctx).DiffAWSMachineTemplates(ctx, namespace, from, to)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to compare AWS machine templates: %w", err))
+		}
+
+		content := fmt.Sprintf("AWS Machine Templates %s/%s → %s\n\n", namespace, from, to) + formatFieldChanges(changes)
+		return newToolResult(content, map[string]any{"from": from, "to": to, "changes": changes})
+	}
+}
+
+// createAWSConfigureSpotHandler configures spot instances on AWS machines
+func createAWSConfigureSpotHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		spot, err := params.OptionalBool(arguments, "spot", true)
+		if err != nil {
+			return toolError(err)
+		}
+		dryRun, err := params.OptionalBool(arguments, "dry_run", false)
+		if err != nil {
+			return toolError(err)
+		}
+		opts := capi.AWSSpotOptions{
+			Namespace:              namespace,
+			MachineDeployment:      params.OptionalString(arguments, "machine_deployment", ""),
+			MachinePool:            params.OptionalString(arguments, "machine_pool", ""),
+			Spot:                   spot,
+			MaxPrice:               params.OptionalString(arguments, "max_price", ""),
+			SpotAllocationStrategy: params.OptionalString(arguments, "spot_allocation_strategy", ""),
+			TemplateName:           params.OptionalString(arguments, "template_name", ""),
+			DryRun:                 dryRun,
+		}
+		if _, ok := arguments["on_demand_base_capacity"]; ok {
+			base, err := params.OptionalInt(arguments, "on_demand_base_capacity", 0)
+			if err != nil {
+				return toolError(err)
+			}
+			value := int64(base)
+			opts.OnDemandBaseCapacity = &value
+		}
+		if _, ok := arguments["on_demand_percentage"]; ok {
+			percentage, err := params.OptionalInt(arguments, "on_demand_percentage", 0)
+			if err != nil {
+				return toolError(err)
+			}
+			value := int64(percentage)
+			opts.OnDemandPercentageAboveBase = &value
+		}
+		if _, ok := arguments["capacity_rebalance"]; ok {
+			rebalance, err := params.OptionalBool(arguments, "capacity_rebalance", false)
+			if err != nil {
+				return toolError(err)
+			}
+			opts.CapacityRebalance = &rebalance
+		}
+		for _, instanceType := range strings.Split(params.OptionalString(arguments, "instance_types", ""), ",") {
+			if instanceType = strings.TrimSpace(instanceType); instanceType != "" {
+				opts.InstanceTypes = append(opts.InstanceTypes, instanceType)
+			}
+		}
+
+		config, err := serverCtx.client(ctx).ConfigureAWSSpot(ctx, opts)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to configure spot instances: %w", err))
+		}
+
+		mode := "on-demand"
+		if config.Spot {
+			mode = "spot"
+		}
+		var content strings.Builder
+		switch {
+		case config.DryRun:
+			content.WriteString(fmt.Sprintf("Dry run: switching %s %s/%s to %s instances\n\n", config.Disruption.Kind, namespace, config.Disruption.Name, mode))
+		default:
+			content.WriteString(fmt.Sprintf("✅ Switched %s %s/%s to %s instances\n\n", config.Disruption.Kind, namespace, config.Disruption.Name, mode))
+		}
+		if config.OldTemplate != "" {
+			content.WriteString(fmt.Sprintf("%s: %s → %s\n\n", config.Kind, config.OldTemplate, config.Name))
+		} else {
+			content.WriteString(fmt.Sprintf("%s: %s\n\n", config.Kind, config.Name))
+		}
+		content.WriteString(formatFieldChanges(config.Changes))
+
+		content.WriteString("\nDisruption Summary:\n")
+		content.WriteString(fmt.Sprintf("  Cluster: %s\n", summaryValue(config.Disruption.Cluster)))
+		content.WriteString(fmt.Sprintf("  Replicas: %d\n", config.Disruption.Replicas))
+		content.WriteString(fmt.Sprintf("  Nodes: %s\n", summaryValue(strings.Join(config.Disruption.Nodes, ", "))))
+		for _, warning := range config.Warnings {
+			content.WriteString(fmt.Sprintf("  ⚠️ %s\n", warning))
+		}
+
+		return newToolResult(content.String(), operationResult{Operation: "configure-spot", Resource: resourceRef{Kind: config.Kind, Namespace: namespace, Name: config.Name}, Details: map[string]any{"configuration": config}})
+	}
+}
+
+// awsMachineTemplateOptions parses the machine settings shared by the create
+// and clone tools
+func awsMachineTemplateOptions(arguments map[string]any) (capi.AWSMachineTemplateOptions, error) {
+	rootVolumeSize, err := params.OptionalInt(arguments, "root_volume_size", 0)
+	if err != nil {
+		return capi.AWSMachineTemplateOptions{}, err
+	}
+	opts := capi.AWSMachineTemplateOptions{RootVolumeSize: int64(rootVolumeSize)}
+	opts.AMI, _ = arguments["ami"].(string)
+	opts.RootVolumeType, _ = arguments["root_volume_type"].(string)
+	return opts, nil
+}
+
+// formatAWSMachineTemplate renders an AWS machine template for display
+func formatAWSMachineTemplate(template *capi.AWSMachineTemplateInfo) string {
+	var content strings.Builder
+	if template.ClusterName != "" {
+		content.WriteString(fmt.Sprintf("  Cluster: %s\n", template.ClusterName))
+	}
+	content.WriteString(fmt.Sprintf("  Instance Type: %s\n", summaryValue(template.InstanceType)))
+	if template.AMI != "" {
+		content.WriteString(fmt.Sprintf("  AMI: %s\n", template.AMI))
+	} else {
+		content.WriteString(fmt.Sprintf("  AMI: looked up (base OS %s)\n", summaryValue(template.ImageLookupBaseOS)))
+	}
+	if template.RootVolumeSize > 0 || template.RootVolumeType != "" {
+		content.WriteString(fmt.Sprintf("  Root Volume: %d GiB %s\n", template.RootVolumeSize, summaryValue(template.RootVolumeType)))
+	}
+	content.WriteString(fmt.Sprintf("  IAM Instance Profile: %s\n", summaryValue(template.IAMInstanceProfile)))
+	content.WriteString(fmt.Sprintf("  SSH Key: %s\n", summaryValue(template.SSHKeyName)))
+	if len(template.AdditionalSecurityGroups) > 0 {
+		content.WriteString(fmt.Sprintf("  Additional Security Groups: %s\n", strings.Join(template.AdditionalSecurityGroups, ", ")))
+	}
+	if len(template.UsedBy) > 0 {
+		content.WriteString(fmt.Sprintf("  Used By: %s\n", strings.Join(template.UsedBy, ", ")))
+	}
+	return content.String()
+}
+
+// formatFieldChanges renders the differences between two objects
+func formatFieldChanges(changes []capi.FieldChange) string {
+	if len(changes) == 0 {
+		return "No differences.\n"
+	}
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Differences (%d):\n", len(changes)))
+	for _, change := range changes {
+		content.WriteString(fmt.Sprintf("  - %s: %s → %s\n", change.Field, fieldChangeValue(change.From), fieldChangeValue(change.To)))
+	}
+	return content.String()
+}
+
+// fieldChangeValue renders a changed value, marking unset ones
+func fieldChangeValue(value any) string {
+	if value == nil {
+		return "(unset)"
+	}
+	return fmt.Sprint(value)
+}
+
+// Placeholder handlers for provider-specific operations
+
+// createAWSCreateClusterHandler creates AWS-specific cluster configuration
+func createAWSCreateClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var content strings.Builder
+		content.WriteString("AWS Cluster Creation (Placeholder)\n\n")
+		content.WriteString("This tool would create AWS-specific cluster resources including:\n")
+		content.WriteString("- AWSCluster resource with VPC, subnet, and security group configuration\n")
+		content.WriteString("- IAM roles and policies for cluster components\n")
+		content.WriteString("- S3 buckets for OIDC discovery (if using IRSA)\n")
+		content.WriteString("- Load balancers for API server access\n\n")
+		content.WriteString("Required parameters would include:\n")
+		content.WriteString("- Region\n")
+		content.WriteString("- VPC CIDR\n")
+		content.WriteString("- Availability zones\n")
+		content.WriteString("- Instance types\n")
+		content.WriteString("- SSH key name\n")
+
+		return newToolResult(content.String(), placeholderResult)
+	}
+}
+
+// createAWSUpdateVPCHandler updates AWS VPC configuration
+func createAWSUpdateVPCHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var content strings.Builder
+		content.WriteString("AWS VPC Update (Placeholder)\n\n")
+		content.WriteString("This tool would update AWS VPC configuration for CAPI clusters.\n")
+		content.WriteString("Operations would include:\n")
+		content.WriteString("- Adding/removing subnets\n")
+		content.WriteString("- Updating route tables\n")
+		content.WriteString("- Modifying security group rules\n")
+		content.WriteString("- Configuring VPC peering\n\n")
+		content.WriteString("Note: VPC updates must be done carefully to avoid disrupting running clusters.\n")
+
+		return newToolResult(content.String(), placeholderResult)
+	}
+}
+
+// createAWSManageSecurityGroupsHandler manages AWS security groups
+func createAWSManageSecurityGroupsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var content strings.Builder
+		content.WriteString("AWS Security Groups Management (Placeholder)\n\n")
+		content.WriteString("This tool would manage security groups for CAPI AWS clusters.\n")
+		content.WriteString("Operations would include:\n")
+		content.WriteString("- Adding/removing ingress rules\n")
+		content.WriteString("- Adding/removing egress rules\n")
+		content.WriteString("- Creating new security groups\n")
+		content.WriteString("- Attaching security groups to instances\n\n")
+		content.WriteString("Common use cases:\n")
+		content.WriteString("- Opening ports for additional services\n")
+		content.WriteString("- Restricting access to specific IP ranges\n")
+		content.WriteString("- Enabling inter-cluster communication\n")
+
+		return newToolResult(content.String(), placeholderResult)
+	}
+}
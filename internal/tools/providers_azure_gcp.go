@@ -0,0 +1,401 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerAzureTools adds the Azure infrastructure tools
+func registerAzureTools(s Registry, serverCtx *ServerContext) {
+	azureListClustersTool := mcp.NewTool(
+		"capi_azure_list_clusters",
+		mcp.WithDescription("List Azure clusters"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace to filter clusters (optional)"),
+		),
+	)
+	addTool(s, azureListClustersTool, createProviderListClustersHandler(serverCtx, capi.ProviderAzure))
+
+	azureGetClusterTool := mcp.NewTool(
+		"capi_azure_get_cluster",
+		mcp.WithDescription("Get Azure cluster details"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Cluster namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Cluster name"),
+		),
+	)
+	addTool(s, azureGetClusterTool, createAzureGetClusterHandler(serverCtx))
+
+	azureListAKSClustersTool := mcp.NewTool(
+		"capi_azure_list_aks_clusters",
+		mcp.WithDescription("List AKS clusters managed through AzureManagedControlPlanes with their version, location and node pools"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace to filter clusters (optional)"),
+		),
+	)
+	addTool(s, azureListAKSClustersTool, createAzureListAKSClustersHandler(serverCtx))
+
+	azureManageResourceGroupTool := mcp.NewTool(
+		"capi_azure_manage_resource_group",
+		mcp.WithDescription("Manage resource groups (placeholder)"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Cluster namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Cluster name"),
+		),
+		mcp.WithString("operation",
+			mcp.Required(),
+			mcp.Description("Operation to perform"),
+		),
+	)
+	addTool(s, azureManageResourceGroupTool, createAzureManageResourceGroupHandler(serverCtx))
+
+	azureNetworkConfigTool := mcp.NewTool(
+		"capi_azure_network_config",
+		mcp.WithDescription("Configure Azure networking (placeholder)"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Cluster namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Cluster name"),
+		),
+		mcp.WithString("operation",
+			mcp.Required(),
+			mcp.Description("Operation to perform"),
+		),
+	)
+	addTool(s, azureNetworkConfigTool, createAzureNetworkConfigHandler(serverCtx))
+}
+
+// registerGCPTools adds the GCP infrastructure tools
+func registerGCPTools(s Registry, serverCtx *ServerContext) {
+	gcpListClustersTool := mcp.NewTool(
+		"capi_gcp_list_clusters",
+		mcp.WithDescription("List GCP clusters"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace to filter clusters (optional)"),
+		),
+	)
+	addTool(s, gcpListClustersTool, createProviderListClustersHandler(serverCtx, capi.ProviderGCP))
+
+	gcpGetClusterTool := mcp.NewTool(
+		"capi_gcp_get_cluster",
+		mcp.WithDescription("Get GCP cluster details"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Cluster namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Cluster name"),
+		),
+	)
+	addTool(s, gcpGetClusterTool, createGCPGetClusterHandler(serverCtx))
+
+	gcpManageNetworkTool := mcp.NewTool(
+		"capi_gcp_manage_network",
+		mcp.WithDescription("Manage GCP networks (placeholder)"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Cluster namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Cluster name"),
+		),
+		mcp.WithString("operation",
+			mcp.Required(),
+			mcp.Description("Operation to perform"),
+		),
+	)
+	addTool(s, gcpManageNetworkTool, createGCPManageNetworkHandler(serverCtx))
+}
+
+// Azure Provider Tools
+
+// createAzureGetClusterHandler gets details of an Azure cluster
+func createAzureGetClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		// Get the cluster
+		cluster, err := serverCtx.client(ctx).GetCluster(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get cluster: %w", err))
+		}
+
+		// Verify it's an Azure cluster
+		if capi.ClusterProvider(cluster) != capi.ProviderAzure {
+			return invalidArgument("cluster %s/%s is not an Azure cluster", namespace, name)
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Azure Cluster: %s/%s\n\n", namespace, name))
+
+		// Basic cluster info
+		content.WriteString("Cluster Information:\n")
+		content.WriteString(fmt.Sprintf("  Phase: %s\n", cluster.Status.Phase))
+		content.WriteString(fmt.Sprintf("  Infrastructure Ready: %v\n", cluster.Status.InfrastructureReady))
+		content.WriteString(fmt.Sprintf("  Control Plane Ready: %v\n", cluster.Status.ControlPlaneReady))
+
+		// Infrastructure reference
+		content.WriteString("\nInfrastructure:\n")
+		content.WriteString(fmt.Sprintf("  Kind: %s\n", cluster.Spec.InfrastructureRef.Kind))
+		content.WriteString(fmt.Sprintf("  Name: %s\n", cluster.Spec.InfrastructureRef.Name))
+
+		if ref := cluster.Spec.ControlPlaneRef; ref == nil || ref.Kind != "AzureManagedControlPlane" {
+			content.WriteString("\nNote: For detailed Azure infrastructure information (resource group, vnet, etc.),\n")
+			content.WriteString("you would need to query the AzureCluster resource directly.\n")
+
+			return newToolResult(content.String(), trimObject(cluster))
+		}
+
+		aks, err := serverCtx.client(ctx).GetAKSCluster(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to read the AKS cluster: %w", err))
+		}
+		content.WriteString("\n")
+		content.WriteString(formatAKSCluster(aks))
+
+		return newToolResult(content.String(), map[string]any{"cluster": trimObject(cluster), "aks": aks})
+	}
+}
+
+// createAzureListAKSClustersHandler lists AKS clusters with their node pools
+func createAzureListAKSClustersHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		namespace := params.OptionalString(request.GetArguments(), "namespace", "")
+
+		clusters, err := serverCtx.client(ctx).ListAKSClusters(ctx, namespace)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list AKS clusters: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString("AKS Clusters:\n\n")
+		for i := range clusters {
+			content.WriteString(fmt.Sprintf("Cluster: %s/%s\n", clusters[i].Namespace, clusters[i].Name))
+			content.WriteString(formatAKSCluster(&clusters[i]))
+			content.WriteString("\n")
+		}
+		if len(clusters) == 0 {
+			content.WriteString("No AKS clusters found.\n")
+		} else {
+			content.WriteString(fmt.Sprintf("Total AKS clusters: %d\n", len(clusters)))
+		}
+
+		return newToolResult(content.String(), map[string]any{"clusters": clusters})
+	}
+}
+
+// formatAKSCluster renders an AKS cluster and its node pools for display
+func formatAKSCluster(aks *capi.AKSClusterInfo) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("AzureManagedControlPlane %s:\n", aks.ControlPlane))
+	content.WriteString(fmt.Sprintf("  Version: %s\n", summaryValue(aks.Version)))
+	content.WriteString(fmt.Sprintf("  Location: %s\n", summaryValue(aks.Location)))
+	content.WriteString(fmt.Sprintf("  Resource Group: %s\n", summaryValue(aks.ResourceGroup)))
+	if aks.NodeResourceGroup != "" {
+		content.WriteString(fmt.Sprintf("  Node Resource Group: %s\n", aks.NodeResourceGroup))
+	}
+	content.WriteString(fmt.Sprintf("  SKU Tier: %s\n", summaryValue(aks.SKUTier)))
+	content.WriteString(fmt.Sprintf("  Network Plugin: %s\n", summaryValue(aks.NetworkPlugin)))
+	if aks.NetworkPolicy != "" {
+		content.WriteString(fmt.Sprintf("  Network Policy: %s\n", aks.NetworkPolicy))
+	}
+	content.WriteString(fmt.Sprintf("  Endpoint: %s\n", summaryValue(aks.Endpoint)))
+	content.WriteString(fmt.Sprintf("  Ready: %v\n", aks.Ready))
+
+	content.WriteString(fmt.Sprintf("\nNode Pools (%d):\n", len(aks.NodePools)))
+	for _, pool := range aks.NodePools {
+		content.WriteString(fmt.Sprintf("  - %s (%s, %s): %s, %d/%d ready, version %s",
+			pool.MachinePool, summaryValue(pool.Mode), summaryValue(pool.ProvisioningState), summaryValue(pool.SKU),
+			pool.ReadyReplicas, pool.Replicas, summaryValue(pool.Version)))
+		if pool.Scaling != nil {
+			content.WriteString(fmt.Sprintf(", autoscaled %d-%d", pool.Scaling.MinSize, pool.Scaling.MaxSize))
+		}
+		if len(pool.AvailabilityZones) > 0 {
+			content.WriteString(fmt.Sprintf(", zones %s", strings.Join(pool.AvailabilityZones, ",")))
+		}
+		content.WriteString("\n")
+	}
+	return content.String()
+}
+
+// createAzureManageResourceGroupHandler manages Azure resource groups
+func createAzureManageResourceGroupHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var content strings.Builder
+		content.WriteString("Azure Resource Group Management (Placeholder)\n\n")
+		content.WriteString("This tool would manage Azure resource groups for CAPI clusters.\n")
+		content.WriteString("Operations would include:\n")
+		content.WriteString("- Creating resource groups\n")
+		content.WriteString("- Setting resource group tags\n")
+		content.WriteString("- Managing resource group policies\n")
+		content.WriteString("- Listing resources in a group\n\n")
+		content.WriteString("Note: CAPI typically creates its own resource groups,\n")
+		content.WriteString("but this tool would help with custom configurations.\n")
+
+		return newToolResult(content.String(), placeholderResult)
+	}
+}
+
+// createAzureNetworkConfigHandler configures Azure networking
+func createAzureNetworkConfigHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var content strings.Builder
+		content.WriteString("Azure Network Configuration (Placeholder)\n\n")
+		content.WriteString("This tool would configure Azure networking for CAPI clusters.\n")
+		content.WriteString("Operations would include:\n")
+		content.WriteString("- Creating/updating VNets\n")
+		content.WriteString("- Managing subnets\n")
+		content.WriteString("- Configuring Network Security Groups\n")
+		content.WriteString("- Setting up VNet peering\n")
+		content.WriteString("- Managing load balancers\n\n")
+		content.WriteString("Common configurations:\n")
+		content.WriteString("- Custom subnet layouts\n")
+		content.WriteString("- Private cluster endpoints\n")
+		content.WriteString("- Multi-region networking\n")
+
+		return newToolResult(content.String(), placeholderResult)
+	}
+}
+
+// GCP Provider Tools
+
+// createGCPGetClusterHandler gets details of a GCP cluster
+func createGCPGetClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		// Get the cluster
+		cluster, err := serverCtx.client(ctx).GetCluster(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get cluster: %w", err))
+		}
+
+		// Verify it's a GCP cluster
+		if capi.ClusterProvider(cluster) != capi.ProviderGCP {
+			return invalidArgument("cluster %s/%s is not a GCP cluster", namespace, name)
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("GCP Cluster: %s/%s\n\n", namespace, name))
+
+		// Basic cluster info
+		content.WriteString("Cluster Information:\n")
+		content.WriteString(fmt.Sprintf("  Phase: %s\n", cluster.Status.Phase))
+		content.WriteString(fmt.Sprintf("  Infrastructure Ready: %v\n", cluster.Status.InfrastructureReady))
+		content.WriteString(fmt.Sprintf("  Control Plane Ready: %v\n", cluster.Status.ControlPlaneReady))
+
+		// Infrastructure reference
+		content.WriteString("\nInfrastructure:\n")
+		content.WriteString(fmt.Sprintf("  Kind: %s\n", cluster.Spec.InfrastructureRef.Kind))
+		content.WriteString(fmt.Sprintf("  Name: %s\n", cluster.Spec.InfrastructureRef.Name))
+
+		gcp, err := serverCtx.client(ctx).GetGCPCluster(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to read the GCP infrastructure: %w", err))
+		}
+		content.WriteString("\n")
+		content.WriteString(formatGCPCluster(gcp))
+
+		return newToolResult(content.String(), map[string]any{"cluster": trimObject(cluster), "gcp": gcp})
+	}
+}
+
+// formatGCPCluster renders the GCP infrastructure of a cluster for display
+func formatGCPCluster(gcp *capi.GCPClusterInfo) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("%s %s:\n", gcp.Kind, gcp.Name))
+	content.WriteString(fmt.Sprintf("  Project: %s\n", summaryValue(gcp.Project)))
+	content.WriteString(fmt.Sprintf("  Region: %s\n", summaryValue(gcp.Region)))
+	content.WriteString(fmt.Sprintf("  Ready: %v\n", gcp.Ready))
+	content.WriteString(fmt.Sprintf("  Control Plane Endpoint: %s\n", summaryValue(gcp.ControlPlaneEndpoint)))
+	if gcp.ControlPlane != "" {
+		content.WriteString(fmt.Sprintf("  GCPManagedControlPlane %s: location %s, release channel %s, version %s\n",
+			gcp.ControlPlane, summaryValue(gcp.Location), summaryValue(gcp.ReleaseChannel), summaryValue(gcp.Version)))
+	}
+
+	content.WriteString(fmt.Sprintf("\nNetwork: %s\n", gcp.Network.Name))
+	content.WriteString(fmt.Sprintf("  Self Link: %s\n", summaryValue(gcp.Network.SelfLink)))
+	content.WriteString(fmt.Sprintf("  Auto-create Subnetworks: %v\n", gcp.Network.AutoCreateSubnetworks))
+	content.WriteString(fmt.Sprintf("  Router: %s\n", summaryValue(gcp.Network.Router)))
+	content.WriteString(fmt.Sprintf("  API Server: %s (forwarding rule %s)\n", summaryValue(gcp.Network.APIServerAddress), summaryValue(gcp.Network.APIServerForwardingRule)))
+
+	content.WriteString(fmt.Sprintf("\nSubnets (%d):\n", len(gcp.Subnets)))
+	for _, subnet := range gcp.Subnets {
+		content.WriteString(fmt.Sprintf("  - %s %s %s", subnet.Name, summaryValue(subnet.Region), summaryValue(subnet.CIDR)))
+		if subnet.Purpose != "" {
+			content.WriteString(fmt.Sprintf(" (%s)", subnet.Purpose))
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString(fmt.Sprintf("\nFirewall Rules (%d):\n", len(gcp.FirewallRules)))
+	for _, rule := range gcp.FirewallRules {
+		content.WriteString(fmt.Sprintf("  - %s\n", rule.Name))
+	}
+
+	content.WriteString(fmt.Sprintf("\nFailure Domains (%d):\n", len(gcp.FailureDomains)))
+	for _, domain := range gcp.FailureDomains {
+		suffix := ""
+		if domain.ControlPlane {
+			suffix = " (control plane)"
+		}
+		content.WriteString(fmt.Sprintf("  - %s%s\n", domain.Name, suffix))
+	}
+	return content.String()
+}
+
+// createGCPManageNetworkHandler manages GCP networks
+func createGCPManageNetworkHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var content strings.Builder
+		content.WriteString("GCP Network Management (Placeholder)\n\n")
+		content.WriteString("This tool would manage GCP networks for CAPI clusters.\n")
+		content.WriteString("Operations would include:\n")
+		content.WriteString("- Creating/updating VPC networks\n")
+		content.WriteString("- Managing subnets\n")
+		content.WriteString("- Configuring firewall rules\n")
+		content.WriteString("- Setting up Cloud NAT\n")
+		content.WriteString("- Managing load balancers\n\n")
+		content.WriteString("GCP-specific features:\n")
+		content.WriteString("- Shared VPC support\n")
+		content.WriteString("- Private Google Access\n")
+		content.WriteString("- Cloud Interconnect integration\n")
+
+		return newToolResult(content.String(), placeholderResult)
+	}
+}
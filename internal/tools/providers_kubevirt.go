@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerKubevirtTools adds the KubeVirt infrastructure tools
+func registerKubevirtTools(s Registry, serverCtx *ServerContext) {
+	kubevirtListClustersTool := mcp.NewTool(
+		"capi_kubevirt_list_clusters",
+		mcp.WithDescription("List KubeVirt clusters"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace to filter clusters (optional)"),
+		),
+	)
+	addTool(s, kubevirtListClustersTool, createProviderListClustersHandler(serverCtx, capi.ProviderKubevirt))
+
+	kubevirtGetClusterTool := mcp.NewTool(
+		"capi_kubevirt_get_cluster",
+		mcp.WithDescription("Get KubeVirt cluster details: control plane endpoint, infra cluster and the KubevirtMachineTemplates of its machines"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Cluster namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Cluster name"),
+		),
+	)
+	addTool(s, kubevirtGetClusterTool, createKubevirtGetClusterHandler(serverCtx))
+
+	kubevirtListVMsTool := mcp.NewTool(
+		"capi_kubevirt_list_vms",
+		mcp.WithDescription("List the KubevirtMachines of a KubeVirt cluster with their Machine, node and backing VirtualMachineInstance"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Cluster namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Cluster name"),
+		),
+	)
+	addTool(s, kubevirtListVMsTool, createKubevirtListVMsHandler(serverCtx))
+}
+
+// createKubevirtGetClusterHandler gets details of a KubeVirt cluster
+func createKubevirtGetClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		kubevirt, err := serverCtx.client(ctx).GetKubevirtCluster(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to read the KubeVirt infrastructure: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("KubeVirt Cluster: %s/%s\n\n", namespace, name))
+		content.WriteString(formatKubevirtCluster(kubevirt))
+		content.WriteString("\nList the virtual machines with: capi_kubevirt_list_vms\n")
+
+		return newToolResult(content.String(), map[string]any{"cluster": clusterRef(namespace, name), "kubevirt": kubevirt})
+	}
+}
+
+// formatKubevirtCluster renders the KubeVirt infrastructure of a cluster for display
+func formatKubevirtCluster(kubevirt *capi.KubevirtClusterInfo) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("KubevirtCluster %s:\n", kubevirt.Name))
+	content.WriteString(fmt.Sprintf("  Control Plane Endpoint: %s\n", summaryValue(kubevirt.ControlPlaneEndpoint)))
+	content.WriteString(fmt.Sprintf("  API Server Service: %s\n", summaryValue(kubevirt.ServiceType)))
+	if kubevirt.InfraClusterSecret != "" {
+		content.WriteString(fmt.Sprintf("  Infra Cluster: external (kubeconfig Secret %s)\n", kubevirt.InfraClusterSecret))
+	} else {
+		content.WriteString("  Infra Cluster: management cluster\n")
+	}
+	content.WriteString(fmt.Sprintf("  Ready: %v\n", kubevirt.Ready))
+	if kubevirt.FailureMessage != "" {
+		content.WriteString(fmt.Sprintf("  ❌ Failure: %s\n", kubevirt.FailureMessage))
+	}
+
+	content.WriteString(fmt.Sprintf("\nMachine Templates (%d):\n", len(kubevirt.MachineTemplates)))
+	for _, template := range kubevirt.MachineTemplates {
+		content.WriteString(fmt.Sprintf("  - %s: %d cores, %s memory, image %s\n",
+			template.Name, template.CPUCores, summaryValue(template.Memory), summaryValue(template.BootImage)))
+		if len(template.UsedBy) > 0 {
+			content.WriteString(fmt.Sprintf("    Used by: %s\n", strings.Join(template.UsedBy, ", ")))
+		}
+	}
+	return content.String()
+}
+
+// createKubevirtListVMsHandler lists the virtual machines of a KubeVirt cluster
+func createKubevirtListVMsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		machines, err := serverCtx.client(ctx).ListKubevirtMachines(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list KubeVirt machines: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("KubeVirt Machines of %s/%s (%d):\n", namespace, name, len(machines.Machines)))
+		if machines.InfraClusterSecret != "" {
+			content.WriteString(fmt.Sprintf("⚠️  The virtual machines run in the external infra cluster of Secret %s; their VirtualMachineInstances are not shown.\n", machines.InfraClusterSecret))
+		}
+		content.WriteString("\n")
+		for _, machine := range machines.Machines {
+			role := "worker"
+			if machine.ControlPlane {
+				role = "control plane"
+			}
+			content.WriteString(fmt.Sprintf("- %s (%s): ready %v\n", machine.Name, role, machine.Ready))
+			if machine.Machine != "" {
+				content.WriteString(fmt.Sprintf("  Machine: %s (%s), node %s\n", machine.Machine, summaryValue(machine.Phase), summaryValue(machine.Node)))
+			} else {
+				content.WriteString("  ⚠️  No Machine references this KubevirtMachine\n")
+			}
+			if machine.VMI != "" {
+				content.WriteString(fmt.Sprintf("  VMI: %s/%s, %s on %s\n", machine.VMNamespace, machine.VMI, summaryValue(machine.VMIPhase), summaryValue(machine.InfraNode)))
+				if len(machine.Addresses) > 0 {
+					content.WriteString(fmt.Sprintf("  Addresses: %s\n", strings.Join(machine.Addresses, ", ")))
+				}
+			} else if machines.InfraClusterSecret == "" {
+				content.WriteString(fmt.Sprintf("  VMI: none in namespace %s\n", machine.VMNamespace))
+			}
+			if machine.FailureReason != "" {
+				content.WriteString(fmt.Sprintf("  ❌ Failure: %s\n", machine.FailureReason))
+			}
+		}
+
+		return newToolResult(content.String(), map[string]any{"cluster": clusterRef(namespace, name), "kubevirt": machines})
+	}
+}
@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerMetal3Tools adds the Metal3 bare metal infrastructure tools
+func registerMetal3Tools(s Registry, serverCtx *ServerContext) {
+	metal3ListClustersTool := mcp.NewTool(
+		"capi_metal3_list_clusters",
+		mcp.WithDescription("List Metal3 bare metal clusters"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace to filter clusters (optional)"),
+		),
+	)
+	addTool(s, metal3ListClustersTool, createProviderListClustersHandler(serverCtx, capi.ProviderMetal3))
+
+	metal3GetClusterTool := mcp.NewTool(
+		"capi_metal3_get_cluster",
+		mcp.WithDescription("Get Metal3 cluster details with the BareMetalHosts its machines are provisioned on"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Cluster namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Cluster name"),
+		),
+	)
+	addTool(s, metal3GetClusterTool, createMetal3GetClusterHandler(serverCtx))
+
+	metal3ListHostsTool := mcp.NewTool(
+		"capi_metal3_list_hosts",
+		mcp.WithDescription("List BareMetalHosts with their provisioning state, power, hardware and the Machine and node provisioned on them"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace to filter hosts (optional)"),
+		),
+		mcp.WithString("cluster",
+			mcp.Description("Only list the hosts of this cluster's machines (optional)"),
+		),
+		mcp.WithString("state",
+			mcp.Description("Only list hosts in this provisioning state, e.g. available or provisioned (optional)"),
+		),
+	)
+	addTool(s, metal3ListHostsTool, createMetal3ListHostsHandler(serverCtx))
+}
+
+// createMetal3GetClusterHandler gets details of a Metal3 cluster
+func createMetal3GetClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		metal3, err := serverCtx.client(ctx).GetMetal3Cluster(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to read the Metal3 infrastructure: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Metal3 Cluster: %s/%s\n\n", namespace, name))
+		content.WriteString(fmt.Sprintf("Metal3Cluster %s:\n", metal3.Name))
+		content.WriteString(fmt.Sprintf("  Control Plane Endpoint: %s\n", summaryValue(metal3.ControlPlaneEndpoint)))
+		content.WriteString(fmt.Sprintf("  Ready: %v\n", metal3.Ready))
+		if metal3.FailureMessage != "" {
+			content.WriteString(fmt.Sprintf("  ❌ Failure: %s\n", metal3.FailureMessage))
+		}
+		content.WriteString(fmt.Sprintf("\nBareMetalHosts (%d):\n", len(metal3.Hosts)))
+		content.WriteString(formatBareMetalHosts(metal3.Hosts))
+
+		return newToolResult(content.String(), map[string]any{"cluster": clusterRef(namespace, name), "metal3": metal3})
+	}
+}
+
+// createMetal3ListHostsHandler lists BareMetalHosts
+func createMetal3ListHostsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace := params.OptionalString(arguments, "namespace", "")
+		clusterName := params.OptionalString(arguments, "cluster", "")
+		state := params.OptionalString(arguments, "state", "")
+
+		hosts, err := serverCtx.client(ctx).ListBareMetalHosts(ctx, namespace, clusterName)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list BareMetalHosts: %w", err))
+		}
+		if state != "" {
+			filtered := []capi.BareMetalHostInfo{}
+			for _, host := range hosts {
+				if strings.EqualFold(host.ProvisioningState, state) {
+					filtered = append(filtered, host)
+				}
+			}
+			hosts = filtered
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("BareMetalHosts (%d):\n", len(hosts)))
+		content.WriteString(formatBareMetalHosts(hosts))
+
+		return newToolResult(content.String(), map[string]any{"hosts": hosts})
+	}
+}
+
+// formatBareMetalHosts renders BareMetalHosts for display
+func formatBareMetalHosts(hosts []capi.BareMetalHostInfo) string {
+	if len(hosts) == 0 {
+		return "  No BareMetalHosts found.\n"
+	}
+	var content strings.Builder
+	for _, host := range hosts {
+		power := "off"
+		if host.PoweredOn {
+			power = "on"
+		}
+		content.WriteString(fmt.Sprintf("  - %s/%s: %s, %s, powered %s\n",
+			host.Namespace, host.Name, summaryValue(host.ProvisioningState), summaryValue(host.OperationalStatus), power))
+		if host.Machine != "" {
+			content.WriteString(fmt.Sprintf("    Machine: %s (cluster %s, node %s)\n", host.Machine, summaryValue(host.Cluster), summaryValue(host.Node)))
+		} else if host.Metal3Machine != "" {
+			content.WriteString(fmt.Sprintf("    Consumed by Metal3Machine %s without a Machine\n", host.Metal3Machine))
+		}
+		content.WriteString(fmt.Sprintf("    BMC: %s, Boot MAC: %s, %d CPUs, %d MiB RAM\n",
+			summaryValue(host.BMCAddress), summaryValue(host.BootMACAddress), host.CPUs, host.RAMMebibytes))
+		if host.Image != "" {
+			content.WriteString(fmt.Sprintf("    Image: %s\n", host.Image))
+		}
+		if host.ErrorMessage != "" {
+			content.WriteString(fmt.Sprintf("    ❌ %s: %s\n", summaryValue(host.ErrorType), host.ErrorMessage))
+		}
+	}
+	return content.String()
+}
@@ -0,0 +1,183 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerVSphereTools adds the vSphere infrastructure tools
+func registerVSphereTools(s Registry, serverCtx *ServerContext) {
+	vsphereListClustersTool := mcp.NewTool(
+		"capi_vsphere_list_clusters",
+		mcp.WithDescription("List vSphere clusters"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace to filter clusters (optional)"),
+		),
+	)
+	addTool(s, vsphereListClustersTool, createProviderListClustersHandler(serverCtx, capi.ProviderVSphere))
+
+	vsphereGetClusterTool := mcp.NewTool(
+		"capi_vsphere_get_cluster",
+		mcp.WithDescription("Get vSphere cluster details"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Cluster namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Cluster name"),
+		),
+	)
+	addTool(s, vsphereGetClusterTool, createVSphereGetClusterHandler(serverCtx))
+
+	vsphereListVMsTool := mcp.NewTool(
+		"capi_vsphere_list_vms",
+		mcp.WithDescription("List the VSphereMachines and VSphereVMs of a vSphere cluster with their Machine, node, power state, placement and resources"),
+		mcp.WithString("namespace",
+			mcp.Required(),
+			mcp.Description("Cluster namespace"),
+		),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Cluster name"),
+		),
+	)
+	addTool(s, vsphereListVMsTool, createVSphereListVMsHandler(serverCtx))
+}
+
+// vSphere Provider Tools
+
+// createVSphereGetClusterHandler gets details of a vSphere cluster
+func createVSphereGetClusterHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		// Get the cluster
+		cluster, err := serverCtx.client(ctx).GetCluster(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get cluster: %w", err))
+		}
+
+		// Verify it's a vSphere cluster
+		if capi.ClusterProvider(cluster) != capi.ProviderVSphere {
+			return invalidArgument("cluster %s/%s is not a vSphere cluster", namespace, name)
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("vSphere Cluster: %s/%s\n\n", namespace, name))
+
+		// Basic cluster info
+		content.WriteString("Cluster Information:\n")
+		content.WriteString(fmt.Sprintf("  Phase: %s\n", cluster.Status.Phase))
+		content.WriteString(fmt.Sprintf("  Infrastructure Ready: %v\n", cluster.Status.InfrastructureReady))
+		content.WriteString(fmt.Sprintf("  Control Plane Ready: %v\n", cluster.Status.ControlPlaneReady))
+
+		// Infrastructure reference
+		content.WriteString("\nInfrastructure:\n")
+		content.WriteString(fmt.Sprintf("  Kind: %s\n", cluster.Spec.InfrastructureRef.Kind))
+		content.WriteString(fmt.Sprintf("  Name: %s\n", cluster.Spec.InfrastructureRef.Name))
+
+		vsphere, err := serverCtx.client(ctx).GetVSphereCluster(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to read the vSphere infrastructure: %w", err))
+		}
+		content.WriteString("\n")
+		content.WriteString(formatVSphereCluster(vsphere))
+		content.WriteString("\nList the virtual machines with: capi_vsphere_list_vms\n")
+
+		return newToolResult(content.String(), map[string]any{"cluster": trimObject(cluster), "vsphere": vsphere})
+	}
+}
+
+// formatVSphereCluster renders the vSphere infrastructure of a cluster for display
+func formatVSphereCluster(vsphere *capi.VSphereClusterInfo) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("VSphereCluster %s:\n", vsphere.Name))
+	content.WriteString(fmt.Sprintf("  Server: %s\n", summaryValue(vsphere.Server)))
+	if vsphere.VCenterVersion != "" {
+		content.WriteString(fmt.Sprintf("  vCenter Version: %s\n", vsphere.VCenterVersion))
+	}
+	content.WriteString(fmt.Sprintf("  Datacenters: %s\n", summaryValue(strings.Join(vsphere.Datacenters, ", "))))
+	content.WriteString(fmt.Sprintf("  Control Plane Endpoint: %s\n", summaryValue(vsphere.ControlPlaneEndpoint)))
+	content.WriteString(fmt.Sprintf("  Identity: %s\n", summaryValue(vsphere.Identity)))
+	content.WriteString(fmt.Sprintf("  Ready: %v\n", vsphere.Ready))
+
+	if len(vsphere.FailureDomains) > 0 {
+		content.WriteString(fmt.Sprintf("\nFailure Domains (%d):\n", len(vsphere.FailureDomains)))
+		for _, domain := range vsphere.FailureDomains {
+			suffix := ""
+			if domain.ControlPlane {
+				suffix = " (control plane)"
+			}
+			content.WriteString(fmt.Sprintf("  - %s%s\n", domain.Name, suffix))
+		}
+	}
+	return content.String()
+}
+
+// createVSphereListVMsHandler lists the virtual machines of a vSphere cluster
+func createVSphereListVMsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		namespace, err := params.RequiredString(arguments, "namespace")
+		if err != nil {
+			return toolError(err)
+		}
+		name, err := params.RequiredString(arguments, "name")
+		if err != nil {
+			return toolError(err)
+		}
+
+		machines, err := serverCtx.client(ctx).ListVSphereMachines(ctx, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list vSphere machines: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("vSphere VMs of cluster %s/%s:\n\n", namespace, name))
+		if len(machines) == 0 {
+			content.WriteString("No VSphereMachines found.\n")
+		}
+		for _, machine := range machines {
+			role := "worker"
+			if machine.ControlPlane {
+				role = "control plane"
+			}
+			content.WriteString(fmt.Sprintf("%s (%s):\n", machine.Name, role))
+			content.WriteString(fmt.Sprintf("  Machine: %s (%s), Node: %s\n", summaryValue(machine.Machine), summaryValue(machine.Phase), summaryValue(machine.Node)))
+			content.WriteString(fmt.Sprintf("  VM: %s, Power State: %s, Ready: %v\n", summaryValue(machine.VM), machine.PowerState, machine.Ready))
+			content.WriteString(fmt.Sprintf("  Datacenter: %s, Resource Pool: %s, Datastore: %s\n",
+				summaryValue(machine.Datacenter), summaryValue(machine.ResourcePool), summaryValue(machine.Datastore)))
+			if machine.Host != "" {
+				content.WriteString(fmt.Sprintf("  Host: %s\n", machine.Host))
+			}
+			content.WriteString(fmt.Sprintf("  Template: %s, %d CPUs, %d MiB, %d GiB\n", summaryValue(machine.Template), machine.NumCPUs, machine.MemoryMiB, machine.DiskGiB))
+			if len(machine.Addresses) > 0 {
+				content.WriteString(fmt.Sprintf("  Addresses: %s\n", strings.Join(machine.Addresses, ", ")))
+			}
+			if machine.FailureReason != "" {
+				content.WriteString(fmt.Sprintf("  ❌ Failure: %s\n", machine.FailureReason))
+			}
+			if machine.Machine == "" {
+				content.WriteString("  ⚠️ No Machine references this VSphereMachine\n")
+			}
+			content.WriteString("\n")
+		}
+
+		return newToolResult(content.String(), map[string]any{"cluster": clusterRef(namespace, name), "machines": machines})
+	}
+}
@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/ratelimit"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// NewRateLimitMiddleware refuses tool calls beyond the budget limiter gives
+// their tool, telling the caller when to retry
+func NewRateLimitMiddleware(limiter *ratelimit.Limiter) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			name := request.Params.Name
+			allowed, rule, retryAfter := limiter.Allow(name, toolGroups(name), time.Now())
+			if !allowed {
+				return mcp.NewToolResultError(fmt.Sprintf("Rate limited: %s is limited to %s calls by the rule %s; retry in %s",
+					name, rule.Limit, rule.Tools, retryAfter.Round(time.Second))), nil
+			}
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,693 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/giantswarm/mcp-capi/internal/rbac"
+	"github.com/giantswarm/mcp-capi/internal/toolpolicy"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+)
+
+// registerRBACTools adds the RBAC inspection tools
+func registerRBACTools(s Registry, serverCtx *ServerContext) {
+	// RBAC tools
+	checkPermissionsTool := mcp.NewTool(
+		"capi_check_permissions",
+		mcp.WithDescription("Check the server's RBAC permissions via SelfSubjectAccessReview (checks the default capability set when verb and resource are omitted)"),
+		mcp.WithString("verb",
+			mcp.Description("Verb to check (e.g., get, list, update, delete)"),
+		),
+		mcp.WithString("resource",
+			mcp.Description("Resource to check (e.g., machinedeployments)"),
+		),
+		mcp.WithString("group",
+			mcp.Description("API group of the resource (e.g., cluster.x-k8s.io, empty for core)"),
+		),
+		mcp.WithString("subresource",
+			mcp.Description("Subresource to check (e.g., scale)"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace to check (empty for cluster-wide)"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Specific resource name (optional)"),
+		),
+	)
+	addTool(s, checkPermissionsTool, createCheckPermissionsHandler(serverCtx))
+
+	rbacManifestTool := mcp.NewTool(
+		"capi_rbac_manifest",
+		mcp.WithDescription("Generate the ClusterRole/Role YAML required by the tools enabled on this server"),
+		mcp.WithBoolean("read_only",
+			mcp.Description("Only include permissions for read-only tools (default: false)"),
+		),
+		mcp.WithString("namespace",
+			mcp.Description("Generate a Role in this namespace instead of a ClusterRole"),
+		),
+		mcp.WithString("name",
+			mcp.Description("Name of the generated roles (default: mcp-capi)"),
+		),
+	)
+	addTool(s, rbacManifestTool, createRBACManifestHandler(serverCtx))
+}
+
+// capiPermission returns a permission on a cluster.x-k8s.io resource
+func capiPermission(resource string, verbs ...string) rbac.Permission {
+	return rbac.Permission{Group: clusterv1.GroupVersion.Group, Resource: resource, Verbs: verbs}
+}
+
+// kcpPermission returns a permission on KubeadmControlPlanes
+func kcpPermission(verbs ...string) rbac.Permission {
+	return rbac.Permission{Group: controlplanev1.GroupVersion.Group, Resource: "kubeadmcontrolplanes", Verbs: verbs}
+}
+
+// awsPermission returns a permission on a resource of the AWS provider
+func awsPermission(resource string, verbs ...string) rbac.Permission {
+	return rbac.Permission{Group: "infrastructure.cluster.x-k8s.io", Resource: resource, Verbs: verbs}
+}
+
+// azurePermission returns a permission on a resource of the Azure provider
+func azurePermission(resource string, verbs ...string) rbac.Permission {
+	return rbac.Permission{Group: "infrastructure.cluster.x-k8s.io", Resource: resource, Verbs: verbs}
+}
+
+// gcpPermission returns a permission on a resource of the GCP provider
+func gcpPermission(resource string, verbs ...string) rbac.Permission {
+	return rbac.Permission{Group: "infrastructure.cluster.x-k8s.io", Resource: resource, Verbs: verbs}
+}
+
+// vspherePermission returns a permission on a resource of the vSphere provider
+func vspherePermission(resource string, verbs ...string) rbac.Permission {
+	return rbac.Permission{Group: "infrastructure.cluster.x-k8s.io", Resource: resource, Verbs: verbs}
+}
+
+// metal3Permission returns a permission on a resource of the Metal3 provider
+func metal3Permission(resource string, verbs ...string) rbac.Permission {
+	return rbac.Permission{Group: "infrastructure.cluster.x-k8s.io", Resource: resource, Verbs: verbs}
+}
+
+// bareMetalHostPermission returns a permission on BareMetalHosts of the
+// baremetal operator
+func bareMetalHostPermission(verbs ...string) rbac.Permission {
+	return rbac.Permission{Group: "metal3.io", Resource: "baremetalhosts", Verbs: verbs}
+}
+
+// kubevirtPermission returns a permission on a resource of the KubeVirt provider
+func kubevirtPermission(resource string, verbs ...string) rbac.Permission {
+	return rbac.Permission{Group: "infrastructure.cluster.x-k8s.io", Resource: resource, Verbs: verbs}
+}
+
+// controlPlanePermission returns a permission on the control planes of all
+// providers
+func controlPlanePermission(verbs ...string) rbac.Permission {
+	return rbac.Permission{Group: controlplanev1.GroupVersion.Group, Resource: "*", Verbs: verbs}
+}
+
+// infrastructurePermission returns a permission on all resources of the
+// infrastructure providers, for the tools working on any discovered kind
+func infrastructurePermission(verbs ...string) rbac.Permission {
+	return rbac.Permission{Group: "infrastructure.cluster.x-k8s.io", Resource: "*", Verbs: verbs}
+}
+
+// nodePermission returns a permission on nodes
+func nodePermission(verbs ...string) rbac.Permission {
+	return rbac.Permission{Resource: "nodes", Verbs: verbs, ClusterScoped: true}
+}
+
+// accessReviewPermission is needed by tools that run RBAC preflight checks
+var accessReviewPermission = rbac.Permission{
+	Group:         "authorization.k8s.io",
+	Resource:      "selfsubjectaccessreviews",
+	Verbs:         []string{"create"},
+	ClusterScoped: true,
+}
+
+// clusterStatusPermissions covers capi.Client.GetClusterStatus
+var clusterStatusPermissions = []rbac.Permission{
+	capiPermission("clusters", "get"),
+	capiPermission("machines", "list"),
+	kcpPermission("get"),
+	azurePermission("azuremanagedcontrolplanes", "get"),
+}
+
+// canaryUpgradePermissions covers fleet.RunCanary: a fleet upgrade plus the
+// canary machine deployment and its rollout
+var canaryUpgradePermissions = withPermissions(clusterStatusPermissions, []rbac.Permission{
+	capiPermission("clusters", "list"),
+	kcpPermission("get", "patch"),
+	capiPermission("machinedeployments", "get", "list", "watch", "patch"),
+	accessReviewPermission,
+})
+
+// backupClusterPermissions covers capi.Client.BackupCluster: the objects of
+// a cluster and its Secrets
+var backupClusterPermissions = []rbac.Permission{
+	capiPermission("clusters", "get"),
+	capiPermission("machinedeployments", "list"),
+	capiPermission("machinepools", "list"),
+	kcpPermission("get"),
+	{Group: "bootstrap.cluster.x-k8s.io", Resource: "*", Verbs: []string{"get"}},
+	infrastructurePermission("get"),
+	{Resource: "secrets", Verbs: []string{"get", "list"}},
+}
+
+// scheduledActionPermissions covers the actions the scheduler runs for
+// capi_create_schedule: hibernating and waking up clusters, scaling machine
+// deployments, upgrades and backups of the clusters of a namespace
+var scheduledActionPermissions = withPermissions(clusterStatusPermissions, backupClusterPermissions, []rbac.Permission{
+	{Resource: "configmaps", Verbs: []string{"create", "get", "list", "update"}},
+	capiPermission("clusters", "list", "patch"),
+	kcpPermission("get", "patch"),
+	capiPermission("machinedeployments", "get", "list", "patch"),
+	capiPermission("machinepools", "list", "patch"),
+	azurePermission("azuremanagedcontrolplanes", "get", "patch"),
+	accessReviewPermission,
+})
+
+// availableVersionsPermissions covers capi.Client.AvailableVersions
+var availableVersionsPermissions = []rbac.Permission{
+	{Group: "release.giantswarm.io", Resource: "releases", Verbs: []string{"list"}, ClusterScoped: true},
+	{Group: "infrastructure.cluster.x-k8s.io", Resource: "vspheremachinetemplates", Verbs: []string{"list"}},
+	{Group: "infrastructure.cluster.x-k8s.io", Resource: "gcpmachinetemplates", Verbs: []string{"list"}},
+	{Group: "infrastructure.cluster.x-k8s.io", Resource: "azuremachinetemplates", Verbs: []string{"list"}},
+	capiPermission("clusters", "list"),
+	capiPermission("machines", "list"),
+	kcpPermission("list"),
+}
+
+// createClusterPermissions covers capi.Client.CreateCluster, including the
+// objects of CAPD development clusters
+var createClusterPermissions = []rbac.Permission{
+	capiPermission("clusters", "create"),
+	capiPermission("machinedeployments", "create"),
+	kcpPermission("create"),
+	{Group: "bootstrap.cluster.x-k8s.io", Resource: "kubeadmconfigtemplates", Verbs: []string{"create"}},
+	{Group: "infrastructure.cluster.x-k8s.io", Resource: "dockerclusters", Verbs: []string{"create"}},
+	{Group: "infrastructure.cluster.x-k8s.io", Resource: "dockermachinetemplates", Verbs: []string{"create"}},
+}
+
+// installedProvidersPermissions covers capi.Client.ListInstalledProviders
+var installedProvidersPermissions = []rbac.Permission{
+	{Group: "clusterctl.cluster.x-k8s.io", Resource: "providers", Verbs: []string{"list"}, ClusterScoped: true},
+	{Group: "apps", Resource: "deployments", Verbs: []string{"list"}, ClusterScoped: true},
+}
+
+// awsMachineTemplatePermissions covers capi.Client.GetAWSMachineTemplate and
+// ListAWSMachineTemplates, which look up the objects using the templates
+var awsMachineTemplatePermissions = []rbac.Permission{
+	awsPermission("awsmachinetemplates", "get", "list"),
+	capiPermission("machinedeployments", "list"),
+	kcpPermission("list"),
+}
+
+// awsSpotPermissions covers capi.Client.ConfigureAWSSpot
+var awsSpotPermissions = []rbac.Permission{
+	capiPermission("machinedeployments", "get", "patch"),
+	capiPermission("machines", "list"),
+	capiPermission("machinehealthchecks", "list"),
+	capiPermission("machinepools", "get"),
+	awsPermission("awsmachinetemplates", "get", "create"),
+	awsPermission("awsmachinepools", "get", "patch"),
+}
+
+// aksClusterPermissions covers capi.Client.ListAKSClusters and
+// capi.Client.GetAKSCluster
+var aksClusterPermissions = []rbac.Permission{
+	capiPermission("clusters", "get", "list"),
+	capiPermission("machinepools", "list"),
+	azurePermission("azuremanagedcontrolplanes", "get", "list"),
+	azurePermission("azuremanagedmachinepools", "list"),
+}
+
+// vsphereMachinePermissions covers capi.Client.GetVSphereCluster and
+// capi.Client.ListVSphereMachines
+var vsphereMachinePermissions = []rbac.Permission{
+	capiPermission("clusters", "get"),
+	capiPermission("machines", "list"),
+	vspherePermission("vsphereclusters", "get"),
+	vspherePermission("vspheremachines", "list"),
+	vspherePermission("vspherevms", "list"),
+}
+
+// updateMachineImagePermissions covers capi.Client.UpdateMachineImage, which
+// clones infrastructure templates of any provider
+var updateMachineImagePermissions = []rbac.Permission{
+	capiPermission("machinedeployments", "get", "patch"),
+	kcpPermission("get", "patch"),
+	{Group: "infrastructure.cluster.x-k8s.io", Resource: "*", Verbs: []string{"get", "create"}},
+}
+
+// controllersStatusPermissions covers capi.Client.GetControllersStatus
+var controllersStatusPermissions = []rbac.Permission{
+	{Group: "apps", Resource: "deployments", Verbs: []string{"list"}, ClusterScoped: true},
+	{Resource: "pods", Verbs: []string{"list"}, ClusterScoped: true},
+	{Group: "admissionregistration.k8s.io", Resource: "validatingwebhookconfigurations", Verbs: []string{"list"}, ClusterScoped: true},
+	{Group: "admissionregistration.k8s.io", Resource: "mutatingwebhookconfigurations", Verbs: []string{"list"}, ClusterScoped: true},
+}
+
+// checkWebhooksPermissions covers capi.Client.CheckWebhooks, which reads the
+// serving certificates of the webhooks and creates a canary in dry-run mode
+var checkWebhooksPermissions = []rbac.Permission{
+	{Group: "admissionregistration.k8s.io", Resource: "validatingwebhookconfigurations", Verbs: []string{"list"}, ClusterScoped: true},
+	{Group: "admissionregistration.k8s.io", Resource: "mutatingwebhookconfigurations", Verbs: []string{"list"}, ClusterScoped: true},
+	{Resource: "services", Verbs: []string{"get"}, ClusterScoped: true},
+	{Group: "discovery.k8s.io", Resource: "endpointslices", Verbs: []string{"list"}, ClusterScoped: true},
+	{Group: "apps", Resource: "deployments", Verbs: []string{"list"}, ClusterScoped: true},
+	{Resource: "secrets", Verbs: []string{"get"}, ClusterScoped: true},
+	capiPermission("clusters", "create"),
+}
+
+// identityPermissions covers capi.Client.ValidateIdentities, which reads the
+// identities, the Secrets they reference and the infrastructure of clusters
+var identityPermissions = []rbac.Permission{
+	capiPermission("clusters", "list"),
+	awsPermission("awsclusterstaticidentities", "list"),
+	awsPermission("awsclusterroleidentities", "list"),
+	awsPermission("awsclustercontrolleridentities", "list"),
+	azurePermission("azureclusteridentities", "list"),
+	vspherePermission("vsphereclusteridentities", "list"),
+	infrastructurePermission("get"),
+	{Group: "controlplane.cluster.x-k8s.io", Resource: "awsmanagedcontrolplanes", Verbs: []string{"get"}},
+	{Group: "apps", Resource: "deployments", Verbs: []string{"list"}, ClusterScoped: true},
+	{Resource: "secrets", Verbs: []string{"get"}, ClusterScoped: true},
+	{Resource: "namespaces", Verbs: []string{"get"}, ClusterScoped: true},
+}
+
+// providerComponentsPermissions covers capi.Client.InstallProviders and
+// UpgradeProviders, which apply arbitrary cluster-scoped and namespaced
+// provider components such as CRDs, RBAC, deployments and webhooks
+var providerComponentsPermissions = withPermissions(installedProvidersPermissions, []rbac.Permission{
+	{Group: "*", Resource: "*", Verbs: []string{"get", "create", "patch"}, ClusterScoped: true},
+})
+
+// withPermissions concatenates permission lists
+func withPermissions(lists ...[]rbac.Permission) []rbac.Permission {
+	var permissions []rbac.Permission
+	for _, list := range lists {
+		permissions = append(permissions, list...)
+	}
+	return permissions
+}
+
+// toolPermissions is the permission registry: the Kubernetes API access each tool needs.
+// Tools that only render static content or manage server-side state need none.
+// Every registered tool must have an entry.
+var toolPermissions = map[string][]rbac.Permission{
+	"test": nil,
+
+	// Cluster tools
+	"capi_create_cluster":     withPermissions(availableVersionsPermissions, createClusterPermissions),
+	"capi_available_versions": withPermissions(clusterStatusPermissions, availableVersionsPermissions),
+	"capi_list_clusters":      {capiPermission("clusters", "list"), capiPermission("machines", "list"), kcpPermission("list"), azurePermission("azuremanagedcontrolplanes", "list")},
+	"capi_get_cluster":        clusterStatusPermissions,
+	"capi_cluster_status":     clusterStatusPermissions,
+	"capi_cluster_health":     clusterStatusPermissions,
+	"capi_probe_api_server":   {capiPermission("clusters", "get", "list"), {Resource: "secrets", Verbs: []string{"get"}}},
+	"capi_upgrade_cluster": withPermissions(clusterStatusPermissions, []rbac.Permission{
+		kcpPermission("get", "patch"),
+		capiPermission("machinedeployments", "list", "patch"),
+		capiPermission("machinepools", "list", "patch"),
+		azurePermission("azuremanagedcontrolplanes", "get", "patch"),
+		accessReviewPermission,
+	}),
+	"capi_upgrade_plan": {
+		capiPermission("clusters", "get"),
+		capiPermission("machines", "list"),
+		capiPermission("machinedeployments", "list"),
+		kcpPermission("get"),
+	},
+	"capi_update_cluster": {capiPermission("clusters", "get", "patch")},
+	"capi_move_cluster":   {capiPermission("clusters", "get")},
+	"capi_backup_cluster": backupClusterPermissions,
+	"capi_push_backup":    backupClusterPermissions,
+	"capi_scale_cluster": {
+		kcpPermission("get", "patch"),
+		capiPermission("machinedeployments", "get", "patch"),
+		capiPermission("clusters", "get"),
+		capiPermission("machinepools", "get", "patch"),
+		azurePermission("azuremanagedmachinepools", "get"),
+	},
+	"capi_get_kubeconfig":   {{Resource: "secrets", Verbs: []string{"get"}}},
+	"capi_issue_kubeconfig": {{Resource: "secrets", Verbs: []string{"get"}}},
+	"capi_rotate_kubeconfig": {
+		capiPermission("clusters", "get"),
+		{Resource: "secrets", Verbs: []string{"get", "update"}},
+	},
+	"capi_pause_cluster":  {capiPermission("clusters", "get", "patch")},
+	"capi_resume_cluster": {capiPermission("clusters", "get", "patch")},
+	// Hibernation scales the control plane of any provider with replicas
+	"capi_hibernate_cluster": {
+		capiPermission("clusters", "get", "patch"),
+		capiPermission("machinedeployments", "list", "patch"),
+		capiPermission("machinepools", "list", "patch"),
+		capiPermission("machines", "list"),
+		controlPlanePermission("get", "patch"),
+	},
+	"capi_wake_cluster": {
+		capiPermission("clusters", "get", "patch"),
+		capiPermission("machinedeployments", "list", "patch"),
+		capiPermission("machinepools", "list", "patch"),
+		controlPlanePermission("get", "patch"),
+	},
+	// Deleting cleans up the objects of a failed generation or clone
+	"capi_generate_cluster": withPermissions(availableVersionsPermissions, []rbac.Permission{
+		capiPermission("clusterclasses", "get"),
+		capiPermission("clusters", "create", "delete"),
+		capiPermission("machinedeployments", "create", "delete"),
+		capiPermission("machinepools", "create", "delete"),
+		kcpPermission("create", "delete"),
+		{Group: "bootstrap.cluster.x-k8s.io", Resource: "*", Verbs: []string{"create", "delete"}},
+		infrastructurePermission("create", "delete"),
+		{Group: "addons.cluster.x-k8s.io", Resource: "clusterresourcesets", Verbs: []string{"create", "delete"}},
+		{Resource: "configmaps", Verbs: []string{"create", "delete"}},
+	}),
+	// Manifests may contain objects of any kind; CRDs are read for their schemas
+	"capi_apply_manifest": {
+		{Group: "*", Resource: "*", Verbs: []string{"get", "patch"}},
+		{Group: "apiextensions.k8s.io", Resource: "customresourcedefinitions", Verbs: []string{"get"}},
+	},
+	"capi_template_variables": {
+		capiPermission("clusterclasses", "get"),
+	},
+	"capi_validate_cluster_config": withPermissions(availableVersionsPermissions, installedProvidersPermissions, []rbac.Permission{
+		capiPermission("clusters", "get"),
+		capiPermission("clusterclasses", "get"),
+		infrastructurePermission("get"),
+		{Resource: "namespaces", Verbs: []string{"get"}, ClusterScoped: true},
+	}),
+	"capi_clone_cluster": {
+		capiPermission("clusters", "get", "create", "delete"),
+		capiPermission("machinedeployments", "list", "create", "delete"),
+		capiPermission("machinepools", "list", "create", "delete"),
+		kcpPermission("get", "create", "delete"),
+		{Group: "bootstrap.cluster.x-k8s.io", Resource: "*", Verbs: []string{"get", "create", "delete"}},
+		infrastructurePermission("get", "create", "delete"),
+	},
+	"capi_export_gitops": {
+		capiPermission("clusters", "get"),
+		capiPermission("machinedeployments", "list"),
+		capiPermission("machinepools", "list"),
+		kcpPermission("get"),
+		{Group: "bootstrap.cluster.x-k8s.io", Resource: "*", Verbs: []string{"get"}},
+		infrastructurePermission("get"),
+	},
+	"capi_delete_cluster": withPermissions(clusterStatusPermissions, []rbac.Permission{capiPermission("clusters", "delete")}),
+
+	// Machine tools
+	"capi_list_machines":             {capiPermission("machines", "list")},
+	"capi_get_machine":               {capiPermission("machines", "get")},
+	"capi_delete_machine":            {capiPermission("machines", "get", "delete")},
+	"capi_remediate_machine":         {capiPermission("machines", "get", "patch")},
+	"capi_set_machine_annotation":    {capiPermission("machines", "get", "patch"), capiPermission("machinesets", "get")},
+	"capi_remove_machine_annotation": {capiPermission("machines", "get", "patch"), capiPermission("machinesets", "get")},
+	"capi_list_machine_annotations":  {capiPermission("machines", "list"), capiPermission("machinesets", "list")},
+	"capi_list_machinedeployments":   {capiPermission("machinedeployments", "list")},
+	"capi_create_machinedeployment":  withPermissions(clusterStatusPermissions, []rbac.Permission{capiPermission("machinedeployments", "create")}),
+	"capi_scale_machinedeployment":   {capiPermission("machinedeployments", "get", "list", "patch")},
+	"capi_update_machinedeployment":  {capiPermission("machinedeployments", "get", "patch")},
+	"capi_rollout_machinedeployment": {capiPermission("machinedeployments", "get", "patch")},
+	"capi_update_machine_image":      updateMachineImagePermissions,
+	"capi_list_machinesets":          {capiPermission("machinesets", "list")},
+	"capi_get_machineset":            {capiPermission("machinesets", "get")},
+
+	// Node tools
+	"capi_drain_node":        {capiPermission("machines", "get"), nodePermission("get", "update"), accessReviewPermission},
+	"capi_cordon_node":       {capiPermission("machines", "get"), nodePermission("get", "update")},
+	"capi_node_status":       {capiPermission("machines", "get"), nodePermission("get")},
+	"capi_autoscaler_status": {capiPermission("machinedeployments", "list"), {Resource: "secrets", Verbs: []string{"get"}}},
+	"capi_top_nodes":         {capiPermission("machines", "list"), {Resource: "secrets", Verbs: []string{"get"}}},
+	"capi_cluster_capacity":  {{Resource: "secrets", Verbs: []string{"get"}}},
+	"capi_pdb_conflicts":     {capiPermission("machines", "get", "list"), capiPermission("machinedeployments", "get"), {Resource: "secrets", Verbs: []string{"get"}}},
+	"capi_node_pods":         {capiPermission("machines", "get"), {Resource: "secrets", Verbs: []string{"get"}}},
+	"capi_node_report": {
+		capiPermission("clusters", "get"),
+		capiPermission("machines", "list"),
+		capiPermission("machinepools", "list"),
+		{Resource: "secrets", Verbs: []string{"get"}},
+	},
+	"capi_addons_status": {{Resource: "secrets", Verbs: []string{"get"}}},
+	"capi_install_cni":   {capiPermission("clusters", "get"), {Resource: "secrets", Verbs: []string{"get"}}},
+	"capi_etcd_status": {
+		capiPermission("clusters", "get"),
+		capiPermission("machines", "list"),
+		kcpPermission("get"),
+		{Resource: "secrets", Verbs: []string{"get"}},
+	},
+
+	// Provider tools
+	"capi_list_infrastructure_providers": installedProvidersPermissions,
+	"capi_server_info":                   installedProvidersPermissions,
+	"capi_get_provider_config":           nil,
+	"capi_controllers_status":            controllersStatusPermissions,
+	"capi_check_webhooks":                checkWebhooksPermissions,
+	"capi_init_providers":                providerComponentsPermissions,
+	"capi_provider_upgrade_plan":         installedProvidersPermissions,
+	"capi_upgrade_providers":             providerComponentsPermissions,
+	"capi_list_identities":               identityPermissions,
+	"capi_validate_identities":           identityPermissions,
+	"capi_list_organizations": {
+		{Group: "security.giantswarm.io", Resource: "organizations", Verbs: []string{"list"}, ClusterScoped: true},
+		{Resource: "namespaces", Verbs: []string{"list"}, ClusterScoped: true},
+		capiPermission("clusters", "list"),
+	},
+	"capi_list_releases": {
+		{Group: "release.giantswarm.io", Resource: "releases", Verbs: []string{"list"}, ClusterScoped: true},
+		capiPermission("clusters", "get"),
+	},
+	"capi_upgrade_release": {
+		{Group: "release.giantswarm.io", Resource: "releases", Verbs: []string{"get"}, ClusterScoped: true},
+		capiPermission("clusters", "get", "patch"),
+	},
+	"capi_list_apps": {
+		capiPermission("clusters", "get"),
+		{Group: "application.giantswarm.io", Resource: "apps", Verbs: []string{"list"}},
+	},
+	"capi_get_app": {{Group: "application.giantswarm.io", Resource: "apps", Verbs: []string{"get"}}},
+	"capi_velero_backup": {
+		capiPermission("clusters", "get"),
+		{Group: "velero.io", Resource: "backups", Verbs: []string{"create"}},
+	},
+	"capi_list_backups": {{Group: "velero.io", Resource: "backups", Verbs: []string{"list"}}},
+	"capi_restore_backup": {
+		capiPermission("clusters", "get"),
+		{Group: "velero.io", Resource: "backups", Verbs: []string{"get"}},
+		{Group: "velero.io", Resource: "restores", Verbs: []string{"create"}},
+	},
+	"capi_list_stored_backups": nil,
+	"capi_restore_cluster": {
+		{Resource: "secrets", Verbs: []string{"create", "get", "patch"}},
+		{Resource: "configmaps", Verbs: []string{"create", "get", "patch"}},
+		capiPermission("*", "create", "get", "patch"),
+		{Group: "controlplane.cluster.x-k8s.io", Resource: "*", Verbs: []string{"create", "get", "patch"}},
+		{Group: "bootstrap.cluster.x-k8s.io", Resource: "*", Verbs: []string{"create", "get", "patch"}},
+		infrastructurePermission("create", "get", "patch"),
+	},
+	"capi_pivot_cluster": withPermissions(installedProvidersPermissions, []rbac.Permission{
+		{Resource: "secrets", Verbs: []string{"get", "list", "patch", "delete"}},
+		capiPermission("*", "get", "list", "patch", "delete"),
+		{Group: "controlplane.cluster.x-k8s.io", Resource: "*", Verbs: []string{"get", "patch", "delete"}},
+		{Group: "bootstrap.cluster.x-k8s.io", Resource: "*", Verbs: []string{"get", "patch", "delete"}},
+		infrastructurePermission("get", "patch", "delete"),
+	}),
+	"capi_backup_history": {
+		capiPermission("clusters", "list"),
+		{Resource: "configmaps", Verbs: []string{"list"}},
+	},
+	"capi_backup_status": {
+		{Group: "velero.io", Resource: "backups", Verbs: []string{"get"}},
+		{Group: "velero.io", Resource: "restores", Verbs: []string{"get"}},
+	},
+	"capi_prepare_namespace": withPermissions(identityPermissions, []rbac.Permission{
+		{Resource: "namespaces", Verbs: []string{"create", "update"}, ClusterScoped: true},
+		{Resource: "secrets", Verbs: []string{"create"}, ClusterScoped: true},
+	}),
+	"capi_aws_list_clusters": {capiPermission("clusters", "get", "list")},
+	"capi_aws_get_cluster": {
+		capiPermission("clusters", "get"),
+		awsPermission("awsclusters", "get"),
+		awsPermission("awsmanagedclusters", "get"),
+		{Group: "controlplane.cluster.x-k8s.io", Resource: "awsmanagedcontrolplanes", Verbs: []string{"get"}},
+	},
+	"capi_aws_create_cluster":          nil,
+	"capi_aws_update_vpc":              nil,
+	"capi_aws_manage_security_groups":  nil,
+	"capi_aws_get_machine_template":    awsMachineTemplatePermissions,
+	"capi_aws_create_machine_template": {awsPermission("awsmachinetemplates", "create")},
+	"capi_aws_clone_machine_template":  {awsPermission("awsmachinetemplates", "get", "create")},
+	"capi_aws_diff_machine_templates":  {awsPermission("awsmachinetemplates", "get")},
+	"capi_aws_configure_spot":          awsSpotPermissions,
+	"capi_azure_list_clusters":         {capiPermission("clusters", "get", "list")},
+	"capi_azure_get_cluster":           aksClusterPermissions,
+	"capi_azure_list_aks_clusters":     aksClusterPermissions,
+	"capi_azure_manage_resource_group": nil,
+	"capi_azure_network_config":        nil,
+	"capi_gcp_list_clusters":           {capiPermission("clusters", "get", "list")},
+	"capi_gcp_get_cluster": {
+		capiPermission("clusters", "get"),
+		gcpPermission("gcpclusters", "get"),
+		gcpPermission("gcpmanagedclusters", "get"),
+		gcpPermission("gcpmanagedcontrolplanes", "get"),
+	},
+	"capi_gcp_manage_network":    nil,
+	"capi_vsphere_list_clusters": {capiPermission("clusters", "get", "list")},
+	"capi_vsphere_get_cluster":   vsphereMachinePermissions,
+	"capi_vsphere_list_vms":      vsphereMachinePermissions,
+	"capi_metal3_list_clusters":  {capiPermission("clusters", "get", "list")},
+	"capi_metal3_get_cluster": {
+		capiPermission("clusters", "get"),
+		capiPermission("machines", "list"),
+		metal3Permission("metal3clusters", "get"),
+		bareMetalHostPermission("list"),
+	},
+	"capi_metal3_list_hosts":      {capiPermission("machines", "list"), bareMetalHostPermission("list")},
+	"capi_kubevirt_list_clusters": {capiPermission("clusters", "get", "list")},
+	"capi_kubevirt_get_cluster": {
+		capiPermission("clusters", "get"),
+		capiPermission("machinedeployments", "list"),
+		kcpPermission("get"),
+		kubevirtPermission("kubevirtclusters", "get"),
+		kubevirtPermission("kubevirtmachinetemplates", "list"),
+	},
+	"capi_kubevirt_list_vms": {
+		capiPermission("clusters", "get"),
+		capiPermission("machines", "list"),
+		kubevirtPermission("kubevirtclusters", "get"),
+		kubevirtPermission("kubevirtmachines", "list"),
+		{Group: "kubevirt.io", Resource: "virtualmachineinstances", Verbs: []string{"list"}},
+	},
+	// The discovery API is readable by any authenticated user
+	"capi_discover_providers":          nil,
+	"capi_list_infrastructure_objects": {infrastructurePermission("list")},
+	"capi_get_infrastructure_object":   {infrastructurePermission("get")},
+
+	// Approval tools
+	"capi_list_approvals":    nil,
+	"capi_approve_operation": nil,
+	"capi_reject_operation":  nil,
+
+	// RBAC tools
+	"capi_check_permissions": {accessReviewPermission},
+	"capi_rbac_manifest":     nil,
+
+	// Audit tools
+	"capi_audit_log": nil,
+
+	// Change history tools
+	"capi_list_changes": nil,
+	// Changes of hibernation cover machine pools and any control plane
+	"capi_revert_change": {
+		capiPermission("clusters", "get", "patch"),
+		capiPermission("machinedeployments", "get", "patch"),
+		capiPermission("machinepools", "get", "patch"),
+		capiPermission("machines", "get", "patch"),
+		controlPlanePermission("get", "patch"),
+	},
+
+	// Management cluster registry tools
+	"capi_list_management_clusters": nil,
+	"capi_use_context":              nil,
+
+	// Job tools
+	"capi_job_status": nil,
+	"capi_job_logs":   nil,
+	"capi_job_cancel": nil,
+
+	// Wait tools; without the watch verb they fall back to polling
+	"capi_wait_for_ready": {
+		capiPermission("clusters", "get", "list", "watch"),
+		capiPermission("machinedeployments", "get", "list", "watch"),
+		kcpPermission("get", "list", "watch"),
+	},
+
+	// Fleet tools
+	"capi_fleet_summary": {capiPermission("clusters", "list"), capiPermission("machines", "list"), kcpPermission("list")},
+	"capi_find_stale_resources": {
+		capiPermission("clusters", "list"),
+		capiPermission("machines", "list"),
+		capiPermission("machinedeployments", "list"),
+		capiPermission("machinesets", "list"),
+	},
+	"capi_export_inventory": {capiPermission("clusters", "list"), capiPermission("machines", "list"), kcpPermission("list")},
+	"capi_fleet_upgrade": withPermissions(clusterStatusPermissions, []rbac.Permission{
+		capiPermission("clusters", "list"),
+		kcpPermission("get", "patch"),
+		capiPermission("machinedeployments", "list", "patch"),
+		accessReviewPermission,
+	}),
+	"capi_canary_upgrade": canaryUpgradePermissions,
+	"capi_canary_resume":  canaryUpgradePermissions,
+	"capi_canary_status":  nil,
+
+	// Schedule tools
+	"capi_create_schedule": scheduledActionPermissions,
+	"capi_list_schedules":  {{Resource: "configmaps", Verbs: []string{"list"}}},
+	"capi_cancel_schedule": {{Resource: "configmaps", Verbs: []string{"get", "delete"}}},
+	"capi_search": {
+		capiPermission("clusters", "list"),
+		capiPermission("machines", "list"),
+		capiPermission("machinedeployments", "list"),
+		kcpPermission("list"),
+	},
+}
+
+// resourcePermissions serve the MCP resources and the watches notifying
+// their subscribers, independent of the enabled tools
+var resourcePermissions = []rbac.Permission{
+	capiPermission("clusters", "get", "list", "watch"),
+	capiPermission("machines", "list", "watch"),
+	capiPermission("machinedeployments", "get", "list", "watch"),
+	kcpPermission("list"),
+}
+
+// RequiredPermissions collects the permissions of the MCP resources and of all
+// tools enabled by the policy, optionally restricted to read-only tools
+func RequiredPermissions(policy *toolpolicy.Policy, readOnly bool) []rbac.Permission {
+	names := make([]string, 0, len(toolPermissions))
+	for name := range toolPermissions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	permissions := slices.Clone(resourcePermissions)
+	for _, name := range names {
+		if readOnly && !readOnlyTools[name] {
+			continue
+		}
+		if !policy.Enabled(name, toolGroups(name)) {
+			continue
+		}
+		permissions = append(permissions, toolPermissions[name]...)
+	}
+	return permissions
+}
+
+// createRBACManifestHandler creates a handler for generating RBAC manifests
+func createRBACManifestHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		arguments := request.GetArguments()
+		readOnly, _ := arguments["read_only"].(bool)
+		namespace, _ := arguments["namespace"].(string)
+		name, _ := arguments["name"].(string)
+
+		manifest, err := rbac.Manifest(RequiredPermissions(serverCtx.ToolPolicy, readOnly), rbac.Options{
+			Name:      name,
+			Namespace: namespace,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate RBAC manifest: %w", err)
+		}
+
+		scope := "full"
+		if readOnly {
+			scope = "read-only"
+		}
+
+		return newToolResult(fmt.Sprintf("# RBAC manifest for the %s tool set enabled on this server\n%s", scope, manifest), map[string]any{"scope": scope, "manifest": manifest})
+	}
+}
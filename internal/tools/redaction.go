@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/giantswarm/mcp-capi/internal/redact"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// secretRequests tells for the tools that can return credentials whether a
+// call explicitly asked for them, which skips redaction
+var secretRequests = map[string]func(arguments map[string]any) bool{
+	"capi_get_kubeconfig": func(arguments map[string]any) bool {
+		return arguments["output"] == kubeconfigFull
+	},
+	"capi_issue_kubeconfig": func(arguments map[string]any) bool {
+		return true
+	},
+	"capi_backup_cluster": func(arguments map[string]any) bool {
+		include, _ := arguments["include_secrets"].(bool)
+		return include
+	},
+}
+
+// NewRedactionMiddleware scrubs certificates, keys and tokens from the text
+// and structured content of every tool result, except for calls that
+// explicitly asked for credentials
+func NewRedactionMiddleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil || result == nil {
+				return result, err
+			}
+			if requested, ok := secretRequests[request.Params.Name]; ok && requested(request.GetArguments()) {
+				return result, nil
+			}
+			return redactResult(result), nil
+		}
+	}
+}
+
+// redactResult scrubs the content blocks of a tool result
+func redactResult(result *mcp.CallToolResult) *mcp.CallToolResult {
+	for i, content := range result.Content {
+		switch content := content.(type) {
+		case mcp.TextContent:
+			content.Text = redact.String(content.Text)
+			result.Content[i] = content
+		case mcp.EmbeddedResource:
+			if resource, ok := content.Resource.(mcp.TextResourceContents); ok {
+				resource.Text = redactJSON(resource.Text)
+				content.Resource = resource
+				result.Content[i] = content
+			}
+		}
+	}
+	return result
+}
+
+// redactJSON scrubs a JSON document by key and value, falling back to
+// scrubbing it as text when it does not parse
+func redactJSON(text string) string {
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return redact.String(text)
+	}
+	encoded, err := json.MarshalIndent(redact.Value(value), "", "  ")
+	if err != nil {
+		return redact.String(text)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,204 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// registerReleaseTools adds the Giant Swarm release tools
+func registerReleaseTools(s Registry, serverCtx *ServerContext) {
+	listReleasesTool := listReleasesParams.NewTool(
+		"capi_list_releases",
+		"List the Giant Swarm releases (Release objects) of the management cluster, newest first, with their Kubernetes version and state. With a cluster, only the releases of its provider and which one it runs.",
+	)
+	addTool(s, listReleasesTool, createListReleasesHandler(serverCtx))
+
+	upgradeReleaseTool := upgradeReleaseParams.NewTool(
+		"capi_upgrade_release",
+		"Upgrade a Giant Swarm cluster to another release by setting its release.giantswarm.io/version label, showing the component and app versions that change. Use dry_run to only show the changes.",
+		withApprovalID(),
+	)
+	addTool(s, upgradeReleaseTool, createUpgradeReleaseHandler(serverCtx))
+}
+
+// listReleasesParams declares the arguments of capi_list_releases
+var listReleasesParams = params.Schema{
+	{Name: "provider", Type: params.String, Enum: capi.ProviderNames(), Description: "Only list the releases of this provider"},
+	{Name: "namespace", Type: params.String, Description: "Namespace of a cluster whose releases to list (optional, with name)"},
+	{Name: "name", Type: params.String, Description: "Name of a cluster whose releases to list (optional, with namespace)"},
+	{Name: "include_deprecated", Type: params.Bool, Default: false, Description: "Also list deprecated releases (default: false)"},
+}
+
+// createListReleasesHandler lists the releases, marking the one a cluster runs
+func createListReleasesHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := listReleasesParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace, name := args.String("namespace"), args.String("name")
+		if (namespace == "") != (name == "") {
+			return invalidArgument("namespace and name are required together")
+		}
+		c := serverCtx.client(ctx)
+
+		provider := capi.Provider(args.String("provider"))
+		current := ""
+		if name != "" {
+			cluster, err := c.GetCluster(ctx, namespace, name)
+			if err != nil {
+				return toolError(fmt.Errorf("failed to get cluster: %w", err))
+			}
+			if clusterProvider := capi.ClusterProvider(cluster); clusterProvider != capi.ProviderUnknown {
+				if provider != "" && provider != clusterProvider {
+					return invalidArgument("cluster %s/%s runs on %s, not %s", namespace, name, clusterProvider, provider)
+				}
+				provider = clusterProvider
+			}
+			current = cluster.Labels[capi.ReleaseVersionLabel]
+		}
+
+		releases, err := c.ListReleases(ctx, provider)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list releases: %w", err))
+		}
+		// Deprecated releases stay listed while a cluster runs them
+		if !args.Bool("include_deprecated") {
+			active := []capi.Release{}
+			for _, release := range releases {
+				if !release.Deprecated() || release.Version == current {
+					active = append(active, release)
+				}
+			}
+			releases = active
+		}
+
+		var content strings.Builder
+		if name != "" {
+			content.WriteString(fmt.Sprintf("Releases for cluster %s/%s", namespace, name))
+			if current != "" {
+				content.WriteString(fmt.Sprintf(" (current: %s)", current))
+			} else {
+				content.WriteString(" (no release label)")
+			}
+			content.WriteString(fmt.Sprintf(" (%d):\n\n", len(releases)))
+		} else {
+			content.WriteString(fmt.Sprintf("Releases (%d):\n\n", len(releases)))
+		}
+		for _, release := range releases {
+			content.WriteString(fmt.Sprintf("- %s", release.Name))
+			if release.Kubernetes != "" {
+				content.WriteString(fmt.Sprintf(", Kubernetes %s", release.Kubernetes))
+			}
+			if release.State != "" {
+				content.WriteString(fmt.Sprintf(", %s", release.State))
+			}
+			if release.Date != "" {
+				content.WriteString(fmt.Sprintf(", released %s", release.Date))
+			}
+			if current != "" && release.Version == current {
+				content.WriteString(" (current)")
+			}
+			content.WriteString("\n")
+		}
+		if len(releases) == 0 {
+			content.WriteString("No releases found.\n")
+		}
+
+		data := map[string]any{"releases": releases}
+		if current != "" {
+			data["currentRelease"] = current
+		}
+		return newToolResult(content.String(), data)
+	}
+}
+
+// upgradeReleaseParams declares the arguments of capi_upgrade_release
+var upgradeReleaseParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "release", Type: params.String, Required: true, Description: "Target release version (e.g., 29.1.0)", Validate: params.Semver},
+	{Name: "dry_run", Type: params.Bool, Default: false, Description: "Only show the component and app changes of the upgrade (default: false)"},
+}
+
+// createUpgradeReleaseHandler upgrades a cluster to another Giant Swarm release
+func createUpgradeReleaseHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := upgradeReleaseParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace, name := args.String("namespace"), args.String("name")
+
+		upgrade, err := serverCtx.client(ctx).UpgradeClusterRelease(ctx, namespace, name, args.String("release"), args.Bool("dry_run"))
+		if err != nil {
+			return toolError(fmt.Errorf("failed to upgrade the release: %w", err))
+		}
+
+		from := upgrade.FromRelease
+		if from == "" {
+			from = "unknown"
+		}
+		var content strings.Builder
+		if upgrade.Applied {
+			content.WriteString(fmt.Sprintf("✅ Cluster %s/%s upgraded from release %s to %s\n\n", namespace, name, from, upgrade.ToRelease))
+		} else {
+			content.WriteString(fmt.Sprintf("Upgrade of cluster %s/%s from release %s to %s (not applied)\n\n", namespace, name, from, upgrade.ToRelease))
+		}
+		content.WriteString(formatReleaseChanges(upgrade.Changes))
+		if upgrade.Applied {
+			content.WriteString("\nThe Giant Swarm operators roll out the new versions; follow the progress with capi_cluster_status.\n")
+		} else {
+			content.WriteString("\nApply the upgrade by calling again with dry_run: false\n")
+		}
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "upgrade-release",
+			Resource:  clusterRef(namespace, name),
+			Details: map[string]any{
+				"fromRelease": upgrade.FromRelease,
+				"toRelease":   upgrade.ToRelease,
+				"changes":     upgrade.Changes,
+				"applied":     upgrade.Applied,
+			},
+		})
+	}
+}
+
+// formatReleaseChanges lists the component and app changes of an upgrade
+func formatReleaseChanges(changes []capi.ReleaseChange) string {
+	if len(changes) == 0 {
+		return "No component or app versions change.\n"
+	}
+	var content strings.Builder
+	for _, kind := range []string{"component", "app"} {
+		title := "Components:\n"
+		if kind == "app" {
+			title = "Apps:\n"
+		}
+		for _, change := range changes {
+			if change.Type != kind {
+				continue
+			}
+			if title != "" {
+				content.WriteString(title)
+				title = ""
+			}
+			switch {
+			case change.From == "":
+				content.WriteString(fmt.Sprintf("  + %s %s (added)\n", change.Name, change.To))
+			case change.To == "":
+				content.WriteString(fmt.Sprintf("  - %s %s (removed)\n", change.Name, change.From))
+			default:
+				content.WriteString(fmt.Sprintf("  • %s: %s → %s\n", change.Name, change.From, change.To))
+			}
+		}
+	}
+	return content.String()
+}
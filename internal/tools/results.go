@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// structuredResultURI identifies the JSON content block of tool results
+const structuredResultURI = "capi://result"
+
+// newToolResult returns a result carrying a human-readable summary followed
+// by the same result as a JSON content block, so automation can consume tool
+// output without parsing the text.
+func newToolResult(text string, data any) (*mcp.CallToolResult, error) {
+	encoded, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode structured result: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.NewTextContent(text),
+			mcp.NewEmbeddedResource(mcp.TextResourceContents{
+				URI:      structuredResultURI,
+				MIMEType: "application/json",
+				Text:     string(encoded),
+			}),
+		},
+	}, nil
+}
+
+// structuredResult returns the JSON content block of a tool result, if any
+func structuredResult(result *mcp.CallToolResult) (json.RawMessage, bool) {
+	for _, content := range result.Content {
+		embedded, ok := content.(mcp.EmbeddedResource)
+		if !ok {
+			continue
+		}
+		if resource, ok := embedded.Resource.(mcp.TextResourceContents); ok && resource.URI == structuredResultURI {
+			return json.RawMessage(resource.Text), true
+		}
+	}
+	return nil, false
+}
+
+// resourceRef identifies a resource affected by an operation
+type resourceRef struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// operationResult is the structured result of tools that change a resource
+type operationResult struct {
+	Operation string         `json:"operation"`
+	Resource  resourceRef    `json:"resource"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// clusterRef identifies a Cluster in structured results
+func clusterRef(namespace, name string) resourceRef {
+	return resourceRef{Kind: "Cluster", Namespace: namespace, Name: name}
+}
+
+// trimObject drops server-side bookkeeping that is noise in structured results
+func trimObject[T metav1.Object](obj T) T {
+	obj.SetManagedFields(nil)
+	return obj
+}
+
+// trimItems applies trimObject to every item of a list
+func trimItems[T any, PT interface {
+	*T
+	metav1.Object
+}](items []T) []T {
+	for i := range items {
+		PT(&items[i]).SetManagedFields(nil)
+	}
+	return items
+}
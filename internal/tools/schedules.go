@@ -0,0 +1,298 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/auth"
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/internal/schedule"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// createScheduleParams declares the arguments of capi_create_schedule
+var createScheduleParams = params.Schema{
+	{Name: "action", Type: params.String, Required: true, Enum: []string{"hibernate", "wake", "scale", "upgrade", "backup"},
+		Description: "Action to run: hibernate or wake the cluster, scale a machine deployment, upgrade the cluster, or push a backup to a backup target"},
+	{Name: "namespace", Type: params.String, Required: true, Validate: params.Namespace,
+		Description: "Namespace of the cluster"},
+	{Name: "cluster_name", Type: params.String, Validate: params.KubernetesName,
+		Description: "Name of the cluster (required, except for backups of all clusters of the namespace)"},
+	{Name: "cron", Type: params.String, Required: true,
+		Description: "When to run, as a cron expression 'minute hour day-of-month month day-of-week' (e.g. '0 22 * * 1-5' for weekdays at 22:00) or @hourly, @daily, @weekly, @monthly"},
+	{Name: "time_zone", Type: params.String, Default: "UTC",
+		Description: "IANA time zone of the cron expression, e.g. Europe/Berlin (default: UTC)"},
+	{Name: "machine_deployment", Type: params.String, Validate: params.KubernetesName,
+		Description: "Machine deployment to scale (required for scale)"},
+	{Name: "replicas", Type: params.Int, NonNegative: true,
+		Description: "Replicas to scale the machine deployment to (required for scale)"},
+	{Name: "target_version", Type: params.String, Validate: params.Semver,
+		Description: "Kubernetes version to upgrade to (required for upgrade)"},
+	{Name: "upgrade_workers", Type: params.Bool, Default: true,
+		Description: "Also upgrade the worker nodes (upgrade only, default: true)"},
+	{Name: "control_plane", Type: params.Bool,
+		Description: "Also scale the control plane to zero when its kind supports it (hibernate only, default: false)"},
+	{Name: "backup_target", Type: params.String,
+		Description: "Backup target to push the bundles to, see capi_list_stored_backups (required for backup)"},
+	{Name: "keep", Type: params.Int, NonNegative: true,
+		Description: "Number of backups to keep per cluster, instead of the count of the target's retention policy (backup only)"},
+	{Name: "include_secrets", Type: params.Bool,
+		Description: "Include the Secrets of the clusters in the bundles (backup only, default: false)"},
+	{Name: maintenanceOverrideArgument, Type: params.Bool,
+		Description: "Also run outside the maintenance windows of the cluster; runs outside them are skipped otherwise (default: false)"},
+	{Name: "description", Type: params.String, Description: "What the schedule is for, e.g. 'nightly hibernation of dev'"},
+}
+
+// listSchedulesParams declares the arguments of capi_list_schedules
+var listSchedulesParams = params.Schema{
+	{Name: "namespace", Type: params.String, Validate: params.Namespace,
+		Description: "Only list the schedules of clusters in this namespace (optional)"},
+	{Name: "cluster_name", Type: params.String, Validate: params.KubernetesName,
+		Description: "Only list the schedules of this cluster (optional)"},
+}
+
+// cancelScheduleParams declares the arguments of capi_cancel_schedule
+var cancelScheduleParams = params.Schema{
+	{Name: "schedule_id", Type: params.String, Required: true, Description: "ID of the schedule to cancel"},
+}
+
+// registerScheduleTools adds the tools managing scheduled operations
+func registerScheduleTools(s Registry, serverCtx *ServerContext) {
+	createScheduleTool := createScheduleParams.NewTool(
+		"capi_create_schedule",
+		"Schedule a recurring hibernation, wake up, machine deployment scale, upgrade or backup of a cluster with a cron expression, e.g. hibernate every weekday night, scale down for the weekend or back up all clusters of a namespace daily. Runs start as background jobs.",
+		withApprovalID(),
+	)
+	addTool(s, createScheduleTool, createCreateScheduleHandler(serverCtx))
+
+	listSchedulesTool := listSchedulesParams.NewTool(
+		"capi_list_schedules",
+		"List scheduled operations with their next run and the job and outcome of their last run",
+	)
+	addTool(s, listSchedulesTool, createListSchedulesHandler(serverCtx))
+
+	cancelScheduleTool := cancelScheduleParams.NewTool(
+		"capi_cancel_schedule",
+		"Cancel a scheduled operation so it no longer runs. A run already started keeps going; cancel its job with capi_job_cancel.",
+	)
+	addTool(s, cancelScheduleTool, createCancelScheduleHandler(serverCtx))
+}
+
+// scheduleVisible reports whether the caller may see a schedule
+func scheduleVisible(ctx context.Context, s *schedule.Schedule) bool {
+	identity, ok := auth.IdentityFromContext(ctx)
+	if !ok || !identity.Policy.Restricted() {
+		return true
+	}
+	return identity.Policy.AllowsNamespace(s.Namespace)
+}
+
+// schedules returns the schedule store, failing when no namespace is
+// configured for it
+func (s *ServerContext) schedules() (*schedule.Store, error) {
+	if s.Schedules == nil {
+		return nil, fmt.Errorf("scheduled operations are not enabled, set MCP_SCHEDULE_NAMESPACE to the namespace storing them")
+	}
+	return s.Schedules, nil
+}
+
+// createCreateScheduleHandler creates a handler for scheduling an operation
+func createCreateScheduleHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := createScheduleParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		store, err := serverCtx.schedules()
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+		clusterName := args.String("cluster_name")
+
+		sched := schedule.Schedule{
+			Cron:                args.String("cron"),
+			TimeZone:            args.String("time_zone"),
+			Action:              schedule.Action(args.String("action")),
+			Namespace:           namespace,
+			Cluster:             clusterName,
+			MaintenanceOverride: args.Bool(maintenanceOverrideArgument),
+			Description:         args.String("description"),
+			Requester:           requesterFromContext(ctx),
+		}
+		c := serverCtx.client(ctx)
+		if clusterName == "" && sched.Action != schedule.ActionBackup {
+			return invalidArgument("cluster_name is required to schedule a %s", sched.Action)
+		}
+		if clusterName != "" {
+			if _, err := c.GetCluster(ctx, namespace, clusterName); err != nil {
+				return toolError(err)
+			}
+		}
+		switch sched.Action {
+		case schedule.ActionScale:
+			if !args.Provided("machine_deployment") || !args.Provided("replicas") {
+				return invalidArgument("machine_deployment and replicas are required to schedule a scale")
+			}
+			md, err := c.GetMachineDeployment(ctx, namespace, args.String("machine_deployment"))
+			if err != nil {
+				return toolError(err)
+			}
+			if md.Spec.ClusterName != clusterName {
+				return invalidArgument("machine deployment %s belongs to cluster %s", md.Name, md.Spec.ClusterName)
+			}
+			replicas := args.Int32("replicas")
+			sched.MachineDeployment = md.Name
+			sched.Replicas = &replicas
+		case schedule.ActionUpgrade:
+			if !args.Provided("target_version") {
+				return invalidArgument("target_version is required to schedule an upgrade")
+			}
+			sched.Version = args.String("target_version")
+			sched.UpgradeWorkers = args.Bool("upgrade_workers")
+		case schedule.ActionHibernate:
+			sched.ControlPlane = args.Bool("control_plane")
+		case schedule.ActionBackup:
+			if _, err := serverCtx.BackupTargets.Get(args.String("backup_target")); err != nil {
+				return invalidArgument("%v", err)
+			}
+			sched.BackupTarget = args.String("backup_target")
+			sched.BackupKeep = args.Int("keep")
+			sched.IncludeSecrets = args.Bool("include_secrets")
+		}
+		if err := sched.Validate(); err != nil {
+			return invalidArgument("%v", err)
+		}
+
+		created, err := store.Create(ctx, sched)
+		if err != nil {
+			return toolError(err)
+		}
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("⏰ Scheduled %s of %s as %s\n\n", created.Action, created.Target(), created.ID))
+		content.WriteString(formatSchedule(created, time.Now()))
+		content.WriteString("\n• List schedules with: capi_list_schedules\n")
+		content.WriteString("• Stop it with: capi_cancel_schedule\n")
+		return newToolResult(content.String(), scheduleResult(created, time.Now()))
+	}
+}
+
+// createListSchedulesHandler creates a handler for listing scheduled operations
+func createListSchedulesHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := listSchedulesParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		store, err := serverCtx.schedules()
+		if err != nil {
+			return toolError(err)
+		}
+		all, err := store.List(ctx)
+		if err != nil {
+			return toolError(err)
+		}
+
+		now := time.Now()
+		namespace, clusterName := args.String("namespace"), args.String("cluster_name")
+		var content strings.Builder
+		visible := []map[string]any{}
+		for i := range all {
+			sched := &all[i]
+			if !scheduleVisible(ctx, sched) || (namespace != "" && sched.Namespace != namespace) || (clusterName != "" && !sched.Covers(sched.Namespace, clusterName)) {
+				continue
+			}
+			visible = append(visible, scheduleResult(sched, now))
+			content.WriteString(formatSchedule(sched, now))
+			content.WriteString("\n")
+		}
+
+		header := fmt.Sprintf("Found %d schedules:\n\n", len(visible))
+		return newToolResult(header+content.String(), map[string]any{"schedules": visible})
+	}
+}
+
+// createCancelScheduleHandler creates a handler for canceling a scheduled
+// operation
+func createCancelScheduleHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := cancelScheduleParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		store, err := serverCtx.schedules()
+		if err != nil {
+			return toolError(err)
+		}
+		id := args.String("schedule_id")
+		sched, err := store.Get(ctx, id)
+		if err == nil && !scheduleVisible(ctx, sched) {
+			err = fmt.Errorf("schedule %s not found", id)
+		}
+		if err != nil {
+			return toolError(err)
+		}
+		if err := store.Delete(ctx, id); err != nil {
+			return toolError(err)
+		}
+
+		return newToolResult(fmt.Sprintf("🗑️ Canceled schedule %s (%s of %s)\n", id, sched.Action, sched.Target()), operationResult{
+			Operation: "cancel-schedule",
+			Resource:  clusterRef(sched.Namespace, sched.Cluster),
+			Details:   map[string]any{"scheduleId": id, "action": sched.Action, "cron": sched.Cron},
+		})
+	}
+}
+
+// scheduleResult is a schedule with its next run for the JSON result
+func scheduleResult(sched *schedule.Schedule, now time.Time) map[string]any {
+	result := map[string]any{"schedule": sched}
+	if next := sched.Next(now); !next.IsZero() {
+		result["nextRun"] = next
+	}
+	return result
+}
+
+// formatSchedule describes a schedule for the text result
+func formatSchedule(sched *schedule.Schedule, now time.Time) string {
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Schedule: %s\n", sched.ID))
+	if sched.Description != "" {
+		content.WriteString(fmt.Sprintf("  Description: %s\n", sched.Description))
+	}
+	content.WriteString(fmt.Sprintf("  Action: %s %s\n", sched.Action, sched.Target()))
+	timeZone := sched.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	content.WriteString(fmt.Sprintf("  Cron: %s (%s)\n", sched.Cron, timeZone))
+	if sched.MaintenanceOverride {
+		content.WriteString("  Maintenance windows: ignored\n")
+	}
+	if sched.BackupKeep > 0 {
+		content.WriteString(fmt.Sprintf("  Keep: %d backups per cluster\n", sched.BackupKeep))
+	}
+	if sched.IncludeSecrets {
+		content.WriteString("  Secrets: included\n")
+	}
+	if next := sched.Next(now); !next.IsZero() {
+		content.WriteString(fmt.Sprintf("  Next run: %s\n", next.Format(time.RFC3339)))
+	}
+	if sched.LastRun != nil {
+		last := sched.LastRun.Format(time.RFC3339)
+		switch {
+		case sched.LastError != "":
+			last += " (failed: " + sched.LastError + ")"
+		case sched.LastJob != "":
+			last += " in job " + sched.LastJob
+		}
+		content.WriteString(fmt.Sprintf("  Last run: %s\n", last))
+	}
+	if sched.Requester != "" {
+		content.WriteString(fmt.Sprintf("  Created by: %s\n", sched.Requester))
+	}
+	return content.String()
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// searchKinds maps the kind argument of capi_search to the searched kinds
+var searchKinds = map[string]string{
+	"cluster":           capi.KindCluster,
+	"control_plane":     capi.KindKubeadmControlPlane,
+	"machinedeployment": capi.KindMachineDeployment,
+	"machine":           capi.KindMachine,
+}
+
+// searchParams declares the arguments of capi_search
+var searchParams = params.Schema{
+	{Name: "query", Type: params.String, Description: "Part of the resource name, ignoring case"},
+	{Name: "namespace", Type: params.String, Description: "Namespace to search (optional, empty for all)"},
+	{Name: labelSelectorArgument, Type: params.String, Validate: params.LabelSelector,
+		Description: "Kubernetes label selector, e.g. 'team=platform'"},
+	{Name: "provider", Type: params.String, Enum: append(capi.ProviderNames(), string(capi.ProviderUnknown)),
+		Description: "Infrastructure provider of the cluster"},
+	{Name: "version", Type: params.String, Description: "Kubernetes version, e.g. v1.29.4, or v1.29 for all its patch versions"},
+	{Name: "phase", Type: params.String, Description: "Phase of the resource, e.g. Provisioned, Running or Failed"},
+	{Name: "kind", Type: params.String, Enum: []string{"cluster", "control_plane", "machinedeployment", "machine"},
+		Description: "Only search one kind of resource (optional, default: all)"},
+}
+
+// registerSearchTools adds the fleet-wide search tool
+func registerSearchTools(s Registry, serverCtx *ServerContext) {
+	searchTool := searchParams.NewTool(
+		"capi_search",
+		"Search clusters, control planes, machine deployments and machines across namespaces by name, labels, provider, Kubernetes version or phase",
+	)
+	addTool(s, searchTool, createSearchHandler(serverCtx))
+}
+
+// createSearchHandler creates a handler for searching the fleet
+func createSearchHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := searchParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		query := capi.SearchQuery{
+			Namespace:     args.String("namespace"),
+			Name:          args.String("query"),
+			LabelSelector: args.String(labelSelectorArgument),
+			Provider:      capi.Provider(args.String("provider")),
+			Version:       args.String("version"),
+			Phase:         args.String("phase"),
+		}
+		if kind := args.String("kind"); kind != "" {
+			query.Kinds = []string{searchKinds[kind]}
+		}
+
+		results, err := serverCtx.client(ctx).Search(ctx, query)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to search: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("Found %d matching resources\n", results.Total()))
+		writeSearchGroup(&content, "Clusters", results.Clusters)
+		writeSearchGroup(&content, "Control Planes", results.ControlPlanes)
+		writeSearchGroup(&content, "Machine Deployments", results.MachineDeployments)
+		writeSearchGroup(&content, "Machines", results.Machines)
+
+		return newToolResult(content.String(), results)
+	}
+}
+
+// writeSearchGroup renders the matches of one kind, one line per match
+func writeSearchGroup(content *strings.Builder, title string, matches []capi.SearchMatch) {
+	if len(matches) == 0 {
+		return
+	}
+	content.WriteString(fmt.Sprintf("\n%s (%d):\n", title, len(matches)))
+	for _, match := range matches {
+		content.WriteString(fmt.Sprintf("  %s/%s  cluster=%s provider=%s version=%s phase=%s\n",
+			match.Namespace, match.Name, summaryValue(match.Cluster), match.Provider,
+			summaryValue(match.Version), summaryValue(match.Phase)))
+	}
+}
@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// CallTracker keeps track of the tool calls in flight, so shutdown can stop
+// accepting new calls and wait for the running ones instead of cutting them
+// off mid-write
+type CallTracker struct {
+	mu       sync.Mutex
+	draining bool
+	// running counts the calls in flight by tool name
+	running map[string]int
+	// idle is closed when the last call finishes while draining
+	idle chan struct{}
+}
+
+// NewCallTracker creates a tracker without calls in flight
+func NewCallTracker() *CallTracker {
+	return &CallTracker{running: map[string]int{}}
+}
+
+// begin registers a call, unless the tracker is draining
+func (t *CallTracker) begin(name string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return false
+	}
+	t.running[name]++
+	return true
+}
+
+// end unregisters a call
+func (t *CallTracker) end(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.running[name]--; t.running[name] == 0 {
+		delete(t.running, name)
+	}
+	if t.draining && len(t.running) == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// Drain refuses new tool calls and waits until the calls in flight have
+// finished. When ctx ends first, it returns an error naming the tools that
+// are still running.
+func (t *CallTracker) Drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.draining = true
+	if len(t.running) == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	if t.idle == nil {
+		t.idle = make(chan struct{})
+	}
+	idle := t.idle
+	t.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.running) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(t.running))
+	for name, count := range t.running {
+		names = append(names, fmt.Sprintf("%s (%d)", name, count))
+	}
+	sort.Strings(names)
+	return fmt.Errorf("tool calls still running: %s", strings.Join(names, ", "))
+}
+
+// NewShutdownMiddleware tracks every tool call with tracker and refuses new
+// calls once the server is shutting down
+func NewShutdownMiddleware(tracker *CallTracker) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			name := request.Params.Name
+			if !tracker.begin(name) {
+				return mcp.NewToolResultError("The server is shutting down and no longer accepts tool calls, retry after it restarts"), nil
+			}
+			defer tracker.end(name)
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package tools defines the MCP tools of the server, grouped by domain, and
+// the middlewares that apply the tool policy, audit log and approval gates.
+package tools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/giantswarm/mcp-capi/internal/approval"
+	"github.com/giantswarm/mcp-capi/internal/audit"
+	"github.com/giantswarm/mcp-capi/internal/backup"
+	"github.com/giantswarm/mcp-capi/internal/fleet"
+	"github.com/giantswarm/mcp-capi/internal/jobs"
+	"github.com/giantswarm/mcp-capi/internal/schedule"
+	"github.com/giantswarm/mcp-capi/internal/toolpolicy"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ServerContext holds shared resources for the tool handlers
+type ServerContext struct {
+	Clients *capi.ClientPool
+	// Client serves the tool calls that select no management cluster instead
+	// of the default client of Clients when set, e.g. a fake in tests
+	Client     capi.CAPIClient
+	Approvals  *approval.Manager
+	ToolPolicy *toolpolicy.Policy
+	AuditLog   *audit.Logger
+	Jobs       *jobs.Manager
+	Canaries   *fleet.CanaryStore
+	// Schedules stores the scheduled operations, nil when they are disabled
+	Schedules *schedule.Store
+	// Providers serves the releases installed by the provider tools, GitHub
+	// when nil
+	Providers capi.ProviderRepository
+	// Discovery decides which provider tools are listed, all of them when nil
+	Discovery *ProviderDiscovery
+	// BackupTargets are the storage backends capi_push_backup pushes
+	// bundles to, none when nil
+	BackupTargets *backup.Targets
+	// KubeconfigDir confines the files capi_get_kubeconfig and the archives
+	// capi_export_gitops write, anywhere when empty
+	KubeconfigDir string
+	// Remote is set for network transports, whose clients may only have the
+	// server read and write files inside KubeconfigDir
+	Remote bool
+	// DefaultKubernetesVersion is the version of clusters created or
+	// generated without one, the newest available version when empty
+	DefaultKubernetesVersion string
+	// ServerVersion is reported by capi_server_info
+	ServerVersion string
+}
+
+// Registry is where tools are registered, usually a *server.MCPServer
+type Registry interface {
+	AddTool(tool mcp.Tool, handler server.ToolHandlerFunc)
+}
+
+// RegisterAll adds every tool of the server. Tools disabled by the tool policy
+// are still registered and hidden by the filter and middleware.
+func RegisterAll(s Registry, serverCtx *ServerContext) {
+	registerTestTool(s)
+	registerClusterTools(s, serverCtx)
+	registerMachineTools(s, serverCtx)
+	registerNodeTools(s, serverCtx)
+	registerProviderTools(s, serverCtx)
+	registerDiscoveryTools(s, serverCtx)
+	registerApprovalTools(s, serverCtx)
+	registerRBACTools(s, serverCtx)
+	registerAuditTools(s, serverCtx)
+	registerChangeTools(s, serverCtx)
+	registerManagementTools(s, serverCtx)
+	registerJobTools(s, serverCtx)
+	registerWaitTools(s, serverCtx)
+	registerSearchTools(s, serverCtx)
+	registerFleetTools(s, serverCtx)
+	registerCanaryTools(s, serverCtx)
+	registerScheduleTools(s, serverCtx)
+	registerManifestTools(s, serverCtx)
+	registerOrganizationTools(s, serverCtx)
+	registerReleaseTools(s, serverCtx)
+	registerAppTools(s, serverCtx)
+	registerGitOpsTools(s, serverCtx)
+	registerVeleroTools(s, serverCtx)
+	registerBackupTools(s, serverCtx)
+	registerPivotTools(s, serverCtx)
+	registerInfoTools(s, serverCtx)
+}
+
+// registerTestTool adds the echo tool used to verify connectivity
+func registerTestTool(s Registry) {
+	testTool := mcp.NewTool(
+		"test",
+		mcp.WithDescription("A simple test tool"),
+		mcp.WithString("message",
+			mcp.Required(),
+			mcp.Description("Message to echo back"),
+		),
+	)
+
+	addTool(s, testTool, testToolHandler)
+}
+
+// testToolHandler handles the test tool
+func testToolHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	arguments := request.GetArguments()
+	message, ok := arguments["message"].(string)
+	if !ok {
+		return invalidArgument("message is required and must be a string")
+	}
+
+	response := fmt.Sprintf("Echo from CAPI MCP Server: %s", message)
+	return newToolResult(response, map[string]any{"message": message})
+}
@@ -0,0 +1,876 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/audit"
+	"github.com/giantswarm/mcp-capi/internal/auth"
+	"github.com/giantswarm/mcp-capi/internal/backup"
+	"github.com/giantswarm/mcp-capi/internal/jobs"
+	"github.com/giantswarm/mcp-capi/internal/maintenance"
+	"github.com/giantswarm/mcp-capi/internal/rbac"
+	"github.com/giantswarm/mcp-capi/internal/toolpolicy"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/giantswarm/mcp-capi/pkg/capi/capitest"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// toolRecorder is a Registry that records registered tools
+type toolRecorder struct {
+	t     *testing.T
+	tools map[string]mcp.Tool
+}
+
+func (r *toolRecorder) AddTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	if _, exists := r.tools[tool.Name]; exists {
+		r.t.Errorf("tool %s is registered twice", tool.Name)
+	}
+	if handler == nil {
+		r.t.Errorf("tool %s is registered without a handler", tool.Name)
+	}
+	r.tools[tool.Name] = tool
+}
+
+// TestRegisterAll ensures every tool is registered once and known to the registries
+func TestRegisterAll(t *testing.T) {
+	recorder := &toolRecorder{t: t, tools: map[string]mcp.Tool{}}
+	RegisterAll(recorder, &ServerContext{})
+
+	for name, tool := range recorder.tools {
+		if _, ok := toolPermissions[name]; !ok {
+			t.Errorf("tool %s has no entry in the permission registry", name)
+		}
+		if tool.Annotations.ReadOnlyHint == nil || *tool.Annotations.ReadOnlyHint != readOnlyTools[name] {
+			t.Errorf("tool %s is registered without matching annotations", name)
+		}
+		if _, ok := tool.InputSchema.Properties[managementClusterArgument]; ok == managementClusterIndependentTools[name] {
+			t.Errorf("tool %s: management cluster selection present = %v", name, ok)
+		}
+		_, hasNamespace := tool.InputSchema.Properties["namespace"]
+		if !hasNamespace && !namespaceIndependentTools[name] && !clusterWideTools[name] {
+			t.Errorf("tool %s takes no namespace but is neither namespace independent nor cluster-wide", name)
+		}
+		if _, ok := tool.InputSchema.Properties[organizationArgument]; hasNamespace && !ok {
+			t.Errorf("tool %s takes a namespace but no %s", name, organizationArgument)
+		}
+		if _, ok := tool.InputSchema.Properties[gitOpsOverrideArgument]; ok != gitOpsGuardedTools[name] {
+			t.Errorf("tool %s: %s present = %v", name, gitOpsOverrideArgument, ok)
+		}
+	}
+	for name := range toolPermissions {
+		if _, ok := recorder.tools[name]; !ok {
+			t.Errorf("permission registry lists %s, which is not registered", name)
+		}
+	}
+	for name := range secretRequests {
+		if _, ok := recorder.tools[name]; !ok {
+			t.Errorf("secret requests list %s, which is not registered", name)
+		}
+	}
+	for name := range maintenanceTargets {
+		if tool, ok := recorder.tools[name]; !ok || readOnlyTools[name] {
+			t.Errorf("maintenance targets list %s, which is not a registered mutating tool", name)
+		} else if _, ok := tool.InputSchema.Properties[maintenanceOverrideArgument]; !ok {
+			t.Errorf("tool %s has no %s argument", name, maintenanceOverrideArgument)
+		}
+	}
+	for name := range previewTools {
+		if tool, ok := recorder.tools[name]; !ok || readOnlyTools[name] {
+			t.Errorf("preview tools list %s, which is not a registered mutating tool", name)
+		} else if _, ok := tool.InputSchema.Properties[previewArgument]; !ok {
+			t.Errorf("tool %s has no %s argument", name, previewArgument)
+		}
+	}
+	for name := range gitOpsGuardedTools {
+		if _, ok := recorder.tools[name]; !ok || readOnlyTools[name] {
+			t.Errorf("GitOps guarded tools list %s, which is not a registered mutating tool", name)
+		}
+	}
+}
+
+// TestToolPermissionsCoverReadOnlyTools ensures read-only manifests never grant mutating verbs
+func TestToolPermissionsCoverReadOnlyTools(t *testing.T) {
+	for name := range readOnlyTools {
+		if _, ok := toolPermissions[name]; !ok {
+			t.Errorf("read-only tool %s has no entry in the permission registry", name)
+		}
+	}
+
+	for _, permission := range RequiredPermissions(&toolpolicy.Policy{}, true) {
+		for _, verb := range permission.Verbs {
+			switch verb {
+			case "get", "list", "watch":
+			default:
+				if permission.Resource != "selfsubjectaccessreviews" {
+					t.Errorf("read-only permission set grants %s on %s", verb, permission.Resource)
+				}
+			}
+		}
+	}
+}
+
+// TestToolPermissionsPatch ensures tools writing through the CAPI client's
+// optimistic-lock merge patch are granted patch rather than update
+func TestToolPermissionsPatch(t *testing.T) {
+	capiGroup := clusterv1.GroupVersion.Group
+	kcpGroup := controlplanev1.GroupVersion.Group
+	patched := map[string][]rbac.Permission{
+		"capi_update_cluster":            {{Group: capiGroup, Resource: "clusters"}},
+		"capi_pause_cluster":             {{Group: capiGroup, Resource: "clusters"}},
+		"capi_resume_cluster":            {{Group: capiGroup, Resource: "clusters"}},
+		"capi_scale_cluster":             {{Group: kcpGroup, Resource: "kubeadmcontrolplanes"}, {Group: capiGroup, Resource: "machinedeployments"}},
+		"capi_upgrade_cluster":           {{Group: kcpGroup, Resource: "kubeadmcontrolplanes"}, {Group: capiGroup, Resource: "machinedeployments"}},
+		"capi_upgrade_release":           {{Group: capiGroup, Resource: "clusters"}},
+		"capi_hibernate_cluster":         {{Group: capiGroup, Resource: "clusters"}, {Group: capiGroup, Resource: "machinedeployments"}, {Group: capiGroup, Resource: "machinepools"}},
+		"capi_wake_cluster":              {{Group: capiGroup, Resource: "clusters"}, {Group: capiGroup, Resource: "machinedeployments"}, {Group: capiGroup, Resource: "machinepools"}},
+		"capi_remediate_machine":         {{Group: capiGroup, Resource: "machines"}},
+		"capi_set_machine_annotation":    {{Group: capiGroup, Resource: "machines"}},
+		"capi_remove_machine_annotation": {{Group: capiGroup, Resource: "machines"}},
+		"capi_scale_machinedeployment":   {{Group: capiGroup, Resource: "machinedeployments"}},
+		"capi_update_machinedeployment":  {{Group: capiGroup, Resource: "machinedeployments"}},
+		"capi_rollout_machinedeployment": {{Group: capiGroup, Resource: "machinedeployments"}},
+		"capi_update_machine_image":      {{Group: kcpGroup, Resource: "kubeadmcontrolplanes"}, {Group: capiGroup, Resource: "machinedeployments"}},
+		"capi_revert_change":             {{Group: capiGroup, Resource: "clusters"}, {Group: capiGroup, Resource: "machinedeployments"}, {Group: capiGroup, Resource: "machinepools"}, {Group: capiGroup, Resource: "machines"}, {Group: kcpGroup, Resource: "kubeadmcontrolplanes"}},
+	}
+	for name, resources := range patched {
+		for _, resource := range resources {
+			granted := false
+			for _, permission := range toolPermissions[name] {
+				if permission.Group == resource.Group && (permission.Resource == resource.Resource || permission.Resource == "*") && slices.Contains(permission.Verbs, "patch") {
+					granted = true
+				}
+			}
+			if !granted {
+				t.Errorf("tool %s does not grant patch on %s", name, resource.Resource)
+			}
+		}
+	}
+}
+
+// TestToolErrorCategories ensures client errors become categorized tool results
+func TestToolErrorCategories(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"cluster not found", fmt.Errorf("failed to get cluster: %w", capi.ErrClusterNotFound), "Cluster not found: failed to get cluster"},
+		{"forbidden", &capi.MissingPermissionsError{Missing: []capi.PermissionCheck{{Verb: "patch", Resource: "clusters"}}}, "capi_check_permissions"},
+		{"uncategorized", errors.New("boom"), "Error: boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := toolError(tt.err)
+			if err != nil {
+				t.Fatalf("toolError returned a transport error: %v", err)
+			}
+			if !result.IsError {
+				t.Fatal("expected an error result")
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if !strings.Contains(text, tt.want) {
+				t.Errorf("result %q does not contain %q", text, tt.want)
+			}
+		})
+	}
+}
+
+// TestNewToolResultStructuredContent ensures results carry both text and JSON content
+func TestNewToolResultStructuredContent(t *testing.T) {
+	result, err := newToolResult("Cluster default/prod paused", operationResult{
+		Operation: "pause",
+		Resource:  clusterRef("default", "prod"),
+	})
+	if err != nil {
+		t.Fatalf("newToolResult() error = %v", err)
+	}
+
+	if text := result.Content[0].(mcp.TextContent).Text; text != "Cluster default/prod paused" {
+		t.Errorf("text content = %q", text)
+	}
+
+	raw, ok := structuredResult(result)
+	if !ok {
+		t.Fatal("result has no structured content")
+	}
+	var decoded operationResult
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("structured content is not valid JSON: %v", err)
+	}
+	if decoded.Operation != "pause" || decoded.Resource.Kind != "Cluster" || decoded.Resource.Name != "prod" {
+		t.Errorf("unexpected structured content: %+v", decoded)
+	}
+
+	if _, err := newToolResult("invalid", map[string]any{"ch": make(chan int)}); err == nil {
+		t.Error("expected an error for data that cannot be encoded")
+	}
+}
+
+// TestToolAnnotations ensures hints agree with the read-only and destructive registries
+func TestToolAnnotations(t *testing.T) {
+	for name := range toolPermissions {
+		annotations := toolAnnotations(name)
+		if *annotations.ReadOnlyHint != readOnlyTools[name] {
+			t.Errorf("%s: readOnlyHint = %v", name, *annotations.ReadOnlyHint)
+		}
+		if *annotations.DestructiveHint != isDestructiveTool(name) {
+			t.Errorf("%s: destructiveHint = %v", name, *annotations.DestructiveHint)
+		}
+		if readOnlyTools[name] && (isDestructiveTool(name) || !*annotations.IdempotentHint) {
+			t.Errorf("%s: read-only tools must be idempotent and non-destructive", name)
+		}
+	}
+
+	for name := range idempotentTools {
+		if _, ok := toolPermissions[name]; !ok {
+			t.Errorf("idempotent tool %s is not a registered tool", name)
+		}
+	}
+}
+
+// TestAuthorize covers read-only and namespace restrictions of identities
+func TestAuthorize(t *testing.T) {
+	viewer := &auth.Identity{Name: "viewer", Policy: auth.Policy{ReadOnly: true}}
+	tenant := &auth.Identity{Name: "tenant", Policy: auth.Policy{Namespaces: []string{"org-acme"}}}
+
+	tests := []struct {
+		name      string
+		identity  *auth.Identity
+		tool      string
+		arguments map[string]any
+		allowed   bool
+	}{
+		{"viewer reads", viewer, "capi_list_clusters", nil, true},
+		{"viewer mutates", viewer, "capi_delete_cluster", map[string]any{"namespace": "org-acme"}, false},
+		{"tenant in namespace", tenant, "capi_scale_machinedeployment", map[string]any{"namespace": "org-acme"}, true},
+		{"tenant in other namespace", tenant, "capi_get_cluster", map[string]any{"namespace": "default"}, false},
+		{"tenant lists all namespaces", tenant, "capi_list_clusters", map[string]any{}, false},
+		{"tenant clones into other namespace", tenant, "capi_clone_cluster", map[string]any{"namespace": "org-acme", "target_namespace": "default"}, false},
+		{"tenant namespace independent tool", tenant, "capi_list_infrastructure_providers", nil, true},
+		{"tenant reverts any change", tenant, "capi_revert_change", map[string]any{"namespace": "org-acme", "change_id": "c1"}, false},
+		{"tenant reads the audit log", tenant, "capi_audit_log", map[string]any{"namespace": "org-acme"}, false},
+		{"viewer reads a redacted kubeconfig", viewer, "capi_get_kubeconfig", map[string]any{"output": kubeconfigRedacted}, true},
+		{"viewer reads a full kubeconfig", viewer, "capi_get_kubeconfig", map[string]any{"output": kubeconfigFull}, false},
+		{"viewer backs up secrets", viewer, "capi_backup_cluster", map[string]any{"include_secrets": true}, false},
+		{"viewer writes a kubeconfig file", viewer, "capi_get_kubeconfig", map[string]any{"path": "prod.yaml"}, false},
+		{"viewer writes an archive", viewer, "capi_export_gitops", map[string]any{"archive": "prod.tar.gz"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := authorize(tt.identity, tt.tool, tt.arguments)
+			if (err == nil) != tt.allowed {
+				t.Errorf("authorize() error = %v, allowed %v", err, tt.allowed)
+			}
+		})
+	}
+
+	filtered := NewAuthFilter()(auth.WithIdentity(context.Background(), viewer), []mcp.Tool{
+		mcp.NewTool("capi_list_clusters"), mcp.NewTool("capi_delete_cluster"),
+	})
+	if len(filtered) != 1 || filtered[0].Name != "capi_list_clusters" {
+		t.Errorf("read-only identity sees %v", filtered)
+	}
+}
+
+// TestProviderToolFilter ensures only the tools of discovered providers are listed
+func TestProviderToolFilter(t *testing.T) {
+	tools := []mcp.Tool{
+		mcp.NewTool("capi_list_clusters"), mcp.NewTool("capi_aws_get_cluster"),
+		mcp.NewTool("capi_vsphere_list_vms"), mcp.NewTool("capi_list_infrastructure_objects"),
+	}
+	names := func(tools []mcp.Tool) string {
+		var names []string
+		for _, tool := range tools {
+			names = append(names, tool.Name)
+		}
+		return strings.Join(names, ",")
+	}
+
+	discovery := &ProviderDiscovery{providers: []capi.Provider{capi.ProviderAWS}}
+	if got := names(NewProviderToolFilter(discovery)(context.Background(), tools)); got != names(tools) {
+		t.Errorf("incomplete discovery lists %s, want all tools", got)
+	}
+
+	discovery.complete = true
+	want := "capi_list_clusters,capi_aws_get_cluster,capi_list_infrastructure_objects"
+	if got := names(NewProviderToolFilter(discovery)(context.Background(), tools)); got != want {
+		t.Errorf("discovery of AWS lists %s, want %s", got, want)
+	}
+}
+
+// TestRedactionMiddleware ensures credentials are scrubbed unless a call asks for them
+func TestRedactionMiddleware(t *testing.T) {
+	handler := NewRedactionMiddleware()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return newToolResult("users:\n- name: admin\n  user:\n    token: s3cr3t\n", map[string]any{
+			"cluster":    clusterRef("default", "prod"),
+			"kubeconfig": "users:\n- name: admin\n  user:\n    token: s3cr3t\n",
+			"user":       map[string]any{"clientKeyData": "a2V5"},
+		})
+	})
+	call := func(name string, arguments map[string]any) string {
+		result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: arguments}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := structuredResult(result)
+		return result.Content[0].(mcp.TextContent).Text + string(data)
+	}
+
+	got := call("capi_get_kubeconfig", map[string]any{"output": "redacted"})
+	for _, secret := range []string{"s3cr3t", "a2V5"} {
+		if strings.Contains(got, secret) {
+			t.Errorf("redacted result still contains %s:\n%s", secret, got)
+		}
+	}
+	if !strings.Contains(got, `"name": "prod"`) {
+		t.Errorf("redacted result lost the cluster:\n%s", got)
+	}
+	if got := call("capi_get_kubeconfig", map[string]any{"output": "full"}); !strings.Contains(got, "s3cr3t") {
+		t.Errorf("explicitly requested credentials are redacted:\n%s", got)
+	}
+}
+
+// TestKubeconfigPath ensures kubeconfigs are only written inside the kubeconfig directory
+func TestKubeconfigPath(t *testing.T) {
+	serverCtx := &ServerContext{KubeconfigDir: "/var/run/kubeconfigs"}
+	if path, err := serverCtx.kubeconfigPath("prod.yaml"); err != nil || path != "/var/run/kubeconfigs/prod.yaml" {
+		t.Errorf("kubeconfigPath(prod.yaml) = %s, %v", path, err)
+	}
+	for _, path := range []string{"../prod.yaml", "/etc/prod.yaml", "/var/run/kubeconfigs"} {
+		if _, err := serverCtx.kubeconfigPath(path); !errors.Is(err, capi.ErrInvalidArgument) {
+			t.Errorf("kubeconfigPath(%s) error = %v, want ErrInvalidArgument", path, err)
+		}
+	}
+
+	// Clients of network transports may not write anywhere
+	remote := &ServerContext{Remote: true}
+	if _, err := remote.kubeconfigPath("/etc/prod.yaml"); !errors.Is(err, capi.ErrInvalidArgument) {
+		t.Errorf("kubeconfigPath() over a network transport error = %v, want ErrInvalidArgument", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "prod.yaml")
+	if err := writeNewFile(path, []byte("first")); err != nil {
+		t.Fatalf("writeNewFile() error = %v", err)
+	}
+	if err := writeNewFile(path, []byte("second")); !errors.Is(err, os.ErrExist) {
+		t.Errorf("writeNewFile() of an existing file error = %v, want ErrExist", err)
+	}
+}
+
+// TestJobTools ensures jobs are followed up on and hidden from tenants of other namespaces
+func TestJobTools(t *testing.T) {
+	serverCtx := &ServerContext{Jobs: jobs.NewManager(jobs.Config{})}
+	tenant := &auth.Identity{Name: "tenant", Policy: auth.Policy{Namespaces: []string{"org-acme"}}}
+
+	job, err := serverCtx.startJob(context.Background(), "upgrade", "default", "default/prod",
+		func(ctx context.Context, logf func(string, ...any)) (any, error) {
+			logf("rolling out")
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	if err != nil {
+		t.Fatalf("startJob() error = %v", err)
+	}
+
+	tenantCtx := auth.WithIdentity(context.Background(), tenant)
+	result, _ := createJobStatusHandler(serverCtx)(tenantCtx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Arguments: map[string]any{"job_id": job.ID}},
+	})
+	if !result.IsError {
+		t.Error("tenant of another namespace sees the job")
+	}
+
+	cancel := mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]any{"job_id": job.ID}}}
+	if result, _ := createJobCancelHandler(serverCtx)(tenantCtx, cancel); !result.IsError {
+		t.Error("tenant of another namespace canceled the job")
+	}
+	result, _ = createJobCancelHandler(serverCtx)(context.Background(), cancel)
+	if result.IsError {
+		t.Fatalf("capi_job_cancel failed: %v", result.Content)
+	}
+	if canceled, _ := serverCtx.Jobs.Get(job.ID); canceled.Status != jobs.StatusCanceled {
+		t.Errorf("job status = %s, want %s", canceled.Status, jobs.StatusCanceled)
+	}
+
+	result, _ = createJobLogsHandler(serverCtx)(context.Background(), cancel)
+	if result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, "rolling out") {
+		t.Errorf("capi_job_logs = %v", result.Content)
+	}
+}
+
+// TestListParams ensures the list tools accept pagination and summary output
+func TestListParams(t *testing.T) {
+	recorder := &toolRecorder{t: t, tools: map[string]mcp.Tool{}}
+	RegisterAll(recorder, &ServerContext{})
+	for _, name := range []string{"capi_list_clusters", "capi_list_machines", "capi_list_machinedeployments"} {
+		for _, argument := range []string{limitArgument, continueArgument, outputArgument, labelSelectorArgument, fieldSelectorArgument} {
+			if _, ok := recorder.tools[name].InputSchema.Properties[argument]; !ok {
+				t.Errorf("tool %s has no %s argument", name, argument)
+			}
+		}
+	}
+
+	result, _ := createListClustersHandler(&ServerContext{})(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Arguments: map[string]any{"limit": -1}},
+	})
+	if !result.IsError {
+		t.Error("capi_list_clusters accepted a negative limit")
+	}
+	result, _ = createListMachineSetsHandler(&ServerContext{})(context.Background(), mcp.CallToolRequest{
+		Params: mcp.CallToolParams{Arguments: map[string]any{"namespace": "default", "label_selector": "team in ("}},
+	})
+	if !result.IsError {
+		t.Error("capi_list_machinesets accepted an invalid label selector")
+	}
+
+	args, err := listMachinesParams.Parse(map[string]any{"namespace": "default", "output": "summary", "limit": 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !summaryOutput(args) || args.Int(limitArgument) != 20 {
+		t.Errorf("unexpected list arguments: summary=%v limit=%d", summaryOutput(args), args.Int(limitArgument))
+	}
+	if hint := continueHint(""); hint != "" {
+		t.Errorf("continueHint() of the last page = %q, want none", hint)
+	}
+}
+
+// TestSearchKinds ensures every kind of capi_search maps to a searched kind
+func TestSearchKinds(t *testing.T) {
+	for _, param := range searchParams {
+		if param.Name != "kind" {
+			continue
+		}
+		for _, kind := range param.Enum {
+			if searchKinds[kind] == "" {
+				t.Errorf("kind %s of capi_search is not mapped", kind)
+			}
+		}
+	}
+}
+
+// TestFormatFleetSummary ensures stuck clusters stand out in the summary
+func TestFormatFleetSummary(t *testing.T) {
+	now := time.Now()
+	summary := capi.SummarizeFleet([]*capi.ClusterStatus{
+		{Namespace: "org-a", Name: "prod", Phase: "Provisioned", Ready: true, Provider: capi.ProviderAWS},
+		{Namespace: "org-b", Name: "old", Phase: "Provisioning", Provider: capi.ProviderAzure, CreatedAt: now.Add(-2 * time.Hour)},
+	}, now, time.Hour)
+
+	text := formatFleetSummary(summary, now)
+	for _, want := range []string{"2 clusters (1 healthy, 0 degraded, 1 unhealthy)", "By provider: aws=1, azure=1", "STUCK org-b/old", "for 2h0m0s"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("summary does not contain %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestFormatUpgradePlan(t *testing.T) {
+	plan := &capi.UpgradePlan{
+		Namespace:     "org-a",
+		Cluster:       "prod",
+		TargetVersion: "v1.30.0",
+		ControlPlane:  &capi.PlannedObject{Kind: "KubeadmControlPlane", Name: "prod-cp", CurrentVersion: "v1.29.4", Replicas: 3, Modified: true, Replacements: 3},
+		MachineDeployments: []capi.PlannedObject{
+			{Kind: "MachineDeployment", Name: "prod-workers", Replicas: 2, Note: "no version set, left unchanged"},
+		},
+		Replacements: 3,
+		Blockers:     []string{"machine prod-workers-x has no node"},
+	}
+
+	text := formatUpgradePlan(plan)
+	for _, want := range []string{
+		"KubeadmControlPlane prod-cp  version=v1.29.4 -> v1.30.0 replicas=3 replacements=3",
+		"MachineDeployment prod-workers  version=unchanged replicas=2 replacements=0 (no version set, left unchanged)",
+		"Estimated machine replacements: 3",
+		"Blockers (1)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("plan does not contain %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestMaintenanceWindowMiddleware(t *testing.T) {
+	c, err := capi.NewClientWithOptions(capi.WithRESTConfig(&rest.Config{Host: "https://127.0.0.1:1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := capi.NewClientPool(c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Changes to prod are only allowed in a window that never opens now
+	prod := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod"}}
+	hour := (time.Now().UTC().Hour() + 12) % 24
+	windows := &maintenance.Config{Rules: []maintenance.Rule{{Cluster: "prod", Windows: []string{fmt.Sprintf("0 %d * * * 1h", hour)}}}}
+	if err := windows.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	maintenanceTargets["test"] = func(ctx context.Context, c capi.CAPIClient, arguments map[string]any) ([]*clusterv1.Cluster, error) {
+		if arguments["name"] == "prod" {
+			return []*clusterv1.Cluster{prod}, nil
+		}
+		return nil, nil
+	}
+	defer delete(maintenanceTargets, "test")
+
+	handler := NewMaintenanceWindowMiddleware(pool, windows)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), nil
+	})
+	call := func(name string, arguments map[string]any) *mcp.CallToolResult {
+		result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: arguments}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	result := call("test", map[string]any{"name": "prod"})
+	if text := result.Content[0].(mcp.TextContent).Text; !result.IsError || !strings.Contains(text, "Outside maintenance window") || !strings.Contains(text, maintenanceOverrideArgument) {
+		t.Errorf("change outside the window = %q, want it rejected", text)
+	}
+	for _, arguments := range []map[string]any{
+		{"name": "prod", maintenanceOverrideArgument: true},
+		{"name": "dev"},
+	} {
+		if result := call("test", arguments); result.IsError {
+			t.Errorf("call with %v rejected: %v", arguments, result.Content)
+		}
+	}
+	if result := call("capi_list_clusters", map[string]any{"name": "prod"}); result.IsError {
+		t.Errorf("read-only call rejected: %v", result.Content)
+	}
+}
+
+func TestGitOpsGuardMiddleware(t *testing.T) {
+	next := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("done"), nil
+	}
+	for _, mode := range []string{GitOpsGuardRefuse, GitOpsGuardWarn} {
+		handler := NewGitOpsGuardMiddleware(mode)(next)
+		for _, arguments := range []map[string]any{{"name": "prod"}, {"name": "prod", gitOpsOverrideArgument: true}} {
+			result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "capi_pause_cluster", Arguments: arguments}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Nothing owned by GitOps was changed, so there is no warning
+			if text := result.Content[0].(mcp.TextContent).Text; text != "done" {
+				t.Errorf("%s mode with %v = %q, want the result unchanged", mode, arguments, text)
+			}
+		}
+	}
+
+	text := formatGitOpsWarning([]capi.GitOpsOwnedObject{{
+		Kind: "Cluster", Namespace: "org-acme", Name: "prod",
+		Owner: capi.GitOpsOwner{Tool: capi.GitOpsFlux, Kind: "Kustomization", Namespace: "flux-system", Name: "clusters"},
+	}})
+	if !strings.Contains(text, "Cluster org-acme/prod, managed by Flux Kustomization flux-system/clusters") {
+		t.Errorf("warning = %q", text)
+	}
+}
+
+func TestPreviewMiddleware(t *testing.T) {
+	var called map[string]any
+	handler := NewPreviewMiddleware()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = request.GetArguments()
+		if request.GetArguments()["name"] == "missing" {
+			return mcp.NewToolResultError("cluster not found"), nil
+		}
+		return mcp.NewToolResultText("Cluster scaled successfully"), nil
+	})
+	call := func(name string, arguments map[string]any) string {
+		result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: arguments}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	text := call("capi_upgrade_cluster", map[string]any{"name": "prod", previewArgument: true, asyncArgument: true})
+	if !strings.Contains(text, "Preview of capi_upgrade_cluster") || !strings.Contains(text, "No object would change") {
+		t.Errorf("preview result = %q", text)
+	}
+	if _, ok := called[asyncArgument]; ok {
+		t.Error("a preview was started in the background")
+	}
+	if text := call("capi_upgrade_cluster", map[string]any{"name": "missing", previewArgument: true}); text != "cluster not found" {
+		t.Errorf("failed preview = %q, want the error of the tool", text)
+	}
+	for _, name := range []string{"capi_scale_cluster", "capi_delete_cluster"} {
+		arguments := map[string]any{"name": "prod"}
+		if name == "capi_delete_cluster" {
+			arguments[previewArgument] = true
+		}
+		if text := call(name, arguments); text != "Cluster scaled successfully" {
+			t.Errorf("%s without a preview = %q", name, text)
+		}
+	}
+
+	text = formatPreview("capi_scale_cluster", []capi.ObjectDiff{{
+		Kind: "MachineDeployment", Namespace: "org-acme", Name: "prod-workers",
+		Changes: []capi.FieldChange{{Field: "spec.replicas", From: int64(2), To: int64(5)}},
+	}})
+	if !strings.Contains(text, "MachineDeployment org-acme/prod-workers:\n  - spec.replicas: 2 → 5") {
+		t.Errorf("formatted preview = %q", text)
+	}
+}
+
+func TestOrganizationMiddleware(t *testing.T) {
+	var called map[string]any
+	handler := NewOrganizationMiddleware()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = request.GetArguments()
+		return mcp.NewToolResultText("ok"), nil
+	})
+	call := func(name string, arguments map[string]any) *mcp.CallToolResult {
+		called = nil
+		result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: arguments}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	call("capi_get_cluster", map[string]any{organizationArgument: "acme", "name": "prod"})
+	if called["namespace"] != "org-acme" || called[organizationArgument] != nil {
+		t.Errorf("arguments = %v, want the namespace of the organization", called)
+	}
+	call("capi_get_cluster", map[string]any{organizationArgument: "acme", "namespace": "org-acme", "name": "prod"})
+	if called["namespace"] != "org-acme" {
+		t.Errorf("arguments = %v, want the matching namespace kept", called)
+	}
+	if result := call("capi_get_cluster", map[string]any{organizationArgument: "acme", "namespace": "default"}); !result.IsError || called != nil {
+		t.Error("a namespace of another organization was accepted")
+	}
+	if result := call("capi_get_cluster", map[string]any{organizationArgument: "Acme!"}); !result.IsError {
+		t.Error("an invalid organization was accepted")
+	}
+
+	// capi_prepare_namespace labels the namespace with the organization
+	call("capi_prepare_namespace", map[string]any{organizationArgument: "acme"})
+	if called["namespace"] != "org-acme" || called[organizationArgument] != "acme" {
+		t.Errorf("arguments = %v, want the namespace defaulted and the organization kept", called)
+	}
+	call("capi_prepare_namespace", map[string]any{organizationArgument: "acme", "namespace": "acme-prod"})
+	if called["namespace"] != "acme-prod" {
+		t.Errorf("arguments = %v, want the namespace kept", called)
+	}
+}
+
+// TestNamespaceMiddleware ensures tool calls stay in the allowed namespaces
+func TestNamespaceMiddleware(t *testing.T) {
+	next := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	}
+	call := func(middleware server.ToolHandlerMiddleware, name string, arguments map[string]any) bool {
+		result, err := middleware(next)(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Name: name, Arguments: arguments}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return !result.IsError
+	}
+
+	if !call(NewNamespaceMiddleware(nil), "capi_list_clusters", nil) {
+		t.Error("a server without allowed namespaces refused to list all clusters")
+	}
+	confined := NewNamespaceMiddleware([]string{"org-acme"})
+	if !call(confined, "capi_get_cluster", map[string]any{"namespace": "org-acme"}) {
+		t.Error("a call in an allowed namespace was refused")
+	}
+	if call(confined, "capi_get_cluster", map[string]any{"namespace": "default"}) {
+		t.Error("a call in another namespace was accepted")
+	}
+	if call(confined, "capi_list_clusters", nil) {
+		t.Error("a call across all namespaces was accepted")
+	}
+	if call(confined, "capi_list_approvals", map[string]any{"namespace": "org-acme"}) {
+		t.Error("a cluster-wide call was accepted")
+	}
+}
+
+// TestEnabledToolGroups ensures capi_server_info counts the tools the policy disables
+func TestEnabledToolGroups(t *testing.T) {
+	groups := enabledToolGroups(&ServerContext{ToolPolicy: &toolpolicy.Policy{Deny: []string{"group:destructive"}}})
+	found := false
+	for _, group := range groups {
+		switch group.Name {
+		case "destructive":
+			found = true
+			if group.Enabled != 0 || group.Total == 0 {
+				t.Errorf("destructive group = %+v, want all tools disabled", group)
+			}
+		case "readonly":
+			if group.Enabled != group.Total {
+				t.Errorf("readonly group = %+v, want all tools enabled", group)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("groups = %+v, want the destructive group", groups)
+	}
+}
+
+// TestAuditMiddleware ensures pushing a backup to a target is audited while
+// returning it inline is not
+func TestAuditMiddleware(t *testing.T) {
+	logger := audit.NewLogger(10)
+	store := &goldenStore{objects: map[string][]byte{}}
+	serverCtx := &ServerContext{
+		Client:        capitest.NewFake(goldenFixtures(nil)...),
+		BackupTargets: backup.NewTargets(backup.NewTarget("s3", store, backup.Retention{})),
+	}
+	middleware := NewAuditMiddleware(logger)
+
+	for _, call := range []struct {
+		name      string
+		handler   server.ToolHandlerFunc
+		arguments map[string]any
+	}{
+		{"capi_backup_cluster", createBackupClusterHandler(serverCtx), map[string]any{"namespace": "org-acme", "name": "prod"}},
+		{"capi_push_backup", createPushBackupHandler(serverCtx), map[string]any{"namespace": "org-acme", "name": "prod", "target": "s3"}},
+	} {
+		request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: call.name, Arguments: call.arguments}}
+		result, err := middleware(call.handler)(context.Background(), request)
+		if err != nil || result.IsError {
+			t.Fatalf("%s = %+v, %v", call.name, result, err)
+		}
+	}
+
+	entries := logger.Recent(audit.Query{})
+	if len(entries) != 1 || entries[0].Tool != "capi_push_backup" || entries[0].Result != audit.ResultSuccess {
+		t.Errorf("audit entries = %+v, want the push only", entries)
+	}
+	if len(store.objects) != 1 {
+		t.Errorf("stored backups = %d, want 1", len(store.objects))
+	}
+}
+
+// TestShutdownMiddleware ensures shutdown waits for running calls and refuses new ones
+func TestShutdownMiddleware(t *testing.T) {
+	tracker := NewCallTracker()
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := NewShutdownMiddleware(tracker)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-release
+		return mcp.NewToolResultText("ok"), nil
+	})
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "capi_scale_cluster"}}
+	go handler(context.Background(), request)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := tracker.Drain(ctx); err == nil || !strings.Contains(err.Error(), "capi_scale_cluster (1)") {
+		t.Errorf("Drain() with a running call error = %v", err)
+	}
+	if result, _ := handler(context.Background(), request); !result.IsError {
+		t.Error("a call during shutdown was accepted")
+	}
+
+	close(release)
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tracker.Drain(ctx); err != nil {
+		t.Errorf("Drain() after the call finished error = %v", err)
+	}
+}
+
+// TestCancellationMiddleware ensures notifications/cancelled aborts the
+// context of the tool call it names
+func TestCancellationMiddleware(t *testing.T) {
+	cancellations := NewCancellations()
+	started := make(chan struct{})
+	next := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-ctx.Done()
+		return toolError(ctx.Err())
+	}
+
+	request := mcp.CallToolRequest{Params: mcp.CallToolParams{Name: "capi_list_clusters"}}
+	cancellations.BeforeCallTool(context.Background(), float64(7), &request)
+	results := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		result, _ := NewCancellationMiddleware(cancellations)(next)(context.Background(), request)
+		results <- result
+	}()
+	<-started
+
+	// Notifications for other requests leave the call running
+	notification := mcp.JSONRPCNotification{Notification: mcp.Notification{Method: MethodNotificationCancelled}}
+	notification.Params.AdditionalFields = map[string]any{"requestId": float64(8)}
+	cancellations.HandleCancelled(context.Background(), notification)
+	select {
+	case <-results:
+		t.Fatal("cancelling another request aborted the call")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	notification.Params.AdditionalFields = map[string]any{"requestId": float64(7), "reason": "user aborted"}
+	cancellations.HandleCancelled(context.Background(), notification)
+	select {
+	case result := <-results:
+		if !result.IsError || !strings.Contains(summarizeResult(result), "Canceled") {
+			t.Errorf("result = %q, want a cancellation error", summarizeResult(result))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the call was not cancelled")
+	}
+	if len(cancellations.calls) != 0 {
+		t.Errorf("%d calls still registered after they finished", len(cancellations.calls))
+	}
+}
+
+// TestClusterHandlers runs cluster handlers against the fake CAPI client
+func TestClusterHandlers(t *testing.T) {
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prod"}}
+	cluster.Status.Phase = string(clusterv1.ClusterPhaseProvisioned)
+	fake := capitest.NewFake(cluster)
+	serverCtx := &ServerContext{Client: fake}
+	call := func(handler server.ToolHandlerFunc, arguments map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		result, err := handler(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: arguments}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	result := call(createListClustersHandler(serverCtx), map[string]any{"namespace": "default"})
+	if text := result.Content[0].(mcp.TextContent).Text; result.IsError || !strings.Contains(text, "Found 1 clusters") || !strings.Contains(text, "prod") {
+		t.Errorf("capi_list_clusters result = %q", text)
+	}
+
+	result = call(createGetClusterHandler(serverCtx), map[string]any{"namespace": "default", "name": "staging"})
+	if text := result.Content[0].(mcp.TextContent).Text; !result.IsError || !strings.Contains(text, "Cluster not found") {
+		t.Errorf("capi_get_cluster of a missing cluster = %q, want a not found error", text)
+	}
+
+	result = call(createPauseClusterHandler(serverCtx), map[string]any{"namespace": "default", "name": "prod"})
+	if result.IsError {
+		t.Fatalf("capi_pause_cluster failed: %s", result.Content[0].(mcp.TextContent).Text)
+	}
+	paused := &clusterv1.Cluster{}
+	if err := fake.Ctrl.Get(context.Background(), client.ObjectKeyFromObject(cluster), paused); err != nil {
+		t.Fatal(err)
+	}
+	if paused.Annotations[clusterv1.PausedAnnotation] != "true" {
+		t.Error("capi_pause_cluster did not pause the cluster")
+	}
+}
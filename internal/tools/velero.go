@@ -0,0 +1,294 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Kinds of Velero objects capi_backup_status reports on
+const (
+	veleroKindBackup  = "backup"
+	veleroKindRestore = "restore"
+)
+
+// veleroNamespaceParam is the namespace Velero runs in, shared by the Velero
+// tools
+var veleroNamespaceParam = params.Param{
+	Name: "velero_namespace", Type: params.String, Default: capi.DefaultVeleroNamespace, Validate: params.Namespace,
+	Description: "Namespace Velero runs in (default: velero)",
+}
+
+// registerVeleroTools adds the tools backing up and restoring clusters with
+// Velero
+func registerVeleroTools(s Registry, serverCtx *ServerContext) {
+	veleroBackupTool := veleroBackupParams.NewTool(
+		"capi_velero_backup",
+		"Start a Velero backup of the namespace of a cluster, with its CAPI objects and Secrets, labeled with the cluster. Velero runs the backup in the background; follow it with capi_backup_status.",
+	)
+	addTool(s, veleroBackupTool, createVeleroBackupHandler(serverCtx))
+
+	listBackupsTool := listBackupsParams.NewTool(
+		"capi_list_backups",
+		"List the Velero backups covering the namespace of a cluster, newest first, with their phase, start time and expiration",
+	)
+	addTool(s, listBackupsTool, createListBackupsHandler(serverCtx))
+
+	restoreBackupTool := restoreBackupParams.NewTool(
+		"capi_restore_backup",
+		"Start a Velero restore of the namespace of a cluster from a completed backup, into the namespace or another one. Refused while the cluster of the backup still exists in the target namespace, since Velero skips existing objects.",
+		withApprovalID(),
+	)
+	addTool(s, restoreBackupTool, createRestoreBackupHandler(serverCtx))
+
+	backupStatusTool := backupStatusParams.NewTool(
+		"capi_backup_status",
+		"Show the status of a Velero backup or restore: phase, progress, errors, warnings and validation errors",
+	)
+	addTool(s, backupStatusTool, createBackupStatusHandler(serverCtx))
+}
+
+// veleroBackupParams declares the arguments of capi_velero_backup
+var veleroBackupParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the cluster"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster"},
+	{Name: "backup_name", Type: params.String, Validate: params.KubernetesName,
+		Description: "Name of the backup (default: <cluster>-<timestamp>)"},
+	{Name: "ttl", Type: params.String, Description: "How long Velero keeps the backup, e.g. 72h (default: the Velero default of 30 days)"},
+	{Name: "storage_location", Type: params.String, Description: "BackupStorageLocation to store the backup in (default: the default location)"},
+	veleroNamespaceParam,
+}
+
+// createVeleroBackupHandler starts a Velero backup of a cluster
+func createVeleroBackupHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := veleroBackupParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace, name := args.String("namespace"), args.String("name")
+		var ttl time.Duration
+		if value := args.String("ttl"); value != "" {
+			if ttl, err = time.ParseDuration(value); err != nil || ttl <= 0 {
+				return invalidArgument("ttl must be a positive duration such as 72h, got %q", value)
+			}
+		}
+
+		backup, err := serverCtx.client(ctx).CreateVeleroBackup(ctx, capi.VeleroBackupOptions{
+			VeleroNamespace: args.String("velero_namespace"),
+			Namespace:       namespace,
+			Cluster:         name,
+			Name:            args.String("backup_name"),
+			TTL:             ttl,
+			StorageLocation: args.String("storage_location"),
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to back up cluster: %w", err))
+		}
+
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("✅ Started Velero backup %s of cluster %s/%s (namespace %s)\n", backup.Name, namespace, name, namespace))
+		if backup.TTL != "" {
+			content.WriteString(fmt.Sprintf("Kept for %s\n", backup.TTL))
+		}
+		content.WriteString("\nFollow the backup with capi_backup_status.\n")
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "velero-backup",
+			Resource:  clusterRef(namespace, name),
+			Details:   map[string]any{"backup": backup},
+		})
+	}
+}
+
+// listBackupsParams declares the arguments of capi_list_backups
+var listBackupsParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace whose backups to list"},
+	{Name: "name", Type: params.String, Description: "Only list the backups of this cluster and of the whole namespace (optional)"},
+	veleroNamespaceParam,
+}
+
+// createListBackupsHandler lists the Velero backups of a namespace
+func createListBackupsHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := listBackupsParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace, name := args.String("namespace"), args.String("name")
+
+		backups, err := serverCtx.client(ctx).ListVeleroBackups(ctx, args.String("velero_namespace"), namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to list backups: %w", err))
+		}
+
+		var content strings.Builder
+		if name != "" {
+			content.WriteString(fmt.Sprintf("Velero backups of cluster %s/%s (%d):\n\n", namespace, name, len(backups)))
+		} else {
+			content.WriteString(fmt.Sprintf("Velero backups of namespace %s (%d):\n\n", namespace, len(backups)))
+		}
+		for _, backup := range backups {
+			content.WriteString(fmt.Sprintf("- %s: %s", backup.Name, capi.VeleroPhase(backup.Phase)))
+			if backup.Cluster != "" {
+				content.WriteString(fmt.Sprintf(", cluster %s", backup.Cluster))
+			} else {
+				content.WriteString(fmt.Sprintf(", namespaces %s", formatIncludedNamespaces(backup.IncludedNamespaces)))
+			}
+			if backup.Started != "" {
+				content.WriteString(fmt.Sprintf(", started %s", backup.Started))
+			}
+			if backup.Expiration != "" {
+				content.WriteString(fmt.Sprintf(", expires %s", backup.Expiration))
+			}
+			content.WriteString("\n")
+		}
+		if len(backups) == 0 {
+			content.WriteString("No backups found.\n")
+		}
+
+		return newToolResult(content.String(), map[string]any{"backups": backups})
+	}
+}
+
+// restoreBackupParams declares the arguments of capi_restore_backup
+var restoreBackupParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace to restore from the backup"},
+	{Name: "backup", Type: params.String, Required: true, Description: "Name of the Velero backup"},
+	{Name: "restore_name", Type: params.String, Validate: params.KubernetesName,
+		Description: "Name of the restore (default: <backup>-restore-<timestamp>)"},
+	{Name: "target_namespace", Type: params.String, Validate: params.Namespace,
+		Description: "Restore the namespace under this name instead (optional)"},
+	veleroNamespaceParam,
+}
+
+// createRestoreBackupHandler starts a Velero restore of a namespace
+func createRestoreBackupHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := restoreBackupParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace := args.String("namespace")
+
+		restore, err := serverCtx.client(ctx).CreateVeleroRestore(ctx, capi.VeleroRestoreOptions{
+			VeleroNamespace: args.String("velero_namespace"),
+			Namespace:       namespace,
+			Backup:          args.String("backup"),
+			Name:            args.String("restore_name"),
+			TargetNamespace: args.String("target_namespace"),
+		})
+		if err != nil {
+			return toolError(fmt.Errorf("failed to restore backup: %w", err))
+		}
+
+		target := namespace
+		if mapped := restore.NamespaceMapping[namespace]; mapped != "" {
+			target = mapped
+		}
+		var content strings.Builder
+		content.WriteString(fmt.Sprintf("✅ Started Velero restore %s of backup %s into namespace %s\n", restore.Name, restore.Backup, target))
+		content.WriteString("\nFollow the restore with capi_backup_status (kind: restore). Restored clusters are reconciled by the providers once their objects exist.\n")
+
+		return newToolResult(content.String(), operationResult{
+			Operation: "velero-restore",
+			Resource:  resourceRef{Kind: "Restore", Namespace: args.String("velero_namespace"), Name: restore.Name},
+			Details:   map[string]any{"restore": restore, "targetNamespace": target},
+		})
+	}
+}
+
+// backupStatusParams declares the arguments of capi_backup_status
+var backupStatusParams = params.Schema{
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace the backup covers or the restore restores"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the backup or restore"},
+	{Name: "kind", Type: params.String, Default: veleroKindBackup, Enum: []string{veleroKindBackup, veleroKindRestore},
+		Description: "Whether name is a backup or a restore (default: backup)"},
+	veleroNamespaceParam,
+}
+
+// createBackupStatusHandler shows the status of a Velero backup or restore
+func createBackupStatusHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := backupStatusParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		namespace, name, veleroNamespace := args.String("namespace"), args.String("name"), args.String("velero_namespace")
+		c := serverCtx.client(ctx)
+
+		var content strings.Builder
+		if args.String("kind") == veleroKindRestore {
+			restore, err := c.GetVeleroRestore(ctx, veleroNamespace, namespace, name)
+			if err != nil {
+				return toolError(fmt.Errorf("failed to get restore: %w", err))
+			}
+			content.WriteString(fmt.Sprintf("Velero restore %s of backup %s: %s\n", restore.Name, restore.Backup, capi.VeleroPhase(restore.Phase)))
+			for from, to := range restore.NamespaceMapping {
+				content.WriteString(fmt.Sprintf("  Namespace: %s → %s\n", from, to))
+			}
+			writeVeleroProgress(&content, restore.Started, restore.Completed, "restored", restore.ItemsRestored, restore.TotalItems,
+				restore.Errors, restore.Warnings, restore.FailureReason, restore.ValidationErrors)
+			return newToolResult(content.String(), map[string]any{"restore": restore})
+		}
+
+		backup, err := c.GetVeleroBackup(ctx, veleroNamespace, namespace, name)
+		if err != nil {
+			return toolError(fmt.Errorf("failed to get backup: %w", err))
+		}
+		content.WriteString(fmt.Sprintf("Velero backup %s: %s\n", backup.Name, capi.VeleroPhase(backup.Phase)))
+		if backup.Cluster != "" {
+			content.WriteString(fmt.Sprintf("  Cluster: %s/%s\n", backup.ClusterNamespace, backup.Cluster))
+		}
+		content.WriteString(fmt.Sprintf("  Namespaces: %s\n", formatIncludedNamespaces(backup.IncludedNamespaces)))
+		if backup.StorageLocation != "" {
+			content.WriteString(fmt.Sprintf("  Storage location: %s\n", backup.StorageLocation))
+		}
+		if backup.Expiration != "" {
+			content.WriteString(fmt.Sprintf("  Expires: %s\n", backup.Expiration))
+		}
+		writeVeleroProgress(&content, backup.Started, backup.Completed, "backed up", backup.ItemsBackedUp, backup.TotalItems,
+			backup.Errors, backup.Warnings, backup.FailureReason, backup.ValidationErrors)
+		if backup.Restorable() {
+			content.WriteString("\nThe backup can be restored with capi_restore_backup.\n")
+		}
+		return newToolResult(content.String(), map[string]any{"backup": backup})
+	}
+}
+
+// writeVeleroProgress writes the timing, progress and problems of a Velero
+// backup or restore
+func writeVeleroProgress(content *strings.Builder, started, completed, verb string, items, total, errors, warnings int64, failureReason string, validationErrors []string) {
+	if started != "" {
+		content.WriteString(fmt.Sprintf("  Started: %s\n", started))
+	}
+	if completed != "" {
+		content.WriteString(fmt.Sprintf("  Completed: %s\n", completed))
+	}
+	if total > 0 {
+		content.WriteString(fmt.Sprintf("  Progress: %d/%d items %s\n", items, total, verb))
+	}
+	if errors > 0 || warnings > 0 {
+		content.WriteString(fmt.Sprintf("  Errors: %d, warnings: %d (details in the Velero logs: velero describe --details)\n", errors, warnings))
+	}
+	if failureReason != "" {
+		content.WriteString(fmt.Sprintf("  Failure: %s\n", failureReason))
+	}
+	for _, msg := range validationErrors {
+		content.WriteString(fmt.Sprintf("  Validation error: %s\n", msg))
+	}
+}
+
+// formatIncludedNamespaces names the namespaces of a backup
+func formatIncludedNamespaces(namespaces []string) string {
+	if len(namespaces) == 0 {
+		return "all"
+	}
+	return strings.Join(namespaces, ", ")
+}
@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/mcp-capi/internal/params"
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// methodProgress is the MCP notification reporting the progress of a request
+const methodProgress = "notifications/progress"
+
+// waitForReadyParams declares the arguments of capi_wait_for_ready
+var waitForReadyParams = params.Schema{
+	{Name: "kind", Type: params.String, Required: true, Enum: []string{"cluster", "control_plane", "machinedeployment"},
+		Description: "What to wait for: a cluster, the control plane of a cluster or a machine deployment"},
+	{Name: "namespace", Type: params.String, Required: true, Description: "Namespace of the resource"},
+	{Name: "name", Type: params.String, Required: true, Description: "Name of the cluster or machine deployment"},
+	{Name: "timeout_seconds", Type: params.Int, Default: 600, NonNegative: true, Description: "Maximum time to wait in seconds (default: 600)"},
+	asyncParam,
+}
+
+// registerWaitTools adds the tools that wait for resources to become ready
+func registerWaitTools(s Registry, serverCtx *ServerContext) {
+	waitForReadyTool := waitForReadyParams.NewTool(
+		"capi_wait_for_ready",
+		"Wait until a cluster, its control plane or a machine deployment is ready, reporting progress along the way",
+	)
+	addTool(s, waitForReadyTool, createWaitForReadyHandler(serverCtx))
+}
+
+// createWaitForReadyHandler creates a handler for waiting on a resource
+func createWaitForReadyHandler(serverCtx *ServerContext) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, err := waitForReadyParams.Parse(request.GetArguments())
+		if err != nil {
+			return toolError(err)
+		}
+		kind := args.String("kind")
+		namespace := args.String("namespace")
+		name := args.String("name")
+		timeout := time.Duration(args.Int("timeout_seconds")) * time.Second
+
+		wait := waitFunc(serverCtx.client(ctx), kind, namespace, name)
+		target := fmt.Sprintf("%s %s/%s", kind, namespace, name)
+
+		if args.Bool(asyncArgument) {
+			job, err := serverCtx.startJob(ctx, "wait", namespace, target, func(ctx context.Context, logf func(format string, args ...any)) (any, error) {
+				var last capi.WaitProgress
+				err := wait(ctx, capi.WaitOptions{Timeout: timeout, OnProgress: func(p capi.WaitProgress) {
+					logf("%s %s/%s: %s", p.Kind, p.Namespace, p.Name, p.Message)
+					last = p
+				}})
+				if err != nil {
+					return nil, err
+				}
+				return last, nil
+			})
+			if err != nil {
+				return toolError(err)
+			}
+			message := fmt.Sprintf("Waiting for %s in job %s. Check it with capi_job_status or capi_job_logs.", target, job.ID)
+			return newToolResult(message, map[string]any{"jobId": job.ID})
+		}
+
+		var progress []capi.WaitProgress
+		notify := progressNotifier(ctx, request)
+		err = wait(ctx, capi.WaitOptions{Timeout: timeout, OnProgress: func(p capi.WaitProgress) {
+			progress = append(progress, p)
+			notify(len(progress), fmt.Sprintf("%s %s/%s: %s", p.Kind, p.Namespace, p.Name, p.Message))
+		}})
+
+		if err != nil {
+			// The error describes the last state of the resource
+			return toolError(err)
+		}
+
+		var content strings.Builder
+		for _, p := range progress {
+			content.WriteString(fmt.Sprintf("[%s] %s\n", p.Elapsed.Round(time.Second), p.Message))
+		}
+		header := fmt.Sprintf("✅ %s is ready\n\nProgress:\n", target)
+		return newToolResult(header+content.String(), map[string]any{"ready": true, "progress": progress})
+	}
+}
+
+// waitFunc returns the client method waiting for a kind of resource
+func waitFunc(c capi.CAPIClient, kind, namespace, name string) func(context.Context, capi.WaitOptions) error {
+	return func(ctx context.Context, opts capi.WaitOptions) error {
+		switch kind {
+		case "control_plane":
+			return c.WaitForControlPlaneReady(ctx, namespace, name, opts)
+		case "machinedeployment":
+			return c.WaitForMachineDeploymentReady(ctx, namespace, name, opts)
+		default:
+			return c.WaitForClusterReady(ctx, namespace, name, opts)
+		}
+	}
+}
+
+// progressNotifier returns a function sending MCP progress notifications for
+// a tool call. Notifications are only sent when the client asked for them by
+// passing a progress token.
+func progressNotifier(ctx context.Context, request mcp.CallToolRequest) func(progress int, message string) {
+	srv := server.ServerFromContext(ctx)
+	if srv == nil || request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return func(int, string) {}
+	}
+	token := request.Params.Meta.ProgressToken
+	return func(progress int, message string) {
+		// Progress is best effort; a client that went away does not fail the call
+		_ = srv.SendNotificationToClient(ctx, methodProgress, map[string]any{
+			"progressToken": token,
+			"progress":      progress,
+			"message":       message,
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AddonCategory is the role of a core addon of a workload cluster
+type AddonCategory string
+
+// Categories of core addons
+const (
+	AddonCNI                    AddonCategory = "cni"
+	AddonCloudControllerManager AddonCategory = "cloud-controller-manager"
+	AddonCSI                    AddonCategory = "csi"
+	AddonCoreDNS                AddonCategory = "coredns"
+	AddonKubeProxy              AddonCategory = "kube-proxy"
+)
+
+// uninitializedTaint is set on nodes until a cloud controller manager
+// initializes them
+const uninitializedTaint = "node.cloudprovider.kubernetes.io/uninitialized"
+
+// AddonsReport describes the core addons of a workload cluster
+type AddonsReport struct {
+	Healthy bool          `json:"healthy"`
+	Addons  []AddonStatus `json:"addons"`
+	// Missing lists the categories a working cluster needs but no workload
+	// provides
+	Missing            []AddonCategory `json:"missing,omitempty"`
+	Nodes              int             `json:"nodes"`
+	NotReadyNodes      int             `json:"notReadyNodes"`
+	UninitializedNodes int             `json:"uninitializedNodes"`
+	Hints              []string        `json:"hints,omitempty"`
+}
+
+// AddonStatus is the health of the DaemonSet or Deployment of an addon
+type AddonStatus struct {
+	Category  AddonCategory `json:"category"`
+	Addon     string        `json:"addon"`
+	Kind      string        `json:"kind"`
+	Namespace string        `json:"namespace"`
+	Name      string        `json:"name"`
+	Desired   int32         `json:"desired"`
+	Ready     int32         `json:"ready"`
+	Healthy   bool          `json:"healthy"`
+	Message   string        `json:"message,omitempty"`
+}
+
+// classifyAddon recognizes the workloads of core addons by name, returning an
+// empty category for other workloads
+func classifyAddon(name string) (AddonCategory, string) {
+	switch {
+	case strings.Contains(name, "calico"):
+		return AddonCNI, "calico"
+	case name == "cilium" || name == "cilium-operator":
+		return AddonCNI, "cilium"
+	case strings.HasPrefix(name, "kube-flannel"):
+		return AddonCNI, "flannel"
+	case strings.Contains(name, "cloud-controller-manager"):
+		return AddonCloudControllerManager, name
+	case strings.Contains(name, "csi"):
+		return AddonCSI, name
+	case name == "coredns":
+		return AddonCoreDNS, name
+	case name == "kube-proxy":
+		return AddonKubeProxy, name
+	}
+	return "", ""
+}
+
+// GetAddonsStatus inspects the DaemonSets and Deployments of the CNI, cloud
+// controller manager, CSI drivers, CoreDNS and kube-proxy of a workload
+// cluster, with hints for the usual reasons of nodes staying NotReady
+func (c *Client) GetAddonsStatus(ctx context.Context, namespace, clusterName string) (*AddonsReport, error) {
+	kubeconfig, err := c.GetKubeconfig(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	clientset, err := c.workloadClientset(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	daemonSets, err := clientset.AppsV1().DaemonSets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemon sets: %w", workloadError("DaemonSet", client.ObjectKey{}, err))
+	}
+	deployments, err := clientset.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", workloadError("Deployment", client.ObjectKey{}, err))
+	}
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", workloadError("Node", client.ObjectKey{}, err))
+	}
+
+	report := &AddonsReport{Healthy: true, Addons: []AddonStatus{}, Nodes: len(nodes.Items)}
+	for _, ds := range daemonSets.Items {
+		if category, addon := classifyAddon(ds.Name); category != "" {
+			report.Addons = append(report.Addons, daemonSetAddon(category, addon, &ds))
+		}
+	}
+	for _, deployment := range deployments.Items {
+		if category, addon := classifyAddon(deployment.Name); category != "" {
+			report.Addons = append(report.Addons, deploymentAddon(category, addon, &deployment))
+		}
+	}
+	sort.Slice(report.Addons, func(i, j int) bool {
+		if report.Addons[i].Category != report.Addons[j].Category {
+			return report.Addons[i].Category < report.Addons[j].Category
+		}
+		return report.Addons[i].Name < report.Addons[j].Name
+	})
+
+	for _, node := range nodes.Items {
+		if !nodeReady(&node) {
+			report.NotReadyNodes++
+		}
+		for _, taint := range node.Spec.Taints {
+			if taint.Key == uninitializedTaint {
+				report.UninitializedNodes++
+				break
+			}
+		}
+	}
+
+	found := map[AddonCategory]string{}
+	for _, addon := range report.Addons {
+		found[addon.Category] = addon.Addon
+		if !addon.Healthy {
+			report.Healthy = false
+		}
+	}
+	if _, ok := found[AddonCNI]; !ok {
+		report.Missing = append(report.Missing, AddonCNI)
+		report.Hints = append(report.Hints, "No CNI is installed: nodes stay NotReady until one is, e.g. with a ClusterResourceSet or HelmChartProxy")
+	}
+	if _, ok := found[AddonCoreDNS]; !ok {
+		report.Missing = append(report.Missing, AddonCoreDNS)
+		report.Hints = append(report.Hints, "CoreDNS is not installed: in-cluster DNS does not resolve")
+	}
+	// Cilium can replace kube-proxy
+	if _, ok := found[AddonKubeProxy]; !ok && found[AddonCNI] != "cilium" {
+		report.Missing = append(report.Missing, AddonKubeProxy)
+		report.Hints = append(report.Hints, "kube-proxy is not installed and no CNI replaces it: Services are not reachable")
+	}
+	if _, ok := found[AddonCloudControllerManager]; !ok && report.UninitializedNodes > 0 {
+		report.Missing = append(report.Missing, AddonCloudControllerManager)
+		report.Hints = append(report.Hints, fmt.Sprintf("%d nodes wait for a cloud controller manager to initialize them, but none is installed", report.UninitializedNodes))
+	} else if report.UninitializedNodes > 0 {
+		report.Hints = append(report.Hints, fmt.Sprintf("%d nodes are not initialized by the cloud controller manager yet", report.UninitializedNodes))
+	}
+	if len(report.Missing) > 0 {
+		report.Healthy = false
+	}
+	return report, nil
+}
+
+// daemonSetAddon describes the health of an addon run by a DaemonSet
+func daemonSetAddon(category AddonCategory, addon string, ds *appsv1.DaemonSet) AddonStatus {
+	status := AddonStatus{
+		Category:  category,
+		Addon:     addon,
+		Kind:      "DaemonSet",
+		Namespace: ds.Namespace,
+		Name:      ds.Name,
+		Desired:   ds.Status.DesiredNumberScheduled,
+		Ready:     ds.Status.NumberReady,
+	}
+	switch {
+	case status.Desired == 0:
+		status.Message = "no pods are scheduled"
+	case status.Ready < status.Desired:
+		status.Message = fmt.Sprintf("%d of %d pods are not ready", status.Desired-status.Ready, status.Desired)
+	default:
+		status.Healthy = true
+	}
+	return status
+}
+
+// deploymentAddon describes the health of an addon run by a Deployment
+func deploymentAddon(category AddonCategory, addon string, deployment *appsv1.Deployment) AddonStatus {
+	status := AddonStatus{
+		Category:  category,
+		Addon:     addon,
+		Kind:      "Deployment",
+		Namespace: deployment.Namespace,
+		Name:      deployment.Name,
+		Desired:   1,
+		Ready:     deployment.Status.ReadyReplicas,
+	}
+	if deployment.Spec.Replicas != nil {
+		status.Desired = *deployment.Spec.Replicas
+	}
+	switch {
+	case status.Desired == 0:
+		status.Message = "scaled to zero"
+	case status.Ready < status.Desired:
+		status.Message = fmt.Sprintf("%d of %d replicas are not ready", status.Desired-status.Ready, status.Desired)
+		for _, condition := range deployment.Status.Conditions {
+			if condition.Type == appsv1.DeploymentAvailable && condition.Status != corev1.ConditionTrue && condition.Message != "" {
+				status.Message += ": " + condition.Message
+			}
+		}
+	default:
+		status.Healthy = true
+	}
+	return status
+}
@@ -0,0 +1,90 @@
+package capi
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestGetAddonsStatus(t *testing.T) {
+	daemonSet := func(namespace, name string, desired, ready int32) *appsv1.DaemonSet {
+		return &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: desired, NumberReady: ready},
+		}
+	}
+	deployment := func(namespace, name string, replicas, ready int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(replicas)},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: ready},
+		}
+	}
+	node := func(name string, ready corev1.ConditionStatus, taints ...corev1.Taint) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{Taints: taints},
+			Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}}},
+		}
+	}
+	newClient := func(workload ...runtime.Object) *Client {
+		return &Client{
+			k8sClient: k8sfake.NewClientset(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-kubeconfig"},
+				Data:       map[string][]byte{"value": []byte("kubeconfig")},
+			}),
+			newWorkloadClientset: func(kubeconfig string) (kubernetes.Interface, error) {
+				return k8sfake.NewClientset(workload...), nil
+			},
+		}
+	}
+
+	report, err := newClient(
+		daemonSet("kube-system", "cilium", 3, 3),
+		deployment("kube-system", "cilium-operator", 2, 2),
+		deployment("kube-system", "coredns", 2, 1),
+		daemonSet("kube-system", "aws-cloud-controller-manager", 1, 1),
+		daemonSet("kube-system", "ebs-csi-node", 3, 3),
+		deployment("default", "web", 3, 0),
+		node("node-1", corev1.ConditionTrue),
+	).GetAddonsStatus(context.Background(), "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Healthy || len(report.Addons) != 5 || len(report.Missing) != 0 {
+		t.Errorf("report = %+v, want 5 addons, coredns degraded and kube-proxy replaced by cilium", report)
+	}
+	for _, addon := range report.Addons {
+		if addon.Healthy != (addon.Name != "coredns") {
+			t.Errorf("addon %s healthy = %v: %s", addon.Name, addon.Healthy, addon.Message)
+		}
+	}
+
+	uninitialized := corev1.Taint{Key: uninitializedTaint, Effect: corev1.TaintEffectNoSchedule}
+	report, err = newClient(
+		daemonSet("kube-system", "kube-proxy", 2, 2),
+		deployment("kube-system", "coredns", 2, 0),
+		node("node-1", corev1.ConditionFalse, uninitialized),
+		node("node-2", corev1.ConditionFalse, uninitialized),
+	).GetAddonsStatus(context.Background(), "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Healthy || report.NotReadyNodes != 2 || report.UninitializedNodes != 2 {
+		t.Errorf("report = %+v, want 2 uninitialized NotReady nodes", report)
+	}
+	if len(report.Missing) != 2 || report.Missing[0] != AddonCNI || report.Missing[1] != AddonCloudControllerManager || len(report.Hints) != 2 {
+		t.Errorf("missing = %v, hints = %v, want the CNI and cloud controller manager", report.Missing, report.Hints)
+	}
+
+	if _, err := newClient().GetAddonsStatus(context.Background(), "org-acme", "missing"); err == nil {
+		t.Error("GetAddonsStatus() succeeded without a kubeconfig")
+	}
+}
@@ -0,0 +1,353 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// azureManagedControlPlaneGVK is the AKS control plane of the Azure provider
+	azureManagedControlPlaneGVK = schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "AzureManagedControlPlane"}
+	// azureManagedMachinePoolGVK is an AKS node pool backing a MachinePool
+	azureManagedMachinePoolGVK = schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "AzureManagedMachinePool"}
+)
+
+// AKSNodePoolScaling is the range the cluster autoscaler of AKS keeps a node
+// pool in
+type AKSNodePoolScaling struct {
+	MinSize int64 `json:"minSize"`
+	MaxSize int64 `json:"maxSize"`
+}
+
+// AKSNodePool is a MachinePool backed by an AzureManagedMachinePool
+type AKSNodePool struct {
+	MachinePool string `json:"machinePool"`
+	// Name is the AzureManagedMachinePool, AgentPool the name of the node
+	// pool in AKS
+	Name              string              `json:"name"`
+	AgentPool         string              `json:"agentPool,omitempty"`
+	Mode              string              `json:"mode,omitempty"`
+	SKU               string              `json:"sku,omitempty"`
+	Version           string              `json:"version,omitempty"`
+	Replicas          int64               `json:"replicas"`
+	ReadyReplicas     int64               `json:"readyReplicas"`
+	Scaling           *AKSNodePoolScaling `json:"scaling,omitempty"`
+	OSDiskSizeGB      int64               `json:"osDiskSizeGB,omitempty"`
+	AvailabilityZones []string            `json:"availabilityZones,omitempty"`
+	Ready             bool                `json:"ready"`
+	ProvisioningState string              `json:"provisioningState,omitempty"`
+}
+
+// AKSClusterInfo is an AKS cluster, read from its AzureManagedControlPlane and
+// the AzureManagedMachinePools of its MachinePools
+type AKSClusterInfo struct {
+	Namespace     string `json:"namespace"`
+	Name          string `json:"name"`
+	ControlPlane  string `json:"controlPlane"`
+	Location      string `json:"location,omitempty"`
+	ResourceGroup string `json:"resourceGroup,omitempty"`
+	// NodeResourceGroup holds the virtual machine scale sets of the node pools
+	NodeResourceGroup string        `json:"nodeResourceGroup,omitempty"`
+	Version           string        `json:"version,omitempty"`
+	SKUTier           string        `json:"skuTier,omitempty"`
+	NetworkPlugin     string        `json:"networkPlugin,omitempty"`
+	NetworkPolicy     string        `json:"networkPolicy,omitempty"`
+	Endpoint          string        `json:"endpoint,omitempty"`
+	Ready             bool          `json:"ready"`
+	NodePools         []AKSNodePool `json:"nodePools"`
+}
+
+// isAKSCluster reports whether the control plane of a cluster is managed by AKS
+func isAKSCluster(cluster *clusterv1.Cluster) bool {
+	ref := cluster.Spec.ControlPlaneRef
+	return ref != nil && ref.Kind == azureManagedControlPlaneGVK.Kind
+}
+
+// ListAKSClusters lists the AKS clusters in a namespace, or in all namespaces
+// if namespace is empty. Control planes and node pools are listed once for
+// the namespace and joined to the clusters in memory.
+func (c *Client) ListAKSClusters(ctx context.Context, namespace string) ([]AKSClusterInfo, error) {
+	clusters, err := c.ListClusters(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	var aksClusters []*clusterv1.Cluster
+	for i := range clusters.Items {
+		if isAKSCluster(&clusters.Items[i]) {
+			aksClusters = append(aksClusters, &clusters.Items[i])
+		}
+	}
+	if len(aksClusters) == 0 {
+		return []AKSClusterInfo{}, nil
+	}
+
+	controlPlanes, err := c.listUnstructured(ctx, azureManagedControlPlaneGVK, client.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*unstructured.Unstructured, len(controlPlanes))
+	for i := range controlPlanes {
+		byName[controlPlanes[i].GetNamespace()+"/"+controlPlanes[i].GetName()] = &controlPlanes[i]
+	}
+	pools, err := c.listAKSNodePools(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]AKSClusterInfo, 0, len(aksClusters))
+	for _, cluster := range aksClusters {
+		info := AKSClusterInfo{Namespace: cluster.Namespace, Name: cluster.Name, ControlPlane: cluster.Spec.ControlPlaneRef.Name}
+		if controlPlane := byName[cluster.Namespace+"/"+info.ControlPlane]; controlPlane != nil {
+			readAKSControlPlane(&info, controlPlane.Object)
+		}
+		info.NodePools = pools[cluster.Namespace+"/"+cluster.Name]
+		if info.NodePools == nil {
+			info.NodePools = []AKSNodePool{}
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// GetAKSCluster reads an AKS cluster with its node pools
+func (c *Client) GetAKSCluster(ctx context.Context, namespace, name string) (*AKSClusterInfo, error) {
+	cluster, err := c.GetCluster(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if !isAKSCluster(cluster) {
+		return nil, errorf(ErrInvalidArgument, "cluster %s/%s is not an AKS cluster", namespace, name)
+	}
+
+	ref := cluster.Spec.ControlPlaneRef
+	controlPlane, err := c.getReferenced(ctx, namespace, ref.APIVersion, ref.Kind, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+	info := &AKSClusterInfo{Namespace: namespace, Name: name, ControlPlane: ref.Name}
+	readAKSControlPlane(info, controlPlane.Object)
+
+	pools, err := c.listAKSNodePools(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	info.NodePools = pools[namespace+"/"+name]
+	if info.NodePools == nil {
+		info.NodePools = []AKSNodePool{}
+	}
+	return info, nil
+}
+
+// readAKSControlPlane fills info from an AzureManagedControlPlane
+func readAKSControlPlane(info *AKSClusterInfo, obj map[string]any) {
+	info.Location, _, _ = unstructured.NestedString(obj, "spec", "location")
+	info.ResourceGroup, _, _ = unstructured.NestedString(obj, "spec", "resourceGroupName")
+	info.NodeResourceGroup, _, _ = unstructured.NestedString(obj, "spec", "nodeResourceGroupName")
+	info.Version, _, _ = unstructured.NestedString(obj, "spec", "version")
+	info.SKUTier, _, _ = unstructured.NestedString(obj, "spec", "sku", "tier")
+	info.NetworkPlugin, _, _ = unstructured.NestedString(obj, "spec", "networkPlugin")
+	info.NetworkPolicy, _, _ = unstructured.NestedString(obj, "spec", "networkPolicy")
+	info.Ready, _, _ = unstructured.NestedBool(obj, "status", "ready")
+	if host, _, _ := unstructured.NestedString(obj, "spec", "controlPlaneEndpoint", "host"); host != "" {
+		info.Endpoint = host
+		if port, found, _ := unstructured.NestedInt64(obj, "spec", "controlPlaneEndpoint", "port"); found {
+			info.Endpoint = fmt.Sprintf("%s:%d", host, port)
+		}
+	}
+}
+
+// listAKSNodePools lists the MachinePools backed by AzureManagedMachinePools,
+// keyed by the namespace and name of their cluster and sorted by name
+func (c *Client) listAKSNodePools(ctx context.Context, namespace string) (map[string][]AKSNodePool, error) {
+	machinePools, err := c.listUnstructured(ctx, machinePoolGVK, client.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	managedPools, err := c.listUnstructured(ctx, azureManagedMachinePoolGVK, client.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*unstructured.Unstructured, len(managedPools))
+	for i := range managedPools {
+		byName[managedPools[i].GetNamespace()+"/"+managedPools[i].GetName()] = &managedPools[i]
+	}
+
+	pools := make(map[string][]AKSNodePool)
+	for i := range machinePools {
+		machinePool := &machinePools[i]
+		ref, _, _ := unstructured.NestedMap(machinePool.Object, "spec", "template", "spec", "infrastructureRef")
+		if ref["kind"] != azureManagedMachinePoolGVK.Kind {
+			continue
+		}
+		pool := AKSNodePool{MachinePool: machinePool.GetName()}
+		pool.Name, _ = ref["name"].(string)
+		pool.Version, _, _ = unstructured.NestedString(machinePool.Object, "spec", "template", "spec", "version")
+		pool.Replicas, _, _ = unstructured.NestedInt64(machinePool.Object, "spec", "replicas")
+		pool.ReadyReplicas, _, _ = unstructured.NestedInt64(machinePool.Object, "status", "readyReplicas")
+		if managed := byName[machinePool.GetNamespace()+"/"+pool.Name]; managed != nil {
+			readAKSNodePool(&pool, managed.Object)
+		}
+
+		clusterName, _, _ := unstructured.NestedString(machinePool.Object, "spec", "clusterName")
+		key := machinePool.GetNamespace() + "/" + clusterName
+		pools[key] = append(pools[key], pool)
+	}
+	for _, clusterPools := range pools {
+		sort.Slice(clusterPools, func(i, j int) bool { return clusterPools[i].MachinePool < clusterPools[j].MachinePool })
+	}
+	return pools, nil
+}
+
+// readAKSNodePool fills pool from an AzureManagedMachinePool
+func readAKSNodePool(pool *AKSNodePool, obj map[string]any) {
+	pool.AgentPool, _, _ = unstructured.NestedString(obj, "spec", "name")
+	pool.Mode, _, _ = unstructured.NestedString(obj, "spec", "mode")
+	pool.SKU, _, _ = unstructured.NestedString(obj, "spec", "sku")
+	pool.OSDiskSizeGB, _, _ = unstructured.NestedInt64(obj, "spec", "osDiskSizeGB")
+	pool.AvailabilityZones, _, _ = unstructured.NestedStringSlice(obj, "spec", "availabilityZones")
+	pool.Ready, _, _ = unstructured.NestedBool(obj, "status", "ready")
+	pool.ProvisioningState, _, _ = unstructured.NestedString(obj, "status", "provisioningState")
+	if scaling, found, _ := unstructured.NestedMap(obj, "spec", "scaling"); found {
+		pool.Scaling = &AKSNodePoolScaling{}
+		pool.Scaling.MinSize, _, _ = unstructured.NestedInt64(scaling, "minSize")
+		pool.Scaling.MaxSize, _, _ = unstructured.NestedInt64(scaling, "maxSize")
+	}
+}
+
+// upgradeAKSCluster upgrades an AKS cluster by setting the version of its
+// AzureManagedControlPlane and, if requested, of the MachinePools of its node
+// pools. AKS upgrades the control plane and node pools in place.
+func (c *Client) upgradeAKSCluster(ctx context.Context, cluster *clusterv1.Cluster, opts UpgradeClusterOptions) error {
+	if err := c.RequirePermissions(ctx, aksUpgradePermissionChecks(opts.Namespace, opts.UpgradeWorkers)...); err != nil {
+		return err
+	}
+
+	ref := cluster.Spec.ControlPlaneRef
+	controlPlane, err := c.getReferenced(ctx, opts.Namespace, ref.APIVersion, ref.Kind, ref.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get control plane: %w", err)
+	}
+	machinePools, err := c.listUnstructured(ctx, machinePoolGVK, client.InNamespace(opts.Namespace))
+	if err != nil {
+		return err
+	}
+	var pools []*unstructured.Unstructured
+	for i := range machinePools {
+		if clusterName, _, _ := unstructured.NestedString(machinePools[i].Object, "spec", "clusterName"); clusterName == cluster.Name {
+			pools = append(pools, &machinePools[i])
+		}
+	}
+
+	if !opts.Force {
+		current, _, _ := unstructured.NestedString(controlPlane.Object, "spec", "version")
+		if violations, err := aksSkewViolations(current, pools, opts.TargetVersion, opts.UpgradeWorkers); err != nil {
+			return err
+		} else if len(violations) > 0 {
+			return skewError(violations)
+		}
+	}
+
+	err = c.updateObject(ctx, client.ObjectKeyFromObject(controlPlane), controlPlane, "", func() error {
+		return unstructured.SetNestedField(controlPlane.Object, opts.TargetVersion, "spec", "version")
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update control plane version: %w", err)
+	}
+
+	if !opts.UpgradeWorkers {
+		return nil
+	}
+	for _, pool := range pools {
+		if _, found, _ := unstructured.NestedString(pool.Object, "spec", "template", "spec", "version"); !found {
+			continue
+		}
+		err := c.updateObject(ctx, client.ObjectKeyFromObject(pool), pool, "", func() error {
+			return unstructured.SetNestedField(pool.Object, opts.TargetVersion, "spec", "template", "spec", "version")
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update machine pool %s: %w", pool.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// aksSkewViolations checks an AKS upgrade against the version skew policies,
+// as upgradeSkewViolations does for KubeadmControlPlanes and MachineDeployments
+func aksSkewViolations(current string, pools []*unstructured.Unstructured, targetVersion string, upgradeWorkers bool) ([]string, error) {
+	target, err := version.ParseSemantic(targetVersion)
+	if err != nil {
+		return nil, errorf(ErrInvalidArgument, "invalid target version %q: %v", targetVersion, err)
+	}
+
+	var violations []string
+	if err := CheckVersionSkew(current, target); err != nil {
+		violations = append(violations, fmt.Sprintf("control plane: %v", err))
+	}
+	for _, pool := range pools {
+		poolVersion, found, _ := unstructured.NestedString(pool.Object, "spec", "template", "spec", "version")
+		if !found {
+			continue
+		}
+		current, err := version.ParseSemantic(poolVersion)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("MachinePool %s: version %q is not a semantic version", pool.GetName(), poolVersion))
+			continue
+		}
+		if upgradeWorkers {
+			if current.GreaterThan(target) {
+				violations = append(violations, fmt.Sprintf("MachinePool %s: cannot downgrade from %s to %s", pool.GetName(), current, target))
+			}
+			continue
+		}
+		if err := checkWorkerSkew(current, target); err != nil {
+			violations = append(violations, fmt.Sprintf("MachinePool %s: %v", pool.GetName(), err))
+		}
+	}
+	return violations, nil
+}
+
+// ScaleMachinePool scales a MachinePool to the specified number of replicas.
+// AKS node pools that are autoscaled are refused, as the autoscaler would
+// undo the change, and System node pools keep at least one node.
+func (c *Client) ScaleMachinePool(ctx context.Context, namespace, name string, replicas int32) error {
+	pool := &unstructured.Unstructured{}
+	pool.SetGroupVersionKind(machinePoolGVK)
+	key := client.ObjectKey{Namespace: namespace, Name: name}
+	if err := c.ctrlClient.Get(ctx, key, pool); err != nil {
+		return fmt.Errorf("failed to get machine pool: %w", resourceError("MachinePool", key, err))
+	}
+
+	ref, _, _ := unstructured.NestedMap(pool.Object, "spec", "template", "spec", "infrastructureRef")
+	if ref["kind"] == azureManagedMachinePoolGVK.Kind {
+		apiVersion, _ := ref["apiVersion"].(string)
+		refName, _ := ref["name"].(string)
+		managed, err := c.getReferenced(ctx, namespace, apiVersion, azureManagedMachinePoolGVK.Kind, refName)
+		if err != nil {
+			return err
+		}
+		nodePool := AKSNodePool{}
+		readAKSNodePool(&nodePool, managed.Object)
+		if nodePool.Scaling != nil {
+			return errorf(ErrPreconditionFailed, "node pool %s is autoscaled between %d and %d nodes; change its scaling instead",
+				name, nodePool.Scaling.MinSize, nodePool.Scaling.MaxSize)
+		}
+		if nodePool.Mode == "System" && replicas < 1 {
+			return errorf(ErrPreconditionFailed, "node pool %s is a System node pool and needs at least one node", name)
+		}
+	}
+
+	err := c.updateObject(ctx, key, pool, "", func() error {
+		return unstructured.SetNestedField(pool.Object, int64(replicas), "spec", "replicas")
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scale machine pool: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAKSClusters(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: "aks"},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneRef:   &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "AzureManagedControlPlane", Name: "aks-cp"},
+			InfrastructureRef: &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "AzureManagedCluster", Name: "aks"},
+		},
+	}
+	controlPlane := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"version":           "v1.29.4",
+			"location":          "westeurope",
+			"resourceGroupName": "aks-rg",
+			"sku":               map[string]any{"tier": "Standard"},
+			"networkPlugin":     "azure",
+		},
+		"status": map[string]any{"ready": true},
+	}}
+	controlPlane.SetGroupVersionKind(azureManagedControlPlaneGVK)
+	controlPlane.SetNamespace("org-a")
+	controlPlane.SetName("aks-cp")
+
+	newPool := func(name, mode string, replicas int64, scaling map[string]any) []client.Object {
+		machinePool := &unstructured.Unstructured{Object: map[string]any{
+			"spec": map[string]any{
+				"clusterName": "aks",
+				"replicas":    replicas,
+				"template": map[string]any{"spec": map[string]any{
+					"clusterName": "aks",
+					"version":     "v1.29.4",
+					"infrastructureRef": map[string]any{
+						"apiVersion": "infrastructure.cluster.x-k8s.io/v1beta1", "kind": "AzureManagedMachinePool", "name": name,
+					},
+				}},
+			},
+		}}
+		machinePool.SetGroupVersionKind(machinePoolGVK)
+		machinePool.SetNamespace("org-a")
+		machinePool.SetName(name)
+		spec := map[string]any{"mode": mode, "sku": "Standard_D4s_v5", "availabilityZones": []any{"1", "2"}}
+		if scaling != nil {
+			spec["scaling"] = scaling
+		}
+		managed := &unstructured.Unstructured{Object: map[string]any{"spec": spec, "status": map[string]any{"ready": true, "provisioningState": "Succeeded"}}}
+		managed.SetGroupVersionKind(azureManagedMachinePoolGVK)
+		managed.SetNamespace("org-a")
+		managed.SetName(name)
+		return []client.Object{machinePool, managed}
+	}
+	objects := []client.Object{cluster, controlPlane}
+	objects = append(objects, newPool("aks-user", "User", 3, map[string]any{"minSize": int64(1), "maxSize": int64(5)})...)
+	objects = append(objects, newPool("aks-system", "System", 1, nil)...)
+
+	c := newAccessReviewClient("get", "list", "patch")
+	c.ctrlClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	ctx := context.Background()
+
+	aks, err := c.GetAKSCluster(ctx, "org-a", "aks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aks.Version != "v1.29.4" || aks.Location != "westeurope" || aks.SKUTier != "Standard" || !aks.Ready {
+		t.Errorf("AKS cluster = %+v", aks)
+	}
+	if len(aks.NodePools) != 2 || aks.NodePools[0].MachinePool != "aks-system" || aks.NodePools[1].Scaling == nil || aks.NodePools[1].Scaling.MaxSize != 5 {
+		t.Errorf("node pools = %+v", aks.NodePools)
+	}
+	clusters, err := c.ListAKSClusters(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 || clusters[0].ResourceGroup != "aks-rg" || len(clusters[0].NodePools) != 2 {
+		t.Errorf("AKS clusters = %+v", clusters)
+	}
+	if status, err := c.GetClusterStatus(ctx, "org-a", "aks"); err != nil || status.Version != "v1.29.4" {
+		t.Errorf("GetClusterStatus() = %+v, %v, want the AKS version", status, err)
+	}
+
+	if err := c.UpgradeCluster(ctx, UpgradeClusterOptions{Namespace: "org-a", Name: "aks", TargetVersion: "v1.31.0", UpgradeWorkers: true}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("UpgradeCluster() skipping a minor version error = %v, want ErrPreconditionFailed", err)
+	}
+	if err := c.UpgradeCluster(ctx, UpgradeClusterOptions{Namespace: "org-a", Name: "aks", TargetVersion: "v1.30.1", UpgradeWorkers: true}); err != nil {
+		t.Fatal(err)
+	}
+	upgraded, err := c.GetAKSCluster(ctx, "org-a", "aks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upgraded.Version != "v1.30.1" || upgraded.NodePools[0].Version != "v1.30.1" || upgraded.NodePools[1].Version != "v1.30.1" {
+		t.Errorf("upgraded cluster = %+v", upgraded)
+	}
+
+	if err := c.ScaleCluster(ctx, "org-a", "aks", "workers", 2, "", "aks-system"); err != nil {
+		t.Fatal(err)
+	}
+	scaled, _ := c.GetAKSCluster(ctx, "org-a", "aks")
+	if scaled.NodePools[0].Replicas != 2 {
+		t.Errorf("system pool replicas = %d, want 2", scaled.NodePools[0].Replicas)
+	}
+	refused := []struct {
+		name, target, machinePool string
+		replicas                  int
+	}{
+		{"autoscaled pool", "workers", "aks-user", 4},
+		{"empty system pool", "workers", "aks-system", 0},
+		{"managed control plane", "controlplane", "", 3},
+	}
+	for _, tt := range refused {
+		if err := c.ScaleCluster(ctx, "org-a", "aks", tt.target, tt.replicas, "", tt.machinePool); !errors.Is(err, ErrPreconditionFailed) {
+			t.Errorf("ScaleCluster() of %s error = %v, want ErrPreconditionFailed", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package capi
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// apiServerProbeTimeout bounds each step of an API server probe
+const apiServerProbeTimeout = 5 * time.Second
+
+// apiServerProbeConcurrency is how many API servers are probed at a time
+const apiServerProbeConcurrency = 10
+
+// APIServerProbe is the outcome of probing the API server of a cluster
+type APIServerProbe struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Endpoint  string `json:"endpoint,omitempty"`
+	// InfrastructureReady is the readiness of the infrastructure in the
+	// status of the Cluster, to tell unreachable API servers of provisioned
+	// clusters from those still being created
+	InfrastructureReady bool          `json:"infrastructureReady"`
+	Reachable           bool          `json:"reachable"`
+	DialLatency         time.Duration `json:"dialLatency,omitempty"`
+	TLS                 *TLSProbe     `json:"tls,omitempty"`
+	// Healthy is set when /healthz answers ok with the workload kubeconfig
+	Healthy        bool          `json:"healthy"`
+	Healthz        string        `json:"healthz,omitempty"`
+	HealthzLatency time.Duration `json:"healthzLatency,omitempty"`
+	Errors         []string      `json:"errors,omitempty"`
+}
+
+// TLSProbe describes the serving certificate of an API server
+type TLSProbe struct {
+	// Valid is set when the certificate chains to the cluster CA of the
+	// kubeconfig, matches the endpoint and has not expired
+	Valid            bool          `json:"valid"`
+	Error            string        `json:"error,omitempty"`
+	Subject          string        `json:"subject,omitempty"`
+	DNSNames         []string      `json:"dnsNames,omitempty"`
+	IPAddresses      []string      `json:"ipAddresses,omitempty"`
+	Expires          time.Time     `json:"expires,omitempty"`
+	HandshakeLatency time.Duration `json:"handshakeLatency,omitempty"`
+}
+
+// ProbeAPIServer dials the control plane endpoint of a cluster, checks its
+// serving certificate against the cluster CA and calls /healthz with the
+// workload kubeconfig. Failures of the probe are reported in the result, not
+// as errors.
+func (c *Client) ProbeAPIServer(ctx context.Context, namespace, name string) (*APIServerProbe, error) {
+	cluster, err := c.GetCluster(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return c.probeAPIServer(ctx, cluster), nil
+}
+
+// ProbeAPIServers probes the API servers of the clusters of a namespace, or
+// of all namespaces if namespace is empty, sorted by namespace and name
+func (c *Client) ProbeAPIServers(ctx context.Context, namespace string) ([]*APIServerProbe, error) {
+	clusters, err := c.ListClusters(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	probes := make([]*APIServerProbe, len(clusters.Items))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, apiServerProbeConcurrency)
+	for i := range clusters.Items {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			probes[i] = c.probeAPIServer(ctx, &clusters.Items[i])
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Slice(probes, func(i, j int) bool {
+		if probes[i].Namespace != probes[j].Namespace {
+			return probes[i].Namespace < probes[j].Namespace
+		}
+		return probes[i].Name < probes[j].Name
+	})
+	return probes, nil
+}
+
+// probeAPIServer probes the API server of a cluster
+func (c *Client) probeAPIServer(ctx context.Context, cluster *clusterv1.Cluster) *APIServerProbe {
+	probe := &APIServerProbe{
+		Namespace:           cluster.Namespace,
+		Name:                cluster.Name,
+		InfrastructureReady: cluster.Status.InfrastructureReady,
+	}
+	endpoint := cluster.Spec.ControlPlaneEndpoint
+	if !endpoint.IsValid() {
+		probe.Errors = append(probe.Errors, "the cluster has no control plane endpoint yet")
+		return probe
+	}
+	probe.Endpoint = net.JoinHostPort(endpoint.Host, strconv.Itoa(int(endpoint.Port)))
+
+	// The kubeconfig holds the CA to verify the endpoint with
+	kubeconfig, err := c.GetKubeconfig(ctx, cluster.Namespace, cluster.Name)
+	if err != nil {
+		probe.Errors = append(probe.Errors, fmt.Sprintf("no kubeconfig: %v", err))
+	}
+
+	dialer := &net.Dialer{Timeout: apiServerProbeTimeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", probe.Endpoint)
+	if err != nil {
+		probe.Errors = append(probe.Errors, fmt.Sprintf("dial %s: %v", probe.Endpoint, err))
+		return probe
+	}
+	probe.Reachable = true
+	probe.DialLatency = time.Since(start)
+	probe.TLS = probeTLS(ctx, conn, endpoint.Host, kubeconfig)
+
+	if kubeconfig == "" {
+		return probe
+	}
+	start = time.Now()
+	body, err := c.getHealthz(ctx, kubeconfig)
+	probe.HealthzLatency = time.Since(start)
+	probe.Healthz = body
+	if err != nil {
+		probe.Errors = append(probe.Errors, fmt.Sprintf("healthz: %v", err))
+	} else {
+		probe.Healthy = body == "ok"
+	}
+	return probe
+}
+
+// probeTLS runs a TLS handshake on conn, which it closes, and verifies the
+// serving certificate against the CA of kubeconfig
+func probeTLS(ctx context.Context, conn net.Conn, host, kubeconfig string) *TLSProbe {
+	probe := &TLSProbe{}
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName: host,
+		// The certificate is verified below to describe it even when invalid
+		InsecureSkipVerify: true,
+	})
+	defer func() { _ = tlsConn.Close() }()
+
+	handshakeCtx, cancel := context.WithTimeout(ctx, apiServerProbeTimeout)
+	defer cancel()
+	start := time.Now()
+	if err := tlsConn.HandshakeContext(handshakeCtx); err != nil {
+		probe.Error = fmt.Sprintf("handshake: %v", err)
+		return probe
+	}
+	probe.HandshakeLatency = time.Since(start)
+
+	certs := tlsConn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		probe.Error = "the API server presented no certificate"
+		return probe
+	}
+	leaf := certs[0]
+	probe.Subject = leaf.Subject.String()
+	probe.DNSNames = leaf.DNSNames
+	for _, ip := range leaf.IPAddresses {
+		probe.IPAddresses = append(probe.IPAddresses, ip.String())
+	}
+	probe.Expires = leaf.NotAfter
+
+	roots, err := kubeconfigCAs(kubeconfig)
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(x509.VerifyOptions{DNSName: host, Roots: roots, Intermediates: intermediates}); err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	probe.Valid = true
+	return probe
+}
+
+// kubeconfigCAs returns the CA of the current cluster of a kubeconfig
+func kubeconfigCAs(kubeconfig string) (*x509.CertPool, error) {
+	if kubeconfig == "" {
+		return nil, fmt.Errorf("cannot verify the certificate without the cluster CA of the kubeconfig")
+	}
+	config, err := clientcmd.Load([]byte(kubeconfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	cluster := currentCluster(config)
+	if cluster == nil || len(cluster.CertificateAuthorityData) == 0 {
+		return nil, fmt.Errorf("the kubeconfig has no cluster CA")
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(cluster.CertificateAuthorityData) {
+		return nil, fmt.Errorf("the cluster CA of the kubeconfig is not a PEM certificate")
+	}
+	return roots, nil
+}
+
+// getHealthz calls /healthz of a workload cluster and returns its response
+func (c *Client) getHealthz(ctx context.Context, kubeconfig string) (string, error) {
+	config, err := c.workloadConfig(kubeconfig)
+	if err != nil {
+		return "", err
+	}
+	config.Timeout = apiServerProbeTimeout
+	httpClient, err := rest.HTTPClientFor(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to create client: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(config.Host, "/")+"/healthz", nil)
+	if err != nil {
+		return "", err
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = response.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(response.Body, 4096))
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode != http.StatusOK {
+		return strings.TrimSpace(string(body)), fmt.Errorf("%s", response.Status)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,116 @@
+package capi
+
+import (
+	"context"
+	"encoding/pem"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestProbeAPIServers(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" {
+			_, _ = w.Write([]byte("ok"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	// Clients distrusting the certificate fail the handshake on purpose
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+	host, portText, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(portText)
+
+	// A listener closed right away leaves a port nothing listens on
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	_ = closed.Close()
+
+	kubeconfig := func(ca []byte) []byte {
+		config := clientcmdapi.NewConfig()
+		config.Clusters["prod"] = &clientcmdapi.Cluster{Server: server.URL, CertificateAuthorityData: ca}
+		config.AuthInfos["prod-admin"] = &clientcmdapi.AuthInfo{Token: "admin"}
+		config.Contexts["prod-admin@prod"] = &clientcmdapi.Context{Cluster: "prod", AuthInfo: "prod-admin"}
+		config.CurrentContext = "prod-admin@prod"
+		data, err := clientcmd.Write(*config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	serverCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	otherCA, _ := newTestCA(t)
+
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	newCluster := func(name string, endpointPort int) *clusterv1.Cluster {
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: name}}
+		if endpointPort != 0 {
+			cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: host, Port: int32(endpointPort)}
+		}
+		cluster.Status.InfrastructureReady = true
+		return cluster
+	}
+	c := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newCluster("prod", port),
+			newCluster("rotated", port),
+			newCluster("down", closedPort),
+			newCluster("new", 0),
+		).Build(),
+		k8sClient: k8sfake.NewClientset(
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-kubeconfig"}, Data: map[string][]byte{"value": kubeconfig(serverCA)}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "rotated-kubeconfig"}, Data: map[string][]byte{"value": kubeconfig(otherCA)}},
+		),
+	}
+
+	probes, err := c.ProbeAPIServers(context.Background(), "org-acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(probes) != 4 {
+		t.Fatalf("probes = %d, want 4", len(probes))
+	}
+	down, fresh, prod, rotated := probes[0], probes[1], probes[2], probes[3]
+
+	if !prod.Reachable || prod.TLS == nil || !prod.TLS.Valid || !prod.Healthy || prod.Healthz != "ok" || len(prod.Errors) != 0 {
+		t.Errorf("prod = %+v (tls %+v), want a healthy API server", prod, prod.TLS)
+	}
+	if !rotated.Reachable || rotated.TLS == nil || rotated.TLS.Valid || rotated.TLS.Expires.IsZero() || rotated.Healthy {
+		t.Errorf("rotated = %+v (tls %+v), want an untrusted certificate", rotated, rotated.TLS)
+	}
+	if down.Reachable || down.Healthy || len(down.Errors) != 2 || !strings.Contains(down.Errors[1], "dial") || !down.InfrastructureReady {
+		t.Errorf("down = %+v, want it unreachable", down)
+	}
+	if fresh.Endpoint != "" || fresh.Reachable || len(fresh.Errors) != 1 {
+		t.Errorf("new = %+v, want no endpoint", fresh)
+	}
+
+	if _, err := c.ProbeAPIServer(context.Background(), "org-acme", "missing"); err == nil {
+		t.Error("ProbeAPIServer(missing) succeeded")
+	}
+}
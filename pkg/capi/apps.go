@@ -0,0 +1,117 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// appStatusDeployed is the release status of an installed App
+const appStatusDeployed = "deployed"
+
+// appGVK is the Giant Swarm App, a Helm chart from a catalog that the app
+// operator installs in a workload cluster or the management cluster
+var appGVK = schema.GroupVersionKind{Group: "application.giantswarm.io", Version: "v1alpha1", Kind: "AppList"}
+
+// App is a Giant Swarm App and its deployment status
+type App struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Chart is the name of the app in its catalog
+	Chart   string `json:"chart"`
+	Catalog string `json:"catalog,omitempty"`
+	// Version is the desired version of the app
+	Version string `json:"version"`
+	// TargetNamespace is the namespace the app is installed into
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+	// InCluster apps are installed in the management cluster
+	InCluster bool `json:"inCluster"`
+	// Status is the Helm release status, e.g. deployed or failed; empty
+	// while the app has not been installed yet
+	Status string `json:"status,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	// DeployedVersion is the version of the installed release
+	DeployedVersion string `json:"deployedVersion,omitempty"`
+	AppVersion      string `json:"appVersion,omitempty"`
+	LastDeployed    string `json:"lastDeployed,omitempty"`
+	// UserConfigMap and UserConfigSecret hold the values set by the user
+	UserConfigMap    string `json:"userConfigMap,omitempty"`
+	UserConfigSecret string `json:"userConfigSecret,omitempty"`
+}
+
+// Deployed reports whether the desired version of the app is installed
+func (a *App) Deployed() bool {
+	return a.Status == appStatusDeployed && a.DeployedVersion == a.Version
+}
+
+// newApp reads an App object
+func newApp(item *unstructured.Unstructured) App {
+	field := func(fields ...string) string {
+		value, _, _ := unstructured.NestedString(item.Object, fields...)
+		return value
+	}
+	app := App{
+		Name:             item.GetName(),
+		Namespace:        item.GetNamespace(),
+		Chart:            field("spec", "name"),
+		Catalog:          field("spec", "catalog"),
+		Version:          field("spec", "version"),
+		TargetNamespace:  field("spec", "namespace"),
+		Status:           field("status", "release", "status"),
+		Reason:           field("status", "release", "reason"),
+		DeployedVersion:  field("status", "version"),
+		AppVersion:       field("status", "appVersion"),
+		LastDeployed:     field("status", "release", "lastDeployed"),
+		UserConfigMap:    field("spec", "userConfig", "configMap", "name"),
+		UserConfigSecret: field("spec", "userConfig", "secret", "name"),
+	}
+	app.InCluster, _, _ = unstructured.NestedBool(item.Object, "spec", "kubeConfig", "inCluster")
+	return app
+}
+
+// targetsCluster reports whether an App belongs to a workload cluster: it is
+// labeled with the cluster or uses the kubeconfig Secret of the cluster
+func targetsCluster(item *unstructured.Unstructured, cluster string) bool {
+	if item.GetLabels()[ClusterLabel] == cluster {
+		return true
+	}
+	secret, _, _ := unstructured.NestedString(item.Object, "spec", "kubeConfig", "secret", "name")
+	return secret == cluster+"-kubeconfig"
+}
+
+// ListClusterApps lists the Giant Swarm Apps of a workload cluster, the apps
+// in the namespace of the cluster labeled with it or installed with its
+// kubeconfig. Management clusters without the App CRD have no apps.
+func (c *Client) ListClusterApps(ctx context.Context, namespace, name string) ([]App, error) {
+	if _, err := c.GetCluster(ctx, namespace, name); err != nil {
+		return nil, err
+	}
+	items, err := c.listUnstructured(ctx, appGVK, client.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	apps := []App{}
+	for i := range items {
+		if targetsCluster(&items[i], name) {
+			apps = append(apps, newApp(&items[i]))
+		}
+	}
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Name < apps[j].Name })
+	return apps, nil
+}
+
+// GetApp returns a Giant Swarm App
+func (c *Client) GetApp(ctx context.Context, namespace, name string) (*App, error) {
+	item := &unstructured.Unstructured{}
+	item.SetGroupVersionKind(appGVK.GroupVersion().WithKind("App"))
+	key := client.ObjectKey{Namespace: namespace, Name: name}
+	if err := c.ctrlClient.Get(ctx, key, item); err != nil {
+		return nil, fmt.Errorf("failed to get app %s/%s: %w", namespace, name, resourceError("App", key, err))
+	}
+	app := newApp(item)
+	return &app, nil
+}
@@ -0,0 +1,84 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestApp returns an App object installed at a version with a status
+func newTestApp(namespace, name string, spec, status map[string]any) *unstructured.Unstructured {
+	app := &unstructured.Unstructured{Object: map[string]any{"spec": spec, "status": status}}
+	app.SetAPIVersion("application.giantswarm.io/v1alpha1")
+	app.SetKind("App")
+	app.SetNamespace(namespace)
+	app.SetName(name)
+	return app
+}
+
+func TestListClusterApps(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	labeled := newTestApp("org-acme", "prod-cilium",
+		map[string]any{"name": "cilium", "catalog": "default", "version": "0.26.0", "namespace": "kube-system",
+			"kubeConfig": map[string]any{"inCluster": false, "secret": map[string]any{"name": "prod-kubeconfig"}}},
+		map[string]any{"version": "0.26.0", "appVersion": "1.15.4", "release": map[string]any{"status": "deployed"}})
+	labeled.SetLabels(map[string]string{ClusterLabel: "prod"})
+	unlabeled := newTestApp("org-acme", "prod-ingress",
+		map[string]any{"name": "ingress-nginx", "version": "3.4.0",
+			"kubeConfig": map[string]any{"secret": map[string]any{"name": "prod-kubeconfig"}}},
+		map[string]any{"version": "3.3.0", "release": map[string]any{"status": "failed", "reason": "timed out"}})
+	defaultApps := newTestApp("org-acme", "prod-default-apps",
+		map[string]any{"name": "default-apps-aws", "version": "1.0.0", "kubeConfig": map[string]any{"inCluster": true}}, nil)
+	defaultApps.SetLabels(map[string]string{ClusterLabel: "prod"})
+	other := newTestApp("org-acme", "dev-cilium",
+		map[string]any{"name": "cilium", "version": "0.26.0", "kubeConfig": map[string]any{"secret": map[string]any{"name": "dev-kubeconfig"}}}, nil)
+
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod"}},
+		labeled, unlabeled, defaultApps, other,
+	).Build()}
+	ctx := context.Background()
+
+	apps, err := c.ListClusterApps(ctx, "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(apps) != 3 {
+		t.Fatalf("apps = %+v, want the three apps of prod", apps)
+	}
+	cilium, defaults, ingress := apps[0], apps[1], apps[2]
+	if cilium.Chart != "cilium" || cilium.AppVersion != "1.15.4" || cilium.TargetNamespace != "kube-system" || !cilium.Deployed() {
+		t.Errorf("cilium = %+v", cilium)
+	}
+	if !defaults.InCluster || defaults.Deployed() {
+		t.Errorf("default apps = %+v, want an in-cluster app not deployed yet", defaults)
+	}
+	if ingress.Status != "failed" || ingress.Reason != "timed out" || ingress.Deployed() {
+		t.Errorf("ingress = %+v", ingress)
+	}
+
+	if _, err := c.ListClusterApps(ctx, "org-acme", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ListClusterApps(missing) error = %v, want not found", err)
+	}
+
+	app, err := c.GetApp(ctx, "org-acme", "prod-ingress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if app.Version != "3.4.0" || app.DeployedVersion != "3.3.0" {
+		t.Errorf("app = %+v", app)
+	}
+	if _, err := c.GetApp(ctx, "org-acme", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetApp(missing) error = %v, want not found", err)
+	}
+}
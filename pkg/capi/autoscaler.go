@@ -0,0 +1,245 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// autoscalerStatusConfigMap is written by cluster-autoscaler in its namespace
+const autoscalerStatusConfigMap = "cluster-autoscaler-status"
+
+// maxAutoscalerEvents bounds the recent events of an AutoscalerStatus
+const maxAutoscalerEvents = 20
+
+// AutoscalerStatus describes the activity of the cluster-autoscaler of a
+// workload cluster
+type AutoscalerStatus struct {
+	// Namespace is where the autoscaler writes its status in the workload
+	// cluster
+	Namespace string `json:"namespace"`
+	Time      string `json:"time,omitempty"`
+	Health    string `json:"health,omitempty"`
+	ScaleUp   string `json:"scaleUp,omitempty"`
+	ScaleDown string `json:"scaleDown,omitempty"`
+	// Raw is the status of autoscalers older than 1.30, which write it as
+	// text rather than YAML
+	Raw        string                `json:"raw,omitempty"`
+	NodeGroups []AutoscalerNodeGroup `json:"nodeGroups"`
+	// UnmanagedMachineDeployments have autoscaler annotations but no node
+	// group, so the autoscaler does not see them
+	UnmanagedMachineDeployments []string `json:"unmanagedMachineDeployments,omitempty"`
+	// UnschedulablePods are pending pods the scheduler found no node for
+	UnschedulablePods int               `json:"unschedulablePods"`
+	Events            []AutoscalerEvent `json:"events"`
+}
+
+// AutoscalerNodeGroup is a node group of the autoscaler, with the
+// MachineDeployment behind it when it is one
+type AutoscalerNodeGroup struct {
+	Name       string `json:"name"`
+	Health     string `json:"health"`
+	Registered int    `json:"registered"`
+	Ready      int    `json:"ready"`
+	Target     int    `json:"target"`
+	MinSize    int    `json:"minSize"`
+	MaxSize    int    `json:"maxSize"`
+	ScaleUp    string `json:"scaleUp"`
+	// Backoff is the error of the last failed scale-up of a group in backoff
+	Backoff             string `json:"backoff,omitempty"`
+	ScaleDown           string `json:"scaleDown"`
+	ScaleDownCandidates int    `json:"scaleDownCandidates,omitempty"`
+	MachineDeployment   string `json:"machineDeployment,omitempty"`
+	Replicas            *int32 `json:"replicas,omitempty"`
+	// TriggeredScaleUps counts the recent scale-ups triggered by pending pods
+	TriggeredScaleUps int `json:"triggeredScaleUps"`
+}
+
+// AutoscalerEvent is a recent event of the autoscaler
+type AutoscalerEvent struct {
+	Time      time.Time `json:"time"`
+	Reason    string    `json:"reason"`
+	Object    string    `json:"object"`
+	Message   string    `json:"message"`
+	NodeGroup string    `json:"nodeGroup,omitempty"`
+}
+
+// autoscalerStatusDocument is the YAML status of cluster-autoscaler
+type autoscalerStatusDocument struct {
+	Time        string `json:"time"`
+	ClusterWide struct {
+		Health    autoscalerCondition `json:"health"`
+		ScaleUp   autoscalerCondition `json:"scaleUp"`
+		ScaleDown autoscalerCondition `json:"scaleDown"`
+	} `json:"clusterWide"`
+	NodeGroups []struct {
+		Name   string `json:"name"`
+		Health struct {
+			autoscalerCondition
+			CloudProviderTarget int `json:"cloudProviderTarget"`
+			MinSize             int `json:"minSize"`
+			MaxSize             int `json:"maxSize"`
+		} `json:"health"`
+		ScaleUp   autoscalerCondition `json:"scaleUp"`
+		ScaleDown autoscalerCondition `json:"scaleDown"`
+	} `json:"nodeGroups"`
+}
+
+// autoscalerCondition is a condition of the autoscaler status
+type autoscalerCondition struct {
+	Status     string `json:"status"`
+	Candidates int    `json:"candidates"`
+	NodeCounts struct {
+		Registered struct {
+			Total int `json:"total"`
+			Ready int `json:"ready"`
+		} `json:"registered"`
+	} `json:"nodeCounts"`
+	BackoffInfo struct {
+		ErrorCode    string `json:"errorCode"`
+		ErrorMessage string `json:"errorMessage"`
+	} `json:"backoffInfo"`
+}
+
+// GetAutoscalerStatus reads the status ConfigMap and recent events of the
+// cluster-autoscaler running in a workload cluster, in autoscalerNamespace
+// (kube-system by default), and ties its node groups back to the
+// MachineDeployments of the cluster. Pending pods the scheduler cannot place
+// are counted as the pressure driving scale-ups.
+func (c *Client) GetAutoscalerStatus(ctx context.Context, namespace, clusterName, autoscalerNamespace string) (*AutoscalerStatus, error) {
+	if autoscalerNamespace == "" {
+		autoscalerNamespace = metav1.NamespaceSystem
+	}
+	mds, err := c.ListMachineDeployments(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	kubeconfig, err := c.GetKubeconfig(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	clientset, err := c.workloadClientset(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	configMap, err := clientset.CoreV1().ConfigMaps(autoscalerNamespace).Get(ctx, autoscalerStatusConfigMap, metav1.GetOptions{})
+	if err != nil {
+		key := client.ObjectKey{Namespace: autoscalerNamespace, Name: autoscalerStatusConfigMap}
+		return nil, fmt.Errorf("failed to get the cluster-autoscaler status, is the autoscaler running in namespace %s of the workload cluster: %w", autoscalerNamespace, workloadError("ConfigMap", key, err))
+	}
+
+	status := &AutoscalerStatus{Namespace: autoscalerNamespace, NodeGroups: []AutoscalerNodeGroup{}, Events: []AutoscalerEvent{}}
+	document := &autoscalerStatusDocument{}
+	if err := yaml.Unmarshal([]byte(configMap.Data["status"]), document); err != nil || document.Time == "" {
+		status.Raw = configMap.Data["status"]
+	} else {
+		status.Time = document.Time
+		status.Health = document.ClusterWide.Health.Status
+		status.ScaleUp = document.ClusterWide.ScaleUp.Status
+		status.ScaleDown = document.ClusterWide.ScaleDown.Status
+		for _, group := range document.NodeGroups {
+			status.NodeGroups = append(status.NodeGroups, AutoscalerNodeGroup{
+				Name:                group.Name,
+				Health:              group.Health.Status,
+				Registered:          group.Health.NodeCounts.Registered.Total,
+				Ready:               group.Health.NodeCounts.Registered.Ready,
+				Target:              group.Health.CloudProviderTarget,
+				MinSize:             group.Health.MinSize,
+				MaxSize:             group.Health.MaxSize,
+				ScaleUp:             group.ScaleUp.Status,
+				Backoff:             strings.TrimSpace(group.ScaleUp.BackoffInfo.ErrorCode + " " + group.ScaleUp.BackoffInfo.ErrorMessage),
+				ScaleDown:           group.ScaleDown.Status,
+				ScaleDownCandidates: group.ScaleDown.Candidates,
+			})
+		}
+	}
+
+	// The clusterapi provider names node groups Kind/namespace/name
+	grouped := map[string]bool{}
+	for i, group := range status.NodeGroups {
+		parts := strings.Split(group.Name, "/")
+		if len(parts) != 3 || parts[0] != "MachineDeployment" || parts[1] != namespace {
+			continue
+		}
+		for _, md := range mds.Items {
+			if md.Name == parts[2] {
+				status.NodeGroups[i].MachineDeployment = md.Name
+				status.NodeGroups[i].Replicas = md.Spec.Replicas
+				grouped[md.Name] = true
+			}
+		}
+	}
+	for _, md := range mds.Items {
+		_, hasMin := md.Annotations[clusterv1.AutoscalerMinSizeAnnotation]
+		_, hasMax := md.Annotations[clusterv1.AutoscalerMaxSizeAnnotation]
+		if hasMin && hasMax && !grouped[md.Name] && status.Raw == "" {
+			status.UnmanagedMachineDeployments = append(status.UnmanagedMachineDeployments, md.Name)
+		}
+	}
+
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{FieldSelector: "status.phase=Pending"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", workloadError("Pod", client.ObjectKey{}, err))
+	}
+	for _, pod := range pods.Items {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse && condition.Reason == corev1.PodReasonUnschedulable {
+				status.UnschedulablePods++
+			}
+		}
+	}
+
+	events, err := clientset.CoreV1().Events(metav1.NamespaceAll).List(ctx, metav1.ListOptions{FieldSelector: "source=cluster-autoscaler"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", workloadError("Event", client.ObjectKey{}, err))
+	}
+	for _, event := range events.Items {
+		if event.Source.Component != "cluster-autoscaler" && event.ReportingController != "cluster-autoscaler" {
+			continue
+		}
+		autoscalerEvent := AutoscalerEvent{
+			Time:    eventTime(&event),
+			Reason:  event.Reason,
+			Object:  event.InvolvedObject.Kind + " " + event.InvolvedObject.Namespace + "/" + event.InvolvedObject.Name,
+			Message: event.Message,
+		}
+		for i, group := range status.NodeGroups {
+			if strings.Contains(event.Message, group.Name) {
+				autoscalerEvent.NodeGroup = group.Name
+				if event.Reason == "TriggeredScaleUp" {
+					status.NodeGroups[i].TriggeredScaleUps++
+				}
+				break
+			}
+		}
+		status.Events = append(status.Events, autoscalerEvent)
+	}
+	sort.Slice(status.Events, func(i, j int) bool {
+		return status.Events[i].Time.After(status.Events[j].Time)
+	})
+	if len(status.Events) > maxAutoscalerEvents {
+		status.Events = status.Events[:maxAutoscalerEvents]
+	}
+	return status, nil
+}
+
+// eventTime returns when an event last happened
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case event.Series != nil:
+		return event.Series.LastObservedTime.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
@@ -0,0 +1,156 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testAutoscalerStatus = `time: 2026-10-16 09:12:40.713420 +0000 UTC
+autoscalerStatus: Running
+clusterWide:
+  health:
+    status: Healthy
+    nodeCounts:
+      registered:
+        total: 5
+        ready: 5
+  scaleUp:
+    status: InProgress
+  scaleDown:
+    status: CandidatesPresent
+    candidates: 1
+nodeGroups:
+- name: MachineDeployment/org-acme/prod-md-0
+  health:
+    status: Healthy
+    nodeCounts:
+      registered:
+        total: 3
+        ready: 3
+    cloudProviderTarget: 4
+    minSize: 1
+    maxSize: 5
+  scaleUp:
+    status: InProgress
+  scaleDown:
+    status: NoCandidates
+- name: MachineDeployment/org-acme/prod-gpu
+  health:
+    status: Healthy
+    nodeCounts:
+      registered:
+        total: 2
+        ready: 2
+    cloudProviderTarget: 2
+    minSize: 0
+    maxSize: 2
+  scaleUp:
+    status: Backoff
+    backoffInfo:
+      errorCode: OutOfResource
+      errorMessage: instance type unavailable
+  scaleDown:
+    status: CandidatesPresent
+    candidates: 1
+`
+
+func TestGetAutoscalerStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	autoscaled := map[string]string{
+		clusterv1.AutoscalerMinSizeAnnotation: "1",
+		clusterv1.AutoscalerMaxSizeAnnotation: "5",
+	}
+	md := func(name string, annotations map[string]string) *clusterv1.MachineDeployment {
+		return &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: name, Annotations: annotations, Labels: map[string]string{clusterv1.ClusterNameLabel: "prod"}},
+			Spec:       clusterv1.MachineDeploymentSpec{ClusterName: "prod", Replicas: ptr.To[int32](3)},
+		}
+	}
+	event := func(name, reason, message string, age time.Duration) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: "shop", Name: name},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "web"},
+			Reason:         reason,
+			Message:        message,
+			Source:         corev1.EventSource{Component: "cluster-autoscaler"},
+			LastTimestamp:  metav1.NewTime(time.Now().Add(-age)),
+		}
+	}
+	pending := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodPending,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable}},
+		},
+	}
+	workload := k8sfake.NewClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: autoscalerStatusConfigMap},
+			Data:       map[string]string{"status": testAutoscalerStatus},
+		},
+		pending,
+		event("web.1", "TriggeredScaleUp", "pod triggered scale-up: [{MachineDeployment/org-acme/prod-md-0 3->4 (max: 5)}]", time.Minute),
+		event("web.2", "NotTriggerScaleUp", "pod didn't trigger scale-up: 1 max node group size reached", time.Hour),
+		&corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web.3"},
+			Reason:     "Scheduled",
+			Source:     corev1.EventSource{Component: "default-scheduler"},
+		},
+	)
+	c := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			md("prod-md-0", autoscaled),
+			md("prod-gpu", nil),
+			md("prod-md-1", autoscaled),
+		).Build(),
+		k8sClient: k8sfake.NewClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-kubeconfig"},
+			Data:       map[string][]byte{"value": []byte("kubeconfig")},
+		}),
+		newWorkloadClientset: func(kubeconfig string) (kubernetes.Interface, error) {
+			return workload, nil
+		},
+	}
+
+	status, err := c.GetAutoscalerStatus(context.Background(), "org-acme", "prod", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Health != "Healthy" || status.ScaleUp != "InProgress" || status.Raw != "" || status.UnschedulablePods != 1 {
+		t.Fatalf("status = %+v, want a healthy autoscaler scaling up for 1 pod", status)
+	}
+	if len(status.NodeGroups) != 2 {
+		t.Fatalf("node groups = %+v, want 2", status.NodeGroups)
+	}
+	md0, gpu := status.NodeGroups[0], status.NodeGroups[1]
+	if md0.MachineDeployment != "prod-md-0" || md0.Target != 4 || md0.MaxSize != 5 || *md0.Replicas != 3 || md0.TriggeredScaleUps != 1 {
+		t.Errorf("prod-md-0 = %+v, want a scale-up from 3 to 4 replicas", md0)
+	}
+	if gpu.ScaleUp != "Backoff" || gpu.Backoff != "OutOfResource instance type unavailable" || gpu.ScaleDownCandidates != 1 {
+		t.Errorf("prod-gpu = %+v, want a backoff", gpu)
+	}
+	if len(status.UnmanagedMachineDeployments) != 1 || status.UnmanagedMachineDeployments[0] != "prod-md-1" {
+		t.Errorf("unmanaged = %v, want prod-md-1", status.UnmanagedMachineDeployments)
+	}
+	if len(status.Events) != 2 || status.Events[0].Reason != "TriggeredScaleUp" || status.Events[0].NodeGroup != md0.Name {
+		t.Errorf("events = %+v, want the scale-up first", status.Events)
+	}
+
+	if _, err := c.GetAutoscalerStatus(context.Background(), "org-acme", "prod", "autoscaler"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetAutoscalerStatus(autoscaler) err = %v, want not found", err)
+	}
+}
@@ -0,0 +1,235 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AWSVPC is the VPC of an AWS cluster
+type AWSVPC struct {
+	ID   string `json:"id,omitempty"`
+	CIDR string `json:"cidr,omitempty"`
+}
+
+// AWSSubnet is a subnet of an AWS cluster
+type AWSSubnet struct {
+	ID               string `json:"id,omitempty"`
+	AvailabilityZone string `json:"availabilityZone,omitempty"`
+	CIDR             string `json:"cidr,omitempty"`
+	Public           bool   `json:"public"`
+	NatGatewayID     string `json:"natGatewayId,omitempty"`
+}
+
+// AWSSecurityGroup is a security group managed for a role of an AWS cluster,
+// e.g. controlplane, node or bastion
+type AWSSecurityGroup struct {
+	Role string `json:"role"`
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// AWSLoadBalancer is a load balancer in front of the API server
+type AWSLoadBalancer struct {
+	// Role is apiserver or secondary
+	Role    string `json:"role"`
+	Name    string `json:"name,omitempty"`
+	DNSName string `json:"dnsName,omitempty"`
+	Scheme  string `json:"scheme,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+// AWSBastion is the bastion host of an AWS cluster
+type AWSBastion struct {
+	Enabled      bool   `json:"enabled"`
+	InstanceType string `json:"instanceType,omitempty"`
+	InstanceID   string `json:"instanceId,omitempty"`
+	State        string `json:"state,omitempty"`
+	PublicIP     string `json:"publicIp,omitempty"`
+	PrivateIP    string `json:"privateIp,omitempty"`
+}
+
+// AWSFailureDomain is an availability zone machines can be placed in
+type AWSFailureDomain struct {
+	Name         string `json:"name"`
+	ControlPlane bool   `json:"controlPlane"`
+}
+
+// AWSClusterInfo is the AWS infrastructure of a cluster, read from its
+// AWSCluster or, for EKS clusters, its AWSManagedCluster and
+// AWSManagedControlPlane
+type AWSClusterInfo struct {
+	// Kind is AWSCluster or AWSManagedCluster
+	Kind                 string             `json:"kind"`
+	Name                 string             `json:"name"`
+	Region               string             `json:"region,omitempty"`
+	SSHKeyName           string             `json:"sshKeyName,omitempty"`
+	Ready                bool               `json:"ready"`
+	ControlPlaneEndpoint string             `json:"controlPlaneEndpoint,omitempty"`
+	VPC                  AWSVPC             `json:"vpc"`
+	Subnets              []AWSSubnet        `json:"subnets"`
+	SecurityGroups       []AWSSecurityGroup `json:"securityGroups"`
+	LoadBalancers        []AWSLoadBalancer  `json:"loadBalancers"`
+	Bastion              AWSBastion         `json:"bastion"`
+	FailureDomains       []AWSFailureDomain `json:"failureDomains"`
+	// ControlPlane names the AWSManagedControlPlane the network was read
+	// from for EKS clusters
+	ControlPlane string `json:"controlPlane,omitempty"`
+}
+
+// isAWSCluster reports whether a cluster runs on the AWS provider
+func isAWSCluster(cluster *clusterv1.Cluster) bool {
+	return ClusterProvider(cluster) == ProviderAWS
+}
+
+// GetAWSCluster reads the AWS infrastructure of a cluster. For EKS clusters,
+// whose AWSManagedCluster only reports readiness and failure domains, the
+// region and network are read from the AWSManagedControlPlane.
+func (c *Client) GetAWSCluster(ctx context.Context, namespace, name string) (*AWSClusterInfo, error) {
+	cluster, err := c.GetCluster(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if !isAWSCluster(cluster) {
+		return nil, errorf(ErrInvalidArgument, "cluster %s/%s is not an AWS cluster", namespace, name)
+	}
+
+	ref := cluster.Spec.InfrastructureRef
+	infra, err := c.getReferenced(ctx, namespace, ref.APIVersion, ref.Kind, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+	info := &AWSClusterInfo{Kind: ref.Kind, Name: ref.Name}
+	readAWSInfrastructure(info, infra.Object)
+
+	if ref.Kind == "AWSManagedCluster" {
+		if cpRef := cluster.Spec.ControlPlaneRef; cpRef != nil && cpRef.Kind == "AWSManagedControlPlane" {
+			controlPlane, err := c.getReferenced(ctx, namespace, cpRef.APIVersion, cpRef.Kind, cpRef.Name)
+			if err != nil {
+				return nil, err
+			}
+			// Readiness stays the one of the AWSManagedCluster
+			ready := info.Ready
+			info.ControlPlane = cpRef.Name
+			readAWSInfrastructure(info, controlPlane.Object)
+			info.Ready = ready
+		}
+	}
+	return info, nil
+}
+
+// getReferenced reads a referenced provider object as unstructured
+func (c *Client) getReferenced(ctx context.Context, namespace, apiVersion, kind, name string) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(apiVersion, kind))
+	key := client.ObjectKey{Namespace: namespace, Name: name}
+	if err := c.ctrlClient.Get(ctx, key, obj); err != nil {
+		return nil, fmt.Errorf("failed to get %s %s/%s: %w", kind, namespace, name, resourceError(kind, key, err))
+	}
+	return obj, nil
+}
+
+// readAWSInfrastructure fills info from an AWSCluster, AWSManagedCluster or
+// AWSManagedControlPlane, keeping what earlier objects set when a field is
+// missing
+func readAWSInfrastructure(info *AWSClusterInfo, obj map[string]any) {
+	setString := func(target *string, fields ...string) {
+		if value, _, _ := unstructured.NestedString(obj, fields...); value != "" {
+			*target = value
+		}
+	}
+
+	setString(&info.Region, "spec", "region")
+	setString(&info.SSHKeyName, "spec", "sshKeyName")
+	if ready, found, _ := unstructured.NestedBool(obj, "status", "ready"); found {
+		info.Ready = ready
+	}
+	if host, _, _ := unstructured.NestedString(obj, "spec", "controlPlaneEndpoint", "host"); host != "" {
+		port, _, _ := unstructured.NestedInt64(obj, "spec", "controlPlaneEndpoint", "port")
+		info.ControlPlaneEndpoint = fmt.Sprintf("%s:%d", host, port)
+	}
+
+	setString(&info.VPC.ID, "spec", "network", "vpc", "id")
+	setString(&info.VPC.CIDR, "spec", "network", "vpc", "cidrBlock")
+
+	if subnets, found, _ := unstructured.NestedSlice(obj, "spec", "network", "subnets"); found {
+		info.Subnets = []AWSSubnet{}
+		for _, item := range subnets {
+			subnet, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			id, _, _ := unstructured.NestedString(subnet, "resourceID")
+			if id == "" {
+				id, _, _ = unstructured.NestedString(subnet, "id")
+			}
+			zone, _, _ := unstructured.NestedString(subnet, "availabilityZone")
+			cidr, _, _ := unstructured.NestedString(subnet, "cidrBlock")
+			public, _, _ := unstructured.NestedBool(subnet, "isPublic")
+			natGateway, _, _ := unstructured.NestedString(subnet, "natGatewayId")
+			info.Subnets = append(info.Subnets, AWSSubnet{ID: id, AvailabilityZone: zone, CIDR: cidr, Public: public, NatGatewayID: natGateway})
+		}
+	}
+
+	if groups, found, _ := unstructured.NestedMap(obj, "status", "networkStatus", "securityGroups"); found {
+		info.SecurityGroups = []AWSSecurityGroup{}
+		for role, item := range groups {
+			group, _ := item.(map[string]any)
+			id, _, _ := unstructured.NestedString(group, "id")
+			name, _, _ := unstructured.NestedString(group, "name")
+			info.SecurityGroups = append(info.SecurityGroups, AWSSecurityGroup{Role: role, ID: id, Name: name})
+		}
+		sort.Slice(info.SecurityGroups, func(i, j int) bool { return info.SecurityGroups[i].Role < info.SecurityGroups[j].Role })
+	}
+
+	for role, field := range map[string]string{"apiserver": "apiServerElb", "secondary": "secondaryAPIServerELB"} {
+		lb, found, _ := unstructured.NestedMap(obj, "status", "networkStatus", field)
+		if !found {
+			continue
+		}
+		balancer := AWSLoadBalancer{Role: role}
+		balancer.Name, _, _ = unstructured.NestedString(lb, "name")
+		balancer.DNSName, _, _ = unstructured.NestedString(lb, "dnsName")
+		balancer.Scheme, _, _ = unstructured.NestedString(lb, "scheme")
+		balancer.Type, _, _ = unstructured.NestedString(lb, "loadBalancerType")
+		info.LoadBalancers = append(info.LoadBalancers, balancer)
+	}
+	sort.Slice(info.LoadBalancers, func(i, j int) bool { return info.LoadBalancers[i].Role < info.LoadBalancers[j].Role })
+
+	if enabled, found, _ := unstructured.NestedBool(obj, "spec", "bastion", "enabled"); found {
+		info.Bastion.Enabled = enabled
+	}
+	setString(&info.Bastion.InstanceType, "spec", "bastion", "instanceType")
+	setString(&info.Bastion.InstanceID, "status", "bastion", "id")
+	setString(&info.Bastion.State, "status", "bastion", "instanceState")
+	setString(&info.Bastion.PublicIP, "status", "bastion", "publicIp")
+	setString(&info.Bastion.PrivateIP, "status", "bastion", "privateIp")
+
+	if domains, found, _ := unstructured.NestedMap(obj, "status", "failureDomains"); found {
+		info.FailureDomains = []AWSFailureDomain{}
+		for name, item := range domains {
+			domain, _ := item.(map[string]any)
+			controlPlane, _, _ := unstructured.NestedBool(domain, "controlPlane")
+			info.FailureDomains = append(info.FailureDomains, AWSFailureDomain{Name: name, ControlPlane: controlPlane})
+		}
+		sort.Slice(info.FailureDomains, func(i, j int) bool { return info.FailureDomains[i].Name < info.FailureDomains[j].Name })
+	}
+
+	if info.Subnets == nil {
+		info.Subnets = []AWSSubnet{}
+	}
+	if info.SecurityGroups == nil {
+		info.SecurityGroups = []AWSSecurityGroup{}
+	}
+	if info.LoadBalancers == nil {
+		info.LoadBalancers = []AWSLoadBalancer{}
+	}
+	if info.FailureDomains == nil {
+		info.FailureDomains = []AWSFailureDomain{}
+	}
+}
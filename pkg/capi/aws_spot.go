@@ -0,0 +1,415 @@
+package capi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// machinePoolGVK is the MachinePool of the experimental CAPI API
+	machinePoolGVK = schema.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "MachinePool"}
+	// awsMachinePoolGVK is the auto scaling group backing a MachinePool on AWS
+	awsMachinePoolGVK = schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta2", Kind: "AWSMachinePool"}
+)
+
+// SpotAllocationStrategies are the strategies auto scaling groups use to pick
+// spot capacity pools
+var SpotAllocationStrategies = []string{"lowest-price", "capacity-optimized", "capacity-optimized-prioritized", "price-capacity-optimized"}
+
+// AWSSpotOptions configures spot instances on the AWSMachineTemplate of a
+// MachineDeployment or on the AWSMachinePool of a MachinePool
+type AWSSpotOptions struct {
+	Namespace string
+	// MachineDeployment or MachinePool selects the machines; exactly one is set
+	MachineDeployment string
+	MachinePool       string
+	// Spot requests spot instances; false switches back to on-demand
+	Spot bool
+	// MaxPrice is the maximum hourly price in USD; empty caps it at the
+	// on-demand price
+	MaxPrice string
+
+	// The mixed instances policy of AWSMachinePools runs a share of the pool
+	// on-demand and spreads spot instances over several instance types
+	OnDemandBaseCapacity        *int64
+	OnDemandPercentageAboveBase *int64
+	SpotAllocationStrategy      string
+	InstanceTypes               []string
+	// CapacityRebalance lets the auto scaling group of an AWSMachinePool
+	// replace spot instances at an elevated risk of interruption before
+	// they are interrupted
+	CapacityRebalance *bool
+
+	// TemplateName names the AWSMachineTemplate cloned for a
+	// MachineDeployment; it defaults to a name derived from the settings
+	TemplateName string
+	// DryRun reports the changes and the disruption without applying them
+	DryRun bool
+}
+
+// mixed reports whether the options configure a mixed instances policy
+func (o AWSSpotOptions) mixed() bool {
+	return len(o.InstanceTypes) > 0 || o.OnDemandBaseCapacity != nil || o.OnDemandPercentageAboveBase != nil || o.SpotAllocationStrategy != ""
+}
+
+// SpotDisruption describes machines that spot interruptions can take away
+type SpotDisruption struct {
+	Kind     string   `json:"kind"`
+	Name     string   `json:"name"`
+	Cluster  string   `json:"cluster"`
+	Replicas int32    `json:"replicas"`
+	Nodes    []string `json:"nodes"`
+	// HealthChecked reports whether a MachineHealthCheck replaces the
+	// machines of interrupted instances
+	HealthChecked bool `json:"healthChecked"`
+}
+
+// AWSSpotConfiguration reports the spot configuration of machines and what
+// interruptions may disrupt
+type AWSSpotConfiguration struct {
+	Namespace string `json:"namespace"`
+	// Kind is the configured object, AWSMachineTemplate or AWSMachinePool
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// OldTemplate is the template the MachineDeployment used before
+	OldTemplate string         `json:"oldTemplate,omitempty"`
+	Spot        bool           `json:"spot"`
+	Changes     []FieldChange  `json:"changes"`
+	Disruption  SpotDisruption `json:"disruption"`
+	Warnings    []string       `json:"warnings"`
+	DryRun      bool           `json:"dryRun,omitempty"`
+}
+
+// ConfigureAWSSpot switches the machines of a MachineDeployment or
+// MachinePool between spot and on-demand instances. A MachineDeployment gets
+// a clone of its AWSMachineTemplate with the spot market options, which rolls
+// out all its machines; an AWSMachinePool is changed in place. The result
+// summarizes the nodes spot interruptions may take away and the safeguards
+// that are missing.
+func (c *Client) ConfigureAWSSpot(ctx context.Context, opts AWSSpotOptions) (*AWSSpotConfiguration, error) {
+	if (opts.MachineDeployment == "") == (opts.MachinePool == "") {
+		return nil, errorf(ErrInvalidArgument, "either a MachineDeployment or a MachinePool is required")
+	}
+	if opts.SpotAllocationStrategy != "" && !slices.Contains(SpotAllocationStrategies, opts.SpotAllocationStrategy) {
+		return nil, errorf(ErrInvalidArgument, "unknown spot allocation strategy %q, use one of %s", opts.SpotAllocationStrategy, strings.Join(SpotAllocationStrategies, ", "))
+	}
+	if !opts.Spot && (opts.MaxPrice != "" || opts.mixed()) {
+		return nil, errorf(ErrInvalidArgument, "spot price and mixed instances options require spot instances")
+	}
+	if opts.OnDemandPercentageAboveBase != nil && (*opts.OnDemandPercentageAboveBase < 0 || *opts.OnDemandPercentageAboveBase > 100) {
+		return nil, errorf(ErrInvalidArgument, "the on-demand percentage must be between 0 and 100")
+	}
+
+	var config *AWSSpotConfiguration
+	var err error
+	if opts.MachineDeployment != "" {
+		config, err = c.configureSpotTemplate(ctx, opts)
+	} else {
+		config, err = c.configureSpotPool(ctx, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	config.Warnings = append(config.Warnings, spotWarnings(config, opts)...)
+	return config, nil
+}
+
+// configureSpotTemplate clones the AWSMachineTemplate of a MachineDeployment
+// with spot market options and points the MachineDeployment to the clone
+func (c *Client) configureSpotTemplate(ctx context.Context, opts AWSSpotOptions) (*AWSSpotConfiguration, error) {
+	if opts.mixed() || opts.CapacityRebalance != nil {
+		return nil, errorf(ErrInvalidArgument, "mixed instances policies and capacity rebalancing are only supported on MachinePools")
+	}
+	md, err := c.GetMachineDeployment(ctx, opts.Namespace, opts.MachineDeployment)
+	if err != nil {
+		return nil, err
+	}
+	ref := md.Spec.Template.Spec.InfrastructureRef
+	if ref.Kind != awsMachineTemplateGVK.Kind {
+		return nil, errorf(ErrInvalidArgument, "MachineDeployment %s uses a %s, not an AWSMachineTemplate", md.Name, ref.Kind)
+	}
+	original, err := c.getReferenced(ctx, opts.Namespace, ref.APIVersion, ref.Kind, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	before, _, _ := unstructured.NestedMap(original.Object, "spec", "template", "spec")
+	after, _, _ := unstructured.NestedMap(original.Object, "spec", "template", "spec")
+	if after == nil {
+		after = map[string]any{}
+	}
+	setSpotMarketOptions(after, opts)
+
+	name := opts.TemplateName
+	if name == "" {
+		suffix := "on-demand"
+		if opts.Spot {
+			sum := sha256.Sum256([]byte("spot:" + opts.MaxPrice))
+			suffix = "spot-" + hex.EncodeToString(sum[:])[:8]
+		}
+		name = fmt.Sprintf("%s-%s", md.Name, suffix)
+	}
+	config := &AWSSpotConfiguration{
+		Namespace:   opts.Namespace,
+		Kind:        ref.Kind,
+		Name:        name,
+		OldTemplate: ref.Name,
+		Spot:        opts.Spot,
+		Changes:     diffFields(before, after),
+		DryRun:      opts.DryRun,
+	}
+	if len(config.Changes) == 0 {
+		return nil, errorf(ErrInvalidArgument, "AWSMachineTemplate %s already has the requested spot configuration", ref.Name)
+	}
+
+	replicas := int32(0)
+	if md.Spec.Replicas != nil {
+		replicas = *md.Spec.Replicas
+	}
+	config.Disruption = SpotDisruption{Kind: "MachineDeployment", Name: md.Name, Cluster: md.Spec.ClusterName, Replicas: replicas}
+	machines, err := c.ListMachines(ctx, opts.Namespace, md.Spec.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+	config.Disruption.Nodes = []string{}
+	for _, machine := range machines.Items {
+		if machine.Labels[clusterv1.MachineDeploymentNameLabel] == md.Name && machine.Status.NodeRef != nil {
+			config.Disruption.Nodes = append(config.Disruption.Nodes, machine.Status.NodeRef.Name)
+		}
+	}
+	config.Disruption.HealthChecked, err = c.healthChecked(ctx, opts.Namespace, md.Spec.ClusterName, md.Spec.Template.Labels)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return config, nil
+	}
+
+	_, err = c.cloneTemplate(ctx, original, name, func(spec map[string]any) error {
+		setSpotMarketOptions(spec, opts)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = c.updateObject(ctx, client.ObjectKeyFromObject(md), md, "configure-spot", func() error {
+		md.Spec.Template.Spec.InfrastructureRef.Name = name
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to point MachineDeployment %s to template %s: %w", md.Name, name, err)
+	}
+	return config, nil
+}
+
+// setSpotMarketOptions requests or removes spot instances in an AWSMachine spec
+func setSpotMarketOptions(spec map[string]any, opts AWSSpotOptions) {
+	if !opts.Spot {
+		delete(spec, "spotMarketOptions")
+		return
+	}
+	options := map[string]any{}
+	if opts.MaxPrice != "" {
+		options["maxPrice"] = opts.MaxPrice
+	}
+	spec["spotMarketOptions"] = options
+}
+
+// configureSpotPool changes the spot configuration of the AWSMachinePool of a
+// MachinePool in place
+func (c *Client) configureSpotPool(ctx context.Context, opts AWSSpotOptions) (*AWSSpotConfiguration, error) {
+	pool := &unstructured.Unstructured{}
+	pool.SetGroupVersionKind(machinePoolGVK)
+	key := client.ObjectKey{Namespace: opts.Namespace, Name: opts.MachinePool}
+	if err := c.ctrlClient.Get(ctx, key, pool); err != nil {
+		return nil, fmt.Errorf("failed to get MachinePool %s/%s: %w", key.Namespace, key.Name, resourceError("MachinePool", key, err))
+	}
+	kind, _, _ := unstructured.NestedString(pool.Object, "spec", "template", "spec", "infrastructureRef", "kind")
+	infraName, _, _ := unstructured.NestedString(pool.Object, "spec", "template", "spec", "infrastructureRef", "name")
+	if kind != awsMachinePoolGVK.Kind {
+		return nil, errorf(ErrInvalidArgument, "MachinePool %s uses a %s, not an AWSMachinePool", opts.MachinePool, kind)
+	}
+
+	awsPool := &unstructured.Unstructured{}
+	awsPool.SetGroupVersionKind(awsMachinePoolGVK)
+	awsKey := client.ObjectKey{Namespace: opts.Namespace, Name: infraName}
+	if err := c.ctrlClient.Get(ctx, awsKey, awsPool); err != nil {
+		return nil, fmt.Errorf("failed to get AWSMachinePool %s/%s: %w", awsKey.Namespace, awsKey.Name, resourceError(kind, awsKey, err))
+	}
+	before, _, _ := unstructured.NestedMap(awsPool.Object, "spec")
+	after, _, _ := unstructured.NestedMap(awsPool.Object, "spec")
+	if after == nil {
+		after = map[string]any{}
+	}
+	if err := setPoolSpotOptions(after, opts); err != nil {
+		return nil, err
+	}
+
+	clusterName, _, _ := unstructured.NestedString(pool.Object, "spec", "clusterName")
+	replicas, _, _ := unstructured.NestedInt64(pool.Object, "spec", "replicas")
+	config := &AWSSpotConfiguration{
+		Namespace: opts.Namespace,
+		Kind:      kind,
+		Name:      infraName,
+		Spot:      opts.Spot,
+		Changes:   diffFields(before, after),
+		DryRun:    opts.DryRun,
+		// MachinePools are not covered by MachineHealthChecks; the auto
+		// scaling group replaces interrupted instances itself
+		Disruption: SpotDisruption{Kind: "MachinePool", Name: opts.MachinePool, Cluster: clusterName, Replicas: int32(replicas), Nodes: []string{}, HealthChecked: true},
+	}
+	if len(config.Changes) == 0 {
+		return nil, errorf(ErrInvalidArgument, "AWSMachinePool %s already has the requested spot configuration", infraName)
+	}
+	nodeRefs, _, _ := unstructured.NestedSlice(pool.Object, "status", "nodeRefs")
+	for _, item := range nodeRefs {
+		ref, _ := item.(map[string]any)
+		if name, _, _ := unstructured.NestedString(ref, "name"); name != "" {
+			config.Disruption.Nodes = append(config.Disruption.Nodes, name)
+		}
+	}
+	if opts.DryRun {
+		return config, nil
+	}
+
+	err := c.updateObject(ctx, awsKey, awsPool, "", func() error {
+		spec, _ := awsPool.Object["spec"].(map[string]any)
+		if spec == nil {
+			spec = map[string]any{}
+			awsPool.Object["spec"] = spec
+		}
+		return setPoolSpotOptions(spec, opts)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update AWSMachinePool %s: %w", infraName, err)
+	}
+	return config, nil
+}
+
+// setPoolSpotOptions changes the spot configuration of an AWSMachinePool
+// spec. Auto scaling groups with a mixed instances policy take their spot
+// settings from the policy, not from the launch template.
+func setPoolSpotOptions(spec map[string]any, opts AWSSpotOptions) error {
+	if opts.CapacityRebalance != nil {
+		spec["capacityRebalance"] = *opts.CapacityRebalance
+	}
+	_, hasMixed := spec["mixedInstancesPolicy"]
+
+	if !opts.Spot {
+		unstructured.RemoveNestedField(spec, "awsLaunchTemplate", "spotMarketOptions")
+		if hasMixed {
+			setNestedValue(spec, "mixedInstancesPolicy.instancesDistribution.onDemandPercentageAboveBaseCapacity", int64(100))
+		}
+		return nil
+	}
+
+	if !opts.mixed() && !hasMixed {
+		options := map[string]any{}
+		if opts.MaxPrice != "" {
+			options["maxPrice"] = opts.MaxPrice
+		}
+		setNestedValue(spec, "awsLaunchTemplate.spotMarketOptions", options)
+		return nil
+	}
+
+	if opts.MaxPrice != "" {
+		return errorf(ErrInvalidArgument, "a maximum spot price cannot be combined with a mixed instances policy, whose spot instances are capped at the on-demand price")
+	}
+	unstructured.RemoveNestedField(spec, "awsLaunchTemplate", "spotMarketOptions")
+	distribution := "mixedInstancesPolicy.instancesDistribution."
+	if opts.OnDemandBaseCapacity != nil {
+		setNestedValue(spec, distribution+"onDemandBaseCapacity", *opts.OnDemandBaseCapacity)
+	}
+	switch {
+	case opts.OnDemandPercentageAboveBase != nil:
+		setNestedValue(spec, distribution+"onDemandPercentageAboveBaseCapacity", *opts.OnDemandPercentageAboveBase)
+	default:
+		// Without a percentage, everything above the base capacity runs on spot
+		if percentage, _, _ := unstructured.NestedInt64(spec, "mixedInstancesPolicy", "instancesDistribution", "onDemandPercentageAboveBaseCapacity"); percentage == 100 || !hasMixed {
+			setNestedValue(spec, distribution+"onDemandPercentageAboveBaseCapacity", int64(0))
+		}
+	}
+	if opts.SpotAllocationStrategy != "" {
+		setNestedValue(spec, distribution+"spotAllocationStrategy", opts.SpotAllocationStrategy)
+	}
+	if len(opts.InstanceTypes) > 0 {
+		overrides := make([]any, 0, len(opts.InstanceTypes))
+		for _, instanceType := range opts.InstanceTypes {
+			overrides = append(overrides, map[string]any{"instanceType": instanceType})
+		}
+		setNestedValue(spec, "mixedInstancesPolicy.overrides", overrides)
+	}
+	return nil
+}
+
+// healthChecked reports whether a MachineHealthCheck of a cluster selects
+// machines with the given labels
+func (c *Client) healthChecked(ctx context.Context, namespace, clusterName string, machineLabels map[string]string) (bool, error) {
+	checks := &clusterv1.MachineHealthCheckList{}
+	if err := c.ctrlClient.List(ctx, checks, client.InNamespace(namespace)); err != nil {
+		return false, fmt.Errorf("failed to list MachineHealthChecks: %w", err)
+	}
+	for _, check := range checks.Items {
+		if check.Spec.ClusterName != clusterName {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&check.Spec.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(machineLabels)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// spotWarnings summarizes what spot interruptions may disrupt
+func spotWarnings(config *AWSSpotConfiguration, opts AWSSpotOptions) []string {
+	disruption := config.Disruption
+	var warnings []string
+	if config.Kind == awsMachineTemplateGVK.Kind && !opts.DryRun {
+		warnings = append(warnings, fmt.Sprintf("All %d machines of MachineDeployment %s are replaced to apply the new template", disruption.Replicas, disruption.Name))
+	}
+	if !opts.Spot {
+		return warnings
+	}
+
+	nodes := "its nodes"
+	if len(disruption.Nodes) > 0 {
+		nodes = strings.Join(disruption.Nodes, ", ")
+	}
+	warnings = append(warnings, fmt.Sprintf("Spot instances can be reclaimed with two minutes notice; the pods running on %s of %s %s in cluster %s are evicted when that happens",
+		nodes, disruption.Kind, disruption.Name, disruption.Cluster))
+
+	onDemandBase := int64(0)
+	if opts.OnDemandBaseCapacity != nil {
+		onDemandBase = *opts.OnDemandBaseCapacity
+	}
+	allSpot := opts.OnDemandPercentageAboveBase == nil || *opts.OnDemandPercentageAboveBase == 0
+	if onDemandBase == 0 && allSpot {
+		warnings = append(warnings, "No capacity stays on-demand: an interruption of the spot capacity pool can take down all nodes at once, so keep workloads that must stay available on another pool or set an on-demand base capacity")
+	}
+	if disruption.Replicas < 2 {
+		warnings = append(warnings, fmt.Sprintf("%s %s has %d replicas, so an interruption leaves it without nodes", disruption.Kind, disruption.Name, disruption.Replicas))
+	}
+	if !disruption.HealthChecked {
+		warnings = append(warnings, fmt.Sprintf("No MachineHealthCheck covers MachineDeployment %s, so the machines of interrupted instances are not replaced", disruption.Name))
+	}
+	if config.Kind == awsMachinePoolGVK.Kind && (opts.CapacityRebalance == nil || !*opts.CapacityRebalance) {
+		warnings = append(warnings, "Capacity rebalancing is not enabled here; with it the auto scaling group replaces instances at elevated risk of interruption ahead of time")
+	}
+	warnings = append(warnings, "Stateful workloads, single-replica deployments and pods without PodDisruptionBudgets are the most exposed to interruptions")
+	return warnings
+}
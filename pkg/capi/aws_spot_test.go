@@ -0,0 +1,153 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConfigureAWSSpot(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	replicas := int32(3)
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: "workers"},
+		Spec: clusterv1.MachineDeploymentSpec{
+			ClusterName: "prod",
+			Replicas:    &replicas,
+			Template: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{Labels: map[string]string{"pool": "workers"}},
+				Spec: clusterv1.MachineSpec{
+					ClusterName:       "prod",
+					InfrastructureRef: corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta2", Kind: "AWSMachineTemplate", Name: "workers-1"},
+				},
+			},
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: "workers-abc", Labels: map[string]string{
+			clusterv1.ClusterNameLabel: "prod", clusterv1.MachineDeploymentNameLabel: "workers",
+		}},
+		Spec:   clusterv1.MachineSpec{ClusterName: "prod"},
+		Status: clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "ip-10-0-1-1"}},
+	}
+	template := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"template": map[string]any{"spec": map[string]any{"instanceType": "m5.large"}}},
+	}}
+	template.SetGroupVersionKind(awsMachineTemplateGVK)
+	template.SetNamespace("org-a")
+	template.SetName("workers-1")
+
+	pool := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"clusterName": "prod",
+			"replicas":    int64(4),
+			"template": map[string]any{"spec": map[string]any{
+				"infrastructureRef": map[string]any{"kind": "AWSMachinePool", "name": "pool-0"},
+			}},
+		},
+		"status": map[string]any{"nodeRefs": []any{map[string]any{"name": "ip-10-0-2-1"}}},
+	}}
+	pool.SetGroupVersionKind(machinePoolGVK)
+	pool.SetNamespace("org-a")
+	pool.SetName("pool-0")
+	awsPool := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"awsLaunchTemplate": map[string]any{"instanceType": "m5.large"}},
+	}}
+	awsPool.SetGroupVersionKind(awsMachinePoolGVK)
+	awsPool.SetNamespace("org-a")
+	awsPool.SetName("pool-0")
+
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(md, machine, template, pool, awsPool).Build()}
+	ctx := context.Background()
+
+	preview, err := c.ConfigureAWSSpot(ctx, AWSSpotOptions{Namespace: "org-a", MachineDeployment: "workers", Spot: true, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(preview.Changes) != 1 || preview.Changes[0].Field != "spotMarketOptions" {
+		t.Errorf("dry run changes = %+v, want spotMarketOptions", preview.Changes)
+	}
+	if current, _ := c.GetMachineDeployment(ctx, "org-a", "workers"); current.Spec.Template.Spec.InfrastructureRef.Name != "workers-1" {
+		t.Error("dry run changed the MachineDeployment")
+	}
+
+	config, err := c.ConfigureAWSSpot(ctx, AWSSpotOptions{Namespace: "org-a", MachineDeployment: "workers", Spot: true, MaxPrice: "0.05", TemplateName: "workers-spot"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.OldTemplate != "workers-1" || config.Disruption.Replicas != 3 || len(config.Disruption.Nodes) != 1 || config.Disruption.HealthChecked {
+		t.Errorf("config = %+v", config)
+	}
+	if !strings.Contains(strings.Join(config.Warnings, "\n"), "No MachineHealthCheck covers MachineDeployment workers") {
+		t.Errorf("warnings = %v, want the missing MachineHealthCheck", config.Warnings)
+	}
+	updated, err := c.GetMachineDeployment(ctx, "org-a", "workers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Spec.Template.Spec.InfrastructureRef.Name != "workers-spot" {
+		t.Errorf("infrastructure reference = %s, want workers-spot", updated.Spec.Template.Spec.InfrastructureRef.Name)
+	}
+	clone, err := c.getAWSMachineTemplate(ctx, "org-a", "workers-spot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price, _, _ := unstructured.NestedString(clone.Object, "spec", "template", "spec", "spotMarketOptions", "maxPrice"); price != "0.05" {
+		t.Errorf("cloned max price = %q, want 0.05", price)
+	}
+
+	base := int64(1)
+	rebalance := true
+	poolConfig, err := c.ConfigureAWSSpot(ctx, AWSSpotOptions{
+		Namespace: "org-a", MachinePool: "pool-0", Spot: true,
+		OnDemandBaseCapacity: &base, SpotAllocationStrategy: "price-capacity-optimized",
+		InstanceTypes: []string{"m5.large", "m6i.large"}, CapacityRebalance: &rebalance,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if poolConfig.Kind != "AWSMachinePool" || poolConfig.Disruption.Replicas != 4 || len(poolConfig.Disruption.Nodes) != 1 {
+		t.Errorf("pool config = %+v", poolConfig)
+	}
+	updatedPool := &unstructured.Unstructured{}
+	updatedPool.SetGroupVersionKind(awsMachinePoolGVK)
+	if err := c.ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-a", Name: "pool-0"}, updatedPool); err != nil {
+		t.Fatal(err)
+	}
+	distribution, _, _ := unstructured.NestedMap(updatedPool.Object, "spec", "mixedInstancesPolicy", "instancesDistribution")
+	if distribution["onDemandBaseCapacity"] != int64(1) || distribution["onDemandPercentageAboveBaseCapacity"] != int64(0) || distribution["spotAllocationStrategy"] != "price-capacity-optimized" {
+		t.Errorf("instances distribution = %v", distribution)
+	}
+	if overrides, _, _ := unstructured.NestedSlice(updatedPool.Object, "spec", "mixedInstancesPolicy", "overrides"); len(overrides) != 2 {
+		t.Errorf("overrides = %v, want 2 instance types", overrides)
+	}
+	if rebalanced, _, _ := unstructured.NestedBool(updatedPool.Object, "spec", "capacityRebalance"); !rebalanced {
+		t.Error("capacity rebalance was not enabled")
+	}
+
+	invalid := []AWSSpotOptions{
+		{Namespace: "org-a", MachineDeployment: "workers", MachinePool: "pool-0", Spot: true},
+		{Namespace: "org-a", MachinePool: "pool-0", Spot: false, MaxPrice: "0.1"},
+		{Namespace: "org-a", MachinePool: "pool-0", Spot: true, MaxPrice: "0.1"},
+		{Namespace: "org-a", MachineDeployment: "workers", Spot: true, InstanceTypes: []string{"m5.large"}},
+		{Namespace: "org-a", MachinePool: "pool-0", Spot: true, SpotAllocationStrategy: "cheapest"},
+	}
+	for _, opts := range invalid {
+		if _, err := c.ConfigureAWSSpot(ctx, opts); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("ConfigureAWSSpot(%+v) error = %v, want ErrInvalidArgument", opts, err)
+		}
+	}
+}
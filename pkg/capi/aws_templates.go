@@ -0,0 +1,334 @@
+package capi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// awsMachineTemplateGVK is the AWSMachineTemplate of the AWS provider v2
+var awsMachineTemplateGVK = schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta2", Kind: "AWSMachineTemplate"}
+
+// defaultIAMInstanceProfile is the instance profile clusterawsadm creates for
+// worker nodes
+const defaultIAMInstanceProfile = "nodes.cluster-api-provider-aws.sigs.k8s.io"
+
+// AWSMachineTemplateInfo summarizes an AWSMachineTemplate
+type AWSMachineTemplateInfo struct {
+	Namespace    string `json:"namespace"`
+	Name         string `json:"name"`
+	ClusterName  string `json:"clusterName,omitempty"`
+	InstanceType string `json:"instanceType"`
+	// AMI is the image ID; without one the provider looks up an image by
+	// ImageLookupBaseOS and the Kubernetes version
+	AMI                      string   `json:"ami,omitempty"`
+	ImageLookupBaseOS        string   `json:"imageLookupBaseOS,omitempty"`
+	RootVolumeSize           int64    `json:"rootVolumeSize,omitempty"`
+	RootVolumeType           string   `json:"rootVolumeType,omitempty"`
+	IAMInstanceProfile       string   `json:"iamInstanceProfile,omitempty"`
+	SSHKeyName               string   `json:"sshKeyName,omitempty"`
+	AdditionalSecurityGroups []string `json:"additionalSecurityGroups"`
+	// UsedBy lists the MachineDeployments and KubeadmControlPlanes
+	// referencing the template
+	UsedBy []string `json:"usedBy"`
+}
+
+// AWSMachineTemplateOptions are the machine settings of a new or cloned
+// AWSMachineTemplate. Empty fields keep the value of the cloned template.
+type AWSMachineTemplateOptions struct {
+	InstanceType       string
+	AMI                string
+	RootVolumeSize     int64
+	RootVolumeType     string
+	IAMInstanceProfile string
+	SSHKeyName         string
+}
+
+// FieldChange is a field that differs between two objects
+type FieldChange struct {
+	// Field is the dotted path of the field, e.g. rootVolume.size
+	Field string `json:"field"`
+	From  any    `json:"from,omitempty"`
+	To    any    `json:"to,omitempty"`
+}
+
+// ListAWSMachineTemplates lists the AWSMachineTemplates of a namespace with
+// the objects using them
+func (c *Client) ListAWSMachineTemplates(ctx context.Context, namespace string) ([]AWSMachineTemplateInfo, error) {
+	var opts []client.ListOption
+	if namespace != "" {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+	templates, err := c.listUnstructured(ctx, awsMachineTemplateGVK, opts...)
+	if err != nil {
+		return nil, err
+	}
+	usedBy, err := c.infrastructureTemplateUsers(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]AWSMachineTemplateInfo, 0, len(templates))
+	for i := range templates {
+		infos = append(infos, newAWSMachineTemplateInfo(&templates[i], usedBy))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Namespace != infos[j].Namespace {
+			return infos[i].Namespace < infos[j].Namespace
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+// GetAWSMachineTemplate returns an AWSMachineTemplate with the objects using it
+func (c *Client) GetAWSMachineTemplate(ctx context.Context, namespace, name string) (*AWSMachineTemplateInfo, error) {
+	template, err := c.getAWSMachineTemplate(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	usedBy, err := c.infrastructureTemplateUsers(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	info := newAWSMachineTemplateInfo(template, usedBy)
+	return &info, nil
+}
+
+func (c *Client) getAWSMachineTemplate(ctx context.Context, namespace, name string) (*unstructured.Unstructured, error) {
+	return c.getReferenced(ctx, namespace, awsMachineTemplateGVK.GroupVersion().String(), awsMachineTemplateGVK.Kind, name)
+}
+
+// CreateAWSMachineTemplate creates an AWSMachineTemplate for the workers of a
+// cluster. The instance type is required; without an AMI the provider looks
+// up an image for the Kubernetes version of the machines.
+func (c *Client) CreateAWSMachineTemplate(ctx context.Context, namespace, name, clusterName string, opts AWSMachineTemplateOptions) (*AWSMachineTemplateInfo, error) {
+	if opts.InstanceType == "" {
+		return nil, errorf(ErrInvalidArgument, "an instance type is required")
+	}
+	if opts.IAMInstanceProfile == "" {
+		opts.IAMInstanceProfile = defaultIAMInstanceProfile
+	}
+
+	template := &unstructured.Unstructured{Object: map[string]any{}}
+	template.SetGroupVersionKind(awsMachineTemplateGVK)
+	template.SetNamespace(namespace)
+	template.SetName(name)
+	if clusterName != "" {
+		template.SetLabels(map[string]string{clusterv1.ClusterNameLabel: clusterName})
+	}
+	spec := map[string]any{}
+	applyAWSMachineOptions(spec, opts)
+	if err := unstructured.SetNestedMap(template.Object, spec, "spec", "template", "spec"); err != nil {
+		return nil, err
+	}
+
+	if err := c.ctrlClient.Create(ctx, template); err != nil {
+		return nil, fmt.Errorf("failed to create AWSMachineTemplate: %w", resourceError(awsMachineTemplateGVK.Kind, client.ObjectKeyFromObject(template), err))
+	}
+	info := newAWSMachineTemplateInfo(template, nil)
+	return &info, nil
+}
+
+// CloneAWSMachineTemplate copies an AWSMachineTemplate under a new name with
+// the settings of opts. AWSMachineTemplates are immutable, so changing the
+// machines of a MachineDeployment takes a new template its infrastructure
+// reference is pointed to, which rolls out the machines.
+func (c *Client) CloneAWSMachineTemplate(ctx context.Context, namespace, source, name string, opts AWSMachineTemplateOptions) (*AWSMachineTemplateInfo, error) {
+	original, err := c.getAWSMachineTemplate(ctx, namespace, source)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := c.cloneTemplate(ctx, original, name, func(spec map[string]any) error {
+		applyAWSMachineOptions(spec, opts)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	info := newAWSMachineTemplateInfo(template, nil)
+	return &info, nil
+}
+
+// cloneTemplate creates a copy of an infrastructure machine template named
+// name, with its machine spec changed by mutate
+func (c *Client) cloneTemplate(ctx context.Context, original *unstructured.Unstructured, name string, mutate func(spec map[string]any) error) (*unstructured.Unstructured, error) {
+	template := &unstructured.Unstructured{Object: map[string]any{}}
+	template.SetGroupVersionKind(original.GroupVersionKind())
+	template.SetNamespace(original.GetNamespace())
+	template.SetName(name)
+	template.SetLabels(original.GetLabels())
+	spec, _, _ := unstructured.NestedMap(original.Object, "spec")
+	if spec == nil {
+		spec = map[string]any{}
+	}
+	template.Object["spec"] = spec
+	machineSpec, _, _ := unstructured.NestedMap(spec, "template", "spec")
+	if machineSpec == nil {
+		machineSpec = map[string]any{}
+	}
+	if err := mutate(machineSpec); err != nil {
+		return nil, err
+	}
+	if err := unstructured.SetNestedMap(template.Object, machineSpec, "spec", "template", "spec"); err != nil {
+		return nil, err
+	}
+
+	kind := template.GetKind()
+	if err := c.ctrlClient.Create(ctx, template); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", kind, resourceError(kind, client.ObjectKeyFromObject(template), err))
+	}
+	return template, nil
+}
+
+// DiffAWSMachineTemplates compares the machine specs of two AWSMachineTemplates
+func (c *Client) DiffAWSMachineTemplates(ctx context.Context, namespace, from, to string) ([]FieldChange, error) {
+	fromTemplate, err := c.getAWSMachineTemplate(ctx, namespace, from)
+	if err != nil {
+		return nil, err
+	}
+	toTemplate, err := c.getAWSMachineTemplate(ctx, namespace, to)
+	if err != nil {
+		return nil, err
+	}
+	fromSpec, _, _ := unstructured.NestedMap(fromTemplate.Object, "spec", "template", "spec")
+	toSpec, _, _ := unstructured.NestedMap(toTemplate.Object, "spec", "template", "spec")
+	return diffFields(fromSpec, toSpec), nil
+}
+
+// applyAWSMachineOptions sets the non-empty options on an AWSMachine spec
+func applyAWSMachineOptions(spec map[string]any, opts AWSMachineTemplateOptions) {
+	if opts.InstanceType != "" {
+		spec["instanceType"] = opts.InstanceType
+	}
+	if opts.AMI != "" {
+		spec["ami"] = map[string]any{"id": opts.AMI}
+	}
+	if opts.RootVolumeSize > 0 {
+		setNestedValue(spec, "rootVolume.size", opts.RootVolumeSize)
+	}
+	if opts.RootVolumeType != "" {
+		setNestedValue(spec, "rootVolume.type", opts.RootVolumeType)
+	}
+	if opts.IAMInstanceProfile != "" {
+		spec["iamInstanceProfile"] = opts.IAMInstanceProfile
+	}
+	if opts.SSHKeyName != "" {
+		spec["sshKeyName"] = opts.SSHKeyName
+	}
+}
+
+// newAWSMachineTemplateInfo summarizes an AWSMachineTemplate, with its users
+// keyed by template name
+func newAWSMachineTemplateInfo(template *unstructured.Unstructured, usedBy map[string][]string) AWSMachineTemplateInfo {
+	spec, _, _ := unstructured.NestedMap(template.Object, "spec", "template", "spec")
+	info := AWSMachineTemplateInfo{
+		Namespace:                template.GetNamespace(),
+		Name:                     template.GetName(),
+		ClusterName:              template.GetLabels()[clusterv1.ClusterNameLabel],
+		AdditionalSecurityGroups: []string{},
+		UsedBy:                   usedBy[template.GetNamespace()+"/"+template.GetName()],
+	}
+	if info.UsedBy == nil {
+		info.UsedBy = []string{}
+	}
+	info.InstanceType, _, _ = unstructured.NestedString(spec, "instanceType")
+	info.AMI, _, _ = unstructured.NestedString(spec, "ami", "id")
+	info.ImageLookupBaseOS, _, _ = unstructured.NestedString(spec, "imageLookupBaseOS")
+	info.RootVolumeSize, _, _ = unstructured.NestedInt64(spec, "rootVolume", "size")
+	info.RootVolumeType, _, _ = unstructured.NestedString(spec, "rootVolume", "type")
+	info.IAMInstanceProfile, _, _ = unstructured.NestedString(spec, "iamInstanceProfile")
+	info.SSHKeyName, _, _ = unstructured.NestedString(spec, "sshKeyName")
+	groups, _, _ := unstructured.NestedSlice(spec, "additionalSecurityGroups")
+	for _, item := range groups {
+		group, _ := item.(map[string]any)
+		if id, _, _ := unstructured.NestedString(group, "id"); id != "" {
+			info.AdditionalSecurityGroups = append(info.AdditionalSecurityGroups, id)
+		}
+	}
+	return info
+}
+
+// infrastructureTemplateUsers maps infrastructure machine templates, keyed by
+// namespace/name, to the MachineDeployments and KubeadmControlPlanes using
+// them
+func (c *Client) infrastructureTemplateUsers(ctx context.Context, namespace string) (map[string][]string, error) {
+	mds, err := c.ListMachineDeployments(ctx, namespace, "")
+	if err != nil {
+		return nil, err
+	}
+	kcps, err := c.ListKubeadmControlPlanes(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	usedBy := make(map[string][]string)
+	for _, md := range mds.Items {
+		ref := md.Spec.Template.Spec.InfrastructureRef
+		key := md.Namespace + "/" + ref.Name
+		usedBy[key] = append(usedBy[key], "MachineDeployment/"+md.Name)
+	}
+	for _, kcp := range kcps.Items {
+		ref := kcp.Spec.MachineTemplate.InfrastructureRef
+		key := kcp.Namespace + "/" + ref.Name
+		usedBy[key] = append(usedBy[key], "KubeadmControlPlane/"+kcp.Name)
+	}
+	return usedBy, nil
+}
+
+// diffFields compares two objects field by field. Nested maps are compared
+// recursively; lists are compared as a whole.
+func diffFields(from, to map[string]any) []FieldChange {
+	changes := []FieldChange{}
+	var walk func(prefix string, from, to map[string]any)
+	walk = func(prefix string, from, to map[string]any) {
+		keys := make(map[string]bool)
+		for key := range from {
+			keys[key] = true
+		}
+		for key := range to {
+			keys[key] = true
+		}
+		for key := range keys {
+			field := key
+			if prefix != "" {
+				field = prefix + "." + key
+			}
+			fromValue, toValue := from[key], to[key]
+			fromMap, fromIsMap := fromValue.(map[string]any)
+			toMap, toIsMap := toValue.(map[string]any)
+			switch {
+			case fromIsMap && toIsMap:
+				walk(field, fromMap, toMap)
+			case fromIsMap && toValue == nil && len(fromMap) > 0:
+				walk(field, fromMap, map[string]any{})
+			case toIsMap && fromValue == nil && len(toMap) > 0:
+				walk(field, map[string]any{}, toMap)
+			case !reflect.DeepEqual(fromValue, toValue):
+				changes = append(changes, FieldChange{Field: field, From: diffValue(fromValue), To: diffValue(toValue)})
+			}
+		}
+	}
+	walk("", from, to)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// diffValue renders lists and maps as JSON so they read as one value
+func diffValue(value any) any {
+	switch value.(type) {
+	case []any, map[string]any:
+		if data, err := json.Marshal(value); err == nil {
+			return string(data)
+		}
+	}
+	return value
+}
@@ -0,0 +1,108 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAWSMachineTemplates(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	workers := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+			"instanceType":             "m5.large",
+			"ami":                      map[string]any{"id": "ami-1"},
+			"rootVolume":               map[string]any{"size": int64(50), "type": "gp2"},
+			"iamInstanceProfile":       "nodes.cluster-api-provider-aws.sigs.k8s.io",
+			"sshKeyName":               "ops",
+			"additionalSecurityGroups": []any{map[string]any{"id": "sg-extra"}},
+		}}},
+	}}
+	workers.SetGroupVersionKind(awsMachineTemplateGVK)
+	workers.SetNamespace("org-a")
+	workers.SetName("prod-workers")
+	workers.SetLabels(map[string]string{clusterv1.ClusterNameLabel: "prod"})
+
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: "prod-md-0"},
+		Spec: clusterv1.MachineDeploymentSpec{Template: clusterv1.MachineTemplateSpec{Spec: clusterv1.MachineSpec{
+			InfrastructureRef: corev1.ObjectReference{Kind: "AWSMachineTemplate", Name: "prod-workers"},
+		}}},
+	}
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: "prod-cp"},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{MachineTemplate: controlplanev1.KubeadmControlPlaneMachineTemplate{
+			InfrastructureRef: corev1.ObjectReference{Kind: "AWSMachineTemplate", Name: "prod-cp"},
+		}},
+	}
+
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(workers, md, kcp).Build()}
+	ctx := context.Background()
+
+	template, err := c.GetAWSMachineTemplate(ctx, "org-a", "prod-workers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if template.InstanceType != "m5.large" || template.AMI != "ami-1" || template.RootVolumeSize != 50 || template.ClusterName != "prod" {
+		t.Errorf("template = %+v", template)
+	}
+	if len(template.AdditionalSecurityGroups) != 1 || len(template.UsedBy) != 1 || template.UsedBy[0] != "MachineDeployment/prod-md-0" {
+		t.Errorf("security groups and users = %v, %v", template.AdditionalSecurityGroups, template.UsedBy)
+	}
+
+	created, err := c.CreateAWSMachineTemplate(ctx, "org-a", "prod-cp", "prod", AWSMachineTemplateOptions{InstanceType: "m5.xlarge", RootVolumeSize: 80})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.IAMInstanceProfile != defaultIAMInstanceProfile || created.RootVolumeSize != 80 {
+		t.Errorf("created template = %+v, want the default instance profile", created)
+	}
+	if _, err := c.CreateAWSMachineTemplate(ctx, "org-a", "prod-cp", "prod", AWSMachineTemplateOptions{InstanceType: "m5.xlarge"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("CreateAWSMachineTemplate() of an existing template error = %v, want ErrAlreadyExists", err)
+	}
+	if _, err := c.CreateAWSMachineTemplate(ctx, "org-a", "empty", "", AWSMachineTemplateOptions{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("CreateAWSMachineTemplate() without an instance type error = %v, want ErrInvalidArgument", err)
+	}
+
+	clone, err := c.CloneAWSMachineTemplate(ctx, "org-a", "prod-workers", "prod-workers-2", AWSMachineTemplateOptions{InstanceType: "m6i.large", RootVolumeSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clone.InstanceType != "m6i.large" || clone.AMI != "ami-1" || clone.RootVolumeSize != 100 || clone.RootVolumeType != "gp2" || clone.ClusterName != "prod" {
+		t.Errorf("clone = %+v, want the overrides on top of the source", clone)
+	}
+	if _, err := c.CloneAWSMachineTemplate(ctx, "org-a", "missing", "copy", AWSMachineTemplateOptions{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("CloneAWSMachineTemplate() of a missing template error = %v, want ErrNotFound", err)
+	}
+
+	changes, err := c.DiffAWSMachineTemplates(ctx, "org-a", "prod-workers", "prod-workers-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Field != "instanceType" || changes[1].Field != "rootVolume.size" || changes[0].To != "m6i.large" {
+		t.Errorf("changes = %+v, want instanceType and rootVolume.size", changes)
+	}
+
+	templates, err := c.ListAWSMachineTemplates(ctx, "org-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 3 || templates[0].Name != "prod-cp" || len(templates[0].UsedBy) != 1 || templates[0].UsedBy[0] != "KubeadmControlPlane/prod-cp" {
+		t.Errorf("templates = %+v", templates)
+	}
+}
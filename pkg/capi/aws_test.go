@@ -0,0 +1,124 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetAWSCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	newCluster := func(name, infraKind, cpKind string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: name},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta2", Kind: infraKind, Name: name},
+				ControlPlaneRef:   &corev1.ObjectReference{APIVersion: "controlplane.cluster.x-k8s.io/v1beta2", Kind: cpKind, Name: name + "-cp"},
+			},
+		}
+	}
+
+	awsCluster := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"region":               "eu-west-1",
+			"controlPlaneEndpoint": map[string]any{"host": "api.prod.example.com", "port": int64(443)},
+			"network": map[string]any{
+				"vpc": map[string]any{"id": "vpc-1", "cidrBlock": "10.0.0.0/16"},
+				"subnets": []any{
+					map[string]any{"resourceID": "subnet-a", "availabilityZone": "eu-west-1a", "cidrBlock": "10.0.0.0/20", "isPublic": true, "natGatewayId": "nat-1"},
+					map[string]any{"id": "subnet-b", "availabilityZone": "eu-west-1a", "cidrBlock": "10.0.16.0/20"},
+				},
+			},
+			"bastion": map[string]any{"enabled": true, "instanceType": "t3.micro"},
+		},
+		"status": map[string]any{
+			"ready": true,
+			"networkStatus": map[string]any{
+				"securityGroups": map[string]any{
+					"node":         map[string]any{"id": "sg-2", "name": "prod-node"},
+					"controlplane": map[string]any{"id": "sg-1", "name": "prod-controlplane"},
+				},
+				"apiServerElb": map[string]any{"name": "prod-apiserver", "dnsName": "prod.elb.amazonaws.com", "scheme": "internet-facing", "loadBalancerType": "nlb"},
+			},
+			"bastion":        map[string]any{"id": "i-123", "instanceState": "running", "publicIp": "1.2.3.4"},
+			"failureDomains": map[string]any{"eu-west-1a": map[string]any{"controlPlane": true}, "eu-west-1b": map[string]any{}},
+		},
+	}}
+	awsCluster.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta2")
+	awsCluster.SetKind("AWSCluster")
+	awsCluster.SetNamespace("org-a")
+	awsCluster.SetName("prod")
+
+	managedCluster := &unstructured.Unstructured{Object: map[string]any{
+		"status": map[string]any{"ready": true, "failureDomains": map[string]any{"eu-west-1a": map[string]any{"controlPlane": true}}},
+	}}
+	managedCluster.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta2")
+	managedCluster.SetKind("AWSManagedCluster")
+	managedCluster.SetNamespace("org-a")
+	managedCluster.SetName("eks")
+
+	managedControlPlane := &unstructured.Unstructured{Object: map[string]any{
+		"spec":   map[string]any{"region": "us-east-1", "network": map[string]any{"vpc": map[string]any{"id": "vpc-eks"}}},
+		"status": map[string]any{"ready": false},
+	}}
+	managedControlPlane.SetAPIVersion("controlplane.cluster.x-k8s.io/v1beta2")
+	managedControlPlane.SetKind("AWSManagedControlPlane")
+	managedControlPlane.SetNamespace("org-a")
+	managedControlPlane.SetName("eks-cp")
+
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newCluster("prod", "AWSCluster", "KubeadmControlPlane"), awsCluster,
+		newCluster("eks", "AWSManagedCluster", "AWSManagedControlPlane"), managedCluster, managedControlPlane,
+		newCluster("azure", "AzureCluster", "KubeadmControlPlane"),
+	).Build()}
+	ctx := context.Background()
+
+	info, err := c.GetAWSCluster(ctx, "org-a", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Region != "eu-west-1" || !info.Ready || info.VPC.ID != "vpc-1" || info.ControlPlaneEndpoint != "api.prod.example.com:443" {
+		t.Errorf("info = %+v", info)
+	}
+	if len(info.Subnets) != 2 || info.Subnets[0].ID != "subnet-a" || !info.Subnets[0].Public || info.Subnets[1].ID != "subnet-b" {
+		t.Errorf("subnets = %+v", info.Subnets)
+	}
+	if len(info.SecurityGroups) != 2 || info.SecurityGroups[0].Role != "controlplane" || info.SecurityGroups[0].ID != "sg-1" {
+		t.Errorf("security groups = %+v", info.SecurityGroups)
+	}
+	if len(info.LoadBalancers) != 1 || info.LoadBalancers[0].DNSName != "prod.elb.amazonaws.com" {
+		t.Errorf("load balancers = %+v", info.LoadBalancers)
+	}
+	if !info.Bastion.Enabled || info.Bastion.InstanceID != "i-123" || info.Bastion.State != "running" {
+		t.Errorf("bastion = %+v", info.Bastion)
+	}
+	if len(info.FailureDomains) != 2 || !info.FailureDomains[0].ControlPlane || info.FailureDomains[1].ControlPlane {
+		t.Errorf("failure domains = %+v", info.FailureDomains)
+	}
+
+	eks, err := c.GetAWSCluster(ctx, "org-a", "eks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eks.Kind != "AWSManagedCluster" || eks.ControlPlane != "eks-cp" || eks.Region != "us-east-1" || eks.VPC.ID != "vpc-eks" {
+		t.Errorf("eks info = %+v", eks)
+	}
+	if !eks.Ready || len(eks.FailureDomains) != 1 {
+		t.Errorf("eks readiness and failure domains = %v, %+v, want those of the AWSManagedCluster", eks.Ready, eks.FailureDomains)
+	}
+
+	if _, err := c.GetAWSCluster(ctx, "org-a", "azure"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GetAWSCluster() of an Azure cluster error = %v, want ErrInvalidArgument", err)
+	}
+}
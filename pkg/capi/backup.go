@@ -0,0 +1,148 @@
+package capi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// BackupClusterOptions contains options for backing up a cluster
+type BackupClusterOptions struct {
+	Namespace      string
+	Name           string
+	IncludeSecrets bool
+	OutputFormat   string // yaml or json
+}
+
+// BackupCluster renders the objects of a cluster as a backup bundle: the
+// objects CloneCluster copies under their own names, without status and
+// server-set metadata, and with IncludeSecrets the Secrets of the cluster
+// (kubeconfig, certificates) and those its objects reference. YAML bundles
+// are multi-document files with a header comment, JSON bundles a v1 List.
+func (c *Client) BackupCluster(ctx context.Context, opts BackupClusterOptions) (string, error) {
+	if opts.OutputFormat == "" {
+		opts.OutputFormat = "yaml"
+	}
+	if opts.OutputFormat != "yaml" && opts.OutputFormat != "json" {
+		return "", errorf(ErrInvalidArgument, "unknown output format %q, use yaml or json", opts.OutputFormat)
+	}
+
+	key := client.ObjectKey{Namespace: opts.Namespace, Name: opts.Name}
+	source := &unstructured.Unstructured{}
+	source.SetGroupVersionKind(clusterv1.GroupVersion.WithKind("Cluster"))
+	if err := c.ctrlClient.Get(ctx, key, source); err != nil {
+		return "", fmt.Errorf("failed to get cluster: %w", resourceError("Cluster", key, err))
+	}
+	// A copy in place keeps the names of the objects
+	cloner := &clusterCloner{
+		client:  c,
+		opts:    CloneClusterOptions{SourceNamespace: opts.Namespace, SourceName: opts.Name, Namespace: opts.Namespace, Name: opts.Name},
+		sources: map[*unstructured.Unstructured]string{},
+		names:   map[string]string{},
+	}
+	if err := cloner.cloneCluster(ctx, source, &ClusterClone{}); err != nil {
+		return "", err
+	}
+	objects := cloner.objects
+	if opts.IncludeSecrets {
+		secrets, err := c.clusterSecrets(ctx, opts.Namespace, opts.Name, secretReferences(cloner.objects))
+		if err != nil {
+			return "", err
+		}
+		objects = append(secrets, objects...)
+	}
+
+	if opts.OutputFormat == "json" {
+		items := make([]any, 0, len(objects))
+		for _, obj := range objects {
+			items = append(items, obj.Object)
+		}
+		data, err := json.MarshalIndent(map[string]any{"apiVersion": "v1", "kind": "List", "items": items}, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to render the backup: %w", err)
+		}
+		return string(data), nil
+	}
+
+	var backup strings.Builder
+	backup.WriteString(fmt.Sprintf("# Cluster backup of %s/%s\n", opts.Namespace, opts.Name))
+	backup.WriteString(fmt.Sprintf("# Created: %s\n", time.Now().UTC().Format(time.RFC3339)))
+	if opts.IncludeSecrets {
+		backup.WriteString(fmt.Sprintf("# Objects: %d, with Secrets\n", len(objects)))
+	} else {
+		backup.WriteString(fmt.Sprintf("# Objects: %d, without Secrets\n", len(objects)))
+	}
+	for _, obj := range objects {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return "", fmt.Errorf("failed to render %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		backup.WriteString("---\n")
+		backup.Write(data)
+	}
+	return backup.String(), nil
+}
+
+// clusterSecrets returns the Secrets labeled with a cluster, such as its
+// kubeconfig and certificate authorities, and the referenced Secrets, as
+// objects without server-set metadata. Missing referenced Secrets are
+// skipped.
+func (c *Client) clusterSecrets(ctx context.Context, namespace, cluster string, references []SecretReference) ([]*unstructured.Unstructured, error) {
+	list := &corev1.SecretList{}
+	if err := c.ctrlClient.List(ctx, list, client.InNamespace(namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: cluster}); err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	secrets := map[string]*corev1.Secret{}
+	for i := range list.Items {
+		secrets[list.Items[i].Name] = &list.Items[i]
+	}
+	for _, reference := range references {
+		if _, ok := secrets[reference.Name]; ok {
+			continue
+		}
+		key := client.ObjectKey{Namespace: namespace, Name: reference.Name}
+		secret := &corev1.Secret{}
+		if err := c.ctrlClient.Get(ctx, key, secret); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get secret: %w", resourceError("Secret", key, err))
+		}
+		secrets[reference.Name] = secret
+	}
+
+	objects := make([]*unstructured.Unstructured, 0, len(secrets))
+	for _, name := range sortedKeys(secrets) {
+		secret := secrets[name]
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"type":       string(secret.Type),
+		}}
+		data := map[string]any{}
+		for key, value := range secret.Data {
+			data[key] = base64.StdEncoding.EncodeToString(value)
+		}
+		obj.Object["data"] = data
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		obj.SetLabels(secret.Labels)
+		annotations := secret.Annotations
+		for _, annotation := range cloneDroppedAnnotations {
+			delete(annotations, annotation)
+		}
+		obj.SetAnnotations(annotations)
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
@@ -0,0 +1,88 @@
+package capi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestBackupCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod", ResourceVersion: "42"},
+		Spec: clusterv1.ClusterSpec{
+			InfrastructureRef: &corev1.ObjectReference{APIVersion: dockerClusterGVK.GroupVersion().String(), Kind: "DockerCluster", Name: "prod"},
+		},
+		Status: clusterv1.ClusterStatus{Phase: "Provisioned"},
+	}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		cluster,
+		newUnstructured(dockerClusterGVK, "org-acme", "prod", nil),
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-kubeconfig", Labels: map[string]string{clusterv1.ClusterNameLabel: "prod"}},
+			Data:       map[string][]byte{"value": []byte("kubeconfig")},
+		},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "other-kubeconfig", Labels: map[string]string{clusterv1.ClusterNameLabel: "other"}}},
+	).Build()}
+	ctx := context.Background()
+
+	bundle, err := c.BackupCluster(ctx, BackupClusterOptions{Namespace: "org-acme", Name: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(bundle, "# Cluster backup of org-acme/prod\n") || strings.Count(bundle, "---\n") != 2 {
+		t.Errorf("bundle = %s, want a header and two documents", bundle)
+	}
+	for _, unwanted := range []string{"kind: Secret", "resourceVersion:", "Provisioned"} {
+		if strings.Contains(bundle, unwanted) {
+			t.Errorf("bundle contains %q:\n%s", unwanted, bundle)
+		}
+	}
+
+	bundle, err = c.BackupCluster(ctx, BackupClusterOptions{Namespace: "org-acme", Name: "prod", IncludeSecrets: true, OutputFormat: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := struct {
+		Kind  string
+		Items []struct {
+			Kind     string
+			Metadata struct{ Name string }
+			Data     map[string]string
+		}
+	}{}
+	if err := json.Unmarshal([]byte(bundle), &list); err != nil {
+		t.Fatal(err)
+	}
+	var objects []string
+	for _, item := range list.Items {
+		objects = append(objects, item.Kind+"/"+item.Metadata.Name)
+	}
+	if list.Kind != "List" || strings.Join(objects, ",") != "Secret/prod-kubeconfig,DockerCluster/prod,Cluster/prod" {
+		t.Errorf("objects = %v, want the secret of the cluster first", objects)
+	}
+	if list.Items[0].Data["value"] != "a3ViZWNvbmZpZw==" {
+		t.Errorf("secret data = %v, want base64", list.Items[0].Data)
+	}
+
+	if _, err := c.BackupCluster(ctx, BackupClusterOptions{Namespace: "org-acme", Name: "prod", OutputFormat: "xml"}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("BackupCluster(xml) error = %v, want ErrInvalidArgument", err)
+	}
+	if _, err := c.BackupCluster(ctx, BackupClusterOptions{Namespace: "org-acme", Name: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("BackupCluster(missing) error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,311 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nodeMetricsGVK is the list of node usage served by metrics-server
+var nodeMetricsGVK = schema.GroupVersionKind{Group: "metrics.k8s.io", Version: "v1beta1", Kind: "NodeMetricsList"}
+
+// pressureConditions are the node conditions reporting resource pressure
+var pressureConditions = []corev1.NodeConditionType{
+	corev1.NodeMemoryPressure,
+	corev1.NodeDiskPressure,
+	corev1.NodePIDPressure,
+}
+
+// ClusterCapacity aggregates the allocatable, requested and used resources of
+// the nodes of a workload cluster. CPU is in millicores, memory in bytes.
+type ClusterCapacity struct {
+	Nodes []NodeCapacity   `json:"nodes"`
+	Total ResourceCapacity `json:"total"`
+	// MetricsAvailable reports whether metrics-server serves the usage of
+	// the nodes
+	MetricsAvailable bool   `json:"metricsAvailable"`
+	MetricsError     string `json:"metricsError,omitempty"`
+	// PressureNodes is the number of nodes under memory, disk or PID
+	// pressure
+	PressureNodes int `json:"pressureNodes"`
+}
+
+// ResourceCapacity is the CPU, memory and pods of one node or all nodes
+type ResourceCapacity struct {
+	AllocatableCPU    int64 `json:"allocatableCPU"`
+	AllocatableMemory int64 `json:"allocatableMemory"`
+	AllocatablePods   int64 `json:"allocatablePods"`
+	RequestedCPU      int64 `json:"requestedCPU"`
+	RequestedMemory   int64 `json:"requestedMemory"`
+	Pods              int64 `json:"pods"`
+	// UsedCPU and UsedMemory come from metrics-server, when available
+	UsedCPU    *int64 `json:"usedCPU,omitempty"`
+	UsedMemory *int64 `json:"usedMemory,omitempty"`
+}
+
+// NodeCapacity is the capacity of a workload node
+type NodeCapacity struct {
+	Name          string `json:"name"`
+	Ready         bool   `json:"ready"`
+	Unschedulable bool   `json:"unschedulable,omitempty"`
+	ResourceCapacity
+	// Pressure lists the pressure conditions of the node
+	Pressure []string `json:"pressure,omitempty"`
+}
+
+// GetClusterCapacity sums the allocatable resources of the nodes of a workload
+// cluster and the requests of the pods scheduled on them, adding the usage
+// reported by metrics-server when it is installed, and flags the nodes under
+// memory, disk or PID pressure
+func (c *Client) GetClusterCapacity(ctx context.Context, namespace, clusterName string) (*ClusterCapacity, error) {
+	kubeconfig, err := c.GetKubeconfig(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	clientset, err := c.workloadClientset(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", workloadError("Node", client.ObjectKey{}, err))
+	}
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", workloadError("Pod", client.ObjectKey{}, err))
+	}
+
+	capacity := &ClusterCapacity{Nodes: []NodeCapacity{}}
+	index := map[string]int{}
+	for _, node := range nodes.Items {
+		allocatable := node.Status.Allocatable
+		nodeCapacity := NodeCapacity{
+			Name:          node.Name,
+			Ready:         nodeReady(&node),
+			Unschedulable: node.Spec.Unschedulable,
+			ResourceCapacity: ResourceCapacity{
+				AllocatableCPU:    allocatable.Cpu().MilliValue(),
+				AllocatableMemory: allocatable.Memory().Value(),
+				AllocatablePods:   allocatable.Pods().Value(),
+			},
+		}
+		for _, conditionType := range pressureConditions {
+			for _, condition := range node.Status.Conditions {
+				if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+					nodeCapacity.Pressure = append(nodeCapacity.Pressure, string(conditionType))
+				}
+			}
+		}
+		if len(nodeCapacity.Pressure) > 0 {
+			capacity.PressureNodes++
+		}
+		index[node.Name] = len(capacity.Nodes)
+		capacity.Nodes = append(capacity.Nodes, nodeCapacity)
+	}
+
+	for _, pod := range pods.Items {
+		i, ok := index[pod.Spec.NodeName]
+		if !ok || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		requests := podRequests(&pod)
+		capacity.Nodes[i].RequestedCPU += requests.Cpu().MilliValue()
+		capacity.Nodes[i].RequestedMemory += requests.Memory().Value()
+		capacity.Nodes[i].Pods++
+	}
+
+	usage, err := c.nodeUsage(ctx, kubeconfig)
+	if err != nil {
+		capacity.MetricsError = err.Error()
+	} else if usage != nil {
+		capacity.MetricsAvailable = true
+		for name, used := range usage {
+			if i, ok := index[name]; ok {
+				cpu, memory := used.Cpu().MilliValue(), used.Memory().Value()
+				capacity.Nodes[i].UsedCPU, capacity.Nodes[i].UsedMemory = &cpu, &memory
+			}
+		}
+	}
+
+	total := &capacity.Total
+	for _, node := range capacity.Nodes {
+		total.AllocatableCPU += node.AllocatableCPU
+		total.AllocatableMemory += node.AllocatableMemory
+		total.AllocatablePods += node.AllocatablePods
+		total.RequestedCPU += node.RequestedCPU
+		total.RequestedMemory += node.RequestedMemory
+		total.Pods += node.Pods
+		if node.UsedCPU != nil {
+			total.UsedCPU = addInt64(total.UsedCPU, *node.UsedCPU)
+			total.UsedMemory = addInt64(total.UsedMemory, *node.UsedMemory)
+		}
+	}
+	sort.Slice(capacity.Nodes, func(i, j int) bool {
+		return capacity.Nodes[i].Name < capacity.Nodes[j].Name
+	})
+	return capacity, nil
+}
+
+// podRequests returns the resources the scheduler reserves for a pod: the
+// larger of the sum of its container requests and the request of each init
+// container, plus the pod overhead
+func podRequests(pod *corev1.Pod) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range pod.Spec.Containers {
+		addResources(requests, container.Resources.Requests)
+	}
+	for _, container := range pod.Spec.InitContainers {
+		for name, quantity := range container.Resources.Requests {
+			if current, ok := requests[name]; !ok || quantity.Cmp(current) > 0 {
+				requests[name] = quantity.DeepCopy()
+			}
+		}
+	}
+	addResources(requests, pod.Spec.Overhead)
+	return requests
+}
+
+// addResources adds the quantities of a resource list to another
+func addResources(list, add corev1.ResourceList) {
+	for name, quantity := range add {
+		current := list[name]
+		current.Add(quantity)
+		list[name] = current
+	}
+}
+
+// addInt64 adds a value to an optional total
+func addInt64(total *int64, value int64) *int64 {
+	if total == nil {
+		return &value
+	}
+	sum := *total + value
+	return &sum
+}
+
+// nodeUsage returns the CPU and memory used by each node of a workload cluster
+// according to metrics-server, or nil when metrics-server is not installed
+func (c *Client) nodeUsage(ctx context.Context, kubeconfig string) (map[string]corev1.ResourceList, error) {
+	workload, err := c.workloadClient(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(nodeMetricsGVK)
+	if err := workload.List(ctx, list); err != nil {
+		if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) || apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get node metrics: %w", err)
+	}
+	usage := map[string]corev1.ResourceList{}
+	for _, item := range list.Items {
+		values, _, _ := unstructured.NestedStringMap(item.Object, "usage")
+		used := corev1.ResourceList{}
+		for name, value := range values {
+			if quantity, err := resource.ParseQuantity(value); err == nil {
+				used[corev1.ResourceName(name)] = quantity
+			}
+		}
+		usage[item.GetName()] = used
+	}
+	return usage, nil
+}
+
+// NodeUsage is the live usage of a workload node, with the Machine behind it
+type NodeUsage struct {
+	Node              string  `json:"node"`
+	Machine           string  `json:"machine,omitempty"`
+	MachineDeployment string  `json:"machineDeployment,omitempty"`
+	ControlPlane      bool    `json:"controlPlane,omitempty"`
+	CPU               int64   `json:"cpu"`
+	CPUPercent        float64 `json:"cpuPercent"`
+	Memory            int64   `json:"memory"`
+	MemoryPercent     float64 `json:"memoryPercent"`
+}
+
+// TopNodes returns the CPU (in millicores) and memory (in bytes) used by the
+// nodes of a workload cluster according to metrics-server, as a percentage
+// of their allocatable resources like kubectl top nodes, correlated to their
+// Machines. Nodes are sorted by decreasing CPU usage, or memory usage when
+// sortBy is "memory".
+func (c *Client) TopNodes(ctx context.Context, namespace, clusterName, sortBy string) ([]NodeUsage, error) {
+	if sortBy != "" && sortBy != "cpu" && sortBy != "memory" {
+		return nil, errorf(ErrInvalidArgument, "invalid sort %q, supported sorts: cpu, memory", sortBy)
+	}
+	machines, err := c.ListMachines(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	kubeconfig, err := c.GetKubeconfig(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	usage, err := c.nodeUsage(ctx, kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	if usage == nil {
+		return nil, errorf(ErrPreconditionFailed, "metrics-server is not installed in cluster %s/%s", namespace, clusterName)
+	}
+	clientset, err := c.workloadClientset(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", workloadError("Node", client.ObjectKey{}, err))
+	}
+
+	nodeMachines := map[string]int{}
+	for i, machine := range machines.Items {
+		if machine.Status.NodeRef != nil {
+			nodeMachines[machine.Status.NodeRef.Name] = i
+		}
+	}
+	percent := func(used, allocatable int64) float64 {
+		if allocatable == 0 {
+			return 0
+		}
+		return float64(used) * 100 / float64(allocatable)
+	}
+	top := []NodeUsage{}
+	for _, node := range nodes.Items {
+		used, ok := usage[node.Name]
+		if !ok {
+			continue
+		}
+		nodeUsage := NodeUsage{
+			Node:   node.Name,
+			CPU:    used.Cpu().MilliValue(),
+			Memory: used.Memory().Value(),
+		}
+		nodeUsage.CPUPercent = percent(nodeUsage.CPU, node.Status.Allocatable.Cpu().MilliValue())
+		nodeUsage.MemoryPercent = percent(nodeUsage.Memory, node.Status.Allocatable.Memory().Value())
+		if i, ok := nodeMachines[node.Name]; ok {
+			machine := &machines.Items[i]
+			nodeUsage.Machine = machine.Name
+			nodeUsage.MachineDeployment = machine.Labels[clusterv1.MachineDeploymentNameLabel]
+			_, nodeUsage.ControlPlane = machine.Labels[clusterv1.MachineControlPlaneLabel]
+		}
+		top = append(top, nodeUsage)
+	}
+	sort.SliceStable(top, func(i, j int) bool {
+		if sortBy == "memory" {
+			return top[i].Memory > top[j].Memory
+		}
+		return top[i].CPU > top[j].CPU
+	})
+	return top, nil
+}
@@ -0,0 +1,224 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestGetClusterCapacity(t *testing.T) {
+	node := func(name, cpu, memory string, conditions ...corev1.NodeConditionType) *corev1.Node {
+		n := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+					corev1.ResourcePods:   resource.MustParse("110"),
+				},
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+		for _, condition := range conditions {
+			n.Status.Conditions = append(n.Status.Conditions, corev1.NodeCondition{Type: condition, Status: corev1.ConditionTrue})
+		}
+		return n
+	}
+	requests := func(cpu, memory string) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}}
+	}
+	pod := func(name, node string, phase corev1.PodPhase, containers ...corev1.ResourceRequirements) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+			Spec:       corev1.PodSpec{NodeName: node},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+		for _, resources := range containers {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Resources: resources})
+		}
+		return p
+	}
+	// The init container requests more memory than the containers together
+	migrated := pod("migrated", "node-2", corev1.PodRunning, requests("100m", "128Mi"))
+	migrated.Spec.InitContainers = []corev1.Container{{Resources: requests("50m", "1Gi")}}
+
+	workload := k8sfake.NewClientset(
+		node("node-1", "4", "16Gi"),
+		node("node-2", "2", "8Gi", corev1.NodeMemoryPressure, corev1.NodeDiskPressure),
+		pod("web", "node-1", corev1.PodRunning, requests("500m", "1Gi"), requests("250m", "512Mi")),
+		pod("job", "node-1", corev1.PodSucceeded, requests("2", "4Gi")),
+		pod("pending", "", corev1.PodPending, requests("1", "1Gi")),
+		migrated,
+	)
+	nodeMetrics := func(name, cpu, memory string) client.Object {
+		metrics := &unstructured.Unstructured{Object: map[string]any{
+			"usage": map[string]any{"cpu": cpu, "memory": memory},
+		}}
+		metrics.SetAPIVersion("metrics.k8s.io/v1beta1")
+		metrics.SetKind("NodeMetrics")
+		metrics.SetName(name)
+		return metrics
+	}
+	newClient := func(metricsServer bool, metrics ...client.Object) *Client {
+		return &Client{
+			k8sClient: k8sfake.NewClientset(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-kubeconfig"},
+				Data:       map[string][]byte{"value": []byte("kubeconfig")},
+			}),
+			newWorkloadClientset: func(kubeconfig string) (kubernetes.Interface, error) {
+				return workload, nil
+			},
+			newWorkloadClient: func(kubeconfig string) (client.Client, error) {
+				builder := fake.NewClientBuilder().WithObjects(metrics...)
+				if !metricsServer {
+					builder = builder.WithInterceptorFuncs(interceptor.Funcs{
+						List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+							return &meta.NoKindMatchError{GroupKind: nodeMetricsGVK.GroupKind()}
+						},
+					})
+				}
+				return builder.Build(), nil
+			},
+		}
+	}
+
+	capacity, err := newClient(true, nodeMetrics("node-1", "1500m", "6Gi"), nodeMetrics("node-2", "250m", "7Gi")).GetClusterCapacity(context.Background(), "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(capacity.Nodes) != 2 || capacity.PressureNodes != 1 || !capacity.MetricsAvailable {
+		t.Fatalf("capacity = %+v, want 2 nodes with metrics and 1 under pressure", capacity)
+	}
+	node1, node2 := capacity.Nodes[0], capacity.Nodes[1]
+	if node1.RequestedCPU != 750 || node1.RequestedMemory != 1536<<20 || node1.Pods != 1 || len(node1.Pressure) != 0 {
+		t.Errorf("node-1 = %+v, want 750m and 1.5Gi requested by 1 pod", node1)
+	}
+	if node2.RequestedCPU != 100 || node2.RequestedMemory != 1<<30 || len(node2.Pressure) != 2 {
+		t.Errorf("node-2 = %+v, want the init container memory and 2 pressures", node2)
+	}
+	total := capacity.Total
+	if total.AllocatableCPU != 6000 || total.AllocatableMemory != 24<<30 || total.RequestedCPU != 850 || total.Pods != 2 {
+		t.Errorf("total = %+v, want 6 CPUs and 24Gi allocatable, 850m requested", total)
+	}
+	if total.UsedCPU == nil || *total.UsedCPU != 1750 || *total.UsedMemory != 13<<30 {
+		t.Errorf("total usage = %v/%v, want 1750m and 13Gi", total.UsedCPU, total.UsedMemory)
+	}
+
+	capacity, err = newClient(false).GetClusterCapacity(context.Background(), "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capacity.MetricsAvailable || capacity.Total.UsedCPU != nil || capacity.Nodes[0].UsedCPU != nil {
+		t.Errorf("capacity = %+v, want no usage without metrics-server", capacity)
+	}
+}
+
+func TestTopNodes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	machine := func(name string, labels map[string]string, node string) *clusterv1.Machine {
+		labels[clusterv1.ClusterNameLabel] = "prod"
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: name, Labels: labels},
+			Spec:       clusterv1.MachineSpec{ClusterName: "prod"},
+			Status:     clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: node}},
+		}
+	}
+	node := func(name string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			}},
+		}
+	}
+	nodeMetrics := func(name, cpu, memory string) client.Object {
+		metrics := &unstructured.Unstructured{Object: map[string]any{
+			"usage": map[string]any{"cpu": cpu, "memory": memory},
+		}}
+		metrics.SetAPIVersion("metrics.k8s.io/v1beta1")
+		metrics.SetKind("NodeMetrics")
+		metrics.SetName(name)
+		return metrics
+	}
+	metrics := []client.Object{
+		nodeMetrics("cp-1", "500m", "6Gi"),
+		nodeMetrics("worker-1", "1500m", "2Gi"),
+		nodeMetrics("unmanaged", "100m", "1Gi"),
+	}
+	c := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			machine("prod-cp-1", map[string]string{clusterv1.MachineControlPlaneLabel: ""}, "cp-1"),
+			machine("prod-md-0-1", map[string]string{clusterv1.MachineDeploymentNameLabel: "prod-md-0"}, "worker-1"),
+		).Build(),
+		k8sClient: k8sfake.NewClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-kubeconfig"},
+			Data:       map[string][]byte{"value": []byte("kubeconfig")},
+		}),
+		newWorkloadClientset: func(kubeconfig string) (kubernetes.Interface, error) {
+			return k8sfake.NewClientset(node("cp-1"), node("worker-1"), node("unmanaged"), node("starting")), nil
+		},
+		newWorkloadClient: func(kubeconfig string) (client.Client, error) {
+			return fake.NewClientBuilder().WithObjects(metrics...).Build(), nil
+		},
+	}
+	ctx := context.Background()
+
+	top, err := c.TopNodes(ctx, "org-acme", "prod", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top) != 3 || top[0].Node != "worker-1" || top[1].Node != "cp-1" || top[2].Node != "unmanaged" {
+		t.Fatalf("top = %+v, want worker-1, cp-1 and unmanaged by CPU", top)
+	}
+	if worker := top[0]; worker.Machine != "prod-md-0-1" || worker.MachineDeployment != "prod-md-0" || worker.CPU != 1500 || worker.CPUPercent != 75 {
+		t.Errorf("worker-1 = %+v, want machine prod-md-0-1 at 75%% CPU", worker)
+	}
+	if cp := top[1]; !cp.ControlPlane || cp.MemoryPercent != 75 {
+		t.Errorf("cp-1 = %+v, want a control plane node at 75%% memory", cp)
+	}
+	if top[2].Machine != "" {
+		t.Errorf("unmanaged = %+v, want no machine", top[2])
+	}
+
+	top, err = c.TopNodes(ctx, "org-acme", "prod", "memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if top[0].Node != "cp-1" {
+		t.Errorf("top by memory = %+v, want cp-1 first", top)
+	}
+
+	if _, err := c.TopNodes(ctx, "org-acme", "prod", "disk"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("TopNodes(disk) err = %v, want an invalid argument", err)
+	}
+	c.newWorkloadClient = func(kubeconfig string) (client.Client, error) {
+		return fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				return &meta.NoKindMatchError{GroupKind: nodeMetricsGVK.GroupKind()}
+			},
+		}).Build(), nil
+	}
+	if _, err := c.TopNodes(ctx, "org-acme", "prod", ""); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("TopNodes without metrics-server err = %v, want a failed precondition", err)
+	}
+}
@@ -0,0 +1,213 @@
+// Package capitest provides a fake CAPI client for tests of code depending
+// on capi.CAPIClient, such as the MCP tool handlers. The fake serves the
+// objects it is seeded with from the controller-runtime and client-go fake
+// clients, so handlers run the same code as against a management cluster.
+package capitest
+
+import (
+	"context"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// Fake is a CAPI client backed by fake clients. Its methods are those of
+// capi.Client, so it satisfies capi.CAPIClient.
+type Fake struct {
+	*capi.Client
+
+	// Ctrl is the controller-runtime fake client holding all objects
+	Ctrl client.WithWatch
+	// Kube is the client-go fake clientset holding the core Kubernetes
+	// objects, such as nodes, secrets and config maps
+	Kube *k8sfake.Clientset
+	// Workload is the fake every workload cluster is reached through, nil
+	// unless set with ServeWorkload
+	Workload *Fake
+}
+
+var _ capi.CAPIClient = (*Fake)(nil)
+
+// Scheme returns a scheme with the core Kubernetes types and the Cluster API
+// types the CAPI client reads as typed objects. Objects of other providers,
+// such as infrastructure templates, are seeded as unstructured objects.
+func Scheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme,
+		clusterv1.AddToScheme,
+		controlplanev1.AddToScheme,
+		bootstrapv1.AddToScheme,
+	} {
+		if err := add(scheme); err != nil {
+			panic(err)
+		}
+	}
+	return scheme
+}
+
+// ServerVersion is the Kubernetes version reported by the fake clientset
+var ServerVersion = version.Info{Major: "1", Minor: "30", GitVersion: "v1.30.2", Platform: "linux/amd64"}
+
+// NewFake creates a fake CAPI client seeded with objects. Core Kubernetes
+// objects are served by both fake clients, the others by the
+// controller-runtime client only. The kinds of the scheme and of the seeded
+// unstructured objects are served, as if their CRDs were installed. Access
+// reviews are allowed; prepend a reactor to Kube to deny them.
+func NewFake(objects ...runtime.Object) *Fake {
+	return NewFakeWithInterceptor(interceptor.Funcs{}, objects...)
+}
+
+// NewFakeWithInterceptor creates a fake CAPI client whose controller-runtime
+// requests pass through funcs, e.g. to inject API errors. Server-side apply
+// is emulated unless funcs intercept patches.
+func NewFakeWithInterceptor(funcs interceptor.Funcs, objects ...runtime.Object) *Fake {
+	if funcs.Patch == nil {
+		funcs.Patch = applyPatch
+	}
+	var core []runtime.Object
+	for _, obj := range objects {
+		if _, _, err := clientgoscheme.Scheme.ObjectKinds(obj); err == nil {
+			core = append(core, obj.DeepCopyObject())
+		}
+	}
+
+	scheme := Scheme()
+	seeded, resources := seededKinds(objects)
+	ctrl := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithRESTMapper(meta.MultiRESTMapper{testrestmapper.TestOnlyStaticRESTMapper(scheme), seeded}).
+		WithRuntimeObjects(objects...).
+		WithInterceptorFuncs(funcs).
+		Build()
+	kube := k8sfake.NewClientset(core...)
+	kube.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = true
+		return true, review, nil
+	})
+	serverVersion := ServerVersion
+	kube.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &serverVersion
+	kube.Discovery().(*fakediscovery.FakeDiscovery).Resources = resources
+	return &Fake{
+		Client: capi.NewClientForClients(kube, ctrl),
+		Ctrl:   ctrl,
+		Kube:   kube,
+	}
+}
+
+// applyPatch emulates server-side apply, which the fake client does not
+// support, by creating the applied object or merging it into the current one
+func applyPatch(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch != client.Apply {
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+	applied, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(applied.GroupVersionKind())
+	err := c.Get(ctx, client.ObjectKeyFromObject(applied), current)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+	if exists {
+		applied.Object = mergeObjects(current.Object, applied.Object)
+	}
+	patchOpts := &client.PatchOptions{}
+	patchOpts.ApplyOptions(opts)
+	switch {
+	case len(patchOpts.DryRun) > 0:
+		return nil
+	case exists:
+		return c.Update(ctx, applied)
+	default:
+		return c.Create(ctx, applied)
+	}
+}
+
+// mergeObjects overlays the fields of applied onto a copy of current
+func mergeObjects(current, applied map[string]any) map[string]any {
+	merged := runtime.DeepCopyJSON(current)
+	for key, value := range applied {
+		if nested, ok := value.(map[string]any); ok {
+			if currentNested, ok := merged[key].(map[string]any); ok {
+				merged[key] = mergeObjects(currentNested, nested)
+				continue
+			}
+		}
+		merged[key] = runtime.DeepCopyJSONValue(value)
+	}
+	return merged
+}
+
+// seededKinds maps the kinds of unstructured objects, such as the objects of
+// infrastructure providers, and lists them for discovery
+func seededKinds(objects []runtime.Object) (meta.RESTMapper, []*metav1.APIResourceList) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	var resources []*metav1.APIResourceList
+	lists := map[schema.GroupVersion]*metav1.APIResourceList{}
+	served := map[schema.GroupVersionKind]bool{}
+	for _, obj := range objects {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok || served[u.GroupVersionKind()] {
+			continue
+		}
+		gvk := u.GroupVersionKind()
+		served[gvk] = true
+		scope := meta.RESTScopeRoot
+		if u.GetNamespace() != "" {
+			scope = meta.RESTScopeNamespace
+		}
+		mapper.Add(gvk, scope)
+
+		list, ok := lists[gvk.GroupVersion()]
+		if !ok {
+			list = &metav1.APIResourceList{GroupVersion: gvk.GroupVersion().String()}
+			lists[gvk.GroupVersion()] = list
+			resources = append(resources, list)
+		}
+		plural, _ := meta.UnsafeGuessKindToResource(gvk)
+		list.APIResources = append(list.APIResources, metav1.APIResource{
+			Name:       plural.Resource,
+			Kind:       gvk.Kind,
+			Namespaced: scope == meta.RESTScopeNamespace,
+			Verbs:      metav1.Verbs{"get", "list", "watch", "create", "update", "patch", "delete"},
+		})
+	}
+	return mapper, resources
+}
+
+// ServeWorkload makes the fake reach every workload cluster through a fake
+// seeded with objects, such as the nodes of the cluster, instead of the
+// admin kubeconfig of the cluster. The kubeconfig Secret must exist all the
+// same.
+func (f *Fake) ServeWorkload(objects ...runtime.Object) *Fake {
+	f.Workload = NewFake(objects...)
+	f.Client.UseWorkloadClients(capi.WorkloadClients{
+		Clientset: f.Workload.Kube,
+		Client:    f.Workload.Ctrl,
+		CAPI:      f.Workload.Client,
+	})
+	return f.Workload
+}
@@ -0,0 +1,55 @@
+package capitest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/giantswarm/mcp-capi/pkg/capi"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestNewFake(t *testing.T) {
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "prod"}}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "prod-worker-1"}}
+	fake := NewFake(cluster, node)
+
+	clusters, err := fake.ListClusters(context.Background(), "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters.Items) != 1 || clusters.Items[0].Name != "prod" {
+		t.Errorf("ListClusters() = %+v, want the seeded cluster", clusters.Items)
+	}
+
+	// Core objects are served by the clientset too
+	if _, err := fake.Kube.CoreV1().Nodes().Get(context.Background(), "prod-worker-1", metav1.GetOptions{}); err != nil {
+		t.Errorf("clientset Get() error = %v", err)
+	}
+	if err := fake.Ctrl.Get(context.Background(), client.ObjectKeyFromObject(node), &corev1.Node{}); err != nil {
+		t.Errorf("controller-runtime Get() error = %v", err)
+	}
+
+	if info, err := fake.Kube.Discovery().ServerVersion(); err != nil || info.GitVersion != ServerVersion.GitVersion {
+		t.Errorf("ServerVersion() = %v, %v, want %s", info, err, ServerVersion.GitVersion)
+	}
+}
+
+func TestNewFakeWithInterceptor(t *testing.T) {
+	clusters := schema.GroupResource{Group: clusterv1.GroupVersion.Group, Resource: "clusters"}
+	fake := NewFakeWithInterceptor(interceptor.Funcs{
+		Get: func(ctx context.Context, _ client.WithWatch, key client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+			return apierrors.NewForbidden(clusters, key.Name, errors.New("denied"))
+		},
+	})
+
+	if _, err := fake.GetCluster(context.Background(), "default", "prod"); !errors.Is(err, capi.ErrForbidden) {
+		t.Errorf("GetCluster() error = %v, want ErrForbidden", err)
+	}
+}
@@ -0,0 +1,312 @@
+package capi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultChangeHistorySize is the number of changes kept when no size is configured
+const DefaultChangeHistorySize = 100
+
+// Change records the state of a resource before the server modified it
+type Change struct {
+	ID         string     `json:"id"`
+	Time       time.Time  `json:"time"`
+	Operation  string     `json:"operation"`
+	Kind       string     `json:"kind"`
+	Namespace  string     `json:"namespace"`
+	Name       string     `json:"name"`
+	RevertedAt *time.Time `json:"revertedAt,omitempty"`
+
+	// APIVersion is set for resources recorded as unstructured objects, such
+	// as the MachineDeployments and MachinePools scaled by hibernation
+	APIVersion string `json:"apiVersion,omitempty"`
+	// Snapshot is the JSON encoded resource before the change
+	Snapshot json.RawMessage `json:"snapshot"`
+}
+
+// Reverted reports whether the change has already been rolled back
+func (c *Change) Reverted() bool {
+	return c.RevertedAt != nil
+}
+
+// ChangeHistory is a bounded list of changes, optionally persisted to a file
+type ChangeHistory struct {
+	mu       sync.Mutex
+	changes  []Change
+	capacity int
+	filename string
+	seq      int
+}
+
+// NewChangeHistory creates a change history keeping up to capacity changes.
+// If filename is set, existing changes are loaded from it and every
+// modification is written back.
+func NewChangeHistory(capacity int, filename string) (*ChangeHistory, error) {
+	if capacity <= 0 {
+		capacity = DefaultChangeHistorySize
+	}
+	h := &ChangeHistory{capacity: capacity, filename: filename}
+
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("failed to read change history: %w", err)
+		default:
+			if err := json.Unmarshal(data, &h.changes); err != nil {
+				return nil, fmt.Errorf("failed to parse change history: %w", err)
+			}
+		}
+	}
+
+	for _, change := range h.changes {
+		var n int
+		if _, err := fmt.Sscanf(change.ID, "chg-%d", &n); err == nil && n > h.seq {
+			h.seq = n
+		}
+	}
+	return h, nil
+}
+
+// Record snapshots obj as the state before the given operation
+func (h *ChangeHistory) Record(operation string, obj client.Object) (*Change, error) {
+	kind, err := changeKind(obj)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot %s %s/%s: %w", kind, obj.GetNamespace(), obj.GetName(), err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	change := Change{
+		ID:        fmt.Sprintf("chg-%d", h.seq),
+		Time:      time.Now(),
+		Operation: operation,
+		Kind:      kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Snapshot:  snapshot,
+	}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		change.APIVersion = u.GetAPIVersion()
+	}
+	h.changes = append(h.changes, change)
+	if len(h.changes) > h.capacity {
+		h.changes = h.changes[len(h.changes)-h.capacity:]
+	}
+
+	return &change, h.save()
+}
+
+// List returns all recorded changes, newest first
+func (h *ChangeHistory) List() []Change {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	changes := make([]Change, 0, len(h.changes))
+	for i := len(h.changes) - 1; i >= 0; i-- {
+		changes = append(changes, h.changes[i])
+	}
+	return changes
+}
+
+// Get returns a change by ID
+func (h *ChangeHistory) Get(id string) (*Change, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := range h.changes {
+		if h.changes[i].ID == id {
+			change := h.changes[i]
+			return &change, true
+		}
+	}
+	return nil, false
+}
+
+// markReverted flags a change as rolled back
+func (h *ChangeHistory) markReverted(id string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := range h.changes {
+		if h.changes[i].ID == id {
+			now := time.Now()
+			h.changes[i].RevertedAt = &now
+			return h.save()
+		}
+	}
+	return errorf(ErrNotFound, "change %s not found", id)
+}
+
+// save writes the history to its file; the caller must hold the lock
+func (h *ChangeHistory) save() error {
+	if h.filename == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(h.changes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode change history: %w", err)
+	}
+	if err := os.WriteFile(h.filename, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write change history: %w", err)
+	}
+	return nil
+}
+
+// changeKind returns the kind of a revertible resource
+func changeKind(obj client.Object) (string, error) {
+	switch o := obj.(type) {
+	case *clusterv1.Cluster:
+		return "Cluster", nil
+	case *clusterv1.MachineDeployment:
+		return "MachineDeployment", nil
+	case *clusterv1.Machine:
+		return "Machine", nil
+	case *controlplanev1.KubeadmControlPlane:
+		return "KubeadmControlPlane", nil
+	case *unstructured.Unstructured:
+		if o.GetAPIVersion() == "" || o.GetKind() == "" {
+			return "", fmt.Errorf("unstructured object %s/%s without a kind for change history", o.GetNamespace(), o.GetName())
+		}
+		return o.GetKind(), nil
+	default:
+		return "", fmt.Errorf("unsupported resource type %T for change history", obj)
+	}
+}
+
+// newChangeObject returns an empty object of the given kind, an
+// unstructured one if the change recorded its API version
+func newChangeObject(apiVersion, kind string) (client.Object, error) {
+	if apiVersion != "" {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(apiVersion)
+		obj.SetKind(kind)
+		return obj, nil
+	}
+	switch kind {
+	case "Cluster":
+		return &clusterv1.Cluster{}, nil
+	case "MachineDeployment":
+		return &clusterv1.MachineDeployment{}, nil
+	case "Machine":
+		return &clusterv1.Machine{}, nil
+	case "KubeadmControlPlane":
+		return &controlplanev1.KubeadmControlPlane{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported kind %q in change history", kind)
+	}
+}
+
+// restoreChange copies the spec, labels and annotations of before onto
+// current. Machines only get their labels and annotations back: their
+// controllers fill in the spec, such as the provider ID, after creation.
+func restoreChange(current, before client.Object) error {
+	current.SetLabels(before.GetLabels())
+	current.SetAnnotations(before.GetAnnotations())
+
+	switch cur := current.(type) {
+	case *clusterv1.Cluster:
+		cur.Spec = before.(*clusterv1.Cluster).Spec
+	case *clusterv1.MachineDeployment:
+		cur.Spec = before.(*clusterv1.MachineDeployment).Spec
+	case *clusterv1.Machine:
+	case *controlplanev1.KubeadmControlPlane:
+		cur.Spec = before.(*controlplanev1.KubeadmControlPlane).Spec
+	case *unstructured.Unstructured:
+		spec, found, err := unstructured.NestedFieldCopy(before.(*unstructured.Unstructured).Object, "spec")
+		if err != nil {
+			return fmt.Errorf("invalid spec in snapshot: %w", err)
+		}
+		if found {
+			cur.Object["spec"] = spec
+		} else {
+			delete(cur.Object, "spec")
+		}
+	default:
+		return fmt.Errorf("unsupported resource type %T for revert", current)
+	}
+	return nil
+}
+
+// SetChangeHistory replaces the change history used to record modifications
+func (c *Client) SetChangeHistory(history *ChangeHistory) {
+	c.changes = history
+}
+
+// ChangeHistory returns the history of modifications made through this client
+func (c *Client) ChangeHistory() *ChangeHistory {
+	return c.changes
+}
+
+// recordChange registers the prior state of a resource after it was modified
+func (c *Client) recordChange(operation string, before client.Object) {
+	if c.changes == nil {
+		return
+	}
+	if _, err := c.changes.Record(operation, before); err != nil {
+		// The modification itself succeeded; a missing undo entry must not fail it
+		slog.Warn("Failed to record change", "operation", operation, "error", err)
+	}
+}
+
+// RevertChange restores the spec, labels and annotations a resource had before the given change.
+// The revert is itself recorded so it can be undone.
+func (c *Client) RevertChange(ctx context.Context, id string) (*Change, error) {
+	if c.changes == nil {
+		return nil, errorf(ErrPreconditionFailed, "change history is disabled")
+	}
+
+	change, ok := c.changes.Get(id)
+	if !ok {
+		return nil, errorf(ErrNotFound, "change %s not found", id)
+	}
+	if change.Reverted() {
+		return nil, errorf(ErrPreconditionFailed, "change %s was already reverted at %s", id, change.RevertedAt.UTC().Format(time.RFC3339))
+	}
+
+	before, err := newChangeObject(change.APIVersion, change.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(change.Snapshot, before); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot of change %s: %w", id, err)
+	}
+
+	current, err := newChangeObject(change.APIVersion, change.Kind)
+	if err != nil {
+		return nil, err
+	}
+	key := client.ObjectKey{Namespace: change.Namespace, Name: change.Name}
+	err = c.updateObject(ctx, key, current, "revert "+id, func() error {
+		return restoreChange(current, before)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to revert %s %s: %w", change.Kind, key, err)
+	}
+
+	if err := c.changes.markReverted(id); err != nil {
+		return nil, err
+	}
+
+	reverted, _ := c.changes.Get(id)
+	return reverted, nil
+}
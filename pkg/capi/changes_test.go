@@ -0,0 +1,166 @@
+package capi
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newChangeTestClient(t *testing.T, history *ChangeHistory, objs ...client.Object) *Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		changes:    history,
+	}
+}
+
+func TestRevertChange(t *testing.T) {
+	replicas := int32(3)
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: "default", Labels: map[string]string{"team": "a"}},
+		Spec:       clusterv1.MachineDeploymentSpec{ClusterName: "prod", Replicas: &replicas},
+	}
+
+	history, err := NewChangeHistory(10, filepath.Join(t.TempDir(), "changes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newChangeTestClient(t, history, md)
+	ctx := context.Background()
+
+	if err := c.ScaleMachineDeployment(ctx, "default", "workers", 10); err != nil {
+		t.Fatalf("ScaleMachineDeployment() error = %v", err)
+	}
+
+	changes := history.List()
+	if len(changes) != 1 || changes[0].Operation != "scale" || changes[0].Kind != "MachineDeployment" {
+		t.Fatalf("unexpected change history: %+v", changes)
+	}
+
+	if _, err := c.RevertChange(ctx, changes[0].ID); err != nil {
+		t.Fatalf("RevertChange() error = %v", err)
+	}
+
+	reverted, err := c.GetMachineDeployment(ctx, "default", "workers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *reverted.Spec.Replicas != 3 {
+		t.Errorf("replicas = %d after revert, want 3", *reverted.Spec.Replicas)
+	}
+
+	if _, err := c.RevertChange(ctx, changes[0].ID); err == nil {
+		t.Error("reverting a change twice should fail")
+	}
+
+	// The revert is recorded as well and the history survives a restart
+	reloaded, err := NewChangeHistory(10, history.filename)
+	if err != nil {
+		t.Fatalf("NewChangeHistory() reload error = %v", err)
+	}
+	all := reloaded.List()
+	if len(all) != 2 || all[0].Operation != "revert "+changes[0].ID || !all[1].Reverted() {
+		t.Errorf("unexpected persisted history: %+v", all)
+	}
+}
+
+func TestChangeHistoryCapacity(t *testing.T) {
+	history, err := NewChangeHistory(2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if _, err := history.Record("pause", &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes := history.List()
+	if len(changes) != 2 || changes[0].Name != "c" || changes[1].Name != "b" {
+		t.Errorf("unexpected changes: %+v", changes)
+	}
+	if _, ok := history.Get("chg-1"); ok {
+		t.Error("oldest change should have been dropped")
+	}
+}
+
+func TestRevertUnstructuredChange(t *testing.T) {
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "default"}}
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev-md-0", Namespace: "default"},
+		Spec:       clusterv1.MachineDeploymentSpec{ClusterName: "dev", Replicas: ptr.To[int32](3)},
+	}
+	history, err := NewChangeHistory(10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newChangeTestClient(t, history, cluster, md)
+	ctx := context.Background()
+
+	if _, err := c.HibernateCluster(ctx, "default", "dev", HibernateOptions{Wait: WaitOptions{Timeout: time.Second, PollInterval: 10 * time.Millisecond}}); err != nil {
+		t.Fatalf("HibernateCluster() error = %v", err)
+	}
+	// Hibernating scales the MachineDeployment, then pauses the Cluster
+	changes := history.List()
+	if len(changes) != 2 || changes[1].Operation != "hibernate" || changes[1].Kind != "MachineDeployment" || changes[1].APIVersion != clusterv1.GroupVersion.String() {
+		t.Fatalf("unexpected change history: %+v", changes)
+	}
+
+	if _, err := c.RevertChange(ctx, changes[1].ID); err != nil {
+		t.Fatalf("RevertChange() error = %v", err)
+	}
+	reverted, err := c.GetMachineDeployment(ctx, "default", "dev-md-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *reverted.Spec.Replicas != 3 || reverted.Annotations[HibernatedReplicasAnnotation] != "" {
+		t.Errorf("dev-md-0 = %d replicas, annotations %v after revert, want 3 and no record", *reverted.Spec.Replicas, reverted.Annotations)
+	}
+	if all := history.List(); len(all) != 3 || all[0].APIVersion != clusterv1.GroupVersion.String() || !all[2].Reverted() {
+		t.Errorf("unexpected history after revert: %+v", all)
+	}
+}
+
+func TestRevertMachineAnnotation(t *testing.T) {
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod-md-x-a", Namespace: "default"},
+		Spec:       clusterv1.MachineSpec{ClusterName: "prod"},
+	}
+	history, err := NewChangeHistory(10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newChangeTestClient(t, history, machine)
+	ctx := context.Background()
+
+	if _, err := c.SetMachineAnnotation(ctx, MachineAnnotationOptions{Namespace: "default", Name: "prod-md-x-a", Annotation: SkipRemediation}); err != nil {
+		t.Fatalf("SetMachineAnnotation() error = %v", err)
+	}
+	changes := history.List()
+	if len(changes) != 1 || changes[0].Operation != "annotate-machine" || changes[0].Kind != "Machine" {
+		t.Fatalf("unexpected change history: %+v", changes)
+	}
+
+	if _, err := c.RevertChange(ctx, changes[0].ID); err != nil {
+		t.Fatalf("RevertChange() error = %v", err)
+	}
+	reverted := &clusterv1.Machine{}
+	if err := c.ctrlClient.Get(ctx, client.ObjectKeyFromObject(machine), reverted); err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted.Annotations) != 0 || reverted.Spec.ClusterName != "prod" {
+		t.Errorf("machine annotations = %v, cluster %q after revert, want none and prod", reverted.Annotations, reverted.Spec.ClusterName)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -32,17 +33,75 @@ type Client struct {
 
 	// config is the rest config used to connect
 	config *rest.Config
+
+	// changes records resource state before modifications so they can be reverted
+	changes *ChangeHistory
+
+	// timeouts bound reads, writes and node drains
+	timeouts OperationTimeouts
+
+	// retries counts the retries of transient errors
+	retries *retryCounters
+
+	// newWorkloadClientset connects to workload clusters, through their admin
+	// kubeconfig when nil
+	newWorkloadClientset func(kubeconfig string) (kubernetes.Interface, error)
+
+	// execInWorkload runs commands in pods of workload clusters, through
+	// their admin kubeconfig when nil
+	execInWorkload func(ctx context.Context, kubeconfig string, exec PodExec) (string, error)
+
+	// newWorkloadClient applies objects to workload clusters, through their
+	// admin kubeconfig when nil
+	newWorkloadClient func(kubeconfig string) (client.Client, error)
+
+	// newWorkloadCAPIClient manages the Cluster API objects of workload
+	// clusters, through their admin kubeconfig when nil
+	newWorkloadCAPIClient func(kubeconfig string) (*Client, error)
 }
 
-// NewClient creates a new CAPI client
+// NewClient creates a new CAPI client. Use NewClientWithOptions to tune the
+// connection.
 func NewClient(kubeconfig string) (*Client, error) {
-	config, err := loadConfig(kubeconfig)
+	return NewClientWithOptions(WithKubeconfig(kubeconfig))
+}
+
+// NewClientForContext creates a CAPI client for a context of a kubeconfig
+// file. An empty kubeconfig uses the file found through the KUBECONFIG
+// variable or at ~/.kube/config, an empty context the file's current context.
+func NewClientForContext(kubeconfig, contextName string) (*Client, error) {
+	config, err := loadContextConfig(kubeconfig, contextName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+		return nil, err
+	}
+	return NewClientForConfig(config)
+}
+
+// NewClientForConfig creates a CAPI client from a rest config. Requests
+// failing with transient errors are retried following DefaultRetryPolicy.
+func NewClientForConfig(config *rest.Config) (*Client, error) {
+	return newClientForConfig(config, DefaultRetryPolicy, OperationTimeouts{})
+}
+
+// NewClientForClients creates a CAPI client on top of existing clients, such
+// as the fake clients of the capitest package. Requests are neither retried
+// nor bounded by timeouts, and changes are recorded in memory.
+func NewClientForClients(k8sClient kubernetes.Interface, ctrlClient client.Client) *Client {
+	changes, _ := NewChangeHistory(DefaultChangeHistorySize, "")
+	return &Client{
+		k8sClient:  k8sClient,
+		ctrlClient: ctrlClient,
+		changes:    changes,
+		retries:    &retryCounters{},
 	}
+}
 
-	// Create standard Kubernetes client
-	k8sClient, err := kubernetes.NewForConfig(config)
+// newClientForConfig creates a CAPI client whose controller-runtime requests
+// are retried following policy, with the operation timeouts bounding each
+// request and its retries, and converted to the served contract outermost
+func newClientForConfig(config *rest.Config, policy RetryPolicy, timeouts OperationTimeouts) (*Client, error) {
+	// Create standard Kubernetes client, bounded by the timeouts as well
+	k8sClient, err := kubernetes.NewForConfig(withRequestTimeouts(config, timeouts))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
@@ -55,6 +114,9 @@ func NewClient(kubeconfig string) (*Client, error) {
 	if err := clusterv1.AddToScheme(scheme); err != nil {
 		return nil, fmt.Errorf("failed to add CAPI to scheme: %w", err)
 	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		return nil, fmt.Errorf("failed to add KubeadmControlPlane to scheme: %w", err)
+	}
 
 	ctrlClient, err := client.New(config, client.Options{
 		Scheme: scheme,
@@ -63,13 +125,44 @@ func NewClient(kubeconfig string) (*Client, error) {
 		return nil, fmt.Errorf("failed to create controller client: %w", err)
 	}
 
+	changes, err := NewChangeHistory(DefaultChangeHistorySize, "")
+	if err != nil {
+		return nil, err
+	}
+
+	retries := &retryCounters{}
+	var wrapped client.Client = newRetryClient(ctrlClient, policy, retries)
+	if timeouts != (OperationTimeouts{}) {
+		wrapped = newTimeoutClient(wrapped, timeouts)
+	}
+
 	return &Client{
 		k8sClient:  k8sClient,
-		ctrlClient: ctrlClient,
+		ctrlClient: newContractClient(wrapped, k8sClient.Discovery()),
 		config:     config,
+		changes:    changes,
+		timeouts:   timeouts,
+		retries:    retries,
 	}, nil
 }
 
+// loadContextConfig loads a context of a kubeconfig file through the default
+// loading rules
+func loadContextConfig(kubeconfig, contextName string) (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		rules.ExplicitPath = kubeconfig
+	}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		rules,
+		&clientcmd.ConfigOverrides{CurrentContext: contextName},
+	).ClientConfig()
+	if err != nil {
+		return nil, errorf(ErrInvalidArgument, "failed to load kubeconfig context %q: %v", contextName, err)
+	}
+	return config, nil
+}
+
 // loadConfig loads the kubeconfig from various sources
 func loadConfig(kubeconfig string) (*rest.Config, error) {
 	// If kubeconfig is provided, use it
@@ -110,10 +203,13 @@ func (c *Client) GetCtrlClient() client.Client {
 }
 
 // ListClusters lists all CAPI clusters in the given namespace
-func (c *Client) ListClusters(ctx context.Context, namespace string) (*clusterv1.ClusterList, error) {
+func (c *Client) ListClusters(ctx context.Context, namespace string, listOpts ...ListOption) (*clusterv1.ClusterList, error) {
 	clusterList := &clusterv1.ClusterList{}
 
-	opts := []client.ListOption{}
+	opts, err := clientListOptions(listOpts, nil)
+	if err != nil {
+		return nil, err
+	}
 	if namespace != "" {
 		opts = append(opts, client.InNamespace(namespace))
 	}
@@ -134,25 +230,21 @@ func (c *Client) GetCluster(ctx context.Context, namespace, name string) (*clust
 	}
 
 	if err := c.ctrlClient.Get(ctx, key, cluster); err != nil {
-		return nil, fmt.Errorf("failed to get cluster %s/%s: %w", namespace, name, err)
+		return nil, fmt.Errorf("failed to get cluster %s/%s: %w", namespace, name, resourceError("Cluster", key, err))
 	}
 
 	return cluster, nil
 }
 
 // ListMachines lists all machines for a given cluster
-func (c *Client) ListMachines(ctx context.Context, namespace, clusterName string) (*clusterv1.MachineList, error) {
+func (c *Client) ListMachines(ctx context.Context, namespace, clusterName string, listOpts ...ListOption) (*clusterv1.MachineList, error) {
 	machineList := &clusterv1.MachineList{}
 
-	opts := []client.ListOption{
-		client.InNamespace(namespace),
-	}
-
-	if clusterName != "" {
-		opts = append(opts, client.MatchingLabels{
-			clusterv1.ClusterNameLabel: clusterName,
-		})
+	opts, err := clientListOptions(listOpts, clusterLabels(clusterName))
+	if err != nil {
+		return nil, err
 	}
+	opts = append(opts, client.InNamespace(namespace))
 
 	if err := c.ctrlClient.List(ctx, machineList, opts...); err != nil {
 		return nil, fmt.Errorf("failed to list machines: %w", err)
@@ -170,7 +262,7 @@ func (c *Client) GetMachine(ctx context.Context, namespace, name string) (*clust
 	}
 
 	if err := c.ctrlClient.Get(ctx, key, machine); err != nil {
-		return nil, fmt.Errorf("failed to get machine %s/%s: %w", namespace, name, err)
+		return nil, fmt.Errorf("failed to get machine %s/%s: %w", namespace, name, resourceError("Machine", key, err))
 	}
 
 	return machine, nil
@@ -193,7 +285,7 @@ func (c *Client) DeleteMachine(ctx context.Context, opts DeleteMachineOptions) e
 
 	// First, get the machine to check if it exists
 	if err := c.ctrlClient.Get(ctx, key, machine); err != nil {
-		return fmt.Errorf("failed to get machine: %w", err)
+		return fmt.Errorf("failed to get machine: %w", resourceError("Machine", key, err))
 	}
 
 	// If not forcing, check if machine is safe to delete
@@ -201,20 +293,24 @@ func (c *Client) DeleteMachine(ctx context.Context, opts DeleteMachineOptions) e
 		// Check if machine is healthy
 		for _, condition := range machine.Status.Conditions {
 			if condition.Type == clusterv1.MachineHealthCheckSucceededCondition && condition.Status == corev1.ConditionTrue {
-				return fmt.Errorf("machine %s is healthy, use force=true to delete anyway", machine.Name)
+				return errorf(ErrPreconditionFailed, "machine %s is healthy, use force=true to delete anyway", machine.Name)
 			}
 		}
 
 		// Check if it's a control plane machine with only one replica
 		if util.IsControlPlaneMachine(machine) {
 			// This is a simplified check - in production you'd want to check the actual replica count
-			return fmt.Errorf("cannot delete control plane machine %s without force=true", machine.Name)
+			return errorf(ErrPreconditionFailed, "cannot delete control plane machine %s without force=true", machine.Name)
 		}
 	}
 
+	if err := checkGitOpsOwner(ctx, machine); err != nil {
+		return err
+	}
+
 	// Delete the machine
 	if err := c.ctrlClient.Delete(ctx, machine); err != nil {
-		return fmt.Errorf("failed to delete machine: %w", err)
+		return fmt.Errorf("failed to delete machine: %w", resourceError("Machine", key, err))
 	}
 
 	return nil
@@ -234,18 +330,15 @@ func (c *Client) RemediateMachine(ctx context.Context, opts RemediateMachineOpti
 		Name:      opts.Name,
 	}
 
-	if err := c.ctrlClient.Get(ctx, key, machine); err != nil {
-		return fmt.Errorf("failed to get machine: %w", err)
-	}
-
 	// Add remediation annotation
-	if machine.Annotations == nil {
-		machine.Annotations = make(map[string]string)
-	}
-	machine.Annotations["cluster.x-k8s.io/remediate-machine"] = fmt.Sprintf("%d", time.Now().Unix())
-
-	// Update the machine
-	if err := c.ctrlClient.Update(ctx, machine); err != nil {
+	err := c.updateObject(ctx, key, machine, "", func() error {
+		if machine.Annotations == nil {
+			machine.Annotations = make(map[string]string)
+		}
+		machine.Annotations["cluster.x-k8s.io/remediate-machine"] = fmt.Sprintf("%d", time.Now().Unix())
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update machine with remediation annotation: %w", err)
 	}
 
@@ -253,18 +346,14 @@ func (c *Client) RemediateMachine(ctx context.Context, opts RemediateMachineOpti
 }
 
 // ListMachineDeployments lists all machine deployments
-func (c *Client) ListMachineDeployments(ctx context.Context, namespace, clusterName string) (*clusterv1.MachineDeploymentList, error) {
+func (c *Client) ListMachineDeployments(ctx context.Context, namespace, clusterName string, listOpts ...ListOption) (*clusterv1.MachineDeploymentList, error) {
 	mdList := &clusterv1.MachineDeploymentList{}
 
-	opts := []client.ListOption{
-		client.InNamespace(namespace),
-	}
-
-	if clusterName != "" {
-		opts = append(opts, client.MatchingLabels{
-			clusterv1.ClusterNameLabel: clusterName,
-		})
+	opts, err := clientListOptions(listOpts, clusterLabels(clusterName))
+	if err != nil {
+		return nil, err
 	}
+	opts = append(opts, client.InNamespace(namespace))
 
 	if err := c.ctrlClient.List(ctx, mdList, opts...); err != nil {
 		return nil, fmt.Errorf("failed to list machine deployments: %w", err)
@@ -282,7 +371,7 @@ func (c *Client) GetMachineDeployment(ctx context.Context, namespace, name strin
 	}
 
 	if err := c.ctrlClient.Get(ctx, key, md); err != nil {
-		return nil, fmt.Errorf("failed to get machine deployment: %w", err)
+		return nil, fmt.Errorf("failed to get machine deployment: %w", resourceError("MachineDeployment", key, err))
 	}
 
 	return md, nil
@@ -324,17 +413,15 @@ func (c *Client) PauseCluster(ctx context.Context, namespace, name string) error
 		Name:      name,
 	}
 
-	if err := c.ctrlClient.Get(ctx, key, cluster); err != nil {
-		return fmt.Errorf("failed to get cluster: %w", err)
-	}
-
 	// Add paused annotation
-	if cluster.Annotations == nil {
-		cluster.Annotations = make(map[string]string)
-	}
-	cluster.Annotations[clusterv1.PausedAnnotation] = "true"
-
-	if err := c.ctrlClient.Update(ctx, cluster); err != nil {
+	err := c.updateObject(ctx, key, cluster, "pause", func() error {
+		if cluster.Annotations == nil {
+			cluster.Annotations = make(map[string]string)
+		}
+		cluster.Annotations[clusterv1.PausedAnnotation] = "true"
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to pause cluster: %w", err)
 	}
 
@@ -349,16 +436,12 @@ func (c *Client) ResumeCluster(ctx context.Context, namespace, name string) erro
 		Name:      name,
 	}
 
-	if err := c.ctrlClient.Get(ctx, key, cluster); err != nil {
-		return fmt.Errorf("failed to get cluster: %w", err)
-	}
-
 	// Remove paused annotation
-	if cluster.Annotations != nil {
+	err := c.updateObject(ctx, key, cluster, "resume", func() error {
 		delete(cluster.Annotations, clusterv1.PausedAnnotation)
-	}
-
-	if err := c.ctrlClient.Update(ctx, cluster); err != nil {
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to resume cluster: %w", err)
 	}
 
@@ -374,17 +457,27 @@ func (c *Client) DeleteCluster(ctx context.Context, namespace, name string) erro
 	}
 
 	if err := c.ctrlClient.Get(ctx, key, cluster); err != nil {
-		return fmt.Errorf("failed to get cluster: %w", err)
+		return fmt.Errorf("failed to get cluster: %w", resourceError("Cluster", key, err))
+	}
+
+	if err := checkGitOpsOwner(ctx, cluster); err != nil {
+		return err
 	}
 
 	// Delete the cluster
 	if err := c.ctrlClient.Delete(ctx, cluster); err != nil {
-		return fmt.Errorf("failed to delete cluster: %w", err)
+		return fmt.Errorf("failed to delete cluster: %w", resourceError("Cluster", key, err))
 	}
 
 	return nil
 }
 
+// The cluster network ranges of the clusters CreateCluster creates
+const (
+	defaultPodCIDR     = "192.168.0.0/16"
+	defaultServiceCIDR = "10.96.0.0/12"
+)
+
 // CreateClusterOptions contains options for creating a new cluster
 type CreateClusterOptions struct {
 	Name              string
@@ -399,6 +492,11 @@ type CreateClusterOptions struct {
 
 // CreateCluster creates a new CAPI cluster with basic configuration
 func (c *Client) CreateCluster(ctx context.Context, opts CreateClusterOptions) (*clusterv1.Cluster, error) {
+	provider, ok := LookupProvider(opts.InfraProvider)
+	if !ok {
+		return nil, errorf(ErrInvalidArgument, "unknown infrastructure provider %q, supported providers: %s", opts.InfraProvider, strings.Join(ProviderNames(), ", "))
+	}
+
 	// For now, we'll create a basic cluster object
 	// In a real implementation, this would create all the necessary resources
 	// (Cluster, KubeadmControlPlane, MachineDeployment, etc.)
@@ -408,16 +506,16 @@ func (c *Client) CreateCluster(ctx context.Context, opts CreateClusterOptions) (
 			Name:      opts.Name,
 			Namespace: opts.Namespace,
 			Labels: map[string]string{
-				"cluster.x-k8s.io/provider": opts.InfraProvider,
+				"cluster.x-k8s.io/provider": string(provider.Name),
 			},
 		},
 		Spec: clusterv1.ClusterSpec{
 			ClusterNetwork: &clusterv1.ClusterNetwork{
 				Pods: &clusterv1.NetworkRanges{
-					CIDRBlocks: []string{"192.168.0.0/16"},
+					CIDRBlocks: []string{defaultPodCIDR},
 				},
 				Services: &clusterv1.NetworkRanges{
-					CIDRBlocks: []string{"10.96.0.0/12"},
+					CIDRBlocks: []string{defaultServiceCIDR},
 				},
 			},
 			ControlPlaneRef: &corev1.ObjectReference{
@@ -426,16 +524,20 @@ func (c *Client) CreateCluster(ctx context.Context, opts CreateClusterOptions) (
 				Name:       opts.Name + "-control-plane",
 			},
 			InfrastructureRef: &corev1.ObjectReference{
-				APIVersion: getInfraAPIVersion(opts.InfraProvider),
-				Kind:       getInfraKind(opts.InfraProvider),
+				APIVersion: provider.APIVersion,
+				Kind:       provider.ClusterKinds[0],
 				Name:       opts.Name,
 			},
 		},
 	}
 
+	if provider.Name == ProviderDocker {
+		return c.createDockerCluster(ctx, cluster, opts)
+	}
+
 	// Create the cluster
 	if err := c.ctrlClient.Create(ctx, cluster); err != nil {
-		return nil, fmt.Errorf("failed to create cluster: %w", err)
+		return nil, fmt.Errorf("failed to create cluster: %w", resourceError("Cluster", client.ObjectKeyFromObject(cluster), err))
 	}
 
 	return cluster, nil
@@ -447,9 +549,13 @@ type UpgradeClusterOptions struct {
 	Name           string
 	TargetVersion  string
 	UpgradeWorkers bool
+	// Force skips the version skew checks
+	Force bool
 }
 
-// UpgradeCluster upgrades a CAPI cluster to a new Kubernetes version
+// UpgradeCluster upgrades a CAPI cluster to a new Kubernetes version. Unless
+// opts.Force is set, it refuses downgrades, skipped minor versions of the
+// control plane and workers left outside the kubelet skew policy.
 func (c *Client) UpgradeCluster(ctx context.Context, opts UpgradeClusterOptions) error {
 	cluster := &clusterv1.Cluster{}
 	key := client.ObjectKey{
@@ -458,7 +564,23 @@ func (c *Client) UpgradeCluster(ctx context.Context, opts UpgradeClusterOptions)
 	}
 
 	if err := c.ctrlClient.Get(ctx, key, cluster); err != nil {
-		return fmt.Errorf("failed to get cluster: %w", err)
+		return fmt.Errorf("failed to get cluster: %w", resourceError("Cluster", key, err))
+	}
+
+	// AKS control planes and node pools are upgraded through their managed resources
+	if isAKSCluster(cluster) {
+		return c.upgradeAKSCluster(ctx, cluster, opts)
+	}
+
+	// Verify RBAC up front so the upgrade does not fail halfway through
+	if err := c.RequirePermissions(ctx, upgradePermissionChecks(opts.Namespace, opts.UpgradeWorkers)...); err != nil {
+		return err
+	}
+
+	if !opts.Force {
+		if err := c.checkUpgradeSkew(ctx, cluster, opts); err != nil {
+			return err
+		}
 	}
 
 	// Update the control plane version
@@ -470,17 +592,17 @@ func (c *Client) UpgradeCluster(ctx context.Context, opts UpgradeClusterOptions)
 				Namespace: cluster.Spec.ControlPlaneRef.Namespace,
 				Name:      cluster.Spec.ControlPlaneRef.Name,
 			}
-			if err := c.ctrlClient.Get(ctx, cpKey, kcp); err != nil {
-				return fmt.Errorf("failed to get control plane: %w", err)
-			}
 
 			// Update version
-			kcp.Spec.Version = opts.TargetVersion
-			if err := c.ctrlClient.Update(ctx, kcp); err != nil {
+			err := c.updateObject(ctx, cpKey, kcp, "upgrade", func() error {
+				kcp.Spec.Version = opts.TargetVersion
+				return nil
+			})
+			if err != nil {
 				return fmt.Errorf("failed to update control plane version: %w", err)
 			}
 		default:
-			return fmt.Errorf("unsupported control plane type: %s", cluster.Spec.ControlPlaneRef.Kind)
+			return errorf(ErrPreconditionFailed, "unsupported control plane type: %s", cluster.Spec.ControlPlaneRef.Kind)
 		}
 	}
 
@@ -493,11 +615,15 @@ func (c *Client) UpgradeCluster(ctx context.Context, opts UpgradeClusterOptions)
 
 		for i := range mdList.Items {
 			md := &mdList.Items[i]
-			if md.Spec.Template.Spec.Version != nil {
-				*md.Spec.Template.Spec.Version = opts.TargetVersion
-				if err := c.ctrlClient.Update(ctx, md); err != nil {
-					return fmt.Errorf("failed to update machine deployment %s: %w", md.Name, err)
-				}
+			if md.Spec.Template.Spec.Version == nil {
+				continue
+			}
+			err := c.updateObject(ctx, client.ObjectKeyFromObject(md), md, "upgrade", func() error {
+				md.Spec.Template.Spec.Version = &opts.TargetVersion
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to update machine deployment %s: %w", md.Name, err)
 			}
 		}
 	}
@@ -505,6 +631,34 @@ func (c *Client) UpgradeCluster(ctx context.Context, opts UpgradeClusterOptions)
 	return nil
 }
 
+// checkUpgradeSkew verifies an upgrade against the version skew policies
+func (c *Client) checkUpgradeSkew(ctx context.Context, cluster *clusterv1.Cluster, opts UpgradeClusterOptions) error {
+	target, err := version.ParseSemantic(opts.TargetVersion)
+	if err != nil {
+		return errorf(ErrInvalidArgument, "invalid target version %q: %v", opts.TargetVersion, err)
+	}
+
+	var kcp *controlplanev1.KubeadmControlPlane
+	if ref := cluster.Spec.ControlPlaneRef; ref != nil && ref.Kind == "KubeadmControlPlane" {
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = cluster.Namespace
+		}
+		if kcp, err = c.GetKubeadmControlPlane(ctx, namespace, ref.Name); err != nil {
+			return fmt.Errorf("failed to get control plane: %w", err)
+		}
+	}
+	mds, err := c.ListMachineDeployments(ctx, cluster.Namespace, cluster.Name)
+	if err != nil {
+		return fmt.Errorf("failed to list machine deployments: %w", err)
+	}
+
+	if violations := upgradeSkewViolations(kcp, mds.Items, target, opts.UpgradeWorkers); len(violations) > 0 {
+		return skewError(violations)
+	}
+	return nil
+}
+
 // UpdateClusterOptions contains options for updating a cluster
 type UpdateClusterOptions struct {
 	Namespace   string
@@ -521,41 +675,39 @@ func (c *Client) UpdateCluster(ctx context.Context, opts UpdateClusterOptions) (
 		Name:      opts.Name,
 	}
 
-	if err := c.ctrlClient.Get(ctx, key, cluster); err != nil {
-		return nil, fmt.Errorf("failed to get cluster: %w", err)
-	}
-
-	// Update labels
-	if opts.Labels != nil {
-		if cluster.Labels == nil {
-			cluster.Labels = make(map[string]string)
-		}
-		for k, v := range opts.Labels {
-			if v == "" {
-				// Empty value means remove the label
-				delete(cluster.Labels, k)
-			} else {
-				cluster.Labels[k] = v
+	err := c.updateObject(ctx, key, cluster, "update", func() error {
+		// Update labels
+		if opts.Labels != nil {
+			if cluster.Labels == nil {
+				cluster.Labels = make(map[string]string)
+			}
+			for k, v := range opts.Labels {
+				if v == "" {
+					// Empty value means remove the label
+					delete(cluster.Labels, k)
+				} else {
+					cluster.Labels[k] = v
+				}
 			}
 		}
-	}
 
-	// Update annotations
-	if opts.Annotations != nil {
-		if cluster.Annotations == nil {
-			cluster.Annotations = make(map[string]string)
-		}
-		for k, v := range opts.Annotations {
-			if v == "" {
-				// Empty value means remove the annotation
-				delete(cluster.Annotations, k)
-			} else {
-				cluster.Annotations[k] = v
+		// Update annotations
+		if opts.Annotations != nil {
+			if cluster.Annotations == nil {
+				cluster.Annotations = make(map[string]string)
+			}
+			for k, v := range opts.Annotations {
+				if v == "" {
+					// Empty value means remove the annotation
+					delete(cluster.Annotations, k)
+				} else {
+					cluster.Annotations[k] = v
+				}
 			}
 		}
-	}
-
-	if err := c.ctrlClient.Update(ctx, cluster); err != nil {
+		return nil
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to update cluster: %w", err)
 	}
 
@@ -582,7 +734,7 @@ func (c *Client) MoveCluster(ctx context.Context, opts MoveClusterOptions) (stri
 	}
 
 	if err := c.ctrlClient.Get(ctx, key, cluster); err != nil {
-		return "", fmt.Errorf("failed to get cluster: %w", err)
+		return "", fmt.Errorf("failed to get cluster: %w", resourceError("Cluster", key, err))
 	}
 
 	// Prepare target namespace
@@ -613,96 +765,14 @@ func (c *Client) MoveCluster(ctx context.Context, opts MoveClusterOptions) (stri
 	return manifest.String(), nil
 }
 
-// BackupClusterOptions contains options for backing up a cluster
-type BackupClusterOptions struct {
-	Namespace      string
-	Name           string
-	IncludeSecrets bool
-	OutputFormat   string // yaml or json
-}
-
-// BackupCluster creates a backup of cluster resources
-func (c *Client) BackupCluster(ctx context.Context, opts BackupClusterOptions) (string, error) {
-	// Get the cluster
-	cluster := &clusterv1.Cluster{}
-	key := client.ObjectKey{
-		Namespace: opts.Namespace,
-		Name:      opts.Name,
-	}
-
-	if err := c.ctrlClient.Get(ctx, key, cluster); err != nil {
-		return "", fmt.Errorf("failed to get cluster: %w", err)
-	}
-
-	// Create backup manifest
-	var backup strings.Builder
-	backup.WriteString("# Cluster Backup\n")
-	backup.WriteString(fmt.Sprintf("# Cluster: %s/%s\n", opts.Namespace, opts.Name))
-	backup.WriteString(fmt.Sprintf("# Date: %s\n", fmt.Sprintf("%v", cluster.CreationTimestamp)))
-	backup.WriteString("# Resources included:\n")
-	backup.WriteString("# - Cluster\n")
-	backup.WriteString("# - Control Plane\n")
-	backup.WriteString("# - MachineDeployments\n")
-	backup.WriteString("# - Infrastructure Resources\n")
-	if opts.IncludeSecrets {
-		backup.WriteString("# - Secrets (kubeconfig, certificates)\n")
-	}
-	backup.WriteString("---\n")
-
-	// Note: In a real implementation, you would:
-	// 1. Export the Cluster resource
-	// 2. Export ControlPlane resources
-	// 3. Export all Machines and MachineDeployments
-	// 4. Export infrastructure-specific resources
-	// 5. Optionally export secrets (kubeconfig, certs)
-	// 6. Add restore instructions
-
-	backup.WriteString("# This is a placeholder implementation\n")
-	backup.WriteString("# Use velero or similar tools for complete cluster backup\n")
-	backup.WriteString("# Example: velero backup create cluster-backup --include-namespaces=<namespace>\n")
-
-	return backup.String(), nil
-}
-
-// Helper functions to map provider to API versions and kinds
-func getInfraAPIVersion(provider string) string {
-	switch provider {
-	case "aws":
-		return "infrastructure.cluster.x-k8s.io/v1beta2"
-	case "azure":
-		return "infrastructure.cluster.x-k8s.io/v1beta1"
-	case "gcp":
-		return "infrastructure.cluster.x-k8s.io/v1beta1"
-	case "vsphere":
-		return "infrastructure.cluster.x-k8s.io/v1beta1"
-	default:
-		return "infrastructure.cluster.x-k8s.io/v1beta1"
-	}
-}
-
-func getInfraKind(provider string) string {
-	switch provider {
-	case "aws":
-		return "AWSCluster"
-	case "azure":
-		return "AzureCluster"
-	case "gcp":
-		return "GCPCluster"
-	case "vsphere":
-		return "VSphereCluster"
-	default:
-		return "Cluster"
-	}
-}
-
 // ClusterHealthStatus represents the health status of a cluster
 type ClusterHealthStatus struct {
-	Healthy           bool
-	ControlPlaneReady bool
-	WorkersReady      bool
-	InfraReady        bool
-	Issues            []string
-	Warnings          []string
+	Healthy           bool     `json:"healthy"`
+	ControlPlaneReady bool     `json:"controlPlaneReady"`
+	WorkersReady      bool     `json:"workersReady"`
+	InfraReady        bool     `json:"infrastructureReady"`
+	Issues            []string `json:"issues,omitempty"`
+	Warnings          []string `json:"warnings,omitempty"`
 }
 
 // GetClusterHealth checks the health of a cluster
@@ -830,7 +900,7 @@ func (c *Client) CreateMachineDeployment(ctx context.Context, opts CreateMachine
 
 	// Create the machine deployment
 	if err := c.ctrlClient.Create(ctx, md); err != nil {
-		return nil, fmt.Errorf("failed to create machine deployment: %w", err)
+		return nil, fmt.Errorf("failed to create machine deployment: %w", resourceError("MachineDeployment", client.ObjectKeyFromObject(md), err))
 	}
 
 	return md, nil
@@ -849,68 +919,117 @@ type UpdateMachineDeploymentOptions struct {
 	Annotations      map[string]string
 	MinReadySeconds  *int32
 	NodeDrainTimeout *metav1.Duration
+	// Force skips the version skew checks of a new Version
+	Force bool
 }
 
-// UpdateMachineDeployment updates a MachineDeployment's configuration
+// UpdateMachineDeployment updates a MachineDeployment's configuration. Unless
+// opts.Force is set, a new version must not be a downgrade nor leave the
+// workers outside the kubelet skew policy of their control plane.
 func (c *Client) UpdateMachineDeployment(ctx context.Context, opts UpdateMachineDeploymentOptions) (*clusterv1.MachineDeployment, error) {
-	md, err := c.GetMachineDeployment(ctx, opts.Namespace, opts.Name)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get machine deployment: %w", err)
+	md := &clusterv1.MachineDeployment{}
+	key := client.ObjectKey{
+		Namespace: opts.Namespace,
+		Name:      opts.Name,
 	}
 
-	// Update version if specified
-	if opts.Version != nil {
-		md.Spec.Template.Spec.Version = opts.Version
+	if opts.Version != nil && !opts.Force {
+		if err := c.checkWorkerVersion(ctx, key, *opts.Version); err != nil {
+			return nil, err
+		}
 	}
 
-	// Update replicas if specified
-	if opts.Replicas != nil {
-		md.Spec.Replicas = opts.Replicas
-	}
+	err := c.updateObject(ctx, key, md, "update", func() error {
+		// Update version if specified
+		if opts.Version != nil {
+			md.Spec.Template.Spec.Version = opts.Version
+		}
 
-	// Update minReadySeconds if specified
-	if opts.MinReadySeconds != nil {
-		md.Spec.MinReadySeconds = opts.MinReadySeconds
-	}
+		// Update replicas if specified
+		if opts.Replicas != nil {
+			md.Spec.Replicas = opts.Replicas
+		}
 
-	// Update nodeDrainTimeout if specified
-	if opts.NodeDrainTimeout != nil {
-		md.Spec.Template.Spec.NodeDrainTimeout = opts.NodeDrainTimeout
-	}
+		// Update minReadySeconds if specified
+		if opts.MinReadySeconds != nil {
+			md.Spec.MinReadySeconds = opts.MinReadySeconds
+		}
 
-	// Update labels
-	if opts.Labels != nil {
-		if md.Labels == nil {
-			md.Labels = make(map[string]string)
+		// Update nodeDrainTimeout if specified
+		if opts.NodeDrainTimeout != nil {
+			md.Spec.Template.Spec.NodeDrainTimeout = opts.NodeDrainTimeout
 		}
-		for k, v := range opts.Labels {
-			if v == "" {
-				delete(md.Labels, k)
-			} else {
-				md.Labels[k] = v
+
+		// Update labels
+		if opts.Labels != nil {
+			if md.Labels == nil {
+				md.Labels = make(map[string]string)
+			}
+			for k, v := range opts.Labels {
+				if v == "" {
+					delete(md.Labels, k)
+				} else {
+					md.Labels[k] = v
+				}
 			}
 		}
+
+		// Update annotations
+		if opts.Annotations != nil {
+			if md.Annotations == nil {
+				md.Annotations = make(map[string]string)
+			}
+			for k, v := range opts.Annotations {
+				if v == "" {
+					delete(md.Annotations, k)
+				} else {
+					md.Annotations[k] = v
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update machine deployment: %w", err)
+	}
+
+	return md, nil
+}
+
+// checkWorkerVersion verifies a new version of a machine deployment against
+// its current version and the version of its control plane
+func (c *Client) checkWorkerVersion(ctx context.Context, key client.ObjectKey, newVersion string) error {
+	target, err := version.ParseSemantic(newVersion)
+	if err != nil {
+		return errorf(ErrInvalidArgument, "invalid version %q: %v", newVersion, err)
+	}
+	md, err := c.GetMachineDeployment(ctx, key.Namespace, key.Name)
+	if err != nil {
+		return err
 	}
 
-	// Update annotations
-	if opts.Annotations != nil {
-		if md.Annotations == nil {
-			md.Annotations = make(map[string]string)
+	var violations []string
+	if md.Spec.Template.Spec.Version != nil {
+		if current, err := version.ParseSemantic(*md.Spec.Template.Spec.Version); err == nil && current.GreaterThan(target) {
+			violations = append(violations, fmt.Sprintf("cannot downgrade from %s to %s", current, target))
 		}
-		for k, v := range opts.Annotations {
-			if v == "" {
-				delete(md.Annotations, k)
-			} else {
-				md.Annotations[k] = v
+	}
+
+	// The control plane version is best effort, as in GetClusterStatus
+	if cluster, err := c.GetCluster(ctx, key.Namespace, md.Spec.ClusterName); err == nil && needsControlPlaneVersion(cluster) {
+		if kcp, err := c.GetKubeadmControlPlane(ctx, key.Namespace, cluster.Spec.ControlPlaneRef.Name); err == nil {
+			if controlPlane, err := version.ParseSemantic(kcp.Spec.Version); err == nil {
+				if err := checkWorkerSkew(target, controlPlane); err != nil {
+					violations = append(violations, err.Error())
+				}
 			}
 		}
 	}
 
-	if err := c.ctrlClient.Update(ctx, md); err != nil {
-		return nil, fmt.Errorf("failed to update machine deployment: %w", err)
+	if len(violations) > 0 {
+		return skewError(violations)
 	}
-
-	return md, nil
+	return nil
 }
 
 // RolloutMachineDeploymentOptions contains options for triggering a rollout
@@ -922,23 +1041,26 @@ type RolloutMachineDeploymentOptions struct {
 
 // RolloutMachineDeployment triggers a rolling update of a MachineDeployment
 func (c *Client) RolloutMachineDeployment(ctx context.Context, opts RolloutMachineDeploymentOptions) error {
-	md, err := c.GetMachineDeployment(ctx, opts.Namespace, opts.Name)
-	if err != nil {
-		return fmt.Errorf("failed to get machine deployment: %w", err)
+	md := &clusterv1.MachineDeployment{}
+	key := client.ObjectKey{
+		Namespace: opts.Namespace,
+		Name:      opts.Name,
 	}
 
 	// Trigger rollout by updating an annotation
-	if md.Spec.Template.Annotations == nil {
-		md.Spec.Template.Annotations = make(map[string]string)
-	}
-
-	// Add rollout annotation with timestamp
-	md.Spec.Template.Annotations["cluster.x-k8s.io/rollout-triggered"] = fmt.Sprintf("%v", metav1.Now().Unix())
-	if opts.Reason != "" {
-		md.Spec.Template.Annotations["cluster.x-k8s.io/rollout-reason"] = opts.Reason
-	}
+	err := c.updateObject(ctx, key, md, "", func() error {
+		if md.Spec.Template.Annotations == nil {
+			md.Spec.Template.Annotations = make(map[string]string)
+		}
 
-	if err := c.ctrlClient.Update(ctx, md); err != nil {
+		// Add rollout annotation with timestamp
+		md.Spec.Template.Annotations["cluster.x-k8s.io/rollout-triggered"] = fmt.Sprintf("%v", metav1.Now().Unix())
+		if opts.Reason != "" {
+			md.Spec.Template.Annotations["cluster.x-k8s.io/rollout-reason"] = opts.Reason
+		}
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to trigger rollout: %w", err)
 	}
 
@@ -946,19 +1068,15 @@ func (c *Client) RolloutMachineDeployment(ctx context.Context, opts RolloutMachi
 }
 
 // ListMachineSets lists all MachineSets in a namespace
-func (c *Client) ListMachineSets(ctx context.Context, namespace, clusterName string) (*clusterv1.MachineSetList, error) {
+func (c *Client) ListMachineSets(ctx context.Context, namespace, clusterName string, listOpts ...ListOption) (*clusterv1.MachineSetList, error) {
 	msList := &clusterv1.MachineSetList{}
 
-	opts := []client.ListOption{
-		client.InNamespace(namespace),
-	}
-
 	// Filter by cluster if specified
-	if clusterName != "" {
-		opts = append(opts, client.MatchingLabels{
-			clusterv1.ClusterNameLabel: clusterName,
-		})
+	opts, err := clientListOptions(listOpts, clusterLabels(clusterName))
+	if err != nil {
+		return nil, err
 	}
+	opts = append(opts, client.InNamespace(namespace))
 
 	if err := c.ctrlClient.List(ctx, msList, opts...); err != nil {
 		return nil, fmt.Errorf("failed to list machine sets: %w", err)
@@ -976,7 +1094,7 @@ func (c *Client) GetMachineSet(ctx context.Context, namespace, name string) (*cl
 	}
 
 	if err := c.ctrlClient.Get(ctx, key, ms); err != nil {
-		return nil, fmt.Errorf("failed to get machine set %s/%s: %w", namespace, name, err)
+		return nil, fmt.Errorf("failed to get machine set %s/%s: %w", namespace, name, resourceError("MachineSet", key, err))
 	}
 
 	return ms, nil
@@ -996,8 +1114,12 @@ type NodeOperationOptions struct {
 	Uncordon bool
 }
 
-// DrainNode safely drains a node
+// DrainNode safely drains a node. The drain timeout bounds the whole
+// operation.
 func (c *Client) DrainNode(ctx context.Context, opts NodeOperationOptions) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Drain)
+	defer cancel()
+
 	// Get the node name from machine if not provided
 	nodeName := opts.NodeName
 	if nodeName == "" && opts.MachineName != "" {
@@ -1006,24 +1128,22 @@ func (c *Client) DrainNode(ctx context.Context, opts NodeOperationOptions) error
 			return fmt.Errorf("failed to get machine: %w", err)
 		}
 		if machine.Status.NodeRef == nil {
-			return fmt.Errorf("machine %s has no associated node", opts.MachineName)
+			return errorf(ErrPreconditionFailed, "machine %s has no associated node", opts.MachineName)
 		}
 		nodeName = machine.Status.NodeRef.Name
 	}
 
 	if nodeName == "" {
-		return fmt.Errorf("either nodeName or machineName must be provided")
+		return errorf(ErrInvalidArgument, "either nodeName or machineName must be provided")
 	}
 
-	// First cordon the node
-	node, err := c.k8sClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to get node %s: %w", nodeName, err)
+	// Verify RBAC before cordoning so the node is not left half-drained
+	if err := c.RequirePermissions(ctx, PermissionCheck{Verb: "update", Resource: "nodes", Name: nodeName}); err != nil {
+		return err
 	}
 
-	// Mark as unschedulable
-	node.Spec.Unschedulable = true
-	if _, err := c.k8sClient.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{}); err != nil {
+	// First cordon the node
+	if err := c.setNodeUnschedulable(ctx, nodeName, true); err != nil {
 		return fmt.Errorf("failed to cordon node %s: %w", nodeName, err)
 	}
 
@@ -1035,7 +1155,7 @@ func (c *Client) DrainNode(ctx context.Context, opts NodeOperationOptions) error
 	// 4. Wait for pods to be evicted
 
 	// For now, return a placeholder message
-	return fmt.Errorf("drain operation not fully implemented - node %s has been cordoned", nodeName)
+	return fmt.Errorf("drain operation not fully implemented for node %s: %w", nodeName, ErrDrainIncomplete)
 }
 
 // CordonNode cordons or uncordons a node
@@ -1048,24 +1168,17 @@ func (c *Client) CordonNode(ctx context.Context, opts NodeOperationOptions) erro
 			return fmt.Errorf("failed to get machine: %w", err)
 		}
 		if machine.Status.NodeRef == nil {
-			return fmt.Errorf("machine %s has no associated node", opts.MachineName)
+			return errorf(ErrPreconditionFailed, "machine %s has no associated node", opts.MachineName)
 		}
 		nodeName = machine.Status.NodeRef.Name
 	}
 
 	if nodeName == "" {
-		return fmt.Errorf("either nodeName or machineName must be provided")
-	}
-
-	node, err := c.k8sClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to get node %s: %w", nodeName, err)
+		return errorf(ErrInvalidArgument, "either nodeName or machineName must be provided")
 	}
 
 	// Update schedulable status
-	node.Spec.Unschedulable = !opts.Uncordon
-
-	if _, err := c.k8sClient.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{}); err != nil {
+	if err := c.setNodeUnschedulable(ctx, nodeName, !opts.Uncordon); err != nil {
 		return fmt.Errorf("failed to update node %s: %w", nodeName, err)
 	}
 
@@ -1082,13 +1195,13 @@ func (c *Client) GetNodeStatus(ctx context.Context, opts NodeOperationOptions) (
 			return nil, fmt.Errorf("failed to get machine: %w", err)
 		}
 		if machine.Status.NodeRef == nil {
-			return nil, fmt.Errorf("machine %s has no associated node", opts.MachineName)
+			return nil, errorf(ErrPreconditionFailed, "machine %s has no associated node", opts.MachineName)
 		}
 		nodeName = machine.Status.NodeRef.Name
 	}
 
 	if nodeName == "" {
-		return nil, fmt.Errorf("either nodeName or machineName must be provided")
+		return nil, errorf(ErrInvalidArgument, "either nodeName or machineName must be provided")
 	}
 
 	// Get node from workload cluster
@@ -1096,7 +1209,7 @@ func (c *Client) GetNodeStatus(ctx context.Context, opts NodeOperationOptions) (
 	// you would need to get the workload cluster kubeconfig and create a client for it
 	node, err := c.k8sClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get node %s: %w", nodeName, err)
+		return nil, fmt.Errorf("failed to get node %s: %w", nodeName, nodeError(nodeName, err))
 	}
 
 	return node, nil
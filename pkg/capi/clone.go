@@ -0,0 +1,373 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// cloneDroppedAnnotations are not copied to a clone, since they describe the
+// state of the source rather than its configuration
+var cloneDroppedAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"machinedeployment.clusters.x-k8s.io/revision",
+	clusterv1.PausedAnnotation,
+	HibernatedAnnotation,
+}
+
+// CloneClusterOptions contains options for cloning a cluster
+type CloneClusterOptions struct {
+	SourceNamespace string
+	SourceName      string
+	// Namespace of the clone, the namespace of the source when empty
+	Namespace string
+	Name      string
+	// PodCIDRs and ServiceCIDRs replace the cluster network ranges of the
+	// source when set
+	PodCIDRs     []string
+	ServiceCIDRs []string
+	// DryRun returns the objects of the clone without creating them
+	DryRun bool
+}
+
+// ClonedObject is an object created for a clone
+type ClonedObject struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Source is the name of the object it was copied from
+	Source string `json:"source"`
+}
+
+// ClusterClone describes a cloned cluster
+type ClusterClone struct {
+	Source    string `json:"source"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Topology is set when the source is managed by a ClusterClass, in which
+	// case only the Cluster is copied
+	Topology bool           `json:"topology"`
+	Objects  []ClonedObject `json:"objects"`
+	DryRun   bool           `json:"dryRun"`
+	// Manifests holds the objects of a dry run as YAML
+	Manifests string `json:"manifests,omitempty"`
+}
+
+// CloneCluster creates a new cluster from the configuration of an existing
+// one. A cluster with a topology is copied as its Cluster only; otherwise
+// its infrastructure cluster, control plane, machine deployments and machine
+// pools are copied with their templates. Names starting with the source
+// cluster name get the new name instead, other names get it as a prefix.
+// Status, endpoints and the paused and hibernated markers are not copied, so
+// the providers create fresh infrastructure; replicas scaled down by
+// hibernation are restored. Objects created before a failure are deleted.
+func (c *Client) CloneCluster(ctx context.Context, opts CloneClusterOptions) (*ClusterClone, error) {
+	if opts.Namespace == "" {
+		opts.Namespace = opts.SourceNamespace
+	}
+	if opts.Name == "" {
+		return nil, errorf(ErrInvalidArgument, "name of the clone is required")
+	}
+	if opts.Namespace == opts.SourceNamespace && opts.Name == opts.SourceName {
+		return nil, errorf(ErrInvalidArgument, "the clone needs another name or namespace than its source")
+	}
+	for _, cidr := range append(append([]string{}, opts.PodCIDRs...), opts.ServiceCIDRs...) {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, errorf(ErrInvalidArgument, "invalid CIDR block %q", cidr)
+		}
+	}
+
+	sourceKey := client.ObjectKey{Namespace: opts.SourceNamespace, Name: opts.SourceName}
+	source := &unstructured.Unstructured{}
+	source.SetGroupVersionKind(clusterv1.GroupVersion.WithKind("Cluster"))
+	if err := c.ctrlClient.Get(ctx, sourceKey, source); err != nil {
+		return nil, fmt.Errorf("failed to get cluster: %w", resourceError("Cluster", sourceKey, err))
+	}
+	targetKey := client.ObjectKey{Namespace: opts.Namespace, Name: opts.Name}
+	err := c.ctrlClient.Get(ctx, targetKey, &clusterv1.Cluster{})
+	if err == nil {
+		return nil, errorf(ErrAlreadyExists, "cluster %s/%s already exists", opts.Namespace, opts.Name)
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get cluster: %w", resourceError("Cluster", targetKey, err))
+	}
+
+	cloner := &clusterCloner{client: c, opts: opts, sources: map[*unstructured.Unstructured]string{}, names: map[string]string{}}
+	clone := &ClusterClone{
+		Source:    opts.SourceNamespace + "/" + opts.SourceName,
+		Namespace: opts.Namespace,
+		Name:      opts.Name,
+		DryRun:    opts.DryRun,
+	}
+	if err := cloner.cloneCluster(ctx, source, clone); err != nil {
+		return nil, err
+	}
+
+	for _, obj := range cloner.objects {
+		clone.Objects = append(clone.Objects, ClonedObject{
+			Kind:   obj.GetKind(),
+			Name:   obj.GetName(),
+			Source: cloner.sources[obj],
+		})
+	}
+	if opts.DryRun {
+		manifests := make([]string, 0, len(cloner.objects))
+		for _, obj := range cloner.objects {
+			data, err := yaml.Marshal(obj.Object)
+			if err != nil {
+				return nil, fmt.Errorf("failed to render %s %s: %w", obj.GetKind(), obj.GetName(), err)
+			}
+			manifests = append(manifests, string(data))
+		}
+		clone.Manifests = strings.Join(manifests, "---\n")
+		return clone, nil
+	}
+
+	if err := c.createAll(ctx, cloner.objects); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// createAll creates objects in order. When one fails, the objects created
+// before it are deleted so no partial cluster is left behind.
+func (c *Client) createAll(ctx context.Context, objects []*unstructured.Unstructured) error {
+	for i, obj := range objects {
+		if err := c.ctrlClient.Create(ctx, obj); err != nil {
+			kind := obj.GetKind()
+			err = fmt.Errorf("failed to create %s: %w", kind, resourceError(kind, client.ObjectKeyFromObject(obj), err))
+			for j := i - 1; j >= 0; j-- {
+				if deleteErr := c.ctrlClient.Delete(ctx, objects[j]); deleteErr != nil && !apierrors.IsNotFound(deleteErr) {
+					err = fmt.Errorf("%w; failed to clean up %s %s: %v", err, objects[j].GetKind(), objects[j].GetName(), deleteErr)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// clusterCloner collects the copies of the objects of a cluster, referenced
+// objects before the objects referencing them
+type clusterCloner struct {
+	client  *Client
+	opts    CloneClusterOptions
+	objects []*unstructured.Unstructured
+	// sources are the names of the objects the copies were made from
+	sources map[*unstructured.Unstructured]string
+	// names are the names of the copies of referenced objects by kind and
+	// name, so shared templates are copied once
+	names map[string]string
+}
+
+// cloneCluster copies the Cluster and, without a topology, the objects it
+// consists of
+func (c *clusterCloner) cloneCluster(ctx context.Context, source *unstructured.Unstructured, clone *ClusterClone) error {
+	cluster := c.copy(source, c.opts.Name)
+	unstructured.RemoveNestedField(cluster.Object, "spec", "controlPlaneEndpoint")
+	if len(c.opts.PodCIDRs) > 0 {
+		if err := unstructured.SetNestedStringSlice(cluster.Object, c.opts.PodCIDRs, "spec", "clusterNetwork", "pods", "cidrBlocks"); err != nil {
+			return fmt.Errorf("failed to set pod CIDRs: %w", err)
+		}
+	}
+	if len(c.opts.ServiceCIDRs) > 0 {
+		if err := unstructured.SetNestedStringSlice(cluster.Object, c.opts.ServiceCIDRs, "spec", "clusterNetwork", "services", "cidrBlocks"); err != nil {
+			return fmt.Errorf("failed to set service CIDRs: %w", err)
+		}
+	}
+
+	if _, ok, _ := unstructured.NestedMap(cluster.Object, "spec", "topology"); ok {
+		// The topology controller creates the other objects from the
+		// ClusterClass, which stays in the namespace of the source
+		clone.Topology = true
+		classNamespace, _, _ := unstructured.NestedString(cluster.Object, "spec", "topology", "classNamespace")
+		if classNamespace == "" && c.opts.Namespace != c.opts.SourceNamespace {
+			if err := unstructured.SetNestedField(cluster.Object, c.opts.SourceNamespace, "spec", "topology", "classNamespace"); err != nil {
+				return fmt.Errorf("failed to set the ClusterClass namespace: %w", err)
+			}
+		}
+		c.add(cluster, source.GetName())
+		return nil
+	}
+
+	if err := c.cloneRef(ctx, cluster, []string{"spec", "infrastructureRef"}, func(obj *unstructured.Unstructured) error {
+		unstructured.RemoveNestedField(obj.Object, "spec", "controlPlaneEndpoint")
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := c.cloneRef(ctx, cluster, []string{"spec", "controlPlaneRef"}, func(obj *unstructured.Unstructured) error {
+		return c.cloneRef(ctx, obj, []string{"spec", "machineTemplate", "infrastructureRef"}, nil)
+	}); err != nil {
+		return err
+	}
+	c.add(cluster, source.GetName())
+
+	for _, kind := range []string{"MachineDeployment", "MachinePool"} {
+		if err := c.cloneWorkers(ctx, kind); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloneWorkers copies the machine deployments or machine pools of the source
+// cluster with the objects their machine template references
+func (c *clusterCloner) cloneWorkers(ctx context.Context, kind string) error {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(clusterv1.GroupVersion.WithKind(kind + "List"))
+	if err := c.client.ctrlClient.List(ctx, list, client.InNamespace(c.opts.SourceNamespace)); err != nil {
+		if kind == "MachinePool" && (meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err)) {
+			return nil
+		}
+		return fmt.Errorf("failed to list %ss: %w", kind, err)
+	}
+	for i := range list.Items {
+		source := &list.Items[i]
+		if clusterName, _, _ := unstructured.NestedString(source.Object, "spec", "clusterName"); clusterName != c.opts.SourceName {
+			continue
+		}
+		obj := c.copy(source, c.rename(source.GetName()))
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[clusterv1.ClusterNameLabel] = c.opts.Name
+		obj.SetLabels(labels)
+		for _, path := range [][]string{
+			{"spec", "selector", "matchLabels"},
+			{"spec", "template", "metadata", "labels"},
+		} {
+			if _, ok, _ := unstructured.NestedStringMap(obj.Object, path...); ok {
+				_ = unstructured.SetNestedField(obj.Object, c.opts.Name, append(path, clusterv1.ClusterNameLabel)...)
+			}
+		}
+		_ = unstructured.SetNestedField(obj.Object, c.opts.Name, "spec", "template", "spec", "clusterName")
+
+		// Restore the replicas of a hibernated source
+		annotations := obj.GetAnnotations()
+		if recorded, ok := annotations[HibernatedReplicasAnnotation]; ok {
+			if replicas, err := strconv.ParseInt(recorded, 10, 64); err == nil {
+				_ = unstructured.SetNestedField(obj.Object, replicas, "spec", "replicas")
+			}
+			delete(annotations, HibernatedReplicasAnnotation)
+			obj.SetAnnotations(annotations)
+		}
+
+		for _, path := range [][]string{
+			{"spec", "template", "spec", "bootstrap", "configRef"},
+			{"spec", "template", "spec", "infrastructureRef"},
+		} {
+			if err := c.cloneRef(ctx, obj, path, nil); err != nil {
+				return err
+			}
+		}
+		c.add(obj, source.GetName())
+	}
+	return nil
+}
+
+// cloneRef copies the object referenced at path of obj, unless it was copied
+// already, and points the reference to the copy. mutate adjusts the copy
+// before it is added. Missing references are left alone.
+func (c *clusterCloner) cloneRef(ctx context.Context, obj *unstructured.Unstructured, path []string, mutate func(*unstructured.Unstructured) error) error {
+	ref, ok, _ := unstructured.NestedStringMap(obj.Object, path...)
+	if !ok || ref["name"] == "" {
+		return nil
+	}
+	gv, err := schema.ParseGroupVersion(ref["apiVersion"])
+	if err != nil {
+		return fmt.Errorf("invalid reference %s of %s %s: %w", strings.Join(path, "."), obj.GetKind(), obj.GetName(), err)
+	}
+	gvk := gv.WithKind(ref["kind"])
+	key := gvk.GroupKind().String() + "/" + ref["name"]
+
+	name, copied := c.names[key]
+	if !copied {
+		namespace := ref["namespace"]
+		if namespace == "" {
+			namespace = c.opts.SourceNamespace
+		}
+		sourceKey := client.ObjectKey{Namespace: namespace, Name: ref["name"]}
+		source := &unstructured.Unstructured{}
+		source.SetGroupVersionKind(gvk)
+		if err := c.client.ctrlClient.Get(ctx, sourceKey, source); err != nil {
+			return fmt.Errorf("failed to get %s: %w", gvk.Kind, resourceError(gvk.Kind, sourceKey, err))
+		}
+		name = c.rename(source.GetName())
+		c.names[key] = name
+		cloned := c.copy(source, name)
+		if mutate != nil {
+			if err := mutate(cloned); err != nil {
+				return err
+			}
+		}
+		c.add(cloned, source.GetName())
+	}
+
+	ref["name"] = name
+	if ref["namespace"] != "" {
+		ref["namespace"] = c.opts.Namespace
+	}
+	delete(ref, "uid")
+	delete(ref, "resourceVersion")
+	return unstructured.SetNestedStringMap(obj.Object, ref, path...)
+}
+
+// copy returns a copy of an object under a new name in the namespace of the
+// clone, without its status and server-set metadata, belonging to the clone
+func (c *clusterCloner) copy(source *unstructured.Unstructured, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": source.GetAPIVersion(),
+		"kind":       source.GetKind(),
+	}}
+	if spec, ok := source.Object["spec"]; ok {
+		obj.Object["spec"] = runtime.DeepCopyJSONValue(spec)
+	}
+	obj.SetNamespace(c.opts.Namespace)
+	obj.SetName(name)
+
+	labels := source.GetLabels()
+	if labels[clusterv1.ClusterNameLabel] == c.opts.SourceName {
+		labels[clusterv1.ClusterNameLabel] = c.opts.Name
+	}
+	obj.SetLabels(labels)
+	annotations := source.GetAnnotations()
+	for _, annotation := range cloneDroppedAnnotations {
+		delete(annotations, annotation)
+	}
+	obj.SetAnnotations(annotations)
+
+	if clusterName, _, _ := unstructured.NestedString(obj.Object, "spec", "clusterName"); clusterName == c.opts.SourceName {
+		_ = unstructured.SetNestedField(obj.Object, c.opts.Name, "spec", "clusterName")
+	}
+	return obj
+}
+
+// rename derives the name of a copy from the name of its source. Copies in
+// place, as for a GitOps export, keep the names.
+func (c *clusterCloner) rename(name string) string {
+	if c.opts.Name == c.opts.SourceName && c.opts.Namespace == c.opts.SourceNamespace {
+		return name
+	}
+	if strings.HasPrefix(name, c.opts.SourceName) {
+		return c.opts.Name + strings.TrimPrefix(name, c.opts.SourceName)
+	}
+	return c.opts.Name + "-" + name
+}
+
+// add records a copy and the name of its source
+func (c *clusterCloner) add(obj *unstructured.Unstructured, source string) {
+	c.objects = append(c.objects, obj)
+	c.sources[obj] = source
+}
@@ -0,0 +1,189 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCloneCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	// A hibernated CAPD cluster, the workers sharing one machine template
+	source := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "org-acme",
+			Name:        "golden",
+			Labels:      map[string]string{"env": "dev"},
+			Annotations: map[string]string{clusterv1.PausedAnnotation: "true", HibernatedAnnotation: "2026-03-02T20:00:00Z"},
+		},
+		Spec: clusterv1.ClusterSpec{
+			ClusterNetwork: &clusterv1.ClusterNetwork{
+				Pods:     &clusterv1.NetworkRanges{CIDRBlocks: []string{"192.168.0.0/16"}},
+				Services: &clusterv1.NetworkRanges{CIDRBlocks: []string{"10.128.0.0/12"}},
+			},
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "172.18.0.3", Port: 6443},
+			ControlPlaneRef:      &corev1.ObjectReference{APIVersion: controlplanev1.GroupVersion.String(), Kind: "KubeadmControlPlane", Name: "golden-control-plane"},
+			InfrastructureRef:    &corev1.ObjectReference{APIVersion: dockerClusterGVK.GroupVersion().String(), Kind: "DockerCluster", Name: "golden"},
+		},
+	}
+	dockerCluster := newUnstructured(dockerClusterGVK, "org-acme", "golden", nil)
+	dockerCluster.Object["spec"] = map[string]any{"controlPlaneEndpoint": map[string]any{"host": "172.18.0.3", "port": int64(6443)}}
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "golden-control-plane"},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			Replicas: ptr.To[int32](1),
+			Version:  "v1.31.2",
+			MachineTemplate: controlplanev1.KubeadmControlPlaneMachineTemplate{InfrastructureRef: corev1.ObjectReference{
+				APIVersion: dockerMachineTemplateGVK.GroupVersion().String(), Kind: "DockerMachineTemplate", Name: "golden-control-plane",
+			}},
+		},
+	}
+	md := func(name string, annotations map[string]string) *clusterv1.MachineDeployment {
+		return &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: name, Annotations: annotations},
+			Spec: clusterv1.MachineDeploymentSpec{
+				ClusterName: "golden",
+				Replicas:    ptr.To[int32](0),
+				Selector:    metav1.LabelSelector{MatchLabels: map[string]string{clusterv1.ClusterNameLabel: "golden"}},
+				Template: clusterv1.MachineTemplateSpec{
+					ObjectMeta: clusterv1.ObjectMeta{Labels: map[string]string{clusterv1.ClusterNameLabel: "golden"}},
+					Spec: clusterv1.MachineSpec{
+						ClusterName: "golden",
+						Bootstrap: clusterv1.Bootstrap{ConfigRef: &corev1.ObjectReference{
+							APIVersion: kubeadmConfigTemplateGVK.GroupVersion().String(), Kind: "KubeadmConfigTemplate", Name: "workers",
+						}},
+						InfrastructureRef: corev1.ObjectReference{
+							APIVersion: dockerMachineTemplateGVK.GroupVersion().String(), Kind: "DockerMachineTemplate", Name: "workers",
+						},
+					},
+				},
+			},
+		}
+	}
+	other := md("other-md-0", nil)
+	other.Spec.ClusterName = "other"
+
+	ctrlClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		source, dockerCluster, kcp,
+		newUnstructured(dockerMachineTemplateGVK, "org-acme", "golden-control-plane", nil),
+		newUnstructured(dockerMachineTemplateGVK, "org-acme", "workers", nil),
+		newUnstructured(kubeadmConfigTemplateGVK, "org-acme", "workers", nil),
+		md("golden-md-0", map[string]string{HibernatedReplicasAnnotation: "3"}),
+		md("golden-md-1", nil),
+		other,
+	).Build()
+	c := &Client{ctrlClient: ctrlClient}
+	ctx := context.Background()
+	opts := CloneClusterOptions{SourceNamespace: "org-acme", SourceName: "golden", Namespace: "org-acme", Name: "copy", PodCIDRs: []string{"10.244.0.0/16"}}
+
+	// A dry run only renders the objects
+	opts.DryRun = true
+	clone, err := c.CloneCluster(ctx, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clone.Objects) != 8 || !strings.Contains(clone.Manifests, "name: copy-md-1") {
+		t.Errorf("dry run = %+v", clone)
+	}
+	if err := ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "copy"}, &clusterv1.Cluster{}); err == nil {
+		t.Error("dry run created the cluster")
+	}
+
+	opts.DryRun = false
+	if _, err := c.CloneCluster(ctx, opts); err != nil {
+		t.Fatal(err)
+	}
+	cluster, err := c.GetCluster(ctx, "org-acme", "copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cluster.Spec.ControlPlaneEndpoint.Host != "" || cluster.Annotations[clusterv1.PausedAnnotation] != "" || cluster.Labels["env"] != "dev" {
+		t.Errorf("cluster = %+v, want the endpoint and paused annotation dropped", cluster.ObjectMeta)
+	}
+	if got := cluster.Spec.ClusterNetwork; got.Pods.CIDRBlocks[0] != "10.244.0.0/16" || got.Services.CIDRBlocks[0] != "10.128.0.0/12" {
+		t.Errorf("cluster network = %+v", got)
+	}
+	if cluster.Spec.ControlPlaneRef.Name != "copy-control-plane" || cluster.Spec.InfrastructureRef.Name != "copy" {
+		t.Errorf("cluster refs = %+v, %+v", cluster.Spec.ControlPlaneRef, cluster.Spec.InfrastructureRef)
+	}
+	copiedKCP, err := c.GetKubeadmControlPlane(ctx, "org-acme", "copy-control-plane")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if copiedKCP.Spec.Version != "v1.31.2" || copiedKCP.Spec.MachineTemplate.InfrastructureRef.Name != "copy-control-plane" {
+		t.Errorf("control plane = %+v", copiedKCP.Spec)
+	}
+
+	mds, err := c.ListMachineDeployments(ctx, "org-acme", "copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mds.Items) != 2 {
+		t.Fatalf("machine deployments = %d, want 2", len(mds.Items))
+	}
+	for _, md := range mds.Items {
+		if md.Spec.Template.Spec.InfrastructureRef.Name != "copy-workers" || md.Spec.Template.Spec.Bootstrap.ConfigRef.Name != "copy-workers" ||
+			md.Spec.Selector.MatchLabels[clusterv1.ClusterNameLabel] != "copy" || md.Spec.Template.Spec.ClusterName != "copy" {
+			t.Errorf("machine deployment %s = %+v", md.Name, md.Spec)
+		}
+	}
+	if *mds.Items[0].Spec.Replicas != 3 || mds.Items[0].Annotations[HibernatedReplicasAnnotation] != "" {
+		t.Errorf("hibernated machine deployment copied with %d replicas, want 3", *mds.Items[0].Spec.Replicas)
+	}
+	copied := &unstructured.Unstructured{}
+	copied.SetGroupVersionKind(dockerClusterGVK)
+	if err := ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "copy"}, copied); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := copied.Object["spec"].(map[string]any)["controlPlaneEndpoint"]; ok {
+		t.Errorf("infrastructure cluster copied with its endpoint: %v", copied.Object["spec"])
+	}
+
+	if _, err := c.CloneCluster(ctx, opts); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("second clone error = %v, want ErrAlreadyExists", err)
+	}
+}
+
+func TestCloneTopologyCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	ctrlClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "golden"},
+		Spec:       clusterv1.ClusterSpec{Topology: &clusterv1.Topology{Class: "quick-start", Version: "v1.31.2"}},
+	}).Build()
+	c := &Client{ctrlClient: ctrlClient}
+
+	clone, err := c.CloneCluster(context.Background(), CloneClusterOptions{SourceNamespace: "org-acme", SourceName: "golden", Namespace: "org-test", Name: "golden"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !clone.Topology || len(clone.Objects) != 1 {
+		t.Errorf("clone = %+v, want the Cluster only", clone)
+	}
+	cluster, err := c.GetCluster(context.Background(), "org-test", "golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cluster.Spec.Topology.ClassNamespace != "org-acme" {
+		t.Errorf("class namespace = %q, want the namespace of the source", cluster.Spec.Topology.ClassNamespace)
+	}
+}
@@ -0,0 +1,258 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/version"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CNI is a container network plugin the client can install
+type CNI string
+
+// Supported CNIs
+const (
+	CNICalico CNI = "calico"
+	CNICilium CNI = "cilium"
+)
+
+// DefaultCNIVersions are the pinned versions of the supported CNIs
+var DefaultCNIVersions = map[CNI]string{
+	CNICalico: "v3.28.2",
+	CNICilium: "1.16.3",
+}
+
+// ciliumCLIImage installs Cilium from its Helm chart, which has no plain
+// manifest to apply
+const ciliumCLIImage = "quay.io/cilium/cilium-cli:v0.16.19"
+
+// ciliumInstaller names the objects of the Cilium installation Job
+const ciliumInstaller = "mcp-capi-cilium-installer"
+
+// CNIRepository serves the manifests of CNIs installed from a manifest
+type CNIRepository interface {
+	// CNIManifest returns the manifest of a CNI release
+	CNIManifest(ctx context.Context, cni CNI, version string) ([]byte, error)
+}
+
+// cniManifests locates the manifests of CNIs on GitHub
+var cniManifests = map[CNI]string{
+	CNICalico: "projectcalico/calico/%s/manifests/calico.yaml",
+}
+
+// CNIManifest downloads the manifest of a CNI release
+func (r *GitHubRepository) CNIManifest(ctx context.Context, cni CNI, version string) ([]byte, error) {
+	path, ok := cniManifests[cni]
+	if !ok {
+		return nil, errorf(ErrInvalidArgument, "%s is not installed from a manifest", cni)
+	}
+	rawURL := r.RawURL
+	if rawURL == "" {
+		rawURL = "https://raw.githubusercontent.com"
+	}
+	return r.get(ctx, strings.TrimSuffix(rawURL, "/")+"/"+fmt.Sprintf(path, version))
+}
+
+// CNIOptions selects the CNI to install in a workload cluster
+type CNIOptions struct {
+	CNI CNI
+	// Version defaults to the pinned version of DefaultCNIVersions
+	Version string
+}
+
+// CNIInstallation describes a CNI applied to a workload cluster
+type CNIInstallation struct {
+	CNI     CNI    `json:"cni"`
+	Version string `json:"version"`
+	PodCIDR string `json:"podCIDR"`
+	Objects int    `json:"objects"`
+	// Job installs Cilium in the workload cluster, as namespace/name
+	Job string `json:"job,omitempty"`
+}
+
+// InstallCNI applies a CNI to a workload cluster with its admin kubeconfig,
+// configured with the pod CIDR of the Cluster. Calico is applied from its
+// release manifest; Cilium by a Job running the Cilium CLI, which renders
+// its Helm chart. Applying the same CNI again is idempotent; a cluster
+// running another CNI is refused.
+func (c *Client) InstallCNI(ctx context.Context, repo CNIRepository, namespace, clusterName string, opts CNIOptions) (*CNIInstallation, error) {
+	defaultVersion, ok := DefaultCNIVersions[opts.CNI]
+	if !ok {
+		return nil, errorf(ErrInvalidArgument, "unsupported CNI %q, supported CNIs: %s, %s", opts.CNI, CNICalico, CNICilium)
+	}
+	cniVersion := opts.Version
+	if cniVersion == "" {
+		cniVersion = defaultVersion
+	}
+	if _, err := version.ParseSemantic(cniVersion); err != nil {
+		return nil, errorf(ErrInvalidArgument, "invalid %s version %q: %v", opts.CNI, cniVersion, err)
+	}
+	// Calico tags its releases with a v prefix, Cilium does not
+	cniVersion = strings.TrimPrefix(cniVersion, "v")
+	if opts.CNI == CNICalico {
+		cniVersion = "v" + cniVersion
+	}
+
+	cluster, err := c.GetCluster(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	podCIDR, err := ipv4PodCIDR(cluster)
+	if err != nil {
+		return nil, err
+	}
+	kubeconfig, err := c.GetKubeconfig(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	workload, err := c.workloadClient(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := workload.List(ctx, daemonSets); err != nil {
+		return nil, fmt.Errorf("failed to list daemon sets: %w", workloadError("DaemonSet", client.ObjectKey{}, err))
+	}
+	for _, ds := range daemonSets.Items {
+		if category, addon := classifyAddon(ds.Name); category == AddonCNI && addon != string(opts.CNI) {
+			return nil, errorf(ErrPreconditionFailed, "cluster %s/%s already runs %s (DaemonSet %s/%s)", namespace, clusterName, addon, ds.Namespace, ds.Name)
+		}
+	}
+
+	installation := &CNIInstallation{CNI: opts.CNI, Version: cniVersion, PodCIDR: podCIDR}
+	var objects []client.Object
+	switch opts.CNI {
+	case CNICalico:
+		data, err := repo.CNIManifest(ctx, CNICalico, cniVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch the manifest of calico %s: %w", cniVersion, err)
+		}
+		manifest, err := decodeManifest(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the manifest of calico %s: %w", cniVersion, err)
+		}
+		if err := setCalicoPodCIDR(manifest, podCIDR); err != nil {
+			return nil, err
+		}
+		for _, obj := range manifest {
+			objects = append(objects, obj)
+		}
+	case CNICilium:
+		objects = ciliumInstallObjects(cniVersion, podCIDR)
+		installation.Job = metav1.NamespaceSystem + "/" + objects[len(objects)-1].GetName()
+	}
+
+	for _, obj := range objects {
+		if err := workload.Patch(ctx, obj, client.Apply, client.FieldOwner(providerFieldManager), client.ForceOwnership); err != nil {
+			kind := obj.GetObjectKind().GroupVersionKind().Kind
+			return installation, fmt.Errorf("failed to apply %s %s: %w", kind, obj.GetName(), workloadError(kind, client.ObjectKeyFromObject(obj), err))
+		}
+		installation.Objects++
+	}
+	return installation, nil
+}
+
+// ipv4PodCIDR returns the first IPv4 pod CIDR of a cluster
+func ipv4PodCIDR(cluster *clusterv1.Cluster) (string, error) {
+	if cluster.Spec.ClusterNetwork != nil && cluster.Spec.ClusterNetwork.Pods != nil {
+		for _, cidr := range cluster.Spec.ClusterNetwork.Pods.CIDRBlocks {
+			if ip, _, err := net.ParseCIDR(cidr); err == nil && ip.To4() != nil {
+				return cidr, nil
+			}
+		}
+	}
+	return "", errorf(ErrPreconditionFailed, "cluster %s/%s has no IPv4 pod CIDR in spec.clusterNetwork.pods.cidrBlocks", cluster.Namespace, cluster.Name)
+}
+
+// setCalicoPodCIDR sets the IP pool of the calico-node DaemonSet of a Calico
+// manifest to the pod CIDR, which the manifest leaves commented out
+func setCalicoPodCIDR(objects []*unstructured.Unstructured, podCIDR string) error {
+	for _, obj := range objects {
+		if obj.GetKind() != "DaemonSet" || obj.GetName() != "calico-node" {
+			continue
+		}
+		containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+		for i, container := range containers {
+			container, ok := container.(map[string]any)
+			if !ok || container["name"] != "calico-node" {
+				continue
+			}
+			env, _, _ := unstructured.NestedSlice(container, "env")
+			found := false
+			for j, variable := range env {
+				if variable, ok := variable.(map[string]any); ok && variable["name"] == "CALICO_IPV4POOL_CIDR" {
+					env[j] = map[string]any{"name": "CALICO_IPV4POOL_CIDR", "value": podCIDR}
+					found = true
+				}
+			}
+			if !found {
+				env = append(env, map[string]any{"name": "CALICO_IPV4POOL_CIDR", "value": podCIDR})
+			}
+			container["env"] = env
+			containers[i] = container
+			return unstructured.SetNestedSlice(obj.Object, containers, "spec", "template", "spec", "containers")
+		}
+	}
+	return fmt.Errorf("the calico manifest has no calico-node container")
+}
+
+// ciliumInstallObjects returns a Job installing Cilium with the Cilium CLI and
+// the ServiceAccount it runs as. The Job uses the host network and tolerates
+// every taint, since nodes are not ready before a CNI runs.
+func ciliumInstallObjects(ciliumVersion, podCIDR string) []client.Object {
+	labels := map[string]string{"app.kubernetes.io/managed-by": providerFieldManager}
+	serviceAccount := &corev1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceSystem, Name: ciliumInstaller, Labels: labels},
+	}
+	binding := &rbacv1.ClusterRoleBinding{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRoleBinding"},
+		ObjectMeta: metav1.ObjectMeta{Name: ciliumInstaller, Labels: labels},
+		RoleRef:    rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "cluster-admin"},
+		Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Namespace: metav1.NamespaceSystem, Name: ciliumInstaller}},
+	}
+	backoffLimit := int32(5)
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{APIVersion: "batch/v1", Kind: "Job"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: metav1.NamespaceSystem,
+			// Jobs cannot be changed, so each version has its own
+			Name:   "cilium-install-" + strings.ReplaceAll(ciliumVersion, ".", "-"),
+			Labels: labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: ciliumInstaller,
+					HostNetwork:        true,
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Tolerations:        []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+					Containers: []corev1.Container{{
+						Name:  "cilium-cli",
+						Image: ciliumCLIImage,
+						Command: []string{
+							"cilium", "install",
+							"--version", ciliumVersion,
+							"--set", "ipam.mode=cluster-pool",
+							"--set", "ipam.operator.clusterPoolIPv4PodCIDRList={" + podCIDR + "}",
+						},
+					}},
+				},
+			},
+		},
+	}
+	return []client.Object{serviceAccount, binding, job}
+}
@@ -0,0 +1,135 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// fakeCNIRepository serves CNI manifests from memory
+type fakeCNIRepository map[string]string
+
+func (r fakeCNIRepository) CNIManifest(_ context.Context, cni CNI, version string) ([]byte, error) {
+	if manifest, ok := r[string(cni)+"@"+version]; ok {
+		return []byte(manifest), nil
+	}
+	return nil, errorf(ErrNotFound, "%s %s not found", cni, version)
+}
+
+const calicoManifest = `apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: calico-node
+  namespace: kube-system
+spec:
+  template:
+    spec:
+      containers:
+      - name: calico-node
+        env:
+        - name: DATASTORE_TYPE
+          value: kubernetes
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: ippools.crd.projectcalico.org
+`
+
+func TestInstallCNI(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	newCluster := func(name string, cidrs ...string) *clusterv1.Cluster {
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: name}}
+		if len(cidrs) > 0 {
+			cluster.Spec.ClusterNetwork = &clusterv1.ClusterNetwork{Pods: &clusterv1.NetworkRanges{CIDRBlocks: cidrs}}
+		}
+		return cluster
+	}
+	kubeconfig := func(name string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: name + "-kubeconfig"}, Data: map[string][]byte{"value": []byte(name)}}
+	}
+
+	var applied []client.Object
+	workloads := map[string][]client.Object{
+		"cilium-cluster": {&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "cilium"}}},
+	}
+	c := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newCluster("prod", "fd00::/104", "192.168.0.0/16"),
+			newCluster("cilium-cluster", "10.244.0.0/16"),
+			newCluster("no-cidr"),
+		).Build(),
+		k8sClient: k8sfake.NewClientset(kubeconfig("prod"), kubeconfig("cilium-cluster"), kubeconfig("no-cidr")),
+		newWorkloadClient: func(kubeconfig string) (client.Client, error) {
+			return fake.NewClientBuilder().WithObjects(workloads[kubeconfig]...).WithInterceptorFuncs(interceptor.Funcs{
+				Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					applied = append(applied, obj)
+					return nil
+				},
+			}).Build(), nil
+		},
+	}
+	repo := fakeCNIRepository{"calico@v3.28.2": calicoManifest}
+
+	installation, err := c.InstallCNI(context.Background(), repo, "org-acme", "prod", CNIOptions{CNI: CNICalico, Version: "3.28.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if installation.Version != "v3.28.2" || installation.PodCIDR != "192.168.0.0/16" || installation.Objects != 2 || len(applied) != 2 {
+		t.Fatalf("installation = %+v, applied %d objects", installation, len(applied))
+	}
+	if applied[0].(*unstructured.Unstructured).GetKind() != "CustomResourceDefinition" {
+		t.Errorf("applied %s first, want the CRD", applied[0].GetName())
+	}
+	containers, _, _ := unstructured.NestedSlice(applied[1].(*unstructured.Unstructured).Object, "spec", "template", "spec", "containers")
+	env := containers[0].(map[string]any)["env"].([]any)
+	if len(env) != 2 || env[1].(map[string]any)["value"] != "192.168.0.0/16" {
+		t.Errorf("calico-node env = %v, want the pod CIDR", env)
+	}
+
+	applied = nil
+	installation, err = c.InstallCNI(context.Background(), repo, "org-acme", "cilium-cluster", CNIOptions{CNI: CNICilium})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if installation.Version != DefaultCNIVersions[CNICilium] || installation.Job != "kube-system/cilium-install-1-16-3" || len(applied) != 3 {
+		t.Fatalf("installation = %+v, applied %d objects", installation, len(applied))
+	}
+	job := applied[2].(*batchv1.Job)
+	if command := strings.Join(job.Spec.Template.Spec.Containers[0].Command, " "); !strings.Contains(command, "clusterPoolIPv4PodCIDRList={10.244.0.0/16}") || !job.Spec.Template.Spec.HostNetwork {
+		t.Errorf("job = %s, want the pod CIDR on the host network", command)
+	}
+
+	tests := []struct {
+		cluster string
+		opts    CNIOptions
+		want    error
+	}{
+		{"prod", CNIOptions{CNI: "flannel"}, ErrInvalidArgument},
+		{"prod", CNIOptions{CNI: CNICalico, Version: "latest"}, ErrInvalidArgument},
+		{"no-cidr", CNIOptions{CNI: CNICalico}, ErrPreconditionFailed},
+		{"cilium-cluster", CNIOptions{CNI: CNICalico}, ErrPreconditionFailed},
+		{"prod", CNIOptions{CNI: CNICalico, Version: "v3.27.0"}, ErrNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := c.InstallCNI(context.Background(), repo, "org-acme", tt.cluster, tt.opts); !errors.Is(err, tt.want) {
+			t.Errorf("InstallCNI(%s, %+v) error = %v, want %v", tt.cluster, tt.opts, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package capi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// ContractV1Beta1 is the Cluster API contract of releases before v1.11
+	ContractV1Beta1 = "v1beta1"
+	// ContractV1Beta2 is the Cluster API contract introduced with v1.11
+	ContractV1Beta2 = "v1beta2"
+)
+
+// APIContract describes the Cluster API versions a management cluster serves
+type APIContract struct {
+	// Version is the preferred version of the cluster.x-k8s.io group, which
+	// tells the contract the installed Cluster API release implements
+	Version string `json:"version"`
+	// Served lists the versions of the cluster.x-k8s.io group
+	Served []string `json:"served"`
+	// Converted lists the API groups that no longer serve v1beta1. Their
+	// objects are read and written as v1beta2 and converted by the client.
+	Converted []string `json:"converted,omitempty"`
+}
+
+// converts reports whether objects of an API group are converted by the client
+func (a *APIContract) converts(group string) bool {
+	return a != nil && slices.Contains(a.Converted, group)
+}
+
+// contractGroups are the API groups whose typed objects the client uses
+var contractGroups = []string{clusterv1.GroupVersion.Group, controlplanev1.GroupVersion.Group}
+
+// detectContract reads the versions of the Cluster API groups through
+// discovery. It fails with ErrNotFound if Cluster API is not installed.
+func detectContract(dc discovery.DiscoveryInterface) (*APIContract, error) {
+	groups, err := dc.ServerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover API groups: %w", err)
+	}
+
+	contract := &APIContract{}
+	for _, group := range groups.Groups {
+		if !slices.Contains(contractGroups, group.Name) {
+			continue
+		}
+		var served []string
+		for _, v := range group.Versions {
+			served = append(served, v.Version)
+		}
+		if group.Name == clusterv1.GroupVersion.Group {
+			contract.Version = group.PreferredVersion.Version
+			contract.Served = served
+		}
+		if !slices.Contains(served, ContractV1Beta1) && slices.Contains(served, ContractV1Beta2) {
+			contract.Converted = append(contract.Converted, group.Name)
+		}
+	}
+	// Discovery does not order the groups
+	slices.Sort(contract.Converted)
+	if contract.Version == "" {
+		return nil, errorf(ErrNotFound, "the %s API is not served, Cluster API is not installed", clusterv1.GroupVersion.Group)
+	}
+	return contract, nil
+}
+
+// APIContract detects the Cluster API contract of the management cluster.
+// The result is cached once detection succeeds.
+func (c *Client) APIContract(ctx context.Context) (*APIContract, error) {
+	cc, ok := c.ctrlClient.(*contractClient)
+	if !ok {
+		return &APIContract{Version: ContractV1Beta1, Served: []string{ContractV1Beta1}}, nil
+	}
+	return cc.apiContract(ctx)
+}
+
+// contractClient serves the typed v1beta1 objects the client is written
+// against from management clusters that only serve the v1beta2 contract,
+// converting them on the way. While v1beta1 is served, the API server
+// converts and requests pass through unchanged.
+type contractClient struct {
+	client.Client
+
+	discovery discovery.DiscoveryInterface
+
+	mu       sync.Mutex
+	contract *APIContract
+}
+
+// newContractClient wraps c to follow the contract detected through dc
+func newContractClient(c client.Client, dc discovery.DiscoveryInterface) *contractClient {
+	return &contractClient{Client: c, discovery: dc}
+}
+
+// apiContract detects the contract on first use. A failed detection is
+// retried on the next request, so a management cluster that is unreachable
+// at startup is handled once it comes back.
+func (c *contractClient) apiContract(_ context.Context) (*APIContract, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.contract != nil {
+		return c.contract, nil
+	}
+	contract, err := detectContract(c.discovery)
+	if err != nil {
+		return nil, err
+	}
+	c.contract = contract
+	return contract, nil
+}
+
+// convertedKind returns the v1beta2 kind of a typed object whose group is
+// converted. It returns false for objects passed through unchanged,
+// including when the contract cannot be detected, leaving the request to
+// report the underlying error.
+func (c *contractClient) convertedKind(ctx context.Context, obj runtime.Object) (schema.GroupVersionKind, bool) {
+	if _, ok := obj.(runtime.Unstructured); ok {
+		return schema.GroupVersionKind{}, false
+	}
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil || gvk.Version != ContractV1Beta1 {
+		return schema.GroupVersionKind{}, false
+	}
+	contract, err := c.apiContract(ctx)
+	if err != nil || !contract.converts(gvk.Group) {
+		return schema.GroupVersionKind{}, false
+	}
+	gvk.Version = ContractV1Beta2
+	return gvk, true
+}
+
+// refAPIVersion resolves the preferred API version of a referenced kind,
+// falling back to the version of the scheme for the Cluster API kinds
+func (c *contractClient) refAPIVersion(group, kind string) string {
+	if mapping, err := c.RESTMapper().RESTMapping(schema.GroupKind{Group: group, Kind: kind}); err == nil {
+		return mapping.GroupVersionKind.GroupVersion().String()
+	}
+	if versions := c.Scheme().PrioritizedVersionsForGroup(group); len(versions) > 0 {
+		return versions[0].String()
+	}
+	return group
+}
+
+// fromV1Beta2 converts a v1beta2 object into the typed v1beta1 obj
+func (c *contractClient) fromV1Beta2(u map[string]any, obj runtime.Object) error {
+	gvk := schema.FromAPIVersionAndKind(fmt.Sprint(u["apiVersion"]), fmt.Sprint(u["kind"]))
+	toV1Beta1(gvk.GroupKind(), u, c.refAPIVersion)
+	u["apiVersion"] = schema.GroupVersion{Group: gvk.Group, Version: ContractV1Beta1}.String()
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u, obj)
+}
+
+// toV1Beta2 converts the typed v1beta1 obj into a v1beta2 object
+func (c *contractClient) toV1Beta2(obj runtime.Object, gvk schema.GroupVersionKind) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	toV1Beta2(gvk.GroupKind(), content)
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(gvk)
+	return u, nil
+}
+
+// fromWatch converts an unstructured v1beta2 object received from a watch to
+// a copy of typed, returning obj unchanged if it cannot be converted
+func (c *contractClient) fromWatch(obj any, typed client.Object) any {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return obj
+	}
+	converted := typed.DeepCopyObject()
+	if err := c.fromV1Beta2(u.DeepCopy().Object, converted); err != nil {
+		return obj
+	}
+	return converted
+}
+
+func (c *contractClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	gvk, ok := c.convertedKind(ctx, obj)
+	if !ok {
+		return c.Client.Get(ctx, key, obj, opts...)
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	if err := c.Client.Get(ctx, key, u, opts...); err != nil {
+		return err
+	}
+	return c.fromV1Beta2(u.Object, obj)
+}
+
+func (c *contractClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	gvk, ok := c.convertedKind(ctx, list)
+	if !ok {
+		return c.Client.List(ctx, list, opts...)
+	}
+	u := &unstructured.UnstructuredList{}
+	u.SetGroupVersionKind(gvk)
+	if err := c.Client.List(ctx, u, opts...); err != nil {
+		return err
+	}
+
+	items := make([]any, 0, len(u.Items))
+	for _, item := range u.Items {
+		toV1Beta1(item.GroupVersionKind().GroupKind(), item.Object, c.refAPIVersion)
+		item.SetAPIVersion(schema.GroupVersion{Group: gvk.Group, Version: ContractV1Beta1}.String())
+		items = append(items, item.Object)
+	}
+	content := u.UnstructuredContent()
+	content["items"] = items
+	content["apiVersion"] = schema.GroupVersion{Group: gvk.Group, Version: ContractV1Beta1}.String()
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(content, list)
+}
+
+func (c *contractClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	gvk, ok := c.convertedKind(ctx, obj)
+	if !ok {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+	u, err := c.toV1Beta2(obj, gvk)
+	if err != nil {
+		return err
+	}
+	if err := c.Client.Create(ctx, u, opts...); err != nil {
+		return err
+	}
+	return c.fromV1Beta2(u.Object, obj)
+}
+
+func (c *contractClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	gvk, ok := c.convertedKind(ctx, obj)
+	if !ok {
+		return c.Client.Update(ctx, obj, opts...)
+	}
+	u, err := c.toV1Beta2(obj, gvk)
+	if err != nil {
+		return err
+	}
+	if err := c.Client.Update(ctx, u, opts...); err != nil {
+		return err
+	}
+	return c.fromV1Beta2(u.Object, obj)
+}
+
+// Patch converts merge patches, which the client uses for all its updates,
+// field by field like objects. Other patch types cannot be converted.
+func (c *contractClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	gvk, ok := c.convertedKind(ctx, obj)
+	if !ok {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+	if patch.Type() != types.MergePatchType {
+		return errorf(ErrInvalidArgument, "%s patches of %s cannot be converted to the %s contract", patch.Type(), gvk.Kind, ContractV1Beta2)
+	}
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	content := make(map[string]any)
+	if err := json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("failed to decode patch: %w", err)
+	}
+	toV1Beta2(gvk.GroupKind(), content)
+	if data, err = json.Marshal(content); err != nil {
+		return fmt.Errorf("failed to encode patch: %w", err)
+	}
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	u.SetNamespace(obj.GetNamespace())
+	u.SetName(obj.GetName())
+	if err := c.Client.Patch(ctx, u, client.RawPatch(types.MergePatchType, data), opts...); err != nil {
+		return err
+	}
+	return c.fromV1Beta2(u.Object, obj)
+}
+
+func (c *contractClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	gvk, ok := c.convertedKind(ctx, obj)
+	if !ok {
+		return c.Client.Delete(ctx, obj, opts...)
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	u.SetNamespace(obj.GetNamespace())
+	u.SetName(obj.GetName())
+	return c.Client.Delete(ctx, u, opts...)
+}
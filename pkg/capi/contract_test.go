@@ -0,0 +1,243 @@
+package capi
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDetectContract(t *testing.T) {
+	tests := []struct {
+		name          string
+		groupVersions []string
+		wantVersion   string
+		wantConverted []string
+		wantErr       bool
+	}{
+		{
+			name:          "v1beta1 only",
+			groupVersions: []string{"cluster.x-k8s.io/v1beta1", "controlplane.cluster.x-k8s.io/v1beta1"},
+			wantVersion:   "v1beta1",
+		},
+		{
+			name:          "both served",
+			groupVersions: []string{"cluster.x-k8s.io/v1beta2", "cluster.x-k8s.io/v1beta1", "controlplane.cluster.x-k8s.io/v1beta2", "controlplane.cluster.x-k8s.io/v1beta1"},
+			wantVersion:   "v1beta2",
+		},
+		{
+			name:          "v1beta2 only",
+			groupVersions: []string{"cluster.x-k8s.io/v1beta2", "controlplane.cluster.x-k8s.io/v1beta2"},
+			wantVersion:   "v1beta2",
+			wantConverted: []string{"cluster.x-k8s.io", "controlplane.cluster.x-k8s.io"},
+		},
+		{
+			name:          "not installed",
+			groupVersions: []string{"apps/v1"},
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := &discoveryfake.FakeDiscovery{Fake: &clienttesting.Fake{}}
+			for _, gv := range tt.groupVersions {
+				dc.Resources = append(dc.Resources, &metav1.APIResourceList{GroupVersion: gv})
+			}
+
+			contract, err := detectContract(dc)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("detectContract() = %+v, want error", contract)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if contract.Version != tt.wantVersion {
+				t.Errorf("Version = %q, want %q", contract.Version, tt.wantVersion)
+			}
+			if len(contract.Converted) != len(tt.wantConverted) {
+				t.Fatalf("Converted = %v, want %v", contract.Converted, tt.wantConverted)
+			}
+			for i := range tt.wantConverted {
+				if contract.Converted[i] != tt.wantConverted[i] {
+					t.Errorf("Converted = %v, want %v", contract.Converted, tt.wantConverted)
+				}
+			}
+		})
+	}
+}
+
+func TestContractClientConvertsV1Beta2(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"controlPlaneRef": map[string]any{"apiGroup": "controlplane.cluster.x-k8s.io", "kind": "KubeadmControlPlane", "name": "prod-cp"},
+		},
+		"status": map[string]any{
+			"phase":          "Provisioned",
+			"initialization": map[string]any{"infrastructureProvisioned": true},
+			"conditions": []any{
+				map[string]any{"type": "Available", "status": "True", "reason": "Available", "lastTransitionTime": "2026-01-01T00:00:00Z"},
+			},
+		},
+	}}
+	cluster.SetAPIVersion("cluster.x-k8s.io/v1beta2")
+	cluster.SetKind("Cluster")
+	cluster.SetNamespace("org-a")
+	cluster.SetName("prod")
+
+	md := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"clusterName": "prod",
+			"replicas":    int64(3),
+			"rollout":     map[string]any{"strategy": map[string]any{"type": "RollingUpdate"}},
+			"template": map[string]any{"spec": map[string]any{
+				"clusterName": "prod",
+				"version":     "v1.30.2",
+				"deletion":    map[string]any{"nodeDrainTimeoutSeconds": int64(600)},
+			}},
+		},
+		"status": map[string]any{
+			"replicas":          int64(3),
+			"readyReplicas":     int64(3),
+			"availableReplicas": int64(2),
+			"upToDateReplicas":  int64(3),
+		},
+	}}
+	md.SetAPIVersion("cluster.x-k8s.io/v1beta2")
+	md.SetKind("MachineDeployment")
+	md.SetNamespace("org-a")
+	md.SetName("prod-workers")
+	md.SetLabels(map[string]string{clusterv1.ClusterNameLabel: "prod"})
+
+	ctrlClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, md).Build()
+	cc := &contractClient{Client: ctrlClient, contract: &APIContract{
+		Version:   ContractV1Beta2,
+		Served:    []string{ContractV1Beta2},
+		Converted: []string{"cluster.x-k8s.io", "controlplane.cluster.x-k8s.io"},
+	}}
+	c := &Client{ctrlClient: cc}
+	ctx := context.Background()
+
+	got, err := c.GetCluster(ctx, "org-a", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Spec.ControlPlaneRef == nil || got.Spec.ControlPlaneRef.APIVersion != controlplanev1.GroupVersion.String() {
+		t.Errorf("ControlPlaneRef = %+v, want apiVersion %s", got.Spec.ControlPlaneRef, controlplanev1.GroupVersion)
+	}
+	if !got.Status.InfrastructureReady {
+		t.Error("InfrastructureReady = false, want true from status.initialization")
+	}
+	if !clusterReady(got) {
+		t.Error("clusterReady() = false, want true from the Available condition")
+	}
+
+	mds, err := c.ListMachineDeployments(ctx, "org-a", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mds.Items) != 1 {
+		t.Fatalf("got %d machine deployments, want 1", len(mds.Items))
+	}
+	gotMD := mds.Items[0]
+	if gotMD.Spec.Strategy == nil || gotMD.Spec.Strategy.Type != clusterv1.RollingUpdateMachineDeploymentStrategyType {
+		t.Errorf("Strategy = %+v, want RollingUpdate", gotMD.Spec.Strategy)
+	}
+	if gotMD.Spec.Template.Spec.NodeDrainTimeout == nil || gotMD.Spec.Template.Spec.NodeDrainTimeout.Duration.Minutes() != 10 {
+		t.Errorf("NodeDrainTimeout = %v, want 10m", gotMD.Spec.Template.Spec.NodeDrainTimeout)
+	}
+	if gotMD.Status.UpdatedReplicas != 3 || gotMD.Status.AvailableReplicas != 2 {
+		t.Errorf("UpdatedReplicas, AvailableReplicas = %d, %d, want 3, 2 from the v1beta2 status", gotMD.Status.UpdatedReplicas, gotMD.Status.AvailableReplicas)
+	}
+
+	// Writes go through the merge patches of updateObject
+	if err := c.ScaleMachineDeployment(ctx, "org-a", "prod-workers", 5); err != nil {
+		t.Fatal(err)
+	}
+	stored := &unstructured.Unstructured{}
+	stored.SetAPIVersion("cluster.x-k8s.io/v1beta2")
+	stored.SetKind("MachineDeployment")
+	if err := ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-a", Name: "prod-workers"}, stored); err != nil {
+		t.Fatal(err)
+	}
+	if replicas, _, _ := unstructured.NestedInt64(stored.Object, "spec", "replicas"); replicas != 5 {
+		t.Errorf("stored replicas = %d, want 5", replicas)
+	}
+	if _, found, _ := unstructured.NestedFieldNoCopy(stored.Object, "spec", "strategy"); found {
+		t.Error("stored object has the v1beta1 spec.strategy field")
+	}
+	if seconds, _, _ := unstructured.NestedInt64(stored.Object, "spec", "template", "spec", "deletion", "nodeDrainTimeoutSeconds"); seconds != 600 {
+		t.Errorf("stored nodeDrainTimeoutSeconds = %d, want 600", seconds)
+	}
+}
+
+func TestContractConversionRoundTrip(t *testing.T) {
+	v1beta1 := map[string]any{
+		"spec": map[string]any{
+			"infrastructureRef": map[string]any{"apiVersion": "infrastructure.cluster.x-k8s.io/v1beta2", "kind": "AWSCluster", "name": "prod", "namespace": "org-a"},
+			"topology":          map[string]any{"class": "aws", "version": "v1.30.2"},
+		},
+		"status": map[string]any{
+			"controlPlaneReady": true,
+			"conditions":        []any{map[string]any{"type": "Ready", "status": "True"}},
+			"v1beta2": map[string]any{
+				"conditions": []any{map[string]any{"type": "Available", "status": "True"}},
+			},
+		},
+	}
+	gk := clusterv1.GroupVersion.WithKind("Cluster").GroupKind()
+
+	toV1Beta2(gk, v1beta1)
+	if group, _ := nestedValue(v1beta1, "spec.infrastructureRef.apiGroup"); group != "infrastructure.cluster.x-k8s.io" {
+		t.Errorf("infrastructureRef.apiGroup = %v", group)
+	}
+	if _, ok := nestedValue(v1beta1, "spec.infrastructureRef.namespace"); ok {
+		t.Error("infrastructureRef keeps its namespace")
+	}
+	if class, _ := nestedValue(v1beta1, "spec.topology.classRef.name"); class != "aws" {
+		t.Errorf("topology.classRef.name = %v, want aws", class)
+	}
+	if _, ok := nestedValue(v1beta1, "status.v1beta2"); ok {
+		t.Error("status.v1beta2 is left behind")
+	}
+	if conditions, _ := nestedValue(v1beta1, "status.deprecated.v1beta1.conditions"); len(conditions.([]any)) != 1 {
+		t.Errorf("deprecated conditions = %v", conditions)
+	}
+
+	toV1Beta1(gk, v1beta1, func(group, kind string) string { return group + "/v1beta2" })
+	if apiVersion, _ := nestedValue(v1beta1, "spec.infrastructureRef.apiVersion"); apiVersion != "infrastructure.cluster.x-k8s.io/v1beta2" {
+		t.Errorf("infrastructureRef.apiVersion = %v", apiVersion)
+	}
+	if class, _ := nestedValue(v1beta1, "spec.topology.class"); class != "aws" {
+		t.Errorf("topology.class = %v, want aws", class)
+	}
+	if ready, _ := nestedValue(v1beta1, "status.controlPlaneReady"); ready != true {
+		t.Errorf("controlPlaneReady = %v, want true", ready)
+	}
+	condition, _ := nestedValue(v1beta1, "status.conditions")
+	if conditions := condition.([]any); len(conditions) != 1 || conditions[0].(map[string]any)["type"] != "Ready" {
+		t.Errorf("conditions = %v, want the v1beta1 Ready condition", conditions)
+	}
+	if _, ok := nestedValue(v1beta1, "status.deprecated"); ok {
+		t.Error("status.deprecated is left behind")
+	}
+}
@@ -0,0 +1,316 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// controllerNamespaces are the namespaces clusterctl installs the core and
+// kubeadm providers into. Their deployments are inspected even when they lack
+// the provider label.
+var controllerNamespaces = []string{
+	"capi-system",
+	"capi-kubeadm-bootstrap-system",
+	"capi-kubeadm-control-plane-system",
+}
+
+// ControllerStatus is the health of a provider controller deployment
+type ControllerStatus struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Provider is the clusterctl name of the provider, e.g. infrastructure-aws
+	Provider        string   `json:"provider,omitempty"`
+	Replicas        int32    `json:"replicas"`
+	ReadyReplicas   int32    `json:"readyReplicas"`
+	UpdatedReplicas int32    `json:"updatedReplicas"`
+	Images          []string `json:"images"`
+	// Restarts sums the container restarts of the controller pods
+	Restarts int32 `json:"restarts"`
+	// LastTermination is the reason the last restarted container stopped,
+	// e.g. OOMKilled or Error
+	LastTermination string `json:"lastTermination,omitempty"`
+	Healthy         bool   `json:"healthy"`
+	Message         string `json:"message"`
+}
+
+// WebhookConfigurationStatus summarizes an admission webhook configuration of
+// a provider
+type WebhookConfigurationStatus struct {
+	Name string `json:"name"`
+	// Type is validating or mutating
+	Type     string `json:"type"`
+	Provider string `json:"provider,omitempty"`
+	Webhooks int    `json:"webhooks"`
+	// Services are the namespace/name of the services the webhooks call
+	Services      []string `json:"services"`
+	FailurePolicy string   `json:"failurePolicy,omitempty"`
+	Healthy       bool     `json:"healthy"`
+	Message       string   `json:"message"`
+}
+
+// ControllersStatus is the health of the Cluster API controllers on the
+// management cluster
+type ControllersStatus struct {
+	Controllers []ControllerStatus           `json:"controllers"`
+	Webhooks    []WebhookConfigurationStatus `json:"webhooks"`
+	Healthy     bool                         `json:"healthy"`
+	// Issues lists the problems found, most likely causes first
+	Issues []string `json:"issues"`
+}
+
+// GetControllersStatus inspects the controller deployments of the core,
+// kubeadm and other providers, found by the cluster.x-k8s.io/provider label
+// and in the namespaces clusterctl uses, with the restarts of their pods,
+// and the webhook configurations calling them. A controller is healthy when
+// all its replicas are ready and updated; a webhook configuration when all
+// its webhooks have a CA bundle and call a namespace with healthy controllers.
+func (c *Client) GetControllersStatus(ctx context.Context) (*ControllersStatus, error) {
+	deployments, err := c.listControllerDeployments(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pods := make(map[string][]corev1.Pod)
+	for _, deployment := range deployments {
+		if _, ok := pods[deployment.Namespace]; ok {
+			continue
+		}
+		list, err := c.k8sClient.CoreV1().Pods(deployment.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list controller pods in %s: %w", deployment.Namespace, err)
+		}
+		pods[deployment.Namespace] = list.Items
+	}
+
+	status := &ControllersStatus{Controllers: []ControllerStatus{}, Webhooks: []WebhookConfigurationStatus{}, Issues: []string{}}
+	// healthyNamespaces tells whether all controllers of a namespace are healthy
+	healthyNamespaces := make(map[string]bool)
+	for i := range deployments {
+		controller := newControllerStatus(&deployments[i], pods[deployments[i].Namespace])
+		if healthy, seen := healthyNamespaces[controller.Namespace]; !seen || healthy {
+			healthyNamespaces[controller.Namespace] = controller.Healthy
+		}
+		if !controller.Healthy {
+			status.Issues = append(status.Issues, fmt.Sprintf("controller %s/%s: %s", controller.Namespace, controller.Name, controller.Message))
+		}
+		status.Controllers = append(status.Controllers, controller)
+	}
+	if len(status.Controllers) == 0 {
+		status.Issues = append(status.Issues, "no Cluster API controller deployment found")
+	}
+
+	validating, err := c.k8sClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{LabelSelector: clusterv1.ProviderNameLabel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list validating webhook configurations: %w", err)
+	}
+	for i := range validating.Items {
+		config := &validating.Items[i]
+		var clients []admissionregistrationv1.WebhookClientConfig
+		var policy *admissionregistrationv1.FailurePolicyType
+		for _, webhook := range config.Webhooks {
+			clients = append(clients, webhook.ClientConfig)
+			policy = webhook.FailurePolicy
+		}
+		status.Webhooks = append(status.Webhooks, newWebhookConfigurationStatus("validating", config.ObjectMeta, clients, policy, healthyNamespaces))
+	}
+	mutating, err := c.k8sClient.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{LabelSelector: clusterv1.ProviderNameLabel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mutating webhook configurations: %w", err)
+	}
+	for i := range mutating.Items {
+		config := &mutating.Items[i]
+		var clients []admissionregistrationv1.WebhookClientConfig
+		var policy *admissionregistrationv1.FailurePolicyType
+		for _, webhook := range config.Webhooks {
+			clients = append(clients, webhook.ClientConfig)
+			policy = webhook.FailurePolicy
+		}
+		status.Webhooks = append(status.Webhooks, newWebhookConfigurationStatus("mutating", config.ObjectMeta, clients, policy, healthyNamespaces))
+	}
+	sort.Slice(status.Webhooks, func(i, j int) bool {
+		if status.Webhooks[i].Name != status.Webhooks[j].Name {
+			return status.Webhooks[i].Name < status.Webhooks[j].Name
+		}
+		return status.Webhooks[i].Type < status.Webhooks[j].Type
+	})
+	for _, webhook := range status.Webhooks {
+		if !webhook.Healthy {
+			status.Issues = append(status.Issues, fmt.Sprintf("%s webhook configuration %s: %s", webhook.Type, webhook.Name, webhook.Message))
+		}
+	}
+
+	status.Healthy = len(status.Issues) == 0
+	return status, nil
+}
+
+// listControllerDeployments lists the labeled provider deployments and those
+// of the clusterctl namespaces, sorted by namespace and name
+func (c *Client) listControllerDeployments(ctx context.Context) ([]appsv1.Deployment, error) {
+	labeled, err := c.k8sClient.AppsV1().Deployments("").List(ctx, metav1.ListOptions{LabelSelector: clusterv1.ProviderNameLabel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list controller deployments: %w", err)
+	}
+	deployments := labeled.Items
+	seen := make(map[string]bool, len(deployments))
+	for _, deployment := range deployments {
+		seen[deployment.Namespace+"/"+deployment.Name] = true
+	}
+	for _, namespace := range controllerNamespaces {
+		list, err := c.k8sClient.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list controller deployments in %s: %w", namespace, err)
+		}
+		for _, deployment := range list.Items {
+			if !seen[deployment.Namespace+"/"+deployment.Name] {
+				seen[deployment.Namespace+"/"+deployment.Name] = true
+				deployments = append(deployments, deployment)
+			}
+		}
+	}
+	sort.Slice(deployments, func(i, j int) bool {
+		if deployments[i].Namespace != deployments[j].Namespace {
+			return deployments[i].Namespace < deployments[j].Namespace
+		}
+		return deployments[i].Name < deployments[j].Name
+	})
+	return deployments, nil
+}
+
+// newControllerStatus summarizes a controller deployment and its pods
+func newControllerStatus(deployment *appsv1.Deployment, pods []corev1.Pod) ControllerStatus {
+	summary := newProviderDeployment(deployment)
+	controller := ControllerStatus{
+		Namespace:       deployment.Namespace,
+		Name:            deployment.Name,
+		Provider:        deployment.Labels[clusterv1.ProviderNameLabel],
+		Replicas:        summary.Replicas,
+		ReadyReplicas:   deployment.Status.ReadyReplicas,
+		UpdatedReplicas: deployment.Status.UpdatedReplicas,
+		Images:          []string{},
+	}
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		controller.Images = append(controller.Images, container.Image)
+	}
+
+	var lastRestart metav1.Time
+	for _, pod := range pods {
+		if !controllerOwnsPod(deployment, &pod) {
+			continue
+		}
+		for _, container := range pod.Status.ContainerStatuses {
+			controller.Restarts += container.RestartCount
+			if terminated := container.LastTerminationState.Terminated; terminated != nil && lastRestart.Before(&terminated.FinishedAt) {
+				lastRestart = terminated.FinishedAt
+				controller.LastTermination = terminated.Reason
+			}
+		}
+	}
+
+	var problems []string
+	if controller.Replicas == 0 {
+		problems = append(problems, "scaled to zero")
+	} else if controller.ReadyReplicas < controller.Replicas {
+		problems = append(problems, fmt.Sprintf("%d/%d replicas ready", controller.ReadyReplicas, controller.Replicas))
+	}
+	if controller.Replicas > 0 && controller.UpdatedReplicas < controller.Replicas {
+		problems = append(problems, fmt.Sprintf("%d/%d replicas updated", controller.UpdatedReplicas, controller.Replicas))
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse {
+			problems = append(problems, condition.Message)
+		}
+	}
+	if len(problems) > 0 {
+		controller.Message = strings.Join(problems, ", ")
+		if controller.LastTermination != "" {
+			controller.Message += fmt.Sprintf(" (last restart: %s)", controller.LastTermination)
+		}
+		return controller
+	}
+	controller.Healthy = true
+	controller.Message = "all replicas ready"
+	if controller.Restarts > 0 {
+		controller.Message = fmt.Sprintf("all replicas ready, %d restarts", controller.Restarts)
+	}
+	return controller
+}
+
+// controllerOwnsPod reports whether a pod matches the selector of a deployment
+func controllerOwnsPod(deployment *appsv1.Deployment, pod *corev1.Pod) bool {
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil || selector.Empty() {
+		return false
+	}
+	return selector.Matches(labels.Set(pod.Labels))
+}
+
+// newWebhookConfigurationStatus summarizes the webhooks of a configuration
+// from their client configurations and the health of the controllers by
+// namespace
+func newWebhookConfigurationStatus(webhookType string, meta metav1.ObjectMeta, clients []admissionregistrationv1.WebhookClientConfig, policy *admissionregistrationv1.FailurePolicyType, healthyNamespaces map[string]bool) WebhookConfigurationStatus {
+	status := WebhookConfigurationStatus{
+		Name:     meta.Name,
+		Type:     webhookType,
+		Provider: meta.Labels[clusterv1.ProviderNameLabel],
+		Webhooks: len(clients),
+		Services: []string{},
+	}
+	if policy != nil {
+		status.FailurePolicy = string(*policy)
+	}
+
+	var problems []string
+	missingCA := 0
+	services := make(map[string]bool)
+	for _, client := range clients {
+		if len(client.CABundle) == 0 {
+			missingCA++
+		}
+		if client.Service == nil {
+			continue
+		}
+		service := client.Service.Namespace + "/" + client.Service.Name
+		if !services[service] {
+			services[service] = true
+			status.Services = append(status.Services, service)
+		}
+		if healthy, ok := healthyNamespaces[client.Service.Namespace]; !ok {
+			problems = append(problems, fmt.Sprintf("no controller found behind service %s", service))
+		} else if !healthy {
+			problems = append(problems, fmt.Sprintf("controller behind service %s is unhealthy", service))
+		}
+	}
+	if missingCA > 0 {
+		problems = append(problems, fmt.Sprintf("%d/%d webhooks have no CA bundle, check the cert-manager CA injector", missingCA, len(clients)))
+	}
+	sort.Strings(status.Services)
+
+	if len(problems) > 0 {
+		status.Message = strings.Join(dedupe(problems), ", ")
+		return status
+	}
+	status.Healthy = true
+	status.Message = "CA bundles injected, controllers ready"
+	return status
+}
+
+// dedupe removes repeated strings, keeping the first occurrence
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := values[:0]
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
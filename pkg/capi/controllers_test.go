@@ -0,0 +1,99 @@
+package capi
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestGetControllersStatus(t *testing.T) {
+	replicas := int32(1)
+	newDeployment := func(namespace, name string, labels map[string]string, ready int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "manager", Image: "example.com/" + name + ":v1.0.0"}}}},
+			},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: ready, UpdatedReplicas: 1},
+		}
+	}
+	crashingPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "capa-system", Name: "capa-controller-manager-abc", Labels: map[string]string{"app": "capa-controller-manager"}},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:         "manager",
+			RestartCount: 7,
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				Reason:     "OOMKilled",
+				FinishedAt: metav1.NewTime(time.Now()),
+			}},
+		}}},
+	}
+	validating := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "capa-validating-webhook-configuration", Labels: map[string]string{clusterv1.ProviderNameLabel: "infrastructure-aws"}},
+		Webhooks: []admissionregistrationv1.ValidatingWebhook{{
+			Name: "validation.awscluster.infrastructure.cluster.x-k8s.io",
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service:  &admissionregistrationv1.ServiceReference{Namespace: "capa-system", Name: "capa-webhook-service"},
+				CABundle: []byte("ca"),
+			},
+		}},
+	}
+	mutating := &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "capi-mutating-webhook-configuration", Labels: map[string]string{clusterv1.ProviderNameLabel: "cluster-api"}},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{{
+			Name: "default.cluster.cluster.x-k8s.io",
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{Namespace: "capi-system", Name: "capi-webhook-service"},
+			},
+		}},
+	}
+
+	c := &Client{k8sClient: k8sfake.NewClientset(
+		newDeployment("capi-system", "capi-controller-manager", nil, 1),
+		newDeployment("capa-system", "capa-controller-manager", map[string]string{clusterv1.ProviderNameLabel: "infrastructure-aws"}, 0),
+		crashingPod, validating, mutating,
+	)}
+
+	status, err := c.GetControllersStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Healthy {
+		t.Error("Healthy = true, want false")
+	}
+	if len(status.Controllers) != 2 {
+		t.Fatalf("got %d controllers, want 2: %+v", len(status.Controllers), status.Controllers)
+	}
+
+	capa := status.Controllers[0]
+	if capa.Name != "capa-controller-manager" || capa.Healthy || capa.Restarts != 7 || capa.LastTermination != "OOMKilled" {
+		t.Errorf("capa controller = %+v", capa)
+	}
+	capi := status.Controllers[1]
+	if capi.Name != "capi-controller-manager" || !capi.Healthy {
+		t.Errorf("capi controller found through its namespace = %+v", capi)
+	}
+
+	if len(status.Webhooks) != 2 {
+		t.Fatalf("got %d webhook configurations, want 2", len(status.Webhooks))
+	}
+	if webhook := status.Webhooks[0]; webhook.Healthy || !strings.Contains(webhook.Message, "unhealthy") {
+		t.Errorf("capa webhook = %+v, want unhealthy controller", webhook)
+	}
+	if webhook := status.Webhooks[1]; webhook.Healthy || !strings.Contains(webhook.Message, "CA bundle") {
+		t.Errorf("capi webhook = %+v, want missing CA bundle", webhook)
+	}
+	if len(status.Issues) != 3 {
+		t.Errorf("issues = %v, want 3", status.Issues)
+	}
+}
@@ -0,0 +1,293 @@
+package capi
+
+import (
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// fieldMove relocates a field between its v1beta1 and its v1beta2 path
+type fieldMove struct {
+	v1beta1, v1beta2 string
+	// duration fields are metav1.Duration strings in v1beta1 and seconds in v1beta2
+	duration bool
+}
+
+// contractConversion lists the fields of a kind that differ between the
+// v1beta1 and v1beta2 contracts. Fields that are not listed, such as the
+// replicas, the version or the labels, are the same in both.
+type contractConversion struct {
+	// moves are applied in order when converting to v1beta2 and in reverse
+	// order when converting back, so a field can be moved out of the way
+	// before another takes its place
+	moves []fieldMove
+	// refs are the v1beta2 paths of references to other objects, which carry
+	// an API group instead of an API version
+	refs []string
+	// fallbacks fill v1beta1 fields that are missing after converting back,
+	// once controllers stop reporting the deprecated v1beta1 status, from the
+	// v1beta2 field with the closest meaning
+	fallbacks []fieldMove
+}
+
+var (
+	// conditionMoves relocate the conditions and failures common to all kinds
+	conditionMoves = []fieldMove{
+		{v1beta1: "status.conditions", v1beta2: "status.deprecated.v1beta1.conditions"},
+		{v1beta1: "status.failureReason", v1beta2: "status.deprecated.v1beta1.failureReason"},
+		{v1beta1: "status.failureMessage", v1beta2: "status.deprecated.v1beta1.failureMessage"},
+		{v1beta1: "status.v1beta2.conditions", v1beta2: "status.conditions"},
+	}
+
+	// replicaMoves relocate the replica counters of machine deployments,
+	// machine sets and control planes
+	replicaMoves = []fieldMove{
+		{v1beta1: "status.readyReplicas", v1beta2: "status.deprecated.v1beta1.readyReplicas"},
+		{v1beta1: "status.availableReplicas", v1beta2: "status.deprecated.v1beta1.availableReplicas"},
+		{v1beta1: "status.updatedReplicas", v1beta2: "status.deprecated.v1beta1.updatedReplicas"},
+		{v1beta1: "status.unavailableReplicas", v1beta2: "status.deprecated.v1beta1.unavailableReplicas"},
+		{v1beta1: "status.v1beta2.readyReplicas", v1beta2: "status.readyReplicas"},
+		{v1beta1: "status.v1beta2.availableReplicas", v1beta2: "status.availableReplicas"},
+		{v1beta1: "status.v1beta2.upToDateReplicas", v1beta2: "status.upToDateReplicas"},
+	}
+
+	conditionFallbacks = []fieldMove{
+		{v1beta1: "status.conditions", v1beta2: "status.v1beta2.conditions"},
+	}
+
+	replicaFallbacks = []fieldMove{
+		{v1beta1: "status.readyReplicas", v1beta2: "status.v1beta2.readyReplicas"},
+		{v1beta1: "status.availableReplicas", v1beta2: "status.v1beta2.availableReplicas"},
+		{v1beta1: "status.updatedReplicas", v1beta2: "status.v1beta2.upToDateReplicas"},
+	}
+
+	// contractConversions are keyed by API group and kind
+	contractConversions = map[schema.GroupKind]contractConversion{
+		{Group: "cluster.x-k8s.io", Kind: "Cluster"}: {
+			moves: concat(conditionMoves, []fieldMove{
+				{v1beta1: "spec.topology.class", v1beta2: "spec.topology.classRef.name"},
+				{v1beta1: "spec.topology.classNamespace", v1beta2: "spec.topology.classRef.namespace"},
+				{v1beta1: "status.infrastructureReady", v1beta2: "status.initialization.infrastructureProvisioned"},
+				{v1beta1: "status.controlPlaneReady", v1beta2: "status.initialization.controlPlaneInitialized"},
+				{v1beta1: "status.v1beta2.controlPlane", v1beta2: "status.controlPlane"},
+				{v1beta1: "status.v1beta2.workers", v1beta2: "status.workers"},
+			}),
+			refs:      []string{"spec.controlPlaneRef", "spec.infrastructureRef"},
+			fallbacks: conditionFallbacks,
+		},
+		{Group: "cluster.x-k8s.io", Kind: "Machine"}: {
+			moves: concat(conditionMoves, machineSpecMoves("spec"), []fieldMove{
+				{v1beta1: "status.bootstrapReady", v1beta2: "status.initialization.bootstrapDataSecretCreated"},
+				{v1beta1: "status.infrastructureReady", v1beta2: "status.initialization.infrastructureProvisioned"},
+			}),
+			refs:      []string{"spec.bootstrap.configRef", "spec.infrastructureRef"},
+			fallbacks: conditionFallbacks,
+		},
+		{Group: "cluster.x-k8s.io", Kind: "MachineDeployment"}: {
+			moves: concat(conditionMoves, replicaMoves, machineSpecMoves("spec.template.spec"), []fieldMove{
+				{v1beta1: "spec.strategy.remediation", v1beta2: "spec.remediation"},
+				{v1beta1: "spec.strategy.rollingUpdate.deletePolicy", v1beta2: "spec.deletion.order"},
+				{v1beta1: "spec.strategy", v1beta2: "spec.rollout.strategy"},
+				{v1beta1: "spec.minReadySeconds", v1beta2: "spec.template.spec.minReadySeconds"},
+			}),
+			refs:      []string{"spec.template.spec.bootstrap.configRef", "spec.template.spec.infrastructureRef"},
+			fallbacks: concat(conditionFallbacks, replicaFallbacks),
+		},
+		{Group: "cluster.x-k8s.io", Kind: "MachineSet"}: {
+			moves: concat(conditionMoves, replicaMoves, machineSpecMoves("spec.template.spec"), []fieldMove{
+				{v1beta1: "status.fullyLabeledReplicas", v1beta2: "status.deprecated.v1beta1.fullyLabeledReplicas"},
+				{v1beta1: "spec.deletePolicy", v1beta2: "spec.deletion.order"},
+				{v1beta1: "spec.minReadySeconds", v1beta2: "spec.template.spec.minReadySeconds"},
+			}),
+			refs:      []string{"spec.template.spec.bootstrap.configRef", "spec.template.spec.infrastructureRef"},
+			fallbacks: concat(conditionFallbacks, replicaFallbacks),
+		},
+		{Group: "controlplane.cluster.x-k8s.io", Kind: "KubeadmControlPlane"}: {
+			moves: concat(conditionMoves, replicaMoves, []fieldMove{
+				{v1beta1: "spec.machineTemplate.infrastructureRef", v1beta2: "spec.machineTemplate.spec.infrastructureRef"},
+				{v1beta1: "spec.machineTemplate.nodeDrainTimeout", v1beta2: "spec.machineTemplate.spec.deletion.nodeDrainTimeoutSeconds", duration: true},
+				{v1beta1: "spec.machineTemplate.nodeVolumeDetachTimeout", v1beta2: "spec.machineTemplate.spec.deletion.nodeVolumeDetachTimeoutSeconds", duration: true},
+				{v1beta1: "spec.machineTemplate.nodeDeletionTimeout", v1beta2: "spec.machineTemplate.spec.deletion.nodeDeletionTimeoutSeconds", duration: true},
+				{v1beta1: "spec.rolloutStrategy", v1beta2: "spec.rollout.strategy"},
+				{v1beta1: "spec.rolloutAfter", v1beta2: "spec.rollout.after"},
+				{v1beta1: "spec.rolloutBefore", v1beta2: "spec.rollout.before"},
+				{v1beta1: "status.initialized", v1beta2: "status.initialization.controlPlaneInitialized"},
+			}),
+			refs:      []string{"spec.machineTemplate.spec.infrastructureRef"},
+			fallbacks: concat(conditionFallbacks, replicaFallbacks),
+		},
+	}
+)
+
+// machineSpecMoves relocates the fields of a machine spec found at prefix
+func machineSpecMoves(prefix string) []fieldMove {
+	return []fieldMove{
+		{v1beta1: prefix + ".nodeDrainTimeout", v1beta2: prefix + ".deletion.nodeDrainTimeoutSeconds", duration: true},
+		{v1beta1: prefix + ".nodeVolumeDetachTimeout", v1beta2: prefix + ".deletion.nodeVolumeDetachTimeoutSeconds", duration: true},
+		{v1beta1: prefix + ".nodeDeletionTimeout", v1beta2: prefix + ".deletion.nodeDeletionTimeoutSeconds", duration: true},
+	}
+}
+
+func concat(moves ...[]fieldMove) []fieldMove {
+	var all []fieldMove
+	for _, m := range moves {
+		all = append(all, m...)
+	}
+	return all
+}
+
+// toV1Beta2 converts the content of a v1beta1 object, or of a merge patch of
+// one, to the v1beta2 contract in place
+func toV1Beta2(gk schema.GroupKind, obj map[string]any) {
+	conversion := contractConversions[gk]
+	for _, move := range conversion.moves {
+		value, ok := nestedValue(obj, move.v1beta1)
+		if !ok {
+			continue
+		}
+		removeNestedValue(obj, move.v1beta1)
+		if move.duration {
+			value = durationToSeconds(value)
+		}
+		setNestedValue(obj, move.v1beta2, value)
+	}
+	for _, path := range conversion.refs {
+		if ref, ok := nestedValue(obj, path); ok {
+			if ref, ok := ref.(map[string]any); ok {
+				refToV1Beta2(ref)
+			}
+		}
+	}
+}
+
+// toV1Beta1 converts the content of a v1beta2 object to the v1beta1 contract
+// in place. apiVersion resolves the API version of a referenced group and kind.
+func toV1Beta1(gk schema.GroupKind, obj map[string]any, apiVersion func(group, kind string) string) {
+	conversion := contractConversions[gk]
+	for _, path := range conversion.refs {
+		if ref, ok := nestedValue(obj, path); ok {
+			if ref, ok := ref.(map[string]any); ok {
+				refToV1Beta1(ref, apiVersion)
+			}
+		}
+	}
+	for i := len(conversion.moves) - 1; i >= 0; i-- {
+		move := conversion.moves[i]
+		value, ok := nestedValue(obj, move.v1beta2)
+		if !ok {
+			continue
+		}
+		removeNestedValue(obj, move.v1beta2)
+		if move.duration {
+			value = secondsToDuration(value)
+		}
+		setNestedValue(obj, move.v1beta1, value)
+	}
+	for _, fallback := range conversion.fallbacks {
+		if _, ok := nestedValue(obj, fallback.v1beta1); ok {
+			continue
+		}
+		if value, ok := nestedValue(obj, fallback.v1beta2); ok {
+			setNestedValue(obj, fallback.v1beta1, value)
+		}
+	}
+}
+
+// refToV1Beta2 turns an object reference into a contract versioned reference,
+// which names the API group and leaves the version to the contract
+func refToV1Beta2(ref map[string]any) {
+	if apiVersion, ok := ref["apiVersion"].(string); ok {
+		if gv, err := schema.ParseGroupVersion(apiVersion); err == nil {
+			ref["apiGroup"] = gv.Group
+		}
+	}
+	for _, field := range []string{"apiVersion", "namespace", "uid", "resourceVersion", "fieldPath"} {
+		delete(ref, field)
+	}
+}
+
+// refToV1Beta1 turns a contract versioned reference into an object reference
+func refToV1Beta1(ref map[string]any, apiVersion func(group, kind string) string) {
+	group, hasGroup := ref["apiGroup"].(string)
+	kind, _ := ref["kind"].(string)
+	delete(ref, "apiGroup")
+	if hasGroup {
+		ref["apiVersion"] = apiVersion(group, kind)
+	}
+}
+
+// durationToSeconds converts a metav1.Duration string to whole seconds
+func durationToSeconds(value any) any {
+	s, ok := value.(string)
+	if !ok {
+		return value
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return value
+	}
+	return int64(d / time.Second)
+}
+
+// secondsToDuration converts seconds to a metav1.Duration string
+func secondsToDuration(value any) any {
+	switch seconds := value.(type) {
+	case int64:
+		return (time.Duration(seconds) * time.Second).String()
+	case float64:
+		return (time.Duration(seconds) * time.Second).String()
+	}
+	return value
+}
+
+// nestedValue returns the value at a dotted path of obj
+func nestedValue(obj map[string]any, path string) (any, bool) {
+	var current any = obj
+	for _, field := range strings.Split(path, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[field]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// setNestedValue sets the value at a dotted path of obj, creating the maps on
+// the way
+func setNestedValue(obj map[string]any, path string, value any) {
+	fields := strings.Split(path, ".")
+	m := obj
+	for _, field := range fields[:len(fields)-1] {
+		next, ok := m[field].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			m[field] = next
+		}
+		m = next
+	}
+	m[fields[len(fields)-1]] = value
+}
+
+// removeNestedValue removes the value at a dotted path of obj along with the
+// maps it leaves empty
+func removeNestedValue(obj map[string]any, path string) {
+	fields := strings.Split(path, ".")
+	parent, ok := obj, true
+	if len(fields) > 1 {
+		var value any
+		if value, ok = nestedValue(obj, strings.Join(fields[:len(fields)-1], ".")); ok {
+			parent, ok = value.(map[string]any)
+		}
+	}
+	if !ok {
+		return
+	}
+	delete(parent, fields[len(fields)-1])
+	if len(parent) == 0 && len(fields) > 1 {
+		removeNestedValue(obj, strings.Join(fields[:len(fields)-1], "."))
+	}
+}
@@ -0,0 +1,269 @@
+package capi
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CredentialMethod is how short-lived workload cluster credentials
+// authenticate
+type CredentialMethod string
+
+const (
+	// CredentialCertificate is a client certificate signed by the cluster CA
+	CredentialCertificate CredentialMethod = "certificate"
+	// CredentialToken is a ServiceAccount token requested from the workload
+	// cluster
+	CredentialToken CredentialMethod = "token"
+)
+
+const (
+	// DefaultCredentialsTTL is how long issued credentials are valid when no
+	// TTL is given
+	DefaultCredentialsTTL = time.Hour
+	// MaxCredentialsTTL bounds the validity of issued credentials
+	MaxCredentialsTTL = 24 * time.Hour
+	// minTokenTTL is the shortest expiry the API server grants to tokens
+	minTokenTTL = 10 * time.Minute
+	// certificateBackdate tolerates clocks of API servers running behind
+	certificateBackdate = 5 * time.Minute
+)
+
+// defaultCredentialGroups are the groups of client certificates when none
+// are given, cluster admin like the kubeconfig Secret
+var defaultCredentialGroups = []string{"system:masters"}
+
+// CredentialsOptions configures the credentials issued for a workload cluster
+type CredentialsOptions struct {
+	// Method defaults to CredentialCertificate
+	Method CredentialMethod
+	// TTL defaults to DefaultCredentialsTTL
+	TTL time.Duration
+	// User and Groups are the subject of a client certificate
+	User   string
+	Groups []string
+	// ServiceAccount is the namespace/name of the account a token is
+	// requested for
+	ServiceAccount string
+}
+
+// WorkloadCredentials are time-limited credentials of a workload cluster
+type WorkloadCredentials struct {
+	Method  CredentialMethod `json:"method"`
+	Subject string           `json:"subject"`
+	Groups  []string         `json:"groups,omitempty"`
+	Server  string           `json:"server"`
+	Expires time.Time        `json:"expires"`
+	// Kubeconfig holds the credentials, it is left to callers whether to
+	// return it
+	Kubeconfig string `json:"-"`
+}
+
+// IssueWorkloadCredentials creates a kubeconfig for a workload cluster whose
+// credentials expire, instead of handing out the admin kubeconfig of the
+// cluster's Secret. Client certificates are signed with the cluster CA of the
+// {cluster}-ca Secret; tokens are requested for an existing ServiceAccount of
+// the workload cluster.
+func (c *Client) IssueWorkloadCredentials(ctx context.Context, namespace, clusterName string, opts CredentialsOptions) (*WorkloadCredentials, error) {
+	if opts.Method == "" {
+		opts.Method = CredentialCertificate
+	}
+	if opts.TTL == 0 {
+		opts.TTL = DefaultCredentialsTTL
+	}
+	if opts.TTL < 0 || opts.TTL > MaxCredentialsTTL {
+		return nil, errorf(ErrInvalidArgument, "ttl must be positive and at most %s, got %s", MaxCredentialsTTL, opts.TTL)
+	}
+
+	admin, err := c.GetKubeconfig(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	adminConfig, err := clientcmd.Load([]byte(admin))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	cluster := currentCluster(adminConfig)
+	if cluster == nil {
+		return nil, errorf(ErrPreconditionFailed, "kubeconfig of cluster %s/%s has no cluster for its current context", namespace, clusterName)
+	}
+
+	var (
+		credentials *WorkloadCredentials
+		user        *clientcmdapi.AuthInfo
+	)
+	switch opts.Method {
+	case CredentialCertificate:
+		credentials, user, err = c.issueClientCertificate(ctx, namespace, clusterName, opts)
+	case CredentialToken:
+		credentials, user, err = c.requestServiceAccountToken(ctx, admin, opts)
+	default:
+		return nil, errorf(ErrInvalidArgument, "unknown credential method %q, must be %s or %s", opts.Method, CredentialCertificate, CredentialToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+	credentials.Server = cluster.Server
+
+	userName := strings.ReplaceAll(credentials.Subject, ":", "-")
+	contextName := userName + "@" + clusterName
+	config := clientcmdapi.NewConfig()
+	config.Clusters[clusterName] = cluster
+	config.AuthInfos[userName] = user
+	config.Contexts[contextName] = &clientcmdapi.Context{Cluster: clusterName, AuthInfo: userName}
+	config.CurrentContext = contextName
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode kubeconfig: %w", err)
+	}
+	credentials.Kubeconfig = string(data)
+	return credentials, nil
+}
+
+// currentCluster returns the cluster of the current context of a kubeconfig
+func currentCluster(config *clientcmdapi.Config) *clientcmdapi.Cluster {
+	if kubeContext, ok := config.Contexts[config.CurrentContext]; ok {
+		return config.Clusters[kubeContext.Cluster]
+	}
+	// Kubeconfigs with a single cluster and no current context
+	if len(config.Clusters) == 1 {
+		for _, cluster := range config.Clusters {
+			return cluster
+		}
+	}
+	return nil
+}
+
+// issueClientCertificate signs a client certificate with the cluster CA
+func (c *Client) issueClientCertificate(ctx context.Context, namespace, clusterName string, opts CredentialsOptions) (*WorkloadCredentials, *clientcmdapi.AuthInfo, error) {
+	if opts.User == "" {
+		return nil, nil, errorf(ErrInvalidArgument, "a user is required for client certificates")
+	}
+	groups := opts.Groups
+	if len(groups) == 0 {
+		groups = defaultCredentialGroups
+	}
+
+	ca, err := c.getClusterCA(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, expires, err := ca.signClientCertificate(opts.User, groups, opts.TTL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &WorkloadCredentials{Method: CredentialCertificate, Subject: opts.User, Groups: groups, Expires: expires}, user, nil
+}
+
+// clusterCA is the certificate authority of a workload cluster's API server
+type clusterCA struct {
+	cert   *x509.Certificate
+	signer crypto.Signer
+	// pem is the CA certificate as stored in the Secret
+	pem []byte
+}
+
+// getClusterCA reads the cluster CA from the {cluster}-ca Secret
+func (c *Client) getClusterCA(ctx context.Context, namespace, clusterName string) (*clusterCA, error) {
+	secretName := clusterName + "-ca"
+	secret, err := c.k8sClient.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster CA: %w", resourceError("Secret", client.ObjectKey{Namespace: namespace, Name: secretName}, err))
+	}
+	pair, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, errorf(ErrPreconditionFailed, "Secret %s/%s does not hold the CA certificate and key: %v", namespace, secretName, err)
+	}
+	signer, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errorf(ErrPreconditionFailed, "Secret %s/%s holds an unsupported CA key", namespace, secretName)
+	}
+	return &clusterCA{cert: pair.Leaf, signer: signer, pem: secret.Data[corev1.TLSCertKey]}, nil
+}
+
+// signClientCertificate creates a key and a client certificate for user and
+// groups valid for ttl, or until the CA expires
+func (ca *clusterCA) signClientCertificate(user string, groups []string, ttl time.Duration) (*clientcmdapi.AuthInfo, time.Time, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to generate key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	now := time.Now()
+	expires := now.Add(ttl).Truncate(time.Second)
+	if expires.After(ca.cert.NotAfter) {
+		expires = ca.cert.NotAfter
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: user, Organization: groups},
+		NotBefore:    now.Add(-certificateBackdate),
+		NotAfter:     expires,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.signer)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to sign client certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to encode key: %w", err)
+	}
+
+	return &clientcmdapi.AuthInfo{
+		ClientCertificateData: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		ClientKeyData:         pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}, expires, nil
+}
+
+// requestServiceAccountToken requests a token of a ServiceAccount from the
+// workload cluster
+func (c *Client) requestServiceAccountToken(ctx context.Context, admin string, opts CredentialsOptions) (*WorkloadCredentials, *clientcmdapi.AuthInfo, error) {
+	namespace, name, ok := strings.Cut(opts.ServiceAccount, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, nil, errorf(ErrInvalidArgument, "a ServiceAccount is required for tokens as namespace/name, got %q", opts.ServiceAccount)
+	}
+	if opts.TTL < minTokenTTL {
+		return nil, nil, errorf(ErrInvalidArgument, "ttl of tokens must be at least %s, got %s", minTokenTTL, opts.TTL)
+	}
+
+	workload, err := c.workloadClientset(admin)
+	if err != nil {
+		return nil, nil, err
+	}
+	expirationSeconds := int64(opts.TTL.Seconds())
+	request := &authenticationv1.TokenRequest{Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expirationSeconds}}
+	response, err := workload.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, name, request, metav1.CreateOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to request token: %w", workloadError("ServiceAccount", client.ObjectKey{Namespace: namespace, Name: name}, err))
+	}
+
+	return &WorkloadCredentials{
+			Method:  CredentialToken,
+			Subject: fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name),
+			Expires: response.Status.ExpirationTimestamp.Time,
+		},
+		&clientcmdapi.AuthInfo{Token: response.Status.Token}, nil
+}
@@ -0,0 +1,149 @@
+package capi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// newTestCA returns the PEM certificate and key of a cluster CA
+func newTestCA(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kubernetes"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caKeyDER, err := x509.MarshalECPrivateKey(caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: caKeyDER})
+}
+
+func TestIssueWorkloadCredentials(t *testing.T) {
+	caPEM, caKeyPEM := newTestCA(t)
+
+	admin := clientcmdapi.NewConfig()
+	admin.Clusters["prod"] = &clientcmdapi.Cluster{Server: "https://prod.example.com:6443", CertificateAuthorityData: caPEM}
+	admin.AuthInfos["prod-admin"] = &clientcmdapi.AuthInfo{Token: "admin"}
+	admin.Contexts["prod-admin@prod"] = &clientcmdapi.Context{Cluster: "prod", AuthInfo: "prod-admin"}
+	admin.CurrentContext = "prod-admin@prod"
+	adminData, err := clientcmd.Write(*admin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenExpires := metav1.NewTime(time.Now().Add(30 * time.Minute).Truncate(time.Second))
+	workload := k8sfake.NewClientset()
+	workload.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		create := action.(k8stesting.CreateAction)
+		if create.GetSubresource() != "token" || create.GetNamespace() != "kube-system" {
+			return false, nil, nil
+		}
+		request := create.GetObject().(*authenticationv1.TokenRequest)
+		if *request.Spec.ExpirationSeconds != 1800 {
+			t.Errorf("token requested for %d seconds, want 1800", *request.Spec.ExpirationSeconds)
+		}
+		request.Status = authenticationv1.TokenRequestStatus{Token: "short-lived", ExpirationTimestamp: tokenExpires}
+		return true, request, nil
+	})
+
+	c := &Client{
+		k8sClient: k8sfake.NewClientset(
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-kubeconfig"}, Data: map[string][]byte{"value": adminData}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-ca"}, Data: map[string][]byte{
+				corev1.TLSCertKey:       caPEM,
+				corev1.TLSPrivateKeyKey: caKeyPEM,
+			}},
+		),
+		newWorkloadClientset: func(kubeconfig string) (kubernetes.Interface, error) {
+			return workload, nil
+		},
+	}
+
+	credentials, err := c.IssueWorkloadCredentials(context.Background(), "org-acme", "prod", CredentialsOptions{User: "alice", TTL: 2 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credentials.Server != "https://prod.example.com:6443" || credentials.Groups[0] != "system:masters" {
+		t.Errorf("credentials = %+v", credentials)
+	}
+	config, err := clientcmd.Load([]byte(credentials.Kubeconfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := config.AuthInfos["alice"]
+	if user == nil || len(user.ClientKeyData) == 0 || config.CurrentContext != "alice@prod" {
+		t.Fatalf("kubeconfig = %s", credentials.Kubeconfig)
+	}
+	certs := parseCertificates(user.ClientCertificateData)
+	if len(certs) != 1 {
+		t.Fatal("kubeconfig has no client certificate")
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caPEM)
+	if _, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}); err != nil {
+		t.Errorf("client certificate is not signed by the cluster CA: %v", err)
+	}
+	if certs[0].Subject.CommonName != "alice" || !certs[0].NotAfter.Equal(credentials.Expires) || time.Until(credentials.Expires) > 2*time.Hour {
+		t.Errorf("client certificate %s expires %s, want alice for 2h", certs[0].Subject, certs[0].NotAfter)
+	}
+
+	credentials, err = c.IssueWorkloadCredentials(context.Background(), "org-acme", "prod", CredentialsOptions{Method: CredentialToken, ServiceAccount: "kube-system/ops", TTL: 30 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if credentials.Subject != "system:serviceaccount:kube-system:ops" || !credentials.Expires.Equal(tokenExpires.Time) {
+		t.Errorf("token credentials = %+v", credentials)
+	}
+	if config, _ := clientcmd.Load([]byte(credentials.Kubeconfig)); config.AuthInfos["system-serviceaccount-kube-system-ops"].Token != "short-lived" {
+		t.Errorf("kubeconfig = %s, want the requested token", credentials.Kubeconfig)
+	}
+
+	invalid := []CredentialsOptions{
+		{User: "alice", TTL: 48 * time.Hour},
+		{Method: CredentialToken},
+		{Method: CredentialToken, ServiceAccount: "kube-system/ops", TTL: time.Minute},
+		{Method: "password"},
+	}
+	for _, opts := range invalid {
+		if _, err := c.IssueWorkloadCredentials(context.Background(), "org-acme", "prod", opts); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("IssueWorkloadCredentials(%+v) error = %v, want ErrInvalidArgument", opts, err)
+		}
+	}
+	if _, err := c.IssueWorkloadCredentials(context.Background(), "org-acme", "missing", CredentialsOptions{User: "alice"}); err == nil {
+		t.Error("IssueWorkloadCredentials() succeeded without a kubeconfig Secret")
+	}
+}
@@ -0,0 +1,216 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// infrastructureGroup is the API group of the infrastructure providers
+const infrastructureGroup = "infrastructure.cluster.x-k8s.io"
+
+// InfrastructureKind is a kind served in the infrastructure API group
+type InfrastructureKind struct {
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion"`
+	Resource   string `json:"resource"`
+	Namespaced bool   `json:"namespaced"`
+	// Provider is the registered provider the kind belongs to, or unknown
+	Provider Provider `json:"provider"`
+}
+
+// InfrastructureDiscovery lists what the infrastructure providers installed
+// on a management cluster serve
+type InfrastructureDiscovery struct {
+	Kinds []InfrastructureKind `json:"kinds"`
+	// Providers are the registered providers with at least one of their
+	// cluster kinds served, in registration order
+	Providers []Provider `json:"providers"`
+}
+
+// Served reports whether a provider has one of its cluster kinds served
+func (d *InfrastructureDiscovery) Served(provider Provider) bool {
+	return slices.Contains(d.Providers, provider)
+}
+
+// DiscoverInfrastructure lists the kinds of the infrastructure API group the
+// management cluster serves, at their preferred version, and the providers
+// they belong to. It reads the discovery API only, so it works without any
+// RBAC permission on the kinds themselves.
+func (c *Client) DiscoverInfrastructure(ctx context.Context) (*InfrastructureDiscovery, error) {
+	return discoverInfrastructure(c.k8sClient.Discovery())
+}
+
+// discoverInfrastructure reads the infrastructure kinds through discovery
+func discoverInfrastructure(dc discovery.DiscoveryInterface) (*InfrastructureDiscovery, error) {
+	groups, err := dc.ServerGroups()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover API groups: %w", err)
+	}
+
+	result := &InfrastructureDiscovery{Kinds: []InfrastructureKind{}, Providers: []Provider{}}
+	for _, group := range groups.Groups {
+		if group.Name != infrastructureGroup {
+			continue
+		}
+		version := group.PreferredVersion.GroupVersion
+		if version == "" && len(group.Versions) > 0 {
+			version = group.Versions[0].GroupVersion
+		}
+		resources, err := dc.ServerResourcesForGroupVersion(version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover %s resources: %w", version, err)
+		}
+		for _, resource := range resources.APIResources {
+			// Subresources such as awsclusters/status are not kinds of their own
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			result.Kinds = append(result.Kinds, InfrastructureKind{
+				Kind:       resource.Kind,
+				APIVersion: version,
+				Resource:   resource.Name,
+				Namespaced: resource.Namespaced,
+				Provider:   providerOfKind(resource.Kind),
+			})
+		}
+	}
+	sort.Slice(result.Kinds, func(i, j int) bool { return result.Kinds[i].Kind < result.Kinds[j].Kind })
+
+	for _, info := range Providers() {
+		for _, kind := range result.Kinds {
+			if slices.Contains(info.ClusterKinds, kind.Kind) {
+				result.Providers = append(result.Providers, info.Name)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// providerOfKind returns the provider of any infrastructure kind. Providers
+// prefix all their kinds like their cluster kinds, e.g. AWSMachine like
+// AWSCluster, so the longest matching prefix wins.
+func providerOfKind(kind string) Provider {
+	if provider := ProviderForKind(kind); provider != ProviderUnknown {
+		return provider
+	}
+	provider, longest := ProviderUnknown, 0
+	for _, info := range Providers() {
+		for _, clusterKind := range info.ClusterKinds {
+			prefix := strings.TrimSuffix(clusterKind, "Cluster")
+			if len(prefix) > longest && strings.HasPrefix(kind, prefix) {
+				provider, longest = info.Name, len(prefix)
+			}
+		}
+	}
+	return provider
+}
+
+// InfrastructureObject summarizes an object of an infrastructure kind
+type InfrastructureObject struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Cluster is the cluster the object is labeled with
+	Cluster        string    `json:"cluster,omitempty"`
+	Ready          bool      `json:"ready"`
+	FailureMessage string    `json:"failureMessage,omitempty"`
+	Created        time.Time `json:"created"`
+	// Spec and Status are only set by GetInfrastructureObject
+	Spec   map[string]any `json:"spec,omitempty"`
+	Status map[string]any `json:"status,omitempty"`
+}
+
+// ListInfrastructureObjects lists the objects of a served infrastructure
+// kind, optionally only those of a cluster
+func (c *Client) ListInfrastructureObjects(ctx context.Context, kind, namespace, clusterName string) ([]InfrastructureObject, error) {
+	served, err := c.infrastructureKind(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+	opts := []client.ListOption{client.InNamespace(namespace)}
+	if clusterName != "" {
+		opts = append(opts, client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName})
+	}
+	items, err := c.listUnstructured(ctx, schema.FromAPIVersionAndKind(served.APIVersion, served.Kind), opts...)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]InfrastructureObject, 0, len(items))
+	for i := range items {
+		objects = append(objects, newInfrastructureObject(&items[i]))
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		if objects[i].Namespace != objects[j].Namespace {
+			return objects[i].Namespace < objects[j].Namespace
+		}
+		return objects[i].Name < objects[j].Name
+	})
+	return objects, nil
+}
+
+// GetInfrastructureObject reads an object of a served infrastructure kind
+// with its spec and status
+func (c *Client) GetInfrastructureObject(ctx context.Context, kind, namespace, name string) (*InfrastructureObject, error) {
+	served, err := c.infrastructureKind(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := c.getReferenced(ctx, namespace, served.APIVersion, served.Kind, name)
+	if err != nil {
+		return nil, err
+	}
+	info := newInfrastructureObject(obj)
+	info.Spec, _, _ = unstructured.NestedMap(obj.Object, "spec")
+	info.Status, _, _ = unstructured.NestedMap(obj.Object, "status")
+	return &info, nil
+}
+
+// infrastructureKind resolves a kind, ignoring case, to the version the
+// management cluster serves
+func (c *Client) infrastructureKind(ctx context.Context, kind string) (*InfrastructureKind, error) {
+	discovered, err := c.DiscoverInfrastructure(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var kinds []string
+	for i := range discovered.Kinds {
+		if strings.EqualFold(discovered.Kinds[i].Kind, kind) {
+			return &discovered.Kinds[i], nil
+		}
+		kinds = append(kinds, discovered.Kinds[i].Kind)
+	}
+	if len(kinds) == 0 {
+		return nil, errorf(ErrNotFound, "the %s API is not served, no infrastructure provider is installed", infrastructureGroup)
+	}
+	return nil, errorf(ErrNotFound, "infrastructure kind %q is not served (served: %s)", kind, strings.Join(kinds, ", "))
+}
+
+// newInfrastructureObject summarizes an infrastructure object. Readiness is
+// read from status.ready and, for providers following the v1beta2 contract,
+// from status.initialization.provisioned.
+func newInfrastructureObject(obj *unstructured.Unstructured) InfrastructureObject {
+	info := InfrastructureObject{
+		Kind:      obj.GetKind(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Cluster:   obj.GetLabels()[clusterv1.ClusterNameLabel],
+		Created:   obj.GetCreationTimestamp().Time,
+	}
+	info.Ready, _, _ = unstructured.NestedBool(obj.Object, "status", "ready")
+	if provisioned, _, _ := unstructured.NestedBool(obj.Object, "status", "initialization", "provisioned"); provisioned {
+		info.Ready = true
+	}
+	info.FailureMessage, _, _ = unstructured.NestedString(obj.Object, "status", "failureMessage")
+	return info
+}
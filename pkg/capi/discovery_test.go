@@ -0,0 +1,139 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newDiscoveryClient returns a client whose discovery serves resources
+func newDiscoveryClient(t *testing.T, resources []*metav1.APIResourceList, objects ...*unstructured.Unstructured) *Client {
+	t.Helper()
+	clientset := k8sfake.NewClientset()
+	clientset.Discovery().(*discoveryfake.FakeDiscovery).Resources = resources
+
+	builder := fake.NewClientBuilder().WithScheme(runtime.NewScheme())
+	for _, obj := range objects {
+		builder = builder.WithObjects(obj)
+	}
+	return &Client{k8sClient: clientset, ctrlClient: builder.Build()}
+}
+
+func TestDiscoverInfrastructure(t *testing.T) {
+	c := newDiscoveryClient(t, []*metav1.APIResourceList{
+		{GroupVersion: "cluster.x-k8s.io/v1beta1", APIResources: []metav1.APIResource{{Name: "clusters", Kind: "Cluster", Namespaced: true}}},
+		{GroupVersion: "infrastructure.cluster.x-k8s.io/v1beta2", APIResources: []metav1.APIResource{
+			{Name: "awsclusters", Kind: "AWSCluster", Namespaced: true},
+			{Name: "awsclusters/status", Kind: "AWSCluster", Namespaced: true},
+			{Name: "awsmachines", Kind: "AWSMachine", Namespaced: true},
+			{Name: "awsclustercontrolleridentities", Kind: "AWSClusterControllerIdentity"},
+			{Name: "vspheremachines", Kind: "VSphereMachine", Namespaced: true},
+			{Name: "fooclusters", Kind: "FooCluster", Namespaced: true},
+		}},
+	})
+
+	discovered, err := c.DiscoverInfrastructure(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Provider{
+		"AWSCluster":                   ProviderAWS,
+		"AWSClusterControllerIdentity": ProviderAWS,
+		"AWSMachine":                   ProviderAWS,
+		"FooCluster":                   ProviderUnknown,
+		"VSphereMachine":               ProviderVSphere,
+	}
+	if len(discovered.Kinds) != len(want) {
+		t.Fatalf("Kinds = %+v, want %d kinds", discovered.Kinds, len(want))
+	}
+	for _, kind := range discovered.Kinds {
+		if kind.Provider != want[kind.Kind] {
+			t.Errorf("provider of %s = %s, want %s", kind.Kind, kind.Provider, want[kind.Kind])
+		}
+		if kind.APIVersion != "infrastructure.cluster.x-k8s.io/v1beta2" {
+			t.Errorf("APIVersion of %s = %s", kind.Kind, kind.APIVersion)
+		}
+	}
+	if discovered.Kinds[0].Kind != "AWSCluster" || discovered.Kinds[1].Namespaced {
+		t.Errorf("Kinds are not sorted by kind: %+v", discovered.Kinds)
+	}
+	// A machine kind alone does not make a provider usable
+	if len(discovered.Providers) != 1 || !discovered.Served(ProviderAWS) || discovered.Served(ProviderVSphere) {
+		t.Errorf("Providers = %v, want [aws]", discovered.Providers)
+	}
+
+	empty := newDiscoveryClient(t, []*metav1.APIResourceList{{GroupVersion: "apps/v1"}})
+	discovered, err = empty.DiscoverInfrastructure(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(discovered.Kinds) != 0 || len(discovered.Providers) != 0 {
+		t.Errorf("DiscoverInfrastructure() without providers = %+v", discovered)
+	}
+}
+
+func TestInfrastructureObjects(t *testing.T) {
+	newHetznerCluster := func(name, cluster string, status map[string]any) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{"hcloudNetwork": map[string]any{"enabled": true}}, "status": status}}
+		obj.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta1")
+		obj.SetKind("HetznerCluster")
+		obj.SetNamespace("org-a")
+		obj.SetName(name)
+		obj.SetLabels(map[string]string{clusterv1.ClusterNameLabel: cluster})
+		return obj
+	}
+	c := newDiscoveryClient(t,
+		[]*metav1.APIResourceList{{GroupVersion: "infrastructure.cluster.x-k8s.io/v1beta1", APIResources: []metav1.APIResource{
+			{Name: "hetznerclusters", Kind: "HetznerCluster", Namespaced: true},
+		}}},
+		newHetznerCluster("beta", "beta", map[string]any{"ready": true}),
+		newHetznerCluster("alpha", "alpha", map[string]any{"initialization": map[string]any{"provisioned": true}}),
+		newHetznerCluster("gamma", "gamma", map[string]any{"failureMessage": "network quota exceeded"}),
+	)
+	ctx := context.Background()
+
+	objects, err := c.ListInfrastructureObjects(ctx, "hetznercluster", "org-a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 3 || objects[0].Name != "alpha" || objects[2].Name != "gamma" {
+		t.Fatalf("ListInfrastructureObjects() = %+v, want alpha, beta and gamma", objects)
+	}
+	if !objects[0].Ready || !objects[1].Ready || objects[2].Ready || objects[2].FailureMessage == "" {
+		t.Errorf("readiness = %+v", objects)
+	}
+	if objects[0].Spec != nil {
+		t.Errorf("listed objects carry their spec: %+v", objects[0])
+	}
+
+	objects, err = c.ListInfrastructureObjects(ctx, "HetznerCluster", "", "beta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || objects[0].Cluster != "beta" {
+		t.Errorf("ListInfrastructureObjects() for cluster beta = %+v", objects)
+	}
+
+	object, err := c.GetInfrastructureObject(ctx, "HetznerCluster", "org-a", "alpha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if object.Spec["hcloudNetwork"] == nil || object.Status["initialization"] == nil {
+		t.Errorf("GetInfrastructureObject() = %+v, want spec and status", object)
+	}
+
+	if _, err := c.GetInfrastructureObject(ctx, "HetznerCluster", "org-a", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetInfrastructureObject() of a missing object = %v, want ErrNotFound", err)
+	}
+	if _, err := c.ListInfrastructureObjects(ctx, "AWSCluster", "", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ListInfrastructureObjects() of a kind not served = %v, want ErrNotFound", err)
+	}
+}
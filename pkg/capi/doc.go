@@ -22,6 +22,13 @@
 //	    log.Fatal(err)
 //	}
 //
+//	// Or tune the connection with options
+//	client, err = capi.NewClientWithOptions(
+//	    capi.WithContext("prod-admin"),
+//	    capi.WithRateLimit(50, 100),
+//	    capi.WithTimeout(30*time.Second),
+//	)
+//
 //	// List all clusters
 //	clusters, err := client.ListClusters(ctx, "default")
 //	if err != nil {
@@ -34,6 +41,31 @@
 //	    log.Fatal(err)
 //	}
 //
+// # API Contracts
+//
+// The client is written against the v1beta1 types of Cluster API. On first
+// use it discovers the versions served by the management cluster; APIContract
+// reports them. While v1beta1 is served the API server converts. Once a
+// release stops serving v1beta1, objects are read and written as v1beta2 and
+// converted by the client, including references, which carry an API group
+// instead of a version, and the relocated status fields. Readiness prefers
+// the v1beta2 conditions, such as Available for clusters, when controllers
+// report them.
+//
+// # Pagination
+//
+// ListClusters, ListMachines, ListMachineDeployments and GetClustersStatus
+// accept ListOptions. WithLimit caps the number of items per request; the
+// Continue field of the result then fetches the next page:
+//
+//	page, err := client.ListClusters(ctx, "", capi.WithLimit(100))
+//	for err == nil && page.Continue != "" {
+//	    page, err = client.ListClusters(ctx, "", capi.WithLimit(100), capi.WithContinue(page.Continue))
+//	}
+//
+// WithLabelSelector and WithFieldSelector filter the items on the server; the
+// label selector is combined with the cluster name filter of the method.
+//
 // # Cluster Operations
 //
 // The package provides comprehensive cluster management capabilities:
@@ -64,12 +96,67 @@
 // Note: Full node operations require access to the workload cluster,
 // which may not always be available from the management cluster context.
 //
+// # Writes and Concurrency
+//
+// Modifications are sent as merge patches guarded by the resourceVersion that
+// was read. When a controller updates the resource concurrently the write is
+// retried on a fresh copy, so only the fields changed by the operation are
+// touched and controller changes are never overwritten.
+//
+// Updates, scaling, upgrades and pause/resume snapshot the prior state of the
+// resource in a ChangeHistory, allowing RevertChange to roll them back.
+//
+// # Version Skew
+//
+// UpgradeCluster and version changes through UpdateMachineDeployment follow
+// the Kubernetes version skew policy. They refuse to downgrade, to skip a
+// minor version of the control plane, to run workers newer than their control
+// plane and to leave workers more than MaxKubeletSkew minor versions behind.
+// Violations match ErrPreconditionFailed; the Force option skips the checks.
+// PlanUpgrade reports the same violations as blockers without changing
+// anything.
+//
+// # Version Catalog
+//
+// AvailableVersions collects the Kubernetes versions a management cluster can
+// provision: those shipped by Giant Swarm releases, those of node images
+// referenced by vSphere, GCP and Azure machine templates, and those running
+// on existing clusters. Sources that are not installed are skipped. The
+// Default of the catalog replaces hardcoded versions when creating clusters.
+//
+// # Waiting for Readiness
+//
+// WaitForClusterReady, WaitForControlPlaneReady and
+// WaitForMachineDeploymentReady block until a resource is ready, the timeout
+// passes or the context ends. They watch the resource and fall back to
+// polling with exponential backoff when watching is not permitted. Progress is
+// reported through a callback, e.g. to log it or forward it to a user:
+//
+//	err := client.WaitForClusterReady(ctx, "default", "my-cluster", capi.WaitOptions{
+//	    Timeout: 20 * time.Minute,
+//	    OnProgress: func(p capi.WaitProgress) {
+//	        log.Printf("%s %s: %s", p.Kind, p.Name, p.Message)
+//	    },
+//	})
+//
 // # Error Handling
 //
 // All methods return detailed errors that can be inspected for specific
 // failure conditions. The package uses fmt.Errorf with %w for error wrapping,
 // allowing errors to be unwrapped and inspected.
 //
+// API errors are classified into sentinel errors such as ErrClusterNotFound,
+// ErrConflict, ErrForbidden and ErrWorkloadUnreachable, so callers can branch
+// on the kind of failure with errors.Is instead of matching messages:
+//
+//	if errors.Is(err, capi.ErrClusterNotFound) {
+//	    // offer to list clusters instead
+//	}
+//
+// A *ResourceError in the chain identifies the affected resource. Refusals
+// based on the arguments or on the current state of a resource match
+// ErrInvalidArgument and ErrPreconditionFailed respectively.
+//
 // # Thread Safety
 //
 // The Client struct and its methods are thread-safe and can be used
@@ -0,0 +1,148 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// dockerClusterGVK is the infrastructure cluster of the Docker provider
+	dockerClusterGVK = schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "DockerCluster"}
+	// dockerMachineTemplateGVK is the machine template of the Docker provider
+	dockerMachineTemplateGVK = schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "DockerMachineTemplate"}
+	// kubeadmConfigTemplateGVK is the bootstrap template of kubeadm workers
+	kubeadmConfigTemplateGVK = schema.GroupVersionKind{Group: "bootstrap.cluster.x-k8s.io", Version: "v1beta1", Kind: "KubeadmConfigTemplate"}
+)
+
+// dockerEvictionHard disables disk pressure eviction, which the small disks of
+// kind nodes would otherwise trigger
+const dockerEvictionHard = "nodefs.available<0%,nodefs.inodesFree<0%,imagefs.available<0%"
+
+// dockerServiceCIDR is CAPD's default service range, clear of kind's own one
+const dockerServiceCIDR = "10.128.0.0/12"
+
+// createDockerCluster creates a development cluster on the Docker provider
+// (CAPD): the DockerCluster, a KubeadmControlPlane and a MachineDeployment
+// with their templates, and the Cluster itself once they exist. It follows
+// the development template of CAPD, so a kind management cluster with the
+// Docker socket mounted can run it.
+func (c *Client) createDockerCluster(ctx context.Context, cluster *clusterv1.Cluster, opts CreateClusterOptions) (*clusterv1.Cluster, error) {
+	cluster.Spec.ClusterNetwork.Services.CIDRBlocks = []string{dockerServiceCIDR}
+	cluster.Spec.ClusterNetwork.ServiceDomain = "cluster.local"
+
+	for _, obj := range dockerClusterObjects(cluster, opts) {
+		if err := c.ctrlClient.Create(ctx, obj); err != nil {
+			kind := obj.GetObjectKind().GroupVersionKind().Kind
+			return nil, fmt.Errorf("failed to create %s: %w", kind, resourceError(kind, client.ObjectKeyFromObject(obj), err))
+		}
+	}
+	return cluster, nil
+}
+
+// dockerClusterObjects returns the objects of a CAPD development cluster, the
+// Cluster last
+func dockerClusterObjects(cluster *clusterv1.Cluster, opts CreateClusterOptions) []client.Object {
+	labels := map[string]string{clusterv1.ClusterNameLabel: opts.Name}
+	controlPlaneTemplate := opts.Name + "-control-plane"
+	workerTemplate := opts.Name + "-md-0"
+
+	dockerCluster := newUnstructured(dockerClusterGVK, opts.Namespace, cluster.Spec.InfrastructureRef.Name, labels)
+	dockerCluster.Object["spec"] = map[string]any{}
+
+	// The control plane nodes mount the Docker socket like in the CAPD
+	// development template, for workloads running kind themselves
+	controlPlaneMachines := newUnstructured(dockerMachineTemplateGVK, opts.Namespace, controlPlaneTemplate, labels)
+	controlPlaneMachines.Object["spec"] = map[string]any{"template": map[string]any{"spec": map[string]any{
+		"extraMounts": []any{map[string]any{"containerPath": "/var/run/docker.sock", "hostPath": "/var/run/docker.sock"}},
+	}}}
+	workerMachines := newUnstructured(dockerMachineTemplateGVK, opts.Namespace, workerTemplate, labels)
+	workerMachines.Object["spec"] = map[string]any{"template": map[string]any{"spec": map[string]any{}}}
+
+	nodeRegistration := bootstrapv1.NodeRegistrationOptions{
+		CRISocket:        "unix:///var/run/containerd/containerd.sock",
+		KubeletExtraArgs: map[string]string{"eviction-hard": dockerEvictionHard},
+	}
+	kcp := &controlplanev1.KubeadmControlPlane{
+		TypeMeta:   metav1.TypeMeta{APIVersion: controlplanev1.GroupVersion.String(), Kind: "KubeadmControlPlane"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: opts.Namespace, Name: cluster.Spec.ControlPlaneRef.Name, Labels: labels},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			Replicas: &opts.ControlPlaneCount,
+			Version:  opts.KubernetesVersion,
+			MachineTemplate: controlplanev1.KubeadmControlPlaneMachineTemplate{
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: dockerMachineTemplateGVK.GroupVersion().String(),
+					Kind:       dockerMachineTemplateGVK.Kind,
+					Name:       controlPlaneTemplate,
+				},
+			},
+			KubeadmConfigSpec: bootstrapv1.KubeadmConfigSpec{
+				ClusterConfiguration: &bootstrapv1.ClusterConfiguration{
+					APIServer: bootstrapv1.APIServer{CertSANs: []string{"localhost", "127.0.0.1", "0.0.0.0", "host.docker.internal"}},
+				},
+				InitConfiguration: &bootstrapv1.InitConfiguration{NodeRegistration: nodeRegistration},
+				JoinConfiguration: &bootstrapv1.JoinConfiguration{NodeRegistration: nodeRegistration},
+			},
+		},
+	}
+
+	workerBootstrap := newUnstructured(kubeadmConfigTemplateGVK, opts.Namespace, workerTemplate, labels)
+	workerBootstrap.Object["spec"] = map[string]any{"template": map[string]any{"spec": map[string]any{
+		"joinConfiguration": map[string]any{"nodeRegistration": map[string]any{
+			"criSocket":        nodeRegistration.CRISocket,
+			"kubeletExtraArgs": map[string]any{"eviction-hard": dockerEvictionHard},
+		}},
+	}}}
+
+	machineLabels := map[string]string{
+		clusterv1.ClusterNameLabel:           opts.Name,
+		clusterv1.MachineDeploymentNameLabel: workerTemplate,
+	}
+	md := &clusterv1.MachineDeployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: clusterv1.GroupVersion.String(), Kind: "MachineDeployment"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: opts.Namespace, Name: workerTemplate, Labels: labels},
+		Spec: clusterv1.MachineDeploymentSpec{
+			ClusterName: opts.Name,
+			Replicas:    &opts.WorkerCount,
+			Selector:    metav1.LabelSelector{MatchLabels: machineLabels},
+			Template: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{Labels: machineLabels},
+				Spec: clusterv1.MachineSpec{
+					ClusterName: opts.Name,
+					Version:     &opts.KubernetesVersion,
+					InfrastructureRef: corev1.ObjectReference{
+						APIVersion: dockerMachineTemplateGVK.GroupVersion().String(),
+						Kind:       dockerMachineTemplateGVK.Kind,
+						Name:       workerTemplate,
+					},
+					Bootstrap: clusterv1.Bootstrap{ConfigRef: &corev1.ObjectReference{
+						APIVersion: kubeadmConfigTemplateGVK.GroupVersion().String(),
+						Kind:       kubeadmConfigTemplateGVK.Kind,
+						Name:       workerTemplate,
+					}},
+				},
+			},
+		},
+	}
+
+	cluster.TypeMeta = metav1.TypeMeta{APIVersion: clusterv1.GroupVersion.String(), Kind: "Cluster"}
+	return []client.Object{dockerCluster, controlPlaneMachines, workerMachines, kcp, workerBootstrap, md, cluster}
+}
+
+// newUnstructured returns an empty object of a kind
+func newUnstructured(gvk schema.GroupVersionKind, namespace, name string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]any{}}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetLabels(labels)
+	return obj
+}
@@ -0,0 +1,71 @@
+package capi
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCreateDockerCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).Build()}
+	ctx := context.Background()
+
+	cluster, err := c.CreateCluster(ctx, CreateClusterOptions{
+		Name: "dev", Namespace: "default", InfraProvider: "docker",
+		KubernetesVersion: "v1.30.0", ControlPlaneCount: 1, WorkerCount: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref := cluster.Spec.InfrastructureRef; ref.Kind != "DockerCluster" || ref.Name != "dev" {
+		t.Errorf("infrastructure ref = %+v", ref)
+	}
+	if ClusterProvider(cluster) != ProviderDocker {
+		t.Errorf("ClusterProvider() = %s, want docker", ClusterProvider(cluster))
+	}
+
+	kcp, err := c.GetKubeadmControlPlane(ctx, "default", "dev-control-plane")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *kcp.Spec.Replicas != 1 || kcp.Spec.Version != "v1.30.0" || kcp.Spec.MachineTemplate.InfrastructureRef.Kind != "DockerMachineTemplate" {
+		t.Errorf("KubeadmControlPlane spec = %+v", kcp.Spec)
+	}
+	md := &clusterv1.MachineDeployment{}
+	if err := c.ctrlClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "dev-md-0"}, md); err != nil {
+		t.Fatal(err)
+	}
+	if *md.Spec.Replicas != 2 || md.Spec.Template.Spec.Bootstrap.ConfigRef.Kind != "KubeadmConfigTemplate" {
+		t.Errorf("MachineDeployment spec = %+v", md.Spec)
+	}
+
+	for _, ref := range []struct {
+		gvk  schema.GroupVersionKind
+		name string
+	}{
+		{dockerClusterGVK, "dev"},
+		{dockerMachineTemplateGVK, "dev-control-plane"},
+		{dockerMachineTemplateGVK, "dev-md-0"},
+		{kubeadmConfigTemplateGVK, "dev-md-0"},
+	} {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(ref.gvk)
+		if err := c.ctrlClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: ref.name}, obj); err != nil {
+			t.Errorf("%s %s was not created: %v", ref.gvk.Kind, ref.name, err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package capi
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Sentinel errors returned by the client. They can be matched with errors.Is
+// regardless of how much context the error has been wrapped with.
+var (
+	// ErrNotFound is returned when a requested resource does not exist
+	ErrNotFound = errors.New("resource not found")
+
+	// ErrClusterNotFound is returned when a requested Cluster does not exist.
+	// Errors matching it also match ErrNotFound.
+	ErrClusterNotFound = errors.New("cluster not found")
+
+	// ErrConflict is returned when a write kept conflicting with concurrent modifications
+	ErrConflict = errors.New("resource was modified concurrently")
+
+	// ErrForbidden is returned when the server's identity is not allowed to perform an operation.
+	// *MissingPermissionsError values match it as well.
+	ErrForbidden = errors.New("operation forbidden")
+
+	// ErrWorkloadUnreachable is returned when the API server holding the workload
+	// cluster's nodes cannot be reached
+	ErrWorkloadUnreachable = errors.New("workload cluster unreachable")
+
+	// ErrInvalidArgument is returned when the options passed to a method are invalid
+	ErrInvalidArgument = errors.New("invalid argument")
+
+	// ErrAlreadyExists is returned when creating a resource that already exists
+	ErrAlreadyExists = errors.New("resource already exists")
+
+	// ErrPreconditionFailed is returned when a safety check refuses an operation
+	// in the current state of the resource
+	ErrPreconditionFailed = errors.New("precondition failed")
+
+	// ErrOperationInProgress is returned when another operation holds the
+	// lock of a cluster
+	ErrOperationInProgress = errors.New("operation in progress")
+
+	// ErrDrainIncomplete is returned by DrainNode after cordoning a node whose
+	// pods could not be evicted
+	ErrDrainIncomplete = errors.New("node was cordoned but its pods were not evicted")
+)
+
+// ResourceError annotates an API error with the resource it concerns and
+// classifies it into the matching sentinel errors. Use errors.As to retrieve
+// the resource from a wrapped error.
+type ResourceError struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Err       error
+}
+
+// Error returns the underlying error; callers add their own context
+func (e *ResourceError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap exposes both the underlying error and the matching sentinel errors
+func (e *ResourceError) Unwrap() []error {
+	return append([]error{e.Err}, classifyError(e.Kind, e.Err)...)
+}
+
+// Is lets *MissingPermissionsError match ErrForbidden
+func (e *MissingPermissionsError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// classifyError maps API and transport errors to sentinel errors
+func classifyError(kind string, err error) []error {
+	switch {
+	case apierrors.IsNotFound(err):
+		if kind == "Cluster" {
+			return []error{ErrClusterNotFound, ErrNotFound}
+		}
+		return []error{ErrNotFound}
+	case apierrors.IsConflict(err):
+		return []error{ErrConflict}
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return []error{ErrForbidden}
+	case apierrors.IsAlreadyExists(err):
+		return []error{ErrAlreadyExists}
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		return []error{ErrInvalidArgument}
+	}
+	return nil
+}
+
+// sentinelError carries a descriptive message while matching a sentinel error
+type sentinelError struct {
+	sentinel error
+	message  string
+}
+
+func (e *sentinelError) Error() string {
+	return e.message
+}
+
+func (e *sentinelError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+// errorf formats an error message that matches sentinel with errors.Is
+func errorf(sentinel error, format string, args ...any) error {
+	return &sentinelError{sentinel: sentinel, message: fmt.Sprintf(format, args...)}
+}
+
+// resourceError wraps err with resource information, or returns nil if err is nil
+func resourceError(kind string, key client.ObjectKey, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ResourceError{Kind: kind, Namespace: key.Namespace, Name: key.Name, Err: err}
+}
+
+// nodeError wraps errors from node operations and flags transport failures as ErrWorkloadUnreachable
+func nodeError(nodeName string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || apierrors.IsServiceUnavailable(err) || apierrors.IsTimeout(err) {
+		return fmt.Errorf("%w: node %s: %w", ErrWorkloadUnreachable, nodeName, err)
+	}
+	return resourceError("Node", client.ObjectKey{Name: nodeName}, err)
+}
@@ -0,0 +1,58 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestGetClusterNotFound(t *testing.T) {
+	c := newChangeTestClient(t, nil)
+
+	_, err := c.GetCluster(context.Background(), "default", "missing")
+	if !errors.Is(err, ErrClusterNotFound) || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrClusterNotFound, got %v", err)
+	}
+	if !apierrors.IsNotFound(err) {
+		t.Error("the underlying API error should remain inspectable")
+	}
+
+	var resourceErr *ResourceError
+	if !errors.As(err, &resourceErr) || resourceErr.Kind != "Cluster" || resourceErr.Name != "missing" {
+		t.Errorf("expected *ResourceError for the cluster, got %#v", resourceErr)
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	gr := schema.GroupResource{Group: "cluster.x-k8s.io", Resource: "machinedeployments"}
+	key := client.ObjectKey{Namespace: "default", Name: "workers"}
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{"conflict", resourceError("MachineDeployment", key, apierrors.NewConflict(gr, "workers", nil)), ErrConflict, true},
+		{"forbidden", resourceError("MachineDeployment", key, apierrors.NewForbidden(gr, "workers", nil)), ErrForbidden, true},
+		{"not found is not a cluster", resourceError("MachineDeployment", key, apierrors.NewNotFound(gr, "workers")), ErrClusterNotFound, false},
+		{"missing permissions", &MissingPermissionsError{Missing: []PermissionCheck{{Verb: "update", Resource: "nodes"}}}, ErrForbidden, true},
+		{"unreachable", nodeError("worker-1", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), ErrWorkloadUnreachable, true},
+		{"already exists", resourceError("MachineDeployment", key, apierrors.NewAlreadyExists(gr, "workers")), ErrAlreadyExists, true},
+		{"refused by safety check", errorf(ErrPreconditionFailed, "machine %s is healthy", "m-1"), ErrPreconditionFailed, true},
+		{"node not found", nodeError("worker-1", apierrors.NewNotFound(schema.GroupResource{Resource: "nodes"}, "worker-1")), ErrWorkloadUnreachable, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("errors.Is(%v, %v) = %v, want %v", tt.err, tt.target, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+package capi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+)
+
+// etcdctlFlags connect etcdctl in the etcd static pods of kubeadm to the
+// local member with the health check client certificate
+var etcdctlFlags = []string{
+	"--cacert=/etc/kubernetes/pki/etcd/ca.crt",
+	"--cert=/etc/kubernetes/pki/etcd/healthcheck-client.crt",
+	"--key=/etc/kubernetes/pki/etcd/healthcheck-client.key",
+	"--command-timeout=5s",
+	"--write-out=json",
+}
+
+// etcdLocalEndpoint is the client URL of the member of an etcd static pod
+const etcdLocalEndpoint = "https://127.0.0.1:2379"
+
+// etcdAlarms names the alarm types of etcd
+var etcdAlarms = map[int]string{1: "NOSPACE", 2: "CORRUPT"}
+
+// EtcdStatus describes the etcd cluster of a kubeadm control plane
+type EtcdStatus struct {
+	ControlPlane string `json:"controlPlane"`
+	// Healthy is the EtcdClusterHealthy condition of the control plane, and
+	// false when queried members raise alarms or cannot be reached
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message,omitempty"`
+	// External is set when the control plane uses an external etcd, which is
+	// not managed or inspected
+	External bool         `json:"external,omitempty"`
+	Leader   string       `json:"leader,omitempty"`
+	Alarms   []string     `json:"alarms,omitempty"`
+	Members  []EtcdMember `json:"members"`
+	// Queried is set when the members were queried with etcdctl in the etcd
+	// pods of the workload cluster; QueryError tells why they were not
+	Queried    bool   `json:"queried"`
+	QueryError string `json:"queryError,omitempty"`
+}
+
+// EtcdMember is an etcd member of a control plane machine. The conditions
+// are surfaced by the KubeadmControlPlane controller, the other fields are
+// only set by direct queries.
+type EtcdMember struct {
+	Machine       string   `json:"machine,omitempty"`
+	Node          string   `json:"node,omitempty"`
+	PodHealthy    string   `json:"podHealthy,omitempty"`
+	PodMessage    string   `json:"podMessage,omitempty"`
+	MemberHealthy string   `json:"memberHealthy,omitempty"`
+	MemberMessage string   `json:"memberMessage,omitempty"`
+	ID            string   `json:"id,omitempty"`
+	Name          string   `json:"name,omitempty"`
+	PeerURLs      []string `json:"peerURLs,omitempty"`
+	Version       string   `json:"version,omitempty"`
+	Leader        bool     `json:"leader,omitempty"`
+	Learner       bool     `json:"learner,omitempty"`
+	DBSize        int64    `json:"dbSize,omitempty"`
+	DBSizeInUse   int64    `json:"dbSizeInUse,omitempty"`
+	RaftTerm      uint64   `json:"raftTerm,omitempty"`
+	RaftIndex     uint64   `json:"raftIndex,omitempty"`
+	Errors        []string `json:"errors,omitempty"`
+}
+
+// etcdMemberList is the output of etcdctl member list
+type etcdMemberList struct {
+	Members []struct {
+		ID         uint64   `json:"ID"`
+		Name       string   `json:"name"`
+		PeerURLs   []string `json:"peerURLs"`
+		ClientURLs []string `json:"clientURLs"`
+		IsLearner  bool     `json:"isLearner"`
+	} `json:"members"`
+}
+
+// etcdEndpointStatus is an entry of the output of etcdctl endpoint status
+type etcdEndpointStatus struct {
+	Endpoint string `json:"Endpoint"`
+	Status   struct {
+		Header struct {
+			MemberID uint64 `json:"member_id"`
+		} `json:"header"`
+		Version     string   `json:"version"`
+		DBSize      int64    `json:"dbSize"`
+		DBSizeInUse int64    `json:"dbSizeInUse"`
+		Leader      uint64   `json:"leader"`
+		RaftIndex   uint64   `json:"raftIndex"`
+		RaftTerm    uint64   `json:"raftTerm"`
+		IsLearner   bool     `json:"isLearner"`
+		Errors      []string `json:"errors"`
+	} `json:"Status"`
+}
+
+// etcdAlarmList is the output of etcdctl alarm list
+type etcdAlarmList struct {
+	Alarms []struct {
+		MemberID uint64 `json:"memberID"`
+		Alarm    int    `json:"alarm"`
+	} `json:"alarms"`
+}
+
+// GetEtcdStatus reports the etcd members of the control plane machines of a
+// cluster from the conditions of the KubeadmControlPlane controller. With
+// query, the members are also queried with etcdctl in the etcd pods of the
+// workload cluster for the leader, alarms, database size and raft state.
+func (c *Client) GetEtcdStatus(ctx context.Context, namespace, clusterName string, query bool) (*EtcdStatus, error) {
+	cluster, err := c.GetCluster(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	ref := cluster.Spec.ControlPlaneRef
+	if ref == nil {
+		return nil, errorf(ErrPreconditionFailed, "cluster %s/%s has no control plane reference", namespace, clusterName)
+	}
+	if ref.Kind != "KubeadmControlPlane" {
+		return nil, errorf(ErrPreconditionFailed, "the etcd of %s control planes is not managed by Cluster API", ref.Kind)
+	}
+	kcpNamespace := namespace
+	if ref.Namespace != "" {
+		kcpNamespace = ref.Namespace
+	}
+	kcp, err := c.GetKubeadmControlPlane(ctx, kcpNamespace, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &EtcdStatus{ControlPlane: kcpNamespace + "/" + kcp.Name, Members: []EtcdMember{}}
+	if config := kcp.Spec.KubeadmConfigSpec.ClusterConfiguration; config != nil && config.Etcd.External != nil {
+		status.External = true
+		status.Message = "The control plane uses an external etcd"
+		return status, nil
+	}
+	if condition := findCondition(kcp.Status.Conditions, controlplanev1.EtcdClusterHealthyCondition); condition != nil {
+		status.Healthy = condition.Status == corev1.ConditionTrue
+		status.Message = condition.Message
+	} else {
+		status.Message = "The control plane does not report the health of etcd yet"
+	}
+
+	machines, err := c.ListMachines(ctx, namespace, clusterName, WithLabelSelector(clusterv1.MachineControlPlaneLabel))
+	if err != nil {
+		return nil, err
+	}
+	for _, machine := range machines.Items {
+		member := EtcdMember{Machine: machine.Name}
+		if machine.Status.NodeRef != nil {
+			member.Node = machine.Status.NodeRef.Name
+		}
+		if condition := findCondition(machine.Status.Conditions, controlplanev1.MachineEtcdPodHealthyCondition); condition != nil {
+			member.PodHealthy, member.PodMessage = string(condition.Status), condition.Message
+		}
+		if condition := findCondition(machine.Status.Conditions, controlplanev1.MachineEtcdMemberHealthyCondition); condition != nil {
+			member.MemberHealthy, member.MemberMessage = string(condition.Status), condition.Message
+		}
+		status.Members = append(status.Members, member)
+	}
+	sort.Slice(status.Members, func(i, j int) bool { return status.Members[i].Machine < status.Members[j].Machine })
+
+	if query {
+		if err := c.queryEtcd(ctx, namespace, clusterName, status); err != nil {
+			status.QueryError = err.Error()
+		} else {
+			status.Queried = true
+		}
+	}
+	return status, nil
+}
+
+// queryEtcd adds the member list, endpoint status and alarms reported by
+// etcdctl to status, using the first etcd pod that answers
+func (c *Client) queryEtcd(ctx context.Context, namespace, clusterName string, status *EtcdStatus) error {
+	kubeconfig, err := c.GetKubeconfig(ctx, namespace, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+
+	var pods []string
+	for _, member := range status.Members {
+		if member.Node != "" {
+			pods = append(pods, "etcd-"+member.Node)
+		}
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("no control plane machine has a node yet")
+	}
+
+	var (
+		pod     string
+		members etcdMemberList
+		lastErr error
+	)
+	for _, candidate := range pods {
+		if lastErr = c.etcdctl(ctx, kubeconfig, candidate, etcdLocalEndpoint, &members, "member", "list"); lastErr == nil {
+			pod = candidate
+			break
+		}
+	}
+	if pod == "" {
+		return lastErr
+	}
+
+	byName := map[string]int{}
+	for i, member := range status.Members {
+		byName[member.Node] = i
+	}
+	names := map[uint64]string{}
+	for _, listed := range members.Members {
+		// Members are named after their node by kubeadm
+		i, ok := byName[listed.Name]
+		if !ok {
+			status.Members = append(status.Members, EtcdMember{})
+			i = len(status.Members) - 1
+			status.Members[i].Errors = append(status.Members[i].Errors, "no control plane machine runs this member")
+		}
+		member := &status.Members[i]
+		member.ID = fmt.Sprintf("%x", listed.ID)
+		member.Name = listed.Name
+		member.PeerURLs = listed.PeerURLs
+		member.Learner = listed.IsLearner
+		names[listed.ID] = listed.Name
+		if len(listed.ClientURLs) == 0 {
+			member.Errors = append(member.Errors, "member has not started")
+			status.Healthy = false
+			continue
+		}
+
+		var endpoints []etcdEndpointStatus
+		if err := c.etcdctl(ctx, kubeconfig, pod, strings.Join(listed.ClientURLs, ","), &endpoints, "endpoint", "status"); err != nil {
+			member.Errors = append(member.Errors, fmt.Sprintf("unreachable: %v", err))
+			status.Healthy = false
+			continue
+		}
+		for _, endpoint := range endpoints {
+			member.Version = endpoint.Status.Version
+			member.DBSize = endpoint.Status.DBSize
+			member.DBSizeInUse = endpoint.Status.DBSizeInUse
+			member.RaftTerm = endpoint.Status.RaftTerm
+			member.RaftIndex = endpoint.Status.RaftIndex
+			member.Leader = endpoint.Status.Leader == listed.ID
+			member.Errors = append(member.Errors, endpoint.Status.Errors...)
+			if endpoint.Status.Leader != 0 && status.Leader == "" {
+				status.Leader = fmt.Sprintf("%x", endpoint.Status.Leader)
+			}
+		}
+	}
+	if name, ok := names[parseHexID(status.Leader)]; ok {
+		status.Leader = name
+	}
+	for _, member := range status.Members {
+		if member.Name == "" && member.Machine != "" {
+			status.Healthy = false
+			break
+		}
+	}
+
+	var alarms etcdAlarmList
+	if err := c.etcdctl(ctx, kubeconfig, pod, etcdLocalEndpoint, &alarms, "alarm", "list"); err != nil {
+		return err
+	}
+	for _, alarm := range alarms.Alarms {
+		name, ok := etcdAlarms[alarm.Alarm]
+		if !ok {
+			name = fmt.Sprintf("alarm %d", alarm.Alarm)
+		}
+		member := names[alarm.MemberID]
+		if member == "" {
+			member = fmt.Sprintf("%x", alarm.MemberID)
+		}
+		status.Alarms = append(status.Alarms, fmt.Sprintf("%s on member %s", name, member))
+		status.Healthy = false
+	}
+	return nil
+}
+
+// etcdctl runs etcdctl in an etcd pod against endpoints and decodes its JSON
+// output into result
+func (c *Client) etcdctl(ctx context.Context, kubeconfig, pod, endpoints string, result any, args ...string) error {
+	command := append([]string{"etcdctl", "--endpoints=" + endpoints}, etcdctlFlags...)
+	output, err := c.execInWorkloadPod(ctx, kubeconfig, PodExec{
+		Namespace: "kube-system",
+		Pod:       pod,
+		Container: "etcd",
+		Command:   append(command, args...),
+	})
+	if err != nil {
+		return fmt.Errorf("etcdctl %s in %s: %w", strings.Join(args, " "), pod, err)
+	}
+	if err := json.Unmarshal([]byte(output), result); err != nil {
+		return fmt.Errorf("failed to parse the output of etcdctl %s: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// parseHexID parses a member ID as printed by etcdctl
+func parseHexID(id string) uint64 {
+	var value uint64
+	_, _ = fmt.Sscanf(id, "%x", &value)
+	return value
+}
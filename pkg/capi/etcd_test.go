@@ -0,0 +1,123 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetEtcdStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	controlPlaneMachine := func(name, node string, memberHealthy corev1.ConditionStatus) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: name, Labels: map[string]string{
+				clusterv1.ClusterNameLabel:         "prod",
+				clusterv1.MachineControlPlaneLabel: "",
+			}},
+			Status: clusterv1.MachineStatus{
+				NodeRef: &corev1.ObjectReference{Name: node},
+				Conditions: clusterv1.Conditions{
+					{Type: controlplanev1.MachineEtcdPodHealthyCondition, Status: corev1.ConditionTrue},
+					{Type: controlplanev1.MachineEtcdMemberHealthyCondition, Status: memberHealthy},
+				},
+			},
+		}
+	}
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-cp"},
+		Status: controlplanev1.KubeadmControlPlaneStatus{Conditions: clusterv1.Conditions{
+			{Type: controlplanev1.EtcdClusterHealthyCondition, Status: corev1.ConditionTrue},
+		}},
+	}
+	worker := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-md-1", Labels: map[string]string{clusterv1.ClusterNameLabel: "prod"}}}
+	ctrlClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod"},
+			Spec:       clusterv1.ClusterSpec{ControlPlaneRef: &corev1.ObjectReference{Kind: "KubeadmControlPlane", Name: "prod-cp"}},
+		},
+		&clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "eks"},
+			Spec:       clusterv1.ClusterSpec{ControlPlaneRef: &corev1.ObjectReference{Kind: "AWSManagedControlPlane", Name: "eks"}},
+		},
+		kcp, worker,
+		controlPlaneMachine("prod-cp-a", "node-a", corev1.ConditionTrue),
+		controlPlaneMachine("prod-cp-b", "node-b", corev1.ConditionFalse),
+	).Build()
+
+	outputs := map[string]string{
+		"member list": `{"members":[
+			{"ID":10,"name":"node-a","peerURLs":["https://10.0.0.1:2380"],"clientURLs":["https://10.0.0.1:2379"]},
+			{"ID":11,"name":"node-b","peerURLs":["https://10.0.0.2:2380"],"clientURLs":["https://10.0.0.2:2379"]}]}`,
+		"https://10.0.0.1:2379 endpoint status": `[{"Endpoint":"https://10.0.0.1:2379","Status":{"header":{"member_id":10},"version":"3.5.12","dbSize":104857600,"dbSizeInUse":52428800,"leader":10,"raftIndex":42,"raftTerm":3}}]`,
+		"alarm list":                            `{"alarms":[{"memberID":10,"alarm":1}]}`,
+	}
+	var pods []string
+	c := &Client{
+		ctrlClient: ctrlClient,
+		k8sClient: k8sfake.NewClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-kubeconfig"},
+			Data:       map[string][]byte{"value": []byte("kubeconfig")},
+		}),
+		execInWorkload: func(ctx context.Context, kubeconfig string, exec PodExec) (string, error) {
+			pods = append(pods, exec.Pod)
+			args := strings.Join(exec.Command[len(exec.Command)-2:], " ")
+			if args == "endpoint status" {
+				args = strings.TrimPrefix(exec.Command[1], "--endpoints=") + " " + args
+			}
+			if output, ok := outputs[args]; ok && exec.Namespace == "kube-system" && exec.Container == "etcd" {
+				return output, nil
+			}
+			return "", fmt.Errorf("context deadline exceeded")
+		},
+	}
+
+	status, err := c.GetEtcdStatus(context.Background(), "org-acme", "prod", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Healthy || status.Queried || len(status.Members) != 2 || status.Members[1].MemberHealthy != "False" || len(pods) != 0 {
+		t.Errorf("status without query = %+v", status)
+	}
+
+	status, err = c.GetEtcdStatus(context.Background(), "org-acme", "prod", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Queried || status.Healthy || status.Leader != "node-a" {
+		t.Errorf("status = %+v, want node-a leading an unhealthy cluster", status)
+	}
+	if len(status.Alarms) != 1 || status.Alarms[0] != "NOSPACE on member node-a" {
+		t.Errorf("alarms = %v, want NOSPACE on node-a", status.Alarms)
+	}
+	a, b := status.Members[0], status.Members[1]
+	if a.ID != "a" || !a.Leader || a.DBSize != 104857600 || a.Version != "3.5.12" || len(a.Errors) != 0 {
+		t.Errorf("member a = %+v", a)
+	}
+	if b.Machine != "prod-cp-b" || b.ID != "b" || len(b.Errors) != 1 || !strings.Contains(b.Errors[0], "unreachable") {
+		t.Errorf("member b = %+v, want it unreachable", b)
+	}
+	if pods[0] != "etcd-node-a" {
+		t.Errorf("queried pods %v, want etcd-node-a first", pods)
+	}
+
+	if _, err := c.GetEtcdStatus(context.Background(), "org-acme", "eks", true); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("GetEtcdStatus(eks) error = %v, want ErrPreconditionFailed", err)
+	}
+}
@@ -0,0 +1,133 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// DefaultStuckAfter is how long a cluster may be not ready before the fleet
+// summary reports it as stuck
+const DefaultStuckAfter = 30 * time.Minute
+
+// Health of a cluster in the fleet summary
+const (
+	// HealthHealthy clusters are ready with all machines running a node
+	HealthHealthy = "healthy"
+	// HealthDegraded clusters are ready but some machines have no node
+	HealthDegraded = "degraded"
+	// HealthUnhealthy clusters are not ready
+	HealthUnhealthy = "unhealthy"
+)
+
+// FleetSummary aggregates the clusters of a management cluster
+type FleetSummary struct {
+	TotalClusters  int              `json:"totalClusters"`
+	TotalMachines  int              `json:"totalMachines"`
+	ReadyMachines  int              `json:"readyMachines"`
+	ByProvider     map[Provider]int `json:"byProvider"`
+	ByPhase        map[string]int   `json:"byPhase"`
+	ByVersion      map[string]int   `json:"byVersion"`
+	ByHealth       map[string]int   `json:"byHealth"`
+	NeedsAttention []FleetIssue     `json:"needsAttention"`
+}
+
+// FleetIssue describes a cluster that is not healthy
+type FleetIssue struct {
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Phase     string    `json:"phase"`
+	Health    string    `json:"health"`
+	Stuck     bool      `json:"stuck"`
+	Since     time.Time `json:"since"`
+	Message   string    `json:"message"`
+}
+
+// GetFleetSummary summarizes the clusters of a namespace, or of all
+// namespaces if namespace is empty. Clusters that have not been ready for
+// longer than stuckAfter, which defaults to DefaultStuckAfter, are reported
+// as stuck.
+func (c *Client) GetFleetSummary(ctx context.Context, namespace string, stuckAfter time.Duration) (*FleetSummary, error) {
+	statuses, err := c.GetClustersStatus(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return SummarizeFleet(statuses.Items, time.Now(), stuckAfter), nil
+}
+
+// SummarizeFleet aggregates cluster statuses as of now. Clusters needing
+// attention are sorted with stuck clusters first, then by how long they have
+// been in their state.
+func SummarizeFleet(statuses []*ClusterStatus, now time.Time, stuckAfter time.Duration) *FleetSummary {
+	if stuckAfter <= 0 {
+		stuckAfter = DefaultStuckAfter
+	}
+	summary := &FleetSummary{
+		ByProvider:     make(map[Provider]int),
+		ByPhase:        make(map[string]int),
+		ByVersion:      make(map[string]int),
+		ByHealth:       map[string]int{HealthHealthy: 0, HealthDegraded: 0, HealthUnhealthy: 0},
+		NeedsAttention: []FleetIssue{},
+	}
+
+	for _, status := range statuses {
+		summary.TotalClusters++
+		summary.TotalMachines += status.TotalMachines
+		summary.ReadyMachines += status.ReadyMachines
+		summary.ByProvider[status.Provider]++
+		summary.ByPhase[orUnknown(status.Phase)]++
+		summary.ByVersion[orUnknown(status.Version)]++
+
+		health := ClusterHealth(status)
+		summary.ByHealth[health]++
+		if health == HealthHealthy {
+			continue
+		}
+
+		since := status.CreatedAt
+		for _, condition := range status.Conditions {
+			if condition.Type == clusterv1.ReadyCondition {
+				since = condition.LastTransitionTime.Time
+			}
+		}
+		issue := FleetIssue{
+			Namespace: status.Namespace,
+			Name:      status.Name,
+			Phase:     orUnknown(status.Phase),
+			Health:    health,
+			Since:     since,
+			Message:   conditionsMessage(status.Phase, status.Conditions),
+		}
+		if health == HealthDegraded {
+			issue.Message = fmt.Sprintf("%d/%d machines have a node", status.ReadyMachines, status.TotalMachines)
+		}
+		if health == HealthUnhealthy && !since.IsZero() && now.Sub(since) > stuckAfter {
+			issue.Stuck = true
+		}
+		summary.NeedsAttention = append(summary.NeedsAttention, issue)
+	}
+
+	sort.SliceStable(summary.NeedsAttention, func(i, j int) bool {
+		a, b := summary.NeedsAttention[i], summary.NeedsAttention[j]
+		if a.Stuck != b.Stuck {
+			return a.Stuck
+		}
+		return a.Since.Before(b.Since)
+	})
+	return summary
+}
+
+// ClusterHealth classifies a cluster as healthy, degraded or unhealthy
+func ClusterHealth(status *ClusterStatus) string {
+	switch {
+	case !status.Ready:
+		return HealthUnhealthy
+	case status.ReadyMachines < status.TotalMachines:
+		return HealthDegraded
+	default:
+		return HealthHealthy
+	}
+}
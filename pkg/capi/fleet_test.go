@@ -0,0 +1,61 @@
+package capi
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestSummarizeFleet(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	notReadySince := func(d time.Duration) clusterv1.Conditions {
+		return clusterv1.Conditions{{
+			Type:               clusterv1.ReadyCondition,
+			Status:             corev1.ConditionFalse,
+			Reason:             "WaitingForControlPlane",
+			LastTransitionTime: metav1.NewTime(now.Add(-d)),
+		}}
+	}
+
+	statuses := []*ClusterStatus{
+		{Namespace: "org-a", Name: "prod", Phase: "Provisioned", Ready: true, Provider: ProviderAWS, Version: "v1.30.1", TotalMachines: 3, ReadyMachines: 3},
+		{Namespace: "org-a", Name: "staging", Phase: "Provisioned", Ready: true, Provider: ProviderAWS, Version: "v1.30.1", TotalMachines: 3, ReadyMachines: 2},
+		{Namespace: "org-b", Name: "new", Phase: "Provisioning", Provider: ProviderAzure, Conditions: notReadySince(5 * time.Minute)},
+		{Namespace: "org-b", Name: "old", Phase: "Provisioning", Provider: ProviderAzure, Version: "v1.29.4", Conditions: notReadySince(2 * time.Hour)},
+	}
+
+	summary := SummarizeFleet(statuses, now, 0)
+	if summary.TotalClusters != 4 || summary.TotalMachines != 6 || summary.ReadyMachines != 5 {
+		t.Errorf("totals = %d clusters, %d/%d machines", summary.TotalClusters, summary.ReadyMachines, summary.TotalMachines)
+	}
+	if summary.ByProvider[ProviderAWS] != 2 || summary.ByProvider[ProviderAzure] != 2 {
+		t.Errorf("ByProvider = %v", summary.ByProvider)
+	}
+	if summary.ByVersion["v1.30.1"] != 2 || summary.ByVersion["Unknown"] != 1 || summary.ByPhase["Provisioning"] != 2 {
+		t.Errorf("ByVersion = %v, ByPhase = %v", summary.ByVersion, summary.ByPhase)
+	}
+	want := map[string]int{HealthHealthy: 1, HealthDegraded: 1, HealthUnhealthy: 2}
+	for health, count := range want {
+		if summary.ByHealth[health] != count {
+			t.Errorf("ByHealth = %v, want %v", summary.ByHealth, want)
+		}
+	}
+
+	// The stuck cluster comes first, the degraded one has no Ready condition
+	// and sorts by its zero creation time
+	if len(summary.NeedsAttention) != 3 {
+		t.Fatalf("NeedsAttention = %+v, want 3 clusters", summary.NeedsAttention)
+	}
+	first := summary.NeedsAttention[0]
+	if first.Name != "old" || !first.Stuck || first.Message != "phase Provisioning, Ready=False (WaitingForControlPlane)" {
+		t.Errorf("first issue = %+v, want the stuck cluster", first)
+	}
+	for _, issue := range summary.NeedsAttention[1:] {
+		if issue.Stuck {
+			t.Errorf("issue %+v is reported as stuck", issue)
+		}
+	}
+}
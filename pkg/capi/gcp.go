@@ -0,0 +1,169 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// GCPNetwork is the VPC network of a GCP cluster
+type GCPNetwork struct {
+	Name                  string `json:"name,omitempty"`
+	SelfLink              string `json:"selfLink,omitempty"`
+	AutoCreateSubnetworks bool   `json:"autoCreateSubnetworks"`
+	Router                string `json:"router,omitempty"`
+	// APIServerAddress and APIServerForwardingRule expose the API server
+	APIServerAddress        string `json:"apiServerAddress,omitempty"`
+	APIServerForwardingRule string `json:"apiServerForwardingRule,omitempty"`
+}
+
+// GCPSubnet is a subnetwork of a GCP cluster
+type GCPSubnet struct {
+	Name    string `json:"name"`
+	Region  string `json:"region,omitempty"`
+	CIDR    string `json:"cidr,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+}
+
+// GCPFirewallRule is a firewall rule created for a GCP cluster
+type GCPFirewallRule struct {
+	Name     string `json:"name"`
+	SelfLink string `json:"selfLink,omitempty"`
+}
+
+// GCPFailureDomain is a zone machines can be placed in
+type GCPFailureDomain struct {
+	Name         string `json:"name"`
+	ControlPlane bool   `json:"controlPlane"`
+}
+
+// GCPClusterInfo is the GCP infrastructure of a cluster, read from its
+// GCPCluster or, for GKE clusters, its GCPManagedCluster and
+// GCPManagedControlPlane
+type GCPClusterInfo struct {
+	// Kind is GCPCluster or GCPManagedCluster
+	Kind                 string             `json:"kind"`
+	Name                 string             `json:"name"`
+	Project              string             `json:"project,omitempty"`
+	Region               string             `json:"region,omitempty"`
+	Ready                bool               `json:"ready"`
+	ControlPlaneEndpoint string             `json:"controlPlaneEndpoint,omitempty"`
+	Network              GCPNetwork         `json:"network"`
+	Subnets              []GCPSubnet        `json:"subnets"`
+	FirewallRules        []GCPFirewallRule  `json:"firewallRules"`
+	FailureDomains       []GCPFailureDomain `json:"failureDomains"`
+	// ControlPlane names the GCPManagedControlPlane of GKE clusters, whose
+	// location, release channel and version are read from it
+	ControlPlane   string `json:"controlPlane,omitempty"`
+	Location       string `json:"location,omitempty"`
+	ReleaseChannel string `json:"releaseChannel,omitempty"`
+	Version        string `json:"version,omitempty"`
+}
+
+// isGCPCluster reports whether a cluster runs on the GCP provider
+func isGCPCluster(cluster *clusterv1.Cluster) bool {
+	return ClusterProvider(cluster) == ProviderGCP
+}
+
+// GetGCPCluster reads the GCP infrastructure of a cluster. The provider
+// objects are read as unstructured, so the GCP provider types are not needed.
+func (c *Client) GetGCPCluster(ctx context.Context, namespace, name string) (*GCPClusterInfo, error) {
+	cluster, err := c.GetCluster(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if !isGCPCluster(cluster) {
+		return nil, errorf(ErrInvalidArgument, "cluster %s/%s is not a GCP cluster", namespace, name)
+	}
+
+	ref := cluster.Spec.InfrastructureRef
+	infra, err := c.getReferenced(ctx, namespace, ref.APIVersion, ref.Kind, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+	info := &GCPClusterInfo{Kind: ref.Kind, Name: ref.Name}
+	readGCPInfrastructure(info, infra.Object)
+
+	if cpRef := cluster.Spec.ControlPlaneRef; cpRef != nil && cpRef.Kind == "GCPManagedControlPlane" {
+		controlPlane, err := c.getReferenced(ctx, namespace, cpRef.APIVersion, cpRef.Kind, cpRef.Name)
+		if err != nil {
+			return nil, err
+		}
+		info.ControlPlane = cpRef.Name
+		info.Location, _, _ = unstructured.NestedString(controlPlane.Object, "spec", "location")
+		info.ReleaseChannel, _, _ = unstructured.NestedString(controlPlane.Object, "spec", "releaseChannel")
+		info.Version, _, _ = unstructured.NestedString(controlPlane.Object, "status", "currentVersion")
+		if info.Version == "" {
+			info.Version, _, _ = unstructured.NestedString(controlPlane.Object, "spec", "controlPlaneVersion")
+		}
+		if info.ControlPlaneEndpoint == "" {
+			if host, _, _ := unstructured.NestedString(controlPlane.Object, "spec", "endpoint", "host"); host != "" {
+				port, _, _ := unstructured.NestedInt64(controlPlane.Object, "spec", "endpoint", "port")
+				info.ControlPlaneEndpoint = fmt.Sprintf("%s:%d", host, port)
+			}
+		}
+	}
+	return info, nil
+}
+
+// readGCPInfrastructure fills info from a GCPCluster or GCPManagedCluster
+func readGCPInfrastructure(info *GCPClusterInfo, obj map[string]any) {
+	info.Project, _, _ = unstructured.NestedString(obj, "spec", "project")
+	info.Region, _, _ = unstructured.NestedString(obj, "spec", "region")
+	info.Ready, _, _ = unstructured.NestedBool(obj, "status", "ready")
+	if host, _, _ := unstructured.NestedString(obj, "spec", "controlPlaneEndpoint", "host"); host != "" {
+		port, _, _ := unstructured.NestedInt64(obj, "spec", "controlPlaneEndpoint", "port")
+		info.ControlPlaneEndpoint = fmt.Sprintf("%s:%d", host, port)
+	}
+
+	info.Network.Name, _, _ = unstructured.NestedString(obj, "spec", "network", "name")
+	if info.Network.Name == "" {
+		// The provider uses the default network unless one is named
+		info.Network.Name = "default"
+	}
+	info.Network.AutoCreateSubnetworks, _, _ = unstructured.NestedBool(obj, "spec", "network", "autoCreateSubnetworks")
+	info.Network.SelfLink, _, _ = unstructured.NestedString(obj, "status", "network", "selfLink")
+	info.Network.Router, _, _ = unstructured.NestedString(obj, "status", "network", "router")
+	info.Network.APIServerAddress, _, _ = unstructured.NestedString(obj, "status", "network", "apiServerIpAddress")
+	info.Network.APIServerForwardingRule, _, _ = unstructured.NestedString(obj, "status", "network", "apiServerForwardingRule")
+
+	info.Subnets = []GCPSubnet{}
+	subnets, _, _ := unstructured.NestedSlice(obj, "spec", "network", "subnets")
+	for _, item := range subnets {
+		subnet, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(subnet, "name")
+		region, _, _ := unstructured.NestedString(subnet, "region")
+		cidr, _, _ := unstructured.NestedString(subnet, "cidrBlock")
+		purpose, _, _ := unstructured.NestedString(subnet, "purpose")
+		info.Subnets = append(info.Subnets, GCPSubnet{Name: name, Region: region, CIDR: cidr, Purpose: purpose})
+	}
+
+	info.FirewallRules = []GCPFirewallRule{}
+	rules, _, _ := unstructured.NestedStringMap(obj, "status", "network", "firewallRules")
+	for name, selfLink := range rules {
+		info.FirewallRules = append(info.FirewallRules, GCPFirewallRule{Name: name, SelfLink: selfLink})
+	}
+	sort.Slice(info.FirewallRules, func(i, j int) bool { return info.FirewallRules[i].Name < info.FirewallRules[j].Name })
+
+	// The status lists the zones the provider found, the spec those requested
+	info.FailureDomains = []GCPFailureDomain{}
+	if domains, found, _ := unstructured.NestedMap(obj, "status", "failureDomains"); found {
+		for name, item := range domains {
+			domain, _ := item.(map[string]any)
+			controlPlane, _, _ := unstructured.NestedBool(domain, "controlPlane")
+			info.FailureDomains = append(info.FailureDomains, GCPFailureDomain{Name: name, ControlPlane: controlPlane})
+		}
+	} else {
+		zones, _, _ := unstructured.NestedStringSlice(obj, "spec", "failureDomains")
+		for _, zone := range zones {
+			info.FailureDomains = append(info.FailureDomains, GCPFailureDomain{Name: zone, ControlPlane: true})
+		}
+	}
+	sort.Slice(info.FailureDomains, func(i, j int) bool { return info.FailureDomains[i].Name < info.FailureDomains[j].Name })
+}
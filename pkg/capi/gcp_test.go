@@ -0,0 +1,122 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetGCPCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	newCluster := func(name, infraKind, cpKind string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: name},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: infraKind, Name: name},
+				ControlPlaneRef:   &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: cpKind, Name: name + "-cp"},
+			},
+		}
+	}
+
+	gcpCluster := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"project":              "acme-prod",
+			"region":               "europe-west3",
+			"controlPlaneEndpoint": map[string]any{"host": "34.1.2.3", "port": int64(443)},
+			"network": map[string]any{
+				"name": "prod-net",
+				"subnets": []any{
+					map[string]any{"name": "prod-nodes", "region": "europe-west3", "cidrBlock": "10.0.0.0/20", "purpose": "PRIVATE"},
+				},
+			},
+		},
+		"status": map[string]any{
+			"ready": true,
+			"network": map[string]any{
+				"selfLink":           "https://www.googleapis.com/compute/v1/projects/acme-prod/global/networks/prod-net",
+				"router":             "prod-router",
+				"apiServerIpAddress": "34.1.2.3",
+				"firewallRules": map[string]any{
+					"allow-prod-intra-cluster": "https://www.googleapis.com/compute/v1/projects/acme-prod/global/firewalls/allow-prod-intra-cluster",
+					"allow-prod-healthchecks":  "https://www.googleapis.com/compute/v1/projects/acme-prod/global/firewalls/allow-prod-healthchecks",
+				},
+			},
+			"failureDomains": map[string]any{"europe-west3-b": map[string]any{"controlPlane": true}, "europe-west3-a": map[string]any{"controlPlane": true}},
+		},
+	}}
+	gcpCluster.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta1")
+	gcpCluster.SetKind("GCPCluster")
+	gcpCluster.SetNamespace("org-a")
+	gcpCluster.SetName("prod")
+
+	managedCluster := &unstructured.Unstructured{Object: map[string]any{
+		"spec":   map[string]any{"project": "acme-gke", "region": "us-central1"},
+		"status": map[string]any{"ready": true},
+	}}
+	managedCluster.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta1")
+	managedCluster.SetKind("GCPManagedCluster")
+	managedCluster.SetNamespace("org-a")
+	managedCluster.SetName("gke")
+
+	managedControlPlane := &unstructured.Unstructured{Object: map[string]any{
+		"spec":   map[string]any{"location": "us-central1", "releaseChannel": "regular", "endpoint": map[string]any{"host": "35.1.2.3", "port": int64(443)}},
+		"status": map[string]any{"currentVersion": "1.30.5-gke.1014001"},
+	}}
+	managedControlPlane.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta1")
+	managedControlPlane.SetKind("GCPManagedControlPlane")
+	managedControlPlane.SetNamespace("org-a")
+	managedControlPlane.SetName("gke-cp")
+
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newCluster("prod", "GCPCluster", "KubeadmControlPlane"), gcpCluster,
+		newCluster("gke", "GCPManagedCluster", "GCPManagedControlPlane"), managedCluster, managedControlPlane,
+		newCluster("aws", "AWSCluster", "KubeadmControlPlane"),
+	).Build()}
+	ctx := context.Background()
+
+	info, err := c.GetGCPCluster(ctx, "org-a", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Project != "acme-prod" || info.Region != "europe-west3" || !info.Ready || info.ControlPlaneEndpoint != "34.1.2.3:443" {
+		t.Errorf("info = %+v", info)
+	}
+	if info.Network.Name != "prod-net" || info.Network.Router != "prod-router" || info.Network.APIServerAddress != "34.1.2.3" {
+		t.Errorf("network = %+v", info.Network)
+	}
+	if len(info.Subnets) != 1 || info.Subnets[0].CIDR != "10.0.0.0/20" || info.Subnets[0].Purpose != "PRIVATE" {
+		t.Errorf("subnets = %+v", info.Subnets)
+	}
+	if len(info.FirewallRules) != 2 || info.FirewallRules[0].Name != "allow-prod-healthchecks" {
+		t.Errorf("firewall rules = %+v", info.FirewallRules)
+	}
+	if len(info.FailureDomains) != 2 || info.FailureDomains[0].Name != "europe-west3-a" || !info.FailureDomains[0].ControlPlane {
+		t.Errorf("failure domains = %+v", info.FailureDomains)
+	}
+
+	gke, err := c.GetGCPCluster(ctx, "org-a", "gke")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gke.Kind != "GCPManagedCluster" || gke.ControlPlane != "gke-cp" || gke.ReleaseChannel != "regular" || gke.Version != "1.30.5-gke.1014001" {
+		t.Errorf("gke info = %+v", gke)
+	}
+	if gke.Network.Name != "default" || gke.ControlPlaneEndpoint != "35.1.2.3:443" {
+		t.Errorf("gke network and endpoint = %+v, %s", gke.Network, gke.ControlPlaneEndpoint)
+	}
+
+	if _, err := c.GetGCPCluster(ctx, "org-a", "aws"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GetGCPCluster() of an AWS cluster error = %v, want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,306 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// TemplateRepository serves the cluster templates of provider releases
+type TemplateRepository interface {
+	// LatestVersion returns the newest release of a provider
+	LatestVersion(ctx context.Context, provider string) (string, error)
+	// ClusterTemplate returns the cluster template of a provider release,
+	// cluster-template.yaml or cluster-template-<flavor>.yaml
+	ClusterTemplate(ctx context.Context, provider, version, flavor string) ([]byte, error)
+}
+
+// ClusterTemplate downloads a cluster template from the release assets of a
+// provider, like clusterctl generate cluster
+func (r *GitHubRepository) ClusterTemplate(ctx context.Context, provider, version, flavor string) ([]byte, error) {
+	release, err := r.release(provider)
+	if err != nil {
+		return nil, err
+	}
+	baseURL := r.BaseURL
+	if baseURL == "" {
+		baseURL = "https://github.com"
+	}
+	return r.get(ctx, fmt.Sprintf("%s/%s/releases/download/%s/%s", strings.TrimSuffix(baseURL, "/"), release.repository, version, templateFile(flavor)))
+}
+
+// templateFile is the name of the cluster template asset of a flavor
+func templateFile(flavor string) string {
+	if flavor == "" {
+		return "cluster-template.yaml"
+	}
+	return "cluster-template-" + flavor + ".yaml"
+}
+
+// GenerateClusterOptions contains options for rendering the manifests of a
+// new cluster
+type GenerateClusterOptions struct {
+	Name              string
+	Namespace         string
+	KubernetesVersion string
+	ControlPlaneCount int32
+	WorkerCount       int32
+
+	// Provider is the infrastructure provider whose cluster template is
+	// rendered, e.g. aws or infrastructure-aws. Version selects its release,
+	// the latest one when empty, and Flavor its template.
+	Provider string
+	Version  string
+	Flavor   string
+
+	// ClusterClass renders a Cluster with a topology of this class instead
+	// of a provider template. ClassNamespace defaults to Namespace.
+	ClusterClass   string
+	ClassNamespace string
+
+	// Variables fill the ${VAR} placeholders of the template in addition to
+	// CLUSTER_NAME, NAMESPACE, KUBERNETES_VERSION,
+	// CONTROL_PLANE_MACHINE_COUNT and WORKER_MACHINE_COUNT. With a
+	// ClusterClass they are the topology variables, JSON values or strings.
+	Variables map[string]string
+
+	// Apply creates the rendered objects
+	Apply bool
+}
+
+// GeneratedObject is an object of a rendered cluster
+type GeneratedObject struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// GeneratedCluster holds the manifests of a rendered cluster
+type GeneratedCluster struct {
+	Namespace    string            `json:"namespace"`
+	Name         string            `json:"name"`
+	Provider     string            `json:"provider,omitempty"`
+	Version      string            `json:"version,omitempty"`
+	Flavor       string            `json:"flavor,omitempty"`
+	ClusterClass string            `json:"clusterClass,omitempty"`
+	Objects      []GeneratedObject `json:"objects"`
+	Manifests    string            `json:"manifests"`
+	Applied      bool              `json:"applied"`
+}
+
+// GenerateCluster renders the manifests of a new cluster like clusterctl
+// generate cluster, from the cluster template of a provider release or as a
+// Cluster with a topology of a ClusterClass, and creates them with
+// opts.Apply. Variables without a value or default are reported as
+// ErrInvalidArgument, as are missing required ClusterClass variables.
+// Objects created before a failure are deleted.
+func (c *Client) GenerateCluster(ctx context.Context, repo TemplateRepository, opts GenerateClusterOptions) (*GeneratedCluster, error) {
+	if opts.Name == "" || opts.Namespace == "" {
+		return nil, errorf(ErrInvalidArgument, "name and namespace of the cluster are required")
+	}
+	if (opts.Provider == "") == (opts.ClusterClass == "") {
+		return nil, errorf(ErrInvalidArgument, "either a provider template or a ClusterClass is required")
+	}
+
+	generated := &GeneratedCluster{Namespace: opts.Namespace, Name: opts.Name, Flavor: opts.Flavor, ClusterClass: opts.ClusterClass}
+	var objects []*unstructured.Unstructured
+	var err error
+	if opts.ClusterClass != "" {
+		objects, err = c.topologyCluster(ctx, opts)
+	} else {
+		objects, err = c.templateCluster(ctx, repo, opts, generated)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make([]string, 0, len(objects))
+	for _, obj := range objects {
+		generated.Objects = append(generated.Objects, GeneratedObject{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()})
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		manifests = append(manifests, string(data))
+	}
+	generated.Manifests = strings.Join(manifests, "---\n")
+	if !opts.Apply {
+		return generated, nil
+	}
+
+	if err := c.checkComponentKinds(objects); err != nil {
+		return nil, fmt.Errorf("cannot create the cluster: %w", err)
+	}
+	if err := c.createAll(ctx, objects); err != nil {
+		return nil, err
+	}
+	generated.Applied = true
+	return generated, nil
+}
+
+// templateCluster fetches and renders the cluster template of a provider
+// release, placing namespaced objects without a namespace in opts.Namespace
+func (c *Client) templateCluster(ctx context.Context, repo TemplateRepository, opts GenerateClusterOptions, generated *GeneratedCluster) ([]*unstructured.Unstructured, error) {
+	var data []byte
+	var err error
+	generated.Provider, generated.Version, data, err = fetchTemplate(ctx, repo, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	variables := builtinVariables(opts)
+	for name, value := range opts.Variables {
+		variables[name] = value
+	}
+	data, missing := substituteVariables(data, func(name string) (string, bool) {
+		value, ok := variables[name]
+		return value, ok
+	})
+	if len(missing) > 0 {
+		return nil, errorf(ErrInvalidArgument, "the %s template of %s %s needs the variables %s", templateFile(opts.Flavor), generated.Provider, generated.Version, strings.Join(missing, ", "))
+	}
+
+	objects, err := decodeManifest(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the cluster template: %w", err)
+	}
+	c.setDefaultNamespace(objects, opts.Namespace)
+	return objects, nil
+}
+
+// setDefaultNamespace places the objects without a namespace in namespace,
+// unless they are known to be cluster-scoped
+func (c *Client) setDefaultNamespace(objects []*unstructured.Unstructured, namespace string) {
+	for _, obj := range objects {
+		if obj.GetNamespace() != "" {
+			continue
+		}
+		gvk := obj.GroupVersionKind()
+		mapping, err := c.ctrlClient.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err == nil && mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			continue
+		}
+		obj.SetNamespace(namespace)
+	}
+}
+
+// fetchTemplate fetches the cluster template of the provider release of
+// opts, the latest release when it has no version. It returns the clusterctl
+// name of the provider and the version.
+func fetchTemplate(ctx context.Context, repo TemplateRepository, opts GenerateClusterOptions) (string, string, []byte, error) {
+	provider := opts.Provider
+	if _, ok := knownProviders[provider]; !ok && !strings.HasPrefix(provider, "infrastructure-") {
+		provider = "infrastructure-" + provider
+	}
+	version := opts.Version
+	if version == "" {
+		latest, err := repo.LatestVersion(ctx, provider)
+		if err != nil {
+			return "", "", nil, err
+		}
+		version = latest
+	}
+	data, err := repo.ClusterTemplate(ctx, provider, version, opts.Flavor)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to fetch %s of %s %s: %w", templateFile(opts.Flavor), provider, version, err)
+	}
+	return provider, version, data, nil
+}
+
+// builtinVariables are the template variables clusterctl sets from its flags
+func builtinVariables(opts GenerateClusterOptions) map[string]string {
+	variables := map[string]string{
+		"CLUSTER_NAME":                opts.Name,
+		"NAMESPACE":                   opts.Namespace,
+		"CONTROL_PLANE_MACHINE_COUNT": strconv.Itoa(int(opts.ControlPlaneCount)),
+		"WORKER_MACHINE_COUNT":        strconv.Itoa(int(opts.WorkerCount)),
+	}
+	if opts.KubernetesVersion != "" {
+		variables["KUBERNETES_VERSION"] = opts.KubernetesVersion
+	}
+	return variables
+}
+
+// getClusterClass returns the ClusterClass of opts
+func (c *Client) getClusterClass(ctx context.Context, opts GenerateClusterOptions) (*clusterv1.ClusterClass, error) {
+	classNamespace := opts.ClassNamespace
+	if classNamespace == "" {
+		classNamespace = opts.Namespace
+	}
+	class := &clusterv1.ClusterClass{}
+	key := client.ObjectKey{Namespace: classNamespace, Name: opts.ClusterClass}
+	if err := c.ctrlClient.Get(ctx, key, class); err != nil {
+		return nil, fmt.Errorf("failed to get ClusterClass: %w", resourceError("ClusterClass", key, err))
+	}
+	return class, nil
+}
+
+// topologyCluster renders a Cluster with a topology of a ClusterClass, one
+// machine deployment of its first worker class and the given variables
+func (c *Client) topologyCluster(ctx context.Context, opts GenerateClusterOptions) ([]*unstructured.Unstructured, error) {
+	if opts.KubernetesVersion == "" {
+		return nil, errorf(ErrInvalidArgument, "a Kubernetes version is required for a cluster of a ClusterClass")
+	}
+	class, err := c.getClusterClass(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	if problems := validateClassVariables(classVariables(class), opts.Variables); len(problems) > 0 {
+		return nil, errorf(ErrInvalidArgument, "invalid variables for ClusterClass %s: %s", opts.ClusterClass, strings.Join(problems, "; "))
+	}
+	variables := make([]clusterv1.ClusterVariable, 0, len(opts.Variables))
+	for name, value := range opts.Variables {
+		_, raw := parseVariableValue(value)
+		variables = append(variables, clusterv1.ClusterVariable{Name: name, Value: apiextensionsv1.JSON{Raw: raw}})
+	}
+	sort.Slice(variables, func(i, j int) bool { return variables[i].Name < variables[j].Name })
+
+	cluster := &clusterv1.Cluster{
+		TypeMeta: metav1.TypeMeta{APIVersion: clusterv1.GroupVersion.String(), Kind: "Cluster"},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: opts.Namespace,
+			Name:      opts.Name,
+		},
+		Spec: clusterv1.ClusterSpec{
+			Topology: &clusterv1.Topology{
+				Class:        opts.ClusterClass,
+				Version:      opts.KubernetesVersion,
+				ControlPlane: clusterv1.ControlPlaneTopology{Replicas: &opts.ControlPlaneCount},
+				Variables:    variables,
+			},
+		},
+	}
+	if class.Namespace != opts.Namespace {
+		cluster.Spec.Topology.ClassNamespace = class.Namespace
+	}
+	if len(class.Spec.Workers.MachineDeployments) > 0 {
+		cluster.Spec.Topology.Workers = &clusterv1.WorkersTopology{
+			MachineDeployments: []clusterv1.MachineDeploymentTopology{{
+				Class:    class.Spec.Workers.MachineDeployments[0].Class,
+				Name:     "md-0",
+				Replicas: &opts.WorkerCount,
+			}},
+		}
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cluster)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the cluster: %w", err)
+	}
+	obj := &unstructured.Unstructured{Object: content}
+	unstructured.RemoveNestedField(obj.Object, "status")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(obj.Object, "spec", "controlPlaneEndpoint")
+	return []*unstructured.Unstructured{obj}, nil
+}
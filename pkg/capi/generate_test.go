@@ -0,0 +1,212 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// fakeTemplateRepository serves cluster templates from memory
+type fakeTemplateRepository struct {
+	fakeProviderRepository
+	templates map[string]string
+}
+
+func (r *fakeTemplateRepository) ClusterTemplate(_ context.Context, provider, version, flavor string) ([]byte, error) {
+	if template, ok := r.templates[provider+"@"+version+"/"+templateFile(flavor)]; ok {
+		return []byte(template), nil
+	}
+	return nil, errorf(ErrNotFound, "%s of %s %s not found", templateFile(flavor), provider, version)
+}
+
+const dockerTemplate = `apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: ${CLUSTER_NAME}
+spec:
+  clusterNetwork:
+    pods:
+      cidrBlocks: ["${POD_CIDR:=192.168.0.0/16}"]
+  controlPlaneRef:
+    apiVersion: controlplane.cluster.x-k8s.io/v1beta1
+    kind: KubeadmControlPlane
+    name: ${CLUSTER_NAME}-control-plane
+  infrastructureRef:
+    apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+    kind: DockerCluster
+    name: ${CLUSTER_NAME}
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+kind: DockerCluster
+metadata:
+  name: ${CLUSTER_NAME}
+  namespace: ${NAMESPACE}
+---
+apiVersion: controlplane.cluster.x-k8s.io/v1beta1
+kind: KubeadmControlPlane
+metadata:
+  name: ${CLUSTER_NAME}-control-plane
+spec:
+  replicas: ${CONTROL_PLANE_MACHINE_COUNT}
+  version: ${KUBERNETES_VERSION}
+  machineTemplate:
+    infrastructureRef:
+      apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+      kind: DockerMachineTemplate
+      name: ${CLUSTER_NAME}-control-plane
+  kubeadmConfigSpec:
+    files:
+    - path: /etc/motd
+      content: ${MOTD}
+`
+
+func TestGenerateClusterFromTemplate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, gvk := range []schema.GroupVersionKind{
+		clusterv1.GroupVersion.WithKind("Cluster"),
+		controlplanev1.GroupVersion.WithKind("KubeadmControlPlane"),
+		dockerClusterGVK,
+	} {
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+	}
+	ctrlClient := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).Build()
+	c := &Client{ctrlClient: ctrlClient}
+	repo := &fakeTemplateRepository{
+		fakeProviderRepository: fakeProviderRepository{latest: map[string]string{"infrastructure-docker": "v1.10.2"}},
+		templates:              map[string]string{"infrastructure-docker@v1.10.2/cluster-template-development.yaml": dockerTemplate},
+	}
+	ctx := context.Background()
+	opts := GenerateClusterOptions{
+		Name: "dev", Namespace: "org-acme", KubernetesVersion: "v1.31.2", ControlPlaneCount: 1,
+		Provider: "docker", Flavor: "development",
+	}
+
+	if _, err := c.GenerateCluster(ctx, repo, opts); !errors.Is(err, ErrInvalidArgument) || !strings.Contains(err.Error(), "MOTD") {
+		t.Fatalf("GenerateCluster() without MOTD error = %v, want the missing variable", err)
+	}
+	opts.Flavor = "missing"
+	if _, err := c.GenerateCluster(ctx, repo, opts); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GenerateCluster() of an unknown flavor error = %v, want ErrNotFound", err)
+	}
+
+	opts.Flavor = "development"
+	opts.Variables = map[string]string{"MOTD": "hello"}
+	generated, err := c.GenerateCluster(ctx, repo, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if generated.Provider != "infrastructure-docker" || generated.Version != "v1.10.2" || len(generated.Objects) != 3 || generated.Applied {
+		t.Errorf("generated = %+v", generated)
+	}
+	for _, obj := range generated.Objects {
+		if obj.Namespace != "org-acme" {
+			t.Errorf("%s %s rendered in namespace %q, want org-acme", obj.Kind, obj.Name, obj.Namespace)
+		}
+	}
+	if !strings.Contains(generated.Manifests, "192.168.0.0/16") || !strings.Contains(generated.Manifests, "replicas: 1") {
+		t.Errorf("manifests = %s", generated.Manifests)
+	}
+	if err := ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "dev"}, &clusterv1.Cluster{}); err == nil {
+		t.Error("rendering created the cluster")
+	}
+
+	opts.Apply = true
+	if generated, err = c.GenerateCluster(ctx, repo, opts); err != nil || !generated.Applied {
+		t.Fatalf("GenerateCluster() with apply = %+v, %v", generated, err)
+	}
+	kcp, err := c.GetKubeadmControlPlane(ctx, "org-acme", "dev-control-plane")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kcp.Spec.Version != "v1.31.2" || *kcp.Spec.Replicas != 1 {
+		t.Errorf("control plane = %+v", kcp.Spec)
+	}
+	dockerCluster := &unstructured.Unstructured{}
+	dockerCluster.SetGroupVersionKind(dockerClusterGVK)
+	if err := ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "dev"}, dockerCluster); err != nil {
+		t.Errorf("DockerCluster was not created: %v", err)
+	}
+
+	// Nothing is left behind when an object already exists
+	opts.Name = "dev2"
+	if err := ctrlClient.Create(ctx, &controlplanev1.KubeadmControlPlane{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "dev2-control-plane"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GenerateCluster(ctx, repo, opts); !errors.Is(err, ErrAlreadyExists) {
+		t.Fatalf("GenerateCluster() over an existing object error = %v, want ErrAlreadyExists", err)
+	}
+	if err := ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "dev2"}, &clusterv1.Cluster{}); err == nil {
+		t.Error("the cluster of a failed generation was left behind")
+	}
+}
+
+func TestGenerateClusterFromClusterClass(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	class := &clusterv1.ClusterClass{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "capi-classes", Name: "quick-start"},
+		Spec: clusterv1.ClusterClassSpec{
+			Workers: clusterv1.WorkersClass{MachineDeployments: []clusterv1.MachineDeploymentClass{{Class: "default-worker"}}},
+			Variables: []clusterv1.ClusterClassVariable{
+				{Name: "region", Required: true},
+				{Name: "sshKeyName"},
+			},
+		},
+	}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(clusterv1.GroupVersion.WithKind("Cluster"), meta.RESTScopeNamespace)
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(class).Build()}
+	ctx := context.Background()
+	opts := GenerateClusterOptions{
+		Name: "dev", Namespace: "org-acme", KubernetesVersion: "v1.31.2", ControlPlaneCount: 3, WorkerCount: 2,
+		ClusterClass: "quick-start", ClassNamespace: "capi-classes",
+		Variables: map[string]string{"sshKeyName": "ops"},
+	}
+
+	if _, err := c.GenerateCluster(ctx, nil, opts); !errors.Is(err, ErrInvalidArgument) || !strings.Contains(err.Error(), "region") {
+		t.Fatalf("GenerateCluster() without region error = %v, want the missing variable", err)
+	}
+	opts.Variables["zone"] = "a"
+	if _, err := c.GenerateCluster(ctx, nil, opts); !errors.Is(err, ErrInvalidArgument) || !strings.Contains(err.Error(), "zone") {
+		t.Fatalf("GenerateCluster() with an unknown variable error = %v", err)
+	}
+
+	delete(opts.Variables, "zone")
+	opts.Variables["region"] = `"eu-west-1"`
+	opts.Apply = true
+	if _, err := c.GenerateCluster(ctx, nil, opts); err != nil {
+		t.Fatal(err)
+	}
+	cluster, err := c.GetCluster(ctx, "org-acme", "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	topology := cluster.Spec.Topology
+	if topology.Class != "quick-start" || topology.ClassNamespace != "capi-classes" || *topology.ControlPlane.Replicas != 3 ||
+		topology.Workers.MachineDeployments[0].Class != "default-worker" || *topology.Workers.MachineDeployments[0].Replicas != 2 {
+		t.Errorf("topology = %+v", topology)
+	}
+	if len(topology.Variables) != 2 || string(topology.Variables[0].Value.Raw) != `"eu-west-1"` || string(topology.Variables[1].Value.Raw) != `"ops"` {
+		t.Errorf("variables = %+v", topology.Variables)
+	}
+}
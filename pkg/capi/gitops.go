@@ -0,0 +1,298 @@
+package capi
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// GitOps tools the sync objects of an export are generated for
+const (
+	GitOpsKustomize = "kustomize"
+	GitOpsFlux      = "flux"
+	GitOpsArgoCD    = "argocd"
+)
+
+// GitOpsTools lists the supported GitOps tools
+var GitOpsTools = []string{GitOpsKustomize, GitOpsFlux, GitOpsArgoCD}
+
+// ExportGitOpsOptions contains options for exporting a cluster to Git
+type ExportGitOpsOptions struct {
+	Namespace string
+	Name      string
+	// Path is the directory of the cluster in the repository, by default
+	// clusters/<namespace>/<name>
+	Path string
+	// Tool adds a Flux Kustomization or an Argo CD Application syncing the
+	// directory; plain kustomize when empty
+	Tool string
+	// Source is the Flux GitRepository (default flux-system) or the Argo CD
+	// repository URL (required) of the repository
+	Source string
+}
+
+// ExportedFile is a file of a GitOps export
+type ExportedFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// SecretReference is a Secret the exported objects reference, which has to
+// be provided outside the repository
+type SecretReference struct {
+	Name string `json:"name"`
+	// ReferencedBy are the objects referencing the Secret as Kind/name
+	ReferencedBy []string `json:"referencedBy"`
+}
+
+// GitOpsExport is the configuration of a cluster as a directory to commit to
+// a GitOps repository
+type GitOpsExport struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Tool      string `json:"tool"`
+	// Topology is set when the cluster is managed by a ClusterClass, in which
+	// case only the Cluster is exported
+	Topology bool           `json:"topology"`
+	Files    []ExportedFile `json:"files"`
+	// Secrets are referenced by name only, never written to the files
+	Secrets []SecretReference `json:"secrets"`
+}
+
+// ExportGitOps writes the configuration of a cluster as a kustomize
+// directory for Flux or Argo CD: one file per object with the objects of
+// CloneCluster under their own names, a kustomization.yaml listing them and
+// a README.md naming the Secrets to provide, plus the sync object of the
+// GitOps tool. Status and server-set metadata are left out, Secrets are only
+// referenced, so the manifests can be committed and adopt the running
+// cluster.
+func (c *Client) ExportGitOps(ctx context.Context, opts ExportGitOpsOptions) (*GitOpsExport, error) {
+	if opts.Tool == "" {
+		opts.Tool = GitOpsKustomize
+	}
+	switch opts.Tool {
+	case GitOpsKustomize:
+	case GitOpsFlux:
+		if opts.Source == "" {
+			opts.Source = "flux-system"
+		}
+	case GitOpsArgoCD:
+		if opts.Source == "" {
+			return nil, errorf(ErrInvalidArgument, "the repository URL is required for Argo CD")
+		}
+	default:
+		return nil, errorf(ErrInvalidArgument, "unknown GitOps tool %q, use one of %s", opts.Tool, strings.Join(GitOpsTools, ", "))
+	}
+	if opts.Path == "" {
+		opts.Path = path.Join("clusters", opts.Namespace, opts.Name)
+	}
+	opts.Path = path.Clean(strings.Trim(opts.Path, "/"))
+	if opts.Path == "." || opts.Path == ".." || strings.HasPrefix(opts.Path, "../") {
+		return nil, errorf(ErrInvalidArgument, "invalid path %q, use a directory inside the repository", opts.Path)
+	}
+
+	sourceKey := client.ObjectKey{Namespace: opts.Namespace, Name: opts.Name}
+	source := &unstructured.Unstructured{}
+	source.SetGroupVersionKind(clusterv1.GroupVersion.WithKind("Cluster"))
+	if err := c.ctrlClient.Get(ctx, sourceKey, source); err != nil {
+		return nil, fmt.Errorf("failed to get cluster: %w", resourceError("Cluster", sourceKey, err))
+	}
+	// A copy in place keeps the names of the objects
+	cloner := &clusterCloner{
+		client:  c,
+		opts:    CloneClusterOptions{SourceNamespace: opts.Namespace, SourceName: opts.Name, Namespace: opts.Namespace, Name: opts.Name},
+		sources: map[*unstructured.Unstructured]string{},
+		names:   map[string]string{},
+	}
+	clone := &ClusterClone{}
+	if err := cloner.cloneCluster(ctx, source, clone); err != nil {
+		return nil, err
+	}
+
+	export := &GitOpsExport{Namespace: opts.Namespace, Name: opts.Name, Path: opts.Path, Tool: opts.Tool, Topology: clone.Topology}
+	resources := make([]string, 0, len(cloner.objects))
+	for _, obj := range cloner.objects {
+		// The namespace comes from the kustomization
+		obj.SetNamespace("")
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		file := strings.ToLower(obj.GetKind()) + "-" + obj.GetName() + ".yaml"
+		resources = append(resources, file)
+		export.add(file, data)
+	}
+	export.Secrets = secretReferences(cloner.objects)
+
+	kustomization, err := yaml.Marshal(map[string]any{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"namespace":  opts.Namespace,
+		"resources":  resources,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render the kustomization: %w", err)
+	}
+	export.add("kustomization.yaml", kustomization)
+
+	if sync := gitOpsSyncObject(opts); sync != nil {
+		data, err := yaml.Marshal(sync)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render the %s sync object: %w", opts.Tool, err)
+		}
+		export.add(opts.Tool+"-sync.yaml", data)
+	}
+	export.add("README.md", []byte(export.readme()))
+	return export, nil
+}
+
+// add adds a file to the directory of the export
+func (e *GitOpsExport) add(name string, data []byte) {
+	e.Files = append(e.Files, ExportedFile{Path: path.Join(e.Path, name), Content: string(data)})
+}
+
+// gitOpsSyncObject returns the Flux Kustomization or Argo CD Application
+// syncing the directory of an export. It is not part of the kustomization,
+// since it is applied to the GitOps tool. Neither prunes, so removing the
+// directory from Git never deletes the cluster.
+func gitOpsSyncObject(opts ExportGitOpsOptions) map[string]any {
+	name := opts.Namespace + "-" + opts.Name
+	switch opts.Tool {
+	case GitOpsFlux:
+		return map[string]any{
+			"apiVersion": "kustomize.toolkit.fluxcd.io/v1",
+			"kind":       "Kustomization",
+			"metadata":   map[string]any{"name": name, "namespace": "flux-system"},
+			"spec": map[string]any{
+				"interval":  "10m",
+				"path":      "./" + opts.Path,
+				"prune":     false,
+				"sourceRef": map[string]any{"kind": "GitRepository", "name": opts.Source},
+			},
+		}
+	case GitOpsArgoCD:
+		return map[string]any{
+			"apiVersion": "argoproj.io/v1alpha1",
+			"kind":       "Application",
+			"metadata":   map[string]any{"name": name, "namespace": "argocd"},
+			"spec": map[string]any{
+				"project":     "default",
+				"source":      map[string]any{"repoURL": opts.Source, "path": opts.Path, "targetRevision": "HEAD"},
+				"destination": map[string]any{"server": "https://kubernetes.default.svc", "namespace": opts.Namespace},
+				"syncPolicy":  map[string]any{"syncOptions": []any{"ServerSideApply=true"}},
+			},
+		}
+	}
+	return nil
+}
+
+// secretReferences finds the Secrets objects reference by name: the fields
+// secretName and the name of secret and secretRef fields, such as the files
+// of kubeadm configs taken from Secrets
+func secretReferences(objects []*unstructured.Unstructured) []SecretReference {
+	referencedBy := map[string][]string{}
+	var walk func(owner string, value any)
+	walk = func(owner string, value any) {
+		switch value := value.(type) {
+		case map[string]any:
+			for key, field := range value {
+				switch key {
+				case "secretName":
+					if name, ok := field.(string); ok && name != "" {
+						referencedBy[name] = append(referencedBy[name], owner)
+					}
+				case "secret", "secretRef":
+					if ref, ok := field.(map[string]any); ok {
+						if name, ok := ref["name"].(string); ok && name != "" {
+							referencedBy[name] = append(referencedBy[name], owner)
+						}
+					}
+				}
+				walk(owner, field)
+			}
+		case []any:
+			for _, item := range value {
+				walk(owner, item)
+			}
+		}
+	}
+	for _, obj := range objects {
+		walk(obj.GetKind()+"/"+obj.GetName(), obj.Object["spec"])
+	}
+
+	secrets := make([]SecretReference, 0, len(referencedBy))
+	for _, name := range sortedKeys(referencedBy) {
+		owners := []string{}
+		for _, owner := range referencedBy[name] {
+			if len(owners) == 0 || owners[len(owners)-1] != owner {
+				owners = append(owners, owner)
+			}
+		}
+		secrets = append(secrets, SecretReference{Name: name, ReferencedBy: owners})
+	}
+	return secrets
+}
+
+// readme describes the directory of an export
+func (e *GitOpsExport) readme() string {
+	var readme strings.Builder
+	readme.WriteString(fmt.Sprintf("# Cluster %s/%s\n\n", e.Namespace, e.Name))
+	readme.WriteString("Exported from the management cluster. The manifests carry no status and no\n")
+	readme.WriteString("server-set metadata, so applying them adopts the running cluster.\n")
+	if e.Topology {
+		readme.WriteString("\nThe cluster is managed by a ClusterClass: the topology controller creates\n")
+		readme.WriteString("its other objects, which are therefore not part of the export.\n")
+	}
+	switch e.Tool {
+	case GitOpsFlux:
+		readme.WriteString(fmt.Sprintf("\nApply %s-sync.yaml to the management cluster to let Flux sync this\n", e.Tool))
+		readme.WriteString("directory. It does not prune, removing the directory leaves the cluster running.\n")
+	case GitOpsArgoCD:
+		readme.WriteString(fmt.Sprintf("\nApply %s-sync.yaml to the management cluster to let Argo CD sync this\n", e.Tool))
+		readme.WriteString("directory. It does not prune, removing the directory leaves the cluster running.\n")
+	}
+	if len(e.Secrets) > 0 {
+		readme.WriteString("\n## Secrets\n\n")
+		readme.WriteString("These Secrets are referenced but not exported. Provide them in the namespace\n")
+		readme.WriteString(fmt.Sprintf("%s outside of Git or encrypted, e.g. with SOPS, Sealed Secrets or External Secrets:\n\n", e.Namespace))
+		for _, secret := range e.Secrets {
+			readme.WriteString(fmt.Sprintf("- `%s`, used by %s\n", secret.Name, strings.Join(secret.ReferencedBy, ", ")))
+		}
+	}
+	return readme.String()
+}
+
+// Archive returns the files of an export as a gzipped tarball
+func (e *GitOpsExport) Archive() ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, file := range e.Files {
+		header := &tar.Header{Name: file.Path, Mode: 0o644, Size: int64(len(file.Content)), ModTime: now, Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to archive %s: %w", file.Path, err)
+		}
+		if _, err := tw.Write([]byte(file.Content)); err != nil {
+			return nil, fmt.Errorf("failed to archive %s: %w", file.Path, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to archive the export: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to archive the export: %w", err)
+	}
+	return buf.Bytes(), nil
+}
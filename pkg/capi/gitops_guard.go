@@ -0,0 +1,138 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Labels and annotations Flux and Argo CD track the objects they apply with
+const (
+	fluxKustomizationNameLabel      = "kustomize.toolkit.fluxcd.io/name"
+	fluxKustomizationNamespaceLabel = "kustomize.toolkit.fluxcd.io/namespace"
+	fluxHelmReleaseNameLabel        = "helm.toolkit.fluxcd.io/name"
+	fluxHelmReleaseNamespaceLabel   = "helm.toolkit.fluxcd.io/namespace"
+	// fluxReconcileAnnotation set to disabled stops Flux from changing an
+	// object
+	fluxReconcileAnnotation = "kustomize.toolkit.fluxcd.io/reconcile"
+	// argoCDTrackingAnnotation is <application>:<group>/<kind>:<namespace>/<name>,
+	// the application prefixed with its namespace and _ outside the Argo CD
+	// namespace
+	argoCDTrackingAnnotation = "argocd.argoproj.io/tracking-id"
+	argoCDInstanceLabel      = "argocd.argoproj.io/instance"
+)
+
+// GitOpsOwner is the Flux or Argo CD object that applies an object from Git
+type GitOpsOwner struct {
+	// Tool is flux or argocd
+	Tool string `json:"tool"`
+	// Kind is Kustomization or HelmRelease for Flux, Application for Argo CD
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// String names the owner, e.g. Flux Kustomization flux-system/clusters
+func (o GitOpsOwner) String() string {
+	tool := "Flux"
+	if o.Tool == GitOpsArgoCD {
+		tool = "Argo CD"
+	}
+	name := o.Name
+	if o.Namespace != "" {
+		name = o.Namespace + "/" + o.Name
+	}
+	return fmt.Sprintf("%s %s %s", tool, o.Kind, name)
+}
+
+// GitOpsOwnerOf returns the Flux Kustomization, Flux HelmRelease or Argo CD
+// Application that applies an object, from the labels and annotations they
+// track their objects with, or nil. Objects Flux is told not to reconcile
+// have no owner.
+func GitOpsOwnerOf(obj metav1.Object) *GitOpsOwner {
+	labels, annotations := obj.GetLabels(), obj.GetAnnotations()
+	if annotations[fluxReconcileAnnotation] != "disabled" {
+		if name := labels[fluxKustomizationNameLabel]; name != "" {
+			return &GitOpsOwner{Tool: GitOpsFlux, Kind: "Kustomization", Namespace: labels[fluxKustomizationNamespaceLabel], Name: name}
+		}
+		if name := labels[fluxHelmReleaseNameLabel]; name != "" {
+			return &GitOpsOwner{Tool: GitOpsFlux, Kind: "HelmRelease", Namespace: labels[fluxHelmReleaseNamespaceLabel], Name: name}
+		}
+	}
+	if tracking := annotations[argoCDTrackingAnnotation]; tracking != "" {
+		application, _, _ := strings.Cut(tracking, ":")
+		owner := &GitOpsOwner{Tool: GitOpsArgoCD, Kind: "Application", Name: application}
+		if namespace, name, ok := strings.Cut(application, "_"); ok {
+			owner.Namespace, owner.Name = namespace, name
+		}
+		return owner
+	}
+	if name := labels[argoCDInstanceLabel]; name != "" {
+		return &GitOpsOwner{Tool: GitOpsArgoCD, Kind: "Application", Name: name}
+	}
+	return nil
+}
+
+// GitOpsOwnedObject is an object owned by a GitOps tool that was changed
+// anyway
+type GitOpsOwnedObject struct {
+	Kind      string      `json:"kind"`
+	Namespace string      `json:"namespace,omitempty"`
+	Name      string      `json:"name"`
+	Owner     GitOpsOwner `json:"owner"`
+}
+
+// GitOpsOverride collects the objects owned by GitOps tools that the
+// operations run with its context change
+type GitOpsOverride struct {
+	mu      sync.Mutex
+	objects []GitOpsOwnedObject
+}
+
+// gitOpsOverrideKey is the context key of a GitOpsOverride
+type gitOpsOverrideKey struct{}
+
+// WithGitOpsOverride returns a context in which the client changes objects
+// owned by Flux or Argo CD, which it refuses otherwise since the change is
+// reverted on the next sync. The returned GitOpsOverride collects them, so
+// callers can warn about the drift.
+func WithGitOpsOverride(ctx context.Context) (context.Context, *GitOpsOverride) {
+	override := &GitOpsOverride{}
+	return context.WithValue(ctx, gitOpsOverrideKey{}, override), override
+}
+
+// Objects returns the owned objects changed so far
+func (o *GitOpsOverride) Objects() []GitOpsOwnedObject {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]GitOpsOwnedObject{}, o.objects...)
+}
+
+// checkGitOpsOwner refuses to change an object owned by a GitOps tool,
+// unless the context overrides the guard, in which case the object is
+// recorded
+func checkGitOpsOwner(ctx context.Context, obj client.Object) error {
+	owner := GitOpsOwnerOf(obj)
+	if owner == nil {
+		return nil
+	}
+	kind := objectKind(obj)
+	override, _ := ctx.Value(gitOpsOverrideKey{}).(*GitOpsOverride)
+	if override == nil {
+		return errorf(ErrPreconditionFailed, "%s %s/%s is managed by %s: a direct change is reverted on its next sync and drifts from Git, change it in its repository or override the GitOps guard",
+			kind, obj.GetNamespace(), obj.GetName(), owner)
+	}
+	override.mu.Lock()
+	defer override.mu.Unlock()
+	for _, owned := range override.objects {
+		if owned.Kind == kind && owned.Namespace == obj.GetNamespace() && owned.Name == obj.GetName() {
+			return nil
+		}
+	}
+	override.objects = append(override.objects, GitOpsOwnedObject{Kind: kind, Namespace: obj.GetNamespace(), Name: obj.GetName(), Owner: *owner})
+	return nil
+}
@@ -0,0 +1,83 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGitOpsOwnerOf(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		want        string
+	}{
+		{"unmanaged", map[string]string{"env": "prod"}, nil, ""},
+		{"flux kustomization", map[string]string{fluxKustomizationNameLabel: "clusters", fluxKustomizationNamespaceLabel: "flux-system"}, nil, "Flux Kustomization flux-system/clusters"},
+		{"flux helm release", map[string]string{fluxHelmReleaseNameLabel: "prod", fluxHelmReleaseNamespaceLabel: "org-acme"}, nil, "Flux HelmRelease org-acme/prod"},
+		{"flux reconcile disabled", map[string]string{fluxKustomizationNameLabel: "clusters"}, map[string]string{fluxReconcileAnnotation: "disabled"}, ""},
+		{"argo cd tracking", nil, map[string]string{argoCDTrackingAnnotation: "prod:cluster.x-k8s.io/Cluster:org-acme/prod"}, "Argo CD Application prod"},
+		{"argo cd tracking in any namespace", nil, map[string]string{argoCDTrackingAnnotation: "team-a_prod:cluster.x-k8s.io/Cluster:org-acme/prod"}, "Argo CD Application team-a/prod"},
+		{"argo cd instance", map[string]string{argoCDInstanceLabel: "prod"}, nil, "Argo CD Application prod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Labels: tt.labels, Annotations: tt.annotations}
+			owner := GitOpsOwnerOf(obj)
+			got := ""
+			if owner != nil {
+				got = owner.String()
+			}
+			if got != tt.want {
+				t.Errorf("GitOpsOwnerOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitOpsGuard(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	managed := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "org-acme",
+		Name:      "prod",
+		Labels:    map[string]string{fluxKustomizationNameLabel: "clusters", fluxKustomizationNamespaceLabel: "flux-system"},
+	}}
+	unmanaged := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "dev"}}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(managed, unmanaged).Build()}
+	ctx := context.Background()
+
+	if err := c.PauseCluster(ctx, "org-acme", "dev"); err != nil {
+		t.Errorf("PauseCluster(dev) error = %v", err)
+	}
+	if err := c.PauseCluster(ctx, "org-acme", "prod"); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("PauseCluster(prod) error = %v, want ErrPreconditionFailed", err)
+	}
+	if err := c.DeleteCluster(ctx, "org-acme", "prod"); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("DeleteCluster(prod) error = %v, want ErrPreconditionFailed", err)
+	}
+
+	overrideCtx, override := WithGitOpsOverride(ctx)
+	if err := c.PauseCluster(overrideCtx, "org-acme", "prod"); err != nil {
+		t.Fatalf("PauseCluster(prod) with override error = %v", err)
+	}
+	cluster, err := c.GetCluster(ctx, "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cluster.Annotations[clusterv1.PausedAnnotation] != "true" {
+		t.Error("the cluster was not paused with the override")
+	}
+	objects := override.Objects()
+	if len(objects) != 1 || objects[0].Name != "prod" || objects[0].Owner.Name != "clusters" {
+		t.Errorf("overridden objects = %+v, want the prod cluster", objects)
+	}
+}
@@ -0,0 +1,144 @@
+package capi
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+func TestExportGitOps(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod", ResourceVersion: "42", UID: "1234"},
+		Spec: clusterv1.ClusterSpec{
+			InfrastructureRef: &corev1.ObjectReference{APIVersion: dockerClusterGVK.GroupVersion().String(), Kind: "DockerCluster", Name: "prod"},
+		},
+		Status: clusterv1.ClusterStatus{Phase: "Provisioned"},
+	}
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-md-0"},
+		Spec: clusterv1.MachineDeploymentSpec{
+			ClusterName: "prod",
+			Template: clusterv1.MachineTemplateSpec{Spec: clusterv1.MachineSpec{
+				ClusterName: "prod",
+				Bootstrap: clusterv1.Bootstrap{ConfigRef: &corev1.ObjectReference{
+					APIVersion: kubeadmConfigTemplateGVK.GroupVersion().String(), Kind: "KubeadmConfigTemplate", Name: "workers",
+				}},
+				InfrastructureRef: corev1.ObjectReference{APIVersion: dockerMachineTemplateGVK.GroupVersion().String(), Kind: "DockerMachineTemplate", Name: "workers"},
+			}},
+		},
+	}
+	configTemplate := newUnstructured(kubeadmConfigTemplateGVK, "org-acme", "workers", nil)
+	configTemplate.Object["spec"] = map[string]any{"template": map[string]any{"spec": map[string]any{
+		"files": []any{map[string]any{"path": "/etc/registry.conf", "contentFrom": map[string]any{"secret": map[string]any{"name": "registry-auth", "key": "config"}}}},
+	}}}
+
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		cluster, md, configTemplate,
+		newUnstructured(dockerClusterGVK, "org-acme", "prod", nil),
+		newUnstructured(dockerMachineTemplateGVK, "org-acme", "workers", nil),
+	).Build()}
+	ctx := context.Background()
+
+	export, err := c.ExportGitOps(ctx, ExportGitOpsOptions{Namespace: "org-acme", Name: "prod", Tool: GitOpsFlux})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, file := range export.Files {
+		files[file.Path] = file.Content
+	}
+	dir := "clusters/org-acme/prod/"
+	for _, name := range []string{
+		"cluster-prod.yaml", "dockercluster-prod.yaml", "machinedeployment-prod-md-0.yaml",
+		"kubeadmconfigtemplate-workers.yaml", "dockermachinetemplate-workers.yaml",
+		"kustomization.yaml", "flux-sync.yaml", "README.md",
+	} {
+		if _, ok := files[dir+name]; !ok {
+			t.Errorf("missing file %s, got %d files", name, len(files))
+		}
+	}
+
+	clusterYAML := files[dir+"cluster-prod.yaml"]
+	for _, unwanted := range []string{"status", "resourceVersion", "uid", "namespace"} {
+		if strings.Contains(clusterYAML, unwanted+":") {
+			t.Errorf("cluster-prod.yaml contains %s:\n%s", unwanted, clusterYAML)
+		}
+	}
+	var kustomization struct {
+		Namespace string   `json:"namespace"`
+		Resources []string `json:"resources"`
+	}
+	if err := yaml.Unmarshal([]byte(files[dir+"kustomization.yaml"]), &kustomization); err != nil {
+		t.Fatal(err)
+	}
+	if kustomization.Namespace != "org-acme" || len(kustomization.Resources) != 5 {
+		t.Errorf("kustomization = %+v, want the five objects in org-acme", kustomization)
+	}
+	if sync := files[dir+"flux-sync.yaml"]; !strings.Contains(sync, "path: ./clusters/org-acme/prod") || !strings.Contains(sync, "prune: false") {
+		t.Errorf("flux-sync.yaml = %s", sync)
+	}
+
+	if len(export.Secrets) != 1 || export.Secrets[0].Name != "registry-auth" || export.Secrets[0].ReferencedBy[0] != "KubeadmConfigTemplate/workers" {
+		t.Errorf("secrets = %+v, want registry-auth", export.Secrets)
+	}
+	if !strings.Contains(files[dir+"README.md"], "`registry-auth`") {
+		t.Errorf("README.md does not name the secret:\n%s", files[dir+"README.md"])
+	}
+
+	archive, err := export.Archive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	archived := 0
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(tr)
+		if string(data) != files[header.Name] {
+			t.Errorf("archived %s differs from the export", header.Name)
+		}
+		archived++
+	}
+	if archived != len(export.Files) {
+		t.Errorf("archived %d files, want %d", archived, len(export.Files))
+	}
+
+	for _, opts := range []ExportGitOpsOptions{
+		{Namespace: "org-acme", Name: "prod", Tool: GitOpsArgoCD},
+		{Namespace: "org-acme", Name: "prod", Tool: "jenkins"},
+		{Namespace: "org-acme", Name: "prod", Path: "../outside"},
+	} {
+		if _, err := c.ExportGitOps(ctx, opts); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("ExportGitOps(%+v) error = %v, want ErrInvalidArgument", opts, err)
+		}
+	}
+	if _, err := c.ExportGitOps(ctx, ExportGitOpsOptions{Namespace: "org-acme", Name: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ExportGitOps(missing) error = %v, want ErrNotFound", err)
+	}
+}
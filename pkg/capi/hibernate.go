@@ -0,0 +1,242 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// HibernatedAnnotation marks a hibernated Cluster, with the time it was
+	// hibernated
+	HibernatedAnnotation = "mcp-capi.giantswarm.io/hibernated"
+	// HibernatedReplicasAnnotation records the replicas a MachineDeployment,
+	// MachinePool or control plane had before its cluster was hibernated
+	HibernatedReplicasAnnotation = "mcp-capi.giantswarm.io/hibernated-replicas"
+)
+
+// HibernateOptions configures HibernateCluster
+type HibernateOptions struct {
+	// ControlPlane also scales the control plane to zero, when its kind
+	// supports it. KubeadmControlPlanes need at least one replica.
+	ControlPlane bool
+	// Wait bounds the wait for the machines to be deleted before the
+	// cluster is paused
+	Wait WaitOptions
+}
+
+// Hibernation describes the objects of a hibernated or woken up cluster
+type Hibernation struct {
+	Namespace string `json:"namespace"`
+	Cluster   string `json:"cluster"`
+	// Since is when the cluster was hibernated
+	Since   string             `json:"since"`
+	Objects []HibernatedObject `json:"objects"`
+	// ControlPlaneKept explains why the control plane was not scaled down
+	ControlPlaneKept string `json:"controlPlaneKept,omitempty"`
+	Paused           bool   `json:"paused"`
+}
+
+// HibernatedObject is an object scaled down by a hibernation, with the
+// replicas it had before and gets back when the cluster wakes up
+type HibernatedObject struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Replicas int64  `json:"replicas"`
+}
+
+// HibernateCluster scales the MachineDeployments and MachinePools of a
+// cluster, and optionally its control plane, to zero, recording their
+// replicas in the HibernatedReplicasAnnotation. Once their machines are
+// deleted, the cluster is paused so nothing scales it back up. Calling it
+// again after a failed wait resumes the hibernation without losing the
+// recorded replicas.
+func (c *Client) HibernateCluster(ctx context.Context, namespace, name string, opts HibernateOptions) (*Hibernation, error) {
+	cluster, err := c.GetCluster(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	since, resumed := cluster.Annotations[HibernatedAnnotation]
+	_, paused := cluster.Annotations[clusterv1.PausedAnnotation]
+	switch {
+	case (paused || cluster.Spec.Paused) && resumed:
+		return nil, errorf(ErrPreconditionFailed, "cluster %s/%s is hibernated since %s", namespace, name, since)
+	case paused || cluster.Spec.Paused:
+		return nil, errorf(ErrPreconditionFailed, "cluster %s/%s is paused, so its machines cannot be scaled down", namespace, name)
+	}
+	if !resumed {
+		since = time.Now().UTC().Format(time.RFC3339)
+	}
+	hibernation := &Hibernation{Namespace: namespace, Cluster: name, Since: since, Objects: []HibernatedObject{}}
+
+	scalables, err := c.scalableObjects(ctx, cluster, opts.ControlPlane)
+	if err != nil {
+		return nil, err
+	}
+	scaledControlPlane := false
+	for _, obj := range scalables {
+		if obj.isControlPlane && obj.reason != "" {
+			hibernation.ControlPlaneKept = obj.reason
+			continue
+		}
+		var replicas int64
+		err := c.updateObject(ctx, client.ObjectKeyFromObject(obj.object), obj.object, "hibernate", func() error {
+			annotations := obj.object.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			if recorded, ok := annotations[HibernatedReplicasAnnotation]; ok {
+				replicas, _ = strconv.ParseInt(recorded, 10, 64)
+			} else {
+				replicas = 1
+				if current, found, _ := unstructured.NestedInt64(obj.object.Object, "spec", "replicas"); found {
+					replicas = current
+				}
+				annotations[HibernatedReplicasAnnotation] = strconv.FormatInt(replicas, 10)
+				obj.object.SetAnnotations(annotations)
+			}
+			return unstructured.SetNestedField(obj.object.Object, int64(0), "spec", "replicas")
+		})
+		if err != nil {
+			return hibernation, fmt.Errorf("failed to scale down %s %s: %w", obj.object.GetKind(), obj.object.GetName(), err)
+		}
+		hibernation.Objects = append(hibernation.Objects, HibernatedObject{Kind: obj.object.GetKind(), Name: obj.object.GetName(), Replicas: replicas})
+		scaledControlPlane = scaledControlPlane || obj.isControlPlane
+	}
+
+	if !resumed {
+		err = c.updateObject(ctx, client.ObjectKeyFromObject(cluster), cluster, "", func() error {
+			if cluster.Annotations == nil {
+				cluster.Annotations = map[string]string{}
+			}
+			cluster.Annotations[HibernatedAnnotation] = since
+			return nil
+		})
+		if err != nil {
+			return hibernation, fmt.Errorf("failed to mark cluster as hibernated: %w", err)
+		}
+	}
+
+	// Paused clusters are not reconciled, so their machines would stay
+	err = c.waitFor(ctx, "Cluster", namespace, name, &clusterv1.ClusterList{}, opts.Wait, func(ctx context.Context) (bool, string, error) {
+		machines, err := c.ListMachines(ctx, namespace, name)
+		if err != nil {
+			return false, "", err
+		}
+		remaining := 0
+		for _, machine := range machines.Items {
+			if _, controlPlane := machine.Labels[clusterv1.MachineControlPlaneLabel]; !controlPlane || scaledControlPlane {
+				remaining++
+			}
+		}
+		return remaining == 0, fmt.Sprintf("%d machines to delete", remaining), nil
+	})
+	if err != nil {
+		return hibernation, fmt.Errorf("machines of cluster %s/%s are not deleted, call again to finish hibernating: %w", namespace, name, err)
+	}
+
+	if err := c.PauseCluster(ctx, namespace, name); err != nil {
+		return hibernation, err
+	}
+	hibernation.Paused = true
+	return hibernation, nil
+}
+
+// WakeCluster resumes a hibernated cluster and scales its MachineDeployments,
+// MachinePools and control plane back to the replicas recorded by
+// HibernateCluster
+func (c *Client) WakeCluster(ctx context.Context, namespace, name string) (*Hibernation, error) {
+	cluster, err := c.GetCluster(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	since, ok := cluster.Annotations[HibernatedAnnotation]
+	if !ok {
+		return nil, errorf(ErrPreconditionFailed, "cluster %s/%s is not hibernated", namespace, name)
+	}
+	hibernation := &Hibernation{Namespace: namespace, Cluster: name, Since: since, Objects: []HibernatedObject{}}
+
+	if err := c.ResumeCluster(ctx, namespace, name); err != nil {
+		return nil, err
+	}
+	scalables, err := c.scalableObjects(ctx, cluster, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range scalables {
+		recorded, ok := obj.object.GetAnnotations()[HibernatedReplicasAnnotation]
+		if !ok {
+			continue
+		}
+		replicas, err := strconv.ParseInt(recorded, 10, 64)
+		if err != nil {
+			return hibernation, errorf(ErrPreconditionFailed, "%s %s has invalid recorded replicas %q", obj.object.GetKind(), obj.object.GetName(), recorded)
+		}
+		err = c.updateObject(ctx, client.ObjectKeyFromObject(obj.object), obj.object, "wake", func() error {
+			annotations := obj.object.GetAnnotations()
+			delete(annotations, HibernatedReplicasAnnotation)
+			obj.object.SetAnnotations(annotations)
+			return unstructured.SetNestedField(obj.object.Object, replicas, "spec", "replicas")
+		})
+		if err != nil {
+			return hibernation, fmt.Errorf("failed to scale up %s %s: %w", obj.object.GetKind(), obj.object.GetName(), err)
+		}
+		hibernation.Objects = append(hibernation.Objects, HibernatedObject{Kind: obj.object.GetKind(), Name: obj.object.GetName(), Replicas: replicas})
+	}
+
+	// The mark goes last, so a failed wake up can be retried
+	err = c.updateObject(ctx, client.ObjectKeyFromObject(cluster), cluster, "", func() error {
+		delete(cluster.Annotations, HibernatedAnnotation)
+		return nil
+	})
+	if err != nil {
+		return hibernation, fmt.Errorf("failed to unmark cluster as hibernated: %w", err)
+	}
+	return hibernation, nil
+}
+
+// scalableObject is an object of a cluster with spec.replicas. reason tells
+// why a control plane cannot be scaled to zero.
+type scalableObject struct {
+	object         *unstructured.Unstructured
+	isControlPlane bool
+	reason         string
+}
+
+// scalableObjects returns the MachineDeployments and MachinePools of a
+// cluster and, if requested, its control plane
+func (c *Client) scalableObjects(ctx context.Context, cluster *clusterv1.Cluster, controlPlane bool) ([]scalableObject, error) {
+	var objects []scalableObject
+	for _, kind := range []string{"MachineDeployment", "MachinePool"} {
+		items, err := c.listUnstructured(ctx, clusterv1.GroupVersion.WithKind(kind+"List"), client.InNamespace(cluster.Namespace))
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			if clusterName, _, _ := unstructured.NestedString(items[i].Object, "spec", "clusterName"); clusterName == cluster.Name {
+				objects = append(objects, scalableObject{object: &items[i]})
+			}
+		}
+	}
+
+	ref := cluster.Spec.ControlPlaneRef
+	if !controlPlane || ref == nil {
+		return objects, nil
+	}
+	obj, err := c.getReferenced(ctx, cluster.Namespace, ref.APIVersion, ref.Kind, ref.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get control plane: %w", err)
+	}
+	scalable := scalableObject{object: obj, isControlPlane: true}
+	if _, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); !found {
+		scalable.reason = fmt.Sprintf("%s %s has no replicas to scale", ref.Kind, ref.Name)
+	} else if ref.Kind == "KubeadmControlPlane" {
+		scalable.reason = fmt.Sprintf("KubeadmControlPlane %s needs at least one replica", ref.Name)
+	}
+	return append(objects, scalable), nil
+}
@@ -0,0 +1,123 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHibernateAndWakeCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	md := func(name string, replicas int32) *clusterv1.MachineDeployment {
+		return &clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: name},
+			Spec:       clusterv1.MachineDeploymentSpec{ClusterName: "dev", Replicas: ptr.To(replicas)},
+		}
+	}
+	worker := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "org-acme",
+		Name:      "dev-md-0-a",
+		Labels:    map[string]string{clusterv1.ClusterNameLabel: "dev"},
+	}}
+	ctrlClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "dev"},
+			Spec: clusterv1.ClusterSpec{ControlPlaneRef: &corev1.ObjectReference{
+				APIVersion: controlplanev1.GroupVersion.String(), Kind: "KubeadmControlPlane", Name: "dev-control-plane",
+			}},
+		},
+		&controlplanev1.KubeadmControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "dev-control-plane"},
+			Spec:       controlplanev1.KubeadmControlPlaneSpec{Replicas: ptr.To[int32](3)},
+		},
+		&clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "org-acme",
+			Name:      "dev-control-plane-a",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: "dev", clusterv1.MachineControlPlaneLabel: ""},
+		}},
+		worker,
+		md("dev-md-0", 3),
+		md("dev-md-1", 0),
+	).Build()
+	c := &Client{ctrlClient: ctrlClient}
+	ctx := context.Background()
+	wait := WaitOptions{Timeout: 50 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+
+	// The worker machine is not deleted without a controller
+	hibernation, err := c.HibernateCluster(ctx, "org-acme", "dev", HibernateOptions{ControlPlane: true, Wait: wait})
+	if err == nil {
+		t.Fatal("HibernateCluster succeeded with a remaining worker")
+	}
+	if len(hibernation.Objects) != 2 || hibernation.Paused || hibernation.ControlPlaneKept == "" {
+		t.Errorf("hibernation = %+v, want 2 scaled objects and the control plane kept", hibernation)
+	}
+	if err := ctrlClient.Delete(ctx, worker); err != nil {
+		t.Fatal(err)
+	}
+
+	hibernation, err = c.HibernateCluster(ctx, "org-acme", "dev", HibernateOptions{ControlPlane: true, Wait: wait})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hibernation.Paused || hibernation.Objects[0].Name != "dev-md-0" || hibernation.Objects[0].Replicas != 3 {
+		t.Errorf("hibernation = %+v, want dev-md-0 recorded with 3 replicas", hibernation)
+	}
+	scaled, err := c.GetMachineDeployment(ctx, "org-acme", "dev-md-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *scaled.Spec.Replicas != 0 || scaled.Annotations[HibernatedReplicasAnnotation] != "3" {
+		t.Errorf("dev-md-0 = %d replicas, annotations %v, want 0 and 3 recorded", *scaled.Spec.Replicas, scaled.Annotations)
+	}
+	cluster, err := c.GetCluster(ctx, "org-acme", "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cluster.Annotations[clusterv1.PausedAnnotation] == "" || cluster.Annotations[HibernatedAnnotation] != hibernation.Since {
+		t.Errorf("cluster annotations = %v, want paused and hibernated", cluster.Annotations)
+	}
+	if _, err := c.HibernateCluster(ctx, "org-acme", "dev", HibernateOptions{Wait: wait}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("HibernateCluster(hibernated) err = %v, want a failed precondition", err)
+	}
+
+	woken, err := c.WakeCluster(ctx, "org-acme", "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(woken.Objects) != 2 {
+		t.Errorf("woken = %+v, want 2 restored objects", woken)
+	}
+	restored, err := c.GetMachineDeployment(ctx, "org-acme", "dev-md-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *restored.Spec.Replicas != 3 || restored.Annotations[HibernatedReplicasAnnotation] != "" {
+		t.Errorf("dev-md-0 = %d replicas, annotations %v, want 3 and no record", *restored.Spec.Replicas, restored.Annotations)
+	}
+	cluster = &clusterv1.Cluster{}
+	if err := ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "dev"}, cluster); err != nil {
+		t.Fatal(err)
+	}
+	if len(cluster.Annotations) != 0 {
+		t.Errorf("cluster annotations = %v, want none", cluster.Annotations)
+	}
+	if _, err := c.WakeCluster(ctx, "org-acme", "dev"); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("WakeCluster(awake) err = %v, want a failed precondition", err)
+	}
+}
@@ -0,0 +1,506 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var (
+	// awsClusterStaticIdentityGVK holds AWS access keys in a Secret
+	awsClusterStaticIdentityGVK = schema.GroupVersionKind{Group: infrastructureGroup, Version: "v1beta2", Kind: "AWSClusterStaticIdentity"}
+	// awsClusterRoleIdentityGVK assumes an IAM role from another identity
+	awsClusterRoleIdentityGVK = schema.GroupVersionKind{Group: infrastructureGroup, Version: "v1beta2", Kind: "AWSClusterRoleIdentity"}
+	// awsClusterControllerIdentityGVK uses the credentials of the CAPA controller
+	awsClusterControllerIdentityGVK = schema.GroupVersionKind{Group: infrastructureGroup, Version: "v1beta2", Kind: "AWSClusterControllerIdentity"}
+	// azureClusterIdentityGVK is a service principal or managed identity of CAPZ
+	azureClusterIdentityGVK = schema.GroupVersionKind{Group: infrastructureGroup, Version: "v1beta1", Kind: "AzureClusterIdentity"}
+	// vsphereClusterIdentityGVK holds vCenter credentials in a Secret
+	vsphereClusterIdentityGVK = schema.GroupVersionKind{Group: infrastructureGroup, Version: "v1beta1", Kind: "VSphereClusterIdentity"}
+)
+
+// identityProviders are the providers whose credentials are validated, with
+// the namespace clusterctl installs their controller into
+var identityProviders = map[Provider]string{
+	ProviderAWS:     "capa-system",
+	ProviderAzure:   "capz-system",
+	ProviderGCP:     "capg-system",
+	ProviderVSphere: "capv-system",
+}
+
+const (
+	// awsDefaultControllerIdentity is the identity CAPA defaults clusters
+	// without an identityRef to
+	awsDefaultControllerIdentity = "default"
+	// gcpBootstrapCredentialsSecret holds the service account key CAPG uses
+	// for clusters without a credentialsRef
+	gcpBootstrapCredentialsSecret = "capg-manager-bootstrap-credentials"
+)
+
+// IdentityInfo is a provider identity and the result of its validation
+type IdentityInfo struct {
+	Provider Provider `json:"provider"`
+	Kind     string   `json:"kind"`
+	// Namespace is empty for cluster-scoped identities
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Type is the type of an AzureClusterIdentity or the role ARN of an
+	// AWSClusterRoleIdentity
+	Type string `json:"type,omitempty"`
+	// Secret is the namespace/name of the Secret holding the credentials
+	Secret string `json:"secret,omitempty"`
+	// SourceIdentity is the kind/name of the identity an
+	// AWSClusterRoleIdentity assumes its role with
+	SourceIdentity string `json:"sourceIdentity,omitempty"`
+	// AllowedNamespaces describes the namespaces whose clusters may use the
+	// identity
+	AllowedNamespaces string `json:"allowedNamespaces,omitempty"`
+	// UsedBy lists the namespace/name of the clusters referencing the identity
+	UsedBy []string `json:"usedBy"`
+	Valid  bool     `json:"valid"`
+	Issues []string `json:"issues"`
+
+	obj *unstructured.Unstructured
+}
+
+// ClusterIdentityCheck is the validation of the credentials a cluster uses
+type ClusterIdentityCheck struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Provider  Provider `json:"provider"`
+	// Identity is the kind/name of the identity or credentials Secret the
+	// cluster uses
+	Identity string   `json:"identity"`
+	Valid    bool     `json:"valid"`
+	Issues   []string `json:"issues"`
+}
+
+// IdentityReport lists the identities of the providers and the clusters
+// using them
+type IdentityReport struct {
+	Identities []IdentityInfo         `json:"identities"`
+	Clusters   []ClusterIdentityCheck `json:"clusters"`
+}
+
+// ValidateIdentities lists the identities of the AWS, Azure, GCP and vSphere
+// providers, or only of provider if set, and checks that the Secrets they
+// reference exist. It then checks that the clusters of namespace reference an
+// existing, valid identity their namespace is allowed to use. Missing
+// credentials are a frequent cause of clusters that never provision without
+// any error on the Cluster itself.
+func (c *Client) ValidateIdentities(ctx context.Context, provider Provider, namespace string) (*IdentityReport, error) {
+	var providers []Provider
+	for p := range identityProviders {
+		if provider == "" || provider == p {
+			providers = append(providers, p)
+		}
+	}
+	if len(providers) == 0 {
+		return nil, errorf(ErrInvalidArgument, "provider %s has no identities to validate (supported: aws, azure, gcp, vsphere)", provider)
+	}
+
+	v := &identityValidator{
+		c:                    c,
+		controllerNamespaces: make(map[Provider]string),
+		namespaceLabels:      make(map[string]labels.Set),
+		secrets:              make(map[string]bool),
+	}
+	v.findControllerNamespaces(ctx)
+	identities, err := v.listIdentities(ctx, providers)
+	if err != nil {
+		return nil, err
+	}
+	byRef := make(map[string]*IdentityInfo, len(identities))
+	for i := range identities {
+		byRef[identityKey(identities[i].Kind, identities[i].Namespace, identities[i].Name)] = &identities[i]
+	}
+
+	clusters, err := c.ListClusters(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	report := &IdentityReport{Identities: identities, Clusters: []ClusterIdentityCheck{}}
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		p := ClusterProvider(cluster)
+		if !slices.Contains(providers, p) {
+			continue
+		}
+		check, err := v.checkCluster(ctx, cluster, p, byRef)
+		if err != nil {
+			return nil, err
+		}
+		check.Valid = len(check.Issues) == 0
+		report.Clusters = append(report.Clusters, check)
+	}
+	sort.Slice(report.Clusters, func(i, j int) bool {
+		if report.Clusters[i].Namespace != report.Clusters[j].Namespace {
+			return report.Clusters[i].Namespace < report.Clusters[j].Namespace
+		}
+		return report.Clusters[i].Name < report.Clusters[j].Name
+	})
+	for i := range report.Identities {
+		report.Identities[i].obj = nil
+	}
+	return report, nil
+}
+
+// identityKey identifies an identity; cluster-scoped ones have no namespace
+func identityKey(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+// identityValidator caches what the validation of identities and clusters
+// reads more than once
+type identityValidator struct {
+	c                    *Client
+	controllerNamespaces map[Provider]string
+	namespaceLabels      map[string]labels.Set
+	secrets              map[string]bool
+}
+
+// findControllerNamespaces locates the provider controllers by their
+// clusterctl label, falling back to the namespaces clusterctl uses, also when
+// deployments cannot be listed
+func (v *identityValidator) findControllerNamespaces(ctx context.Context) {
+	for provider, namespace := range identityProviders {
+		v.controllerNamespaces[provider] = namespace
+	}
+	deployments, err := v.c.k8sClient.AppsV1().Deployments("").List(ctx, metav1.ListOptions{LabelSelector: clusterv1.ProviderNameLabel})
+	if err != nil {
+		return
+	}
+	for _, deployment := range deployments.Items {
+		name, ok := strings.CutPrefix(deployment.Labels[clusterv1.ProviderNameLabel], "infrastructure-")
+		if _, known := identityProviders[Provider(name)]; ok && known {
+			v.controllerNamespaces[Provider(name)] = deployment.Namespace
+		}
+	}
+}
+
+// listIdentities lists and validates the identities of providers
+func (v *identityValidator) listIdentities(ctx context.Context, providers []Provider) ([]IdentityInfo, error) {
+	identities := []IdentityInfo{}
+	add := func(provider Provider, gvk schema.GroupVersionKind, read func(*IdentityInfo) error) error {
+		if !slices.Contains(providers, provider) {
+			return nil
+		}
+		items, err := v.c.listUnstructured(ctx, gvk)
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			info := IdentityInfo{
+				Provider: provider, Kind: gvk.Kind, Namespace: items[i].GetNamespace(), Name: items[i].GetName(),
+				UsedBy: []string{}, Issues: []string{}, obj: &items[i],
+			}
+			if err := read(&info); err != nil {
+				return err
+			}
+			identities = append(identities, info)
+		}
+		return nil
+	}
+
+	awsNamespace := v.controllerNamespaces[ProviderAWS]
+	vsphereNamespace := v.controllerNamespaces[ProviderVSphere]
+	err := add(ProviderAWS, awsClusterStaticIdentityGVK, func(info *IdentityInfo) error {
+		name, _, _ := unstructured.NestedString(info.obj.Object, "spec", "secretRef")
+		return v.requireSecret(ctx, info, "spec.secretRef", awsNamespace, name)
+	})
+	if err == nil {
+		err = add(ProviderAWS, awsClusterRoleIdentityGVK, func(info *IdentityInfo) error {
+			info.Type, _, _ = unstructured.NestedString(info.obj.Object, "spec", "roleARN")
+			kind, _, _ := unstructured.NestedString(info.obj.Object, "spec", "sourceIdentityRef", "kind")
+			name, _, _ := unstructured.NestedString(info.obj.Object, "spec", "sourceIdentityRef", "name")
+			if kind == "" || name == "" {
+				info.Issues = append(info.Issues, "spec.sourceIdentityRef is not set, the role cannot be assumed")
+			} else {
+				info.SourceIdentity = kind + "/" + name
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		err = add(ProviderAWS, awsClusterControllerIdentityGVK, func(info *IdentityInfo) error { return nil })
+	}
+	if err == nil {
+		err = add(ProviderAzure, azureClusterIdentityGVK, func(info *IdentityInfo) error {
+			info.Type, _, _ = unstructured.NestedString(info.obj.Object, "spec", "type")
+			if clientID, _, _ := unstructured.NestedString(info.obj.Object, "spec", "clientID"); clientID == "" {
+				info.Issues = append(info.Issues, "spec.clientID is not set")
+			}
+			switch info.Type {
+			case "ServicePrincipal", "ServicePrincipalCertificate", "ManualServicePrincipal":
+				name, _, _ := unstructured.NestedString(info.obj.Object, "spec", "clientSecret", "name")
+				namespace, _, _ := unstructured.NestedString(info.obj.Object, "spec", "clientSecret", "namespace")
+				if namespace == "" {
+					namespace = info.Namespace
+				}
+				return v.requireSecret(ctx, info, "spec.clientSecret", namespace, name)
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		err = add(ProviderVSphere, vsphereClusterIdentityGVK, func(info *IdentityInfo) error {
+			name, _, _ := unstructured.NestedString(info.obj.Object, "spec", "secretName")
+			return v.requireSecret(ctx, info, "spec.secretName", vsphereNamespace, name)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Role identities are only as good as the identities they assume their
+	// role with, checked once all are known
+	for i := range identities {
+		info := &identities[i]
+		info.AllowedNamespaces = describeAllowedNamespaces(info)
+		if info.SourceIdentity == "" {
+			continue
+		}
+		kind, name, _ := strings.Cut(info.SourceIdentity, "/")
+		if !slices.ContainsFunc(identities, func(source IdentityInfo) bool { return source.Kind == kind && source.Name == name }) {
+			info.Issues = append(info.Issues, fmt.Sprintf("source identity %s does not exist", info.SourceIdentity))
+		}
+	}
+	for i := range identities {
+		identities[i].Valid = len(identities[i].Issues) == 0
+	}
+	sort.Slice(identities, func(i, j int) bool {
+		a, b := identities[i], identities[j]
+		return identityKey(a.Kind, a.Namespace, a.Name) < identityKey(b.Kind, b.Namespace, b.Name)
+	})
+	return identities, nil
+}
+
+// requireSecret records the credentials Secret of an identity and an issue
+// when it is not set or does not exist
+func (v *identityValidator) requireSecret(ctx context.Context, info *IdentityInfo, field, namespace, name string) error {
+	if name == "" {
+		info.Issues = append(info.Issues, field+" is not set")
+		return nil
+	}
+	info.Secret = namespace + "/" + name
+	found, err := v.secretExists(ctx, namespace, name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		info.Issues = append(info.Issues, fmt.Sprintf("Secret %s does not exist", info.Secret))
+	}
+	return nil
+}
+
+// secretExists reports whether a Secret exists
+func (v *identityValidator) secretExists(ctx context.Context, namespace, name string) (bool, error) {
+	key := namespace + "/" + name
+	if found, ok := v.secrets[key]; ok {
+		return found, nil
+	}
+	_, err := v.c.k8sClient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return false, fmt.Errorf("failed to get Secret %s: %w", key, err)
+	}
+	v.secrets[key] = err == nil
+	return err == nil, nil
+}
+
+// checkCluster validates the identity a cluster references
+func (v *identityValidator) checkCluster(ctx context.Context, cluster *clusterv1.Cluster, provider Provider, identities map[string]*IdentityInfo) (ClusterIdentityCheck, error) {
+	check := ClusterIdentityCheck{Namespace: cluster.Namespace, Name: cluster.Name, Provider: provider, Issues: []string{}}
+	ref := cluster.Spec.InfrastructureRef
+	infra, err := v.c.getReferenced(ctx, cluster.Namespace, ref.APIVersion, ref.Kind, ref.Name)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			check.Issues = append(check.Issues, fmt.Sprintf("%s %s does not exist", ref.Kind, ref.Name))
+			return check, nil
+		}
+		return check, err
+	}
+
+	if provider == ProviderGCP {
+		return check, v.checkGCPCredentials(ctx, &check, infra)
+	}
+
+	// Managed control planes such as EKS and AKS carry the identity instead
+	// of the infrastructure cluster
+	identityRef, _, _ := unstructured.NestedStringMap(infra.Object, "spec", "identityRef")
+	if identityRef == nil && cluster.Spec.ControlPlaneRef != nil && strings.Contains(cluster.Spec.ControlPlaneRef.Kind, "Managed") {
+		cpRef := cluster.Spec.ControlPlaneRef
+		controlPlane, err := v.c.getReferenced(ctx, cluster.Namespace, cpRef.APIVersion, cpRef.Kind, cpRef.Name)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return check, err
+		}
+		if controlPlane != nil {
+			identityRef, _, _ = unstructured.NestedStringMap(controlPlane.Object, "spec", "identityRef")
+		}
+	}
+
+	kind, name := identityRef["kind"], identityRef["name"]
+	switch {
+	case identityRef == nil && provider == ProviderAWS:
+		kind, name = awsClusterControllerIdentityGVK.Kind, awsDefaultControllerIdentity
+	case identityRef == nil && provider == ProviderAzure:
+		check.Issues = append(check.Issues, "no identityRef is set, CAPZ needs an AzureClusterIdentity to authenticate")
+		return check, nil
+	case identityRef == nil:
+		check.Identity = "controller credentials"
+		return check, nil
+	}
+	check.Identity = kind + "/" + name
+
+	// vSphere clusters may reference a Secret in their namespace directly
+	if kind == "Secret" {
+		found, err := v.secretExists(ctx, cluster.Namespace, name)
+		if err != nil {
+			return check, err
+		}
+		if !found {
+			check.Issues = append(check.Issues, fmt.Sprintf("Secret %s/%s does not exist", cluster.Namespace, name))
+		}
+		return check, nil
+	}
+
+	namespace := ""
+	if provider == ProviderAzure {
+		namespace = identityRef["namespace"]
+		if namespace == "" {
+			namespace = cluster.Namespace
+		}
+	}
+	identity := identities[identityKey(kind, namespace, name)]
+	if identity == nil {
+		check.Issues = append(check.Issues, fmt.Sprintf("%s %s does not exist", kind, strings.TrimPrefix(namespace+"/"+name, "/")))
+		return check, nil
+	}
+	identity.UsedBy = append(identity.UsedBy, cluster.Namespace+"/"+cluster.Name)
+	for _, issue := range identity.Issues {
+		check.Issues = append(check.Issues, fmt.Sprintf("%s: %s", check.Identity, issue))
+	}
+	allowed, err := v.allows(ctx, identity, cluster.Namespace)
+	if err != nil {
+		return check, err
+	}
+	if !allowed {
+		check.Issues = append(check.Issues, fmt.Sprintf("%s does not allow clusters of namespace %s (allowed: %s)", check.Identity, cluster.Namespace, identity.AllowedNamespaces))
+	}
+	return check, nil
+}
+
+// checkGCPCredentials validates the credentials Secret of a GCP cluster, the
+// one referenced by its GCPCluster or the bootstrap credentials of CAPG
+func (v *identityValidator) checkGCPCredentials(ctx context.Context, check *ClusterIdentityCheck, infra *unstructured.Unstructured) error {
+	namespace := v.controllerNamespaces[ProviderGCP]
+	name := gcpBootstrapCredentialsSecret
+	if ref, _, _ := unstructured.NestedStringMap(infra.Object, "spec", "credentialsRef"); ref["name"] != "" {
+		namespace, name = ref["namespace"], ref["name"]
+		if namespace == "" {
+			namespace = check.Namespace
+		}
+	}
+	check.Identity = "Secret/" + namespace + "/" + name
+	found, err := v.secretExists(ctx, namespace, name)
+	if err != nil {
+		return err
+	}
+	if !found {
+		check.Issues = append(check.Issues, fmt.Sprintf("credentials Secret %s/%s does not exist", namespace, name))
+	}
+	return nil
+}
+
+// allows reports whether clusters of a namespace may use an identity. An
+// identity without allowedNamespaces allows none, an empty one all; Azure
+// identities always allow their own namespace.
+func (v *identityValidator) allows(ctx context.Context, identity *IdentityInfo, namespace string) (bool, error) {
+	if identity.Provider == ProviderAzure && identity.Namespace == namespace {
+		return true, nil
+	}
+	allowed, found, _ := unstructured.NestedMap(identity.obj.Object, "spec", "allowedNamespaces")
+	if !found {
+		return false, nil
+	}
+	list, _, _ := unstructured.NestedStringSlice(allowed, "list")
+	selector, hasSelector, _ := unstructured.NestedMap(allowed, "selector")
+	if len(list) == 0 && !hasSelector {
+		return true, nil
+	}
+	if slices.Contains(list, namespace) {
+		return true, nil
+	}
+	if !hasSelector {
+		return false, nil
+	}
+	return v.selectorMatches(ctx, selector, namespace)
+}
+
+// selectorMatches reports whether a namespace matches a label selector
+func (v *identityValidator) selectorMatches(ctx context.Context, selector map[string]any, namespace string) (bool, error) {
+	var labelSelector metav1.LabelSelector
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selector, &labelSelector); err != nil {
+		return false, fmt.Errorf("invalid allowedNamespaces selector: %w", err)
+	}
+	parsed, err := metav1.LabelSelectorAsSelector(&labelSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid allowedNamespaces selector: %w", err)
+	}
+	set, ok := v.namespaceLabels[namespace]
+	if !ok {
+		ns, err := v.c.k8sClient.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to get namespace %s: %w", namespace, err)
+		}
+		set = labels.Set(ns.Labels)
+		v.namespaceLabels[namespace] = set
+	}
+	return parsed.Matches(set), nil
+}
+
+// describeAllowedNamespaces summarizes the allowedNamespaces of an identity
+func describeAllowedNamespaces(identity *IdentityInfo) string {
+	var list []string
+	if identity.Provider == ProviderAzure {
+		list = append(list, identity.Namespace)
+	}
+	allowed, found, _ := unstructured.NestedMap(identity.obj.Object, "spec", "allowedNamespaces")
+	if !found && len(list) == 0 {
+		return "none"
+	}
+	configured, _, _ := unstructured.NestedStringSlice(allowed, "list")
+	selector, hasSelector, _ := unstructured.NestedMap(allowed, "selector")
+	if found && len(configured) == 0 && !hasSelector {
+		return "all namespaces"
+	}
+	for _, namespace := range configured {
+		if !slices.Contains(list, namespace) {
+			list = append(list, namespace)
+		}
+	}
+
+	var parts []string
+	if len(list) > 0 {
+		parts = append(parts, "namespaces "+strings.Join(list, ", "))
+	}
+	if hasSelector {
+		var labelSelector metav1.LabelSelector
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(selector, &labelSelector); err == nil {
+			parts = append(parts, "selector "+metav1.FormatLabelSelector(&labelSelector))
+		}
+	}
+	return strings.Join(parts, " or ")
+}
@@ -0,0 +1,166 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateIdentities(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	newObject := func(gvk schema.GroupVersionKind, namespace, name string, spec map[string]any) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]any{"spec": spec}}
+		obj.SetGroupVersionKind(gvk)
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		return obj
+	}
+	newCluster := func(namespace, name, apiVersion, kind string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{APIVersion: apiVersion, Kind: kind, Name: name},
+			},
+		}
+	}
+	awsCluster := schema.GroupVersionKind{Group: infrastructureGroup, Version: "v1beta2", Kind: "AWSCluster"}
+	azureCluster := schema.GroupVersionKind{Group: infrastructureGroup, Version: "v1beta1", Kind: "AzureCluster"}
+	gcpCluster := schema.GroupVersionKind{Group: infrastructureGroup, Version: "v1beta1", Kind: "GCPCluster"}
+	vsphereCluster := schema.GroupVersionKind{Group: infrastructureGroup, Version: "v1beta1", Kind: "VSphereCluster"}
+
+	objects := []client.Object{
+		// AWS: a role identity assumed with a static identity whose Secret
+		// exists, and a static identity missing its Secret
+		newObject(awsClusterStaticIdentityGVK, "", "root", map[string]any{
+			"secretRef": "root-credentials", "allowedNamespaces": map[string]any{},
+		}),
+		newObject(awsClusterStaticIdentityGVK, "", "broken", map[string]any{
+			"secretRef": "missing", "allowedNamespaces": map[string]any{},
+		}),
+		newObject(awsClusterRoleIdentityGVK, "", "prod", map[string]any{
+			"roleARN":           "arn:aws:iam::123456789012:role/capa",
+			"sourceIdentityRef": map[string]any{"kind": "AWSClusterStaticIdentity", "name": "root"},
+			"allowedNamespaces": map[string]any{"selector": map[string]any{"matchLabels": map[string]any{"env": "prod"}}},
+		}),
+		newCluster("org-prod", "eks", awsCluster.GroupVersion().String(), "AWSCluster"),
+		newObject(awsCluster, "org-prod", "eks", map[string]any{"identityRef": map[string]any{"kind": "AWSClusterRoleIdentity", "name": "prod"}}),
+		newCluster("org-dev", "dev", awsCluster.GroupVersion().String(), "AWSCluster"),
+		newObject(awsCluster, "org-dev", "dev", map[string]any{"identityRef": map[string]any{"kind": "AWSClusterRoleIdentity", "name": "prod"}}),
+		newCluster("org-dev", "default", awsCluster.GroupVersion().String(), "AWSCluster"),
+		newObject(awsCluster, "org-dev", "default", map[string]any{}),
+
+		// Azure: a service principal allowed in its own namespace only
+		newObject(azureClusterIdentityGVK, "org-prod", "sp", map[string]any{
+			"type": "ServicePrincipal", "clientID": "id", "clientSecret": map[string]any{"name": "sp-secret"},
+		}),
+		newCluster("org-prod", "aks", azureCluster.GroupVersion().String(), "AzureCluster"),
+		newObject(azureCluster, "org-prod", "aks", map[string]any{"identityRef": map[string]any{"kind": "AzureClusterIdentity", "name": "sp"}}),
+		newCluster("org-dev", "azure", azureCluster.GroupVersion().String(), "AzureCluster"),
+		newObject(azureCluster, "org-dev", "azure", map[string]any{"identityRef": map[string]any{"kind": "AzureClusterIdentity", "name": "sp", "namespace": "org-prod"}}),
+
+		// GCP: the bootstrap credentials of CAPG and a missing referenced Secret
+		newCluster("org-prod", "gke", gcpCluster.GroupVersion().String(), "GCPCluster"),
+		newObject(gcpCluster, "org-prod", "gke", map[string]any{}),
+		newCluster("org-dev", "gcp", gcpCluster.GroupVersion().String(), "GCPCluster"),
+		newObject(gcpCluster, "org-dev", "gcp", map[string]any{"credentialsRef": map[string]any{"name": "gcp-key"}}),
+
+		// vSphere: credentials referenced as a Secret of the cluster namespace
+		newCluster("org-dev", "vsphere", vsphereCluster.GroupVersion().String(), "VSphereCluster"),
+		newObject(vsphereCluster, "org-dev", "vsphere", map[string]any{"identityRef": map[string]any{"kind": "Secret", "name": "vsphere"}}),
+	}
+
+	secret := func(namespace, name string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	c := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(),
+		k8sClient: k8sfake.NewClientset(
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "capa-custom", Name: "capa-controller-manager", Labels: map[string]string{clusterv1.ProviderNameLabel: "infrastructure-aws"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "org-prod", Labels: map[string]string{"env": "prod"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "org-dev", Labels: map[string]string{"env": "dev"}}},
+			secret("capa-custom", "root-credentials"),
+			secret("org-prod", "sp-secret"),
+			secret("capg-system", gcpBootstrapCredentialsSecret),
+		),
+	}
+
+	report, err := c.ValidateIdentities(context.Background(), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identities := map[string]IdentityInfo{}
+	for _, identity := range report.Identities {
+		identities[identity.Kind+"/"+identity.Name] = identity
+	}
+	if root := identities["AWSClusterStaticIdentity/root"]; !root.Valid || root.Secret != "capa-custom/root-credentials" || root.AllowedNamespaces != "all namespaces" {
+		t.Errorf("root identity = %+v, want valid with its Secret in the controller namespace", root)
+	}
+	if broken := identities["AWSClusterStaticIdentity/broken"]; broken.Valid || len(broken.Issues) != 1 {
+		t.Errorf("broken identity = %+v, want its missing Secret reported", broken)
+	}
+	if prod := identities["AWSClusterRoleIdentity/prod"]; !prod.Valid || prod.AllowedNamespaces != "selector env=prod" || len(prod.UsedBy) != 2 {
+		t.Errorf("prod identity = %+v", prod)
+	}
+	if sp := identities["AzureClusterIdentity/sp"]; !sp.Valid || sp.Type != "ServicePrincipal" || sp.AllowedNamespaces != "namespaces org-prod" {
+		t.Errorf("sp identity = %+v", sp)
+	}
+
+	want := map[string]string{
+		"org-prod/eks":    "",
+		"org-dev/dev":     "does not allow clusters of namespace org-dev",
+		"org-dev/default": "AWSClusterControllerIdentity default does not exist",
+		"org-prod/aks":    "",
+		"org-dev/azure":   "does not allow clusters of namespace org-dev",
+		"org-prod/gke":    "",
+		"org-dev/gcp":     "Secret org-dev/gcp-key does not exist",
+		"org-dev/vsphere": "Secret org-dev/vsphere does not exist",
+	}
+	if len(report.Clusters) != len(want) {
+		t.Fatalf("Clusters = %+v, want %d", report.Clusters, len(want))
+	}
+	for _, check := range report.Clusters {
+		issue, ok := want[check.Namespace+"/"+check.Name]
+		if !ok {
+			t.Errorf("unexpected cluster %s/%s", check.Namespace, check.Name)
+			continue
+		}
+		if issue == "" {
+			if !check.Valid {
+				t.Errorf("cluster %s/%s: issues %v, want valid", check.Namespace, check.Name, check.Issues)
+			}
+			continue
+		}
+		if check.Valid || len(check.Issues) != 1 || !strings.Contains(check.Issues[0], issue) {
+			t.Errorf("cluster %s/%s: issues %v, want %q", check.Namespace, check.Name, check.Issues, issue)
+		}
+	}
+
+	report, err = c.ValidateIdentities(context.Background(), ProviderGCP, "org-prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Identities) != 0 || len(report.Clusters) != 1 || report.Clusters[0].Identity != "Secret/capg-system/"+gcpBootstrapCredentialsSecret {
+		t.Errorf("GCP report = %+v, want the bootstrap credentials of gke", report)
+	}
+
+	if _, err := c.ValidateIdentities(context.Background(), ProviderDocker, ""); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ValidateIdentities(docker) error = %v, want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,215 @@
+package capi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// UpdateMachineImageOptions selects the machines whose node image is replaced
+type UpdateMachineImageOptions struct {
+	Namespace string
+	// Name is a MachineDeployment, or a KubeadmControlPlane if ControlPlane
+	// is set
+	Name         string
+	ControlPlane bool
+	// Image is the AMI ID, Azure image version, vSphere template or GCP image
+	Image string
+	// TemplateName names the new infrastructure template. It defaults to
+	// Name with a suffix derived from the image, so repeating an update
+	// reuses the template.
+	TemplateName string
+	// Force skips the check that the image is built for the Kubernetes
+	// version of the machines
+	Force bool
+}
+
+// MachineImageUpdate reports the node image update of a MachineDeployment or
+// KubeadmControlPlane
+type MachineImageUpdate struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Version is the Kubernetes version of the machines
+	Version      string `json:"version,omitempty"`
+	TemplateKind string `json:"templateKind"`
+	OldTemplate  string `json:"oldTemplate"`
+	NewTemplate  string `json:"newTemplate"`
+	// ImageField is the path of the image in the machine spec
+	ImageField string `json:"imageField"`
+	OldImage   string `json:"oldImage,omitempty"`
+	NewImage   string `json:"newImage"`
+	// ImageVersion is the Kubernetes version read from the image name. It is
+	// empty if the image does not carry one, e.g. for AMI IDs.
+	ImageVersion string `json:"imageVersion,omitempty"`
+	// Reused is set when a template with the image already existed
+	Reused bool `json:"reused,omitempty"`
+}
+
+// machineImage locates the node image in the machine spec of an
+// infrastructure template kind
+type machineImage struct {
+	// fields are the candidate paths of the image, the first one present in
+	// a template is replaced; the first one is set if none is
+	fields [][]string
+	// validate checks the format of a new image
+	validate func(image string) error
+	// version extracts the Kubernetes version an image is built for
+	version func(image string) string
+}
+
+// amiPattern matches EC2 image IDs
+var amiPattern = regexp.MustCompile(`^ami-[0-9a-f]{8,17}$`)
+
+// machineImages are the node images of the infrastructure templates whose
+// image can be updated
+var machineImages = map[string]machineImage{
+	"AWSMachineTemplate": {
+		fields: [][]string{{"ami", "id"}},
+		validate: func(image string) error {
+			if !amiPattern.MatchString(image) {
+				return fmt.Errorf("%q is not an AMI ID (ami-<hex>)", image)
+			}
+			return nil
+		},
+	},
+	"AzureMachineTemplate": {
+		fields: [][]string{
+			{"image", "computeGallery", "version"},
+			{"image", "sharedGallery", "version"},
+			{"image", "marketplace", "version"},
+			{"image", "id"},
+		},
+		version: func(image string) string {
+			if v := azureImageVersion(image); v != "" {
+				return v
+			}
+			return versionInName(image)
+		},
+	},
+	"VSphereMachineTemplate": {fields: [][]string{{"template"}}, version: versionInName},
+	"GCPMachineTemplate":     {fields: [][]string{{"image"}}, version: versionInName},
+}
+
+// field returns the path of the image in a machine spec
+func (m machineImage) field(spec map[string]any) []string {
+	for _, field := range m.fields {
+		if _, found, _ := unstructured.NestedFieldNoCopy(spec, field...); found {
+			return field
+		}
+	}
+	return m.fields[0]
+}
+
+// UpdateMachineImage replaces the node image of a MachineDeployment or
+// KubeadmControlPlane. Infrastructure templates are immutable, so the template
+// is cloned with the new image and the infrastructure reference pointed to
+// the clone, which rolls out the machines. Unless opts.Force is set, images
+// whose name carries a Kubernetes version must match the one of the machines.
+func (c *Client) UpdateMachineImage(ctx context.Context, opts UpdateMachineImageOptions) (*MachineImageUpdate, error) {
+	if opts.Image == "" {
+		return nil, errorf(ErrInvalidArgument, "an image is required")
+	}
+
+	update := &MachineImageUpdate{Kind: "MachineDeployment", Namespace: opts.Namespace, Name: opts.Name, NewImage: opts.Image}
+	var ref corev1.ObjectReference
+	if opts.ControlPlane {
+		update.Kind = "KubeadmControlPlane"
+		kcp, err := c.GetKubeadmControlPlane(ctx, opts.Namespace, opts.Name)
+		if err != nil {
+			return nil, err
+		}
+		ref = kcp.Spec.MachineTemplate.InfrastructureRef
+		update.Version = kcp.Spec.Version
+	} else {
+		md, err := c.GetMachineDeployment(ctx, opts.Namespace, opts.Name)
+		if err != nil {
+			return nil, err
+		}
+		ref = md.Spec.Template.Spec.InfrastructureRef
+		if md.Spec.Template.Spec.Version != nil {
+			update.Version = *md.Spec.Template.Spec.Version
+		}
+	}
+	update.TemplateKind = ref.Kind
+	update.OldTemplate = ref.Name
+
+	images, ok := machineImages[ref.Kind]
+	if !ok {
+		return nil, errorf(ErrPreconditionFailed, "updating the image of %s templates is not supported", ref.Kind)
+	}
+	if images.validate != nil {
+		if err := images.validate(opts.Image); err != nil {
+			return nil, errorf(ErrInvalidArgument, "%v", err)
+		}
+	}
+	if images.version != nil {
+		update.ImageVersion = images.version(opts.Image)
+	}
+	if !opts.Force && update.ImageVersion != "" && update.Version != "" && !sameVersion(update.ImageVersion, update.Version) {
+		return nil, errorf(ErrPreconditionFailed, "image %s is built for Kubernetes %s, but the machines of %s %s run %s (use force to update anyway)",
+			opts.Image, update.ImageVersion, update.Kind, opts.Name, update.Version)
+	}
+
+	original, err := c.getReferenced(ctx, opts.Namespace, ref.APIVersion, ref.Kind, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+	spec, _, _ := unstructured.NestedMap(original.Object, "spec", "template", "spec")
+	field := images.field(spec)
+	update.ImageField = strings.Join(field, ".")
+	update.OldImage, _, _ = unstructured.NestedString(spec, field...)
+	if update.OldImage == opts.Image {
+		return nil, errorf(ErrInvalidArgument, "%s %s already uses image %s", update.Kind, opts.Name, opts.Image)
+	}
+
+	update.NewTemplate = opts.TemplateName
+	if update.NewTemplate == "" {
+		sum := sha256.Sum256([]byte(opts.Image))
+		update.NewTemplate = fmt.Sprintf("%s-%s", opts.Name, hex.EncodeToString(sum[:])[:8])
+	}
+	_, err = c.cloneTemplate(ctx, original, update.NewTemplate, func(spec map[string]any) error {
+		return unstructured.SetNestedField(spec, opts.Image, field...)
+	})
+	if errors.Is(err, ErrAlreadyExists) {
+		existing, getErr := c.getReferenced(ctx, opts.Namespace, ref.APIVersion, ref.Kind, update.NewTemplate)
+		if getErr != nil {
+			return nil, getErr
+		}
+		if image, _, _ := unstructured.NestedString(existing.Object, append([]string{"spec", "template", "spec"}, field...)...); image != opts.Image {
+			return nil, err
+		}
+		update.Reused = true
+	} else if err != nil {
+		return nil, err
+	}
+
+	key := client.ObjectKey{Namespace: opts.Namespace, Name: opts.Name}
+	if opts.ControlPlane {
+		kcp := &controlplanev1.KubeadmControlPlane{}
+		err = c.updateObject(ctx, key, kcp, "update-image", func() error {
+			kcp.Spec.MachineTemplate.InfrastructureRef.Name = update.NewTemplate
+			return nil
+		})
+	} else {
+		md := &clusterv1.MachineDeployment{}
+		err = c.updateObject(ctx, key, md, "update-image", func() error {
+			md.Spec.Template.Spec.InfrastructureRef.Name = update.NewTemplate
+			return nil
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to point %s %s to template %s: %w", update.Kind, opts.Name, update.NewTemplate, err)
+	}
+	return update, nil
+}
@@ -0,0 +1,125 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateMachineImage(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	newTemplate := func(apiVersion, kind, name string, spec map[string]any) *unstructured.Unstructured {
+		template := &unstructured.Unstructured{Object: map[string]any{
+			"spec": map[string]any{"template": map[string]any{"spec": spec}},
+		}}
+		template.SetAPIVersion(apiVersion)
+		template.SetKind(kind)
+		template.SetNamespace("org-a")
+		template.SetName(name)
+		return template
+	}
+	version := "v1.30.2"
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: "workers"},
+		Spec: clusterv1.MachineDeploymentSpec{Template: clusterv1.MachineTemplateSpec{Spec: clusterv1.MachineSpec{
+			Version:           &version,
+			InfrastructureRef: corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "VSphereMachineTemplate", Name: "workers-1"},
+		}}},
+	}
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: "prod-cp"},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			Version: "v1.30.2",
+			MachineTemplate: controlplanev1.KubeadmControlPlaneMachineTemplate{
+				InfrastructureRef: corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta2", Kind: "AWSMachineTemplate", Name: "prod-cp-1"},
+			},
+		},
+	}
+	docker := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: "docker"},
+		Spec: clusterv1.MachineDeploymentSpec{Template: clusterv1.MachineTemplateSpec{Spec: clusterv1.MachineSpec{
+			InfrastructureRef: corev1.ObjectReference{Kind: "DockerMachineTemplate", Name: "docker"},
+		}}},
+	}
+
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		md, kcp, docker,
+		newTemplate("infrastructure.cluster.x-k8s.io/v1beta1", "VSphereMachineTemplate", "workers-1", map[string]any{"template": "ubuntu-2204-kube-v1.30.1", "numCPUs": int64(4)}),
+		newTemplate("infrastructure.cluster.x-k8s.io/v1beta2", "AWSMachineTemplate", "prod-cp-1", map[string]any{"instanceType": "m5.xlarge", "ami": map[string]any{"id": "ami-0123456789abcdef0"}}),
+	).Build()}
+	ctx := context.Background()
+
+	_, err := c.UpdateMachineImage(ctx, UpdateMachineImageOptions{Namespace: "org-a", Name: "workers", Image: "ubuntu-2204-kube-v1.31.0"})
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("UpdateMachineImage() with an image for another version error = %v, want ErrPreconditionFailed", err)
+	}
+
+	update, err := c.UpdateMachineImage(ctx, UpdateMachineImageOptions{Namespace: "org-a", Name: "workers", Image: "ubuntu-2204-kube-v1.30.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if update.OldImage != "ubuntu-2204-kube-v1.30.1" || update.ImageField != "template" || update.ImageVersion != "v1.30.2" || update.Reused {
+		t.Errorf("update = %+v", update)
+	}
+	updated, err := c.GetMachineDeployment(ctx, "org-a", "workers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Spec.Template.Spec.InfrastructureRef.Name != update.NewTemplate {
+		t.Errorf("infrastructure reference = %s, want %s", updated.Spec.Template.Spec.InfrastructureRef.Name, update.NewTemplate)
+	}
+	clone := &unstructured.Unstructured{}
+	clone.SetGroupVersionKind(schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "VSphereMachineTemplate"})
+	if err := c.ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-a", Name: update.NewTemplate}, clone); err != nil {
+		t.Fatal(err)
+	}
+	if image, _, _ := unstructured.NestedString(clone.Object, "spec", "template", "spec", "template"); image != "ubuntu-2204-kube-v1.30.2" {
+		t.Errorf("cloned image = %s", image)
+	}
+	if cpus, _, _ := unstructured.NestedInt64(clone.Object, "spec", "template", "spec", "numCPUs"); cpus != 4 {
+		t.Errorf("cloned numCPUs = %d, want the one of the source", cpus)
+	}
+
+	if _, err := c.UpdateMachineImage(ctx, UpdateMachineImageOptions{Namespace: "org-a", Name: "workers", Image: "ubuntu-2204-kube-v1.30.2"}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("UpdateMachineImage() to the current image error = %v, want ErrInvalidArgument", err)
+	}
+
+	if _, err := c.UpdateMachineImage(ctx, UpdateMachineImageOptions{Namespace: "org-a", Name: "prod-cp", ControlPlane: true, Image: "ubuntu-22.04"}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("UpdateMachineImage() with an invalid AMI error = %v, want ErrInvalidArgument", err)
+	}
+	cpUpdate, err := c.UpdateMachineImage(ctx, UpdateMachineImageOptions{Namespace: "org-a", Name: "prod-cp", ControlPlane: true, Image: "ami-0fedcba9876543210", TemplateName: "prod-cp-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cpUpdate.ImageField != "ami.id" || cpUpdate.ImageVersion != "" || cpUpdate.NewTemplate != "prod-cp-2" {
+		t.Errorf("control plane update = %+v", cpUpdate)
+	}
+	updatedKCP, err := c.GetKubeadmControlPlane(ctx, "org-a", "prod-cp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updatedKCP.Spec.MachineTemplate.InfrastructureRef.Name != "prod-cp-2" {
+		t.Errorf("control plane infrastructure reference = %s, want prod-cp-2", updatedKCP.Spec.MachineTemplate.InfrastructureRef.Name)
+	}
+
+	if _, err := c.UpdateMachineImage(ctx, UpdateMachineImageOptions{Namespace: "org-a", Name: "docker", Image: "kindest/node:v1.30.2"}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("UpdateMachineImage() of a Docker template error = %v, want ErrPreconditionFailed", err)
+	}
+}
@@ -0,0 +1,59 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+)
+
+// ManagementClusterInfo describes what a management cluster runs: its
+// Kubernetes version, the Cluster API contract and release, and the
+// installed providers
+type ManagementClusterInfo struct {
+	KubernetesVersion string `json:"kubernetesVersion"`
+	Platform          string `json:"platform,omitempty"`
+	// Contract is the Cluster API contract served by the management cluster
+	Contract *APIContract `json:"contract,omitempty"`
+	// ClusterAPIVersion is the version of the installed core provider
+	ClusterAPIVersion string              `json:"clusterAPIVersion,omitempty"`
+	Providers         []InstalledProvider `json:"providers"`
+	// Retries counts the requests retried after transient errors
+	Retries RetryStats `json:"retries"`
+	// Warnings tell which parts could not be detected
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ManagementClusterInfo detects the version of the management cluster, its
+// Cluster API contract and its installed providers. Only an unreachable API
+// server is an error; failures detecting the contract or the providers are
+// reported as warnings.
+func (c *Client) ManagementClusterInfo(ctx context.Context) (*ManagementClusterInfo, error) {
+	version, err := c.k8sClient.Discovery().ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the management cluster version: %w", err)
+	}
+	info := &ManagementClusterInfo{
+		KubernetesVersion: version.GitVersion,
+		Platform:          version.Platform,
+		Providers:         []InstalledProvider{},
+		Retries:           c.RetryStats(),
+	}
+
+	if contract, err := c.APIContract(ctx); err != nil {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("failed to detect the Cluster API contract: %v", err))
+	} else {
+		info.Contract = contract
+	}
+
+	providers, err := c.ListInstalledProviders(ctx)
+	if err != nil {
+		info.Warnings = append(info.Warnings, fmt.Sprintf("failed to discover installed providers: %v", err))
+		return info, nil
+	}
+	info.Providers = providers
+	for _, provider := range providers {
+		if provider.Type == "CoreProvider" {
+			info.ClusterAPIVersion = provider.Version
+		}
+	}
+	return info, nil
+}
@@ -0,0 +1,37 @@
+package capi
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestManagementClusterInfo(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	k8sClient := k8sfake.NewClientset(
+		newProviderDeploymentObject("capi-system", "capi-controller-manager", "cluster-api", "registry.k8s.io/cluster-api/cluster-api-controller:v1.10.2"),
+		newProviderDeploymentObject("capa-system", "capa-controller-manager", "infrastructure-aws", "registry.k8s.io/cluster-api-aws/cluster-api-aws-controller:v2.8.1"),
+	)
+	k8sClient.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: "v1.31.2", Platform: "linux/amd64"}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).Build(), k8sClient: k8sClient}
+
+	info, err := c.ManagementClusterInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.KubernetesVersion != "v1.31.2" || info.ClusterAPIVersion != "v1.10.2" || len(info.Providers) != 2 {
+		t.Errorf("ManagementClusterInfo() = %+v, want v1.31.2 with Cluster API v1.10.2 and two providers", info)
+	}
+	if info.Contract == nil || info.Contract.Version != ContractV1Beta1 || len(info.Warnings) != 0 {
+		t.Errorf("contract = %+v, warnings = %v, want %s", info.Contract, info.Warnings, ContractV1Beta1)
+	}
+}
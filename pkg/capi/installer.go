@@ -0,0 +1,552 @@
+package capi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/version"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// providerFieldManager owns the fields of provider components applied by
+	// the client
+	providerFieldManager = "mcp-capi"
+	// maxComponentsSize limits the components YAML read from a repository
+	maxComponentsSize = 32 << 20
+)
+
+// ProviderRepository serves the releases of Cluster API providers, named as
+// in clusterctl, e.g. cluster-api or infrastructure-aws
+type ProviderRepository interface {
+	// LatestVersion returns the newest release of a provider
+	LatestVersion(ctx context.Context, provider string) (string, error)
+	// Components returns the components YAML of a provider release
+	Components(ctx context.Context, provider, version string) ([]byte, error)
+}
+
+// providerRelease locates the releases of a provider on GitHub
+type providerRelease struct {
+	repository string
+	components string
+}
+
+// knownProviders are the providers the GitHub repository can install
+var knownProviders = map[string]providerRelease{
+	"cluster-api":            {"kubernetes-sigs/cluster-api", "core-components.yaml"},
+	"bootstrap-kubeadm":      {"kubernetes-sigs/cluster-api", "bootstrap-components.yaml"},
+	"control-plane-kubeadm":  {"kubernetes-sigs/cluster-api", "control-plane-components.yaml"},
+	"infrastructure-aws":     {"kubernetes-sigs/cluster-api-provider-aws", "infrastructure-components.yaml"},
+	"infrastructure-azure":   {"kubernetes-sigs/cluster-api-provider-azure", "infrastructure-components.yaml"},
+	"infrastructure-gcp":     {"kubernetes-sigs/cluster-api-provider-gcp", "infrastructure-components.yaml"},
+	"infrastructure-vsphere": {"kubernetes-sigs/cluster-api-provider-vsphere", "infrastructure-components.yaml"},
+}
+
+// GitHubRepository reads provider releases from GitHub, as clusterctl does
+type GitHubRepository struct {
+	// BaseURL serves the release assets, APIURL the releases API and RawURL
+	// the files of repositories. They default to github.com, api.github.com
+	// and raw.githubusercontent.com and may point to a mirror.
+	BaseURL string
+	APIURL  string
+	RawURL  string
+	// Token authenticates to the API, which raises its rate limit
+	Token      string
+	HTTPClient *http.Client
+}
+
+func (r *GitHubRepository) release(provider string) (providerRelease, error) {
+	release, ok := knownProviders[provider]
+	if !ok {
+		return providerRelease{}, errorf(ErrInvalidArgument, "unknown provider %s, supported providers: %s", provider, strings.Join(KnownProviders(), ", "))
+	}
+	return release, nil
+}
+
+// LatestVersion returns the tag of the latest release of a provider
+func (r *GitHubRepository) LatestVersion(ctx context.Context, provider string) (string, error) {
+	release, err := r.release(provider)
+	if err != nil {
+		return "", err
+	}
+	apiURL := r.APIURL
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+	body, err := r.get(ctx, fmt.Sprintf("%s/repos/%s/releases/latest", strings.TrimSuffix(apiURL, "/"), release.repository))
+	if err != nil {
+		return "", err
+	}
+	var latest struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.Unmarshal(body, &latest); err != nil || latest.TagName == "" {
+		return "", fmt.Errorf("failed to read the latest release of %s", provider)
+	}
+	return latest.TagName, nil
+}
+
+// Components downloads the components YAML of a provider release
+func (r *GitHubRepository) Components(ctx context.Context, provider, version string) ([]byte, error) {
+	release, err := r.release(provider)
+	if err != nil {
+		return nil, err
+	}
+	baseURL := r.BaseURL
+	if baseURL == "" {
+		baseURL = "https://github.com"
+	}
+	return r.get(ctx, fmt.Sprintf("%s/%s/releases/download/%s/%s", strings.TrimSuffix(baseURL, "/"), release.repository, version, release.components))
+}
+
+func (r *GitHubRepository) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.Token)
+	}
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errorf(ErrNotFound, "%s not found", url)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxComponentsSize))
+}
+
+// KnownProviders lists the providers the GitHub repository can install
+func KnownProviders() []string {
+	names := make([]string, 0, len(knownProviders))
+	for name := range knownProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProviderChange describes a provider installed, upgraded or planned
+type ProviderChange struct {
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Namespace      string `json:"namespace,omitempty"`
+	CurrentVersion string `json:"currentVersion,omitempty"`
+	TargetVersion  string `json:"targetVersion,omitempty"`
+	// Changed reports whether the provider is, or would be, applied
+	Changed bool `json:"changed"`
+	// Objects counts the component objects applied
+	Objects int    `json:"objects,omitempty"`
+	Note    string `json:"note,omitempty"`
+}
+
+// ProviderOptions configures InstallProviders and UpgradeProviders
+type ProviderOptions struct {
+	// Providers are clusterctl provider names with an optional version, such
+	// as infrastructure-aws:v2.7.1. The latest release is used without one.
+	Providers []string
+	// Variables resolves the ${VAR} placeholders of the components, which
+	// clusterctl reads from the environment
+	Variables func(name string) (string, bool)
+	// DryRun validates the components on the server without persisting them
+	DryRun bool
+}
+
+// ParseProviderSpec splits a provider of the form name[:version]
+func ParseProviderSpec(spec string) (name, providerVersion string, err error) {
+	name, providerVersion, _ = strings.Cut(strings.TrimSpace(spec), ":")
+	if name == "" {
+		return "", "", errorf(ErrInvalidArgument, "provider %q has no name", spec)
+	}
+	if providerVersion != "" {
+		if _, err := version.ParseSemantic(providerVersion); err != nil {
+			return "", "", errorf(ErrInvalidArgument, "invalid version of provider %s: %v", name, err)
+		}
+	}
+	return name, providerVersion, nil
+}
+
+// providerType returns the short name and clusterctl type of a provider name
+func providerType(name string) (string, string) {
+	if name == "cluster-api" {
+		return name, providerTypes[name]
+	}
+	for prefix, providerType := range providerTypes {
+		if rest, ok := strings.CutPrefix(name, prefix+"-"); ok {
+			return rest, providerType
+		}
+	}
+	return name, "Unknown"
+}
+
+// providerOrder installs the core provider before the others, as their
+// controllers and webhooks rely on its CRDs
+var providerOrder = map[string]int{
+	"CoreProvider":           0,
+	"BootstrapProvider":      1,
+	"ControlPlaneProvider":   2,
+	"InfrastructureProvider": 3,
+}
+
+func sortProviderChanges(changes []ProviderChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		oi, ok := providerOrder[changes[i].Type]
+		if !ok {
+			oi = len(providerOrder)
+		}
+		oj, ok := providerOrder[changes[j].Type]
+		if !ok {
+			oj = len(providerOrder)
+		}
+		return oi < oj
+	})
+}
+
+// installedProviderVersions maps the names of installed providers to them
+func (c *Client) installedProviderVersions(ctx context.Context) (map[string]InstalledProvider, error) {
+	installed, err := c.ListInstalledProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]InstalledProvider, len(installed))
+	for _, provider := range installed {
+		byName[provider.Name] = provider
+	}
+	return byName, nil
+}
+
+// InstallProviders installs providers on the management cluster like
+// clusterctl init: the components of each release are fetched from repo,
+// their variables substituted and applied with server-side apply, and the
+// provider is recorded in the clusterctl inventory if it is installed.
+// Providers that are already installed are skipped; use UpgradeProviders to
+// change their version.
+func (c *Client) InstallProviders(ctx context.Context, repo ProviderRepository, opts ProviderOptions) ([]ProviderChange, error) {
+	installed, err := c.installedProviderVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]ProviderChange, 0, len(opts.Providers))
+	for _, spec := range opts.Providers {
+		name, target, err := ParseProviderSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		_, kind := providerType(name)
+		change := ProviderChange{Name: name, Type: kind, TargetVersion: target}
+		if current, ok := installed[name]; ok {
+			change.Namespace, change.CurrentVersion = current.Namespace, current.Version
+			change.Note = "already installed, use the upgrade to change its version"
+		}
+		changes = append(changes, change)
+	}
+	sortProviderChanges(changes)
+
+	for i := range changes {
+		if changes[i].CurrentVersion != "" || changes[i].Note != "" {
+			continue
+		}
+		if err := c.applyProvider(ctx, repo, &changes[i], opts); err != nil {
+			return changes, err
+		}
+	}
+	return changes, nil
+}
+
+// PlanProviderUpgrade compares the versions of installed providers with the
+// latest releases of repo, or with the versions given in providers. Without
+// providers, all installed providers the repository knows are planned.
+func (c *Client) PlanProviderUpgrade(ctx context.Context, repo ProviderRepository, providers []string) ([]ProviderChange, error) {
+	installed, err := c.installedProviderVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]string)
+	if len(providers) == 0 {
+		for name, provider := range installed {
+			if _, ok := knownProviders[name]; ok || provider.Source == ProviderSourceInventory {
+				targets[name] = ""
+			}
+		}
+	}
+	for _, spec := range providers {
+		name, target, err := ParseProviderSpec(spec)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := installed[name]; !ok {
+			return nil, errorf(ErrNotFound, "provider %s is not installed", name)
+		}
+		targets[name] = target
+	}
+
+	changes := make([]ProviderChange, 0, len(targets))
+	for name, target := range targets {
+		provider := installed[name]
+		change := ProviderChange{
+			Name:           name,
+			Type:           provider.Type,
+			Namespace:      provider.Namespace,
+			CurrentVersion: provider.Version,
+			TargetVersion:  target,
+		}
+		if change.TargetVersion == "" {
+			if change.TargetVersion, err = repo.LatestVersion(ctx, name); err != nil {
+				change.Note = fmt.Sprintf("latest version unknown: %v", err)
+				changes = append(changes, change)
+				continue
+			}
+		}
+		change.Changed, change.Note = upgradeNeeded(change.CurrentVersion, change.TargetVersion)
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	sortProviderChanges(changes)
+	return changes, nil
+}
+
+// upgradeNeeded compares an installed version with a target version
+func upgradeNeeded(current, target string) (bool, string) {
+	to, err := version.ParseSemantic(target)
+	if err != nil {
+		return false, fmt.Sprintf("target version %q is not a semantic version", target)
+	}
+	from, err := version.ParseSemantic(current)
+	if err != nil {
+		return true, "installed version unknown"
+	}
+	switch {
+	case from.GreaterThan(to):
+		return false, "cannot downgrade"
+	case !to.GreaterThan(from):
+		return false, "up to date"
+	}
+	return true, ""
+}
+
+// UpgradeProviders upgrades installed providers like clusterctl upgrade
+// apply, applying the components of the planned versions over the installed
+// ones. Objects dropped by a newer release are not deleted.
+func (c *Client) UpgradeProviders(ctx context.Context, repo ProviderRepository, opts ProviderOptions) ([]ProviderChange, error) {
+	changes, err := c.PlanProviderUpgrade(ctx, repo, opts.Providers)
+	if err != nil {
+		return nil, err
+	}
+	for i := range changes {
+		if !changes[i].Changed {
+			continue
+		}
+		if err := c.applyProvider(ctx, repo, &changes[i], opts); err != nil {
+			return changes, err
+		}
+	}
+	return changes, nil
+}
+
+// applyProvider fetches and applies the components of a provider release,
+// filling in the target version, namespace and object count of change
+func (c *Client) applyProvider(ctx context.Context, repo ProviderRepository, change *ProviderChange, opts ProviderOptions) error {
+	if change.TargetVersion == "" {
+		latest, err := repo.LatestVersion(ctx, change.Name)
+		if err != nil {
+			return err
+		}
+		change.TargetVersion = latest
+	}
+	data, err := repo.Components(ctx, change.Name, change.TargetVersion)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the components of %s %s: %w", change.Name, change.TargetVersion, err)
+	}
+	objects, err := parseComponents(data, change.Name, opts.Variables)
+	if err != nil {
+		return err
+	}
+	if err := c.checkComponentKinds(objects); err != nil {
+		return fmt.Errorf("cannot install %s: %w", change.Name, err)
+	}
+
+	applyOpts := []client.PatchOption{client.FieldOwner(providerFieldManager), client.ForceOwnership}
+	if opts.DryRun {
+		applyOpts = append(applyOpts, client.DryRunAll)
+	}
+	for _, obj := range objects {
+		if obj.GetKind() == "Namespace" && change.Namespace == "" {
+			change.Namespace = obj.GetName()
+		}
+		if err := c.ctrlClient.Patch(ctx, obj, client.Apply, applyOpts...); err != nil {
+			return fmt.Errorf("failed to apply %s %s of %s: %w", obj.GetKind(), obj.GetName(), change.Name, err)
+		}
+		change.Objects++
+	}
+	change.Changed = true
+
+	if err := c.recordProvider(ctx, change, applyOpts); err != nil {
+		change.Note = err.Error()
+	}
+	return nil
+}
+
+// recordProvider records an applied provider in the clusterctl inventory
+func (c *Client) recordProvider(ctx context.Context, change *ProviderChange, applyOpts []client.PatchOption) error {
+	providerName, kind := providerType(change.Name)
+	inventory := &unstructured.Unstructured{Object: map[string]any{
+		"providerName": providerName,
+		"type":         kind,
+		"version":      change.TargetVersion,
+	}}
+	inventory.SetAPIVersion(providerInventoryGVK.GroupVersion().String())
+	inventory.SetKind("Provider")
+	inventory.SetNamespace(change.Namespace)
+	inventory.SetName(change.Name)
+	inventory.SetLabels(map[string]string{
+		"clusterctl.cluster.x-k8s.io": "",
+		clusterv1.ProviderNameLabel:   change.Name,
+	})
+	err := c.ctrlClient.Patch(ctx, inventory, client.Apply, applyOpts...)
+	if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+		return errors.New("the clusterctl inventory is not installed, the provider is only known by its deployments")
+	}
+	return err
+}
+
+// checkComponentKinds verifies that the kinds of the components are served,
+// apart from the CRDs the components bring along. Provider webhooks need
+// certificates from cert-manager, which clusterctl would install first.
+func (c *Client) checkComponentKinds(objects []*unstructured.Unstructured) error {
+	crds := make(map[string]bool)
+	for _, obj := range objects {
+		if obj.GetKind() == "CustomResourceDefinition" {
+			group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+			kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+			crds[group+"/"+kind] = true
+		}
+	}
+	for _, obj := range objects {
+		gvk := obj.GroupVersionKind()
+		if crds[gvk.Group+"/"+gvk.Kind] {
+			continue
+		}
+		if _, err := c.ctrlClient.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			if gvk.Group == "cert-manager.io" {
+				return errorf(ErrPreconditionFailed, "cert-manager must be installed first: %s is not served", gvk.Kind)
+			}
+			return errorf(ErrPreconditionFailed, "%s %s is not served", gvk.GroupVersion(), gvk.Kind)
+		}
+	}
+	return nil
+}
+
+// componentOrder applies namespaces and CRDs before the objects using them
+// and webhook configurations after the services they call
+var componentOrder = map[string]int{
+	"Namespace":                      0,
+	"CustomResourceDefinition":       1,
+	"MutatingWebhookConfiguration":   3,
+	"ValidatingWebhookConfiguration": 3,
+}
+
+// parseComponents substitutes the variables of a components YAML and decodes
+// its objects, labeling them with the provider as clusterctl does
+func parseComponents(data []byte, provider string, variables func(string) (string, bool)) ([]*unstructured.Unstructured, error) {
+	data, missing := substituteVariables(data, variables)
+	if len(missing) > 0 {
+		return nil, errorf(ErrInvalidArgument, "provider %s needs the variables %s, set them in the environment of the server", provider, strings.Join(missing, ", "))
+	}
+
+	objects, err := decodeManifest(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the components of %s: %w", provider, err)
+	}
+	for _, obj := range objects {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[clusterv1.ProviderNameLabel] = provider
+		obj.SetLabels(labels)
+	}
+	return objects, nil
+}
+
+// decodeManifest decodes the objects of a multi-document YAML in the order
+// they can be applied in
+func decodeManifest(data []byte) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+
+	order := func(obj *unstructured.Unstructured) int {
+		if o, ok := componentOrder[obj.GetKind()]; ok {
+			return o
+		}
+		return 2
+	}
+	sort.SliceStable(objects, func(i, j int) bool { return order(objects[i]) < order(objects[j]) })
+	return objects, nil
+}
+
+// variablePattern matches ${VAR}, ${VAR:=default} and ${VAR:-default}
+var variablePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::[=-]([^}]*))?\}`)
+
+// substituteVariables replaces the variables of a components YAML, returning
+// the names of the variables that are neither set nor have a default
+func substituteVariables(data []byte, lookup func(string) (string, bool)) ([]byte, []string) {
+	missing := make(map[string]bool)
+	result := variablePattern.ReplaceAllFunc(data, func(match []byte) []byte {
+		groups := variablePattern.FindSubmatch(match)
+		name := string(groups[1])
+		if lookup != nil {
+			if value, ok := lookup(name); ok {
+				return []byte(value)
+			}
+		}
+		if bytes.Contains(match, []byte(":=")) || bytes.Contains(match, []byte(":-")) {
+			return groups[2]
+		}
+		missing[name] = true
+		return match
+	})
+
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return result, names
+}
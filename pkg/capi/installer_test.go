@@ -0,0 +1,218 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// fakeProviderRepository serves releases from memory
+type fakeProviderRepository struct {
+	latest     map[string]string
+	components map[string]string
+}
+
+func (r *fakeProviderRepository) LatestVersion(_ context.Context, provider string) (string, error) {
+	if version, ok := r.latest[provider]; ok {
+		return version, nil
+	}
+	return "", errorf(ErrNotFound, "provider %s not found", provider)
+}
+
+func (r *fakeProviderRepository) Components(_ context.Context, provider, version string) ([]byte, error) {
+	if components, ok := r.components[provider+"@"+version]; ok {
+		return []byte(components), nil
+	}
+	return nil, errorf(ErrNotFound, "release %s of %s not found", version, provider)
+}
+
+const awsComponents = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: capa-controller-manager
+  namespace: ${CAPA_NAMESPACE:=capa-system}
+spec:
+  template:
+    spec:
+      containers:
+      - name: manager
+        env:
+        - name: CREDENTIALS
+          value: ${AWS_B64ENCODED_CREDENTIALS}
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: awsclusters.infrastructure.cluster.x-k8s.io
+spec:
+  group: infrastructure.cluster.x-k8s.io
+  names:
+    kind: AWSCluster
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: capa-system
+`
+
+func newInstallerTestClient(t *testing.T, mapped []schema.GroupVersionKind, applied *[]client.Object, objects ...runtime.Object) *Client {
+	t.Helper()
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, gvk := range mapped {
+		mapper.Add(gvk, meta.RESTScopeRoot)
+	}
+	ctrlClient := fake.NewClientBuilder().WithRESTMapper(mapper).WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			*applied = append(*applied, obj)
+			return nil
+		},
+	}).Build()
+	return &Client{ctrlClient: ctrlClient, k8sClient: k8sfake.NewClientset(objects...)}
+}
+
+func newProviderDeploymentObject(namespace, name, provider, image string) *appsv1.Deployment {
+	replicas := int32(1)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{clusterv1.ProviderNameLabel: provider}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "manager", Image: image}}}},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: 1},
+	}
+}
+
+func TestSubstituteVariables(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		value, ok := map[string]string{"REGION": "eu-west-1"}[name]
+		return value, ok
+	}
+	data, missing := substituteVariables([]byte("${REGION} ${ZONE:=a} ${TIER:-} ${SECRET} ${SECRET} ${REGION:=us-east-1}"), lookup)
+	if got, want := string(data), "eu-west-1 a  ${SECRET} ${SECRET} eu-west-1"; got != want {
+		t.Errorf("substituteVariables() = %q, want %q", got, want)
+	}
+	if len(missing) != 1 || missing[0] != "SECRET" {
+		t.Errorf("missing = %v, want [SECRET]", missing)
+	}
+}
+
+func TestInstallProviders(t *testing.T) {
+	mapped := []schema.GroupVersionKind{
+		{Version: "v1", Kind: "Namespace"},
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+		{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"},
+	}
+	repo := &fakeProviderRepository{
+		latest:     map[string]string{"infrastructure-aws": "v2.7.1"},
+		components: map[string]string{"infrastructure-aws@v2.7.1": awsComponents},
+	}
+	var applied []client.Object
+	c := newInstallerTestClient(t, mapped, &applied,
+		newProviderDeploymentObject("capi-system", "capi-controller-manager", "cluster-api", "registry.k8s.io/cluster-api/cluster-api-controller:v1.10.2"))
+	ctx := context.Background()
+
+	opts := ProviderOptions{Providers: []string{"infrastructure-aws", "cluster-api"}}
+	if _, err := c.InstallProviders(ctx, repo, opts); !errors.Is(err, ErrInvalidArgument) {
+		t.Fatalf("InstallProviders() without AWS_B64ENCODED_CREDENTIALS error = %v, want ErrInvalidArgument", err)
+	}
+
+	opts.Variables = func(name string) (string, bool) { return "c2VjcmV0", name == "AWS_B64ENCODED_CREDENTIALS" }
+	changes, err := c.InstallProviders(ctx, repo, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Name != "cluster-api" {
+		t.Fatalf("changes = %+v, want the core provider first", changes)
+	}
+	if changes[0].Changed || changes[0].CurrentVersion != "v1.10.2" {
+		t.Errorf("core change = %+v, want the installed provider skipped", changes[0])
+	}
+	aws := changes[1]
+	if !aws.Changed || aws.TargetVersion != "v2.7.1" || aws.Namespace != "capa-system" || aws.Objects != 3 {
+		t.Errorf("aws change = %+v", aws)
+	}
+
+	kinds := make([]string, 0, len(applied))
+	for _, obj := range applied {
+		kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+		if obj.GetLabels()[clusterv1.ProviderNameLabel] != "infrastructure-aws" {
+			t.Errorf("%s %s is not labeled with its provider", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName())
+		}
+	}
+	want := []string{"Namespace", "CustomResourceDefinition", "Deployment", "Provider"}
+	if len(kinds) != len(want) {
+		t.Fatalf("applied %v, want %v", kinds, want)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Errorf("applied %v, want %v", kinds, want)
+			break
+		}
+	}
+}
+
+func TestInstallProvidersRequiresCertManager(t *testing.T) {
+	repo := &fakeProviderRepository{components: map[string]string{"cluster-api@v1.11.0": `apiVersion: cert-manager.io/v1
+kind: Certificate
+metadata:
+  name: capi-serving-cert
+  namespace: capi-system
+`}}
+	var applied []client.Object
+	c := newInstallerTestClient(t, nil, &applied)
+
+	_, err := c.InstallProviders(context.Background(), repo, ProviderOptions{Providers: []string{"cluster-api:v1.11.0"}})
+	if !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("InstallProviders() error = %v, want ErrPreconditionFailed", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("applied %d objects, want none", len(applied))
+	}
+}
+
+func TestPlanProviderUpgrade(t *testing.T) {
+	repo := &fakeProviderRepository{latest: map[string]string{"cluster-api": "v1.11.0", "infrastructure-aws": "v2.7.1"}}
+	var applied []client.Object
+	c := newInstallerTestClient(t, nil, &applied,
+		newProviderDeploymentObject("capi-system", "capi-controller-manager", "cluster-api", "registry.k8s.io/cluster-api/cluster-api-controller:v1.10.2"),
+		newProviderDeploymentObject("capa-system", "capa-controller-manager", "infrastructure-aws", "registry.k8s.io/cluster-api-aws/cluster-api-aws-controller:v2.7.1"))
+	ctx := context.Background()
+
+	plan, err := c.PlanProviderUpgrade(ctx, repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 2 {
+		t.Fatalf("plan = %+v, want 2 providers", plan)
+	}
+	if core := plan[0]; core.Name != "cluster-api" || !core.Changed || core.CurrentVersion != "v1.10.2" || core.TargetVersion != "v1.11.0" {
+		t.Errorf("core plan = %+v", core)
+	}
+	if aws := plan[1]; aws.Changed || aws.Note != "up to date" {
+		t.Errorf("aws plan = %+v, want up to date", aws)
+	}
+
+	plan, err = c.PlanProviderUpgrade(ctx, repo, []string{"cluster-api:v1.9.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 1 || plan[0].Changed || plan[0].Note != "cannot downgrade" {
+		t.Errorf("downgrade plan = %+v", plan)
+	}
+
+	if _, err := c.PlanProviderUpgrade(ctx, repo, []string{"infrastructure-azure"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("PlanProviderUpgrade() of a provider that is not installed error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,230 @@
+package capi
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CAPIClient is the part of Client the MCP tool handlers use. Handlers depend
+// on it instead of on Client, so they can be tested against the fake of the
+// capitest package.
+type CAPIClient interface {
+	// Clusters, machines, machine sets, machine deployments and nodes
+	GetCtrlClient() client.Client
+	ListClusters(ctx context.Context, namespace string, listOpts ...ListOption) (*clusterv1.ClusterList, error)
+	GetCluster(ctx context.Context, namespace, name string) (*clusterv1.Cluster, error)
+	ListMachines(ctx context.Context, namespace, clusterName string, listOpts ...ListOption) (*clusterv1.MachineList, error)
+	GetMachine(ctx context.Context, namespace, name string) (*clusterv1.Machine, error)
+	DeleteMachine(ctx context.Context, opts DeleteMachineOptions) error
+	RemediateMachine(ctx context.Context, opts RemediateMachineOptions) error
+	ListMachineDeployments(ctx context.Context, namespace, clusterName string, listOpts ...ListOption) (*clusterv1.MachineDeploymentList, error)
+	GetMachineDeployment(ctx context.Context, namespace, name string) (*clusterv1.MachineDeployment, error)
+	GetKubeconfig(ctx context.Context, namespace, clusterName string) (string, error)
+	PauseCluster(ctx context.Context, namespace, name string) error
+	ResumeCluster(ctx context.Context, namespace, name string) error
+	DeleteCluster(ctx context.Context, namespace, name string) error
+	CreateCluster(ctx context.Context, opts CreateClusterOptions) (*clusterv1.Cluster, error)
+	UpgradeCluster(ctx context.Context, opts UpgradeClusterOptions) error
+	UpdateCluster(ctx context.Context, opts UpdateClusterOptions) (*clusterv1.Cluster, error)
+	MoveCluster(ctx context.Context, opts MoveClusterOptions) (string, error)
+	GetClusterHealth(ctx context.Context, namespace, name string) (*ClusterHealthStatus, error)
+	CreateMachineDeployment(ctx context.Context, opts CreateMachineDeploymentOptions) (*clusterv1.MachineDeployment, error)
+	UpdateMachineDeployment(ctx context.Context, opts UpdateMachineDeploymentOptions) (*clusterv1.MachineDeployment, error)
+	RolloutMachineDeployment(ctx context.Context, opts RolloutMachineDeploymentOptions) error
+	ListMachineSets(ctx context.Context, namespace, clusterName string, listOpts ...ListOption) (*clusterv1.MachineSetList, error)
+	GetMachineSet(ctx context.Context, namespace, name string) (*clusterv1.MachineSet, error)
+	DrainNode(ctx context.Context, opts NodeOperationOptions) error
+	CordonNode(ctx context.Context, opts NodeOperationOptions) error
+	GetNodeStatus(ctx context.Context, opts NodeOperationOptions) (*corev1.Node, error)
+
+	// Cluster status
+	GetClusterStatus(ctx context.Context, namespace, name string) (*ClusterStatus, error)
+	GetClustersStatus(ctx context.Context, namespace string, opts ...ListOption) (*ClusterStatusList, error)
+	GetUpgradeProgress(ctx context.Context, namespace, name, targetVersion string) (*UpgradeProgress, error)
+
+	// Waiting for readiness
+	WaitForClusterReady(ctx context.Context, namespace, name string, opts WaitOptions) error
+	WaitForMachineDeploymentReady(ctx context.Context, namespace, name string, opts WaitOptions) error
+	WaitForControlPlaneReady(ctx context.Context, namespace, clusterName string, opts WaitOptions) error
+
+	// Scaling
+	ScaleCluster(ctx context.Context, namespace, clusterName, target string, replicas int, machineDeploymentName, machinePoolName string) error
+	ScaleMachineDeployment(ctx context.Context, namespace, name string, replicas int32) error
+
+	// Hibernation
+	HibernateCluster(ctx context.Context, namespace, name string, opts HibernateOptions) (*Hibernation, error)
+	WakeCluster(ctx context.Context, namespace, name string) (*Hibernation, error)
+
+	// Machine annotations and deletion protection
+	SetMachineAnnotation(ctx context.Context, opts MachineAnnotationOptions) (*MachineProtection, error)
+	ListMachineProtections(ctx context.Context, namespace, clusterName string) ([]MachineProtection, error)
+
+	// Node reports
+	GetNodeReport(ctx context.Context, namespace, clusterName string) (*NodeReport, error)
+	ListNodePods(ctx context.Context, namespace, clusterName, nodeName string) (*NodePods, error)
+
+	// Upgrade planning
+	PlanUpgrade(ctx context.Context, namespace, name, targetVersion string, upgradeWorkers bool) (*UpgradePlan, error)
+
+	// Cluster configuration validation
+	ValidateClusterConfig(ctx context.Context, config ClusterConfig) *PreflightReport
+
+	// Kubeconfigs
+	RotateKubeconfig(ctx context.Context, namespace, clusterName string) (*KubeconfigRotation, error)
+
+	// Workload cluster credentials
+	IssueWorkloadCredentials(ctx context.Context, namespace, clusterName string, opts CredentialsOptions) (*WorkloadCredentials, error)
+
+	// Cloning
+	CloneCluster(ctx context.Context, opts CloneClusterOptions) (*ClusterClone, error)
+
+	// Pivoting
+	PivotCluster(ctx context.Context, opts PivotOptions) (*Pivot, error)
+
+	// Machine images
+	UpdateMachineImage(ctx context.Context, opts UpdateMachineImageOptions) (*MachineImageUpdate, error)
+
+	// Capacity
+	GetClusterCapacity(ctx context.Context, namespace, clusterName string) (*ClusterCapacity, error)
+	TopNodes(ctx context.Context, namespace, clusterName, sortBy string) ([]NodeUsage, error)
+
+	// Stale resources
+	FindStaleResources(ctx context.Context, namespace, clusterName string, thresholds StaleThresholds) ([]StaleResource, error)
+
+	// Pod disruption budgets
+	AnalyzePDBConflicts(ctx context.Context, namespace, clusterName string, opts PDBAnalysisOptions) (*PDBAnalysis, error)
+
+	// etcd
+	GetEtcdStatus(ctx context.Context, namespace, clusterName string, query bool) (*EtcdStatus, error)
+
+	// API server probes
+	ProbeAPIServer(ctx context.Context, namespace, name string) (*APIServerProbe, error)
+	ProbeAPIServers(ctx context.Context, namespace string) ([]*APIServerProbe, error)
+
+	// Cluster autoscaler
+	GetAutoscalerStatus(ctx context.Context, namespace, clusterName, autoscalerNamespace string) (*AutoscalerStatus, error)
+
+	// Add-ons
+	GetAddonsStatus(ctx context.Context, namespace, clusterName string) (*AddonsReport, error)
+
+	// Apps
+	ListClusterApps(ctx context.Context, namespace, name string) ([]App, error)
+	GetApp(ctx context.Context, namespace, name string) (*App, error)
+
+	// Search
+	Search(ctx context.Context, query SearchQuery) (*SearchResults, error)
+
+	// Fleet summaries
+	GetFleetSummary(ctx context.Context, namespace string, stuckAfter time.Duration) (*FleetSummary, error)
+
+	// Organizations
+	ListOrganizations(ctx context.Context) ([]Organization, error)
+
+	// Namespaces
+	PrepareNamespace(ctx context.Context, opts PrepareNamespaceOptions) (*NamespacePreparation, error)
+
+	// Webhooks
+	CheckWebhooks(ctx context.Context, namespace string, dryRun bool) (*WebhookHealth, error)
+
+	// Controllers
+	GetControllersStatus(ctx context.Context) (*ControllersStatus, error)
+
+	// Change history
+	ChangeHistory() *ChangeHistory
+	RevertChange(ctx context.Context, id string) (*Change, error)
+
+	// Permissions
+	CheckPermissions(ctx context.Context, checks []PermissionCheck) ([]PermissionResult, error)
+
+	// Management cluster information
+	ManagementClusterInfo(ctx context.Context) (*ManagementClusterInfo, error)
+
+	// Infrastructure discovery
+	DiscoverInfrastructure(ctx context.Context) (*InfrastructureDiscovery, error)
+	ListInfrastructureObjects(ctx context.Context, kind, namespace, clusterName string) ([]InfrastructureObject, error)
+	GetInfrastructureObject(ctx context.Context, kind, namespace, name string) (*InfrastructureObject, error)
+
+	// Installed providers
+	ListInstalledProviders(ctx context.Context) ([]InstalledProvider, error)
+
+	// Provider installation
+	InstallProviders(ctx context.Context, repo ProviderRepository, opts ProviderOptions) ([]ProviderChange, error)
+	PlanProviderUpgrade(ctx context.Context, repo ProviderRepository, providers []string) ([]ProviderChange, error)
+	UpgradeProviders(ctx context.Context, repo ProviderRepository, opts ProviderOptions) ([]ProviderChange, error)
+
+	// Provider releases
+	ListReleases(ctx context.Context, provider Provider) ([]Release, error)
+	UpgradeClusterRelease(ctx context.Context, namespace, name, targetRelease string, dryRun bool) (*ReleaseUpgrade, error)
+
+	// Kubernetes versions
+	AvailableVersions(ctx context.Context, query VersionQuery) (*VersionCatalog, error)
+
+	// Cluster templates
+	GenerateCluster(ctx context.Context, repo TemplateRepository, opts GenerateClusterOptions) (*GeneratedCluster, error)
+
+	// Template variables
+	TemplateVariables(ctx context.Context, repo TemplateRepository, opts GenerateClusterOptions) (*TemplateVariables, error)
+
+	// Manifests
+	ApplyManifest(ctx context.Context, opts ApplyManifestOptions) (*ManifestApply, error)
+
+	// GitOps export
+	ExportGitOps(ctx context.Context, opts ExportGitOpsOptions) (*GitOpsExport, error)
+
+	// CNI installation
+	InstallCNI(ctx context.Context, repo CNIRepository, namespace, clusterName string, opts CNIOptions) (*CNIInstallation, error)
+
+	// Backups
+	BackupCluster(ctx context.Context, opts BackupClusterOptions) (string, error)
+
+	// Restores
+	RestoreCluster(ctx context.Context, opts RestoreClusterOptions) (*ClusterRestore, error)
+
+	// Velero
+	CreateVeleroBackup(ctx context.Context, opts VeleroBackupOptions) (*VeleroBackup, error)
+	ListVeleroBackups(ctx context.Context, veleroNamespace, namespace, cluster string) ([]VeleroBackup, error)
+	GetVeleroBackup(ctx context.Context, veleroNamespace, namespace, name string) (*VeleroBackup, error)
+	CreateVeleroRestore(ctx context.Context, opts VeleroRestoreOptions) (*VeleroRestore, error)
+	GetVeleroRestore(ctx context.Context, veleroNamespace, namespace, name string) (*VeleroRestore, error)
+
+	// Provider identities
+	ValidateIdentities(ctx context.Context, provider Provider, namespace string) (*IdentityReport, error)
+
+	// AWS
+	GetAWSCluster(ctx context.Context, namespace, name string) (*AWSClusterInfo, error)
+
+	// AWS machine templates
+	ListAWSMachineTemplates(ctx context.Context, namespace string) ([]AWSMachineTemplateInfo, error)
+	GetAWSMachineTemplate(ctx context.Context, namespace, name string) (*AWSMachineTemplateInfo, error)
+	CreateAWSMachineTemplate(ctx context.Context, namespace, name, clusterName string, opts AWSMachineTemplateOptions) (*AWSMachineTemplateInfo, error)
+	CloneAWSMachineTemplate(ctx context.Context, namespace, source, name string, opts AWSMachineTemplateOptions) (*AWSMachineTemplateInfo, error)
+	DiffAWSMachineTemplates(ctx context.Context, namespace, from, to string) ([]FieldChange, error)
+
+	// AWS spot instances
+	ConfigureAWSSpot(ctx context.Context, opts AWSSpotOptions) (*AWSSpotConfiguration, error)
+
+	// AKS
+	ListAKSClusters(ctx context.Context, namespace string) ([]AKSClusterInfo, error)
+	GetAKSCluster(ctx context.Context, namespace, name string) (*AKSClusterInfo, error)
+
+	// GCP
+	GetGCPCluster(ctx context.Context, namespace, name string) (*GCPClusterInfo, error)
+
+	// vSphere
+	GetVSphereCluster(ctx context.Context, namespace, name string) (*VSphereClusterInfo, error)
+	ListVSphereMachines(ctx context.Context, namespace, clusterName string) ([]VSphereMachineInfo, error)
+
+	// KubeVirt
+	GetKubevirtCluster(ctx context.Context, namespace, name string) (*KubevirtClusterInfo, error)
+	ListKubevirtMachines(ctx context.Context, namespace, clusterName string) (*KubevirtMachinesInfo, error)
+
+	// Metal3
+	GetMetal3Cluster(ctx context.Context, namespace, name string) (*Metal3ClusterInfo, error)
+	ListBareMetalHosts(ctx context.Context, namespace, clusterName string) ([]BareMetalHostInfo, error)
+}
+
+var _ CAPIClient = (*Client)(nil)
@@ -0,0 +1,190 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+const (
+	// ProviderSourceInventory providers are recorded in the clusterctl inventory
+	ProviderSourceInventory = "clusterctl"
+	// ProviderSourceDeployment providers were installed without clusterctl,
+	// e.g. by Helm, and are only known by their controller deployments
+	ProviderSourceDeployment = "deployment"
+)
+
+// providerInventoryGVK is the clusterctl inventory of installed providers
+var providerInventoryGVK = schema.GroupVersionKind{Group: "clusterctl.cluster.x-k8s.io", Version: "v1alpha3", Kind: "ProviderList"}
+
+// providerTypes map the prefix of provider labels to clusterctl provider types
+var providerTypes = map[string]string{
+	"cluster-api":       "CoreProvider",
+	"bootstrap":         "BootstrapProvider",
+	"control-plane":     "ControlPlaneProvider",
+	"infrastructure":    "InfrastructureProvider",
+	"ipam":              "IPAMProvider",
+	"runtime-extension": "RuntimeExtensionProvider",
+	"addon":             "AddonProvider",
+}
+
+// ProviderDeployment is a controller deployment of an installed provider
+type ProviderDeployment struct {
+	Name          string `json:"name"`
+	Replicas      int32  `json:"replicas"`
+	ReadyReplicas int32  `json:"readyReplicas"`
+	Image         string `json:"image,omitempty"`
+}
+
+// InstalledProvider is a Cluster API provider installed on the management cluster
+type InstalledProvider struct {
+	// Name is the clusterctl name of the provider, e.g. infrastructure-aws
+	Name string `json:"name"`
+	// ProviderName is the short name of the provider, e.g. aws
+	ProviderName string `json:"providerName"`
+	Type         string `json:"type"`
+	Version      string `json:"version,omitempty"`
+	Namespace    string `json:"namespace"`
+	// Source tells whether the provider was found in the clusterctl
+	// inventory or only through its controller deployments
+	Source      string               `json:"source"`
+	Deployments []ProviderDeployment `json:"deployments"`
+	Healthy     bool                 `json:"healthy"`
+	Message     string               `json:"message"`
+}
+
+// ListInstalledProviders discovers the providers installed on the management
+// cluster from the clusterctl inventory, completed by the controller
+// deployments labeled with cluster.x-k8s.io/provider. Providers installed
+// without clusterctl are reported from their deployments, with the version
+// taken from the image tag. A provider is healthy when all replicas of its
+// controller deployments are ready.
+func (c *Client) ListInstalledProviders(ctx context.Context) ([]InstalledProvider, error) {
+	inventory, err := c.listUnstructured(ctx, providerInventoryGVK)
+	if err != nil {
+		return nil, err
+	}
+	deployments, err := c.k8sClient.AppsV1().Deployments("").List(ctx, metav1.ListOptions{LabelSelector: clusterv1.ProviderNameLabel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list provider deployments: %w", err)
+	}
+
+	byKey := make(map[string]*InstalledProvider)
+	var providers []*InstalledProvider
+	for _, item := range inventory {
+		provider := newInventoryProvider(&item)
+		byKey[provider.Namespace+"/"+provider.Name] = provider
+		providers = append(providers, provider)
+	}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		name := deployment.Labels[clusterv1.ProviderNameLabel]
+		provider, ok := byKey[deployment.Namespace+"/"+name]
+		if !ok {
+			provider = newDeploymentProvider(name, deployment)
+			byKey[deployment.Namespace+"/"+name] = provider
+			providers = append(providers, provider)
+		}
+		provider.Deployments = append(provider.Deployments, newProviderDeployment(deployment))
+	}
+
+	result := make([]InstalledProvider, 0, len(providers))
+	for _, provider := range providers {
+		provider.Healthy, provider.Message = providerHealth(provider.Deployments)
+		result = append(result, *provider)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Type != result[j].Type {
+			return result[i].Type < result[j].Type
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+// newInventoryProvider reads a clusterctl Provider object
+func newInventoryProvider(item *unstructured.Unstructured) *InstalledProvider {
+	providerName, _, _ := unstructured.NestedString(item.Object, "providerName")
+	providerType, _, _ := unstructured.NestedString(item.Object, "type")
+	version, _, _ := unstructured.NestedString(item.Object, "version")
+	return &InstalledProvider{
+		Name:         item.GetName(),
+		ProviderName: providerName,
+		Type:         providerType,
+		Version:      version,
+		Namespace:    item.GetNamespace(),
+		Source:       ProviderSourceInventory,
+		Deployments:  []ProviderDeployment{},
+	}
+}
+
+// newDeploymentProvider describes a provider known only by a deployment
+// labeled with its clusterctl name, such as infrastructure-aws or cluster-api
+func newDeploymentProvider(name string, deployment *appsv1.Deployment) *InstalledProvider {
+	providerName, kind := providerType(name)
+	provider := &InstalledProvider{
+		Name:         name,
+		ProviderName: providerName,
+		Type:         kind,
+		Namespace:    deployment.Namespace,
+		Source:       ProviderSourceDeployment,
+		Deployments:  []ProviderDeployment{},
+	}
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if version := imageTag(container.Image); strings.HasPrefix(version, "v") {
+			provider.Version = version
+			break
+		}
+	}
+	return provider
+}
+
+// newProviderDeployment summarizes a controller deployment
+func newProviderDeployment(deployment *appsv1.Deployment) ProviderDeployment {
+	summary := ProviderDeployment{
+		Name:          deployment.Name,
+		Replicas:      1,
+		ReadyReplicas: deployment.Status.ReadyReplicas,
+	}
+	if deployment.Spec.Replicas != nil {
+		summary.Replicas = *deployment.Spec.Replicas
+	}
+	if containers := deployment.Spec.Template.Spec.Containers; len(containers) > 0 {
+		summary.Image = containers[0].Image
+	}
+	return summary
+}
+
+// providerHealth reports whether all controller replicas are ready
+func providerHealth(deployments []ProviderDeployment) (bool, string) {
+	if len(deployments) == 0 {
+		return false, "no controller deployment found"
+	}
+	var unready []string
+	for _, deployment := range deployments {
+		if deployment.ReadyReplicas < deployment.Replicas || deployment.Replicas == 0 {
+			unready = append(unready, fmt.Sprintf("%s %d/%d ready", deployment.Name, deployment.ReadyReplicas, deployment.Replicas))
+		}
+	}
+	if len(unready) > 0 {
+		return false, strings.Join(unready, ", ")
+	}
+	return true, "all controller replicas ready"
+}
+
+// imageTag returns the tag of a container image reference
+func imageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	slash := strings.LastIndex(image, "/")
+	if colon := strings.LastIndex(image, ":"); colon > slash {
+		return image[colon+1:]
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+package capi
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestListInstalledProviders(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	inventory := &unstructured.Unstructured{Object: map[string]any{
+		"providerName": "cluster-api",
+		"type":         "CoreProvider",
+		"version":      "v1.10.2",
+	}}
+	inventory.SetAPIVersion("clusterctl.cluster.x-k8s.io/v1alpha3")
+	inventory.SetKind("Provider")
+	inventory.SetNamespace("capi-system")
+	inventory.SetName("cluster-api")
+
+	replicas := int32(1)
+	newDeployment := func(namespace, name, provider, image string, ready int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{clusterv1.ProviderNameLabel: provider}},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "manager", Image: image}}}},
+			},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: ready},
+		}
+	}
+
+	c := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(inventory).Build(),
+		k8sClient: k8sfake.NewClientset(
+			newDeployment("capi-system", "capi-controller-manager", "cluster-api", "registry.k8s.io/cluster-api/cluster-api-controller:v1.10.2", 1),
+			newDeployment("capa-system", "capa-controller-manager", "infrastructure-aws", "gsoci.azurecr.io/giantswarm/cluster-api-aws-controller:v2.7.1", 0),
+		),
+	}
+
+	providers, err := c.ListInstalledProviders(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(providers) != 2 {
+		t.Fatalf("got %d providers, want 2: %+v", len(providers), providers)
+	}
+
+	core := providers[0]
+	if core.Name != "cluster-api" || core.Source != ProviderSourceInventory || core.Version != "v1.10.2" {
+		t.Errorf("core provider = %+v", core)
+	}
+	if !core.Healthy || len(core.Deployments) != 1 {
+		t.Errorf("core provider health = %v with %d deployments, want healthy with 1", core.Healthy, len(core.Deployments))
+	}
+
+	aws := providers[1]
+	if aws.Type != "InfrastructureProvider" || aws.ProviderName != "aws" || aws.Source != ProviderSourceDeployment {
+		t.Errorf("aws provider = %+v", aws)
+	}
+	if aws.Version != "v2.7.1" {
+		t.Errorf("aws version = %q, want v2.7.1 from the image tag", aws.Version)
+	}
+	if aws.Healthy {
+		t.Errorf("aws provider is healthy with no ready replicas: %s", aws.Message)
+	}
+}
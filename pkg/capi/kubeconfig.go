@@ -0,0 +1,246 @@
+package capi
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// KubeconfigInfo describes how a kubeconfig connects, without its credentials
+type KubeconfigInfo struct {
+	CurrentContext string              `json:"currentContext,omitempty"`
+	Contexts       []KubeconfigContext `json:"contexts"`
+	Clusters       []KubeconfigCluster `json:"clusters"`
+	Users          []KubeconfigUser    `json:"users"`
+}
+
+// KubeconfigContext pairs a cluster with a user
+type KubeconfigContext struct {
+	Name      string `json:"name"`
+	Cluster   string `json:"cluster"`
+	User      string `json:"user"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// KubeconfigCluster is an API server of a kubeconfig
+type KubeconfigCluster struct {
+	Name                  string `json:"name"`
+	Server                string `json:"server"`
+	TLSServerName         string `json:"tlsServerName,omitempty"`
+	InsecureSkipTLSVerify bool   `json:"insecureSkipTLSVerify,omitempty"`
+	// CAExpires is the expiry of the certificate authority of the server
+	CAExpires *time.Time `json:"caExpires,omitempty"`
+}
+
+// KubeconfigUser is a user of a kubeconfig, described by how it
+// authenticates
+type KubeconfigUser struct {
+	Name string `json:"name"`
+	// AuthMethod is client certificate, token, exec, auth provider or basic
+	AuthMethod string `json:"authMethod"`
+	// Subject and CertificateExpires describe the client certificate
+	Subject            string     `json:"subject,omitempty"`
+	CertificateExpires *time.Time `json:"certificateExpires,omitempty"`
+}
+
+// ParseKubeconfig describes the contexts, clusters and users of a kubeconfig
+func ParseKubeconfig(data string) (*KubeconfigInfo, error) {
+	config, err := clientcmd.Load([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	info := &KubeconfigInfo{
+		CurrentContext: config.CurrentContext,
+		Contexts:       []KubeconfigContext{},
+		Clusters:       []KubeconfigCluster{},
+		Users:          []KubeconfigUser{},
+	}
+	for name, context := range config.Contexts {
+		info.Contexts = append(info.Contexts, KubeconfigContext{Name: name, Cluster: context.Cluster, User: context.AuthInfo, Namespace: context.Namespace})
+	}
+	for name, cluster := range config.Clusters {
+		info.Clusters = append(info.Clusters, KubeconfigCluster{
+			Name:                  name,
+			Server:                cluster.Server,
+			TLSServerName:         cluster.TLSServerName,
+			InsecureSkipTLSVerify: cluster.InsecureSkipTLSVerify,
+			CAExpires:             certificateExpiry(cluster.CertificateAuthorityData),
+		})
+	}
+	for name, user := range config.AuthInfos {
+		info.Users = append(info.Users, newKubeconfigUser(name, user))
+	}
+	sort.Slice(info.Contexts, func(i, j int) bool { return info.Contexts[i].Name < info.Contexts[j].Name })
+	sort.Slice(info.Clusters, func(i, j int) bool { return info.Clusters[i].Name < info.Clusters[j].Name })
+	sort.Slice(info.Users, func(i, j int) bool { return info.Users[i].Name < info.Users[j].Name })
+	return info, nil
+}
+
+// newKubeconfigUser describes how a kubeconfig user authenticates
+func newKubeconfigUser(name string, user *clientcmdapi.AuthInfo) KubeconfigUser {
+	info := KubeconfigUser{Name: name}
+	switch {
+	case len(user.ClientCertificateData) > 0 || user.ClientCertificate != "":
+		info.AuthMethod = "client certificate"
+		if certs := parseCertificates(user.ClientCertificateData); len(certs) > 0 {
+			info.Subject = certs[0].Subject.String()
+			info.CertificateExpires = &certs[0].NotAfter
+		}
+	case user.Token != "" || user.TokenFile != "":
+		info.AuthMethod = "token"
+	case user.Exec != nil:
+		info.AuthMethod = "exec " + user.Exec.Command
+	case user.AuthProvider != nil:
+		info.AuthMethod = "auth provider " + user.AuthProvider.Name
+	case user.Username != "":
+		info.AuthMethod = "basic"
+	default:
+		info.AuthMethod = "none"
+	}
+	return info
+}
+
+// certificateExpiry returns the expiry of the first certificate of PEM data
+func certificateExpiry(data []byte) *time.Time {
+	if certs := parseCertificates(data); len(certs) > 0 {
+		return &certs[0].NotAfter
+	}
+	return nil
+}
+
+// redactedKeyData is base64 encoded as REDACTED
+var redactedKeyData, _ = base64.StdEncoding.DecodeString("REDACTED")
+
+// RedactKubeconfig masks the client keys, tokens and passwords of a
+// kubeconfig, keeping the certificates and everything else needed to tell
+// where it connects
+func RedactKubeconfig(data string) (string, error) {
+	config, err := clientcmd.Load([]byte(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	if err := clientcmdapi.RedactSecrets(config); err != nil {
+		return "", fmt.Errorf("failed to redact kubeconfig: %w", err)
+	}
+	// Masked data fields are base64 encoded on write, store what encodes to
+	// the placeholder like kubectl config view does
+	for _, user := range config.AuthInfos {
+		if len(user.ClientKeyData) > 0 {
+			user.ClientKeyData = redactedKeyData
+		}
+	}
+	redacted, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode kubeconfig: %w", err)
+	}
+	return string(redacted), nil
+}
+
+// adminCertificateTTL is the validity of rotated admin client certificates,
+// a year like the certificates Cluster API generates
+const adminCertificateTTL = 365 * 24 * time.Hour
+
+// KubeconfigRotation describes a regenerated kubeconfig Secret
+type KubeconfigRotation struct {
+	Secret  string    `json:"secret"`
+	Server  string    `json:"server"`
+	Subject string    `json:"subject"`
+	Expires time.Time `json:"expires"`
+	// PreviousExpires is when the client certificate of the replaced
+	// kubeconfig expires. It cannot be revoked and stays valid until then,
+	// unless the cluster CA is rotated.
+	PreviousExpires *time.Time `json:"previousExpires,omitempty"`
+	// CAChanged is set when the replaced kubeconfig trusted another CA than
+	// the current cluster CA
+	CAChanged bool `json:"caChanged"`
+}
+
+// RotateKubeconfig regenerates the admin kubeconfig of the {cluster}-kubeconfig
+// Secret with a new client certificate signed by the cluster CA, e.g. after
+// the CA was rotated or the kubeconfig leaked. The API server is the control
+// plane endpoint of the Cluster. Workload cluster clients are created from
+// the Secret whenever they are needed, so the new credentials are used right
+// away. The replaced Secret is deliberately not recorded in the change
+// history, reverting would restore the old credentials.
+func (c *Client) RotateKubeconfig(ctx context.Context, namespace, clusterName string) (*KubeconfigRotation, error) {
+	cluster, err := c.GetCluster(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	secretName := clusterName + "-kubeconfig"
+	secret, err := c.k8sClient.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig secret: %w", resourceError("Secret", client.ObjectKey{Namespace: namespace, Name: secretName}, err))
+	}
+	dataKey := "value"
+	if _, ok := secret.Data[dataKey]; !ok {
+		if _, ok := secret.Data["data"]; ok {
+			dataKey = "data"
+		}
+	}
+
+	rotation := &KubeconfigRotation{Secret: namespace + "/" + secretName, Subject: "kubernetes-admin"}
+	var previousCA []byte
+	if previous, err := clientcmd.Load(secret.Data[dataKey]); err == nil {
+		if previousCluster := currentCluster(previous); previousCluster != nil {
+			rotation.Server = previousCluster.Server
+			previousCA = previousCluster.CertificateAuthorityData
+		}
+		for _, user := range previous.AuthInfos {
+			rotation.PreviousExpires = certificateExpiry(user.ClientCertificateData)
+			if rotation.PreviousExpires != nil {
+				break
+			}
+		}
+	}
+	if endpoint := cluster.Spec.ControlPlaneEndpoint; endpoint.IsValid() {
+		rotation.Server = fmt.Sprintf("https://%s", net.JoinHostPort(endpoint.Host, fmt.Sprint(endpoint.Port)))
+	}
+	if rotation.Server == "" {
+		return nil, errorf(ErrPreconditionFailed, "cluster %s/%s has no control plane endpoint yet", namespace, clusterName)
+	}
+
+	ca, err := c.getClusterCA(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	rotation.CAChanged = previousCA != nil && !bytes.Equal(bytes.TrimSpace(previousCA), bytes.TrimSpace(ca.pem))
+	user, expires, err := ca.signClientCertificate(rotation.Subject, defaultCredentialGroups, adminCertificateTTL)
+	if err != nil {
+		return nil, err
+	}
+	rotation.Expires = expires
+
+	// Named like the kubeconfigs Cluster API generates
+	userName := clusterName + "-admin"
+	contextName := userName + "@" + clusterName
+	config := clientcmdapi.NewConfig()
+	config.Clusters[clusterName] = &clientcmdapi.Cluster{Server: rotation.Server, CertificateAuthorityData: ca.pem}
+	config.AuthInfos[userName] = user
+	config.Contexts[contextName] = &clientcmdapi.Context{Cluster: clusterName, AuthInfo: userName}
+	config.CurrentContext = contextName
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode kubeconfig: %w", err)
+	}
+
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[dataKey] = data
+	if _, err := c.k8sClient.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to update kubeconfig secret: %w", resourceError("Secret", client.ObjectKey{Namespace: namespace, Name: secretName}, err))
+	}
+	return rotation, nil
+}
@@ -0,0 +1,140 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestKubeconfig(t *testing.T) {
+	expires := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+	ca, cert := newTestCertificates(t, "kubernetes-admin", expires)
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters["prod"] = &clientcmdapi.Cluster{Server: "https://prod.example.com:6443", CertificateAuthorityData: ca}
+	config.AuthInfos["prod-admin"] = &clientcmdapi.AuthInfo{ClientCertificateData: cert, ClientKeyData: []byte("private-key")}
+	config.AuthInfos["robot"] = &clientcmdapi.AuthInfo{Token: "s3cr3t"}
+	config.Contexts["prod-admin@prod"] = &clientcmdapi.Context{Cluster: "prod", AuthInfo: "prod-admin"}
+	config.CurrentContext = "prod-admin@prod"
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := ParseKubeconfig(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.CurrentContext != "prod-admin@prod" || len(info.Contexts) != 1 || info.Contexts[0].User != "prod-admin" {
+		t.Errorf("contexts = %q %+v", info.CurrentContext, info.Contexts)
+	}
+	if len(info.Clusters) != 1 || info.Clusters[0].Server != "https://prod.example.com:6443" || info.Clusters[0].CAExpires == nil {
+		t.Errorf("clusters = %+v, want prod with its CA expiry", info.Clusters)
+	}
+	if len(info.Users) != 2 {
+		t.Fatalf("users = %+v, want 2", info.Users)
+	}
+	if admin := info.Users[0]; admin.AuthMethod != "client certificate" || admin.Subject != "CN=kubernetes-admin" || !admin.CertificateExpires.Equal(expires) {
+		t.Errorf("admin = %+v, want the client certificate described", admin)
+	}
+	if robot := info.Users[1]; robot.AuthMethod != "token" {
+		t.Errorf("robot = %+v, want token", robot)
+	}
+
+	redacted, err := RedactKubeconfig(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"s3cr3t", "cHJpdmF0ZS1rZXk="} {
+		if strings.Contains(redacted, secret) {
+			t.Errorf("redacted kubeconfig still contains %s:\n%s", secret, redacted)
+		}
+	}
+	for _, kept := range []string{"client-key-data: REDACTED", "token: REDACTED", "server: https://prod.example.com:6443"} {
+		if !strings.Contains(redacted, kept) {
+			t.Errorf("redacted kubeconfig does not contain %q:\n%s", kept, redacted)
+		}
+	}
+
+	if _, err := ParseKubeconfig("not: [a kubeconfig"); err == nil {
+		t.Error("ParseKubeconfig() succeeded for invalid YAML")
+	}
+}
+
+func TestRotateKubeconfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	oldCA, _ := newTestCA(t)
+	caPEM, caKeyPEM := newTestCA(t)
+	expires := time.Now().Add(90 * 24 * time.Hour).Truncate(time.Second)
+	_, oldCert := newTestCertificates(t, "kubernetes-admin", expires)
+
+	old := clientcmdapi.NewConfig()
+	old.Clusters["prod"] = &clientcmdapi.Cluster{Server: "https://10.0.0.1:6443", CertificateAuthorityData: oldCA}
+	old.AuthInfos["prod-admin"] = &clientcmdapi.AuthInfo{ClientCertificateData: oldCert, ClientKeyData: []byte("leaked")}
+	old.Contexts["prod-admin@prod"] = &clientcmdapi.Context{Cluster: "prod", AuthInfo: "prod-admin"}
+	old.CurrentContext = "prod-admin@prod"
+	oldData, err := clientcmd.Write(*old)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k8sClient := k8sfake.NewClientset(
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-kubeconfig", Labels: map[string]string{clusterv1.ClusterNameLabel: "prod"}}, Data: map[string][]byte{"value": oldData}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-ca"}, Data: map[string][]byte{corev1.TLSCertKey: caPEM, corev1.TLSPrivateKeyKey: caKeyPEM}},
+	)
+	c := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(&clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod"},
+			Spec:       clusterv1.ClusterSpec{ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "prod.example.com", Port: 6443}},
+		}).Build(),
+		k8sClient: k8sClient,
+	}
+
+	rotation, err := c.RotateKubeconfig(context.Background(), "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotation.Server != "https://prod.example.com:6443" || !rotation.CAChanged || rotation.PreviousExpires == nil || !rotation.PreviousExpires.Equal(expires) {
+		t.Errorf("rotation = %+v", rotation)
+	}
+
+	kubeconfig, err := c.GetKubeconfig(context.Background(), "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(kubeconfig, "bGVha2Vk") {
+		t.Error("rotated kubeconfig still holds the old key")
+	}
+	info, err := ParseKubeconfig(kubeconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.CurrentContext != "prod-admin@prod" || info.Clusters[0].Server != rotation.Server || info.Users[0].Subject != "CN=kubernetes-admin,O=system:masters" {
+		t.Errorf("rotated kubeconfig = %+v", info)
+	}
+	secret, err := k8sClient.CoreV1().Secrets("org-acme").Get(context.Background(), "prod-kubeconfig", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Labels[clusterv1.ClusterNameLabel] != "prod" {
+		t.Errorf("rotation dropped the labels of the Secret: %v", secret.Labels)
+	}
+
+	if _, err := c.RotateKubeconfig(context.Background(), "org-acme", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RotateKubeconfig(missing) error = %v, want ErrNotFound", err)
+	}
+}
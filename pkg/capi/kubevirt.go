@@ -0,0 +1,280 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// kubevirtMachineTemplateGVK is the machine template of the KubeVirt provider
+	kubevirtMachineTemplateGVK = schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1alpha1", Kind: "KubevirtMachineTemplate"}
+	// kubevirtMachineGVK is the infrastructure machine of the KubeVirt provider
+	kubevirtMachineGVK = schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1alpha1", Kind: "KubevirtMachine"}
+	// virtualMachineInstanceGVK is a running KubeVirt virtual machine
+	virtualMachineInstanceGVK = schema.GroupVersionKind{Group: "kubevirt.io", Version: "v1", Kind: "VirtualMachineInstance"}
+)
+
+// KubevirtMachineTemplateInfo summarizes the virtual machine of a
+// KubevirtMachineTemplate
+type KubevirtMachineTemplateInfo struct {
+	Name     string `json:"name"`
+	CPUCores int64  `json:"cpuCores,omitempty"`
+	Memory   string `json:"memory,omitempty"`
+	// BootImage is the container disk image or the source of the boot data
+	// volume
+	BootImage string `json:"bootImage,omitempty"`
+	// UsedBy lists the MachineDeployments and KubeadmControlPlanes of the
+	// cluster referencing the template
+	UsedBy []string `json:"usedBy"`
+}
+
+// KubevirtClusterInfo is the KubeVirt infrastructure of a cluster
+type KubevirtClusterInfo struct {
+	Name                 string `json:"name"`
+	ControlPlaneEndpoint string `json:"controlPlaneEndpoint,omitempty"`
+	// ServiceType is the type of the Service exposing the API server in the
+	// infra cluster
+	ServiceType string `json:"serviceType,omitempty"`
+	// InfraClusterSecret names the kubeconfig Secret of an external infra
+	// cluster; empty when the virtual machines run in the management cluster
+	InfraClusterSecret string                        `json:"infraClusterSecret,omitempty"`
+	Ready              bool                          `json:"ready"`
+	FailureMessage     string                        `json:"failureMessage,omitempty"`
+	MachineTemplates   []KubevirtMachineTemplateInfo `json:"machineTemplates"`
+}
+
+// KubevirtMachineInfo correlates a CAPI Machine with its KubevirtMachine and
+// the VirtualMachineInstance backing it. VMI fields are empty while the
+// instance does not exist and when it runs in an external infra cluster.
+type KubevirtMachineInfo struct {
+	Name         string `json:"name"`
+	Machine      string `json:"machine,omitempty"`
+	Node         string `json:"node,omitempty"`
+	Phase        string `json:"phase,omitempty"`
+	ControlPlane bool   `json:"controlPlane"`
+	Ready        bool   `json:"ready"`
+	// VMNamespace is the namespace of the virtual machine in the infra cluster
+	VMNamespace string `json:"vmNamespace"`
+	VMI         string `json:"vmi,omitempty"`
+	VMIPhase    string `json:"vmiPhase,omitempty"`
+	// InfraNode is the node of the infra cluster running the instance
+	InfraNode     string   `json:"infraNode,omitempty"`
+	Addresses     []string `json:"addresses,omitempty"`
+	FailureReason string   `json:"failureReason,omitempty"`
+}
+
+// KubevirtMachinesInfo lists the machines of a KubeVirt cluster
+type KubevirtMachinesInfo struct {
+	// InfraClusterSecret is set when the virtual machines run in an external
+	// infra cluster, whose VirtualMachineInstances are not read
+	InfraClusterSecret string                `json:"infraClusterSecret,omitempty"`
+	Machines           []KubevirtMachineInfo `json:"machines"`
+}
+
+// isKubevirtCluster reports whether a cluster runs on the KubeVirt provider
+func isKubevirtCluster(cluster *clusterv1.Cluster) bool {
+	return ClusterProvider(cluster) == ProviderKubevirt
+}
+
+// GetKubevirtCluster reads the KubevirtCluster of a cluster and the
+// KubevirtMachineTemplates its machines are created from
+func (c *Client) GetKubevirtCluster(ctx context.Context, namespace, name string) (*KubevirtClusterInfo, error) {
+	cluster, infra, err := c.getKubevirtCluster(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	obj := infra.Object
+	info := &KubevirtClusterInfo{Name: infra.GetName(), MachineTemplates: []KubevirtMachineTemplateInfo{}}
+	info.Ready, _, _ = unstructured.NestedBool(obj, "status", "ready")
+	info.FailureMessage, _, _ = unstructured.NestedString(obj, "status", "failureMessage")
+	info.ServiceType, _, _ = unstructured.NestedString(obj, "spec", "controlPlaneServiceTemplate", "spec", "type")
+	info.InfraClusterSecret, _, _ = unstructured.NestedString(obj, "spec", "infraClusterSecretRef", "name")
+	if host, _, _ := unstructured.NestedString(obj, "spec", "controlPlaneEndpoint", "host"); host != "" {
+		port, _, _ := unstructured.NestedInt64(obj, "spec", "controlPlaneEndpoint", "port")
+		info.ControlPlaneEndpoint = fmt.Sprintf("%s:%d", host, port)
+	}
+
+	usedBy, err := c.clusterTemplateUsers(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := c.listUnstructured(ctx, kubevirtMachineTemplateGVK, client.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		template := &templates[i]
+		users := usedBy[template.GetName()]
+		if users == nil && template.GetLabels()[clusterv1.ClusterNameLabel] != name {
+			continue
+		}
+		info.MachineTemplates = append(info.MachineTemplates, newKubevirtMachineTemplateInfo(template, users))
+	}
+	sort.Slice(info.MachineTemplates, func(i, j int) bool { return info.MachineTemplates[i].Name < info.MachineTemplates[j].Name })
+	return info, nil
+}
+
+// ListKubevirtMachines lists the machines of a KubeVirt cluster with their
+// KubevirtMachine and VirtualMachineInstance
+func (c *Client) ListKubevirtMachines(ctx context.Context, namespace, clusterName string) (*KubevirtMachinesInfo, error) {
+	_, infra, err := c.getKubevirtCluster(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	result := &KubevirtMachinesInfo{Machines: []KubevirtMachineInfo{}}
+	result.InfraClusterSecret, _, _ = unstructured.NestedString(infra.Object, "spec", "infraClusterSecretRef", "name")
+
+	machines, err := c.ListMachines(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	selector := client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName}
+	kubevirtMachines, err := c.listUnstructured(ctx, kubevirtMachineGVK, client.InNamespace(namespace), selector)
+	if err != nil {
+		return nil, err
+	}
+	machinesByInfra := make(map[string]*clusterv1.Machine, len(machines.Items))
+	for i := range machines.Items {
+		if ref := machines.Items[i].Spec.InfrastructureRef; ref.Kind == kubevirtMachineGVK.Kind {
+			machinesByInfra[ref.Name] = &machines.Items[i]
+		}
+	}
+
+	// The provider names the virtual machine after the KubevirtMachine and
+	// creates it in the namespace of the VM template, defaulting to the one of
+	// the cluster
+	vmis := make(map[string]*unstructured.Unstructured)
+	listed := make(map[string]bool)
+	for i := range kubevirtMachines {
+		obj := kubevirtMachines[i].Object
+		info := KubevirtMachineInfo{Name: kubevirtMachines[i].GetName(), VMNamespace: namespace}
+		if vmNamespace, _, _ := unstructured.NestedString(obj, "spec", "virtualMachineTemplate", "metadata", "namespace"); vmNamespace != "" {
+			info.VMNamespace = vmNamespace
+		}
+		info.Ready, _, _ = unstructured.NestedBool(obj, "status", "ready")
+		info.FailureReason, _, _ = unstructured.NestedString(obj, "status", "failureReason")
+		if _, ok := kubevirtMachines[i].GetLabels()[clusterv1.MachineControlPlaneLabel]; ok {
+			info.ControlPlane = true
+		}
+		if machine := machinesByInfra[info.Name]; machine != nil {
+			info.Machine = machine.Name
+			info.Phase = GetMachinePhase(machine)
+			if machine.Status.NodeRef != nil {
+				info.Node = machine.Status.NodeRef.Name
+			}
+			_, info.ControlPlane = machine.Labels[clusterv1.MachineControlPlaneLabel]
+		}
+
+		if result.InfraClusterSecret == "" {
+			if !listed[info.VMNamespace] {
+				items, err := c.listUnstructured(ctx, virtualMachineInstanceGVK, client.InNamespace(info.VMNamespace))
+				if err != nil {
+					return nil, err
+				}
+				for j := range items {
+					vmis[items[j].GetNamespace()+"/"+items[j].GetName()] = &items[j]
+				}
+				listed[info.VMNamespace] = true
+			}
+			if vmi := vmis[info.VMNamespace+"/"+info.Name]; vmi != nil {
+				readVirtualMachineInstance(&info, vmi)
+			}
+		}
+		result.Machines = append(result.Machines, info)
+	}
+	sort.Slice(result.Machines, func(i, j int) bool { return result.Machines[i].Name < result.Machines[j].Name })
+	return result, nil
+}
+
+// getKubevirtCluster reads a cluster and its KubevirtCluster
+func (c *Client) getKubevirtCluster(ctx context.Context, namespace, name string) (*clusterv1.Cluster, *unstructured.Unstructured, error) {
+	cluster, err := c.GetCluster(ctx, namespace, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isKubevirtCluster(cluster) {
+		return nil, nil, errorf(ErrInvalidArgument, "cluster %s/%s is not a KubeVirt cluster", namespace, name)
+	}
+	ref := cluster.Spec.InfrastructureRef
+	infra, err := c.getReferenced(ctx, namespace, ref.APIVersion, ref.Kind, ref.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cluster, infra, nil
+}
+
+// clusterTemplateUsers maps the infrastructure machine templates of a cluster,
+// keyed by name, to its MachineDeployments and KubeadmControlPlane using them
+func (c *Client) clusterTemplateUsers(ctx context.Context, cluster *clusterv1.Cluster) (map[string][]string, error) {
+	mds, err := c.ListMachineDeployments(ctx, cluster.Namespace, cluster.Name)
+	if err != nil {
+		return nil, err
+	}
+	usedBy := make(map[string][]string)
+	for _, md := range mds.Items {
+		ref := md.Spec.Template.Spec.InfrastructureRef
+		usedBy[ref.Name] = append(usedBy[ref.Name], "MachineDeployment/"+md.Name)
+	}
+	if ref := cluster.Spec.ControlPlaneRef; ref != nil && ref.Kind == "KubeadmControlPlane" {
+		kcp, err := c.GetKubeadmControlPlane(ctx, cluster.Namespace, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		name := kcp.Spec.MachineTemplate.InfrastructureRef.Name
+		usedBy[name] = append(usedBy[name], "KubeadmControlPlane/"+kcp.Name)
+	}
+	return usedBy, nil
+}
+
+// newKubevirtMachineTemplateInfo summarizes a KubevirtMachineTemplate
+func newKubevirtMachineTemplateInfo(template *unstructured.Unstructured, usedBy []string) KubevirtMachineTemplateInfo {
+	info := KubevirtMachineTemplateInfo{Name: template.GetName(), UsedBy: usedBy}
+	if info.UsedBy == nil {
+		info.UsedBy = []string{}
+	}
+	vm, _, _ := unstructured.NestedMap(template.Object, "spec", "template", "spec", "virtualMachineTemplate", "spec")
+	info.CPUCores, _, _ = unstructured.NestedInt64(vm, "template", "spec", "domain", "cpu", "cores")
+	info.Memory, _, _ = unstructured.NestedString(vm, "template", "spec", "domain", "memory", "guest")
+	if info.Memory == "" {
+		info.Memory, _, _ = unstructured.NestedString(vm, "template", "spec", "domain", "resources", "requests", "memory")
+	}
+	volumes, _, _ := unstructured.NestedSlice(vm, "template", "spec", "volumes")
+	for _, item := range volumes {
+		volume, _ := item.(map[string]any)
+		if image, _, _ := unstructured.NestedString(volume, "containerDisk", "image"); image != "" {
+			info.BootImage = image
+			return info
+		}
+	}
+	dataVolumes, _, _ := unstructured.NestedSlice(vm, "dataVolumeTemplates")
+	for _, item := range dataVolumes {
+		dataVolume, _ := item.(map[string]any)
+		for _, source := range []string{"registry", "http"} {
+			if url, _, _ := unstructured.NestedString(dataVolume, "spec", "source", source, "url"); url != "" {
+				info.BootImage = url
+				return info
+			}
+		}
+	}
+	return info
+}
+
+// readVirtualMachineInstance fills info from a VirtualMachineInstance
+func readVirtualMachineInstance(info *KubevirtMachineInfo, vmi *unstructured.Unstructured) {
+	info.VMI = vmi.GetName()
+	info.VMIPhase, _, _ = unstructured.NestedString(vmi.Object, "status", "phase")
+	info.InfraNode, _, _ = unstructured.NestedString(vmi.Object, "status", "nodeName")
+	interfaces, _, _ := unstructured.NestedSlice(vmi.Object, "status", "interfaces")
+	for _, item := range interfaces {
+		iface, _ := item.(map[string]any)
+		if address, _, _ := unstructured.NestedString(iface, "ipAddress"); address != "" {
+			info.Addresses = append(info.Addresses, address)
+		}
+	}
+}
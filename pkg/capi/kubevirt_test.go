@@ -0,0 +1,147 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestKubevirtCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	newCluster := func(name, infraKind string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: name},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha1", Kind: infraKind, Name: name},
+			},
+		}
+	}
+	newKubevirtCluster := func(name string, spec map[string]any) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]any{"spec": spec, "status": map[string]any{"ready": true}}}
+		obj.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1alpha1")
+		obj.SetKind("KubevirtCluster")
+		obj.SetNamespace("org-a")
+		obj.SetName(name)
+		return obj
+	}
+	labels := map[string]string{clusterv1.ClusterNameLabel: "virt"}
+
+	template := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"template": map[string]any{"spec": map[string]any{"virtualMachineTemplate": map[string]any{"spec": map[string]any{
+			"template": map[string]any{"spec": map[string]any{
+				"domain":  map[string]any{"cpu": map[string]any{"cores": int64(2)}, "memory": map[string]any{"guest": "4Gi"}},
+				"volumes": []any{map[string]any{"name": "containervolume", "containerDisk": map[string]any{"image": "quay.io/capk/ubuntu-2204-container-disk:v1.30.1"}}},
+			}},
+		}}}}},
+	}}
+	template.SetGroupVersionKind(kubevirtMachineTemplateGVK)
+	template.SetNamespace("org-a")
+	template.SetName("virt-md-0")
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: "virt-md-0", Labels: labels},
+		Spec: clusterv1.MachineDeploymentSpec{
+			ClusterName: "virt",
+			Template: clusterv1.MachineTemplateSpec{Spec: clusterv1.MachineSpec{
+				ClusterName:       "virt",
+				InfrastructureRef: corev1.ObjectReference{Kind: "KubevirtMachineTemplate", Name: "virt-md-0"},
+			}},
+		},
+	}
+
+	newKubevirtMachine := func(name string, ready bool) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]any{"status": map[string]any{"ready": ready}}}
+		obj.SetGroupVersionKind(kubevirtMachineGVK)
+		obj.SetNamespace("org-a")
+		obj.SetName(name)
+		obj.SetLabels(labels)
+		return obj
+	}
+	vmi := &unstructured.Unstructured{Object: map[string]any{
+		"status": map[string]any{
+			"phase":      "Running",
+			"nodeName":   "infra-node-3",
+			"interfaces": []any{map[string]any{"name": "default", "ipAddress": "10.244.1.17"}},
+		},
+	}}
+	vmi.SetGroupVersionKind(virtualMachineInstanceGVK)
+	vmi.SetNamespace("org-a")
+	vmi.SetName("virt-md-0-abc")
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: "virt-md-0-abc-x", Labels: labels},
+		Spec: clusterv1.MachineSpec{
+			ClusterName:       "virt",
+			InfrastructureRef: corev1.ObjectReference{Kind: "KubevirtMachine", Name: "virt-md-0-abc"},
+		},
+		Status: clusterv1.MachineStatus{Phase: "Running", NodeRef: &corev1.ObjectReference{Name: "virt-md-0-abc"}},
+	}
+
+	objects := []client.Object{
+		newCluster("virt", "KubevirtCluster"),
+		newKubevirtCluster("virt", map[string]any{
+			"controlPlaneEndpoint":        map[string]any{"host": "virt-lb.org-a.svc", "port": int64(6443)},
+			"controlPlaneServiceTemplate": map[string]any{"spec": map[string]any{"type": "LoadBalancer"}},
+		}),
+		newCluster("remote", "KubevirtCluster"),
+		newKubevirtCluster("remote", map[string]any{"infraClusterSecretRef": map[string]any{"kind": "Secret", "name": "infra-kubeconfig"}}),
+		newCluster("aws", "AWSCluster"),
+		template, md, machine, vmi, newKubevirtMachine("virt-md-0-abc", true), newKubevirtMachine("virt-md-0-def", false),
+	}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()}
+	ctx := context.Background()
+
+	info, err := c.GetKubevirtCluster(ctx, "org-a", "virt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ControlPlaneEndpoint != "virt-lb.org-a.svc:6443" || info.ServiceType != "LoadBalancer" || info.InfraClusterSecret != "" || !info.Ready {
+		t.Errorf("info = %+v", info)
+	}
+	if len(info.MachineTemplates) != 1 {
+		t.Fatalf("machine templates = %+v, want 1", info.MachineTemplates)
+	}
+	if tmpl := info.MachineTemplates[0]; tmpl.CPUCores != 2 || tmpl.Memory != "4Gi" || tmpl.BootImage != "quay.io/capk/ubuntu-2204-container-disk:v1.30.1" || len(tmpl.UsedBy) != 1 {
+		t.Errorf("machine template = %+v", tmpl)
+	}
+
+	machines, err := c.ListKubevirtMachines(ctx, "org-a", "virt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(machines.Machines) != 2 {
+		t.Fatalf("machines = %+v, want 2", machines.Machines)
+	}
+	running := machines.Machines[0]
+	if running.Machine != "virt-md-0-abc-x" || running.Node != "virt-md-0-abc" || running.VMI != "virt-md-0-abc" || running.VMIPhase != "Running" || running.InfraNode != "infra-node-3" {
+		t.Errorf("running machine = %+v", running)
+	}
+	if len(running.Addresses) != 1 || running.Addresses[0] != "10.244.1.17" {
+		t.Errorf("addresses = %v", running.Addresses)
+	}
+	if pending := machines.Machines[1]; pending.Machine != "" || pending.VMI != "" || pending.Ready {
+		t.Errorf("pending machine = %+v, want no Machine nor VMI", pending)
+	}
+
+	remote, err := c.ListKubevirtMachines(ctx, "org-a", "remote")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remote.InfraClusterSecret != "infra-kubeconfig" {
+		t.Errorf("remote infra cluster secret = %q", remote.InfraClusterSecret)
+	}
+
+	if _, err := c.GetKubevirtCluster(ctx, "org-a", "aws"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GetKubevirtCluster() of an AWS cluster error = %v, want ErrInvalidArgument", err)
+	}
+}
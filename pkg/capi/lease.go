@@ -0,0 +1,182 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OperationLeaseAnnotation records the operation holding an operation lease
+const OperationLeaseAnnotation = "mcp-capi.giantswarm.io/operation"
+
+// operationLeasePrefix prefixes the names of operation leases, which are
+// followed by the cluster name
+const operationLeasePrefix = "mcp-capi-operation-"
+
+// OperationLease is a Lease in the namespace of a cluster held while an
+// operation changes the cluster, so server replicas sharing a management
+// cluster do not change the same cluster at once. It is renewed until
+// released; a lease of a holder that stopped renewing it expires after its
+// duration and can be taken over.
+type OperationLease struct {
+	Namespace string
+	Name      string
+	Holder    string
+	Operation string
+
+	c        *Client
+	duration time.Duration
+	stop     context.CancelFunc
+	renewing sync.WaitGroup
+}
+
+// OperationLeaseName returns the name of the operation lease of a cluster
+func OperationLeaseName(clusterName string) string {
+	return operationLeasePrefix + clusterName
+}
+
+// AcquireOperationLease takes the operation lease of a cluster for holder.
+// It fails with an error matching ErrOperationInProgress while another
+// holder's lease has not expired.
+func (c *Client) AcquireOperationLease(ctx context.Context, namespace, clusterName, holder, operation string, duration time.Duration) (*OperationLease, error) {
+	if holder == "" || duration < time.Second {
+		return nil, errorf(ErrInvalidArgument, "an operation lease needs a holder and a duration of at least 1s")
+	}
+	leases := c.k8sClient.CoordinationV1().Leases(namespace)
+	key := client.ObjectKey{Namespace: namespace, Name: OperationLeaseName(clusterName)}
+	now := metav1.NewMicroTime(time.Now())
+	seconds := int32(duration / time.Second)
+
+	lease, err := leases.Get(ctx, key.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        key.Name,
+				Namespace:   namespace,
+				Labels:      map[string]string{clusterv1.ClusterNameLabel: clusterName},
+				Annotations: map[string]string{OperationLeaseAnnotation: operation},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &holder,
+				LeaseDurationSeconds: &seconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		if _, err := leases.Create(ctx, lease, metav1.CreateOptions{}); err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				return nil, errorf(ErrOperationInProgress, "operation in progress on cluster %s/%s: its lease was just taken", namespace, clusterName)
+			}
+			return nil, fmt.Errorf("failed to create operation lease %s/%s: %w", namespace, key.Name, resourceError("Lease", key, err))
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to get operation lease %s/%s: %w", namespace, key.Name, resourceError("Lease", key, err))
+	default:
+		if leaseHeld(lease, holder, now.Time) {
+			return nil, errorf(ErrOperationInProgress, "operation in progress on cluster %s/%s: %s by %s since %s",
+				namespace, clusterName, leaseOperation(lease), *lease.Spec.HolderIdentity, lease.Spec.AcquireTime.UTC().Format(time.RFC3339))
+		}
+		if lease.Annotations == nil {
+			lease.Annotations = map[string]string{}
+		}
+		lease.Annotations[OperationLeaseAnnotation] = operation
+		lease.Spec.HolderIdentity = &holder
+		lease.Spec.LeaseDurationSeconds = &seconds
+		lease.Spec.AcquireTime = &now
+		lease.Spec.RenewTime = &now
+		if _, err := leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+			if apierrors.IsConflict(err) {
+				return nil, errorf(ErrOperationInProgress, "operation in progress on cluster %s/%s: its lease was just taken", namespace, clusterName)
+			}
+			return nil, fmt.Errorf("failed to take over operation lease %s/%s: %w", namespace, key.Name, resourceError("Lease", key, err))
+		}
+	}
+
+	renewCtx, stop := context.WithCancel(context.Background())
+	l := &OperationLease{Namespace: namespace, Name: key.Name, Holder: holder, Operation: operation, c: c, duration: duration, stop: stop}
+	l.renewing.Add(1)
+	go l.renew(renewCtx)
+	return l, nil
+}
+
+// leaseHeld tells whether a lease is held by another holder and has not
+// expired at now
+func leaseHeld(lease *coordinationv1.Lease, holder string, now time.Time) bool {
+	spec := lease.Spec
+	if spec.HolderIdentity == nil || *spec.HolderIdentity == "" || *spec.HolderIdentity == holder {
+		return false
+	}
+	if spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+		return false
+	}
+	return now.Before(spec.RenewTime.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second))
+}
+
+// leaseOperation returns the operation recorded on a lease
+func leaseOperation(lease *coordinationv1.Lease) string {
+	if operation := lease.Annotations[OperationLeaseAnnotation]; operation != "" {
+		return operation
+	}
+	return "an operation"
+}
+
+// renew keeps the lease from expiring until ctx ends
+func (l *OperationLease) renew(ctx context.Context) {
+	defer l.renewing.Done()
+	ticker := time.NewTicker(l.duration / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		leases := l.c.k8sClient.CoordinationV1().Leases(l.Namespace)
+		lease, err := leases.Get(ctx, l.Name, metav1.GetOptions{})
+		if err == nil && (lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != l.Holder) {
+			slog.Warn("Operation lease was taken over", "lease", l.Namespace+"/"+l.Name, "operation", l.Operation)
+			return
+		}
+		if err == nil {
+			now := metav1.NewMicroTime(time.Now())
+			lease.Spec.RenewTime = &now
+			_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+		}
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("Failed to renew operation lease", "lease", l.Namespace+"/"+l.Name, "operation", l.Operation, "error", err)
+		}
+	}
+}
+
+// Release stops renewing the lease and deletes it, unless another holder
+// took it over
+func (l *OperationLease) Release(ctx context.Context) error {
+	l.stop()
+	l.renewing.Wait()
+
+	leases := l.c.k8sClient.CoordinationV1().Leases(l.Namespace)
+	lease, err := leases.Get(ctx, l.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get operation lease %s/%s: %w", l.Namespace, l.Name, err)
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != l.Holder {
+		return nil
+	}
+	precondition := metav1.Preconditions{ResourceVersion: &lease.ResourceVersion}
+	if err := leases.Delete(ctx, l.Name, metav1.DeleteOptions{Preconditions: &precondition}); err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+		return fmt.Errorf("failed to delete operation lease %s/%s: %w", l.Namespace, l.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAcquireOperationLease(t *testing.T) {
+	ctx := context.Background()
+	c := &Client{k8sClient: k8sfake.NewClientset()}
+
+	lease, err := c.AcquireOperationLease(ctx, "org-acme", "prod", "replica-a", "capi_scale_cluster by jane", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := c.k8sClient.CoordinationV1().Leases("org-acme").Get(ctx, OperationLeaseName("prod"), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *stored.Spec.HolderIdentity != "replica-a" || stored.Annotations[OperationLeaseAnnotation] != "capi_scale_cluster by jane" {
+		t.Errorf("lease = %+v", stored)
+	}
+
+	// Another replica is refused while the lease is held
+	_, err = c.AcquireOperationLease(ctx, "org-acme", "prod", "replica-b", "capi_upgrade_cluster by joe", time.Minute)
+	if !errors.Is(err, ErrOperationInProgress) {
+		t.Fatalf("second acquisition error = %v, want ErrOperationInProgress", err)
+	}
+
+	if err := lease.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.AcquireOperationLease(ctx, "org-acme", "prod", "replica-b", "capi_upgrade_cluster by joe", time.Minute); err != nil {
+		t.Fatalf("acquisition after release error = %v", err)
+	}
+}
+
+func TestAcquireExpiredOperationLease(t *testing.T) {
+	ctx := context.Background()
+	holder := "crashed-replica"
+	seconds := int32(60)
+	renewed := metav1.NewMicroTime(time.Now().Add(-time.Hour))
+	c := &Client{k8sClient: k8sfake.NewClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: OperationLeaseName("prod")},
+		Spec:       coordinationv1.LeaseSpec{HolderIdentity: &holder, LeaseDurationSeconds: &seconds, AcquireTime: &renewed, RenewTime: &renewed},
+	})}
+
+	lease, err := c.AcquireOperationLease(ctx, "org-acme", "prod", "replica-a", "capi_delete_machine by jane", time.Minute)
+	if err != nil {
+		t.Fatalf("taking over an expired lease error = %v", err)
+	}
+	defer lease.Release(ctx)
+	stored, err := c.k8sClient.CoordinationV1().Leases("org-acme").Get(ctx, OperationLeaseName("prod"), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *stored.Spec.HolderIdentity != "replica-a" {
+		t.Errorf("holder = %s, want replica-a", *stored.Spec.HolderIdentity)
+	}
+}
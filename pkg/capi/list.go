@@ -0,0 +1,101 @@
+package capi
+
+import (
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ListOption narrows down the results of the List methods
+type ListOption func(*listOptions)
+
+type listOptions struct {
+	limit         int64
+	continueToken string
+	labelSelector string
+	fieldSelector string
+}
+
+// WithLimit returns at most limit items. When more items exist, the Continue
+// field of the returned list holds the token for WithContinue to fetch the
+// next page.
+func WithLimit(limit int64) ListOption {
+	return func(o *listOptions) {
+		o.limit = limit
+	}
+}
+
+// WithContinue fetches the page following a list returned with WithLimit.
+// The other options must be the same as for the previous page.
+func WithContinue(token string) ListOption {
+	return func(o *listOptions) {
+		o.continueToken = token
+	}
+}
+
+// WithLabelSelector returns only items matching a label selector in the
+// Kubernetes syntax, e.g. "team=platform,env!=dev". It is combined with the
+// cluster name filter of the List methods.
+func WithLabelSelector(selector string) ListOption {
+	return func(o *listOptions) {
+		o.labelSelector = selector
+	}
+}
+
+// WithFieldSelector returns only items matching a field selector in the
+// Kubernetes syntax. Custom resources support metadata.name and
+// metadata.namespace, plus the selectable fields declared by their CRD.
+func WithFieldSelector(selector string) ListOption {
+	return func(o *listOptions) {
+		o.fieldSelector = selector
+	}
+}
+
+// clientListOptions converts the options into controller-runtime list options.
+// matchLabels are required in addition to the label selector.
+func clientListOptions(opts []ListOption, matchLabels map[string]string) ([]client.ListOption, error) {
+	o := &listOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.limit < 0 {
+		return nil, errorf(ErrInvalidArgument, "limit must not be negative")
+	}
+
+	var result []client.ListOption
+	selector, err := labels.Parse(o.labelSelector)
+	if err != nil {
+		return nil, errorf(ErrInvalidArgument, "invalid label selector %q: %v", o.labelSelector, err)
+	}
+	if len(matchLabels) > 0 {
+		requirements, _ := labels.SelectorFromSet(matchLabels).Requirements()
+		selector = selector.Add(requirements...)
+	}
+	if !selector.Empty() {
+		result = append(result, client.MatchingLabelsSelector{Selector: selector})
+	}
+	if o.fieldSelector != "" {
+		fieldSelector, err := fields.ParseSelector(o.fieldSelector)
+		if err != nil {
+			return nil, errorf(ErrInvalidArgument, "invalid field selector %q: %v", o.fieldSelector, err)
+		}
+		result = append(result, client.MatchingFieldsSelector{Selector: fieldSelector})
+	}
+	if o.limit > 0 {
+		result = append(result, client.Limit(o.limit))
+	}
+	if o.continueToken != "" {
+		result = append(result, client.Continue(o.continueToken))
+	}
+	return result, nil
+}
+
+// clusterLabels selects the objects of a cluster, or all objects if
+// clusterName is empty
+func clusterLabels(clusterName string) map[string]string {
+	if clusterName == "" {
+		return nil
+	}
+	return map[string]string{clusterv1.ClusterNameLabel: clusterName}
+}
@@ -0,0 +1,99 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestListOptions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	var got client.ListOptions
+	ctrlClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				got = client.ListOptions{}
+				got.ApplyOptions(opts)
+				return c.List(ctx, list, opts...)
+			},
+		}).
+		Build()
+	c := &Client{ctrlClient: ctrlClient}
+
+	if _, err := c.ListMachines(context.Background(), "default", "prod", WithLimit(50), WithContinue("token")); err != nil {
+		t.Fatalf("ListMachines() error = %v", err)
+	}
+	if got.Limit != 50 || got.Continue != "token" || got.Namespace != "default" {
+		t.Errorf("ListMachines() sent %+v, want limit 50 and continue token in namespace default", got)
+	}
+
+	if _, err := c.ListClusters(context.Background(), ""); err != nil {
+		t.Fatalf("ListClusters() error = %v", err)
+	}
+	if got.Limit != 0 || got.Continue != "" {
+		t.Errorf("ListClusters() without options sent %+v, want no pagination", got)
+	}
+
+	if _, err := c.ListMachineDeployments(context.Background(), "default", "", WithLimit(-1)); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ListMachineDeployments() with a negative limit error = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestListSelectors(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	machine := func(name, cluster string, controlPlane bool) *clusterv1.Machine {
+		m := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster},
+		}}
+		if controlPlane {
+			m.Labels[clusterv1.MachineControlPlaneLabel] = ""
+		}
+		return m
+	}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		machine("prod-cp", "prod", true),
+		machine("prod-worker", "prod", false),
+		machine("dev-cp", "dev", true),
+	).Build()}
+
+	// The selector narrows down the cluster name filter instead of replacing it
+	machines, err := c.ListMachines(context.Background(), "default", "prod", WithLabelSelector(clusterv1.MachineControlPlaneLabel))
+	if err != nil {
+		t.Fatalf("ListMachines() error = %v", err)
+	}
+	if len(machines.Items) != 1 || machines.Items[0].Name != "prod-cp" {
+		t.Errorf("ListMachines() = %d machines, want only prod-cp", len(machines.Items))
+	}
+
+	machines, err = c.ListMachines(context.Background(), "default", "", WithLabelSelector(clusterv1.MachineControlPlaneLabel))
+	if err != nil {
+		t.Fatalf("ListMachines() error = %v", err)
+	}
+	if len(machines.Items) != 2 {
+		t.Errorf("ListMachines() of all clusters = %d machines, want 2", len(machines.Items))
+	}
+
+	if _, err := c.ListClusters(context.Background(), "", WithLabelSelector("team in (")); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ListClusters() with an invalid label selector error = %v, want ErrInvalidArgument", err)
+	}
+	if _, err := c.ListClusters(context.Background(), "", WithFieldSelector("metadata.name")); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ListClusters() with an invalid field selector error = %v, want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,278 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Actions of the objects of a manifest
+const (
+	ManifestCreate    = "create"
+	ManifestUpdate    = "update"
+	ManifestUnchanged = "unchanged"
+)
+
+// crdGVK is the kind of CustomResourceDefinitions, read as unstructured
+// objects since the client scheme does not include apiextensions
+var crdGVK = apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinition")
+
+// ApplyManifestOptions configures ApplyManifest
+type ApplyManifestOptions struct {
+	// Manifest is a set of YAML or JSON documents
+	Manifest []byte
+	// Namespace of the namespaced objects that have none
+	Namespace string
+	// Force takes over fields managed by others, like kubectl apply
+	// --server-side --force-conflicts
+	Force bool
+	// DryRun validates and diffs the objects without applying them
+	DryRun bool
+	// AllowNamespace, if set, limits the objects to the namespaces it
+	// accepts; cluster-scoped objects are then refused
+	AllowNamespace func(namespace string) bool
+}
+
+// ManifestObject is an object of a manifest with what applying it changes
+type ManifestObject struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	// Action is create, update or unchanged, empty when the object is invalid
+	Action string `json:"action,omitempty"`
+	// Changes of an update, from the existing object to the result of a
+	// server-side dry-run; Secret values are not shown
+	Changes  []FieldChange `json:"changes,omitempty"`
+	Problems []string      `json:"problems,omitempty"`
+}
+
+// ManifestApply is the outcome of ApplyManifest
+type ManifestApply struct {
+	Objects []ManifestObject `json:"objects"`
+	// Valid reports that no object has problems
+	Valid   bool `json:"valid"`
+	Applied bool `json:"applied"`
+}
+
+// ApplyManifest applies a set of objects with server-side apply after
+// checking them: every kind must be served, custom resources must match the
+// schema of their CRD and a server-side dry-run must succeed. The result
+// shows the diff of each object against its current state. Nothing is
+// applied when an object has problems or with opts.DryRun; objects applied
+// before a failure are left in place.
+func (c *Client) ApplyManifest(ctx context.Context, opts ApplyManifestOptions) (*ManifestApply, error) {
+	objects, err := decodeManifest(opts.Manifest)
+	if err != nil {
+		return nil, errorf(ErrInvalidArgument, "failed to decode the manifest: %v", err)
+	}
+	if len(objects) == 0 {
+		return nil, errorf(ErrInvalidArgument, "the manifest has no objects")
+	}
+	c.setDefaultNamespace(objects, opts.Namespace)
+
+	patchOpts := []client.PatchOption{client.FieldOwner(providerFieldManager)}
+	if opts.Force {
+		patchOpts = append(patchOpts, client.ForceOwnership)
+	}
+	result := &ManifestApply{Objects: make([]ManifestObject, 0, len(objects)), Valid: true}
+	existing := make([]*unstructured.Unstructured, len(objects))
+	for i, obj := range objects {
+		checked := ManifestObject{APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		checked.Problems, err = c.checkManifestObject(ctx, obj, opts.AllowNamespace)
+		if err != nil {
+			return nil, err
+		}
+		if len(checked.Problems) == 0 {
+			existing[i], err = c.getManifestObject(ctx, obj)
+			if err != nil {
+				return nil, err
+			}
+			if existing[i] != nil {
+				if err := checkGitOpsOwner(ctx, existing[i]); err != nil {
+					checked.Problems = append(checked.Problems, err.Error())
+				}
+			}
+			checked.Action, checked.Changes, err = c.dryRunManifestObject(ctx, obj, existing[i], patchOpts)
+			if err != nil {
+				checked.Problems = append(checked.Problems, err.Error())
+			}
+		}
+		if len(checked.Problems) > 0 {
+			result.Valid = false
+			checked.Action = ""
+		}
+		result.Objects = append(result.Objects, checked)
+	}
+	if !result.Valid || opts.DryRun {
+		return result, nil
+	}
+
+	for i, obj := range objects {
+		if result.Objects[i].Action == ManifestUnchanged {
+			continue
+		}
+		if err := c.ctrlClient.Patch(ctx, obj, client.Apply, patchOpts...); err != nil {
+			return nil, fmt.Errorf("failed to apply %s %s after applying %d objects: %w", obj.GetKind(), obj.GetName(), i, err)
+		}
+		if existing[i] != nil {
+			c.recordManifestChange(existing[i])
+		}
+	}
+	result.Applied = true
+	return result, nil
+}
+
+// checkManifestObject reports the problems of an object that the server
+// would reject: missing names, kinds that are not served, namespaces that
+// are not allowed and, for custom resources, fields that do not match the
+// schema of the CRD
+func (c *Client) checkManifestObject(ctx context.Context, obj *unstructured.Unstructured, allowNamespace func(string) bool) ([]string, error) {
+	var problems []string
+	if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+		return []string{"apiVersion and kind are required"}, nil
+	}
+	if obj.GetName() == "" {
+		problems = append(problems, "metadata.name is required, server-side apply does not support generateName")
+	}
+	gvk := obj.GroupVersionKind()
+	mapping, err := c.ctrlClient.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return append(problems, fmt.Sprintf("%s is not served by the management cluster", gvk.GroupVersion().WithKind(gvk.Kind))), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up %s: %w", gvk.Kind, err)
+	}
+	namespaced := mapping.Scope.Name() == meta.RESTScopeNameNamespace
+	switch {
+	case !namespaced && obj.GetNamespace() != "":
+		problems = append(problems, fmt.Sprintf("%s is cluster-scoped and cannot have a namespace", gvk.Kind))
+	case allowNamespace != nil && !namespaced:
+		problems = append(problems, fmt.Sprintf("%s is cluster-scoped, only namespaced objects are allowed", gvk.Kind))
+	case allowNamespace != nil && !allowNamespace(obj.GetNamespace()):
+		problems = append(problems, fmt.Sprintf("namespace %s is not allowed", obj.GetNamespace()))
+	}
+
+	crdSchema, err := c.crdSchema(ctx, mapping.Resource)
+	if err != nil || crdSchema == nil {
+		return problems, err
+	}
+	// The API server validates metadata itself
+	fields := make(map[string]any, len(obj.Object))
+	for name, value := range obj.Object {
+		if name != "apiVersion" && name != "kind" && name != "metadata" {
+			fields[name] = value
+		}
+	}
+	return append(problems, validateSchema(crdSchema, fields, gvk.Kind)...), nil
+}
+
+// crdSchema returns the schema of a version of a custom resource, or nil
+// for resources that are not defined by a CRD
+func (c *Client) crdSchema(ctx context.Context, resource schema.GroupVersionResource) (*apiextensionsv1.JSONSchemaProps, error) {
+	if resource.Group == "" {
+		return nil, nil
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(crdGVK)
+	key := client.ObjectKey{Name: resource.Resource + "." + resource.Group}
+	if err := c.ctrlClient.Get(ctx, key, obj); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			// Built-in and aggregated APIs are validated by the dry-run
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get CustomResourceDefinition: %w", resourceError("CustomResourceDefinition", key, err))
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, crd); err != nil {
+		return nil, fmt.Errorf("failed to decode CustomResourceDefinition %s: %w", key.Name, err)
+	}
+	for _, version := range crd.Spec.Versions {
+		if version.Name == resource.Version && version.Schema != nil {
+			return version.Schema.OpenAPIV3Schema, nil
+		}
+	}
+	return nil, nil
+}
+
+// getManifestObject returns the current state of an object, or nil when it
+// does not exist
+func (c *Client) getManifestObject(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(obj.GroupVersionKind())
+	key := client.ObjectKeyFromObject(obj)
+	if err := c.ctrlClient.Get(ctx, key, current); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get %s: %w", obj.GetKind(), resourceError(obj.GetKind(), key, err))
+	}
+	return current, nil
+}
+
+// dryRunManifestObject applies an object with a server-side dry-run and
+// diffs the result against the current state
+func (c *Client) dryRunManifestObject(ctx context.Context, obj, current *unstructured.Unstructured, patchOpts []client.PatchOption) (string, []FieldChange, error) {
+	dryRun := obj.DeepCopy()
+	if err := c.ctrlClient.Patch(ctx, dryRun, client.Apply, append(patchOpts, client.DryRunAll)...); err != nil {
+		return "", nil, fmt.Errorf("dry-run failed: %w", err)
+	}
+	if current == nil {
+		return ManifestCreate, nil, nil
+	}
+	changes := diffFields(comparableObject(current), comparableObject(dryRun))
+	if len(changes) == 0 {
+		return ManifestUnchanged, nil, nil
+	}
+	if obj.GetAPIVersion() == "v1" && obj.GetKind() == "Secret" {
+		for i := range changes {
+			changes[i].From, changes[i].To = hiddenValue(changes[i].From), hiddenValue(changes[i].To)
+		}
+	}
+	return ManifestUpdate, changes, nil
+}
+
+// hiddenValue masks a set value of a Secret
+func hiddenValue(value any) any {
+	if value == nil {
+		return nil
+	}
+	return "(hidden)"
+}
+
+// comparableObject drops the status and the metadata the server maintains,
+// which a diff of the desired state should not show
+func comparableObject(obj *unstructured.Unstructured) map[string]any {
+	object := obj.DeepCopy().Object
+	delete(object, "status")
+	for _, field := range []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp"} {
+		unstructured.RemoveNestedField(object, "metadata", field)
+	}
+	return object
+}
+
+// recordManifestChange records the prior state of the kinds the change
+// history can revert
+func (c *Client) recordManifestChange(before *unstructured.Unstructured) {
+	gvk := before.GroupVersionKind()
+	if gvk.Group != clusterv1.GroupVersion.Group && gvk.Group != controlplanev1.GroupVersion.Group {
+		return
+	}
+	obj, err := newChangeObject("", gvk.Kind)
+	if err != nil {
+		return
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(before.Object, obj); err != nil {
+		return
+	}
+	c.recordChange("apply", obj)
+}
@@ -0,0 +1,214 @@
+package capi
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// applyPatch emulates server-side apply in the fake client by merging the
+// applied object into the current one
+func applyPatch(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch != client.Apply {
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+	applied := obj.(*unstructured.Unstructured)
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(applied.GroupVersionKind())
+	err := c.Get(ctx, client.ObjectKeyFromObject(applied), current)
+	exists := err == nil
+	if exists {
+		applied.Object = mergeObjects(current.Object, applied.Object)
+	}
+	patchOpts := &client.PatchOptions{}
+	patchOpts.ApplyOptions(opts)
+	switch {
+	case len(patchOpts.DryRun) > 0:
+		return nil
+	case exists:
+		return c.Update(ctx, applied)
+	default:
+		return c.Create(ctx, applied)
+	}
+}
+
+// mergeObjects overlays the fields of applied onto a copy of current
+func mergeObjects(current, applied map[string]any) map[string]any {
+	merged := runtime.DeepCopyJSON(current)
+	for key, value := range applied {
+		if nested, ok := value.(map[string]any); ok {
+			if currentNested, ok := merged[key].(map[string]any); ok {
+				merged[key] = mergeObjects(currentNested, nested)
+				continue
+			}
+		}
+		merged[key] = runtime.DeepCopyJSONValue(value)
+	}
+	return merged
+}
+
+func newDockerClusterCRD(t *testing.T) *unstructured.Unstructured {
+	t.Helper()
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "dockerclusters.infrastructure.cluster.x-k8s.io"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "infrastructure.cluster.x-k8s.io",
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+				Name: "v1beta1",
+				Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+					Type: "object",
+					Properties: map[string]apiextensionsv1.JSONSchemaProps{
+						"apiVersion": {Type: "string"},
+						"kind":       {Type: "string"},
+						"metadata":   {Type: "object"},
+						"spec": {Type: "object", Properties: map[string]apiextensionsv1.JSONSchemaProps{
+							"loadBalancer": {Type: "object", Properties: map[string]apiextensionsv1.JSONSchemaProps{
+								"imageTag": {Type: "string"},
+							}},
+						}},
+					},
+				}},
+			}},
+		},
+	}
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(crd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := &unstructured.Unstructured{Object: object}
+	obj.SetGroupVersionKind(crdGVK)
+	return obj
+}
+
+const clusterManifest = `apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: dev
+  labels:
+    team: ops
+spec:
+  paused: true
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+kind: DockerCluster
+metadata:
+  name: dev
+spec:
+  loadBalancer:
+    imageTag: v1.0.0
+`
+
+func TestApplyManifest(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(clusterv1.GroupVersion.WithKind("Cluster"), meta.RESTScopeNamespace)
+	mapper.Add(dockerClusterGVK, meta.RESTScopeNamespace)
+	mapper.Add(crdGVK, meta.RESTScopeRoot)
+	ctrlClient := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).
+		WithObjects(&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "dev"}}, newDockerClusterCRD(t)).
+		WithInterceptorFuncs(interceptor.Funcs{Patch: applyPatch}).
+		Build()
+	history, err := NewChangeHistory(0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{ctrlClient: ctrlClient, changes: history}
+	ctx := context.Background()
+
+	invalid := strings.Replace(clusterManifest, "imageTag: v1.0.0", "imageTag: 1\n  bogus: true", 1) + `---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta2
+kind: AWSCluster
+metadata:
+  name: dev
+`
+	result, err := c.ApplyManifest(ctx, ApplyManifestOptions{Manifest: []byte(invalid), Namespace: "org-acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Valid || result.Applied || len(result.Objects) != 3 {
+		t.Fatalf("result of an invalid manifest = %+v", result)
+	}
+	dockerCluster := result.Objects[1]
+	want := []string{"DockerCluster.spec.bogus is not a known field", "DockerCluster.spec.loadBalancer.imageTag must be a string"}
+	if strings.Join(dockerCluster.Problems, "\n") != strings.Join(want, "\n") || dockerCluster.Namespace != "org-acme" {
+		t.Errorf("DockerCluster = %+v, want problems %q", dockerCluster, want)
+	}
+	if problems := result.Objects[2].Problems; len(problems) != 1 || !strings.Contains(problems[0], "not served") {
+		t.Errorf("AWSCluster problems = %q", problems)
+	}
+
+	result, err = c.ApplyManifest(ctx, ApplyManifestOptions{Manifest: []byte(clusterManifest), Namespace: "org-acme", DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid || result.Applied {
+		t.Fatalf("dry-run result = %+v", result)
+	}
+	cluster := result.Objects[0]
+	if cluster.Action != ManifestUpdate || len(cluster.Changes) != 2 ||
+		cluster.Changes[0].Field != "metadata.labels.team" || cluster.Changes[1].Field != "spec.paused" || cluster.Changes[1].To != true {
+		t.Errorf("Cluster = %+v", cluster)
+	}
+	if result.Objects[1].Action != ManifestCreate {
+		t.Errorf("DockerCluster action = %q, want create", result.Objects[1].Action)
+	}
+	if current, _ := c.GetCluster(ctx, "org-acme", "dev"); current.Spec.Paused {
+		t.Error("the dry-run paused the cluster")
+	}
+
+	if result, err = c.ApplyManifest(ctx, ApplyManifestOptions{Manifest: []byte(clusterManifest), Namespace: "org-acme"}); err != nil || !result.Applied {
+		t.Fatalf("ApplyManifest() = %+v, %v", result, err)
+	}
+	if current, _ := c.GetCluster(ctx, "org-acme", "dev"); !current.Spec.Paused || current.Labels["team"] != "ops" {
+		t.Errorf("cluster after apply = %+v", current)
+	}
+	created := &unstructured.Unstructured{}
+	created.SetGroupVersionKind(dockerClusterGVK)
+	if err := ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "dev"}, created); err != nil {
+		t.Errorf("DockerCluster was not created: %v", err)
+	}
+	if changes := history.List(); len(changes) != 1 || changes[0].Kind != "Cluster" || changes[0].Operation != "apply" {
+		t.Errorf("recorded changes = %+v", changes)
+	}
+
+	result, err = c.ApplyManifest(ctx, ApplyManifestOptions{Manifest: []byte(clusterManifest), Namespace: "org-acme", DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, obj := range result.Objects {
+		if obj.Action != ManifestUnchanged {
+			t.Errorf("%s after apply = %+v, want unchanged", obj.Kind, obj)
+		}
+	}
+
+	allowed := func(namespace string) bool { return namespace == "org-acme" }
+	other := strings.Replace(clusterManifest, "name: dev\n  labels", "name: dev\n  namespace: org-other\n  labels", 1)
+	result, err = c.ApplyManifest(ctx, ApplyManifestOptions{Manifest: []byte(other), Namespace: "org-acme", AllowNamespace: allowed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems := result.Objects[0].Problems; result.Valid || len(problems) != 1 || problems[0] != "namespace org-other is not allowed" {
+		t.Errorf("problems in another namespace = %q", problems)
+	}
+	crd := "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: widgets.example.com\n"
+	result, err = c.ApplyManifest(ctx, ApplyManifestOptions{Manifest: []byte(crd), Namespace: "org-acme", AllowNamespace: allowed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if problems := result.Objects[0].Problems; len(problems) != 1 || !strings.Contains(problems[0], "cluster-scoped") {
+		t.Errorf("problems of a cluster-scoped object = %q", problems)
+	}
+}
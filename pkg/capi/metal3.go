@@ -0,0 +1,157 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// bareMetalHostGVK is a physical host managed by the Metal3 baremetal operator
+var bareMetalHostGVK = schema.GroupVersionKind{Group: "metal3.io", Version: "v1alpha1", Kind: "BareMetalHost"}
+
+// BareMetalHostInfo is a BareMetalHost, correlated with the Metal3Machine
+// consuming it and the CAPI Machine of that Metal3Machine
+type BareMetalHostInfo struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// ProvisioningState is e.g. available, provisioning, provisioned or
+	// deprovisioning
+	ProvisioningState string `json:"provisioningState,omitempty"`
+	// OperationalStatus is OK, discovered, error, delayed or detached
+	OperationalStatus string `json:"operationalStatus,omitempty"`
+	ErrorType         string `json:"errorType,omitempty"`
+	ErrorMessage      string `json:"errorMessage,omitempty"`
+	Online            bool   `json:"online"`
+	PoweredOn         bool   `json:"poweredOn"`
+	BMCAddress        string `json:"bmcAddress,omitempty"`
+	BootMACAddress    string `json:"bootMACAddress,omitempty"`
+	Image             string `json:"image,omitempty"`
+	CPUs              int64  `json:"cpus,omitempty"`
+	RAMMebibytes      int64  `json:"ramMebibytes,omitempty"`
+	// Metal3Machine consumes the host; Machine, Cluster and Node are read
+	// from the CAPI Machine owning it
+	Metal3Machine string `json:"metal3Machine,omitempty"`
+	Machine       string `json:"machine,omitempty"`
+	Cluster       string `json:"cluster,omitempty"`
+	Node          string `json:"node,omitempty"`
+}
+
+// Metal3ClusterInfo is the Metal3 infrastructure of a cluster with the hosts
+// its machines run on
+type Metal3ClusterInfo struct {
+	Name                 string              `json:"name"`
+	ControlPlaneEndpoint string              `json:"controlPlaneEndpoint,omitempty"`
+	Ready                bool                `json:"ready"`
+	FailureMessage       string              `json:"failureMessage,omitempty"`
+	Hosts                []BareMetalHostInfo `json:"hosts"`
+}
+
+// isMetal3Cluster reports whether a cluster runs on the Metal3 provider
+func isMetal3Cluster(cluster *clusterv1.Cluster) bool {
+	return ClusterProvider(cluster) == ProviderMetal3
+}
+
+// GetMetal3Cluster reads the Metal3Cluster of a cluster and the
+// BareMetalHosts its machines are provisioned on
+func (c *Client) GetMetal3Cluster(ctx context.Context, namespace, name string) (*Metal3ClusterInfo, error) {
+	cluster, err := c.GetCluster(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if !isMetal3Cluster(cluster) {
+		return nil, errorf(ErrInvalidArgument, "cluster %s/%s is not a Metal3 cluster", namespace, name)
+	}
+
+	ref := cluster.Spec.InfrastructureRef
+	infra, err := c.getReferenced(ctx, namespace, ref.APIVersion, ref.Kind, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+	info := &Metal3ClusterInfo{Name: ref.Name}
+	info.Ready, _, _ = unstructured.NestedBool(infra.Object, "status", "ready")
+	info.FailureMessage, _, _ = unstructured.NestedString(infra.Object, "status", "failureMessage")
+	if host, _, _ := unstructured.NestedString(infra.Object, "spec", "controlPlaneEndpoint", "host"); host != "" {
+		port, _, _ := unstructured.NestedInt64(infra.Object, "spec", "controlPlaneEndpoint", "port")
+		info.ControlPlaneEndpoint = fmt.Sprintf("%s:%d", host, port)
+	}
+
+	if info.Hosts, err = c.ListBareMetalHosts(ctx, namespace, name); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// ListBareMetalHosts lists the BareMetalHosts in a namespace, or in all
+// namespaces if namespace is empty, with the Machines provisioned on them. If
+// clusterName is set only the hosts of its machines are listed.
+func (c *Client) ListBareMetalHosts(ctx context.Context, namespace, clusterName string) ([]BareMetalHostInfo, error) {
+	hosts, err := c.listUnstructured(ctx, bareMetalHostGVK, client.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	machines, err := c.ListMachines(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	machinesByInfra := make(map[string]*clusterv1.Machine, len(machines.Items))
+	for i := range machines.Items {
+		if ref := machines.Items[i].Spec.InfrastructureRef; ref.Kind == "Metal3Machine" {
+			machinesByInfra[machines.Items[i].Namespace+"/"+ref.Name] = &machines.Items[i]
+		}
+	}
+
+	infos := []BareMetalHostInfo{}
+	for i := range hosts {
+		info := newBareMetalHostInfo(&hosts[i])
+		if info.Metal3Machine != "" {
+			consumerNamespace, _, _ := unstructured.NestedString(hosts[i].Object, "spec", "consumerRef", "namespace")
+			if consumerNamespace == "" {
+				consumerNamespace = info.Namespace
+			}
+			if machine := machinesByInfra[consumerNamespace+"/"+info.Metal3Machine]; machine != nil {
+				info.Machine = machine.Name
+				info.Cluster = machine.Spec.ClusterName
+				if machine.Status.NodeRef != nil {
+					info.Node = machine.Status.NodeRef.Name
+				}
+			}
+		}
+		if clusterName != "" && info.Cluster != clusterName {
+			continue
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Namespace != infos[j].Namespace {
+			return infos[i].Namespace < infos[j].Namespace
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+// newBareMetalHostInfo reads a BareMetalHost
+func newBareMetalHostInfo(host *unstructured.Unstructured) BareMetalHostInfo {
+	obj := host.Object
+	info := BareMetalHostInfo{Namespace: host.GetNamespace(), Name: host.GetName()}
+	info.ProvisioningState, _, _ = unstructured.NestedString(obj, "status", "provisioning", "state")
+	info.OperationalStatus, _, _ = unstructured.NestedString(obj, "status", "operationalStatus")
+	info.ErrorType, _, _ = unstructured.NestedString(obj, "status", "errorType")
+	info.ErrorMessage, _, _ = unstructured.NestedString(obj, "status", "errorMessage")
+	info.Online, _, _ = unstructured.NestedBool(obj, "spec", "online")
+	info.PoweredOn, _, _ = unstructured.NestedBool(obj, "status", "poweredOn")
+	info.BMCAddress, _, _ = unstructured.NestedString(obj, "spec", "bmc", "address")
+	info.BootMACAddress, _, _ = unstructured.NestedString(obj, "spec", "bootMACAddress")
+	info.Image, _, _ = unstructured.NestedString(obj, "status", "provisioning", "image", "url")
+	info.CPUs, _, _ = unstructured.NestedInt64(obj, "status", "hardware", "cpu", "count")
+	info.RAMMebibytes, _, _ = unstructured.NestedInt64(obj, "status", "hardware", "ramMebibytes")
+	if kind, _, _ := unstructured.NestedString(obj, "spec", "consumerRef", "kind"); kind == "Metal3Machine" {
+		info.Metal3Machine, _, _ = unstructured.NestedString(obj, "spec", "consumerRef", "name")
+	}
+	return info
+}
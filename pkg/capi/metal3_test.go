@@ -0,0 +1,110 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMetal3Cluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	newCluster := func(name, infraKind string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: name},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: infraKind, Name: name},
+			},
+		}
+	}
+	metal3Cluster := &unstructured.Unstructured{Object: map[string]any{
+		"spec":   map[string]any{"controlPlaneEndpoint": map[string]any{"host": "192.168.10.5", "port": int64(6443)}},
+		"status": map[string]any{"ready": true},
+	}}
+	metal3Cluster.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta1")
+	metal3Cluster.SetKind("Metal3Cluster")
+	metal3Cluster.SetNamespace("org-a")
+	metal3Cluster.SetName("edge")
+
+	newHost := func(name, state string, consumer string) *unstructured.Unstructured {
+		spec := map[string]any{
+			"online":         true,
+			"bootMACAddress": "00:5c:52:31:3a:9c",
+			"bmc":            map[string]any{"address": "redfish://10.0.1.1/redfish/v1/Systems/1"},
+		}
+		if consumer != "" {
+			spec["consumerRef"] = map[string]any{"apiVersion": "infrastructure.cluster.x-k8s.io/v1beta1", "kind": "Metal3Machine", "name": consumer}
+		}
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"spec": spec,
+			"status": map[string]any{
+				"operationalStatus": "OK",
+				"poweredOn":         consumer != "",
+				"provisioning":      map[string]any{"state": state, "image": map[string]any{"url": "http://images/ubuntu.qcow2"}},
+				"hardware":          map[string]any{"cpu": map[string]any{"count": int64(32)}, "ramMebibytes": int64(131072)},
+			},
+		}}
+		obj.SetGroupVersionKind(bareMetalHostGVK)
+		obj.SetNamespace("org-a")
+		obj.SetName(name)
+		return obj
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: "edge-cp-x", Labels: map[string]string{clusterv1.ClusterNameLabel: "edge"}},
+		Spec: clusterv1.MachineSpec{
+			ClusterName:       "edge",
+			InfrastructureRef: corev1.ObjectReference{Kind: "Metal3Machine", Name: "edge-cp-abc"},
+		},
+		Status: clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "edge-node-1"}},
+	}
+	objects := []client.Object{
+		newCluster("edge", "Metal3Cluster"), metal3Cluster, newCluster("aws", "AWSCluster"), machine,
+		newHost("server-1", "provisioned", "edge-cp-abc"), newHost("server-2", "available", ""),
+	}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()}
+	ctx := context.Background()
+
+	hosts, err := c.ListBareMetalHosts(ctx, "org-a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("hosts = %+v, want 2", hosts)
+	}
+	provisioned := hosts[0]
+	if provisioned.Metal3Machine != "edge-cp-abc" || provisioned.Machine != "edge-cp-x" || provisioned.Cluster != "edge" || provisioned.Node != "edge-node-1" {
+		t.Errorf("provisioned host = %+v, want it correlated with the Machine", provisioned)
+	}
+	if provisioned.ProvisioningState != "provisioned" || !provisioned.PoweredOn || provisioned.CPUs != 32 || provisioned.RAMMebibytes != 131072 {
+		t.Errorf("provisioned host = %+v", provisioned)
+	}
+	if available := hosts[1]; available.ProvisioningState != "available" || available.Machine != "" {
+		t.Errorf("available host = %+v, want no Machine", available)
+	}
+
+	info, err := c.GetMetal3Cluster(ctx, "org-a", "edge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ControlPlaneEndpoint != "192.168.10.5:6443" || !info.Ready {
+		t.Errorf("info = %+v", info)
+	}
+	if len(info.Hosts) != 1 || info.Hosts[0].Name != "server-1" {
+		t.Errorf("cluster hosts = %+v, want only the provisioned one", info.Hosts)
+	}
+
+	if _, err := c.GetMetal3Cluster(ctx, "org-a", "aws"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GetMetal3Cluster() of an AWS cluster error = %v, want ErrInvalidArgument", err)
+	}
+}
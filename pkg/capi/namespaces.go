@@ -0,0 +1,263 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CopiedFromAnnotation marks Secrets copied by PrepareNamespace with the
+// namespace/name of their source
+const CopiedFromAnnotation = "mcp-capi.giantswarm.io/copied-from"
+
+// The outcomes of copying a Secret into a namespace
+const (
+	SecretCopied    = "copied"
+	SecretUnchanged = "unchanged"
+	SecretConflict  = "conflict"
+)
+
+// PrepareNamespaceOptions contains options for preparing the namespace of
+// new clusters
+type PrepareNamespaceOptions struct {
+	Namespace string
+	// Organization sets the Giant Swarm organization label
+	Organization string
+	// Labels are set on the namespace in addition
+	Labels map[string]string
+	// Secrets are the namespace/name of Secrets to copy into the namespace,
+	// such as provider credentials
+	Secrets []string
+	// Provider, if set, reports the identities the clusters of the namespace
+	// can use
+	Provider Provider
+}
+
+// CopiedSecret is a Secret copied into a prepared namespace
+type CopiedSecret struct {
+	Source string `json:"source"`
+	Name   string `json:"name"`
+	Result string `json:"result"`
+}
+
+// NamespacePreparation is the outcome of preparing a namespace
+type NamespacePreparation struct {
+	Namespace string `json:"namespace"`
+	Created   bool   `json:"created"`
+	// Labels are the labels added or changed
+	Labels  map[string]string `json:"labels"`
+	Secrets []CopiedSecret    `json:"secrets"`
+	// Identities are the kind/name of the valid provider identities the
+	// namespace may use
+	Identities []string `json:"identities,omitempty"`
+	Ready      bool     `json:"ready"`
+	Issues     []string `json:"issues"`
+}
+
+// PrepareNamespace makes a namespace ready for workload clusters: it creates
+// the namespace if missing, sets the organization and other labels, and
+// copies Secrets such as provider credentials into it. Existing Secrets with
+// other data are left alone and reported as conflicts. With a provider, the
+// namespace is only ready when a valid identity of the provider allows it;
+// the identities are checked last, so selectors see the new labels.
+func (c *Client) PrepareNamespace(ctx context.Context, opts PrepareNamespaceOptions) (*NamespacePreparation, error) {
+	if msgs := validation.IsDNS1123Label(opts.Namespace); len(msgs) > 0 {
+		return nil, errorf(ErrInvalidArgument, "invalid namespace %q: %s", opts.Namespace, strings.Join(msgs, "; "))
+	}
+	wanted := maps.Clone(opts.Labels)
+	if wanted == nil {
+		wanted = map[string]string{}
+	}
+	if opts.Organization != "" {
+		wanted[OrganizationLabel] = opts.Organization
+	}
+	for key, value := range wanted {
+		if msgs := validation.IsQualifiedName(key); len(msgs) > 0 {
+			return nil, errorf(ErrInvalidArgument, "invalid label %q: %s", key, strings.Join(msgs, "; "))
+		}
+		if msgs := validation.IsValidLabelValue(value); len(msgs) > 0 {
+			return nil, errorf(ErrInvalidArgument, "invalid value of label %s: %s", key, strings.Join(msgs, "; "))
+		}
+	}
+	sources := make([][2]string, 0, len(opts.Secrets))
+	for _, secret := range opts.Secrets {
+		namespace, name, ok := strings.Cut(secret, "/")
+		if !ok || namespace == "" || name == "" {
+			return nil, errorf(ErrInvalidArgument, "secret %q is not of the form namespace/name", secret)
+		}
+		sources = append(sources, [2]string{namespace, name})
+	}
+	if opts.Provider != "" {
+		if _, ok := LookupProvider(string(opts.Provider)); !ok {
+			return nil, errorf(ErrInvalidArgument, "unknown infrastructure provider %q, supported providers: %s", opts.Provider, strings.Join(ProviderNames(), ", "))
+		}
+	}
+
+	result := &NamespacePreparation{Namespace: opts.Namespace, Labels: map[string]string{}, Secrets: []CopiedSecret{}, Issues: []string{}}
+	ns, err := c.labelNamespace(ctx, opts.Namespace, wanted, result)
+	if err != nil {
+		return nil, err
+	}
+	if ns.Status.Phase == corev1.NamespaceTerminating {
+		result.Issues = append(result.Issues, fmt.Sprintf("namespace %s is terminating", opts.Namespace))
+	}
+
+	for _, source := range sources {
+		copied, err := c.copySecret(ctx, source[0], source[1], opts.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		if copied.Result == SecretConflict {
+			result.Issues = append(result.Issues, fmt.Sprintf("Secret %s/%s already exists with other data than %s", opts.Namespace, copied.Name, copied.Source))
+		}
+		result.Secrets = append(result.Secrets, copied)
+	}
+
+	if opts.Provider != "" {
+		if err := c.checkNamespaceIdentities(ctx, opts.Provider, result); err != nil {
+			return nil, err
+		}
+	}
+	result.Ready = len(result.Issues) == 0
+	return result, nil
+}
+
+// labelNamespace creates a namespace with labels, or adds the labels it lacks
+func (c *Client) labelNamespace(ctx context.Context, name string, wanted map[string]string, result *NamespacePreparation) (*corev1.Namespace, error) {
+	namespaces := c.k8sClient.CoreV1().Namespaces()
+	ns, err := namespaces.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: wanted}}
+		if ns, err = namespaces.Create(ctx, ns, metav1.CreateOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to create namespace %s: %w", name, resourceError("Namespace", client.ObjectKey{Name: name}, err))
+		}
+		result.Created = true
+		maps.Copy(result.Labels, wanted)
+		return ns, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace %s: %w", name, resourceError("Namespace", client.ObjectKey{Name: name}, err))
+	}
+
+	for key, value := range wanted {
+		if current, ok := ns.Labels[key]; !ok || current != value {
+			result.Labels[key] = value
+		}
+	}
+	if len(result.Labels) == 0 {
+		return ns, nil
+	}
+	if ns.Labels == nil {
+		ns.Labels = map[string]string{}
+	}
+	maps.Copy(ns.Labels, result.Labels)
+	if ns, err = namespaces.Update(ctx, ns, metav1.UpdateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to label namespace %s: %w", name, resourceError("Namespace", client.ObjectKey{Name: name}, err))
+	}
+	return ns, nil
+}
+
+// copySecret copies a Secret into a namespace under the same name, unless a
+// Secret of that name exists there already
+func (c *Client) copySecret(ctx context.Context, namespace, name, target string) (CopiedSecret, error) {
+	copied := CopiedSecret{Source: namespace + "/" + name, Name: name}
+	source, err := c.k8sClient.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return copied, fmt.Errorf("failed to get Secret %s: %w", copied.Source, resourceError("Secret", client.ObjectKey{Namespace: namespace, Name: name}, err))
+	}
+	if namespace == target {
+		copied.Result = SecretUnchanged
+		return copied, nil
+	}
+
+	secrets := c.k8sClient.CoreV1().Secrets(target)
+	existing, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		copied.Result = SecretUnchanged
+		if existing.Type != source.Type || !reflect.DeepEqual(existing.Data, source.Data) {
+			copied.Result = SecretConflict
+		}
+		return copied, nil
+	case !apierrors.IsNotFound(err):
+		return copied, fmt.Errorf("failed to get Secret %s/%s: %w", target, name, resourceError("Secret", client.ObjectKey{Namespace: target, Name: name}, err))
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   target,
+			Name:        name,
+			Labels:      source.Labels,
+			Annotations: map[string]string{CopiedFromAnnotation: copied.Source},
+		},
+		Type: source.Type,
+		Data: source.Data,
+	}
+	if _, err := secrets.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		return copied, fmt.Errorf("failed to copy Secret %s: %w", copied.Source, resourceError("Secret", client.ObjectKey{Namespace: target, Name: name}, err))
+	}
+	copied.Result = SecretCopied
+	return copied, nil
+}
+
+// checkNamespaceIdentities records the valid identities of a provider that
+// allow the namespace, and an issue when there is none. GCP has no
+// identities; its clusters need the credentials of CAPG or a copied Secret.
+func (c *Client) checkNamespaceIdentities(ctx context.Context, provider Provider, result *NamespacePreparation) error {
+	if _, ok := identityProviders[provider]; !ok {
+		return nil
+	}
+	v := &identityValidator{
+		c:                    c,
+		controllerNamespaces: make(map[Provider]string),
+		namespaceLabels:      make(map[string]labels.Set),
+		secrets:              make(map[string]bool),
+	}
+	v.findControllerNamespaces(ctx)
+	if provider == ProviderGCP {
+		if len(result.Secrets) > 0 {
+			return nil
+		}
+		found, err := v.secretExists(ctx, v.controllerNamespaces[ProviderGCP], gcpBootstrapCredentialsSecret)
+		if err != nil {
+			return err
+		}
+		if !found {
+			result.Issues = append(result.Issues, fmt.Sprintf("Secret %s/%s of CAPG does not exist, copy a credentials Secret into the namespace", v.controllerNamespaces[ProviderGCP], gcpBootstrapCredentialsSecret))
+		}
+		return nil
+	}
+
+	identities, err := v.listIdentities(ctx, []Provider{provider})
+	if err != nil {
+		return err
+	}
+	for i := range identities {
+		identity := &identities[i]
+		if !identity.Valid {
+			continue
+		}
+		allowed, err := v.allows(ctx, identity, result.Namespace)
+		if err != nil {
+			return err
+		}
+		if allowed {
+			result.Identities = append(result.Identities, identity.Kind+"/"+strings.TrimPrefix(identity.Namespace+"/"+identity.Name, "/"))
+		}
+	}
+	slices.Sort(result.Identities)
+	if len(result.Identities) == 0 {
+		result.Issues = append(result.Issues, fmt.Sprintf("no valid %s identity allows namespace %s, see capi_list_identities", provider, result.Namespace))
+	}
+	return nil
+}
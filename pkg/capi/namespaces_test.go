@@ -0,0 +1,120 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPrepareNamespace(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	// The identity allows the namespaces of the acme organization only
+	identity := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"secretRef": "aws-credentials",
+			"allowedNamespaces": map[string]any{
+				"selector": map[string]any{"matchLabels": map[string]any{OrganizationLabel: "acme"}},
+			},
+		},
+	}}
+	identity.SetGroupVersionKind(awsClusterStaticIdentityGVK)
+	identity.SetName("acme")
+
+	credentials := func(namespace string, key string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "aws-credentials"},
+			Data:       map[string][]byte{"AccessKeyID": []byte(key)},
+		}
+	}
+	c := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(identity).Build(),
+		k8sClient: k8sfake.NewClientset(
+			credentials("capa-system", "AKIA1"),
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "org-other", Labels: map[string]string{"team": "b"}}},
+			credentials("org-other", "AKIA2"),
+		),
+	}
+	ctx := context.Background()
+
+	result, err := c.PrepareNamespace(ctx, PrepareNamespaceOptions{
+		Namespace:    "org-acme",
+		Organization: "acme",
+		Secrets:      []string{"capa-system/aws-credentials"},
+		Provider:     ProviderAWS,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Created || result.Labels[OrganizationLabel] != "acme" {
+		t.Errorf("result = %+v, want a created namespace with the organization label", result)
+	}
+	if len(result.Secrets) != 1 || result.Secrets[0].Result != SecretCopied {
+		t.Errorf("secrets = %+v, want one copied", result.Secrets)
+	}
+	if !result.Ready || len(result.Identities) != 1 || result.Identities[0] != "AWSClusterStaticIdentity/acme" {
+		t.Errorf("result = %+v, want ready with the acme identity", result)
+	}
+	copied, err := c.k8sClient.CoreV1().Secrets("org-acme").Get(ctx, "aws-credentials", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(copied.Data["AccessKeyID"]) != "AKIA1" || copied.Annotations[CopiedFromAnnotation] != "capa-system/aws-credentials" {
+		t.Errorf("copied Secret = %+v", copied)
+	}
+
+	// Preparing again changes nothing
+	result, err = c.PrepareNamespace(ctx, PrepareNamespaceOptions{
+		Namespace:    "org-acme",
+		Organization: "acme",
+		Secrets:      []string{"capa-system/aws-credentials"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created || len(result.Labels) != 0 || result.Secrets[0].Result != SecretUnchanged || !result.Ready {
+		t.Errorf("second preparation = %+v, want nothing changed", result)
+	}
+
+	// An existing namespace gets the missing labels; its own Secret is kept
+	result, err = c.PrepareNamespace(ctx, PrepareNamespaceOptions{
+		Namespace:    "org-other",
+		Organization: "other",
+		Secrets:      []string{"capa-system/aws-credentials"},
+		Provider:     ProviderAWS,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created || result.Labels[OrganizationLabel] != "other" {
+		t.Errorf("result = %+v, want the organization label added", result)
+	}
+	if result.Secrets[0].Result != SecretConflict || result.Ready || len(result.Issues) != 2 {
+		t.Errorf("result = %+v, want a conflicting Secret and no allowed identity", result)
+	}
+	ns, err := c.k8sClient.CoreV1().Namespaces().Get(ctx, "org-other", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns.Labels["team"] != "b" || ns.Labels[OrganizationLabel] != "other" {
+		t.Errorf("labels = %v, want the existing and the organization label", ns.Labels)
+	}
+
+	if _, err := c.PrepareNamespace(ctx, PrepareNamespaceOptions{Namespace: "org-acme", Secrets: []string{"aws-credentials"}}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("PrepareNamespace() with a Secret without namespace error = %v, want ErrInvalidArgument", err)
+	}
+	if _, err := c.PrepareNamespace(ctx, PrepareNamespaceOptions{Namespace: "org-acme", Secrets: []string{"capa-system/missing"}}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("PrepareNamespace() with a missing Secret error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,358 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NodeIssueKind classifies a mismatch between Machines and Nodes
+type NodeIssueKind string
+
+const (
+	// NodeIssueMachineWithoutNode is a Machine whose Node is missing
+	NodeIssueMachineWithoutNode NodeIssueKind = "machine-without-node"
+	// NodeIssueNodeWithoutMachine is a Node that no Machine or MachinePool
+	// accounts for
+	NodeIssueNodeWithoutMachine NodeIssueKind = "node-without-machine"
+	// NodeIssueProviderIDMismatch is a Machine and its Node disagreeing on the
+	// provider ID of the instance
+	NodeIssueProviderIDMismatch NodeIssueKind = "provider-id-mismatch"
+	// NodeIssueVersionDrift is a kubelet running another version than its
+	// Machine, usually left behind by a partial upgrade
+	NodeIssueVersionDrift NodeIssueKind = "version-drift"
+)
+
+// NodeReport joins the Machines of a cluster to the Nodes of its workload
+// cluster
+type NodeReport struct {
+	Machines int           `json:"machines"`
+	Nodes    int           `json:"nodes"`
+	Pairs    []MachineNode `json:"pairs"`
+	Issues   []NodeIssue   `json:"issues"`
+}
+
+// MachineNode is a Machine and its Node; either is empty when missing
+type MachineNode struct {
+	Machine        string `json:"machine,omitempty"`
+	MachinePool    string `json:"machinePool,omitempty"`
+	Node           string `json:"node,omitempty"`
+	Phase          string `json:"phase,omitempty"`
+	ProviderID     string `json:"providerID,omitempty"`
+	NodeProviderID string `json:"nodeProviderID,omitempty"`
+	Version        string `json:"version,omitempty"`
+	KubeletVersion string `json:"kubeletVersion,omitempty"`
+	Ready          bool   `json:"ready"`
+}
+
+// NodeIssue is a mismatch between a Machine and a Node
+type NodeIssue struct {
+	Kind    NodeIssueKind `json:"kind"`
+	Machine string        `json:"machine,omitempty"`
+	Node    string        `json:"node,omitempty"`
+	Message string        `json:"message"`
+}
+
+// GetNodeReport joins the Machines of a cluster to the Nodes of its workload
+// cluster, by node reference and then by provider ID, and reports machines
+// without nodes, nodes without machines, provider ID mismatches and kubelet
+// versions that differ from the version of their Machine. Nodes listed by
+// MachinePools, which have no Machines for some providers, are accounted for.
+func (c *Client) GetNodeReport(ctx context.Context, namespace, clusterName string) (*NodeReport, error) {
+	if _, err := c.GetCluster(ctx, namespace, clusterName); err != nil {
+		return nil, err
+	}
+	machines, err := c.ListMachines(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	machinePools, err := c.listUnstructured(ctx, machinePoolGVK, client.InNamespace(namespace), client.MatchingLabels(clusterLabels(clusterName)))
+	if err != nil {
+		return nil, err
+	}
+
+	kubeconfig, err := c.GetKubeconfig(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	clientset, err := c.workloadClientset(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", workloadError("Node", client.ObjectKey{}, err))
+	}
+
+	report := &NodeReport{Machines: len(machines.Items), Nodes: len(nodes.Items), Pairs: []MachineNode{}, Issues: []NodeIssue{}}
+	byName := make(map[string]*corev1.Node, len(nodes.Items))
+	byProviderID := make(map[string]*corev1.Node, len(nodes.Items))
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		byName[node.Name] = node
+		if node.Spec.ProviderID != "" {
+			byProviderID[node.Spec.ProviderID] = node
+		}
+	}
+	joined := make(map[string]bool, len(nodes.Items))
+
+	for _, machine := range machines.Items {
+		pair := MachineNode{Machine: machine.Name, Phase: machine.Status.Phase}
+		if machine.Spec.ProviderID != nil {
+			pair.ProviderID = *machine.Spec.ProviderID
+		}
+		if machine.Spec.Version != nil {
+			pair.Version = *machine.Spec.Version
+		}
+
+		var node *corev1.Node
+		if ref := machine.Status.NodeRef; ref != nil {
+			if node = byName[ref.Name]; node == nil {
+				report.Issues = append(report.Issues, NodeIssue{Kind: NodeIssueMachineWithoutNode, Machine: machine.Name, Node: ref.Name,
+					Message: fmt.Sprintf("node %s referenced by the machine does not exist", ref.Name)})
+			}
+		} else if node = byProviderID[pair.ProviderID]; node == nil {
+			report.Issues = append(report.Issues, NodeIssue{Kind: NodeIssueMachineWithoutNode, Machine: machine.Name,
+				Message: fmt.Sprintf("the machine has no node (phase %s)", machine.Status.Phase)})
+		}
+		if node != nil {
+			joined[node.Name] = true
+			pair.Node = node.Name
+			pair.NodeProviderID = node.Spec.ProviderID
+			pair.KubeletVersion = node.Status.NodeInfo.KubeletVersion
+			pair.Ready = nodeReady(node)
+			report.Issues = append(report.Issues, machineNodeIssues(pair)...)
+		}
+		report.Pairs = append(report.Pairs, pair)
+	}
+
+	for _, machinePool := range machinePools {
+		refs, _, _ := unstructured.NestedSlice(machinePool.Object, "status", "nodeRefs")
+		for _, ref := range refs {
+			ref, ok := ref.(map[string]any)
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(ref, "name")
+			node := byName[name]
+			if node == nil || joined[name] {
+				continue
+			}
+			joined[name] = true
+			report.Pairs = append(report.Pairs, MachineNode{
+				MachinePool:    machinePool.GetName(),
+				Node:           name,
+				NodeProviderID: node.Spec.ProviderID,
+				KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+				Ready:          nodeReady(node),
+			})
+		}
+	}
+
+	for _, node := range nodes.Items {
+		if joined[node.Name] {
+			continue
+		}
+		report.Pairs = append(report.Pairs, MachineNode{
+			Node:           node.Name,
+			NodeProviderID: node.Spec.ProviderID,
+			KubeletVersion: node.Status.NodeInfo.KubeletVersion,
+			Ready:          nodeReady(&node),
+		})
+		report.Issues = append(report.Issues, NodeIssue{Kind: NodeIssueNodeWithoutMachine, Node: node.Name,
+			Message: "no machine or machine pool of the cluster owns the node"})
+	}
+
+	sort.SliceStable(report.Issues, func(i, j int) bool { return report.Issues[i].Kind < report.Issues[j].Kind })
+	return report, nil
+}
+
+// machineNodeIssues compares a Machine with the Node it is joined to
+func machineNodeIssues(pair MachineNode) []NodeIssue {
+	var issues []NodeIssue
+	if pair.ProviderID != "" && pair.NodeProviderID != "" && pair.ProviderID != pair.NodeProviderID {
+		issues = append(issues, NodeIssue{Kind: NodeIssueProviderIDMismatch, Machine: pair.Machine, Node: pair.Node,
+			Message: fmt.Sprintf("the machine has provider ID %s, the node %s", pair.ProviderID, pair.NodeProviderID)})
+	}
+	if pair.Version != "" && pair.KubeletVersion != "" && normalizeVersion(pair.Version) != normalizeVersion(pair.KubeletVersion) {
+		issues = append(issues, NodeIssue{Kind: NodeIssueVersionDrift, Machine: pair.Machine, Node: pair.Node,
+			Message: fmt.Sprintf("the kubelet runs %s, the machine specifies %s", pair.KubeletVersion, pair.Version)})
+	}
+	return issues
+}
+
+// nodeReady tells whether the Ready condition of a node is true
+func nodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// normalizeVersion strips the v prefix of a Kubernetes version
+func normalizeVersion(version string) string {
+	return strings.TrimPrefix(version, "v")
+}
+
+// mirrorPodAnnotation marks the API mirrors of static pods
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// NodePods lists the pods running on a node by their controller, to assess
+// what draining or deleting the node disrupts
+type NodePods struct {
+	Node   string     `json:"node"`
+	Pods   int        `json:"pods"`
+	Groups []PodGroup `json:"groups"`
+	// DaemonSetPods and MirrorPods stay on the node during a drain
+	DaemonSetPods int `json:"daemonSetPods"`
+	MirrorPods    int `json:"mirrorPods"`
+	// UnmanagedPods are not recreated elsewhere and need a forced drain
+	UnmanagedPods int `json:"unmanagedPods"`
+	// LocalStoragePods lose their emptyDir data when evicted
+	LocalStoragePods int `json:"localStoragePods"`
+	// BlockedPods are protected by a PodDisruptionBudget that allows no
+	// disruption, so their eviction fails until it does
+	BlockedPods int `json:"blockedPods"`
+}
+
+// PodGroup is the pods of a node sharing a controller
+type PodGroup struct {
+	// Kind and Name are those of the controller, resolving ReplicaSets to
+	// their Deployment; Kind is empty for unmanaged pods
+	Kind      string    `json:"kind,omitempty"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name,omitempty"`
+	Pods      []NodePod `json:"pods"`
+}
+
+// NodePod is a pod running on a node
+type NodePod struct {
+	Name         string `json:"name"`
+	Phase        string `json:"phase"`
+	Mirror       bool   `json:"mirror,omitempty"`
+	LocalStorage bool   `json:"localStorage,omitempty"`
+	// PDB protects the pod, allowing DisruptionsAllowed evictions
+	PDB                string `json:"pdb,omitempty"`
+	DisruptionsAllowed int32  `json:"disruptionsAllowed,omitempty"`
+}
+
+// ListNodePods lists the pods running on a node of a workload cluster,
+// grouped by controller and flagging the pods a drain cannot evict freely:
+// unmanaged pods, pods with local storage and pods whose PodDisruptionBudget
+// allows no disruption
+func (c *Client) ListNodePods(ctx context.Context, namespace, clusterName, nodeName string) (*NodePods, error) {
+	if nodeName == "" {
+		return nil, errorf(ErrInvalidArgument, "node name is required")
+	}
+	kubeconfig, err := c.GetKubeconfig(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	clientset, err := c.workloadClientset(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to get node %s: %w", nodeName, workloadError("Node", client.ObjectKey{Name: nodeName}, err))
+	}
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{FieldSelector: "spec.nodeName=" + nodeName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", workloadError("Pod", client.ObjectKey{}, err))
+	}
+	pdbs, err := clientset.PolicyV1().PodDisruptionBudgets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pod disruption budgets: %w", workloadError("PodDisruptionBudget", client.ObjectKey{}, err))
+	}
+
+	result := &NodePods{Node: nodeName, Groups: []PodGroup{}}
+	groups := map[string]int{}
+	// ReplicaSets resolved to their Deployment
+	deployments := map[string]string{}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != nodeName || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		result.Pods++
+		nodePod := NodePod{Name: pod.Name, Phase: string(pod.Status.Phase)}
+		if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+			nodePod.Mirror = true
+			result.MirrorPods++
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.EmptyDir != nil {
+				nodePod.LocalStorage = true
+				result.LocalStoragePods++
+				break
+			}
+		}
+		if pdb := matchingPDB(pdbs.Items, &pod); pdb != nil {
+			nodePod.PDB = pdb.Name
+			nodePod.DisruptionsAllowed = pdb.Status.DisruptionsAllowed
+			if pdb.Status.DisruptionsAllowed == 0 {
+				result.BlockedPods++
+			}
+		}
+
+		group := PodGroup{Namespace: pod.Namespace}
+		if owner := metav1.GetControllerOf(&pod); owner != nil {
+			group.Kind, group.Name = owner.Kind, owner.Name
+			if owner.Kind == "ReplicaSet" {
+				key := pod.Namespace + "/" + owner.Name
+				deployment, ok := deployments[key]
+				if !ok {
+					if rs, err := clientset.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{}); err == nil {
+						if rsOwner := metav1.GetControllerOf(rs); rsOwner != nil && rsOwner.Kind == "Deployment" {
+							deployment = rsOwner.Name
+						}
+					}
+					deployments[key] = deployment
+				}
+				if deployment != "" {
+					group.Kind, group.Name = "Deployment", deployment
+				}
+			}
+		}
+		switch {
+		case group.Kind == "DaemonSet":
+			result.DaemonSetPods++
+		case group.Kind == "" && !nodePod.Mirror:
+			result.UnmanagedPods++
+		}
+
+		key := group.Kind + "/" + group.Namespace + "/" + group.Name
+		if group.Kind == "" {
+			// Unmanaged pods are listed by themselves
+			key += pod.Name
+		}
+		i, ok := groups[key]
+		if !ok {
+			i = len(result.Groups)
+			groups[key] = i
+			result.Groups = append(result.Groups, group)
+		}
+		result.Groups[i].Pods = append(result.Groups[i].Pods, nodePod)
+	}
+
+	sort.Slice(result.Groups, func(i, j int) bool {
+		a, b := result.Groups[i], result.Groups[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Pods[0].Name < b.Pods[0].Name
+	})
+	return result, nil
+}
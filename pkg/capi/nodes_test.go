@@ -0,0 +1,170 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetNodeReport(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	newMachine := func(name, providerID, version, node string) *clusterv1.Machine {
+		machine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: name, Labels: map[string]string{clusterv1.ClusterNameLabel: "prod"}},
+			Spec:       clusterv1.MachineSpec{ClusterName: "prod", ProviderID: &providerID, Version: &version},
+			Status:     clusterv1.MachineStatus{Phase: "Running"},
+		}
+		if node != "" {
+			machine.Status.NodeRef = &corev1.ObjectReference{Name: node}
+		}
+		return machine
+	}
+	newNode := func(name, providerID, kubelet string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{ProviderID: providerID},
+			Status: corev1.NodeStatus{
+				NodeInfo:   corev1.NodeSystemInfo{KubeletVersion: kubelet},
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+
+	provisioning := newMachine("prod-md-3", "aws:///eu-west-1a/i-3", "v1.30.2", "")
+	provisioning.Status.Phase = "Provisioning"
+	c := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod"}},
+			newMachine("prod-md-1", "aws:///eu-west-1a/i-1", "v1.30.2", "node-1"),
+			newMachine("prod-md-2", "aws:///eu-west-1a/i-2", "v1.30.2", "node-2"),
+			provisioning,
+			newMachine("prod-md-4", "aws:///eu-west-1a/i-4", "v1.30.2", "node-4"),
+			newMachine("prod-md-5", "aws:///eu-west-1a/i-5", "v1.30.2", ""),
+		).Build(),
+		k8sClient: k8sfake.NewClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-kubeconfig"},
+			Data:       map[string][]byte{"value": []byte("kubeconfig")},
+		}),
+		newWorkloadClientset: func(kubeconfig string) (kubernetes.Interface, error) {
+			return k8sfake.NewClientset(
+				newNode("node-1", "aws:///eu-west-1a/i-1", "v1.30.2"),
+				newNode("node-2", "aws:///eu-west-1a/i-22", "v1.29.6"),
+				newNode("node-5", "aws:///eu-west-1a/i-5", "v1.30.2"),
+				newNode("stray", "aws:///eu-west-1a/i-9", "v1.30.2"),
+			), nil
+		},
+	}
+
+	report, err := c.GetNodeReport(context.Background(), "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Machines != 5 || report.Nodes != 4 || len(report.Pairs) != 6 {
+		t.Errorf("report = %d machines, %d nodes, %d pairs", report.Machines, report.Nodes, len(report.Pairs))
+	}
+	want := []NodeIssue{
+		{Kind: NodeIssueMachineWithoutNode, Machine: "prod-md-3"},
+		{Kind: NodeIssueMachineWithoutNode, Machine: "prod-md-4", Node: "node-4"},
+		{Kind: NodeIssueNodeWithoutMachine, Node: "stray"},
+		{Kind: NodeIssueProviderIDMismatch, Machine: "prod-md-2", Node: "node-2"},
+		{Kind: NodeIssueVersionDrift, Machine: "prod-md-2", Node: "node-2"},
+	}
+	if len(report.Issues) != len(want) {
+		t.Fatalf("issues = %+v, want %d", report.Issues, len(want))
+	}
+	for i, issue := range report.Issues {
+		if issue.Kind != want[i].Kind || issue.Machine != want[i].Machine || issue.Node != want[i].Node {
+			t.Errorf("issue %d = %+v, want %+v", i, issue, want[i])
+		}
+	}
+	// prod-md-5 has no node reference yet but is joined by provider ID
+	if pair := report.Pairs[4]; pair.Machine != "prod-md-5" || pair.Node != "node-5" || !pair.Ready {
+		t.Errorf("pair = %+v, want prod-md-5 joined to node-5", pair)
+	}
+
+	if _, err := c.GetNodeReport(context.Background(), "org-acme", "missing"); err == nil {
+		t.Error("GetNodeReport(missing) succeeded")
+	}
+}
+
+func TestListNodePods(t *testing.T) {
+	controller := func(kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: ptr.To(true)}}
+	}
+	pod := func(namespace, name, node string, owners []metav1.OwnerReference, labels map[string]string, volumes ...corev1.Volume) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, OwnerReferences: owners, Labels: labels},
+			Spec:       corev1.PodSpec{NodeName: node, Volumes: volumes},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	mirror := pod("kube-system", "kube-apiserver-node-1", "node-1", nil, nil)
+	mirror.Annotations = map[string]string{mirrorPodAnnotation: "hash"}
+	completed := pod("default", "migration", "node-1", controller("Job", "migration"), nil)
+	completed.Status.Phase = corev1.PodSucceeded
+	scratch := corev1.Volume{Name: "scratch", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}
+
+	workload := k8sfake.NewClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web-5d8f", OwnerReferences: controller("Deployment", "web")}},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}},
+			Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: 0},
+		},
+		pod("shop", "web-5d8f-a", "node-1", controller("ReplicaSet", "web-5d8f"), map[string]string{"app": "web"}),
+		pod("shop", "web-5d8f-b", "node-1", controller("ReplicaSet", "web-5d8f"), map[string]string{"app": "web"}),
+		pod("shop", "web-5d8f-c", "node-2", controller("ReplicaSet", "web-5d8f"), map[string]string{"app": "web"}),
+		pod("shop", "cache-0", "node-1", controller("StatefulSet", "cache"), nil, scratch),
+		pod("kube-system", "cilium-x7k2p", "node-1", controller("DaemonSet", "cilium"), nil),
+		pod("default", "debug", "node-1", nil, nil),
+		mirror, completed,
+	)
+	c := &Client{
+		k8sClient: k8sfake.NewClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-kubeconfig"},
+			Data:       map[string][]byte{"value": []byte("kubeconfig")},
+		}),
+		newWorkloadClientset: func(kubeconfig string) (kubernetes.Interface, error) {
+			return workload, nil
+		},
+	}
+
+	result, err := c.ListNodePods(context.Background(), "org-acme", "prod", "node-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Pods != 6 || result.DaemonSetPods != 1 || result.MirrorPods != 1 || result.UnmanagedPods != 1 || result.LocalStoragePods != 1 || result.BlockedPods != 2 {
+		t.Errorf("result = %+v", result)
+	}
+	want := []string{"/default/", "/kube-system/", "DaemonSet/kube-system/cilium", "Deployment/shop/web", "StatefulSet/shop/cache"}
+	if len(result.Groups) != len(want) {
+		t.Fatalf("groups = %+v, want %d", result.Groups, len(want))
+	}
+	for i, group := range result.Groups {
+		if key := group.Kind + "/" + group.Namespace + "/" + group.Name; key != want[i] {
+			t.Errorf("group %d = %s, want %s", i, key, want[i])
+		}
+	}
+	if web := result.Groups[3]; len(web.Pods) != 2 || web.Pods[0].PDB != "web" {
+		t.Errorf("web = %+v, want 2 pods protected by the web PDB", web)
+	}
+
+	if _, err := c.ListNodePods(context.Background(), "org-acme", "prod", "node-9"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ListNodePods(node-9) error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,171 @@
+package capi
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// Option configures how a client connects to the management cluster
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	kubeconfig  string
+	contextName string
+	config      *rest.Config
+
+	qps       float32
+	burst     int
+	timeout   time.Duration
+	userAgent string
+
+	impersonate *rest.ImpersonationConfig
+
+	operationTimeouts OperationTimeouts
+	retryPolicy       *RetryPolicy
+}
+
+// WithKubeconfig loads the connection from a kubeconfig file instead of the
+// in-cluster configuration, KUBECONFIG or ~/.kube/config
+func WithKubeconfig(path string) Option {
+	return func(o *clientOptions) {
+		o.kubeconfig = path
+	}
+}
+
+// WithContext selects a context of the kubeconfig instead of its current context
+func WithContext(name string) Option {
+	return func(o *clientOptions) {
+		o.contextName = name
+	}
+}
+
+// WithRESTConfig connects with an existing rest config instead of loading a
+// kubeconfig. The config is copied, so the remaining options do not modify it.
+func WithRESTConfig(config *rest.Config) Option {
+	return func(o *clientOptions) {
+		o.config = config
+	}
+}
+
+// WithRateLimit sets the queries per second and burst of client-side rate limiting
+func WithRateLimit(qps float32, burst int) Option {
+	return func(o *clientOptions) {
+		o.qps = qps
+		o.burst = burst
+	}
+}
+
+// WithTimeout limits the duration of each request to the API server
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithOperationTimeouts bounds each read, write and node drain, on top of
+// the per-request timeout, so a hung API server cannot block callers
+func WithOperationTimeouts(timeouts OperationTimeouts) Option {
+	return func(o *clientOptions) {
+		o.operationTimeouts = timeouts
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy, the retries of requests
+// failing with transient errors
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *clientOptions) {
+		o.retryPolicy = &policy
+	}
+}
+
+// WithUserAgent sets the user agent sent to the API server
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithImpersonation sends all requests on behalf of a user and its groups
+func WithImpersonation(user string, groups ...string) Option {
+	return func(o *clientOptions) {
+		o.impersonate = &rest.ImpersonationConfig{UserName: user, Groups: groups}
+	}
+}
+
+// NewClientWithOptions creates a CAPI client configured by options. Without
+// options it behaves like NewClient("").
+func NewClientWithOptions(opts ...Option) (*Client, error) {
+	o := &clientOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if err := o.operationTimeouts.validate(); err != nil {
+		return nil, err
+	}
+	policy := DefaultRetryPolicy
+	if o.retryPolicy != nil {
+		policy = *o.retryPolicy
+	}
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	config, err := o.restConfig()
+	if err != nil {
+		return nil, err
+	}
+	return newClientForConfig(config, policy, o.operationTimeouts)
+}
+
+// restConfig loads the connection and applies the tuning options to it
+func (o *clientOptions) restConfig() (*rest.Config, error) {
+	if o.qps < 0 || o.burst < 0 {
+		return nil, errorf(ErrInvalidArgument, "rate limit must not be negative (qps %v, burst %d)", o.qps, o.burst)
+	}
+	if o.timeout < 0 {
+		return nil, errorf(ErrInvalidArgument, "timeout must not be negative (%s)", o.timeout)
+	}
+	if o.impersonate != nil && o.impersonate.UserName == "" {
+		return nil, errorf(ErrInvalidArgument, "impersonation requires a user name")
+	}
+
+	var config *rest.Config
+	switch {
+	case o.config != nil:
+		if o.kubeconfig != "" || o.contextName != "" {
+			return nil, errorf(ErrInvalidArgument, "a rest config cannot be combined with a kubeconfig or context")
+		}
+		config = rest.CopyConfig(o.config)
+	case o.contextName != "":
+		loaded, err := loadContextConfig(o.kubeconfig, o.contextName)
+		if err != nil {
+			return nil, err
+		}
+		config = loaded
+	default:
+		loaded, err := loadConfig(o.kubeconfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+		}
+		config = loaded
+	}
+
+	if o.qps > 0 {
+		config.QPS = o.qps
+	}
+	if o.burst > 0 {
+		config.Burst = o.burst
+	}
+	if o.timeout > 0 {
+		config.Timeout = o.timeout
+	}
+	if o.userAgent != "" {
+		config.UserAgent = o.userAgent
+	}
+	if o.impersonate != nil {
+		config.Impersonate = *o.impersonate
+	}
+	return config, nil
+}
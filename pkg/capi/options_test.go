@@ -0,0 +1,60 @@
+package capi
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestNewClientWithOptions(t *testing.T) {
+	base := &rest.Config{Host: "https://127.0.0.1:6443"}
+	c, err := NewClientWithOptions(
+		WithRESTConfig(base),
+		WithRateLimit(50, 100),
+		WithTimeout(30*time.Second),
+		WithUserAgent("mcp-capi/test"),
+		WithImpersonation("jane", "capi-admins"),
+	)
+	if err != nil {
+		t.Fatalf("NewClientWithOptions() error = %v", err)
+	}
+	config := c.config
+	if config.QPS != 50 || config.Burst != 100 || config.Timeout != 30*time.Second || config.UserAgent != "mcp-capi/test" {
+		t.Errorf("options not applied: %+v", config)
+	}
+	if config.Impersonate.UserName != "jane" || len(config.Impersonate.Groups) != 1 {
+		t.Errorf("impersonation not applied: %+v", config.Impersonate)
+	}
+	if base.QPS != 0 || base.UserAgent != "" {
+		t.Error("the injected rest config must not be modified")
+	}
+
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	us, err := NewClientWithOptions(WithKubeconfig(kubeconfig), WithContext("us-admin"))
+	if err != nil {
+		t.Fatalf("NewClientWithOptions(us-admin) error = %v", err)
+	}
+	if us.config.Host != "https://127.0.0.1:7443" {
+		t.Errorf("context us-admin uses %s", us.config.Host)
+	}
+
+	invalid := map[string][]Option{
+		"negative rate limit":        {WithRESTConfig(base), WithRateLimit(-1, 0)},
+		"negative timeout":           {WithRESTConfig(base), WithTimeout(-time.Second)},
+		"impersonation without user": {WithRESTConfig(base), WithImpersonation("")},
+		"rest config and context":    {WithRESTConfig(base), WithContext("us-admin")},
+		"unknown context":            {WithKubeconfig(kubeconfig), WithContext("missing")},
+	}
+	for name, opts := range invalid {
+		if _, err := NewClientWithOptions(opts...); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: error = %v, want ErrInvalidArgument", name, err)
+		}
+	}
+}
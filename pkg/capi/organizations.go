@@ -0,0 +1,120 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// OrganizationLabel names the Giant Swarm organization owning a namespace
+	// or cluster
+	OrganizationLabel = "giantswarm.io/organization"
+	// ReleaseVersionLabel is the Giant Swarm release of a cluster
+	ReleaseVersionLabel = "release.giantswarm.io/version"
+	// ClusterLabel names the workload cluster a Giant Swarm object, such as
+	// an App, belongs to
+	ClusterLabel = "giantswarm.io/cluster"
+	// ServicePriorityLabel is the service priority of a cluster, e.g. highest
+	ServicePriorityLabel = "giantswarm.io/service-priority"
+
+	// organizationNamespacePrefix starts the namespaces Giant Swarm creates
+	// for organizations
+	organizationNamespacePrefix = "org-"
+)
+
+// organizationGVK is the Giant Swarm organization, a cluster-scoped object
+// whose status names its namespace
+var organizationGVK = schema.GroupVersionKind{Group: "security.giantswarm.io", Version: "v1alpha1", Kind: "OrganizationList"}
+
+// OrganizationNamespace returns the namespace of a Giant Swarm organization
+func OrganizationNamespace(organization string) string {
+	return organizationNamespacePrefix + organization
+}
+
+// Organization is a Giant Swarm organization and the clusters in its
+// namespace
+type Organization struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Clusters are the names of the clusters in the namespace
+	Clusters []string `json:"clusters"`
+}
+
+// ListOrganizations lists the Giant Swarm organizations of the management
+// cluster, from the Organization objects and the namespaces labeled with
+// giantswarm.io/organization, and the clusters of each. Management clusters
+// without the Organization CRD only report the labeled namespaces.
+func (c *Client) ListOrganizations(ctx context.Context) ([]Organization, error) {
+	byNamespace := map[string]*Organization{}
+	add := func(name, namespace string) {
+		if _, ok := byNamespace[namespace]; !ok {
+			byNamespace[namespace] = &Organization{Name: name, Namespace: namespace, Clusters: []string{}}
+		}
+	}
+
+	items, err := c.listUnstructured(ctx, organizationGVK)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		namespace, _, _ := unstructured.NestedString(item.Object, "status", "namespace")
+		if namespace == "" {
+			namespace = OrganizationNamespace(item.GetName())
+		}
+		add(item.GetName(), namespace)
+	}
+
+	namespaces, err := c.k8sClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: OrganizationLabel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	for _, ns := range namespaces.Items {
+		add(ns.Labels[OrganizationLabel], ns.Name)
+	}
+
+	clusters, err := c.ListClusters(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, cluster := range clusters.Items {
+		if org, ok := byNamespace[cluster.Namespace]; ok {
+			org.Clusters = append(org.Clusters, cluster.Name)
+		}
+	}
+
+	organizations := make([]Organization, 0, len(byNamespace))
+	for _, org := range byNamespace {
+		sort.Strings(org.Clusters)
+		organizations = append(organizations, *org)
+	}
+	sort.Slice(organizations, func(i, j int) bool {
+		if organizations[i].Name != organizations[j].Name {
+			return organizations[i].Name < organizations[j].Name
+		}
+		return organizations[i].Namespace < organizations[j].Namespace
+	})
+	return organizations, nil
+}
+
+// giantSwarmLabels returns the labels of the giantswarm.io domain and its
+// subdomains, such as the organization, release and service priority
+func giantSwarmLabels(labels map[string]string) map[string]string {
+	var found map[string]string
+	for key, value := range labels {
+		prefix, _, ok := strings.Cut(key, "/")
+		if !ok || (prefix != "giantswarm.io" && !strings.HasSuffix(prefix, ".giantswarm.io")) {
+			continue
+		}
+		if found == nil {
+			found = map[string]string{}
+		}
+		found[key] = value
+	}
+	return found
+}
@@ -0,0 +1,88 @@
+package capi
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestListOrganizations(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	organization := &unstructured.Unstructured{Object: map[string]any{
+		"status": map[string]any{"namespace": "org-acme"},
+	}}
+	organization.SetAPIVersion("security.giantswarm.io/v1alpha1")
+	organization.SetKind("Organization")
+	organization.SetName("acme")
+	newCluster := func(namespace, name string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+
+	c := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			organization,
+			newCluster("org-acme", "prod"),
+			newCluster("org-acme", "dev"),
+			newCluster("org-globex", "staging"),
+			newCluster("default", "kind"),
+		).Build(),
+		k8sClient: k8sfake.NewClientset(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "org-acme", Labels: map[string]string{OrganizationLabel: "acme"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "org-globex", Labels: map[string]string{OrganizationLabel: "globex"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		),
+	}
+
+	organizations, err := c.ListOrganizations(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(organizations) != 2 {
+		t.Fatalf("organizations = %+v, want acme and globex", organizations)
+	}
+	acme, globex := organizations[0], organizations[1]
+	if acme.Name != "acme" || acme.Namespace != "org-acme" || strings.Join(acme.Clusters, ",") != "dev,prod" {
+		t.Errorf("acme = %+v", acme)
+	}
+	if globex.Name != "globex" || globex.Namespace != "org-globex" || strings.Join(globex.Clusters, ",") != "staging" {
+		t.Errorf("globex = %+v", globex)
+	}
+}
+
+func TestGiantSwarmLabels(t *testing.T) {
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "org-acme",
+		Name:      "prod",
+		Labels: map[string]string{
+			OrganizationLabel:           "acme",
+			ReleaseVersionLabel:         "29.1.0",
+			ServicePriorityLabel:        "highest",
+			"giantswarm.io/cluster":     "prod",
+			"cluster.x-k8s.io/provider": "aws",
+			"notgiantswarm.io/label":    "x",
+		},
+	}}
+	status := newClusterStatus(cluster, nil, nil)
+	if len(status.GiantSwarmLabels) != 4 {
+		t.Errorf("GiantSwarmLabels = %v, want the four giantswarm.io labels", status.GiantSwarmLabels)
+	}
+
+	info := FormatClusterInfo(status)
+	for _, want := range []string{"Organization: acme", "Release: 29.1.0", "Service Priority: highest", "giantswarm.io/cluster: prod"} {
+		if !strings.Contains(info, want) {
+			t.Errorf("FormatClusterInfo() = %q, want %q", info, want)
+		}
+	}
+}
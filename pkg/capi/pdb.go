@@ -0,0 +1,251 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DisruptionOperation is an operation evicting the pods of workload nodes
+type DisruptionOperation string
+
+// Operations checked against PodDisruptionBudgets
+const (
+	// DisruptionDrain drains a single node
+	DisruptionDrain DisruptionOperation = "drain"
+	// DisruptionScaleDown deletes machines of a machine deployment, any of
+	// which may be chosen
+	DisruptionScaleDown DisruptionOperation = "scale-down"
+	// DisruptionUpgrade replaces the machines of a cluster, or of one of its
+	// machine deployments, one after the other
+	DisruptionUpgrade DisruptionOperation = "upgrade"
+)
+
+// PDBAnalysisOptions describes the operation to check
+type PDBAnalysisOptions struct {
+	Operation DisruptionOperation
+	// Node or Machine selects the node of a drain
+	Node    string
+	Machine string
+	// MachineDeployment selects the machines of a scale-down, or limits an
+	// upgrade to them
+	MachineDeployment string
+}
+
+// PDBAnalysis reports the PodDisruptionBudgets protecting the pods an
+// operation would evict
+type PDBAnalysis struct {
+	Operation DisruptionOperation `json:"operation"`
+	Nodes     []string            `json:"nodes"`
+	// Pods is the number of pods evicted from the nodes, leaving out
+	// DaemonSet and static pods
+	Pods int `json:"pods"`
+	// Blocked reports whether a budget would stop evictions
+	Blocked bool          `json:"blocked"`
+	Budgets []PDBConflict `json:"budgets"`
+}
+
+// PDBConflict is a PodDisruptionBudget protecting evicted pods
+type PDBConflict struct {
+	Namespace          string   `json:"namespace"`
+	Name               string   `json:"name"`
+	MinAvailable       string   `json:"minAvailable,omitempty"`
+	MaxUnavailable     string   `json:"maxUnavailable,omitempty"`
+	ExpectedPods       int32    `json:"expectedPods"`
+	CurrentHealthy     int32    `json:"currentHealthy"`
+	DesiredHealthy     int32    `json:"desiredHealthy"`
+	DisruptionsAllowed int32    `json:"disruptionsAllowed"`
+	AffectedPods       int      `json:"affectedPods"`
+	AffectedNodes      []string `json:"affectedNodes"`
+	// Blocking budgets allow no disruption, so evictions fail
+	Blocking bool `json:"blocking"`
+	// Permanent budgets allow no disruption even with every pod healthy,
+	// so evictions never succeed without changing them
+	Permanent bool   `json:"permanent,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// matchingPDB returns the PodDisruptionBudget selecting a pod, if any
+func matchingPDB(pdbs []policyv1.PodDisruptionBudget, pod *corev1.Pod) *policyv1.PodDisruptionBudget {
+	for i, pdb := range pdbs {
+		if pdb.Namespace != pod.Namespace || pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() || !selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		return &pdbs[i]
+	}
+	return nil
+}
+
+// AnalyzePDBConflicts checks the PodDisruptionBudgets of a workload cluster
+// against the pods a drain, scale-down or upgrade would evict, reporting the
+// budgets that allow no disruption and would leave the operation stuck.
+// Budgets that allow disruptions but fewer than the pods evicted from a
+// node are reported too, as they slow the drain down.
+func (c *Client) AnalyzePDBConflicts(ctx context.Context, namespace, clusterName string, opts PDBAnalysisOptions) (*PDBAnalysis, error) {
+	nodes, err := c.disruptedNodes(ctx, namespace, clusterName, opts)
+	if err != nil {
+		return nil, err
+	}
+	analysis := &PDBAnalysis{Operation: opts.Operation, Nodes: nodes, Budgets: []PDBConflict{}}
+	if len(nodes) == 0 {
+		return analysis, nil
+	}
+
+	kubeconfig, err := c.GetKubeconfig(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	clientset, err := c.workloadClientset(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", workloadError("Pod", client.ObjectKey{}, err))
+	}
+	pdbs, err := clientset.PolicyV1().PodDisruptionBudgets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pod disruption budgets: %w", workloadError("PodDisruptionBudget", client.ObjectKey{}, err))
+	}
+
+	disrupted := map[string]bool{}
+	for _, node := range nodes {
+		disrupted[node] = true
+	}
+	conflicts := map[string]*PDBConflict{}
+	// podsPerNode counts the evicted pods of each budget on each node
+	podsPerNode := map[string]map[string]int{}
+	for _, pod := range pods.Items {
+		if !disrupted[pod.Spec.NodeName] || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		// Drains leave DaemonSet and static pods in place
+		if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+			continue
+		}
+		if owner := metav1.GetControllerOf(&pod); owner != nil && owner.Kind == "DaemonSet" {
+			continue
+		}
+		analysis.Pods++
+		pdb := matchingPDB(pdbs.Items, &pod)
+		if pdb == nil {
+			continue
+		}
+		key := pdb.Namespace + "/" + pdb.Name
+		conflict, ok := conflicts[key]
+		if !ok {
+			conflict = &PDBConflict{
+				Namespace:          pdb.Namespace,
+				Name:               pdb.Name,
+				ExpectedPods:       pdb.Status.ExpectedPods,
+				CurrentHealthy:     pdb.Status.CurrentHealthy,
+				DesiredHealthy:     pdb.Status.DesiredHealthy,
+				DisruptionsAllowed: pdb.Status.DisruptionsAllowed,
+			}
+			if pdb.Spec.MinAvailable != nil {
+				conflict.MinAvailable = pdb.Spec.MinAvailable.String()
+			}
+			if pdb.Spec.MaxUnavailable != nil {
+				conflict.MaxUnavailable = pdb.Spec.MaxUnavailable.String()
+			}
+			conflicts[key] = conflict
+			podsPerNode[key] = map[string]int{}
+		}
+		conflict.AffectedPods++
+		podsPerNode[key][pod.Spec.NodeName]++
+	}
+
+	for key, conflict := range conflicts {
+		mostPods := 0
+		for node, count := range podsPerNode[key] {
+			conflict.AffectedNodes = append(conflict.AffectedNodes, node)
+			mostPods = max(mostPods, count)
+		}
+		sort.Strings(conflict.AffectedNodes)
+		conflict.Permanent = conflict.ExpectedPods > 0 && conflict.DesiredHealthy >= conflict.ExpectedPods
+		switch {
+		case conflict.Permanent:
+			conflict.Blocking = true
+			conflict.Reason = fmt.Sprintf("the budget requires all %d pods to be healthy, so no pod can ever be evicted", conflict.ExpectedPods)
+		case conflict.DisruptionsAllowed == 0:
+			conflict.Blocking = true
+			conflict.Reason = fmt.Sprintf("only %d of %d pods are healthy and %d must be, so evictions fail until more pods become healthy", conflict.CurrentHealthy, conflict.ExpectedPods, conflict.DesiredHealthy)
+		case int(conflict.DisruptionsAllowed) < mostPods:
+			conflict.Reason = fmt.Sprintf("%d pods run on one node but %d disruptions are allowed, so the drain waits for evicted pods to be rescheduled", mostPods, conflict.DisruptionsAllowed)
+		}
+		if conflict.Blocking {
+			analysis.Blocked = true
+		}
+		analysis.Budgets = append(analysis.Budgets, *conflict)
+	}
+	sort.Slice(analysis.Budgets, func(i, j int) bool {
+		a, b := analysis.Budgets[i], analysis.Budgets[j]
+		if a.Blocking != b.Blocking {
+			return a.Blocking
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return analysis, nil
+}
+
+// disruptedNodes returns the workload nodes an operation evicts pods from
+func (c *Client) disruptedNodes(ctx context.Context, namespace, clusterName string, opts PDBAnalysisOptions) ([]string, error) {
+	switch opts.Operation {
+	case DisruptionDrain:
+		if opts.Node != "" {
+			return []string{opts.Node}, nil
+		}
+		if opts.Machine == "" {
+			return nil, errorf(ErrInvalidArgument, "a drain requires a node or a machine")
+		}
+		machine, err := c.GetMachine(ctx, namespace, opts.Machine)
+		if err != nil {
+			return nil, err
+		}
+		if machine.Status.NodeRef == nil {
+			return nil, errorf(ErrPreconditionFailed, "machine %s/%s has no node", namespace, opts.Machine)
+		}
+		return []string{machine.Status.NodeRef.Name}, nil
+	case DisruptionScaleDown, DisruptionUpgrade:
+		var listOpts []ListOption
+		if opts.MachineDeployment != "" {
+			md, err := c.GetMachineDeployment(ctx, namespace, opts.MachineDeployment)
+			if err != nil {
+				return nil, err
+			}
+			if md.Spec.ClusterName != clusterName {
+				return nil, errorf(ErrInvalidArgument, "machine deployment %s/%s belongs to cluster %s", namespace, md.Name, md.Spec.ClusterName)
+			}
+			listOpts = append(listOpts, WithLabelSelector(clusterv1.MachineDeploymentNameLabel+"="+md.Name))
+		} else if opts.Operation == DisruptionScaleDown {
+			return nil, errorf(ErrInvalidArgument, "a scale-down requires a machine deployment")
+		}
+		machines, err := c.ListMachines(ctx, namespace, clusterName, listOpts...)
+		if err != nil {
+			return nil, err
+		}
+		nodes := []string{}
+		for _, machine := range machines.Items {
+			if machine.Status.NodeRef != nil {
+				nodes = append(nodes, machine.Status.NodeRef.Name)
+			}
+		}
+		sort.Strings(nodes)
+		return nodes, nil
+	}
+	return nil, errorf(ErrInvalidArgument, "unsupported operation %q, supported operations: %s, %s, %s", opts.Operation, DisruptionDrain, DisruptionScaleDown, DisruptionUpgrade)
+}
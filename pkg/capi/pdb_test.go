@@ -0,0 +1,137 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAnalyzePDBConflicts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	machine := func(name, md, node string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: name, Labels: map[string]string{
+				clusterv1.ClusterNameLabel:           "prod",
+				clusterv1.MachineDeploymentNameLabel: md,
+			}},
+			Spec:   clusterv1.MachineSpec{ClusterName: "prod"},
+			Status: clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: node}},
+		}
+	}
+	pod := func(namespace, name, node string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+			Spec:       corev1.PodSpec{NodeName: node},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	pdb := func(namespace, name, app string, minAvailable intstr.IntOrString, status policyv1.PodDisruptionBudgetStatus) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+			},
+			Status: status,
+		}
+	}
+	agent := pod("kube-system", "agent-1", "node-1", map[string]string{"app": "db"})
+	agent.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "agent", Controller: ptr.To(true)}}
+
+	workload := k8sfake.NewClientset(
+		// Requires every pod: never evictable
+		pdb("shop", "db", "db", intstr.FromInt32(3), policyv1.PodDisruptionBudgetStatus{ExpectedPods: 3, CurrentHealthy: 3, DesiredHealthy: 3}),
+		// A pod is unhealthy: evictions wait
+		pdb("shop", "web", "web", intstr.FromString("50%"), policyv1.PodDisruptionBudgetStatus{ExpectedPods: 4, CurrentHealthy: 2, DesiredHealthy: 2}),
+		pdb("shop", "api", "api", intstr.FromInt32(1), policyv1.PodDisruptionBudgetStatus{ExpectedPods: 3, CurrentHealthy: 3, DesiredHealthy: 1, DisruptionsAllowed: 2}),
+		pod("shop", "db-0", "node-1", map[string]string{"app": "db"}),
+		pod("shop", "web-a", "node-2", map[string]string{"app": "web"}),
+		pod("shop", "api-a", "node-1", map[string]string{"app": "api"}),
+		pod("shop", "api-b", "node-1", map[string]string{"app": "api"}),
+		pod("shop", "api-c", "node-1", map[string]string{"app": "api"}),
+		pod("shop", "worker", "node-1", nil),
+		agent,
+	)
+	c := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&clusterv1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-md-0"}, Spec: clusterv1.MachineDeploymentSpec{ClusterName: "prod"}},
+			&clusterv1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-md-1"}, Spec: clusterv1.MachineDeploymentSpec{ClusterName: "prod"}},
+			&clusterv1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "dev-md-0"}, Spec: clusterv1.MachineDeploymentSpec{ClusterName: "dev"}},
+			machine("prod-md-0-a", "prod-md-0", "node-1"),
+			machine("prod-md-1-a", "prod-md-1", "node-2"),
+			&clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-md-1-b", Labels: map[string]string{clusterv1.ClusterNameLabel: "prod"}},
+				Spec:       clusterv1.MachineSpec{ClusterName: "prod"},
+			},
+		).Build(),
+		k8sClient: k8sfake.NewClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-kubeconfig"},
+			Data:       map[string][]byte{"value": []byte("kubeconfig")},
+		}),
+		newWorkloadClientset: func(kubeconfig string) (kubernetes.Interface, error) {
+			return workload, nil
+		},
+	}
+	ctx := context.Background()
+
+	drain, err := c.AnalyzePDBConflicts(ctx, "org-acme", "prod", PDBAnalysisOptions{Operation: DisruptionDrain, Machine: "prod-md-0-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !drain.Blocked || drain.Pods != 5 || len(drain.Budgets) != 2 {
+		t.Fatalf("drain = %+v, want 5 evicted pods and 2 budgets", drain)
+	}
+	if db := drain.Budgets[0]; db.Name != "db" || !db.Blocking || !db.Permanent || db.AffectedPods != 1 || db.MinAvailable != "3" {
+		t.Errorf("db = %+v, want a permanently blocking budget", db)
+	}
+	if api := drain.Budgets[1]; api.Name != "api" || api.Blocking || api.AffectedPods != 3 || api.Reason == "" {
+		t.Errorf("api = %+v, want a slow but not blocking budget", api)
+	}
+
+	scaleDown, err := c.AnalyzePDBConflicts(ctx, "org-acme", "prod", PDBAnalysisOptions{Operation: DisruptionScaleDown, MachineDeployment: "prod-md-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !scaleDown.Blocked || len(scaleDown.Budgets) != 1 {
+		t.Fatalf("scale-down = %+v, want the web budget", scaleDown)
+	}
+	if web := scaleDown.Budgets[0]; !web.Blocking || web.Permanent || web.AffectedNodes[0] != "node-2" {
+		t.Errorf("web = %+v, want a temporarily blocking budget", web)
+	}
+
+	upgrade, err := c.AnalyzePDBConflicts(ctx, "org-acme", "prod", PDBAnalysisOptions{Operation: DisruptionUpgrade})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(upgrade.Nodes) != 2 || len(upgrade.Budgets) != 3 || upgrade.Pods != 6 {
+		t.Errorf("upgrade = %+v, want 2 nodes, 6 pods and 3 budgets", upgrade)
+	}
+
+	for name, opts := range map[string]PDBAnalysisOptions{
+		"unknown operation":        {Operation: "reboot"},
+		"scale-down without md":    {Operation: DisruptionScaleDown},
+		"drain without node":       {Operation: DisruptionDrain},
+		"md of another cluster":    {Operation: DisruptionUpgrade, MachineDeployment: "dev-md-0"},
+		"machine without its node": {Operation: DisruptionDrain, Machine: "prod-md-1-b"},
+	} {
+		if _, err := c.AnalyzePDBConflicts(ctx, "org-acme", "prod", opts); err == nil {
+			t.Errorf("%s: AnalyzePDBConflicts succeeded", name)
+		} else if !errors.Is(err, ErrInvalidArgument) && !errors.Is(err, ErrPreconditionFailed) {
+			t.Errorf("%s: err = %v, want an invalid argument or precondition", name, err)
+		}
+	}
+}
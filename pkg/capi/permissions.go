@@ -0,0 +1,184 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+)
+
+// PermissionCheck describes a single verb on a Kubernetes resource
+type PermissionCheck struct {
+	Verb        string `json:"verb"`
+	Group       string `json:"group,omitempty"`
+	Resource    string `json:"resource"`
+	Subresource string `json:"subresource,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+// String renders the check in kubectl auth can-i style
+func (p PermissionCheck) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource = fmt.Sprintf("%s.%s", p.Resource, p.Group)
+	}
+	if p.Subresource != "" {
+		resource = fmt.Sprintf("%s/%s", resource, p.Subresource)
+	}
+	if p.Name != "" {
+		resource = fmt.Sprintf("%s/%s", resource, p.Name)
+	}
+
+	scope := "cluster-wide"
+	if p.Namespace != "" {
+		scope = fmt.Sprintf("in namespace %s", p.Namespace)
+	}
+	return fmt.Sprintf("%s %s %s", p.Verb, resource, scope)
+}
+
+// PermissionResult is the outcome of a permission check
+type PermissionResult struct {
+	PermissionCheck
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// MissingPermissionsError is returned by RequirePermissions when the server's
+// identity lacks RBAC permissions required for an operation
+type MissingPermissionsError struct {
+	Missing []PermissionCheck
+}
+
+// Error returns an actionable description of the missing permissions
+func (e *MissingPermissionsError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("missing RBAC permissions: the server's identity cannot ")
+	for i, check := range e.Missing {
+		if i > 0 {
+			sb.WriteString("; ")
+		}
+		sb.WriteString(check.String())
+	}
+	sb.WriteString(" (grant these verbs in a Role/ClusterRole bound to the server's identity)")
+	return sb.String()
+}
+
+// CheckPermission asks the API server whether the current identity may perform the given action
+func (c *Client) CheckPermission(ctx context.Context, check PermissionCheck) (*PermissionResult, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:        check.Verb,
+				Group:       check.Group,
+				Resource:    check.Resource,
+				Subresource: check.Subresource,
+				Namespace:   check.Namespace,
+				Name:        check.Name,
+			},
+		},
+	}
+
+	result, err := c.k8sClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to review access for %s: %w", check, err)
+	}
+
+	reason := result.Status.Reason
+	if result.Status.EvaluationError != "" {
+		reason = strings.TrimSpace(reason + " " + result.Status.EvaluationError)
+	}
+
+	return &PermissionResult{
+		PermissionCheck: check,
+		Allowed:         result.Status.Allowed,
+		Reason:          reason,
+	}, nil
+}
+
+// CheckPermissions runs several permission checks
+func (c *Client) CheckPermissions(ctx context.Context, checks []PermissionCheck) ([]PermissionResult, error) {
+	results := make([]PermissionResult, 0, len(checks))
+	for _, check := range checks {
+		result, err := c.CheckPermission(ctx, check)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *result)
+	}
+	return results, nil
+}
+
+// RequirePermissions is a preflight helper that fails with a *MissingPermissionsError
+// if any of the checks is denied
+func (c *Client) RequirePermissions(ctx context.Context, checks ...PermissionCheck) error {
+	results, err := c.CheckPermissions(ctx, checks)
+	if err != nil {
+		return err
+	}
+
+	var missing []PermissionCheck
+	for _, result := range results {
+		if !result.Allowed {
+			missing = append(missing, result.PermissionCheck)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingPermissionsError{Missing: missing}
+	}
+	return nil
+}
+
+// DefaultPermissionChecks returns the checks covering the core capabilities of the server
+func DefaultPermissionChecks(namespace string) []PermissionCheck {
+	capiGroup := clusterv1.GroupVersion.Group
+	kcpGroup := controlplanev1.GroupVersion.Group
+
+	var checks []PermissionCheck
+	for _, resource := range []string{"clusters", "machines", "machinedeployments", "machinesets"} {
+		for _, verb := range []string{"get", "list", "patch", "delete"} {
+			checks = append(checks, PermissionCheck{Verb: verb, Group: capiGroup, Resource: resource, Namespace: namespace})
+		}
+	}
+	checks = append(checks,
+		PermissionCheck{Verb: "create", Group: capiGroup, Resource: "clusters", Namespace: namespace},
+		PermissionCheck{Verb: "create", Group: capiGroup, Resource: "machinedeployments", Namespace: namespace},
+		PermissionCheck{Verb: "get", Group: kcpGroup, Resource: "kubeadmcontrolplanes", Namespace: namespace},
+		PermissionCheck{Verb: "patch", Group: kcpGroup, Resource: "kubeadmcontrolplanes", Namespace: namespace},
+		PermissionCheck{Verb: "get", Resource: "secrets", Namespace: namespace},
+		PermissionCheck{Verb: "update", Resource: "nodes"},
+	)
+	return checks
+}
+
+// upgradePermissionChecks returns the permissions UpgradeCluster needs before touching anything
+func upgradePermissionChecks(namespace string, upgradeWorkers bool) []PermissionCheck {
+	checks := []PermissionCheck{
+		{Verb: "patch", Group: controlplanev1.GroupVersion.Group, Resource: "kubeadmcontrolplanes", Namespace: namespace},
+	}
+	if upgradeWorkers {
+		checks = append(checks,
+			PermissionCheck{Verb: "list", Group: clusterv1.GroupVersion.Group, Resource: "machinedeployments", Namespace: namespace},
+			PermissionCheck{Verb: "patch", Group: clusterv1.GroupVersion.Group, Resource: "machinedeployments", Namespace: namespace},
+		)
+	}
+	return checks
+}
+
+// aksUpgradePermissionChecks are the permissions upgradeAKSCluster needs
+func aksUpgradePermissionChecks(namespace string, upgradeWorkers bool) []PermissionCheck {
+	checks := []PermissionCheck{
+		{Verb: "patch", Group: azureManagedControlPlaneGVK.Group, Resource: "azuremanagedcontrolplanes", Namespace: namespace},
+		{Verb: "list", Group: clusterv1.GroupVersion.Group, Resource: "machinepools", Namespace: namespace},
+	}
+	if upgradeWorkers {
+		checks = append(checks,
+			PermissionCheck{Verb: "patch", Group: clusterv1.GroupVersion.Group, Resource: "machinepools", Namespace: namespace},
+		)
+	}
+	return checks
+}
@@ -0,0 +1,74 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newAccessReviewClient returns a client whose access reviews allow only the given verbs
+func newAccessReviewClient(allowedVerbs ...string) *Client {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		for _, verb := range allowedVerbs {
+			if review.Spec.ResourceAttributes.Verb == verb {
+				review.Status.Allowed = true
+			}
+		}
+		return true, review, nil
+	})
+	return &Client{k8sClient: clientset}
+}
+
+func TestRequirePermissions(t *testing.T) {
+	c := newAccessReviewClient("get", "list")
+	ctx := context.Background()
+
+	if err := c.RequirePermissions(ctx, PermissionCheck{Verb: "get", Group: "cluster.x-k8s.io", Resource: "clusters"}); err != nil {
+		t.Fatalf("RequirePermissions() unexpected error = %v", err)
+	}
+
+	err := c.RequirePermissions(ctx,
+		PermissionCheck{Verb: "list", Group: "cluster.x-k8s.io", Resource: "machinedeployments", Namespace: "org-acme"},
+		PermissionCheck{Verb: "update", Group: "cluster.x-k8s.io", Resource: "machinedeployments", Namespace: "org-acme"},
+	)
+	var missing *MissingPermissionsError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected *MissingPermissionsError, got %v", err)
+	}
+	if len(missing.Missing) != 1 || missing.Missing[0].Verb != "update" {
+		t.Errorf("unexpected missing permissions: %+v", missing.Missing)
+	}
+	if !strings.Contains(err.Error(), "update machinedeployments.cluster.x-k8s.io in namespace org-acme") {
+		t.Errorf("error message is not actionable: %s", err)
+	}
+}
+
+func TestPermissionCheckString(t *testing.T) {
+	tests := []struct {
+		check PermissionCheck
+		want  string
+	}{
+		{
+			check: PermissionCheck{Verb: "update", Resource: "nodes", Name: "worker-1"},
+			want:  "update nodes/worker-1 cluster-wide",
+		},
+		{
+			check: PermissionCheck{Verb: "patch", Group: "cluster.x-k8s.io", Resource: "machinedeployments", Subresource: "scale", Namespace: "default"},
+			want:  "patch machinedeployments.cluster.x-k8s.io/scale in namespace default",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.check.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
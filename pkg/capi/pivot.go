@@ -0,0 +1,447 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultPivotProviderTimeout bounds the wait for the providers of the
+	// workload cluster to become ready
+	DefaultPivotProviderTimeout = 10 * time.Minute
+	// DefaultPivotVerifyTimeout bounds the wait for the moved cluster to be
+	// reconciled by the controllers of the workload cluster
+	DefaultPivotVerifyTimeout = 15 * time.Minute
+)
+
+// pivotRefPaths are the references followed from the objects of a cluster to
+// the objects they consist of
+var pivotRefPaths = [][]string{
+	{"spec", "infrastructureRef"},
+	{"spec", "controlPlaneRef"},
+	{"spec", "machineTemplate", "infrastructureRef"},
+	{"spec", "template", "spec", "bootstrap", "configRef"},
+	{"spec", "template", "spec", "infrastructureRef"},
+	{"spec", "bootstrap", "configRef"},
+}
+
+// pivotKinds are the Cluster API kinds listed by the cluster name label,
+// besides the objects referenced from them
+var pivotKinds = []string{"MachineDeployment", "MachineSet", "MachinePool", "Machine", "MachineHealthCheck"}
+
+// PivotOptions contains options for making a workload cluster manage itself
+type PivotOptions struct {
+	Namespace string
+	Name      string
+	// Repository provides the components of the providers
+	Repository ProviderRepository
+	// Providers are installed on the workload cluster, as clusterctl
+	// provider names with an optional version. When empty, the core,
+	// bootstrap, control plane and infrastructure providers of the
+	// management cluster are installed in their versions.
+	Providers []string
+	// Variables resolves the ${VAR} placeholders of the components
+	Variables func(name string) (string, bool)
+	// ProviderTimeout and VerifyTimeout bound the waits for the providers
+	// and the reconciliation of the moved cluster; they default to
+	// DefaultPivotProviderTimeout and DefaultPivotVerifyTimeout
+	ProviderTimeout time.Duration
+	VerifyTimeout   time.Duration
+	// DryRun reports the providers and objects without changing anything
+	DryRun bool
+	// OnProgress is called when a step finishes
+	OnProgress func(step PivotStep)
+}
+
+// PivotStep is a step of a pivot and its outcome
+type PivotStep struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"` // done, skipped or failed
+	Message string `json:"message,omitempty"`
+}
+
+// Pivot describes a cluster moved into itself
+type Pivot struct {
+	Namespace string           `json:"namespace"`
+	Cluster   string           `json:"cluster"`
+	Steps     []PivotStep      `json:"steps"`
+	Providers []ProviderChange `json:"providers"`
+	// Moved are the objects created in the workload cluster, Skipped those
+	// that existed there already or are not moved
+	Moved   []RestoredObject `json:"moved"`
+	Skipped []RestoredObject `json:"skipped,omitempty"`
+	// Deleted is the number of objects removed from the management cluster
+	Deleted int `json:"deleted"`
+	// SelfManaged is set once the objects of the cluster live in the
+	// cluster itself
+	SelfManaged bool `json:"selfManaged"`
+	// Verified is set when the moved cluster was reconciled to Ready by
+	// its own controllers
+	Verified bool `json:"verified"`
+	DryRun   bool `json:"dryRun"`
+}
+
+// step records the outcome of a step and reports it
+func (p *Pivot) step(opts PivotOptions, name, status, message string) {
+	step := PivotStep{Name: name, Status: status, Message: message}
+	p.Steps = append(p.Steps, step)
+	if opts.OnProgress != nil {
+		opts.OnProgress(step)
+	}
+}
+
+// workloadCAPIClient connects a CAPI client to a workload cluster with its
+// admin kubeconfig, to manage the Cluster API objects it holds
+func (c *Client) workloadCAPIClient(kubeconfig string) (*Client, error) {
+	if c.newWorkloadCAPIClient != nil {
+		return c.newWorkloadCAPIClient(kubeconfig)
+	}
+	config, err := c.workloadConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientForConfig(config)
+}
+
+// PivotCluster makes a workload cluster manage itself, the bootstrapping
+// pattern of clusterctl init and clusterctl move: the providers of the
+// management cluster are installed on the workload cluster, the Cluster is
+// paused, its objects are created in the workload cluster with their owner
+// references re-linked and removed from the management cluster, and the
+// moved Cluster is resumed and waited for until its own controllers report
+// it Ready. When the objects cannot all be created, the created ones are
+// removed again and the Cluster is resumed where it was.
+func (c *Client) PivotCluster(ctx context.Context, opts PivotOptions) (*Pivot, error) {
+	if opts.ProviderTimeout <= 0 {
+		opts.ProviderTimeout = DefaultPivotProviderTimeout
+	}
+	if opts.VerifyTimeout <= 0 {
+		opts.VerifyTimeout = DefaultPivotVerifyTimeout
+	}
+	pivot := &Pivot{Namespace: opts.Namespace, Cluster: opts.Name, Steps: []PivotStep{}, Moved: []RestoredObject{}, DryRun: opts.DryRun}
+
+	cluster, err := c.GetCluster(ctx, opts.Namespace, opts.Name)
+	if err != nil {
+		return nil, err
+	}
+	if cluster.Spec.Topology != nil {
+		return nil, errorf(ErrPreconditionFailed, "cluster %s/%s is managed by ClusterClass %s, which would have to move along with it", opts.Namespace, opts.Name, cluster.Spec.Topology.Class)
+	}
+	if !cluster.Status.ControlPlaneReady || !cluster.Status.InfrastructureReady {
+		return nil, errorf(ErrPreconditionFailed, "cluster %s/%s is not provisioned yet (control plane ready: %v, infrastructure ready: %v)",
+			opts.Namespace, opts.Name, cluster.Status.ControlPlaneReady, cluster.Status.InfrastructureReady)
+	}
+	kubeconfig, err := c.GetKubeconfig(ctx, opts.Namespace, opts.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	target, err := c.workloadCAPIClient(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := target.GetCluster(ctx, opts.Namespace, opts.Name); err == nil {
+		return nil, errorf(ErrAlreadyExists, "cluster %s/%s already exists in the workload cluster", opts.Namespace, opts.Name)
+	}
+
+	// Install the providers of the management cluster
+	providers := opts.Providers
+	if len(providers) == 0 {
+		installed, err := c.ListInstalledProviders(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, provider := range installed {
+			if _, ok := providerOrder[provider.Type]; !ok {
+				continue
+			}
+			spec := provider.Name
+			if provider.Version != "" {
+				spec += ":" + provider.Version
+			}
+			providers = append(providers, spec)
+		}
+		if len(providers) == 0 {
+			return nil, errorf(ErrPreconditionFailed, "no providers found on the management cluster, name them explicitly")
+		}
+	}
+	pivot.Providers, err = target.InstallProviders(ctx, opts.Repository, ProviderOptions{Providers: providers, Variables: opts.Variables, DryRun: opts.DryRun})
+	if err != nil {
+		pivot.step(opts, "install providers", "failed", err.Error())
+		return pivot, fmt.Errorf("failed to install providers on the workload cluster: %w", err)
+	}
+	pivot.step(opts, "install providers", "done", fmt.Sprintf("%d providers: %s", len(providers), strings.Join(providers, ", ")))
+	if !opts.DryRun {
+		if err := target.waitForProviders(ctx, pivot.Providers, opts.ProviderTimeout); err != nil {
+			pivot.step(opts, "wait for providers", "failed", err.Error())
+			return pivot, err
+		}
+		pivot.step(opts, "wait for providers", "done", "all provider controllers are ready")
+	}
+
+	objects, err := c.pivotObjects(ctx, cluster)
+	if err != nil {
+		return pivot, err
+	}
+	if opts.DryRun {
+		for _, obj := range objects {
+			pivot.Moved = append(pivot.Moved, RestoredObject{Kind: obj.GetKind(), Name: obj.GetName()})
+		}
+		pivot.step(opts, "move objects", "skipped", fmt.Sprintf("dry run, %d objects would move", len(objects)))
+		return pivot, nil
+	}
+
+	// Pause the source, so neither side reconciles during the move
+	if err := c.setClusterPaused(ctx, opts.Namespace, opts.Name, true); err != nil {
+		return pivot, err
+	}
+	pivot.step(opts, "pause cluster", "done", "reconciliation paused on the management cluster")
+	for _, obj := range objects {
+		if obj.GetKind() == "Cluster" && obj.GroupVersionKind().Group == clusterv1.GroupVersion.Group {
+			// The copy stays paused until the source is gone
+			_ = unstructured.SetNestedField(obj.Object, true, "spec", "paused")
+		}
+	}
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: opts.Namespace}}
+	if err := target.ctrlClient.Create(ctx, namespace); err != nil && !apierrors.IsAlreadyExists(err) {
+		return pivot, c.abortPivot(ctx, pivot, opts, target, objects, nil, fmt.Errorf("failed to create namespace: %w", workloadError("Namespace", client.ObjectKeyFromObject(namespace), err)))
+	}
+	restore, err := target.RestoreCluster(ctx, RestoreClusterOptions{Namespace: opts.Namespace, Objects: objects, KeepPaused: true})
+	if restore != nil {
+		pivot.Moved, pivot.Skipped = restore.Restored, restore.Skipped
+	}
+	if err == nil && len(restore.Failed) > 0 {
+		var failed []string
+		for _, obj := range restore.Failed {
+			failed = append(failed, fmt.Sprintf("%s %s: %s", obj.Kind, obj.Name, obj.Reason))
+		}
+		err = fmt.Errorf("failed to create %d objects in the workload cluster: %s", len(failed), strings.Join(failed, "; "))
+	}
+	if err != nil {
+		return pivot, c.abortPivot(ctx, pivot, opts, target, objects, restore, err)
+	}
+	pivot.step(opts, "move objects", "done", fmt.Sprintf("%d objects created in the workload cluster, owner references re-linked", len(restore.Restored)))
+
+	// Remove the source objects without their finalizers, so the
+	// infrastructure is not deleted with them
+	var errs []error
+	for i := len(objects) - 1; i >= 0; i-- {
+		if err := c.deleteWithoutFinalizers(ctx, objects[i]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		pivot.Deleted++
+	}
+	if err := errors.Join(errs...); err != nil {
+		pivot.step(opts, "delete source objects", "failed", err.Error())
+		return pivot, fmt.Errorf("the cluster was moved but not removed from the management cluster, which must not resume it: %w", err)
+	}
+	pivot.step(opts, "delete source objects", "done", fmt.Sprintf("%d objects removed from the management cluster", pivot.Deleted))
+	pivot.SelfManaged = true
+
+	if err := target.setClusterPaused(ctx, opts.Namespace, opts.Name, false); err != nil {
+		pivot.step(opts, "resume cluster", "failed", err.Error())
+		return pivot, err
+	}
+	pivot.step(opts, "resume cluster", "done", "reconciliation resumed in the workload cluster")
+
+	err = target.WaitForClusterReady(ctx, opts.Namespace, opts.Name, WaitOptions{Timeout: opts.VerifyTimeout})
+	if err != nil {
+		pivot.step(opts, "verify reconciliation", "failed", err.Error())
+		return pivot, nil
+	}
+	pivot.Verified = true
+	pivot.step(opts, "verify reconciliation", "done", "the cluster is Ready under its own controllers")
+	return pivot, nil
+}
+
+// abortPivot removes the objects created in the workload cluster and resumes
+// the cluster on the management cluster after a failed move
+func (c *Client) abortPivot(ctx context.Context, pivot *Pivot, opts PivotOptions, target *Client, objects []*unstructured.Unstructured, restore *ClusterRestore, cause error) error {
+	pivot.step(opts, "move objects", "failed", cause.Error())
+	created := map[string]bool{}
+	if restore != nil {
+		for _, obj := range restore.Restored {
+			created[obj.Kind+"/"+obj.Name] = true
+		}
+	}
+	var errs []error
+	for i := len(objects) - 1; i >= 0; i-- {
+		if !created[objects[i].GetKind()+"/"+objects[i].GetName()] {
+			continue
+		}
+		if err := target.deleteWithoutFinalizers(ctx, objects[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := c.setClusterPaused(ctx, opts.Namespace, opts.Name, false); err != nil {
+		errs = append(errs, err)
+	}
+	pivot.Moved = []RestoredObject{}
+	if err := errors.Join(errs...); err != nil {
+		pivot.step(opts, "roll back", "failed", err.Error())
+		return fmt.Errorf("%w; rolling back failed: %v", cause, err)
+	}
+	pivot.step(opts, "roll back", "done", "created objects removed, cluster resumed on the management cluster")
+	return cause
+}
+
+// pivotObjects collects the objects of a cluster: the Cluster, the objects
+// labeled with its name and all objects they reference, and its Secrets
+func (c *Client) pivotObjects(ctx context.Context, cluster *clusterv1.Cluster) ([]*unstructured.Unstructured, error) {
+	seen := map[string]bool{}
+	var objects []*unstructured.Unstructured
+	var queue []*unstructured.Unstructured
+	add := func(obj *unstructured.Unstructured) {
+		key := obj.GroupVersionKind().GroupKind().String() + "/" + obj.GetName()
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		objects = append(objects, obj)
+		queue = append(queue, obj)
+	}
+
+	source := &unstructured.Unstructured{}
+	source.SetGroupVersionKind(clusterv1.GroupVersion.WithKind("Cluster"))
+	key := client.ObjectKeyFromObject(cluster)
+	if err := c.ctrlClient.Get(ctx, key, source); err != nil {
+		return nil, fmt.Errorf("failed to get cluster: %w", resourceError("Cluster", key, err))
+	}
+	add(source)
+	labels := client.MatchingLabels{clusterv1.ClusterNameLabel: cluster.Name}
+	for _, kind := range pivotKinds {
+		items, err := c.listUnstructured(ctx, clusterv1.GroupVersion.WithKind(kind+"List"), client.InNamespace(cluster.Namespace), labels)
+		if err != nil {
+			return nil, err
+		}
+		for i := range items {
+			items[i].SetGroupVersionKind(clusterv1.GroupVersion.WithKind(kind))
+			add(&items[i])
+		}
+	}
+
+	for len(queue) > 0 {
+		obj := queue[0]
+		queue = queue[1:]
+		for _, path := range pivotRefPaths {
+			ref, ok, _ := unstructured.NestedStringMap(obj.Object, path...)
+			if !ok || ref["name"] == "" {
+				continue
+			}
+			gv, err := schema.ParseGroupVersion(ref["apiVersion"])
+			if err != nil {
+				return nil, fmt.Errorf("invalid reference %s of %s %s: %w", strings.Join(path, "."), obj.GetKind(), obj.GetName(), err)
+			}
+			referenced := &unstructured.Unstructured{}
+			referenced.SetGroupVersionKind(gv.WithKind(ref["kind"]))
+			refKey := client.ObjectKey{Namespace: cluster.Namespace, Name: ref["name"]}
+			if err := c.ctrlClient.Get(ctx, refKey, referenced); err != nil {
+				if apierrors.IsNotFound(err) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to get %s: %w", ref["kind"], resourceError(ref["kind"], refKey, err))
+			}
+			add(referenced)
+		}
+	}
+
+	secrets := &unstructured.UnstructuredList{}
+	secrets.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("SecretList"))
+	if err := c.ctrlClient.List(ctx, secrets, client.InNamespace(cluster.Namespace), labels); err != nil {
+		return nil, fmt.Errorf("failed to list secrets: %w", err)
+	}
+	for i := range secrets.Items {
+		secrets.Items[i].SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+		add(&secrets.Items[i])
+	}
+	for _, obj := range objects {
+		annotations := obj.GetAnnotations()
+		delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		obj.SetAnnotations(annotations)
+	}
+	return objects, nil
+}
+
+// waitForProviders waits until the controllers of the installed providers
+// are ready
+func (c *Client) waitForProviders(ctx context.Context, changes []ProviderChange, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	interval := DefaultWaitPollInterval
+	for {
+		installed, err := c.installedProviderVersions(ctx)
+		pending := []string{}
+		if err == nil {
+			for _, change := range changes {
+				if provider, ok := installed[change.Name]; !ok || !provider.Healthy {
+					pending = append(pending, change.Name)
+				}
+			}
+			if len(pending) == 0 {
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("providers of the workload cluster are not ready after %s: %w", timeout, err)
+			}
+			return fmt.Errorf("providers of the workload cluster are not ready after %s: %s", timeout, strings.Join(pending, ", "))
+		case <-time.After(interval):
+			interval = min(2*interval, DefaultWaitMaxPollInterval)
+		}
+	}
+}
+
+// setClusterPaused sets spec.paused of a cluster
+func (c *Client) setClusterPaused(ctx context.Context, namespace, name string, paused bool) error {
+	cluster, err := c.GetCluster(ctx, namespace, name)
+	if err != nil {
+		return err
+	}
+	patch := client.MergeFrom(cluster.DeepCopy())
+	cluster.Spec.Paused = paused
+	if err := c.ctrlClient.Patch(ctx, cluster, patch); err != nil {
+		return fmt.Errorf("failed to set paused=%v: %w", paused, resourceError("Cluster", client.ObjectKeyFromObject(cluster), err))
+	}
+	return nil
+}
+
+// deleteWithoutFinalizers deletes an object after removing its finalizers, so
+// no controller cleans up what it describes
+func (c *Client) deleteWithoutFinalizers(ctx context.Context, obj *unstructured.Unstructured) error {
+	key := client.ObjectKeyFromObject(obj)
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(obj.GroupVersionKind())
+	if err := c.ctrlClient.Get(ctx, key, current); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+			return nil
+		}
+		return resourceError(obj.GetKind(), key, err)
+	}
+	if len(current.GetFinalizers()) > 0 {
+		patch := client.MergeFrom(current.DeepCopy())
+		current.SetFinalizers(nil)
+		if err := c.ctrlClient.Patch(ctx, current, patch); err != nil {
+			return fmt.Errorf("failed to remove the finalizers of %s %s: %w", obj.GetKind(), obj.GetName(), resourceError(obj.GetKind(), key, err))
+		}
+	}
+	if err := c.ctrlClient.Delete(ctx, current); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %s %s: %w", obj.GetKind(), obj.GetName(), resourceError(obj.GetKind(), key, err))
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// pivotProviders are the provider deployments of both clusters
+func pivotProviders() []runtime.Object {
+	return []runtime.Object{
+		newProviderDeploymentObject("capi-system", "capi-controller-manager", "cluster-api", "registry.k8s.io/cluster-api/cluster-api-controller:v1.10.2"),
+		newProviderDeploymentObject("capi-kubeadm-bootstrap-system", "capi-kubeadm-bootstrap-controller-manager", "bootstrap-kubeadm", "registry.k8s.io/cluster-api/kubeadm-bootstrap-controller:v1.10.2"),
+		newProviderDeploymentObject("capi-kubeadm-control-plane-system", "capi-kubeadm-control-plane-controller-manager", "control-plane-kubeadm", "registry.k8s.io/cluster-api/kubeadm-control-plane-controller:v1.10.2"),
+		newProviderDeploymentObject("capd-system", "capd-controller-manager", "infrastructure-docker", "registry.k8s.io/cluster-api/capd-manager:v1.10.2"),
+	}
+}
+
+// newPivotClients returns a management cluster holding cluster org-acme/prod
+// and the workload cluster prod, whose controllers report an unpaused
+// Cluster Ready. Creating objects of kind failKind in the workload cluster
+// fails.
+func newPivotClients(t *testing.T, failKind string) (*Client, *Client) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	ref := func(gvk string, kind, name string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{APIVersion: gvk, Kind: kind, Name: name}
+	}
+	infra, bootstrap := dockerClusterGVK.GroupVersion().String(), kubeadmConfigTemplateGVK.GroupVersion().String()
+	labels := map[string]string{clusterv1.ClusterNameLabel: "prod"}
+	kubeconfig := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-kubeconfig", Labels: labels},
+		Data:       map[string][]byte{"value": []byte("kubeconfig")},
+	}
+	kcp := newUnstructured(schema.FromAPIVersionAndKind("controlplane.cluster.x-k8s.io/v1beta1", "KubeadmControlPlane"), "org-acme", "prod", nil)
+	_ = unstructured.SetNestedStringMap(kcp.Object, map[string]string{"apiVersion": infra, "kind": "DockerMachineTemplate", "name": "prod-cp"}, "spec", "machineTemplate", "infrastructureRef")
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-md", Labels: labels, UID: "md-uid"},
+		Spec: clusterv1.MachineDeploymentSpec{ClusterName: "prod", Template: clusterv1.MachineTemplateSpec{Spec: clusterv1.MachineSpec{
+			ClusterName:       "prod",
+			Bootstrap:         clusterv1.Bootstrap{ConfigRef: ref(bootstrap, "KubeadmConfigTemplate", "prod-md")},
+			InfrastructureRef: *ref(infra, "DockerMachineTemplate", "prod-md"),
+		}}},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "org-acme", Name: "prod-md-abcde", Labels: labels, Finalizers: []string{clusterv1.MachineFinalizer},
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: clusterv1.GroupVersion.String(), Kind: "MachineDeployment", Name: "prod-md", UID: "md-uid"}},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName:       "prod",
+			Bootstrap:         clusterv1.Bootstrap{ConfigRef: ref(bootstrap, "KubeadmConfig", "prod-md-abcde")},
+			InfrastructureRef: *ref(infra, "DockerMachine", "prod-md-abcde"),
+		},
+	}
+	management := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod"},
+				Spec: clusterv1.ClusterSpec{
+					InfrastructureRef: ref(infra, "DockerCluster", "prod"),
+					ControlPlaneRef:   ref("controlplane.cluster.x-k8s.io/v1beta1", "KubeadmControlPlane", "prod"),
+				},
+				Status: clusterv1.ClusterStatus{ControlPlaneReady: true, InfrastructureReady: true},
+			},
+			newUnstructured(dockerClusterGVK, "org-acme", "prod", nil),
+			kcp,
+			newUnstructured(dockerMachineTemplateGVK, "org-acme", "prod-cp", nil),
+			md,
+			newUnstructured(kubeadmConfigTemplateGVK, "org-acme", "prod-md", nil),
+			newUnstructured(dockerMachineTemplateGVK, "org-acme", "prod-md", nil),
+			machine,
+			newUnstructured(schema.FromAPIVersionAndKind(bootstrap, "KubeadmConfig"), "org-acme", "prod-md-abcde", labels),
+			newUnstructured(schema.FromAPIVersionAndKind(infra, "DockerMachine"), "org-acme", "prod-md-abcde", labels),
+			kubeconfig,
+		).Build(),
+		k8sClient: k8sfake.NewClientset(append(pivotProviders(), kubeconfig)...),
+	}
+
+	created := 0
+	workload := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if failKind != "" && obj.GetObjectKind().GroupVersionKind().Kind == failKind {
+					return apierrors.NewForbidden(clusterv1.GroupVersion.WithResource("machines").GroupResource(), obj.GetName(), errors.New("denied"))
+				}
+				created++
+				obj.SetUID(types.UID(fmt.Sprintf("uid-%d", created)))
+				return c.Create(ctx, obj, opts...)
+			},
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				if err := c.Get(ctx, key, obj, opts...); err != nil {
+					return err
+				}
+				if cluster, ok := obj.(*clusterv1.Cluster); ok && !cluster.Spec.Paused {
+					cluster.Status.Conditions = clusterv1.Conditions{{Type: clusterv1.ReadyCondition, Status: corev1.ConditionTrue}}
+				}
+				return nil
+			},
+		}).Build(),
+		k8sClient: k8sfake.NewClientset(pivotProviders()...),
+	}
+	management.newWorkloadCAPIClient = func(config string) (*Client, error) {
+		if config != "kubeconfig" {
+			t.Errorf("workload client for kubeconfig %q", config)
+		}
+		return workload, nil
+	}
+	return management, workload
+}
+
+func TestPivotCluster(t *testing.T) {
+	management, workload := newPivotClients(t, "")
+	ctx := context.Background()
+	opts := PivotOptions{Namespace: "org-acme", Name: "prod", Repository: &fakeProviderRepository{}}
+
+	dryRun := opts
+	dryRun.DryRun = true
+	pivot, err := management.PivotCluster(ctx, dryRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pivot.Moved) != 11 || len(pivot.Providers) != 4 || pivot.SelfManaged {
+		t.Errorf("dry run = %+v, want 11 objects and 4 providers planned", pivot)
+	}
+	if _, err := workload.GetCluster(ctx, "org-acme", "prod"); err == nil {
+		t.Error("dry run moved the cluster")
+	}
+
+	var steps []string
+	opts.OnProgress = func(step PivotStep) { steps = append(steps, step.Name+": "+step.Status) }
+	pivot, err = management.PivotCluster(ctx, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pivot.SelfManaged || !pivot.Verified || len(pivot.Moved) != 11 || pivot.Deleted != 11 {
+		t.Errorf("pivot = %+v, want 11 objects moved and the cluster verified", pivot)
+	}
+	if len(steps) != 7 || steps[6] != "verify reconciliation: done" {
+		t.Errorf("steps = %v", steps)
+	}
+	for _, change := range pivot.Providers {
+		if change.Note == "" {
+			t.Errorf("provider %s = %+v, want it already installed", change.Name, change)
+		}
+	}
+
+	if _, err := management.GetCluster(ctx, "org-acme", "prod"); err == nil {
+		t.Error("the cluster is still on the management cluster")
+	}
+	machine := &clusterv1.Machine{}
+	if err := management.ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "prod-md-abcde"}, machine); !apierrors.IsNotFound(err) {
+		t.Errorf("machine on the management cluster error = %v, want it deleted despite its finalizer", err)
+	}
+	cluster, err := workload.GetCluster(ctx, "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cluster.Spec.Paused || cluster.Spec.ControlPlaneRef == nil {
+		t.Errorf("moved cluster = %+v, want it resumed", cluster.Spec)
+	}
+	md := &clusterv1.MachineDeployment{}
+	if err := workload.ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "prod-md"}, md); err != nil {
+		t.Fatal(err)
+	}
+	if err := workload.ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "prod-md-abcde"}, machine); err != nil {
+		t.Fatal(err)
+	}
+	if owners := machine.OwnerReferences; len(owners) != 1 || owners[0].UID != md.UID {
+		t.Errorf("moved machine owners = %+v, want the moved machine deployment %s", owners, md.UID)
+	}
+
+	if _, err := management.PivotCluster(ctx, opts); err == nil {
+		t.Error("PivotCluster() of a moved cluster should fail")
+	}
+}
+
+func TestPivotClusterRollback(t *testing.T) {
+	management, workload := newPivotClients(t, "Machine")
+	ctx := context.Background()
+
+	pivot, err := management.PivotCluster(ctx, PivotOptions{Namespace: "org-acme", Name: "prod", Repository: &fakeProviderRepository{}})
+	if err == nil || pivot == nil || pivot.SelfManaged {
+		t.Fatalf("PivotCluster() = %+v, %v, want a failed move", pivot, err)
+	}
+	cluster, err := management.GetCluster(ctx, "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cluster.Spec.Paused {
+		t.Error("the cluster stayed paused on the management cluster")
+	}
+	if _, err := workload.GetCluster(ctx, "org-acme", "prod"); err == nil {
+		t.Error("the created cluster was not removed from the workload cluster")
+	}
+	if last := pivot.Steps[len(pivot.Steps)-1]; last.Name != "roll back" || last.Status != "done" {
+		t.Errorf("last step = %+v, want the rollback", last)
+	}
+}
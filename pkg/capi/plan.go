@@ -0,0 +1,182 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+)
+
+// PlannedObject is an object of a cluster considered by an upgrade plan
+type PlannedObject struct {
+	Kind           string `json:"kind"`
+	Name           string `json:"name"`
+	CurrentVersion string `json:"currentVersion,omitempty"`
+	Replicas       int32  `json:"replicas"`
+	// Modified reports whether the upgrade would change the object
+	Modified bool `json:"modified"`
+	// Replacements estimates the machines rolled out to reach the target
+	// version: the machines of the object not running it yet
+	Replacements int    `json:"replacements"`
+	Note         string `json:"note,omitempty"`
+}
+
+// UpgradePlan describes what upgrading a cluster would do, without doing it
+type UpgradePlan struct {
+	Namespace          string          `json:"namespace"`
+	Cluster            string          `json:"cluster"`
+	TargetVersion      string          `json:"targetVersion"`
+	UpgradeWorkers     bool            `json:"upgradeWorkers"`
+	ControlPlane       *PlannedObject  `json:"controlPlane,omitempty"`
+	MachineDeployments []PlannedObject `json:"machineDeployments"`
+	// Replacements is the total number of machines expected to be replaced
+	Replacements int `json:"replacements"`
+	// Blockers are the reasons the upgrade should not be started
+	Blockers []string `json:"blockers"`
+}
+
+// Blocked reports whether anything prevents the upgrade
+func (p *UpgradePlan) Blocked() bool {
+	return len(p.Blockers) > 0
+}
+
+// PlanUpgrade reports the current versions of the control plane and machine
+// deployments of a cluster, the objects UpgradeCluster would modify, the
+// machines it would replace and anything that should block the upgrade:
+// version skew, paused resources and unhealthy machines.
+func (c *Client) PlanUpgrade(ctx context.Context, namespace, name, targetVersion string, upgradeWorkers bool) (*UpgradePlan, error) {
+	target, err := version.ParseSemantic(targetVersion)
+	if err != nil {
+		return nil, errorf(ErrInvalidArgument, "invalid target version %q: %v", targetVersion, err)
+	}
+	cluster, err := c.GetCluster(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	machines, err := c.ListMachines(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	mds, err := c.ListMachineDeployments(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &UpgradePlan{
+		Namespace:          namespace,
+		Cluster:            name,
+		TargetVersion:      targetVersion,
+		UpgradeWorkers:     upgradeWorkers,
+		MachineDeployments: []PlannedObject{},
+		Blockers:           []string{},
+	}
+	block := func(format string, args ...any) {
+		plan.Blockers = append(plan.Blockers, fmt.Sprintf(format, args...))
+	}
+	if cluster.Spec.Paused {
+		block("cluster %s is paused", name)
+	}
+
+	// Control plane
+	var kcp *controlplanev1.KubeadmControlPlane
+	switch ref := cluster.Spec.ControlPlaneRef; {
+	case ref == nil:
+		block("cluster has no control plane reference")
+	case ref.Kind != "KubeadmControlPlane":
+		block("unsupported control plane type: %s", ref.Kind)
+	default:
+		kcp, err = c.GetKubeadmControlPlane(ctx, namespace, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		cp := &PlannedObject{
+			Kind:           "KubeadmControlPlane",
+			Name:           kcp.Name,
+			CurrentVersion: kcp.Spec.Version,
+			Modified:       !sameVersion(kcp.Spec.Version, targetVersion),
+		}
+		if kcp.Spec.Replicas != nil {
+			cp.Replicas = *kcp.Spec.Replicas
+		}
+		cp.Replacements = outdatedMachines(machines.Items, targetVersion, func(m *clusterv1.Machine) bool {
+			_, ok := m.Labels[clusterv1.MachineControlPlaneLabel]
+			return ok
+		})
+		if _, ok := kcp.Annotations[clusterv1.PausedAnnotation]; ok {
+			block("KubeadmControlPlane %s is paused", kcp.Name)
+		}
+		plan.ControlPlane = cp
+		plan.Replacements += cp.Replacements
+	}
+
+	// Worker machine deployments
+	for i := range mds.Items {
+		md := &mds.Items[i]
+		planned := PlannedObject{Kind: "MachineDeployment", Name: md.Name}
+		if md.Spec.Template.Spec.Version != nil {
+			planned.CurrentVersion = *md.Spec.Template.Spec.Version
+		}
+		if md.Spec.Replicas != nil {
+			planned.Replicas = *md.Spec.Replicas
+		}
+		switch {
+		case !upgradeWorkers:
+			planned.Note = "workers are not upgraded (upgrade_workers=false)"
+		case planned.CurrentVersion == "":
+			planned.Note = "no version set, left unchanged"
+		default:
+			planned.Modified = !sameVersion(planned.CurrentVersion, targetVersion)
+			planned.Replacements = outdatedMachines(machines.Items, targetVersion, func(m *clusterv1.Machine) bool {
+				return m.Labels[clusterv1.MachineDeploymentNameLabel] == md.Name
+			})
+			_, pausedAnnotation := md.Annotations[clusterv1.PausedAnnotation]
+			if (md.Spec.Paused || pausedAnnotation) && planned.Modified {
+				block("MachineDeployment %s is paused", md.Name)
+			}
+		}
+		plan.MachineDeployments = append(plan.MachineDeployments, planned)
+		plan.Replacements += planned.Replacements
+	}
+
+	for _, violation := range upgradeSkewViolations(kcp, mds.Items, target, upgradeWorkers) {
+		block("version skew: %s", violation)
+	}
+
+	// Unhealthy machines make it impossible to tell a failed rollout apart
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		switch {
+		case !machine.DeletionTimestamp.IsZero():
+		case machine.Status.FailureReason != nil || machine.Status.Phase == string(clusterv1.MachinePhaseFailed):
+			block("machine %s has failed", machine.Name)
+		case machine.Status.NodeRef == nil:
+			block("machine %s has no node", machine.Name)
+		}
+	}
+
+	return plan, nil
+}
+
+// sameVersion compares Kubernetes versions with or without the v prefix
+func sameVersion(a, b string) bool {
+	return strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
+}
+
+// outdatedMachines counts the machines selected by match that do not run
+// targetVersion, leaving out machines being deleted
+func outdatedMachines(machines []clusterv1.Machine, targetVersion string, match func(*clusterv1.Machine) bool) int {
+	n := 0
+	for i := range machines {
+		machine := &machines[i]
+		if !machine.DeletionTimestamp.IsZero() || !match(machine) {
+			continue
+		}
+		if machine.Spec.Version == nil || !sameVersion(*machine.Spec.Version, targetVersion) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,93 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPlanUpgrade(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	replicas := int32(2)
+	oldVersion := "v1.29.4"
+	newMachine := func(name string, labels map[string]string, withNode bool) *clusterv1.Machine {
+		labels[clusterv1.ClusterNameLabel] = "prod"
+		machine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "org-a", Labels: labels},
+			Spec:       clusterv1.MachineSpec{ClusterName: "prod", Version: &oldVersion},
+		}
+		if withNode {
+			machine.Status.NodeRef = &corev1.ObjectReference{Name: name}
+		}
+		return machine
+	}
+	objects := []client.Object{
+		&clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "org-a"},
+			Spec:       clusterv1.ClusterSpec{ControlPlaneRef: &corev1.ObjectReference{Kind: "KubeadmControlPlane", Name: "prod-cp"}},
+		},
+		&controlplanev1.KubeadmControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod-cp", Namespace: "org-a"},
+			Spec:       controlplanev1.KubeadmControlPlaneSpec{Version: oldVersion, Replicas: &replicas},
+		},
+		&clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod-workers", Namespace: "org-a", Labels: map[string]string{clusterv1.ClusterNameLabel: "prod"}},
+			Spec: clusterv1.MachineDeploymentSpec{
+				ClusterName: "prod",
+				Replicas:    &replicas,
+				Template:    clusterv1.MachineTemplateSpec{Spec: clusterv1.MachineSpec{ClusterName: "prod", Version: &oldVersion}},
+			},
+		},
+		newMachine("cp-1", map[string]string{clusterv1.MachineControlPlaneLabel: ""}, true),
+		newMachine("cp-2", map[string]string{clusterv1.MachineControlPlaneLabel: ""}, true),
+		newMachine("worker-1", map[string]string{clusterv1.MachineDeploymentNameLabel: "prod-workers"}, true),
+		newMachine("worker-2", map[string]string{clusterv1.MachineDeploymentNameLabel: "prod-workers"}, false),
+	}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()}
+	ctx := context.Background()
+
+	plan, err := c.PlanUpgrade(ctx, "org-a", "prod", "v1.30.0", true)
+	if err != nil {
+		t.Fatalf("PlanUpgrade() error = %v", err)
+	}
+	if plan.ControlPlane == nil || !plan.ControlPlane.Modified || plan.ControlPlane.Replacements != 2 {
+		t.Errorf("control plane = %+v, want 2 replacements", plan.ControlPlane)
+	}
+	if len(plan.MachineDeployments) != 1 || !plan.MachineDeployments[0].Modified || plan.Replacements != 4 {
+		t.Errorf("plan = %+v, want the workers modified and 4 replacements", plan)
+	}
+	if len(plan.Blockers) != 1 || !strings.Contains(plan.Blockers[0], "worker-2 has no node") {
+		t.Errorf("Blockers = %v, want the machine without a node", plan.Blockers)
+	}
+
+	plan, err = c.PlanUpgrade(ctx, "org-a", "prod", "v1.31.0", false)
+	if err != nil {
+		t.Fatalf("PlanUpgrade() error = %v", err)
+	}
+	if plan.MachineDeployments[0].Modified || plan.Replacements != 2 {
+		t.Errorf("plan without workers = %+v", plan)
+	}
+	if !plan.Blocked() || !strings.Contains(plan.Blockers[0], "cannot skip minor versions") {
+		t.Errorf("Blockers = %v, want the version skew", plan.Blockers)
+	}
+
+	if _, err := c.PlanUpgrade(ctx, "org-a", "prod", "latest", true); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("PlanUpgrade(latest) error = %v, want an invalid argument", err)
+	}
+}
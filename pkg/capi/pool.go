@@ -0,0 +1,242 @@
+package capi
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// ManagementCluster is a named management cluster a server can operate on
+type ManagementCluster struct {
+	Name string `json:"name"`
+	// Kubeconfig is the path of the kubeconfig file; empty uses the default
+	// kubeconfig of the server
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// Context is the kubeconfig context; empty uses the file's current context
+	Context     string `json:"context,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ClientPool hands out clients for the management clusters a server can
+// operate on. Requests that do not name a management cluster or kubeconfig
+// context use the default client.
+//
+// Clients are created on first use and cached per kubeconfig file and
+// context. Each pooled client keeps its own in-memory change history, so
+// changes are always reverted on the management cluster they were made on.
+//
+// The default client can be switched to another context of the default
+// kubeconfig at runtime with UseContext, and all clients can be rebuilt from
+// changed kubeconfig files with Reload.
+type ClientPool struct {
+	// startupClient is the client of the kubeconfig loaded at startup
+	startupClient *Client
+
+	// managementClusters is the registry of named management clusters
+	managementClusters map[string]ManagementCluster
+
+	// newClient creates the client of a kubeconfig context
+	newClient func(kubeconfig, contextName string) (*Client, error)
+
+	mu      sync.Mutex
+	clients map[string]*Client
+	// activeContext is the context selected with UseContext; empty selects
+	// the startup client
+	activeContext string
+}
+
+// NewClientPool creates a pool around the default client. The names of the
+// management clusters are accepted for the management_cluster argument.
+// Pooled clients are created with the given options, so they connect like
+// the default client.
+func NewClientPool(defaultClient *Client, managementClusters []ManagementCluster, opts ...Option) (*ClientPool, error) {
+	registry := make(map[string]ManagementCluster, len(managementClusters))
+	for _, cluster := range managementClusters {
+		if cluster.Name == "" {
+			return nil, fmt.Errorf("management cluster without name")
+		}
+		if _, exists := registry[cluster.Name]; exists {
+			return nil, fmt.Errorf("management cluster %s is declared twice", cluster.Name)
+		}
+		if cluster.Kubeconfig == "" && cluster.Context == "" {
+			return nil, fmt.Errorf("management cluster %s needs a kubeconfig or a context", cluster.Name)
+		}
+		registry[cluster.Name] = cluster
+	}
+
+	return &ClientPool{
+		startupClient:      defaultClient,
+		managementClusters: registry,
+		newClient: func(kubeconfig, contextName string) (*Client, error) {
+			return newPooledClient(append(slices.Clone(opts), WithKubeconfig(kubeconfig), WithContext(contextName))...)
+		},
+		clients: make(map[string]*Client),
+	}, nil
+}
+
+// newPooledClient creates a client for a kubeconfig context with its providers initialized
+func newPooledClient(opts ...Option) (*Client, error) {
+	c, err := NewClientWithOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.InitializeProviders(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Default returns the client of the active context, which is the
+// kubeconfig loaded at startup unless another context was selected
+func (p *ClientPool) Default() *Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.defaultLocked()
+}
+
+// defaultLocked returns the default client; p.mu must be held
+func (p *ClientPool) defaultLocked() *Client {
+	if p.activeContext != "" {
+		if c, ok := p.clients[clientKey("", p.activeContext)]; ok {
+			return c
+		}
+	}
+	return p.startupClient
+}
+
+// ActiveContext returns the context selected with UseContext, or an empty
+// string while the kubeconfig loaded at startup is used
+func (p *ClientPool) ActiveContext() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.activeContext
+}
+
+// UseContext makes a context of the default kubeconfig the default for
+// requests that do not select a management cluster. An empty context
+// switches back to the kubeconfig loaded at startup.
+func (p *ClientPool) UseContext(contextName string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if contextName != "" {
+		if _, err := p.getLocked("", contextName); err != nil {
+			return err
+		}
+	}
+	p.activeContext = contextName
+	return nil
+}
+
+// Reload replaces the startup client and rebuilds all pooled clients from
+// their kubeconfig files, for example after credentials were rotated. Change
+// histories carry over to the new clients. Clients that cannot be rebuilt
+// keep their previous configuration and are reported in the returned error.
+func (p *ClientPool) Reload(startupClient *Client) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if startupClient != nil {
+		startupClient.SetChangeHistory(p.startupClient.ChangeHistory())
+		p.startupClient = startupClient
+	}
+
+	var errs []error
+	for key, old := range p.clients {
+		kubeconfig, contextName, _ := strings.Cut(key, "\x00")
+		c, err := p.newClient(kubeconfig, contextName)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to reload kubeconfig context %q: %w", contextName, err))
+			continue
+		}
+		c.SetChangeHistory(old.ChangeHistory())
+		p.clients[key] = c
+	}
+	return errors.Join(errs...)
+}
+
+// KubeconfigFiles returns the kubeconfig files the pooled clients are
+// loaded from, so they can be watched for changes
+func (p *ClientPool) KubeconfigFiles() []string {
+	files := clientcmd.NewDefaultClientConfigLoadingRules().GetLoadingPrecedence()
+	for _, cluster := range p.ManagementClusters() {
+		if cluster.Kubeconfig != "" && !slices.Contains(files, cluster.Kubeconfig) {
+			files = append(files, cluster.Kubeconfig)
+		}
+	}
+	return files
+}
+
+// ManagementClusters returns the registered management clusters sorted by name
+func (p *ClientPool) ManagementClusters() []ManagementCluster {
+	clusters := make([]ManagementCluster, 0, len(p.managementClusters))
+	for _, cluster := range p.managementClusters {
+		clusters = append(clusters, cluster)
+	}
+	slices.SortFunc(clusters, func(a, b ManagementCluster) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return clusters
+}
+
+// managementClusterNames lists the registered names for error messages
+func (p *ClientPool) managementClusterNames() string {
+	var names []string
+	for _, cluster := range p.ManagementClusters() {
+		names = append(names, cluster.Name)
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// Get returns the client for a management cluster name or a context of the
+// default kubeconfig. At most one of them may be set; if neither is, the
+// default client is returned.
+func (p *ClientPool) Get(managementCluster, kubeconfigContext string) (*Client, error) {
+	var kubeconfig string
+	switch {
+	case managementCluster != "" && kubeconfigContext != "":
+		return nil, errorf(ErrInvalidArgument, "management_cluster and kubeconfig_context are mutually exclusive")
+	case managementCluster != "":
+		cluster, ok := p.managementClusters[managementCluster]
+		if !ok {
+			return nil, errorf(ErrInvalidArgument, "unknown management cluster %q (configured: %s)", managementCluster, p.managementClusterNames())
+		}
+		kubeconfig, kubeconfigContext = cluster.Kubeconfig, cluster.Context
+	case kubeconfigContext == "":
+		return p.Default(), nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.getLocked(kubeconfig, kubeconfigContext)
+}
+
+// getLocked returns the cached client of a kubeconfig context or creates it;
+// p.mu must be held
+func (p *ClientPool) getLocked(kubeconfig, kubeconfigContext string) (*Client, error) {
+	key := clientKey(kubeconfig, kubeconfigContext)
+	if c, ok := p.clients[key]; ok {
+		return c, nil
+	}
+	c, err := p.newClient(kubeconfig, kubeconfigContext)
+	if err != nil {
+		return nil, err
+	}
+	p.clients[key] = c
+	return c, nil
+}
+
+// clientKey identifies a pooled client by kubeconfig file and context
+func clientKey(kubeconfig, contextName string) string {
+	return kubeconfig + "\x00" + contextName
+}
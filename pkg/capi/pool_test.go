@@ -0,0 +1,167 @@
+package capi
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: eu
+  cluster:
+    server: https://127.0.0.1:6443
+- name: us
+  cluster:
+    server: https://127.0.0.1:7443
+contexts:
+- name: eu-admin
+  context: {cluster: eu, user: admin}
+- name: us-admin
+  context: {cluster: us, user: admin}
+current-context: eu-admin
+users:
+- name: admin
+  user: {token: secret}
+`
+
+func TestClientPool(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", kubeconfig)
+
+	// A second file for a management cluster with its own kubeconfig
+	euKubeconfig := filepath.Join(t.TempDir(), "eu")
+	if err := os.WriteFile(euKubeconfig, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	defaultClient := &Client{}
+	pool, err := NewClientPool(defaultClient, []ManagementCluster{
+		{Name: "us", Context: "us-admin"},
+		{Name: "eu", Kubeconfig: euKubeconfig},
+	})
+	if err != nil {
+		t.Fatalf("NewClientPool() error = %v", err)
+	}
+	if clusters := pool.ManagementClusters(); len(clusters) != 2 || clusters[0].Name != "eu" {
+		t.Errorf("ManagementClusters() = %v, want eu and us sorted by name", clusters)
+	}
+
+	if c, err := pool.Get("", ""); err != nil || c != defaultClient {
+		t.Errorf("Get() without selection = %p, %v, want the default client", c, err)
+	}
+
+	us, err := pool.Get("us", "")
+	if err != nil {
+		t.Fatalf("Get(us) error = %v", err)
+	}
+	if us.config.Host != "https://127.0.0.1:7443" {
+		t.Errorf("management cluster us uses %s", us.config.Host)
+	}
+	if again, _ := pool.Get("", "us-admin"); again != us {
+		t.Error("clients should be cached per kubeconfig context")
+	}
+	if us.ChangeHistory() == defaultClient.ChangeHistory() {
+		t.Error("pooled clients must not share the default change history")
+	}
+
+	eu, err := pool.Get("eu", "")
+	if err != nil {
+		t.Fatalf("Get(eu) error = %v", err)
+	}
+	if eu.config.Host != "https://127.0.0.1:6443" {
+		t.Errorf("management cluster eu uses %s, want the current context of its kubeconfig", eu.config.Host)
+	}
+
+	invalid := [][]ManagementCluster{
+		{{Name: "eu", Context: "eu-admin"}, {Name: "eu", Context: "us-admin"}},
+		{{Name: "eu"}},
+		{{Context: "eu-admin"}},
+	}
+	for _, clusters := range invalid {
+		if _, err := NewClientPool(defaultClient, clusters); err == nil {
+			t.Errorf("NewClientPool(%v) should fail", clusters)
+		}
+	}
+
+	for _, tt := range []struct{ cluster, context string }{
+		{"asia", ""},
+		{"", "missing"},
+		{"us", "eu-admin"},
+	} {
+		if _, err := pool.Get(tt.cluster, tt.context); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("Get(%q, %q) error = %v, want ErrInvalidArgument", tt.cluster, tt.context, err)
+		}
+	}
+}
+
+func TestClientPoolContextSwitchAndReload(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", kubeconfig)
+
+	startup, err := NewClientForContext("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := NewClientPool(startup, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files := pool.KubeconfigFiles(); !slices.Contains(files, kubeconfig) {
+		t.Errorf("KubeconfigFiles() = %v, want %s", files, kubeconfig)
+	}
+
+	if err := pool.UseContext("us-admin"); err != nil {
+		t.Fatalf("UseContext(us-admin) error = %v", err)
+	}
+	us := pool.Default()
+	if us.config.Host != "https://127.0.0.1:7443" || pool.ActiveContext() != "us-admin" {
+		t.Errorf("default client uses %s after switching to us-admin", us.config.Host)
+	}
+	if c, _ := pool.Get("", ""); c != us {
+		t.Error("requests without selection should use the active context")
+	}
+	if err := pool.UseContext("missing"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("UseContext(missing) error = %v, want ErrInvalidArgument", err)
+	}
+	if pool.Default() != us {
+		t.Error("a failed switch must keep the active context")
+	}
+
+	// Rotate the server of us-admin and reload
+	rotated := strings.Replace(testKubeconfig, "127.0.0.1:7443", "127.0.0.1:8443", 1)
+	if err := os.WriteFile(kubeconfig, []byte(rotated), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	reloadedStartup, err := NewClientForContext("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Reload(reloadedStartup); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	reloaded := pool.Default()
+	if reloaded == us || reloaded.config.Host != "https://127.0.0.1:8443" {
+		t.Errorf("default client uses %s after reload, want the rotated server", reloaded.config.Host)
+	}
+	if reloaded.ChangeHistory() != us.ChangeHistory() {
+		t.Error("reload must keep the change history of the context")
+	}
+
+	if err := pool.UseContext(""); err != nil {
+		t.Fatal(err)
+	}
+	if pool.Default() != reloadedStartup || reloadedStartup.ChangeHistory() != startup.ChangeHistory() {
+		t.Error("switching back should use the reloaded startup client with its change history")
+	}
+}
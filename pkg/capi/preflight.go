@@ -0,0 +1,362 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/version"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The checks of a cluster configuration
+const (
+	PreflightName      = "name"
+	PreflightNamespace = "namespace"
+	PreflightProvider  = "provider"
+	PreflightTemplates = "templates"
+	PreflightNetwork   = "network"
+	PreflightVersion   = "version"
+)
+
+// ClusterConfig is the proposed configuration of a new cluster
+type ClusterConfig struct {
+	Name      string
+	Namespace string
+	// Provider is the infrastructure provider, e.g. aws
+	Provider          string
+	KubernetesVersion string
+	// PodCIDRs and ServiceCIDRs default to the ranges CreateCluster sets
+	PodCIDRs     []string
+	ServiceCIDRs []string
+	// MachineTemplates name infrastructure machine templates of the provider
+	// in the namespace the cluster would use
+	MachineTemplates []string
+	// ClusterClass, in ClassNamespace or else Namespace, is the class the
+	// cluster would have a topology of
+	ClusterClass   string
+	ClassNamespace string
+}
+
+// PreflightCheck is the outcome of a check of a cluster configuration
+type PreflightCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	// Reasons explain the outcome, one per problem when the check failed
+	Reasons []string `json:"reasons"`
+}
+
+// PreflightReport is the outcome of all checks of a cluster configuration
+type PreflightReport struct {
+	Namespace string           `json:"namespace"`
+	Name      string           `json:"name"`
+	Passed    bool             `json:"passed"`
+	Checks    []PreflightCheck `json:"checks"`
+}
+
+// ValidateClusterConfig checks a proposed cluster before it is created: that
+// the name and namespace are valid and the name is free, that the provider
+// is known and installed and healthy, that the referenced templates and
+// ClusterClass exist, that the network ranges are valid and overlap neither
+// each other nor those of the clusters in the namespace, and that the
+// Kubernetes version is available for the provider. Failed lookups fail
+// their check instead of the validation.
+func (c *Client) ValidateClusterConfig(ctx context.Context, config ClusterConfig) *PreflightReport {
+	report := &PreflightReport{Namespace: config.Namespace, Name: config.Name}
+	provider, known := LookupProvider(config.Provider)
+	report.add(PreflightName, newPreflightCheck(c.checkClusterName(ctx, config)))
+	report.add(PreflightNamespace, newPreflightCheck(c.checkClusterNamespace(ctx, config)))
+	report.add(PreflightProvider, newPreflightCheck(c.checkClusterProvider(ctx, config, provider, known)))
+	report.add(PreflightTemplates, newPreflightCheck(c.checkClusterTemplates(ctx, config, provider, known)))
+	report.add(PreflightNetwork, newPreflightCheck(c.checkClusterNetwork(ctx, config, provider)))
+	report.add(PreflightVersion, newPreflightCheck(c.checkClusterVersion(ctx, config, provider, known)))
+
+	report.Passed = true
+	for _, check := range report.Checks {
+		report.Passed = report.Passed && check.Passed
+	}
+	return report
+}
+
+// add records a check under its name
+func (r *PreflightReport) add(name string, check PreflightCheck) {
+	check.Name = name
+	r.Checks = append(r.Checks, check)
+}
+
+// newPreflightCheck returns a check that passed with the reason ok, or failed
+// with problems
+func newPreflightCheck(ok string, problems []string) PreflightCheck {
+	if len(problems) > 0 {
+		return PreflightCheck{Reasons: problems}
+	}
+	return PreflightCheck{Passed: true, Reasons: []string{ok}}
+}
+
+// checkClusterName checks that the name is a DNS label not taken yet
+func (c *Client) checkClusterName(ctx context.Context, config ClusterConfig) (string, []string) {
+	// Names end up in labels and in the host names of machines
+	if msgs := validation.IsDNS1123Label(config.Name); len(msgs) > 0 {
+		return "", []string{fmt.Sprintf("%q is not a valid cluster name: %s", config.Name, strings.Join(msgs, "; "))}
+	}
+	if validation.IsDNS1123Label(config.Namespace) != nil {
+		return "valid name", nil
+	}
+	_, err := c.GetCluster(ctx, config.Namespace, config.Name)
+	switch {
+	case err == nil:
+		return "", []string{fmt.Sprintf("cluster %s/%s already exists", config.Namespace, config.Name)}
+	case !errors.Is(err, ErrNotFound):
+		return "", []string{fmt.Sprintf("could not check for an existing cluster: %v", err)}
+	}
+	return "valid name, no cluster has it yet", nil
+}
+
+// checkClusterNamespace checks that the namespace is valid and exists
+func (c *Client) checkClusterNamespace(ctx context.Context, config ClusterConfig) (string, []string) {
+	if msgs := validation.IsDNS1123Label(config.Namespace); len(msgs) > 0 {
+		return "", []string{fmt.Sprintf("%q is not a valid namespace: %s", config.Namespace, strings.Join(msgs, "; "))}
+	}
+	if _, err := c.k8sClient.CoreV1().Namespaces().Get(ctx, config.Namespace, metav1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", []string{fmt.Sprintf("namespace %s does not exist", config.Namespace)}
+		}
+		return "", []string{fmt.Sprintf("could not get namespace %s: %v", config.Namespace, err)}
+	}
+	return fmt.Sprintf("namespace %s exists", config.Namespace), nil
+}
+
+// checkClusterProvider checks that the infrastructure provider is installed
+// and its controllers are ready
+func (c *Client) checkClusterProvider(ctx context.Context, config ClusterConfig, provider ProviderInfo, known bool) (string, []string) {
+	if !known {
+		return "", []string{fmt.Sprintf("unknown infrastructure provider %q, supported providers: %s", config.Provider, strings.Join(ProviderNames(), ", "))}
+	}
+	installed, err := c.ListInstalledProviders(ctx)
+	if err != nil {
+		return "", []string{fmt.Sprintf("could not list installed providers: %v", err)}
+	}
+	for _, candidate := range installed {
+		if candidate.Type != "InfrastructureProvider" || candidate.ProviderName != string(provider.Name) {
+			continue
+		}
+		if !candidate.Healthy {
+			return "", []string{fmt.Sprintf("provider %s is installed but not healthy: %s", candidate.Name, candidate.Message)}
+		}
+		return fmt.Sprintf("provider %s %s is installed and healthy", candidate.Name, candidate.Version), nil
+	}
+	return "", []string{fmt.Sprintf("infrastructure provider %s is not installed, see capi_init_providers", provider.Name)}
+}
+
+// checkClusterTemplates checks that the machine templates and ClusterClass
+// exist
+func (c *Client) checkClusterTemplates(ctx context.Context, config ClusterConfig, provider ProviderInfo, known bool) (string, []string) {
+	if len(config.MachineTemplates) == 0 && config.ClusterClass == "" {
+		return "no templates referenced", nil
+	}
+
+	var problems, found []string
+	if len(config.MachineTemplates) > 0 {
+		if !known {
+			problems = append(problems, "the machine templates cannot be looked up without a known provider")
+		} else {
+			gvk := machineTemplateGVK(provider)
+			for _, name := range config.MachineTemplates {
+				template := &unstructured.Unstructured{}
+				template.SetGroupVersionKind(gvk)
+				key := client.ObjectKey{Namespace: config.Namespace, Name: name}
+				if err := c.ctrlClient.Get(ctx, key, template); err != nil {
+					problems = append(problems, templateProblem(gvk.Kind, key, resourceError(gvk.Kind, key, err)))
+					continue
+				}
+				found = append(found, gvk.Kind+" "+name)
+			}
+		}
+	}
+	if config.ClusterClass != "" {
+		_, err := c.getClusterClass(ctx, GenerateClusterOptions{Namespace: config.Namespace, ClusterClass: config.ClusterClass, ClassNamespace: config.ClassNamespace})
+		if err != nil {
+			key := client.ObjectKey{Namespace: config.ClassNamespace, Name: config.ClusterClass}
+			if key.Namespace == "" {
+				key.Namespace = config.Namespace
+			}
+			problems = append(problems, templateProblem("ClusterClass", key, err))
+		} else {
+			found = append(found, "ClusterClass "+config.ClusterClass)
+		}
+	}
+	return "found " + strings.Join(found, ", "), problems
+}
+
+// templateProblem describes a template that could not be found
+func templateProblem(kind string, key client.ObjectKey, err error) string {
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Sprintf("%s %s does not exist", kind, key)
+	}
+	return fmt.Sprintf("could not get %s %s: %v", kind, key, err)
+}
+
+// machineTemplateGVK returns the infrastructure machine template kind of a
+// provider, e.g. AWSMachineTemplate for AWSCluster
+func machineTemplateGVK(provider ProviderInfo) schema.GroupVersionKind {
+	kind := strings.TrimSuffix(provider.ClusterKinds[0], "Cluster") + "MachineTemplate"
+	return schema.FromAPIVersionAndKind(provider.APIVersion, kind)
+}
+
+// checkClusterNetwork checks that the network ranges are valid and overlap
+// neither each other nor those of the clusters in the namespace
+func (c *Client) checkClusterNetwork(ctx context.Context, config ClusterConfig, provider ProviderInfo) (string, []string) {
+	pods, services := config.PodCIDRs, config.ServiceCIDRs
+	if len(pods) == 0 {
+		pods = []string{defaultPodCIDR}
+	}
+	if len(services) == 0 {
+		services = []string{defaultServiceCIDR}
+		if provider.Name == ProviderDocker {
+			services = []string{dockerServiceCIDR}
+		}
+	}
+
+	var problems []string
+	var proposed []namedRange
+	for _, cidr := range pods {
+		if r, ok := parseRange(cidr, "pods"); ok {
+			proposed = append(proposed, r)
+		} else {
+			problems = append(problems, fmt.Sprintf("invalid pod CIDR block %q", cidr))
+		}
+	}
+	for _, cidr := range services {
+		if r, ok := parseRange(cidr, "services"); ok {
+			proposed = append(proposed, r)
+		} else {
+			problems = append(problems, fmt.Sprintf("invalid service CIDR block %q", cidr))
+		}
+	}
+	for i, a := range proposed {
+		for _, b := range proposed[i+1:] {
+			if a.overlaps(b) {
+				problems = append(problems, fmt.Sprintf("%s range %s overlaps %s range %s", a.use, a.cidr, b.use, b.cidr))
+			}
+		}
+	}
+
+	if validation.IsDNS1123Label(config.Namespace) != nil {
+		return "", problems
+	}
+	clusters, err := c.ListClusters(ctx, config.Namespace)
+	if err != nil {
+		return "", append(problems, fmt.Sprintf("could not list the clusters in %s: %v", config.Namespace, err))
+	}
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		for _, existing := range clusterRanges(cluster) {
+			for _, r := range proposed {
+				if r.overlaps(existing) {
+					problems = append(problems, fmt.Sprintf("%s range %s overlaps %s range %s of cluster %s", r.use, r.cidr, existing.use, existing.cidr, cluster.Name))
+				}
+			}
+		}
+	}
+	cidrs := make([]string, 0, len(proposed))
+	for _, r := range proposed {
+		cidrs = append(cidrs, r.cidr)
+	}
+	return fmt.Sprintf("%s overlap no range of the %d clusters in %s", strings.Join(cidrs, ", "), len(clusters.Items), config.Namespace), problems
+}
+
+// namedRange is a CIDR block of the pods or services of a cluster
+type namedRange struct {
+	use     string
+	cidr    string
+	network *net.IPNet
+}
+
+// parseRange parses a CIDR block used for pods or services
+func parseRange(cidr, use string) (namedRange, bool) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return namedRange{}, false
+	}
+	return namedRange{use: use, cidr: cidr, network: network}, true
+}
+
+// overlaps reports whether two ranges share addresses; CIDR blocks either
+// nest or are disjoint, so either contains the start of the other
+func (r namedRange) overlaps(other namedRange) bool {
+	return r.network.Contains(other.network.IP) || other.network.Contains(r.network.IP)
+}
+
+// clusterRanges returns the valid pod and service ranges of a cluster
+func clusterRanges(cluster *clusterv1.Cluster) []namedRange {
+	network := cluster.Spec.ClusterNetwork
+	if network == nil {
+		return nil
+	}
+	var ranges []namedRange
+	add := func(blocks *clusterv1.NetworkRanges, use string) {
+		if blocks == nil {
+			return
+		}
+		for _, cidr := range blocks.CIDRBlocks {
+			if r, ok := parseRange(cidr, use); ok {
+				ranges = append(ranges, r)
+			}
+		}
+	}
+	add(network.Pods, "pods")
+	add(network.Services, "services")
+	return ranges
+}
+
+// checkClusterVersion checks that the Kubernetes version is available for
+// the provider and not deprecated
+func (c *Client) checkClusterVersion(ctx context.Context, config ClusterConfig, provider ProviderInfo, known bool) (string, []string) {
+	if config.KubernetesVersion != "" {
+		if _, err := version.ParseSemantic(config.KubernetesVersion); err != nil {
+			return "", []string{fmt.Sprintf("invalid Kubernetes version %q: %v", config.KubernetesVersion, err)}
+		}
+	}
+	if !known {
+		return "", []string{"the available versions cannot be looked up without a known provider"}
+	}
+	query := VersionQuery{Provider: provider.Name}
+	if validation.IsDNS1123Label(config.Namespace) == nil {
+		query.Namespace = config.Namespace
+	}
+	catalog, err := c.AvailableVersions(ctx, query)
+	if err != nil {
+		return "", []string{fmt.Sprintf("could not look up the available versions: %v", err)}
+	}
+	if config.KubernetesVersion == "" {
+		if def := catalog.Default(); def != "" {
+			return fmt.Sprintf("no version given, %s would be used", def), nil
+		}
+		return "", []string{fmt.Sprintf("no version given and no %s versions are available", provider.Name)}
+	}
+
+	wanted := "v" + strings.TrimPrefix(config.KubernetesVersion, "v")
+	var available []string
+	for _, candidate := range catalog.Versions {
+		if candidate.Version != wanted {
+			available = append(available, candidate.Version)
+			continue
+		}
+		if candidate.Deprecated {
+			return "", []string{fmt.Sprintf("%s is deprecated by every release shipping it", wanted)}
+		}
+		return fmt.Sprintf("%s is available from %s", wanted, strings.Join(candidate.Details, ", ")), nil
+	}
+	if len(available) == 0 {
+		return "", []string{fmt.Sprintf("%s cannot be verified: no %s versions are available", wanted, provider.Name)}
+	}
+	return "", []string{fmt.Sprintf("%s is not available for %s, available versions: %s", wanted, provider.Name, strings.Join(available, ", "))}
+}
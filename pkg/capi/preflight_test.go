@@ -0,0 +1,128 @@
+package capi
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateClusterConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	newRelease := func(name, state, kubernetes string) *unstructured.Unstructured {
+		release := &unstructured.Unstructured{Object: map[string]any{
+			"spec": map[string]any{
+				"state":      state,
+				"components": []any{map[string]any{"name": "kubernetes", "version": kubernetes}},
+			},
+		}}
+		release.SetAPIVersion("release.giantswarm.io/v1alpha1")
+		release.SetKind("Release")
+		release.SetName(name)
+		return release
+	}
+	template := &unstructured.Unstructured{Object: map[string]any{"spec": map[string]any{}}}
+	template.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta2")
+	template.SetKind("AWSMachineTemplate")
+	template.SetNamespace("org-acme")
+	template.SetName("workers")
+	existing := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod"},
+		Spec: clusterv1.ClusterSpec{
+			ClusterNetwork: &clusterv1.ClusterNetwork{
+				Pods:     &clusterv1.NetworkRanges{CIDRBlocks: []string{"192.168.0.0/16"}},
+				Services: &clusterv1.NetworkRanges{CIDRBlocks: []string{"10.96.0.0/12"}},
+			},
+		},
+	}
+
+	replicas := int32(1)
+	controller := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "capa-system", Name: "capa-controller-manager", Labels: map[string]string{clusterv1.ProviderNameLabel: "infrastructure-aws"}},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "manager", Image: "registry.k8s.io/cluster-api-aws/cluster-api-aws-controller:v2.7.1"}}}},
+		},
+		Status: appsv1.DeploymentStatus{ReadyReplicas: 1},
+	}
+
+	c := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newRelease("aws-25.0.0", "deprecated", "1.29.4"),
+			newRelease("aws-26.0.0", "active", "1.30.2"),
+			template,
+			existing,
+		).Build(),
+		k8sClient: k8sfake.NewClientset(controller, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "org-acme"}}),
+	}
+	ctx := context.Background()
+
+	report := c.ValidateClusterConfig(ctx, ClusterConfig{
+		Name:              "dev",
+		Namespace:         "org-acme",
+		Provider:          "aws",
+		KubernetesVersion: "1.30.2",
+		PodCIDRs:          []string{"10.244.0.0/16"},
+		ServiceCIDRs:      []string{"172.20.0.0/16"},
+		MachineTemplates:  []string{"workers"},
+	})
+	if !report.Passed {
+		t.Errorf("valid configuration failed: %+v", report.Checks)
+	}
+	if len(report.Checks) != 6 {
+		t.Errorf("got %d checks, want 6", len(report.Checks))
+	}
+
+	report = c.ValidateClusterConfig(ctx, ClusterConfig{
+		Name:              "prod",
+		Namespace:         "org-acme",
+		Provider:          "aws",
+		KubernetesVersion: "v1.29.4",
+		PodCIDRs:          []string{"10.0.0.0/8"},
+		MachineTemplates:  []string{"workers", "missing"},
+	})
+	if report.Passed {
+		t.Error("invalid configuration passed")
+	}
+	wantFailed := map[string]string{
+		PreflightName:      "already exists",
+		PreflightTemplates: "AWSMachineTemplate org-acme/missing does not exist",
+		PreflightNetwork:   "pods range 10.0.0.0/8 overlaps services range 10.96.0.0/12",
+		PreflightVersion:   "deprecated",
+	}
+	for _, check := range report.Checks {
+		want, failed := wantFailed[check.Name]
+		if check.Passed == failed {
+			t.Errorf("check %s passed = %v: %v", check.Name, check.Passed, check.Reasons)
+			continue
+		}
+		if failed && !strings.Contains(strings.Join(check.Reasons, "\n"), want) {
+			t.Errorf("check %s reasons = %v, want %q", check.Name, check.Reasons, want)
+		}
+	}
+	// The default service range is that of the existing cluster
+	for _, check := range report.Checks {
+		if check.Name == PreflightNetwork && !strings.Contains(strings.Join(check.Reasons, "\n"), "services range 10.96.0.0/12 of cluster prod") {
+			t.Errorf("network reasons = %v, want the overlap with cluster prod", check.Reasons)
+		}
+	}
+
+	report = c.ValidateClusterConfig(ctx, ClusterConfig{Name: "Dev", Namespace: "org-missing", Provider: "nope"})
+	for _, check := range report.Checks {
+		if check.Name != PreflightTemplates && check.Name != PreflightNetwork && check.Passed {
+			t.Errorf("check %s passed: %v", check.Name, check.Reasons)
+		}
+	}
+}
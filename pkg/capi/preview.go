@@ -0,0 +1,82 @@
+package capi
+
+import (
+	"context"
+	"reflect"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ObjectDiff is the change an operation makes to an object
+type ObjectDiff struct {
+	Kind      string        `json:"kind"`
+	Namespace string        `json:"namespace,omitempty"`
+	Name      string        `json:"name"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+// Preview collects the changes of the operations run with its context
+// instead of persisting them
+type Preview struct {
+	mu    sync.Mutex
+	diffs []ObjectDiff
+}
+
+// previewKey is the context key of a Preview
+type previewKey struct{}
+
+// WithPreview returns a context in which the updates of the client are sent
+// as server-side dry-runs, so admission and defaulting apply but nothing is
+// persisted. The returned Preview collects the diff of every object the
+// operation would change. Operations creating or deleting objects do not
+// support previews.
+func WithPreview(ctx context.Context) (context.Context, *Preview) {
+	preview := &Preview{}
+	return context.WithValue(ctx, previewKey{}, preview), preview
+}
+
+// previewFromContext returns the Preview of a context, or nil
+func previewFromContext(ctx context.Context) *Preview {
+	preview, _ := ctx.Value(previewKey{}).(*Preview)
+	return preview
+}
+
+// Diffs returns the changes collected so far, in the order the operation
+// made them; an object changed twice is listed twice
+func (p *Preview) Diffs() []ObjectDiff {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]ObjectDiff{}, p.diffs...)
+}
+
+// record adds the diff between an object and the result of its dry-run
+func (p *Preview) record(before, after client.Object) error {
+	from, err := runtime.DefaultUnstructuredConverter.ToUnstructured(before)
+	if err != nil {
+		return err
+	}
+	to, err := runtime.DefaultUnstructuredConverter.ToUnstructured(after)
+	if err != nil {
+		return err
+	}
+	changes := diffFields(comparableObject(&unstructured.Unstructured{Object: from}), comparableObject(&unstructured.Unstructured{Object: to}))
+	if len(changes) == 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.diffs = append(p.diffs, ObjectDiff{Kind: objectKind(before), Namespace: before.GetNamespace(), Name: before.GetName(), Changes: changes})
+	return nil
+}
+
+// objectKind returns the kind of a typed or unstructured object or list
+func objectKind(obj runtime.Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	return reflect.TypeOf(obj).Elem().Name()
+}
@@ -0,0 +1,69 @@
+package capi
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPreview(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-workers", Labels: map[string]string{clusterv1.ClusterNameLabel: "prod"}},
+		Spec: clusterv1.MachineDeploymentSpec{
+			ClusterName: "prod",
+			Replicas:    ptr.To[int32](2),
+			Template:    clusterv1.MachineTemplateSpec{Spec: clusterv1.MachineSpec{ClusterName: "prod", Version: ptr.To("v1.30.2")}},
+		},
+	}
+	history, err := NewChangeHistory(0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(md).Build(), changes: history}
+
+	ctx, preview := WithPreview(context.Background())
+	if err := c.ScaleMachineDeployment(ctx, "org-acme", "prod-workers", 5); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.UpdateCluster(ctx, UpdateClusterOptions{Namespace: "org-acme", Name: "missing"}); err == nil {
+		t.Error("UpdateCluster() of a missing cluster succeeded in a preview")
+	}
+	// Unchanged objects are left out
+	if err := c.ScaleMachineDeployment(ctx, "org-acme", "prod-workers", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	diffs := preview.Diffs()
+	if len(diffs) != 1 {
+		t.Fatalf("diffs = %+v, want one", diffs)
+	}
+	diff := diffs[0]
+	if diff.Kind != "MachineDeployment" || diff.Namespace != "org-acme" || diff.Name != "prod-workers" ||
+		len(diff.Changes) != 1 || diff.Changes[0].Field != "spec.replicas" || diff.Changes[0].From != int64(2) || diff.Changes[0].To != int64(5) {
+		t.Errorf("diff = %+v", diff)
+	}
+
+	current, err := c.GetMachineDeployment(context.Background(), "org-acme", "prod-workers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *current.Spec.Replicas != 2 {
+		t.Errorf("the preview scaled the machine deployment to %d", *current.Spec.Replicas)
+	}
+	if changes := history.List(); len(changes) != 0 {
+		t.Errorf("the preview recorded changes: %+v", changes)
+	}
+}
@@ -0,0 +1,192 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Machine annotations steering MachineHealthCheck remediation and MachineSet
+// scale-down
+const (
+	// SkipRemediation keeps MachineHealthChecks from remediating a machine
+	// while it is unhealthy
+	SkipRemediation = "skip-remediation"
+	// DeleteFirst makes the MachineSet of a machine delete it before any
+	// other machine when it scales down, whatever its delete policy
+	DeleteFirst = "delete-first"
+)
+
+// machineAnnotations maps the machine annotation names of the tools to the
+// Cluster API annotations
+var machineAnnotations = map[string]string{
+	SkipRemediation: clusterv1.MachineSkipRemediationAnnotation,
+	DeleteFirst:     clusterv1.DeleteMachineAnnotation,
+}
+
+// MachineAnnotationOptions contains options for setting or removing a
+// remediation or scale-down annotation of a machine
+type MachineAnnotationOptions struct {
+	Namespace string
+	Name      string
+	// Annotation is SkipRemediation or DeleteFirst
+	Annotation string
+	// Reason is stored as the annotation value, which Cluster API ignores
+	Reason string
+	Remove bool
+}
+
+// MachineProtection describes the remediation and scale-down annotations of
+// a machine and the delete policy of its MachineSet
+type MachineProtection struct {
+	Namespace       string `json:"namespace"`
+	Name            string `json:"name"`
+	Cluster         string `json:"cluster"`
+	SkipRemediation bool   `json:"skipRemediation"`
+	DeleteFirst     bool   `json:"deleteFirst"`
+	// Reasons are the annotation values by annotation name
+	Reasons    map[string]string `json:"reasons,omitempty"`
+	MachineSet string            `json:"machineSet,omitempty"`
+	// DeletePolicy of the MachineSet, Random when unset
+	DeletePolicy string `json:"deletePolicy,omitempty"`
+	// Changed is false when the machine already had the requested state
+	Changed  bool     `json:"changed"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// SetMachineAnnotation sets or removes the skip-remediation or delete-machine
+// annotation of a machine, protecting it from MachineHealthCheck remediation
+// or marking it to go first when its MachineSet scales down
+func (c *Client) SetMachineAnnotation(ctx context.Context, opts MachineAnnotationOptions) (*MachineProtection, error) {
+	annotation, ok := machineAnnotations[opts.Annotation]
+	if !ok {
+		return nil, errorf(ErrInvalidArgument, "unknown machine annotation %q, use %s or %s", opts.Annotation, SkipRemediation, DeleteFirst)
+	}
+	value := opts.Reason
+	if value == "" {
+		value = "true"
+	}
+
+	machine := &clusterv1.Machine{}
+	key := client.ObjectKey{Namespace: opts.Namespace, Name: opts.Name}
+	if err := c.ctrlClient.Get(ctx, key, machine); err != nil {
+		return nil, fmt.Errorf("failed to get machine: %w", resourceError("Machine", key, err))
+	}
+	current, has := machine.Annotations[annotation]
+	changed := has == opts.Remove || (!opts.Remove && opts.Reason != "" && current != value)
+	if changed {
+		operation := "annotate-machine"
+		if opts.Remove {
+			operation = "unannotate-machine"
+		}
+		err := c.updateObject(ctx, key, machine, operation, func() error {
+			if opts.Remove {
+				delete(machine.Annotations, annotation)
+				return nil
+			}
+			if machine.Annotations == nil {
+				machine.Annotations = map[string]string{}
+			}
+			machine.Annotations[annotation] = value
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update machine annotations: %w", err)
+		}
+	}
+
+	protection := machineProtection(machine, nil)
+	protection.Changed = changed
+	if protection.MachineSet != "" {
+		ms := &clusterv1.MachineSet{}
+		if err := c.ctrlClient.Get(ctx, client.ObjectKey{Namespace: opts.Namespace, Name: protection.MachineSet}, ms); err == nil {
+			protection.DeletePolicy = machineSetDeletePolicy(ms)
+		}
+	}
+	if opts.Annotation == DeleteFirst && !opts.Remove && protection.MachineSet == "" {
+		protection.Warnings = append(protection.Warnings, "the machine is not owned by a MachineSet, so no scale-down deletes it first")
+	}
+	if opts.Annotation == SkipRemediation && !opts.Remove && machine.Annotations[clusterv1.RemediateMachineAnnotation] != "" {
+		protection.Warnings = append(protection.Warnings, "the machine is marked for remediation, which skip-remediation does not cancel")
+	}
+	return protection, nil
+}
+
+// ListMachineProtections lists the machines of a namespace, optionally of a
+// cluster, that have remediation or scale-down annotations
+func (c *Client) ListMachineProtections(ctx context.Context, namespace, clusterName string) ([]MachineProtection, error) {
+	machines, err := c.ListMachines(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	machineSets, err := c.ListMachineSets(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	policies := map[string]string{}
+	for i := range machineSets.Items {
+		policies[machineSets.Items[i].Name] = machineSetDeletePolicy(&machineSets.Items[i])
+	}
+
+	protections := []MachineProtection{}
+	for i := range machines.Items {
+		protection := machineProtection(&machines.Items[i], policies)
+		if protection.SkipRemediation || protection.DeleteFirst {
+			protections = append(protections, *protection)
+		}
+	}
+	sort.Slice(protections, func(i, j int) bool {
+		if protections[i].Cluster != protections[j].Cluster {
+			return protections[i].Cluster < protections[j].Cluster
+		}
+		return protections[i].Name < protections[j].Name
+	})
+	return protections, nil
+}
+
+// machineProtection reads the annotations of a machine, with the delete
+// policy of its MachineSet when policies has it
+func machineProtection(machine *clusterv1.Machine, policies map[string]string) *MachineProtection {
+	protection := &MachineProtection{
+		Namespace: machine.Namespace,
+		Name:      machine.Name,
+		Cluster:   machine.Spec.ClusterName,
+	}
+	for name, annotation := range machineAnnotations {
+		value, ok := machine.Annotations[annotation]
+		if !ok {
+			continue
+		}
+		switch name {
+		case SkipRemediation:
+			protection.SkipRemediation = true
+		case DeleteFirst:
+			protection.DeleteFirst = true
+		}
+		if value != "" && value != "true" {
+			if protection.Reasons == nil {
+				protection.Reasons = map[string]string{}
+			}
+			protection.Reasons[name] = value
+		}
+	}
+	for _, owner := range machine.OwnerReferences {
+		if owner.Kind == "MachineSet" {
+			protection.MachineSet = owner.Name
+			protection.DeletePolicy = policies[owner.Name]
+		}
+	}
+	return protection
+}
+
+// machineSetDeletePolicy returns the delete policy of a MachineSet, which
+// defaults to Random
+func machineSetDeletePolicy(ms *clusterv1.MachineSet) string {
+	if ms.Spec.DeletePolicy == "" {
+		return string(clusterv1.RandomMachineSetDeletePolicy)
+	}
+	return ms.Spec.DeletePolicy
+}
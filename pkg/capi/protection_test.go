@@ -0,0 +1,89 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSetMachineAnnotation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	labels := map[string]string{clusterv1.ClusterNameLabel: "prod"}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&clusterv1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-md-x", Labels: labels},
+			Spec:       clusterv1.MachineSetSpec{ClusterName: "prod", DeletePolicy: "Oldest"},
+		},
+		&clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "org-acme", Name: "prod-md-x-a", Labels: labels,
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: clusterv1.GroupVersion.String(), Kind: "MachineSet", Name: "prod-md-x"}},
+			},
+			Spec: clusterv1.MachineSpec{ClusterName: "prod"},
+		},
+		&clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-cp-b", Labels: labels},
+			Spec:       clusterv1.MachineSpec{ClusterName: "prod"},
+		},
+	).Build()}
+	ctx := context.Background()
+
+	protection, err := c.SetMachineAnnotation(ctx, MachineAnnotationOptions{Namespace: "org-acme", Name: "prod-md-x-a", Annotation: SkipRemediation, Reason: "debugging the kubelet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !protection.Changed || !protection.SkipRemediation || protection.DeletePolicy != "Oldest" || protection.Reasons[SkipRemediation] != "debugging the kubelet" {
+		t.Errorf("SetMachineAnnotation() = %+v, want remediation skipped with the reason", protection)
+	}
+	machine, err := c.GetMachine(ctx, "org-acme", "prod-md-x-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := machine.Annotations[clusterv1.MachineSkipRemediationAnnotation]; !ok {
+		t.Errorf("machine annotations = %v, want %s", machine.Annotations, clusterv1.MachineSkipRemediationAnnotation)
+	}
+	protection, err = c.SetMachineAnnotation(ctx, MachineAnnotationOptions{Namespace: "org-acme", Name: "prod-md-x-a", Annotation: SkipRemediation})
+	if err != nil || protection.Changed {
+		t.Errorf("repeated SetMachineAnnotation() = %+v, %v, want no change", protection, err)
+	}
+
+	protection, err = c.SetMachineAnnotation(ctx, MachineAnnotationOptions{Namespace: "org-acme", Name: "prod-cp-b", Annotation: DeleteFirst})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !protection.DeleteFirst || len(protection.Warnings) != 1 {
+		t.Errorf("SetMachineAnnotation() without a MachineSet = %+v, want a warning", protection)
+	}
+
+	protections, err := c.ListMachineProtections(ctx, "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(protections) != 2 || protections[0].Name != "prod-cp-b" || protections[1].DeletePolicy != "Oldest" {
+		t.Errorf("ListMachineProtections() = %+v", protections)
+	}
+
+	protection, err = c.SetMachineAnnotation(ctx, MachineAnnotationOptions{Namespace: "org-acme", Name: "prod-cp-b", Annotation: DeleteFirst, Remove: true})
+	if err != nil || !protection.Changed || protection.DeleteFirst {
+		t.Errorf("removing the annotation = %+v, %v", protection, err)
+	}
+	protections, err = c.ListMachineProtections(ctx, "org-acme", "prod")
+	if err != nil || len(protections) != 1 {
+		t.Errorf("ListMachineProtections() after removal = %+v, %v, want one machine", protections, err)
+	}
+
+	if _, err := c.SetMachineAnnotation(ctx, MachineAnnotationOptions{Namespace: "org-acme", Name: "prod-cp-b", Annotation: "keep"}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("SetMachineAnnotation(keep) error = %v, want ErrInvalidArgument", err)
+	}
+	if _, err := c.SetMachineAnnotation(ctx, MachineAnnotationOptions{Namespace: "org-acme", Name: "missing", Annotation: DeleteFirst}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("SetMachineAnnotation(missing) error = %v, want ErrNotFound", err)
+	}
+}
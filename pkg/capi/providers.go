@@ -2,8 +2,10 @@ package capi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -12,11 +14,14 @@ import (
 type Provider string
 
 const (
-	ProviderAWS     Provider = "aws"
-	ProviderAzure   Provider = "azure"
-	ProviderGCP     Provider = "gcp"
-	ProviderVSphere Provider = "vsphere"
-	ProviderUnknown Provider = "unknown"
+	ProviderAWS      Provider = "aws"
+	ProviderAzure    Provider = "azure"
+	ProviderGCP      Provider = "gcp"
+	ProviderVSphere  Provider = "vsphere"
+	ProviderMetal3   Provider = "metal3"
+	ProviderKubevirt Provider = "kubevirt"
+	ProviderDocker   Provider = "docker"
+	ProviderUnknown  Provider = "unknown"
 )
 
 // InitializeProviders adds all provider schemes to the client
@@ -44,20 +49,16 @@ func (c *Client) GetProviderForCluster(ctx context.Context, namespace, clusterNa
 	if cluster.Spec.InfrastructureRef == nil {
 		return ProviderUnknown, fmt.Errorf("cluster has no infrastructure reference")
 	}
+	return ClusterProvider(cluster), nil
+}
 
-	// Determine provider based on the infrastructure reference kind
-	switch cluster.Spec.InfrastructureRef.Kind {
-	case "AWSCluster":
-		return ProviderAWS, nil
-	case "AzureCluster":
-		return ProviderAzure, nil
-	case "GCPCluster":
-		return ProviderGCP, nil
-	case "VSphereCluster":
-		return ProviderVSphere, nil
-	default:
-		return ProviderUnknown, nil
+// ClusterProvider determines the provider of a cluster from its
+// infrastructure reference kind
+func ClusterProvider(cluster *clusterv1.Cluster) Provider {
+	if cluster.Spec.InfrastructureRef == nil {
+		return ProviderUnknown
 	}
+	return ProviderForKind(cluster.Spec.InfrastructureRef.Kind)
 }
 
 // GetKubeadmControlPlane retrieves the KubeadmControlPlane for a cluster
@@ -69,17 +70,20 @@ func (c *Client) GetKubeadmControlPlane(ctx context.Context, namespace, name str
 	}
 
 	if err := c.ctrlClient.Get(ctx, key, kcp); err != nil {
-		return nil, fmt.Errorf("failed to get KubeadmControlPlane %s/%s: %w", namespace, name, err)
+		return nil, fmt.Errorf("failed to get KubeadmControlPlane %s/%s: %w", namespace, name, resourceError("KubeadmControlPlane", key, err))
 	}
 
 	return kcp, nil
 }
 
 // ListKubeadmControlPlanes lists all KubeadmControlPlanes
-func (c *Client) ListKubeadmControlPlanes(ctx context.Context, namespace string) (*controlplanev1.KubeadmControlPlaneList, error) {
+func (c *Client) ListKubeadmControlPlanes(ctx context.Context, namespace string, listOpts ...ListOption) (*controlplanev1.KubeadmControlPlaneList, error) {
 	kcpList := &controlplanev1.KubeadmControlPlaneList{}
 
-	opts := []client.ListOption{}
+	opts, err := clientListOptions(listOpts, nil)
+	if err != nil {
+		return nil, err
+	}
 	if namespace != "" {
 		opts = append(opts, client.InNamespace(namespace))
 	}
@@ -101,47 +105,60 @@ func (c *Client) GetInfrastructureResource(ctx context.Context, ref *client.Obje
 
 // ScaleControlPlane scales a KubeadmControlPlane to the specified number of replicas
 func (c *Client) ScaleControlPlane(ctx context.Context, namespace, name string, replicas int32) error {
-	kcp, err := c.GetKubeadmControlPlane(ctx, namespace, name)
-	if err != nil {
-		return err
-	}
+	kcp := &controlplanev1.KubeadmControlPlane{}
+	key := client.ObjectKey{Namespace: namespace, Name: name}
 
 	// Update replicas
-	kcp.Spec.Replicas = &replicas
-
-	if err := c.ctrlClient.Update(ctx, kcp); err != nil {
+	err := c.updateObject(ctx, key, kcp, "scale", func() error {
+		kcp.Spec.Replicas = &replicas
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to scale control plane: %w", err)
 	}
 
 	return nil
 }
 
-// ScaleCluster scales either control plane or worker nodes of a cluster
-func (c *Client) ScaleCluster(ctx context.Context, namespace, clusterName, target string, replicas int, machineDeploymentName string) error {
+// ScaleCluster scales either control plane or worker nodes of a cluster.
+// Workers are scaled through a MachineDeployment or, e.g. for AKS node pools,
+// a MachinePool.
+func (c *Client) ScaleCluster(ctx context.Context, namespace, clusterName, target string, replicas int, machineDeploymentName, machinePoolName string) error {
 	switch target {
 	case "controlplane":
-		return c.ScaleControlPlane(ctx, namespace, clusterName, int32(replicas))
+		err := c.ScaleControlPlane(ctx, namespace, clusterName, int32(replicas))
+		if errors.Is(err, ErrNotFound) {
+			if cluster, getErr := c.GetCluster(ctx, namespace, clusterName); getErr == nil && isAKSCluster(cluster) {
+				return errorf(ErrPreconditionFailed, "the control plane of AKS cluster %s is managed by Azure and cannot be scaled", clusterName)
+			}
+		}
+		return err
 	case "workers":
-		if machineDeploymentName == "" {
-			return fmt.Errorf("machineDeployment name is required when scaling workers")
+		switch {
+		case machineDeploymentName != "" && machinePoolName != "":
+			return errorf(ErrInvalidArgument, "set either a machineDeployment or a machinePool name, not both")
+		case machinePoolName != "":
+			return c.ScaleMachinePool(ctx, namespace, machinePoolName, int32(replicas))
+		case machineDeploymentName == "":
+			return errorf(ErrInvalidArgument, "machineDeployment or machinePool name is required when scaling workers")
 		}
 		return c.ScaleMachineDeployment(ctx, namespace, machineDeploymentName, int32(replicas))
 	default:
-		return fmt.Errorf("invalid target: %s (must be 'controlplane' or 'workers')", target)
+		return errorf(ErrInvalidArgument, "invalid target: %s (must be 'controlplane' or 'workers')", target)
 	}
 }
 
 // ScaleMachineDeployment scales a MachineDeployment to the specified number of replicas
 func (c *Client) ScaleMachineDeployment(ctx context.Context, namespace, name string, replicas int32) error {
-	md, err := c.GetMachineDeployment(ctx, namespace, name)
-	if err != nil {
-		return err
-	}
+	md := &clusterv1.MachineDeployment{}
+	key := client.ObjectKey{Namespace: namespace, Name: name}
 
 	// Update replicas
-	md.Spec.Replicas = &replicas
-
-	if err := c.ctrlClient.Update(ctx, md); err != nil {
+	err := c.updateObject(ctx, key, md, "scale", func() error {
+		md.Spec.Replicas = &replicas
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to scale machine deployment: %w", err)
 	}
 
@@ -0,0 +1,234 @@
+package capi
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Community infrastructure providers, detected and created through the
+// registry without dedicated tools
+const (
+	ProviderHetzner   Provider = "hetzner"
+	ProviderNutanix   Provider = "nutanix"
+	ProviderOCI       Provider = "oci"
+	ProviderProxmox   Provider = "proxmox"
+	ProviderLinode    Provider = "linode"
+	ProviderOpenStack Provider = "openstack"
+)
+
+// ProviderCapabilities flags what a provider offers and what the server
+// supports for it
+type ProviderCapabilities struct {
+	// Inspect is set when dedicated capi_<provider>_ tools read the
+	// provider's infrastructure
+	Inspect bool `json:"inspect"`
+	// ManagedKubernetes is set when the provider offers a managed control
+	// plane, such as EKS, AKS, GKE or OKE
+	ManagedKubernetes bool `json:"managedKubernetes"`
+	// MachinePools is set when the provider implements MachinePools
+	MachinePools bool `json:"machinePools"`
+	// CreateCluster is set when capi_create_cluster creates all objects of a
+	// working cluster rather than only the Cluster
+	CreateCluster bool `json:"createCluster"`
+}
+
+// ProviderInfo describes an infrastructure provider
+type ProviderInfo struct {
+	Name        Provider `json:"name"`
+	DisplayName string   `json:"displayName"`
+	// ClusterKinds are the infrastructure cluster kinds of the provider; the
+	// first is the one of new clusters
+	ClusterKinds []string `json:"clusterKinds"`
+	// APIVersion is the API version of the infrastructure cluster of new
+	// clusters
+	APIVersion   string               `json:"apiVersion"`
+	Capabilities ProviderCapabilities `json:"capabilities"`
+}
+
+// builtinProviders are the providers known to the server. Adding a provider
+// only takes an entry here or a call to RegisterProvider.
+var builtinProviders = []ProviderInfo{
+	{
+		Name: ProviderAWS, DisplayName: "AWS",
+		ClusterKinds: []string{"AWSCluster", "AWSManagedCluster"},
+		APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta2",
+		Capabilities: ProviderCapabilities{Inspect: true, ManagedKubernetes: true, MachinePools: true},
+	},
+	{
+		Name: ProviderAzure, DisplayName: "Azure",
+		ClusterKinds: []string{"AzureCluster", "AzureManagedCluster", "AzureASOManagedCluster"},
+		APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+		Capabilities: ProviderCapabilities{Inspect: true, ManagedKubernetes: true, MachinePools: true},
+	},
+	{
+		Name: ProviderGCP, DisplayName: "GCP",
+		ClusterKinds: []string{"GCPCluster", "GCPManagedCluster"},
+		APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+		Capabilities: ProviderCapabilities{Inspect: true, ManagedKubernetes: true, MachinePools: true},
+	},
+	{
+		Name: ProviderVSphere, DisplayName: "vSphere",
+		ClusterKinds: []string{"VSphereCluster"},
+		APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+		Capabilities: ProviderCapabilities{Inspect: true},
+	},
+	{
+		Name: ProviderMetal3, DisplayName: "Metal3",
+		ClusterKinds: []string{"Metal3Cluster"},
+		APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+		Capabilities: ProviderCapabilities{Inspect: true},
+	},
+	{
+		Name: ProviderKubevirt, DisplayName: "KubeVirt",
+		ClusterKinds: []string{"KubevirtCluster"},
+		APIVersion:   "infrastructure.cluster.x-k8s.io/v1alpha1",
+		Capabilities: ProviderCapabilities{Inspect: true},
+	},
+	{
+		Name: ProviderDocker, DisplayName: "Docker",
+		ClusterKinds: []string{"DockerCluster"},
+		APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+		Capabilities: ProviderCapabilities{MachinePools: true, CreateCluster: true},
+	},
+	{
+		Name: ProviderHetzner, DisplayName: "Hetzner",
+		ClusterKinds: []string{"HetznerCluster"},
+		APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+	},
+	{
+		Name: ProviderNutanix, DisplayName: "Nutanix",
+		ClusterKinds: []string{"NutanixCluster"},
+		APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+	},
+	{
+		Name: ProviderOCI, DisplayName: "Oracle Cloud Infrastructure",
+		ClusterKinds: []string{"OCICluster", "OCIManagedCluster"},
+		APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta2",
+		Capabilities: ProviderCapabilities{ManagedKubernetes: true, MachinePools: true},
+	},
+	{
+		Name: ProviderProxmox, DisplayName: "Proxmox",
+		ClusterKinds: []string{"ProxmoxCluster"},
+		APIVersion:   "infrastructure.cluster.x-k8s.io/v1alpha1",
+	},
+	{
+		Name: ProviderLinode, DisplayName: "Linode",
+		ClusterKinds: []string{"LinodeCluster"},
+		APIVersion:   "infrastructure.cluster.x-k8s.io/v1alpha2",
+	},
+	{
+		Name: ProviderOpenStack, DisplayName: "OpenStack",
+		ClusterKinds: []string{"OpenStackCluster"},
+		APIVersion:   "infrastructure.cluster.x-k8s.io/v1beta1",
+	},
+}
+
+// providerRegistry maps providers and their infrastructure cluster kinds
+type providerRegistry struct {
+	mu        sync.RWMutex
+	providers []ProviderInfo
+	byKind    map[string]Provider
+}
+
+// defaultProviders is the registry used by the package functions
+var defaultProviders = newProviderRegistry(builtinProviders...)
+
+// newProviderRegistry returns a registry of providers. It panics on
+// conflicting entries, which are programming errors.
+func newProviderRegistry(providers ...ProviderInfo) *providerRegistry {
+	r := &providerRegistry{byKind: make(map[string]Provider)}
+	for _, info := range providers {
+		if err := r.register(info); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// register adds a provider, refusing names and kinds already registered
+func (r *providerRegistry) register(info ProviderInfo) error {
+	if info.Name == "" || info.Name == ProviderUnknown || len(info.ClusterKinds) == 0 {
+		return errorf(ErrInvalidArgument, "provider %q needs a name and at least one cluster kind", info.Name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.providers {
+		if existing.Name == info.Name {
+			return errorf(ErrAlreadyExists, "provider %s is already registered", info.Name)
+		}
+	}
+	for _, kind := range info.ClusterKinds {
+		if provider, ok := r.byKind[kind]; ok {
+			return errorf(ErrAlreadyExists, "kind %s is already registered for provider %s", kind, provider)
+		}
+	}
+	info.ClusterKinds = slices.Clone(info.ClusterKinds)
+	for _, kind := range info.ClusterKinds {
+		r.byKind[kind] = info.Name
+	}
+	r.providers = append(r.providers, info)
+	return nil
+}
+
+// lookup returns a provider by name, ignoring case
+func (r *providerRegistry) lookup(name string) (ProviderInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, info := range r.providers {
+		if strings.EqualFold(string(info.Name), name) {
+			return info, true
+		}
+	}
+	return ProviderInfo{}, false
+}
+
+// forKind returns the provider of an infrastructure cluster kind
+func (r *providerRegistry) forKind(kind string) Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if provider, ok := r.byKind[kind]; ok {
+		return provider
+	}
+	return ProviderUnknown
+}
+
+// list returns the providers in registration order
+func (r *providerRegistry) list() []ProviderInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.providers)
+}
+
+// RegisterProvider adds an infrastructure provider to the registry. Register
+// providers during initialization: tool schemas list the providers known when
+// they are built.
+func RegisterProvider(info ProviderInfo) error {
+	return defaultProviders.register(info)
+}
+
+// LookupProvider returns a registered provider by name, ignoring case
+func LookupProvider(name string) (ProviderInfo, bool) {
+	return defaultProviders.lookup(name)
+}
+
+// ProviderForKind returns the provider of an infrastructure cluster kind, or
+// ProviderUnknown
+func ProviderForKind(kind string) Provider {
+	return defaultProviders.forKind(kind)
+}
+
+// Providers lists the registered providers
+func Providers() []ProviderInfo {
+	return defaultProviders.list()
+}
+
+// ProviderNames lists the names of the registered providers
+func ProviderNames() []string {
+	providers := defaultProviders.list()
+	names := make([]string, 0, len(providers))
+	for _, info := range providers {
+		names = append(names, string(info.Name))
+	}
+	return names
+}
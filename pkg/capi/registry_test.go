@@ -0,0 +1,76 @@
+package capi
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestProviderForKind(t *testing.T) {
+	tests := map[string]Provider{
+		"AWSCluster":          ProviderAWS,
+		"AWSManagedCluster":   ProviderAWS,
+		"AzureManagedCluster": ProviderAzure,
+		"DockerCluster":       ProviderDocker,
+		"HetznerCluster":      ProviderHetzner,
+		"NutanixCluster":      ProviderNutanix,
+		"OCIManagedCluster":   ProviderOCI,
+		"ProxmoxCluster":      ProviderProxmox,
+		"LinodeCluster":       ProviderLinode,
+		"OpenStackCluster":    ProviderOpenStack,
+		"UnheardOfCluster":    ProviderUnknown,
+	}
+	for kind, want := range tests {
+		if got := ProviderForKind(kind); got != want {
+			t.Errorf("ProviderForKind(%s) = %s, want %s", kind, got, want)
+		}
+	}
+
+	cluster := &clusterv1.Cluster{}
+	if got := ClusterProvider(cluster); got != ProviderUnknown {
+		t.Errorf("ClusterProvider() without infrastructure reference = %s, want unknown", got)
+	}
+	cluster.Spec.InfrastructureRef = &corev1.ObjectReference{Kind: "OCICluster"}
+	if got := ClusterProvider(cluster); got != ProviderOCI {
+		t.Errorf("ClusterProvider() = %s, want oci", got)
+	}
+
+	info, ok := LookupProvider("Hetzner")
+	if !ok || info.ClusterKinds[0] != "HetznerCluster" || info.APIVersion == "" {
+		t.Errorf("LookupProvider(Hetzner) = %+v, %v", info, ok)
+	}
+	if _, ok := LookupProvider("unknown"); ok {
+		t.Error("LookupProvider(unknown) found a provider")
+	}
+}
+
+func TestProviderRegistryRegister(t *testing.T) {
+	registry := newProviderRegistry(ProviderInfo{Name: ProviderAWS, ClusterKinds: []string{"AWSCluster"}})
+
+	if err := registry.register(ProviderInfo{Name: "outscale", ClusterKinds: []string{"OscCluster"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := registry.forKind("OscCluster"); got != "outscale" {
+		t.Errorf("forKind(OscCluster) = %s, want outscale", got)
+	}
+	if len(registry.list()) != 2 {
+		t.Errorf("list() = %+v, want 2 providers", registry.list())
+	}
+
+	for name, info := range map[string]ProviderInfo{
+		"duplicate name": {Name: ProviderAWS, ClusterKinds: []string{"OtherCluster"}},
+		"duplicate kind": {Name: "other", ClusterKinds: []string{"AWSCluster"}},
+	} {
+		if err := registry.register(info); !errors.Is(err, ErrAlreadyExists) {
+			t.Errorf("%s: register() error = %v, want ErrAlreadyExists", name, err)
+		}
+	}
+	if err := registry.register(ProviderInfo{Name: "nokinds"}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("register() without kinds error = %v, want ErrInvalidArgument", err)
+	}
+	if got := registry.forKind("OtherCluster"); got != ProviderUnknown {
+		t.Errorf("forKind(OtherCluster) = %s, a refused provider was registered", got)
+	}
+}
@@ -0,0 +1,233 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// releaseStateDeprecated marks releases no new clusters or upgrades should use
+const releaseStateDeprecated = "deprecated"
+
+// ReleaseComponent is a component or app of a Giant Swarm release
+type ReleaseComponent struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Release is a Giant Swarm release: a tested set of component and app
+// versions that clusters of a provider are created with and upgraded to
+type Release struct {
+	// Name is the name of the Release object, e.g. aws-29.1.0
+	Name     string   `json:"name"`
+	Provider Provider `json:"provider"`
+	// Version is the release version clusters are labeled with, e.g. 29.1.0
+	Version    string             `json:"version"`
+	State      string             `json:"state,omitempty"`
+	Date       string             `json:"date,omitempty"`
+	Kubernetes string             `json:"kubernetes,omitempty"`
+	Components []ReleaseComponent `json:"components"`
+	Apps       []ReleaseComponent `json:"apps"`
+}
+
+// Deprecated reports whether the release may no longer be used
+func (r *Release) Deprecated() bool {
+	return r.State == releaseStateDeprecated
+}
+
+// ListReleases lists the Giant Swarm releases of the management cluster,
+// only those of provider if set, newest first. Management clusters without
+// the Release CRD have no releases.
+func (c *Client) ListReleases(ctx context.Context, provider Provider) ([]Release, error) {
+	items, err := c.listUnstructured(ctx, releaseGVK)
+	if err != nil {
+		return nil, err
+	}
+	releases := []Release{}
+	for i := range items {
+		release := newRelease(&items[i])
+		if provider != "" && release.Provider != provider {
+			continue
+		}
+		releases = append(releases, release)
+	}
+	sort.SliceStable(releases, func(i, j int) bool {
+		a, errA := version.ParseSemantic(releases[i].Version)
+		b, errB := version.ParseSemantic(releases[j].Version)
+		if errA != nil || errB != nil {
+			return releases[i].Name > releases[j].Name
+		}
+		return a.GreaterThan(b)
+	})
+	return releases, nil
+}
+
+// newRelease reads a Release object. Releases name their provider as prefix
+// of their version, e.g. aws-29.1.0.
+func newRelease(item *unstructured.Unstructured) Release {
+	release := Release{Name: item.GetName(), Version: item.GetName(), Components: []ReleaseComponent{}, Apps: []ReleaseComponent{}}
+	if prefix, v, ok := strings.Cut(item.GetName(), "-"); ok {
+		if _, known := LookupProvider(prefix); known {
+			release.Provider, release.Version = Provider(prefix), v
+		}
+	}
+	release.State, _, _ = unstructured.NestedString(item.Object, "spec", "state")
+	release.Date, _, _ = unstructured.NestedString(item.Object, "spec", "date")
+	release.Components = releaseComponents(item, "components")
+	release.Apps = releaseComponents(item, "apps")
+	for _, component := range release.Components {
+		if component.Name == "kubernetes" {
+			release.Kubernetes = component.Version
+		}
+	}
+	return release
+}
+
+// releaseComponents reads the components or apps of a Release object
+func releaseComponents(item *unstructured.Unstructured, field string) []ReleaseComponent {
+	entries, _, _ := unstructured.NestedSlice(item.Object, "spec", field)
+	components := make([]ReleaseComponent, 0, len(entries))
+	for _, entry := range entries {
+		fields, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := fields["name"].(string)
+		v, _ := fields["version"].(string)
+		components = append(components, ReleaseComponent{Name: name, Version: v})
+	}
+	return components
+}
+
+// getRelease returns the release of a provider with a version
+func (c *Client) getRelease(ctx context.Context, provider Provider, releaseVersion string) (*Release, error) {
+	item := &unstructured.Unstructured{}
+	item.SetGroupVersionKind(releaseGVK.GroupVersion().WithKind("Release"))
+	key := client.ObjectKey{Name: string(provider) + "-" + strings.TrimPrefix(releaseVersion, "v")}
+	if err := c.ctrlClient.Get(ctx, key, item); err != nil {
+		return nil, fmt.Errorf("failed to get release %s: %w", key.Name, resourceError("Release", key, err))
+	}
+	release := newRelease(item)
+	return &release, nil
+}
+
+// ReleaseChange is a component or app whose version differs between two
+// releases. From is empty for added and To for removed ones.
+type ReleaseChange struct {
+	Name string `json:"name"`
+	// Type is component or app
+	Type string `json:"type"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// diffReleases lists the components and apps that change between releases
+func diffReleases(from, to *Release) []ReleaseChange {
+	changes := []ReleaseChange{}
+	diff := func(kind string, before, after []ReleaseComponent) {
+		versions := map[string][2]string{}
+		for _, component := range before {
+			v := versions[component.Name]
+			v[0] = component.Version
+			versions[component.Name] = v
+		}
+		for _, component := range after {
+			v := versions[component.Name]
+			v[1] = component.Version
+			versions[component.Name] = v
+		}
+		for _, name := range sortedKeys(versions) {
+			if v := versions[name]; v[0] != v[1] {
+				changes = append(changes, ReleaseChange{Name: name, Type: kind, From: v[0], To: v[1]})
+			}
+		}
+	}
+	var beforeComponents, beforeApps []ReleaseComponent
+	if from != nil {
+		beforeComponents, beforeApps = from.Components, from.Apps
+	}
+	diff("component", beforeComponents, to.Components)
+	diff("app", beforeApps, to.Apps)
+	return changes
+}
+
+// ReleaseUpgrade describes the upgrade of a cluster to another release
+type ReleaseUpgrade struct {
+	Namespace   string `json:"namespace"`
+	Cluster     string `json:"cluster"`
+	FromRelease string `json:"fromRelease,omitempty"`
+	ToRelease   string `json:"toRelease"`
+	// Changes are the component and app versions the upgrade changes; all of
+	// the target release when the current release is unknown
+	Changes []ReleaseChange `json:"changes"`
+	// Applied is false for a dry run
+	Applied bool `json:"applied"`
+}
+
+// UpgradeClusterRelease upgrades a Giant Swarm cluster to another release by
+// setting its release.giantswarm.io/version label, which the Giant Swarm
+// operators roll out to the components of the cluster. The target release
+// must exist for the provider of the cluster, must not be deprecated and
+// must be newer than the current one. With dryRun only the component and
+// app changes are returned.
+func (c *Client) UpgradeClusterRelease(ctx context.Context, namespace, name, targetRelease string, dryRun bool) (*ReleaseUpgrade, error) {
+	targetRelease = strings.TrimPrefix(targetRelease, "v")
+	if _, err := version.ParseSemantic(targetRelease); err != nil {
+		return nil, errorf(ErrInvalidArgument, "invalid release version %q: %v", targetRelease, err)
+	}
+	cluster, err := c.GetCluster(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	provider := ClusterProvider(cluster)
+	if provider == ProviderUnknown {
+		return nil, errorf(ErrPreconditionFailed, "the provider of cluster %s/%s is unknown, its releases cannot be found", namespace, name)
+	}
+	target, err := c.getRelease(ctx, provider, targetRelease)
+	if err != nil {
+		return nil, err
+	}
+	if target.Deprecated() {
+		return nil, errorf(ErrPreconditionFailed, "release %s is deprecated", target.Name)
+	}
+
+	upgrade := &ReleaseUpgrade{Namespace: namespace, Cluster: name, FromRelease: cluster.Labels[ReleaseVersionLabel], ToRelease: target.Version}
+	var current *Release
+	if upgrade.FromRelease != "" {
+		if upgrade.FromRelease == target.Version {
+			return nil, errorf(ErrPreconditionFailed, "cluster %s/%s already runs release %s", namespace, name, target.Version)
+		}
+		from, err := version.ParseSemantic(upgrade.FromRelease)
+		if err == nil && !version.MustParseSemantic(target.Version).GreaterThan(from) {
+			return nil, errorf(ErrPreconditionFailed, "release %s is older than release %s of cluster %s/%s, downgrades are not supported", target.Version, upgrade.FromRelease, namespace, name)
+		}
+		// A current release that no longer exists shows the whole target
+		current, err = c.getRelease(ctx, provider, upgrade.FromRelease)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+	upgrade.Changes = diffReleases(current, target)
+	if dryRun {
+		return upgrade, nil
+	}
+
+	err = c.updateObject(ctx, client.ObjectKeyFromObject(cluster), cluster, "upgrade-release", func() error {
+		if cluster.Labels == nil {
+			cluster.Labels = map[string]string{}
+		}
+		cluster.Labels[ReleaseVersionLabel] = target.Version
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set the release of cluster %s/%s: %w", namespace, name, err)
+	}
+	upgrade.Applied = previewFromContext(ctx) == nil
+	return upgrade, nil
+}
@@ -0,0 +1,120 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestRelease returns a Release object with components and apps given as
+// name/version pairs
+func newTestRelease(name, state string, components, apps []string) *unstructured.Unstructured {
+	pairs := func(values []string) []any {
+		var entries []any
+		for i := 0; i+1 < len(values); i += 2 {
+			entries = append(entries, map[string]any{"name": values[i], "version": values[i+1]})
+		}
+		return entries
+	}
+	release := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"state": state, "components": pairs(components), "apps": pairs(apps)},
+	}}
+	release.SetAPIVersion("release.giantswarm.io/v1alpha1")
+	release.SetKind("Release")
+	release.SetName(name)
+	return release
+}
+
+func TestUpgradeClusterRelease(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod", Labels: map[string]string{ReleaseVersionLabel: "29.0.0"}},
+		Spec:       clusterv1.ClusterSpec{InfrastructureRef: &corev1.ObjectReference{Kind: "AWSCluster", Name: "prod"}},
+	}
+	history, err := NewChangeHistory(0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{
+		ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			cluster,
+			newTestRelease("aws-28.0.0", "deprecated", []string{"kubernetes", "1.29.4"}, nil),
+			newTestRelease("aws-29.0.0", "active", []string{"kubernetes", "1.30.2", "flatcar", "3815.2.5"}, []string{"cilium", "0.25.0", "coredns", "1.21.0"}),
+			newTestRelease("aws-29.1.0", "active", []string{"kubernetes", "1.30.4", "flatcar", "3815.2.5"}, []string{"cilium", "0.26.0", "karpenter", "0.1.0"}),
+			newTestRelease("azure-29.1.0", "active", []string{"kubernetes", "1.30.4"}, nil),
+		).Build(),
+		changes: history,
+	}
+	ctx := context.Background()
+
+	releases, err := c.ListReleases(ctx, ProviderAWS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(releases) != 3 || releases[0].Version != "29.1.0" || releases[2].Version != "28.0.0" {
+		t.Fatalf("releases = %+v, want the AWS releases newest first", releases)
+	}
+	if releases[0].Kubernetes != "1.30.4" || !releases[2].Deprecated() {
+		t.Errorf("releases = %+v", releases)
+	}
+
+	upgrade, err := c.UpgradeClusterRelease(ctx, "org-acme", "prod", "v29.1.0", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ReleaseChange{
+		{Name: "kubernetes", Type: "component", From: "1.30.2", To: "1.30.4"},
+		{Name: "cilium", Type: "app", From: "0.25.0", To: "0.26.0"},
+		{Name: "coredns", Type: "app", From: "1.21.0"},
+		{Name: "karpenter", Type: "app", To: "0.1.0"},
+	}
+	if len(upgrade.Changes) != len(want) {
+		t.Fatalf("changes = %+v, want %+v", upgrade.Changes, want)
+	}
+	for i := range want {
+		if upgrade.Changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, upgrade.Changes[i], want[i])
+		}
+	}
+	if upgrade.Applied {
+		t.Error("a dry run was applied")
+	}
+
+	for target, wantErr := range map[string]error{
+		"28.0.0": ErrPreconditionFailed,
+		"29.0.0": ErrPreconditionFailed,
+		"30.0.0": ErrNotFound,
+		"next":   ErrInvalidArgument,
+	} {
+		if _, err := c.UpgradeClusterRelease(ctx, "org-acme", "prod", target, false); !errors.Is(err, wantErr) {
+			t.Errorf("UpgradeClusterRelease(%s) error = %v, want %v", target, err, wantErr)
+		}
+	}
+
+	if upgrade, err = c.UpgradeClusterRelease(ctx, "org-acme", "prod", "29.1.0", false); err != nil {
+		t.Fatal(err)
+	}
+	if !upgrade.Applied {
+		t.Error("the upgrade was not applied")
+	}
+	current, err := c.GetCluster(ctx, "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Labels[ReleaseVersionLabel] != "29.1.0" {
+		t.Errorf("release label = %q, want 29.1.0", current.Labels[ReleaseVersionLabel])
+	}
+	if changes := history.List(); len(changes) != 1 || changes[0].Operation != "upgrade-release" {
+		t.Errorf("recorded changes = %+v, want the release upgrade", changes)
+	}
+}
@@ -0,0 +1,344 @@
+package capi
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DecodeBundle reads the objects of a backup bundle: a multi-document YAML
+// as written by BackupCluster or ExportGitOps, or a JSON v1 List
+func DecodeBundle(data []byte) ([]*unstructured.Unstructured, error) {
+	var objects []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, errorf(ErrInvalidArgument, "invalid bundle: %v", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if obj.IsList() {
+			err := obj.EachListItem(func(item runtime.Object) error {
+				objects = append(objects, item.(*unstructured.Unstructured))
+				return nil
+			})
+			if err != nil {
+				return nil, errorf(ErrInvalidArgument, "invalid bundle: %v", err)
+			}
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+// DecodeExportArchive reads the objects of the YAML files of a .tar.gz
+// archive written by GitOpsExport.Archive
+func DecodeExportArchive(data []byte) ([]*unstructured.Unstructured, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errorf(ErrInvalidArgument, "invalid archive: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	var objects []*unstructured.Unstructured
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, errorf(ErrInvalidArgument, "invalid archive: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg || (path.Ext(header.Name) != ".yaml" && path.Ext(header.Name) != ".yml") {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		fileObjects, err := DecodeBundle(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", header.Name, err)
+		}
+		objects = append(objects, fileObjects...)
+	}
+}
+
+// RestoreClusterOptions contains options for restoring a cluster
+type RestoreClusterOptions struct {
+	// Namespace the objects are restored into, whatever namespace the
+	// bundle names
+	Namespace string
+	// Objects are the objects of the bundle, see DecodeBundle
+	Objects []*unstructured.Unstructured
+	// KeepPaused leaves the Cluster paused after the restore, so its
+	// objects can be checked before the controllers act on them
+	KeepPaused bool
+	// DryRun reports what would be restored without creating anything
+	DryRun bool
+}
+
+// RestoredObject is an object of a bundle and what the restore did with it
+type RestoredObject struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Reason is why an object was skipped or failed
+	Reason string `json:"reason,omitempty"`
+}
+
+// OwnerLink is an owner reference set on a restored object
+type OwnerLink struct {
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+}
+
+// ClusterRestore describes a restored cluster
+type ClusterRestore struct {
+	Namespace string `json:"namespace"`
+	Cluster   string `json:"cluster"`
+	// Restored are the created objects in the order they were created
+	Restored []RestoredObject `json:"restored"`
+	// Skipped are objects that exist already or are not part of a cluster
+	Skipped []RestoredObject `json:"skipped"`
+	// Failed are objects whose creation failed
+	Failed []RestoredObject `json:"failed,omitempty"`
+	// OwnerReferences are the owner references set after the objects were
+	// created
+	OwnerReferences []OwnerLink `json:"ownerReferences,omitempty"`
+	Paused          bool        `json:"paused"`
+	DryRun          bool        `json:"dryRun"`
+}
+
+// restoreRank orders the objects of a bundle: Secrets and ConfigMaps,
+// templates, infrastructure clusters, the Cluster, control planes, machine
+// deployments and pools, then the rest
+func restoreRank(obj *unstructured.Unstructured) int {
+	gvk := obj.GroupVersionKind()
+	switch {
+	case gvk.Group == "":
+		return 0
+	case gvk.Kind == "ClusterClass" || strings.HasSuffix(gvk.Kind, "Template"):
+		return 1
+	case gvk.Group == infrastructureGroup && strings.HasSuffix(gvk.Kind, "Cluster"):
+		return 2
+	case gvk.Group == clusterv1.GroupVersion.Group && gvk.Kind == "Cluster":
+		return 3
+	case gvk.Group == "controlplane.cluster.x-k8s.io" || strings.HasSuffix(gvk.Kind, "ControlPlane"):
+		return 4
+	case gvk.Group == clusterv1.GroupVersion.Group && (gvk.Kind == "MachineDeployment" || gvk.Kind == "MachinePool" || gvk.Kind == "MachineHealthCheck"):
+		return 5
+	}
+	return 6
+}
+
+// restorable reports whether an object belongs to a cluster: a Secret,
+// ConfigMap or Cluster API object. Other objects of exports, such as the
+// kustomization and the Flux or Argo CD sync object, are skipped.
+func restorable(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	if gvk.Group == "" {
+		return gvk.Kind == "Secret" || gvk.Kind == "ConfigMap"
+	}
+	return gvk.Group == clusterv1.GroupVersion.Group || strings.HasSuffix(gvk.Group, "."+clusterv1.GroupVersion.Group)
+}
+
+// ownedByCluster reports whether Cluster API makes the Cluster an owner of
+// an object: its infrastructure cluster, control plane, machine deployments
+// and pools, health checks, templates and Secrets
+func ownedByCluster(obj *unstructured.Unstructured, rank int) bool {
+	switch rank {
+	case 0:
+		return obj.GetKind() == "Secret" && obj.GetLabels()[clusterv1.ClusterNameLabel] != ""
+	case 1:
+		return obj.GetKind() != "ClusterClass"
+	case 2, 4, 5:
+		return true
+	}
+	return false
+}
+
+// RestoreCluster re-creates the objects of a backup bundle in a namespace in
+// the order Cluster API needs them: templates, the infrastructure cluster,
+// the Cluster, the control plane and the machine deployments. Objects that
+// exist already are skipped and kept as they are. The Cluster is created
+// paused, so no controller acts on a partial cluster, and resumed at the
+// end unless KeepPaused is set or it was paused in the bundle. Owner
+// references of the bundle are re-linked to the new UIDs of their owners,
+// and objects Cluster API expects to be owned by the Cluster get it as
+// owner.
+func (c *Client) RestoreCluster(ctx context.Context, opts RestoreClusterOptions) (*ClusterRestore, error) {
+	var clusters []*unstructured.Unstructured
+	for _, obj := range opts.Objects {
+		if obj.GroupVersionKind().GroupKind() == clusterv1.GroupVersion.WithKind("Cluster").GroupKind() {
+			clusters = append(clusters, obj)
+		}
+	}
+	if len(clusters) != 1 {
+		return nil, errorf(ErrInvalidArgument, "a bundle must hold exactly one Cluster, found %d", len(clusters))
+	}
+	restore := &ClusterRestore{
+		Namespace: opts.Namespace,
+		Cluster:   clusters[0].GetName(),
+		Restored:  []RestoredObject{},
+		Skipped:   []RestoredObject{},
+		DryRun:    opts.DryRun,
+	}
+	bundlePaused, _, _ := unstructured.NestedBool(clusters[0].Object, "spec", "paused")
+
+	type pending struct {
+		obj    *unstructured.Unstructured
+		rank   int
+		owners []metav1.OwnerReference
+	}
+	var objects []pending
+	for _, source := range opts.Objects {
+		if !restorable(source) {
+			restore.Skipped = append(restore.Skipped, RestoredObject{Kind: source.GetKind(), Name: source.GetName(), Reason: "not part of a Cluster API cluster"})
+			continue
+		}
+		obj := source.DeepCopy()
+		owners := obj.GetOwnerReferences()
+		obj.SetNamespace(opts.Namespace)
+		obj.SetOwnerReferences(nil)
+		for _, field := range []string{"resourceVersion", "uid", "creationTimestamp", "generation", "managedFields", "deletionTimestamp", "deletionGracePeriodSeconds", "selfLink"} {
+			unstructured.RemoveNestedField(obj.Object, "metadata", field)
+		}
+		delete(obj.Object, "status")
+		objects = append(objects, pending{obj: obj, rank: restoreRank(obj), owners: owners})
+	}
+	sort.SliceStable(objects, func(i, j int) bool { return objects[i].rank < objects[j].rank })
+
+	// uids are the UIDs of the restored and existing objects by group, kind
+	// and name, to re-link owner references
+	uids := map[string]string{}
+	ownerKey := func(group, kind, name string) string { return group + "/" + kind + "/" + name }
+	var created []pending
+	for _, p := range objects {
+		obj := p.obj
+		ref := RestoredObject{Kind: obj.GetKind(), Name: obj.GetName()}
+		key := client.ObjectKeyFromObject(obj)
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(obj.GroupVersionKind())
+		err := c.ctrlClient.Get(ctx, key, existing)
+		switch {
+		case err == nil:
+			ref.Reason = "already exists"
+			restore.Skipped = append(restore.Skipped, ref)
+			uids[ownerKey(obj.GroupVersionKind().Group, obj.GetKind(), obj.GetName())] = string(existing.GetUID())
+			continue
+		case meta.IsNoMatchError(err):
+			ref.Reason = "kind is not installed"
+			restore.Skipped = append(restore.Skipped, ref)
+			continue
+		case !apierrors.IsNotFound(err):
+			ref.Reason = resourceError(obj.GetKind(), key, err).Error()
+			restore.Failed = append(restore.Failed, ref)
+			continue
+		}
+
+		if opts.DryRun {
+			restore.Restored = append(restore.Restored, ref)
+			continue
+		}
+		if p.rank == 3 {
+			if err := unstructured.SetNestedField(obj.Object, true, "spec", "paused"); err != nil {
+				return nil, err
+			}
+		}
+		if err := c.ctrlClient.Create(ctx, obj); err != nil {
+			ref.Reason = err.Error()
+			restore.Failed = append(restore.Failed, ref)
+			continue
+		}
+		restore.Restored = append(restore.Restored, ref)
+		uids[ownerKey(obj.GroupVersionKind().Group, obj.GetKind(), obj.GetName())] = string(obj.GetUID())
+		created = append(created, p)
+	}
+	if opts.DryRun {
+		return restore, nil
+	}
+
+	clusterUID := uids[ownerKey(clusterv1.GroupVersion.Group, "Cluster", restore.Cluster)]
+	for _, p := range created {
+		var owners []metav1.OwnerReference
+		for _, owner := range p.owners {
+			gv, err := schema.ParseGroupVersion(owner.APIVersion)
+			if err != nil {
+				continue
+			}
+			uid, ok := uids[ownerKey(gv.Group, owner.Kind, owner.Name)]
+			if !ok {
+				// Owners outside the bundle, such as a ClusterResourceSet,
+				// are linked when they exist
+				existing := &unstructured.Unstructured{}
+				existing.SetGroupVersionKind(gv.WithKind(owner.Kind))
+				if err := c.ctrlClient.Get(ctx, client.ObjectKey{Namespace: opts.Namespace, Name: owner.Name}, existing); err != nil {
+					continue
+				}
+				uid = string(existing.GetUID())
+			}
+			owner.UID = types.UID(uid)
+			owners = append(owners, owner)
+		}
+		if len(p.owners) == 0 && clusterUID != "" && ownedByCluster(p.obj, p.rank) {
+			owners = append(owners, metav1.OwnerReference{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Cluster",
+				Name:       restore.Cluster,
+				UID:        types.UID(clusterUID),
+			})
+		}
+		if len(owners) == 0 {
+			continue
+		}
+		patch := client.MergeFrom(p.obj.DeepCopy())
+		p.obj.SetOwnerReferences(owners)
+		if err := c.ctrlClient.Patch(ctx, p.obj, patch); err != nil {
+			return restore, fmt.Errorf("failed to set the owner references of %s: %w", p.obj.GetKind(), resourceError(p.obj.GetKind(), client.ObjectKeyFromObject(p.obj), err))
+		}
+		for _, owner := range owners {
+			restore.OwnerReferences = append(restore.OwnerReferences, OwnerLink{Kind: p.obj.GetKind(), Name: p.obj.GetName(), Owner: owner.Kind + "/" + owner.Name})
+		}
+	}
+
+	// Resume the Cluster, unless it was not created by the restore
+	restore.Paused = bundlePaused || opts.KeepPaused
+	for _, p := range created {
+		if p.rank != 3 || restore.Paused {
+			continue
+		}
+		patch := client.MergeFrom(p.obj.DeepCopy())
+		if err := unstructured.SetNestedField(p.obj.Object, false, "spec", "paused"); err != nil {
+			return restore, err
+		}
+		if err := c.ctrlClient.Patch(ctx, p.obj, patch); err != nil {
+			restore.Paused = true
+			return restore, fmt.Errorf("failed to resume the cluster: %w", resourceError("Cluster", client.ObjectKeyFromObject(p.obj), err))
+		}
+	}
+	return restore, nil
+}
@@ -0,0 +1,204 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const restoreBundle = `# Cluster backup of org-acme/prod
+---
+apiVersion: cluster.x-k8s.io/v1beta1
+kind: MachineDeployment
+metadata:
+  name: prod-workers
+  namespace: org-old
+  ownerReferences:
+  - apiVersion: cluster.x-k8s.io/v1beta1
+    kind: Cluster
+    name: prod
+    uid: 0ld-uid
+spec:
+  clusterName: prod
+  template:
+    spec:
+      clusterName: prod
+status:
+  replicas: 3
+---
+apiVersion: controlplane.cluster.x-k8s.io/v1beta1
+kind: KubeadmControlPlane
+metadata:
+  name: prod
+  namespace: org-old
+  resourceVersion: "7"
+---
+apiVersion: cluster.x-k8s.io/v1beta1
+kind: Cluster
+metadata:
+  name: prod
+  namespace: org-old
+  uid: 0ld-uid
+spec:
+  infrastructureRef:
+    apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+    kind: DockerCluster
+    name: prod
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+kind: DockerMachineTemplate
+metadata:
+  name: prod-workers
+  namespace: org-old
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1beta1
+kind: DockerCluster
+metadata:
+  name: prod
+  namespace: org-old
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: prod-ca
+  namespace: org-old
+  labels:
+    cluster.x-k8s.io/cluster-name: prod
+---
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- cluster.yaml
+`
+
+func TestDecodeBundle(t *testing.T) {
+	objects, err := DecodeBundle([]byte(`{"apiVersion":"v1","kind":"List","items":[{"apiVersion":"v1","kind":"Secret","metadata":{"name":"a"}},{"apiVersion":"cluster.x-k8s.io/v1beta1","kind":"Cluster","metadata":{"name":"b"}}]}`))
+	if err != nil || len(objects) != 2 || objects[1].GetKind() != "Cluster" {
+		t.Errorf("DecodeBundle(List) = %v, %v, want the two items", objects, err)
+	}
+
+	export := &GitOpsExport{Files: []ExportedFile{
+		{Path: "org-acme/prod/cluster.yaml", Content: restoreBundle},
+		{Path: "org-acme/prod/README.md", Content: "not a manifest"},
+	}}
+	archive, err := export.Archive()
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects, err = DecodeExportArchive(archive)
+	if err != nil || len(objects) != 7 {
+		t.Errorf("DecodeExportArchive() = %d objects, %v, want 7", len(objects), err)
+	}
+
+	if _, err := DecodeBundle([]byte("kind: [")); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("DecodeBundle(invalid) error = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestRestoreCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	// The API server sets the UIDs the owner references are linked with
+	created := 0
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newUnstructured(dockerMachineTemplateGVK, "org-acme", "prod-workers", nil),
+	).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			created++
+			obj.SetUID(types.UID(fmt.Sprintf("uid-%d", created)))
+			return c.Create(ctx, obj, opts...)
+		},
+	}).Build()}
+	ctx := context.Background()
+	objects, err := DecodeBundle([]byte(restoreBundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restore, err := c.RestoreCluster(ctx, RestoreClusterOptions{Namespace: "org-acme", Objects: objects, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restore.Restored) != 5 || len(restore.Skipped) != 2 {
+		t.Errorf("dry run = %+v, want 5 objects to restore and 2 skipped", restore)
+	}
+	cluster := &clusterv1.Cluster{}
+	if err := c.ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "prod"}, cluster); err == nil {
+		t.Error("dry run created the cluster")
+	}
+
+	restore, err = c.RestoreCluster(ctx, RestoreClusterOptions{Namespace: "org-acme", Objects: objects})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored, skipped []string
+	for _, obj := range restore.Restored {
+		restored = append(restored, obj.Kind+"/"+obj.Name)
+	}
+	for _, obj := range restore.Skipped {
+		skipped = append(skipped, obj.Kind+"/"+obj.Name+": "+obj.Reason)
+	}
+	if got, want := strings.Join(restored, ","), "Secret/prod-ca,DockerCluster/prod,Cluster/prod,KubeadmControlPlane/prod,MachineDeployment/prod-workers"; got != want {
+		t.Errorf("restored = %s, want %s", got, want)
+	}
+	if got, want := strings.Join(skipped, ","), "Kustomization/: not part of a Cluster API cluster,DockerMachineTemplate/prod-workers: already exists"; got != want {
+		t.Errorf("skipped = %s, want %s", got, want)
+	}
+	if len(restore.Failed) != 0 || restore.Paused {
+		t.Errorf("restore = %+v, want no failures and the cluster resumed", restore)
+	}
+
+	if err := c.ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "prod"}, cluster); err != nil {
+		t.Fatal(err)
+	}
+	if cluster.Spec.Paused || cluster.UID != "uid-3" {
+		t.Errorf("cluster = %+v, want a resumed new cluster", cluster.ObjectMeta)
+	}
+	md := &clusterv1.MachineDeployment{}
+	if err := c.ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "prod-workers"}, md); err != nil {
+		t.Fatal(err)
+	}
+	if refs := md.OwnerReferences; len(refs) != 1 || refs[0].UID != cluster.UID {
+		t.Errorf("machine deployment owners = %+v, want the new cluster", refs)
+	}
+	if md.Status.Replicas != 0 {
+		t.Errorf("machine deployment status = %+v, want it dropped", md.Status)
+	}
+	kcp := &unstructured.Unstructured{}
+	kcp.SetGroupVersionKind(schema.GroupVersionKind{Group: "controlplane.cluster.x-k8s.io", Version: "v1beta1", Kind: "KubeadmControlPlane"})
+	if err := c.ctrlClient.Get(ctx, client.ObjectKey{Namespace: "org-acme", Name: "prod"}, kcp); err != nil {
+		t.Fatal(err)
+	}
+	if refs := kcp.GetOwnerReferences(); len(refs) != 1 || refs[0].Kind != "Cluster" || refs[0].UID != cluster.UID {
+		t.Errorf("control plane owners = %+v, want the cluster", refs)
+	}
+	if len(restore.OwnerReferences) != 4 {
+		t.Errorf("owner references = %+v, want the secret, infrastructure cluster, control plane and machine deployment linked", restore.OwnerReferences)
+	}
+
+	restore, err = c.RestoreCluster(ctx, RestoreClusterOptions{Namespace: "org-acme", Objects: objects})
+	if err != nil || len(restore.Restored) != 0 || len(restore.Skipped) != 7 {
+		t.Errorf("second restore = %+v, %v, want everything skipped", restore, err)
+	}
+
+	if _, err := c.RestoreCluster(ctx, RestoreClusterOptions{Namespace: "org-acme", Objects: objects[:1]}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("RestoreCluster() without a cluster error = %v, want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,201 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RetryPolicy retries requests failing with transient errors, with
+// exponential backoff and jitter: throttling (429), an unavailable or timed
+// out API server, timed out or unreachable admission webhooks, and refused
+// or reset connections. Writes are only retried when the error shows they
+// were not applied.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt; zero
+	// disables retries
+	MaxRetries int
+	// InitialBackoff is the wait before the first retry, doubled for each
+	// further retry up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Jitter randomizes each wait by up to this fraction of it
+	Jitter float64
+}
+
+// DefaultRetryPolicy is the retry policy of clients without WithRetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     3,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Jitter:         0.2,
+}
+
+// validate rejects negative settings
+func (p RetryPolicy) validate() error {
+	if p.MaxRetries < 0 || p.InitialBackoff < 0 || p.MaxBackoff < 0 || p.Jitter < 0 || p.Jitter > 1 {
+		return errorf(ErrInvalidArgument, "invalid retry policy %+v: settings must not be negative and jitter at most 1", p)
+	}
+	return nil
+}
+
+// backoff returns the wait before retry number attempt, counting from 1
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		backoff += time.Duration((rand.Float64()*2 - 1) * p.Jitter * float64(backoff))
+	}
+	return backoff
+}
+
+// RetryStats counts the retries of a client since it was created
+type RetryStats struct {
+	// Retries is the number of requests sent again after a transient error
+	Retries int64 `json:"retries"`
+	// Exhausted is the number of operations that failed after all retries
+	Exhausted int64 `json:"exhausted"`
+}
+
+// retryCounters are updated by all requests of a client
+type retryCounters struct {
+	retries   atomic.Int64
+	exhausted atomic.Int64
+}
+
+// RetryStats returns the retry counters of the client
+func (c *Client) RetryStats() RetryStats {
+	if c.retries == nil {
+		return RetryStats{}
+	}
+	return RetryStats{Retries: c.retries.retries.Load(), Exhausted: c.retries.exhausted.Load()}
+}
+
+// transientError tells whether a request failing with err may succeed when
+// sent again. Only errors showing the request was not applied count for
+// writes, since a write whose connection broke may have been applied.
+func transientError(err error, write bool) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	switch {
+	case apierrors.IsTooManyRequests(err), apierrors.IsServiceUnavailable(err):
+		return true
+	case webhookUnavailable(err):
+		return true
+	case utilnet.IsConnectionRefused(err):
+		return true
+	case write:
+		return false
+	case apierrors.IsServerTimeout(err), apierrors.IsTimeout(err), apierrors.IsUnexpectedServerError(err):
+		return true
+	case utilnet.IsConnectionReset(err), utilnet.IsProbableEOF(err):
+		return true
+	}
+	return false
+}
+
+// webhookUnavailable tells whether an admission webhook could not be called,
+// which rejects the request before it is applied
+func webhookUnavailable(err error) bool {
+	if !apierrors.IsInternalError(err) {
+		return false
+	}
+	message := err.Error()
+	if !strings.Contains(message, "failed calling webhook") {
+		return false
+	}
+	for _, cause := range []string{"context deadline exceeded", "timeout", "connection refused", "no endpoints available", "connection reset"} {
+		if strings.Contains(message, cause) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryClient retries the requests of a controller-runtime client following
+// a retry policy
+type retryClient struct {
+	client.Client
+
+	policy   RetryPolicy
+	counters *retryCounters
+}
+
+// newRetryClient wraps c to retry its requests following policy
+func newRetryClient(c client.Client, policy RetryPolicy, counters *retryCounters) *retryClient {
+	return &retryClient{Client: c, policy: policy, counters: counters}
+}
+
+// do runs request until it succeeds, fails with a permanent error, the
+// retries are used up or ctx ends
+func (c *retryClient) do(ctx context.Context, verb string, write bool, obj runtime.Object, request func() error) error {
+	err := request()
+	for attempt := 1; transientError(err, write); attempt++ {
+		if attempt > c.policy.MaxRetries {
+			if c.policy.MaxRetries > 0 {
+				c.counters.exhausted.Add(1)
+				slog.WarnContext(ctx, "Kubernetes request failed after retries", "verb", verb, "kind", objectKind(obj), "retries", c.policy.MaxRetries, "error", err)
+			}
+			return err
+		}
+		backoff := c.policy.backoff(attempt)
+		if delay, ok := apierrors.SuggestsClientDelay(err); ok && time.Duration(delay)*time.Second > backoff {
+			backoff = time.Duration(delay) * time.Second
+		}
+		c.counters.retries.Add(1)
+		slog.InfoContext(ctx, "Retrying Kubernetes request after transient error", "verb", verb, "kind", objectKind(obj), "attempt", attempt, "backoff", backoff, "error", err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		err = request()
+	}
+	return err
+}
+
+func (c *retryClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return c.do(ctx, "get", false, obj, func() error { return c.Client.Get(ctx, key, obj, opts...) })
+}
+
+func (c *retryClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.do(ctx, "list", false, list, func() error { return c.Client.List(ctx, list, opts...) })
+}
+
+func (c *retryClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return c.do(ctx, "create", true, obj, func() error { return c.Client.Create(ctx, obj, opts...) })
+}
+
+func (c *retryClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return c.do(ctx, "update", true, obj, func() error { return c.Client.Update(ctx, obj, opts...) })
+}
+
+func (c *retryClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return c.do(ctx, "patch", true, obj, func() error { return c.Client.Patch(ctx, obj, patch, opts...) })
+}
+
+func (c *retryClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return c.do(ctx, "delete", true, obj, func() error { return c.Client.Delete(ctx, obj, opts...) })
+}
+
+func (c *retryClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return c.do(ctx, "deletecollection", true, obj, func() error { return c.Client.DeleteAllOf(ctx, obj, opts...) })
+}
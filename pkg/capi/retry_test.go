@@ -0,0 +1,115 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestTransientError(t *testing.T) {
+	clusters := schema.GroupResource{Group: clusterv1.GroupVersion.Group, Resource: "clusters"}
+	webhookTimeout := apierrors.NewInternalError(errors.New(`Internal error occurred: failed calling webhook "validation.cluster.cluster.x-k8s.io": context deadline exceeded`))
+	tests := []struct {
+		name  string
+		err   error
+		read  bool
+		write bool
+	}{
+		{name: "throttled", err: apierrors.NewTooManyRequests("slow down", 1), read: true, write: true},
+		{name: "unavailable", err: apierrors.NewServiceUnavailable("starting"), read: true, write: true},
+		{name: "webhook timeout", err: webhookTimeout, read: true, write: true},
+		{name: "connection refused", err: syscall.ECONNREFUSED, read: true, write: true},
+		{name: "connection reset", err: syscall.ECONNRESET, read: true},
+		{name: "server timeout", err: apierrors.NewServerTimeout(clusters, "get", 1), read: true},
+		{name: "not found", err: apierrors.NewNotFound(clusters, "prod")},
+		{name: "conflict", err: apierrors.NewConflict(clusters, "prod", errors.New("modified"))},
+		{name: "internal error", err: apierrors.NewInternalError(errors.New("panic"))},
+		{name: "deadline", err: context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transientError(tt.err, false); got != tt.read {
+				t.Errorf("transientError(read) = %v, want %v", got, tt.read)
+			}
+			if got := transientError(tt.err, true); got != tt.write {
+				t.Errorf("transientError(write) = %v, want %v", got, tt.write)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second}
+	for i, w := range want {
+		if got := policy.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.backoff(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("backoff(1) with jitter = %s, want within 50%% of 100ms", got)
+		}
+	}
+}
+
+func TestRetryClient(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	clusters := schema.GroupResource{Group: clusterv1.GroupVersion.Group, Resource: "clusters"}
+	var gets, patches int
+	flaky := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, _ client.WithWatch, _ client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+			if gets++; gets < 3 {
+				return apierrors.NewTooManyRequests("slow down", 0)
+			}
+			return nil
+		},
+		Patch: func(ctx context.Context, _ client.WithWatch, _ client.Object, _ client.Patch, _ ...client.PatchOption) error {
+			patches++
+			return apierrors.NewServerTimeout(clusters, "patch", 0)
+		},
+	}).Build()
+	counters := &retryCounters{}
+	c := newRetryClient(flaky, RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond}, counters)
+
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "prod"}, &clusterv1.Cluster{}); err != nil {
+		t.Fatalf("Get() error = %v, want success after retries", err)
+	}
+	if gets != 3 || counters.retries.Load() != 2 {
+		t.Errorf("gets = %d, retries = %d, want 3 attempts and 2 retries", gets, counters.retries.Load())
+	}
+
+	// A write that may have been applied is not sent again
+	cluster := &clusterv1.Cluster{}
+	cluster.Namespace, cluster.Name = "default", "prod"
+	if err := c.Patch(context.Background(), cluster, client.MergeFrom(cluster.DeepCopy())); !apierrors.IsServerTimeout(err) {
+		t.Fatalf("Patch() error = %v, want the server timeout", err)
+	}
+	if patches != 1 {
+		t.Errorf("patches = %d, want a single attempt", patches)
+	}
+
+	// Retries end once they are used up
+	gets = -10
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "prod"}, &clusterv1.Cluster{}); !apierrors.IsTooManyRequests(err) {
+		t.Fatalf("Get() error = %v, want the last throttling error", err)
+	}
+	if counters.exhausted.Load() != 1 {
+		t.Errorf("exhausted = %d, want 1", counters.exhausted.Load())
+	}
+}
@@ -0,0 +1,242 @@
+package capi
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+)
+
+// Kinds of resources covered by Search
+const (
+	KindCluster             = "Cluster"
+	KindMachine             = "Machine"
+	KindMachineDeployment   = "MachineDeployment"
+	KindKubeadmControlPlane = "KubeadmControlPlane"
+)
+
+// SearchQuery selects the resources returned by Search. All set criteria
+// must match; at least one of them is required.
+type SearchQuery struct {
+	// Namespace limits the search to one namespace; empty searches all
+	Namespace string
+	// Name matches resources whose name contains it, ignoring case
+	Name string
+	// LabelSelector matches the labels of the resources
+	LabelSelector string
+	// Provider matches resources of clusters on an infrastructure provider
+	Provider Provider
+	// Version matches a Kubernetes version exactly, or all patch versions
+	// of a minor version such as v1.29
+	Version string
+	// Phase matches the phase of the resources, ignoring case. Control
+	// planes have no phase and never match it.
+	Phase string
+	// Kinds limits the search to some kinds; empty searches all kinds
+	Kinds []string
+}
+
+// SearchMatch describes a resource found by Search
+type SearchMatch struct {
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Cluster   string   `json:"cluster"`
+	Provider  Provider `json:"provider"`
+	Version   string   `json:"version,omitempty"`
+	Phase     string   `json:"phase,omitempty"`
+}
+
+// SearchResults holds the matches of a search grouped by kind
+type SearchResults struct {
+	Clusters           []SearchMatch `json:"clusters"`
+	ControlPlanes      []SearchMatch `json:"controlPlanes"`
+	MachineDeployments []SearchMatch `json:"machineDeployments"`
+	Machines           []SearchMatch `json:"machines"`
+}
+
+// Total returns the number of matches of all kinds
+func (r *SearchResults) Total() int {
+	return len(r.Clusters) + len(r.ControlPlanes) + len(r.MachineDeployments) + len(r.Machines)
+}
+
+// Search finds clusters, control planes, machine deployments and machines
+// matching a query. Each kind is listed once; clusters and control planes are
+// always listed to resolve the provider and version of the other kinds.
+func (c *Client) Search(ctx context.Context, query SearchQuery) (*SearchResults, error) {
+	if query.Name == "" && query.LabelSelector == "" && query.Provider == "" && query.Version == "" && query.Phase == "" {
+		return nil, errorf(ErrInvalidArgument, "at least one search criterion is required")
+	}
+	selector, err := labels.Parse(query.LabelSelector)
+	if err != nil {
+		return nil, errorf(ErrInvalidArgument, "invalid label selector %q: %v", query.LabelSelector, err)
+	}
+	kinds := make(map[string]bool)
+	for _, kind := range query.Kinds {
+		switch kind {
+		case KindCluster, KindMachine, KindMachineDeployment, KindKubeadmControlPlane:
+			kinds[kind] = true
+		default:
+			return nil, errorf(ErrInvalidArgument, "unsupported kind %q", kind)
+		}
+	}
+	searched := func(kind string) bool {
+		return len(kinds) == 0 || kinds[kind]
+	}
+
+	clusters, err := c.ListClusters(ctx, query.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	kcps, err := c.ListKubeadmControlPlanes(ctx, query.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	kcpsByKey := make(map[string]*controlplanev1.KubeadmControlPlane, len(kcps.Items))
+	for i := range kcps.Items {
+		kcpsByKey[kcps.Items[i].Namespace+"/"+kcps.Items[i].Name] = &kcps.Items[i]
+	}
+	providers := make(map[string]Provider, len(clusters.Items))
+	controlPlaneClusters := make(map[string]string)
+	results := &SearchResults{
+		Clusters:           []SearchMatch{},
+		ControlPlanes:      []SearchMatch{},
+		MachineDeployments: []SearchMatch{},
+		Machines:           []SearchMatch{},
+	}
+
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		provider := ClusterProvider(cluster)
+		providers[cluster.Namespace+"/"+cluster.Name] = provider
+		if ref := cluster.Spec.ControlPlaneRef; ref != nil && ref.Kind == "KubeadmControlPlane" {
+			controlPlaneClusters[cluster.Namespace+"/"+ref.Name] = cluster.Name
+		}
+
+		version := ""
+		if cluster.Spec.Topology != nil {
+			version = cluster.Spec.Topology.Version
+		}
+		if needsControlPlaneVersion(cluster) {
+			if kcp, ok := kcpsByKey[cluster.Namespace+"/"+cluster.Spec.ControlPlaneRef.Name]; ok {
+				version = kcp.Spec.Version
+			}
+		}
+		match := SearchMatch{
+			Kind:      KindCluster,
+			Namespace: cluster.Namespace,
+			Name:      cluster.Name,
+			Cluster:   cluster.Name,
+			Provider:  provider,
+			Version:   version,
+			Phase:     cluster.Status.Phase,
+		}
+		if searched(KindCluster) && selector.Matches(labels.Set(cluster.Labels)) && query.matches(match) {
+			results.Clusters = append(results.Clusters, match)
+		}
+	}
+	providerOfCluster := func(namespace, name string) Provider {
+		if provider, ok := providers[namespace+"/"+name]; ok {
+			return provider
+		}
+		return ProviderUnknown
+	}
+
+	if searched(KindKubeadmControlPlane) {
+		for _, kcp := range kcps.Items {
+			clusterName := kcp.Labels[clusterv1.ClusterNameLabel]
+			if clusterName == "" {
+				clusterName = controlPlaneClusters[kcp.Namespace+"/"+kcp.Name]
+			}
+			match := SearchMatch{
+				Kind:      KindKubeadmControlPlane,
+				Namespace: kcp.Namespace,
+				Name:      kcp.Name,
+				Cluster:   clusterName,
+				Provider:  providerOfCluster(kcp.Namespace, clusterName),
+				Version:   kcp.Spec.Version,
+			}
+			if selector.Matches(labels.Set(kcp.Labels)) && query.matches(match) {
+				results.ControlPlanes = append(results.ControlPlanes, match)
+			}
+		}
+	}
+
+	// Machines and machine deployments are the bulk of a fleet, so their
+	// labels are matched by the API server
+	if searched(KindMachineDeployment) {
+		mds, err := c.ListMachineDeployments(ctx, query.Namespace, "", WithLabelSelector(query.LabelSelector))
+		if err != nil {
+			return nil, err
+		}
+		for _, md := range mds.Items {
+			match := SearchMatch{
+				Kind:      KindMachineDeployment,
+				Namespace: md.Namespace,
+				Name:      md.Name,
+				Cluster:   md.Spec.ClusterName,
+				Provider:  providerOfCluster(md.Namespace, md.Spec.ClusterName),
+				Phase:     md.Status.Phase,
+			}
+			if md.Spec.Template.Spec.Version != nil {
+				match.Version = *md.Spec.Template.Spec.Version
+			}
+			if query.matches(match) {
+				results.MachineDeployments = append(results.MachineDeployments, match)
+			}
+		}
+	}
+
+	if searched(KindMachine) {
+		machines, err := c.ListMachines(ctx, query.Namespace, "", WithLabelSelector(query.LabelSelector))
+		if err != nil {
+			return nil, err
+		}
+		for _, machine := range machines.Items {
+			match := SearchMatch{
+				Kind:      KindMachine,
+				Namespace: machine.Namespace,
+				Name:      machine.Name,
+				Cluster:   machine.Spec.ClusterName,
+				Provider:  providerOfCluster(machine.Namespace, machine.Spec.ClusterName),
+				Phase:     machine.Status.Phase,
+			}
+			if machine.Spec.Version != nil {
+				match.Version = *machine.Spec.Version
+			}
+			if query.matches(match) {
+				results.Machines = append(results.Machines, match)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// matches checks the criteria of a query other than the label selector
+func (q SearchQuery) matches(match SearchMatch) bool {
+	if q.Name != "" && !strings.Contains(strings.ToLower(match.Name), strings.ToLower(q.Name)) {
+		return false
+	}
+	if q.Provider != "" && match.Provider != q.Provider {
+		return false
+	}
+	if q.Version != "" && !versionMatches(match.Version, q.Version) {
+		return false
+	}
+	if q.Phase != "" && !strings.EqualFold(match.Phase, q.Phase) {
+		return false
+	}
+	return true
+}
+
+// versionMatches reports whether version equals query or is a patch version
+// of it, ignoring the "v" prefix
+func versionMatches(version, query string) bool {
+	version = strings.TrimPrefix(version, "v")
+	query = strings.TrimPrefix(query, "v")
+	return version != "" && (version == query || strings.HasPrefix(version, query+"."))
+}
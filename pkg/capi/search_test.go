@@ -0,0 +1,109 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSearch(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	newCluster := func(namespace, name, infraKind, team string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{"team": team}},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{Kind: infraKind, Name: name},
+				ControlPlaneRef:   &corev1.ObjectReference{Kind: "KubeadmControlPlane", Name: name + "-cp"},
+			},
+			Status: clusterv1.ClusterStatus{Phase: "Provisioned"},
+		}
+	}
+	newKCP := func(namespace, name, version string) *controlplanev1.KubeadmControlPlane {
+		return &controlplanev1.KubeadmControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       controlplanev1.KubeadmControlPlaneSpec{Version: version},
+		}
+	}
+	newMachine := func(namespace, name, cluster, version, phase string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster},
+			},
+			Spec:   clusterv1.MachineSpec{ClusterName: cluster, Version: &version},
+			Status: clusterv1.MachineStatus{Phase: phase},
+		}
+	}
+
+	objects := []client.Object{
+		newCluster("org-a", "payments-prod", "AWSCluster", "payments"),
+		newCluster("org-a", "search-prod", "AzureCluster", "search"),
+		newCluster("org-b", "payments-dev", "AWSCluster", "payments"),
+		newKCP("org-a", "payments-prod-cp", "v1.29.4"),
+		newKCP("org-a", "search-prod-cp", "v1.30.1"),
+		newKCP("org-b", "payments-dev-cp", "v1.30.0"),
+		newMachine("org-a", "payments-prod-abc", "payments-prod", "v1.29.4", "Running"),
+		newMachine("org-a", "search-prod-def", "search-prod", "v1.30.1", "Failed"),
+		newMachine("org-b", "payments-dev-ghi", "payments-dev", "v1.30.0", "Running"),
+	}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()}
+	ctx := context.Background()
+
+	results, err := c.Search(ctx, SearchQuery{Name: "PAYMENTS"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results.Clusters) != 2 || len(results.ControlPlanes) != 2 || len(results.Machines) != 2 {
+		t.Errorf("Search(name) = %+v, want 2 clusters, control planes and machines", results)
+	}
+	if results.ControlPlanes[0].Cluster != "payments-prod" || results.ControlPlanes[0].Provider != ProviderAWS {
+		t.Errorf("control plane match = %+v, want it resolved to its cluster", results.ControlPlanes[0])
+	}
+
+	results, err = c.Search(ctx, SearchQuery{Version: "v1.30", Provider: ProviderAWS})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if results.Total() != 3 || len(results.Clusters) != 1 || results.Clusters[0].Name != "payments-dev" {
+		t.Errorf("Search(version, provider) = %+v, want payments-dev with its control plane and machine", results)
+	}
+
+	results, err = c.Search(ctx, SearchQuery{Phase: "failed", Kinds: []string{KindMachine}})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if results.Total() != 1 || results.Machines[0].Name != "search-prod-def" {
+		t.Errorf("Search(phase) = %+v, want only the failed machine", results)
+	}
+
+	results, err = c.Search(ctx, SearchQuery{Namespace: "org-a", LabelSelector: "team=payments"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results.Clusters) != 1 || results.Clusters[0].Name != "payments-prod" || results.Clusters[0].Version != "v1.29.4" {
+		t.Errorf("Search(label) = %+v, want payments-prod at v1.29.4", results.Clusters)
+	}
+
+	if _, err := c.Search(ctx, SearchQuery{Namespace: "org-a"}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Search() without criteria error = %v, want ErrInvalidArgument", err)
+	}
+	if _, err := c.Search(ctx, SearchQuery{Name: "prod", Kinds: []string{"Node"}}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("Search() of an unsupported kind error = %v, want ErrInvalidArgument", err)
+	}
+}
@@ -0,0 +1,90 @@
+package capi
+
+import (
+	"errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/version"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+)
+
+// MaxKubeletSkew is how many minor versions a kubelet may be older than the
+// control plane, following the Kubernetes version skew policy
+const MaxKubeletSkew = 3
+
+// CheckVersionSkew verifies that a control plane can be upgraded from current
+// to target: kubeadm neither downgrades nor skips minor versions
+func CheckVersionSkew(current string, target *version.Version) error {
+	if current == "" {
+		return errors.New("current Kubernetes version is unknown")
+	}
+	from, err := version.ParseSemantic(current)
+	if err != nil {
+		return fmt.Errorf("current version %q is not a semantic version", current)
+	}
+	switch {
+	case from.GreaterThan(target):
+		return fmt.Errorf("cannot downgrade from %s to %s", current, target)
+	case target.Major() != from.Major() || target.Minor() > from.Minor()+1:
+		return fmt.Errorf("cannot skip minor versions from %s to %s", current, target)
+	}
+	return nil
+}
+
+// checkWorkerSkew verifies that workers running worker can join a control
+// plane running controlPlane: not newer, and at most MaxKubeletSkew minor
+// versions older
+func checkWorkerSkew(worker, controlPlane *version.Version) error {
+	switch {
+	case worker.GreaterThan(controlPlane):
+		return fmt.Errorf("workers cannot run %s, newer than the control plane at %s", worker, controlPlane)
+	case worker.Major() != controlPlane.Major() || worker.Minor()+MaxKubeletSkew < controlPlane.Minor():
+		return fmt.Errorf("workers at %s would be more than %d minor versions older than the control plane at %s", worker, MaxKubeletSkew, controlPlane)
+	}
+	return nil
+}
+
+// upgradeSkewViolations lists the version skew policies an upgrade of a
+// cluster to target would break. kcp is nil for clusters without a
+// KubeadmControlPlane, whose control plane version is not checked.
+func upgradeSkewViolations(kcp *controlplanev1.KubeadmControlPlane, mds []clusterv1.MachineDeployment, target *version.Version, upgradeWorkers bool) []string {
+	var violations []string
+	if kcp != nil {
+		if err := CheckVersionSkew(kcp.Spec.Version, target); err != nil {
+			violations = append(violations, fmt.Sprintf("control plane: %v", err))
+		}
+	}
+
+	for i := range mds {
+		md := &mds[i]
+		if md.Spec.Template.Spec.Version == nil {
+			continue
+		}
+		current, err := version.ParseSemantic(*md.Spec.Template.Spec.Version)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("MachineDeployment %s: version %q is not a semantic version", md.Name, *md.Spec.Template.Spec.Version))
+			continue
+		}
+		if upgradeWorkers {
+			if current.GreaterThan(target) {
+				violations = append(violations, fmt.Sprintf("MachineDeployment %s: cannot downgrade from %s to %s", md.Name, current, target))
+			}
+			continue
+		}
+		// Workers left behind must stay compatible with the new control plane
+		if err := checkWorkerSkew(current, target); err != nil {
+			violations = append(violations, fmt.Sprintf("MachineDeployment %s: %v", md.Name, err))
+		}
+	}
+	return violations
+}
+
+// skewError reports version skew violations, which force overrides
+func skewError(violations []string) error {
+	message := violations[0]
+	if len(violations) > 1 {
+		message = fmt.Sprintf("%s (and %d more)", message, len(violations)-1)
+	}
+	return errorf(ErrPreconditionFailed, "version skew policy violated: %s; pass force to override", message)
+}
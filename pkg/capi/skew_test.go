@@ -0,0 +1,105 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/version"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckVersionSkew(t *testing.T) {
+	target := version.MustParseSemantic("v1.30.1")
+	tests := []struct {
+		current string
+		wantErr string
+	}{
+		{"v1.29.4", ""},
+		{"v1.30.0", ""},
+		{"v1.30.1", ""},
+		{"v1.31.0", "downgrade"},
+		{"v1.28.9", "skip minor"},
+		{"", "unknown"},
+		{"latest", "not a semantic version"},
+	}
+	for _, tt := range tests {
+		err := CheckVersionSkew(tt.current, target)
+		if (err == nil) != (tt.wantErr == "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("CheckVersionSkew(%q) error = %v, want %q", tt.current, err, tt.wantErr)
+		}
+	}
+}
+
+func TestUpgradeSkewViolations(t *testing.T) {
+	newMD := func(name, v string) clusterv1.MachineDeployment {
+		md := clusterv1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		md.Spec.Template.Spec.Version = &v
+		return md
+	}
+	kcp := &controlplanev1.KubeadmControlPlane{Spec: controlplanev1.KubeadmControlPlaneSpec{Version: "v1.30.2"}}
+	mds := []clusterv1.MachineDeployment{newMD("current", "v1.30.2"), newMD("old", "v1.27.3")}
+
+	if violations := upgradeSkewViolations(kcp, mds, version.MustParseSemantic("v1.31.0"), true); len(violations) != 0 {
+		t.Errorf("upgrade with workers: violations = %v, want none", violations)
+	}
+	violations := upgradeSkewViolations(kcp, mds, version.MustParseSemantic("v1.31.0"), false)
+	if len(violations) != 1 || !strings.Contains(violations[0], "MachineDeployment old") {
+		t.Errorf("upgrade without workers: violations = %v, want the old workers left behind", violations)
+	}
+	violations = upgradeSkewViolations(kcp, mds, version.MustParseSemantic("v1.29.0"), true)
+	if len(violations) != 2 || !strings.Contains(violations[0], "control plane: cannot downgrade") {
+		t.Errorf("downgrade: violations = %v, want the control plane and workers", violations)
+	}
+}
+
+func TestUpdateMachineDeploymentVersionSkew(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	workerVersion := "v1.29.4"
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "org-a"},
+			Spec:       clusterv1.ClusterSpec{ControlPlaneRef: &corev1.ObjectReference{Kind: "KubeadmControlPlane", Name: "prod-cp"}},
+		},
+		&controlplanev1.KubeadmControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod-cp", Namespace: "org-a"},
+			Spec:       controlplanev1.KubeadmControlPlaneSpec{Version: "v1.30.2"},
+		},
+		&clusterv1.MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod-workers", Namespace: "org-a"},
+			Spec: clusterv1.MachineDeploymentSpec{
+				ClusterName: "prod",
+				Template:    clusterv1.MachineTemplateSpec{Spec: clusterv1.MachineSpec{ClusterName: "prod", Version: &workerVersion}},
+			},
+		},
+	).Build()}
+	ctx := context.Background()
+
+	for _, v := range []string{"v1.31.0", "v1.29.0"} {
+		_, err := c.UpdateMachineDeployment(ctx, UpdateMachineDeploymentOptions{Namespace: "org-a", Name: "prod-workers", Version: &v})
+		if !errors.Is(err, ErrPreconditionFailed) || !strings.Contains(err.Error(), "pass force") {
+			t.Errorf("UpdateMachineDeployment(%s) error = %v, want a version skew error", v, err)
+		}
+	}
+
+	v := "v1.30.2"
+	if _, err := c.UpdateMachineDeployment(ctx, UpdateMachineDeploymentOptions{Namespace: "org-a", Name: "prod-workers", Version: &v}); err != nil {
+		t.Errorf("UpdateMachineDeployment(%s) error = %v", v, err)
+	}
+	v = "v1.31.0"
+	if _, err := c.UpdateMachineDeployment(ctx, UpdateMachineDeploymentOptions{Namespace: "org-a", Name: "prod-workers", Version: &v, Force: true}); err != nil {
+		t.Errorf("UpdateMachineDeployment(%s, force) error = %v", v, err)
+	}
+}
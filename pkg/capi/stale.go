@@ -0,0 +1,219 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// Default thresholds of FindStaleResources
+const (
+	// DefaultStaleProvisioningAfter is how long a machine may take to get a node
+	DefaultStaleProvisioningAfter = 30 * time.Minute
+	// DefaultStaleDeletingAfter is how long a machine or cluster may take to
+	// be deleted
+	DefaultStaleDeletingAfter = 30 * time.Minute
+	// DefaultStaleClusterAfter is how long a cluster may take to be provisioned
+	DefaultStaleClusterAfter = DefaultStuckAfter
+	// DefaultStaleRolloutAfter is how long a MachineDeployment rollout may
+	// take to update all replicas
+	DefaultStaleRolloutAfter = time.Hour
+)
+
+// States of stale resources
+const (
+	StaleProvisioning   = "Provisioning"
+	StaleDeleting       = "Deleting"
+	StaleNotProvisioned = "NotProvisioned"
+	StaleRollout        = "RolloutStalled"
+)
+
+// StaleThresholds are the durations after which resources that have not
+// reached their desired state are reported as stale. Zero values use the
+// defaults.
+type StaleThresholds struct {
+	Provisioning time.Duration `json:"provisioning"`
+	Deleting     time.Duration `json:"deleting"`
+	Cluster      time.Duration `json:"cluster"`
+	Rollout      time.Duration `json:"rollout"`
+}
+
+// withDefaults fills in the default thresholds
+func (t StaleThresholds) withDefaults() StaleThresholds {
+	if t.Provisioning <= 0 {
+		t.Provisioning = DefaultStaleProvisioningAfter
+	}
+	if t.Deleting <= 0 {
+		t.Deleting = DefaultStaleDeletingAfter
+	}
+	if t.Cluster <= 0 {
+		t.Cluster = DefaultStaleClusterAfter
+	}
+	if t.Rollout <= 0 {
+		t.Rollout = DefaultStaleRolloutAfter
+	}
+	return t
+}
+
+// StaleResource is a Cluster, Machine or MachineDeployment that has been
+// stuck on its way to its desired state for longer than its threshold
+type StaleResource struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Cluster   string    `json:"cluster"`
+	State     string    `json:"state"`
+	Since     time.Time `json:"since"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// Key identifies the resource and its state, so scanners can tell new stale
+// resources from those they already reported
+func (r StaleResource) Key() string {
+	return fmt.Sprintf("%s/%s/%s/%s", r.Kind, r.Namespace, r.Name, r.State)
+}
+
+// FindStaleResources lists the clusters of a namespace, or of all namespaces
+// if namespace is empty, optionally only clusterName, and reports machines
+// stuck provisioning or deleting, clusters that are still not provisioned or
+// being deleted, and MachineDeployments whose rollout stopped progressing.
+// Paused clusters are skipped with their machines and MachineDeployments.
+func (c *Client) FindStaleResources(ctx context.Context, namespace, clusterName string, thresholds StaleThresholds) ([]StaleResource, error) {
+	clusters, err := c.ListClusters(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	machines, err := c.ListMachines(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	mds, err := c.ListMachineDeployments(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	machineSets, err := c.ListMachineSets(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if clusterName != "" {
+		var matching []clusterv1.Cluster
+		for _, cluster := range clusters.Items {
+			if cluster.Name == clusterName {
+				matching = append(matching, cluster)
+			}
+		}
+		clusters.Items = matching
+	}
+	return DetectStaleResources(clusters.Items, machines.Items, mds.Items, machineSets.Items, time.Now(), thresholds), nil
+}
+
+// DetectStaleResources reports the stale resources as of now, sorted by how
+// long they have been stuck
+func DetectStaleResources(clusters []clusterv1.Cluster, machines []clusterv1.Machine, mds []clusterv1.MachineDeployment, machineSets []clusterv1.MachineSet, now time.Time, thresholds StaleThresholds) []StaleResource {
+	thresholds = thresholds.withDefaults()
+	stale := []StaleResource{}
+	paused := map[string]bool{}
+	for i := range clusters {
+		cluster := &clusters[i]
+		if cluster.Spec.Paused || hasAnnotation(cluster.Annotations, clusterv1.PausedAnnotation) {
+			paused[cluster.Namespace+"/"+cluster.Name] = true
+			continue
+		}
+		resource := StaleResource{Kind: "Cluster", Namespace: cluster.Namespace, Name: cluster.Name, Cluster: cluster.Name}
+		switch {
+		case cluster.DeletionTimestamp != nil:
+			resource.State, resource.Since = StaleDeleting, cluster.DeletionTimestamp.Time
+			if now.Sub(resource.Since) <= thresholds.Deleting {
+				continue
+			}
+		case cluster.Status.Phase != string(clusterv1.ClusterPhaseProvisioned):
+			resource.State, resource.Since = StaleNotProvisioned, cluster.CreationTimestamp.Time
+			if now.Sub(resource.Since) <= thresholds.Cluster {
+				continue
+			}
+		default:
+			continue
+		}
+		resource.Message = conditionsMessage(orUnknown(cluster.Status.Phase), cluster.Status.Conditions)
+		stale = append(stale, resource)
+	}
+
+	for i := range machines {
+		machine := &machines[i]
+		if paused[machine.Namespace+"/"+machine.Spec.ClusterName] {
+			continue
+		}
+		resource := StaleResource{Kind: "Machine", Namespace: machine.Namespace, Name: machine.Name, Cluster: machine.Spec.ClusterName}
+		switch {
+		case machine.DeletionTimestamp != nil:
+			resource.State, resource.Since = StaleDeleting, machine.DeletionTimestamp.Time
+			if now.Sub(resource.Since) <= thresholds.Deleting {
+				continue
+			}
+		case machine.Status.NodeRef == nil && machine.Status.Phase != string(clusterv1.MachinePhaseFailed):
+			// Pending, Provisioning and Provisioned machines all wait for their node
+			resource.State, resource.Since = StaleProvisioning, machine.CreationTimestamp.Time
+			if now.Sub(resource.Since) <= thresholds.Provisioning {
+				continue
+			}
+		default:
+			continue
+		}
+		resource.Message = conditionsMessage(orUnknown(machine.Status.Phase), machine.Status.Conditions)
+		stale = append(stale, resource)
+	}
+
+	for i := range mds {
+		md := &mds[i]
+		if paused[md.Namespace+"/"+md.Spec.ClusterName] || md.DeletionTimestamp != nil || md.Spec.Replicas == nil {
+			continue
+		}
+		replicas := *md.Spec.Replicas
+		if md.Status.ObservedGeneration >= md.Generation && md.Status.UpdatedReplicas >= replicas && md.Status.UnavailableReplicas == 0 {
+			continue
+		}
+		since := rolloutStart(md, machineSets)
+		if now.Sub(since) <= thresholds.Rollout {
+			continue
+		}
+		stale = append(stale, StaleResource{
+			Kind:      "MachineDeployment",
+			Namespace: md.Namespace,
+			Name:      md.Name,
+			Cluster:   md.Spec.ClusterName,
+			State:     StaleRollout,
+			Since:     since,
+			Message: fmt.Sprintf("%d/%d replicas updated, %d unavailable",
+				md.Status.UpdatedReplicas, replicas, md.Status.UnavailableReplicas),
+		})
+	}
+
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].Since.Before(stale[j].Since)
+	})
+	return stale
+}
+
+// rolloutStart estimates when the current rollout of a MachineDeployment
+// started: when its newest MachineSet was created, or the MachineDeployment
+// itself without MachineSets
+func rolloutStart(md *clusterv1.MachineDeployment, machineSets []clusterv1.MachineSet) time.Time {
+	start := md.CreationTimestamp
+	for i := range machineSets {
+		ms := &machineSets[i]
+		if ms.Namespace == md.Namespace && metav1.IsControlledBy(ms, md) && start.Before(&ms.CreationTimestamp) {
+			start = ms.CreationTimestamp
+		}
+	}
+	return start.Time
+}
+
+// hasAnnotation reports whether annotations contain key
+func hasAnnotation(annotations map[string]string, key string) bool {
+	_, ok := annotations[key]
+	return ok
+}
@@ -0,0 +1,91 @@
+package capi
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDetectStaleResources(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) metav1.Time { return metav1.NewTime(now.Add(-d)) }
+	deleting := func(d time.Duration) *metav1.Time { t := ago(d); return &t }
+	meta := func(name string, created time.Duration) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: "org-acme", Name: name, CreationTimestamp: ago(created)}
+	}
+
+	clusters := []clusterv1.Cluster{
+		{ObjectMeta: meta("prod", 48*time.Hour), Status: clusterv1.ClusterStatus{Phase: string(clusterv1.ClusterPhaseProvisioned)}},
+		{ObjectMeta: meta("new", 2*time.Hour), Status: clusterv1.ClusterStatus{
+			Phase:      string(clusterv1.ClusterPhaseProvisioning),
+			Conditions: clusterv1.Conditions{{Type: clusterv1.InfrastructureReadyCondition, Status: corev1.ConditionFalse, Reason: "WaitingForLoadBalancer"}},
+		}},
+		{ObjectMeta: meta("fresh", 10*time.Minute), Status: clusterv1.ClusterStatus{Phase: string(clusterv1.ClusterPhaseProvisioning)}},
+		{ObjectMeta: meta("hibernated", 48*time.Hour), Spec: clusterv1.ClusterSpec{Paused: true}},
+	}
+	md := clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-md", UID: "md-uid", Generation: 3, CreationTimestamp: ago(48 * time.Hour)},
+		Spec:       clusterv1.MachineDeploymentSpec{ClusterName: "prod", Replicas: ptr.To[int32](3)},
+		Status:     clusterv1.MachineDeploymentStatus{ObservedGeneration: 3, UpdatedReplicas: 1, UnavailableReplicas: 1},
+	}
+	machineSets := []clusterv1.MachineSet{{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "org-acme", Name: "prod-md-new", CreationTimestamp: ago(210 * time.Minute),
+		OwnerReferences: []metav1.OwnerReference{{Kind: "MachineDeployment", Name: "prod-md", UID: "md-uid", Controller: ptr.To(true)}},
+	}}}
+	machines := []clusterv1.Machine{
+		{ObjectMeta: meta("prod-md-a", 3*time.Hour), Spec: clusterv1.MachineSpec{ClusterName: "prod"}, Status: clusterv1.MachineStatus{Phase: "Provisioning"}},
+		{ObjectMeta: meta("prod-md-b", 20*time.Minute), Spec: clusterv1.MachineSpec{ClusterName: "prod"}, Status: clusterv1.MachineStatus{Phase: "Provisioning"}},
+		{ObjectMeta: meta("prod-md-c", 48*time.Hour), Spec: clusterv1.MachineSpec{ClusterName: "prod"}, Status: clusterv1.MachineStatus{Phase: "Running", NodeRef: &corev1.ObjectReference{Name: "node-c"}}},
+		{ObjectMeta: meta("hibernated-md-a", 48*time.Hour), Spec: clusterv1.MachineSpec{ClusterName: "hibernated"}},
+	}
+	machines[2].DeletionTimestamp = deleting(time.Hour)
+
+	stale := DetectStaleResources(clusters, machines, []clusterv1.MachineDeployment{md}, machineSets, now, StaleThresholds{})
+	var got []string
+	for _, resource := range stale {
+		got = append(got, resource.Kind+"/"+resource.Name+"="+resource.State)
+	}
+	want := "MachineDeployment/prod-md=RolloutStalled,Machine/prod-md-a=Provisioning,Cluster/new=NotProvisioned,Machine/prod-md-c=Deleting"
+	if strings.Join(got, ",") != want {
+		t.Errorf("DetectStaleResources() = %s, want %s", strings.Join(got, ","), want)
+	}
+	if msg := stale[2].Message; !strings.Contains(msg, "WaitingForLoadBalancer") {
+		t.Errorf("cluster message = %q, want the pending condition", msg)
+	}
+	if msg := stale[0].Message; msg != "1/3 replicas updated, 1 unavailable" {
+		t.Errorf("machine deployment message = %q", msg)
+	}
+
+	stale = DetectStaleResources(clusters, machines, []clusterv1.MachineDeployment{md}, machineSets, now, StaleThresholds{Provisioning: 4 * time.Hour, Rollout: 4 * time.Hour, Deleting: 2 * time.Hour, Cluster: 4 * time.Hour})
+	if len(stale) != 0 {
+		t.Errorf("DetectStaleResources() with longer thresholds = %+v, want nothing", stale)
+	}
+}
+
+func TestFindStaleResources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	created := metav1.NewTime(time.Now().Add(-time.Hour))
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod", CreationTimestamp: created}},
+		&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-other", Name: "dev", CreationTimestamp: created}},
+	).Build()}
+
+	stale, err := c.FindStaleResources(context.Background(), "", "prod", StaleThresholds{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 1 || stale[0].Name != "prod" || stale[0].Key() != "Cluster/org-acme/prod/NotProvisioned" {
+		t.Errorf("FindStaleResources() = %+v, want cluster prod not provisioned", stale)
+	}
+}
@@ -0,0 +1,235 @@
+package capi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OperationTimeouts bound the duration of client operations, so a hung API
+// server cannot block a caller indefinitely. A zero duration leaves the
+// operation bounded only by the caller's context and the request timeout.
+type OperationTimeouts struct {
+	// Read bounds each get and list
+	Read time.Duration
+	// Write bounds each create, update, patch and delete
+	Write time.Duration
+	// Drain bounds a whole node drain, from cordoning to the last eviction
+	Drain time.Duration
+}
+
+// validate rejects negative timeouts
+func (t OperationTimeouts) validate() error {
+	if t.Read < 0 || t.Write < 0 || t.Drain < 0 {
+		return errorf(ErrInvalidArgument, "operation timeouts must not be negative (read %s, write %s, drain %s)", t.Read, t.Write, t.Drain)
+	}
+	return nil
+}
+
+// withTimeout derives a child context ending after timeout, or returns ctx
+// unchanged when timeout is zero
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timeoutClient derives a context with a deadline for each request. The
+// caller's context still applies, so canceling it aborts the request.
+type timeoutClient struct {
+	client.Client
+
+	timeouts OperationTimeouts
+}
+
+// newTimeoutClient wraps c to bound its requests by timeouts
+func newTimeoutClient(c client.Client, timeouts OperationTimeouts) *timeoutClient {
+	return &timeoutClient{Client: c, timeouts: timeouts}
+}
+
+func (c *timeoutClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Read)
+	defer cancel()
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c *timeoutClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Read)
+	defer cancel()
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *timeoutClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Write)
+	defer cancel()
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *timeoutClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Write)
+	defer cancel()
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *timeoutClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Write)
+	defer cancel()
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *timeoutClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Write)
+	defer cancel()
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *timeoutClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	ctx, cancel := withTimeout(ctx, c.timeouts.Write)
+	defer cancel()
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// Status bounds the status writes of the client like its other writes
+func (c *timeoutClient) Status() client.SubResourceWriter {
+	return &timeoutSubResourceWriter{SubResourceWriter: c.Client.Status(), timeout: c.timeouts.Write}
+}
+
+// SubResource bounds the subresource requests of the client, such as
+// evictions and scale updates
+func (c *timeoutClient) SubResource(subResource string) client.SubResourceClient {
+	sub := c.Client.SubResource(subResource)
+	return &timeoutSubResourceClient{
+		SubResourceClient: sub,
+		writer:            timeoutSubResourceWriter{SubResourceWriter: sub, timeout: c.timeouts.Write},
+		timeout:           c.timeouts.Read,
+	}
+}
+
+// timeoutSubResourceWriter bounds each subresource write by timeout
+type timeoutSubResourceWriter struct {
+	client.SubResourceWriter
+
+	timeout time.Duration
+}
+
+func (w *timeoutSubResourceWriter) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	ctx, cancel := withTimeout(ctx, w.timeout)
+	defer cancel()
+	return w.SubResourceWriter.Create(ctx, obj, subResource, opts...)
+}
+
+func (w *timeoutSubResourceWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	ctx, cancel := withTimeout(ctx, w.timeout)
+	defer cancel()
+	return w.SubResourceWriter.Update(ctx, obj, opts...)
+}
+
+func (w *timeoutSubResourceWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	ctx, cancel := withTimeout(ctx, w.timeout)
+	defer cancel()
+	return w.SubResourceWriter.Patch(ctx, obj, patch, opts...)
+}
+
+// timeoutSubResourceClient bounds subresource reads by timeout and writes
+// through writer
+type timeoutSubResourceClient struct {
+	client.SubResourceClient
+
+	writer  timeoutSubResourceWriter
+	timeout time.Duration
+}
+
+func (c *timeoutSubResourceClient) Get(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceGetOption) error {
+	ctx, cancel := withTimeout(ctx, c.timeout)
+	defer cancel()
+	return c.SubResourceClient.Get(ctx, obj, subResource, opts...)
+}
+
+func (c *timeoutSubResourceClient) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	return c.writer.Create(ctx, obj, subResource, opts...)
+}
+
+func (c *timeoutSubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return c.writer.Update(ctx, obj, opts...)
+}
+
+func (c *timeoutSubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	return c.writer.Patch(ctx, obj, patch, opts...)
+}
+
+// withRequestTimeouts returns a copy of config whose requests are bounded by
+// the read and write timeouts, for the clientsets of nodes, secrets, leases
+// and access reviews that the timeoutClient does not cover. config is
+// returned unchanged without read and write timeouts.
+func withRequestTimeouts(config *rest.Config, timeouts OperationTimeouts) *rest.Config {
+	if timeouts.Read <= 0 && timeouts.Write <= 0 {
+		return config
+	}
+	config = rest.CopyConfig(config)
+	config.Wrap(func(next http.RoundTripper) http.RoundTripper {
+		return &timeoutRoundTripper{next: next, timeouts: timeouts}
+	})
+	return config
+}
+
+// timeoutRoundTripper derives a context with a deadline for each request,
+// the read timeout for GET and the write timeout for the other methods.
+// Watches and streams, such as exec and followed logs, run until the caller
+// ends them.
+type timeoutRoundTripper struct {
+	next     http.RoundTripper
+	timeouts OperationTimeouts
+}
+
+func (rt *timeoutRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	timeout := rt.timeouts.Write
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		timeout = rt.timeouts.Read
+	}
+	if timeout <= 0 || isStreamingRequest(req) {
+		return rt.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	resp, err := rt.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The deadline also bounds reading the body, so it ends with the body
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// isStreamingRequest reports whether a request keeps its connection open
+// for as long as the caller wants
+func isStreamingRequest(req *http.Request) bool {
+	query := req.URL.Query()
+	if query.Get("watch") == "true" || query.Get("follow") == "true" || req.Header.Get("Upgrade") != "" {
+		return true
+	}
+	switch path.Base(req.URL.Path) {
+	case "exec", "attach", "portforward", "proxy":
+		return true
+	}
+	return false
+}
+
+// cancelOnClose cancels the context of a response when its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
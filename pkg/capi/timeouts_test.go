@@ -0,0 +1,159 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestTimeoutClient(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	var writeDeadline time.Time
+	hung := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		// A hung API server only answers once the request is abandoned
+		Get: func(ctx context.Context, _ client.WithWatch, _ client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		Patch: func(ctx context.Context, _ client.WithWatch, _ client.Object, _ client.Patch, _ ...client.PatchOption) error {
+			writeDeadline, _ = ctx.Deadline()
+			return nil
+		},
+		SubResourceUpdate: func(ctx context.Context, _ client.Client, _ string, _ client.Object, _ ...client.SubResourceUpdateOption) error {
+			writeDeadline, _ = ctx.Deadline()
+			return nil
+		},
+	}).Build()
+	c := newTimeoutClient(hung, OperationTimeouts{Read: 10 * time.Millisecond, Write: time.Minute})
+
+	start := time.Now()
+	err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "prod"}, &clusterv1.Cluster{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Get() error = %v, want a deadline exceeded error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Get() returned after %s, the read timeout was not applied", elapsed)
+	}
+
+	// Canceling the caller's context aborts the request before its timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.timeouts.Read = time.Hour
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "prod"}, &clusterv1.Cluster{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Get() error = %v, want context canceled", err)
+	}
+
+	cluster := &clusterv1.Cluster{}
+	cluster.Namespace, cluster.Name = "default", "prod"
+	if err := c.Patch(context.Background(), cluster, client.MergeFrom(cluster.DeepCopy())); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := time.Until(writeDeadline); remaining <= 0 || remaining > time.Minute {
+		t.Errorf("Patch() deadline in %s, want within the write timeout", remaining)
+	}
+
+	writeDeadline = time.Time{}
+	if err := c.Status().Update(context.Background(), cluster); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := time.Until(writeDeadline); remaining <= 0 || remaining > time.Minute {
+		t.Errorf("Status().Update() deadline in %s, want within the write timeout", remaining)
+	}
+
+	// Without timeouts requests are only bounded by the caller
+	writeDeadline = time.Time{}
+	unbounded := newTimeoutClient(hung, OperationTimeouts{})
+	if err := unbounded.Patch(context.Background(), cluster, client.MergeFrom(cluster.DeepCopy())); err != nil {
+		t.Fatal(err)
+	}
+	if !writeDeadline.IsZero() {
+		t.Errorf("Patch() got a deadline without a write timeout")
+	}
+}
+
+func TestWithOperationTimeouts(t *testing.T) {
+	config := &rest.Config{Host: "https://127.0.0.1:6443"}
+	timeouts := OperationTimeouts{Read: time.Second, Write: 2 * time.Second, Drain: time.Minute}
+	c, err := NewClientWithOptions(WithRESTConfig(config), WithOperationTimeouts(timeouts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.timeouts != timeouts {
+		t.Errorf("timeouts = %+v, want %+v", c.timeouts, timeouts)
+	}
+	// The contract conversion stays outermost, so APIContract keeps working
+	cc, ok := c.ctrlClient.(*contractClient)
+	if !ok {
+		t.Fatalf("ctrlClient is a %T, want the contract client", c.ctrlClient)
+	}
+	if _, ok := cc.Client.(*timeoutClient); !ok {
+		t.Errorf("contract client wraps a %T, want the timeout client", cc.Client)
+	}
+
+	_, err = NewClientWithOptions(WithRESTConfig(config), WithOperationTimeouts(OperationTimeouts{Read: -time.Second}))
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("negative timeout error = %v, want ErrInvalidArgument", err)
+	}
+}
+
+func TestRequestTimeouts(t *testing.T) {
+	// A hung API server answers watches and nothing else, until the test ends
+	done := make(chan struct{})
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("watch") == "true" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer apiServer.Close()
+	defer close(done)
+
+	c, err := NewClientWithOptions(WithRESTConfig(&rest.Config{Host: apiServer.URL}), WithOperationTimeouts(OperationTimeouts{Read: 20 * time.Millisecond, Write: 20 * time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	start := time.Now()
+	if _, err := c.k8sClient.CoreV1().Nodes().Get(ctx, "worker-1", metav1.GetOptions{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() error = %v, want a deadline exceeded error", err)
+	}
+	if _, err := c.k8sClient.CoreV1().Nodes().Update(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}}, metav1.UpdateOptions{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Update() error = %v, want a deadline exceeded error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("clientset calls returned after %s, the timeouts were not applied", elapsed)
+	}
+
+	// Watches outlive the read timeout
+	watcher, err := c.k8sClient.CoreV1().Nodes().Watch(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	defer watcher.Stop()
+	select {
+	case event, ok := <-watcher.ResultChan():
+		t.Errorf("watch ended within the read timeout: %+v, open %v", event, ok)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,78 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updateObject reads the object identified by key into obj, applies mutate and
+// sends the difference as a merge patch. The patch carries the resourceVersion
+// that was read, so a concurrent write by a controller surfaces as a conflict;
+// conflicts are retried with a fresh read so mutate always sees current state.
+//
+// If operation is not empty the state before the successful attempt is
+// recorded in the change history under that operation. In a context of
+// WithPreview the patch is a dry-run and its diff is added to the preview
+// instead. Objects owned by Flux or Argo CD are only changed in a context of
+// WithGitOpsOverride.
+func (c *Client) updateObject(ctx context.Context, key client.ObjectKey, obj client.Object, operation string, mutate func() error) error {
+	preview := previewFromContext(ctx)
+	var patchOpts []client.PatchOption
+	if preview != nil {
+		patchOpts = append(patchOpts, client.DryRunAll)
+	}
+	var before client.Object
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := c.ctrlClient.Get(ctx, key, obj); err != nil {
+			return err
+		}
+		before = obj.DeepCopyObject().(client.Object)
+		if err := checkGitOpsOwner(ctx, obj); err != nil {
+			return err
+		}
+
+		if err := mutate(); err != nil {
+			return err
+		}
+
+		patch := client.MergeFromWithOptions(before, client.MergeFromWithOptimisticLock{})
+		return c.ctrlClient.Patch(ctx, obj, patch, patchOpts...)
+	})
+	if err != nil {
+		return resourceError(objectKind(obj), key, err)
+	}
+
+	if preview != nil {
+		if err := preview.record(before, obj); err != nil {
+			return fmt.Errorf("failed to diff %s %s: %w", objectKind(obj), key, err)
+		}
+		return nil
+	}
+
+	if operation != "" {
+		c.recordChange(operation, before)
+	}
+	return nil
+}
+
+// setNodeUnschedulable cordons or uncordons a node, retrying on conflicts with the kubelet
+func (c *Client) setNodeUnschedulable(ctx context.Context, nodeName string, unschedulable bool) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := c.k8sClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if node.Spec.Unschedulable == unschedulable {
+			return nil
+		}
+
+		node.Spec.Unschedulable = unschedulable
+		_, err = c.k8sClient.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		return err
+	})
+	return nodeError(nodeName, err)
+}
@@ -0,0 +1,77 @@
+package capi
+
+import (
+	"context"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestUpdateObjectRetriesOnConflict(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "default", Labels: map[string]string{"owner": "team-a"}},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a controller updating the cluster between our read and write
+	conflicts := 0
+	ctrlClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(cluster).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				if conflicts == 0 {
+					conflicts++
+					current := &clusterv1.Cluster{}
+					if err := c.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+						return err
+					}
+					current.Labels["reconciled"] = "true"
+					if err := c.Update(ctx, current); err != nil {
+						return err
+					}
+					return apierrors.NewConflict(schema.GroupResource{Group: "cluster.x-k8s.io", Resource: "clusters"}, obj.GetName(), nil)
+				}
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+		}).
+		Build()
+
+	history, err := NewChangeHistory(10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{ctrlClient: ctrlClient, changes: history}
+
+	if err := c.PauseCluster(context.Background(), "default", "prod"); err != nil {
+		t.Fatalf("PauseCluster() error = %v", err)
+	}
+	if conflicts != 1 {
+		t.Fatalf("expected one simulated conflict, got %d", conflicts)
+	}
+
+	updated := &clusterv1.Cluster{}
+	if err := ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.Annotations[clusterv1.PausedAnnotation] != "true" {
+		t.Error("paused annotation was not applied after retry")
+	}
+	if updated.Labels["reconciled"] != "true" {
+		t.Error("concurrent controller change was overwritten")
+	}
+	if changes := history.List(); len(changes) != 1 {
+		t.Errorf("expected exactly one recorded change, got %d", len(changes))
+	}
+}
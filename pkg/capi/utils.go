@@ -4,25 +4,41 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	v1beta2conditions "sigs.k8s.io/cluster-api/util/conditions/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // ClusterStatus represents the status of a CAPI cluster
 type ClusterStatus struct {
-	Name              string
-	Namespace         string
-	Phase             string
-	Ready             bool
-	ControlPlaneReady bool
-	InfraReady        bool
-	Version           string
-	Provider          Provider
-	TotalMachines     int
-	ReadyMachines     int
-	Conditions        clusterv1.Conditions
+	Name                 string               `json:"name"`
+	Namespace            string               `json:"namespace"`
+	Phase                string               `json:"phase"`
+	Ready                bool                 `json:"ready"`
+	ControlPlaneReady    bool                 `json:"controlPlaneReady"`
+	InfraReady           bool                 `json:"infrastructureReady"`
+	Version              string               `json:"version,omitempty"`
+	Provider             Provider             `json:"provider"`
+	TotalMachines        int                  `json:"totalMachines"`
+	ReadyMachines        int                  `json:"readyMachines"`
+	ControlPlaneMachines int                  `json:"controlPlaneMachines"`
+	Conditions           clusterv1.Conditions `json:"conditions,omitempty"`
+	CreatedAt            time.Time            `json:"createdAt"`
+	// GiantSwarmLabels are the giantswarm.io labels of the cluster, such as
+	// its organization, release and service priority
+	GiantSwarmLabels map[string]string `json:"giantswarmLabels,omitempty"`
+}
+
+// ClusterStatusList is a page of cluster statuses. Continue is set when more
+// clusters remain and is passed to WithContinue to fetch them.
+type ClusterStatusList struct {
+	Items    []*ClusterStatus `json:"items"`
+	Continue string           `json:"continue,omitempty"`
 }
 
 // GetClusterStatus retrieves comprehensive status information for a cluster
@@ -32,47 +48,128 @@ func (c *Client) GetClusterStatus(ctx context.Context, namespace, name string) (
 		return nil, err
 	}
 
+	// Machine counts and the control plane version are best effort
+	var machines []clusterv1.Machine
+	if list, err := c.ListMachines(ctx, namespace, name); err == nil {
+		machines = list.Items
+	}
+	var kcp *controlplanev1.KubeadmControlPlane
+	if needsControlPlaneVersion(cluster) {
+		kcp, _ = c.GetKubeadmControlPlane(ctx, namespace, cluster.Spec.ControlPlaneRef.Name)
+	}
+
+	status := newClusterStatus(cluster, machines, kcp)
+	if status.Version == "" && isAKSCluster(cluster) {
+		ref := cluster.Spec.ControlPlaneRef
+		if controlPlane, err := c.getReferenced(ctx, namespace, ref.APIVersion, ref.Kind, ref.Name); err == nil {
+			status.Version, _, _ = unstructured.NestedString(controlPlane.Object, "spec", "version")
+		}
+	}
+	return status, nil
+}
+
+// GetClustersStatus retrieves the status of all clusters in a namespace, or
+// in all namespaces if namespace is empty. Unlike calling GetClusterStatus per
+// cluster, it lists clusters, machines and control planes once and joins them
+// in memory, which keeps the number of API requests constant on large fleets.
+// The options page through the clusters; machines and control planes are
+// always listed for the whole namespace.
+func (c *Client) GetClustersStatus(ctx context.Context, namespace string, opts ...ListOption) (*ClusterStatusList, error) {
+	clusters, err := c.ListClusters(ctx, namespace, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if len(clusters.Items) == 0 {
+		return &ClusterStatusList{Items: []*ClusterStatus{}, Continue: clusters.Continue}, nil
+	}
+
+	// Machine counts and control plane versions are best effort, as in GetClusterStatus
+	machinesByCluster := make(map[string][]clusterv1.Machine)
+	if machines, err := c.ListMachines(ctx, namespace, ""); err == nil {
+		for _, machine := range machines.Items {
+			key := machine.Namespace + "/" + machine.Labels[clusterv1.ClusterNameLabel]
+			machinesByCluster[key] = append(machinesByCluster[key], machine)
+		}
+	}
+	needsKCPs, needsAKS := false, false
+	for i := range clusters.Items {
+		needsKCPs = needsKCPs || needsControlPlaneVersion(&clusters.Items[i])
+		needsAKS = needsAKS || isAKSCluster(&clusters.Items[i])
+	}
+	kcps := make(map[string]*controlplanev1.KubeadmControlPlane)
+	if needsKCPs {
+		if list, err := c.ListKubeadmControlPlanes(ctx, namespace); err == nil {
+			for i := range list.Items {
+				kcps[list.Items[i].Namespace+"/"+list.Items[i].Name] = &list.Items[i]
+			}
+		}
+	}
+
+	aksVersions := make(map[string]string)
+	if needsAKS {
+		if list, err := c.listUnstructured(ctx, azureManagedControlPlaneGVK, client.InNamespace(namespace)); err == nil {
+			for _, controlPlane := range list {
+				aksVersions[controlPlane.GetNamespace()+"/"+controlPlane.GetName()], _, _ = unstructured.NestedString(controlPlane.Object, "spec", "version")
+			}
+		}
+	}
+
+	statuses := make([]*ClusterStatus, 0, len(clusters.Items))
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		var kcp *controlplanev1.KubeadmControlPlane
+		if needsControlPlaneVersion(cluster) {
+			kcp = kcps[cluster.Namespace+"/"+cluster.Spec.ControlPlaneRef.Name]
+		}
+		status := newClusterStatus(cluster, machinesByCluster[cluster.Namespace+"/"+cluster.Name], kcp)
+		if status.Version == "" && isAKSCluster(cluster) {
+			status.Version = aksVersions[cluster.Namespace+"/"+cluster.Spec.ControlPlaneRef.Name]
+		}
+		statuses = append(statuses, status)
+	}
+	return &ClusterStatusList{Items: statuses, Continue: clusters.Continue}, nil
+}
+
+// needsControlPlaneVersion reports whether the version of a cluster is only
+// found on its KubeadmControlPlane
+func needsControlPlaneVersion(cluster *clusterv1.Cluster) bool {
+	hasTopologyVersion := cluster.Spec.Topology != nil && cluster.Spec.Topology.Version != ""
+	return !hasTopologyVersion && cluster.Spec.ControlPlaneRef != nil && cluster.Spec.ControlPlaneRef.Kind == "KubeadmControlPlane"
+}
+
+// newClusterStatus summarizes a cluster with its machines and, if the version
+// is not set in the topology, its KubeadmControlPlane, which may be nil
+func newClusterStatus(cluster *clusterv1.Cluster, machines []clusterv1.Machine, kcp *controlplanev1.KubeadmControlPlane) *ClusterStatus {
 	status := &ClusterStatus{
 		Name:              cluster.Name,
 		Namespace:         cluster.Namespace,
 		Phase:             string(cluster.Status.Phase),
-		Ready:             conditions.IsTrue(cluster, clusterv1.ReadyCondition),
+		Ready:             clusterReady(cluster),
 		ControlPlaneReady: cluster.Status.ControlPlaneReady,
 		InfraReady:        cluster.Status.InfrastructureReady,
+		Provider:          ClusterProvider(cluster),
 		Conditions:        cluster.Status.Conditions,
+		CreatedAt:         cluster.CreationTimestamp.Time,
+		GiantSwarmLabels:  giantSwarmLabels(cluster.Labels),
 	}
 
-	// Get version from cluster spec
+	// Get version from cluster spec, falling back to the control plane
 	if cluster.Spec.Topology != nil && cluster.Spec.Topology.Version != "" {
 		status.Version = cluster.Spec.Topology.Version
+	} else if kcp != nil {
+		status.Version = kcp.Spec.Version
 	}
 
-	// Get provider information
-	provider, _ := c.GetProviderForCluster(ctx, namespace, name)
-	status.Provider = provider
-
-	// Get machine counts
-	machines, err := c.ListMachines(ctx, namespace, name)
-	if err == nil {
-		status.TotalMachines = len(machines.Items)
-		for _, machine := range machines.Items {
-			if machine.Status.NodeRef != nil {
-				status.ReadyMachines++
-			}
+	status.TotalMachines = len(machines)
+	for _, machine := range machines {
+		if machine.Status.NodeRef != nil {
+			status.ReadyMachines++
 		}
-	}
-
-	// Get control plane version if available
-	if cluster.Spec.ControlPlaneRef != nil && status.Version == "" {
-		if cluster.Spec.ControlPlaneRef.Kind == "KubeadmControlPlane" {
-			kcp, err := c.GetKubeadmControlPlane(ctx, namespace, cluster.Spec.ControlPlaneRef.Name)
-			if err == nil && kcp.Spec.Version != "" {
-				status.Version = kcp.Spec.Version
-			}
+		if _, ok := machine.Labels[clusterv1.MachineControlPlaneLabel]; ok {
+			status.ControlPlaneMachines++
 		}
 	}
-
-	return status, nil
+	return status
 }
 
 // IsClusterReady checks if a cluster is fully ready
@@ -82,22 +179,96 @@ func (c *Client) IsClusterReady(ctx context.Context, namespace, name string) (bo
 		return false, err
 	}
 
-	return conditions.IsTrue(cluster, clusterv1.ReadyCondition), nil
+	return clusterReady(cluster), nil
+}
+
+// clusterReady reports whether a cluster is ready. Controllers of the v1beta2
+// contract report the Available condition, which replaces Ready and is
+// preferred when set.
+func clusterReady(cluster *clusterv1.Cluster) bool {
+	if v1beta2conditions.Has(cluster, clusterv1.ClusterAvailableV1Beta2Condition) {
+		return v1beta2conditions.IsTrue(cluster, clusterv1.ClusterAvailableV1Beta2Condition)
+	}
+	return conditions.IsTrue(cluster, clusterv1.ReadyCondition)
+}
+
+// IsMachineReady reports whether a machine is ready, preferring the Ready
+// condition of the v1beta2 contract when set
+func IsMachineReady(machine *clusterv1.Machine) bool {
+	if v1beta2conditions.Has(machine, clusterv1.MachineReadyV1Beta2Condition) {
+		return v1beta2conditions.IsTrue(machine, clusterv1.MachineReadyV1Beta2Condition)
+	}
+	return conditions.IsTrue(machine, clusterv1.ReadyCondition)
+}
+
+// controlPlaneReady reports whether a KubeadmControlPlane is ready, preferring
+// the Available condition of the v1beta2 contract when set
+func controlPlaneReady(kcp *controlplanev1.KubeadmControlPlane) bool {
+	if v1beta2conditions.Has(kcp, controlplanev1.KubeadmControlPlaneAvailableV1Beta2Condition) {
+		return v1beta2conditions.IsTrue(kcp, controlplanev1.KubeadmControlPlaneAvailableV1Beta2Condition)
+	}
+	return kcp.Status.Ready
+}
+
+// UpgradeProgress tells how many machines of a cluster run a Kubernetes version
+type UpgradeProgress struct {
+	TargetVersion        string `json:"targetVersion"`
+	ClusterReady         bool   `json:"clusterReady"`
+	ControlPlaneMachines int    `json:"controlPlaneMachines"`
+	ControlPlaneUpgraded int    `json:"controlPlaneUpgraded"`
+	WorkerMachines       int    `json:"workerMachines"`
+	WorkersUpgraded      int    `json:"workersUpgraded"`
+}
+
+// ControlPlaneDone reports whether every control plane machine runs the target version
+func (p *UpgradeProgress) ControlPlaneDone() bool {
+	return p.ControlPlaneMachines == p.ControlPlaneUpgraded
+}
+
+// Done reports whether the cluster is ready with all control plane machines
+// and, if includeWorkers is set, all worker machines on the target version
+func (p *UpgradeProgress) Done(includeWorkers bool) bool {
+	if !p.ClusterReady || !p.ControlPlaneDone() {
+		return false
+	}
+	return !includeWorkers || p.WorkerMachines == p.WorkersUpgraded
 }
 
-// WaitForClusterReady waits for a cluster to become ready
-// This is a simplified version - in production you'd want proper timeout handling
-func (c *Client) WaitForClusterReady(ctx context.Context, namespace, name string) error {
-	// This would typically use a wait.Poll or watch mechanism
-	// For now, just check once
+// GetUpgradeProgress counts the machines of a cluster that run the target
+// version with a node attached. Machines being deleted are left out, since
+// rolling updates replace rather than upgrade machines.
+func (c *Client) GetUpgradeProgress(ctx context.Context, namespace, name, targetVersion string) (*UpgradeProgress, error) {
 	ready, err := c.IsClusterReady(ctx, namespace, name)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if !ready {
-		return fmt.Errorf("cluster %s/%s is not ready", namespace, name)
+	machines, err := c.ListMachines(ctx, namespace, name)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	progress := &UpgradeProgress{TargetVersion: targetVersion, ClusterReady: ready}
+	for i := range machines.Items {
+		machine := &machines.Items[i]
+		if !machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		upgraded := machine.Spec.Version != nil &&
+			strings.TrimPrefix(*machine.Spec.Version, "v") == strings.TrimPrefix(targetVersion, "v") &&
+			machine.Status.NodeRef != nil
+		if _, ok := machine.Labels[clusterv1.MachineControlPlaneLabel]; ok {
+			progress.ControlPlaneMachines++
+			if upgraded {
+				progress.ControlPlaneUpgraded++
+			}
+			continue
+		}
+		progress.WorkerMachines++
+		if upgraded {
+			progress.WorkersUpgraded++
+		}
+	}
+	return progress, nil
 }
 
 // GetMachinePhase returns a human-readable phase for a machine
@@ -107,7 +278,7 @@ func GetMachinePhase(machine *clusterv1.Machine) string {
 	}
 
 	// Check conditions
-	if conditions.IsTrue(machine, clusterv1.ReadyCondition) {
+	if IsMachineReady(machine) {
 		return "Running"
 	}
 
@@ -116,7 +287,7 @@ func GetMachinePhase(machine *clusterv1.Machine) string {
 
 // GetControlPlaneStatus returns the status of a KubeadmControlPlane
 func GetControlPlaneStatus(kcp *controlplanev1.KubeadmControlPlane) string {
-	if kcp.Status.Ready {
+	if controlPlaneReady(kcp) {
 		return "Ready"
 	}
 
@@ -141,6 +312,28 @@ func FormatClusterInfo(status *ClusterStatus) string {
 	sb.WriteString(fmt.Sprintf("Provider: %s\n", status.Provider))
 	sb.WriteString(fmt.Sprintf("Version: %s\n", status.Version))
 	sb.WriteString(fmt.Sprintf("Machines: %d/%d ready\n", status.ReadyMachines, status.TotalMachines))
+	if org := status.GiantSwarmLabels[OrganizationLabel]; org != "" {
+		sb.WriteString(fmt.Sprintf("Organization: %s\n", org))
+	}
+	if release := status.GiantSwarmLabels[ReleaseVersionLabel]; release != "" {
+		sb.WriteString(fmt.Sprintf("Release: %s\n", release))
+	}
+	if priority := status.GiantSwarmLabels[ServicePriorityLabel]; priority != "" {
+		sb.WriteString(fmt.Sprintf("Service Priority: %s\n", priority))
+	}
+
+	var other []string
+	for _, key := range sortedKeys(status.GiantSwarmLabels) {
+		switch key {
+		case OrganizationLabel, ReleaseVersionLabel, ServicePriorityLabel:
+		default:
+			other = append(other, fmt.Sprintf("  %s: %s\n", key, status.GiantSwarmLabels[key]))
+		}
+	}
+	if len(other) > 0 {
+		sb.WriteString("\nGiant Swarm Labels:\n")
+		sb.WriteString(strings.Join(other, ""))
+	}
 
 	if len(status.Conditions) > 0 {
 		sb.WriteString("\nConditions:\n")
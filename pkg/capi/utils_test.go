@@ -0,0 +1,168 @@
+package capi
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestGetUpgradeProgress(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "default"},
+		Status: clusterv1.ClusterStatus{
+			Conditions: clusterv1.Conditions{{Type: clusterv1.ReadyCondition, Status: corev1.ConditionTrue}},
+		},
+	}
+	machine := func(name, version string, controlPlane, hasNode bool) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: "prod"},
+			},
+			Spec: clusterv1.MachineSpec{ClusterName: "prod", Version: &version},
+		}
+		if controlPlane {
+			m.Labels[clusterv1.MachineControlPlaneLabel] = ""
+		}
+		if hasNode {
+			m.Status.NodeRef = &corev1.ObjectReference{Name: name}
+		}
+		return m
+	}
+
+	objects := []client.Object{
+		cluster,
+		machine("cp-1", "v1.30.0", true, true),
+		machine("cp-2", "1.30.0", true, true),
+		machine("worker-1", "v1.30.0", false, false),
+		machine("worker-2", "v1.29.4", false, true),
+	}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()}
+
+	progress, err := c.GetUpgradeProgress(context.Background(), "default", "prod", "v1.30.0")
+	if err != nil {
+		t.Fatalf("GetUpgradeProgress() error = %v", err)
+	}
+	want := UpgradeProgress{
+		TargetVersion:        "v1.30.0",
+		ClusterReady:         true,
+		ControlPlaneMachines: 2,
+		ControlPlaneUpgraded: 2,
+		WorkerMachines:       2,
+		WorkersUpgraded:      0,
+	}
+	if *progress != want {
+		t.Errorf("GetUpgradeProgress() = %+v, want %+v", *progress, want)
+	}
+	if !progress.Done(false) {
+		t.Error("Done(false) = false, want true once the control plane is upgraded")
+	}
+	if progress.Done(true) {
+		t.Error("Done(true) = true while workers are still on the old version")
+	}
+}
+
+func TestGetClustersStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	newCluster := func(namespace, name string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{Kind: "AWSCluster", Name: name},
+				ControlPlaneRef:   &corev1.ObjectReference{Kind: "KubeadmControlPlane", Name: name + "-cp"},
+			},
+		}
+	}
+	newMachine := func(namespace, name, cluster string, hasNode bool) *clusterv1.Machine {
+		m := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster},
+		}}
+		if hasNode {
+			m.Status.NodeRef = &corev1.ObjectReference{Name: name}
+		}
+		return m
+	}
+
+	objects := []client.Object{
+		newCluster("org-a", "prod"),
+		newCluster("org-b", "prod"),
+		&controlplanev1.KubeadmControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod-cp", Namespace: "org-a"},
+			Spec:       controlplanev1.KubeadmControlPlaneSpec{Version: "v1.30.0"},
+		},
+		&controlplanev1.KubeadmControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod-cp", Namespace: "org-b"},
+			Spec:       controlplanev1.KubeadmControlPlaneSpec{Version: "v1.29.4"},
+		},
+		newMachine("org-a", "prod-1", "prod", true),
+		newMachine("org-a", "prod-2", "prod", false),
+		newMachine("org-b", "prod-1", "prod", true),
+	}
+
+	requests := 0
+	ctrlClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+				requests++
+				return c.Get(ctx, key, obj, opts...)
+			},
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				requests++
+				return c.List(ctx, list, opts...)
+			},
+		}).
+		Build()
+	c := &Client{ctrlClient: ctrlClient}
+
+	list, err := c.GetClustersStatus(context.Background(), "")
+	if err != nil {
+		t.Fatalf("GetClustersStatus() error = %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("GetClustersStatus() sent %d requests, want 3", requests)
+	}
+	statuses := list.Items
+	if len(statuses) != 2 {
+		t.Fatalf("GetClustersStatus() returned %d clusters, want 2", len(statuses))
+	}
+
+	// The batch must produce the same result as the per-cluster status
+	for _, status := range statuses {
+		single, err := c.GetClusterStatus(context.Background(), status.Namespace, status.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.Version != single.Version || status.TotalMachines != single.TotalMachines ||
+			status.ReadyMachines != single.ReadyMachines || status.Provider != single.Provider {
+			t.Errorf("GetClustersStatus() = %+v, GetClusterStatus() = %+v", status, single)
+		}
+	}
+	if statuses[0].Namespace != "org-a" || statuses[0].Version != "v1.30.0" || statuses[0].TotalMachines != 2 || statuses[0].ReadyMachines != 1 {
+		t.Errorf("unexpected status of org-a/prod: %+v", statuses[0])
+	}
+}
@@ -0,0 +1,374 @@
+package capi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// TemplateVariable describes an input of a cluster template or ClusterClass
+type TemplateVariable struct {
+	Name string `json:"name"`
+	// Type is the JSON schema type of a ClusterClass variable; template
+	// variables are strings
+	Type        string   `json:"type"`
+	Required    bool     `json:"required"`
+	Default     string   `json:"default,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	// Builtin variables are set from the cluster name, namespace, version
+	// and machine counts by GenerateCluster
+	Builtin bool `json:"builtin,omitempty"`
+}
+
+// TemplateVariables lists the inputs of a cluster template or ClusterClass
+// and the problems of a proposed set of values
+type TemplateVariables struct {
+	// Source is the template or ClusterClass the variables belong to
+	Source    string             `json:"source"`
+	Variables []TemplateVariable `json:"variables"`
+	// Problems of the proposed values, none when they are valid
+	Problems []string `json:"problems"`
+}
+
+// TemplateVariables lists the variables of the provider template or
+// ClusterClass of opts, like clusterctl generate cluster --list-variables,
+// and validates opts.Variables against them: required variables must be
+// set, unknown ones are reported and ClusterClass values must match the
+// schema of their variable.
+func (c *Client) TemplateVariables(ctx context.Context, repo TemplateRepository, opts GenerateClusterOptions) (*TemplateVariables, error) {
+	if (opts.Provider == "") == (opts.ClusterClass == "") {
+		return nil, errorf(ErrInvalidArgument, "either a provider template or a ClusterClass is required")
+	}
+
+	if opts.ClusterClass != "" {
+		class, err := c.getClusterClass(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		definitions := classVariables(class)
+		result := &TemplateVariables{
+			Source:    fmt.Sprintf("ClusterClass %s/%s", class.Namespace, class.Name),
+			Variables: make([]TemplateVariable, 0, len(definitions)),
+			Problems:  []string{},
+		}
+		for _, definition := range definitions {
+			result.Variables = append(result.Variables, definition.TemplateVariable)
+		}
+		if opts.Variables != nil {
+			result.Problems = append(result.Problems, validateClassVariables(definitions, opts.Variables)...)
+		}
+		return result, nil
+	}
+
+	provider, version, data, err := fetchTemplate(ctx, repo, opts)
+	if err != nil {
+		return nil, err
+	}
+	result := &TemplateVariables{
+		Source:    fmt.Sprintf("%s %s %s", provider, version, templateFile(opts.Flavor)),
+		Variables: templateVariables(data),
+		Problems:  []string{},
+	}
+	if opts.Variables == nil {
+		return result, nil
+	}
+	known := make(map[string]bool, len(result.Variables))
+	for _, variable := range result.Variables {
+		known[variable.Name] = true
+		if _, ok := opts.Variables[variable.Name]; variable.Required && !variable.Builtin && !ok {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s is required", variable.Name))
+		}
+	}
+	for _, name := range sortedKeys(opts.Variables) {
+		if !known[name] {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s is not used by the template", name))
+		}
+	}
+	return result, nil
+}
+
+// templateVariables returns the ${VAR} placeholders of a template sorted by
+// name. A variable is required unless one of its placeholders has a default.
+func templateVariables(data []byte) []TemplateVariable {
+	builtin := builtinVariables(GenerateClusterOptions{KubernetesVersion: "set"})
+	byName := make(map[string]*TemplateVariable)
+	for _, groups := range variablePattern.FindAllSubmatch(data, -1) {
+		name := string(groups[1])
+		variable, ok := byName[name]
+		if !ok {
+			_, isBuiltin := builtin[name]
+			variable = &TemplateVariable{Name: name, Type: "string", Required: true, Builtin: isBuiltin}
+			byName[name] = variable
+		}
+		if bytes.Contains(groups[0], []byte(":=")) || bytes.Contains(groups[0], []byte(":-")) {
+			variable.Required = false
+			if variable.Default == "" {
+				variable.Default = string(groups[2])
+			}
+		}
+	}
+
+	variables := make([]TemplateVariable, 0, len(byName))
+	for _, variable := range byName {
+		variables = append(variables, *variable)
+	}
+	sort.Slice(variables, func(i, j int) bool { return variables[i].Name < variables[j].Name })
+	return variables
+}
+
+// classVariable is a variable of a ClusterClass with its schema
+type classVariable struct {
+	TemplateVariable
+	schema *apiextensionsv1.JSONSchemaProps
+}
+
+// classVariables returns the variables of a ClusterClass sorted by name,
+// from its spec and the definitions of its patches in its status
+func classVariables(class *clusterv1.ClusterClass) []classVariable {
+	byName := make(map[string]classVariable)
+	add := func(name string, required bool, schema clusterv1.JSONSchemaProps) {
+		if _, ok := byName[name]; ok {
+			return
+		}
+		variable := classVariable{
+			TemplateVariable: TemplateVariable{
+				Name:        name,
+				Type:        schema.Type,
+				Required:    required,
+				Description: schema.Description,
+			},
+			schema: classSchema(schema),
+		}
+		if schema.Default != nil {
+			variable.Default = string(schema.Default.Raw)
+		}
+		for _, value := range schema.Enum {
+			variable.Enum = append(variable.Enum, string(value.Raw))
+		}
+		byName[name] = variable
+	}
+	for _, definition := range class.Spec.Variables {
+		add(definition.Name, definition.Required, definition.Schema.OpenAPIV3Schema)
+	}
+	for _, status := range class.Status.Variables {
+		if len(status.Definitions) > 0 {
+			add(status.Name, status.Definitions[0].Required, status.Definitions[0].Schema.OpenAPIV3Schema)
+		}
+	}
+
+	variables := make([]classVariable, 0, len(byName))
+	for _, variable := range byName {
+		variables = append(variables, variable)
+	}
+	sort.Slice(variables, func(i, j int) bool { return variables[i].Name < variables[j].Name })
+	return variables
+}
+
+// validateClassVariables checks values against the variables of a
+// ClusterClass, returning a description of each problem
+func validateClassVariables(definitions []classVariable, values map[string]string) []string {
+	var problems []string
+	known := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		known[definition.Name] = true
+		value, ok := values[definition.Name]
+		if !ok {
+			if definition.Required {
+				problems = append(problems, fmt.Sprintf("%s is required", definition.Name))
+			}
+			continue
+		}
+		parsed, _ := parseVariableValue(value)
+		problems = append(problems, validateSchema(definition.schema, parsed, definition.Name)...)
+	}
+	for _, name := range sortedKeys(values) {
+		if !known[name] {
+			problems = append(problems, fmt.Sprintf("%s is not a variable of the ClusterClass", name))
+		}
+	}
+	return problems
+}
+
+// parseVariableValue reads a variable value as JSON, or as a string when it
+// is not valid JSON, returning the value and its JSON encoding
+func parseVariableValue(value string) (any, []byte) {
+	var parsed any
+	if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+		return parsed, []byte(value)
+	}
+	raw, _ := json.Marshal(value)
+	return value, raw
+}
+
+// classSchema converts the schema of a ClusterClass variable to the
+// apiextensions type of CRD schemas, as Cluster API does to validate values
+func classSchema(schema clusterv1.JSONSchemaProps) *apiextensionsv1.JSONSchemaProps {
+	converted := &apiextensionsv1.JSONSchemaProps{}
+	if data, err := json.Marshal(schema); err == nil {
+		_ = json.Unmarshal(data, converted)
+	}
+	return converted
+}
+
+// validateSchema checks a JSON value against the structural subset of
+// OpenAPI v3 used by CRDs and ClusterClass variables. CEL rules and formats
+// are left to the API server.
+func validateSchema(schema *apiextensionsv1.JSONSchemaProps, value any, path string) []string {
+	if value == nil {
+		if schema.Nullable {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s must not be null", path)}
+	}
+	preserveUnknown := schema.XPreserveUnknownFields != nil && *schema.XPreserveUnknownFields
+	if schema.XIntOrString {
+		if _, ok := value.(string); ok {
+			return nil
+		}
+		if n, ok := number(value); ok && n == float64(int64(n)) {
+			return nil
+		}
+		return []string{fmt.Sprintf("%s must be an integer or a string", path)}
+	}
+
+	var problems []string
+	if len(schema.Enum) > 0 {
+		allowed := make([]string, 0, len(schema.Enum))
+		found := false
+		for _, option := range schema.Enum {
+			var parsed any
+			if json.Unmarshal(option.Raw, &parsed) == nil && reflect.DeepEqual(parsed, normalizeNumber(value)) {
+				found = true
+			}
+			allowed = append(allowed, string(option.Raw))
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("%s must be one of %s", path, strings.Join(allowed, ", ")))
+		}
+	}
+
+	switch schema.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s must be a string", path))
+		}
+		if schema.MinLength != nil && int64(len(s)) < *schema.MinLength {
+			problems = append(problems, fmt.Sprintf("%s must be at least %d characters long", path, *schema.MinLength))
+		}
+		if schema.MaxLength != nil && int64(len(s)) > *schema.MaxLength {
+			problems = append(problems, fmt.Sprintf("%s must be at most %d characters long", path, *schema.MaxLength))
+		}
+		if schema.Pattern != "" {
+			if pattern, err := regexp.Compile(schema.Pattern); err == nil && !pattern.MatchString(s) {
+				problems = append(problems, fmt.Sprintf("%s must match %s", path, schema.Pattern))
+			}
+		}
+	case "integer", "number":
+		n, ok := number(value)
+		if !ok || (schema.Type == "integer" && n != float64(int64(n))) {
+			return append(problems, fmt.Sprintf("%s must be %s", path, map[string]string{"integer": "an integer", "number": "a number"}[schema.Type]))
+		}
+		if schema.Minimum != nil && (n < *schema.Minimum || schema.ExclusiveMinimum && n == *schema.Minimum) {
+			problems = append(problems, fmt.Sprintf("%s must be greater than %s%v", path, orEqual(!schema.ExclusiveMinimum), *schema.Minimum))
+		}
+		if schema.Maximum != nil && (n > *schema.Maximum || schema.ExclusiveMaximum && n == *schema.Maximum) {
+			problems = append(problems, fmt.Sprintf("%s must be less than %s%v", path, orEqual(!schema.ExclusiveMaximum), *schema.Maximum))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			problems = append(problems, fmt.Sprintf("%s must be a boolean", path))
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s must be an array", path))
+		}
+		if schema.MinItems != nil && int64(len(items)) < *schema.MinItems {
+			problems = append(problems, fmt.Sprintf("%s must have at least %d items", path, *schema.MinItems))
+		}
+		if schema.MaxItems != nil && int64(len(items)) > *schema.MaxItems {
+			problems = append(problems, fmt.Sprintf("%s must have at most %d items", path, *schema.MaxItems))
+		}
+		if schema.Items != nil && schema.Items.Schema != nil {
+			for i, item := range items {
+				problems = append(problems, validateSchema(schema.Items.Schema, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case "object":
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return append(problems, fmt.Sprintf("%s must be an object", path))
+		}
+		for _, name := range schema.Required {
+			if _, ok := fields[name]; !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s is required", path, name))
+			}
+		}
+		for _, name := range sortedKeys(fields) {
+			field := path + "." + name
+			if property, ok := schema.Properties[name]; ok {
+				problems = append(problems, validateSchema(&property, fields[name], field)...)
+				continue
+			}
+			switch {
+			case schema.XEmbeddedResource && (name == "apiVersion" || name == "kind" || name == "metadata"):
+			case schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+				problems = append(problems, validateSchema(schema.AdditionalProperties.Schema, fields[name], field)...)
+			case schema.AdditionalProperties != nil && schema.AdditionalProperties.Allows, preserveUnknown:
+			default:
+				problems = append(problems, fmt.Sprintf("%s is not a known field", field))
+			}
+		}
+	}
+	return problems
+}
+
+// number returns a JSON or YAML number as a float64
+func number(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// normalizeNumber turns numbers into float64 to compare them with values
+// decoded from JSON
+func normalizeNumber(value any) any {
+	if n, ok := number(value); ok {
+		return n
+	}
+	return value
+}
+
+// orEqual phrases an inclusive bound
+func orEqual(inclusive bool) string {
+	if inclusive {
+		return "or equal to "
+	}
+	return ""
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
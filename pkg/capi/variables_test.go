@@ -0,0 +1,176 @@
+package capi
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTemplateVariablesOfTemplate(t *testing.T) {
+	repo := &fakeTemplateRepository{
+		fakeProviderRepository: fakeProviderRepository{latest: map[string]string{"infrastructure-docker": "v1.10.2"}},
+		templates:              map[string]string{"infrastructure-docker@v1.10.2/cluster-template.yaml": dockerTemplate},
+	}
+	c := &Client{ctrlClient: fake.NewClientBuilder().Build()}
+	ctx := context.Background()
+	opts := GenerateClusterOptions{Namespace: "org-acme", Provider: "docker"}
+
+	result, err := c.TemplateVariables(ctx, repo, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Source != "infrastructure-docker v1.10.2 cluster-template.yaml" {
+		t.Errorf("source = %q", result.Source)
+	}
+	want := []TemplateVariable{
+		{Name: "CLUSTER_NAME", Type: "string", Required: true, Builtin: true},
+		{Name: "CONTROL_PLANE_MACHINE_COUNT", Type: "string", Required: true, Builtin: true},
+		{Name: "KUBERNETES_VERSION", Type: "string", Required: true, Builtin: true},
+		{Name: "MOTD", Type: "string", Required: true},
+		{Name: "NAMESPACE", Type: "string", Required: true, Builtin: true},
+		{Name: "POD_CIDR", Type: "string", Default: "192.168.0.0/16"},
+	}
+	if !reflect.DeepEqual(result.Variables, want) {
+		t.Errorf("variables = %+v, want %+v", result.Variables, want)
+	}
+	if len(result.Problems) != 0 {
+		t.Errorf("problems without proposed values = %v", result.Problems)
+	}
+
+	opts.Variables = map[string]string{"AWS_REGION": "eu-west-1"}
+	if result, err = c.TemplateVariables(ctx, repo, opts); err != nil {
+		t.Fatal(err)
+	}
+	wantProblems := []string{"MOTD is required", "AWS_REGION is not used by the template"}
+	if !reflect.DeepEqual(result.Problems, wantProblems) {
+		t.Errorf("problems = %v, want %v", result.Problems, wantProblems)
+	}
+}
+
+func TestTemplateVariablesOfClusterClass(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	class := &clusterv1.ClusterClass{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "quick-start"},
+		Spec: clusterv1.ClusterClassSpec{
+			Variables: []clusterv1.ClusterClassVariable{
+				{Name: "region", Required: true, Schema: clusterv1.VariableSchema{OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+					Type: "string", Description: "AWS region", MinLength: ptr.To[int64](1),
+				}}},
+				{Name: "replicas", Schema: clusterv1.VariableSchema{OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+					Type: "integer", Minimum: ptr.To[int64](1), Default: &apiextensionsv1.JSON{Raw: []byte("3")},
+				}}},
+			},
+		},
+		Status: clusterv1.ClusterClassStatus{
+			Variables: []clusterv1.ClusterClassStatusVariable{{
+				Name: "imageRepository",
+				Definitions: []clusterv1.ClusterClassStatusVariableDefinition{{
+					From: "patch",
+					Schema: clusterv1.VariableSchema{OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+						Type: "string", Enum: []apiextensionsv1.JSON{{Raw: []byte(`"registry.k8s.io"`)}, {Raw: []byte(`"gsoci.azurecr.io"`)}},
+					}},
+				}},
+			}},
+		},
+	}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(class).Build()}
+	ctx := context.Background()
+	opts := GenerateClusterOptions{Namespace: "org-acme", ClusterClass: "quick-start"}
+
+	result, err := c.TemplateVariables(ctx, nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, variable := range result.Variables {
+		names = append(names, variable.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"imageRepository", "region", "replicas"}) {
+		t.Fatalf("variables = %v", names)
+	}
+	if region := result.Variables[1]; !region.Required || region.Type != "string" || region.Description != "AWS region" {
+		t.Errorf("region = %+v", region)
+	}
+	if replicas := result.Variables[2]; replicas.Required || replicas.Default != "3" {
+		t.Errorf("replicas = %+v", replicas)
+	}
+	if image := result.Variables[0]; len(image.Enum) != 2 {
+		t.Errorf("imageRepository = %+v", image)
+	}
+
+	opts.Variables = map[string]string{"replicas": "0", "imageRepository": "docker.io", "zone": "a"}
+	if result, err = c.TemplateVariables(ctx, nil, opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Problems) != 4 {
+		t.Fatalf("problems = %v, want 4", result.Problems)
+	}
+	for i, name := range []string{"imageRepository", "region", "replicas", "zone"} {
+		if !strings.HasPrefix(result.Problems[i], name+" ") {
+			t.Errorf("problem %d = %q, want one about %s", i, result.Problems[i], name)
+		}
+	}
+
+	opts.Variables = map[string]string{"region": "eu-west-1", "replicas": "3", "imageRepository": "registry.k8s.io"}
+	if result, err = c.TemplateVariables(ctx, nil, opts); err != nil || len(result.Problems) != 0 {
+		t.Errorf("TemplateVariables() of valid values = %v, %v", result.Problems, err)
+	}
+
+	if _, err := c.TemplateVariables(ctx, nil, GenerateClusterOptions{Namespace: "org-acme"}); err == nil {
+		t.Error("TemplateVariables() without a template or ClusterClass succeeded")
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	object := clusterv1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"name"},
+		Properties: map[string]clusterv1.JSONSchemaProps{
+			"name":  {Type: "string", Pattern: "^[a-z]+$"},
+			"ports": {Type: "array", MaxItems: ptr.To[int64](2), Items: &clusterv1.JSONSchemaProps{Type: "integer", Maximum: ptr.To[int64](65535)}},
+		},
+	}
+	labels := clusterv1.JSONSchemaProps{Type: "object", AdditionalProperties: &clusterv1.JSONSchemaProps{Type: "string"}}
+	tests := []struct {
+		name   string
+		schema clusterv1.JSONSchemaProps
+		value  string
+		want   []string
+	}{
+		{"string", clusterv1.JSONSchemaProps{Type: "string"}, "plain", nil},
+		{"not a string", clusterv1.JSONSchemaProps{Type: "string"}, "1", []string{"v must be a string"}},
+		{"boolean", clusterv1.JSONSchemaProps{Type: "boolean"}, "true", nil},
+		{"fraction", clusterv1.JSONSchemaProps{Type: "integer"}, "1.5", []string{"v must be an integer"}},
+		{"exclusive minimum", clusterv1.JSONSchemaProps{Type: "number", Minimum: ptr.To[int64](1), ExclusiveMinimum: true}, "1", []string{"v must be greater than 1"}},
+		{"null", clusterv1.JSONSchemaProps{Type: "string"}, "null", []string{"v must not be null"}},
+		{"int or string", clusterv1.JSONSchemaProps{XIntOrString: true}, "10%", nil},
+		{"object", object, `{"name": "web", "ports": [80, 443]}`, nil},
+		{"nested problems", object, `{"ports": [80, 443, 70000], "extra": 1}`, []string{
+			"v.name is required",
+			"v.extra is not a known field",
+			"v.ports must have at most 2 items",
+			"v.ports[2] must be less than or equal to 65535",
+		}},
+		{"additional properties", labels, `{"team": "ops", "tier": 1}`, []string{"v.tier must be a string"}},
+		{"preserved fields", clusterv1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: true}, `{"any": "thing"}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, _ := parseVariableValue(tt.value)
+			if got := validateSchema(classSchema(tt.schema), value, "v"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateSchema() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
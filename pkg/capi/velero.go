@@ -0,0 +1,355 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultVeleroNamespace is where Velero runs and keeps its backups and
+	// restores unless configured otherwise
+	DefaultVeleroNamespace = "velero"
+
+	// BackupClusterLabel and BackupClusterNamespaceLabel mark the Velero
+	// backups made for a cluster
+	BackupClusterLabel          = "mcp-capi.giantswarm.io/cluster"
+	BackupClusterNamespaceLabel = "mcp-capi.giantswarm.io/cluster-namespace"
+)
+
+// Phases of Velero backups and restores that restores can start from
+const (
+	veleroPhaseCompleted       = "Completed"
+	veleroPhasePartiallyFailed = "PartiallyFailed"
+)
+
+var (
+	// veleroBackupGVK is the Velero Backup of the resources of namespaces
+	veleroBackupGVK = schema.GroupVersionKind{Group: "velero.io", Version: "v1", Kind: "Backup"}
+	// veleroRestoreGVK is the Velero Restore of a Backup
+	veleroRestoreGVK = schema.GroupVersionKind{Group: "velero.io", Version: "v1", Kind: "Restore"}
+)
+
+// VeleroBackupOptions contains options for backing up a cluster with Velero
+type VeleroBackupOptions struct {
+	// VeleroNamespace is where Velero runs, DefaultVeleroNamespace when empty
+	VeleroNamespace string
+	Namespace       string
+	Cluster         string
+	// Name of the backup, <cluster>-<timestamp> when empty
+	Name string
+	// TTL is how long Velero keeps the backup, its default when zero
+	TTL time.Duration
+	// StorageLocation is the BackupStorageLocation, the default when empty
+	StorageLocation string
+}
+
+// VeleroBackup is a Velero backup of the namespace of a cluster
+type VeleroBackup struct {
+	Name string `json:"name"`
+	// Cluster and ClusterNamespace are set for backups made for a cluster
+	Cluster          string `json:"cluster,omitempty"`
+	ClusterNamespace string `json:"clusterNamespace,omitempty"`
+	// IncludedNamespaces are the backed up namespaces, all when empty
+	IncludedNamespaces []string `json:"includedNamespaces,omitempty"`
+	StorageLocation    string   `json:"storageLocation,omitempty"`
+	TTL                string   `json:"ttl,omitempty"`
+	Phase              string   `json:"phase,omitempty"`
+	Started            string   `json:"started,omitempty"`
+	Completed          string   `json:"completed,omitempty"`
+	Expiration         string   `json:"expiration,omitempty"`
+	ItemsBackedUp      int64    `json:"itemsBackedUp"`
+	TotalItems         int64    `json:"totalItems"`
+	Errors             int64    `json:"errors"`
+	Warnings           int64    `json:"warnings"`
+	FailureReason      string   `json:"failureReason,omitempty"`
+	ValidationErrors   []string `json:"validationErrors,omitempty"`
+}
+
+// Restorable reports whether a restore can start from the backup
+func (b *VeleroBackup) Restorable() bool {
+	return b.Phase == veleroPhaseCompleted || b.Phase == veleroPhasePartiallyFailed
+}
+
+// includes reports whether the backup covers a namespace
+func (b *VeleroBackup) includes(namespace string) bool {
+	return len(b.IncludedNamespaces) == 0 || slices.Contains(b.IncludedNamespaces, "*") || slices.Contains(b.IncludedNamespaces, namespace)
+}
+
+// newVeleroBackup reads a Velero Backup object
+func newVeleroBackup(item *unstructured.Unstructured) VeleroBackup {
+	field := func(fields ...string) string {
+		value, _, _ := unstructured.NestedString(item.Object, fields...)
+		return value
+	}
+	count := func(fields ...string) int64 {
+		value, _, _ := unstructured.NestedInt64(item.Object, fields...)
+		return value
+	}
+	backup := VeleroBackup{
+		Name:             item.GetName(),
+		Cluster:          item.GetLabels()[BackupClusterLabel],
+		ClusterNamespace: item.GetLabels()[BackupClusterNamespaceLabel],
+		StorageLocation:  field("spec", "storageLocation"),
+		TTL:              field("spec", "ttl"),
+		Phase:            field("status", "phase"),
+		Started:          field("status", "startTimestamp"),
+		Completed:        field("status", "completionTimestamp"),
+		Expiration:       field("status", "expiration"),
+		ItemsBackedUp:    count("status", "progress", "itemsBackedUp"),
+		TotalItems:       count("status", "progress", "totalItems"),
+		Errors:           count("status", "errors"),
+		Warnings:         count("status", "warnings"),
+		FailureReason:    field("status", "failureReason"),
+	}
+	backup.IncludedNamespaces, _, _ = unstructured.NestedStringSlice(item.Object, "spec", "includedNamespaces")
+	backup.ValidationErrors, _, _ = unstructured.NestedStringSlice(item.Object, "status", "validationErrors")
+	return backup
+}
+
+// veleroError reports a missing Velero installation as a failed
+// precondition
+func veleroError(kind string, key client.ObjectKey, err error) error {
+	if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+		return errorf(ErrPreconditionFailed, "Velero is not installed on the management cluster: %v", err)
+	}
+	return resourceError(kind, key, err)
+}
+
+// CreateVeleroBackup starts a Velero backup of the namespace of a cluster,
+// which holds the CAPI objects of the cluster with their Secrets. The
+// backup is labeled with the cluster, Velero runs it in the background.
+func (c *Client) CreateVeleroBackup(ctx context.Context, opts VeleroBackupOptions) (*VeleroBackup, error) {
+	if opts.VeleroNamespace == "" {
+		opts.VeleroNamespace = DefaultVeleroNamespace
+	}
+	if _, err := c.GetCluster(ctx, opts.Namespace, opts.Cluster); err != nil {
+		return nil, err
+	}
+	if opts.Name == "" {
+		opts.Name = fmt.Sprintf("%s-%s", opts.Cluster, time.Now().UTC().Format("20060102150405"))
+	}
+
+	backup := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"includedNamespaces": []any{opts.Namespace},
+		},
+	}}
+	backup.SetGroupVersionKind(veleroBackupGVK)
+	backup.SetNamespace(opts.VeleroNamespace)
+	backup.SetName(opts.Name)
+	backup.SetLabels(map[string]string{
+		BackupClusterLabel:          opts.Cluster,
+		BackupClusterNamespaceLabel: opts.Namespace,
+	})
+	if opts.TTL > 0 {
+		_ = unstructured.SetNestedField(backup.Object, opts.TTL.String(), "spec", "ttl")
+	}
+	if opts.StorageLocation != "" {
+		_ = unstructured.SetNestedField(backup.Object, opts.StorageLocation, "spec", "storageLocation")
+	}
+	if err := c.ctrlClient.Create(ctx, backup); err != nil {
+		return nil, fmt.Errorf("failed to create backup: %w", veleroError("Backup", client.ObjectKeyFromObject(backup), err))
+	}
+	result := newVeleroBackup(backup)
+	return &result, nil
+}
+
+// ListVeleroBackups lists the Velero backups covering a namespace, newest
+// first. With a cluster only the backups made for it and those of the whole
+// namespace are listed. Management clusters without Velero have no backups.
+func (c *Client) ListVeleroBackups(ctx context.Context, veleroNamespace, namespace, cluster string) ([]VeleroBackup, error) {
+	if veleroNamespace == "" {
+		veleroNamespace = DefaultVeleroNamespace
+	}
+	items, err := c.listUnstructured(ctx, veleroBackupGVK.GroupVersion().WithKind("BackupList"), client.InNamespace(veleroNamespace))
+	if err != nil {
+		return nil, err
+	}
+	backups := []VeleroBackup{}
+	for i := range items {
+		backup := newVeleroBackup(&items[i])
+		if !backup.includes(namespace) || (cluster != "" && backup.Cluster != "" && backup.Cluster != cluster) {
+			continue
+		}
+		backups = append(backups, backup)
+	}
+	// Backups that have not started yet are the newest
+	sort.SliceStable(backups, func(i, j int) bool {
+		if a, b := backups[i].Started, backups[j].Started; a != b {
+			return a == "" || (b != "" && a > b)
+		}
+		return backups[i].Name > backups[j].Name
+	})
+	return backups, nil
+}
+
+// GetVeleroBackup returns a Velero backup covering a namespace
+func (c *Client) GetVeleroBackup(ctx context.Context, veleroNamespace, namespace, name string) (*VeleroBackup, error) {
+	if veleroNamespace == "" {
+		veleroNamespace = DefaultVeleroNamespace
+	}
+	item := &unstructured.Unstructured{}
+	item.SetGroupVersionKind(veleroBackupGVK)
+	key := client.ObjectKey{Namespace: veleroNamespace, Name: name}
+	if err := c.ctrlClient.Get(ctx, key, item); err != nil {
+		return nil, fmt.Errorf("failed to get backup: %w", veleroError("Backup", key, err))
+	}
+	backup := newVeleroBackup(item)
+	// Backups of other namespaces are not revealed
+	if !backup.includes(namespace) {
+		return nil, errorf(ErrNotFound, "backup %s of namespace %s not found", name, namespace)
+	}
+	return &backup, nil
+}
+
+// VeleroRestoreOptions contains options for restoring a Velero backup
+type VeleroRestoreOptions struct {
+	VeleroNamespace string
+	// Namespace is the namespace to restore from the backup
+	Namespace string
+	Backup    string
+	// Name of the restore, <backup>-restore-<timestamp> when empty
+	Name string
+	// TargetNamespace restores the namespace under another name
+	TargetNamespace string
+}
+
+// VeleroRestore is a Velero restore of a backup
+type VeleroRestore struct {
+	Name             string            `json:"name"`
+	Backup           string            `json:"backup"`
+	Namespace        string            `json:"namespace,omitempty"`
+	NamespaceMapping map[string]string `json:"namespaceMapping,omitempty"`
+	Phase            string            `json:"phase,omitempty"`
+	Started          string            `json:"started,omitempty"`
+	Completed        string            `json:"completed,omitempty"`
+	ItemsRestored    int64             `json:"itemsRestored"`
+	TotalItems       int64             `json:"totalItems"`
+	Errors           int64             `json:"errors"`
+	Warnings         int64             `json:"warnings"`
+	FailureReason    string            `json:"failureReason,omitempty"`
+	ValidationErrors []string          `json:"validationErrors,omitempty"`
+}
+
+// newVeleroRestore reads a Velero Restore object
+func newVeleroRestore(item *unstructured.Unstructured) VeleroRestore {
+	field := func(fields ...string) string {
+		value, _, _ := unstructured.NestedString(item.Object, fields...)
+		return value
+	}
+	count := func(fields ...string) int64 {
+		value, _, _ := unstructured.NestedInt64(item.Object, fields...)
+		return value
+	}
+	restore := VeleroRestore{
+		Name:          item.GetName(),
+		Backup:        field("spec", "backupName"),
+		Phase:         field("status", "phase"),
+		Started:       field("status", "startTimestamp"),
+		Completed:     field("status", "completionTimestamp"),
+		ItemsRestored: count("status", "progress", "itemsRestored"),
+		TotalItems:    count("status", "progress", "totalItems"),
+		Errors:        count("status", "errors"),
+		Warnings:      count("status", "warnings"),
+		FailureReason: field("status", "failureReason"),
+	}
+	if namespaces, _, _ := unstructured.NestedStringSlice(item.Object, "spec", "includedNamespaces"); len(namespaces) > 0 {
+		restore.Namespace = namespaces[0]
+	}
+	restore.NamespaceMapping, _, _ = unstructured.NestedStringMap(item.Object, "spec", "namespaceMapping")
+	restore.ValidationErrors, _, _ = unstructured.NestedStringSlice(item.Object, "status", "validationErrors")
+	return restore
+}
+
+// CreateVeleroRestore starts a Velero restore of the namespace of a cluster
+// from a completed backup, into the namespace or TargetNamespace. Velero
+// skips objects that exist, so the restore is refused while the clusters of
+// the backup still exist in the target namespace.
+func (c *Client) CreateVeleroRestore(ctx context.Context, opts VeleroRestoreOptions) (*VeleroRestore, error) {
+	if opts.VeleroNamespace == "" {
+		opts.VeleroNamespace = DefaultVeleroNamespace
+	}
+	backup, err := c.GetVeleroBackup(ctx, opts.VeleroNamespace, opts.Namespace, opts.Backup)
+	if err != nil {
+		return nil, err
+	}
+	if !backup.Restorable() {
+		return nil, errorf(ErrPreconditionFailed, "backup %s is %s, only completed backups can be restored", backup.Name, VeleroPhase(backup.Phase))
+	}
+	target := opts.Namespace
+	if opts.TargetNamespace != "" {
+		target = opts.TargetNamespace
+	}
+	if backup.Cluster != "" {
+		key := client.ObjectKey{Namespace: target, Name: backup.Cluster}
+		err := c.ctrlClient.Get(ctx, key, &clusterv1.Cluster{})
+		if err == nil {
+			return nil, errorf(ErrPreconditionFailed, "cluster %s/%s exists, Velero would skip its objects; delete it or restore into another namespace", target, backup.Cluster)
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get cluster: %w", resourceError("Cluster", key, err))
+		}
+	}
+	if opts.Name == "" {
+		opts.Name = fmt.Sprintf("%s-restore-%s", backup.Name, time.Now().UTC().Format("20060102150405"))
+	}
+
+	restore := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"backupName":         backup.Name,
+			"includedNamespaces": []any{opts.Namespace},
+		},
+	}}
+	restore.SetGroupVersionKind(veleroRestoreGVK)
+	restore.SetNamespace(opts.VeleroNamespace)
+	restore.SetName(opts.Name)
+	if backup.Cluster != "" {
+		restore.SetLabels(map[string]string{BackupClusterLabel: backup.Cluster, BackupClusterNamespaceLabel: target})
+	}
+	if target != opts.Namespace {
+		_ = unstructured.SetNestedStringMap(restore.Object, map[string]string{opts.Namespace: target}, "spec", "namespaceMapping")
+	}
+	if err := c.ctrlClient.Create(ctx, restore); err != nil {
+		return nil, fmt.Errorf("failed to create restore: %w", veleroError("Restore", client.ObjectKeyFromObject(restore), err))
+	}
+	result := newVeleroRestore(restore)
+	return &result, nil
+}
+
+// GetVeleroRestore returns a Velero restore of a namespace
+func (c *Client) GetVeleroRestore(ctx context.Context, veleroNamespace, namespace, name string) (*VeleroRestore, error) {
+	if veleroNamespace == "" {
+		veleroNamespace = DefaultVeleroNamespace
+	}
+	item := &unstructured.Unstructured{}
+	item.SetGroupVersionKind(veleroRestoreGVK)
+	key := client.ObjectKey{Namespace: veleroNamespace, Name: name}
+	if err := c.ctrlClient.Get(ctx, key, item); err != nil {
+		return nil, fmt.Errorf("failed to get restore: %w", veleroError("Restore", key, err))
+	}
+	restore := newVeleroRestore(item)
+	if restore.Namespace != namespace && restore.NamespaceMapping[restore.Namespace] != namespace {
+		return nil, errorf(ErrNotFound, "restore %s of namespace %s not found", name, namespace)
+	}
+	return &restore, nil
+}
+
+// VeleroPhase names the phase of a Velero backup or restore, New for those
+// Velero has not picked up yet
+func VeleroPhase(phase string) string {
+	if phase == "" {
+		return "New"
+	}
+	return phase
+}
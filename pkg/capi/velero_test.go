@@ -0,0 +1,105 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newTestBackup returns a Velero Backup of namespaces in a phase
+func newTestBackup(name, phase, started string, namespaces ...string) *unstructured.Unstructured {
+	included := make([]any, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		included = append(included, namespace)
+	}
+	backup := &unstructured.Unstructured{Object: map[string]any{
+		"spec":   map[string]any{"includedNamespaces": included},
+		"status": map[string]any{"phase": phase, "startTimestamp": started},
+	}}
+	backup.SetGroupVersionKind(veleroBackupGVK)
+	backup.SetNamespace(DefaultVeleroNamespace)
+	backup.SetName(name)
+	return backup
+}
+
+func TestVeleroBackups(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	completed := newTestBackup("prod-nightly", veleroPhaseCompleted, "2026-10-01T02:00:00Z", "org-acme")
+	completed.SetLabels(map[string]string{BackupClusterLabel: "prod", BackupClusterNamespaceLabel: "org-acme"})
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod"}},
+		completed,
+		newTestBackup("acme-weekly", "InProgress", "2026-10-02T02:00:00Z", "org-acme", "org-globex"),
+		newTestBackup("globex", veleroPhaseCompleted, "2026-10-03T02:00:00Z", "org-globex"),
+	).Build()}
+	ctx := context.Background()
+
+	backup, err := c.CreateVeleroBackup(ctx, VeleroBackupOptions{Namespace: "org-acme", Cluster: "prod", Name: "prod-manual", TTL: 72 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backup.Cluster != "prod" || backup.TTL != "72h0m0s" || len(backup.IncludedNamespaces) != 1 || backup.IncludedNamespaces[0] != "org-acme" {
+		t.Errorf("backup = %+v", backup)
+	}
+	if _, err := c.CreateVeleroBackup(ctx, VeleroBackupOptions{Namespace: "org-acme", Cluster: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("CreateVeleroBackup(missing) error = %v, want ErrNotFound", err)
+	}
+
+	backups, err := c.ListVeleroBackups(ctx, "", "org-acme", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, backup := range backups {
+		names = append(names, backup.Name)
+	}
+	if len(names) != 3 || names[0] != "prod-manual" || names[1] != "acme-weekly" || names[2] != "prod-nightly" {
+		t.Errorf("backups = %v, want those of org-acme newest first", names)
+	}
+	if _, err := c.GetVeleroBackup(ctx, "", "org-acme", "globex"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetVeleroBackup(globex) error = %v, want a backup of another namespace hidden", err)
+	}
+
+	// The cluster still exists, and the weekly backup is not finished
+	for _, opts := range []VeleroRestoreOptions{
+		{Namespace: "org-acme", Backup: "prod-nightly"},
+		{Namespace: "org-acme", Backup: "acme-weekly", TargetNamespace: "org-acme-restored"},
+	} {
+		if _, err := c.CreateVeleroRestore(ctx, opts); !errors.Is(err, ErrPreconditionFailed) {
+			t.Errorf("CreateVeleroRestore(%+v) error = %v, want ErrPreconditionFailed", opts, err)
+		}
+	}
+	restore, err := c.CreateVeleroRestore(ctx, VeleroRestoreOptions{Namespace: "org-acme", Backup: "prod-nightly", Name: "prod-restore", TargetNamespace: "org-acme-restored"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restore.Backup != "prod-nightly" || restore.NamespaceMapping["org-acme"] != "org-acme-restored" {
+		t.Errorf("restore = %+v", restore)
+	}
+	if _, err := c.GetVeleroRestore(ctx, "", "org-acme-restored", "prod-restore"); err != nil {
+		t.Errorf("GetVeleroRestore() error = %v", err)
+	}
+	if _, err := c.GetVeleroRestore(ctx, "", "org-globex", "prod-restore"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetVeleroRestore(org-globex) error = %v, want ErrNotFound", err)
+	}
+
+	stored := &unstructured.Unstructured{}
+	stored.SetGroupVersionKind(veleroRestoreGVK)
+	if err := c.ctrlClient.Get(ctx, client.ObjectKey{Namespace: DefaultVeleroNamespace, Name: "prod-restore"}, stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored.GetLabels()[BackupClusterNamespaceLabel] != "org-acme-restored" {
+		t.Errorf("restore labels = %v", stored.GetLabels())
+	}
+}
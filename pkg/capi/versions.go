@@ -0,0 +1,282 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// VersionSource is where the catalog found a Kubernetes version
+type VersionSource string
+
+const (
+	// VersionSourceRelease versions are shipped by a Giant Swarm release
+	VersionSourceRelease VersionSource = "release"
+	// VersionSourceMachineImage versions have a node image referenced by an
+	// infrastructure machine template
+	VersionSourceMachineImage VersionSource = "machine-image"
+	// VersionSourceInUse versions run on existing clusters
+	VersionSourceInUse VersionSource = "in-use"
+)
+
+// AvailableVersion is a Kubernetes version of the catalog
+type AvailableVersion struct {
+	Version string          `json:"version"`
+	Sources []VersionSource `json:"sources"`
+	// Details name the releases, templates or clusters the version was found in
+	Details []string `json:"details"`
+	// Deprecated is set when every release shipping the version is deprecated
+	Deprecated bool `json:"deprecated,omitempty"`
+	// UpgradeTarget reports whether the cluster of the query can be upgraded
+	// to the version; it is only set when the query names a cluster
+	UpgradeTarget *bool `json:"upgradeTarget,omitempty"`
+}
+
+// VersionQuery selects the versions of a catalog
+type VersionQuery struct {
+	// Provider limits the versions to an infrastructure provider
+	Provider Provider
+	// Namespace limits machine templates and clusters in use to a namespace
+	Namespace string
+	// Cluster, with Namespace, selects the provider of a cluster and marks the
+	// versions it can be upgraded to
+	Cluster string
+}
+
+// VersionCatalog lists the Kubernetes versions available on a management
+// cluster, newest first
+type VersionCatalog struct {
+	Provider       Provider           `json:"provider,omitempty"`
+	Cluster        string             `json:"cluster,omitempty"`
+	CurrentVersion string             `json:"currentVersion,omitempty"`
+	Versions       []AvailableVersion `json:"versions"`
+	// Skipped lists the sources the caller may not read
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// Default returns the newest version shipped by a release or a machine image
+// that is not deprecated, falling back to the newest version in use. It is
+// empty if the catalog found no version at all.
+func (v *VersionCatalog) Default() string {
+	for _, available := range v.Versions {
+		if !available.Deprecated && (available.has(VersionSourceRelease) || available.has(VersionSourceMachineImage)) {
+			return available.Version
+		}
+	}
+	for _, available := range v.Versions {
+		if !available.Deprecated {
+			return available.Version
+		}
+	}
+	return ""
+}
+
+// has reports whether a version was found in source
+func (a *AvailableVersion) has(source VersionSource) bool {
+	for _, s := range a.Sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// releaseGVK is the Giant Swarm release, listing the components of a
+	// platform version
+	releaseGVK = schema.GroupVersionKind{Group: "release.giantswarm.io", Version: "v1alpha1", Kind: "ReleaseList"}
+
+	// machineImageFields locate the node image in the infrastructure machine
+	// templates whose image names carry the Kubernetes version
+	machineImageFields = []struct {
+		provider Provider
+		gvk      schema.GroupVersionKind
+		field    []string
+		parse    func(string) string
+	}{
+		{ProviderVSphere, infraTemplateGVK("VSphereMachineTemplateList"), []string{"spec", "template", "spec", "template"}, versionInName},
+		{ProviderGCP, infraTemplateGVK("GCPMachineTemplateList"), []string{"spec", "template", "spec", "image"}, versionInName},
+		{ProviderAzure, infraTemplateGVK("AzureMachineTemplateList"), []string{"spec", "template", "spec", "image", "marketplace", "version"}, azureImageVersion},
+	}
+
+	// nameVersionPattern matches versions in image names such as
+	// ubuntu-2204-kube-v1.30.2 or capi-ubuntu-1-30-2-1712345678
+	nameVersionPattern = regexp.MustCompile(`(?:^|[^0-9])v?(1)[.-]([0-9]{1,2})[.-]([0-9]{1,2})(?:[^0-9]|$)`)
+	// azureImagePattern matches reference image versions such as 130.2.20240717
+	azureImagePattern = regexp.MustCompile(`^1([0-9]{2})\.([0-9]{1,2})\.[0-9]+$`)
+)
+
+// infraTemplateGVK returns the kind of an infrastructure machine template list
+func infraTemplateGVK(kind string) schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: kind}
+}
+
+// versionInName extracts a Kubernetes version from an image name
+func versionInName(name string) string {
+	match := nameVersionPattern.FindStringSubmatch(name)
+	if match == nil {
+		return ""
+	}
+	return fmt.Sprintf("v%s.%s.%s", match[1], match[2], match[3])
+}
+
+// azureImageVersion converts a CAPZ reference image version to a Kubernetes version
+func azureImageVersion(imageVersion string) string {
+	match := azureImagePattern.FindStringSubmatch(imageVersion)
+	if match == nil {
+		return ""
+	}
+	return fmt.Sprintf("v1.%s.%s", strings.TrimLeft(match[1], "0"), match[2])
+}
+
+// AvailableVersions builds the catalog of Kubernetes versions from the Giant
+// Swarm releases, the node images of infrastructure machine templates and the
+// versions of existing clusters. Sources whose resources are not installed on
+// the management cluster are skipped.
+func (c *Client) AvailableVersions(ctx context.Context, query VersionQuery) (*VersionCatalog, error) {
+	catalog := &VersionCatalog{Provider: query.Provider, Versions: []AvailableVersion{}}
+	if query.Cluster != "" {
+		status, err := c.GetClusterStatus(ctx, query.Namespace, query.Cluster)
+		if err != nil {
+			return nil, err
+		}
+		catalog.Cluster = query.Cluster
+		catalog.CurrentVersion = status.Version
+		if query.Provider == "" {
+			catalog.Provider = status.Provider
+		}
+	}
+	provider := catalog.Provider
+
+	found := make(map[string]*AvailableVersion)
+	var order []string
+	releaseStates := make(map[string][]bool)
+	add := func(v string, source VersionSource, detail string) {
+		parsed, err := version.ParseSemantic(v)
+		if err != nil {
+			return
+		}
+		key := "v" + parsed.String()
+		available, ok := found[key]
+		if !ok {
+			available = &AvailableVersion{Version: key}
+			found[key] = available
+			order = append(order, key)
+		}
+		if !available.has(source) {
+			available.Sources = append(available.Sources, source)
+		}
+		available.Details = append(available.Details, detail)
+	}
+
+	// A source the caller may not read leaves the catalog incomplete rather
+	// than failing it
+	list := func(gvk schema.GroupVersionKind, opts ...client.ListOption) ([]unstructured.Unstructured, error) {
+		items, err := c.listUnstructured(ctx, gvk, opts...)
+		if apierrors.IsForbidden(err) {
+			catalog.Skipped = append(catalog.Skipped, strings.TrimSuffix(gvk.Kind, "List"))
+			return nil, nil
+		}
+		return items, err
+	}
+
+	// Giant Swarm releases name their provider as prefix, e.g. aws-25.0.0
+	releases, err := list(releaseGVK)
+	if err != nil {
+		return nil, err
+	}
+	for _, release := range releases {
+		if provider != "" && provider != ProviderUnknown && !strings.HasPrefix(release.GetName(), string(provider)+"-") {
+			continue
+		}
+		state, _, _ := unstructured.NestedString(release.Object, "spec", "state")
+		components, _, _ := unstructured.NestedSlice(release.Object, "spec", "components")
+		for _, component := range components {
+			fields, ok := component.(map[string]any)
+			if !ok || fields["name"] != "kubernetes" {
+				continue
+			}
+			v, _ := fields["version"].(string)
+			add(v, VersionSourceRelease, "release "+release.GetName())
+			if parsed, err := version.ParseSemantic(v); err == nil {
+				key := "v" + parsed.String()
+				releaseStates[key] = append(releaseStates[key], state == "deprecated")
+			}
+		}
+	}
+
+	for _, images := range machineImageFields {
+		if provider != "" && provider != ProviderUnknown && provider != images.provider {
+			continue
+		}
+		templates, err := list(images.gvk, client.InNamespace(query.Namespace))
+		if err != nil {
+			return nil, err
+		}
+		for _, template := range templates {
+			image, _, _ := unstructured.NestedString(template.Object, images.field...)
+			if v := images.parse(image); v != "" {
+				add(v, VersionSourceMachineImage, fmt.Sprintf("machine image %s (%s/%s)", image, template.GetNamespace(), template.GetName()))
+			}
+		}
+	}
+
+	statuses, err := c.GetClustersStatus(ctx, query.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range statuses.Items {
+		if provider != "" && provider != ProviderUnknown && status.Provider != provider {
+			continue
+		}
+		add(status.Version, VersionSourceInUse, fmt.Sprintf("cluster %s/%s", status.Namespace, status.Name))
+	}
+
+	var current *version.Version
+	if catalog.CurrentVersion != "" {
+		current, _ = version.ParseSemantic(catalog.CurrentVersion)
+	}
+	for _, key := range order {
+		available := found[key]
+		if states := releaseStates[key]; len(states) > 0 {
+			available.Deprecated = true
+			for _, deprecated := range states {
+				available.Deprecated = available.Deprecated && deprecated
+			}
+		}
+		if query.Cluster != "" {
+			target := version.MustParseSemantic(key)
+			upgradeable := current != nil && target.GreaterThan(current) && CheckVersionSkew(catalog.CurrentVersion, target) == nil
+			available.UpgradeTarget = &upgradeable
+		}
+		catalog.Versions = append(catalog.Versions, *available)
+	}
+	sort.SliceStable(catalog.Versions, func(i, j int) bool {
+		return version.MustParseSemantic(catalog.Versions[i].Version).GreaterThan(version.MustParseSemantic(catalog.Versions[j].Version))
+	})
+	return catalog, nil
+}
+
+// listUnstructured lists resources of a kind that may not be installed on the
+// management cluster, returning no items if it is not
+func (c *Client) listUnstructured(ctx context.Context, gvk schema.GroupVersionKind, opts ...client.ListOption) ([]unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk)
+	if err := c.ctrlClient.List(ctx, list, opts...); err != nil {
+		if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list %s: %w", strings.TrimSuffix(gvk.Kind, "List"), err)
+	}
+	return list.Items, nil
+}
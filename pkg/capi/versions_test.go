@@ -0,0 +1,116 @@
+package capi
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAvailableVersions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := controlplanev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	newRelease := func(name, state, kubernetes string) *unstructured.Unstructured {
+		release := &unstructured.Unstructured{Object: map[string]any{
+			"spec": map[string]any{
+				"state": state,
+				"components": []any{
+					map[string]any{"name": "cluster-aws", "version": "2.0.0"},
+					map[string]any{"name": "kubernetes", "version": kubernetes},
+				},
+			},
+		}}
+		release.SetAPIVersion("release.giantswarm.io/v1alpha1")
+		release.SetKind("Release")
+		release.SetName(name)
+		return release
+	}
+	template := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"template": map[string]any{"spec": map[string]any{"template": "ubuntu-2204-kube-v1.31.1"}}},
+	}}
+	template.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta1")
+	template.SetKind("VSphereMachineTemplate")
+	template.SetNamespace("org-a")
+	template.SetName("workers")
+
+	objects := []client.Object{
+		newRelease("aws-25.0.0", "deprecated", "1.29.4"),
+		newRelease("aws-26.0.0", "active", "1.30.2"),
+		newRelease("azure-26.0.0", "active", "1.30.3"),
+		template,
+		&clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "org-a"},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{Kind: "AWSCluster", Name: "prod"},
+				ControlPlaneRef:   &corev1.ObjectReference{Kind: "KubeadmControlPlane", Name: "prod-cp"},
+			},
+		},
+		&controlplanev1.KubeadmControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod-cp", Namespace: "org-a"},
+			Spec:       controlplanev1.KubeadmControlPlaneSpec{Version: "v1.29.4"},
+		},
+	}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()}
+	ctx := context.Background()
+
+	catalog, err := c.AvailableVersions(ctx, VersionQuery{Namespace: "org-a", Cluster: "prod"})
+	if err != nil {
+		t.Fatalf("AvailableVersions() error = %v", err)
+	}
+	if catalog.Provider != ProviderAWS || catalog.CurrentVersion != "v1.29.4" {
+		t.Errorf("catalog = %+v, want the provider and version of the cluster", catalog)
+	}
+	if len(catalog.Versions) != 2 || catalog.Versions[0].Version != "v1.30.2" || catalog.Versions[1].Version != "v1.29.4" {
+		t.Fatalf("Versions = %+v, want the AWS releases newest first", catalog.Versions)
+	}
+	if !*catalog.Versions[0].UpgradeTarget || *catalog.Versions[1].UpgradeTarget {
+		t.Errorf("UpgradeTarget = %v, %v, want only the next minor", *catalog.Versions[0].UpgradeTarget, *catalog.Versions[1].UpgradeTarget)
+	}
+	old := catalog.Versions[1]
+	if !old.Deprecated || len(old.Sources) != 2 || old.Sources[1] != VersionSourceInUse {
+		t.Errorf("old version = %+v, want a deprecated release in use", old)
+	}
+	if catalog.Default() != "v1.30.2" {
+		t.Errorf("Default() = %s, want v1.30.2", catalog.Default())
+	}
+
+	catalog, err = c.AvailableVersions(ctx, VersionQuery{Provider: ProviderVSphere})
+	if err != nil {
+		t.Fatalf("AvailableVersions(vsphere) error = %v", err)
+	}
+	if len(catalog.Versions) != 1 || catalog.Versions[0].Version != "v1.31.1" || catalog.Versions[0].Sources[0] != VersionSourceMachineImage {
+		t.Errorf("Versions = %+v, want the machine image", catalog.Versions)
+	}
+}
+
+func TestImageVersions(t *testing.T) {
+	tests := []struct {
+		parse func(string) string
+		image string
+		want  string
+	}{
+		{versionInName, "ubuntu-2204-kube-v1.30.2", "v1.30.2"},
+		{versionInName, "capi-ubuntu-1-29-4-1712345678", "v1.29.4"},
+		{versionInName, "flatcar-stable", ""},
+		{azureImageVersion, "130.2.20240717", "v1.30.2"},
+		{azureImageVersion, "latest", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.parse(tt.image); got != tt.want {
+			t.Errorf("version of %q = %q, want %q", tt.image, got, tt.want)
+		}
+	}
+}
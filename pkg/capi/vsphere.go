@@ -0,0 +1,243 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// vsphereMachineGVK is the infrastructure machine of the vSphere provider
+	vsphereMachineGVK = schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "VSphereMachine"}
+	// vsphereVMGVK is the virtual machine a VSphereMachine creates in vCenter
+	vsphereVMGVK = schema.GroupVersionKind{Group: "infrastructure.cluster.x-k8s.io", Version: "v1beta1", Kind: "VSphereVM"}
+)
+
+// VSphereFailureDomain is a failure domain machines can be placed in
+type VSphereFailureDomain struct {
+	Name         string `json:"name"`
+	ControlPlane bool   `json:"controlPlane"`
+}
+
+// VSphereClusterInfo is the vSphere infrastructure of a cluster, read from its
+// VSphereCluster and the VSphereMachines of the cluster
+type VSphereClusterInfo struct {
+	Name                 string `json:"name"`
+	Server               string `json:"server,omitempty"`
+	Thumbprint           string `json:"thumbprint,omitempty"`
+	ControlPlaneEndpoint string `json:"controlPlaneEndpoint,omitempty"`
+	// Identity is the kind and name of the credentials for vCenter
+	Identity       string                 `json:"identity,omitempty"`
+	VCenterVersion string                 `json:"vCenterVersion,omitempty"`
+	Ready          bool                   `json:"ready"`
+	FailureDomains []VSphereFailureDomain `json:"failureDomains"`
+	// Datacenters are those the machines of the cluster are placed in, as
+	// VSphereClusters do not name one
+	Datacenters []string `json:"datacenters"`
+}
+
+// VSphereMachineInfo correlates a CAPI Machine with its VSphereMachine and
+// VSphereVM. Machine is empty for VSphereMachines without a Machine, VM for
+// VSphereMachines whose VM was not created yet.
+type VSphereMachineInfo struct {
+	Machine      string `json:"machine,omitempty"`
+	Name         string `json:"name"`
+	VM           string `json:"vm,omitempty"`
+	Node         string `json:"node,omitempty"`
+	Phase        string `json:"phase,omitempty"`
+	ControlPlane bool   `json:"controlPlane"`
+	Template     string `json:"template,omitempty"`
+	Datacenter   string `json:"datacenter,omitempty"`
+	Datastore    string `json:"datastore,omitempty"`
+	ResourcePool string `json:"resourcePool,omitempty"`
+	Folder       string `json:"folder,omitempty"`
+	NumCPUs      int64  `json:"numCPUs,omitempty"`
+	MemoryMiB    int64  `json:"memoryMiB,omitempty"`
+	DiskGiB      int64  `json:"diskGiB,omitempty"`
+	// Host is the ESXi host the VM runs on
+	Host string `json:"host,omitempty"`
+	// PowerState is read from the VM status where the provider reports it,
+	// otherwise ready VMs are poweredOn and others unknown
+	PowerState string   `json:"powerState"`
+	Addresses  []string `json:"addresses,omitempty"`
+	Ready      bool     `json:"ready"`
+	// FailureReason is the reason the VSphereMachine or VSphereVM failed
+	FailureReason string `json:"failureReason,omitempty"`
+}
+
+// isVSphereCluster reports whether a cluster runs on the vSphere provider
+func isVSphereCluster(cluster *clusterv1.Cluster) bool {
+	return ClusterProvider(cluster) == ProviderVSphere
+}
+
+// GetVSphereCluster reads the vSphere infrastructure of a cluster
+func (c *Client) GetVSphereCluster(ctx context.Context, namespace, name string) (*VSphereClusterInfo, error) {
+	cluster, err := c.GetCluster(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if !isVSphereCluster(cluster) {
+		return nil, errorf(ErrInvalidArgument, "cluster %s/%s is not a vSphere cluster", namespace, name)
+	}
+
+	ref := cluster.Spec.InfrastructureRef
+	infra, err := c.getReferenced(ctx, namespace, ref.APIVersion, ref.Kind, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+	obj := infra.Object
+	info := &VSphereClusterInfo{Name: ref.Name, FailureDomains: []VSphereFailureDomain{}, Datacenters: []string{}}
+	info.Server, _, _ = unstructured.NestedString(obj, "spec", "server")
+	info.Thumbprint, _, _ = unstructured.NestedString(obj, "spec", "thumbprint")
+	info.VCenterVersion, _, _ = unstructured.NestedString(obj, "status", "vCenterVersion")
+	info.Ready, _, _ = unstructured.NestedBool(obj, "status", "ready")
+	if host, _, _ := unstructured.NestedString(obj, "spec", "controlPlaneEndpoint", "host"); host != "" {
+		port, _, _ := unstructured.NestedInt64(obj, "spec", "controlPlaneEndpoint", "port")
+		info.ControlPlaneEndpoint = fmt.Sprintf("%s:%d", host, port)
+	}
+	if kind, _, _ := unstructured.NestedString(obj, "spec", "identityRef", "kind"); kind != "" {
+		identity, _, _ := unstructured.NestedString(obj, "spec", "identityRef", "name")
+		info.Identity = kind + "/" + identity
+	}
+	if domains, found, _ := unstructured.NestedMap(obj, "status", "failureDomains"); found {
+		for name, item := range domains {
+			domain, _ := item.(map[string]any)
+			controlPlane, _, _ := unstructured.NestedBool(domain, "controlPlane")
+			info.FailureDomains = append(info.FailureDomains, VSphereFailureDomain{Name: name, ControlPlane: controlPlane})
+		}
+		sort.Slice(info.FailureDomains, func(i, j int) bool { return info.FailureDomains[i].Name < info.FailureDomains[j].Name })
+	}
+
+	machines, err := c.listVSphereMachines(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, machine := range machines {
+		if machine.Datacenter != "" && !slices.Contains(info.Datacenters, machine.Datacenter) {
+			info.Datacenters = append(info.Datacenters, machine.Datacenter)
+		}
+	}
+	sort.Strings(info.Datacenters)
+	return info, nil
+}
+
+// ListVSphereMachines lists the machines of a vSphere cluster with their
+// VSphereMachine and VSphereVM
+func (c *Client) ListVSphereMachines(ctx context.Context, namespace, clusterName string) ([]VSphereMachineInfo, error) {
+	cluster, err := c.GetCluster(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if !isVSphereCluster(cluster) {
+		return nil, errorf(ErrInvalidArgument, "cluster %s/%s is not a vSphere cluster", namespace, clusterName)
+	}
+	return c.listVSphereMachines(ctx, namespace, clusterName)
+}
+
+// listVSphereMachines joins the Machines, VSphereMachines and VSphereVMs of a
+// cluster. VSphereVMs are matched to the VSphereMachine owning them, falling
+// back to the name the provider gives them, which is the one of the
+// VSphereMachine.
+func (c *Client) listVSphereMachines(ctx context.Context, namespace, clusterName string) ([]VSphereMachineInfo, error) {
+	machines, err := c.ListMachines(ctx, namespace, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	selector := client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName}
+	vsphereMachines, err := c.listUnstructured(ctx, vsphereMachineGVK, client.InNamespace(namespace), selector)
+	if err != nil {
+		return nil, err
+	}
+	vms, err := c.listUnstructured(ctx, vsphereVMGVK, client.InNamespace(namespace), selector)
+	if err != nil {
+		return nil, err
+	}
+
+	vmsByMachine := make(map[string]*unstructured.Unstructured, len(vms))
+	for i := range vms {
+		owner := vms[i].GetName()
+		for _, ref := range vms[i].GetOwnerReferences() {
+			if ref.Kind == vsphereMachineGVK.Kind {
+				owner = ref.Name
+			}
+		}
+		vmsByMachine[owner] = &vms[i]
+	}
+	machinesByInfra := make(map[string]*clusterv1.Machine, len(machines.Items))
+	for i := range machines.Items {
+		if ref := machines.Items[i].Spec.InfrastructureRef; ref.Kind == vsphereMachineGVK.Kind {
+			machinesByInfra[ref.Name] = &machines.Items[i]
+		}
+	}
+
+	infos := make([]VSphereMachineInfo, 0, len(vsphereMachines))
+	for i := range vsphereMachines {
+		vsphereMachine := &vsphereMachines[i]
+		info := VSphereMachineInfo{Name: vsphereMachine.GetName(), PowerState: "unknown"}
+		readVSphereMachine(&info, vsphereMachine.Object)
+		if _, ok := vsphereMachine.GetLabels()[clusterv1.MachineControlPlaneLabel]; ok {
+			info.ControlPlane = true
+		}
+		if machine := machinesByInfra[info.Name]; machine != nil {
+			info.Machine = machine.Name
+			info.Phase = GetMachinePhase(machine)
+			if machine.Status.NodeRef != nil {
+				info.Node = machine.Status.NodeRef.Name
+			}
+			_, info.ControlPlane = machine.Labels[clusterv1.MachineControlPlaneLabel]
+		}
+		if vm := vmsByMachine[info.Name]; vm != nil {
+			readVSphereVM(&info, vm.Object)
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// readVSphereMachine fills info from a VSphereMachine
+func readVSphereMachine(info *VSphereMachineInfo, obj map[string]any) {
+	info.Template, _, _ = unstructured.NestedString(obj, "spec", "template")
+	info.Datacenter, _, _ = unstructured.NestedString(obj, "spec", "datacenter")
+	info.Datastore, _, _ = unstructured.NestedString(obj, "spec", "datastore")
+	info.ResourcePool, _, _ = unstructured.NestedString(obj, "spec", "resourcePool")
+	info.Folder, _, _ = unstructured.NestedString(obj, "spec", "folder")
+	info.NumCPUs, _, _ = unstructured.NestedInt64(obj, "spec", "numCPUs")
+	info.MemoryMiB, _, _ = unstructured.NestedInt64(obj, "spec", "memoryMiB")
+	info.DiskGiB, _, _ = unstructured.NestedInt64(obj, "spec", "diskGiB")
+	info.Ready, _, _ = unstructured.NestedBool(obj, "status", "ready")
+	info.FailureReason, _, _ = unstructured.NestedString(obj, "status", "failureReason")
+	addresses, _, _ := unstructured.NestedSlice(obj, "status", "addresses")
+	for _, item := range addresses {
+		entry, _ := item.(map[string]any)
+		if address, _, _ := unstructured.NestedString(entry, "address"); address != "" {
+			info.Addresses = append(info.Addresses, address)
+		}
+	}
+}
+
+// readVSphereVM fills info from a VSphereVM, whose placement and addresses
+// reflect what vCenter reports
+func readVSphereVM(info *VSphereMachineInfo, obj map[string]any) {
+	info.VM, _, _ = unstructured.NestedString(obj, "metadata", "name")
+	info.Host, _, _ = unstructured.NestedString(obj, "status", "host")
+	if addresses, _, _ := unstructured.NestedStringSlice(obj, "status", "addresses"); len(addresses) > 0 {
+		info.Addresses = addresses
+	}
+	if reason, _, _ := unstructured.NestedString(obj, "status", "failureReason"); reason != "" {
+		info.FailureReason = reason
+	}
+	ready, _, _ := unstructured.NestedBool(obj, "status", "ready")
+	switch state, _, _ := unstructured.NestedString(obj, "status", "powerState"); {
+	case state != "":
+		info.PowerState = state
+	case ready:
+		info.PowerState = "poweredOn"
+	}
+}
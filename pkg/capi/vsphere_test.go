@@ -0,0 +1,110 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestVSphereCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: "prod"},
+		Spec: clusterv1.ClusterSpec{
+			InfrastructureRef: &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "VSphereCluster", Name: "prod"},
+		},
+	}
+	vsphereCluster := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{
+			"server":               "vcenter.example.com",
+			"controlPlaneEndpoint": map[string]any{"host": "10.0.0.10", "port": int64(6443)},
+			"identityRef":          map[string]any{"kind": "Secret", "name": "prod-credentials"},
+		},
+		"status": map[string]any{"ready": true, "vCenterVersion": "8.0.2"},
+	}}
+	vsphereCluster.SetAPIVersion("infrastructure.cluster.x-k8s.io/v1beta1")
+	vsphereCluster.SetKind("VSphereCluster")
+	vsphereCluster.SetNamespace("org-a")
+	vsphereCluster.SetName("prod")
+
+	labels := map[string]string{clusterv1.ClusterNameLabel: "prod"}
+	newVSphereMachine := func(name string, ready bool) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]any{
+			"spec": map[string]any{
+				"template": "ubuntu-2204-kube-v1.30.2", "datacenter": "dc1", "datastore": "ds1",
+				"resourcePool": "prod-pool", "numCPUs": int64(4), "memoryMiB": int64(8192), "diskGiB": int64(40),
+			},
+			"status": map[string]any{"ready": ready},
+		}}
+		obj.SetGroupVersionKind(vsphereMachineGVK)
+		obj.SetNamespace("org-a")
+		obj.SetName(name)
+		obj.SetLabels(labels)
+		return obj
+	}
+	vm := &unstructured.Unstructured{Object: map[string]any{
+		"status": map[string]any{"ready": true, "host": "esxi-01", "addresses": []any{"10.0.0.21"}},
+	}}
+	vm.SetGroupVersionKind(vsphereVMGVK)
+	vm.SetNamespace("org-a")
+	vm.SetName("prod-md-abc")
+	vm.SetLabels(labels)
+	vm.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "VSphereMachine", Name: "prod-md-abc", UID: "1"}})
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "org-a", Name: "prod-md-abc-x", Labels: labels},
+		Spec: clusterv1.MachineSpec{
+			ClusterName:       "prod",
+			InfrastructureRef: corev1.ObjectReference{Kind: "VSphereMachine", Name: "prod-md-abc"},
+		},
+		Status: clusterv1.MachineStatus{Phase: "Running", NodeRef: &corev1.ObjectReference{Name: "prod-md-abc"}},
+	}
+	objects := []client.Object{cluster, vsphereCluster, machine, vm, newVSphereMachine("prod-md-abc", true), newVSphereMachine("prod-md-orphan", false)}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()}
+	ctx := context.Background()
+
+	info, err := c.GetVSphereCluster(ctx, "org-a", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Server != "vcenter.example.com" || info.ControlPlaneEndpoint != "10.0.0.10:6443" || info.Identity != "Secret/prod-credentials" || !info.Ready {
+		t.Errorf("info = %+v", info)
+	}
+	if len(info.Datacenters) != 1 || info.Datacenters[0] != "dc1" {
+		t.Errorf("datacenters = %v, want the one of the machines", info.Datacenters)
+	}
+
+	machines, err := c.ListVSphereMachines(ctx, "org-a", "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(machines) != 2 {
+		t.Fatalf("machines = %+v, want 2", machines)
+	}
+	running := machines[0]
+	if running.Machine != "prod-md-abc-x" || running.Node != "prod-md-abc" || running.VM != "prod-md-abc" || running.Host != "esxi-01" || running.PowerState != "poweredOn" {
+		t.Errorf("running machine = %+v", running)
+	}
+	if running.ResourcePool != "prod-pool" || running.NumCPUs != 4 || len(running.Addresses) != 1 {
+		t.Errorf("running machine placement = %+v", running)
+	}
+	if orphan := machines[1]; orphan.Machine != "" || orphan.VM != "" || orphan.PowerState != "unknown" {
+		t.Errorf("orphan = %+v, want no Machine nor VM", orphan)
+	}
+
+	if _, err := c.ListVSphereMachines(ctx, "org-a", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ListVSphereMachines() of a missing cluster error = %v, want ErrNotFound", err)
+	}
+}
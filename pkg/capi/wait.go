@@ -0,0 +1,293 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/watch"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultWaitPollInterval is the first interval of the polling fallback
+	DefaultWaitPollInterval = time.Second
+	// DefaultWaitMaxPollInterval caps the backoff of the polling fallback. While
+	// a watch is established, the object is also checked at this interval.
+	DefaultWaitMaxPollInterval = 30 * time.Second
+)
+
+// WaitProgress reports the state of an object being waited for
+type WaitProgress struct {
+	Kind      string        `json:"kind"`
+	Namespace string        `json:"namespace"`
+	Name      string        `json:"name"`
+	Ready     bool          `json:"ready"`
+	Message   string        `json:"message"`
+	Elapsed   time.Duration `json:"elapsed"`
+}
+
+// WaitOptions configures the WaitFor methods
+type WaitOptions struct {
+	// Timeout limits the wait in addition to the deadline of the context;
+	// zero waits until the context ends
+	Timeout time.Duration
+	// OnProgress is called with the initial state of the object and whenever
+	// its state changes
+	OnProgress func(WaitProgress)
+	// PollInterval and MaxPollInterval bound the exponential backoff used
+	// when the object cannot be watched; they default to
+	// DefaultWaitPollInterval and DefaultWaitMaxPollInterval
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration
+}
+
+// readiness reports whether an object is ready and describes its state
+type readiness func(ctx context.Context) (bool, string, error)
+
+// WaitForClusterReady waits until a cluster has the Ready condition
+func (c *Client) WaitForClusterReady(ctx context.Context, namespace, name string, opts WaitOptions) error {
+	return c.waitFor(ctx, "Cluster", namespace, name, &clusterv1.ClusterList{}, opts, func(ctx context.Context) (bool, string, error) {
+		cluster, err := c.GetCluster(ctx, namespace, name)
+		if err != nil {
+			return false, "", err
+		}
+		return clusterReady(cluster), conditionsMessage(cluster.Status.Phase, cluster.Status.Conditions), nil
+	})
+}
+
+// WaitForMachineDeploymentReady waits until all replicas of a machine
+// deployment are updated to its current spec and available
+func (c *Client) WaitForMachineDeploymentReady(ctx context.Context, namespace, name string, opts WaitOptions) error {
+	return c.waitFor(ctx, "MachineDeployment", namespace, name, &clusterv1.MachineDeploymentList{}, opts, func(ctx context.Context) (bool, string, error) {
+		md, err := c.GetMachineDeployment(ctx, namespace, name)
+		if err != nil {
+			return false, "", err
+		}
+		desired := int32(1)
+		if md.Spec.Replicas != nil {
+			desired = *md.Spec.Replicas
+		}
+		status := md.Status
+		ready := status.ObservedGeneration >= md.Generation &&
+			status.Replicas == desired &&
+			status.UpdatedReplicas == desired &&
+			status.AvailableReplicas == desired &&
+			status.UnavailableReplicas == 0
+		message := fmt.Sprintf("phase %s, %d/%d replicas updated, %d/%d available",
+			orUnknown(status.Phase), status.UpdatedReplicas, desired, status.AvailableReplicas, desired)
+		return ready, message, nil
+	})
+}
+
+// WaitForControlPlaneReady waits until the KubeadmControlPlane of a cluster is
+// ready with all replicas updated to its current spec
+func (c *Client) WaitForControlPlaneReady(ctx context.Context, namespace, clusterName string, opts WaitOptions) error {
+	cluster, err := c.GetCluster(ctx, namespace, clusterName)
+	if err != nil {
+		return err
+	}
+	ref := cluster.Spec.ControlPlaneRef
+	if ref == nil {
+		return errorf(ErrPreconditionFailed, "cluster %s/%s has no control plane reference", namespace, clusterName)
+	}
+	if ref.Kind != "KubeadmControlPlane" {
+		return errorf(ErrPreconditionFailed, "unsupported control plane type: %s", ref.Kind)
+	}
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+
+	return c.waitFor(ctx, "KubeadmControlPlane", namespace, ref.Name, &controlplanev1.KubeadmControlPlaneList{}, opts, func(ctx context.Context) (bool, string, error) {
+		kcp, err := c.GetKubeadmControlPlane(ctx, namespace, ref.Name)
+		if err != nil {
+			return false, "", err
+		}
+		desired := int32(1)
+		if kcp.Spec.Replicas != nil {
+			desired = *kcp.Spec.Replicas
+		}
+		status := kcp.Status
+		ready := controlPlaneReady(kcp) &&
+			status.ObservedGeneration >= kcp.Generation &&
+			status.Replicas == desired &&
+			status.UpdatedReplicas == desired &&
+			status.ReadyReplicas == desired &&
+			status.UnavailableReplicas == 0
+		message := fmt.Sprintf("version %s, %d/%d replicas updated, %d/%d ready",
+			orUnknown(kcp.Spec.Version), status.UpdatedReplicas, desired, status.ReadyReplicas, desired)
+		return ready, message, nil
+	})
+}
+
+// waitFor checks an object until it is ready. Changes are picked up through a
+// watch on list, whose items are the objects of the kind; when the watch is
+// not available, the object is polled with exponential backoff instead.
+// Errors reading the object are retried, except for errors that cannot
+// resolve by themselves, such as a missing object or missing permissions.
+func (c *Client) waitFor(ctx context.Context, kind, namespace, name string, list client.ObjectList, opts WaitOptions, check readiness) error {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultWaitPollInterval
+	}
+	if opts.MaxPollInterval <= 0 {
+		opts.MaxPollInterval = DefaultWaitMaxPollInterval
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	events := c.watchObject(ctx, namespace, name, list)
+	interval := opts.PollInterval
+	lastMessage := ""
+	reported := false
+	for {
+		ready, message, err := check(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			if permanentWaitError(err) {
+				return err
+			}
+			message = fmt.Sprintf("failed to read %s: %v", kind, err)
+		case err == nil && (!reported || message != lastMessage || ready):
+			if opts.OnProgress != nil {
+				opts.OnProgress(WaitProgress{
+					Kind:      kind,
+					Namespace: namespace,
+					Name:      name,
+					Ready:     ready,
+					Message:   message,
+					Elapsed:   time.Since(start),
+				})
+			}
+			reported = true
+		}
+		if err == nil && ready {
+			return nil
+		}
+		if message != "" {
+			lastMessage = message
+		}
+
+		// Without a watch or after an error, back off exponentially; otherwise
+		// wait for the next event and check at the maximum interval in case an
+		// event was missed
+		delay := interval
+		if events != nil && err == nil {
+			delay = opts.MaxPollInterval
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s %s/%s is not ready after %s (%s): %w", kind, namespace, name, time.Since(start).Round(time.Second), lastMessage, ctx.Err())
+		case _, ok := <-events:
+			if !ok {
+				events = nil
+			}
+		case <-timer.C:
+			interval = min(2*interval, opts.MaxPollInterval)
+		}
+		timer.Stop()
+	}
+}
+
+// watchObject returns a channel receiving a value whenever the named object
+// changes, or nil if the object cannot be watched. The channel is closed when
+// the watch ends.
+func (c *Client) watchObject(ctx context.Context, namespace, name string, list client.ObjectList) <-chan struct{} {
+	watcher, ok := c.ctrlClient.(client.WithWatch)
+	if !ok {
+		if c.config == nil {
+			return nil
+		}
+		var err error
+		watcher, err = client.NewWithWatch(c.config, client.Options{Scheme: c.ctrlClient.Scheme(), Mapper: c.ctrlClient.RESTMapper()})
+		if err != nil {
+			return nil
+		}
+	}
+
+	w, err := watcher.Watch(ctx, list, client.InNamespace(namespace))
+	if err != nil {
+		return nil
+	}
+
+	events := make(chan struct{}, 1)
+	go func() {
+		defer close(events)
+		defer w.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-w.ResultChan():
+				if !ok || event.Type == watch.Error {
+					return
+				}
+				if obj, isObject := event.Object.(client.Object); !isObject || obj.GetName() != name {
+					continue
+				}
+				// Coalesce events arriving while the object is being checked
+				select {
+				case events <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return events
+}
+
+// permanentWaitError reports whether waiting longer cannot resolve an error
+func permanentWaitError(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) ||
+		apierrors.IsNotFound(err) || apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err)
+}
+
+// negativePolarityConditions are v1beta2 conditions that are false when all is
+// well, for objects whose v1beta1 conditions are no longer reported
+var negativePolarityConditions = map[clusterv1.ConditionType]bool{
+	clusterv1.DeletingV1Beta2Condition:    true,
+	clusterv1.PausedV1Beta2Condition:      true,
+	clusterv1.RollingOutV1Beta2Condition:  true,
+	clusterv1.ScalingUpV1Beta2Condition:   true,
+	clusterv1.ScalingDownV1Beta2Condition: true,
+	clusterv1.RemediatingV1Beta2Condition: true,
+}
+
+// conditionsMessage summarizes a phase and the conditions that are not true
+func conditionsMessage(phase string, conds clusterv1.Conditions) string {
+	var pending []string
+	for _, condition := range conds {
+		if condition.Status == corev1.ConditionTrue ||
+			(condition.Status == corev1.ConditionFalse && negativePolarityConditions[condition.Type]) {
+			continue
+		}
+		description := fmt.Sprintf("%s=%s", condition.Type, condition.Status)
+		if condition.Reason != "" {
+			description += fmt.Sprintf(" (%s)", condition.Reason)
+		}
+		pending = append(pending, description)
+	}
+	message := "phase " + orUnknown(phase)
+	if len(pending) > 0 {
+		message += ", " + strings.Join(pending, ", ")
+	}
+	return message
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "Unknown"
+	}
+	return value
+}
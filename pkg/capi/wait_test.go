@@ -0,0 +1,104 @@
+package capi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWaitForClusterReady(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "default"},
+		Status: clusterv1.ClusterStatus{
+			Phase:      "Provisioning",
+			Conditions: clusterv1.Conditions{{Type: clusterv1.ReadyCondition, Status: corev1.ConditionFalse, Reason: "WaitingForControlPlane"}},
+		},
+	}
+	ctrlClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster).Build()
+	c := &Client{ctrlClient: ctrlClient}
+
+	var mu sync.Mutex
+	var progress []WaitProgress
+	done := make(chan error, 1)
+	go func() {
+		done <- c.WaitForClusterReady(context.Background(), "default", "prod", WaitOptions{
+			Timeout: 10 * time.Second,
+			OnProgress: func(p WaitProgress) {
+				mu.Lock()
+				defer mu.Unlock()
+				progress = append(progress, p)
+			},
+		})
+	}()
+
+	// Let the wait report the initial state before the cluster becomes ready
+	time.Sleep(50 * time.Millisecond)
+	current := &clusterv1.Cluster{}
+	if err := ctrlClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), current); err != nil {
+		t.Fatal(err)
+	}
+	current.Status.Phase = "Provisioned"
+	current.Status.Conditions = clusterv1.Conditions{{Type: clusterv1.ReadyCondition, Status: corev1.ConditionTrue}}
+	if err := ctrlClient.Update(context.Background(), current); err != nil {
+		t.Fatal(err)
+	}
+
+	// The watch must pick up the change well before the polling interval
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("WaitForClusterReady() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WaitForClusterReady() did not notice the cluster becoming ready")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(progress) != 2 {
+		t.Fatalf("expected 2 progress reports, got %+v", progress)
+	}
+	if progress[0].Ready || progress[0].Message != "phase Provisioning, Ready=False (WaitingForControlPlane)" {
+		t.Errorf("unexpected initial progress: %+v", progress[0])
+	}
+	if !progress[1].Ready {
+		t.Errorf("unexpected final progress: %+v", progress[1])
+	}
+}
+
+func TestWaitForMachineDeploymentReadyTimeout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	replicas := int32(3)
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: "default"},
+		Spec:       clusterv1.MachineDeploymentSpec{Replicas: &replicas},
+		Status:     clusterv1.MachineDeploymentStatus{Replicas: 3, UpdatedReplicas: 1, AvailableReplicas: 3},
+	}
+	c := &Client{ctrlClient: fake.NewClientBuilder().WithScheme(scheme).WithObjects(md).Build()}
+
+	err := c.WaitForMachineDeploymentReady(context.Background(), "default", "workers", WaitOptions{Timeout: 50 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForMachineDeploymentReady() error = %v, want a deadline error", err)
+	}
+
+	err = c.WaitForMachineDeploymentReady(context.Background(), "default", "missing", WaitOptions{Timeout: 5 * time.Second})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("WaitForMachineDeploymentReady() of a missing object error = %v, want ErrNotFound", err)
+	}
+}
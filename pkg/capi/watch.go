@@ -0,0 +1,180 @@
+package capi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	toolscache "k8s.io/client-go/tools/cache"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// watchSyncTimeout limits the time to list the watched resources initially
+const watchSyncTimeout = time.Minute
+
+// ResourceChangeType tells how a watched object changed
+type ResourceChangeType string
+
+const (
+	ResourceAdded   ResourceChangeType = "Added"
+	ResourceUpdated ResourceChangeType = "Updated"
+	ResourceDeleted ResourceChangeType = "Deleted"
+)
+
+// ResourceChange describes a change of a Cluster, Machine or
+// MachineDeployment that is relevant to readers of its state
+type ResourceChange struct {
+	Type      ResourceChangeType
+	Kind      string
+	Namespace string
+	Name      string
+	// ClusterName is the cluster the object belongs to
+	ClusterName string
+}
+
+// WatchResources calls onChange whenever a Cluster, Machine or
+// MachineDeployment is created, deleted, starts deleting or changes its phase
+// or conditions, until ctx is canceled. Updates that only touch other fields,
+// such as periodic status heartbeats, are not reported. WatchResources returns
+// once the watches are established.
+func (c *Client) WatchResources(ctx context.Context, onChange func(ResourceChange)) error {
+	informers, err := cache.New(c.config, cache.Options{Scheme: c.ctrlClient.Scheme()})
+	if err != nil {
+		return fmt.Errorf("failed to create watch cache: %w", err)
+	}
+
+	// Management clusters that only serve v1beta2 are watched through
+	// unstructured objects, converted before they are compared
+	cc, _ := c.ctrlClient.(*contractClient)
+	for _, typed := range []client.Object{&clusterv1.Cluster{}, &clusterv1.Machine{}, &clusterv1.MachineDeployment{}} {
+		obj := typed
+		convert := func(obj any) any { return obj }
+		if cc != nil {
+			if gvk, ok := cc.convertedKind(ctx, typed); ok {
+				u := &unstructured.Unstructured{}
+				u.SetGroupVersionKind(gvk)
+				obj = u
+				convert = func(obj any) any { return cc.fromWatch(obj, typed) }
+			}
+		}
+		informer, err := informers.GetInformer(ctx, obj)
+		if err != nil {
+			return fmt.Errorf("failed to watch %T: %w", typed, err)
+		}
+		_, err = informer.AddEventHandler(toolscache.ResourceEventHandlerDetailedFuncs{
+			AddFunc: func(obj any, isInInitialList bool) {
+				if isInInitialList {
+					return
+				}
+				if change, ok := newResourceChange(ResourceAdded, convert(obj)); ok {
+					onChange(change)
+				}
+			},
+			UpdateFunc: func(oldObj, newObj any) {
+				oldObj, newObj = convert(oldObj), convert(newObj)
+				if !relevantUpdate(oldObj, newObj) {
+					return
+				}
+				if change, ok := newResourceChange(ResourceUpdated, newObj); ok {
+					onChange(change)
+				}
+			},
+			DeleteFunc: func(obj any) {
+				if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				if change, ok := newResourceChange(ResourceDeleted, convert(obj)); ok {
+					onChange(change)
+				}
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to watch %T: %w", typed, err)
+		}
+	}
+
+	// Stop the informers when the initial sync fails, e.g. for lack of the
+	// watch permission, instead of retrying in the background forever
+	watchCtx, stop := context.WithCancel(ctx)
+	go func() {
+		_ = informers.Start(watchCtx)
+		stop()
+	}()
+
+	syncCtx, cancel := context.WithTimeout(watchCtx, watchSyncTimeout)
+	defer cancel()
+	if !informers.WaitForCacheSync(syncCtx) {
+		stop()
+		return fmt.Errorf("failed to sync watches of CAPI resources within %s", watchSyncTimeout)
+	}
+	return nil
+}
+
+// newResourceChange describes a change of a watched object
+func newResourceChange(changeType ResourceChangeType, obj any) (ResourceChange, bool) {
+	change := ResourceChange{Type: changeType}
+	switch o := obj.(type) {
+	case *clusterv1.Cluster:
+		change.Kind, change.ClusterName = "Cluster", o.Name
+	case *clusterv1.Machine:
+		change.Kind, change.ClusterName = "Machine", o.Spec.ClusterName
+	case *clusterv1.MachineDeployment:
+		change.Kind, change.ClusterName = "MachineDeployment", o.Spec.ClusterName
+	default:
+		return ResourceChange{}, false
+	}
+	object := obj.(client.Object)
+	change.Namespace, change.Name = object.GetNamespace(), object.GetName()
+	return change, true
+}
+
+// relevantUpdate reports whether an update changes the phase, the
+// conditions or the deletion state of an object
+func relevantUpdate(oldObj, newObj any) bool {
+	oldPhase, oldConditions, ok := phaseAndConditions(oldObj)
+	if !ok {
+		return false
+	}
+	newPhase, newConditions, ok := phaseAndConditions(newObj)
+	if !ok {
+		return false
+	}
+	if oldPhase != newPhase || len(oldConditions) != len(newConditions) {
+		return true
+	}
+	if (oldObj.(client.Object).GetDeletionTimestamp() == nil) != (newObj.(client.Object).GetDeletionTimestamp() == nil) {
+		return true
+	}
+
+	for _, condition := range newConditions {
+		old := findCondition(oldConditions, condition.Type)
+		if old == nil || old.Status != condition.Status || old.Reason != condition.Reason || old.Severity != condition.Severity {
+			return true
+		}
+	}
+	return false
+}
+
+func phaseAndConditions(obj any) (string, clusterv1.Conditions, bool) {
+	switch o := obj.(type) {
+	case *clusterv1.Cluster:
+		return o.Status.Phase, o.Status.Conditions, true
+	case *clusterv1.Machine:
+		return o.Status.Phase, o.Status.Conditions, true
+	case *clusterv1.MachineDeployment:
+		return o.Status.Phase, o.Status.Conditions, true
+	}
+	return "", nil, false
+}
+
+func findCondition(conditions clusterv1.Conditions, conditionType clusterv1.ConditionType) *clusterv1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
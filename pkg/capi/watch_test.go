@@ -0,0 +1,47 @@
+package capi
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+func TestRelevantUpdate(t *testing.T) {
+	machine := func(phase string, ready corev1.ConditionStatus) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "org-acme", Name: "prod-md-0-abc"},
+			Spec:       clusterv1.MachineSpec{ClusterName: "prod"},
+			Status: clusterv1.MachineStatus{
+				Phase: phase,
+				Conditions: clusterv1.Conditions{{
+					Type:               clusterv1.ReadyCondition,
+					Status:             ready,
+					LastTransitionTime: metav1.Now(),
+				}},
+			},
+		}
+	}
+
+	if relevantUpdate(machine("Running", corev1.ConditionTrue), machine("Running", corev1.ConditionTrue)) {
+		t.Error("an update without phase or condition changes should not be reported")
+	}
+	if !relevantUpdate(machine("Provisioning", corev1.ConditionFalse), machine("Running", corev1.ConditionFalse)) {
+		t.Error("a phase change should be reported")
+	}
+	if !relevantUpdate(machine("Running", corev1.ConditionTrue), machine("Running", corev1.ConditionFalse)) {
+		t.Error("a condition change should be reported")
+	}
+
+	deleting := machine("Running", corev1.ConditionTrue)
+	deleting.DeletionTimestamp = &metav1.Time{}
+	if !relevantUpdate(machine("Running", corev1.ConditionTrue), deleting) {
+		t.Error("the start of a deletion should be reported")
+	}
+
+	change, ok := newResourceChange(ResourceUpdated, deleting)
+	if !ok || change.Kind != "Machine" || change.ClusterName != "prod" || change.Name != "prod-md-0-abc" {
+		t.Errorf("unexpected change %+v", change)
+	}
+}
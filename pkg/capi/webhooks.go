@@ -0,0 +1,350 @@
+package capi
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// certificateExpiryWarning is how long before expiry certificates are
+// reported, leaving time to fix a cert-manager that stopped renewing them
+const certificateExpiryWarning = 7 * 24 * time.Hour
+
+// WebhookServiceCheck is the health of the service behind the webhooks of a
+// webhook configuration
+type WebhookServiceCheck struct {
+	Configuration string `json:"configuration"`
+	// Type is validating or mutating
+	Type     string   `json:"type"`
+	Provider string   `json:"provider,omitempty"`
+	Webhooks []string `json:"webhooks"`
+	// Service is the namespace/name of the service the webhooks call
+	Service string `json:"service"`
+	// ReadyEndpoints counts the ready endpoints of the service
+	ReadyEndpoints int `json:"readyEndpoints"`
+	// CAExpires is when the first CA certificate of the CA bundle expires
+	CAExpires *time.Time `json:"caExpires,omitempty"`
+	// ServingCertExpires is when the serving certificate mounted by the
+	// controller expires
+	ServingCertExpires *time.Time `json:"servingCertExpires,omitempty"`
+	Healthy            bool       `json:"healthy"`
+	Issues             []string   `json:"issues"`
+}
+
+// WebhookDryRun is the result of creating a canary object in dry-run mode,
+// which calls the admission webhooks of its kind without persisting it
+type WebhookDryRun struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	// Reachable reports whether the API server could call the webhooks; a
+	// rejection by a webhook still proves it is reachable
+	Reachable bool   `json:"reachable"`
+	Message   string `json:"message"`
+}
+
+// WebhookHealth is the result of CheckWebhooks
+type WebhookHealth struct {
+	Services []WebhookServiceCheck `json:"services"`
+	DryRun   *WebhookDryRun        `json:"dryRun,omitempty"`
+	Healthy  bool                  `json:"healthy"`
+	Issues   []string              `json:"issues"`
+}
+
+// CheckWebhooks exercises the admission webhooks of the providers: the CA
+// bundles and serving certificates are parsed and verified, the services they
+// call must have ready endpoints and, with dryRun, a canary Cluster is created
+// in namespace with dry run, which fails with errors like "connection
+// refused" when the API server cannot reach the webhooks.
+func (c *Client) CheckWebhooks(ctx context.Context, namespace string, dryRun bool) (*WebhookHealth, error) {
+	health := &WebhookHealth{Services: []WebhookServiceCheck{}, Issues: []string{}}
+	now := time.Now()
+
+	validating, err := c.k8sClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{LabelSelector: clusterv1.ProviderNameLabel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list validating webhook configurations: %w", err)
+	}
+	for _, config := range validating.Items {
+		var webhooks []webhookClient
+		for _, webhook := range config.Webhooks {
+			webhooks = append(webhooks, webhookClient{name: webhook.Name, config: webhook.ClientConfig})
+		}
+		checks, err := c.checkWebhookServices(ctx, "validating", config.ObjectMeta, webhooks, now)
+		if err != nil {
+			return nil, err
+		}
+		health.Services = append(health.Services, checks...)
+	}
+	mutating, err := c.k8sClient.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{LabelSelector: clusterv1.ProviderNameLabel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list mutating webhook configurations: %w", err)
+	}
+	for _, config := range mutating.Items {
+		var webhooks []webhookClient
+		for _, webhook := range config.Webhooks {
+			webhooks = append(webhooks, webhookClient{name: webhook.Name, config: webhook.ClientConfig})
+		}
+		checks, err := c.checkWebhookServices(ctx, "mutating", config.ObjectMeta, webhooks, now)
+		if err != nil {
+			return nil, err
+		}
+		health.Services = append(health.Services, checks...)
+	}
+	if len(health.Services) == 0 {
+		health.Issues = append(health.Issues, "no webhook configuration labeled with cluster.x-k8s.io/provider found")
+	}
+	for _, check := range health.Services {
+		for _, issue := range check.Issues {
+			health.Issues = append(health.Issues, fmt.Sprintf("%s webhooks of %s (%s): %s", check.Type, check.Configuration, check.Service, issue))
+		}
+	}
+
+	if dryRun {
+		health.DryRun = c.dryRunCanary(ctx, namespace)
+		if !health.DryRun.Reachable {
+			health.Issues = append(health.Issues, fmt.Sprintf("dry-run create of a %s failed: %s", health.DryRun.Kind, health.DryRun.Message))
+		}
+	}
+
+	health.Healthy = len(health.Issues) == 0
+	return health, nil
+}
+
+// webhookClient is a webhook of a validating or mutating configuration
+type webhookClient struct {
+	name   string
+	config admissionregistrationv1.WebhookClientConfig
+}
+
+// checkWebhookServices checks the services called by the webhooks of a
+// configuration, grouping the webhooks sharing a service and CA bundle
+func (c *Client) checkWebhookServices(ctx context.Context, webhookType string, meta metav1.ObjectMeta, webhooks []webhookClient, now time.Time) ([]WebhookServiceCheck, error) {
+	byService := make(map[string]*WebhookServiceCheck)
+	caBundles := make(map[string][]byte)
+	var services []string
+	for _, webhook := range webhooks {
+		service := "-"
+		if ref := webhook.config.Service; ref != nil {
+			service = ref.Namespace + "/" + ref.Name
+		}
+		check, ok := byService[service]
+		if !ok {
+			check = &WebhookServiceCheck{
+				Configuration: meta.Name,
+				Type:          webhookType,
+				Provider:      meta.Labels[clusterv1.ProviderNameLabel],
+				Service:       service,
+				Issues:        []string{},
+			}
+			byService[service] = check
+			services = append(services, service)
+		}
+		check.Webhooks = append(check.Webhooks, webhook.name)
+		if len(webhook.config.CABundle) == 0 {
+			check.Issues = append(check.Issues, fmt.Sprintf("webhook %s has no CA bundle, check the cert-manager CA injector", webhook.name))
+		} else if _, ok := caBundles[service]; !ok {
+			caBundles[service] = webhook.config.CABundle
+		}
+	}
+	sort.Strings(services)
+
+	checks := make([]WebhookServiceCheck, 0, len(services))
+	for _, service := range services {
+		check := byService[service]
+		namespace, name, _ := strings.Cut(service, "/")
+		roots := checkCABundle(check, caBundles[service], now)
+		if service != "-" {
+			if err := c.checkWebhookService(ctx, check, namespace, name, roots, now); err != nil {
+				return nil, err
+			}
+		}
+		check.Healthy = len(check.Issues) == 0
+		checks = append(checks, *check)
+	}
+	return checks, nil
+}
+
+// checkCABundle parses a CA bundle and records its expiry, returning the
+// certificates to verify the serving certificate with
+func checkCABundle(check *WebhookServiceCheck, bundle []byte, now time.Time) *x509.CertPool {
+	if len(bundle) == 0 {
+		return nil
+	}
+	certs := parseCertificates(bundle)
+	if len(certs) == 0 {
+		check.Issues = append(check.Issues, "the CA bundle holds no valid certificate")
+		return nil
+	}
+	roots := x509.NewCertPool()
+	for _, cert := range certs {
+		roots.AddCert(cert)
+	}
+	expires := certs[0].NotAfter
+	check.CAExpires = &expires
+	if issue := expiryIssue("CA certificate", expires, now); issue != "" {
+		check.Issues = append(check.Issues, issue)
+	}
+	return roots
+}
+
+// checkWebhookService checks the endpoints of a webhook service and the
+// serving certificate mounted by the controllers behind it
+func (c *Client) checkWebhookService(ctx context.Context, check *WebhookServiceCheck, namespace, name string, roots *x509.CertPool, now time.Time) error {
+	service, err := c.k8sClient.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		check.Issues = append(check.Issues, "the service does not exist")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get webhook service %s/%s: %w", namespace, name, err)
+	}
+
+	slices, err := c.k8sClient.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.Set{discoveryv1.LabelServiceName: name}.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list endpoints of webhook service %s/%s: %w", namespace, name, err)
+	}
+	for _, slice := range slices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				check.ReadyEndpoints++
+			}
+		}
+	}
+	if check.ReadyEndpoints == 0 {
+		check.Issues = append(check.Issues, "the service has no ready endpoints, the webhook calls are refused")
+	}
+
+	secret, err := c.servingCertSecret(ctx, service)
+	if err != nil || secret == nil {
+		return err
+	}
+	certs := parseCertificates(secret.Data[corev1.TLSCertKey])
+	if len(certs) == 0 {
+		check.Issues = append(check.Issues, fmt.Sprintf("secret %s holds no valid serving certificate", secret.Name))
+		return nil
+	}
+	serving := certs[0]
+	check.ServingCertExpires = &serving.NotAfter
+	if issue := expiryIssue("serving certificate", serving.NotAfter, now); issue != "" {
+		check.Issues = append(check.Issues, issue)
+	}
+	if roots == nil {
+		return nil
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err = serving.Verify(x509.VerifyOptions{
+		DNSName:       fmt.Sprintf("%s.%s.svc", name, namespace),
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	})
+	if err != nil {
+		check.Issues = append(check.Issues, fmt.Sprintf("the serving certificate does not verify against the CA bundle: %v", err))
+	}
+	return nil
+}
+
+// servingCertSecret finds the TLS secret mounted by the deployment behind a
+// service. It returns nil if no deployment or secret is found, e.g. for
+// webhooks served outside the cluster.
+func (c *Client) servingCertSecret(ctx context.Context, service *corev1.Service) (*corev1.Secret, error) {
+	if len(service.Spec.Selector) == 0 {
+		return nil, nil
+	}
+	deployments, err := c.k8sClient.AppsV1().Deployments(service.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments in %s: %w", service.Namespace, err)
+	}
+	selector := labels.SelectorFromSet(service.Spec.Selector)
+	for _, deployment := range deployments.Items {
+		if !selector.Matches(labels.Set(deployment.Spec.Template.Labels)) {
+			continue
+		}
+		for _, volume := range deployment.Spec.Template.Spec.Volumes {
+			if volume.Secret == nil {
+				continue
+			}
+			secret, err := c.k8sClient.CoreV1().Secrets(service.Namespace).Get(ctx, volume.Secret.SecretName, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get secret %s/%s: %w", service.Namespace, volume.Secret.SecretName, err)
+			}
+			if _, ok := secret.Data[corev1.TLSCertKey]; ok {
+				return secret, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// dryRunCanary creates a minimal Cluster in dry-run mode, which runs the
+// defaulting and validating webhooks of clusters without persisting it
+func (c *Client) dryRunCanary(ctx context.Context, namespace string) *WebhookDryRun {
+	result := &WebhookDryRun{Kind: "Cluster", Namespace: namespace}
+	canary := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{
+		Namespace:    namespace,
+		GenerateName: "mcp-capi-webhook-canary-",
+	}}
+	err := c.ctrlClient.Create(ctx, canary, client.DryRunAll)
+	switch {
+	case err == nil:
+		result.Reachable = true
+		result.Message = "the webhooks admitted the canary cluster"
+	case strings.Contains(err.Error(), "failed calling webhook"):
+		result.Message = err.Error()
+	case apierrors.IsInvalid(err) || (apierrors.IsForbidden(err) && strings.Contains(err.Error(), "admission webhook")):
+		result.Reachable = true
+		result.Message = fmt.Sprintf("the webhooks rejected the canary cluster, so they are reachable: %v", err)
+	default:
+		result.Message = err.Error()
+	}
+	return result
+}
+
+// parseCertificates decodes the PEM certificates of data, skipping others
+func parseCertificates(data []byte) []*x509.Certificate {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certs = append(certs, cert)
+		}
+	}
+}
+
+// expiryIssue describes an expired or soon expiring certificate
+func expiryIssue(what string, expires, now time.Time) string {
+	switch {
+	case now.After(expires):
+		return fmt.Sprintf("the %s expired at %s", what, expires.Format(time.RFC3339))
+	case expires.Sub(now) < certificateExpiryWarning:
+		return fmt.Sprintf("the %s expires at %s", what, expires.Format(time.RFC3339))
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+package capi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newTestCertificates returns a PEM CA and a serving certificate it signed
+// for dnsName, valid until notAfter
+func newTestCertificates(t *testing.T, dnsName string, notAfter time.Time) ([]byte, []byte) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "webhook-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	servingKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	servingTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	servingDER, err := x509.CreateCertificate(rand.Reader, servingTemplate, ca, &servingKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: servingDER})
+}
+
+func TestCheckWebhooks(t *testing.T) {
+	caPEM, servingPEM := newTestCertificates(t, "capi-webhook-service.capi-system.svc", time.Now().Add(2*24*time.Hour))
+	ready := true
+	objects := []runtime.Object{
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "capi-validating-webhook-configuration", Labels: map[string]string{clusterv1.ProviderNameLabel: "cluster-api"}},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{
+				{Name: "validation.cluster.cluster.x-k8s.io", ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service: &admissionregistrationv1.ServiceReference{Namespace: "capi-system", Name: "capi-webhook-service"}, CABundle: caPEM}},
+				{Name: "validation.machine.cluster.x-k8s.io", ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service: &admissionregistrationv1.ServiceReference{Namespace: "capi-system", Name: "capi-webhook-service"}, CABundle: caPEM}},
+			},
+		},
+		&admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "capa-mutating-webhook-configuration", Labels: map[string]string{clusterv1.ProviderNameLabel: "infrastructure-aws"}},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{
+				{Name: "default.awscluster.infrastructure.cluster.x-k8s.io", ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service: &admissionregistrationv1.ServiceReference{Namespace: "capa-system", Name: "capa-webhook-service"}, CABundle: caPEM}},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "capi-system", Name: "capi-webhook-service"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"control-plane": "controller-manager"}},
+		},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "capi-system", Name: "capi-webhook-service-abc", Labels: map[string]string{discoveryv1.LabelServiceName: "capi-webhook-service"}},
+			Endpoints:  []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: &ready}}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "capi-system", Name: "capi-controller-manager"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"control-plane": "controller-manager"}},
+				Spec: corev1.PodSpec{Volumes: []corev1.Volume{{Name: "cert", VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{SecretName: "capi-webhook-service-cert"}}}}},
+			}},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "capi-system", Name: "capi-webhook-service-cert"},
+			Data:       map[string][]byte{corev1.TLSCertKey: servingPEM},
+		},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "capa-system", Name: "capa-webhook-service"}},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clusterv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	ctrlClient := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			return errors.New(`Internal error occurred: failed calling webhook "default.cluster.cluster.x-k8s.io": dial tcp 10.0.0.1:443: connect: connection refused`)
+		},
+	}).Build()
+	c := &Client{ctrlClient: ctrlClient, k8sClient: k8sfake.NewClientset(objects...)}
+
+	health, err := c.CheckWebhooks(context.Background(), "default", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.Healthy || len(health.Services) != 2 {
+		t.Fatalf("health = %+v, want 2 unhealthy services", health)
+	}
+
+	capa := health.Services[1]
+	if capa.Service != "capa-system/capa-webhook-service" || capa.ReadyEndpoints != 0 || capa.Healthy {
+		t.Errorf("capa check = %+v, want no ready endpoints", capa)
+	}
+
+	capi := health.Services[0]
+	if len(capi.Webhooks) != 2 || capi.ReadyEndpoints != 1 || capi.ServingCertExpires == nil {
+		t.Errorf("capi check = %+v", capi)
+	}
+	if len(capi.Issues) != 1 || !strings.Contains(capi.Issues[0], "serving certificate expires") {
+		t.Errorf("capi issues = %v, want the soon expiring serving certificate", capi.Issues)
+	}
+
+	if health.DryRun == nil || health.DryRun.Reachable || !strings.Contains(health.DryRun.Message, "connection refused") {
+		t.Errorf("dry run = %+v, want unreachable webhooks", health.DryRun)
+	}
+}
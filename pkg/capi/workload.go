@@ -0,0 +1,149 @@
+package capi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/remotecommand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PodExec is a command run in a container of a workload cluster pod
+type PodExec struct {
+	Namespace string
+	Pod       string
+	Container string
+	Command   []string
+}
+
+// WorkloadClients reach workload clusters instead of their admin kubeconfig,
+// such as the fake clients of the capitest package
+type WorkloadClients struct {
+	// Clientset reads and changes core Kubernetes objects, such as nodes
+	Clientset kubernetes.Interface
+	// Client applies objects of any kind
+	Client client.Client
+	// CAPI manages the Cluster API objects of workload clusters
+	CAPI *Client
+}
+
+// UseWorkloadClients makes the client reach every workload cluster through
+// clients. Nil clients keep connecting through the admin kubeconfig.
+func (c *Client) UseWorkloadClients(clients WorkloadClients) {
+	if clients.Clientset != nil {
+		c.newWorkloadClientset = func(string) (kubernetes.Interface, error) { return clients.Clientset, nil }
+	}
+	if clients.Client != nil {
+		c.newWorkloadClient = func(string) (client.Client, error) { return clients.Client, nil }
+	}
+	if clients.CAPI != nil {
+		c.newWorkloadCAPIClient = func(string) (*Client, error) { return clients.CAPI, nil }
+	}
+}
+
+// workloadConfig creates the rest config of a workload cluster from its admin
+// kubeconfig
+func (c *Client) workloadConfig(kubeconfig string) (*rest.Config, error) {
+	config, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeconfig))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+	// Workload clusters get the same request timeout, rate limit and
+	// operation timeouts
+	if c.config != nil {
+		config.Timeout = c.config.Timeout
+		config.QPS = c.config.QPS
+		config.Burst = c.config.Burst
+	}
+	return withRequestTimeouts(config, c.timeouts), nil
+}
+
+// workloadClientset connects to a workload cluster with its admin kubeconfig
+func (c *Client) workloadClientset(kubeconfig string) (kubernetes.Interface, error) {
+	if c.newWorkloadClientset != nil {
+		return c.newWorkloadClientset(kubeconfig)
+	}
+	config, err := c.workloadConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workload cluster client: %w", err)
+	}
+	return clientset, nil
+}
+
+// workloadClient connects to a workload cluster with its admin kubeconfig to
+// apply objects of any kind
+func (c *Client) workloadClient(kubeconfig string) (client.Client, error) {
+	if c.newWorkloadClient != nil {
+		return c.newWorkloadClient(kubeconfig)
+	}
+	config, err := c.workloadConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	workloadClient, err := client.New(config, client.Options{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workload cluster client: %w", err)
+	}
+	return workloadClient, nil
+}
+
+// execInWorkloadPod runs a command in a pod of a workload cluster and returns
+// its standard output
+func (c *Client) execInWorkloadPod(ctx context.Context, kubeconfig string, exec PodExec) (string, error) {
+	if c.execInWorkload != nil {
+		return c.execInWorkload(ctx, kubeconfig, exec)
+	}
+	config, err := c.workloadConfig(kubeconfig)
+	if err != nil {
+		return "", err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to create workload cluster client: %w", err)
+	}
+
+	request := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(exec.Namespace).Name(exec.Pod).SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: exec.Container,
+			Command:   exec.Command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", request.URL())
+	if err != nil {
+		return "", fmt.Errorf("failed to exec in pod %s/%s: %w", exec.Namespace, exec.Pod, err)
+	}
+	var stdout, stderr bytes.Buffer
+	if err := executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			err = fmt.Errorf("%w: %s", err, message)
+		}
+		return "", workloadError("Pod", client.ObjectKey{Namespace: exec.Namespace, Name: exec.Pod}, err)
+	}
+	return stdout.String(), nil
+}
+
+// workloadError classifies errors of requests to workload clusters, flagging
+// transport failures as ErrWorkloadUnreachable
+func workloadError(kind string, key client.ObjectKey, err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) || apierrors.IsServiceUnavailable(err) || apierrors.IsTimeout(err) {
+		return fmt.Errorf("%w: %w", ErrWorkloadUnreachable, err)
+	}
+	return resourceError(kind, key, err)
+}